@@ -0,0 +1,255 @@
+// internal/api/auth.go
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Errors returned by the built-in credential checks
+var (
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrTokenExpired  = errors.New("token expired")
+	ErrUnknownAPIKey = errors.New("unknown API key")
+)
+
+// apiKeyHeader carries a static API key
+const apiKeyHeader = "X-API-Key"
+
+// Caller is an authenticated principal, the wallet it owns and the roles it holds,
+// which the server's Policy decides its requests by
+type Caller struct {
+	Principal string
+	UserID    string // wallet the caller owns; empty leaves it to PrincipalWallet
+	Roles     []string
+}
+
+// Admin reports whether c holds RoleAdmin
+func (c *Caller) Admin() bool {
+	return slices.Contains(c.Roles, RoleAdmin)
+}
+
+// CredentialCheck authenticates a request by one kind of credential. It returns a nil
+// Caller and no error when the request carries no credential of its kind, so the next
+// check can try.
+type CredentialCheck func(r *http.Request) (*Caller, error)
+
+// authenticatedCallerKey is the context key of the authenticated *Caller
+type authenticatedCallerKey struct{}
+
+// CallerFromContext returns the caller identified by AuthenticateCaller, if any
+func CallerFromContext(ctx context.Context) *Caller {
+	caller, _ := ctx.Value(authenticatedCallerKey{}).(*Caller)
+	return caller
+}
+
+// AuthenticateCaller identifies the caller by the first check that finds credentials
+// on the request, rejecting requests with none or with invalid ones with 401. The
+// server then checks the caller's requests against DefaultPolicy, or the Policy
+// installed by Authorize.
+//
+//	api.AuthenticateCaller(api.JWT(cfg), api.APIKeys(keys))
+func AuthenticateCaller(checks ...CredentialCheck) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var caller *Caller
+			for _, check := range checks {
+				c, err := check(r)
+				if err != nil {
+					writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated: " + err.Error()})
+					return
+				}
+				if c != nil {
+					caller = c
+					break
+				}
+			}
+			if caller == nil {
+				writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated: no credentials"})
+				return
+			}
+
+			if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+				info.principal = caller.Principal
+			}
+			ctx := context.WithValue(r.Context(), principalKey{}, caller.Principal)
+			ctx = context.WithValue(ctx, authenticatedCallerKey{}, caller)
+			if caller.UserID != "" {
+				ctx = context.WithValue(ctx, walletUserKey{}, caller.UserID)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeys authenticates requests sending one of keys in X-API-Key as the caller it maps
+// to. Keys are looked up by their SHA-256 hash, so lookup timing says nothing about
+// the keys held.
+func APIKeys(keys map[string]Caller) CredentialCheck {
+	byHash := make(map[[sha256.Size]byte]Caller, len(keys))
+	for key, caller := range keys {
+		byHash[sha256.Sum256([]byte(key))] = caller
+	}
+	return func(r *http.Request) (*Caller, error) {
+		key := r.Header.Get(apiKeyHeader)
+		if key == "" {
+			return nil, nil
+		}
+		caller, ok := byHash[sha256.Sum256([]byte(key))]
+		if !ok {
+			return nil, ErrUnknownAPIKey
+		}
+		caller.Roles = slices.Clone(caller.Roles)
+		return &caller, nil
+	}
+}
+
+// JWTConfig says which bearer tokens JWT accepts and how their claims map to a caller
+type JWTConfig struct {
+	// Secret verifies HS256 tokens; PublicKey verifies RS256 ones. Tokens signed with
+	// any other algorithm are refused.
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+
+	Issuer   string // required iss, when set
+	Audience string // required aud, when set
+	Leeway   time.Duration
+
+	// UserClaim names the claim holding the caller's user ID (default "user_id");
+	// RolesClaim the string or list of strings holding its roles (default "roles")
+	UserClaim  string
+	RolesClaim string
+
+	Now func() time.Time // defaults to time.Now
+}
+
+// JWT authenticates requests sending a bearer token signed per cfg. The token must
+// carry sub, which becomes the principal, and exp; nbf, iss and aud are checked when
+// present or configured.
+func JWT(cfg JWTConfig) CredentialCheck {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "user_id"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return func(r *http.Request) (*Caller, error) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return nil, nil
+		}
+		claims, err := cfg.verify(token)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.caller(claims)
+	}
+}
+
+// verify checks token's signature and returns its claims
+func (cfg *JWTConfig) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == "HS256" && len(cfg.Secret) > 0:
+		mac := hmac.New(sha256.New, cfg.Secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, ErrInvalidToken
+		}
+	case header.Alg == "RS256" && cfg.PublicKey != nil:
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(cfg.PublicKey, crypto.SHA256, digest[:], sig) != nil {
+			return nil, ErrInvalidToken
+		}
+	default:
+		return nil, ErrInvalidToken
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// caller checks the registered claims and maps the rest to a Caller
+func (cfg *JWTConfig) caller(claims map[string]any) (*Caller, error) {
+	now := cfg.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(cfg.Leeway)) {
+		return nil, ErrTokenExpired
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(cfg.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, ErrInvalidToken
+	}
+	if cfg.Issuer != "" && claims["iss"] != cfg.Issuer {
+		return nil, ErrInvalidToken
+	}
+	if cfg.Audience != "" && !slices.Contains(stringsClaim(claims["aud"]), cfg.Audience) {
+		return nil, ErrInvalidToken
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, ErrInvalidToken
+	}
+
+	userID, _ := claims[cfg.UserClaim].(string)
+	return &Caller{Principal: sub, UserID: userID, Roles: stringsClaim(claims[cfg.RolesClaim])}, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token into v
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringsClaim reads a claim that may be a single string or a list of them
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
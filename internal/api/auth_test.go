@@ -0,0 +1,206 @@
+// internal/api/auth_test.go
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"wallet-app/internal/wallet"
+)
+
+var authNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// signHS256 returns a token with claims signed by secret under alg
+func signHS256(alg string, secret []byte, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// unsigned strips the signature from token
+func unsigned(token string) string {
+	return token[:strings.LastIndex(token, ".")+1]
+}
+
+// bearer returns a request carrying token
+func bearer(token string) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("s3cret")
+	check := JWT(JWTConfig{Secret: secret, Issuer: "https://login.example.com", Audience: "wallet", Now: func() time.Time { return authNow }})
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{"sub": "sub-1", "iss": "https://login.example.com", "aud": []string{"wallet", "other"}, "exp": authNow.Add(time.Minute).Unix()}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantErr   error
+		wantUser  string
+		wantAdmin bool
+	}{
+		{"valid", signHS256("HS256", secret, claims(map[string]any{"user_id": "alice"})), nil, "alice", false},
+		{"single role", signHS256("HS256", secret, claims(map[string]any{"roles": "admin"})), nil, "", true},
+		{"role list", signHS256("HS256", secret, claims(map[string]any{"roles": []string{"support", "admin"}})), nil, "", true},
+		{"expired", signHS256("HS256", secret, claims(map[string]any{"exp": authNow.Add(-time.Second).Unix()})), ErrTokenExpired, "", false},
+		{"no expiry", signHS256("HS256", secret, claims(map[string]any{"exp": nil})), ErrInvalidToken, "", false},
+		{"not yet valid", signHS256("HS256", secret, claims(map[string]any{"nbf": authNow.Add(time.Minute).Unix()})), ErrInvalidToken, "", false},
+		{"other issuer", signHS256("HS256", secret, claims(map[string]any{"iss": "https://evil.example.com"})), ErrInvalidToken, "", false},
+		{"other audience", signHS256("HS256", secret, claims(map[string]any{"aud": "billing"})), ErrInvalidToken, "", false},
+		{"no subject", signHS256("HS256", secret, claims(map[string]any{"sub": ""})), ErrInvalidToken, "", false},
+		{"wrong secret", signHS256("HS256", []byte("guess"), claims(nil)), ErrInvalidToken, "", false},
+		{"unsigned", unsigned(signHS256("none", secret, claims(nil))), ErrInvalidToken, "", false},
+		{"RS256 without a key", signHS256("RS256", secret, claims(nil)), ErrInvalidToken, "", false},
+		{"malformed", "not.a-token", ErrInvalidToken, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller, err := check(bearer(tt.token))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("JWT() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if caller.Principal != "sub-1" || caller.UserID != tt.wantUser || caller.Admin() != tt.wantAdmin {
+				t.Errorf("caller = %+v, want user %q, admin %v", caller, tt.wantUser, tt.wantAdmin)
+			}
+		})
+	}
+
+	if caller, err := check(bearer("")); caller != nil || err != nil {
+		t.Errorf("JWT() without a token = %+v, %v, want no credentials", caller, err)
+	}
+}
+
+func TestJWT_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256"})
+	payload, _ := json.Marshal(map[string]any{"sub": "svc", "exp": authNow.Add(time.Hour).Unix()})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	token := signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	check := JWT(JWTConfig{PublicKey: &key.PublicKey, Now: func() time.Time { return authNow }})
+	if caller, err := check(bearer(token)); err != nil || caller.Principal != "svc" {
+		t.Errorf("JWT() = %+v, %v, want svc", caller, err)
+	}
+	// A token signed with the public key as an HMAC secret is refused
+	if _, err := check(bearer(signHS256("HS256", key.PublicKey.N.Bytes(), map[string]any{"sub": "svc", "exp": authNow.Add(time.Hour).Unix()}))); err != ErrInvalidToken {
+		t.Errorf("JWT() with a switched algorithm error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	check := APIKeys(map[string]Caller{"k-ops": {Principal: "ops", Roles: []string{RoleAdmin}}})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if caller, err := check(req); caller != nil || err != nil {
+		t.Errorf("APIKeys() without a key = %+v, %v, want no credentials", caller, err)
+	}
+	req.Header.Set(apiKeyHeader, "k-ops")
+	caller, err := check(req)
+	if err != nil || caller.Principal != "ops" || !caller.Admin() {
+		t.Errorf("APIKeys() = %+v, %v, want admin ops", caller, err)
+	}
+	caller.Roles[0] = "tampered"
+	if again, _ := check(req); !again.Admin() {
+		t.Errorf("APIKeys() returned shared roles: %+v", again)
+	}
+	req.Header.Set(apiKeyHeader, "k-guess")
+	if _, err := check(req); err != ErrUnknownAPIKey {
+		t.Errorf("APIKeys() with an unknown key error = %v, want %v", err, ErrUnknownAPIKey)
+	}
+}
+
+func TestServer_Authorization(t *testing.T) {
+	ws := wallet.NewWalletService()
+	for _, id := range []string{"alice", "bob"} {
+		ws.CreateUser(id, id, id+"@example.com")
+		ws.Deposit(id, 50, "seed")
+	}
+	ws.Withdraw("bob", 80, "rent")
+	bobFailures, _ := ws.ListFailedOperations("bob", "")
+
+	secret := []byte("s3cret")
+	srv := NewServer(ws, AuthenticateCaller(
+		JWT(JWTConfig{Secret: secret, Now: func() time.Time { return authNow }}),
+		APIKeys(map[string]Caller{"k-ops": {Principal: "ops", Roles: []string{RoleAdmin}}}),
+	))
+	alice := signHS256("HS256", secret, map[string]any{"sub": "sub-alice", "user_id": "alice", "exp": authNow.Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name       string
+		token      string
+		apiKey     string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"no credentials", "", "", "GET", "/users/alice/balance", "", http.StatusUnauthorized},
+		{"bad token", "x.y.z", "", "GET", "/users/alice/balance", "", http.StatusUnauthorized},
+		{"own balance", alice, "", "GET", "/users/alice/balance", "", http.StatusOK},
+		{"own wallet via /me", alice, "", "GET", "/me/balance", "", http.StatusOK},
+		{"other balance", alice, "", "GET", "/users/bob/balance", "", http.StatusForbidden},
+		{"deposit elsewhere", alice, "", "POST", "/users/bob/deposits", `{"amount":"5"}`, http.StatusForbidden},
+		{"transfer from self", alice, "", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"5"}`, http.StatusCreated},
+		{"transfer from other", alice, "", "POST", "/transfers", `{"from":"bob","to":"alice","amount":"5"}`, http.StatusForbidden},
+		{"retry other's failure", alice, "", "POST", "/failures/" + bobFailures[0].ID + "/retry", "", http.StatusForbidden},
+		{"create another user", alice, "", "POST", "/users", `{"id":"carol","name":"Carol","email":"c@example.com"}`, http.StatusForbidden},
+		{"card processor", alice, "", "POST", "/cards/authorizations", `{"card_id":"c1","amount":"1"}`, http.StatusForbidden},
+		{"rates", alice, "", "GET", "/rates?from=USD&to=EUR", "", http.StatusOK},
+		{"admin reads any", "", "k-ops", "GET", "/users/bob/balance", "", http.StatusOK},
+		{"admin creates users", "", "k-ops", "POST", "/users", `{"id":"carol","name":"Carol","email":"c@example.com"}`, http.StatusCreated},
+		{"admin without a wallet", "", "k-ops", "GET", "/me/balance", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, rec.Code, rec.Body, tt.wantStatus)
+			}
+		})
+	}
+
+	if history, _ := ws.GetTransactionHistory("bob"); !slices.ContainsFunc(history, func(tx *wallet.Transaction) bool { return tx.FromUserID == "alice" }) {
+		t.Errorf("bob's history %+v lacks alice's transfer", history)
+	}
+}
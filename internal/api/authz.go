@@ -0,0 +1,114 @@
+// internal/api/authz.go
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// ErrForbidden is returned by DefaultPolicy for actions the caller may not take
+var ErrForbidden = errors.New("caller may not perform this action")
+
+// Roles DefaultPolicy knows. Callers holding neither are plain users.
+const (
+	RoleAdmin   = "admin"   // may do anything to any wallet
+	RoleSupport = "support" // may also read any wallet and its history
+)
+
+// Action names what a request does to a wallet
+type Action string
+
+const (
+	ActionReadWallet   Action = "wallet:read"    // balance, history, pending items, limits, closure, failures
+	ActionMoveFunds    Action = "wallet:move"    // withdrawals, transfers and retries
+	ActionDeposit      Action = "wallet:deposit" // staff crediting a wallet from outside, or retrying it
+	ActionManageWallet Action = "wallet:manage"  // closure and statement descriptors
+	ActionSetLimits    Action = "wallet:limits"  // staff changing spending limits
+	ActionCreateUser   Action = "user:create"
+	ActionFreeze       Action = "wallet:freeze" // admin freezes of wallet segments
+	ActionAdjust       Action = "wallet:adjust" // staff balance adjustments
+	ActionProcessCards Action = "card:process"  // card processor callbacks
+	ActionReadAudit    Action = "audit:read"    // the audit log of every caller
+)
+
+// staffActions are the actions DefaultPolicy leaves to admins even on a caller's own
+// wallet
+var staffActions = []Action{ActionDeposit, ActionSetLimits, ActionFreeze, ActionAdjust, ActionProcessCards, ActionReadAudit}
+
+// Policy decides whether caller may take action on userID's wallet, returning an error
+// saying why not. userID is empty for actions not aimed at one wallet. caller is nil
+// for requests AuthenticateCaller did not see.
+type Policy func(ctx context.Context, caller *Caller, action Action, userID string) error
+
+// policyKey is the context key of the Policy installed by Authorize
+type policyKey struct{}
+
+// Authorize has the server check every request against policy instead of
+// DefaultPolicy. It must run after AuthenticateCaller so policy sees the caller.
+func Authorize(policy Policy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), policyKey{}, policy)))
+		})
+	}
+}
+
+// DefaultPolicy lets admins do anything and support staff read any wallet. Anyone else
+// may only create, read, use and manage their own wallet, and only admins may deposit,
+// change spending limits, freeze wallets, adjust balances, read the audit log or call
+// the card processor endpoints.
+func DefaultPolicy(ctx context.Context, caller *Caller, action Action, userID string) error {
+	switch {
+	case caller == nil:
+		return ErrForbidden
+	case caller.Admin():
+		return nil
+	case slices.Contains(staffActions, action):
+		return ErrForbidden
+	case action == ActionReadWallet && slices.Contains(caller.Roles, RoleSupport):
+		return nil
+	case userID != "" && WalletUserFromContext(ctx) == userID:
+		return nil
+	}
+	return ErrForbidden
+}
+
+// permit reports whether the request may take action on userID's wallet, responding
+// with 403 when it may not. Requests are checked by the policy installed by Authorize,
+// or by DefaultPolicy when AuthenticateCaller identified the caller; others are not
+// checked.
+func (s *Server) permit(w http.ResponseWriter, r *http.Request, action Action, userID string) bool {
+	caller := CallerFromContext(r.Context())
+	policy, ok := r.Context().Value(policyKey{}).(Policy)
+	if !ok {
+		if caller == nil {
+			return true
+		}
+		policy = DefaultPolicy
+	}
+	if err := policy(r.Context(), caller, action, userID); err != nil {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "forbidden: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// forWallet serves handler to callers permitted action on the wallet named in the path
+func (s *Server) forWallet(action Action, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.permit(w, r, action, r.PathValue("id")) {
+			handler(w, r)
+		}
+	}
+}
+
+// forService serves handler to callers permitted action regardless of wallet
+func (s *Server) forService(action Action, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.permit(w, r, action, "") {
+			handler(w, r)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// internal/api/authz_test.go
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wallet-app/internal/wallet"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	user := &Caller{Principal: "u", UserID: "alice"}
+	support := &Caller{Principal: "s", Roles: []string{RoleSupport}}
+	admin := &Caller{Principal: "a", Roles: []string{RoleAdmin}}
+	ctx := context.WithValue(context.Background(), walletUserKey{}, "alice")
+
+	tests := []struct {
+		name    string
+		caller  *Caller
+		action  Action
+		userID  string
+		allowed bool
+	}{
+		{"user reads own wallet", user, ActionReadWallet, "alice", true},
+		{"user moves own funds", user, ActionMoveFunds, "alice", true},
+		{"user reads another wallet", user, ActionReadWallet, "bob", false},
+		{"user manages own wallet", user, ActionManageWallet, "alice", true},
+		{"user deposits to own wallet", user, ActionDeposit, "alice", false},
+		{"user sets own limits", user, ActionSetLimits, "alice", false},
+		{"user adjusts own balance", user, ActionAdjust, "alice", false},
+		{"user freezes", user, ActionFreeze, "", false},
+		{"support reads any wallet", support, ActionReadWallet, "bob", true},
+		{"support moves funds", support, ActionMoveFunds, "bob", false},
+		{"support manages a wallet", support, ActionManageWallet, "bob", false},
+		{"support processes cards", support, ActionProcessCards, "", false},
+		{"support reads the audit log", support, ActionReadAudit, "", false},
+		{"admin adjusts", admin, ActionAdjust, "bob", true},
+		{"admin deposits", admin, ActionDeposit, "bob", true},
+		{"admin sets limits", admin, ActionSetLimits, "bob", true},
+		{"admin freezes", admin, ActionFreeze, "", true},
+		{"anonymous", nil, ActionReadWallet, "alice", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := DefaultPolicy(ctx, tt.caller, tt.action, tt.userID); (err == nil) != tt.allowed {
+				t.Errorf("DefaultPolicy() error = %v, want allowed %v", err, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestServer_RolePolicy(t *testing.T) {
+	ws := wallet.NewWalletService()
+	for _, id := range []string{"alice", "bob"} {
+		ws.CreateUser(id, id, id+"@example.com")
+		ws.Deposit(id, 50, "seed")
+	}
+	ws.SetUserAttributes("bob", wallet.UserAttributes{Country: "GB"})
+	ws.SetStaffRole("ops", wallet.StaffAdmin)
+	keys := APIKeys(map[string]Caller{
+		"k-alice":   {Principal: "alice-app", UserID: "alice"},
+		"k-support": {Principal: "desk", Roles: []string{RoleSupport}},
+		"k-ops":     {Principal: "ops", Roles: []string{RoleAdmin}},
+	})
+	srv := NewServer(ws, AuthenticateCaller(keys))
+
+	tests := []struct {
+		name       string
+		key        string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"support reads any history", "k-support", "GET", "/users/bob/transactions", "", http.StatusOK, `"description":"seed"`},
+		{"support cannot move funds", "k-support", "POST", "/users/bob/withdrawals", `{"amount":"5"}`, http.StatusForbidden, "may not"},
+		{"support cannot freeze", "k-support", "POST", "/segments/freeze", `{"country":"GB","reason":"fraud ring"}`, http.StatusForbidden, "may not"},
+		{"user cannot deposit", "k-alice", "POST", "/users/alice/deposits", `{"amount":"1000"}`, http.StatusForbidden, "may not"},
+		{"user cannot lift own limits", "k-alice", "PUT", "/users/alice/limits", `{"daily":"1000000"}`, http.StatusForbidden, "may not"},
+		{"user withdraws", "k-alice", "POST", "/users/alice/withdrawals", `{"amount":"5"}`, http.StatusCreated, `"balance":"45"`},
+		{"admin deposits", "k-ops", "POST", "/users/alice/deposits", `{"amount":"5"}`, http.StatusCreated, `"balance":"50"`},
+		{"admin sets limits", "k-ops", "PUT", "/users/alice/limits", `{"daily":"100"}`, http.StatusOK, `"daily":"100"`},
+		{"user cannot adjust", "k-alice", "POST", "/users/alice/adjustments", `{"amount":"10","reason":"goodwill"}`, http.StatusForbidden, "may not"},
+		{"admin adjusts", "k-ops", "POST", "/users/alice/adjustments", `{"amount":"10","reason":"goodwill"}`, http.StatusCreated, `"status":"posted"`},
+		{"adjustment needs a reason code", "k-ops", "POST", "/users/alice/adjustments", `{"amount":"10","reason":"because"}`, http.StatusBadRequest, "reason"},
+		{"admin freezes a segment", "k-ops", "POST", "/segments/freeze", `{"country":"GB","reason":"fraud ring"}`, http.StatusOK, `"affected":["bob"]`},
+		{"frozen wallet", "k-ops", "POST", "/users/bob/withdrawals", `{"amount":"5"}`, http.StatusConflict, "frozen"},
+		{"admin unfreezes", "k-ops", "POST", "/segments/unfreeze", `{"country":"GB","reason":"cleared"}`, http.StatusOK, `"kind":"unfreeze"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(apiKeyHeader, tt.key)
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, rec.Code, rec.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	if b, _ := ws.GetBalance("alice"); b != 60 {
+		t.Errorf("alice's balance = %v, want 60", b)
+	}
+	if f := ws.GetAdminFreeze("bob"); f != nil {
+		t.Errorf("bob still frozen: %+v", f)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	maintenance := errors.New("writes are paused for maintenance")
+	// An embedder's policy: reads as usual, no writes at all
+	policy := func(ctx context.Context, caller *Caller, action Action, userID string) error {
+		if action != ActionReadWallet {
+			return maintenance
+		}
+		return DefaultPolicy(ctx, caller, action, userID)
+	}
+	keys := APIKeys(map[string]Caller{"k-ops": {Principal: "ops", Roles: []string{RoleAdmin}}})
+	srv := NewServer(ws, AuthenticateCaller(keys), Authorize(policy))
+
+	for _, tt := range []struct {
+		method, path, body string
+		wantStatus         int
+	}{
+		{"GET", "/users/alice/balance", "", http.StatusOK},
+		{"POST", "/users/alice/deposits", `{"amount":"5"}`, http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set(apiKeyHeader, "k-ops")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, rec.Code, rec.Body, tt.wantStatus)
+		}
+		if rec.Code == http.StatusForbidden && !strings.Contains(rec.Body.String(), maintenance.Error()) {
+			t.Errorf("body = %s, want the policy's reason", rec.Body)
+		}
+	}
+}
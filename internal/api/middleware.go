@@ -0,0 +1,238 @@
+// internal/api/middleware.go
+package api
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-app/internal/ratelimit"
+	"wallet-app/internal/wallet"
+)
+
+// Middleware wraps a handler with a cross-cutting concern
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h in middleware; the first runs outermost
+func Chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// Authenticator identifies the caller of a request, returning an error when the request
+// carries no valid credentials
+type Authenticator func(r *http.Request) (principal string, err error)
+
+// principalKey is the context key of the authenticated caller
+type principalKey struct{}
+
+// PrincipalFromContext returns the caller identified by Authenticate, if any
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// Authenticate rejects requests auth does not accept with 401 and passes the rest on
+// with the caller in their context
+func Authenticate(auth Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := auth(r)
+			if err != nil {
+				writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated: " + err.Error()})
+				return
+			}
+			if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+				info.principal = principal
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+		})
+	}
+}
+
+// walletUserKey is the context key of the user whose wallet the caller owns
+type walletUserKey struct{}
+
+// WalletUserFromContext returns the user PrincipalWallet resolved the caller to, if any
+func WalletUserFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(walletUserKey{}).(string)
+	return userID
+}
+
+// PrincipalWallet resolves the caller identified by Authenticate, taken as an OAuth
+// subject of issuer, to the user it is linked to, whose wallet the /me endpoints then
+// serve. Callers without a linked user pass on unresolved, as do callers whose
+// credentials already named their user.
+func PrincipalWallet(ws *wallet.WalletService, issuer string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal := PrincipalFromContext(r.Context()); principal != "" && WalletUserFromContext(r.Context()) == "" {
+				if user, err := ws.GetUserByIdentity(wallet.IdentityOAuth, issuer, principal); err == nil {
+					r = r.WithContext(context.WithValue(r.Context(), walletUserKey{}, user.ID))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimit answers 429 with Retry-After once a caller runs out of tokens in limiter.
+// Callers are keyed by key, or when nil by the authenticated principal, falling back
+// to the client address.
+func RateLimit(limiter *ratelimit.Limiter, key func(*http.Request) string) Middleware {
+	if key == nil {
+		key = callerKey
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retry := limiter.Allow(key(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+				writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "rate limit exceeded"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Tenant serves each request for the tenant resolve names, or when nil the one sent in
+// X-Tenant-Id; response amounts are displayed per that tenant's display policies
+func Tenant(resolve func(*http.Request) string) Middleware {
+	if resolve == nil {
+		resolve = func(r *http.Request) string { return r.Header.Get(tenantHeader) }
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(wallet.ContextWithTenant(r.Context(), resolve(r))))
+		})
+	}
+}
+
+// requestInfo is what inner layers learn about a request, reported back to Metrics and
+// Logging running outside them
+type requestInfo struct {
+	route     string // pattern matched by Server
+	principal string // caller identified by Authenticate
+}
+
+// requestInfoKey is the context key of a request's *requestInfo
+type requestInfoKey struct{}
+
+// withRequestInfo returns r's requestInfo, adding one to r when no outer middleware
+// has yet
+func withRequestInfo(r *http.Request) (*requestInfo, *http.Request) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		return info, r
+	}
+	info := &requestInfo{}
+	return info, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+}
+
+// Metrics counts requests by method, route and status and observes their duration
+func Metrics(m wallet.MetricsRecorder) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info, r := withRequestInfo(r)
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			labels := map[string]string{"method": r.Method, "route": route(info.route), "status": strconv.Itoa(sw.status)}
+			m.IncCounter("http_requests_total", labels)
+			m.ObserveValue("http_request_seconds", time.Since(start).Seconds(), labels)
+		})
+	}
+}
+
+// Trace gives every request a trace ID: the one sent in X-Request-Id, or a new one.
+// The ID is echoed in the response and recorded on the transactions the request
+// creates.
+func Trace() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(traceHeader)
+			if id == "" {
+				id = wallet.NewTraceID()
+				r = r.Clone(r.Context())
+				r.Header.Set(traceHeader, id)
+			}
+			w.Header().Set(traceHeader, id)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Logging logs one line per request with its outcome and duration
+func Logging(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info, r := withRequestInfo(r)
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			level := slog.LevelInfo
+			if sw.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "http request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", sw.status),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("request_id", cmp.Or(w.Header().Get(traceHeader), r.Header.Get(traceHeader))),
+				slog.String("route", route(info.route)),
+				slog.String("principal", info.principal),
+			)
+		})
+	}
+}
+
+// statusWriter remembers the status written through it
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// route labels a request by the pattern it matched, so metrics do not grow a label per
+// user ID
+func route(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	return pattern
+}
+
+// callerKey keys rate limits by principal, or by client address for anonymous callers
+func callerKey(r *http.Request) string {
+	if principal := PrincipalFromContext(r.Context()); principal != "" {
+		return "principal:" + principal
+	}
+	return "addr:" + clientAddr(r)
+}
+
+// clientAddr returns the address of the client that sent r
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,123 @@
+// internal/api/middleware_test.go
+package api
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"wallet-app/internal/ratelimit"
+	"wallet-app/internal/wallet"
+)
+
+// countingMetrics records counters by name and label values
+type countingMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func (m *countingMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name+" "+labels["method"]+" "+labels["route"]+" "+labels["status"]]++
+}
+
+func (m *countingMetrics) ObserveValue(string, float64, map[string]string) {}
+
+func TestServer_Middleware(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.New(1, 2, ratelimit.WithClock(func() time.Time { return now }))
+	metrics := &countingMetrics{counters: make(map[string]int)}
+	var logs bytes.Buffer
+	auth := func(r *http.Request) (string, error) {
+		if key := r.Header.Get("Authorization"); strings.HasPrefix(key, "Bearer ") {
+			return strings.TrimPrefix(key, "Bearer "), nil
+		}
+		return "", errors.New("missing bearer token")
+	}
+	srv := NewServer(ws, Trace(), Logging(slog.New(slog.NewTextHandler(&logs, nil))), Metrics(metrics), Authenticate(auth), RateLimit(limiter, nil))
+
+	steps := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"first", "ops", http.StatusOK},
+		{"second", "ops", http.StatusOK},
+		{"over the limit", "ops", http.StatusTooManyRequests},
+		{"other caller", "audit", http.StatusOK},
+	}
+	for _, s := range steps {
+		req := httptest.NewRequest("GET", "/users/alice/balance", nil)
+		if s.token != "" {
+			req.Header.Set("Authorization", "Bearer "+s.token)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != s.wantStatus {
+			t.Errorf("%s: status = %d, want %d", s.name, rec.Code, s.wantStatus)
+		}
+		if rec.Header().Get("X-Request-Id") == "" {
+			t.Errorf("%s: no request ID in the response", s.name)
+		}
+	}
+
+	if got := metrics.counters["http_requests_total GET GET /users/{id}/balance 200"]; got != 3 {
+		t.Errorf("counted %d successful balance reads, want 3 (counters %v)", got, metrics.counters)
+	}
+	if got := metrics.counters["http_requests_total GET unmatched 429"]; got != 1 {
+		t.Errorf("counted %d limited requests, want 1 (counters %v)", got, metrics.counters)
+	}
+	if !strings.Contains(logs.String(), "principal=audit") || strings.Count(logs.String(), "http request") != len(steps) {
+		t.Errorf("logs = %s", logs.String())
+	}
+}
+
+func TestPrincipalWallet(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 25, "seed")
+	const issuer = "https://login.example.com"
+	ws.LinkIdentity("alice", wallet.ExternalIdentity{Kind: wallet.IdentityOAuth, Issuer: issuer, Subject: "sub-1"})
+
+	auth := func(r *http.Request) (string, error) {
+		return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), nil
+	}
+	srv := NewServer(ws, Authenticate(auth), PrincipalWallet(ws, issuer))
+
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"linked caller", "sub-1", "/me/balance", http.StatusOK, `"user_id":"alice","currency":"USD","balance":"25"`},
+		{"linked caller's history", "sub-1", "/me/transactions", http.StatusOK, `"description":"seed"`},
+		{"unlinked caller", "sub-2", "/me/balance", http.StatusNotFound, "no wallet linked"},
+		{"anonymous", "", "/me/balance", http.StatusUnauthorized, "unauthenticated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("status = %d, body = %s; want %d containing %s", rec.Code, rec.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
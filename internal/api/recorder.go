@@ -0,0 +1,349 @@
+// internal/api/recorder.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RecordedRequest is the captured form of an HTTP request
+type RecordedRequest struct {
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	BodyText    string          `json:"body_text,omitempty"` // used when the body is not JSON
+}
+
+// RecordedResponse is the captured form of an HTTP response
+type RecordedResponse struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	BodyText    string          `json:"body_text,omitempty"`
+}
+
+// Exchange is one recorded request/response pair, stored as a golden file
+type Exchange struct {
+	Seq      int              `json:"seq"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Recorder is middleware that writes every request/response pair passing through it
+// to a golden file in dir, named by sequence number, method and path
+type Recorder struct {
+	next http.Handler
+	dir  string
+
+	mu  sync.Mutex
+	seq int
+	err error
+}
+
+// NewRecorder wraps next, recording exchanges into dir
+func NewRecorder(next http.Handler, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Recorder{next: next, dir: dir}, nil
+}
+
+// ServeHTTP serves the request through the wrapped handler and records the exchange.
+// Requests are serialized so sequence numbers match execution order on replay.
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "reading request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	capture := httptest.NewRecorder()
+	rec.next.ServeHTTP(capture, r)
+
+	for k, v := range capture.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(capture.Code)
+	w.Write(capture.Body.Bytes())
+
+	rec.seq++
+	ex := Exchange{Seq: rec.seq}
+	ex.Request.Method = r.Method
+	ex.Request.Path = r.URL.RequestURI()
+	ex.Request.ContentType = r.Header.Get("Content-Type")
+	ex.Request.Body, ex.Request.BodyText = splitBody(body)
+	ex.Response.Status = capture.Code
+	ex.Response.ContentType = capture.Header().Get("Content-Type")
+	ex.Response.Body, ex.Response.BodyText = splitBody(capture.Body.Bytes())
+
+	if err := rec.write(ex); err != nil && rec.err == nil {
+		rec.err = err
+	}
+}
+
+// Err returns the first error hit while writing golden files
+func (rec *Recorder) Err() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.err
+}
+
+// write stores an exchange as indented JSON
+func (rec *Recorder) write(ex Exchange) error {
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%04d_%s_%s.json", ex.Seq, ex.Request.Method, slug(ex.Request.Path))
+	return os.WriteFile(filepath.Join(rec.dir, name), append(data, '\n'), 0o644)
+}
+
+// LoadExchanges reads the golden files in dir in sequence order
+func LoadExchanges(dir string) ([]Exchange, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	exchanges := make([]Exchange, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var ex Exchange
+		if err := json.Unmarshal(data, &ex); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(p), err)
+		}
+		exchanges = append(exchanges, ex)
+	}
+	sort.Slice(exchanges, func(i, j int) bool { return exchanges[i].Seq < exchanges[j].Seq })
+	return exchanges, nil
+}
+
+// DiffKind classifies a difference found on replay
+type DiffKind string
+
+const (
+	DiffStatus       DiffKind = "status"
+	DiffMissingField DiffKind = "missing_field"
+	DiffNewField     DiffKind = "new_field"
+	DiffTypeChanged  DiffKind = "type_changed"
+	DiffValueChanged DiffKind = "value_changed"
+)
+
+// Breaking reports whether clients written against the recording could break
+func (k DiffKind) Breaking() bool {
+	return k == DiffStatus || k == DiffMissingField || k == DiffTypeChanged
+}
+
+// Difference is one mismatch between a recorded and a replayed response
+type Difference struct {
+	Seq      int
+	Request  string // "METHOD path"
+	Field    string // JSON path within the response body, "" for the status
+	Kind     DiffKind
+	Recorded string
+	Replayed string
+}
+
+// ReplayOptions tunes response comparison
+type ReplayOptions struct {
+	// IgnoreFields lists JSON object keys whose values legitimately differ between
+	// runs, such as generated IDs and timestamps. Presence and type are still checked.
+	IgnoreFields []string
+}
+
+// ReplayReport summarises a replay run
+type ReplayReport struct {
+	Exchanges   int
+	Differences []Difference
+}
+
+// Breaking returns the differences that change status codes or payload shapes
+func (r *ReplayReport) Breaking() []Difference {
+	var breaking []Difference
+	for _, d := range r.Differences {
+		if d.Kind.Breaking() {
+			breaking = append(breaking, d)
+		}
+	}
+	return breaking
+}
+
+// Replay sends every recorded request in dir to h in order and compares the responses
+// with the recording
+func Replay(h http.Handler, dir string, opts ReplayOptions) (*ReplayReport, error) {
+	exchanges, err := LoadExchanges(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ignore := make(map[string]bool, len(opts.IgnoreFields))
+	for _, f := range opts.IgnoreFields {
+		ignore[f] = true
+	}
+
+	report := &ReplayReport{Exchanges: len(exchanges)}
+	for _, ex := range exchanges {
+		body := []byte(ex.Request.BodyText)
+		if len(ex.Request.Body) > 0 {
+			body = ex.Request.Body
+		}
+		req := httptest.NewRequest(ex.Request.Method, ex.Request.Path, bytes.NewReader(body))
+		if ex.Request.ContentType != "" {
+			req.Header.Set("Content-Type", ex.Request.ContentType)
+		}
+
+		got := httptest.NewRecorder()
+		h.ServeHTTP(got, req)
+
+		c := comparer{seq: ex.Seq, request: ex.Request.Method + " " + ex.Request.Path, ignore: ignore}
+		if got.Code != ex.Response.Status {
+			c.add("", DiffStatus, fmt.Sprint(ex.Response.Status), fmt.Sprint(got.Code))
+		}
+
+		gotBody, gotText := splitBody(got.Body.Bytes())
+		switch {
+		case len(ex.Response.Body) > 0 && len(gotBody) > 0:
+			var want, have any
+			json.Unmarshal(ex.Response.Body, &want)
+			json.Unmarshal(gotBody, &have)
+			c.compare("$", want, have, false)
+		case ex.Response.BodyText != gotText || len(ex.Response.Body) != len(gotBody):
+			c.add("$", DiffTypeChanged, string(ex.Response.Body)+ex.Response.BodyText, string(gotBody)+gotText)
+		}
+
+		report.Differences = append(report.Differences, c.diffs...)
+	}
+
+	return report, nil
+}
+
+// comparer walks two decoded JSON values collecting differences
+type comparer struct {
+	seq     int
+	request string
+	ignore  map[string]bool
+	diffs   []Difference
+}
+
+func (c *comparer) add(field string, kind DiffKind, recorded, replayed string) {
+	c.diffs = append(c.diffs, Difference{
+		Seq: c.seq, Request: c.request, Field: field, Kind: kind, Recorded: recorded, Replayed: replayed,
+	})
+}
+
+// compare records differences between want and have at path. With ignoreValue set
+// only the JSON type is compared.
+func (c *comparer) compare(path string, want, have any, ignoreValue bool) {
+	if jsonType(want) != jsonType(have) {
+		c.add(path, DiffTypeChanged, jsonType(want), jsonType(have))
+		return
+	}
+
+	switch w := want.(type) {
+	case map[string]any:
+		h := have.(map[string]any)
+		for _, k := range sortedKeys(w) {
+			hv, ok := h[k]
+			if !ok {
+				c.add(path+"."+k, DiffMissingField, compact(w[k]), "")
+				continue
+			}
+			c.compare(path+"."+k, w[k], hv, c.ignore[k])
+		}
+		for _, k := range sortedKeys(h) {
+			if _, ok := w[k]; !ok {
+				c.add(path+"."+k, DiffNewField, "", compact(h[k]))
+			}
+		}
+	case []any:
+		h := have.([]any)
+		if len(w) != len(h) && !ignoreValue {
+			c.add(path, DiffValueChanged, fmt.Sprintf("%d items", len(w)), fmt.Sprintf("%d items", len(h)))
+		}
+		for i := 0; i < len(w) && i < len(h); i++ {
+			c.compare(fmt.Sprintf("%s[%d]", path, i), w[i], h[i], ignoreValue)
+		}
+	default:
+		if !ignoreValue && compact(want) != compact(have) {
+			c.add(path, DiffValueChanged, compact(want), compact(have))
+		}
+	}
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// compact renders a decoded JSON value on one line
+func compact(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitBody returns body as JSON when it is valid JSON, otherwise as text
+func splitBody(body []byte) (json.RawMessage, string) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, ""
+	}
+	if json.Valid(trimmed) {
+		var buf bytes.Buffer
+		json.Compact(&buf, trimmed)
+		return buf.Bytes(), ""
+	}
+	return nil, string(body)
+}
+
+// slug turns a request path into a file-name fragment
+func slug(path string) string {
+	path = strings.Trim(path, "/")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, path)
+}
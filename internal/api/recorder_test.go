@@ -0,0 +1,95 @@
+// internal/api/recorder_test.go
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"wallet-app/internal/wallet"
+)
+
+// recordSession drives a short scripted session through h
+func recordSession(h http.Handler) {
+	do(h, "POST", "/users", `{"id":"alice","name":"Alice","email":"a@example.com"}`)
+	do(h, "POST", "/users", `{"id":"bob","name":"Bob","email":"b@example.com"}`)
+	do(h, "POST", "/users/alice/deposits", `{"amount":"50","description":"seed"}`)
+	do(h, "POST", "/transfers", `{"from":"alice","to":"bob","amount":"20"}`)
+	do(h, "GET", "/users/alice/transactions", "")
+}
+
+func TestRecordAndReplay_NoChanges(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(NewServer(wallet.NewWalletService()), dir)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	recordSession(rec)
+	if err := rec.Err(); err != nil {
+		t.Fatalf("recording error = %v", err)
+	}
+
+	exchanges, err := LoadExchanges(dir)
+	if err != nil || len(exchanges) != 5 {
+		t.Fatalf("LoadExchanges() = %d exchanges, %v; want 5", len(exchanges), err)
+	}
+
+	report, err := Replay(NewServer(wallet.NewWalletService()), dir, ReplayOptions{IgnoreFields: []string{"id", "timestamp"}})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if report.Exchanges != 5 || len(report.Differences) != 0 {
+		t.Errorf("report = %+v, want 5 exchanges and no differences", report)
+	}
+}
+
+func TestReplay_DetectsBreakingChanges(t *testing.T) {
+	dir := t.TempDir()
+	rec, _ := NewRecorder(NewServer(wallet.NewWalletService()), dir)
+	recordSession(rec)
+
+	// A "new version" that renames balance and rejects transfers
+	next := NewServer(wallet.NewWalletService())
+	changed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/transfers" {
+			writeJSON(w, http.StatusForbidden, errorResponse{Error: "disabled"})
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/deposits") {
+			next.ServeHTTP(httptestDiscard{}, r)
+			writeJSON(w, http.StatusCreated, map[string]any{"user_id": "alice", "available": "50"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	report, err := Replay(changed, dir, ReplayOptions{IgnoreFields: []string{"id", "timestamp"}})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	kinds := map[DiffKind]int{}
+	for _, d := range report.Breaking() {
+		kinds[d.Kind]++
+	}
+	if kinds[DiffStatus] != 1 || kinds[DiffMissingField] < 1 {
+		t.Errorf("breaking differences = %+v", report.Breaking())
+	}
+
+	var newField bool
+	for _, d := range report.Differences {
+		if d.Kind == DiffNewField && d.Field == "$.available" {
+			newField = true
+		}
+	}
+	if !newField {
+		t.Errorf("differences = %+v, want new field $.available", report.Differences)
+	}
+}
+
+// httptestDiscard is a ResponseWriter that drops everything
+type httptestDiscard struct{}
+
+func (httptestDiscard) Header() http.Header         { return http.Header{} }
+func (httptestDiscard) Write(b []byte) (int, error) { return len(b), nil }
+func (httptestDiscard) WriteHeader(int)             {}
@@ -0,0 +1,1033 @@
+// internal/api/server.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// Server exposes a WalletService over HTTP with JSON payloads. Amounts travel as
+// decimal strings so no precision is lost on the wire. Each response amount has a
+// _display twin rendered per the tenant's display policy: clients show that one and
+// compute with the exact one.
+type Server struct {
+	ws      *wallet.WalletService
+	mux     *http.ServeMux
+	handler http.Handler
+}
+
+// NewServer creates an HTTP handler for ws. Every endpoint is served through
+// middleware, the first running outermost, e.g.
+//
+//	api.NewServer(ws, api.Trace(), api.Logging(logger), api.Metrics(m), api.Authenticate(auth), api.RateLimit(limiter, nil))
+//
+// Behind AuthenticateCaller, every request is checked against DefaultPolicy, or the
+// Policy installed by Authorize.
+func NewServer(ws *wallet.WalletService, middleware ...Middleware) *Server {
+	s := &Server{ws: ws, mux: http.NewServeMux()}
+	s.handler = Chain(http.HandlerFunc(s.route), middleware...)
+
+	s.mux.HandleFunc("POST /users", s.createUser)
+	s.mux.HandleFunc("GET /users/{id}/balance", s.forWallet(ActionReadWallet, s.getBalance))
+	s.mux.HandleFunc("GET /users/{id}/transactions", s.forWallet(ActionReadWallet, s.getTransactions))
+	s.mux.HandleFunc("GET /users/{id}/pending", s.forWallet(ActionReadWallet, s.getPendingItems))
+	s.mux.HandleFunc("GET /users/{id}/limits", s.forWallet(ActionReadWallet, s.getSpendingLimits))
+	s.mux.HandleFunc("PUT /users/{id}/limits", s.forWallet(ActionSetLimits, s.setSpendingLimits))
+	s.mux.HandleFunc("POST /users/{id}/closure", s.forWallet(ActionManageWallet, s.closeWallet))
+	s.mux.HandleFunc("GET /users/{id}/closure", s.forWallet(ActionReadWallet, s.getClosure))
+	s.mux.HandleFunc("GET /users/{id}/descriptor", s.forWallet(ActionReadWallet, s.getDescriptor))
+	s.mux.HandleFunc("PUT /users/{id}/descriptor", s.forWallet(ActionManageWallet, s.setDescriptor))
+	s.mux.HandleFunc("POST /users/{id}/deposits", s.forWallet(ActionDeposit, s.deposit))
+	s.mux.HandleFunc("POST /users/{id}/withdrawals", s.forWallet(ActionMoveFunds, s.withdraw))
+	s.mux.HandleFunc("POST /transfers", s.transfer)
+	s.mux.HandleFunc("POST /users/{id}/client-tx-ids", s.forWallet(ActionMoveFunds, s.reserveClientTxID))
+	s.mux.HandleFunc("GET /users/{id}/failures", s.forWallet(ActionReadWallet, s.getFailures))
+	s.mux.HandleFunc("POST /failures/{id}/retry", s.retryFailure)
+	s.mux.HandleFunc("POST /users/{id}/adjustments", s.forWallet(ActionAdjust, s.requestAdjustment))
+
+	// Admin actions on every wallet matching a segment filter
+	s.mux.HandleFunc("POST /segments/freeze", s.forService(ActionFreeze, s.freezeSegment))
+	s.mux.HandleFunc("POST /segments/unfreeze", s.forService(ActionFreeze, s.unfreezeSegment))
+
+	// The authenticated caller's own wallet, resolved by PrincipalWallet
+	s.mux.HandleFunc("GET /me/balance", s.me(s.getBalance))
+	s.mux.HandleFunc("GET /me/transactions", s.me(s.getTransactions))
+	s.mux.HandleFunc("GET /me/pending", s.me(s.getPendingItems))
+	s.mux.HandleFunc("GET /me/limits", s.me(s.getSpendingLimits))
+	s.mux.HandleFunc("GET /me/failures", s.me(s.getFailures))
+
+	s.mux.HandleFunc("GET /rates", s.getRates)
+	s.mux.HandleFunc("GET /audit", s.forService(ActionReadAudit, s.getAuditLog))
+
+	// Card processor callbacks
+	s.mux.HandleFunc("POST /cards/authorizations", s.forService(ActionProcessCards, s.authorizeCard))
+	s.mux.HandleFunc("POST /cards/authorizations/{id}/capture", s.forService(ActionProcessCards, s.captureAuthorization))
+	s.mux.HandleFunc("POST /cards/authorizations/{id}/release", s.forService(ActionProcessCards, s.releaseAuthorization))
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// route dispatches r to its endpoint and reports the matched pattern to the middleware
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		info.route = r.Pattern
+	}
+}
+
+// idempotencyHeader carries a client-chosen key on deposits, withdrawals and transfers.
+// A retry with the same key does not apply the operation again; the response reports
+// the current balance.
+const idempotencyHeader = "Idempotency-Key"
+
+// traceHeader carries a client-chosen request ID, recorded on the transactions the
+// request creates
+const traceHeader = "X-Request-Id"
+
+// sessionHeader carries a session token. Responses reporting a balance set it to a token
+// covering the request's writes; reads that send it back reflect at least those writes.
+const sessionHeader = "X-Session-Token"
+
+// tenantHeader names the tenant a request is served for when Tenant has no resolver
+const tenantHeader = "X-Tenant-Id"
+
+// createUserRequest is the body of POST /users
+type createUserRequest struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// moneyRequest is the body of deposit and withdrawal requests
+type moneyRequest struct {
+	Amount      decimal.Decimal `json:"amount"`
+	Description string          `json:"description"`
+}
+
+// transferRequest is the body of POST /transfers
+type transferRequest struct {
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Amount      decimal.Decimal `json:"amount"`
+	Description string          `json:"description"`
+	ClientTxID  string          `json:"client_tx_id"` // reserved beforehand; applies the transfer exactly once
+}
+
+// clientTxIDRequest is the body of POST /users/{id}/client-tx-ids
+type clientTxIDRequest struct {
+	ClientTxID string `json:"client_tx_id"`
+	TTLSeconds int64  `json:"ttl_seconds"` // default wallet.DefaultClientTxIDTTL
+}
+
+// clientTxIDResponse is the wire form of a client transaction ID reservation
+type clientTxIDResponse struct {
+	ClientTxID string `json:"client_tx_id"`
+	UserID     string `json:"user_id"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
+
+// cardAuthRequest is the body of POST /cards/authorizations
+type cardAuthRequest struct {
+	CardID       string          `json:"card_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Merchant     string          `json:"merchant"`
+	ProcessorRef string          `json:"processor_ref"`
+}
+
+// captureRequest is the body of a capture at clearing
+type captureRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// balanceResponse is returned by balance queries and money movements
+type balanceResponse struct {
+	UserID         string          `json:"user_id"`
+	Currency       string          `json:"currency"`
+	Balance        decimal.Decimal `json:"balance"`
+	BalanceDisplay string          `json:"balance_display"`
+}
+
+// transactionResponse is the wire form of a transaction
+type transactionResponse struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	From          string          `json:"from"`
+	To            string          `json:"to"`
+	Amount        decimal.Decimal `json:"amount"`
+	AmountDisplay string          `json:"amount_display"`
+	Currency      string          `json:"currency"`
+	Description   string          `json:"description"`
+	Timestamp     int64           `json:"timestamp"`
+	Counterparty  string          `json:"counterparty,omitempty"` // statement descriptor or ID of the other party
+}
+
+// descriptorRequest is the body of PUT /users/{id}/descriptor; empty clears it
+type descriptorRequest struct {
+	Descriptor string `json:"descriptor"`
+}
+
+// descriptorResponse is the wire form of a wallet's statement descriptor
+type descriptorResponse struct {
+	UserID     string                     `json:"user_id"`
+	Descriptor string                     `json:"descriptor"`
+	Changes    []descriptorChangeResponse `json:"changes"`
+}
+
+// descriptorChangeResponse is the wire form of a change to a statement descriptor
+type descriptorChangeResponse struct {
+	Descriptor string `json:"descriptor"`
+	Previous   string `json:"previous"`
+	ChangedBy  string `json:"changed_by"`
+	ChangedAt  int64  `json:"changed_at"`
+}
+
+// closureRequest is the body of POST /users/{id}/closure
+type closureRequest struct {
+	SweepTo       string `json:"sweep_to"`
+	DestinationID string `json:"destination_id"`
+}
+
+// closureResponse is the wire form of a wallet closure
+type closureResponse struct {
+	UserID     string   `json:"user_id"`
+	Step       string   `json:"step"`
+	Completed  []string `json:"completed"`
+	BlockedBy  []string `json:"blocked_by,omitempty"`
+	PayoutTxID string   `json:"payout_tx_id,omitempty"`
+	ClosedAt   int64    `json:"closed_at,omitempty"`
+}
+
+// pendingItemResponse is the wire form of a pending item
+type pendingItemResponse struct {
+	Kind          string          `json:"kind"`
+	ID            string          `json:"id"`
+	Amount        decimal.Decimal `json:"amount"`
+	AmountDisplay string          `json:"amount_display"`
+	Currency      string          `json:"currency"`
+	Incoming      bool            `json:"incoming"`
+	Counterparty  string          `json:"counterparty,omitempty"`
+	Description   string          `json:"description,omitempty"`
+	Since         int64           `json:"since,omitempty"`
+	Until         int64           `json:"until,omitempty"`
+}
+
+// failedOperationResponse is the wire form of a failed operation
+type failedOperationResponse struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Counterparty  string          `json:"counterparty,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	AmountDisplay string          `json:"amount_display"`
+	Description   string          `json:"description,omitempty"`
+	Reason        string          `json:"reason"`
+	Error         string          `json:"error"`
+	Retryable     bool            `json:"retryable"`
+	Attempts      int             `json:"attempts"`
+	FailedAt      int64           `json:"failed_at"`
+	RetriedAsTxID string          `json:"retried_as_tx_id,omitempty"`
+}
+
+// adjustmentRequest is the body of POST /users/{id}/adjustments; negative amounts debit
+type adjustmentRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+	Reason string          `json:"reason"`
+	Note   string          `json:"note"`
+}
+
+// adjustmentResponse is the wire form of an adjustment request
+type adjustmentResponse struct {
+	ID            string          `json:"id"`
+	UserID        string          `json:"user_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	AmountDisplay string          `json:"amount_display"`
+	Status        string          `json:"status"`
+	RequiredRole  string          `json:"required_role"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+}
+
+// segmentActionRequest is the body of POST /segments/freeze and /segments/unfreeze
+type segmentActionRequest struct {
+	Country   string   `json:"country"`
+	Tags      []string `json:"tags"`
+	RiskFlags []string `json:"risk_flags"`
+	Reason    string   `json:"reason"`
+	DryRun    bool     `json:"dry_run"`
+}
+
+// segmentActionResponse is the wire form of a segment action
+type segmentActionResponse struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"`
+	Matched  int      `json:"matched"`
+	Affected []string `json:"affected"`
+	Skipped  int      `json:"skipped"`
+	DryRun   bool     `json:"dry_run"`
+}
+
+// spendingLimitsRequest is the body of PUT /users/{id}/limits; omitted limits are unset
+type spendingLimitsRequest struct {
+	PerTransaction decimal.Decimal `json:"per_transaction"`
+	Daily          decimal.Decimal `json:"daily"`
+	Monthly        decimal.Decimal `json:"monthly"`
+}
+
+// spendingLimitsResponse is the wire form of a wallet's limits and their use
+type spendingLimitsResponse struct {
+	UserID                string          `json:"user_id"`
+	Currency              string          `json:"currency"`
+	PerTransaction        decimal.Decimal `json:"per_transaction"`
+	Daily                 decimal.Decimal `json:"daily"`
+	Monthly               decimal.Decimal `json:"monthly"`
+	DailyUsed             decimal.Decimal `json:"daily_used"`
+	MonthlyUsed           decimal.Decimal `json:"monthly_used"`
+	PerTransactionDisplay string          `json:"per_transaction_display"`
+	DailyDisplay          string          `json:"daily_display"`
+	MonthlyDisplay        string          `json:"monthly_display"`
+	DailyUsedDisplay      string          `json:"daily_used_display"`
+	MonthlyUsedDisplay    string          `json:"monthly_used_display"`
+}
+
+// cardAuthResponse is the wire form of a card authorization
+type cardAuthResponse struct {
+	ID              string          `json:"id"`
+	CardID          string          `json:"card_id"`
+	Status          string          `json:"status"`
+	Approved        bool            `json:"approved"`
+	DeclineReason   string          `json:"decline_reason,omitempty"`
+	Currency        string          `json:"currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	AmountDisplay   string          `json:"amount_display"`
+	Captured        decimal.Decimal `json:"captured"`
+	CapturedDisplay string          `json:"captured_display"`
+	ExpiresAt       int64           `json:"expires_at,omitempty"`
+}
+
+// rateResponse is the wire form of a stored exchange rate
+type rateResponse struct {
+	ID         string          `json:"id"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Rate       decimal.Decimal `json:"rate"`
+	Source     string          `json:"source"`
+	ObservedAt int64           `json:"observed_at"`
+}
+
+// auditEntryResponse is the wire form of an audit log entry
+type auditEntryResponse struct {
+	ID            string   `json:"id"`
+	At            int64    `json:"at"`
+	ActorID       string   `json:"actor_id"`
+	IP            string   `json:"ip,omitempty"`
+	UserAgent     string   `json:"user_agent,omitempty"`
+	RequestID     string   `json:"request_id,omitempty"`
+	Operation     string   `json:"operation"`
+	UserIDs       []string `json:"user_ids"`
+	TransactionID string   `json:"transaction_id,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// errorResponse is the body of every non-2xx response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if !s.permit(w, r, ActionCreateUser, req.ID) {
+		return
+	}
+	if err := s.ws.CreateUserContext(requestContext(r), req.ID, req.Name, req.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+func (s *Server) getBalance(w http.ResponseWriter, r *http.Request) {
+	s.writeBalance(w, r, http.StatusOK, r.PathValue("id"))
+}
+
+func (s *Server) getTransactions(w http.ResponseWriter, r *http.Request) {
+	history, err := s.ws.GetTransactionHistoryContext(requestContext(r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	out := make([]transactionResponse, 0, len(history))
+	for _, tx := range history {
+		resp := s.toTransactionResponse(r, tx)
+		resp.Counterparty = s.ws.CounterpartyLabel(r.PathValue("id"), tx)
+		out = append(out, resp)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getPendingItems(w http.ResponseWriter, r *http.Request) {
+	items, err := s.ws.GetPendingItems(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	out := make([]pendingItemResponse, 0, len(items))
+	for _, item := range items {
+		out = append(out, pendingItemResponse{
+			Kind:          string(item.Kind),
+			ID:            item.ID,
+			Amount:        item.Amount,
+			AmountDisplay: s.display(r, item.Amount, item.Currency),
+			Currency:      item.Currency,
+			Incoming:      item.Incoming,
+			Counterparty:  item.CounterpartyID,
+			Description:   item.Description,
+			Since:         item.Since,
+			Until:         item.Until,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	s.writeSpendingLimits(w, r, http.StatusOK, r.PathValue("id"))
+}
+
+func (s *Server) setSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	var req spendingLimitsRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	userID := r.PathValue("id")
+	limits := wallet.SpendingLimits{PerTransaction: req.PerTransaction, Daily: req.Daily, Monthly: req.Monthly}
+	if err := s.ws.SetSpendingLimits(userID, limits); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeSpendingLimits(w, r, http.StatusOK, userID)
+}
+
+// writeSpendingLimits responds with userID's limits and their use
+func (s *Server) writeSpendingLimits(w http.ResponseWriter, r *http.Request, status int, userID string) {
+	usage, err := s.ws.GetSpendingUsage(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, spendingLimitsResponse{
+		UserID:                usage.UserID,
+		Currency:              usage.Currency,
+		PerTransaction:        usage.Limits.PerTransaction,
+		Daily:                 usage.Limits.Daily,
+		Monthly:               usage.Limits.Monthly,
+		DailyUsed:             usage.Daily,
+		MonthlyUsed:           usage.Monthly,
+		PerTransactionDisplay: s.display(r, usage.Limits.PerTransaction, usage.Currency),
+		DailyDisplay:          s.display(r, usage.Limits.Daily, usage.Currency),
+		MonthlyDisplay:        s.display(r, usage.Limits.Monthly, usage.Currency),
+		DailyUsedDisplay:      s.display(r, usage.Daily, usage.Currency),
+		MonthlyUsedDisplay:    s.display(r, usage.Monthly, usage.Currency),
+	})
+}
+
+// closeWallet starts or resumes a closure. A closure stopped by unsettled items is
+// reported with 202 and the items blocking it.
+func (s *Server) closeWallet(w http.ResponseWriter, r *http.Request) {
+	var req closureRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	closure, err := s.ws.CloseWalletContext(requestContext(r), r.PathValue("id"), wallet.ClosureRequest{
+		SweepToUserID: req.SweepTo,
+		DestinationID: req.DestinationID,
+	})
+	switch {
+	case errors.Is(err, wallet.ErrClosureBlocked):
+		writeJSON(w, http.StatusAccepted, toClosureResponse(closure))
+	case err != nil:
+		writeError(w, err)
+	default:
+		writeJSON(w, http.StatusOK, toClosureResponse(closure))
+	}
+}
+
+func (s *Server) getClosure(w http.ResponseWriter, r *http.Request) {
+	closure, err := s.ws.GetClosureStatus(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toClosureResponse(closure))
+}
+
+func (s *Server) getDescriptor(w http.ResponseWriter, r *http.Request) {
+	s.writeDescriptor(w, r.PathValue("id"))
+}
+
+// setDescriptor sets the name the wallet's counterparties see for it, recording the
+// caller as the one who changed it
+func (s *Server) setDescriptor(w http.ResponseWriter, r *http.Request) {
+	var req descriptorRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	userID := r.PathValue("id")
+	if err := s.ws.SetStatementDescriptor(userID, req.Descriptor, PrincipalFromContext(r.Context())); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeDescriptor(w, userID)
+}
+
+// writeDescriptor responds with a wallet's statement descriptor and its changes
+func (s *Server) writeDescriptor(w http.ResponseWriter, userID string) {
+	changes, err := s.ws.StatementDescriptorHistory(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := descriptorResponse{UserID: userID, Descriptor: s.ws.StatementDescriptor(userID), Changes: make([]descriptorChangeResponse, 0, len(changes))}
+	for _, c := range changes {
+		resp.Changes = append(resp.Changes, descriptorChangeResponse{Descriptor: c.Descriptor, Previous: c.Previous, ChangedBy: c.ChangedBy, ChangedAt: c.ChangedAt})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) deposit(w http.ResponseWriter, r *http.Request) {
+	var req moneyRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	userID := r.PathValue("id")
+	var err error
+	if key := r.Header.Get(idempotencyHeader); key != "" {
+		_, err = s.ws.DepositIdempotentContext(requestContext(r), key, userID, req.Amount, req.Description)
+	} else {
+		err = s.ws.DepositContext(requestContext(r), userID, req.Amount, req.Description)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeBalance(w, r, http.StatusCreated, userID)
+}
+
+func (s *Server) withdraw(w http.ResponseWriter, r *http.Request) {
+	var req moneyRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	userID := r.PathValue("id")
+	var err error
+	if key := r.Header.Get(idempotencyHeader); key != "" {
+		_, err = s.ws.WithdrawIdempotentContext(requestContext(r), key, userID, req.Amount, req.Description)
+	} else {
+		err = s.ws.WithdrawContext(requestContext(r), userID, req.Amount, req.Description)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeBalance(w, r, http.StatusCreated, userID)
+}
+
+func (s *Server) transfer(w http.ResponseWriter, r *http.Request) {
+	var req transferRequest
+	if !decode(w, r, &req) || !s.permit(w, r, ActionMoveFunds, req.From) {
+		return
+	}
+	ctx := requestContext(r)
+	var err error
+	switch key := r.Header.Get(idempotencyHeader); {
+	case req.ClientTxID != "":
+		_, err = s.ws.TransferClientTxIDContext(ctx, req.ClientTxID, req.From, req.To, req.Amount, req.Description)
+	case key != "":
+		_, err = s.ws.TransferIdempotentContext(ctx, key, req.From, req.To, req.Amount, req.Description)
+	default:
+		err = s.ws.TransferContext(ctx, req.From, req.To, req.Amount, req.Description)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeBalance(w, r, http.StatusCreated, req.From)
+}
+
+// reserveClientTxID sets aside a client-generated ID for the wallet's next transfer
+func (s *Server) reserveClientTxID(w http.ResponseWriter, r *http.Request) {
+	var req clientTxIDRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	res, err := s.ws.ReserveClientTxID(r.PathValue("id"), req.ClientTxID, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, clientTxIDResponse{ClientTxID: res.ID, UserID: res.UserID, ExpiresAt: res.ExpiresAt})
+}
+
+// getFailures lists a wallet's failed operations, newest first, optionally only those
+// with ?reason=
+func (s *Server) getFailures(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	failures, err := s.ws.ListFailedOperations(userID, wallet.FailureReason(r.URL.Query().Get("reason")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	currency := s.baseCurrency(userID)
+	out := make([]failedOperationResponse, 0, len(failures))
+	for _, f := range failures {
+		out = append(out, failedOperationResponse{
+			ID:            f.ID,
+			Type:          string(f.Type),
+			Counterparty:  f.CounterpartyID,
+			Amount:        f.Amount,
+			AmountDisplay: s.display(r, f.Amount, currency),
+			Description:   f.Description,
+			Reason:        string(f.Reason),
+			Error:         f.Error,
+			Retryable:     f.Retryable,
+			Attempts:      f.Attempts,
+			FailedAt:      f.FailedAt,
+			RetriedAsTxID: f.TransactionID,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// retryFailure runs a failed operation again, responding with the transaction it created
+func (s *Server) retryFailure(w http.ResponseWriter, r *http.Request) {
+	failure, err := s.ws.GetFailedOperation(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// Retrying a deposit credits the wallet from outside, which only staff may do
+	action := ActionMoveFunds
+	if failure.Type == wallet.TransactionDeposit {
+		action = ActionDeposit
+	}
+	if !s.permit(w, r, action, failure.UserID) {
+		return
+	}
+	tx, err := s.ws.RetryOperationContext(requestContext(r), failure.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.toTransactionResponse(r, tx))
+}
+
+// requestAdjustment has the caller, as the maker, correct a wallet's balance. The
+// caller must be registered staff; adjustments above its level wait for a checker.
+func (s *Server) requestAdjustment(w http.ResponseWriter, r *http.Request) {
+	var req adjustmentRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	userID := r.PathValue("id")
+	adj, err := s.ws.RequestAdjustmentContext(requestContext(r), PrincipalFromContext(r.Context()), userID, req.Amount, wallet.AdjustmentReason(req.Reason), req.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if adj.Status == wallet.AdjustmentPending {
+		status = http.StatusAccepted
+	}
+	writeJSON(w, status, adjustmentResponse{
+		ID:            adj.ID,
+		UserID:        adj.UserID,
+		Amount:        adj.Amount,
+		AmountDisplay: s.display(r, adj.Amount, s.baseCurrency(userID)),
+		Status:        string(adj.Status),
+		RequiredRole:  string(adj.RequiredRole),
+		TransactionID: adj.TransactionID,
+	})
+}
+
+func (s *Server) freezeSegment(w http.ResponseWriter, r *http.Request) {
+	s.runSegmentAction(w, r, s.ws.FreezeSegmentContext)
+}
+
+func (s *Server) unfreezeSegment(w http.ResponseWriter, r *http.Request) {
+	s.runSegmentAction(w, r, s.ws.UnfreezeSegmentContext)
+}
+
+// runSegmentAction runs a segment action on behalf of the caller, who is recorded as
+// its actor
+func (s *Server) runSegmentAction(w http.ResponseWriter, r *http.Request, run func(context.Context, wallet.SegmentActionRequest) (*wallet.SegmentAction, error)) {
+	var req segmentActionRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	action, err := run(requestContext(r), wallet.SegmentActionRequest{
+		Filter: wallet.SegmentFilter{Country: req.Country, Tags: req.Tags, RiskFlags: req.RiskFlags},
+		Actor:  PrincipalFromContext(r.Context()),
+		Reason: req.Reason,
+		DryRun: req.DryRun,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	affected := action.Affected
+	if affected == nil {
+		affected = []string{}
+	}
+	writeJSON(w, http.StatusOK, segmentActionResponse{
+		ID:       action.ID,
+		Kind:     string(action.Kind),
+		Matched:  action.Matched,
+		Affected: affected,
+		Skipped:  action.Skipped,
+		DryRun:   action.DryRun,
+	})
+}
+
+// getRates lists historical rates for ?from=&to=, optionally bounded by Unix-second
+// ?since= and ?until=
+func (s *Server) getRates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var bounds [2]time.Time
+	for i, name := range []string{"since", "until"} {
+		if v := q.Get(name); v != "" {
+			secs, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid " + name + ": " + v})
+				return
+			}
+			bounds[i] = time.Unix(secs, 0)
+		}
+	}
+
+	history := s.ws.RateHistory(q.Get("from"), q.Get("to"), bounds[0], bounds[1])
+	out := make([]rateResponse, 0, len(history))
+	for _, rec := range history {
+		out = append(out, rateResponse{
+			ID:         rec.ID,
+			From:       rec.From,
+			To:         rec.To,
+			Rate:       rec.Rate,
+			Source:     rec.Source,
+			ObservedAt: rec.ObservedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// getAuditLog lists audit entries, oldest first, optionally only those of ?actor=,
+// bounded by Unix-second ?since= and ?until= and capped at ?limit=
+func (s *Server) getAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := wallet.AuditQuery{ActorID: q.Get("actor")}
+	bounds := []*int64{&query.Since, &query.Until}
+	for i, name := range []string{"since", "until"} {
+		if v := q.Get(name); v != "" {
+			secs, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid " + name + ": " + v})
+				return
+			}
+			*bounds[i] = secs
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid limit: " + v})
+			return
+		}
+		query.Limit = n
+	}
+
+	entries, err := s.ws.QueryAuditLog(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]auditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, auditEntryResponse{
+			ID:            e.ID,
+			At:            e.At,
+			ActorID:       e.Actor.ID,
+			IP:            e.Actor.IP,
+			UserAgent:     e.Actor.UserAgent,
+			RequestID:     e.Actor.RequestID,
+			Operation:     e.Operation,
+			UserIDs:       e.UserIDs,
+			TransactionID: e.TransactionID,
+			Error:         e.Error,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) authorizeCard(w http.ResponseWriter, r *http.Request) {
+	var req cardAuthRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	auth, err := s.ws.AuthorizeCardContext(requestContext(r), wallet.CardAuthRequest{
+		CardID:       req.CardID,
+		Amount:       req.Amount,
+		Merchant:     req.Merchant,
+		ProcessorRef: req.ProcessorRef,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// A decline is a decision, not a failure: processors read it from the body
+	writeJSON(w, http.StatusCreated, s.toCardAuthResponse(r, auth))
+}
+
+func (s *Server) captureAuthorization(w http.ResponseWriter, r *http.Request) {
+	var req captureRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	auth, err := s.ws.CaptureAuthorizationContext(requestContext(r), r.PathValue("id"), req.Amount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.toCardAuthResponse(r, auth))
+}
+
+func (s *Server) releaseAuthorization(w http.ResponseWriter, r *http.Request) {
+	auth, err := s.ws.ReleaseAuthorizationContext(requestContext(r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.toCardAuthResponse(r, auth))
+}
+
+// me serves handler for the caller's own wallet, as if its user ID were in the path.
+// Callers without a linked wallet get 401 when unauthenticated and 404 otherwise.
+func (s *Server) me(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := WalletUserFromContext(r.Context())
+		switch {
+		case userID != "":
+		case PrincipalFromContext(r.Context()) == "":
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthenticated: no caller to resolve"})
+			return
+		default:
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "no wallet linked to the caller"})
+			return
+		}
+		r.SetPathValue("id", userID)
+		handler(w, r)
+	}
+}
+
+// requestContext returns r's context carrying the actor making the request and its
+// trace ID and session token, if it sent them
+func requestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if id := r.Header.Get(traceHeader); id != "" {
+		ctx = wallet.ContextWithTraceID(ctx, id)
+	}
+	if token := r.Header.Get(sessionHeader); token != "" {
+		ctx = wallet.ContextWithSessionToken(ctx, wallet.SessionToken(token))
+	}
+	return wallet.ContextWithActor(ctx, wallet.Actor{
+		ID:        PrincipalFromContext(ctx),
+		IP:        clientAddr(r),
+		UserAgent: r.UserAgent(),
+		RequestID: r.Header.Get(traceHeader),
+	})
+}
+
+// writeBalance responds with a user's current balance and a session token covering it
+func (s *Server) writeBalance(w http.ResponseWriter, r *http.Request, status int, userID string) {
+	token := s.ws.SessionToken()
+	balance, err := s.ws.GetBalanceContext(requestContext(r), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	currency := s.baseCurrency(userID)
+	w.Header().Set(sessionHeader, string(token))
+	writeJSON(w, status, balanceResponse{
+		UserID:         userID,
+		Currency:       currency,
+		Balance:        balance,
+		BalanceDisplay: s.display(r, balance, currency),
+	})
+}
+
+// display renders amount in currency per the display policy of the request's tenant
+func (s *Server) display(r *http.Request, amount decimal.Decimal, currency string) string {
+	return s.ws.DisplayAmount(wallet.TenantFromContext(r.Context()), amount, currency)
+}
+
+// baseCurrency returns the base currency of userID's wallet, or the service default
+// when it has none
+func (s *Server) baseCurrency(userID string) string {
+	snap, err := s.ws.GetWallet(userID)
+	if err != nil {
+		return wallet.DefaultCurrency
+	}
+	return snap.Currency
+}
+
+// toTransactionResponse converts a transaction to its wire form
+func (s *Server) toTransactionResponse(r *http.Request, tx *wallet.Transaction) transactionResponse {
+	return transactionResponse{
+		ID:            tx.ID,
+		Type:          string(tx.Type),
+		From:          tx.FromUserID,
+		To:            tx.ToUserID,
+		Amount:        tx.Amount,
+		AmountDisplay: s.display(r, tx.Amount, tx.Currency),
+		Currency:      tx.Currency,
+		Description:   tx.Description,
+		Timestamp:     tx.Timestamp,
+	}
+}
+
+// toClosureResponse converts a closure to its wire form
+func toClosureResponse(c *wallet.WalletClosure) closureResponse {
+	resp := closureResponse{
+		UserID:     c.UserID,
+		Step:       string(c.Step),
+		Completed:  make([]string, 0, len(c.Completed)),
+		BlockedBy:  c.BlockedBy,
+		PayoutTxID: c.PayoutTxID,
+		ClosedAt:   c.ClosedAt,
+	}
+	for _, r := range c.Completed {
+		resp.Completed = append(resp.Completed, string(r.Step))
+	}
+	return resp
+}
+
+// toCardAuthResponse converts a card authorization to its wire form
+func (s *Server) toCardAuthResponse(r *http.Request, auth *wallet.CardAuthorization) cardAuthResponse {
+	currency := s.baseCurrency(auth.UserID)
+	resp := cardAuthResponse{
+		ID:              auth.ID,
+		CardID:          auth.CardID,
+		Status:          string(auth.Status),
+		Approved:        auth.Status != wallet.CardAuthDeclined,
+		DeclineReason:   auth.DeclineReason,
+		Currency:        currency,
+		Amount:          auth.Amount,
+		AmountDisplay:   s.display(r, auth.Amount, currency),
+		Captured:        auth.Captured,
+		CapturedDisplay: s.display(r, auth.Captured, currency),
+	}
+	if resp.Approved {
+		resp.ExpiresAt = auth.ExpiresAt
+	}
+	return resp
+}
+
+// errorStatus maps service errors to HTTP status codes
+var errorStatus = []struct {
+	err    error
+	status int
+}{
+	{wallet.ErrUserNotFound, http.StatusNotFound},
+	{wallet.ErrUserAlreadyExists, http.StatusConflict},
+	{wallet.ErrEmailTaken, http.StatusConflict},
+	{wallet.ErrReservedUserID, http.StatusBadRequest},
+	{wallet.ErrInsufficientBalance, http.StatusUnprocessableEntity},
+	{wallet.ErrBelowMinimumBalance, http.StatusUnprocessableEntity},
+	{wallet.ErrAboveMaximumBalance, http.StatusUnprocessableEntity},
+	{wallet.ErrLimitExceeded, http.StatusUnprocessableEntity},
+	{wallet.ErrInvalidSpendingLimit, http.StatusBadRequest},
+	{wallet.ErrBelowFloor, http.StatusUnprocessableEntity},
+	{wallet.ErrInvalidAmount, http.StatusBadRequest},
+	{wallet.ErrSameUserTransfer, http.StatusBadRequest},
+	{wallet.ErrInvalidCurrency, http.StatusBadRequest},
+	{wallet.ErrCurrencyMismatch, http.StatusBadRequest},
+	{wallet.ErrPrecisionExceeded, http.StatusBadRequest},
+	{wallet.ErrCounterpartyBlocked, http.StatusForbidden},
+	{wallet.ErrDestinationRequired, http.StatusForbidden},
+	{wallet.ErrOperationRejected, http.StatusForbidden},
+	{wallet.ErrTransferHeld, http.StatusAccepted},
+	{wallet.ErrCardNotFound, http.StatusNotFound},
+	{wallet.ErrAuthorizationNotFound, http.StatusNotFound},
+	{wallet.ErrAuthorizationClosed, http.StatusConflict},
+	{wallet.ErrCaptureExceedsAuth, http.StatusUnprocessableEntity},
+	{wallet.ErrWalletFrozen, http.StatusConflict},
+	{wallet.ErrWalletAdminFrozen, http.StatusConflict},
+	{wallet.ErrWalletClosed, http.StatusConflict},
+	{wallet.ErrStepUpRequired, http.StatusForbidden},
+	{wallet.ErrRestrictedLimit, http.StatusUnprocessableEntity},
+	{wallet.ErrConsentRequired, http.StatusForbidden},
+	{wallet.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity},
+	{wallet.ErrNotStaff, http.StatusForbidden},
+	{wallet.ErrAdminRequired, http.StatusForbidden},
+	{wallet.ErrInvalidReason, http.StatusBadRequest},
+	{wallet.ErrAdjustmentAboveLimits, http.StatusUnprocessableEntity},
+	{wallet.ErrEmptySegment, http.StatusBadRequest},
+	{wallet.ErrSegmentActionReason, http.StatusBadRequest},
+	{wallet.ErrInvalidClientTxID, http.StatusBadRequest},
+	{wallet.ErrClientTxIDTaken, http.StatusConflict},
+	{wallet.ErrClientTxIDNotReserved, http.StatusUnprocessableEntity},
+	{wallet.ErrClientTxIDExpired, http.StatusUnprocessableEntity},
+	{wallet.ErrClientTxIDInFlight, http.StatusConflict},
+	{wallet.ErrClientTxIDUsed, http.StatusConflict},
+	{wallet.ErrInvalidDescriptor, http.StatusBadRequest},
+	{wallet.ErrFailureNotFound, http.StatusNotFound},
+	{wallet.ErrFailureNotRetryable, http.StatusConflict},
+	{wallet.ErrFailureRetried, http.StatusConflict},
+	{wallet.ErrClosureNotFound, http.StatusNotFound},
+	{wallet.ErrClosureDestination, http.StatusBadRequest},
+	{wallet.ErrInvalidSessionToken, http.StatusBadRequest},
+	{wallet.ErrSessionBehind, http.StatusServiceUnavailable},
+}
+
+// writeError responds with the status mapped from err
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	for _, m := range errorStatus {
+		if errors.Is(err, m.err) {
+			status = m.status
+			break
+		}
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// decode parses a JSON request body, responding with 400 on failure
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,496 @@
+// internal/api/server_test.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/wallet"
+)
+
+// do sends a request to h and returns the recorded response
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	srv := NewServer(wallet.NewWalletService())
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"create alice", "POST", "/users", `{"id":"alice","name":"Alice","email":"a@example.com"}`, http.StatusCreated, `"id":"alice"`},
+		{"create bob", "POST", "/users", `{"id":"bob","name":"Bob","email":"b@example.com"}`, http.StatusCreated, `"id":"bob"`},
+		{"duplicate user", "POST", "/users", `{"id":"bob","name":"Bob","email":"b@example.com"}`, http.StatusConflict, "already exists"},
+		{"deposit", "POST", "/users/alice/deposits", `{"amount":"100.10","description":"seed"}`, http.StatusCreated, `"balance":"100.1"`},
+		{"numeric amount", "POST", "/users/alice/deposits", `{"amount":0.2}`, http.StatusCreated, `"balance":"100.3"`},
+		{"invalid amount", "POST", "/users/alice/deposits", `{"amount":"-1"}`, http.StatusBadRequest, "invalid amount"},
+		{"unknown field", "POST", "/users/alice/deposits", `{"amt":"1"}`, http.StatusBadRequest, "invalid request body"},
+		{"transfer", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"40.3"}`, http.StatusCreated, `"balance":"60"`},
+		{"insufficient", "POST", "/users/bob/withdrawals", `{"amount":"41"}`, http.StatusUnprocessableEntity, "insufficient"},
+		{"withdraw", "POST", "/users/bob/withdrawals", `{"amount":"0.3"}`, http.StatusCreated, `"balance":"40"`},
+		{"balance", "GET", "/users/bob/balance", "", http.StatusOK, `"balance":"40"`},
+		{"unknown user", "GET", "/users/ghost/balance", "", http.StatusNotFound, "user not found"},
+		{"no pending items", "GET", "/users/bob/pending", "", http.StatusOK, "[]"},
+		{"pending of unknown user", "GET", "/users/ghost/pending", "", http.StatusNotFound, "user not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+
+	rec := do(srv, "GET", "/users/alice/transactions", "")
+	var history []transactionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("decoding history: %v", err)
+	}
+	if len(history) != 3 || history[2].Type != "transfer" || history[2].Amount.String() != "40.3" {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestServer_CardAuthorizations(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 100, "seed")
+	card, _ := ws.IssueCard("alice", "", wallet.CardLimits{}, time.Hour)
+	srv := NewServer(ws)
+
+	rec := do(srv, "POST", "/cards/authorizations", `{"card_id":"`+card.ID+`","amount":"30","merchant":"grocer","processor_ref":"p1"}`)
+	var auth cardAuthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &auth); err != nil || rec.Code != http.StatusCreated || !auth.Approved {
+		t.Fatalf("authorize = %d %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"declined", "/cards/authorizations", `{"card_id":"` + card.ID + `","amount":"80","merchant":"grocer"}`, http.StatusCreated, `"decline_reason":"insufficient_funds"`},
+		{"unknown card", "/cards/authorizations", `{"card_id":"nope","amount":"1"}`, http.StatusNotFound, "card not found"},
+		{"over capture", "/cards/authorizations/" + auth.ID + "/capture", `{"amount":"31"}`, http.StatusUnprocessableEntity, "exceeds"},
+		{"capture", "/cards/authorizations/" + auth.ID + "/capture", `{"amount":"25"}`, http.StatusOK, `"status":"captured"`},
+		{"release after capture", "/cards/authorizations/" + auth.ID + "/release", "", http.StatusConflict, "no longer open"},
+		{"unknown authorization", "/cards/authorizations/nope/release", "", http.StatusNotFound, "authorization not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, "POST", tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+
+	if b, _ := ws.GetBalanceDecimal("alice"); b.String() != "75" {
+		t.Errorf("balance = %s, want 75", b)
+	}
+}
+
+func TestServer_WalletClosure(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+	srv := NewServer(ws)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"no closure yet", "GET", "/users/alice/closure", "", http.StatusNotFound, "no closure"},
+		{"missing destination", "POST", "/users/alice/closure", `{}`, http.StatusBadRequest, "sweep user"},
+		{"close", "POST", "/users/alice/closure", `{"sweep_to":"bob"}`, http.StatusOK, `"step":"closed"`},
+		{"status", "GET", "/users/alice/closure", "", http.StatusOK, `"completed":["frozen","pending_cancelled","paid_out","statement_generated","closed"]`},
+		{"deposit to closed wallet", "POST", "/users/alice/deposits", `{"amount":"1"}`, http.StatusConflict, "wallet is closed"},
+		{"swept balance", "GET", "/users/bob/balance", "", http.StatusOK, `"balance":"100"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_SpendingLimits(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+	srv := NewServer(ws)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"no limits", "GET", "/users/alice/limits", "", http.StatusOK, `"daily":"0"`},
+		{"negative limit", "PUT", "/users/alice/limits", `{"daily":"-1"}`, http.StatusBadRequest, "must not be negative"},
+		{"set limits", "PUT", "/users/alice/limits", `{"per_transaction":"30","daily":"50"}`, http.StatusOK, `"per_transaction":"30","daily":"50","monthly":"0"`},
+		{"within limits", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"30"}`, http.StatusCreated, `"balance":"70"`},
+		{"over daily limit", "POST", "/users/alice/withdrawals", `{"amount":"25"}`, http.StatusUnprocessableEntity, "50 per day, 30 used"},
+		{"usage", "GET", "/users/alice/limits", "", http.StatusOK, `"daily_used":"30","monthly_used":"30"`},
+		{"unknown user", "GET", "/users/ghost/limits", "", http.StatusNotFound, "user not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_FailedOperations(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 10, "seed")
+	srv := NewServer(ws)
+
+	if rec := do(srv, "POST", "/users/alice/withdrawals", `{"amount":"25","description":"rent"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("withdrawal status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	failures, _ := ws.ListFailedOperations("alice", "")
+	if len(failures) != 1 {
+		t.Fatalf("logged %+v, want one failure", failures)
+	}
+	retry := "/failures/" + failures[0].ID + "/retry"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"listed", "GET", "/users/alice/failures", http.StatusOK, `"type":"withdraw","amount":"25","amount_display":"25.00","description":"rent","reason":"insufficient_funds"`},
+		{"filtered out", "GET", "/users/alice/failures?reason=limit_exceeded", http.StatusOK, `[]`},
+		{"still short", "POST", retry, http.StatusUnprocessableEntity, "insufficient balance"},
+		{"unknown failure", "POST", "/failures/fail_missing/retry", http.StatusNotFound, "failed operation not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, tt.method, tt.path, "")
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, rec.Code, rec.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	ws.Deposit("alice", 20, "payday")
+	if rec := do(srv, "POST", retry, ""); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"type":"withdraw"`) {
+		t.Errorf("retry = %d %s, want the withdrawal", rec.Code, rec.Body)
+	}
+	if rec := do(srv, "POST", retry, ""); rec.Code != http.StatusConflict {
+		t.Errorf("second retry status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := do(srv, "GET", "/users/alice/failures", ""); !strings.Contains(rec.Body.String(), `"attempts":3,`) || !strings.Contains(rec.Body.String(), `"retried_as_tx_id":"tx`) {
+		t.Errorf("failures after retrying = %s", rec.Body)
+	}
+}
+
+func TestServer_DisplayPolicies(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 12.5, "seed")
+	ws.SetDisplayPolicy("acme", "USD", wallet.DisplayPolicy{Places: 1, TrailingZeros: wallet.TrimTrailingZeros})
+	srv := NewServer(ws, Tenant(nil))
+
+	tests := []struct {
+		name     string
+		tenant   string
+		path     string
+		wantBody string
+	}{
+		{"currency precision", "", "/users/alice/balance", `"balance":"12.5","balance_display":"12.50"`},
+		{"tenant policy", "acme", "/users/alice/balance", `"balance":"12.5","balance_display":"12.5"`},
+		{"other tenant", "globex", "/users/alice/balance", `"balance_display":"12.50"`},
+		{"transactions", "acme", "/users/alice/transactions", `"amount":"12.5","amount_display":"12.5"`},
+		{"limits", "", "/users/alice/limits", `"daily_display":"0.00"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set(tenantHeader, tt.tenant)
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s = %d %s, want 200 containing %s", tt.path, rec.Code, rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Rates(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.RecordRate("EUR", "USD", decimal.RequireFromString("1.08"), "ecb")
+	srv := NewServer(ws)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"history", "/rates?from=eur&to=usd", http.StatusOK, `"rate":"1.08","source":"ecb"`},
+		{"other pair", "/rates?from=usd&to=eur", http.StatusOK, `[]`},
+		{"future window", "/rates?from=eur&to=usd&since=99999999999", http.StatusOK, `[]`},
+		{"bad bound", "/rates?from=eur&to=usd&until=soon", http.StatusBadRequest, "invalid until"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, "GET", tt.path, "")
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s = %d %s, want %d containing %s", tt.path, rec.Code, rec.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_IdempotencyKey(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	srv := NewServer(ws)
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/users/alice/deposits", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "retry-1")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := send(`{"amount":"25"}`); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"balance":"25"`) {
+			t.Errorf("attempt %d = %d %s, want the deposit applied once", i, rec.Code, rec.Body)
+		}
+	}
+	if rec := send(`{"amount":"30"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key = %d %s, want %d", rec.Code, rec.Body, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestServer_ClientTxID(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 50, "seed")
+	srv := NewServer(ws)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"reserve", "POST", "/users/alice/client-tx-ids", `{"client_tx_id":"c-1","ttl_seconds":60}`, http.StatusCreated, `"client_tx_id":"c-1","user_id":"alice"`},
+		{"taken", "POST", "/users/bob/client-tx-ids", `{"client_tx_id":"c-1"}`, http.StatusConflict, "already reserved"},
+		{"no ID", "POST", "/users/alice/client-tx-ids", `{}`, http.StatusBadRequest, "required"},
+		{"transfer", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"20","client_tx_id":"c-1"}`, http.StatusCreated, `"balance":"30"`},
+		{"network retry", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"20","client_tx_id":"c-1"}`, http.StatusCreated, `"balance":"30"`},
+		{"not reserved", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"20","client_tx_id":"c-2"}`, http.StatusUnprocessableEntity, "not reserved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, rec.Code, rec.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_AuditLog(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	keys := APIKeys(map[string]Caller{
+		"k-alice": {Principal: "alice-app", UserID: "alice"},
+		"k-ops":   {Principal: "ops", Roles: []string{RoleAdmin}},
+	})
+	srv := NewServer(ws, AuthenticateCaller(keys))
+
+	send := func(key, method, path, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(apiKeyHeader, key)
+		req.Header.Set("User-Agent", "wallet-ios/2.1")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	send("k-ops", "POST", "/users/alice/deposits", `{"amount":"40"}`, traceHeader, "req-1")
+	send("k-alice", "POST", "/users/alice/withdrawals", `{"amount":"5"}`, idempotencyHeader, "w-1")
+	send("k-alice", "POST", "/transfers", `{"from":"alice","to":"bob","amount":"90"}`)
+
+	tests := []struct {
+		name       string
+		key        string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"deposit with its request", "k-ops", "/audit?actor=ops", http.StatusOK, `"actor_id":"ops","ip":"192.0.2.1","user_agent":"wallet-ios/2.1","request_id":"req-1","operation":"deposit","user_ids":["alice"]`},
+		{"idempotent withdrawal", "k-ops", "/audit?actor=alice-app", http.StatusOK, `"operation":"withdraw","user_ids":["alice"],"transaction_id":"`},
+		{"failed transfer", "k-ops", "/audit?actor=alice-app", http.StatusOK, `"operation":"transfer","user_ids":["alice","bob"],"error":"insufficient balance"`},
+		{"other actor", "k-ops", "/audit?actor=desk", http.StatusOK, `[]`},
+		{"bad bound", "k-ops", "/audit?since=yesterday", http.StatusBadRequest, "invalid since"},
+		{"users may not read it", "k-alice", "/audit", http.StatusForbidden, "may not"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := send(tt.key, "GET", tt.path, "")
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s = %d %s, want %d containing %s", tt.path, rec.Code, rec.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	var limited []auditEntryResponse
+	json.NewDecoder(send("k-ops", "GET", "/audit?actor=alice-app&limit=1", "").Body).Decode(&limited)
+	if len(limited) != 1 || limited[0].Operation != "withdraw" {
+		t.Errorf("GET /audit?limit=1 = %+v, want the withdrawal alone", limited)
+	}
+}
+
+func TestServer_StatementDescriptor(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("shop", "Corner Shop Ltd", "shop@example.com")
+	ws.Deposit("alice", 50, "seed")
+	srv := NewServer(ws)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"set", "PUT", "/users/shop/descriptor", `{"descriptor":"CORNER SHOP"}`, http.StatusOK, `"descriptor":"CORNER SHOP","changes":[{"descriptor":"CORNER SHOP","previous":""`},
+		{"invalid", "PUT", "/users/shop/descriptor", `{"descriptor":"<b>"}`, http.StatusBadRequest, "statement descriptor"},
+		{"unknown wallet", "GET", "/users/ghost/descriptor", "", http.StatusNotFound, "not found"},
+		{"pay the shop", "POST", "/transfers", `{"from":"alice","to":"shop","amount":"12"}`, http.StatusCreated, `"balance":"38"`},
+		{"counterparty", "GET", "/users/alice/transactions", "", http.StatusOK, `"counterparty":"CORNER SHOP"`},
+		{"payee sees the payer's ID", "GET", "/users/shop/transactions", "", http.StatusOK, `"counterparty":"alice"`},
+		{"cleared", "PUT", "/users/shop/descriptor", `{"descriptor":""}`, http.StatusOK, `"descriptor":"","changes":[`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(srv, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, rec.Code, rec.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_RequestIDRecorded(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	srv := NewServer(ws)
+
+	req := httptest.NewRequest("POST", "/users/alice/deposits", strings.NewReader(`{"amount":"10"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "req-42")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("deposit = %d %s", rec.Code, rec.Body)
+	}
+
+	history, _ := ws.GetTransactionHistory("alice")
+	if len(history) != 1 || history[0].Metadata["trace_id"] != "req-42" {
+		t.Errorf("history = %+v, want the request ID recorded", history)
+	}
+}
+
+func TestServer_SessionToken(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	srv := NewServer(ws)
+
+	req := httptest.NewRequest("POST", "/users/alice/deposits", strings.NewReader(`{"amount":"10"}`))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	token := rec.Header().Get("X-Session-Token")
+	if rec.Code != http.StatusCreated || token == "" {
+		t.Fatalf("deposit = %d with token %q", rec.Code, token)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"own write", token, http.StatusOK},
+		{"write not seen yet", "s1000", http.StatusServiceUnavailable},
+		{"malformed", "bogus", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			req := httptest.NewRequest("GET", "/users/alice/balance", nil).WithContext(ctx)
+			req.Header.Set("X-Session-Token", tt.token)
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("balance = %d %s, want %d", rec.Code, rec.Body, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// internal/grpcapi/interceptors.go
+package grpcapi
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-app/internal/ratelimit"
+	"wallet-app/internal/wallet"
+)
+
+// CallInfo describes a call to the interceptors handling it. The same CallInfo is passed
+// down the chain, so what an inner interceptor learns is visible to outer ones once the
+// call returns.
+type CallInfo struct {
+	Method    string      // method name without ServicePath
+	Header    http.Header // request metadata
+	Trailer   http.Header // metadata sent back with the status
+	Principal string      // caller identified by AuthInterceptor
+}
+
+// UnaryHandler runs a call on a wire-encoded request and returns the wire-encoded reply
+type UnaryHandler func(ctx context.Context, req []byte) ([]byte, error)
+
+// UnaryInterceptor wraps every call with a cross-cutting concern. It calls next to
+// continue the call, or returns without doing so to reject it.
+type UnaryInterceptor func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error)
+
+// StatusError is a failure with an explicit status code, for interceptors rejecting a
+// call
+type StatusError struct {
+	Code    Code
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// chain returns handler wrapped in interceptors; the first runs outermost
+func chain(interceptors []UnaryInterceptor, call *CallInfo, handler UnaryHandler) UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req []byte) ([]byte, error) {
+			return interceptor(ctx, call, req, next)
+		}
+	}
+	return handler
+}
+
+// Authenticator identifies the caller from the call's metadata, returning an error when
+// it carries no valid credentials
+type Authenticator func(ctx context.Context, call *CallInfo) (principal string, err error)
+
+// principalKey is the context key of the authenticated caller
+type principalKey struct{}
+
+// PrincipalFromContext returns the caller identified by AuthInterceptor, if any
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// AuthInterceptor fails calls auth does not accept with Unauthenticated and passes the
+// rest on with the caller in their context, also as the actor of the audit log
+func AuthInterceptor(auth Authenticator) UnaryInterceptor {
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		principal, err := auth(ctx, call)
+		if err != nil {
+			return nil, &StatusError{Code: Unauthenticated, Message: "unauthenticated: " + err.Error()}
+		}
+		call.Principal = principal
+		actor := wallet.ActorFromContext(ctx)
+		actor.ID = principal
+		ctx = wallet.ContextWithActor(ctx, actor)
+		return next(context.WithValue(ctx, principalKey{}, principal), req)
+	}
+}
+
+// Policy decides whether the caller of call may act on userID's wallet, returning an
+// error saying why not. call.Principal is empty when no AuthInterceptor ran before it.
+type Policy func(ctx context.Context, call *CallInfo, userID string) error
+
+// ErrForbidden is returned by OwnWalletPolicy for wallets the caller does not own
+var ErrForbidden = errors.New("caller may not act on this wallet")
+
+// OwnWalletPolicy lets each principal act only on the wallet with its own ID
+func OwnWalletPolicy(ctx context.Context, call *CallInfo, userID string) error {
+	if call.Principal == "" || call.Principal != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeInterceptor fails calls policy refuses with PermissionDenied. The wallet a
+// call acts on is its user_id, or from_user_id for Transfer. It must run after
+// AuthInterceptor so policy sees the caller.
+func AuthorizeInterceptor(policy Policy) UnaryInterceptor {
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		userID, err := subjectOf(call.Method, req)
+		if err != nil {
+			return nil, invalidArgument(err)
+		}
+		if err := policy(ctx, call, userID); err != nil {
+			return nil, &StatusError{Code: PermissionDenied, Message: "forbidden: " + err.Error()}
+		}
+		return next(ctx, req)
+	}
+}
+
+// subjectOf decodes the wallet a call to method acts on from its request
+func subjectOf(method string, req []byte) (string, error) {
+	var err error
+	switch method {
+	case "CreateUser":
+		var m CreateUserRequest
+		err = m.Unmarshal(req)
+		return m.UserID, err
+	case "GetWallet":
+		var m GetWalletRequest
+		err = m.Unmarshal(req)
+		return m.UserID, err
+	case "Deposit":
+		var m DepositRequest
+		err = m.Unmarshal(req)
+		return m.UserID, err
+	case "Withdraw":
+		var m WithdrawRequest
+		err = m.Unmarshal(req)
+		return m.UserID, err
+	case "Transfer":
+		var m TransferRequest
+		err = m.Unmarshal(req)
+		return m.FromUserID, err
+	case "ListTransactions":
+		var m ListTransactionsRequest
+		err = m.Unmarshal(req)
+		return m.UserID, err
+	}
+	return "", fmt.Errorf("no wallet known for method %s", method)
+}
+
+// RateLimitInterceptor fails calls with ResourceExhausted once a caller runs out of
+// tokens in limiter, telling it when to retry in the retry-after trailer. Callers are
+// keyed by key, or when nil by the authenticated principal.
+func RateLimitInterceptor(limiter *ratelimit.Limiter, key func(ctx context.Context, call *CallInfo) string) UnaryInterceptor {
+	if key == nil {
+		key = func(ctx context.Context, call *CallInfo) string { return call.Principal }
+	}
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		if ok, retry := limiter.Allow(key(ctx, call)); !ok {
+			call.Trailer.Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			return nil, &StatusError{Code: ResourceExhausted, Message: "rate limit exceeded"}
+		}
+		return next(ctx, req)
+	}
+}
+
+// TenantInterceptor serves each call for the tenant resolve names, or when nil the one
+// sent in x-tenant-id; reply amounts are displayed per that tenant's display policies
+func TenantInterceptor(resolve func(ctx context.Context, call *CallInfo) string) UnaryInterceptor {
+	if resolve == nil {
+		resolve = func(ctx context.Context, call *CallInfo) string { return call.Header.Get(tenantHeader) }
+	}
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		return next(wallet.ContextWithTenant(ctx, resolve(ctx, call)), req)
+	}
+}
+
+// MetricsInterceptor counts calls by method and status code and observes their duration
+func MetricsInterceptor(m wallet.MetricsRecorder) UnaryInterceptor {
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+
+		code := OK
+		if err != nil {
+			code = codeOf(err)
+		}
+		labels := map[string]string{"method": call.Method, "code": strconv.Itoa(int(code))}
+		m.IncCounter("grpc_calls_total", labels)
+		m.ObserveValue("grpc_call_seconds", time.Since(start).Seconds(), labels)
+		return resp, err
+	}
+}
+
+// TraceInterceptor gives every call a trace ID: the one sent in x-request-id, or a new
+// one. The ID is returned in the trailers and recorded on the transactions the call
+// creates.
+func TraceInterceptor() UnaryInterceptor {
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		id := wallet.TraceIDFromContext(ctx)
+		if id == "" {
+			id = wallet.NewTraceID()
+			ctx = wallet.ContextWithTraceID(ctx, id)
+		}
+		call.Trailer.Set(requestIDHeader, id)
+		return next(ctx, req)
+	}
+}
+
+// LoggingInterceptor logs one line per call with its outcome and duration
+func LoggingInterceptor(logger *slog.Logger) UnaryInterceptor {
+	return func(ctx context.Context, call *CallInfo, req []byte, next UnaryHandler) ([]byte, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+
+		code, level := OK, slog.LevelInfo
+		if err != nil {
+			code = codeOf(err)
+			if code == Internal {
+				level = slog.LevelError
+			}
+		}
+		logger.LogAttrs(ctx, level, "grpc call",
+			slog.String("method", call.Method),
+			slog.Int("code", int(code)),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("request_id", cmp.Or(call.Trailer.Get(requestIDHeader), call.Header.Get(requestIDHeader))),
+			slog.String("principal", call.Principal),
+		)
+		return resp, err
+	}
+}
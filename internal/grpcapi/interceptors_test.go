@@ -0,0 +1,225 @@
+// internal/grpcapi/interceptors_test.go
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"wallet-app/internal/ratelimit"
+	"wallet-app/internal/wallet"
+)
+
+// countingMetrics records counters by name and label values
+type countingMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func (m *countingMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name+" "+labels["method"]+" "+labels["code"]]++
+}
+
+func (m *countingMetrics) ObserveValue(string, float64, map[string]string) {}
+
+// lockedBuffer is a log sink safe for the server's goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServer_Interceptors(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.New(1, 2, ratelimit.WithClock(func() time.Time { return now }))
+	metrics := &countingMetrics{counters: make(map[string]int)}
+	var logs lockedBuffer
+	auth := func(ctx context.Context, call *CallInfo) (string, error) {
+		// Every test call comes from the same client; the method stands in for a caller
+		if call.Method == "CreateUser" {
+			return "", errors.New("no credentials")
+		}
+		return "caller-" + call.Method, nil
+	}
+	base, client := startServer(t, ws,
+		TraceInterceptor(),
+		LoggingInterceptor(slog.New(slog.NewTextHandler(&logs, nil))),
+		MetricsInterceptor(metrics),
+		AuthInterceptor(auth),
+		RateLimitInterceptor(limiter, nil),
+	)
+
+	steps := []struct {
+		name     string
+		method   string
+		req      message
+		wantCode Code
+	}{
+		{"unauthenticated", "CreateUser", &CreateUserRequest{UserID: "bob"}, Unauthenticated},
+		{"first", "GetWallet", &GetWalletRequest{UserID: "alice"}, OK},
+		{"second", "GetWallet", &GetWalletRequest{UserID: "alice"}, OK},
+		{"over the limit", "GetWallet", &GetWalletRequest{UserID: "alice"}, ResourceExhausted},
+		{"other caller", "Deposit", &DepositRequest{UserID: "alice", Amount: "5"}, OK},
+	}
+	for _, s := range steps {
+		var w Wallet
+		if code, msg := call(t, client, base, s.method, s.req, &w); code != s.wantCode {
+			t.Errorf("%s: code = %d (%s), want %d", s.name, code, msg, s.wantCode)
+		}
+	}
+
+	for key, want := range map[string]int{
+		"grpc_calls_total GetWallet 0":   2,
+		"grpc_calls_total GetWallet 8":   1,
+		"grpc_calls_total CreateUser 16": 1,
+	} {
+		if got := metrics.counters[key]; got != want {
+			t.Errorf("%s = %d, want %d", key, got, want)
+		}
+	}
+	history, _ := ws.GetTransactionHistory("alice")
+	if len(history) != 1 || history[0].Metadata["trace_id"] == "" {
+		t.Errorf("history = %+v, want the deposit traced", history)
+	}
+	if out := logs.String(); strings.Count(out, "grpc call") != len(steps) || !strings.Contains(out, "principal=caller-Deposit") {
+		t.Errorf("logs = %s", out)
+	}
+}
+
+func TestTenantInterceptor(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 12.5, "seed")
+	ws.Deposit("alice", 7.5, "top-up")
+	ws.SetDisplayPolicy("acme", "USD", wallet.DisplayPolicy{Places: 2, TrailingZeros: wallet.TrimWholeZeros})
+
+	// Every test call comes from the same client; the method stands in for a tenant
+	base, client := startServer(t, ws, TenantInterceptor(func(ctx context.Context, call *CallInfo) string {
+		if call.Method == "GetWallet" {
+			return "acme"
+		}
+		return ""
+	}))
+
+	var w Wallet
+	if code, msg := call(t, client, base, "GetWallet", &GetWalletRequest{UserID: "alice"}, &w); code != OK {
+		t.Fatalf("GetWallet = %d %s", code, msg)
+	}
+	if w.Balance != "20" || w.BalanceDisplay != "20" {
+		t.Errorf("wallet = %s displayed %q, want 20 displayed %q", w.Balance, w.BalanceDisplay, "20")
+	}
+
+	var list ListTransactionsResponse
+	if code, msg := call(t, client, base, "ListTransactions", &ListTransactionsRequest{UserID: "alice"}, &list); code != OK {
+		t.Fatalf("ListTransactions = %d %s", code, msg)
+	}
+	// Other tenants keep the currency's precision
+	if len(list.Transactions) != 2 || list.Transactions[0].Amount != "12.5" || list.Transactions[0].AmountDisplay != "12.50" {
+		t.Errorf("transactions = %+v, want 12.5 displayed 12.50 first", list.Transactions)
+	}
+}
+
+// discard accepts any reply
+type discard struct{}
+
+func (discard) Unmarshal([]byte) error { return nil }
+
+func TestAuthorizeInterceptor(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 50, "seed")
+	ws.Deposit("bob", 50, "seed")
+
+	// The x-user header stands in for a caller's credentials
+	auth := func(ctx context.Context, call *CallInfo) (string, error) {
+		return call.Header.Get("X-User"), nil
+	}
+	base, client := startServer(t, ws, AuthInterceptor(auth), AuthorizeInterceptor(OwnWalletPolicy))
+
+	tests := []struct {
+		name     string
+		method   string
+		req      message
+		wantCode Code
+	}{
+		{"own wallet", "GetWallet", &GetWalletRequest{UserID: "alice"}, OK},
+		{"read another wallet", "GetWallet", &GetWalletRequest{UserID: "bob"}, PermissionDenied},
+		{"deposit to another wallet", "Deposit", &DepositRequest{UserID: "bob", Amount: "5"}, PermissionDenied},
+		{"withdraw from another wallet", "Withdraw", &WithdrawRequest{UserID: "bob", Amount: "5"}, PermissionDenied},
+		{"transfer from another wallet", "Transfer", &TransferRequest{FromUserID: "bob", ToUserID: "alice", Amount: "5"}, PermissionDenied},
+		{"transfer from own wallet", "Transfer", &TransferRequest{FromUserID: "alice", ToUserID: "bob", Amount: "5"}, OK},
+		{"another history", "ListTransactions", &ListTransactionsRequest{UserID: "bob"}, PermissionDenied},
+		{"create another user", "CreateUser", &CreateUserRequest{UserID: "carol", Name: "Carol", Email: "c@example.com"}, PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, msg := callAs(t, client, base, "alice", tt.method, tt.req, discard{}); code != tt.wantCode {
+				t.Errorf("code = %d (%s), want %d", code, msg, tt.wantCode)
+			}
+		})
+	}
+
+	if b, _ := ws.GetBalance("bob"); b != 55 {
+		t.Errorf("bob's balance = %v, want 55 after alice's one permitted transfer", b)
+	}
+}
+
+func TestAuthInterceptor_RecordsActor(t *testing.T) {
+	ws := wallet.NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+
+	auth := func(ctx context.Context, call *CallInfo) (string, error) {
+		return call.Header.Get("X-User"), nil
+	}
+	base, client := startServer(t, ws, TraceInterceptor(), AuthInterceptor(auth))
+
+	calls := []struct {
+		method string
+		req    message
+	}{
+		{"Deposit", &DepositRequest{UserID: "alice", Amount: "20", IdempotencyKey: "dep-1"}},
+		{"Withdraw", &WithdrawRequest{UserID: "alice", Amount: "5", IdempotencyKey: "wd-1"}},
+		{"Transfer", &TransferRequest{FromUserID: "alice", ToUserID: "bob", Amount: "5", IdempotencyKey: "tr-1"}},
+	}
+	for _, c := range calls {
+		if code, msg := callAs(t, client, base, "alice", c.method, c.req, discard{}); code != OK {
+			t.Fatalf("%s: code = %d (%s)", c.method, code, msg)
+		}
+	}
+
+	entries, _ := ws.QueryAuditLog(wallet.AuditQuery{ActorID: "alice"})
+	var ops []string
+	for _, e := range entries {
+		ops = append(ops, e.Operation)
+		if e.Actor.IP != "127.0.0.1" || e.Actor.UserAgent == "" || e.Actor.RequestID == "" {
+			t.Errorf("%s actor = %+v, want the caller's address, user agent and request ID", e.Operation, e.Actor)
+		}
+	}
+	if want := []string{"deposit", "withdraw", "transfer"}; !slices.Equal(ops, want) {
+		t.Errorf("alice's audited operations = %v, want %v", ops, want)
+	}
+}
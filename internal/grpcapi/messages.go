@@ -0,0 +1,391 @@
+// internal/grpcapi/messages.go
+package grpcapi
+
+// The types below mirror the messages in wallet.proto field for field. Marshal and
+// Unmarshal speak the protobuf wire format, so clients generated from wallet.proto in
+// any language interoperate with them.
+
+// User mirrors wallet.v1.User
+type User struct {
+	ID    string
+	Name  string
+	Email string
+}
+
+// Marshal encodes the message
+func (m *User) Marshal() []byte {
+	var e encoder
+	e.string(1, m.ID)
+	e.string(2, m.Name)
+	e.string(3, m.Email)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *User) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.ID = f.string()
+		case 2:
+			m.Name = f.string()
+		case 3:
+			m.Email = f.string()
+		}
+		return nil
+	})
+}
+
+// Wallet mirrors wallet.v1.Wallet
+type Wallet struct {
+	UserID          string
+	Currency        string
+	Balance         string
+	ForeignBalances map[string]string
+	AutoSettle      bool
+	BalanceDisplay  string
+}
+
+// Marshal encodes the message
+func (m *Wallet) Marshal() []byte {
+	var e encoder
+	e.string(1, m.UserID)
+	e.string(2, m.Currency)
+	e.string(3, m.Balance)
+	e.stringMap(4, m.ForeignBalances)
+	e.bool(5, m.AutoSettle)
+	e.string(6, m.BalanceDisplay)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *Wallet) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.UserID = f.string()
+		case 2:
+			m.Currency = f.string()
+		case 3:
+			m.Balance = f.string()
+		case 4:
+			return decodeMapEntry(&m.ForeignBalances, f.data)
+		case 5:
+			m.AutoSettle = f.bool()
+		case 6:
+			m.BalanceDisplay = f.string()
+		}
+		return nil
+	})
+}
+
+// Transaction mirrors wallet.v1.Transaction
+type Transaction struct {
+	ID            string
+	FromUserID    string
+	ToUserID      string
+	Amount        string
+	Currency      string
+	Type          string
+	Description   string
+	Timestamp     int64
+	ParentTxID    string
+	Metadata      map[string]string
+	Status        string
+	AmountDisplay string
+}
+
+// Marshal encodes the message
+func (m *Transaction) Marshal() []byte {
+	var e encoder
+	e.string(1, m.ID)
+	e.string(2, m.FromUserID)
+	e.string(3, m.ToUserID)
+	e.string(4, m.Amount)
+	e.string(5, m.Currency)
+	e.string(6, m.Type)
+	e.string(7, m.Description)
+	e.int64(8, m.Timestamp)
+	e.string(9, m.ParentTxID)
+	e.stringMap(10, m.Metadata)
+	e.string(11, m.Status)
+	e.string(12, m.AmountDisplay)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *Transaction) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.ID = f.string()
+		case 2:
+			m.FromUserID = f.string()
+		case 3:
+			m.ToUserID = f.string()
+		case 4:
+			m.Amount = f.string()
+		case 5:
+			m.Currency = f.string()
+		case 6:
+			m.Type = f.string()
+		case 7:
+			m.Description = f.string()
+		case 8:
+			m.Timestamp = f.int64()
+		case 9:
+			m.ParentTxID = f.string()
+		case 10:
+			return decodeMapEntry(&m.Metadata, f.data)
+		case 11:
+			m.Status = f.string()
+		case 12:
+			m.AmountDisplay = f.string()
+		}
+		return nil
+	})
+}
+
+// CreateUserRequest mirrors wallet.v1.CreateUserRequest
+type CreateUserRequest struct {
+	UserID string
+	Name   string
+	Email  string
+}
+
+// Marshal encodes the message
+func (m *CreateUserRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.UserID)
+	e.string(2, m.Name)
+	e.string(3, m.Email)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *CreateUserRequest) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.UserID = f.string()
+		case 2:
+			m.Name = f.string()
+		case 3:
+			m.Email = f.string()
+		}
+		return nil
+	})
+}
+
+// GetWalletRequest mirrors wallet.v1.GetWalletRequest
+type GetWalletRequest struct {
+	UserID string
+}
+
+// Marshal encodes the message
+func (m *GetWalletRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.UserID)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *GetWalletRequest) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		if f.num == 1 {
+			m.UserID = f.string()
+		}
+		return nil
+	})
+}
+
+// DepositRequest mirrors wallet.v1.DepositRequest
+type DepositRequest struct {
+	UserID         string
+	Amount         string
+	Description    string
+	Currency       string
+	IdempotencyKey string
+}
+
+// Marshal encodes the message
+func (m *DepositRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.UserID)
+	e.string(2, m.Amount)
+	e.string(3, m.Description)
+	e.string(4, m.Currency)
+	e.string(5, m.IdempotencyKey)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *DepositRequest) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.UserID = f.string()
+		case 2:
+			m.Amount = f.string()
+		case 3:
+			m.Description = f.string()
+		case 4:
+			m.Currency = f.string()
+		case 5:
+			m.IdempotencyKey = f.string()
+		}
+		return nil
+	})
+}
+
+// WithdrawRequest mirrors wallet.v1.WithdrawRequest
+type WithdrawRequest struct {
+	UserID         string
+	Amount         string
+	Description    string
+	IdempotencyKey string
+}
+
+// Marshal encodes the message
+func (m *WithdrawRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.UserID)
+	e.string(2, m.Amount)
+	e.string(3, m.Description)
+	e.string(4, m.IdempotencyKey)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *WithdrawRequest) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.UserID = f.string()
+		case 2:
+			m.Amount = f.string()
+		case 3:
+			m.Description = f.string()
+		case 4:
+			m.IdempotencyKey = f.string()
+		}
+		return nil
+	})
+}
+
+// TransferRequest mirrors wallet.v1.TransferRequest
+type TransferRequest struct {
+	FromUserID     string
+	ToUserID       string
+	Amount         string
+	Description    string
+	IdempotencyKey string
+}
+
+// Marshal encodes the message
+func (m *TransferRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.FromUserID)
+	e.string(2, m.ToUserID)
+	e.string(3, m.Amount)
+	e.string(4, m.Description)
+	e.string(5, m.IdempotencyKey)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *TransferRequest) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.FromUserID = f.string()
+		case 2:
+			m.ToUserID = f.string()
+		case 3:
+			m.Amount = f.string()
+		case 4:
+			m.Description = f.string()
+		case 5:
+			m.IdempotencyKey = f.string()
+		}
+		return nil
+	})
+}
+
+// TransferResponse mirrors wallet.v1.TransferResponse
+type TransferResponse struct {
+	Sender *Wallet
+	Held   bool
+}
+
+// Marshal encodes the message
+func (m *TransferResponse) Marshal() []byte {
+	var e encoder
+	e.message(1, m.Sender, m.Sender != nil)
+	e.bool(2, m.Held)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *TransferResponse) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.Sender = &Wallet{}
+			return m.Sender.Unmarshal(f.data)
+		case 2:
+			m.Held = f.bool()
+		}
+		return nil
+	})
+}
+
+// ListTransactionsRequest mirrors wallet.v1.ListTransactionsRequest
+type ListTransactionsRequest struct {
+	UserID string
+}
+
+// Marshal encodes the message
+func (m *ListTransactionsRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.UserID)
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *ListTransactionsRequest) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		if f.num == 1 {
+			m.UserID = f.string()
+		}
+		return nil
+	})
+}
+
+// ListTransactionsResponse mirrors wallet.v1.ListTransactionsResponse
+type ListTransactionsResponse struct {
+	Transactions []*Transaction
+}
+
+// Marshal encodes the message
+func (m *ListTransactionsResponse) Marshal() []byte {
+	var e encoder
+	for _, tx := range m.Transactions {
+		e.message(1, tx, true)
+	}
+	return e.buf
+}
+
+// Unmarshal decodes the message
+func (m *ListTransactionsResponse) Unmarshal(b []byte) error {
+	return decode(b, func(f field) error {
+		if f.num == 1 {
+			tx := &Transaction{}
+			if err := tx.Unmarshal(f.data); err != nil {
+				return err
+			}
+			m.Transactions = append(m.Transactions, tx)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,361 @@
+// internal/grpcapi/server.go
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// ServicePath is the HTTP path prefix of the WalletService methods
+const ServicePath = "/wallet.v1.WalletService/"
+
+// Code is a gRPC status code
+type Code int
+
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unauthenticated    Code = 16
+)
+
+// Server exposes a WalletService over gRPC. It speaks the gRPC HTTP/2 protocol directly,
+// so it can be mounted on any http.Server that serves HTTP/2; for plaintext traffic
+// inside a cluster enable unencrypted HTTP/2:
+//
+//	var protocols http.Protocols
+//	protocols.SetUnencryptedHTTP2(true)
+//	srv := &http.Server{Addr: ":9090", Handler: grpcapi.NewServer(ws), Protocols: &protocols}
+type Server struct {
+	ws           *wallet.WalletService
+	methods      map[string]func(ctx context.Context, req []byte) (message, error)
+	interceptors []UnaryInterceptor
+}
+
+// message is any wallet.v1 message
+type message interface {
+	Marshal() []byte
+}
+
+// NewServer creates a gRPC handler for ws. Every call runs through interceptors, the
+// first running outermost.
+func NewServer(ws *wallet.WalletService, interceptors ...UnaryInterceptor) *Server {
+	s := &Server{ws: ws, interceptors: interceptors}
+	s.methods = map[string]func(context.Context, []byte) (message, error){
+		"CreateUser":       s.createUser,
+		"GetWallet":        s.getWallet,
+		"Deposit":          s.deposit,
+		"Withdraw":         s.withdraw,
+		"Transfer":         s.transfer,
+		"ListTransactions": s.listTransactions,
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler for unary calls
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc+proto")
+	w.WriteHeader(http.StatusOK)
+
+	name := strings.TrimPrefix(r.URL.Path, ServicePath)
+	method, ok := s.methods[name]
+	if !ok || !strings.HasPrefix(r.URL.Path, ServicePath) {
+		writeStatus(w, Unimplemented, "unknown method "+r.URL.Path)
+		return
+	}
+	req, err := readFrame(r.Body)
+	if err != nil {
+		writeStatus(w, InvalidArgument, "reading request: "+err.Error())
+		return
+	}
+	call := &CallInfo{Method: name, Header: r.Header, Trailer: make(http.Header)}
+	handler := chain(s.interceptors, call, func(ctx context.Context, req []byte) ([]byte, error) {
+		resp, err := method(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Marshal(), nil
+	})
+	resp, err := handler(requestContext(r), req)
+	for key, values := range call.Trailer {
+		w.Header()[http.TrailerPrefix+key] = values
+	}
+	if err != nil {
+		writeStatus(w, codeOf(err), err.Error())
+		return
+	}
+	w.Write(appendFrame(nil, resp))
+	writeStatus(w, OK, "")
+}
+
+// requestIDHeader carries a client-chosen request ID in the call metadata, recorded on
+// the transactions the call creates
+const requestIDHeader = "X-Request-Id"
+
+// tenantHeader names the tenant a call is served for when TenantInterceptor has no
+// resolver
+const tenantHeader = "X-Tenant-Id"
+
+// requestContext returns r's context carrying the call's request ID, if it sent one,
+// and the caller's address and user agent for the audit log. AuthInterceptor adds the
+// caller's identity.
+func requestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	id := r.Header.Get(requestIDHeader)
+	if id != "" {
+		ctx = wallet.ContextWithTraceID(ctx, id)
+	}
+	return wallet.ContextWithActor(ctx, wallet.Actor{
+		IP:        clientAddr(r),
+		UserAgent: r.UserAgent(),
+		RequestID: id,
+	})
+}
+
+// clientAddr returns the host part of r's remote address
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeStatus sets the gRPC status trailers. gRPC answers every call with HTTP 200; the
+// outcome travels in the trailers.
+func writeStatus(w http.ResponseWriter, code Code, msg string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(int(code)))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", url.PathEscape(msg))
+	}
+}
+
+func (s *Server) createUser(ctx context.Context, b []byte) (message, error) {
+	var req CreateUserRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := s.ws.CreateUserContext(ctx, req.UserID, req.Name, req.Email); err != nil {
+		return nil, err
+	}
+	return &User{ID: req.UserID, Name: req.Name, Email: req.Email}, nil
+}
+
+func (s *Server) getWallet(ctx context.Context, b []byte) (message, error) {
+	var req GetWalletRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, invalidArgument(err)
+	}
+	return s.wallet(ctx, req.UserID)
+}
+
+func (s *Server) deposit(ctx context.Context, b []byte) (message, error) {
+	var req DepositRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, invalidArgument(err)
+	}
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.Currency != "" && req.IdempotencyKey != "":
+		return nil, fmt.Errorf("%w: idempotency keys apply to base-currency deposits", errInvalidArgument)
+	case req.Currency != "":
+		err = s.ws.DepositCurrencyContext(ctx, req.UserID, req.Currency, amount, req.Description)
+	case req.IdempotencyKey != "":
+		_, err = s.ws.DepositIdempotentContext(ctx, req.IdempotencyKey, req.UserID, amount, req.Description)
+	default:
+		err = s.ws.DepositContext(ctx, req.UserID, amount, req.Description)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.wallet(ctx, req.UserID)
+}
+
+func (s *Server) withdraw(ctx context.Context, b []byte) (message, error) {
+	var req WithdrawRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, invalidArgument(err)
+	}
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if req.IdempotencyKey != "" {
+		_, err = s.ws.WithdrawIdempotentContext(ctx, req.IdempotencyKey, req.UserID, amount, req.Description)
+	} else {
+		err = s.ws.WithdrawContext(ctx, req.UserID, amount, req.Description)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.wallet(ctx, req.UserID)
+}
+
+func (s *Server) transfer(ctx context.Context, b []byte) (message, error) {
+	var req TransferRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, invalidArgument(err)
+	}
+	amount, err := parseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if req.IdempotencyKey != "" {
+		_, err = s.ws.TransferIdempotentContext(ctx, req.IdempotencyKey, req.FromUserID, req.ToUserID, amount, req.Description)
+	} else {
+		err = s.ws.TransferContext(ctx, req.FromUserID, req.ToUserID, amount, req.Description)
+	}
+	resp := &TransferResponse{}
+	// A held transfer has debited the sender; the call succeeded, delivery is pending
+	if errors.Is(err, wallet.ErrTransferHeld) {
+		resp.Held = true
+	} else if err != nil {
+		return nil, err
+	}
+	if resp.Sender, err = s.wallet(ctx, req.FromUserID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) listTransactions(ctx context.Context, b []byte) (message, error) {
+	var req ListTransactionsRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, invalidArgument(err)
+	}
+	history, err := s.ws.GetTransactionHistoryContext(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	tenant := wallet.TenantFromContext(ctx)
+	resp := &ListTransactionsResponse{Transactions: make([]*Transaction, len(history))}
+	for i, tx := range history {
+		resp.Transactions[i] = &Transaction{
+			ID:            tx.ID,
+			FromUserID:    tx.FromUserID,
+			ToUserID:      tx.ToUserID,
+			Amount:        tx.Amount.String(),
+			Currency:      tx.Currency,
+			Type:          string(tx.Type),
+			Description:   tx.Description,
+			Timestamp:     tx.Timestamp,
+			ParentTxID:    tx.ParentTxID,
+			Metadata:      tx.Metadata,
+			Status:        string(tx.Status),
+			AmountDisplay: s.ws.DisplayAmount(tenant, tx.Amount, tx.Currency),
+		}
+	}
+	return resp, nil
+}
+
+// wallet loads userID's wallet as a wallet.v1.Wallet, displaying its balance per the
+// call's tenant
+func (s *Server) wallet(ctx context.Context, userID string) (*Wallet, error) {
+	snap, err := s.ws.GetWallet(userID)
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{
+		UserID:         snap.UserID,
+		Currency:       snap.Currency,
+		Balance:        snap.Balance.String(),
+		AutoSettle:     snap.AutoSettle,
+		BalanceDisplay: s.ws.DisplayAmount(wallet.TenantFromContext(ctx), snap.Balance, snap.Currency),
+	}
+	if len(snap.Foreign) > 0 {
+		w.ForeignBalances = make(map[string]string, len(snap.Foreign))
+		for currency, amount := range snap.Foreign {
+			w.ForeignBalances[currency] = amount.String()
+		}
+	}
+	return w, nil
+}
+
+// errInvalidArgument marks malformed requests
+var errInvalidArgument = errors.New("invalid argument")
+
+func invalidArgument(err error) error {
+	return fmt.Errorf("%w: %v", errInvalidArgument, err)
+}
+
+// parseAmount parses a decimal string amount
+func parseAmount(s string) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("%w: amount %q is not a decimal", errInvalidArgument, s)
+	}
+	return amount, nil
+}
+
+// errorCodes maps service errors to gRPC status codes
+var errorCodes = []struct {
+	err  error
+	code Code
+}{
+	{errInvalidArgument, InvalidArgument},
+	{context.Canceled, Canceled},
+	{context.DeadlineExceeded, DeadlineExceeded},
+	{wallet.ErrTimeout, DeadlineExceeded},
+	{wallet.ErrUserNotFound, NotFound},
+	{wallet.ErrUserAlreadyExists, AlreadyExists},
+	{wallet.ErrEmailTaken, AlreadyExists},
+	{wallet.ErrReservedUserID, InvalidArgument},
+	{wallet.ErrInsufficientBalance, FailedPrecondition},
+	{wallet.ErrBelowMinimumBalance, FailedPrecondition},
+	{wallet.ErrAboveMaximumBalance, FailedPrecondition},
+	{wallet.ErrLimitExceeded, FailedPrecondition},
+	{wallet.ErrBelowFloor, FailedPrecondition},
+	{wallet.ErrInvalidAmount, InvalidArgument},
+	{wallet.ErrSameUserTransfer, InvalidArgument},
+	{wallet.ErrInvalidCurrency, InvalidArgument},
+	{wallet.ErrCurrencyMismatch, InvalidArgument},
+	{wallet.ErrPrecisionExceeded, InvalidArgument},
+	{wallet.ErrCounterpartyBlocked, PermissionDenied},
+	{wallet.ErrDestinationRequired, PermissionDenied},
+	{wallet.ErrOperationRejected, PermissionDenied},
+	{wallet.ErrWalletFrozen, FailedPrecondition},
+	{wallet.ErrWalletAdminFrozen, FailedPrecondition},
+	{wallet.ErrWalletClosed, FailedPrecondition},
+	{wallet.ErrStepUpRequired, PermissionDenied},
+	{wallet.ErrRestrictedLimit, FailedPrecondition},
+	{wallet.ErrConsentRequired, FailedPrecondition},
+	{wallet.ErrIdempotencyKeyReused, InvalidArgument},
+}
+
+// codeOf returns the status code mapped from err
+func codeOf(err error) Code {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code
+	}
+	for _, m := range errorCodes {
+		if errors.Is(err, m.err) {
+			return m.code
+		}
+	}
+	return Internal
+}
@@ -0,0 +1,154 @@
+// internal/grpcapi/server_test.go
+package grpcapi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"wallet-app/internal/wallet"
+)
+
+// startServer serves ws over plaintext HTTP/2, as a gRPC peer inside a cluster would
+// reach it, and returns its URL with a matching client
+func startServer(t *testing.T, ws *wallet.WalletService, interceptors ...UnaryInterceptor) (string, *http.Client) {
+	t.Helper()
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	ts := httptest.NewUnstartedServer(NewServer(ws, interceptors...))
+	ts.Config.Protocols = &protocols
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts.URL, &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+}
+
+// call invokes a unary method and decodes the reply into resp on success
+func call(t *testing.T, client *http.Client, base, method string, req message, resp interface{ Unmarshal([]byte) error }) (Code, string) {
+	t.Helper()
+	return callAs(t, client, base, "", method, req, resp)
+}
+
+// callAs is call sending user in the x-user metadata, when set
+func callAs(t *testing.T, client *http.Client, base, user, method string, req message, resp interface{ Unmarshal([]byte) error }) (Code, string) {
+	t.Helper()
+	httpReq, _ := http.NewRequest("POST", base+ServicePath+method, bytes.NewReader(appendFrame(nil, req.Marshal())))
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("TE", "trailers")
+	if user != "" {
+		httpReq.Header.Set("X-User", user)
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.ProtoMajor != 2 || httpResp.StatusCode != http.StatusOK {
+		t.Fatalf("%s: %s %s, want HTTP/2 200", method, httpResp.Proto, httpResp.Status)
+	}
+
+	body, _ := io.ReadAll(httpResp.Body)
+	code, _ := strconv.Atoi(httpResp.Trailer.Get("Grpc-Status"))
+	msg, _ := url.PathUnescape(httpResp.Trailer.Get("Grpc-Message"))
+	if Code(code) == OK {
+		frame, err := readFrame(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("%s: reading reply: %v", method, err)
+		}
+		if err := resp.Unmarshal(frame); err != nil {
+			t.Fatalf("%s: decoding reply: %v", method, err)
+		}
+	}
+	return Code(code), msg
+}
+
+func TestServer_Calls(t *testing.T) {
+	ws := wallet.NewWalletService()
+	base, client := startServer(t, ws)
+
+	var user User
+	if code, msg := call(t, client, base, "CreateUser", &CreateUserRequest{UserID: "alice", Name: "Alice", Email: "a@example.com"}, &user); code != OK || user.ID != "alice" {
+		t.Fatalf("CreateUser = %d %s, %+v", code, msg, user)
+	}
+	call(t, client, base, "CreateUser", &CreateUserRequest{UserID: "bob", Name: "Bob", Email: "b@example.com"}, &user)
+
+	tests := []struct {
+		name        string
+		method      string
+		req         message
+		wantCode    Code
+		wantBalance string
+	}{
+		{"deposit", "Deposit", &DepositRequest{UserID: "alice", Amount: "100.10", Description: "seed"}, OK, "100.1"},
+		{"sub-cent precision kept", "Deposit", &DepositRequest{UserID: "alice", Amount: "0.2"}, OK, "100.3"},
+		{"not a decimal", "Deposit", &DepositRequest{UserID: "alice", Amount: "1e"}, InvalidArgument, ""},
+		{"negative amount", "Deposit", &DepositRequest{UserID: "alice", Amount: "-1"}, InvalidArgument, ""},
+		{"unknown user", "GetWallet", &GetWalletRequest{UserID: "ghost"}, NotFound, ""},
+		{"duplicate user", "CreateUser", &CreateUserRequest{UserID: "bob", Email: "other@example.com"}, AlreadyExists, ""},
+		{"insufficient", "Withdraw", &WithdrawRequest{UserID: "bob", Amount: "1"}, FailedPrecondition, ""},
+		{"withdraw", "Withdraw", &WithdrawRequest{UserID: "alice", Amount: "0.3"}, OK, "100"},
+		{"get wallet", "GetWallet", &GetWalletRequest{UserID: "alice"}, OK, "100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w Wallet
+			code, msg := call(t, client, base, tt.method, tt.req, &w)
+			if code != tt.wantCode {
+				t.Fatalf("code = %d (%s), want %d", code, msg, tt.wantCode)
+			}
+			if code == OK && w.Balance != tt.wantBalance {
+				t.Errorf("balance = %q, want %q", w.Balance, tt.wantBalance)
+			}
+		})
+	}
+
+	var transfer TransferResponse
+	if code, msg := call(t, client, base, "Transfer", &TransferRequest{FromUserID: "alice", ToUserID: "bob", Amount: "40.3"}, &transfer); code != OK {
+		t.Fatalf("Transfer = %d %s", code, msg)
+	}
+	if transfer.Held || transfer.Sender == nil || transfer.Sender.Balance != "59.7" {
+		t.Errorf("Transfer reply = %+v", transfer)
+	}
+
+	var list ListTransactionsResponse
+	if code, msg := call(t, client, base, "ListTransactions", &ListTransactionsRequest{UserID: "bob"}, &list); code != OK {
+		t.Fatalf("ListTransactions = %d %s", code, msg)
+	}
+	if len(list.Transactions) != 1 || list.Transactions[0].Amount != "40.3" || list.Transactions[0].FromUserID != "alice" {
+		t.Errorf("transactions = %+v", list.Transactions)
+	}
+
+	if code, _ := call(t, client, base, "Freeze", &GetWalletRequest{UserID: "alice"}, &user); code != Unimplemented {
+		t.Errorf("unknown method code = %d, want %d", code, Unimplemented)
+	}
+}
+
+func TestMessages_RoundTrip(t *testing.T) {
+	tx := &Transaction{
+		ID: "tx1", FromUserID: "alice", ToUserID: "bob", Amount: "0.000001", Currency: "USD",
+		Type: "transfer", Timestamp: 1700000000, ParentTxID: "tx0",
+		Metadata: map[string]string{"order": "42", "channel": "api"}, Status: "pending", AmountDisplay: "0.00",
+	}
+	var got ListTransactionsResponse
+	if err := got.Unmarshal((&ListTransactionsResponse{Transactions: []*Transaction{tx}}).Marshal()); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(got.Transactions) != 1 || !reflect.DeepEqual(got.Transactions[0], tx) {
+		t.Errorf("round trip = %+v, want %+v", got.Transactions, tx)
+	}
+
+	w := &Wallet{UserID: "alice", Currency: "USD", Balance: "12.50", ForeignBalances: map[string]string{"EUR": "3"}, AutoSettle: true, BalanceDisplay: "12.5"}
+	var gotWallet Wallet
+	if err := gotWallet.Unmarshal(w.Marshal()); err != nil || !reflect.DeepEqual(&gotWallet, w) {
+		t.Errorf("wallet round trip = %+v, %v", gotWallet, err)
+	}
+
+	if err := gotWallet.Unmarshal([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Error("Unmarshal(truncated) error = nil")
+	}
+}
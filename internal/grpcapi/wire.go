@@ -0,0 +1,199 @@
+// internal/grpcapi/wire.go
+package grpcapi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Protobuf wire types used by wallet.proto
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errMalformed is returned for undecodable protobuf input
+var errMalformed = errors.New("malformed protobuf message")
+
+// encoder appends protobuf fields. Scalar fields holding their zero value are omitted,
+// as proto3 requires.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(num, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(num)<<3|uint64(wireType))
+}
+
+func (e *encoder) string(num int, s string) {
+	if s == "" {
+		return
+	}
+	e.bytes(num, []byte(s))
+}
+
+func (e *encoder) bytes(num int, b []byte) {
+	e.tag(num, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) int64(num int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(num, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+func (e *encoder) bool(num int, v bool) {
+	if !v {
+		return
+	}
+	e.tag(num, wireVarint)
+	e.buf = append(e.buf, 1)
+}
+
+// message encodes a nested message; nil messages are omitted
+func (e *encoder) message(num int, m interface{ Marshal() []byte }, present bool) {
+	if present {
+		e.bytes(num, m.Marshal())
+	}
+}
+
+// stringMap encodes a map<string, string> as entries sorted by key, so output is stable
+func (e *encoder) stringMap(num int, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry encoder
+		entry.string(1, k)
+		entry.string(2, m[k])
+		e.bytes(num, entry.buf)
+	}
+}
+
+// field is one decoded protobuf field. Varint fields carry their value in v, bytes
+// fields in data.
+type field struct {
+	num  int
+	v    uint64
+	data []byte
+}
+
+func (f field) string() string { return string(f.data) }
+func (f field) int64() int64   { return int64(f.v) }
+func (f field) bool() bool     { return f.v != 0 }
+
+// decode calls fn for every field of a message, skipping fixed-width fields no wallet
+// message uses
+func decode(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errMalformed
+		}
+		b = b[n:]
+		f := field{num: int(key >> 3)}
+		switch key & 7 {
+		case wireVarint:
+			if f.v, n = binary.Uvarint(b); n <= 0 {
+				return errMalformed
+			}
+			b = b[n:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return errMalformed
+			}
+			f.data, b = b[n:n+int(size)], b[n+int(size):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errMalformed
+			}
+			b = b[8:]
+			continue
+		case wireFixed32:
+			if len(b) < 4 {
+				return errMalformed
+			}
+			b = b[4:]
+			continue
+		default:
+			return fmt.Errorf("%w: wire type %d", errMalformed, key&7)
+		}
+		if f.num == 0 {
+			return errMalformed
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeMapEntry adds one map<string, string> entry to m
+func decodeMapEntry(m *map[string]string, data []byte) error {
+	var key, value string
+	err := decode(data, func(f field) error {
+		switch f.num {
+		case 1:
+			key = f.string()
+		case 2:
+			value = f.string()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[key] = value
+	return nil
+}
+
+// maxMessageSize bounds a single gRPC message
+const maxMessageSize = 4 << 20
+
+// readFrame reads one length-prefixed gRPC message. Compressed messages are rejected;
+// the server never advertises an encoding.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != 0 {
+		return nil, errCompressed
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxMessageSize {
+		return nil, errTooLarge
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// appendFrame appends msg to buf as a length-prefixed, uncompressed gRPC message
+func appendFrame(buf, msg []byte) []byte {
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(msg)))
+	return append(buf, msg...)
+}
+
+var (
+	errCompressed = errors.New("compressed messages are not supported")
+	errTooLarge   = errors.New("message exceeds the size limit")
+)
@@ -0,0 +1,192 @@
+// internal/moneymath/moneymath.go
+package moneymath
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for money calculations
+var (
+	ErrInvalidWeights = errors.New("weights must be non-negative with a positive total")
+	ErrInvalidCount   = errors.New("split count must be positive")
+	ErrInvalidTiers   = errors.New("tiers must have ascending bounds and only the last may be unbounded")
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Rounding selects how a value is rounded to a number of decimal places
+type Rounding int
+
+const (
+	// HalfUp rounds halves away from zero, the usual commercial rounding
+	HalfUp Rounding = iota
+	// HalfEven rounds halves to the nearest even digit (banker's rounding)
+	HalfEven
+	// Down truncates toward zero
+	Down
+	// Up rounds away from zero
+	Up
+)
+
+// Round rounds d to places decimals using mode
+func Round(d decimal.Decimal, places int32, mode Rounding) decimal.Decimal {
+	switch mode {
+	case HalfEven:
+		return d.RoundBank(places)
+	case Down:
+		return d.Truncate(places)
+	case Up:
+		return d.RoundUp(places)
+	}
+	return d.Round(places)
+}
+
+// Percent returns pct percent of amount rounded to places with mode
+func Percent(amount, pct decimal.Decimal, places int32, mode Rounding) decimal.Decimal {
+	return Round(amount.Mul(pct).Div(hundred), places, mode)
+}
+
+// Allocate splits total in proportion to weights using the largest-remainder method.
+// Each share is truncated to places decimals and the leftover units go to the shares
+// with the largest truncated remainders, earlier weights first on ties, so the result
+// always sums to exactly total. Negative totals are allocated by magnitude.
+func Allocate(total decimal.Decimal, weights []decimal.Decimal, places int32) ([]decimal.Decimal, error) {
+	sum := decimal.Zero
+	for _, w := range weights {
+		if w.IsNegative() {
+			return nil, ErrInvalidWeights
+		}
+		sum = sum.Add(w)
+	}
+	if !sum.IsPositive() {
+		return nil, ErrInvalidWeights
+	}
+
+	negative := total.IsNegative()
+	magnitude := total.Abs().Truncate(places)
+
+	shares := make([]decimal.Decimal, len(weights))
+	remainders := make([]decimal.Decimal, len(weights))
+	allocated := decimal.Zero
+	for i, w := range weights {
+		exact := magnitude.Mul(w).Div(sum)
+		shares[i] = exact.Truncate(places)
+		remainders[i] = exact.Sub(shares[i])
+		allocated = allocated.Add(shares[i])
+	}
+
+	// Hand out leftover units by descending remainder; indexes break ties
+	order := make([]int, 0, len(weights))
+	for i, w := range weights {
+		if w.IsPositive() {
+			order = append(order, i)
+		}
+	}
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && remainders[order[j]].GreaterThan(remainders[order[j-1]]); j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+
+	unit := decimal.New(1, -places)
+	leftover := magnitude.Sub(allocated)
+	for k := 0; leftover.GreaterThanOrEqual(unit); k = (k + 1) % len(order) {
+		shares[order[k]] = shares[order[k]].Add(unit)
+		leftover = leftover.Sub(unit)
+	}
+
+	if negative {
+		for i := range shares {
+			shares[i] = shares[i].Neg()
+		}
+	}
+	return shares, nil
+}
+
+// Split divides total into n equal shares that sum exactly to total; indivisible units
+// go to the first shares
+func Split(total decimal.Decimal, n int, places int32) ([]decimal.Decimal, error) {
+	if n <= 0 {
+		return nil, ErrInvalidCount
+	}
+	weights := make([]decimal.Decimal, n)
+	for i := range weights {
+		weights[i] = decimal.NewFromInt(1)
+	}
+	return Allocate(total, weights, places)
+}
+
+// Tier is one band of a tiered rate schedule. The band covers amounts from the previous
+// tier's UpTo to this UpTo; a zero UpTo on the last tier leaves it unbounded. Rate is a
+// percentage applied to the part of the amount inside the band.
+type Tier struct {
+	UpTo decimal.Decimal
+	Rate decimal.Decimal
+}
+
+// TierCharge itemises the part of a tiered computation falling in one band
+type TierCharge struct {
+	From   decimal.Decimal
+	To     decimal.Decimal // zero when unbounded
+	Base   decimal.Decimal // portion of the amount inside the band
+	Rate   decimal.Decimal
+	Charge decimal.Decimal // unrounded Base * Rate / 100
+}
+
+// Tiered applies a marginal tiered rate schedule to amount, like tax brackets or
+// balance-tiered interest. Band charges are kept exact and only the total is rounded,
+// so itemised lines may not sum to the rounded total by more than one unit.
+func Tiered(amount decimal.Decimal, tiers []Tier, places int32, mode Rounding) (decimal.Decimal, []TierCharge, error) {
+	if err := checkTiers(tiers); err != nil {
+		return decimal.Zero, nil, err
+	}
+
+	var lines []TierCharge
+	total := decimal.Zero
+	lower := decimal.Zero
+	for _, t := range tiers {
+		if !amount.GreaterThan(lower) {
+			break
+		}
+		upper := amount
+		if !t.UpTo.IsZero() && t.UpTo.LessThan(amount) {
+			upper = t.UpTo
+		}
+		base := upper.Sub(lower)
+		charge := base.Mul(t.Rate).Div(hundred)
+		lines = append(lines, TierCharge{From: lower, To: t.UpTo, Base: base, Rate: t.Rate, Charge: charge})
+		total = total.Add(charge)
+		if t.UpTo.IsZero() {
+			break
+		}
+		lower = t.UpTo
+	}
+
+	return Round(total, places, mode), lines, nil
+}
+
+// checkTiers validates that tier bounds ascend and only the last tier is unbounded
+func checkTiers(tiers []Tier) error {
+	if len(tiers) == 0 {
+		return ErrInvalidTiers
+	}
+	prev := decimal.Zero
+	for i, t := range tiers {
+		if t.Rate.IsNegative() {
+			return ErrInvalidTiers
+		}
+		if t.UpTo.IsZero() {
+			if i != len(tiers)-1 {
+				return ErrInvalidTiers
+			}
+			continue
+		}
+		if !t.UpTo.GreaterThan(prev) {
+			return ErrInvalidTiers
+		}
+		prev = t.UpTo
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// internal/moneymath/moneymath_test.go
+package moneymath
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// d parses a decimal literal
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// ds parses decimal literals
+func ds(values ...string) []decimal.Decimal {
+	out := make([]decimal.Decimal, len(values))
+	for i, v := range values {
+		out[i] = d(v)
+	}
+	return out
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   string
+		weights []string
+		places  int32
+		want    []string
+	}{
+		{"thirds", "100", []string{"1", "1", "1"}, 2, []string{"33.34", "33.33", "33.33"}},
+		{"largest remainder wins", "10", []string{"1", "2", "4"}, 2, []string{"1.43", "2.86", "5.71"}},
+		{"zero weight gets nothing", "1", []string{"0", "1", "1"}, 2, []string{"0", "0.5", "0.5"}},
+		{"whole units", "7", []string{"1", "1", "1"}, 0, []string{"3", "2", "2"}},
+		{"negative total", "-100", []string{"1", "1", "1"}, 2, []string{"-33.34", "-33.33", "-33.33"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Allocate(d(tt.total), ds(tt.weights...), tt.places)
+			if err != nil {
+				t.Fatalf("Allocate() error = %v", err)
+			}
+			sum := decimal.Zero
+			for i, share := range got {
+				sum = sum.Add(share)
+				if !share.Equal(d(tt.want[i])) {
+					t.Errorf("share %d = %s, want %s", i, share, tt.want[i])
+				}
+			}
+			if !sum.Equal(d(tt.total)) {
+				t.Errorf("sum = %s, want %s", sum, tt.total)
+			}
+		})
+	}
+
+	if _, err := Allocate(d("1"), ds("0", "0"), 2); err != ErrInvalidWeights {
+		t.Errorf("zero weights error = %v, want %v", err, ErrInvalidWeights)
+	}
+	if _, err := Split(d("1"), 0, 2); err != ErrInvalidCount {
+		t.Errorf("Split(0) error = %v, want %v", err, ErrInvalidCount)
+	}
+}
+
+func TestPercentAndRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount string
+		pct    string
+		mode   Rounding
+		want   string
+	}{
+		{"half up", "10.05", "50", HalfUp, "5.03"},
+		{"half even", "10.05", "50", HalfEven, "5.02"},
+		{"down", "19.99", "10", Down, "1.99"},
+		{"up", "19.91", "10", Up, "2"},
+		{"negative half up", "-10.05", "50", HalfUp, "-5.03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percent(d(tt.amount), d(tt.pct), 2, tt.mode); !got.Equal(d(tt.want)) {
+				t.Errorf("Percent() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTiered(t *testing.T) {
+	tiers := []Tier{
+		{UpTo: d("1000"), Rate: d("1")},
+		{UpTo: d("5000"), Rate: d("2")},
+		{Rate: d("3")},
+	}
+
+	tests := []struct {
+		amount    string
+		want      string
+		wantLines int
+	}{
+		{"500", "5", 1},
+		{"1000", "10", 1},
+		{"3000", "50", 2},
+		{"10000", "240", 3},
+		{"0", "0", 0},
+	}
+
+	for _, tt := range tests {
+		got, lines, err := Tiered(d(tt.amount), tiers, 2, HalfUp)
+		if err != nil {
+			t.Fatalf("Tiered(%s) error = %v", tt.amount, err)
+		}
+		if !got.Equal(d(tt.want)) || len(lines) != tt.wantLines {
+			t.Errorf("Tiered(%s) = %s with %d lines, want %s with %d", tt.amount, got, len(lines), tt.want, tt.wantLines)
+		}
+	}
+
+	invalid := [][]Tier{
+		nil,
+		{{UpTo: d("100"), Rate: d("1")}, {UpTo: d("50"), Rate: d("1")}},
+		{{Rate: d("1")}, {UpTo: d("50"), Rate: d("1")}},
+		{{UpTo: d("100"), Rate: d("-1")}},
+	}
+	for i, tiers := range invalid {
+		if _, _, err := Tiered(d("10"), tiers, 2, HalfUp); err != ErrInvalidTiers {
+			t.Errorf("invalid schedule %d error = %v, want %v", i, err, ErrInvalidTiers)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// internal/pgstore/clienttx.go
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"wallet-app/internal/wallet"
+)
+
+// Store is a wallet.ClientTxRegistry shared by the service instances using its
+// database. Reservations change state in single conditional statements, so two
+// instances never claim the same ID.
+var _ wallet.ClientTxRegistry = (*Store)(nil)
+
+// ReserveClientTxID stores r unless its ID is pending, used, or reserved by another
+// user until after r.ReservedAt, and returns the reservation then stored for the ID
+func (s *Store) ReserveClientTxID(r wallet.ClientTxReservation) (wallet.ClientTxReservation, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO wallet_client_tx_ids (id, user_id, status, reserved_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET user_id = EXCLUDED.user_id, reserved_at = EXCLUDED.reserved_at, expires_at = EXCLUDED.expires_at
+		WHERE wallet_client_tx_ids.status = 'reserved'
+			AND (wallet_client_tx_ids.user_id = EXCLUDED.user_id OR wallet_client_tx_ids.expires_at <= EXCLUDED.reserved_at)`,
+		r.ID, r.UserID, string(wallet.ClientTxReserved), r.ReservedAt, r.ExpiresAt)
+	if err != nil {
+		return wallet.ClientTxReservation{}, err
+	}
+	return loadClientTxID(ctx, s.db, r.ID)
+}
+
+// ClaimClientTxID marks userID's reservation of id pending if it is reserved and
+// unexpired at now
+func (s *Store) ClaimClientTxID(id, userID string, now int64) (wallet.ClientTxReservation, bool, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `UPDATE wallet_client_tx_ids SET status = 'pending'
+		WHERE id = $1 AND user_id = $2 AND status = 'reserved' AND expires_at > $3`, id, userID, now)
+	if err != nil {
+		return wallet.ClientTxReservation{}, false, err
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return wallet.ClientTxReservation{}, false, err
+	}
+	r, err := loadClientTxID(ctx, s.db, id)
+	return r, claimed == 1, err
+}
+
+// FinishClientTxID marks the pending reservation of id used by transactionID, or
+// reserved again when transactionID is empty
+func (s *Store) FinishClientTxID(id, transactionID string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	status := wallet.ClientTxUsed
+	if transactionID == "" {
+		status = wallet.ClientTxReserved
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE wallet_client_tx_ids SET status = $2, transaction_id = $3
+		WHERE id = $1 AND status = 'pending'`, id, string(status), transactionID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return wallet.ErrClientTxIDNotReserved
+	}
+	return nil
+}
+
+// ExpireClientTxIDs deletes reservations that expired unused by now
+func (s *Store) ExpireClientTxIDs(now int64) (int, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM wallet_client_tx_ids WHERE status = 'reserved' AND expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// loadClientTxID reads the reservation of id
+func loadClientTxID(ctx context.Context, db *sql.DB, id string) (wallet.ClientTxReservation, error) {
+	var r wallet.ClientTxReservation
+	var status string
+	err := db.QueryRowContext(ctx, `SELECT id, user_id, status, reserved_at, expires_at, transaction_id
+		FROM wallet_client_tx_ids WHERE id = $1`, id).
+		Scan(&r.ID, &r.UserID, &status, &r.ReservedAt, &r.ExpiresAt, &r.TransactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r, wallet.ErrClientTxIDNotReserved
+	}
+	r.Status = wallet.ClientTxStatus(status)
+	return r, err
+}
@@ -0,0 +1,397 @@
+// internal/pgstore/pgstore.go
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// DefaultTimeout bounds each store call when no timeout is configured
+const DefaultTimeout = 5 * time.Second
+
+// migrationLockID serializes migrations between processes sharing a database
+const migrationLockID = 7_461_203
+
+// migrations are applied in order; version i+1 is migrations[i]. Never edit an applied
+// migration, append a new one instead.
+var migrations = []string{
+	`CREATE TABLE wallet_users (
+		id    TEXT PRIMARY KEY,
+		name  TEXT NOT NULL,
+		email TEXT NOT NULL
+	);
+	CREATE TABLE wallet_wallets (
+		user_id          TEXT PRIMARY KEY,
+		currency         TEXT NOT NULL,
+		balance          NUMERIC NOT NULL,
+		foreign_balances JSONB NOT NULL DEFAULT '{}',
+		auto_settle      BOOLEAN NOT NULL DEFAULT FALSE
+	);
+	CREATE TABLE wallet_transactions (
+		seq          BIGSERIAL PRIMARY KEY,
+		id           TEXT NOT NULL UNIQUE,
+		type         TEXT NOT NULL,
+		from_user_id TEXT NOT NULL,
+		to_user_id   TEXT NOT NULL,
+		amount       NUMERIC NOT NULL,
+		currency     TEXT NOT NULL,
+		created_at   BIGINT NOT NULL,
+		body         JSONB NOT NULL
+	)`,
+	`CREATE INDEX wallet_transactions_from_idx ON wallet_transactions (from_user_id, seq);
+	CREATE INDEX wallet_transactions_to_idx ON wallet_transactions (to_user_id, seq);
+	CREATE INDEX wallet_users_email_idx ON wallet_users (email)`,
+	`CREATE TABLE wallet_client_tx_ids (
+		id             TEXT PRIMARY KEY,
+		user_id        TEXT NOT NULL,
+		status         TEXT NOT NULL,
+		reserved_at    BIGINT NOT NULL,
+		expires_at     BIGINT NOT NULL,
+		transaction_id TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX wallet_client_tx_ids_expiry_idx ON wallet_client_tx_ids (expires_at) WHERE status = 'reserved'`,
+}
+
+// Store is a wallet.Store kept in PostgreSQL. Transactions commit atomically with the
+// wallets they touched: the wallet rows are locked in ID order, the transaction is
+// inserted and the balances updated in one database transaction.
+//
+// Store works with any database/sql PostgreSQL driver; the caller opens the *sql.DB
+// and keeps ownership of it.
+type Store struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithTimeout bounds each store call
+func WithTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.timeout = d
+	}
+}
+
+// Open migrates the schema in db to the latest version and returns a store using it
+func Open(db *sql.DB, opts ...Option) (*Store, error) {
+	s := &Store{db: db, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.Migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Migrate applies pending schema migrations. Concurrent callers wait on an advisory
+// lock, so only one applies each migration.
+func (s *Store) Migrate() error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS wallet_schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+			return err
+		}
+
+		var current int
+		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM wallet_schema_migrations`).Scan(&current); err != nil {
+			return err
+		}
+		if current > len(migrations) {
+			return fmt.Errorf("database schema version %d is newer than this build's %d", current, len(migrations))
+		}
+		for version := current + 1; version <= len(migrations); version++ {
+			if _, err := tx.ExecContext(ctx, migrations[version-1]); err != nil {
+				return fmt.Errorf("migration %d: %w", version, err)
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO wallet_schema_migrations (version) VALUES ($1)`, version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveUser stores the latest version of a user
+func (s *Store) SaveUser(user wallet.User) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO wallet_users (id, name, email) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email`,
+		user.ID, user.Name, user.Email)
+	return err
+}
+
+// SaveWallet stores the latest version of a wallet
+func (s *Store) SaveWallet(w wallet.WalletSnapshot) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return saveWallet(ctx, tx, w)
+	})
+}
+
+// AppendTransaction adds a transaction to the log
+func (s *Store) AppendTransaction(t wallet.Transaction) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return insertTransaction(ctx, tx, t)
+	})
+}
+
+// CommitTransaction inserts t and updates the wallets it touched in one database
+// transaction. The wallet rows are locked in ID order first, so concurrent writers to
+// the same wallets serialize instead of deadlocking.
+func (s *Store) CommitTransaction(t wallet.Transaction, wallets []wallet.WalletSnapshot) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	wallets = append([]wallet.WalletSnapshot(nil), wallets...)
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].UserID < wallets[j].UserID })
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockWallets(ctx, tx, wallets); err != nil {
+			return err
+		}
+		if err := insertTransaction(ctx, tx, t); err != nil {
+			return err
+		}
+		for _, w := range wallets {
+			if err := saveWallet(ctx, tx, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CommitTransactions is CommitTransaction for a group: every wallet the group touches
+// is locked once, in ID order, and the transactions are inserted in order with their
+// wallets in one database transaction
+func (s *Store) CommitTransactions(commits []wallet.TransactionCommit) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var wallets []wallet.WalletSnapshot
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		for _, w := range c.Wallets {
+			if !seen[w.UserID] {
+				seen[w.UserID] = true
+				wallets = append(wallets, w)
+			}
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].UserID < wallets[j].UserID })
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockWallets(ctx, tx, wallets); err != nil {
+			return err
+		}
+		for _, c := range commits {
+			if err := insertTransaction(ctx, tx, c.Transaction); err != nil {
+				return err
+			}
+			for _, w := range c.Wallets {
+				if err := saveWallet(ctx, tx, w); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Load returns the stored users, wallets and transaction log
+func (s *Store) Load() (*wallet.StoreState, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	state := &wallet.StoreState{}
+	// One snapshot for all three reads
+	readOnly := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := s.inTxOpts(ctx, readOnly, func(tx *sql.Tx) error {
+		var err error
+		if state.Users, err = loadUsers(ctx, tx); err != nil {
+			return err
+		}
+		if state.Wallets, err = loadWallets(ctx, tx); err != nil {
+			return err
+		}
+		state.Transactions, err = loadTransactions(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// context returns the context bounding one store call
+func (s *Store) context() (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+// inTx runs fn in a database transaction, committing if it succeeds
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return s.inTxOpts(ctx, nil, fn)
+}
+
+// inTxOpts runs fn in a database transaction with the given options
+func (s *Store) inTxOpts(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// lockWallets locks the rows of wallets, which must be sorted by user ID
+func lockWallets(ctx context.Context, tx *sql.Tx, wallets []wallet.WalletSnapshot) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+	placeholders := make([]string, len(wallets))
+	args := make([]any, len(wallets))
+	for i, w := range wallets {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = w.UserID
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM wallet_wallets WHERE user_id IN (`+
+		strings.Join(placeholders, ", ")+`) ORDER BY user_id FOR UPDATE`, args...)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// saveWallet upserts one wallet row
+func saveWallet(ctx context.Context, tx *sql.Tx, w wallet.WalletSnapshot) error {
+	foreign, err := json.Marshal(w.Foreign)
+	if err != nil {
+		return err
+	}
+	if w.Foreign == nil {
+		foreign = []byte("{}")
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO wallet_wallets (user_id, currency, balance, foreign_balances, auto_settle)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET currency = EXCLUDED.currency, balance = EXCLUDED.balance,
+			foreign_balances = EXCLUDED.foreign_balances, auto_settle = EXCLUDED.auto_settle`,
+		w.UserID, w.Currency, w.Balance.String(), string(foreign), w.AutoSettle)
+	return err
+}
+
+// insertTransaction appends one transaction row. The queryable columns duplicate parts
+// of body, which holds the full transaction.
+func insertTransaction(ctx context.Context, tx *sql.Tx, t wallet.Transaction) error {
+	body, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO wallet_transactions
+		(id, type, from_user_id, to_user_id, amount, currency, created_at, body)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		t.ID, string(t.Type), t.FromUserID, t.ToUserID, t.Amount.String(), t.Currency, t.Timestamp, string(body))
+	return err
+}
+
+// loadUsers reads every user
+func loadUsers(ctx context.Context, tx *sql.Tx) ([]wallet.User, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name, email FROM wallet_users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []wallet.User
+	for rows.Next() {
+		var u wallet.User
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// loadWallets reads every wallet
+func loadWallets(ctx context.Context, tx *sql.Tx) ([]wallet.WalletSnapshot, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT user_id, currency, balance, foreign_balances, auto_settle
+		FROM wallet_wallets ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []wallet.WalletSnapshot
+	for rows.Next() {
+		var (
+			w       wallet.WalletSnapshot
+			balance string
+			foreign []byte
+		)
+		if err := rows.Scan(&w.UserID, &w.Currency, &balance, &foreign, &w.AutoSettle); err != nil {
+			return nil, err
+		}
+		if w.Balance, err = decimal.NewFromString(balance); err != nil {
+			return nil, fmt.Errorf("wallet %s balance: %w", w.UserID, err)
+		}
+		if err := json.Unmarshal(foreign, &w.Foreign); err != nil {
+			return nil, fmt.Errorf("wallet %s foreign balances: %w", w.UserID, err)
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, rows.Err()
+}
+
+// loadTransactions reads the log in insertion order
+func loadTransactions(ctx context.Context, tx *sql.Tx) ([]wallet.Transaction, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT body FROM wallet_transactions ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []wallet.Transaction
+	for rows.Next() {
+		var body []byte
+		if err := rows.Scan(&body); err != nil {
+			return nil, err
+		}
+		var t wallet.Transaction
+		if err := json.Unmarshal(body, &t); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
@@ -0,0 +1,421 @@
+// internal/pgstore/pgstore_test.go
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// fakeDB is a database/sql connector that understands just the statements the store
+// issues, keeping tables in memory. Writes inside a transaction apply on commit.
+type fakeDB struct {
+	mu      sync.Mutex
+	version int64
+	users   map[string][]driver.Value
+	wallets map[string][]driver.Value
+	bodies  []driver.Value
+	txIDs   map[string][]driver.Value // client transaction ID reservations by ID
+	log     []string
+	failOn  string
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{users: make(map[string][]driver.Value), wallets: make(map[string][]driver.Value), txIDs: make(map[string][]driver.Value)}
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+// statements returns the logged statements, each cut to its first line
+func (db *fakeDB) statements() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.log...)
+}
+
+type fakeConn struct {
+	db      *fakeDB
+	pending []func()
+	inTx    bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.record("BEGIN")
+	c.inTx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.record("COMMIT")
+	c.db.mu.Lock()
+	for _, apply := range c.pending {
+		apply()
+	}
+	c.db.mu.Unlock()
+	c.pending, c.inTx = nil, false
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.record("ROLLBACK")
+	c.pending, c.inTx = nil, false
+	return nil
+}
+
+func (c *fakeConn) record(stmt string) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.log = append(c.db.log, stmt)
+}
+
+// write applies a mutation now or, inside a transaction, on commit
+func (c *fakeConn) write(apply func()) {
+	if c.inTx {
+		c.pending = append(c.pending, apply)
+		return
+	}
+	c.db.mu.Lock()
+	apply()
+	c.db.mu.Unlock()
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	q := strings.TrimSpace(s.query)
+	s.conn.record(strings.SplitN(q, "\n", 2)[0])
+	if s.conn.db.failOn != "" && strings.Contains(q, s.conn.db.failOn) {
+		return nil, errors.New("injected failure")
+	}
+	db := s.conn.db
+	switch {
+	case strings.HasPrefix(q, "INSERT INTO wallet_schema_migrations"):
+		s.conn.write(func() { db.version = args[0].(int64) })
+	case strings.HasPrefix(q, "INSERT INTO wallet_users"):
+		s.conn.write(func() { db.users[args[0].(string)] = args })
+	case strings.HasPrefix(q, "INSERT INTO wallet_wallets"):
+		s.conn.write(func() { db.wallets[args[0].(string)] = args })
+	case strings.HasPrefix(q, "INSERT INTO wallet_transactions"):
+		s.conn.write(func() { db.bodies = append(db.bodies, args[7]) })
+	case strings.Contains(q, "wallet_client_tx_ids"):
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		return driver.RowsAffected(db.execClientTxID(q, args)), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// execClientTxID applies the store's conditional statements on reservations, columns
+// id, user_id, status, reserved_at, expires_at and transaction_id. Caller must hold
+// db.mu.
+func (db *fakeDB) execClientTxID(q string, args []driver.Value) int64 {
+	switch {
+	case strings.HasPrefix(q, "INSERT"):
+		cur, ok := db.txIDs[args[0].(string)]
+		if ok && (cur[2] != "reserved" || (cur[1] != args[1] && cur[4].(int64) > args[3].(int64))) {
+			return 0
+		}
+		db.txIDs[args[0].(string)] = []driver.Value{args[0], args[1], args[2], args[3], args[4], ""}
+	case strings.Contains(q, "SET status = 'pending'"):
+		cur, ok := db.txIDs[args[0].(string)]
+		if !ok || cur[1] != args[1] || cur[2] != "reserved" || cur[4].(int64) <= args[2].(int64) {
+			return 0
+		}
+		cur[2] = "pending"
+	case strings.HasPrefix(q, "UPDATE"):
+		cur, ok := db.txIDs[args[0].(string)]
+		if !ok || cur[2] != "pending" {
+			return 0
+		}
+		cur[2], cur[5] = args[1], args[2]
+	case strings.HasPrefix(q, "DELETE"):
+		var n int64
+		for id, cur := range db.txIDs {
+			if cur[2] == "reserved" && cur[4].(int64) <= args[0].(int64) {
+				delete(db.txIDs, id)
+				n++
+			}
+		}
+		return n
+	}
+	return 1
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	q := strings.TrimSpace(s.query)
+	s.conn.record(strings.SplitN(q, "\n", 2)[0])
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(q, "SELECT COALESCE(MAX(version)"):
+		return &fakeRows{rows: [][]driver.Value{{db.version}}}, nil
+	case strings.HasPrefix(q, "SELECT id, name, email"):
+		return &fakeRows{rows: sortedRows(db.users)}, nil
+	case strings.HasPrefix(q, "SELECT user_id, currency"):
+		return &fakeRows{rows: sortedRows(db.wallets)}, nil
+	case strings.HasPrefix(q, "SELECT id, user_id, status"):
+		if row, ok := db.txIDs[args[0].(string)]; ok {
+			return &fakeRows{rows: [][]driver.Value{row}}, nil
+		}
+		return &fakeRows{}, nil
+	case strings.HasPrefix(q, "SELECT body"):
+		var rows [][]driver.Value
+		for _, body := range db.bodies {
+			rows = append(rows, []driver.Value{body})
+		}
+		return &fakeRows{rows: rows}, nil
+	}
+	return &fakeRows{}, nil
+}
+
+// sortedRows returns table rows ordered by key
+func sortedRows(table map[string][]driver.Value) [][]driver.Value {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rows := make([][]driver.Value, len(keys))
+	for i, k := range keys {
+		rows[i] = table[k]
+	}
+	return rows
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// openFake opens a store over a fresh fake database
+func openFake(t *testing.T) (*Store, *fakeDB) {
+	t.Helper()
+	fake := newFakeDB()
+	db := sql.OpenDB(fake)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := Open(db)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return store, fake
+}
+
+func TestOpen_AppliesMigrationsOnce(t *testing.T) {
+	store, fake := openFake(t)
+	if fake.version != int64(len(migrations)) {
+		t.Fatalf("schema version = %d, want %d", fake.version, len(migrations))
+	}
+	applied := len(fake.statements())
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	for _, stmt := range fake.statements()[applied:] {
+		if strings.HasPrefix(stmt, "CREATE TABLE wallet_") || strings.HasPrefix(stmt, "CREATE INDEX") {
+			t.Errorf("Migrate() re-ran %q", stmt)
+		}
+	}
+
+	fake.version = int64(len(migrations) + 1)
+	if err := store.Migrate(); err == nil {
+		t.Error("Migrate() against a newer schema succeeded")
+	}
+}
+
+func TestCommitTransaction_AtomicWithRowLocks(t *testing.T) {
+	tests := []struct {
+		name       string
+		failOn     string
+		wantEnd    string
+		wantBodies int
+	}{
+		{"commits", "", "COMMIT", 1},
+		{"wallet update fails", "INSERT INTO wallet_wallets", "ROLLBACK", 0},
+		{"insert fails", "INSERT INTO wallet_transactions", "ROLLBACK", 0},
+	}
+	for _, tt := range tests {
+		store, fake := openFake(t)
+		fake.failOn = tt.failOn
+		start := len(fake.statements())
+
+		tx := wallet.Transaction{ID: "tx_1", FromUserID: "bob", ToUserID: "alice", Amount: decimal.NewFromInt(5), Type: wallet.TransactionTransfer}
+		wallets := []wallet.WalletSnapshot{
+			{UserID: "bob", Currency: "USD", Balance: decimal.NewFromInt(5)},
+			{UserID: "alice", Currency: "USD", Balance: decimal.NewFromInt(5)},
+		}
+		err := store.CommitTransaction(tx, wallets)
+		if (err != nil) != (tt.failOn != "") {
+			t.Errorf("%s: CommitTransaction() error = %v", tt.name, err)
+		}
+
+		stmts := fake.statements()[start:]
+		if stmts[0] != "BEGIN" || !strings.Contains(stmts[1], "FOR UPDATE") || stmts[len(stmts)-1] != tt.wantEnd {
+			t.Errorf("%s: statements = %q", tt.name, stmts)
+		}
+		if len(fake.bodies) != tt.wantBodies || len(fake.wallets) != tt.wantBodies*2 {
+			t.Errorf("%s: stored %d transactions and %d wallets", tt.name, len(fake.bodies), len(fake.wallets))
+		}
+	}
+}
+
+func TestStore_WalletServiceSurvivesRestart(t *testing.T) {
+	store, fake := openFake(t)
+
+	ws := wallet.NewWalletService(wallet.WithStore(store))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Transfer("alice", "bob", 30.25, "rent")
+	ws.DepositCurrency("bob", "EUR", decimal.NewFromInt(7), "invoice")
+	if report := ws.CheckHealth(); report.Status != wallet.HealthOK {
+		t.Fatalf("CheckHealth() = %+v", report)
+	}
+
+	// The transfer locked both wallets and committed them with the transaction
+	var locks int
+	for _, stmt := range fake.statements() {
+		if strings.Contains(stmt, "FOR UPDATE") {
+			locks++
+		}
+	}
+	if locks != 3 {
+		t.Errorf("row-lock queries = %d, want one per transaction", locks)
+	}
+
+	reopened, err := wallet.OpenWalletService(store)
+	if err != nil {
+		t.Fatalf("OpenWalletService() error = %v", err)
+	}
+	balances := []struct {
+		userID, currency, want string
+	}{
+		{"alice", wallet.DefaultCurrency, "69.75"},
+		{"bob", wallet.DefaultCurrency, "30.25"},
+		{"bob", "EUR", "7"},
+	}
+	for _, b := range balances {
+		if got, _ := reopened.GetCurrencyBalance(b.userID, b.currency); got.String() != b.want {
+			t.Errorf("%s %s balance = %s, want %s", b.userID, b.currency, got, b.want)
+		}
+	}
+	if history, _ := reopened.GetTransactionHistory("bob"); len(history) != 2 {
+		t.Errorf("bob history = %d entries, want 2", len(history))
+	}
+}
+
+func TestCommitTransactions_OneDatabaseTransaction(t *testing.T) {
+	store, fake := openFake(t)
+	start := len(fake.statements())
+
+	commits := []wallet.TransactionCommit{
+		{
+			Transaction: wallet.Transaction{ID: "tx_1", FromUserID: "bob", ToUserID: "alice", Amount: decimal.NewFromInt(5), Type: wallet.TransactionTransfer},
+			Wallets: []wallet.WalletSnapshot{
+				{UserID: "bob", Currency: "USD", Balance: decimal.NewFromInt(5)},
+				{UserID: "alice", Currency: "USD", Balance: decimal.NewFromInt(5)},
+			},
+		},
+		{
+			Transaction: wallet.Transaction{ID: "tx_2", FromUserID: "carol", ToUserID: "carol", Amount: decimal.NewFromInt(3), Type: wallet.TransactionDeposit},
+			Wallets:     []wallet.WalletSnapshot{{UserID: "carol", Currency: "USD", Balance: decimal.NewFromInt(3)}},
+		},
+	}
+	if err := store.CommitTransactions(commits); err != nil {
+		t.Fatalf("CommitTransactions() error = %v", err)
+	}
+
+	stmts := fake.statements()[start:]
+	var begins, locks int
+	for _, stmt := range stmts {
+		switch {
+		case stmt == "BEGIN":
+			begins++
+		case strings.Contains(stmt, "FOR UPDATE"):
+			locks++
+		}
+	}
+	if begins != 1 || locks != 1 || stmts[len(stmts)-1] != "COMMIT" {
+		t.Errorf("statements = %q, want one transaction locking once", stmts)
+	}
+	if len(fake.bodies) != 2 || len(fake.wallets) != 3 {
+		t.Errorf("stored %d transactions and %d wallets, want 2 and 3", len(fake.bodies), len(fake.wallets))
+	}
+}
+
+func TestStore_ClientTxIDsSharedBetweenInstances(t *testing.T) {
+	store, fake := openFake(t)
+	var instances []*wallet.WalletService
+	for range 2 {
+		ws := wallet.NewWalletService(wallet.WithStore(store))
+		ws.CreateUser("alice", "Alice", "alice@example.com")
+		ws.CreateUser("bob", "Bob", "bob@example.com")
+		ws.Deposit("alice", 100, "seed")
+		instances = append(instances, ws)
+	}
+
+	if _, err := instances[0].ReserveClientTxID("alice", "c-1", 0); err != nil {
+		t.Fatalf("ReserveClientTxID() error = %v", err)
+	}
+	if _, err := instances[1].ReserveClientTxID("bob", "c-1", 0); err != wallet.ErrClientTxIDTaken {
+		t.Errorf("reserving a held ID on another instance error = %v, want %v", err, wallet.ErrClientTxIDTaken)
+	}
+	tx, err := instances[0].TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(30), "rent")
+	if err != nil {
+		t.Fatalf("TransferClientTxID() error = %v", err)
+	}
+	if _, err := instances[1].TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(30), "rent"); err != wallet.ErrClientTxIDUsed {
+		t.Errorf("TransferClientTxID() on another instance error = %v, want %v", err, wallet.ErrClientTxIDUsed)
+	}
+	if row := fake.txIDs["c-1"]; row[2] != "used" || row[5] != tx.ID {
+		t.Errorf("stored reservation = %v, want used by %s", row, tx.ID)
+	}
+
+	instances[1].ReserveClientTxID("bob", "c-2", 0)
+	if n, err := store.ExpireClientTxIDs(time.Now().Add(time.Hour).Unix()); n != 1 || err != nil {
+		t.Errorf("ExpireClientTxIDs() = %d, %v, want 1", n, err)
+	}
+}
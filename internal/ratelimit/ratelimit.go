@@ -0,0 +1,93 @@
+// internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneEvery is how many calls a Limiter serves between sweeps of idle buckets
+const pruneEvery = 1024
+
+// Limiter is a set of token buckets, one per key such as a caller or client address.
+// Each bucket holds up to Burst tokens and refills at Rate tokens per second; a request
+// takes one token.
+type Limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+// bucket is the state of one key
+type bucket struct {
+	tokens float64
+	at     time.Time // when tokens was last brought up to date
+}
+
+// Option configures a Limiter
+type Option func(*Limiter)
+
+// WithClock replaces the wall clock, for tests
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// New creates a limiter allowing rate requests per second per key with bursts of up to
+// burst requests
+func New(rate float64, burst int, opts ...Option) *Limiter {
+	l := &Limiter{rate: rate, burst: float64(max(burst, 1)), now: time.Now, buckets: make(map[string]*bucket)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it returns false and
+// how long until a token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.calls++; l.calls%pruneEvery == 0 {
+		l.prune(now)
+	}
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: l.burst, at: now}
+		l.buckets[key] = b
+	}
+	b.refill(now, l.rate, l.burst)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, time.Duration(1<<63 - 1)
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// refill adds the tokens earned since b.at
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if elapsed := now.Sub(b.at).Seconds(); elapsed > 0 {
+		b.tokens = min(burst, b.tokens+elapsed*rate)
+	}
+	b.at = now
+}
+
+// prune drops buckets that have refilled completely; they behave like new ones. Caller
+// must hold l.mu.
+func (l *Limiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.refill(now, l.rate, l.burst); b.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// internal/ratelimit/ratelimit_test.go
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(2, 3, WithClock(func() time.Time { return now }))
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		want      bool
+		wantRetry time.Duration
+	}{
+		{"burst 1", 0, "a", true, 0},
+		{"burst 2", 0, "a", true, 0},
+		{"burst 3", 0, "a", true, 0},
+		{"empty", 0, "a", false, 500 * time.Millisecond},
+		{"other key unaffected", 0, "b", true, 0},
+		{"half a token", 250 * time.Millisecond, "a", false, 250 * time.Millisecond},
+		{"refilled one", 250 * time.Millisecond, "a", true, 0},
+		{"refill capped at burst", time.Hour, "a", true, 0},
+		{"capped 2", 0, "a", true, 0},
+		{"capped 3", 0, "a", true, 0},
+		{"capped empty", 0, "a", false, 500 * time.Millisecond},
+	}
+	for _, s := range steps {
+		now = now.Add(s.advance)
+		ok, retry := l.Allow(s.key)
+		if ok != s.want || retry != s.wantRetry {
+			t.Errorf("%s: Allow() = %v, %v; want %v, %v", s.name, ok, retry, s.want, s.wantRetry)
+		}
+	}
+}
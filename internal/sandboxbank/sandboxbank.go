@@ -0,0 +1,299 @@
+// internal/sandboxbank/sandboxbank.go
+package sandboxbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+	"wallet-app/internal/wallet"
+)
+
+// Error definitions for the sandbox bank
+var (
+	ErrAccountRejected = errors.New("sandbox: account rejected")
+	ErrNotConnected    = errors.New("sandbox: no callback sink connected")
+)
+
+// Behavior controls how the sandbox treats transfers in one direction
+type Behavior struct {
+	Delay       time.Duration // time from initiation to the first callback
+	Spacing     time.Duration // time between callbacks of one transfer
+	FailureRate float64       // probability a transfer fails, 0..1
+	// Parts splits each settlement into this many partial callbacks; a failing
+	// transfer settles a random number of parts before the failure callback
+	Parts         int
+	DuplicateRate float64 // probability each callback is delivered twice, 0..1
+}
+
+// Config configures a sandbox bank
+type Config struct {
+	Name     string // rail name; defaults to "sandbox"
+	Seed     int64  // seed for failures and duplicates; 0 seeds from the clock
+	Now      func() time.Time
+	Deposits Behavior
+	Payouts  Behavior
+	// RejectedAccounts are refused at initiation, like a closed or invalid account
+	RejectedAccounts []string
+	FailureReason    string // defaults to "declined by sandbox"
+	// Latency is how long Initiate takes to acknowledge, in real time, to exercise
+	// deadline budgets against a slow bank
+	Latency time.Duration
+}
+
+// CallbackSink receives callbacks, normally WalletService.HandleRailCallback
+type CallbackSink func(cb wallet.RailCallback) error
+
+// pendingCallback is a callback waiting for its delivery time
+type pendingCallback struct {
+	due time.Time
+	seq int
+	cb  wallet.RailCallback
+}
+
+// Delivery records one callback the sandbox delivered
+type Delivery struct {
+	Callback wallet.RailCallback
+	Err      error
+}
+
+// Bank is a simulated bank implementing wallet.PaymentRail. Callbacks are queued with
+// due times and delivered by DeliverDue or Flush, so tests control exactly when the
+// wallet hears about settlements.
+type Bank struct {
+	cfg Config
+
+	mu        sync.Mutex
+	rng       *rand.Rand
+	sink      CallbackSink
+	queue     []pendingCallback
+	seq       int
+	requests  map[string]wallet.RailRequest // by reference
+	rejected  map[string]bool
+	delivered []Delivery
+}
+
+// New creates a sandbox bank
+func New(cfg Config) *Bank {
+	if cfg.Name == "" {
+		cfg.Name = "sandbox"
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.FailureReason == "" {
+		cfg.FailureReason = "declined by sandbox"
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	b := &Bank{
+		cfg:      cfg,
+		rng:      rand.New(rand.NewSource(seed)),
+		requests: make(map[string]wallet.RailRequest),
+		rejected: make(map[string]bool, len(cfg.RejectedAccounts)),
+	}
+	for _, account := range cfg.RejectedAccounts {
+		b.rejected[account] = true
+	}
+	return b
+}
+
+// Connect sets where callbacks are delivered
+func (b *Bank) Connect(sink CallbackSink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sink = sink
+}
+
+// SetBehavior replaces the behaviour for one direction, e.g. to start failing payouts
+// midway through a test
+func (b *Bank) SetBehavior(direction wallet.RailDirection, behavior Behavior) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if direction == wallet.RailPayout {
+		b.cfg.Payouts = behavior
+	} else {
+		b.cfg.Deposits = behavior
+	}
+}
+
+// Name returns the rail name
+func (b *Bank) Name() string {
+	return b.cfg.Name
+}
+
+// Initiate accepts a transfer and queues its callbacks. Repeating a request ID returns
+// the original reference without queueing anything, as a real bank's idempotency
+// key would.
+func (b *Bank) Initiate(req wallet.RailRequest) (string, error) {
+	return b.InitiateContext(context.Background(), req)
+}
+
+// InitiateContext is Initiate, giving up when ctx is done before the bank answers. A
+// request abandoned this way is never queued.
+func (b *Bank) InitiateContext(ctx context.Context, req wallet.RailRequest) (string, error) {
+	if b.cfg.Latency > 0 {
+		timer := time.NewTimer(b.cfg.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reference := b.cfg.Name + "-" + req.ID
+	if _, seen := b.requests[reference]; seen {
+		return reference, nil
+	}
+	if b.rejected[req.Account] {
+		return "", ErrAccountRejected
+	}
+	b.requests[reference] = req
+
+	behavior := b.cfg.Deposits
+	if req.Direction == wallet.RailPayout {
+		behavior = b.cfg.Payouts
+	}
+	b.plan(reference, req, behavior)
+	return reference, nil
+}
+
+// plan queues the callbacks for one transfer. Callers hold b.mu.
+func (b *Bank) plan(reference string, req wallet.RailRequest, behavior Behavior) {
+	parts := behavior.Parts
+	if parts < 1 {
+		parts = 1
+	}
+	places := int32(0)
+	if exp := req.Amount.Exponent(); exp < 0 {
+		places = -exp
+	}
+	amounts, err := moneymath.Split(req.Amount, parts, places)
+	if err != nil {
+		amounts = []decimal.Decimal{req.Amount}
+	}
+
+	settleParts := len(amounts)
+	fails := b.rng.Float64() < behavior.FailureRate
+	if fails {
+		settleParts = b.rng.Intn(len(amounts))
+	}
+
+	due := b.cfg.Now().Add(behavior.Delay)
+	var callbacks []wallet.RailCallback
+	for i := 0; i < settleParts; i++ {
+		callbacks = append(callbacks, wallet.RailCallback{
+			ID:        fmt.Sprintf("%s-%d", reference, i+1),
+			Reference: reference,
+			Status:    wallet.RailSettled,
+			Amount:    amounts[i],
+		})
+	}
+	if fails {
+		callbacks = append(callbacks, wallet.RailCallback{
+			ID:        fmt.Sprintf("%s-fail", reference),
+			Reference: reference,
+			Status:    wallet.RailFailed,
+			Reason:    b.cfg.FailureReason,
+		})
+	}
+
+	for _, cb := range callbacks {
+		b.enqueue(due, cb)
+		if b.rng.Float64() < behavior.DuplicateRate {
+			b.enqueue(due, cb)
+		}
+		due = due.Add(behavior.Spacing)
+	}
+}
+
+// enqueue adds a callback to the delivery queue. Callers hold b.mu.
+func (b *Bank) enqueue(due time.Time, cb wallet.RailCallback) {
+	b.seq++
+	b.queue = append(b.queue, pendingCallback{due: due, seq: b.seq, cb: cb})
+}
+
+// DeliverDue delivers every queued callback whose time has come, in due order, and
+// returns how many were delivered
+func (b *Bank) DeliverDue() (int, error) {
+	return b.deliver(b.cfg.Now(), false)
+}
+
+// Flush delivers every queued callback regardless of its due time
+func (b *Bank) Flush() (int, error) {
+	return b.deliver(time.Time{}, true)
+}
+
+// deliver hands due callbacks to the sink outside the bank lock
+func (b *Bank) deliver(now time.Time, all bool) (int, error) {
+	b.mu.Lock()
+	sink := b.sink
+	if sink == nil {
+		b.mu.Unlock()
+		return 0, ErrNotConnected
+	}
+	sort.SliceStable(b.queue, func(i, j int) bool {
+		if !b.queue[i].due.Equal(b.queue[j].due) {
+			return b.queue[i].due.Before(b.queue[j].due)
+		}
+		return b.queue[i].seq < b.queue[j].seq
+	})
+	n := 0
+	for n < len(b.queue) && (all || !b.queue[n].due.After(now)) {
+		n++
+	}
+	due := append([]pendingCallback(nil), b.queue[:n]...)
+	b.queue = b.queue[n:]
+	b.mu.Unlock()
+
+	var firstErr error
+	for _, p := range due {
+		err := sink(p.cb)
+		b.mu.Lock()
+		b.delivered = append(b.delivered, Delivery{Callback: p.cb, Err: err})
+		b.mu.Unlock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return len(due), firstErr
+}
+
+// Pending returns the number of callbacks still queued
+func (b *Bank) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queue)
+}
+
+// Delivered returns every callback delivered so far with the sink's answer
+func (b *Bank) Delivered() []Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Delivery(nil), b.delivered...)
+}
+
+// Request returns the transfer request behind a reference
+func (b *Bank) Request(reference string) (wallet.RailRequest, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	req, ok := b.requests[reference]
+	return req, ok
+}
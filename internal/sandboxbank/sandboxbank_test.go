@@ -0,0 +1,159 @@
+// internal/sandboxbank/sandboxbank_test.go
+package sandboxbank
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/wallet"
+)
+
+// testClock is a manually advanced clock
+type testClock struct{ t time.Time }
+
+func (c *testClock) Now() time.Time          { return c.t }
+func (c *testClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// connect wires a sandbox bank to a fresh wallet service with one funded user
+func connect(t *testing.T, cfg Config) (*Bank, *wallet.WalletService, *testClock) {
+	t.Helper()
+	clock := &testClock{t: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.Now = clock.Now
+	if cfg.Seed == 0 {
+		cfg.Seed = 42
+	}
+
+	bank := New(cfg)
+	ws := wallet.NewWalletService(wallet.WithClock(clock.Now), wallet.WithPaymentRail(bank))
+	bank.Connect(ws.HandleRailCallback)
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	return bank, ws, clock
+}
+
+func TestSandbox_DelayedPartialDepositWithDuplicates(t *testing.T) {
+	bank, ws, clock := connect(t, Config{
+		Deposits: Behavior{Delay: time.Hour, Spacing: 10 * time.Minute, Parts: 4, DuplicateRate: 1},
+	})
+
+	rt, err := ws.DepositViaRail("alice", "DE89-0001", decimal.NewFromInt(100))
+	if err != nil {
+		t.Fatalf("DepositViaRail() error = %v", err)
+	}
+
+	if n, _ := bank.DeliverDue(); n != 0 {
+		t.Errorf("DeliverDue() before the delay = %d, want 0", n)
+	}
+	clock.Advance(time.Hour)
+	if n, _ := bank.DeliverDue(); n != 2 {
+		t.Errorf("DeliverDue() after the delay = %d, want the first part twice", n)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("balance after one part = %s, want 25", b)
+	}
+
+	if n, err := bank.Flush(); n != 6 || err != nil {
+		t.Errorf("Flush() = %d, %v, want 6 deliveries", n, err)
+	}
+	got, _ := ws.GetRailTransfer(rt.ID)
+	if got.Status != wallet.RailSettled || len(got.TransactionIDs) != 4 {
+		t.Errorf("transfer = %+v, want settled over four credits", got)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("balance = %s, want 100 despite duplicate callbacks", b)
+	}
+	if len(bank.Delivered()) != 8 || bank.Pending() != 0 {
+		t.Errorf("delivered %d, pending %d; want 8 and 0", len(bank.Delivered()), bank.Pending())
+	}
+}
+
+func TestSandbox_FailedPayoutAfterPartialSettlement(t *testing.T) {
+	bank, ws, _ := connect(t, Config{Payouts: Behavior{FailureRate: 1, Parts: 3}})
+	ws.Deposit("alice", 200, "seed")
+
+	rt, err := ws.PayoutViaRail("alice", "DE89-0001", decimal.NewFromInt(90))
+	if err != nil {
+		t.Fatalf("PayoutViaRail() error = %v", err)
+	}
+	bank.Flush()
+
+	got, _ := ws.GetRailTransfer(rt.ID)
+	if got.Status != wallet.RailFailed || got.Reason != "declined by sandbox" {
+		t.Fatalf("transfer = %+v, want failed", got)
+	}
+	want := decimal.NewFromInt(200).Sub(got.Settled)
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(want) {
+		t.Errorf("balance = %s, want %s after the unsettled part came back", b, want)
+	}
+}
+
+func TestSandbox_RejectedAccount(t *testing.T) {
+	bank, ws, _ := connect(t, Config{RejectedAccounts: []string{"CLOSED-1"}})
+	ws.Deposit("alice", 50, "seed")
+
+	if _, err := ws.PayoutViaRail("alice", "CLOSED-1", decimal.NewFromInt(20)); !errors.Is(err, ErrAccountRejected) {
+		t.Errorf("PayoutViaRail() error = %v, want %v", err, ErrAccountRejected)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("balance = %s, want 50", b)
+	}
+	if bank.Pending() != 0 {
+		t.Errorf("pending = %d, want 0", bank.Pending())
+	}
+}
+
+func TestSandbox_ChaosKeepsLedgerConsistent(t *testing.T) {
+	chaos := Behavior{Delay: time.Minute, Spacing: time.Minute, FailureRate: 0.3, Parts: 3, DuplicateRate: 0.5}
+	bank, ws, clock := connect(t, Config{Seed: 7, Deposits: chaos, Payouts: chaos})
+	ws.Deposit("alice", 1000, "seed")
+
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			ws.DepositViaRail("alice", "DE89-0001", decimal.RequireFromString("10.01"))
+		} else {
+			ws.PayoutViaRail("alice", "DE89-0001", decimal.RequireFromString("7.5"))
+		}
+		clock.Advance(30 * time.Second)
+		if _, err := bank.DeliverDue(); err != nil {
+			t.Fatalf("DeliverDue() error = %v", err)
+		}
+	}
+	bank.Flush()
+
+	for _, rt := range ws.ListRailTransfers("alice") {
+		if rt.Status == wallet.RailPending {
+			t.Errorf("transfer %s still pending after all callbacks", rt.ID)
+		}
+	}
+	if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+}
+
+func TestSandbox_SlowBankTimesOut(t *testing.T) {
+	bank, ws, _ := connect(t, Config{Latency: time.Second})
+	ws.Deposit("alice", 50, "seed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := ws.PayoutViaRailContext(ctx, "alice", "DE89-0001", decimal.NewFromInt(20))
+	if !errors.Is(err, wallet.ErrTimeout) {
+		t.Fatalf("PayoutViaRailContext() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("timed out after %v, want well before the bank's latency", elapsed)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("balance = %s, want 50", b)
+	}
+	if bank.Pending() != 0 {
+		t.Errorf("pending = %d, want nothing queued for the abandoned request", bank.Pending())
+	}
+}
@@ -0,0 +1,377 @@
+// internal/wallet/adjustmentlevels.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for staff balance adjustments
+var (
+	ErrInvalidAdjustmentLevels = errors.New("adjustment levels need known staff roles with increasing limits")
+	ErrAdjustmentAboveLimits   = errors.New("adjustment is above every authorization level")
+	ErrAdjustmentNotFound      = errors.New("adjustment request not found")
+	ErrAdjustmentNotPending    = errors.New("adjustment request is not awaiting approval")
+	ErrAdjustmentNotAuthorized = errors.New("staff role cannot authorize an adjustment of this size")
+	ErrAdjustmentSelfApproval  = errors.New("an adjustment must be approved by someone other than its maker")
+)
+
+// AdjustmentLevel lets staff holding Role authorize adjustments of up to Limit, in
+// either direction. A zero Limit is unlimited.
+type AdjustmentLevel struct {
+	Role  StaffRole
+	Limit decimal.Decimal
+}
+
+// DefaultAdjustmentLevels lets support post small corrections, needs finance for larger
+// ones and an admin above that
+var DefaultAdjustmentLevels = []AdjustmentLevel{
+	{Role: StaffSupport, Limit: decimal.NewFromInt(100)},
+	{Role: StaffFinance, Limit: decimal.NewFromInt(10000)},
+	{Role: StaffAdmin},
+}
+
+// AdjustmentStatus tracks an adjustment request from its maker to its posting
+type AdjustmentStatus string
+
+const (
+	AdjustmentPending  AdjustmentStatus = "pending"
+	AdjustmentPosted   AdjustmentStatus = "posted"
+	AdjustmentRejected AdjustmentStatus = "rejected"
+)
+
+// AdjustmentRequest is a staff member's correction of a wallet's base-currency balance.
+// One within the maker's own level posts at once; a larger one is escalated to the
+// lowest level covering it and waits for a checker there or above.
+type AdjustmentRequest struct {
+	ID            string
+	UserID        string
+	Amount        decimal.Decimal // signed: negative amounts debit the wallet
+	Reason        AdjustmentReason
+	Note          string
+	MakerID       string
+	MakerRole     StaffRole
+	RequiredRole  StaffRole // lowest role whose level covers Amount
+	Escalated     bool      // Amount is above the maker's own level
+	Status        AdjustmentStatus
+	CheckerID     string
+	Comment       string
+	TransactionID string
+	CreatedAt     int64
+	DecidedAt     int64
+}
+
+// adjustmentDesk holds the authorization levels and adjustment requests
+type adjustmentDesk struct {
+	mu       sync.Mutex
+	levels   []AdjustmentLevel // nil uses DefaultAdjustmentLevels
+	requests map[string]*AdjustmentRequest
+	deciding map[string]bool // requests an approval is posting
+}
+
+// SetAdjustmentLevels replaces the authorization levels, lowest first. Each role may
+// appear once and limits must increase; only the last level may be unlimited.
+func (ws *WalletService) SetAdjustmentLevels(levels []AdjustmentLevel) error {
+	if len(levels) == 0 {
+		return ErrInvalidAdjustmentLevels
+	}
+	seen := make(map[StaffRole]bool, len(levels))
+	for i, l := range levels {
+		switch {
+		case l.Role != StaffSupport && l.Role != StaffFinance && l.Role != StaffAdmin, seen[l.Role]:
+			return ErrInvalidAdjustmentLevels
+		case l.Limit.IsNegative(), l.Limit.IsZero() && i != len(levels)-1:
+			return ErrInvalidAdjustmentLevels
+		case i > 0 && !l.Limit.IsZero() && l.Limit.LessThanOrEqual(levels[i-1].Limit):
+			return ErrInvalidAdjustmentLevels
+		}
+		seen[l.Role] = true
+	}
+
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+	ws.adjustments.levels = append([]AdjustmentLevel(nil), levels...)
+	return nil
+}
+
+// GetAdjustmentLevels returns the authorization levels in force, lowest first
+func (ws *WalletService) GetAdjustmentLevels() []AdjustmentLevel {
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+	return append([]AdjustmentLevel(nil), ws.adjustmentLevels()...)
+}
+
+// RequestAdjustment has makerID correct userID's base-currency balance by amount,
+// negative amounts debiting it. Within the maker's level it posts at once; above it
+// the request is escalated and waits for ApproveAdjustment.
+func (ws *WalletService) RequestAdjustment(makerID, userID string, amount decimal.Decimal, reason AdjustmentReason, note string) (*AdjustmentRequest, error) {
+	return ws.RequestAdjustmentContext(context.Background(), makerID, userID, amount, reason, note)
+}
+
+// RequestAdjustmentContext is RequestAdjustment on behalf of a caller's ctx. The request
+// is audited as request_adjustment; the correction it posts, now or once approved, as
+// adjustment.
+func (ws *WalletService) RequestAdjustmentContext(ctx context.Context, makerID, userID string, amount decimal.Decimal, reason AdjustmentReason, note string) (adj *AdjustmentRequest, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		entry := AuditEntry{Operation: "request_adjustment", UserIDs: []string{userID}}
+		if adj != nil {
+			entry.TransactionID = adj.TransactionID
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		ws.RecordAudit(ctx, entry)
+	}()
+
+	if amount.IsZero() {
+		return nil, ErrInvalidAmount
+	}
+	if !validReasons[reason] {
+		return nil, ErrInvalidReason
+	}
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+	makerRole, err := ws.staffRole(makerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.adjustments.mu.Lock()
+	levels := ws.adjustmentLevels()
+	ws.adjustments.mu.Unlock()
+
+	required := adjustmentLevelFor(levels, amount.Abs())
+	if required < 0 {
+		return nil, ErrAdjustmentAboveLimits
+	}
+	req := &AdjustmentRequest{
+		ID:           ws.newID("adj"),
+		UserID:       userID,
+		Amount:       amount,
+		Reason:       reason,
+		Note:         note,
+		MakerID:      makerID,
+		MakerRole:    makerRole,
+		RequiredRole: levels[required].Role,
+		Escalated:    roleLevel(levels, makerRole) < required,
+		Status:       AdjustmentPending,
+		CreatedAt:    ws.now().Unix(),
+	}
+
+	if !req.Escalated {
+		tx, err := ws.postAdjustmentRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		req.Status, req.TransactionID, req.DecidedAt = AdjustmentPosted, tx.ID, tx.Timestamp
+	}
+
+	ws.adjustments.mu.Lock()
+	if ws.adjustments.requests == nil {
+		ws.adjustments.requests = make(map[string]*AdjustmentRequest)
+	}
+	ws.adjustments.requests[req.ID] = req
+	result := *req
+	ws.adjustments.mu.Unlock()
+
+	ws.metrics.IncCounter("adjustment_requests_total", map[string]string{"status": string(req.Status), "required_role": string(req.RequiredRole)})
+	if req.Escalated {
+		ws.notifier.Notify(Notification{
+			Type:    "adjustment_escalated",
+			Subject: "Balance adjustment awaiting approval",
+			Message: fmt.Sprintf("%s requested a %s adjustment of %s's balance; it needs %s approval", makerID, amount, userID, req.RequiredRole),
+			Data: map[string]string{
+				"request_id":    req.ID,
+				"user_id":       userID,
+				"amount":        amount.String(),
+				"maker_id":      makerID,
+				"required_role": string(req.RequiredRole),
+			},
+			Timestamp: req.CreatedAt,
+		})
+	}
+	return &result, nil
+}
+
+// ApproveAdjustment has checkerID approve and post an escalated adjustment. The checker
+// must not be its maker and must hold a role whose level covers the amount.
+func (ws *WalletService) ApproveAdjustment(requestID, checkerID, comment string) (*AdjustmentRequest, error) {
+	return ws.ApproveAdjustmentContext(context.Background(), requestID, checkerID, comment)
+}
+
+// ApproveAdjustmentContext is ApproveAdjustment on behalf of a caller's ctx
+func (ws *WalletService) ApproveAdjustmentContext(ctx context.Context, requestID, checkerID, comment string) (*AdjustmentRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	req, err := ws.claimAdjustment(requestID, checkerID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := *req
+	pending.CheckerID = checkerID
+	tx, err := ws.postAdjustmentRequest(ctx, &pending)
+
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+	delete(ws.adjustments.deciding, requestID)
+	if err != nil {
+		return nil, err
+	}
+	req.Status, req.CheckerID, req.Comment = AdjustmentPosted, checkerID, comment
+	req.TransactionID, req.DecidedAt = tx.ID, tx.Timestamp
+	ws.metrics.IncCounter("adjustment_approvals_total", map[string]string{"role": string(req.RequiredRole)})
+	result := *req
+	return &result, nil
+}
+
+// RejectAdjustment has checkerID turn down an escalated adjustment. The same rules as
+// for approving it apply.
+func (ws *WalletService) RejectAdjustment(requestID, checkerID, comment string) (*AdjustmentRequest, error) {
+	req, err := ws.claimAdjustment(requestID, checkerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+	delete(ws.adjustments.deciding, requestID)
+	req.Status, req.CheckerID, req.Comment, req.DecidedAt = AdjustmentRejected, checkerID, comment, ws.now().Unix()
+	result := *req
+	return &result, nil
+}
+
+// GetAdjustmentRequest returns an adjustment request
+func (ws *WalletService) GetAdjustmentRequest(requestID string) (*AdjustmentRequest, error) {
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+
+	req, exists := ws.adjustments.requests[requestID]
+	if !exists {
+		return nil, ErrAdjustmentNotFound
+	}
+	result := *req
+	return &result, nil
+}
+
+// ListAdjustmentRequests returns the adjustment requests in status, or all of them when
+// status is empty, oldest first
+func (ws *WalletService) ListAdjustmentRequests(status AdjustmentStatus) []*AdjustmentRequest {
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+
+	var list []*AdjustmentRequest
+	for _, req := range ws.adjustments.requests {
+		if status == "" || req.Status == status {
+			copied := *req
+			list = append(list, &copied)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt != list[j].CreatedAt {
+			return list[i].CreatedAt < list[j].CreatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// claimAdjustment checks checkerID may decide requestID and marks it as being decided,
+// so two checkers cannot both post it
+func (ws *WalletService) claimAdjustment(requestID, checkerID string) (*AdjustmentRequest, error) {
+	checkerRole, err := ws.staffRole(checkerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.adjustments.mu.Lock()
+	defer ws.adjustments.mu.Unlock()
+
+	req, exists := ws.adjustments.requests[requestID]
+	if !exists {
+		return nil, ErrAdjustmentNotFound
+	}
+	if req.Status != AdjustmentPending || ws.adjustments.deciding[requestID] {
+		return nil, ErrAdjustmentNotPending
+	}
+	if checkerID == req.MakerID {
+		return nil, ErrAdjustmentSelfApproval
+	}
+	levels := ws.adjustmentLevels()
+	required := adjustmentLevelFor(levels, req.Amount.Abs())
+	if required < 0 {
+		return nil, ErrAdjustmentAboveLimits
+	}
+	if roleLevel(levels, checkerRole) < required {
+		return nil, ErrAdjustmentNotAuthorized
+	}
+
+	if ws.adjustments.deciding == nil {
+		ws.adjustments.deciding = make(map[string]bool)
+	}
+	ws.adjustments.deciding[requestID] = true
+	return req, nil
+}
+
+// postAdjustmentRequest posts req's correction. A debit may not take the balance below
+// zero.
+func (ws *WalletService) postAdjustmentRequest(ctx context.Context, req *AdjustmentRequest) (*Transaction, error) {
+	metadata := map[string]string{"adjustment_request": req.ID, "maker_id": req.MakerID}
+	if req.CheckerID != "" {
+		metadata["checker_id"] = req.CheckerID
+	}
+	return ws.postAdjustment(ctx, "adjustment", req.UserID, req.Reason, metadata, func(current decimal.Decimal) (decimal.Decimal, error) {
+		return current.Add(req.Amount), nil
+	})
+}
+
+// staffRole returns staffID's role
+func (ws *WalletService) staffRole(staffID string) (StaffRole, error) {
+	ws.annotations.mu.Lock()
+	defer ws.annotations.mu.Unlock()
+
+	role, isStaff := ws.annotations.staff[staffID]
+	if !isStaff {
+		return "", ErrNotStaff
+	}
+	return role, nil
+}
+
+// adjustmentLevels returns the levels in force. Caller must hold ws.adjustments.mu.
+func (ws *WalletService) adjustmentLevels() []AdjustmentLevel {
+	if ws.adjustments.levels == nil {
+		return DefaultAdjustmentLevels
+	}
+	return ws.adjustments.levels
+}
+
+// adjustmentLevelFor returns the index of the lowest level whose limit covers amount,
+// or -1 when none does
+func adjustmentLevelFor(levels []AdjustmentLevel, amount decimal.Decimal) int {
+	for i, l := range levels {
+		if l.Limit.IsZero() || amount.LessThanOrEqual(l.Limit) {
+			return i
+		}
+	}
+	return -1
+}
+
+// roleLevel returns the index of role's level, or -1 when it has none
+func roleLevel(levels []AdjustmentLevel, role StaffRole) int {
+	for i, l := range levels {
+		if l.Role == role {
+			return i
+		}
+	}
+	return -1
+}
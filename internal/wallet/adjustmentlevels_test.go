@@ -0,0 +1,186 @@
+// internal/wallet/adjustmentlevels_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRequestAdjustment_Levels(t *testing.T) {
+	tests := []struct {
+		name         string
+		maker        string
+		amount       string
+		wantStatus   AdjustmentStatus
+		wantRequired StaffRole
+		wantBalance  string
+	}{
+		{"support posts a small credit", "sam", "40", AdjustmentPosted, StaffSupport, "540"},
+		{"support posts a small debit", "sam", "-100", AdjustmentPosted, StaffSupport, "400"},
+		{"large debit escalates to finance", "sam", "-100.01", AdjustmentPending, StaffFinance, "500"},
+		{"finance posts within its level", "fay", "-450", AdjustmentPosted, StaffFinance, "50"},
+		{"above finance escalates to admin", "fay", "10000.01", AdjustmentPending, StaffAdmin, "500"},
+		{"admin posts anything", "ada", "25000", AdjustmentPosted, StaffAdmin, "25500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			ws := NewWalletService(WithNotifier(notifier))
+			ws.CreateUser("alice", "Alice", "a@example.com")
+			ws.Deposit("alice", 500, "seed")
+			for id, role := range map[string]StaffRole{"sam": StaffSupport, "fay": StaffFinance, "fin": StaffFinance, "ada": StaffAdmin} {
+				if err := ws.SetStaffRole(id, role); err != nil {
+					t.Fatalf("SetStaffRole(%s) error = %v", id, err)
+				}
+			}
+			req, err := ws.RequestAdjustment(tt.maker, "alice", decimal.RequireFromString(tt.amount), ReasonErrorCorrection, "ticket 42")
+			if err != nil {
+				t.Fatalf("RequestAdjustment() error = %v", err)
+			}
+			if req.Status != tt.wantStatus || req.RequiredRole != tt.wantRequired {
+				t.Errorf("request = %s needing %s, want %s needing %s", req.Status, req.RequiredRole, tt.wantStatus, tt.wantRequired)
+			}
+			if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.RequireFromString(tt.wantBalance)) {
+				t.Errorf("balance = %s, want %s", b, tt.wantBalance)
+			}
+
+			escalated := tt.wantStatus == AdjustmentPending
+			if req.Escalated != escalated || (len(notifier.all()) == 1) != escalated {
+				t.Errorf("escalated = %v with notifications %+v, want %v", req.Escalated, notifier.all(), escalated)
+			}
+			if !escalated {
+				tx, err := ws.findTransaction(req.TransactionID)
+				if err != nil || tx.Metadata["maker_id"] != tt.maker || tx.Metadata["adjustment_request"] != req.ID {
+					t.Errorf("adjustment = %+v, %v", tx, err)
+				}
+			}
+		})
+	}
+}
+
+func TestApproveAdjustment_MakerChecker(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 500, "seed")
+	for id, role := range map[string]StaffRole{"sam": StaffSupport, "fay": StaffFinance, "fin": StaffFinance, "ada": StaffAdmin} {
+		if err := ws.SetStaffRole(id, role); err != nil {
+			t.Fatalf("SetStaffRole(%s) error = %v", id, err)
+		}
+	}
+	req, err := ws.RequestAdjustment("sam", "alice", decimal.NewFromInt(-300), ReasonFraudRecovery, "chargeback")
+	if err != nil || req.Status != AdjustmentPending {
+		t.Fatalf("RequestAdjustment() = %+v, %v", req, err)
+	}
+
+	steps := []struct {
+		name    string
+		checker string
+		wantErr error
+	}{
+		{"not staff", "mallory", ErrNotStaff},
+		{"maker", "sam", ErrAdjustmentSelfApproval},
+		{"finance", "fay", nil},
+		{"already posted", "fin", ErrAdjustmentNotPending},
+	}
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := ws.ApproveAdjustment(req.ID, s.checker, "verified"); !errors.Is(err, s.wantErr) || (err != nil) != (s.wantErr != nil) {
+				t.Errorf("ApproveAdjustment() error = %v, want %v", err, s.wantErr)
+			}
+		})
+	}
+
+	got, _ := ws.GetAdjustmentRequest(req.ID)
+	if got.Status != AdjustmentPosted || got.CheckerID != "fay" || got.TransactionID == "" {
+		t.Errorf("request = %+v, want posted by fay", got)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("balance = %s, want 200", b)
+	}
+	tx, _ := ws.findTransaction(got.TransactionID)
+	if tx.Type != TransactionAdjustmentDebit || tx.Metadata["checker_id"] != "fay" {
+		t.Errorf("adjustment = %+v", tx)
+	}
+	if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+}
+
+func TestApproveAdjustment_Authority(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 500, "seed")
+	for id, role := range map[string]StaffRole{"sam": StaffSupport, "fay": StaffFinance, "fin": StaffFinance, "ada": StaffAdmin} {
+		if err := ws.SetStaffRole(id, role); err != nil {
+			t.Fatalf("SetStaffRole(%s) error = %v", id, err)
+		}
+	}
+	big, _ := ws.RequestAdjustment("sam", "alice", decimal.NewFromInt(20000), ReasonMigration, "")
+	overdrawn, _ := ws.RequestAdjustment("sam", "alice", decimal.NewFromInt(-600), ReasonErrorCorrection, "")
+	rejected, _ := ws.RequestAdjustment("fay", "alice", decimal.NewFromInt(-12000), ReasonErrorCorrection, "")
+
+	// A debit beyond the balance stays pending for another look
+	if _, err := ws.ApproveAdjustment(overdrawn.ID, "fay", ""); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("approving an overdrawing debit error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if got, _ := ws.GetAdjustmentRequest(overdrawn.ID); got.Status != AdjustmentPending {
+		t.Errorf("overdrawing request status = %s, want pending", got.Status)
+	}
+
+	if _, err := ws.ApproveAdjustment(big.ID, "fay", ""); !errors.Is(err, ErrAdjustmentNotAuthorized) {
+		t.Errorf("finance approving above its level error = %v, want %v", err, ErrAdjustmentNotAuthorized)
+	}
+	if _, err := ws.ApproveAdjustment(big.ID, "ada", ""); err != nil {
+		t.Errorf("admin approving error = %v", err)
+	}
+
+	if got, err := ws.RejectAdjustment(rejected.ID, "ada", "no evidence"); err != nil || got.Status != AdjustmentRejected {
+		t.Errorf("RejectAdjustment() = %+v, %v", got, err)
+	}
+	if pending := ws.ListAdjustmentRequests(AdjustmentPending); len(pending) != 1 || pending[0].ID != overdrawn.ID {
+		t.Errorf("pending = %+v, want only the overdrawing debit", pending)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(20500)) {
+		t.Errorf("balance = %s, want 20500", b)
+	}
+}
+
+func TestSetAdjustmentLevels(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 500, "seed")
+	for id, role := range map[string]StaffRole{"sam": StaffSupport, "fay": StaffFinance, "fin": StaffFinance, "ada": StaffAdmin} {
+		if err := ws.SetStaffRole(id, role); err != nil {
+			t.Fatalf("SetStaffRole(%s) error = %v", id, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		levels  []AdjustmentLevel
+		wantErr error
+	}{
+		{"empty", nil, ErrInvalidAdjustmentLevels},
+		{"unknown role", []AdjustmentLevel{{Role: "intern", Limit: decimal.NewFromInt(5)}}, ErrInvalidAdjustmentLevels},
+		{"repeated role", []AdjustmentLevel{{Role: StaffSupport, Limit: decimal.NewFromInt(5)}, {Role: StaffSupport, Limit: decimal.NewFromInt(10)}}, ErrInvalidAdjustmentLevels},
+		{"decreasing", []AdjustmentLevel{{Role: StaffSupport, Limit: decimal.NewFromInt(50)}, {Role: StaffFinance, Limit: decimal.NewFromInt(10)}}, ErrInvalidAdjustmentLevels},
+		{"unlimited before the last", []AdjustmentLevel{{Role: StaffSupport}, {Role: StaffFinance, Limit: decimal.NewFromInt(10)}}, ErrInvalidAdjustmentLevels},
+		{"capped", []AdjustmentLevel{{Role: StaffSupport, Limit: decimal.NewFromInt(10)}, {Role: StaffFinance, Limit: decimal.NewFromInt(1000)}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.SetAdjustmentLevels(tt.levels); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetAdjustmentLevels() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if req, err := ws.RequestAdjustment("sam", "alice", decimal.NewFromInt(20), ReasonGoodwill, ""); err != nil || req.RequiredRole != StaffFinance {
+		t.Errorf("RequestAdjustment(20) = %+v, %v, want finance required", req, err)
+	}
+	if _, err := ws.RequestAdjustment("ada", "alice", decimal.NewFromInt(1001), ReasonGoodwill, ""); !errors.Is(err, ErrAdjustmentAboveLimits) {
+		t.Errorf("RequestAdjustment(1001) error = %v, want %v", err, ErrAdjustmentAboveLimits)
+	}
+}
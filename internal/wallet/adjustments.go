@@ -0,0 +1,193 @@
+// internal/wallet/adjustments.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for admin corrections
+var (
+	ErrInvalidReason = errors.New("unknown adjustment reason code")
+	ErrAdminRequired = errors.New("only admins may post direct balance adjustments")
+)
+
+// metaActorID records on a direct admin correction the admin who posted it
+const metaActorID = "actor_id"
+
+// AdjustmentReason is the audit reason code required for admin balance corrections
+type AdjustmentReason string
+
+const (
+	ReasonErrorCorrection AdjustmentReason = "error_correction" // fixing a processing error
+	ReasonReconciliation  AdjustmentReason = "reconciliation"   // matching an external statement
+	ReasonGoodwill        AdjustmentReason = "goodwill"         // customer service credit
+	ReasonFraudRecovery   AdjustmentReason = "fraud_recovery"   // clawing back fraudulent funds
+	ReasonMigration       AdjustmentReason = "migration"        // carrying over balances from another system
+)
+
+// validReasons is the set of accepted reason codes
+var validReasons = map[AdjustmentReason]bool{
+	ReasonErrorCorrection: true,
+	ReasonReconciliation:  true,
+	ReasonGoodwill:        true,
+	ReasonFraudRecovery:   true,
+	ReasonMigration:       true,
+}
+
+// SetBalance brings a user's base-currency balance to target by posting the adjustment
+// transaction that makes up the difference, so the ledger still explains every balance.
+// It returns the posted adjustment, or nil when the balance already equals target.
+// Validators and hold rules do not apply to admin corrections.
+func (ws *WalletService) SetBalance(userID string, target decimal.Decimal, reason AdjustmentReason) (*Transaction, error) {
+	return ws.SetBalanceContext(context.Background(), userID, target, reason)
+}
+
+// SetBalanceContext is SetBalance on behalf of a caller's ctx
+func (ws *WalletService) SetBalanceContext(ctx context.Context, userID string, target decimal.Decimal, reason AdjustmentReason) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ws.postAdjustment(ctx, "set_balance", userID, reason, nil, func(decimal.Decimal) (decimal.Decimal, error) {
+		if target.IsNegative() {
+			return decimal.Zero, ErrInvalidAmount
+		}
+		return target, nil
+	})
+}
+
+// AdminAdjust corrects userID's base-currency balance by a signed amount on behalf of
+// actorID, who must be a registered admin: positive amounts credit the wallet and
+// negative ones debit it, never beyond the funds not held. A wallet that is overdrawn
+// can always be credited. The correction is posted as an adjustment
+// transaction recording the actor and the reason code, so it is never mistaken for a
+// deposit or withdrawal. Validators and hold rules do not apply.
+func (ws *WalletService) AdminAdjust(userID string, amount decimal.Decimal, reason AdjustmentReason, actorID string) (*Transaction, error) {
+	return ws.AdminAdjustContext(context.Background(), userID, amount, reason, actorID)
+}
+
+// AdminAdjustContext is AdminAdjust on behalf of a caller's ctx
+func (ws *WalletService) AdminAdjustContext(ctx context.Context, userID string, amount decimal.Decimal, reason AdjustmentReason, actorID string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		return nil, ErrInvalidAmount
+	}
+	role, err := ws.staffRole(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if role != StaffAdmin {
+		return nil, ErrAdminRequired
+	}
+
+	return ws.postAdjustment(ctx, "admin_adjust", userID, reason, map[string]string{metaActorID: actorID}, func(current decimal.Decimal) (decimal.Decimal, error) {
+		return current.Add(amount), nil
+	})
+}
+
+// ListAdjustments returns the audit trail of balance corrections to userID's wallet,
+// oldest first: every adjustment posted by AdminAdjust, SetBalance or an approved
+// adjustment request, with its reason code and the staff involved in its metadata
+func (ws *WalletService) ListAdjustments(userID string) ([]*Transaction, error) {
+	it, err := ws.IterateTransactions(userID, IterateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var list []*Transaction
+	for it.Next() {
+		if tx := it.Transaction(); tx.Type == TransactionAdjustmentCredit || tx.Type == TransactionAdjustmentDebit {
+			list = append(list, tx)
+		}
+	}
+	return list, it.Err()
+}
+
+// postAdjustment brings userID's base-currency balance to the target computed from its
+// current balance under the user's lock, recording metadata on the adjustment. A debit
+// fails with ErrInsufficientBalance if it exceeds the wallet's available funds, so held
+// funds stay covered; credits are always allowed.
+func (ws *WalletService) postAdjustment(ctx context.Context, op, userID string, reason AdjustmentReason, metadata map[string]string, targetFor func(current decimal.Decimal) (decimal.Decimal, error)) (tx *Transaction, err error) {
+	timer := ws.startOp(op, userID)
+	defer func() {
+		timer.finish(err)
+		ws.audit(ctx, op, tx, err, userID)
+	}()
+
+	if !validReasons[reason] {
+		return nil, ErrInvalidReason
+	}
+
+	userLock := ws.userLocks.getLock(userID)
+	userLock.Lock()
+	defer userLock.Unlock()
+	timer.locked()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	wallet.mu.RLock()
+	current := wallet.Balance
+	available := wallet.available(wallet.Currency)
+	wallet.mu.RUnlock()
+
+	target, err := targetFor(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.checkAmount(wallet.Currency, target, false); err != nil {
+		return nil, err
+	}
+
+	delta := target.Sub(current)
+	if delta.IsZero() {
+		return nil, nil
+	}
+	if delta.IsNegative() && available.LessThan(delta.Neg()) {
+		return nil, ErrInsufficientBalance
+	}
+
+	tx = &Transaction{
+		ID:          ws.newID("tx"),
+		Amount:      delta.Abs(),
+		Currency:    wallet.Currency,
+		Description: fmt.Sprintf("balance adjustment (%s)", reason),
+		Metadata: map[string]string{
+			"reason_code":      string(reason),
+			"previous_balance": current.String(),
+			"target_balance":   target.String(),
+		},
+	}
+	for k, v := range metadata {
+		tx.Metadata[k] = v
+	}
+	stampTrace(ctx, tx)
+	if delta.IsPositive() {
+		tx.Type, tx.ToUserID = TransactionAdjustmentCredit, userID
+	} else {
+		tx.Type, tx.FromUserID = TransactionAdjustmentDebit, userID
+	}
+
+	wallet.mu.Lock()
+	wallet.adjust(tx.Currency, delta)
+	wallet.publish()
+	wallet.mu.Unlock()
+
+	ws.stampTransaction(tx)
+	ws.recordTransaction(tx)
+
+	ws.metrics.IncCounter("balance_adjustments_total", map[string]string{"reason": string(reason)})
+
+	return tx, nil
+}
@@ -0,0 +1,177 @@
+// internal/wallet/adjustments_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSetBalance_PostsCompensatingEntries(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "salary")
+
+	tests := []struct {
+		name     string
+		target   string
+		wantType TransactionType
+		wantAmt  string
+	}{
+		{"raise", "150.25", TransactionAdjustmentCredit, "50.25"},
+		{"lower", "20", TransactionAdjustmentDebit, "130.25"},
+		{"to zero", "0", TransactionAdjustmentDebit, "20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := ws.SetBalance("alice", decimal.RequireFromString(tt.target), ReasonErrorCorrection)
+			if err != nil {
+				t.Fatalf("SetBalance() error = %v", err)
+			}
+			if tx.Type != tt.wantType || !tx.Amount.Equal(decimal.RequireFromString(tt.wantAmt)) {
+				t.Errorf("adjustment = %s %s, want %s %s", tx.Type, tx.Amount, tt.wantType, tt.wantAmt)
+			}
+			if tx.Metadata["reason_code"] != string(ReasonErrorCorrection) || tx.Metadata["target_balance"] != tt.target {
+				t.Errorf("metadata = %v", tx.Metadata)
+			}
+			if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.RequireFromString(tt.target)) {
+				t.Errorf("balance = %s, want %s", b, tt.target)
+			}
+			if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+				t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+			}
+		})
+	}
+
+	if tx, err := ws.SetBalance("alice", decimal.Zero, ReasonGoodwill); tx != nil || err != nil {
+		t.Errorf("SetBalance() at target = %+v, %v, want no adjustment", tx, err)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+}
+
+func TestSetBalance_Validation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	tests := []struct {
+		name    string
+		userID  string
+		target  string
+		reason  AdjustmentReason
+		wantErr error
+	}{
+		{"missing reason", "alice", "10", "", ErrInvalidReason},
+		{"unknown reason", "alice", "10", "because", ErrInvalidReason},
+		{"negative target", "alice", "-1", ReasonReconciliation, ErrInvalidAmount},
+		{"unknown user", "nobody", "10", ReasonReconciliation, ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.SetBalance(tt.userID, decimal.RequireFromString(tt.target), tt.reason); err != tt.wantErr {
+				t.Errorf("SetBalance() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminAdjust(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "salary")
+	ws.SetStaffRole("ops", StaffAdmin)
+	ws.SetStaffRole("desk", StaffSupport)
+
+	tx, err := ws.AdminAdjust("alice", decimal.RequireFromString("12.50"), ReasonGoodwill, "ops")
+	if err != nil {
+		t.Fatalf("AdminAdjust() error = %v", err)
+	}
+	if tx.Type != TransactionAdjustmentCredit || !tx.Amount.Equal(decimal.RequireFromString("12.50")) ||
+		tx.Metadata[metaActorID] != "ops" || tx.Metadata["reason_code"] != string(ReasonGoodwill) {
+		t.Errorf("credit = %+v", tx)
+	}
+	if tx, err = ws.AdminAdjust("alice", decimal.NewFromInt(-40), ReasonFraudRecovery, "ops"); err != nil || tx.Type != TransactionAdjustmentDebit || !tx.Amount.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("AdminAdjust() debit = %+v, %v", tx, err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.RequireFromString("72.50")) {
+		t.Errorf("balance = %s, want 72.50", b)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		amount  string
+		reason  AdjustmentReason
+		actorID string
+		wantErr error
+	}{
+		{"zero amount", "alice", "0", ReasonGoodwill, "ops", ErrInvalidAmount},
+		{"not staff", "alice", "5", ReasonGoodwill, "alice", ErrNotStaff},
+		{"support staff", "alice", "5", ReasonGoodwill, "desk", ErrAdminRequired},
+		{"missing reason", "alice", "5", "", "ops", ErrInvalidReason},
+		{"below zero", "alice", "-72.51", ReasonReconciliation, "ops", ErrInsufficientBalance},
+		{"unknown user", "nobody", "5", ReasonGoodwill, "ops", ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.AdminAdjust(tt.userID, decimal.RequireFromString(tt.amount), tt.reason, tt.actorID); err != tt.wantErr {
+				t.Errorf("AdminAdjust() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	ws.SetBalance("alice", decimal.NewFromInt(100), ReasonReconciliation)
+	list, err := ws.ListAdjustments("alice")
+	if err != nil || len(list) != 3 {
+		t.Fatalf("ListAdjustments() = %+v, %v, want three adjustments", list, err)
+	}
+	if list[0].Metadata[metaActorID] != "ops" || list[2].Metadata["reason_code"] != string(ReasonReconciliation) {
+		t.Errorf("ListAdjustments() = %+v", list)
+	}
+	if _, err := ws.ListAdjustments("nobody"); err != ErrUserNotFound {
+		t.Errorf("ListAdjustments(nobody) error = %v, want %v", err, ErrUserNotFound)
+	}
+	if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+}
+
+func TestAdminAdjust_HeldAndOverdrawnWallets(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.SetStaffRole("ops", StaffAdmin)
+
+	// Held funds stay covered: only the 40 not held may be debited
+	ws.Deposit("alice", 100, "salary")
+	if _, err := ws.Hold("alice", decimal.NewFromInt(60)); err != nil {
+		t.Fatalf("Hold() error = %v", err)
+	}
+	if _, err := ws.AdminAdjust("alice", decimal.NewFromInt(-41), ReasonFraudRecovery, "ops"); err != ErrInsufficientBalance {
+		t.Errorf("AdminAdjust(-41) with 60 held error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if _, err := ws.SetBalance("alice", decimal.NewFromInt(59), ReasonReconciliation); err != ErrInsufficientBalance {
+		t.Errorf("SetBalance(59) with 60 held error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if _, err := ws.AdminAdjust("alice", decimal.NewFromInt(-40), ReasonFraudRecovery, "ops"); err != nil {
+		t.Errorf("AdminAdjust(-40) with 60 held error = %v", err)
+	}
+
+	// An overdrawn wallet can still be credited, but not debited further
+	ws.SetOverdraftLimit("bob", decimal.NewFromInt(100))
+	if err := ws.Withdraw("bob", 50, "cash"); err != nil {
+		t.Fatalf("Withdraw() error = %v", err)
+	}
+	if _, err := ws.AdminAdjust("bob", decimal.NewFromInt(20), ReasonGoodwill, "ops"); err != nil {
+		t.Errorf("AdminAdjust(+20) on an overdrawn wallet error = %v", err)
+	}
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(-30)) {
+		t.Errorf("bob's balance = %s, want -30", b)
+	}
+	if _, err := ws.AdminAdjust("bob", decimal.NewFromInt(-1), ReasonFraudRecovery, "ops"); err != ErrInsufficientBalance {
+		t.Errorf("AdminAdjust(-1) on an overdrawn wallet error = %v, want %v", err, ErrInsufficientBalance)
+	}
+}
@@ -0,0 +1,344 @@
+// internal/wallet/analytics.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrInvalidRange is returned for analytics queries with an empty or unbounded range
+var ErrInvalidRange = errors.New("invalid analytics range")
+
+// Granularity is the width of the buckets analytics are grouped into
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityWeek  Granularity = "week"
+	GranularityMonth Granularity = "month"
+)
+
+// userActivity summarises one user's history in UTC day buckets
+type userActivity struct {
+	signedUpAt   int64
+	firstTx      int64
+	lastActivity int64
+	days         []int64 // sorted unique day starts with any transaction
+	depositDays  []int64 // sorted unique day starts with a deposit
+}
+
+// activityIndex keeps day-bucketed activity per user so cohort queries never rescan
+// the transaction log. It is guarded by ws.mu and updated as transactions are recorded.
+type activityIndex struct {
+	users map[string]*userActivity
+}
+
+// ActivityBucket counts distinct active users in one period
+type ActivityBucket struct {
+	Start time.Time
+	Users int
+}
+
+// ChurnedWallet is a wallet with no activity for the queried span
+type ChurnedWallet struct {
+	UserID       string
+	LastActivity int64 // last transaction, or signup when the user never transacted
+	InactiveDays int
+}
+
+// RetentionCohort follows users whose first deposit fell in one period. Retained[k] is
+// how many of them deposited again k periods later; Retained[0] is the cohort size.
+type RetentionCohort struct {
+	Start    time.Time
+	Size     int
+	Retained []int
+}
+
+// Rate returns the share of the cohort retained k periods later
+func (c RetentionCohort) Rate(k int) float64 {
+	if c.Size == 0 || k < 0 || k >= len(c.Retained) {
+		return 0
+	}
+	return float64(c.Retained[k]) / float64(c.Size)
+}
+
+// ConversionBucket reports how many users who signed up in a period went on to make
+// their first transaction
+type ConversionBucket struct {
+	Start     time.Time
+	SignedUp  int
+	Converted int
+}
+
+// Rate returns the share of signups that converted
+func (b ConversionBucket) Rate() float64 {
+	if b.SignedUp == 0 {
+		return 0
+	}
+	return float64(b.Converted) / float64(b.SignedUp)
+}
+
+// ActiveUsers counts distinct users with at least one transaction in each period
+// between from and to
+func (ws *WalletService) ActiveUsers(from, to time.Time, g Granularity) ([]ActivityBucket, error) {
+	starts, err := buckets(from, to, g)
+	if err != nil {
+		return nil, err
+	}
+	lo, hi := dayStart(from), to.Unix()
+
+	counts := make([]int, len(starts))
+	ws.mu.RLock()
+	for _, a := range ws.activity.users {
+		seen := -1
+		for _, day := range daysBetween(a.days, lo, hi) {
+			i := bucketOf(starts, day)
+			if i != seen {
+				counts[i]++
+				seen = i
+			}
+		}
+	}
+	ws.mu.RUnlock()
+
+	out := make([]ActivityBucket, len(starts))
+	for i, start := range starts {
+		out[i] = ActivityBucket{Start: time.Unix(start, 0).UTC(), Users: counts[i]}
+	}
+	return out, nil
+}
+
+// ChurnedWallets returns users with no transaction in the last inactiveFor, longest
+// inactive first
+func (ws *WalletService) ChurnedWallets(inactiveFor time.Duration) []ChurnedWallet {
+	now := ws.now()
+	cutoff := now.Add(-inactiveFor).Unix()
+
+	var out []ChurnedWallet
+	ws.mu.RLock()
+	for userID := range ws.users {
+		last := int64(0)
+		if a := ws.activity.users[userID]; a != nil {
+			last = max(a.signedUpAt, a.lastActivity)
+		}
+		if last < cutoff {
+			out = append(out, ChurnedWallet{
+				UserID:       userID,
+				LastActivity: last,
+				InactiveDays: int(now.Sub(time.Unix(last, 0)) / (24 * time.Hour)),
+			})
+		}
+	}
+	ws.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].LastActivity != out[j].LastActivity {
+			return out[i].LastActivity < out[j].LastActivity
+		}
+		return out[i].UserID < out[j].UserID
+	})
+	return out
+}
+
+// DepositRetention groups users into cohorts by the period of their first deposit
+// between from and to, and follows each cohort for the given number of periods
+func (ws *WalletService) DepositRetention(from, to time.Time, g Granularity, periods int) ([]RetentionCohort, error) {
+	if periods < 1 {
+		return nil, ErrInvalidRange
+	}
+	starts, err := buckets(from, to, g)
+	if err != nil {
+		return nil, err
+	}
+	// Follow-up periods may run past to
+	end := nextBucket(time.Unix(starts[len(starts)-1], 0).UTC(), g, periods)
+	follow, err := buckets(from, end, g)
+	if err != nil {
+		return nil, err
+	}
+	lo, hi := dayStart(from), to.Unix()
+
+	cohorts := make([]RetentionCohort, len(starts))
+	for i, start := range starts {
+		cohorts[i] = RetentionCohort{Start: time.Unix(start, 0).UTC(), Retained: make([]int, periods)}
+	}
+
+	ws.mu.RLock()
+	for _, a := range ws.activity.users {
+		if len(a.depositDays) == 0 || a.depositDays[0] < lo || a.depositDays[0] >= hi {
+			continue
+		}
+		cohort := bucketOf(follow, a.depositDays[0])
+		cohorts[cohort].Size++
+
+		seen := -1
+		for _, day := range daysBetween(a.depositDays, a.depositDays[0], end.Unix()) {
+			k := bucketOf(follow, day) - cohort
+			if k != seen && k < periods {
+				cohorts[cohort].Retained[k]++
+				seen = k
+			}
+		}
+	}
+	ws.mu.RUnlock()
+
+	return cohorts, nil
+}
+
+// FirstTransactionConversion groups users by signup period between from and to and
+// counts those whose first transaction came within the window; a zero window counts
+// any first transaction so far
+func (ws *WalletService) FirstTransactionConversion(from, to time.Time, g Granularity, within time.Duration) ([]ConversionBucket, error) {
+	starts, err := buckets(from, to, g)
+	if err != nil {
+		return nil, err
+	}
+	lo, hi := from.Unix(), to.Unix()
+
+	out := make([]ConversionBucket, len(starts))
+	for i, start := range starts {
+		out[i].Start = time.Unix(start, 0).UTC()
+	}
+
+	ws.mu.RLock()
+	for _, a := range ws.activity.users {
+		if a.signedUpAt == 0 || a.signedUpAt < lo || a.signedUpAt >= hi {
+			continue
+		}
+		i := bucketOf(starts, a.signedUpAt)
+		out[i].SignedUp++
+		if a.firstTx != 0 && (within <= 0 || a.firstTx-a.signedUpAt <= int64(within/time.Second)) {
+			out[i].Converted++
+		}
+	}
+	ws.mu.RUnlock()
+
+	return out, nil
+}
+
+// signup records when a user was created. Callers hold ws.mu for writing.
+func (x *activityIndex) signup(userID string, at int64) {
+	x.user(userID).signedUpAt = at
+}
+
+// apply folds tx into the activity of the users it touches. Callers hold ws.mu for
+// writing.
+func (x *activityIndex) apply(tx *Transaction, users map[string]*User) {
+	day := dayStart(time.Unix(tx.Timestamp, 0))
+	for _, userID := range [...]string{tx.FromUserID, tx.ToUserID} {
+		if _, isUser := users[userID]; !isUser {
+			continue
+		}
+		a := x.user(userID)
+		if a.firstTx == 0 || tx.Timestamp < a.firstTx {
+			a.firstTx = tx.Timestamp
+		}
+		a.lastActivity = max(a.lastActivity, tx.Timestamp)
+		a.days = insertDay(a.days, day)
+		if countsAsDeposit(tx.Type) && tx.settled() && userID == tx.ToUserID {
+			a.depositDays = insertDay(a.depositDays, day)
+		}
+	}
+}
+
+// user returns the activity record for userID, creating it on first use
+func (x *activityIndex) user(userID string) *userActivity {
+	if x.users == nil {
+		x.users = make(map[string]*userActivity)
+	}
+	a := x.users[userID]
+	if a == nil {
+		a = &userActivity{}
+		x.users[userID] = a
+	}
+	return a
+}
+
+// rebuildActivity reindexes the transaction log after a restore. Snapshots carry no
+// signup times, so a user's first transaction stands in for it. Callers hold ws.mu for
+// writing.
+func (ws *WalletService) rebuildActivity() {
+	ws.activity = activityIndex{}
+	for _, tx := range ws.transactions {
+		ws.activity.apply(tx, ws.users)
+	}
+	for _, a := range ws.activity.users {
+		a.signedUpAt = a.firstTx
+	}
+}
+
+// insertDay adds day to a sorted slice of unique days. The log is almost always in
+// time order, so the common case is an append or a no-op.
+func insertDay(days []int64, day int64) []int64 {
+	n := len(days)
+	if n == 0 || days[n-1] < day {
+		return append(days, day)
+	}
+	i := sort.Search(n, func(i int) bool { return days[i] >= day })
+	if days[i] == day {
+		return days
+	}
+	days = append(days, 0)
+	copy(days[i+1:], days[i:])
+	days[i] = day
+	return days
+}
+
+// daysBetween returns the part of sorted days in [lo, hi)
+func daysBetween(days []int64, lo, hi int64) []int64 {
+	i := sort.Search(len(days), func(i int) bool { return days[i] >= lo })
+	j := sort.Search(len(days), func(i int) bool { return days[i] >= hi })
+	return days[i:j]
+}
+
+// buckets returns the start of every period overlapping [from, to)
+func buckets(from, to time.Time, g Granularity) ([]int64, error) {
+	switch g {
+	case GranularityDay, GranularityWeek, GranularityMonth:
+	default:
+		return nil, ErrInvalidRange
+	}
+	if from.IsZero() || !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+
+	var starts []int64
+	for t := bucketStart(from, g); t.Before(to); t = nextBucket(t, g, 1) {
+		starts = append(starts, t.Unix())
+	}
+	return starts, nil
+}
+
+// bucketOf returns the index of the bucket containing ts
+func bucketOf(starts []int64, ts int64) int {
+	return sort.Search(len(starts), func(i int) bool { return starts[i] > ts }) - 1
+}
+
+// bucketStart returns the UTC start of the period containing t
+func bucketStart(t time.Time, g Granularity) time.Time {
+	period := MandateDaily
+	switch g {
+	case GranularityWeek:
+		period = MandateWeekly
+	case GranularityMonth:
+		period = MandateMonthly
+	}
+	return periodStart(t.UTC(), period)
+}
+
+// nextBucket returns the start of the period n periods after the one starting at t
+func nextBucket(t time.Time, g Granularity, n int) time.Time {
+	switch g {
+	case GranularityWeek:
+		return t.AddDate(0, 0, 7*n)
+	case GranularityMonth:
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// dayStart returns the unix time of the UTC day containing t
+func dayStart(t time.Time) int64 {
+	return bucketStart(t, GranularityDay).Unix()
+}
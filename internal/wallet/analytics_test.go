@@ -0,0 +1,175 @@
+// internal/wallet/analytics_test.go
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// seedAnalytics plays a short history on clock, starting Mon 2024-01-01, and returns
+// the start:
+//
+//	alice signs up day 0, deposits days 0, 8 and 15
+//	bob   signs up day 0, deposits day 1, transfers to alice day 9
+//	carol signs up day 2, never transacts
+//	dave  signs up day 7, deposits day 10
+func seedAnalytics(ws *WalletService, clock *fakeClock) time.Time {
+	start := clock.Now()
+
+	at := func(day int) {
+		clock.Advance(start.AddDate(0, 0, day).Sub(clock.Now()))
+	}
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 10, "")
+	at(1)
+	ws.Deposit("bob", 10, "")
+	at(2)
+	ws.CreateUser("carol", "Carol", "carol@example.com")
+	at(7)
+	ws.CreateUser("dave", "Dave", "dave@example.com")
+	at(8)
+	ws.Deposit("alice", 10, "")
+	at(9)
+	ws.Transfer("bob", "alice", 5, "")
+	at(10)
+	ws.Deposit("dave", 10, "")
+	at(15)
+	ws.Deposit("alice", 10, "")
+	return start
+}
+
+func TestAnalytics_ActiveUsers(t *testing.T) {
+	ws, clock := newTestService()
+	start := seedAnalytics(ws, clock)
+
+	weeks, err := ws.ActiveUsers(start, start.AddDate(0, 0, 20), GranularityWeek)
+	if err != nil {
+		t.Fatalf("ActiveUsers() error = %v", err)
+	}
+	want := []int{2, 3, 1}
+	if len(weeks) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(weeks), len(want))
+	}
+	for i, w := range weeks {
+		if w.Users != want[i] {
+			t.Errorf("week %d active = %d, want %d", i, w.Users, want[i])
+		}
+	}
+	if !weeks[1].Start.Equal(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("second bucket starts %v", weeks[1].Start)
+	}
+
+	if _, err := ws.ActiveUsers(start, start, GranularityDay); err != ErrInvalidRange {
+		t.Errorf("empty range error = %v, want %v", err, ErrInvalidRange)
+	}
+	if _, err := ws.ActiveUsers(start, start.AddDate(0, 0, 1), "hour"); err != ErrInvalidRange {
+		t.Errorf("unknown granularity error = %v, want %v", err, ErrInvalidRange)
+	}
+}
+
+func TestAnalytics_ChurnedWallets(t *testing.T) {
+	ws, clock := newTestService()
+	seedAnalytics(ws, clock)
+
+	// The clock is on day 15
+	tests := []struct {
+		inactive time.Duration
+		want     []string
+	}{
+		{3 * 24 * time.Hour, []string{"carol", "bob", "dave"}},
+		{6 * 24 * time.Hour, []string{"carol"}},
+		{13 * 24 * time.Hour, nil},
+	}
+	for _, tt := range tests {
+		got := ws.ChurnedWallets(tt.inactive)
+		ids := make([]string, len(got))
+		for i, c := range got {
+			ids[i] = c.UserID
+		}
+		if len(ids) != len(tt.want) {
+			t.Errorf("ChurnedWallets(%v) = %v, want %v", tt.inactive, ids, tt.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.want[i] {
+				t.Errorf("ChurnedWallets(%v) = %v, want %v", tt.inactive, ids, tt.want)
+				break
+			}
+		}
+	}
+	if got := ws.ChurnedWallets(3 * 24 * time.Hour); got[0].InactiveDays != 13 {
+		t.Errorf("carol inactive for %d days, want 13", got[0].InactiveDays)
+	}
+}
+
+func TestAnalytics_DepositRetention(t *testing.T) {
+	ws, clock := newTestService()
+	start := seedAnalytics(ws, clock)
+
+	cohorts, err := ws.DepositRetention(start, start.AddDate(0, 0, 13), GranularityWeek, 3)
+	if err != nil {
+		t.Fatalf("DepositRetention() error = %v", err)
+	}
+	// Week 0: alice and bob; alice deposits again in weeks 1 and 2. Week 1: dave.
+	want := [][]int{{2, 1, 1}, {1, 0, 0}}
+	for i, c := range cohorts {
+		for k := range want[i] {
+			if c.Retained[k] != want[i][k] {
+				t.Errorf("cohort %d retained = %v, want %v", i, c.Retained, want[i])
+				break
+			}
+		}
+	}
+	if rate := cohorts[0].Rate(1); rate != 0.5 {
+		t.Errorf("week 0 retention after one week = %v, want 0.5", rate)
+	}
+}
+
+func TestAnalytics_FirstTransactionConversion(t *testing.T) {
+	ws, clock := newTestService()
+	start := seedAnalytics(ws, clock)
+
+	tests := []struct {
+		within time.Duration
+		want   []int
+	}{
+		{0, []int{2, 1}},
+		{24 * time.Hour, []int{2, 0}},
+		{time.Hour, []int{1, 0}},
+	}
+	for _, tt := range tests {
+		got, err := ws.FirstTransactionConversion(start, start.AddDate(0, 0, 13), GranularityWeek, tt.within)
+		if err != nil {
+			t.Fatalf("FirstTransactionConversion() error = %v", err)
+		}
+		if got[0].SignedUp != 3 || got[1].SignedUp != 1 {
+			t.Errorf("signups = %d, %d, want 3, 1", got[0].SignedUp, got[1].SignedUp)
+		}
+		for i := range tt.want {
+			if got[i].Converted != tt.want[i] {
+				t.Errorf("within %v week %d converted = %d, want %d", tt.within, i, got[i].Converted, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestAnalytics_SurviveRestore(t *testing.T) {
+	ws, clock := newTestService()
+	start := seedAnalytics(ws, clock)
+
+	var buf bytes.Buffer
+	if err := ws.Backup(&buf); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	restored, err := Restore(&buf)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	weeks, _ := restored.ActiveUsers(start, start.AddDate(0, 0, 20), GranularityWeek)
+	if weeks[0].Users != 2 || weeks[1].Users != 3 || weeks[2].Users != 1 {
+		t.Errorf("restored active users = %+v", weeks)
+	}
+}
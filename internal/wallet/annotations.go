@@ -0,0 +1,216 @@
+// internal/wallet/annotations.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Error definitions for staff annotations
+var (
+	ErrNotStaff              = errors.New("not a staff member")
+	ErrInvalidStaffRole      = errors.New("invalid staff role")
+	ErrAnnotationTextMissing = errors.New("annotation text is required")
+	ErrAnnotationVisibility  = errors.New("staff role cannot use this annotation visibility")
+)
+
+// StaffRole is the access level of a support or operations staff member. Staff IDs are
+// operator identities and need not be wallet users.
+type StaffRole string
+
+const (
+	StaffSupport StaffRole = "support"
+	StaffFinance StaffRole = "finance"
+	StaffAdmin   StaffRole = "admin"
+)
+
+// AnnotationVisibility says which staff may read an annotation. Annotations are never
+// shown to wallet users; user-visible text belongs in transaction descriptions.
+type AnnotationVisibility string
+
+const (
+	VisibleToStaff  AnnotationVisibility = "staff" // support and admin
+	VisibleToAdmins AnnotationVisibility = "admin"
+)
+
+// AnnotationSubject is the kind of record an annotation is attached to
+type AnnotationSubject string
+
+const (
+	AnnotationTransaction AnnotationSubject = "transaction"
+	AnnotationUser        AnnotationSubject = "user"
+)
+
+// Annotation is an internal staff note on a transaction or user. Annotations are
+// append-only so they double as an audit trail.
+type Annotation struct {
+	ID         string
+	Subject    AnnotationSubject
+	SubjectID  string
+	AuthorID   string
+	AuthorRole StaffRole
+	Text       string
+	Visibility AnnotationVisibility
+	CreatedAt  int64
+}
+
+// AnnotationQuery filters SearchAnnotations. Zero fields match everything.
+type AnnotationQuery struct {
+	Text      string // case-insensitive substring of the annotation text
+	AuthorID  string
+	Subject   AnnotationSubject
+	SubjectID string
+	Since     int64 // inclusive
+	Until     int64 // exclusive
+}
+
+// annotationBook holds staff roles and annotations
+type annotationBook struct {
+	mu          sync.Mutex
+	staff       map[string]StaffRole
+	annotations []*Annotation
+	bySubject   map[string][]*Annotation
+}
+
+// SetStaffRole grants staffID the given role, replacing any previous one
+func (ws *WalletService) SetStaffRole(staffID string, role StaffRole) error {
+	if staffID == "" || (role != StaffSupport && role != StaffFinance && role != StaffAdmin) {
+		return ErrInvalidStaffRole
+	}
+
+	ws.annotations.mu.Lock()
+	defer ws.annotations.mu.Unlock()
+
+	if ws.annotations.staff == nil {
+		ws.annotations.staff = make(map[string]StaffRole)
+	}
+	ws.annotations.staff[staffID] = role
+	return nil
+}
+
+// RemoveStaff revokes staffID's access. Their annotations stay.
+func (ws *WalletService) RemoveStaff(staffID string) error {
+	ws.annotations.mu.Lock()
+	defer ws.annotations.mu.Unlock()
+
+	if _, exists := ws.annotations.staff[staffID]; !exists {
+		return ErrNotStaff
+	}
+	delete(ws.annotations.staff, staffID)
+	return nil
+}
+
+// AnnotateTransaction attaches an internal note to a transaction in the hot log or the
+// archive
+func (ws *WalletService) AnnotateTransaction(authorID, txID, text string, visibility AnnotationVisibility) (*Annotation, error) {
+	if _, err := ws.findTransaction(txID); err != nil {
+		return nil, err
+	}
+	return ws.annotate(authorID, AnnotationTransaction, txID, text, visibility)
+}
+
+// AnnotateUser attaches an internal note to a user
+func (ws *WalletService) AnnotateUser(authorID, userID, text string, visibility AnnotationVisibility) (*Annotation, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	return ws.annotate(authorID, AnnotationUser, userID, text, visibility)
+}
+
+// ListAnnotations returns the annotations on one transaction or user that viewerID may
+// read, oldest first
+func (ws *WalletService) ListAnnotations(viewerID string, subject AnnotationSubject, subjectID string) ([]Annotation, error) {
+	return ws.SearchAnnotations(viewerID, AnnotationQuery{Subject: subject, SubjectID: subjectID})
+}
+
+// SearchAnnotations returns the annotations matching query that viewerID may read,
+// oldest first
+func (ws *WalletService) SearchAnnotations(viewerID string, query AnnotationQuery) ([]Annotation, error) {
+	ws.annotations.mu.Lock()
+	defer ws.annotations.mu.Unlock()
+
+	role, isStaff := ws.annotations.staff[viewerID]
+	if !isStaff {
+		return nil, ErrNotStaff
+	}
+
+	candidates := ws.annotations.annotations
+	if query.Subject != "" && query.SubjectID != "" {
+		candidates = ws.annotations.bySubject[annotationKey(query.Subject, query.SubjectID)]
+	}
+
+	text := strings.ToLower(query.Text)
+	result := []Annotation{}
+	for _, a := range candidates {
+		switch {
+		case !canReadAnnotation(role, a.Visibility):
+		case query.AuthorID != "" && a.AuthorID != query.AuthorID:
+		case query.Subject != "" && a.Subject != query.Subject:
+		case query.SubjectID != "" && a.SubjectID != query.SubjectID:
+		case query.Since != 0 && a.CreatedAt < query.Since:
+		case query.Until != 0 && a.CreatedAt >= query.Until:
+		case text != "" && !strings.Contains(strings.ToLower(a.Text), text):
+		default:
+			result = append(result, *a)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt < result[j].CreatedAt })
+	return result, nil
+}
+
+// annotate records an annotation after checking the author may write it
+func (ws *WalletService) annotate(authorID string, subject AnnotationSubject, subjectID, text string, visibility AnnotationVisibility) (*Annotation, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrAnnotationTextMissing
+	}
+	if visibility == "" {
+		visibility = VisibleToStaff
+	}
+
+	ws.annotations.mu.Lock()
+	defer ws.annotations.mu.Unlock()
+
+	role, isStaff := ws.annotations.staff[authorID]
+	if !isStaff {
+		return nil, ErrNotStaff
+	}
+	if (visibility != VisibleToStaff && visibility != VisibleToAdmins) || !canReadAnnotation(role, visibility) {
+		return nil, ErrAnnotationVisibility
+	}
+
+	a := &Annotation{
+		ID:         ws.newID("ann"),
+		Subject:    subject,
+		SubjectID:  subjectID,
+		AuthorID:   authorID,
+		AuthorRole: role,
+		Text:       text,
+		Visibility: visibility,
+		CreatedAt:  ws.now().Unix(),
+	}
+	if ws.annotations.bySubject == nil {
+		ws.annotations.bySubject = make(map[string][]*Annotation)
+	}
+	key := annotationKey(subject, subjectID)
+	ws.annotations.annotations = append(ws.annotations.annotations, a)
+	ws.annotations.bySubject[key] = append(ws.annotations.bySubject[key], a)
+
+	copied := *a
+	return &copied, nil
+}
+
+// canReadAnnotation reports whether a staff role may read annotations of visibility
+func canReadAnnotation(role StaffRole, visibility AnnotationVisibility) bool {
+	return visibility == VisibleToStaff || role == StaffAdmin
+}
+
+// annotationKey indexes annotations by the record they are attached to
+func annotationKey(subject AnnotationSubject, subjectID string) string {
+	return string(subject) + ":" + subjectID
+}
@@ -0,0 +1,107 @@
+// internal/wallet/annotations_test.go
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAnnotations_VisibilityAndSearch(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	tx, _ := ws.transfer(context.Background(), "alice", "bob", decimal.NewFromInt(30), "rent", transferOptions{})
+	ws.SetStaffRole("sam", StaffSupport)
+	ws.SetStaffRole("ada", StaffAdmin)
+
+	first, err := ws.AnnotateTransaction("sam", tx.ID, "Customer called about this rent payment", VisibleToStaff)
+	if err != nil {
+		t.Fatalf("AnnotateTransaction() error = %v", err)
+	}
+	if first.AuthorID != "sam" || first.AuthorRole != StaffSupport || first.CreatedAt != clock.Now().Unix() {
+		t.Errorf("annotation = %+v", first)
+	}
+	clock.Advance(time.Hour)
+	ws.AnnotateTransaction("ada", tx.ID, "Fraud team asked to watch this account", VisibleToAdmins)
+	ws.AnnotateUser("sam", "alice", "Prefers email contact", "")
+
+	tests := []struct {
+		name     string
+		viewerID string
+		query    AnnotationQuery
+		wantText []string
+		wantErr  error
+	}{
+		{"support sees staff notes only", "sam", AnnotationQuery{Subject: AnnotationTransaction, SubjectID: tx.ID}, []string{"Customer called"}, nil},
+		{"admin sees both", "ada", AnnotationQuery{Subject: AnnotationTransaction, SubjectID: tx.ID}, []string{"Customer called", "Fraud team"}, nil},
+		{"text search is case-insensitive", "ada", AnnotationQuery{Text: "EMAIL"}, []string{"Prefers email"}, nil},
+		{"by author", "ada", AnnotationQuery{AuthorID: "ada"}, []string{"Fraud team"}, nil},
+		{"by time window", "ada", AnnotationQuery{Since: clock.Now().Unix()}, []string{"Fraud team", "Prefers email"}, nil},
+		{"by subject kind", "sam", AnnotationQuery{Subject: AnnotationUser}, []string{"Prefers email"}, nil},
+		{"wallet user is not staff", "alice", AnnotationQuery{}, nil, ErrNotStaff},
+	}
+	for _, tt := range tests {
+		got, err := ws.SearchAnnotations(tt.viewerID, tt.query)
+		if err != tt.wantErr {
+			t.Fatalf("%s: SearchAnnotations() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if len(got) != len(tt.wantText) {
+			t.Fatalf("%s: SearchAnnotations() = %+v, want %d results", tt.name, got, len(tt.wantText))
+		}
+		for i, want := range tt.wantText {
+			if !strings.HasPrefix(got[i].Text, want) {
+				t.Errorf("%s: result %d = %q, want prefix %q", tt.name, i, got[i].Text, want)
+			}
+		}
+	}
+
+	// Annotations never reach user-facing history or exports
+	var buf bytes.Buffer
+	ws.ExportTransactionHistory("alice", &buf)
+	if strings.Contains(buf.String(), "Customer called") || len(tx.Metadata) != 0 {
+		t.Errorf("annotation leaked into user-visible data: %s", buf.String())
+	}
+
+	ws.RemoveStaff("sam")
+	if _, err := ws.ListAnnotations("sam", AnnotationUser, "alice"); err != ErrNotStaff {
+		t.Errorf("ListAnnotations() after RemoveStaff error = %v, want %v", err, ErrNotStaff)
+	}
+	if got, _ := ws.ListAnnotations("ada", AnnotationUser, "alice"); len(got) != 1 || got[0].AuthorID != "sam" {
+		t.Errorf("annotations of removed staff = %+v, want kept", got)
+	}
+}
+
+func TestAnnotations_Validation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 10, "seed")
+	history, _ := ws.GetTransactionHistory("alice")
+	tx := history[0]
+	ws.SetStaffRole("sam", StaffSupport)
+
+	tests := []struct {
+		name    string
+		op      func() error
+		wantErr error
+	}{
+		{"empty text", func() error { _, err := ws.AnnotateUser("sam", "alice", "  ", VisibleToStaff); return err }, ErrAnnotationTextMissing},
+		{"non-staff author", func() error { _, err := ws.AnnotateUser("alice", "alice", "hi", VisibleToStaff); return err }, ErrNotStaff},
+		{"support writing admin note", func() error { _, err := ws.AnnotateTransaction("sam", tx.ID, "x", VisibleToAdmins); return err }, ErrAnnotationVisibility},
+		{"unknown visibility", func() error { _, err := ws.AnnotateUser("sam", "alice", "x", "public"); return err }, ErrAnnotationVisibility},
+		{"unknown transaction", func() error { _, err := ws.AnnotateTransaction("sam", "nope", "x", VisibleToStaff); return err }, ErrTransactionNotFound},
+		{"unknown user", func() error { _, err := ws.AnnotateUser("sam", "ghost", "x", VisibleToStaff); return err }, ErrUserNotFound},
+		{"invalid role", func() error { return ws.SetStaffRole("sam", "owner") }, ErrInvalidStaffRole},
+		{"remove unknown staff", func() error { return ws.RemoveStaff("ghost") }, ErrNotStaff},
+	}
+	for _, tt := range tests {
+		if err := tt.op(); err != tt.wantErr {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
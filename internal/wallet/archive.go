@@ -0,0 +1,242 @@
+// internal/wallet/archive.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrArchiveNotConfigured is returned when archiving without an ArchiveStore
+var ErrArchiveNotConfigured = errors.New("archive store not configured")
+
+// ArchiveStore holds transactions moved out of the in-memory hot log. Iterate must
+// yield a user's archived transactions in the order they were appended.
+type ArchiveStore interface {
+	Append(txs []*Transaction) error
+	Iterate(userID string, opts IterateOptions) (TransactionIterator, error)
+}
+
+// WithArchive sets the cold store that archived transactions are moved to
+func WithArchive(a ArchiveStore) Option {
+	return func(ws *WalletService) {
+		ws.archive = a
+	}
+}
+
+// ArchiveTransactionsBefore moves the leading run of hot-log transactions older than
+// cutoff (a Unix timestamp) into the archive and returns how many were moved. History,
+// exports and ledger checks keep seeing archived entries through the merged iterator.
+func (ws *WalletService) ArchiveTransactionsBefore(cutoff int64) (int, error) {
+	return ws.ArchiveTransactionsBeforeContext(context.Background(), cutoff)
+}
+
+// ArchiveTransactionsBeforeContext is ArchiveTransactionsBefore bounded by ctx and the
+// archive layer budget. The hot log is left untouched when the write times out.
+func (ws *WalletService) ArchiveTransactionsBeforeContext(ctx context.Context, cutoff int64) (int, error) {
+	if ws.archive == nil {
+		return 0, ErrArchiveNotConfigured
+	}
+
+	ws.retention.evictMu.Lock()
+	defer ws.retention.evictMu.Unlock()
+
+	ws.mu.RLock()
+	n := 0
+	for n < len(ws.transactions) && ws.transactions[n].Timestamp < cutoff {
+		n++
+	}
+	batch := append([]*Transaction(nil), ws.transactions[:n]...)
+	ws.mu.RUnlock()
+
+	if n == 0 {
+		return 0, nil
+	}
+
+	// Write to the archive before trimming the hot log; a reader racing with us may
+	// briefly see an entry in both places, which the merged iterator deduplicates
+	if err := ws.archiveAppend(ctx, batch); err != nil {
+		return 0, err
+	}
+	ws.trimHotLog(batch)
+
+	return n, nil
+}
+
+// MemoryArchive is an in-process ArchiveStore, useful for tests and small deployments
+type MemoryArchive struct {
+	mu  sync.RWMutex
+	txs []*Transaction
+}
+
+// NewMemoryArchive creates an empty in-memory archive
+func NewMemoryArchive() *MemoryArchive {
+	return &MemoryArchive{}
+}
+
+// Append adds transactions to the archive
+func (a *MemoryArchive) Append(txs []*Transaction) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.txs = append(a.txs, txs...)
+	return nil
+}
+
+// AppendContext is Append, refusing to start once ctx is done
+func (a *MemoryArchive) AppendContext(ctx context.Context, txs []*Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.Append(txs)
+}
+
+// IterateContext is Iterate, refusing to start once ctx is done
+func (a *MemoryArchive) IterateContext(ctx context.Context, userID string, opts IterateOptions) (TransactionIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.Iterate(userID, opts)
+}
+
+// Iterate returns the archived transactions matching userID and the time window
+func (a *MemoryArchive) Iterate(userID string, opts IterateOptions) (TransactionIterator, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var matched []*Transaction
+	for _, tx := range a.txs {
+		if opts.matches(userID, tx) {
+			matched = append(matched, tx)
+		}
+	}
+	return &sliceIterator{txs: matched}, nil
+}
+
+// Len returns the number of archived transactions
+func (a *MemoryArchive) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.txs)
+}
+
+// mergedIterator interleaves archived and hot transactions by timestamp, archive first
+// on ties, and drops entries seen in both while archiving is in progress
+type mergedIterator struct {
+	cold, hot         TransactionIterator
+	coldNext, hotNext *Transaction
+	started           bool
+	cur               *Transaction
+	boundaryTime      int64
+	boundaryIDs       map[string]bool // IDs already yielded at boundaryTime
+	err               error
+	closed            bool
+}
+
+func newMergedIterator(cold, hot TransactionIterator) *mergedIterator {
+	return &mergedIterator{cold: cold, hot: hot, boundaryIDs: make(map[string]bool)}
+}
+
+// Next advances to the next transaction across both sources
+func (it *mergedIterator) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		it.coldNext = it.advance(it.cold)
+		it.hotNext = it.advance(it.hot)
+	}
+
+	for {
+		var next *Transaction
+		switch {
+		case it.coldNext == nil && it.hotNext == nil:
+			it.cur = nil
+			return false
+		case it.hotNext == nil || (it.coldNext != nil && it.coldNext.Timestamp <= it.hotNext.Timestamp):
+			next = it.coldNext
+			it.coldNext = it.advance(it.cold)
+		default:
+			next = it.hotNext
+			it.hotNext = it.advance(it.hot)
+		}
+		if it.err != nil {
+			return false
+		}
+
+		if next.Timestamp != it.boundaryTime {
+			it.boundaryTime = next.Timestamp
+			it.boundaryIDs = make(map[string]bool)
+		}
+		if it.boundaryIDs[next.ID] {
+			continue
+		}
+		it.boundaryIDs[next.ID] = true
+
+		it.cur = next
+		return true
+	}
+}
+
+// advance pulls the next entry from src, capturing its error
+func (it *mergedIterator) advance(src TransactionIterator) *Transaction {
+	if src.Next() {
+		return src.Transaction()
+	}
+	if err := src.Err(); err != nil && it.err == nil {
+		it.err = err
+	}
+	return nil
+}
+
+// Transaction returns the current transaction
+func (it *mergedIterator) Transaction() *Transaction {
+	return it.cur
+}
+
+// Err returns the first error from either source
+func (it *mergedIterator) Err() error {
+	return it.err
+}
+
+// Close closes both sources
+func (it *mergedIterator) Close() error {
+	it.closed = true
+	errCold := it.cold.Close()
+	errHot := it.hot.Close()
+	if errCold != nil {
+		return errCold
+	}
+	return errHot
+}
+
+// sliceIterator iterates over a pre-materialised slice
+type sliceIterator struct {
+	txs []*Transaction
+	pos int
+	cur *Transaction
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.pos >= len(it.txs) {
+		it.cur = nil
+		return false
+	}
+	it.cur = it.txs[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *sliceIterator) Transaction() *Transaction { return it.cur }
+func (it *sliceIterator) Err() error                { return nil }
+func (it *sliceIterator) Close() error              { it.txs = nil; return nil }
+
+// errIterator yields nothing and reports err
+type errIterator struct {
+	err error
+}
+
+func (it *errIterator) Next() bool                { return false }
+func (it *errIterator) Transaction() *Transaction { return nil }
+func (it *errIterator) Err() error                { return it.err }
+func (it *errIterator) Close() error              { return nil }
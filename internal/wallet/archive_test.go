@@ -0,0 +1,68 @@
+// internal/wallet/archive_test.go
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWalletService_ArchiveFederatedHistory tests that history spans the archive boundary
+func TestWalletService_ArchiveFederatedHistory(t *testing.T) {
+	archive := NewMemoryArchive()
+	ws, clock := newTestService(WithArchive(archive))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+
+	descriptions := []string{"old-1", "old-2", "boundary", "new-1", "new-2"}
+	var cutoff int64
+	for i, desc := range descriptions {
+		if i == 2 {
+			cutoff = clock.Now().Unix()
+		}
+		ws.Deposit("user1", 10.0, desc)
+		clock.Advance(time.Hour)
+	}
+	ws.Transfer("user1", "user2", 5.0, "transfer")
+
+	moved, err := ws.ArchiveTransactionsBefore(cutoff)
+	if err != nil || moved != 2 || archive.Len() != 2 {
+		t.Fatalf("ArchiveTransactionsBefore() = %d, %v (archive has %d)", moved, err, archive.Len())
+	}
+
+	history, _ := ws.GetTransactionHistory("user1")
+	if len(history) != 6 {
+		t.Fatalf("Expected 6 transactions across hot and archive, got %d", len(history))
+	}
+	for i, desc := range descriptions {
+		if history[i].Description != desc {
+			t.Errorf("history[%d] = %s, want %s", i, history[i].Description, desc)
+		}
+	}
+
+	if mismatch, _ := ws.CheckWalletIntegrity("user1"); mismatch != nil {
+		t.Errorf("Ledger mismatch after archiving: %+v", mismatch)
+	}
+
+	var csv bytes.Buffer
+	ws.ExportTransactionHistory("user1", &csv)
+	if !strings.Contains(csv.String(), "old-1") || !strings.Contains(csv.String(), "new-2") {
+		t.Errorf("Export missing archived or hot rows: %s", csv.String())
+	}
+
+	// An entry present in both stores mid-archive is yielded once
+	ws.mu.RLock()
+	overlap := ws.transactions[0]
+	ws.mu.RUnlock()
+	archive.Append([]*Transaction{overlap})
+
+	history, _ = ws.GetTransactionHistory("user1")
+	if len(history) != 6 {
+		t.Errorf("Expected duplicate to be dropped, got %d transactions", len(history))
+	}
+
+	if _, err := NewWalletService().ArchiveTransactionsBefore(cutoff); err != ErrArchiveNotConfigured {
+		t.Errorf("Expected ErrArchiveNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+// internal/wallet/attachments.go
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error definitions for attachment storage
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment exceeds the size limit")
+	ErrAttachmentType     = errors.New("attachment content type not allowed")
+	ErrAttachmentKey      = errors.New("invalid attachment key")
+	ErrAttachmentCorrupt  = errors.New("attachment does not match its checksum")
+	ErrAttachmentPurged   = errors.New("attachment deleted after its case closed")
+)
+
+// DefaultAttachmentMaxSize is the largest attachment accepted when no limit is set
+const DefaultAttachmentMaxSize = 10 << 20
+
+// DefaultAttachmentTypes are the content types accepted when none are set
+var DefaultAttachmentTypes = []string{"application/pdf", "image/jpeg", "image/png", "text/plain"}
+
+// AttachmentStore keeps evidence blobs attached to cases. Keys are slash-separated
+// paths of letters, digits, '.', '_' and '-', such as "cases/case-1/evid-2".
+type AttachmentStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error) // ErrAttachmentNotFound when missing
+	Delete(key string) error        // deleting a missing key is not an error
+}
+
+// AttachmentPolicy limits what may be attached and how long it is kept
+type AttachmentPolicy struct {
+	MaxSize      int64         // bytes; DefaultAttachmentMaxSize when unset
+	ContentTypes []string      // DefaultAttachmentTypes when empty
+	Retention    time.Duration // how long blobs outlive their case; deleted at close when zero
+}
+
+// attachmentDesk holds the configured store and policy
+type attachmentDesk struct {
+	mu     sync.Mutex
+	store  AttachmentStore
+	policy AttachmentPolicy
+}
+
+// WithAttachmentStore sets where case evidence is stored and the limits it must meet.
+// Without it evidence is kept in memory under the default policy.
+func WithAttachmentStore(store AttachmentStore, policy AttachmentPolicy) Option {
+	return func(ws *WalletService) {
+		ws.attachments.store = store
+		ws.attachments.policy = policy
+	}
+}
+
+// attachmentStore returns the store and policy with defaults filled in
+func (ws *WalletService) attachmentStore() (AttachmentStore, AttachmentPolicy) {
+	ws.attachments.mu.Lock()
+	defer ws.attachments.mu.Unlock()
+
+	if ws.attachments.store == nil {
+		ws.attachments.store = NewMemoryAttachmentStore()
+	}
+	policy := ws.attachments.policy
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = DefaultAttachmentMaxSize
+	}
+	if len(policy.ContentTypes) == 0 {
+		policy.ContentTypes = DefaultAttachmentTypes
+	}
+	return ws.attachments.store, policy
+}
+
+// putAttachment checks data against the policy and stores it under key. It returns
+// the content type without parameters and the hex SHA-256 checksum of data.
+func (ws *WalletService) putAttachment(key, contentType string, data []byte) (string, string, error) {
+	store, policy := ws.attachmentStore()
+	if int64(len(data)) > policy.MaxSize {
+		return "", "", ErrAttachmentTooLarge
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(policy.ContentTypes, mediaType) {
+		return "", "", ErrAttachmentType
+	}
+	if err := store.Put(key, data); err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256(data)
+	ws.metrics.IncCounter("attachments_stored_total", map[string]string{"type": mediaType})
+	return mediaType, hex.EncodeToString(sum[:]), nil
+}
+
+// getAttachment reads key and verifies it still matches checksum
+func (ws *WalletService) getAttachment(key, checksum string) ([]byte, error) {
+	store, _ := ws.attachmentStore()
+	data, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != checksum {
+		return nil, ErrAttachmentCorrupt
+	}
+	return data, nil
+}
+
+// deleteAttachments deletes keys, stopping at the first failure
+func (ws *WalletService) deleteAttachments(keys []string) error {
+	store, _ := ws.attachmentStore()
+	for _, key := range keys {
+		if err := store.Delete(key); err != nil {
+			return err
+		}
+		ws.metrics.IncCounter("attachments_deleted_total", nil)
+	}
+	return nil
+}
+
+// validAttachmentKey reports whether key is a relative slash-separated path of
+// non-empty segments, none of them "." or ".."
+func validAttachmentKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("._-/", c)) {
+			return false
+		}
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// MemoryAttachmentStore is an in-process AttachmentStore, useful for tests and small
+// deployments
+type MemoryAttachmentStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemoryAttachmentStore creates an empty in-memory attachment store
+func NewMemoryAttachmentStore() *MemoryAttachmentStore {
+	return &MemoryAttachmentStore{blobs: make(map[string][]byte)}
+}
+
+// Put implements AttachmentStore
+func (s *MemoryAttachmentStore) Put(key string, data []byte) error {
+	if !validAttachmentKey(key) {
+		return ErrAttachmentKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = slices.Clone(data)
+	return nil
+}
+
+// Get implements AttachmentStore
+func (s *MemoryAttachmentStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, exists := s.blobs[key]
+	if !exists {
+		return nil, ErrAttachmentNotFound
+	}
+	return slices.Clone(data), nil
+}
+
+// Delete implements AttachmentStore
+func (s *MemoryAttachmentStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+// Len returns the number of stored attachments
+func (s *MemoryAttachmentStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.blobs)
+}
+
+// FileAttachmentStore keeps each attachment as a file under Dir, at the path its key
+// names. Files are written under a temporary name and renamed into place, so a reader
+// never sees a partial blob.
+type FileAttachmentStore struct {
+	Dir string
+}
+
+// Put implements AttachmentStore
+func (s FileAttachmentStore) Put(key string, data []byte) error {
+	if !validAttachmentKey(key) {
+		return ErrAttachmentKey
+	}
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".attachment-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err = errors.Join(err, f.Sync(), f.Close()); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get implements AttachmentStore
+func (s FileAttachmentStore) Get(key string) ([]byte, error) {
+	if !validAttachmentKey(key) {
+		return nil, ErrAttachmentKey
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrAttachmentNotFound
+	}
+	return data, err
+}
+
+// Delete implements AttachmentStore
+func (s FileAttachmentStore) Delete(key string) error {
+	if !validAttachmentKey(key) {
+		return ErrAttachmentKey
+	}
+	err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+// internal/wallet/attachments_test.go
+package wallet
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAttachmentStores(t *testing.T) {
+	stores := map[string]AttachmentStore{
+		"memory": NewMemoryAttachmentStore(),
+		"file":   FileAttachmentStore{Dir: t.TempDir()},
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Put("cases/case-1/evid-1", []byte("%PDF-1.7")); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if got, err := store.Get("cases/case-1/evid-1"); err != nil || !bytes.Equal(got, []byte("%PDF-1.7")) {
+				t.Errorf("Get() = %q, %v; want the stored blob", got, err)
+			}
+			for _, key := range []string{"", "/abs", "a/../b", "a//b", "a b", `a\b`} {
+				if err := store.Put(key, nil); err != ErrAttachmentKey {
+					t.Errorf("Put(%q) error = %v, want %v", key, err, ErrAttachmentKey)
+				}
+			}
+			if err := store.Delete("cases/case-1/evid-1"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if err := store.Delete("cases/case-1/evid-1"); err != nil {
+				t.Errorf("second Delete() error = %v, want nil", err)
+			}
+			if _, err := store.Get("cases/case-1/evid-1"); err != ErrAttachmentNotFound {
+				t.Errorf("Get() after delete error = %v, want %v", err, ErrAttachmentNotFound)
+			}
+		})
+	}
+}
+
+func TestAttachCaseEvidence_Policy(t *testing.T) {
+	store := NewMemoryAttachmentStore()
+	ws := NewWalletService(WithAttachmentStore(store, AttachmentPolicy{MaxSize: 8, ContentTypes: []string{"application/pdf"}}))
+	caseID := holdForReview(t, ws)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		wantErr     error
+	}{
+		{"allowed", "application/pdf", "%PDF", nil},
+		{"parameters ignored", "Application/PDF; name=x.pdf", "%PDF", nil},
+		{"too large", "application/pdf", "%PDF-1.7 long", ErrAttachmentTooLarge},
+		{"type not allowed", "application/x-msdownload", "MZ", ErrAttachmentType},
+		{"malformed type", "pdf;;", "%PDF", ErrAttachmentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ws.AttachCaseEvidence(caseID, CaseEvidence{Name: "doc", ContentType: tt.contentType, Data: []byte(tt.data)})
+			if err != tt.wantErr {
+				t.Errorf("AttachCaseEvidence() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	c, _ := ws.GetCase(caseID)
+	if len(c.Evidence) != 2 || store.Len() != 2 {
+		t.Fatalf("evidence = %d records, %d blobs; want 2 of each", len(c.Evidence), store.Len())
+	}
+	e := c.Evidence[1]
+	if e.ContentType != "application/pdf" || e.Size != 4 || e.Data != nil || len(e.Checksum) != 64 {
+		t.Errorf("evidence = %+v, want normalized type, size 4, checksum and no data", e)
+	}
+	if data, err := ws.GetCaseEvidence(caseID, e.ID); err != nil || string(data) != "%PDF" {
+		t.Errorf("GetCaseEvidence() = %q, %v; want %%PDF", data, err)
+	}
+
+	store.Put(evidenceKey(caseID, e.ID), []byte("%PDX"))
+	if _, err := ws.GetCaseEvidence(caseID, e.ID); err != ErrAttachmentCorrupt {
+		t.Errorf("GetCaseEvidence() of tampered blob error = %v, want %v", err, ErrAttachmentCorrupt)
+	}
+}
+
+func TestResolveCase_PurgesEvidence(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+	}{
+		{"at close", 0},
+		{"after retention", 30 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ws, clock := newTestService(WithAttachmentStore(FileAttachmentStore{Dir: dir}, AttachmentPolicy{Retention: tt.retention}))
+			caseID := holdForReview(t, ws)
+			if err := ws.AttachCaseEvidence(caseID, CaseEvidence{Name: "id.png", ContentType: "image/png", Data: []byte("PNG")}); err != nil {
+				t.Fatalf("AttachCaseEvidence() error = %v", err)
+			}
+			c, _ := ws.GetCase(caseID)
+			path := filepath.Join(dir, "cases", caseID, c.Evidence[0].ID)
+
+			if _, err := ws.ResolveCase(caseID, "reviewer1", true, "cleared"); err != nil {
+				t.Fatalf("ResolveCase() error = %v", err)
+			}
+			if tt.retention > 0 {
+				if _, err := os.Stat(path); err != nil {
+					t.Fatalf("blob deleted before retention passed: %v", err)
+				}
+				clock.Advance(tt.retention)
+				ws.RunDueJobs()
+			}
+
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("blob still stored after purge: %v", err)
+			}
+			c, _ = ws.GetCase(caseID)
+			if len(c.Evidence) != 1 || c.Evidence[0].PurgedAt != clock.Now().Unix() {
+				t.Errorf("evidence = %+v, want the record kept and marked purged", c.Evidence)
+			}
+			if _, err := ws.GetCaseEvidence(caseID, c.Evidence[0].ID); err != ErrAttachmentPurged {
+				t.Errorf("GetCaseEvidence() after purge error = %v, want %v", err, ErrAttachmentPurged)
+			}
+		})
+	}
+}
+
+// holdForReview has ws hold a transfer for review and returns its case
+func holdForReview(t *testing.T, ws *WalletService) string {
+	t.Helper()
+	ws.CreateUser("sender", "Sender", "s@example.com")
+	ws.CreateUser("recipient", "Recipient", "r@example.com")
+	ws.Deposit("sender", 100, "seed")
+	ws.RegisterHoldRule("all", func(op *Operation) string { return "review everything" })
+
+	if err := ws.Transfer("sender", "recipient", 50, "payment"); err != ErrTransferHeld {
+		t.Fatalf("Transfer() error = %v, want %v", err, ErrTransferHeld)
+	}
+	cases := ws.ListCases(CaseOpen)
+	if len(cases) != 1 {
+		t.Fatalf("ListCases() = %d cases, want 1", len(cases))
+	}
+	return cases[0].ID
+}
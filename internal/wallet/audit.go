@@ -0,0 +1,165 @@
+// internal/wallet/audit.go
+package wallet
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Actor is who performed an operation and the request it arrived in
+type Actor struct {
+	ID        string // principal acting; empty for in-process callers
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// actorKey is the context key of the acting Actor
+type actorKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying actor. The Context variants of the
+// service methods record it in the audit log.
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx. A missing request ID is taken
+// from ctx's trace ID.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	if actor.RequestID == "" {
+		actor.RequestID = TraceIDFromContext(ctx)
+	}
+	return actor
+}
+
+// AuditEntry records one operation, who performed it and how it ended. Entries are
+// never changed once appended.
+type AuditEntry struct {
+	ID            string
+	At            int64
+	Actor         Actor
+	Operation     string   // e.g. "deposit", "transfer", "create_user"
+	UserIDs       []string // wallets the operation was aimed at
+	TransactionID string   // transaction it created, if any
+	Error         string   // empty when the operation succeeded
+}
+
+// AuditQuery selects audit entries. Zero fields match everything; Until is exclusive.
+type AuditQuery struct {
+	ActorID string
+	Since   int64
+	Until   int64
+	Limit   int // most entries returned, oldest first; 0 for all
+}
+
+// matches reports whether e is selected by q
+func (q AuditQuery) matches(e AuditEntry) bool {
+	return (q.ActorID == "" || e.Actor.ID == q.ActorID) &&
+		(q.Since == 0 || e.At >= q.Since) &&
+		(q.Until == 0 || e.At < q.Until)
+}
+
+// AuditStore is an append-only log of audit entries, kept apart from the transaction
+// log. QueryAudit returns matching entries in the order they were appended.
+type AuditStore interface {
+	AppendAudit(entry AuditEntry) error
+	QueryAudit(q AuditQuery) ([]AuditEntry, error)
+}
+
+// WithAuditStore keeps the audit log in s instead of in memory
+func WithAuditStore(s AuditStore) Option {
+	return func(ws *WalletService) {
+		ws.auditLog = s
+	}
+}
+
+// RecordAudit appends entry to the audit log, filling in its ID and time and, when
+// entry has no actor, the actor carried by ctx. The service records its own operations;
+// embedders use RecordAudit for operations it cannot see the caller of.
+func (ws *WalletService) RecordAudit(ctx context.Context, entry AuditEntry) error {
+	entry.ID = ws.newID("audit")
+	entry.At = ws.now().Unix()
+	if entry.Actor == (Actor{}) {
+		entry.Actor = ActorFromContext(ctx)
+	}
+	entry.UserIDs = slices.Clone(entry.UserIDs)
+
+	if err := ws.auditLog.AppendAudit(entry); err != nil {
+		ws.metrics.IncCounter("audit_write_failures_total", nil)
+		return err
+	}
+	return nil
+}
+
+// QueryAuditLog returns the audit entries matching q, oldest first
+func (ws *WalletService) QueryAuditLog(q AuditQuery) ([]AuditEntry, error) {
+	return ws.auditLog.QueryAudit(q)
+}
+
+// audit records an operation performed on behalf of ctx. A failed audit write does not
+// undo the operation; it is counted in audit_write_failures_total.
+func (ws *WalletService) audit(ctx context.Context, operation string, tx *Transaction, err error, userIDs ...string) {
+	entry := AuditEntry{Operation: operation, UserIDs: userIDs}
+	if tx != nil {
+		entry.TransactionID = tx.ID
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	ws.RecordAudit(ctx, entry)
+}
+
+// auditTx records the money movement tx on behalf of ctx under its type, with the
+// wallets it touched. Its ID is recorded only when it was logged.
+func (ws *WalletService) auditTx(ctx context.Context, tx *Transaction, err error) {
+	var userIDs []string
+	for _, id := range []string{tx.FromUserID, tx.ToUserID} {
+		if id != "" && !slices.Contains(userIDs, id) {
+			userIDs = append(userIDs, id)
+		}
+	}
+	logged := tx
+	if err != nil {
+		logged = nil
+	}
+	ws.audit(ctx, string(tx.Type), logged, err, userIDs...)
+}
+
+// MemoryAuditLog is an in-memory AuditStore, the service's default
+type MemoryAuditLog struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+}
+
+// NewMemoryAuditLog creates an empty in-memory audit log
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+// AppendAudit adds entry to the end of the log
+func (l *MemoryAuditLog) AppendAudit(entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+// QueryAudit returns the entries matching q in the order they were appended
+func (l *MemoryAuditLog) QueryAudit(q AuditQuery) ([]AuditEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var out []AuditEntry
+	for _, e := range l.entries {
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+		if q.matches(e) {
+			e.UserIDs = slices.Clone(e.UserIDs)
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
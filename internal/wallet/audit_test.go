@@ -0,0 +1,139 @@
+// internal/wallet/audit_test.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAuditLog_RecordsActors(t *testing.T) {
+	ws, clock := newTestService()
+	ops := ContextWithActor(context.Background(), Actor{ID: "ops", IP: "10.0.0.1", UserAgent: "console/1.0", RequestID: "req-1"})
+	app := ContextWithTraceID(ContextWithActor(context.Background(), Actor{ID: "alice-app", IP: "192.0.2.7"}), "trace-9")
+
+	ws.CreateUserContext(ops, "alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	clock.Advance(time.Hour)
+	ws.DepositContext(ops, "alice", decimal.NewFromInt(50), "seed")
+	ws.TransferContext(app, "alice", "bob", decimal.NewFromInt(80), "too much")
+	clock.Advance(time.Hour)
+	ws.TransferContext(app, "alice", "bob", decimal.NewFromInt(20), "rent")
+
+	all, err := ws.QueryAuditLog(AuditQuery{})
+	if err != nil || len(all) != 5 {
+		t.Fatalf("QueryAuditLog() = %+v, %v, want five entries", all, err)
+	}
+	if e := all[0]; e.Operation != "create_user" || e.Actor != (Actor{ID: "ops", IP: "10.0.0.1", UserAgent: "console/1.0", RequestID: "req-1"}) {
+		t.Errorf("first entry = %+v", e)
+	}
+	if e := all[1]; e.Operation != "create_user" || e.Actor.ID != "" {
+		t.Errorf("in-process entry = %+v, want no actor", e)
+	}
+	if e := all[3]; e.Actor.RequestID != "trace-9" || e.TransactionID != "" || e.Error != ErrInsufficientBalance.Error() {
+		t.Errorf("failed transfer entry = %+v", e)
+	}
+	history, _ := ws.GetTransactionHistory("bob")
+	if e := all[4]; e.TransactionID != history[0].ID || len(e.UserIDs) != 2 || e.UserIDs[1] != "bob" {
+		t.Errorf("transfer entry = %+v, want transaction %s", e, history[0].ID)
+	}
+
+	start := clock.Now().Add(-2 * time.Hour).Unix()
+	tests := []struct {
+		name    string
+		query   AuditQuery
+		wantOps []string
+	}{
+		{"by actor", AuditQuery{ActorID: "ops"}, []string{"create_user", "deposit"}},
+		{"since", AuditQuery{Since: start + 3600}, []string{"deposit", "transfer", "transfer"}},
+		{"until", AuditQuery{Until: start + 3600}, []string{"create_user", "create_user"}},
+		{"actor in range", AuditQuery{ActorID: "alice-app", Since: start + 7200}, []string{"transfer"}},
+		{"limit", AuditQuery{Since: start + 3600, Limit: 1}, []string{"deposit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := ws.QueryAuditLog(tt.query)
+			var ops []string
+			for _, e := range got {
+				ops = append(ops, e.Operation)
+			}
+			if len(ops) != len(tt.wantOps) {
+				t.Fatalf("QueryAuditLog() operations = %v, want %v", ops, tt.wantOps)
+			}
+			for i := range ops {
+				if ops[i] != tt.wantOps[i] {
+					t.Errorf("QueryAuditLog() operations = %v, want %v", ops, tt.wantOps)
+				}
+			}
+		})
+	}
+}
+
+// failingAuditStore refuses every write
+type failingAuditStore struct{ MemoryAuditLog }
+
+func (s *failingAuditStore) AppendAudit(AuditEntry) error { return errors.New("disk full") }
+
+func TestAuditLog_WriteFailureKeepsOperation(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithAuditStore(&failingAuditStore{}), WithMetrics(metrics))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	if err := ws.Deposit("alice", 10, "seed"); err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	if b, _ := ws.GetBalance("alice"); b != 10 {
+		t.Errorf("balance = %v, want 10", b)
+	}
+	if n := metrics.Counter("audit_write_failures_total", nil); n != 2 {
+		t.Errorf("audit_write_failures_total = %d, want 2", n)
+	}
+	if err := ws.RecordAudit(context.Background(), AuditEntry{Operation: "export"}); err == nil {
+		t.Error("RecordAudit() succeeded against a failing store")
+	}
+}
+
+func TestAuditLog_CoversEveryMoneyMovement(t *testing.T) {
+	rates := StaticRateProvider{"USD/EUR": decimal.RequireFromString("0.9")}
+	ws := NewWalletService(WithRateProvider(rates))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.SetUserAttributes("bob", UserAttributes{Country: "KE"})
+	ops := ContextWithActor(context.Background(), Actor{ID: "ops"})
+
+	ws.Mint("alice", decimal.NewFromInt(100))
+	ws.SetBalance("alice", decimal.NewFromInt(90), ReasonReconciliation)
+	ws.BatchPayout("alice", []Payout{{UserID: "bob", Amount: decimal.NewFromInt(10)}}, "prizes")
+	h, _ := ws.Hold("alice", decimal.NewFromInt(5))
+	ws.CaptureHold(h.ID, decimal.NewFromInt(5))
+	q, _ := ws.QuoteConversion("alice", "USD", "EUR", decimal.NewFromInt(10))
+	ws.ConvertWithQuote(q.ID)
+	ws.Burn("bob", decimal.NewFromInt(1))
+	ws.DepositIdempotentContext(ops, "k-1", "bob", decimal.NewFromInt(3), "refund")
+	ws.FreezeSegmentContext(ops, SegmentActionRequest{Filter: SegmentFilter{Country: "KE"}, Actor: "ops", Reason: "review"})
+
+	all, _ := ws.QueryAuditLog(AuditQuery{})
+	var got []string
+	for _, e := range all[2:] {
+		got = append(got, e.Operation)
+	}
+	want := []string{"mint", "set_balance", "batch_payout", "hold_capture", "conversion", "burn", "deposit", "freeze_segment"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("audited operations = %v, want %v", got, want)
+	}
+	for _, e := range all[2:] {
+		if e.Error != "" || (e.TransactionID == "" && e.Operation != "freeze_segment") {
+			t.Errorf("entry = %+v, want a successful movement with its transaction", e)
+		}
+	}
+	if e := all[len(all)-2]; e.Actor.ID != "ops" {
+		t.Errorf("idempotent deposit actor = %q, want ops", e.Actor.ID)
+	}
+	if e := all[len(all)-1]; e.Actor.ID != "ops" || len(e.UserIDs) != 1 || e.UserIDs[0] != "bob" {
+		t.Errorf("segment freeze entry = %+v", e)
+	}
+}
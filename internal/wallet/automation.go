@@ -0,0 +1,445 @@
+// internal/wallet/automation.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+// Error definitions for automation rules
+var (
+	ErrRuleNotFound   = errors.New("automation rule not found")
+	ErrInvalidRule    = errors.New("invalid automation rule")
+	ErrRuleChainDepth = errors.New("automation chain too deep")
+)
+
+// MaxAutomationDepth bounds how many automation-created transactions may trigger further
+// rules in a chain, so rules that feed each other cannot loop forever
+const MaxAutomationDepth = 3
+
+// Metadata keys recorded on transactions created by automation rules
+const (
+	metaAutomationRule  = "automation_rule"
+	metaAutomationDepth = "automation_depth"
+)
+
+// TriggerKind selects what fires an automation rule
+type TriggerKind string
+
+const (
+	// TriggerIncomingCredit fires when the wallet receives more than Threshold
+	TriggerIncomingCredit TriggerKind = "incoming_credit"
+	// TriggerBalanceBelow fires when the balance drops below Threshold; it re-arms once
+	// the balance is back at or above Threshold
+	TriggerBalanceBelow TriggerKind = "balance_below"
+	// TriggerMonthly fires on DayOfMonth every month
+	TriggerMonthly TriggerKind = "monthly"
+)
+
+// ActionKind selects what an automation rule does when fired
+type ActionKind string
+
+const (
+	// ActionTransfer moves funds to ToUserID, e.g. a savings wallet or a biller
+	ActionTransfer ActionKind = "transfer"
+	// ActionNotify sends Message to the rule owner
+	ActionNotify ActionKind = "notify"
+)
+
+// AutomationTrigger describes when a rule fires
+type AutomationTrigger struct {
+	Kind       TriggerKind
+	Threshold  decimal.Decimal
+	DayOfMonth int
+}
+
+// AutomationAction describes what a rule does. For transfers Amount is fixed unless
+// Percent is set, in which case the amount is that percentage of the triggering credit.
+type AutomationAction struct {
+	Kind     ActionKind
+	ToUserID string
+	Amount   decimal.Decimal
+	Percent  decimal.Decimal
+	Message  string
+}
+
+// AutomationRule is a user-defined if-this-then-that rule on their wallet
+type AutomationRule struct {
+	ID        string
+	UserID    string
+	Name      string
+	Trigger   AutomationTrigger
+	Action    AutomationAction
+	Paused    bool
+	CreatedAt int64
+}
+
+// RuleExecution records one firing of a rule
+type RuleExecution struct {
+	RuleID        string
+	EventOffset   int64 // triggering event; zero for scheduled triggers
+	Timestamp     int64
+	TransactionID string
+	Error         string
+}
+
+// automationRule is a rule with its runtime state
+type automationRule struct {
+	rule       AutomationRule
+	triggered  bool // balance-below rules stay quiet until re-armed
+	jobID      string
+	executions []RuleExecution
+}
+
+// automationEngine holds rules and the event cursor
+type automationEngine struct {
+	mu      sync.Mutex
+	rules   map[string]*automationRule
+	cursor  int64
+	process sync.Mutex // serializes ProcessAutomations passes
+}
+
+// CreateAutomationRule adds a rule to userID's wallet
+func (ws *WalletService) CreateAutomationRule(userID, name string, trigger AutomationTrigger, action AutomationAction) (*AutomationRule, error) {
+	if err := validateRule(userID, trigger, action); err != nil {
+		return nil, err
+	}
+
+	ws.mu.RLock()
+	_, ownerExists := ws.users[userID]
+	_, targetExists := ws.users[action.ToUserID]
+	ws.mu.RUnlock()
+
+	if !ownerExists || (action.Kind == ActionTransfer && !targetExists) {
+		return nil, ErrUserNotFound
+	}
+
+	r := &automationRule{
+		rule: AutomationRule{
+			ID:        ws.newID("rule"),
+			UserID:    userID,
+			Name:      name,
+			Trigger:   trigger,
+			Action:    action,
+			CreatedAt: ws.now().Unix(),
+		},
+	}
+
+	if trigger.Kind == TriggerMonthly {
+		now := ws.now()
+		first := monthDay(now.Year(), now.Month(), trigger.DayOfMonth, time.Date(0, 1, 1, 0, 0, 0, 0, now.Location()))
+		if !first.After(now) {
+			first = Monthly(trigger.DayOfMonth)(first)
+		}
+		ruleID := r.rule.ID
+		r.jobID = ws.schedule("automation", userID, first, Monthly(trigger.DayOfMonth), func(now time.Time) error {
+			return ws.fireScheduledRule(ruleID)
+		})
+	}
+
+	ws.automation.mu.Lock()
+	defer ws.automation.mu.Unlock()
+
+	if ws.automation.rules == nil {
+		ws.automation.rules = make(map[string]*automationRule)
+		// Rules only react to events emitted after the first rule exists
+		ws.automation.cursor = ws.LatestEventOffset()
+	}
+	ws.automation.rules[r.rule.ID] = r
+
+	copied := r.rule
+	return &copied, nil
+}
+
+// DeleteAutomationRule removes a rule and its schedule
+func (ws *WalletService) DeleteAutomationRule(userID, ruleID string) error {
+	ws.automation.mu.Lock()
+	defer ws.automation.mu.Unlock()
+
+	r, exists := ws.automation.rules[ruleID]
+	if !exists || r.rule.UserID != userID {
+		return ErrRuleNotFound
+	}
+	if r.jobID != "" {
+		ws.CancelJob(r.jobID)
+	}
+	delete(ws.automation.rules, ruleID)
+	return nil
+}
+
+// SetAutomationRulePaused pauses or resumes a rule
+func (ws *WalletService) SetAutomationRulePaused(userID, ruleID string, paused bool) error {
+	ws.automation.mu.Lock()
+	defer ws.automation.mu.Unlock()
+
+	r, exists := ws.automation.rules[ruleID]
+	if !exists || r.rule.UserID != userID {
+		return ErrRuleNotFound
+	}
+	r.rule.Paused = paused
+	return nil
+}
+
+// ListAutomationRules returns a user's rules, oldest first
+func (ws *WalletService) ListAutomationRules(userID string) []AutomationRule {
+	ws.automation.mu.Lock()
+	defer ws.automation.mu.Unlock()
+
+	var rules []AutomationRule
+	for _, r := range ws.automation.rules {
+		if r.rule.UserID == userID {
+			rules = append(rules, r.rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].CreatedAt != rules[j].CreatedAt {
+			return rules[i].CreatedAt < rules[j].CreatedAt
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules
+}
+
+// GetRuleExecutions returns the execution history of a rule, oldest first
+func (ws *WalletService) GetRuleExecutions(ruleID string) ([]RuleExecution, error) {
+	ws.automation.mu.Lock()
+	defer ws.automation.mu.Unlock()
+
+	r, exists := ws.automation.rules[ruleID]
+	if !exists {
+		return nil, ErrRuleNotFound
+	}
+	return append([]RuleExecution(nil), r.executions...), nil
+}
+
+// ProcessAutomations evaluates event-driven rules against every event emitted since the
+// previous pass and runs the actions of rules that fire. Actions run outside all locks.
+func (ws *WalletService) ProcessAutomations() []RuleExecution {
+	ws.automation.process.Lock()
+	defer ws.automation.process.Unlock()
+
+	var executions []RuleExecution
+	for {
+		ws.automation.mu.Lock()
+		cursor := ws.automation.cursor
+		ws.automation.mu.Unlock()
+
+		events := ws.EventsSince(cursor, 100)
+		if len(events) == 0 {
+			return executions
+		}
+
+		for _, evt := range events {
+			for _, r := range ws.matchingRules(evt) {
+				executions = append(executions, ws.runRule(r, evt))
+			}
+			ws.automation.mu.Lock()
+			ws.automation.cursor = evt.Offset
+			ws.automation.mu.Unlock()
+		}
+	}
+}
+
+// matchingRules returns the rules that fire for evt
+func (ws *WalletService) matchingRules(evt Event) []AutomationRule {
+	tx := evt.Transaction
+	if tx == nil {
+		return nil
+	}
+	depth := automationDepth(tx)
+
+	ws.automation.mu.Lock()
+	candidates := make([]*automationRule, 0, len(ws.automation.rules))
+	for _, r := range ws.automation.rules {
+		if r.rule.Paused || r.rule.Trigger.Kind == TriggerMonthly {
+			continue
+		}
+		if r.rule.UserID != tx.FromUserID && r.rule.UserID != tx.ToUserID {
+			continue
+		}
+		// A rule never reacts to the transactions it created itself
+		if tx.Metadata[metaAutomationRule] == r.rule.ID {
+			continue
+		}
+		candidates = append(candidates, r)
+	}
+	ws.automation.mu.Unlock()
+
+	var fired []AutomationRule
+	for _, r := range candidates {
+		if ws.ruleFires(r, evt) {
+			if depth >= MaxAutomationDepth {
+				ws.recordExecution(r.rule.ID, RuleExecution{
+					RuleID:      r.rule.ID,
+					EventOffset: evt.Offset,
+					Timestamp:   ws.now().Unix(),
+					Error:       ErrRuleChainDepth.Error(),
+				})
+				continue
+			}
+			fired = append(fired, r.rule)
+		}
+	}
+	sort.Slice(fired, func(i, j int) bool { return fired[i].ID < fired[j].ID })
+	return fired
+}
+
+// ruleFires evaluates a rule's trigger against evt, updating balance-below arming state
+func (ws *WalletService) ruleFires(r *automationRule, evt Event) bool {
+	trigger := r.rule.Trigger
+	switch trigger.Kind {
+	case TriggerIncomingCredit:
+		return evt.Transaction.balanceEffect(r.rule.UserID, DefaultCurrency).GreaterThan(trigger.Threshold)
+	case TriggerBalanceBelow:
+		balance, known := evt.Balances[r.rule.UserID]
+		if !known {
+			return false
+		}
+		ws.automation.mu.Lock()
+		defer ws.automation.mu.Unlock()
+		if balance.GreaterThanOrEqual(trigger.Threshold) {
+			r.triggered = false
+			return false
+		}
+		if r.triggered {
+			return false
+		}
+		r.triggered = true
+		return true
+	}
+	return false
+}
+
+// fireScheduledRule runs a monthly rule from the scheduler
+func (ws *WalletService) fireScheduledRule(ruleID string) error {
+	ws.automation.mu.Lock()
+	r, exists := ws.automation.rules[ruleID]
+	if !exists || r.rule.Paused {
+		ws.automation.mu.Unlock()
+		return nil
+	}
+	rule := r.rule
+	ws.automation.mu.Unlock()
+
+	exec := ws.runRule(rule, Event{})
+	if exec.Error != "" {
+		return errors.New(exec.Error)
+	}
+	return nil
+}
+
+// runRule executes a rule's action and records the outcome
+func (ws *WalletService) runRule(rule AutomationRule, evt Event) RuleExecution {
+	exec := RuleExecution{
+		RuleID:      rule.ID,
+		EventOffset: evt.Offset,
+		Timestamp:   ws.now().Unix(),
+	}
+
+	var depth int
+	var trigger *Transaction
+	if evt.Transaction != nil {
+		trigger = evt.Transaction
+		depth = automationDepth(trigger)
+	}
+
+	action := rule.Action
+	switch action.Kind {
+	case ActionTransfer:
+		amount := action.Amount
+		if !action.Percent.IsZero() && trigger != nil {
+			credit := trigger.balanceEffect(rule.UserID, DefaultCurrency)
+			amount = moneymath.Percent(credit, action.Percent, ws.currencyPrecision(DefaultCurrency), moneymath.HalfUp)
+		}
+		tx, err := ws.transfer(context.Background(), rule.UserID, action.ToUserID, amount, "automation: "+rule.Name, transferOptions{
+			metadata: map[string]string{
+				metaAutomationRule:  rule.ID,
+				metaAutomationDepth: strconv.Itoa(depth + 1),
+			},
+			priority: PriorityBatch,
+		})
+		if tx != nil {
+			exec.TransactionID = tx.ID
+		}
+		if err != nil {
+			exec.Error = err.Error()
+		}
+	case ActionNotify:
+		err := ws.notify(Notification{
+			UserID:    rule.UserID,
+			Type:      "automation",
+			Subject:   rule.Name,
+			Message:   action.Message,
+			Data:      map[string]string{"rule_id": rule.ID, "event_offset": fmt.Sprint(evt.Offset)},
+			Timestamp: exec.Timestamp,
+		})
+		if err != nil {
+			exec.Error = err.Error()
+		}
+	}
+
+	ws.recordExecution(rule.ID, exec)
+	return exec
+}
+
+// recordExecution appends to a rule's execution history
+func (ws *WalletService) recordExecution(ruleID string, exec RuleExecution) {
+	ws.automation.mu.Lock()
+	defer ws.automation.mu.Unlock()
+
+	if r, exists := ws.automation.rules[ruleID]; exists {
+		r.executions = append(r.executions, exec)
+	}
+}
+
+// automationDepth returns how many automation hops produced tx
+func automationDepth(tx *Transaction) int {
+	depth, _ := strconv.Atoi(tx.Metadata[metaAutomationDepth])
+	return depth
+}
+
+// validateRule checks that a rule's trigger and action are well formed
+func validateRule(userID string, trigger AutomationTrigger, action AutomationAction) error {
+	switch trigger.Kind {
+	case TriggerIncomingCredit, TriggerBalanceBelow:
+		if trigger.Threshold.IsNegative() {
+			return ErrInvalidRule
+		}
+	case TriggerMonthly:
+		if trigger.DayOfMonth < 1 || trigger.DayOfMonth > 31 {
+			return ErrInvalidRule
+		}
+	default:
+		return ErrInvalidRule
+	}
+
+	switch action.Kind {
+	case ActionTransfer:
+		if action.ToUserID == "" || action.ToUserID == userID {
+			return ErrInvalidRule
+		}
+		if action.Percent.IsZero() && action.Amount.LessThanOrEqual(decimal.Zero) {
+			return ErrInvalidRule
+		}
+		if !action.Percent.IsZero() && (trigger.Kind != TriggerIncomingCredit ||
+			action.Percent.LessThanOrEqual(decimal.Zero) || action.Percent.GreaterThan(decimal.NewFromInt(100))) {
+			return ErrInvalidRule
+		}
+	case ActionNotify:
+		if action.Message == "" {
+			return ErrInvalidRule
+		}
+	default:
+		return ErrInvalidRule
+	}
+	return nil
+}
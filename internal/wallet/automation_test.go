@@ -0,0 +1,147 @@
+// internal/wallet/automation_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAutomation_IncomingCreditMovesToSavings(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("savings", "Savings", "savings@example.com")
+	ws.CreateUser("employer", "Employer", "employer@example.com")
+	ws.Deposit("employer", 10000, "seed")
+
+	rule, err := ws.CreateAutomationRule("alice", "save 10% of pay",
+		AutomationTrigger{Kind: TriggerIncomingCredit, Threshold: decimal.NewFromInt(1000)},
+		AutomationAction{Kind: ActionTransfer, ToUserID: "savings", Percent: decimal.NewFromInt(10)})
+	if err != nil {
+		t.Fatalf("CreateAutomationRule() error = %v", err)
+	}
+
+	ws.Transfer("employer", "alice", 500, "small")
+	ws.Transfer("employer", "alice", 3000, "salary")
+	ws.ProcessAutomations()
+
+	if b, _ := ws.GetBalanceDecimal("savings"); !b.Equal(decimal.NewFromInt(300)) {
+		t.Errorf("savings balance = %s, want 300", b)
+	}
+
+	execs, _ := ws.GetRuleExecutions(rule.ID)
+	if len(execs) != 1 || execs[0].TransactionID == "" || execs[0].Error != "" {
+		t.Errorf("executions = %+v, want one successful run", execs)
+	}
+
+	// Already processed events are not evaluated again
+	if again := ws.ProcessAutomations(); len(again) != 0 {
+		t.Errorf("second pass ran %d rules, want 0", len(again))
+	}
+}
+
+func TestAutomation_BalanceBelowFiresOncePerCrossing(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, _ := newTestService(WithNotifier(notifier))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 200, "seed")
+
+	ws.CreateAutomationRule("alice", "low balance",
+		AutomationTrigger{Kind: TriggerBalanceBelow, Threshold: decimal.NewFromInt(100)},
+		AutomationAction{Kind: ActionNotify, Message: "balance is low"})
+
+	ws.Withdraw("alice", 150, "rent")
+	ws.Withdraw("alice", 10, "coffee")
+	ws.ProcessAutomations()
+	if sent := notifier.all(); len(sent) != 1 {
+		t.Fatalf("notifications = %d, want 1 while below threshold", len(sent))
+	}
+
+	ws.Deposit("alice", 500, "top up")
+	ws.Withdraw("alice", 500, "spend")
+	ws.ProcessAutomations()
+	if sent := notifier.all(); len(sent) != 2 {
+		t.Errorf("notifications = %d, want 2 after re-arming", len(sent))
+	}
+}
+
+func TestAutomation_LoopProtection(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("savings", "Savings", "savings@example.com")
+	ws.CreateUser("employer", "Employer", "employer@example.com")
+	ws.Deposit("employer", 10000, "seed")
+
+	// Two rules that bounce every incoming credit back and forth
+	ws.CreateAutomationRule("alice", "forward",
+		AutomationTrigger{Kind: TriggerIncomingCredit, Threshold: decimal.Zero},
+		AutomationAction{Kind: ActionTransfer, ToUserID: "savings", Amount: decimal.NewFromInt(1)})
+	back, _ := ws.CreateAutomationRule("savings", "back",
+		AutomationTrigger{Kind: TriggerIncomingCredit, Threshold: decimal.Zero},
+		AutomationAction{Kind: ActionTransfer, ToUserID: "alice", Amount: decimal.NewFromInt(1)})
+
+	ws.Transfer("employer", "alice", 10, "start")
+	ws.ProcessAutomations()
+
+	// employer -> alice -> savings -> alice -> savings, then the chain is cut
+	execs, _ := ws.GetRuleExecutions(back.ID)
+	if len(execs) != 2 || execs[1].Error != ErrRuleChainDepth.Error() {
+		t.Errorf("back executions = %+v, want one run then a chain depth stop", execs)
+	}
+	if b, _ := ws.GetBalanceDecimal("savings"); !b.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("savings balance = %s, want 1", b)
+	}
+}
+
+func TestAutomation_MonthlyAutoPay(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("utility", "Utility", "utility@example.com")
+	ws.Deposit("alice", 1000, "seed")
+
+	rule, err := ws.CreateAutomationRule("alice", "electricity",
+		AutomationTrigger{Kind: TriggerMonthly, DayOfMonth: 1},
+		AutomationAction{Kind: ActionTransfer, ToUserID: "utility", Amount: decimal.NewFromInt(80)})
+	if err != nil {
+		t.Fatalf("CreateAutomationRule() error = %v", err)
+	}
+
+	// The fake clock starts on Jan 1 at noon, so the first run is Feb 1
+	clock.Advance(31 * 24 * time.Hour)
+	ws.RunDueJobs()
+	ws.SetAutomationRulePaused("alice", rule.ID, true)
+	clock.Advance(29 * 24 * time.Hour)
+	ws.RunDueJobs()
+
+	if b, _ := ws.GetBalanceDecimal("utility"); !b.Equal(decimal.NewFromInt(80)) {
+		t.Errorf("utility balance = %s, want 80", b)
+	}
+}
+
+func TestCreateAutomationRule_Validation(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("savings", "Savings", "savings@example.com")
+
+	tests := []struct {
+		name    string
+		trigger AutomationTrigger
+		action  AutomationAction
+		wantErr error
+	}{
+		{"unknown trigger", AutomationTrigger{Kind: "hourly"}, AutomationAction{Kind: ActionNotify, Message: "x"}, ErrInvalidRule},
+		{"bad day", AutomationTrigger{Kind: TriggerMonthly, DayOfMonth: 32}, AutomationAction{Kind: ActionNotify, Message: "x"}, ErrInvalidRule},
+		{"transfer to self", AutomationTrigger{Kind: TriggerMonthly, DayOfMonth: 1}, AutomationAction{Kind: ActionTransfer, ToUserID: "alice", Amount: decimal.NewFromInt(1)}, ErrInvalidRule},
+		{"percent on monthly", AutomationTrigger{Kind: TriggerMonthly, DayOfMonth: 1}, AutomationAction{Kind: ActionTransfer, ToUserID: "savings", Percent: decimal.NewFromInt(5)}, ErrInvalidRule},
+		{"unknown target", AutomationTrigger{Kind: TriggerMonthly, DayOfMonth: 1}, AutomationAction{Kind: ActionTransfer, ToUserID: "ghost", Amount: decimal.NewFromInt(1)}, ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.CreateAutomationRule("alice", tt.name, tt.trigger, tt.action); err != tt.wantErr {
+				t.Errorf("CreateAutomationRule() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// internal/wallet/autosettle.go
+package wallet
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// SetAutoSettle turns the auto-settle rule on or off for a user's wallet. With the rule
+// on, every foreign-currency credit is converted into the wallet's base currency at the
+// RateProvider's rate as soon as it arrives.
+func (ws *WalletService) SetAutoSettle(userID string, enabled bool) error {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	wallet.mu.Lock()
+	wallet.AutoSettle = enabled
+	wallet.mu.Unlock()
+	ws.persistWallet(wallet)
+
+	return nil
+}
+
+// GetAutoSettle reports whether a user's wallet auto-settles foreign credits
+func (ws *WalletService) GetAutoSettle(userID string) (bool, error) {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return false, ErrUserNotFound
+	}
+
+	wallet.mu.RLock()
+	defer wallet.mu.RUnlock()
+
+	return wallet.AutoSettle, nil
+}
+
+// autoSettle converts a just-posted foreign credit into the wallet's base currency and
+// records the conversion linked to the credit. The credit itself always stands: when no
+// rate is available or a validator rejects the conversion, the funds stay in the foreign
+// currency and the skip is counted. Caller must hold the owner's user lock.
+func (ws *WalletService) autoSettle(wallet *Wallet, credit *Transaction) {
+	base := wallet.Currency
+
+	rate, err := ws.settlementRate(credit.Currency, base)
+	if err != nil {
+		ws.metrics.IncCounter("auto_settle_skipped_total", map[string]string{"currency": credit.Currency})
+		return
+	}
+
+	conversion := &Transaction{
+		ID:          ws.newID("tx"),
+		FromUserID:  credit.ToUserID,
+		ToUserID:    credit.ToUserID,
+		Amount:      credit.Amount,
+		Currency:    credit.Currency,
+		Type:        TransactionConversion,
+		Description: "auto-settle " + credit.ID,
+		ToAmount:    credit.Amount.Mul(rate).Round(ws.currencyPrecision(base)),
+		ToCurrency:  base,
+		Rate:        rate,
+		ParentTxID:  credit.ID,
+		Metadata:    map[string]string{"rate_id": ws.observeRate(credit.Currency, base, rate)},
+	}
+	if err := ws.validate(conversion); err != nil {
+		ws.metrics.IncCounter("auto_settle_skipped_total", map[string]string{"currency": credit.Currency})
+		return
+	}
+
+	wallet.mu.Lock()
+	wallet.adjust(conversion.Currency, conversion.Amount.Neg())
+	wallet.adjust(base, conversion.ToAmount)
+	wallet.publish()
+	wallet.mu.Unlock()
+
+	conversion.Timestamp = ws.now().Unix()
+	ws.recordTransaction(conversion)
+	ws.metrics.IncCounter("auto_settle_total", map[string]string{"currency": credit.Currency})
+}
+
+// settlementRate returns the rate for converting from into to, checking both currencies
+// are convertible
+func (ws *WalletService) settlementRate(from, to string) (decimal.Decimal, error) {
+	for _, code := range []string{from, to} {
+		c, err := ws.GetCurrency(code)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if !c.Convertible {
+			return decimal.Zero, ErrCurrencyNotConvertible
+		}
+	}
+	if ws.rates == nil {
+		return decimal.Zero, ErrRateUnavailable
+	}
+	rate, err := ws.rates.Rate(from, to)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if rate.LessThanOrEqual(decimal.Zero) {
+		return decimal.Zero, ErrRateUnavailable
+	}
+	return rate, nil
+}
@@ -0,0 +1,90 @@
+// internal/wallet/autosettle_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAutoSettle_ConvertsForeignCredits(t *testing.T) {
+	rates := StaticRateProvider{"EUR/USD": decimal.RequireFromString("1.1")}
+	ws := NewWalletService(WithRateProvider(rates))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	if err := ws.SetAutoSettle("alice", true); err != nil {
+		t.Fatalf("SetAutoSettle() error = %v", err)
+	}
+	if err := ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(50), "invoice"); err != nil {
+		t.Fatalf("DepositCurrency() error = %v", err)
+	}
+
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(55)) {
+		t.Errorf("USD balance = %s, want 55", b)
+	}
+	if b, _ := ws.GetCurrencyBalance("alice", "EUR"); !b.IsZero() {
+		t.Errorf("EUR balance = %s, want 0", b)
+	}
+
+	history, _ := ws.GetTransactionHistory("alice")
+	if len(history) != 2 {
+		t.Fatalf("history has %d transactions, want 2", len(history))
+	}
+	credit, conversion := history[0], history[1]
+	if credit.Type != TransactionDeposit || credit.Currency != "EUR" {
+		t.Errorf("credit = %+v, want EUR deposit", credit)
+	}
+	if conversion.Type != TransactionConversion || conversion.ParentTxID != credit.ID {
+		t.Errorf("conversion = %+v, want conversion linked to %s", conversion, credit.ID)
+	}
+	if !conversion.ToAmount.Equal(decimal.NewFromInt(55)) || conversion.ToCurrency != "USD" {
+		t.Errorf("conversion credited %s %s, want 55 USD", conversion.ToAmount, conversion.ToCurrency)
+	}
+
+	if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+}
+
+func TestAutoSettle_LeavesCreditWhenNotSettled(t *testing.T) {
+	tests := []struct {
+		name       string
+		autoSettle bool
+		rates      RateProvider
+	}{
+		{"rule off", false, StaticRateProvider{"EUR/USD": decimal.RequireFromString("1.1")}},
+		{"no rate", true, StaticRateProvider{}},
+		{"no provider", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := NewWalletService()
+			if tt.rates != nil {
+				ws = NewWalletService(WithRateProvider(tt.rates))
+			}
+			ws.CreateUser("alice", "Alice", "alice@example.com")
+			ws.SetAutoSettle("alice", tt.autoSettle)
+
+			if err := ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(50), ""); err != nil {
+				t.Fatalf("DepositCurrency() error = %v", err)
+			}
+			if b, _ := ws.GetCurrencyBalance("alice", "EUR"); !b.Equal(decimal.NewFromInt(50)) {
+				t.Errorf("EUR balance = %s, want 50", b)
+			}
+			if history, _ := ws.GetTransactionHistory("alice"); len(history) != 1 {
+				t.Errorf("history has %d transactions, want 1", len(history))
+			}
+		})
+	}
+}
+
+func TestSetAutoSettle_UnknownUser(t *testing.T) {
+	ws := NewWalletService()
+	if err := ws.SetAutoSettle("ghost", true); err != ErrUserNotFound {
+		t.Errorf("SetAutoSettle() error = %v, want %v", err, ErrUserNotFound)
+	}
+	if _, err := ws.GetAutoSettle("ghost"); err != ErrUserNotFound {
+		t.Errorf("GetAutoSettle() error = %v, want %v", err, ErrUserNotFound)
+	}
+}
@@ -0,0 +1,155 @@
+// internal/wallet/backup.go
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// SnapshotVersion is the format version written by Snapshot
+const SnapshotVersion = 1
+
+// ErrUnsupportedSnapshot is returned when restoring a snapshot of an unknown version
+var ErrUnsupportedSnapshot = errors.New("unsupported snapshot version")
+
+// WalletSnapshot is the persisted form of a wallet
+type WalletSnapshot struct {
+	UserID   string
+	Currency string
+	Balance  decimal.Decimal
+	Foreign  map[string]decimal.Decimal
+
+	AutoSettle bool
+}
+
+// Snapshot is a point-in-time copy of all users, wallets and transactions
+type Snapshot struct {
+	Version      int
+	CreatedAt    int64
+	LogPosition  int // number of transactions ever logged when the snapshot was cut
+	Users        []User
+	Wallets      []WalletSnapshot
+	Transactions []Transaction
+
+	// Supply and InTransit carry the tracked money supply per currency at LogPosition
+	Supply    map[string]decimal.Decimal
+	InTransit map[string]decimal.Decimal
+
+	// System carries each system account's balance per currency at LogPosition
+	System map[string]map[string]decimal.Decimal
+
+	// Rates is the history of exchange rates used by conversions
+	Rates []RateRecord
+}
+
+// Snapshot captures a consistent copy of the service state. Every user lock is held
+// while copying so no operation is half-applied in the result. Transactions already
+// moved to an ArchiveStore are not included.
+func (ws *WalletService) Snapshot() *Snapshot {
+	ws.mu.RLock()
+	userIDs := make([]string, 0, len(ws.users))
+	for id := range ws.users {
+		userIDs = append(userIDs, id)
+	}
+	ws.mu.RUnlock()
+
+	unlock := ws.lockUsers(PriorityReporting, userIDs...)
+	defer unlock()
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	snap := &Snapshot{
+		Version:      SnapshotVersion,
+		CreatedAt:    ws.now().Unix(),
+		LogPosition:  ws.logBase + len(ws.transactions),
+		Users:        make([]User, 0, len(ws.users)),
+		Wallets:      make([]WalletSnapshot, 0, len(ws.wallets)),
+		Transactions: make([]Transaction, 0, len(ws.transactions)),
+		Supply:       copyAmounts(ws.supply.supply),
+		InTransit:    copyAmounts(ws.supply.inTransit),
+		System:       copySystemBalances(ws.supply.system),
+		Rates:        ws.fxRates.snapshot(),
+	}
+
+	for _, user := range ws.users {
+		snap.Users = append(snap.Users, *user)
+	}
+	sort.Slice(snap.Users, func(i, j int) bool { return snap.Users[i].ID < snap.Users[j].ID })
+
+	for _, wallet := range ws.wallets {
+		wallet.mu.RLock()
+		snap.Wallets = append(snap.Wallets, wallet.snapshot())
+		wallet.mu.RUnlock()
+	}
+	sort.Slice(snap.Wallets, func(i, j int) bool { return snap.Wallets[i].UserID < snap.Wallets[j].UserID })
+
+	for _, tx := range ws.transactions {
+		snap.Transactions = append(snap.Transactions, *tx)
+	}
+
+	return snap
+}
+
+// RestoreSnapshot builds a new service from a snapshot
+func RestoreSnapshot(snap *Snapshot, opts ...Option) (*WalletService, error) {
+	if snap.Version != SnapshotVersion {
+		return nil, ErrUnsupportedSnapshot
+	}
+
+	ws := NewWalletService(opts...)
+	for i := range snap.Users {
+		user := snap.Users[i]
+		ws.users[user.ID] = &user
+	}
+	for _, w := range snap.Wallets {
+		foreign := make(map[string]decimal.Decimal, len(w.Foreign))
+		for currency, amount := range w.Foreign {
+			foreign[currency] = amount
+		}
+		ws.registerWallet(&Wallet{
+			UserID:   w.UserID,
+			Currency: w.Currency,
+			Balance:  w.Balance,
+			Foreign:  foreign,
+
+			AutoSettle: w.AutoSettle,
+		})
+	}
+	for i := range snap.Transactions {
+		tx := snap.Transactions[i]
+		ws.transactions = append(ws.transactions, &tx)
+		ws.retention.hotBytes += transactionSize(&tx)
+		ws.indexTransaction(&tx)
+	}
+	ws.restoreSupply(snap)
+	ws.rebuildEmailIndex()
+	ws.rebuildActivity()
+	history, err := ws.fullHistory()
+	if err != nil {
+		return nil, err
+	}
+	ws.relinkArchived(history)
+	ws.rebuildRefunds(history)
+	ws.fxRates.restore(snap.Rates)
+
+	return ws, nil
+}
+
+// Backup writes a JSON snapshot of the service to w
+func (ws *WalletService) Backup(w io.Writer) error {
+	return json.NewEncoder(w).Encode(ws.Snapshot())
+}
+
+// Restore reads a backup written by Backup and returns the restored service
+func Restore(r io.Reader, opts ...Option) (*WalletService, error) {
+	var snap Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+	return RestoreSnapshot(&snap, opts...)
+}
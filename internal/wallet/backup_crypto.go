@@ -0,0 +1,226 @@
+// internal/wallet/backup_crypto.go
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Error definitions for encrypted backups
+var (
+	ErrUnknownMasterKey = errors.New("unknown master key")
+	ErrBackupIntegrity  = errors.New("backup integrity check failed")
+)
+
+// encryptedBackupVersion is the envelope format version
+const encryptedBackupVersion = 1
+
+// KeyWrapper wraps per-backup data keys with a master key, e.g. a KMS or HSM.
+// Implementations must keep retired master keys available for UnwrapKey so that
+// backups taken before a rotation remain restorable.
+type KeyWrapper interface {
+	WrapKey(dataKey []byte) (keyID string, wrapped []byte, err error)
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// EncryptedBackup is the envelope written by EncryptedBackup
+type EncryptedBackup struct {
+	Version    int
+	KeyID      string // master key that wrapped DataKey
+	DataKey    []byte // wrapped per-backup data key
+	Nonce      []byte
+	Ciphertext []byte
+	MAC        []byte // HMAC over every other field, keyed from the data key
+}
+
+// EncryptedBackup writes a snapshot encrypted with a fresh data key, which is itself
+// wrapped by the current master key of kw
+func (ws *WalletService) EncryptedBackup(w io.Writer, kw KeyWrapper) error {
+	var plain bytes.Buffer
+	if err := ws.Backup(&plain); err != nil {
+		return err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+
+	env := &EncryptedBackup{Version: encryptedBackupVersion}
+	if err := env.seal(dataKey, plain.Bytes()); err != nil {
+		return err
+	}
+	if err := env.wrap(dataKey, kw); err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(env)
+}
+
+// RestoreEncryptedBackup verifies and decrypts a backup written by EncryptedBackup
+func RestoreEncryptedBackup(r io.Reader, kw KeyWrapper, opts ...Option) (*WalletService, error) {
+	env, dataKey, err := openEnvelope(r, kw)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := env.open(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return Restore(bytes.NewReader(plain), opts...)
+}
+
+// RotateBackupKey rewraps a backup's data key under kw's current master key without
+// decrypting the payload, so backups can be migrated off a retired master key
+func RotateBackupKey(r io.Reader, w io.Writer, kw KeyWrapper) error {
+	env, dataKey, err := openEnvelope(r, kw)
+	if err != nil {
+		return err
+	}
+	if err := env.wrap(dataKey, kw); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(env)
+}
+
+// openEnvelope decodes an envelope, unwraps its data key and checks its MAC
+func openEnvelope(r io.Reader, kw KeyWrapper) (*EncryptedBackup, []byte, error) {
+	var env EncryptedBackup
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return nil, nil, err
+	}
+	if env.Version != encryptedBackupVersion {
+		return nil, nil, ErrUnsupportedSnapshot
+	}
+
+	dataKey, err := kw.UnwrapKey(env.KeyID, env.DataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hmac.Equal(env.mac(dataKey), env.MAC) {
+		return nil, nil, ErrBackupIntegrity
+	}
+
+	return &env, dataKey, nil
+}
+
+// seal encrypts plain with dataKey using AES-256-GCM
+func (env *EncryptedBackup) seal(dataKey, plain []byte) error {
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return err
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plain, nil)
+	return nil
+}
+
+// open decrypts the payload with dataKey
+func (env *EncryptedBackup) open(dataKey []byte) ([]byte, error) {
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrBackupIntegrity
+	}
+	return plain, nil
+}
+
+// wrap wraps dataKey under the current master key and refreshes the MAC
+func (env *EncryptedBackup) wrap(dataKey []byte, kw KeyWrapper) error {
+	keyID, wrapped, err := kw.WrapKey(dataKey)
+	if err != nil {
+		return err
+	}
+	env.KeyID, env.DataKey = keyID, wrapped
+	env.MAC = env.mac(dataKey)
+	return nil
+}
+
+// mac authenticates the envelope header and ciphertext
+func (env *EncryptedBackup) mac(dataKey []byte) []byte {
+	macKey := sha256.Sum256(append([]byte("wallet-backup-mac:"), dataKey...))
+	h := hmac.New(sha256.New, macKey[:])
+	h.Write(canonicalFields(fmt.Sprint(env.Version), env.KeyID, string(env.DataKey),
+		string(env.Nonce), string(env.Ciphertext)))
+	return h.Sum(nil)
+}
+
+// newGCM builds an AES-GCM AEAD for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// StaticKeyring is a KeyWrapper backed by in-process master keys. New backups are
+// wrapped with the current key; retired keys stay available for unwrapping.
+type StaticKeyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyring creates a keyring whose current master key is keys[currentID].
+// Keys must be 16, 24 or 32 bytes long.
+func NewStaticKeyring(currentID string, keys map[string][]byte) (*StaticKeyring, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, ErrUnknownMasterKey
+	}
+	for _, key := range keys {
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, err
+		}
+	}
+	return &StaticKeyring{current: currentID, keys: keys}, nil
+}
+
+// WrapKey encrypts dataKey with the current master key
+func (k *StaticKeyring) WrapKey(dataKey []byte) (string, []byte, error) {
+	gcm, err := newGCM(k.keys[k.current])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return k.current, gcm.Seal(nonce, nonce, dataKey, []byte(k.current)), nil
+}
+
+// UnwrapKey decrypts a data key wrapped by the named master key
+func (k *StaticKeyring) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownMasterKey
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, ErrBackupIntegrity
+	}
+	nonce, sealed := wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():]
+	dataKey, err := gcm.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, ErrBackupIntegrity
+	}
+	return dataKey, nil
+}
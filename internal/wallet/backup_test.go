@@ -0,0 +1,109 @@
+// internal/wallet/backup_test.go
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// seedBackup gives ws a little history to back up
+func seedBackup(ws *WalletService) {
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 100.10, "deposit")
+	ws.DepositCurrency("user1", "EUR", decimal.NewFromInt(5), "euros")
+	ws.Transfer("user1", "user2", 40.05, "transfer")
+}
+
+// assertRestored checks that a restored service matches seedBackup
+func assertRestored(t *testing.T, restored *WalletService) {
+	t.Helper()
+
+	balance, err := restored.GetBalanceDecimal("user1")
+	if err != nil || !balance.Equal(decimal.RequireFromString("60.05")) {
+		t.Errorf("Expected user1 balance 60.05, got %s (%v)", balance.String(), err)
+	}
+	eur, _ := restored.GetCurrencyBalance("user1", "EUR")
+	if !eur.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("Expected 5 EUR, got %s", eur.String())
+	}
+	history, _ := restored.GetTransactionHistory("user2")
+	if len(history) != 1 {
+		t.Errorf("Expected 1 transaction for user2, got %d", len(history))
+	}
+	if mismatch, _ := restored.CheckWalletIntegrity("user1"); mismatch != nil {
+		t.Errorf("Restored ledger mismatch: %+v", mismatch)
+	}
+}
+
+// TestWalletService_BackupRestore tests the plain JSON backup round trip
+func TestWalletService_BackupRestore(t *testing.T) {
+	ws := NewWalletService()
+	seedBackup(ws)
+	var buf bytes.Buffer
+	if err := ws.Backup(&buf); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	restored, err := Restore(&buf)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	assertRestored(t, restored)
+
+	if _, err := RestoreSnapshot(&Snapshot{Version: 99}); err != ErrUnsupportedSnapshot {
+		t.Errorf("Expected ErrUnsupportedSnapshot, got %v", err)
+	}
+}
+
+// TestWalletService_EncryptedBackupRotation tests envelope encryption, tamper detection
+// and restoring old backups after a master key rotation
+func TestWalletService_EncryptedBackupRotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	before, _ := NewStaticKeyring("k1", map[string][]byte{"k1": oldKey})
+	ws := NewWalletService()
+	seedBackup(ws)
+	var backup bytes.Buffer
+	if err := ws.EncryptedBackup(&backup, before); err != nil {
+		t.Fatalf("EncryptedBackup() error = %v", err)
+	}
+	if bytes.Contains(backup.Bytes(), []byte("john@example.com")) {
+		t.Fatal("Backup contains plaintext user data")
+	}
+
+	// After rotation the keyring wraps with k2 but can still unwrap k1 backups
+	after, _ := NewStaticKeyring("k2", map[string][]byte{"k1": oldKey, "k2": newKey})
+	restored, err := RestoreEncryptedBackup(bytes.NewReader(backup.Bytes()), after)
+	if err != nil {
+		t.Fatalf("RestoreEncryptedBackup() error = %v", err)
+	}
+	assertRestored(t, restored)
+
+	var rotated bytes.Buffer
+	if err := RotateBackupKey(bytes.NewReader(backup.Bytes()), &rotated, after); err != nil {
+		t.Fatalf("RotateBackupKey() error = %v", err)
+	}
+	onlyNew, _ := NewStaticKeyring("k2", map[string][]byte{"k2": newKey})
+	restored, err = RestoreEncryptedBackup(bytes.NewReader(rotated.Bytes()), onlyNew)
+	if err != nil {
+		t.Fatalf("Restore after rotation error = %v", err)
+	}
+	assertRestored(t, restored)
+
+	if _, err := RestoreEncryptedBackup(bytes.NewReader(backup.Bytes()), onlyNew); err != ErrUnknownMasterKey {
+		t.Errorf("Expected ErrUnknownMasterKey for retired key, got %v", err)
+	}
+
+	var env EncryptedBackup
+	json.Unmarshal(rotated.Bytes(), &env)
+	env.Ciphertext[0] ^= 0xff
+	tampered, _ := json.Marshal(env)
+	if _, err := RestoreEncryptedBackup(bytes.NewReader(tampered), onlyNew); err != ErrBackupIntegrity {
+		t.Errorf("Expected ErrBackupIntegrity for tampered backup, got %v", err)
+	}
+}
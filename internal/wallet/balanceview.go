@@ -0,0 +1,52 @@
+// internal/wallet/balanceview.go
+package wallet
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// walletView is an immutable copy of a wallet's holdings. Writers build a new view under
+// w.mu after every change and publish it atomically, so balance reads need no locks.
+type walletView struct {
+	balance decimal.Decimal
+	foreign map[string]decimal.Decimal // never modified once published
+}
+
+// balanceIn returns the view's holding in currency
+func (v *walletView) balanceIn(currency, base string) decimal.Decimal {
+	if currency == base {
+		return v.balance
+	}
+	return v.foreign[currency]
+}
+
+// publish makes the wallet's current holdings visible to lock-free readers. Caller must
+// hold w.mu for writing and call it after every change to Balance or Foreign.
+func (w *Wallet) publish() {
+	foreign := make(map[string]decimal.Decimal, len(w.Foreign))
+	for currency, amount := range w.Foreign {
+		foreign[currency] = amount
+	}
+	w.view.Store(&walletView{balance: w.Balance, foreign: foreign})
+}
+
+// registerWallet adds a wallet to the service and to the lock-free read index. Caller
+// must hold ws.mu for writing.
+func (ws *WalletService) registerWallet(w *Wallet) {
+	w.mu.Lock()
+	w.publish()
+	w.mu.Unlock()
+
+	ws.wallets[w.UserID] = w
+	ws.walletIndex.Store(w.UserID, w)
+}
+
+// loadView returns the last published view of userID's wallet without taking any lock
+func (ws *WalletService) loadView(userID string) (*walletView, string, error) {
+	v, exists := ws.walletIndex.Load(userID)
+	if !exists {
+		return nil, "", ErrUserNotFound
+	}
+	w := v.(*Wallet)
+	return w.view.Load(), w.Currency, nil
+}
@@ -0,0 +1,166 @@
+// internal/wallet/balanceview_test.go
+package wallet
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGetBalanceDecimal_ConcurrentReadsSeeCommittedBalances(t *testing.T) {
+	const users, perUser = 4, 100
+	ws := NewWalletService()
+	for i := 0; i < users; i++ {
+		id := fmt.Sprintf("user%d", i)
+		ws.CreateUser(id, id, id+"@example.com")
+		ws.Deposit(id, perUser, "seed")
+	}
+
+	stop := make(chan struct{})
+	var writers, readers sync.WaitGroup
+	for i := 0; i < users; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			from, to := fmt.Sprintf("user%d", i), fmt.Sprintf("user%d", (i+1)%users)
+			for n := 0; n < 200; n++ {
+				ws.Transfer(from, to, 1, "ring")
+				ws.Deposit(from, 0.5, "top up")
+				ws.Withdraw(from, 0.5, "take out")
+			}
+		}(i)
+	}
+
+	errs := make(chan error, users*2)
+	for r := 0; r < users*2; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			id := fmt.Sprintf("user%d", r%users)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				b, err := ws.GetBalanceDecimal(id)
+				if err != nil || b.IsNegative() || b.GreaterThan(decimal.NewFromInt(users*perUser+1)) {
+					errs <- fmt.Errorf("GetBalanceDecimal(%s) = %s, %v", id, b, err)
+					return
+				}
+				if _, err := ws.GetCurrencyBalance(id, DefaultCurrency); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(r)
+	}
+
+	writers.Wait()
+	close(stop)
+	readers.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	total := decimal.Zero
+	for i := 0; i < users; i++ {
+		b, _ := ws.GetBalanceDecimal(fmt.Sprintf("user%d", i))
+		total = total.Add(b)
+	}
+	if !total.Equal(decimal.NewFromInt(users * perUser)) {
+		t.Errorf("total balance = %s, want %d", total, users*perUser)
+	}
+}
+
+func TestGetBalanceDecimal_ViewTracksEveryWritePath(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.CreateUser("carol", "Carol", "carol@example.com")
+
+	tests := []struct {
+		name      string
+		op        func() error
+		wantAlice int64
+	}{
+		{"deposit", func() error { return ws.Deposit("alice", 100, "") }, 100},
+		{"withdraw", func() error { return ws.Withdraw("alice", 10, "") }, 90},
+		{"transfer", func() error { return ws.Transfer("alice", "bob", 20, "") }, 70},
+		{"batch payout", func() error {
+			_, err := ws.BatchPayout("alice", []Payout{{UserID: "bob", Amount: decimal.NewFromInt(5)}, {UserID: "carol", Amount: decimal.NewFromInt(5)}}, "")
+			return err
+		}, 60},
+		{"foreign deposit", func() error { return ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(7), "") }, 60},
+	}
+	for _, tt := range tests {
+		if err := tt.op(); err != nil {
+			t.Fatalf("%s: error = %v", tt.name, err)
+		}
+		if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(tt.wantAlice)) {
+			t.Errorf("after %s alice balance = %s, want %d", tt.name, b, tt.wantAlice)
+		}
+	}
+	if b, _ := ws.GetCurrencyBalance("alice", "eur"); !b.Equal(decimal.NewFromInt(7)) {
+		t.Errorf("alice EUR balance = %s, want 7", b)
+	}
+
+	restored, err := RestoreSnapshot(ws.Snapshot())
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	if b, _ := restored.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("restored bob balance = %s, want 25", b)
+	}
+	if _, err := restored.GetBalanceDecimal("ghost"); err != ErrUserNotFound {
+		t.Errorf("GetBalanceDecimal(ghost) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+// BenchmarkGetBalanceDecimal measures a single reader
+func BenchmarkGetBalanceDecimal(b *testing.B) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("user1", 100, "seed")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ws.GetBalanceDecimal("user1")
+	}
+}
+
+// BenchmarkGetBalanceDecimal_ParallelWithWriter measures readers racing a transfer loop
+func BenchmarkGetBalanceDecimal_ParallelWithWriter(b *testing.B) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 1000, "seed")
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				ws.Transfer("user1", "user2", 1, "bench")
+				ws.Transfer("user2", "user1", 1, "bench")
+			}
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ws.GetBalanceDecimal("user1")
+		}
+	})
+	b.StopTimer()
+	close(stop)
+	<-done
+}
@@ -0,0 +1,106 @@
+// internal/wallet/batching.go
+package wallet
+
+import (
+	"sync"
+	"time"
+)
+
+// Defaults for write batching
+const (
+	DefaultBatchMaxRecords = 64
+	DefaultBatchMaxDelay   = 2 * time.Millisecond
+)
+
+// WriteBatchPolicy bounds how transaction-log appends are grouped. A group is flushed
+// once MaxRecords are waiting or its first record has waited MaxDelay, whichever comes
+// first, so no write waits longer than MaxDelay plus the flush before it.
+type WriteBatchPolicy struct {
+	MaxRecords int           // DefaultBatchMaxRecords when zero
+	MaxDelay   time.Duration // DefaultBatchMaxDelay when zero
+}
+
+// logBatcher groups transactions waiting to be appended to the log. The writer that
+// opens a group leads it: it waits for the group to fill or time out, then appends the
+// whole group under one hold of ws.mu and one store write while the others wait.
+type logBatcher struct {
+	enabled bool
+	policy  WriteBatchPolicy
+
+	mu      sync.Mutex
+	group   *logGroup  // the group accepting records, nil when none is open
+	flushMu sync.Mutex // keeps groups in log order when a flush is slow
+}
+
+// logGroup is one batch of transactions appended together
+type logGroup struct {
+	txs    []*Transaction
+	opened time.Time
+	full   chan struct{} // closed when MaxRecords are waiting
+	done   chan struct{} // closed once the group is in the log
+}
+
+// WithWriteBatching groups transaction-log appends, and the store writes that go with
+// them, under burst load. Each write still returns only once its transaction is in the
+// log, after waiting at most policy's MaxDelay for others to join it, so under light
+// load batching adds up to MaxDelay to every write.
+func WithWriteBatching(policy WriteBatchPolicy) Option {
+	return func(ws *WalletService) {
+		if policy.MaxRecords <= 0 {
+			policy.MaxRecords = DefaultBatchMaxRecords
+		}
+		if policy.MaxDelay <= 0 {
+			policy.MaxDelay = DefaultBatchMaxDelay
+		}
+		ws.batcher.policy = policy
+		ws.batcher.enabled = true
+	}
+}
+
+// appendBatched adds tx to the open group, or opens one, and returns once the group is
+// in the log
+func (ws *WalletService) appendBatched(tx *Transaction) {
+	b := &ws.batcher
+	b.mu.Lock()
+	g := b.group
+	leader := g == nil
+	if leader {
+		g = &logGroup{opened: time.Now(), full: make(chan struct{}), done: make(chan struct{})}
+		b.group = g
+	}
+	g.txs = append(g.txs, tx)
+	if len(g.txs) == b.policy.MaxRecords {
+		close(g.full)
+	}
+	b.mu.Unlock()
+
+	if !leader {
+		<-g.done
+		return
+	}
+
+	timer := time.NewTimer(b.policy.MaxDelay)
+	select {
+	case <-g.full:
+	case <-timer.C:
+	}
+	timer.Stop()
+
+	// Close the group before waiting for the previous flush, so writers arriving in
+	// the meantime start the next group instead of growing this one unbounded
+	b.mu.Lock()
+	b.group = nil
+	b.mu.Unlock()
+
+	b.flushMu.Lock()
+	waited := time.Since(g.opened)
+	start := time.Now()
+	ws.appendToLog(g.txs)
+	b.flushMu.Unlock()
+	close(g.done)
+
+	ws.metrics.IncCounter("log_batches_total", nil)
+	ws.metrics.ObserveValue("log_batch_size", float64(len(g.txs)), nil)
+	ws.metrics.ObserveValue("log_batch_wait_seconds", waited.Seconds(), nil)
+	ws.metrics.ObserveValue("log_batch_flush_seconds", time.Since(start).Seconds(), nil)
+}
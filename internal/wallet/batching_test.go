@@ -0,0 +1,114 @@
+// internal/wallet/batching_test.go
+package wallet
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWriteBatching_BurstLoad(t *testing.T) {
+	file, err := NewFileStore(filepath.Join(t.TempDir(), "wallet.log"), true)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer file.Close()
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithStore(file), WithMetrics(metrics), WithWriteBatching(WriteBatchPolicy{MaxRecords: 8, MaxDelay: 20 * time.Millisecond}))
+
+	const users, perUser = 16, 5
+	for i := 0; i < users; i++ {
+		ws.CreateUser(fmt.Sprintf("u%d", i), "User", fmt.Sprintf("u%d@example.com", i))
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for j := 1; j <= perUser; j++ {
+				ws.Deposit(id, float64(j), "burst")
+				// Each write is in the log once it returns
+				if history, _ := ws.GetTransactionHistory(id); len(history) != j {
+					t.Errorf("%s history = %d entries after %d deposits", id, len(history), j)
+				}
+			}
+		}(fmt.Sprintf("u%d", i))
+	}
+	wg.Wait()
+
+	batches := metrics.Counter("log_batches_total", nil)
+	if batches == 0 || batches >= users*perUser {
+		t.Errorf("log_batches_total = %d for %d writes, want them grouped", batches, users*perUser)
+	}
+	if size := metrics.Value("log_batch_size", nil); size < 1 || size > 8+users {
+		t.Errorf("log_batch_size = %v", size)
+	}
+
+	// Events follow log order and the store holds every write, with no journal entry open
+	var offsets []string
+	for _, e := range ws.EventsSince(0, 0) {
+		if e.Transaction != nil {
+			offsets = append(offsets, e.Transaction.ID)
+		}
+	}
+	ws.mu.RLock()
+	for i, tx := range ws.transactions {
+		if i >= len(offsets) || offsets[i] != tx.ID {
+			t.Fatalf("event %d does not match log entry %s", i, tx.ID)
+		}
+	}
+	ws.mu.RUnlock()
+	if open, _ := file.OpenTransfers(); len(open) != 0 {
+		t.Errorf("open journal entries = %d, want 0", len(open))
+	}
+	reopened, err := OpenWalletService(file)
+	if err != nil {
+		t.Fatalf("OpenWalletService() error = %v", err)
+	}
+	for i := 0; i < users; i++ {
+		if b, _ := reopened.GetBalanceDecimal(fmt.Sprintf("u%d", i)); !b.Equal(decimal.NewFromInt(15)) {
+			t.Errorf("u%d reopened balance = %s, want 15", i, b)
+		}
+	}
+}
+
+func TestWriteBatching_Latency(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  WriteBatchPolicy
+		writers int
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		// A lone write waits out the delay for company
+		{"lone write waits the delay", WriteBatchPolicy{MaxRecords: 4, MaxDelay: 30 * time.Millisecond}, 1, 30 * time.Millisecond, 5 * time.Second},
+		// A full group flushes at once
+		{"full group flushes early", WriteBatchPolicy{MaxRecords: 4, MaxDelay: time.Minute}, 4, 0, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := NewWalletService(WithWriteBatching(tt.policy))
+			for i := 0; i < tt.writers; i++ {
+				ws.CreateUser(fmt.Sprintf("u%d", i), "User", fmt.Sprintf("u%d@example.com", i))
+			}
+
+			start := time.Now()
+			var wg sync.WaitGroup
+			for i := 0; i < tt.writers; i++ {
+				wg.Add(1)
+				go func(id string) {
+					defer wg.Done()
+					ws.Deposit(id, 1, "write")
+				}(fmt.Sprintf("u%d", i))
+			}
+			wg.Wait()
+			if elapsed := time.Since(start); elapsed < tt.atLeast || elapsed > tt.atMost {
+				t.Errorf("writes took %s, want between %s and %s", elapsed, tt.atLeast, tt.atMost)
+			}
+		})
+	}
+}
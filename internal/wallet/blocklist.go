@@ -0,0 +1,125 @@
+// internal/wallet/blocklist.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for counterparty blocking
+var (
+	ErrCounterpartyBlocked = errors.New("counterparty has been blocked by recipient")
+	ErrCannotBlockSelf     = errors.New("cannot block yourself")
+)
+
+// BlockedUser is an entry in a user's deny list
+type BlockedUser struct {
+	UserID    string
+	BlockedAt int64
+}
+
+// blockList tracks, per user, the counterparties they refuse to receive from
+type blockList struct {
+	mu     sync.RWMutex
+	blocks map[string]map[string]int64 // blocker -> blocked -> blocked at
+}
+
+// BlockUser adds blockedUserID to userID's deny list so that incoming transfers
+// and requests from that party are rejected
+func (ws *WalletService) BlockUser(userID, blockedUserID string) error {
+	if userID == blockedUserID {
+		return ErrCannotBlockSelf
+	}
+
+	ws.mu.RLock()
+	_, userExists := ws.users[userID]
+	_, blockedExists := ws.users[blockedUserID]
+	ws.mu.RUnlock()
+
+	if !userExists || !blockedExists {
+		return ErrUserNotFound
+	}
+
+	ws.blocks.mu.Lock()
+	defer ws.blocks.mu.Unlock()
+
+	if ws.blocks.blocks == nil {
+		ws.blocks.blocks = make(map[string]map[string]int64)
+	}
+	if ws.blocks.blocks[userID] == nil {
+		ws.blocks.blocks[userID] = make(map[string]int64)
+	}
+	if _, already := ws.blocks.blocks[userID][blockedUserID]; !already {
+		ws.blocks.blocks[userID][blockedUserID] = ws.now().Unix()
+	}
+
+	return nil
+}
+
+// UnblockUser removes blockedUserID from userID's deny list
+func (ws *WalletService) UnblockUser(userID, blockedUserID string) error {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	ws.blocks.mu.Lock()
+	defer ws.blocks.mu.Unlock()
+
+	delete(ws.blocks.blocks[userID], blockedUserID)
+	return nil
+}
+
+// GetBlockedUsers returns userID's deny list ordered by user ID
+func (ws *WalletService) GetBlockedUsers(userID string) ([]BlockedUser, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.blocks.mu.RLock()
+	defer ws.blocks.mu.RUnlock()
+
+	blocked := make([]BlockedUser, 0, len(ws.blocks.blocks[userID]))
+	for id, at := range ws.blocks.blocks[userID] {
+		blocked = append(blocked, BlockedUser{UserID: id, BlockedAt: at})
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].UserID < blocked[j].UserID
+	})
+
+	return blocked, nil
+}
+
+// IsBlocked reports whether userID has blocked counterpartyID
+func (ws *WalletService) IsBlocked(userID, counterpartyID string) bool {
+	ws.blocks.mu.RLock()
+	defer ws.blocks.mu.RUnlock()
+
+	_, blocked := ws.blocks.blocks[userID][counterpartyID]
+	return blocked
+}
+
+// AdminTransfer moves funds between users on behalf of an operator, bypassing
+// counterparty blocks. Balance and existence checks still apply.
+func (ws *WalletService) AdminTransfer(fromUserID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.AdminTransferContext(context.Background(), fromUserID, toUserID, amount, description)
+}
+
+// AdminTransferContext is AdminTransfer on behalf of a caller's ctx
+func (ws *WalletService) AdminTransferContext(ctx context.Context, fromUserID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ws.transfer(ctx, fromUserID, toUserID, amount, description, transferOptions{skipBlockCheck: true})
+}
@@ -0,0 +1,67 @@
+// internal/wallet/blocklist_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestWalletService_BlockUser tests that blocked counterparties cannot send transfers
+func TestWalletService_BlockUser(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 100.0, "deposit")
+	ws.Deposit("user2", 100.0, "deposit")
+
+	if err := ws.BlockUser("user1", "user2"); err != nil {
+		t.Fatalf("BlockUser() error = %v", err)
+	}
+
+	if err := ws.Transfer("user2", "user1", 10.0, "blocked transfer"); err != ErrCounterpartyBlocked {
+		t.Errorf("Expected ErrCounterpartyBlocked, got %v", err)
+	}
+
+	// Blocking is one-directional: the blocker can still pay the blocked party
+	if err := ws.Transfer("user1", "user2", 10.0, "outgoing transfer"); err != nil {
+		t.Errorf("Transfer() from blocker error = %v", err)
+	}
+
+	blocked, err := ws.GetBlockedUsers("user1")
+	if err != nil || len(blocked) != 1 || blocked[0].UserID != "user2" {
+		t.Errorf("GetBlockedUsers() = %+v, %v", blocked, err)
+	}
+
+	if _, err := ws.AdminTransfer("user2", "user1", decimal.NewFromInt(5), "admin override"); err != nil {
+		t.Errorf("AdminTransfer() error = %v", err)
+	}
+
+	if err := ws.UnblockUser("user1", "user2"); err != nil {
+		t.Fatalf("UnblockUser() error = %v", err)
+	}
+	if err := ws.Transfer("user2", "user1", 10.0, "after unblock"); err != nil {
+		t.Errorf("Transfer() after unblock error = %v", err)
+	}
+
+	balance, _ := ws.GetBalanceDecimal("user1")
+	if !balance.Equal(decimal.NewFromInt(105)) {
+		t.Errorf("Expected user1 balance 105, got %s", balance.String())
+	}
+}
+
+// TestWalletService_BlockUser_Errors tests validation of block list management
+func TestWalletService_BlockUser_Errors(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+
+	if err := ws.BlockUser("user1", "user1"); err != ErrCannotBlockSelf {
+		t.Errorf("Expected ErrCannotBlockSelf, got %v", err)
+	}
+	if err := ws.BlockUser("user1", "nonexistent"); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := ws.GetBlockedUsers("nonexistent"); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
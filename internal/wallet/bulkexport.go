@@ -0,0 +1,196 @@
+// internal/wallet/bulkexport.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// DefaultExportParallelism is the number of users exported at once when unset
+const DefaultExportParallelism = 4
+
+// ErrInvalidCheckpoint is returned when resuming from a checkpoint without a cutoff
+var ErrInvalidCheckpoint = errors.New("export checkpoint has no cutoff")
+
+// ExportSink receives the histories of a bulk export, one file per user
+type ExportSink interface {
+	// Create starts userID's file; committing it replaces any file from an earlier run
+	Create(userID string) (ExportFile, error)
+}
+
+// ExportFile is one user's history being written. Commit makes it visible once
+// complete; Abort discards it, so an interrupted job never leaves a truncated file.
+type ExportFile interface {
+	io.Writer
+	Commit() error
+	Abort() error
+}
+
+// ExportCheckpoint records the progress of a bulk export. Save it from
+// BulkExportOptions.OnCheckpoint and pass it back as Resume to continue after an
+// interruption: finished users are skipped and the rest are exported up to the same
+// cutoff, the second after the first run started, so the dataset is consistent across
+// runs.
+type ExportCheckpoint struct {
+	Cutoff int64    // entries at or after this Unix time are left out
+	Done   []string // users whose file is committed, sorted
+}
+
+// BulkExportOptions controls ExportAllHistories
+type BulkExportOptions struct {
+	Parallelism  int               // users exported at once; DefaultExportParallelism when unset
+	Resume       *ExportCheckpoint // progress of an interrupted run to continue
+	OnCheckpoint func(ExportCheckpoint) error
+}
+
+// ExportAllHistories streams every user's history as CSV to its own file in sink,
+// for compliance requests covering the whole user base. Users are exported in
+// parallel, at most opts.Parallelism at a time, and opts.OnCheckpoint is called after
+// each one finishes. The first failure, or ctx being done, stops the job; the returned
+// checkpoint then says where to resume.
+func (ws *WalletService) ExportAllHistories(ctx context.Context, sink ExportSink, opts BulkExportOptions) (ExportCheckpoint, error) {
+	progress := exportProgress{onCheckpoint: opts.OnCheckpoint}
+	if opts.Resume != nil {
+		if opts.Resume.Cutoff <= 0 {
+			return ExportCheckpoint{}, ErrInvalidCheckpoint
+		}
+		progress.checkpoint = ExportCheckpoint{Cutoff: opts.Resume.Cutoff, Done: slices.Sorted(slices.Values(opts.Resume.Done))}
+	} else {
+		progress.checkpoint.Cutoff = ws.now().Unix() + 1
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultExportParallelism
+	}
+
+	ws.mu.RLock()
+	var pending []string
+	for userID := range ws.users {
+		if _, done := slices.BinarySearch(progress.checkpoint.Done, userID); !done {
+			pending = append(pending, userID)
+		}
+	}
+	ws.mu.RUnlock()
+	sort.Strings(pending)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for range min(parallelism, len(pending)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range queue {
+				if err := ws.exportUser(ctx, sink, userID, progress.checkpoint.Cutoff); err != nil {
+					cancel(fmt.Errorf("exporting %s: %w", userID, err))
+					continue
+				}
+				if err := progress.done(userID); err != nil {
+					cancel(fmt.Errorf("saving checkpoint: %w", err))
+				}
+			}
+		}()
+	}
+feed:
+	for _, userID := range pending {
+		select {
+		case queue <- userID:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	status := "complete"
+	if ctx.Err() != nil {
+		status = "interrupted"
+	}
+	ws.metrics.IncCounter("bulk_exports_total", map[string]string{"status": status})
+	return progress.snapshot(), context.Cause(ctx)
+}
+
+// exportUser writes userID's history before cutoff to a new file in sink
+func (ws *WalletService) exportUser(ctx context.Context, sink ExportSink, userID string, cutoff int64) error {
+	f, err := sink.Create(userID)
+	if err != nil {
+		return err
+	}
+	if err := ws.exportHistory(ctx, userID, IterateOptions{Until: cutoff}, f); err != nil {
+		return errors.Join(err, f.Abort())
+	}
+	return f.Commit()
+}
+
+// exportProgress tracks the checkpoint of a running bulk export
+type exportProgress struct {
+	mu           sync.Mutex
+	checkpoint   ExportCheckpoint
+	onCheckpoint func(ExportCheckpoint) error
+}
+
+// done marks userID exported and saves the checkpoint. Saves are serialised, so each
+// saved checkpoint includes every earlier one.
+func (p *exportProgress) done(userID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i, _ := slices.BinarySearch(p.checkpoint.Done, userID)
+	p.checkpoint.Done = slices.Insert(p.checkpoint.Done, i, userID)
+	if p.onCheckpoint == nil {
+		return nil
+	}
+	return p.onCheckpoint(ExportCheckpoint{Cutoff: p.checkpoint.Cutoff, Done: slices.Clone(p.checkpoint.Done)})
+}
+
+// snapshot returns a copy of the checkpoint
+func (p *exportProgress) snapshot() ExportCheckpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ExportCheckpoint{Cutoff: p.checkpoint.Cutoff, Done: slices.Clone(p.checkpoint.Done)}
+}
+
+// DirExportSink writes each user's history to <dir>/<user>.csv. Files are written
+// under a temporary name and renamed into place on commit.
+type DirExportSink struct {
+	Dir string
+}
+
+// Create implements ExportSink
+func (s DirExportSink) Create(userID string) (ExportFile, error) {
+	f, err := os.CreateTemp(s.Dir, ".export-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &dirExportFile{File: f, path: filepath.Join(s.Dir, url.PathEscape(userID)+".csv")}, nil
+}
+
+// dirExportFile is a history being written by DirExportSink
+type dirExportFile struct {
+	*os.File
+	path string
+}
+
+// Commit syncs the file and renames it into place
+func (f *dirExportFile) Commit() error {
+	if err := errors.Join(f.Sync(), f.Close()); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), f.path)
+}
+
+// Abort removes the partial file
+func (f *dirExportFile) Abort() error {
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,116 @@
+// internal/wallet/bulkexport_test.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakySink is a DirExportSink that fails the first export of one user and counts
+// the files it creates
+type flakySink struct {
+	DirExportSink
+	failUser string
+
+	mu      sync.Mutex
+	created map[string]int
+}
+
+func (s *flakySink) Create(userID string) (ExportFile, error) {
+	s.mu.Lock()
+	s.created[userID]++
+	attempt := s.created[userID]
+	s.mu.Unlock()
+	if userID == s.failUser && attempt == 1 {
+		return nil, errors.New("disk full")
+	}
+	return s.DirExportSink.Create(userID)
+}
+
+func TestExportAllHistories(t *testing.T) {
+	ws, clock := newTestService()
+	for i := range 6 {
+		userID := fmt.Sprintf("user%d", i)
+		ws.CreateUser(userID, userID, userID+"@example.com")
+		for range i {
+			ws.Deposit(userID, 10, "seed")
+		}
+	}
+
+	dir := t.TempDir()
+	sink := &flakySink{DirExportSink: DirExportSink{Dir: dir}, failUser: "user3", created: map[string]int{}}
+	var saved []ExportCheckpoint
+	var mu sync.Mutex
+	opts := BulkExportOptions{Parallelism: 2, OnCheckpoint: func(c ExportCheckpoint) error {
+		mu.Lock()
+		defer mu.Unlock()
+		saved = append(saved, c)
+		return nil
+	}}
+
+	checkpoint, err := ws.ExportAllHistories(context.Background(), sink, opts)
+	if err == nil || !strings.Contains(err.Error(), "user3") {
+		t.Fatalf("first run error = %v, want the user3 failure", err)
+	}
+	if slices.Contains(checkpoint.Done, "user3") {
+		t.Errorf("checkpoint = %+v, want user3 unfinished", checkpoint)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "user3.csv")); !os.IsNotExist(err) {
+		t.Errorf("user3.csv stat error = %v, want no file", err)
+	}
+	if len(saved) != len(checkpoint.Done) || (len(saved) > 0 && !slices.Equal(saved[len(saved)-1].Done, checkpoint.Done)) {
+		t.Errorf("saved checkpoints = %+v, want one per finished user ending at %+v", saved, checkpoint)
+	}
+
+	// Entries after the first run's cutoff stay out of the resumed export
+	clock.Advance(time.Minute)
+	ws.Deposit("user5", 10, "late")
+
+	opts.Resume = &checkpoint
+	final, err := ws.ExportAllHistories(context.Background(), sink, opts)
+	if err != nil {
+		t.Fatalf("resumed run error = %v", err)
+	}
+	if final.Cutoff != checkpoint.Cutoff || len(final.Done) != 6 {
+		t.Errorf("final checkpoint = %+v, want all six users at the first cutoff", final)
+	}
+	for i := range 6 {
+		userID := fmt.Sprintf("user%d", i)
+		data, err := os.ReadFile(filepath.Join(dir, userID+".csv"))
+		if err != nil {
+			t.Fatalf("reading %s: %v", userID, err)
+		}
+		if rows := strings.Count(string(data), "\n") - 1; rows != i {
+			t.Errorf("%s rows = %d, want %d", userID, rows, i)
+		}
+		if slices.Contains(checkpoint.Done, userID) && sink.created[userID] != 1 {
+			t.Errorf("%s exported %d times, want once", userID, sink.created[userID])
+		}
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".export-*")); len(leftovers) != 0 {
+		t.Errorf("temporary files left = %v", leftovers)
+	}
+}
+
+func TestExportAllHistoriesCancelled(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	checkpoint, err := ws.ExportAllHistories(ctx, DirExportSink{Dir: t.TempDir()}, BulkExportOptions{})
+	if !errors.Is(err, context.Canceled) || len(checkpoint.Done) != 0 {
+		t.Errorf("ExportAllHistories() = %+v, %v; want nothing done and %v", checkpoint, err, context.Canceled)
+	}
+	if _, err := ws.ExportAllHistories(context.Background(), DirExportSink{}, BulkExportOptions{Resume: &ExportCheckpoint{}}); err != ErrInvalidCheckpoint {
+		t.Errorf("resume without cutoff error = %v, want %v", err, ErrInvalidCheckpoint)
+	}
+}
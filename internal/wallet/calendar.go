@@ -0,0 +1,162 @@
+// internal/wallet/calendar.go
+package wallet
+
+import (
+	"sync"
+	"time"
+)
+
+// RollConvention decides how a date falling on a non-business day is moved
+type RollConvention int
+
+const (
+	// Unadjusted leaves dates as they are
+	Unadjusted RollConvention = iota
+	// Following moves to the next business day
+	Following
+	// ModifiedFollowing moves to the next business day unless that crosses into the next
+	// month, in which case it moves to the previous business day
+	ModifiedFollowing
+	// Preceding moves to the previous business day
+	Preceding
+	// ModifiedPreceding moves to the previous business day unless that crosses into the
+	// previous month, in which case it moves to the next business day
+	ModifiedPreceding
+)
+
+// BusinessCalendar knows the weekend days and holidays of one region
+type BusinessCalendar struct {
+	Region string
+
+	mu       sync.RWMutex
+	weekend  map[time.Weekday]bool
+	holidays map[civilDate]string
+}
+
+// civilDate is a calendar day independent of time zone and time of day
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// dateOf returns the calendar day of t in t's own location
+func dateOf(t time.Time) civilDate {
+	y, m, d := t.Date()
+	return civilDate{y, m, d}
+}
+
+// NewBusinessCalendar creates a calendar for region. Weekend defaults to Saturday and Sunday.
+func NewBusinessCalendar(region string, weekend ...time.Weekday) *BusinessCalendar {
+	if len(weekend) == 0 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	cal := &BusinessCalendar{
+		Region:   region,
+		weekend:  make(map[time.Weekday]bool, len(weekend)),
+		holidays: make(map[civilDate]string),
+	}
+	for _, d := range weekend {
+		cal.weekend[d] = true
+	}
+	return cal
+}
+
+// AddHoliday marks the calendar day of date as a holiday
+func (c *BusinessCalendar) AddHoliday(date time.Time, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holidays[dateOf(date)] = name
+}
+
+// Holiday returns the holiday name for t's day, if any
+func (c *BusinessCalendar) Holiday(t time.Time) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.holidays[dateOf(t)]
+	return name, ok
+}
+
+// IsBusinessDay reports whether t falls on neither a weekend day nor a holiday
+func (c *BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.holidays[dateOf(t)]
+	return !holiday
+}
+
+// Roll moves t onto a business day per the convention, keeping its time of day
+func (c *BusinessCalendar) Roll(t time.Time, conv RollConvention) time.Time {
+	if conv == Unadjusted || c.IsBusinessDay(t) {
+		return t
+	}
+
+	switch conv {
+	case Following:
+		return c.step(t, 1)
+	case Preceding:
+		return c.step(t, -1)
+	case ModifiedFollowing:
+		if next := c.step(t, 1); next.Month() == t.Month() {
+			return next
+		}
+		return c.step(t, -1)
+	case ModifiedPreceding:
+		if prev := c.step(t, -1); prev.Month() == t.Month() {
+			return prev
+		}
+		return c.step(t, 1)
+	}
+	return t
+}
+
+// AddBusinessDays moves t forward (or backward when n is negative) by n business days
+func (c *BusinessCalendar) AddBusinessDays(t time.Time, n int) time.Time {
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		t = c.step(t, dir)
+	}
+	return t
+}
+
+// step returns the nearest business day strictly after (dir 1) or before (dir -1) t
+func (c *BusinessCalendar) step(t time.Time, dir int) time.Time {
+	for {
+		t = t.AddDate(0, 0, dir)
+		if c.IsBusinessDay(t) {
+			return t
+		}
+	}
+}
+
+// WithBusinessCalendar makes scheduled payments, settlement dates and interest accrual
+// honour cal, rolling non-business dates per conv
+func WithBusinessCalendar(cal *BusinessCalendar, conv RollConvention) Option {
+	return func(ws *WalletService) {
+		ws.calendar = cal
+		ws.rollConvention = conv
+	}
+}
+
+// rollDate adjusts t to a business day when a calendar is configured
+func (ws *WalletService) rollDate(t time.Time) time.Time {
+	if ws.calendar == nil {
+		return t
+	}
+	return ws.calendar.Roll(t, ws.rollConvention)
+}
+
+// SettlementDate returns the date funds initiated at t settle after lag business days.
+// Without a calendar every day counts as a business day.
+func (ws *WalletService) SettlementDate(t time.Time, lag int) time.Time {
+	if ws.calendar == nil {
+		return t.AddDate(0, 0, lag)
+	}
+	return ws.calendar.AddBusinessDays(ws.calendar.Roll(t, Following), lag)
+}
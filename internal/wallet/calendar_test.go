@@ -0,0 +1,99 @@
+// internal/wallet/calendar_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// day returns midnight UTC of the given date
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBusinessCalendar_Roll(t *testing.T) {
+	cal := NewBusinessCalendar("US")
+	cal.AddHoliday(day(2024, time.July, 4), "Independence Day")
+
+	tests := []struct {
+		name string
+		date time.Time
+		conv RollConvention
+		want time.Time
+	}{
+		{"business day unchanged", day(2024, time.July, 3), Following, day(2024, time.July, 3)},
+		{"unadjusted", day(2024, time.July, 6), Unadjusted, day(2024, time.July, 6)},
+		{"holiday following", day(2024, time.July, 4), Following, day(2024, time.July, 5)},
+		{"saturday following", day(2024, time.July, 6), Following, day(2024, time.July, 8)},
+		{"saturday preceding", day(2024, time.July, 6), Preceding, day(2024, time.July, 5)},
+		{"month end modified following", day(2024, time.August, 31), ModifiedFollowing, day(2024, time.August, 30)},
+		{"month end following", day(2024, time.August, 31), Following, day(2024, time.September, 2)},
+		{"month start modified preceding", day(2024, time.June, 1), ModifiedPreceding, day(2024, time.June, 3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.Roll(tt.date, tt.conv); !got.Equal(tt.want) {
+				t.Errorf("Roll(%s) = %s, want %s", tt.date.Format("Mon 2006-01-02"), got.Format("Mon 2006-01-02"), tt.want.Format("Mon 2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestBusinessCalendar_AddBusinessDays(t *testing.T) {
+	cal := NewBusinessCalendar("AE", time.Friday, time.Saturday)
+
+	// Thursday + 1 business day skips the Friday/Saturday weekend
+	if got := cal.AddBusinessDays(day(2024, time.January, 4), 1); !got.Equal(day(2024, time.January, 7)) {
+		t.Errorf("AddBusinessDays(+1) = %s, want Sunday 2024-01-07", got.Format("Mon 2006-01-02"))
+	}
+	if got := cal.AddBusinessDays(day(2024, time.January, 7), -1); !got.Equal(day(2024, time.January, 4)) {
+		t.Errorf("AddBusinessDays(-1) = %s, want Thursday 2024-01-04", got.Format("Mon 2006-01-02"))
+	}
+}
+
+func TestSchedulePayment_RollsToBusinessDay(t *testing.T) {
+	cal := NewBusinessCalendar("US")
+	ws, clock := newTestService(WithBusinessCalendar(cal, Following))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("landlord", "Landlord", "landlord@example.com")
+	ws.Deposit("alice", 1000, "seed")
+
+	// Saturday 2024-01-06 rolls to Monday 2024-01-08; the next nominal run is the 13th
+	saturday := time.Date(2024, time.January, 6, 9, 0, 0, 0, time.UTC)
+	jobID, err := ws.SchedulePayment("alice", "landlord", decimal.NewFromInt(100), "rent", saturday, Every(7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("SchedulePayment() error = %v", err)
+	}
+
+	job, _ := ws.GetJob(jobID)
+	if want := time.Date(2024, time.January, 8, 9, 0, 0, 0, time.UTC); job.NextRun != want.Unix() {
+		t.Fatalf("NextRun = %s, want %s", time.Unix(job.NextRun, 0).UTC(), want)
+	}
+
+	clock.Advance(7 * 24 * time.Hour)
+	ws.RunDueJobs()
+
+	if b, _ := ws.GetBalanceDecimal("landlord"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("landlord balance = %s, want 100", b)
+	}
+	job, _ = ws.GetJob(jobID)
+	if want := time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC); job.NextRun != want.Unix() {
+		t.Errorf("NextRun after first run = %s, want %s", time.Unix(job.NextRun, 0).UTC(), want)
+	}
+}
+
+func TestSettlementDate(t *testing.T) {
+	friday := day(2024, time.January, 5)
+
+	if got := NewWalletService().SettlementDate(friday, 2); !got.Equal(day(2024, time.January, 7)) {
+		t.Errorf("SettlementDate without calendar = %s, want 2024-01-07", got.Format("2006-01-02"))
+	}
+
+	ws := NewWalletService(WithBusinessCalendar(NewBusinessCalendar("US"), Following))
+	if got := ws.SettlementDate(friday, 2); !got.Equal(day(2024, time.January, 9)) {
+		t.Errorf("SettlementDate = %s, want 2024-01-09", got.Format("2006-01-02"))
+	}
+}
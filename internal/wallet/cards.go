@@ -0,0 +1,559 @@
+// internal/wallet/cards.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for stored-value cards
+var (
+	ErrCardNotFound          = errors.New("card not found")
+	ErrCardCancelled         = errors.New("card is cancelled")
+	ErrInvalidCard           = errors.New("invalid card")
+	ErrAuthorizationNotFound = errors.New("card authorization not found")
+	ErrAuthorizationClosed   = errors.New("card authorization is no longer open")
+	ErrCaptureExceedsAuth    = errors.New("capture exceeds the authorized amount")
+)
+
+// DefaultCardAuthorizationTTL is how long an uncaptured authorization holds funds
+// before it is released automatically
+const DefaultCardAuthorizationTTL = 7 * 24 * time.Hour
+
+// CardStatus is the lifecycle state of a card
+type CardStatus string
+
+const (
+	CardActive    CardStatus = "active"
+	CardFrozen    CardStatus = "frozen"
+	CardCancelled CardStatus = "cancelled"
+)
+
+// CardLimits caps card spending; a zero limit is unlimited
+type CardLimits struct {
+	PerTransaction decimal.Decimal
+	Daily          decimal.Decimal
+}
+
+// Card is a virtual stored-value card drawing on a user's wallet
+type Card struct {
+	ID        string
+	UserID    string
+	Label     string
+	Limits    CardLimits
+	Status    CardStatus
+	ExpiresAt int64
+	CreatedAt int64
+
+	// Authorized spend in the current day, net of released authorizations
+	DayStart   int64
+	SpentToday decimal.Decimal
+}
+
+// CardAuthStatus is the state of a card authorization
+type CardAuthStatus string
+
+const (
+	CardAuthApproved CardAuthStatus = "approved"
+	CardAuthDeclined CardAuthStatus = "declined"
+	CardAuthCaptured CardAuthStatus = "captured"
+	CardAuthReleased CardAuthStatus = "released"
+)
+
+// Decline reasons reported to the card processor
+const (
+	DeclineCardFrozen        = "card_frozen"
+	DeclineCardCancelled     = "card_cancelled"
+	DeclineCardExpired       = "card_expired"
+	DeclineTransactionLimit  = "exceeds_transaction_limit"
+	DeclineDailyLimit        = "exceeds_daily_limit"
+	DeclineInsufficientFunds = "insufficient_funds"
+	DeclineDoNotHonor        = "do_not_honor"
+)
+
+// CardAuthRequest is what a card processor sends to authorize a purchase. ProcessorRef
+// is the processor's own ID for the authorization and makes retries idempotent.
+type CardAuthRequest struct {
+	CardID       string
+	Amount       decimal.Decimal
+	Merchant     string
+	ProcessorRef string
+}
+
+// CardAuthorization is the wallet's answer to an authorization request and tracks the
+// hold it placed through to capture or release
+type CardAuthorization struct {
+	ID             string
+	CardID         string
+	UserID         string
+	Merchant       string
+	ProcessorRef   string
+	Amount         decimal.Decimal
+	Captured       decimal.Decimal
+	Status         CardAuthStatus
+	DeclineReason  string
+	TransactionIDs []string // hold, capture and release entries
+	ExpiresAt      int64
+	CreatedAt      int64
+	UpdatedAt      int64
+
+	jobID    string
+	dayStart int64 // day whose allowance the authorization counts against
+}
+
+// cardBook holds issued cards and their authorizations
+type cardBook struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	cards map[string]*Card
+	auths map[string]*CardAuthorization
+	byRef map[string]string // processor ref -> authorization ID
+}
+
+// WithCardAuthorizationTTL sets how long uncaptured authorizations hold funds
+func WithCardAuthorizationTTL(d time.Duration) Option {
+	return func(ws *WalletService) {
+		ws.cards.ttl = d
+	}
+}
+
+// IssueCard issues a virtual card on userID's wallet, valid for validFor
+func (ws *WalletService) IssueCard(userID, label string, limits CardLimits, validFor time.Duration) (*Card, error) {
+	if validFor <= 0 || limits.PerTransaction.IsNegative() || limits.Daily.IsNegative() {
+		return nil, ErrInvalidCard
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	now := ws.now()
+	card := &Card{
+		ID:         ws.newID("card"),
+		UserID:     userID,
+		Label:      label,
+		Limits:     limits,
+		Status:     CardActive,
+		ExpiresAt:  now.Add(validFor).Unix(),
+		CreatedAt:  now.Unix(),
+		DayStart:   periodStart(now, MandateDaily).Unix(),
+		SpentToday: decimal.Zero,
+	}
+
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+	ws.cards.init()
+	ws.cards.cards[card.ID] = card
+
+	copied := *card
+	return &copied, nil
+}
+
+// FreezeCard declines new authorizations until the card is unfrozen. Open
+// authorizations can still be captured.
+func (ws *WalletService) FreezeCard(cardID, userID string) error {
+	return ws.setCardStatus(cardID, userID, CardFrozen)
+}
+
+// UnfreezeCard re-enables a frozen card
+func (ws *WalletService) UnfreezeCard(cardID, userID string) error {
+	return ws.setCardStatus(cardID, userID, CardActive)
+}
+
+// CancelCard permanently cancels a card
+func (ws *WalletService) CancelCard(cardID, userID string) error {
+	return ws.setCardStatus(cardID, userID, CardCancelled)
+}
+
+// SetCardLimits replaces a card's spend limits
+func (ws *WalletService) SetCardLimits(cardID, userID string, limits CardLimits) error {
+	if limits.PerTransaction.IsNegative() || limits.Daily.IsNegative() {
+		return ErrInvalidCard
+	}
+
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+
+	card, exists := ws.cards.cards[cardID]
+	if !exists || card.UserID != userID {
+		return ErrCardNotFound
+	}
+	card.Limits = limits
+	return nil
+}
+
+// GetCard returns a card by ID
+func (ws *WalletService) GetCard(cardID string) (*Card, error) {
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+
+	card, exists := ws.cards.cards[cardID]
+	if !exists {
+		return nil, ErrCardNotFound
+	}
+	copied := *card
+	return &copied, nil
+}
+
+// ListCards returns a user's cards, oldest first
+func (ws *WalletService) ListCards(userID string) []Card {
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+
+	var out []Card
+	for _, card := range ws.cards.cards {
+		if card.UserID == userID {
+			out = append(out, *card)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// AuthorizeCard approves or declines a card purchase. An approval debits the wallet into
+// a hold that is captured at clearing or released; a decline is returned as an
+// authorization with a DeclineReason, not as an error. Errors mean the request itself
+// was malformed. Repeating a ProcessorRef returns the original decision.
+func (ws *WalletService) AuthorizeCard(req CardAuthRequest) (*CardAuthorization, error) {
+	return ws.AuthorizeCardContext(context.Background(), req)
+}
+
+// AuthorizeCardContext is AuthorizeCard on behalf of a caller's ctx
+func (ws *WalletService) AuthorizeCardContext(ctx context.Context, req CardAuthRequest) (*CardAuthorization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	now := ws.now()
+	ws.cards.mu.Lock()
+	if id, seen := ws.cards.byRef[req.ProcessorRef]; seen && req.ProcessorRef != "" {
+		copied := ws.cards.auths[id].copy()
+		ws.cards.mu.Unlock()
+		return copied, nil
+	}
+	card, exists := ws.cards.cards[req.CardID]
+	if !exists {
+		ws.cards.mu.Unlock()
+		return nil, ErrCardNotFound
+	}
+
+	ttl := ws.cards.ttl
+	if ttl <= 0 {
+		ttl = DefaultCardAuthorizationTTL
+	}
+	auth := &CardAuthorization{
+		ID:           ws.newID("cardauth"),
+		CardID:       card.ID,
+		UserID:       card.UserID,
+		Merchant:     req.Merchant,
+		ProcessorRef: req.ProcessorRef,
+		Amount:       req.Amount,
+		Captured:     decimal.Zero,
+		Status:       CardAuthApproved,
+		ExpiresAt:    now.Add(ttl).Unix(),
+		CreatedAt:    now.Unix(),
+	}
+	auth.UpdatedAt = auth.CreatedAt
+
+	// Reserve the daily allowance before the hold so concurrent authorizations cannot
+	// overshoot it together
+	auth.DeclineReason = ws.checkCard(card, req.Amount, now)
+	if auth.DeclineReason == "" {
+		card.SpentToday = card.SpentToday.Add(req.Amount)
+	}
+	auth.dayStart = card.DayStart
+	ws.cards.auths[auth.ID] = auth
+	if req.ProcessorRef != "" {
+		ws.cards.byRef[req.ProcessorRef] = auth.ID
+	}
+	ws.cards.mu.Unlock()
+
+	if auth.DeclineReason == "" {
+		hold := &Transaction{
+			FromUserID:  auth.UserID,
+			ToUserID:    cardCounterparty(req.Merchant),
+			Amount:      req.Amount,
+			Type:        TransactionCardHold,
+			Description: "card authorization at " + req.Merchant,
+			Metadata:    map[string]string{"card_id": card.ID, "card_authorization": auth.ID},
+		}
+		if err := ws.postDebit(ctx, hold); err != nil {
+			ws.cards.mu.Lock()
+			auth.DeclineReason = declineReasonFor(err)
+			if card.DayStart == auth.dayStart {
+				card.SpentToday = card.SpentToday.Sub(req.Amount)
+			}
+			ws.cards.mu.Unlock()
+		} else {
+			ws.cards.mu.Lock()
+			auth.TransactionIDs = append(auth.TransactionIDs, hold.ID)
+			ws.cards.mu.Unlock()
+		}
+	}
+
+	ws.cards.mu.Lock()
+	if auth.DeclineReason != "" {
+		auth.Status = CardAuthDeclined
+	}
+	copied := auth.copy()
+	ws.cards.mu.Unlock()
+
+	ws.metrics.IncCounter("card_authorizations_total", map[string]string{"status": string(copied.Status), "reason": copied.DeclineReason})
+	if copied.Status == CardAuthApproved {
+		jobID := ws.schedule("card_auth_expiry", copied.UserID, time.Unix(copied.ExpiresAt, 0), nil, func(now time.Time) error {
+			return ws.expireAuthorization(copied.ID)
+		})
+		ws.cards.mu.Lock()
+		auth.jobID = jobID
+		ws.cards.mu.Unlock()
+	}
+	return copied, nil
+}
+
+// CaptureAuthorization settles amount of an approved authorization when the purchase
+// clears. The captured money leaves the system to the card network; any uncaptured
+// remainder is released back to the wallet.
+func (ws *WalletService) CaptureAuthorization(authID string, amount decimal.Decimal) (*CardAuthorization, error) {
+	return ws.CaptureAuthorizationContext(context.Background(), authID, amount)
+}
+
+// CaptureAuthorizationContext is CaptureAuthorization on behalf of a caller's ctx
+func (ws *WalletService) CaptureAuthorizationContext(ctx context.Context, authID string, amount decimal.Decimal) (*CardAuthorization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	ws.cards.mu.Lock()
+	auth, err := ws.cards.openAuthorization(authID)
+	if err != nil {
+		ws.cards.mu.Unlock()
+		return nil, err
+	}
+	if amount.GreaterThan(auth.Amount) {
+		ws.cards.mu.Unlock()
+		return nil, ErrCaptureExceedsAuth
+	}
+	auth.Status, auth.Captured = CardAuthCaptured, amount
+	auth.UpdatedAt = ws.now().Unix()
+	a := *auth
+	ws.cards.mu.Unlock()
+	ws.CancelJob(a.jobID)
+
+	capture := &Transaction{
+		FromUserID:  a.UserID,
+		ToUserID:    cardCounterparty(a.Merchant),
+		Amount:      amount,
+		Type:        TransactionCardCapture,
+		Description: "card purchase at " + a.Merchant,
+		Metadata:    map[string]string{"card_id": a.CardID, "card_authorization": a.ID},
+	}
+	ws.recordCardEntry(capture)
+	ids := []string{capture.ID}
+
+	if remainder := a.Amount.Sub(amount); remainder.IsPositive() {
+		release, err := ws.releaseCardHold(ctx, &a, remainder, "uncaptured remainder")
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, release.ID)
+	}
+
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+	auth.TransactionIDs = append(auth.TransactionIDs, ids...)
+	ws.metrics.ObserveValue("card_capture_amount", amount.InexactFloat64(), nil)
+	return auth.copy(), nil
+}
+
+// ReleaseAuthorization returns the full hold of an approved authorization to the
+// wallet, as when a processor reverses a purchase before clearing
+func (ws *WalletService) ReleaseAuthorization(authID string) (*CardAuthorization, error) {
+	return ws.ReleaseAuthorizationContext(context.Background(), authID)
+}
+
+// ReleaseAuthorizationContext is ReleaseAuthorization on behalf of a caller's ctx
+func (ws *WalletService) ReleaseAuthorizationContext(ctx context.Context, authID string) (*CardAuthorization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ws.releaseAuthorization(ctx, authID, "authorization reversed")
+}
+
+// GetAuthorization returns a card authorization by ID
+func (ws *WalletService) GetAuthorization(authID string) (*CardAuthorization, error) {
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+
+	auth, exists := ws.cards.auths[authID]
+	if !exists {
+		return nil, ErrAuthorizationNotFound
+	}
+	return auth.copy(), nil
+}
+
+// expireAuthorization releases an authorization nobody captured in time
+func (ws *WalletService) expireAuthorization(authID string) error {
+	_, err := ws.releaseAuthorization(context.Background(), authID, "authorization expired")
+	if errors.Is(err, ErrAuthorizationClosed) {
+		return nil
+	}
+	return err
+}
+
+// releaseAuthorization closes an approved authorization and returns its hold
+func (ws *WalletService) releaseAuthorization(ctx context.Context, authID, reason string) (*CardAuthorization, error) {
+	ws.cards.mu.Lock()
+	auth, err := ws.cards.openAuthorization(authID)
+	if err != nil {
+		ws.cards.mu.Unlock()
+		return nil, err
+	}
+	auth.Status = CardAuthReleased
+	auth.UpdatedAt = ws.now().Unix()
+	a := *auth
+
+	// A released authorization no longer counts against today's allowance
+	if card := ws.cards.cards[a.CardID]; card != nil && card.DayStart == a.dayStart {
+		card.SpentToday = card.SpentToday.Sub(a.Amount)
+	}
+	ws.cards.mu.Unlock()
+	ws.CancelJob(a.jobID)
+
+	release, err := ws.releaseCardHold(ctx, &a, a.Amount, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+	auth.TransactionIDs = append(auth.TransactionIDs, release.ID)
+	return auth.copy(), nil
+}
+
+// releaseCardHold credits amount of an authorization's hold back to the wallet
+func (ws *WalletService) releaseCardHold(ctx context.Context, a *CardAuthorization, amount decimal.Decimal, reason string) (*Transaction, error) {
+	release := &Transaction{
+		FromUserID:  cardCounterparty(a.Merchant),
+		ToUserID:    a.UserID,
+		Amount:      amount,
+		Type:        TransactionCardRelease,
+		Description: "card hold released: " + reason,
+		Metadata:    map[string]string{"card_id": a.CardID, "card_authorization": a.ID},
+	}
+	if err := ws.postCredit(ctx, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// recordCardEntry records a ledger entry that moves no wallet balance
+func (ws *WalletService) recordCardEntry(tx *Transaction) {
+	ws.mu.RLock()
+	wallet := ws.wallets[tx.FromUserID]
+	ws.mu.RUnlock()
+	if tx.Currency == "" && wallet != nil {
+		tx.Currency = wallet.Currency
+	}
+	ws.stampTransaction(tx)
+	ws.recordTransaction(tx)
+}
+
+// setCardStatus changes a card's status on behalf of its owner
+func (ws *WalletService) setCardStatus(cardID, userID string, status CardStatus) error {
+	ws.cards.mu.Lock()
+	defer ws.cards.mu.Unlock()
+
+	card, exists := ws.cards.cards[cardID]
+	if !exists || card.UserID != userID {
+		return ErrCardNotFound
+	}
+	if card.Status == CardCancelled {
+		return ErrCardCancelled
+	}
+	card.Status = status
+	return nil
+}
+
+// checkCard returns why the card cannot authorize amount now, or "" when it can.
+// Callers hold ws.cards.mu.
+func (ws *WalletService) checkCard(card *Card, amount decimal.Decimal, now time.Time) string {
+	switch {
+	case card.Status == CardCancelled:
+		return DeclineCardCancelled
+	case card.Status == CardFrozen:
+		return DeclineCardFrozen
+	case now.Unix() >= card.ExpiresAt:
+		return DeclineCardExpired
+	case card.Limits.PerTransaction.IsPositive() && amount.GreaterThan(card.Limits.PerTransaction):
+		return DeclineTransactionLimit
+	}
+
+	if start := periodStart(now, MandateDaily).Unix(); start != card.DayStart {
+		card.DayStart, card.SpentToday = start, decimal.Zero
+	}
+	if card.Limits.Daily.IsPositive() && card.SpentToday.Add(amount).GreaterThan(card.Limits.Daily) {
+		return DeclineDailyLimit
+	}
+	return ""
+}
+
+// declineReasonFor maps a failed hold to the reason reported to the processor
+func declineReasonFor(err error) string {
+	if errors.Is(err, ErrInsufficientBalance) || errors.Is(err, ErrBelowMinimumBalance) {
+		return DeclineInsufficientFunds
+	}
+	return DeclineDoNotHonor
+}
+
+// cardCounterparty names the outside party of card ledger entries
+func cardCounterparty(merchant string) string {
+	return "card:" + merchant
+}
+
+// openAuthorization returns an approved authorization that has not been captured or
+// released. Callers hold b.mu.
+func (b *cardBook) openAuthorization(authID string) (*CardAuthorization, error) {
+	auth, exists := b.auths[authID]
+	if !exists {
+		return nil, ErrAuthorizationNotFound
+	}
+	if auth.Status != CardAuthApproved || len(auth.TransactionIDs) == 0 {
+		return nil, ErrAuthorizationClosed
+	}
+	return auth, nil
+}
+
+// init allocates the maps on first use. Callers hold b.mu.
+func (b *cardBook) init() {
+	if b.cards == nil {
+		b.cards = make(map[string]*Card)
+		b.auths = make(map[string]*CardAuthorization)
+		b.byRef = make(map[string]string)
+	}
+}
+
+// copy returns a copy safe to hand to callers. Callers hold ws.cards.mu.
+func (a *CardAuthorization) copy() *CardAuthorization {
+	copied := *a
+	copied.TransactionIDs = append([]string(nil), a.TransactionIDs...)
+	return &copied
+}
@@ -0,0 +1,151 @@
+// internal/wallet/cards_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCard_AuthorizeCaptureRelease(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+	card, err := ws.IssueCard("alice", "groceries", CardLimits{}, 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueCard() error = %v", err)
+	}
+
+	auth, err := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(40), Merchant: "grocer", ProcessorRef: "p1"})
+	if err != nil || auth.Status != CardAuthApproved {
+		t.Fatalf("AuthorizeCard() = %+v, %v, want approved", auth, err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("balance after auth = %s, want 60", b)
+	}
+	if again, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(40), Merchant: "grocer", ProcessorRef: "p1"}); again.ID != auth.ID {
+		t.Errorf("retried authorization ID = %s, want %s", again.ID, auth.ID)
+	}
+
+	// Clearing for less than authorized releases the rest
+	captured, err := ws.CaptureAuthorization(auth.ID, decimal.NewFromInt(35))
+	if err != nil || captured.Status != CardAuthCaptured || len(captured.TransactionIDs) != 3 {
+		t.Fatalf("CaptureAuthorization() = %+v, %v", captured, err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(65)) {
+		t.Errorf("balance after capture = %s, want 65", b)
+	}
+	if _, err := ws.CaptureAuthorization(auth.ID, decimal.NewFromInt(1)); err != ErrAuthorizationClosed {
+		t.Errorf("second capture error = %v, want %v", err, ErrAuthorizationClosed)
+	}
+
+	second, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(20), Merchant: "cafe"})
+	if _, err := ws.CaptureAuthorization(second.ID, decimal.NewFromInt(21)); err != ErrCaptureExceedsAuth {
+		t.Errorf("over-capture error = %v, want %v", err, ErrCaptureExceedsAuth)
+	}
+	if released, err := ws.ReleaseAuthorization(second.ID); err != nil || released.Status != CardAuthReleased {
+		t.Errorf("ReleaseAuthorization() = %+v, %v", released, err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(65)) {
+		t.Errorf("balance after release = %s, want 65", b)
+	}
+
+	if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+	if supply := ws.GetTotalSupply()[DefaultCurrency]; !supply.Equal(decimal.NewFromInt(65)) {
+		t.Errorf("supply = %s, want 65 after 35 left to the card network", supply)
+	}
+}
+
+func TestCard_Declines(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+	card, err := ws.IssueCard("alice", "groceries", CardLimits{PerTransaction: decimal.NewFromInt(50), Daily: decimal.NewFromInt(70)}, 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueCard() error = %v", err)
+	}
+
+	authorize := func(amount int64) *CardAuthorization {
+		t.Helper()
+		auth, err := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(amount), Merchant: "shop"})
+		if err != nil {
+			t.Fatalf("AuthorizeCard(%d) error = %v", amount, err)
+		}
+		return auth
+	}
+
+	tests := []struct {
+		name   string
+		setup  func()
+		amount int64
+		want   string
+	}{
+		{"over transaction limit", nil, 60, DeclineTransactionLimit},
+		{"within limits", nil, 50, ""},
+		{"over daily limit", nil, 30, DeclineDailyLimit},
+		{"frozen", func() { ws.FreezeCard(card.ID, "alice") }, 10, DeclineCardFrozen},
+		{"unfrozen", func() { ws.UnfreezeCard(card.ID, "alice") }, 20, ""},
+		{"new day but insufficient funds", func() { clock.Advance(24 * time.Hour) }, 40, DeclineInsufficientFunds},
+		{"expired", func() { clock.Advance(365 * 24 * time.Hour) }, 10, DeclineCardExpired},
+	}
+	for _, tt := range tests {
+		if tt.setup != nil {
+			tt.setup()
+		}
+		auth := authorize(tt.amount)
+		if auth.DeclineReason != tt.want {
+			t.Errorf("%s: decline reason = %q, want %q", tt.name, auth.DeclineReason, tt.want)
+		}
+		if (auth.Status == CardAuthDeclined) != (tt.want != "") {
+			t.Errorf("%s: status = %s", tt.name, auth.Status)
+		}
+	}
+
+	// Declined authorizations hold nothing; the two approvals are released on expiry
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("balance = %s, want 30", b)
+	}
+	ws.RunDueJobs()
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("balance after expiry jobs = %s, want 100", b)
+	}
+
+	ws.CancelCard(card.ID, "alice")
+	if err := ws.UnfreezeCard(card.ID, "alice"); err != ErrCardCancelled {
+		t.Errorf("UnfreezeCard() on a cancelled card error = %v, want %v", err, ErrCardCancelled)
+	}
+	if _, err := ws.AuthorizeCard(CardAuthRequest{CardID: "card_missing", Amount: decimal.NewFromInt(1)}); err != ErrCardNotFound {
+		t.Errorf("unknown card error = %v, want %v", err, ErrCardNotFound)
+	}
+}
+
+func TestCard_UncapturedAuthorizationExpires(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+	card, err := ws.IssueCard("alice", "groceries", CardLimits{Daily: decimal.NewFromInt(50)}, 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueCard() error = %v", err)
+	}
+
+	auth, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(50), Merchant: "hotel"})
+	clock.Advance(DefaultCardAuthorizationTTL)
+	ws.RunDueJobs()
+
+	got, _ := ws.GetAuthorization(auth.ID)
+	if got.Status != CardAuthReleased {
+		t.Fatalf("authorization status = %s, want released", got.Status)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("balance = %s, want 100 after expiry", b)
+	}
+	if _, err := ws.CaptureAuthorization(auth.ID, decimal.NewFromInt(50)); err != ErrAuthorizationClosed {
+		t.Errorf("late capture error = %v, want %v", err, ErrAuthorizationClosed)
+	}
+}
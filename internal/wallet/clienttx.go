@@ -0,0 +1,255 @@
+// internal/wallet/clienttx.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for client transaction IDs
+var (
+	ErrInvalidClientTxID     = errors.New("client transaction ID is required")
+	ErrClientTxIDTaken       = errors.New("client transaction ID is already reserved or used")
+	ErrClientTxIDNotReserved = errors.New("client transaction ID was not reserved by the sender")
+	ErrClientTxIDExpired     = errors.New("client transaction ID reservation expired")
+	ErrClientTxIDInFlight    = errors.New("a transfer with this client transaction ID is in progress")
+	ErrClientTxIDUsed        = errors.New("client transaction ID was used by a transfer on another instance")
+)
+
+// DefaultClientTxIDTTL is how long a reservation stays usable when no TTL is given
+const DefaultClientTxIDTTL = 15 * time.Minute
+
+// metaClientTxID records on a transfer the client transaction ID it used
+const metaClientTxID = "client_tx_id"
+
+// ClientTxStatus is the state of a client transaction ID reservation
+type ClientTxStatus string
+
+const (
+	ClientTxReserved ClientTxStatus = "reserved"
+	ClientTxPending  ClientTxStatus = "pending" // claimed by a transfer in progress
+	ClientTxUsed     ClientTxStatus = "used"
+)
+
+// ClientTxReservation is a client-generated transaction ID set aside for one user's
+// next transfer
+type ClientTxReservation struct {
+	ID            string
+	UserID        string
+	Status        ClientTxStatus
+	ReservedAt    int64
+	ExpiresAt     int64
+	TransactionID string // set once used
+}
+
+// ClientTxRegistry holds client transaction ID reservations. The service keeps them in
+// memory unless its Store implements ClientTxRegistry or WithClientTxRegistry names
+// another; instances sharing a registry never use an ID twice between them.
+type ClientTxRegistry interface {
+	// ReserveClientTxID stores r unless its ID is pending, used, or reserved by another
+	// user until after r.ReservedAt, and returns the reservation then stored for the ID
+	ReserveClientTxID(r ClientTxReservation) (ClientTxReservation, error)
+	// ClaimClientTxID marks userID's reservation of id pending if it is reserved and
+	// unexpired at now, reporting whether it did, and returns the reservation stored for
+	// id; ErrClientTxIDNotReserved when there is none
+	ClaimClientTxID(id, userID string, now int64) (ClientTxReservation, bool, error)
+	// FinishClientTxID marks the pending reservation of id used by transactionID, or
+	// reserved again when transactionID is empty
+	FinishClientTxID(id, transactionID string) error
+	// ExpireClientTxIDs drops reservations that expired unused by now, returning how many
+	ExpireClientTxIDs(now int64) (int, error)
+}
+
+// WithClientTxRegistry keeps client transaction ID reservations in r, which instances
+// of a multi-instance deployment share
+func WithClientTxRegistry(r ClientTxRegistry) Option {
+	return func(ws *WalletService) {
+		ws.clientTxIDs = r
+	}
+}
+
+// initClientTxIDs picks the registry when no option named one
+func (ws *WalletService) initClientTxIDs() {
+	if ws.clientTxIDs != nil {
+		return
+	}
+	if r, ok := ws.store.(ClientTxRegistry); ok {
+		ws.clientTxIDs = r
+		return
+	}
+	ws.clientTxIDs = &clientTxBook{reservations: make(map[string]*ClientTxReservation)}
+}
+
+// ReserveClientTxID sets aside a client-generated ID for userID's next transfer for ttl
+// (DefaultClientTxIDTTL when ttl is not positive). Reserving an unused ID again extends
+// the reservation. IDs nobody used are free again once they expire.
+func (ws *WalletService) ReserveClientTxID(userID, clientTxID string, ttl time.Duration) (*ClientTxReservation, error) {
+	clientTxID = strings.TrimSpace(clientTxID)
+	if clientTxID == "" {
+		return nil, ErrInvalidClientTxID
+	}
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+	if ttl <= 0 {
+		ttl = DefaultClientTxIDTTL
+	}
+
+	now := ws.now()
+	stored, err := ws.clientTxIDs.ReserveClientTxID(ClientTxReservation{
+		ID:         clientTxID,
+		UserID:     userID,
+		Status:     ClientTxReserved,
+		ReservedAt: now.Unix(),
+		ExpiresAt:  now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stored.UserID != userID || stored.Status != ClientTxReserved {
+		return nil, ErrClientTxIDTaken
+	}
+	ws.metrics.IncCounter("client_tx_ids_reserved_total", nil)
+	return &stored, nil
+}
+
+// TransferClientTxID is TransferDecimal under a client transaction ID the sender
+// reserved, applied exactly once: replays on this instance return the transfer the ID
+// created, and instances sharing the registry refuse an ID another one claimed. A
+// failed transfer leaves the ID reserved for a retry until it expires.
+func (ws *WalletService) TransferClientTxID(clientTxID, fromUserID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.TransferClientTxIDContext(context.Background(), clientTxID, fromUserID, toUserID, amount, description)
+}
+
+// TransferClientTxIDContext is TransferClientTxID on behalf of a caller's ctx
+func (ws *WalletService) TransferClientTxIDContext(ctx context.Context, clientTxID, fromUserID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	clientTxID = strings.TrimSpace(clientTxID)
+	if clientTxID == "" {
+		return nil, ErrInvalidClientTxID
+	}
+
+	want := &Transaction{FromUserID: fromUserID, ToUserID: toUserID, Amount: amount, Type: TransactionTransfer}
+	return ws.idempotent(metaClientTxID+":"+clientTxID, want, func(meta map[string]string) (*Transaction, error) {
+		stored, claimed, err := ws.clientTxIDs.ClaimClientTxID(clientTxID, fromUserID, ws.now().Unix())
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, claimError(stored, fromUserID)
+		}
+
+		meta[metaClientTxID] = clientTxID
+		tx, err := ws.transfer(ctx, fromUserID, toUserID, amount, description, transferOptions{metadata: meta})
+		// A transfer held for review was applied and keeps the ID
+		var txID string
+		if tx != nil {
+			txID = tx.ID
+		}
+		if finishErr := ws.clientTxIDs.FinishClientTxID(clientTxID, txID); finishErr != nil && err == nil {
+			err = finishErr
+		}
+		return tx, err
+	})
+}
+
+// ExpireClientTxIDs drops reservations that expired unused, returning how many. The
+// in-memory registry also drops them as it goes; shared registries should be swept
+// periodically.
+func (ws *WalletService) ExpireClientTxIDs() (int, error) {
+	return ws.clientTxIDs.ExpireClientTxIDs(ws.now().Unix())
+}
+
+// claimError says why fromUserID could not claim stored
+func claimError(stored ClientTxReservation, fromUserID string) error {
+	switch {
+	case stored.UserID != fromUserID:
+		return ErrClientTxIDNotReserved
+	case stored.Status == ClientTxPending:
+		return ErrClientTxIDInFlight
+	case stored.Status == ClientTxUsed:
+		return ErrClientTxIDUsed
+	}
+	return ErrClientTxIDExpired
+}
+
+// clientTxBook is the in-memory ClientTxRegistry
+type clientTxBook struct {
+	mu           sync.Mutex
+	reservations map[string]*ClientTxReservation
+	sinceSweep   int
+}
+
+// clientTxSweepEvery is how many reservations the in-memory registry takes between
+// sweeps of expired ones
+const clientTxSweepEvery = 256
+
+func (b *clientTxBook) ReserveClientTxID(r ClientTxReservation) (ClientTxReservation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.sinceSweep++; b.sinceSweep >= clientTxSweepEvery {
+		b.expire(r.ReservedAt)
+	}
+	if cur, ok := b.reservations[r.ID]; ok && (cur.Status != ClientTxReserved || (cur.UserID != r.UserID && cur.ExpiresAt > r.ReservedAt)) {
+		return *cur, nil
+	}
+	b.reservations[r.ID] = &r
+	return r, nil
+}
+
+func (b *clientTxBook) ClaimClientTxID(id, userID string, now int64) (ClientTxReservation, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cur, ok := b.reservations[id]
+	if !ok {
+		return ClientTxReservation{}, false, ErrClientTxIDNotReserved
+	}
+	if cur.UserID != userID || cur.Status != ClientTxReserved || cur.ExpiresAt <= now {
+		return *cur, false, nil
+	}
+	cur.Status = ClientTxPending
+	return *cur, true, nil
+}
+
+func (b *clientTxBook) FinishClientTxID(id, transactionID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cur, ok := b.reservations[id]
+	if !ok || cur.Status != ClientTxPending {
+		return ErrClientTxIDNotReserved
+	}
+	cur.Status, cur.TransactionID = ClientTxUsed, transactionID
+	if transactionID == "" {
+		cur.Status = ClientTxReserved
+	}
+	return nil
+}
+
+func (b *clientTxBook) ExpireClientTxIDs(now int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.expire(now), nil
+}
+
+// expire drops reservations that expired unused by now. Caller must hold b.mu.
+func (b *clientTxBook) expire(now int64) int {
+	b.sinceSweep = 0
+	var n int
+	for id, r := range b.reservations {
+		if r.Status == ClientTxReserved && r.ExpiresAt <= now {
+			delete(b.reservations, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,133 @@
+// internal/wallet/clienttx_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTransferClientTxID_ExactlyOnce(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	if _, err := ws.ReserveClientTxID("alice", "c-1", 0); err != nil {
+		t.Fatalf("ReserveClientTxID() error = %v", err)
+	}
+	tx, err := ws.TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(30), "rent")
+	if err != nil {
+		t.Fatalf("TransferClientTxID() error = %v", err)
+	}
+	if tx.Metadata[metaClientTxID] != "c-1" {
+		t.Errorf("metadata = %v", tx.Metadata)
+	}
+
+	// The client never saw the response and sends the transfer again
+	again, err := ws.TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(30), "rent")
+	if err != nil || again.ID != tx.ID {
+		t.Errorf("replay = %+v, %v, want %s", again, err, tx.ID)
+	}
+	if _, err := ws.TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(31), "rent"); err != ErrIdempotencyKeyReused {
+		t.Errorf("replay with another amount error = %v, want %v", err, ErrIdempotencyKeyReused)
+	}
+	if _, err := ws.ReserveClientTxID("alice", "c-1", 0); err != ErrClientTxIDTaken {
+		t.Errorf("reserving a used ID error = %v, want %v", err, ErrClientTxIDTaken)
+	}
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("bob's balance = %s, want 30", b)
+	}
+}
+
+func TestTransferClientTxID_Validation(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 10, "seed")
+	ws.ReserveClientTxID("alice", "c-alice", time.Minute)
+	ws.ReserveClientTxID("alice", "c-stale", time.Second)
+	clock.Advance(2 * time.Second)
+
+	tests := []struct {
+		name    string
+		id      string
+		from    string
+		wantErr error
+	}{
+		{"no ID", " ", "alice", ErrInvalidClientTxID},
+		{"not reserved", "c-none", "alice", ErrClientTxIDNotReserved},
+		{"reserved by another user", "c-alice", "bob", ErrClientTxIDNotReserved},
+		{"expired", "c-stale", "alice", ErrClientTxIDExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.TransferClientTxID(tt.id, tt.from, "bob", decimal.NewFromInt(1), ""); err != tt.wantErr {
+				t.Errorf("TransferClientTxID() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ws.ReserveClientTxID("bob", "c-alice", 0); err != ErrClientTxIDTaken {
+		t.Errorf("reserving another user's ID error = %v, want %v", err, ErrClientTxIDTaken)
+	}
+	if _, err := ws.ReserveClientTxID("ghost", "c-ghost", 0); err != ErrUserNotFound {
+		t.Errorf("ReserveClientTxID(ghost) error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	// A failed transfer leaves the ID reserved for the retry
+	if _, err := ws.TransferClientTxID("c-alice", "alice", "bob", decimal.NewFromInt(25), ""); err != ErrInsufficientBalance {
+		t.Fatalf("TransferClientTxID() error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	ws.Deposit("alice", 20, "payday")
+	if _, err := ws.TransferClientTxID("c-alice", "alice", "bob", decimal.NewFromInt(25), ""); err != nil {
+		t.Errorf("retry error = %v", err)
+	}
+}
+
+func TestClientTxIDs_Expiry(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+
+	ws.ReserveClientTxID("alice", "c-1", time.Minute)
+	ws.ReserveClientTxID("alice", "c-2", time.Hour)
+	clock.Advance(2 * time.Minute)
+
+	if n, err := ws.ExpireClientTxIDs(); n != 1 || err != nil {
+		t.Errorf("ExpireClientTxIDs() = %d, %v, want 1", n, err)
+	}
+	if r, err := ws.ReserveClientTxID("bob", "c-1", 0); err != nil || r.ExpiresAt != clock.Now().Add(DefaultClientTxIDTTL).Unix() {
+		t.Errorf("reserving an expired ID = %+v, %v", r, err)
+	}
+	if _, err := ws.ReserveClientTxID("bob", "c-2", 0); err != ErrClientTxIDTaken {
+		t.Errorf("reserving a live ID error = %v, want %v", err, ErrClientTxIDTaken)
+	}
+}
+
+func TestTransferClientTxID_SharedRegistry(t *testing.T) {
+	shared := &clientTxBook{reservations: make(map[string]*ClientTxReservation)}
+	var instances []*WalletService
+	for range 2 {
+		ws := NewWalletService(WithClientTxRegistry(shared))
+		ws.CreateUser("alice", "Alice", "a@example.com")
+		ws.CreateUser("bob", "Bob", "b@example.com")
+		ws.Deposit("alice", 100, "seed")
+		instances = append(instances, ws)
+	}
+
+	if _, err := instances[0].ReserveClientTxID("alice", "c-1", 0); err != nil {
+		t.Fatalf("ReserveClientTxID() error = %v", err)
+	}
+	if _, err := instances[0].TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(30), ""); err != nil {
+		t.Fatalf("TransferClientTxID() error = %v", err)
+	}
+	// A retry routed to the other instance is refused rather than applied again
+	if _, err := instances[1].TransferClientTxID("c-1", "alice", "bob", decimal.NewFromInt(30), ""); err != ErrClientTxIDUsed {
+		t.Errorf("TransferClientTxID() on another instance error = %v, want %v", err, ErrClientTxIDUsed)
+	}
+	if b, _ := instances[1].GetBalanceDecimal("bob"); !b.IsZero() {
+		t.Errorf("bob's balance on the other instance = %s, want 0", b)
+	}
+}
@@ -0,0 +1,448 @@
+// internal/wallet/closure.go
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Error definitions for wallet closure
+var (
+	ErrWalletFrozen          = errors.New("wallet is frozen for closure")
+	ErrWalletClosed          = errors.New("wallet is closed")
+	ErrClosureBlocked        = errors.New("wallet closure is blocked by unsettled items")
+	ErrClosureNotFound       = errors.New("no closure in progress for wallet")
+	ErrClosureDestination    = errors.New("closure needs exactly one of a sweep user or a withdrawal destination")
+	ErrClosureNotCancellable = errors.New("closure can no longer be cancelled")
+)
+
+// metaWalletClosure marks transactions posted by the closure workflow itself
+const metaWalletClosure = "wallet_closure"
+
+// ClosureStep is a stage of the closure workflow, in order
+type ClosureStep string
+
+const (
+	ClosureFrozen             ClosureStep = "frozen"
+	ClosurePendingCancelled   ClosureStep = "pending_cancelled"
+	ClosurePaidOut            ClosureStep = "paid_out"
+	ClosureStatementGenerated ClosureStep = "statement_generated"
+	ClosureClosed             ClosureStep = "closed"
+)
+
+// closureSteps lists the workflow in execution order. Pending items are cancelled before
+// the payout so released holds are swept too, and the statement comes last so it
+// includes the payout.
+var closureSteps = []ClosureStep{
+	ClosureFrozen,
+	ClosurePendingCancelled,
+	ClosurePaidOut,
+	ClosureStatementGenerated,
+	ClosureClosed,
+}
+
+// closureAllowedTypes may still touch a frozen wallet: money returning from holds and
+// escrows, interest already earned, and admin corrections and treasury operations
+var closureAllowedTypes = map[TransactionType]bool{
+	TransactionHoldRelease:      true,
+	TransactionHoldReversal:     true,
+	TransactionGiftRefund:       true,
+	TransactionFederationRefund: true,
+	TransactionRailReversal:     true,
+	TransactionCardRelease:      true,
+	TransactionReserveRelease:   true,
+	TransactionAdjustmentCredit: true,
+	TransactionAdjustmentDebit:  true,
+	TransactionInterest:         true,
+	TransactionMint:             true,
+	TransactionBurn:             true,
+}
+
+// ClosureRequest says where the remaining balance goes: to another user, or to one of
+// the user's registered withdrawal destinations
+type ClosureRequest struct {
+	SweepToUserID string
+	DestinationID string
+}
+
+// ClosureStepResult records when a step completed
+type ClosureStepResult struct {
+	Step        ClosureStep
+	CompletedAt int64
+	Detail      string
+}
+
+// WalletClosure reports the progress of closing a wallet
+type WalletClosure struct {
+	UserID      string
+	Request     ClosureRequest
+	Step        ClosureStep // last completed step
+	Completed   []ClosureStepResult
+	BlockedBy   []string // unsettled items stopping the next step
+	PayoutTxID  string
+	Statement   []byte // final statement as CSV, set once generated
+	RequestedAt int64
+	ClosedAt    int64
+}
+
+// closureBook holds wallet closures by user
+type closureBook struct {
+	mu       sync.Mutex
+	closures map[string]*WalletClosure
+}
+
+// CloseWallet freezes the wallet and runs the closure workflow as far as it can:
+// cancel schedules, mandates, cards and holds, pay out the remaining balance, generate
+// the final statement and mark the wallet closed. When unsettled items such as open
+// compliance cases or pending rail transfers stop it, it returns the closure with
+// BlockedBy filled and ErrClosureBlocked; calling CloseWallet again resumes, optionally
+// with a new request.
+func (ws *WalletService) CloseWallet(userID string, req ClosureRequest) (*WalletClosure, error) {
+	return ws.CloseWalletContext(context.Background(), userID, req)
+}
+
+// CloseWalletContext is CloseWallet on behalf of a caller's ctx
+func (ws *WalletService) CloseWalletContext(ctx context.Context, userID string, req ClosureRequest) (*WalletClosure, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if (req.SweepToUserID == "") == (req.DestinationID == "") || req.SweepToUserID == userID {
+		return nil, ErrClosureDestination
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.wallets[userID]
+	_, sweepExists := ws.wallets[req.SweepToUserID]
+	ws.mu.RUnlock()
+	if !exists || (req.SweepToUserID != "" && !sweepExists) {
+		return nil, ErrUserNotFound
+	}
+	if req.SweepToUserID != "" && ws.closureStep(req.SweepToUserID) != "" {
+		return nil, ErrWalletFrozen
+	}
+	if req.DestinationID != "" {
+		if _, err := ws.usableDestination(userID, req.DestinationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if ws.closureStep(userID) == "" {
+		ws.freezeWallet(userID, req)
+	}
+	ws.closures.mu.Lock()
+	c := ws.closures.closures[userID]
+	if c.Step == ClosureClosed {
+		ws.closures.mu.Unlock()
+		return nil, ErrWalletClosed
+	}
+	c.Request = req
+	c.BlockedBy = nil
+	ws.closures.mu.Unlock()
+
+	for {
+		next := nextClosureStep(ws.closureStep(userID))
+		if next == "" {
+			break
+		}
+		detail, blockers, err := ws.runClosureStep(ctx, userID, req, next)
+		if errors.Is(err, errClosureRetry) {
+			continue
+		}
+		if err != nil {
+			closure, _ := ws.GetClosureStatus(userID)
+			return closure, err
+		}
+		if len(blockers) > 0 {
+			ws.closures.mu.Lock()
+			c.BlockedBy = blockers
+			ws.closures.mu.Unlock()
+			closure, _ := ws.GetClosureStatus(userID)
+			return closure, ErrClosureBlocked
+		}
+		ws.completeClosureStep(userID, next, detail)
+	}
+
+	closure, err := ws.GetClosureStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+	ws.metrics.IncCounter("wallet_closures_total", nil)
+	ws.notify(Notification{
+		UserID:    userID,
+		Type:      "wallet_closed",
+		Subject:   "Your wallet is closed",
+		Message:   "Your wallet was closed and its balance paid out",
+		Data:      map[string]string{"payout_tx_id": closure.PayoutTxID},
+		Timestamp: ws.now().Unix(),
+	})
+	return closure, nil
+}
+
+// GetClosureStatus reports how far a wallet's closure has progressed
+func (ws *WalletService) GetClosureStatus(userID string) (*WalletClosure, error) {
+	ws.closures.mu.Lock()
+	defer ws.closures.mu.Unlock()
+
+	c, exists := ws.closures.closures[userID]
+	if !exists {
+		return nil, ErrClosureNotFound
+	}
+	copied := *c
+	copied.Completed = append([]ClosureStepResult(nil), c.Completed...)
+	copied.BlockedBy = append([]string(nil), c.BlockedBy...)
+	copied.Statement = append([]byte(nil), c.Statement...)
+	return &copied, nil
+}
+
+// CancelWalletClosure unfreezes a wallet whose balance has not been paid out yet.
+// Schedules and holds already cancelled stay cancelled.
+func (ws *WalletService) CancelWalletClosure(userID string) error {
+	unlock := ws.lockUsers(PriorityInteractive, userID)
+	defer unlock()
+
+	ws.closures.mu.Lock()
+	defer ws.closures.mu.Unlock()
+
+	c, exists := ws.closures.closures[userID]
+	if !exists {
+		return ErrClosureNotFound
+	}
+	if closureStepIndex(c.Step) >= closureStepIndex(ClosurePaidOut) {
+		return ErrClosureNotCancellable
+	}
+	delete(ws.closures.closures, userID)
+	return nil
+}
+
+// freezeWallet starts a closure. Taking the user lock lets operations already under
+// way finish; later ones see the freeze when they validate.
+func (ws *WalletService) freezeWallet(userID string, req ClosureRequest) {
+	unlock := ws.lockUsers(PriorityInteractive, userID)
+	defer unlock()
+
+	now := ws.now().Unix()
+	ws.closures.mu.Lock()
+	defer ws.closures.mu.Unlock()
+
+	if ws.closures.closures == nil {
+		ws.closures.closures = make(map[string]*WalletClosure)
+	}
+	ws.closures.closures[userID] = &WalletClosure{
+		UserID:      userID,
+		Request:     req,
+		Step:        ClosureFrozen,
+		Completed:   []ClosureStepResult{{Step: ClosureFrozen, CompletedAt: now}},
+		RequestedAt: now,
+	}
+}
+
+// runClosureStep performs one step and returns a detail for the status report, or the
+// items blocking it
+func (ws *WalletService) runClosureStep(ctx context.Context, userID string, req ClosureRequest, step ClosureStep) (string, []string, error) {
+	switch step {
+	case ClosurePendingCancelled:
+		return ws.cancelPendingForClosure(ctx, userID)
+	case ClosurePaidOut:
+		return ws.payOutForClosure(ctx, userID, req)
+	case ClosureStatementGenerated:
+		var buf bytes.Buffer
+		if err := ws.ExportTransactionHistory(userID, &buf); err != nil {
+			return "", nil, err
+		}
+		ws.closures.mu.Lock()
+		ws.closures.closures[userID].Statement = buf.Bytes()
+		ws.closures.mu.Unlock()
+		return fmt.Sprintf("%d bytes", buf.Len()), nil, nil
+	case ClosureClosed:
+		if b, _ := ws.GetBalanceDecimal(userID); !b.IsZero() {
+			// Money returned after the payout; pay it out before closing
+			ws.closures.mu.Lock()
+			ws.closures.closures[userID].Step = ClosurePendingCancelled
+			ws.closures.mu.Unlock()
+			return "", nil, errClosureRetry
+		}
+		ws.closures.mu.Lock()
+		ws.closures.closures[userID].ClosedAt = ws.now().Unix()
+		ws.closures.mu.Unlock()
+	}
+	return "", nil, nil
+}
+
+// errClosureRetry makes CloseWallet report progress after rewinding a step
+var errClosureRetry = errors.New("closure step rewound")
+
+// cancelPendingForClosure cancels what the user can cancel and reports what must settle
+// on its own first
+func (ws *WalletService) cancelPendingForClosure(ctx context.Context, userID string) (string, []string, error) {
+	items, err := ws.GetPendingItems(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cancelled := 0
+	var blockers []string
+	for _, item := range items {
+		var err error
+		switch item.Kind {
+		case PendingCardHold:
+			_, err = ws.releaseAuthorization(ctx, item.ID, "wallet closed")
+		case PendingScheduledPayment:
+			err = ws.CancelJob(item.ID)
+		case PendingGift:
+			if err = ws.CancelGift(item.ID, userID); errors.Is(err, ErrGiftNotCancelable) {
+				blockers = append(blockers, fmt.Sprintf("%s %s", item.Kind, item.ID))
+				continue
+			}
+		case PendingPaymentRequest:
+			err = ws.CancelPaymentLink(item.ID, userID)
+		case PendingExpense, PendingApproval:
+			// Expenses belong to the organization; they proceed without this user
+			continue
+		default:
+			blockers = append(blockers, fmt.Sprintf("%s %s", item.Kind, item.ID))
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		cancelled++
+	}
+
+	cancelled += ws.cancelStandingForClosure(userID)
+
+	ws.mu.RLock()
+	wallet := ws.wallets[userID]
+	ws.mu.RUnlock()
+	wallet.mu.RLock()
+	for currency, amount := range wallet.Foreign {
+		if !amount.IsZero() {
+			blockers = append(blockers, "foreign balance "+currency)
+		}
+	}
+	wallet.mu.RUnlock()
+
+	sort.Strings(blockers)
+	return fmt.Sprintf("%d items cancelled", cancelled), blockers, nil
+}
+
+// cancelStandingForClosure revokes mandates, cancels cards and conversion orders and
+// deletes automation rules of userID and returns how many it stopped
+func (ws *WalletService) cancelStandingForClosure(userID string) int {
+	stopped := 0
+
+	ws.mandates.mu.Lock()
+	for _, m := range ws.mandates.mandates {
+		if (m.PayerID == userID || m.MerchantID == userID) && m.Status != MandateRevoked {
+			m.Status = MandateRevoked
+			stopped++
+		}
+	}
+	ws.mandates.mu.Unlock()
+
+	for _, card := range ws.ListCards(userID) {
+		if card.Status != CardCancelled && ws.CancelCard(card.ID, userID) == nil {
+			stopped++
+		}
+	}
+
+	for _, rule := range ws.ListAutomationRules(userID) {
+		if ws.DeleteAutomationRule(userID, rule.ID) == nil {
+			stopped++
+		}
+	}
+	stopped += ws.cancelConversionOrders(userID)
+	stopped += ws.releaseHolds(userID)
+	stopped += ws.releaseReservations(userID)
+	return stopped
+}
+
+// payOutForClosure moves the remaining balance to the sweep user or destination
+func (ws *WalletService) payOutForClosure(ctx context.Context, userID string, req ClosureRequest) (string, []string, error) {
+	balance, err := ws.GetBalanceDecimal(userID)
+	if err != nil || !balance.IsPositive() {
+		return "nothing to pay out", nil, err
+	}
+
+	metadata := map[string]string{metaWalletClosure: userID}
+	var tx *Transaction
+	if req.SweepToUserID != "" {
+		tx, err = ws.transfer(ctx, userID, req.SweepToUserID, balance, "wallet closure sweep", transferOptions{metadata: metadata})
+	} else {
+		tx, err = ws.withdrawTo(ctx, userID, req.DestinationID, balance, "wallet closure payout", metadata)
+	}
+	if errors.Is(err, ErrTransferHeld) {
+		return "", []string{"payout held for compliance review"}, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	ws.closures.mu.Lock()
+	ws.closures.closures[userID].PayoutTxID = tx.ID
+	ws.closures.mu.Unlock()
+	return balance.String() + " paid out", nil, nil
+}
+
+// completeClosureStep records a finished step
+func (ws *WalletService) completeClosureStep(userID string, step ClosureStep, detail string) {
+	ws.closures.mu.Lock()
+	defer ws.closures.mu.Unlock()
+
+	c := ws.closures.closures[userID]
+	c.Step = step
+	c.BlockedBy = nil
+	c.Completed = append(c.Completed, ClosureStepResult{Step: step, CompletedAt: ws.now().Unix(), Detail: detail})
+}
+
+// closureStep returns the last completed closure step of userID, or "" when the wallet
+// is not being closed
+func (ws *WalletService) closureStep(userID string) ClosureStep {
+	ws.closures.mu.Lock()
+	defer ws.closures.mu.Unlock()
+
+	if c, exists := ws.closures.closures[userID]; exists {
+		return c.Step
+	}
+	return ""
+}
+
+// checkClosure rejects transactions touching a frozen or closed wallet, except money
+// returning from holds, admin corrections and the closure's own payout
+func (ws *WalletService) checkClosure(tx *Transaction) error {
+	if closureAllowedTypes[tx.Type] || tx.Metadata[metaWalletClosure] != "" {
+		return nil
+	}
+	for _, id := range []string{tx.FromUserID, tx.ToUserID} {
+		switch ws.closureStep(id) {
+		case "":
+		case ClosureClosed:
+			return ErrWalletClosed
+		default:
+			return ErrWalletFrozen
+		}
+	}
+	return nil
+}
+
+// nextClosureStep returns the step after step, or "" after the last one
+func nextClosureStep(step ClosureStep) ClosureStep {
+	i := closureStepIndex(step)
+	if i < 0 || i+1 >= len(closureSteps) {
+		return ""
+	}
+	return closureSteps[i+1]
+}
+
+// closureStepIndex returns the position of step in the workflow, or -1
+func closureStepIndex(step ClosureStep) int {
+	for i, s := range closureSteps {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
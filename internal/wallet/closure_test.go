@@ -0,0 +1,151 @@
+// internal/wallet/closure_test.go
+package wallet
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// holdToShop holds every transfer to the shop for review
+func holdToShop(op *Operation) string {
+	if op.ToUserID == "shop" {
+		return "merchant under review"
+	}
+	return ""
+}
+
+func TestCloseWallet_SweepsAndCloses(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	card, _ := ws.IssueCard("alice", "main", CardLimits{}, 365*24*time.Hour)
+	ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(15), Merchant: "cafe"})
+	jobID, _ := ws.SchedulePayment("alice", "bob", decimal.NewFromInt(10), "rent", clock.Now().Add(time.Hour), nil)
+	mandate, _ := ws.CreateMandate("alice", "shop", decimal.NewFromInt(20), MandateMonthly)
+	ws.CreatePaymentLink("alice", decimal.NewFromInt(5), "", 0, PaymentLinkReusable)
+
+	closure, err := ws.CloseWallet("alice", ClosureRequest{SweepToUserID: "bob"})
+	if err != nil {
+		t.Fatalf("CloseWallet() error = %v", err)
+	}
+
+	var steps []ClosureStep
+	for _, r := range closure.Completed {
+		steps = append(steps, r.Step)
+	}
+	if closure.Step != ClosureClosed || len(steps) != len(closureSteps) || closure.ClosedAt == 0 {
+		t.Errorf("closure = %+v, want every step completed", closure)
+	}
+	if !strings.Contains(string(closure.Statement), closure.PayoutTxID) {
+		t.Errorf("final statement does not include the payout %s:\n%s", closure.PayoutTxID, closure.Statement)
+	}
+
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("bob balance = %s, want the released hold swept too (100)", b)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.IsZero() {
+		t.Errorf("alice balance = %s, want 0", b)
+	}
+	if job, _ := ws.GetJob(jobID); job.Status != JobCancelled {
+		t.Errorf("scheduled payment status = %s, want cancelled", job.Status)
+	}
+	if m, _ := ws.GetMandate(mandate.ID); m.Status != MandateRevoked {
+		t.Errorf("mandate status = %s, want revoked", m.Status)
+	}
+	if items, _ := ws.GetPendingItems("alice"); len(items) != 0 {
+		t.Errorf("pending items after closure = %+v", items)
+	}
+
+	tests := []struct {
+		name string
+		op   func() error
+	}{
+		{"deposit", func() error { return ws.Deposit("alice", 1, "") }},
+		{"incoming transfer", func() error { return ws.Transfer("bob", "alice", 1, "") }},
+		{"close again", func() error { _, err := ws.CloseWallet("alice", ClosureRequest{SweepToUserID: "bob"}); return err }},
+	}
+	for _, tt := range tests {
+		if err := tt.op(); err != ErrWalletClosed {
+			t.Errorf("%s on closed wallet error = %v, want %v", tt.name, err, ErrWalletClosed)
+		}
+	}
+}
+
+func TestCloseWallet_BlockedThenResumed(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.RegisterHoldRule("shop_review", holdToShop)
+	ws.Transfer("alice", "shop", 60, "big purchase")
+
+	closure, err := ws.CloseWallet("alice", ClosureRequest{SweepToUserID: "bob"})
+	if err != ErrClosureBlocked {
+		t.Fatalf("CloseWallet() error = %v, want %v", err, ErrClosureBlocked)
+	}
+	if closure.Step != ClosureFrozen || len(closure.BlockedBy) != 1 || !strings.HasPrefix(closure.BlockedBy[0], string(PendingComplianceHold)) {
+		t.Errorf("blocked closure = %+v, want frozen and blocked by the compliance case", closure)
+	}
+	if err := ws.Withdraw("alice", 1, ""); err != ErrWalletFrozen {
+		t.Errorf("Withdraw() while frozen error = %v, want %v", err, ErrWalletFrozen)
+	}
+
+	// Reversing the held transfer returns the money, which the resumed closure sweeps
+	cases := ws.ListCases(CaseOpen)
+	if _, err := ws.ResolveCase(cases[0].ID, "reviewer", false, "declined"); err != nil {
+		t.Fatalf("ResolveCase() error = %v", err)
+	}
+	closure, err = ws.CloseWallet("alice", ClosureRequest{SweepToUserID: "bob"})
+	if err != nil || closure.Step != ClosureClosed {
+		t.Fatalf("resumed CloseWallet() = %+v, %v", closure, err)
+	}
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("bob balance = %s, want 100", b)
+	}
+}
+
+func TestCloseWallet_CancelAndValidation(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.RegisterHoldRule("shop_review", holdToShop)
+	ws.Transfer("alice", "shop", 60, "big purchase")
+	ws.CloseWallet("alice", ClosureRequest{SweepToUserID: "bob"})
+
+	if err := ws.CancelWalletClosure("alice"); err != nil {
+		t.Fatalf("CancelWalletClosure() error = %v", err)
+	}
+	if err := ws.Deposit("alice", 1, ""); err != nil {
+		t.Errorf("Deposit() after cancelled closure error = %v", err)
+	}
+	if _, err := ws.GetClosureStatus("alice"); err != ErrClosureNotFound {
+		t.Errorf("GetClosureStatus() error = %v, want %v", err, ErrClosureNotFound)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		req     ClosureRequest
+		wantErr error
+	}{
+		{"no destination", "alice", ClosureRequest{}, ErrClosureDestination},
+		{"both destinations", "alice", ClosureRequest{SweepToUserID: "bob", DestinationID: "dest"}, ErrClosureDestination},
+		{"sweep to self", "alice", ClosureRequest{SweepToUserID: "alice"}, ErrClosureDestination},
+		{"unknown sweep user", "alice", ClosureRequest{SweepToUserID: "ghost"}, ErrUserNotFound},
+		{"unknown destination", "alice", ClosureRequest{DestinationID: "dest"}, ErrDestinationNotFound},
+		{"unknown user", "ghost", ClosureRequest{SweepToUserID: "bob"}, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := ws.CloseWallet(tt.userID, tt.req); err != tt.wantErr {
+			t.Errorf("%s: CloseWallet() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// internal/wallet/codec_test.go
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestCodec_SnapshotRoundTrip tests binary backups and restoring legacy JSON backups
+func TestCodec_SnapshotRoundTrip(t *testing.T) {
+	ws := NewWalletService()
+	seedBackup(ws)
+
+	var binaryBuf, jsonBuf bytes.Buffer
+	if err := ws.BackupBinary(&binaryBuf); err != nil {
+		t.Fatalf("BackupBinary() error = %v", err)
+	}
+	if err := ws.Backup(&jsonBuf); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	// Gob's fixed type descriptors dominate tiny backups, so compare sizes on a log of
+	// realistic length
+	large := NewWalletService()
+	seedBackup(large)
+	for i := 0; i < 50; i++ {
+		large.Deposit("user2", 1.25, "top-up")
+	}
+	var largeBinary, largeJSON bytes.Buffer
+	large.BackupBinary(&largeBinary)
+	large.Backup(&largeJSON)
+	if largeBinary.Len() >= largeJSON.Len() {
+		t.Errorf("binary backup is %d bytes, JSON %d; want smaller", largeBinary.Len(), largeJSON.Len())
+	}
+
+	for name, buf := range map[string]*bytes.Buffer{"binary": &binaryBuf, "json": &jsonBuf} {
+		t.Run(name, func(t *testing.T) {
+			restored, err := RestoreBinary(buf)
+			if err != nil {
+				t.Fatalf("RestoreBinary() error = %v", err)
+			}
+			assertRestored(t, restored)
+		})
+	}
+}
+
+// TestCodec_TransactionStream tests encoding a log of transaction records
+func TestCodec_TransactionStream(t *testing.T) {
+	ws := NewWalletService()
+	seedBackup(ws)
+	history, _ := ws.GetTransactionHistory("user1")
+
+	var buf bytes.Buffer
+	enc := NewTransactionEncoder(&buf)
+	for _, tx := range history {
+		if err := enc.Encode(tx); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+
+	dec := NewTransactionDecoder(&buf)
+	for i, want := range history {
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode() record %d error = %v", i, err)
+		}
+		if got.ID != want.ID || !got.Amount.Equal(want.Amount) || got.Currency != want.Currency || got.Type != want.Type {
+			t.Errorf("record %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode() at end error = %v, want io.EOF", err)
+	}
+}
+
+// TestCodec_FederationMessages tests that vouchers and receipts survive the wire with
+// their signatures intact
+func TestCodec_FederationMessages(t *testing.T) {
+	alpha, beta := newFederatedPair(newFakeClock())
+
+	voucher, _ := alpha.SendFederatedTransfer("alice", "beta", "bob", decimal.RequireFromString("12.34"), "wire")
+	data, err := voucher.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	var received FederationVoucher
+	if err := received.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+
+	receipt, err := beta.ReceiveFederatedTransfer(received)
+	if err != nil || receipt.Status != ReceiptAccepted {
+		t.Fatalf("ReceiveFederatedTransfer() = %+v, %v", receipt, err)
+	}
+
+	data, _ = receipt.MarshalBinary()
+	var returned FederationReceipt
+	if err := returned.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if transfer, err := alpha.ApplyFederationReceipt(returned); err != nil || transfer.Status != OutboundSettled {
+		t.Errorf("ApplyFederationReceipt() = %+v, %v", transfer, err)
+	}
+
+	// A receipt envelope is not a voucher
+	if err := received.UnmarshalBinary(data); !errors.Is(err, ErrWireKindMismatch) {
+		t.Errorf("decoding receipt as voucher error = %v, want %v", err, ErrWireKindMismatch)
+	}
+}
+
+// TestCodec_RejectsUnknownEnvelopes tests malformed and future envelopes
+func TestCodec_RejectsUnknownEnvelopes(t *testing.T) {
+	future := append(append([]byte{}, wireMagic...), byte(WireVoucher))
+	future = binary.AppendUvarint(future, WireVersion+1)
+	future = binary.AppendUvarint(future, 0)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"future version", future, ErrUnsupportedWireVersion},
+		{"not an envelope", []byte(`{"ID":"x"}`), ErrNotWireEnvelope},
+		{"truncated", wireMagic[:1], ErrNotWireEnvelope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v FederationVoucher
+			if err := v.UnmarshalBinary(tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UnmarshalBinary() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
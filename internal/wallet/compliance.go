@@ -0,0 +1,473 @@
+// internal/wallet/compliance.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for compliance holds and review cases
+var (
+	ErrTransferHeld    = errors.New("transfer held for compliance review")
+	ErrCaseNotFound    = errors.New("compliance case not found")
+	ErrCaseResolved    = errors.New("compliance case already resolved")
+	ErrCaseNoteMissing = errors.New("case note text is required")
+)
+
+// CaseStatus is the lifecycle state of a compliance case
+type CaseStatus string
+
+const (
+	CaseOpen     CaseStatus = "open"
+	CaseReleased CaseStatus = "released"
+	CaseReversed CaseStatus = "reversed"
+)
+
+// HoldRuleFunc inspects a transfer before it is applied and returns a non-empty reason
+// to hold it for review. Like validators, hold rules run while the affected users are
+// locked and must not call mutating WalletService methods.
+type HoldRuleFunc func(op *Operation) (reason string)
+
+// CaseNote is a free-text entry in a case's review log
+type CaseNote struct {
+	Author    string
+	Text      string
+	Timestamp int64
+}
+
+// CaseEvidence is a document or artefact attached to a case. Data is only read when
+// attaching: the blob goes to the attachment store, and cases returned by GetCase leave
+// it empty. Read it back with GetCaseEvidence.
+type CaseEvidence struct {
+	ID          string
+	Name        string
+	ContentType string
+	Data        []byte
+	Size        int64
+	Checksum    string // hex SHA-256 of Data
+	AddedBy     string
+	AddedAt     int64
+	PurgedAt    int64 // set once the blob is deleted after the case closed
+}
+
+// ComplianceCase tracks the review of one held transaction
+type ComplianceCase struct {
+	ID             string
+	TransactionID  string
+	UserID         string // sender of the held funds
+	CounterpartyID string
+	Amount         decimal.Decimal
+	Currency       string
+	Rule           string
+	Reason         string
+	Status         CaseStatus
+	Assignee       string
+	Notes          []CaseNote
+	Evidence       []CaseEvidence
+	OpenedAt       int64
+	ResolvedAt     int64
+	ResolvedBy     string
+
+	// ResolutionTransactionID is the release or reversal that closed the case
+	ResolutionTransactionID string
+}
+
+// CaseMetrics summarises the review backlog
+type CaseMetrics struct {
+	Open       int
+	Resolved   int
+	OldestAge  time.Duration
+	AverageAge time.Duration
+}
+
+// holdRule is a named HoldRuleFunc
+type holdRule struct {
+	name string
+	fn   HoldRuleFunc
+}
+
+// complianceDesk holds hold rules, cases and backlog limits
+type complianceDesk struct {
+	mu         sync.Mutex
+	rules      []holdRule
+	cases      map[string]*ComplianceCase
+	maxBacklog int
+	maxCaseAge time.Duration
+}
+
+// WithCaseBacklogLimits makes the compliance health check report degraded when more than
+// maxOpen cases are open or the oldest open case is older than maxAge. Zero disables a limit.
+func WithCaseBacklogLimits(maxOpen int, maxAge time.Duration) Option {
+	return func(ws *WalletService) {
+		ws.compliance.maxBacklog = maxOpen
+		ws.compliance.maxCaseAge = maxAge
+	}
+}
+
+// RegisterHoldRule adds a named rule evaluated for every transfer, in registration order
+func (ws *WalletService) RegisterHoldRule(name string, fn HoldRuleFunc) {
+	ws.compliance.mu.Lock()
+	defer ws.compliance.mu.Unlock()
+
+	ws.compliance.rules = append(ws.compliance.rules, holdRule{name: name, fn: fn})
+}
+
+// holdReason returns the first rule that wants tx held, and why
+func (ws *WalletService) holdReason(tx *Transaction) (rule, reason string) {
+	ws.compliance.mu.Lock()
+	rules := ws.compliance.rules
+	ws.compliance.mu.Unlock()
+
+	if len(rules) == 0 {
+		return "", ""
+	}
+
+	op := &Operation{
+		Type:        tx.Type,
+		FromUserID:  tx.FromUserID,
+		ToUserID:    tx.ToUserID,
+		Amount:      tx.Amount,
+		Currency:    tx.Currency,
+		Description: tx.Description,
+		Time:        ws.now(),
+	}
+	for _, r := range rules {
+		if reason := r.fn(op); reason != "" {
+			return r.name, reason
+		}
+	}
+	return "", ""
+}
+
+// openCase creates a review case for a held transaction
+func (ws *WalletService) openCase(tx *Transaction, rule, reason string) *ComplianceCase {
+	c := &ComplianceCase{
+		ID:             ws.newID("case"),
+		TransactionID:  tx.ID,
+		UserID:         tx.FromUserID,
+		CounterpartyID: tx.ToUserID,
+		Amount:         tx.Amount,
+		Currency:       tx.Currency,
+		Rule:           rule,
+		Reason:         reason,
+		Status:         CaseOpen,
+		OpenedAt:       ws.now().Unix(),
+	}
+
+	ws.compliance.mu.Lock()
+	if ws.compliance.cases == nil {
+		ws.compliance.cases = make(map[string]*ComplianceCase)
+	}
+	ws.compliance.cases[c.ID] = c
+	ws.compliance.mu.Unlock()
+
+	ws.metrics.IncCounter("compliance_holds_total", map[string]string{"rule": rule})
+	return c
+}
+
+// GetCase returns a copy of a compliance case
+func (ws *WalletService) GetCase(caseID string) (*ComplianceCase, error) {
+	ws.compliance.mu.Lock()
+	defer ws.compliance.mu.Unlock()
+
+	c, exists := ws.compliance.cases[caseID]
+	if !exists {
+		return nil, ErrCaseNotFound
+	}
+	return c.clone(), nil
+}
+
+// ListCases returns cases with the given status, oldest first. An empty status lists all.
+func (ws *WalletService) ListCases(status CaseStatus) []*ComplianceCase {
+	ws.compliance.mu.Lock()
+	defer ws.compliance.mu.Unlock()
+
+	var cases []*ComplianceCase
+	for _, c := range ws.compliance.cases {
+		if status == "" || c.Status == status {
+			cases = append(cases, c.clone())
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		if cases[i].OpenedAt != cases[j].OpenedAt {
+			return cases[i].OpenedAt < cases[j].OpenedAt
+		}
+		return cases[i].ID < cases[j].ID
+	})
+	return cases
+}
+
+// AssignCase sets the reviewer responsible for an open case
+func (ws *WalletService) AssignCase(caseID, assignee string) error {
+	return ws.updateOpenCase(caseID, func(c *ComplianceCase) {
+		c.Assignee = assignee
+	})
+}
+
+// AddCaseNote appends a note to an open case's review log
+func (ws *WalletService) AddCaseNote(caseID, author, text string) error {
+	if text == "" {
+		return ErrCaseNoteMissing
+	}
+	now := ws.now().Unix()
+	return ws.updateOpenCase(caseID, func(c *ComplianceCase) {
+		c.Notes = append(c.Notes, CaseNote{Author: author, Text: text, Timestamp: now})
+	})
+}
+
+// AttachCaseEvidence attaches a document to an open case. The document must meet the
+// attachment policy's size and content type limits.
+func (ws *WalletService) AttachCaseEvidence(caseID string, evidence CaseEvidence) error {
+	if err := ws.updateOpenCase(caseID, func(*ComplianceCase) {}); err != nil {
+		return err
+	}
+
+	evidence.ID = ws.newID("evid")
+	key := evidenceKey(caseID, evidence.ID)
+	contentType, checksum, err := ws.putAttachment(key, evidence.ContentType, evidence.Data)
+	if err != nil {
+		return err
+	}
+	evidence.ContentType, evidence.Checksum = contentType, checksum
+	evidence.Size = int64(len(evidence.Data))
+	evidence.Data = nil
+	evidence.AddedAt = ws.now().Unix()
+
+	err = ws.updateOpenCase(caseID, func(c *ComplianceCase) {
+		c.Evidence = append(c.Evidence, evidence)
+	})
+	if err != nil {
+		// The case closed while the blob was being stored
+		ws.deleteAttachments([]string{key})
+	}
+	return err
+}
+
+// GetCaseEvidence returns the document attached to a case as evidenceID, checked
+// against the checksum taken when it was attached
+func (ws *WalletService) GetCaseEvidence(caseID, evidenceID string) ([]byte, error) {
+	ws.compliance.mu.Lock()
+	c, exists := ws.compliance.cases[caseID]
+	if !exists {
+		ws.compliance.mu.Unlock()
+		return nil, ErrCaseNotFound
+	}
+	i := slices.IndexFunc(c.Evidence, func(e CaseEvidence) bool { return e.ID == evidenceID })
+	if i < 0 {
+		ws.compliance.mu.Unlock()
+		return nil, ErrAttachmentNotFound
+	}
+	evidence := c.Evidence[i]
+	ws.compliance.mu.Unlock()
+
+	if evidence.PurgedAt != 0 {
+		return nil, ErrAttachmentPurged
+	}
+	return ws.getAttachment(evidenceKey(caseID, evidenceID), evidence.Checksum)
+}
+
+// ResolveCase closes a case. With release the held funds are credited to the original
+// recipient; otherwise the hold is reversed and the sender is refunded.
+func (ws *WalletService) ResolveCase(caseID, reviewer string, release bool, note string) (*Transaction, error) {
+	return ws.ResolveCaseContext(context.Background(), caseID, reviewer, release, note)
+}
+
+// ResolveCaseContext is ResolveCase on behalf of a caller's ctx
+func (ws *WalletService) ResolveCaseContext(ctx context.Context, caseID, reviewer string, release bool, note string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ws.compliance.mu.Lock()
+	c, exists := ws.compliance.cases[caseID]
+	if !exists {
+		ws.compliance.mu.Unlock()
+		return nil, ErrCaseNotFound
+	}
+	if c.Status != CaseOpen {
+		ws.compliance.mu.Unlock()
+		return nil, ErrCaseResolved
+	}
+	// Mark before posting so a concurrent resolution cannot move the funds twice
+	status := CaseReversed
+	if release {
+		status = CaseReleased
+	}
+	c.Status = status
+	held := *c
+	ws.compliance.mu.Unlock()
+
+	tx := &Transaction{
+		Amount:     held.Amount,
+		Currency:   held.Currency,
+		ParentTxID: held.TransactionID,
+	}
+	if release {
+		tx.Type = TransactionHoldRelease
+		tx.FromUserID, tx.ToUserID = held.UserID, held.CounterpartyID
+		tx.Description = "released " + held.ID
+	} else {
+		tx.Type = TransactionHoldReversal
+		tx.FromUserID, tx.ToUserID = held.CounterpartyID, held.UserID
+		tx.Description = "reversed " + held.ID
+	}
+
+	if err := ws.postCredit(ctx, tx); err != nil {
+		ws.compliance.mu.Lock()
+		c.Status = CaseOpen
+		ws.compliance.mu.Unlock()
+		return nil, err
+	}
+	if !release {
+		ws.cancelHeldRefund(held.TransactionID, held.Amount)
+	}
+
+	now := ws.now().Unix()
+	ws.compliance.mu.Lock()
+	c.ResolvedAt = now
+	c.ResolvedBy = reviewer
+	c.ResolutionTransactionID = tx.ID
+	if note != "" {
+		c.Notes = append(c.Notes, CaseNote{Author: reviewer, Text: note, Timestamp: now})
+	}
+	ws.compliance.mu.Unlock()
+
+	ws.metrics.IncCounter("compliance_cases_resolved_total", map[string]string{"status": string(status)})
+	ws.metrics.ObserveValue("compliance_case_age_seconds", float64(now-held.OpenedAt), nil)
+	ws.scheduleEvidencePurge(caseID)
+
+	return tx, nil
+}
+
+// GetCaseMetrics reports the size and age of the review backlog
+func (ws *WalletService) GetCaseMetrics() CaseMetrics {
+	now := ws.now().Unix()
+
+	ws.compliance.mu.Lock()
+	defer ws.compliance.mu.Unlock()
+
+	var m CaseMetrics
+	var totalAge int64
+	for _, c := range ws.compliance.cases {
+		if c.Status != CaseOpen {
+			m.Resolved++
+			continue
+		}
+		m.Open++
+		age := now - c.OpenedAt
+		totalAge += age
+		if d := time.Duration(age) * time.Second; d > m.OldestAge {
+			m.OldestAge = d
+		}
+	}
+	if m.Open > 0 {
+		m.AverageAge = time.Duration(totalAge/int64(m.Open)) * time.Second
+	}
+	return m
+}
+
+// complianceHealth reports degraded when the review backlog exceeds its configured limits
+func (ws *WalletService) complianceHealth() HealthCheckResult {
+	m := ws.GetCaseMetrics()
+	result := HealthCheckResult{
+		Status: HealthOK,
+		Data: map[string]string{
+			"open":                fmt.Sprint(m.Open),
+			"oldest_age_seconds":  fmt.Sprint(int64(m.OldestAge.Seconds())),
+			"average_age_seconds": fmt.Sprint(int64(m.AverageAge.Seconds())),
+		},
+	}
+
+	ws.compliance.mu.Lock()
+	maxBacklog, maxAge := ws.compliance.maxBacklog, ws.compliance.maxCaseAge
+	ws.compliance.mu.Unlock()
+
+	switch {
+	case maxBacklog > 0 && m.Open > maxBacklog:
+		result.Status = HealthDegraded
+		result.Detail = fmt.Sprintf("%d open cases exceeds limit of %d", m.Open, maxBacklog)
+	case maxAge > 0 && m.OldestAge > maxAge:
+		result.Status = HealthDegraded
+		result.Detail = fmt.Sprintf("oldest open case is %s old, limit %s", m.OldestAge, maxAge)
+	}
+	return result
+}
+
+// updateOpenCase applies fn to a case that is still open
+func (ws *WalletService) updateOpenCase(caseID string, fn func(*ComplianceCase)) error {
+	ws.compliance.mu.Lock()
+	defer ws.compliance.mu.Unlock()
+
+	c, exists := ws.compliance.cases[caseID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+	if c.Status != CaseOpen {
+		return ErrCaseResolved
+	}
+	fn(c)
+	return nil
+}
+
+// scheduleEvidencePurge deletes a closed case's evidence blobs once the attachment
+// policy's retention has passed, at once when it is zero. The evidence records stay on
+// the case, marked purged. Evidence of a case under legal hold is kept.
+func (ws *WalletService) scheduleEvidencePurge(caseID string) {
+	purge := func(now time.Time) error {
+		// Released holds reschedule the purge
+		if ws.caseOnLegalHold(caseID) {
+			return nil
+		}
+
+		ws.compliance.mu.Lock()
+		c := ws.compliance.cases[caseID]
+		var keys []string
+		for _, e := range c.Evidence {
+			if e.PurgedAt == 0 {
+				keys = append(keys, evidenceKey(caseID, e.ID))
+			}
+		}
+		ws.compliance.mu.Unlock()
+
+		if err := ws.deleteAttachments(keys); err != nil {
+			return err
+		}
+
+		ws.compliance.mu.Lock()
+		for i := range c.Evidence {
+			if c.Evidence[i].PurgedAt == 0 {
+				c.Evidence[i].PurgedAt = now.Unix()
+			}
+		}
+		ws.compliance.mu.Unlock()
+		return nil
+	}
+
+	_, policy := ws.attachmentStore()
+	if policy.Retention <= 0 {
+		// A failed delete is retried by the job
+		if purge(ws.now()) == nil {
+			return
+		}
+	}
+	ws.schedule("case_evidence_purge", caseID, ws.now().Add(policy.Retention), nil, purge)
+}
+
+// evidenceKey is the attachment store key of a case's evidence
+func evidenceKey(caseID, evidenceID string) string {
+	return "cases/" + caseID + "/" + evidenceID
+}
+
+// clone returns a deep copy of the case
+func (c *ComplianceCase) clone() *ComplianceCase {
+	copied := *c
+	copied.Notes = append([]CaseNote(nil), c.Notes...)
+	copied.Evidence = append([]CaseEvidence(nil), c.Evidence...)
+	return &copied
+}
@@ -0,0 +1,157 @@
+// internal/wallet/compliance_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// holdOver holds transfers larger than limit
+func holdOver(limit int64) HoldRuleFunc {
+	return func(op *Operation) string {
+		if op.Amount.GreaterThan(decimal.NewFromInt(limit)) {
+			return "amount above review threshold"
+		}
+		return ""
+	}
+}
+
+func TestTransfer_HeldForReview(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 1000, "seed")
+	ws.RegisterHoldRule("large_transfer", holdOver(500))
+
+	if err := ws.Transfer("alice", "bob", 100, "small"); err != nil {
+		t.Fatalf("small Transfer() error = %v", err)
+	}
+	if err := ws.Transfer("alice", "bob", 600, "large"); !errors.Is(err, ErrTransferHeld) {
+		t.Fatalf("large Transfer() error = %v, want %v", err, ErrTransferHeld)
+	}
+
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(300)) {
+		t.Errorf("alice balance = %s, want 300", b)
+	}
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("bob balance = %s, want 100 while held", b)
+	}
+
+	cases := ws.ListCases(CaseOpen)
+	if len(cases) != 1 {
+		t.Fatalf("open cases = %d, want 1", len(cases))
+	}
+	c := cases[0]
+	if c.UserID != "alice" || c.CounterpartyID != "bob" || c.Rule != "large_transfer" || !c.Amount.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("case = %+v", c)
+	}
+}
+
+func TestResolveCase(t *testing.T) {
+	tests := []struct {
+		name       string
+		release    bool
+		wantStatus CaseStatus
+		wantAlice  int64
+		wantBob    int64
+	}{
+		{"release", true, CaseReleased, 400, 600},
+		{"reverse", false, CaseReversed, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, _ := newTestService()
+			ws.CreateUser("alice", "Alice", "alice@example.com")
+			ws.CreateUser("bob", "Bob", "bob@example.com")
+			ws.Deposit("alice", 1000, "seed")
+			ws.RegisterHoldRule("large_transfer", holdOver(500))
+			ws.Transfer("alice", "bob", 600, "large")
+			c := ws.ListCases(CaseOpen)[0]
+
+			if err := ws.AssignCase(c.ID, "reviewer1"); err != nil {
+				t.Fatalf("AssignCase() error = %v", err)
+			}
+			if err := ws.AddCaseNote(c.ID, "reviewer1", "checking source of funds"); err != nil {
+				t.Fatalf("AddCaseNote() error = %v", err)
+			}
+			if err := ws.AttachCaseEvidence(c.ID, CaseEvidence{Name: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF")}); err != nil {
+				t.Fatalf("AttachCaseEvidence() error = %v", err)
+			}
+
+			tx, err := ws.ResolveCase(c.ID, "reviewer1", tt.release, "done")
+			if err != nil {
+				t.Fatalf("ResolveCase() error = %v", err)
+			}
+			if tx.ParentTxID != c.TransactionID {
+				t.Errorf("resolution linked to %q, want %q", tx.ParentTxID, c.TransactionID)
+			}
+
+			if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(tt.wantAlice)) {
+				t.Errorf("alice balance = %s, want %d", b, tt.wantAlice)
+			}
+			if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(tt.wantBob)) {
+				t.Errorf("bob balance = %s, want %d", b, tt.wantBob)
+			}
+
+			got, _ := ws.GetCase(c.ID)
+			if got.Status != tt.wantStatus || got.Assignee != "reviewer1" || len(got.Notes) != 2 || len(got.Evidence) != 1 {
+				t.Errorf("case = %+v", got)
+			}
+			if _, err := ws.ResolveCase(c.ID, "reviewer1", tt.release, ""); err != ErrCaseResolved {
+				t.Errorf("second ResolveCase() error = %v, want %v", err, ErrCaseResolved)
+			}
+			if err := ws.AddCaseNote(c.ID, "reviewer1", "late"); err != ErrCaseResolved {
+				t.Errorf("AddCaseNote() on resolved case error = %v, want %v", err, ErrCaseResolved)
+			}
+
+			for _, user := range []string{"alice", "bob"} {
+				if mismatch, err := ws.CheckWalletIntegrity(user); err != nil || mismatch != nil {
+					t.Errorf("CheckWalletIntegrity(%s) = %+v, %v", user, mismatch, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCaseMetricsAndHealth(t *testing.T) {
+	ws, clock := newTestService(WithCaseBacklogLimits(0, 48*time.Hour))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 1000, "seed")
+	ws.RegisterHoldRule("large_transfer", holdOver(500))
+
+	ws.Transfer("alice", "bob", 600, "first")
+	clock.Advance(24 * time.Hour)
+
+	if report := ws.CheckHealth(); report.Status != HealthOK {
+		t.Errorf("health = %s, want ok", report.Status)
+	}
+
+	ws.Deposit("alice", 1000, "top up")
+	ws.Transfer("alice", "bob", 700, "second")
+	clock.Advance(25 * time.Hour)
+
+	m := ws.GetCaseMetrics()
+	if m.Open != 2 || m.OldestAge != 49*time.Hour || m.AverageAge != 37*time.Hour {
+		t.Errorf("metrics = %+v", m)
+	}
+
+	report := ws.CheckHealth()
+	if report.Status != HealthDegraded {
+		t.Fatalf("health = %s, want degraded", report.Status)
+	}
+	if report.Checks[0].Name != "compliance_cases" || report.Checks[0].Data["open"] != "2" {
+		t.Errorf("compliance check = %+v", report.Checks[0])
+	}
+
+	ws.RegisterHealthCheck("database", func() HealthCheckResult {
+		return HealthCheckResult{Status: HealthFailing, Detail: "connection refused"}
+	})
+	if report := ws.CheckHealth(); report.Status != HealthFailing || len(report.Checks) != 3 {
+		t.Errorf("report = %+v, want failing with 3 checks", report)
+	}
+}
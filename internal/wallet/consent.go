@@ -0,0 +1,239 @@
+// internal/wallet/consent.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Error definitions for consents
+var (
+	ErrConsentRequired     = errors.New("a required consent has not been accepted")
+	ErrInvalidConsent      = errors.New("consent version needs a kind and a version")
+	ErrConsentNotPublished = errors.New("consent version is not the published one")
+	ErrConsentNotAccepted  = errors.New("consent is not currently accepted")
+)
+
+// ConsentKind names a document or permission users agree to
+type ConsentKind string
+
+const (
+	ConsentTerms       ConsentKind = "terms"
+	ConsentMarketing   ConsentKind = "marketing"
+	ConsentDataSharing ConsentKind = "data_sharing"
+)
+
+// EventConsentRequired is emitted for every user who has to accept a newly published
+// mandatory consent version
+const EventConsentRequired EventType = "consent_required"
+
+// ConsentVersion is a published version of a consent. Users must accept a Mandatory
+// version again even if they accepted an earlier one; until they do, transactions of
+// the Blocks types they initiate are refused.
+type ConsentVersion struct {
+	Kind        ConsentKind
+	Version     string
+	Mandatory   bool
+	Blocks      []TransactionType
+	PublishedAt int64
+}
+
+// ConsentRecord is one acceptance or withdrawal of a consent by a user
+type ConsentRecord struct {
+	Kind     ConsentKind
+	Version  string
+	Accepted bool // false when the user withdrew the consent
+	At       int64
+}
+
+// consentBook holds the published consent versions and each user's consent history
+type consentBook struct {
+	mu        sync.Mutex
+	published map[ConsentKind]ConsentVersion // current version per kind
+	records   map[string][]ConsentRecord     // by user, oldest first
+}
+
+// PublishConsentVersion makes v the current version of its kind. Publishing a
+// mandatory version emits EventConsentRequired and notifies every user who has not
+// accepted it yet.
+func (ws *WalletService) PublishConsentVersion(v ConsentVersion) error {
+	if v.Kind == "" || v.Version == "" {
+		return ErrInvalidConsent
+	}
+	v.Blocks = slices.Clone(v.Blocks)
+	v.PublishedAt = ws.now().Unix()
+
+	ws.mu.RLock()
+	userIDs := make([]string, 0, len(ws.users))
+	for userID := range ws.users {
+		userIDs = append(userIDs, userID)
+	}
+	ws.mu.RUnlock()
+	sort.Strings(userIDs)
+
+	ws.consents.mu.Lock()
+	if ws.consents.published == nil {
+		ws.consents.published = make(map[ConsentKind]ConsentVersion)
+	}
+	ws.consents.published[v.Kind] = v
+	var pending []string
+	if v.Mandatory {
+		for _, userID := range userIDs {
+			if !ws.consents.accepted(userID, v) {
+				pending = append(pending, userID)
+			}
+		}
+	}
+	ws.consents.mu.Unlock()
+
+	for _, userID := range pending {
+		ws.emit(Event{Type: EventConsentRequired, UserID: userID, Timestamp: v.PublishedAt})
+		ws.notify(Notification{
+			UserID:  userID,
+			Type:    string(EventConsentRequired),
+			Subject: "Please review our updated " + string(v.Kind),
+			Message: fmt.Sprintf("Version %s of the %s needs your acceptance", v.Version, v.Kind),
+			Data:    map[string]string{"kind": string(v.Kind), "version": v.Version},
+		})
+	}
+	return nil
+}
+
+// GetConsentVersion returns the published version of kind
+func (ws *WalletService) GetConsentVersion(kind ConsentKind) (*ConsentVersion, error) {
+	ws.consents.mu.Lock()
+	defer ws.consents.mu.Unlock()
+
+	v, exists := ws.consents.published[kind]
+	if !exists {
+		return nil, ErrConsentNotPublished
+	}
+	v.Blocks = slices.Clone(v.Blocks)
+	return &v, nil
+}
+
+// AcceptConsent records userID accepting the published version of kind. Accepting an
+// outdated version fails with ErrConsentNotPublished.
+func (ws *WalletService) AcceptConsent(userID string, kind ConsentKind, version string) error {
+	return ws.recordConsent(userID, kind, func(published ConsentVersion, current ConsentRecord) (ConsentRecord, error) {
+		if published.Version != version {
+			return ConsentRecord{}, ErrConsentNotPublished
+		}
+		return ConsentRecord{Kind: kind, Version: version, Accepted: true}, nil
+	})
+}
+
+// WithdrawConsent records userID withdrawing an accepted consent, e.g. opting out of
+// marketing. Withdrawing a mandatory consent blocks what it guards again.
+func (ws *WalletService) WithdrawConsent(userID string, kind ConsentKind) error {
+	return ws.recordConsent(userID, kind, func(published ConsentVersion, current ConsentRecord) (ConsentRecord, error) {
+		if !current.Accepted {
+			return ConsentRecord{}, ErrConsentNotAccepted
+		}
+		return ConsentRecord{Kind: kind, Version: current.Version}, nil
+	})
+}
+
+// recordConsent appends the record decide builds from the published version and the
+// user's current record of kind
+func (ws *WalletService) recordConsent(userID string, kind ConsentKind, decide func(published ConsentVersion, current ConsentRecord) (ConsentRecord, error)) error {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	ws.consents.mu.Lock()
+	defer ws.consents.mu.Unlock()
+
+	published, exists := ws.consents.published[kind]
+	if !exists {
+		return ErrConsentNotPublished
+	}
+	current := ws.consents.current(userID, kind)
+	record, err := decide(published, current)
+	if err != nil {
+		return err
+	}
+	record.At = ws.now().Unix()
+	if ws.consents.records == nil {
+		ws.consents.records = make(map[string][]ConsentRecord)
+	}
+	ws.consents.records[userID] = append(ws.consents.records[userID], record)
+	return nil
+}
+
+// GetConsentHistory returns every acceptance and withdrawal by userID, oldest first
+func (ws *WalletService) GetConsentHistory(userID string) ([]ConsentRecord, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.consents.mu.Lock()
+	defer ws.consents.mu.Unlock()
+	return slices.Clone(ws.consents.records[userID]), nil
+}
+
+// MissingConsents returns the mandatory versions userID has yet to accept, by kind
+func (ws *WalletService) MissingConsents(userID string) ([]ConsentVersion, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.consents.mu.Lock()
+	defer ws.consents.mu.Unlock()
+	var missing []ConsentVersion
+	for _, v := range ws.consents.published {
+		if v.Mandatory && !ws.consents.accepted(userID, v) {
+			v.Blocks = slices.Clone(v.Blocks)
+			missing = append(missing, v)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Kind < missing[j].Kind })
+	return missing, nil
+}
+
+// checkConsent refuses transactions a mandatory consent guards until the initiating
+// user has accepted its current version
+func (ws *WalletService) checkConsent(tx *Transaction) error {
+	userID := tx.FromUserID
+	if transactionKind(tx.Type) == KindCredit {
+		userID = tx.ToUserID
+	}
+
+	ws.consents.mu.Lock()
+	defer ws.consents.mu.Unlock()
+	for _, v := range ws.consents.published {
+		if v.Mandatory && slices.Contains(v.Blocks, tx.Type) && !ws.consents.accepted(userID, v) {
+			return fmt.Errorf("%w: %s version %s", ErrConsentRequired, v.Kind, v.Version)
+		}
+	}
+	return nil
+}
+
+// current returns userID's latest record of kind. Caller must hold b.mu.
+func (b *consentBook) current(userID string, kind ConsentKind) ConsentRecord {
+	records := b.records[userID]
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Kind == kind {
+			return records[i]
+		}
+	}
+	return ConsentRecord{}
+}
+
+// accepted reports whether userID currently accepts version v. Caller must hold b.mu.
+func (b *consentBook) accepted(userID string, v ConsentVersion) bool {
+	r := b.current(userID, v.Kind)
+	return r.Accepted && r.Version == v.Version
+}
@@ -0,0 +1,100 @@
+// internal/wallet/consent_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConsentBlocksUntilAccepted(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	terms := ConsentVersion{Kind: ConsentTerms, Version: "2024-01", Mandatory: true,
+		Blocks: []TransactionType{TransactionWithdraw, TransactionTransfer}}
+	if err := ws.PublishConsentVersion(terms); err != nil {
+		t.Fatalf("PublishConsentVersion() error = %v", err)
+	}
+	if got := countEvents(ws, EventConsentRequired); got != 2 {
+		t.Errorf("consent_required events = %d, want one per user", got)
+	}
+
+	steps := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{"deposit is not guarded", func() error { return ws.Deposit("alice", 10, "") }, nil},
+		{"withdraw blocked", func() error { return ws.Withdraw("alice", 10, "") }, ErrConsentRequired},
+		{"accept outdated version", func() error { return ws.AcceptConsent("alice", ConsentTerms, "2023-06") }, ErrConsentNotPublished},
+		{"accept", func() error { return ws.AcceptConsent("alice", ConsentTerms, "2024-01") }, nil},
+		{"withdraw allowed", func() error { return ws.Withdraw("alice", 10, "") }, nil},
+		{"new version published", func() error {
+			terms.Version = "2024-02"
+			return ws.PublishConsentVersion(terms)
+		}, nil},
+		{"transfer blocked again", func() error { return ws.Transfer("alice", "bob", 10, "") }, ErrConsentRequired},
+		{"re-accept", func() error { return ws.AcceptConsent("alice", ConsentTerms, "2024-02") }, nil},
+		{"transfer allowed", func() error { return ws.Transfer("alice", "bob", 10, "") }, nil},
+	}
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			clock.Advance(time.Minute)
+			if err := s.run(); !errors.Is(err, s.wantErr) {
+				t.Errorf("error = %v, want %v", err, s.wantErr)
+			}
+		})
+	}
+
+	if got := countEvents(ws, EventConsentRequired); got != 4 {
+		t.Errorf("consent_required events = %d, want 4 after the second version", got)
+	}
+	if missing, _ := ws.MissingConsents("bob"); len(missing) != 1 || missing[0].Version != "2024-02" {
+		t.Errorf("MissingConsents(bob) = %+v, want terms 2024-02", missing)
+	}
+	if missing, _ := ws.MissingConsents("alice"); len(missing) != 0 {
+		t.Errorf("MissingConsents(alice) = %+v, want none", missing)
+	}
+	history, _ := ws.GetConsentHistory("alice")
+	if len(history) != 2 || history[1].Version != "2024-02" || history[1].At <= history[0].At {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestOptionalConsent(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.PublishConsentVersion(ConsentVersion{Kind: ConsentMarketing, Version: "1"})
+
+	if got := countEvents(ws, EventConsentRequired); got != 0 {
+		t.Errorf("consent_required events = %d, want none for an optional consent", got)
+	}
+	if err := ws.WithdrawConsent("alice", ConsentMarketing); err != ErrConsentNotAccepted {
+		t.Errorf("WithdrawConsent(never accepted) error = %v, want %v", err, ErrConsentNotAccepted)
+	}
+	ws.AcceptConsent("alice", ConsentMarketing, "1")
+	if err := ws.WithdrawConsent("alice", ConsentMarketing); err != nil {
+		t.Fatalf("WithdrawConsent() error = %v", err)
+	}
+	history, _ := ws.GetConsentHistory("alice")
+	if len(history) != 2 || !history[0].Accepted || history[1].Accepted {
+		t.Errorf("history = %+v, want accept then withdrawal", history)
+	}
+	if err := ws.AcceptConsent("alice", ConsentDataSharing, "1"); err != ErrConsentNotPublished {
+		t.Errorf("AcceptConsent(unpublished kind) error = %v, want %v", err, ErrConsentNotPublished)
+	}
+}
+
+// countEvents counts emitted events of type t
+func countEvents(ws *WalletService, t EventType) int {
+	n := 0
+	for _, evt := range ws.EventsSince(0, 1000) {
+		if evt.Type == t {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,227 @@
+// internal/wallet/currency.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for the currency registry
+var (
+	ErrCurrencyExists          = errors.New("currency already registered")
+	ErrCurrencyNotTransferable = errors.New("currency cannot be transferred between users")
+	ErrCurrencyNotConvertible  = errors.New("currency cannot be converted")
+	ErrPrecisionExceeded       = errors.New("amount has more decimal places than the currency allows")
+)
+
+// Currency describes a unit of value the service can hold: a fiat currency or a custom
+// asset such as loyalty points
+type Currency struct {
+	Code      string
+	Name      string
+	Symbol    string
+	Precision int32 // decimal places used for rounding and display
+
+	// StrictPrecision rejects amounts with more decimal places than Precision
+	StrictPrecision bool
+	// Transferable allows the currency to move between users
+	Transferable bool
+	// Convertible allows the currency to be exchanged for other convertible currencies
+	Convertible bool
+}
+
+// defaultCurrencies are registered on every new service
+var defaultCurrencies = []Currency{
+	{Code: "USD", Name: "US Dollar", Symbol: "$", Precision: 2, Transferable: true, Convertible: true},
+	{Code: "EUR", Name: "Euro", Symbol: "€", Precision: 2, Transferable: true, Convertible: true},
+	{Code: "GBP", Name: "Pound Sterling", Symbol: "£", Precision: 2, Transferable: true, Convertible: true},
+	{Code: "JPY", Name: "Japanese Yen", Symbol: "¥", Precision: 0, Transferable: true, Convertible: true},
+}
+
+// currencyRegistry holds the currencies known to the service
+type currencyRegistry struct {
+	mu     sync.RWMutex
+	byCode map[string]Currency
+}
+
+// defaultCurrencyMap returns the default currencies keyed by code
+func defaultCurrencyMap() map[string]Currency {
+	byCode := make(map[string]Currency, len(defaultCurrencies))
+	for _, c := range defaultCurrencies {
+		byCode[c.Code] = c
+	}
+	return byCode
+}
+
+// RegisterCurrency adds a custom currency or asset to the registry
+func (ws *WalletService) RegisterCurrency(c Currency) error {
+	c.Code = normalizeCurrency(c.Code)
+	if c.Code == "" || c.Precision < 0 {
+		return ErrInvalidCurrency
+	}
+
+	ws.currencies.mu.Lock()
+	defer ws.currencies.mu.Unlock()
+
+	if _, exists := ws.currencies.byCode[c.Code]; exists {
+		return ErrCurrencyExists
+	}
+	ws.currencies.byCode[c.Code] = c
+
+	return nil
+}
+
+// GetCurrency returns the registered definition of a currency
+func (ws *WalletService) GetCurrency(code string) (Currency, error) {
+	ws.currencies.mu.RLock()
+	defer ws.currencies.mu.RUnlock()
+
+	c, exists := ws.currencies.byCode[normalizeCurrency(code)]
+	if !exists {
+		return Currency{}, ErrInvalidCurrency
+	}
+	return c, nil
+}
+
+// ListCurrencies returns all registered currencies ordered by code
+func (ws *WalletService) ListCurrencies() []Currency {
+	ws.currencies.mu.RLock()
+	defer ws.currencies.mu.RUnlock()
+
+	list := make([]Currency, 0, len(ws.currencies.byCode))
+	for _, c := range ws.currencies.byCode {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// FormatAmount renders amount using the currency's symbol and precision
+func (ws *WalletService) FormatAmount(amount decimal.Decimal, code string) (string, error) {
+	c, err := ws.GetCurrency(code)
+	if err != nil {
+		return "", err
+	}
+
+	value := amount.StringFixed(c.Precision)
+	if c.Symbol == "" {
+		return value + " " + c.Code, nil
+	}
+	if amount.IsNegative() {
+		return "-" + c.Symbol + strings.TrimPrefix(value, "-"), nil
+	}
+	return c.Symbol + value, nil
+}
+
+// checkAmount validates amount against the registry entry for code. When transfer is
+// set the currency must also be transferable between users.
+func (ws *WalletService) checkAmount(code string, amount decimal.Decimal, transfer bool) error {
+	c, err := ws.GetCurrency(code)
+	if err != nil {
+		return err
+	}
+	if c.StrictPrecision && !amount.Equal(amount.Truncate(c.Precision)) {
+		return ErrPrecisionExceeded
+	}
+	if transfer && !c.Transferable {
+		return ErrCurrencyNotTransferable
+	}
+	return nil
+}
+
+// currencyPrecision returns the number of decimal places amounts in code are rounded to
+func (ws *WalletService) currencyPrecision(code string) int32 {
+	if c, err := ws.GetCurrency(code); err == nil {
+		return c.Precision
+	}
+	return 2
+}
+
+// normalizeCurrency canonicalizes a currency code, returning "" when it is unusable
+func normalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// balanceIn returns the wallet's holding in currency. Caller must hold w.mu.
+func (w *Wallet) balanceIn(currency string) decimal.Decimal {
+	if currency == w.Currency {
+		return w.Balance
+	}
+	return w.Foreign[currency]
+}
+
+// available returns the part of the holding in currency not reserved by holds. Caller
+// must hold w.mu.
+func (w *Wallet) available(currency string) decimal.Decimal {
+	return w.balanceIn(currency).Sub(w.held[currency])
+}
+
+// hold adds delta to the funds reserved in currency. Caller must hold w.mu.
+func (w *Wallet) hold(currency string, delta decimal.Decimal) {
+	if delta.IsZero() {
+		return
+	}
+	if w.held == nil {
+		w.held = make(map[string]decimal.Decimal)
+	}
+	w.held[currency] = w.held[currency].Add(delta)
+}
+
+// adjust adds delta to the wallet's holding in currency. Caller must hold w.mu.
+func (w *Wallet) adjust(currency string, delta decimal.Decimal) {
+	if currency == w.Currency {
+		w.Balance = w.Balance.Add(delta)
+		return
+	}
+	if w.Foreign == nil {
+		w.Foreign = make(map[string]decimal.Decimal)
+	}
+	w.Foreign[currency] = w.Foreign[currency].Add(delta)
+}
+
+// DepositCurrency adds funds denominated in the given currency to a user's wallet
+func (ws *WalletService) DepositCurrency(userID, currency string, amount decimal.Decimal, description string) error {
+	return ws.DepositCurrencyContext(context.Background(), userID, currency, amount, description)
+}
+
+// DepositCurrencyContext is DepositCurrency on behalf of a caller's ctx
+func (ws *WalletService) DepositCurrencyContext(ctx context.Context, userID, currency string, amount decimal.Decimal, description string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return ErrInvalidAmount
+	}
+	currency = normalizeCurrency(currency)
+	if currency == "" {
+		return ErrInvalidCurrency
+	}
+
+	return ws.postCredit(ctx, &Transaction{
+		FromUserID:  userID,
+		ToUserID:    userID,
+		Amount:      amount,
+		Currency:    currency,
+		Type:        TransactionDeposit,
+		Description: description,
+	})
+}
+
+// GetCurrencyBalance returns a user's holding in the given currency
+func (ws *WalletService) GetCurrencyBalance(userID, currency string) (decimal.Decimal, error) {
+	currency = normalizeCurrency(currency)
+	if currency == "" {
+		return decimal.Zero, ErrInvalidCurrency
+	}
+
+	view, base, err := ws.loadView(userID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return view.balanceIn(currency, base), nil
+}
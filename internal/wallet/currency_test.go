@@ -0,0 +1,76 @@
+// internal/wallet/currency_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestWalletService_CurrencyRegistry tests custom assets and registry-driven validation
+func TestWalletService_CurrencyRegistry(t *testing.T) {
+	rates := StaticRateProvider{"PTS/USD": decimal.RequireFromString("0.01")}
+	ws := NewWalletService(WithRateProvider(rates))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+
+	points := Currency{Code: "pts", Name: "Loyalty Points", Precision: 0, StrictPrecision: true}
+	if err := ws.RegisterCurrency(points); err != nil {
+		t.Fatalf("RegisterCurrency() error = %v", err)
+	}
+	if err := ws.RegisterCurrency(points); err != ErrCurrencyExists {
+		t.Errorf("Expected ErrCurrencyExists, got %v", err)
+	}
+
+	if err := ws.DepositCurrency("user1", "PTS", decimal.NewFromInt(150), "signup bonus"); err != nil {
+		t.Fatalf("DepositCurrency() error = %v", err)
+	}
+	if err := ws.DepositCurrency("user1", "PTS", decimal.RequireFromString("1.5"), "fractional"); err != ErrPrecisionExceeded {
+		t.Errorf("Expected ErrPrecisionExceeded, got %v", err)
+	}
+	if err := ws.DepositCurrency("user1", "XYZ", decimal.NewFromInt(1), "unknown"); err != ErrInvalidCurrency {
+		t.Errorf("Expected ErrInvalidCurrency, got %v", err)
+	}
+	if _, err := ws.QuoteConversion("user1", "PTS", "USD", decimal.NewFromInt(100)); err != ErrCurrencyNotConvertible {
+		t.Errorf("Expected ErrCurrencyNotConvertible, got %v", err)
+	}
+
+	// Non-transferable base currencies block transfers between users
+	ws.RegisterCurrency(Currency{Code: "GOLD", Precision: 0})
+	ws.wallets["user1"].Currency = "GOLD"
+	ws.wallets["user2"].Currency = "GOLD"
+	ws.DepositDecimal("user1", decimal.NewFromInt(10), "loot")
+	if err := ws.Transfer("user1", "user2", 5, "trade"); err != ErrCurrencyNotTransferable {
+		t.Errorf("Expected ErrCurrencyNotTransferable, got %v", err)
+	}
+}
+
+// TestWalletService_FormatAmount tests display formatting per currency
+func TestWalletService_FormatAmount(t *testing.T) {
+	ws := NewWalletService()
+	ws.RegisterCurrency(Currency{Code: "PTS", Precision: 0})
+
+	tests := []struct {
+		amount string
+		code   string
+		want   string
+	}{
+		{"12.5", "USD", "$12.50"},
+		{"-3", "EUR", "-€3.00"},
+		{"1234.56", "JPY", "¥1235"},
+		{"150", "PTS", "150 PTS"},
+	}
+	for _, tt := range tests {
+		got, err := ws.FormatAmount(decimal.RequireFromString(tt.amount), tt.code)
+		if err != nil || got != tt.want {
+			t.Errorf("FormatAmount(%s, %s) = %q, %v; want %q", tt.amount, tt.code, got, err, tt.want)
+		}
+	}
+
+	if _, err := ws.FormatAmount(decimal.NewFromInt(1), "XYZ"); err != ErrInvalidCurrency {
+		t.Errorf("Expected ErrInvalidCurrency, got %v", err)
+	}
+	if len(ws.ListCurrencies()) != len(defaultCurrencies)+1 {
+		t.Errorf("Expected %d currencies, got %d", len(defaultCurrencies)+1, len(ws.ListCurrencies()))
+	}
+}
@@ -0,0 +1,428 @@
+// internal/wallet/dataretention.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error definitions for data retention and legal holds
+var (
+	ErrInvalidRetentionRule = errors.New("retention rule needs a known kind of data and a positive age")
+	ErrLegalHoldTarget      = errors.New("legal hold must name exactly one user or case")
+	ErrLegalHoldRationale   = errors.New("legal hold needs an actor and a reason")
+	ErrLegalHoldNotFound    = errors.New("legal hold not found")
+)
+
+// DefaultDataRetentionInterval is how often the retention job runs when no interval is set
+const DefaultDataRetentionInterval = 24 * time.Hour
+
+// RetentionData names a kind of data retention rules purge
+type RetentionData string
+
+const (
+	// RetainTransactionPII: transaction descriptions, approval comments and the
+	// metadata keys a rule lists. Amounts, parties and timestamps stay in the ledger.
+	RetainTransactionPII RetentionData = "transaction_pii"
+	// RetainAuditLogs: impersonation and scheduled-job audit entries
+	RetainAuditLogs RetentionData = "audit_logs"
+)
+
+// RetentionRule purges one kind of data once it is older than After, e.g. transaction
+// PII after 7 years or audit logs after 10
+type RetentionRule struct {
+	Data         RetentionData
+	After        time.Duration
+	MetadataKeys []string // for RetainTransactionPII: metadata keys holding PII
+}
+
+// DataRetentionPolicy is the set of retention rules and how often they are applied
+type DataRetentionPolicy struct {
+	Rules    []RetentionRule
+	Interval time.Duration // DefaultDataRetentionInterval when zero
+}
+
+// LegalHold exempts a user's data, or a compliance case's, from retention rules until
+// it is released
+type LegalHold struct {
+	ID         string
+	UserID     string
+	CaseID     string
+	Actor      string
+	Reason     string
+	PlacedAt   int64
+	ReleasedBy string
+	ReleasedAt int64
+}
+
+// RetentionResult reports what one rule purged in a run. Exempt counts the items past
+// the rule's age kept because of a legal hold.
+type RetentionResult struct {
+	Rule   RetentionRule
+	Cutoff int64
+	Purged int
+	Exempt int
+	Error  string
+}
+
+// RetentionRun reports one application of the retention rules
+type RetentionRun struct {
+	ID        string
+	StartedAt int64
+	Results   []RetentionResult
+}
+
+// dataRetentionDesk holds the retention policy, legal holds and the reports of past runs
+type dataRetentionDesk struct {
+	mu      sync.Mutex
+	policy  DataRetentionPolicy
+	enabled bool
+	holds   map[string]*LegalHold
+	runs    []RetentionRun
+}
+
+// WithDataRetention applies policy's rules as a scheduled job once per interval. Rules
+// are checked when they run: an invalid one is reported in the run and purges nothing.
+func WithDataRetention(policy DataRetentionPolicy) Option {
+	return func(ws *WalletService) {
+		ws.dataRetention.policy = policy
+		ws.dataRetention.enabled = true
+	}
+}
+
+// PlaceLegalHold exempts hold.UserID's or hold.CaseID's data from retention rules.
+// Holding a case also keeps its evidence past the attachment retention.
+func (ws *WalletService) PlaceLegalHold(hold LegalHold) (*LegalHold, error) {
+	if (hold.UserID == "") == (hold.CaseID == "") {
+		return nil, ErrLegalHoldTarget
+	}
+	if strings.TrimSpace(hold.Actor) == "" || strings.TrimSpace(hold.Reason) == "" {
+		return nil, ErrLegalHoldRationale
+	}
+	if hold.UserID != "" && !ws.walletExists(hold.UserID) {
+		return nil, ErrUserNotFound
+	}
+	if hold.CaseID != "" {
+		if _, err := ws.GetCase(hold.CaseID); err != nil {
+			return nil, err
+		}
+	}
+
+	placed := &LegalHold{
+		ID: ws.newID("lhold"), UserID: hold.UserID, CaseID: hold.CaseID,
+		Actor: hold.Actor, Reason: hold.Reason, PlacedAt: ws.now().Unix(),
+	}
+	ws.dataRetention.mu.Lock()
+	if ws.dataRetention.holds == nil {
+		ws.dataRetention.holds = make(map[string]*LegalHold)
+	}
+	ws.dataRetention.holds[placed.ID] = placed
+	ws.dataRetention.mu.Unlock()
+
+	ws.metrics.IncCounter("legal_holds_placed_total", nil)
+	copied := *placed
+	return &copied, nil
+}
+
+// ReleaseLegalHold lifts a legal hold. A released case whose evidence was kept by the
+// hold has it deleted once the attachment retention has passed again.
+func (ws *WalletService) ReleaseLegalHold(holdID, actor string) error {
+	if strings.TrimSpace(actor) == "" {
+		return ErrLegalHoldRationale
+	}
+
+	ws.dataRetention.mu.Lock()
+	hold, exists := ws.dataRetention.holds[holdID]
+	if !exists || hold.ReleasedAt != 0 {
+		ws.dataRetention.mu.Unlock()
+		return ErrLegalHoldNotFound
+	}
+	hold.ReleasedBy, hold.ReleasedAt = actor, ws.now().Unix()
+	caseID := hold.CaseID
+	ws.dataRetention.mu.Unlock()
+
+	if caseID != "" && !ws.caseOnLegalHold(caseID) {
+		if c, err := ws.GetCase(caseID); err == nil && c.Status != CaseOpen && hasUnpurgedEvidence(c) {
+			ws.scheduleEvidencePurge(caseID)
+		}
+	}
+	return nil
+}
+
+// ListLegalHolds returns the legal holds in force, oldest first
+func (ws *WalletService) ListLegalHolds() []LegalHold {
+	ws.dataRetention.mu.Lock()
+	defer ws.dataRetention.mu.Unlock()
+
+	holds := []LegalHold{}
+	for _, h := range ws.dataRetention.holds {
+		if h.ReleasedAt == 0 {
+			holds = append(holds, *h)
+		}
+	}
+	sortLegalHolds(holds)
+	return holds
+}
+
+// RunDataRetention applies the retention rules now and returns what they purged. The
+// run is also kept for ListRetentionRuns.
+func (ws *WalletService) RunDataRetention() *RetentionRun {
+	ws.dataRetention.mu.Lock()
+	rules := append([]RetentionRule(nil), ws.dataRetention.policy.Rules...)
+	ws.dataRetention.mu.Unlock()
+
+	now := ws.now()
+	run := RetentionRun{ID: ws.newID("retention"), StartedAt: now.Unix()}
+	for _, rule := range rules {
+		result := RetentionResult{Rule: rule, Cutoff: now.Add(-rule.After).Unix()}
+		var err error
+		switch {
+		case rule.After <= 0:
+			err = ErrInvalidRetentionRule
+		case rule.Data == RetainTransactionPII:
+			result.Purged, result.Exempt, err = ws.purgeTransactionPII(result.Cutoff, rule.MetadataKeys)
+		case rule.Data == RetainAuditLogs:
+			result.Purged, result.Exempt = ws.purgeAuditLogs(result.Cutoff)
+		default:
+			err = ErrInvalidRetentionRule
+		}
+		if err != nil {
+			result.Error = err.Error()
+			ws.metrics.IncCounter("data_retention_failures_total", map[string]string{"data": string(rule.Data)})
+		}
+		ws.metrics.ObserveValue("data_retention_purged", float64(result.Purged), map[string]string{"data": string(rule.Data)})
+		run.Results = append(run.Results, result)
+	}
+
+	ws.dataRetention.mu.Lock()
+	ws.dataRetention.runs = append(ws.dataRetention.runs, run)
+	ws.dataRetention.mu.Unlock()
+	return &run
+}
+
+// ListRetentionRuns returns the reports of past retention runs, oldest first
+func (ws *WalletService) ListRetentionRuns() []RetentionRun {
+	ws.dataRetention.mu.Lock()
+	defer ws.dataRetention.mu.Unlock()
+	return append([]RetentionRun(nil), ws.dataRetention.runs...)
+}
+
+// startDataRetentionJob schedules the retention rules when WithDataRetention is set
+func (ws *WalletService) startDataRetentionJob() {
+	if !ws.dataRetention.enabled {
+		return
+	}
+	d := ws.dataRetention.policy.Interval
+	if d <= 0 {
+		d = DefaultDataRetentionInterval
+	}
+	ws.schedule("data_retention", "", ws.now().Add(d), Every(d), func(time.Time) error {
+		for _, result := range ws.RunDataRetention().Results {
+			if result.Error != "" {
+				return fmt.Errorf("%s: %s", result.Rule.Data, result.Error)
+			}
+		}
+		return nil
+	})
+}
+
+// ArchiveRedactor is implemented by archive stores that can purge PII from archived
+// transactions. Redact calls redact on each archived transaction older than before,
+// which reports whether it changed it, and returns how many changed. Archives without
+// it keep their PII and are left to their own retention.
+type ArchiveRedactor interface {
+	Redact(before int64, redact func(tx *Transaction) bool) (int, error)
+}
+
+// Redact purges PII from archived transactions, see ArchiveRedactor
+func (a *MemoryArchive) Redact(before int64, redact func(tx *Transaction) bool) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	n := 0
+	for _, tx := range a.txs {
+		if tx.Timestamp < before && redact(tx) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// purgeTransactionPII redacts the transactions logged before cutoff that no legal hold
+// covers, in the hot log and, when it supports it, the archive
+func (ws *WalletService) purgeTransactionPII(cutoff int64, metadataKeys []string) (purged, exempt int, err error) {
+	heldUsers, heldTxs := ws.legalHoldScope()
+	redact := func(tx *Transaction) bool {
+		if !hasPII(tx, metadataKeys) {
+			return false
+		}
+		if heldUsers[tx.FromUserID] || heldUsers[tx.ToUserID] || heldTxs[tx.ID] {
+			exempt++
+			return false
+		}
+		redactPII(tx, metadataKeys)
+		return true
+	}
+
+	ws.mu.Lock()
+	for _, tx := range ws.transactions {
+		if tx.Timestamp < cutoff && redact(tx) {
+			purged++
+		}
+	}
+	ws.mu.Unlock()
+
+	if archive, ok := ws.archive.(ArchiveRedactor); ok {
+		n, err := archive.Redact(cutoff, redact)
+		purged += n
+		if err != nil {
+			return purged, exempt, err
+		}
+	}
+	return purged, exempt, nil
+}
+
+// purgeAuditLogs deletes audit entries recorded before cutoff. Impersonation entries
+// about a user under legal hold are kept; job audit entries belong to no user or case
+// and go by age alone.
+func (ws *WalletService) purgeAuditLogs(cutoff int64) (purged, exempt int) {
+	heldUsers, _ := ws.legalHoldScope()
+
+	ws.impersonation.mu.Lock()
+	kept := ws.impersonation.audit[:0]
+	for _, e := range ws.impersonation.audit {
+		switch {
+		case e.Timestamp >= cutoff:
+			kept = append(kept, e)
+		case heldUsers[e.TargetUserID]:
+			kept = append(kept, e)
+			exempt++
+		default:
+			purged++
+		}
+	}
+	clear(ws.impersonation.audit[len(kept):])
+	ws.impersonation.audit = kept
+	ws.impersonation.mu.Unlock()
+
+	ws.scheduler.mu.Lock()
+	jobs := ws.scheduler.audit[:0]
+	for _, e := range ws.scheduler.audit {
+		if e.Timestamp >= cutoff {
+			jobs = append(jobs, e)
+		} else {
+			purged++
+		}
+	}
+	clear(ws.scheduler.audit[len(jobs):])
+	ws.scheduler.audit = jobs
+	ws.scheduler.mu.Unlock()
+
+	return purged, exempt
+}
+
+// legalHoldScope returns the users under legal hold and the transactions of the cases
+// under legal hold
+func (ws *WalletService) legalHoldScope() (users, txs map[string]bool) {
+	users, txs = make(map[string]bool), make(map[string]bool)
+	var caseIDs []string
+	ws.dataRetention.mu.Lock()
+	for _, h := range ws.dataRetention.holds {
+		if h.ReleasedAt != 0 {
+			continue
+		}
+		if h.UserID != "" {
+			users[h.UserID] = true
+		} else {
+			caseIDs = append(caseIDs, h.CaseID)
+		}
+	}
+	ws.dataRetention.mu.Unlock()
+
+	for _, id := range caseIDs {
+		if c, err := ws.GetCase(id); err == nil {
+			txs[c.TransactionID] = true
+			if c.ResolutionTransactionID != "" {
+				txs[c.ResolutionTransactionID] = true
+			}
+		}
+	}
+	return users, txs
+}
+
+// caseOnLegalHold reports whether a legal hold in force covers caseID
+func (ws *WalletService) caseOnLegalHold(caseID string) bool {
+	ws.dataRetention.mu.Lock()
+	defer ws.dataRetention.mu.Unlock()
+	for _, h := range ws.dataRetention.holds {
+		if h.CaseID == caseID && h.ReleasedAt == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPII reports whether tx still carries data a PII purge removes
+func hasPII(tx *Transaction, metadataKeys []string) bool {
+	if tx.Description != "" {
+		return true
+	}
+	for _, a := range tx.Approvals {
+		if a.Comment != "" {
+			return true
+		}
+	}
+	for _, k := range metadataKeys {
+		if _, exists := tx.Metadata[k]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPII clears tx's description, approval comments and the listed metadata keys.
+// Approvals and metadata are replaced rather than edited, as readers may share them.
+func redactPII(tx *Transaction, metadataKeys []string) {
+	tx.Description = ""
+	if len(tx.Approvals) > 0 {
+		approvals := append([]Approval(nil), tx.Approvals...)
+		for i := range approvals {
+			approvals[i].Comment = ""
+		}
+		tx.Approvals = approvals
+	}
+	if len(metadataKeys) > 0 && len(tx.Metadata) > 0 {
+		metadata := make(map[string]string, len(tx.Metadata))
+		for k, v := range tx.Metadata {
+			metadata[k] = v
+		}
+		for _, k := range metadataKeys {
+			delete(metadata, k)
+		}
+		tx.Metadata = metadata
+	}
+}
+
+// hasUnpurgedEvidence reports whether any of c's evidence blobs are still stored
+func hasUnpurgedEvidence(c *ComplianceCase) bool {
+	for _, e := range c.Evidence {
+		if e.PurgedAt == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sortLegalHolds orders holds by placement, then ID
+func sortLegalHolds(holds []LegalHold) {
+	sort.Slice(holds, func(i, j int) bool {
+		if holds[i].PlacedAt != holds[j].PlacedAt {
+			return holds[i].PlacedAt < holds[j].PlacedAt
+		}
+		return holds[i].ID < holds[j].ID
+	})
+}
@@ -0,0 +1,168 @@
+// internal/wallet/dataretention_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+)
+
+const retentionYear = 365 * 24 * time.Hour
+
+func TestDataRetention_PurgesWithLegalHolds(t *testing.T) {
+	ws, clock := newTestService(WithArchive(NewMemoryArchive()), WithDataRetention(DataRetentionPolicy{
+		Rules: []RetentionRule{
+			{Data: RetainTransactionPII, After: 7 * retentionYear, MetadataKeys: []string{"note"}},
+			{Data: RetainAuditLogs, After: 5 * retentionYear},
+		},
+	}))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 1000, "seed")
+	ws.RegisterHoldRule("large_transfer", holdOver(500))
+	ws.CreateUser("carol", "Carol", "carol@example.com")
+	ws.SetStaffRole("sam", StaffSupport)
+	ws.Transfer("alice", "bob", 100, "rent for flat 4")
+	ws.Transfer("alice", "carol", 600, "large")
+	c := ws.ListCases(CaseOpen)[0]
+	for _, target := range []string{"alice", "bob"} {
+		ws.StartImpersonation(ImpersonationRequest{StaffID: "sam", TargetUserID: target, Reason: ReasonCustomerRequest, Duration: time.Minute})
+	}
+	clock.Advance(time.Second)
+	if _, err := ws.ArchiveTransactionsBefore(clock.Now().Unix()); err != nil {
+		t.Fatalf("ArchiveTransactionsBefore() error = %v", err)
+	}
+
+	if _, err := ws.PlaceLegalHold(LegalHold{UserID: "bob", Actor: "legal", Reason: "litigation"}); err != nil {
+		t.Fatalf("PlaceLegalHold(user) error = %v", err)
+	}
+	if _, err := ws.PlaceLegalHold(LegalHold{CaseID: c.ID, Actor: "legal", Reason: "regulator request"}); err != nil {
+		t.Fatalf("PlaceLegalHold(case) error = %v", err)
+	}
+	clock.Advance(8 * retentionYear)
+	ws.Deposit("alice", 10, "recent")
+
+	run := ws.RunDataRetention()
+	tests := []struct {
+		data   RetentionData
+		purged int
+		exempt int
+	}{
+		// The seed deposit is purged; the transfer to bob and the held case transfer are kept
+		{RetainTransactionPII, 1, 2},
+		// Each session start is audited; bob's is kept
+		{RetainAuditLogs, 1, 1},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("run results = %+v", run.Results)
+	}
+	for i, tt := range tests {
+		got := run.Results[i]
+		if got.Rule.Data != tt.data || got.Purged != tt.purged || got.Exempt != tt.exempt || got.Error != "" {
+			t.Errorf("result %d = %+v, want %s purged %d exempt %d", i, got, tt.data, tt.purged, tt.exempt)
+		}
+	}
+	if got := ws.ListImpersonationAudit(ImpersonationAuditFilter{TargetUserID: "alice"}); len(got) != 0 {
+		t.Errorf("alice's audit entries = %d after purge, want 0", len(got))
+	}
+
+	descriptions := func() map[string]bool {
+		seen := make(map[string]bool)
+		history, _ := ws.GetTransactionHistory("alice")
+		for _, tx := range history {
+			seen[tx.Description] = true
+		}
+		return seen
+	}
+	got := descriptions()
+	for desc, want := range map[string]bool{"seed": false, "rent for flat 4": true, "large": true, "recent": true} {
+		if got[desc] != want {
+			t.Errorf("description %q kept = %v, want %v", desc, got[desc], want)
+		}
+	}
+
+	// Once bob's hold is lifted, his transfer goes in the next run
+	bobHold := ws.ListLegalHolds()[0]
+	if err := ws.ReleaseLegalHold(bobHold.ID, "legal"); err != nil {
+		t.Fatalf("ReleaseLegalHold() error = %v", err)
+	}
+	if got := ws.RunDataRetention().Results[0]; got.Purged != 1 || got.Exempt != 1 {
+		t.Errorf("second run = %+v, want 1 purged, 1 exempt", got)
+	}
+	if descriptions()["rent for flat 4"] {
+		t.Error("transfer description kept after its hold was released")
+	}
+	if got := len(ws.ListRetentionRuns()); got != 2 {
+		t.Errorf("ListRetentionRuns() = %d runs, want 2", got)
+	}
+}
+
+func TestLegalHolds(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 1000, "seed")
+	ws.RegisterHoldRule("large_transfer", holdOver(500))
+	ws.Transfer("alice", "bob", 600, "large")
+	c := ws.ListCases(CaseOpen)[0]
+	ws.AttachCaseEvidence(c.ID, CaseEvidence{Name: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF")})
+
+	tests := []struct {
+		name    string
+		hold    LegalHold
+		wantErr error
+	}{
+		{"no target", LegalHold{Actor: "legal", Reason: "litigation"}, ErrLegalHoldTarget},
+		{"two targets", LegalHold{UserID: "alice", CaseID: c.ID, Actor: "legal", Reason: "litigation"}, ErrLegalHoldTarget},
+		{"no reason", LegalHold{UserID: "alice", Actor: "legal"}, ErrLegalHoldRationale},
+		{"unknown user", LegalHold{UserID: "ghost", Actor: "legal", Reason: "litigation"}, ErrUserNotFound},
+		{"unknown case", LegalHold{CaseID: "case-x", Actor: "legal", Reason: "litigation"}, ErrCaseNotFound},
+		{"case", LegalHold{CaseID: c.ID, Actor: "legal", Reason: "litigation"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.PlaceLegalHold(tt.hold); err != tt.wantErr {
+				t.Errorf("PlaceLegalHold() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Evidence of a held case outlives the case until the hold is released
+	if _, err := ws.ResolveCase(c.ID, "reviewer1", true, "fine"); err != nil {
+		t.Fatalf("ResolveCase() error = %v", err)
+	}
+	c, _ = ws.GetCase(c.ID)
+	if _, err := ws.GetCaseEvidence(c.ID, c.Evidence[0].ID); err != nil {
+		t.Errorf("GetCaseEvidence() under hold error = %v", err)
+	}
+	hold := ws.ListLegalHolds()[0]
+	if err := ws.ReleaseLegalHold(hold.ID, "legal"); err != nil {
+		t.Fatalf("ReleaseLegalHold() error = %v", err)
+	}
+	if _, err := ws.GetCaseEvidence(c.ID, c.Evidence[0].ID); err != ErrAttachmentPurged {
+		t.Errorf("GetCaseEvidence() after release error = %v, want %v", err, ErrAttachmentPurged)
+	}
+	if err := ws.ReleaseLegalHold(hold.ID, "legal"); err != ErrLegalHoldNotFound {
+		t.Errorf("second ReleaseLegalHold() error = %v, want %v", err, ErrLegalHoldNotFound)
+	}
+	if got := ws.ListLegalHolds(); len(got) != 0 {
+		t.Errorf("ListLegalHolds() = %+v, want none", got)
+	}
+}
+
+func TestDataRetention_InvalidRule(t *testing.T) {
+	ws, clock := newTestService(WithDataRetention(DataRetentionPolicy{
+		Rules:    []RetentionRule{{Data: RetainAuditLogs}, {Data: "cookies", After: time.Hour}},
+		Interval: time.Hour,
+	}))
+
+	clock.Advance(time.Hour)
+	results := ws.RunDueJobs()
+	if len(results) != 1 || results[0].Kind != "data_retention" || results[0].Err == nil {
+		t.Fatalf("RunDueJobs() = %+v, want a failed data_retention run", results)
+	}
+	for _, r := range ws.ListRetentionRuns()[0].Results {
+		if r.Error != ErrInvalidRetentionRule.Error() {
+			t.Errorf("result for %q error = %q, want %q", r.Rule.Data, r.Error, ErrInvalidRetentionRule)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// internal/wallet/deadline.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout matches every TimeoutError with errors.Is
+var ErrTimeout = errors.New("operation timed out")
+
+// Layers a request can spend time in outside the service
+const (
+	LayerArchive = "archive"
+	LayerRail    = "rail"
+)
+
+// LayerBudget bounds the time one layer may take out of the caller's deadline
+type LayerBudget struct {
+	Max time.Duration // longest the layer may run; 0 leaves only the caller's deadline
+	// Reserve is kept back from the caller's deadline for the work that follows the
+	// layer, such as reversing a payout debit when the rail times out
+	Reserve time.Duration
+}
+
+// DefaultLayerBudgets apply to layers without a budget set by WithLayerBudget
+var DefaultLayerBudgets = map[string]LayerBudget{
+	LayerArchive: {Max: 2 * time.Second},
+	LayerRail:    {Max: 5 * time.Second, Reserve: 50 * time.Millisecond},
+}
+
+// TimeoutError reports a layer that ran out of its share of the deadline
+type TimeoutError struct {
+	Layer  string
+	Budget time.Duration // time the layer was given; zero when none was left
+	Err    error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Budget <= 0 {
+		return fmt.Sprintf("%s: no time left in the deadline budget", e.Layer)
+	}
+	return fmt.Sprintf("%s: timed out after %v: %v", e.Layer, e.Budget, e.Err)
+}
+
+// Unwrap returns the underlying context error
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Is matches ErrTimeout
+func (e *TimeoutError) Is(target error) bool { return target == ErrTimeout }
+
+// ArchiveStoreContext is an ArchiveStore that honours deadlines. Stores implementing it
+// are cancelled when their budget runs out; plain stores cannot be interrupted and are
+// only skipped when no time is left.
+type ArchiveStoreContext interface {
+	ArchiveStore
+	AppendContext(ctx context.Context, txs []*Transaction) error
+	IterateContext(ctx context.Context, userID string, opts IterateOptions) (TransactionIterator, error)
+}
+
+// PaymentRailContext is a PaymentRail that honours deadlines
+type PaymentRailContext interface {
+	PaymentRail
+	InitiateContext(ctx context.Context, req RailRequest) (string, error)
+}
+
+// WithLayerBudget sets the deadline budget for a layer
+func WithLayerBudget(layer string, budget LayerBudget) Option {
+	return func(ws *WalletService) {
+		if ws.budgets == nil {
+			ws.budgets = make(map[string]LayerBudget)
+		}
+		ws.budgets[layer] = budget
+	}
+}
+
+// layerBudget returns the budget configured for layer
+func (ws *WalletService) layerBudget(layer string) LayerBudget {
+	if b, ok := ws.budgets[layer]; ok {
+		return b
+	}
+	return DefaultLayerBudgets[layer]
+}
+
+// callLayer runs call with a context bounded by the layer's budget. Running out of
+// time, before or during the call, yields a *TimeoutError.
+func (ws *WalletService) callLayer(ctx context.Context, layer string, call func(ctx context.Context) error) error {
+	budget := ws.layerBudget(layer)
+	start := time.Now()
+
+	deadline, bounded := time.Time{}, false
+	if budget.Max > 0 {
+		deadline, bounded = start.Add(budget.Max), true
+	}
+	if parent, ok := ctx.Deadline(); ok {
+		if parent = parent.Add(-budget.Reserve); !bounded || parent.Before(deadline) {
+			deadline, bounded = parent, true
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return ws.layerTimeout(layer, 0, err)
+	}
+	if bounded && !deadline.After(start) {
+		return ws.layerTimeout(layer, 0, context.DeadlineExceeded)
+	}
+
+	layerCtx, cancel := ctx, context.CancelFunc(func() {})
+	if bounded {
+		layerCtx, cancel = context.WithDeadline(ctx, deadline)
+	}
+	defer cancel()
+
+	err := call(layerCtx)
+	ws.metrics.ObserveValue("layer_duration_seconds", time.Since(start).Seconds(), map[string]string{"layer": layer})
+	if err != nil && errors.Is(layerCtx.Err(), context.DeadlineExceeded) {
+		return ws.layerTimeout(layer, deadline.Sub(start), err)
+	}
+	return err
+}
+
+// layerTimeout builds the timeout error for layer and counts it
+func (ws *WalletService) layerTimeout(layer string, budget time.Duration, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	ws.metrics.IncCounter("layer_timeouts_total", map[string]string{"layer": layer})
+	return &TimeoutError{Layer: layer, Budget: max(budget, 0), Err: err}
+}
+
+// archiveAppend writes txs to the archive within the archive budget
+func (ws *WalletService) archiveAppend(ctx context.Context, txs []*Transaction) error {
+	return ws.callLayer(ctx, LayerArchive, func(ctx context.Context) error {
+		if store, ok := ws.archive.(ArchiveStoreContext); ok {
+			return store.AppendContext(ctx, txs)
+		}
+		return ws.archive.Append(txs)
+	})
+}
+
+// archiveIterate opens an archive iterator within the archive budget
+func (ws *WalletService) archiveIterate(ctx context.Context, userID string, opts IterateOptions) (TransactionIterator, error) {
+	var it TransactionIterator
+	err := ws.callLayer(ctx, LayerArchive, func(ctx context.Context) (err error) {
+		if store, ok := ws.archive.(ArchiveStoreContext); ok {
+			it, err = store.IterateContext(ctx, userID, opts)
+		} else {
+			it, err = ws.archive.Iterate(userID, opts)
+		}
+		return err
+	})
+	return it, err
+}
+
+// railInitiate hands req to the rail within the rail budget
+func (ws *WalletService) railInitiate(ctx context.Context, rail PaymentRail, req RailRequest) (string, error) {
+	var reference string
+	err := ws.callLayer(ctx, LayerRail, func(ctx context.Context) (err error) {
+		if r, ok := rail.(PaymentRailContext); ok {
+			reference, err = r.InitiateContext(ctx, req)
+		} else {
+			reference, err = rail.Initiate(req)
+		}
+		return err
+	})
+	return reference, err
+}
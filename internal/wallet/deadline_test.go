@@ -0,0 +1,115 @@
+// internal/wallet/deadline_test.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// slowArchive blocks every call until its context is done
+type slowArchive struct {
+	*MemoryArchive
+	calls int
+}
+
+func (a *slowArchive) AppendContext(ctx context.Context, txs []*Transaction) error {
+	a.calls++
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (a *slowArchive) IterateContext(ctx context.Context, userID string, opts IterateOptions) (TransactionIterator, error) {
+	a.calls++
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDeadline_LayerBudgets(t *testing.T) {
+	archive := &slowArchive{MemoryArchive: NewMemoryArchive()}
+	ws, clock := newTestService(WithArchive(archive),
+		WithLayerBudget(LayerArchive, LayerBudget{Max: 20 * time.Millisecond, Reserve: 40 * time.Millisecond}))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 10, "seed")
+	cutoff := clock.Now().Unix() + 1
+
+	withDeadline := func(d time.Duration) func() context.Context {
+		return func() context.Context {
+			ctx, cancel := context.WithTimeout(context.Background(), d)
+			t.Cleanup(cancel)
+			return ctx
+		}
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        func() context.Context
+		wantBudget time.Duration // upper bound on the reported budget
+		wantCalls  int
+	}{
+		{"layer max", context.Background, 20 * time.Millisecond, 1},
+		{"caller deadline minus reserve", withDeadline(50 * time.Millisecond), 10 * time.Millisecond, 1},
+		{"reserve exhausts the deadline", withDeadline(30 * time.Millisecond), 0, 0},
+	}
+	for _, tt := range tests {
+		archive.calls = 0
+		start := time.Now()
+		_, err := ws.ArchiveTransactionsBeforeContext(tt.ctx(), cutoff)
+
+		var timeout *TimeoutError
+		if !errors.As(err, &timeout) || !errors.Is(err, ErrTimeout) || timeout.Layer != LayerArchive {
+			t.Errorf("%s: error = %v, want an archive timeout", tt.name, err)
+			continue
+		}
+		if timeout.Budget > tt.wantBudget || time.Since(start) > tt.wantBudget+time.Second {
+			t.Errorf("%s: budget %v after %v, want at most %v", tt.name, timeout.Budget, time.Since(start), tt.wantBudget)
+		}
+		if archive.calls != tt.wantCalls {
+			t.Errorf("%s: archive called %d times, want %d", tt.name, archive.calls, tt.wantCalls)
+		}
+	}
+
+	if _, err := ws.ArchiveTransactionsBeforeContext(cancelled, cutoff); !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Errorf("cancelled context error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := ws.IterateTransactionsContext(context.Background(), "alice", IterateOptions{}); !errors.Is(err, ErrTimeout) {
+		t.Errorf("IterateTransactionsContext() error = %v, want a timeout", err)
+	}
+
+	// The hot log keeps everything the archive never accepted
+	ws.mu.RLock()
+	hot := len(ws.transactions)
+	ws.mu.RUnlock()
+	if hot != 1 {
+		t.Errorf("hot log has %d entries, want 1", hot)
+	}
+}
+
+func TestDeadline_RailReserve(t *testing.T) {
+	rail := &stubRail{}
+	ws := NewWalletService(WithPaymentRail(rail), WithLayerBudget(LayerRail, LayerBudget{Reserve: time.Hour}))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := ws.PayoutViaRailContext(ctx, "alice", "DE89-0001", decimal.NewFromInt(40)); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("PayoutViaRailContext() error = %v, want a timeout", err)
+	}
+	if len(rail.requests) != 0 {
+		t.Errorf("rail saw %d requests, want none", len(rail.requests))
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("balance = %s, want 100 after the payout was reversed", b)
+	}
+
+	// Without a caller deadline the reserve does not apply
+	if _, err := ws.PayoutViaRail("alice", "DE89-0001", decimal.NewFromInt(40)); err != nil {
+		t.Errorf("PayoutViaRail() error = %v", err)
+	}
+}
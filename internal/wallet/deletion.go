@@ -0,0 +1,228 @@
+// internal/wallet/deletion.go
+package wallet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// DeletionAction is what deleting a user would do with an affected item
+type DeletionAction string
+
+const (
+	DeletionSweep   DeletionAction = "sweep"   // balance paid out or swept to another wallet
+	DeletionCancel  DeletionAction = "cancel"  // cancelled or released on deletion
+	DeletionRevoke  DeletionAction = "revoke"  // mandate revoked
+	DeletionRemove  DeletionAction = "remove"  // membership or rule removed
+	DeletionProceed DeletionAction = "proceed" // continues without the user
+	DeletionBlocked DeletionAction = "blocked" // must be resolved before deleting
+)
+
+// DeletionImpactKind names what an affected item is
+type DeletionImpactKind string
+
+const (
+	ImpactBalance        DeletionImpactKind = "balance"
+	ImpactForeignBalance DeletionImpactKind = "foreign_balance"
+	ImpactMandate        DeletionImpactKind = "mandate"
+	ImpactCard           DeletionImpactKind = "card"
+	ImpactAutomationRule DeletionImpactKind = "automation_rule"
+	ImpactOrgMembership  DeletionImpactKind = "org_membership"
+	// Pending items keep their PendingKind as impact kind
+)
+
+// DeletionImpact is one thing deleting a user would touch
+type DeletionImpact struct {
+	Kind     DeletionImpactKind
+	ID       string
+	Amount   decimal.Decimal
+	Currency string
+	Action   DeletionAction
+	Detail   string
+}
+
+// DeletionPreview lists everything deleting a user would affect. Blockers are the
+// impacts support must resolve first; the user can be deleted once there are none.
+type DeletionPreview struct {
+	UserID      string
+	GeneratedAt int64
+	Impacts     []DeletionImpact
+	Blockers    []DeletionImpact
+}
+
+// Ready reports whether nothing blocks the deletion
+func (p *DeletionPreview) Ready() bool {
+	return len(p.Blockers) == 0
+}
+
+// pendingDeletionActions says what deletion does with each kind of pending item. Kinds
+// missing here settle on their own and block deletion until they have.
+var pendingDeletionActions = map[PendingKind]DeletionAction{
+	PendingCardHold:         DeletionCancel,
+	PendingScheduledPayment: DeletionCancel,
+	PendingPaymentRequest:   DeletionCancel,
+	PendingExpense:          DeletionProceed,
+}
+
+// PreviewUserDeletion lists what deleting or anonymizing userID would affect: the
+// balance to sweep, foreign balances, open holds and pending transfers, schedules and
+// gifts, mandates, cards, automation rules and organization memberships. It changes
+// nothing; the same rules decide what wallet closure cancels and what blocks it.
+func (ws *WalletService) PreviewUserDeletion(userID string) (*DeletionPreview, error) {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	preview := &DeletionPreview{UserID: userID, GeneratedAt: ws.now().Unix()}
+
+	wallet.mu.RLock()
+	if wallet.Balance.IsPositive() {
+		preview.add(DeletionImpact{
+			Kind: ImpactBalance, Amount: wallet.Balance, Currency: wallet.Currency,
+			Action: DeletionSweep, Detail: "paid out or swept before deletion",
+		})
+	}
+	for currency, amount := range wallet.Foreign {
+		if !amount.IsZero() {
+			preview.add(DeletionImpact{
+				Kind: ImpactForeignBalance, Amount: amount, Currency: currency,
+				Action: DeletionBlocked, Detail: "convert or withdraw first",
+			})
+		}
+	}
+	wallet.mu.RUnlock()
+
+	items, err := ws.GetPendingItems(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		preview.add(ws.pendingDeletionImpact(userID, item))
+	}
+
+	for _, m := range ws.ListMandates(userID) {
+		if m.Status != MandateRevoked {
+			preview.add(DeletionImpact{
+				Kind: ImpactMandate, ID: m.ID, Amount: m.MaxPerPeriod, Action: DeletionRevoke,
+				Detail: fmt.Sprintf("%s mandate from %s to %s", m.Status, m.PayerID, m.MerchantID),
+			})
+		}
+	}
+	for _, card := range ws.ListCards(userID) {
+		if card.Status != CardCancelled {
+			preview.add(DeletionImpact{Kind: ImpactCard, ID: card.ID, Action: DeletionCancel, Detail: card.Label})
+		}
+	}
+	for _, rule := range ws.ListAutomationRules(userID) {
+		preview.add(DeletionImpact{Kind: ImpactAutomationRule, ID: rule.ID, Action: DeletionRemove})
+	}
+	for _, impact := range ws.membershipDeletionImpacts(userID) {
+		preview.add(impact)
+	}
+	return preview, nil
+}
+
+// add records an impact, also as a blocker when it blocks deletion
+func (p *DeletionPreview) add(impact DeletionImpact) {
+	p.Impacts = append(p.Impacts, impact)
+	if impact.Action == DeletionBlocked {
+		p.Blockers = append(p.Blockers, impact)
+	}
+}
+
+// pendingDeletionImpact describes what deletion does with a pending item
+func (ws *WalletService) pendingDeletionImpact(userID string, item PendingItem) DeletionImpact {
+	impact := DeletionImpact{
+		Kind: DeletionImpactKind(item.Kind), ID: item.ID, Amount: item.Amount, Currency: item.Currency,
+		Action: DeletionBlocked, Detail: "must settle first",
+	}
+	if action, ok := pendingDeletionActions[item.Kind]; ok {
+		impact.Action, impact.Detail = action, item.Description
+	}
+
+	switch item.Kind {
+	case PendingGift:
+		// Only the sender can cancel, and only before delivery
+		if gift, err := ws.GetGift(item.ID); err == nil && gift.SenderID == userID && gift.Status == GiftScheduled {
+			impact.Action, impact.Detail = DeletionCancel, "scheduled gift"
+		}
+	case PendingApproval:
+		impact.Detail = "expense awaiting this user's review"
+		if ws.soleApprover(userID, item.ID) {
+			impact.Detail = "no other member can approve this step"
+		} else {
+			impact.Action = DeletionProceed
+		}
+	}
+	return impact
+}
+
+// soleApprover reports whether userID is the only member who can approve the current
+// step of an expense
+func (ws *WalletService) soleApprover(userID, expenseID string) bool {
+	ws.orgs.mu.RLock()
+	defer ws.orgs.mu.RUnlock()
+
+	e, ok := ws.orgs.expenses[expenseID]
+	if !ok || e.Status != ExpensePending {
+		return false
+	}
+	role := e.Chain[e.CurrentStep].Role
+	for memberID, r := range ws.orgs.orgs[e.OrgID].Members {
+		if r == role && memberID != userID && memberID != e.SubmitterID {
+			return false
+		}
+	}
+	return true
+}
+
+// membershipDeletionImpacts lists the user's organization memberships. Leaving is
+// blocked when the user is the only member holding a role the approval chain needs.
+func (ws *WalletService) membershipDeletionImpacts(userID string) []DeletionImpact {
+	ws.orgs.mu.RLock()
+	defer ws.orgs.mu.RUnlock()
+
+	var impacts []DeletionImpact
+	for _, org := range ws.orgs.orgs {
+		role, member := org.Members[userID]
+		if !member {
+			continue
+		}
+		impact := DeletionImpact{
+			Kind: ImpactOrgMembership, ID: org.ID, Action: DeletionRemove,
+			Detail: fmt.Sprintf("%s of %s", role, org.Name),
+		}
+		if chainNeedsRole(org.ApprovalChain, role) && !otherMemberHolds(org, userID, role) {
+			impact.Action = DeletionBlocked
+			impact.Detail = fmt.Sprintf("only %s of %s; assign another before deleting", role, org.Name)
+		}
+		impacts = append(impacts, impact)
+	}
+	sort.Slice(impacts, func(i, j int) bool { return impacts[i].ID < impacts[j].ID })
+	return impacts
+}
+
+// chainNeedsRole reports whether any approval step is satisfied by role
+func chainNeedsRole(chain []ApprovalStep, role OrgRole) bool {
+	for _, step := range chain {
+		if step.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// otherMemberHolds reports whether a member other than userID holds role
+func otherMemberHolds(org *Organization, userID string, role OrgRole) bool {
+	for memberID, r := range org.Members {
+		if memberID != userID && r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// internal/wallet/deletion_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPreviewUserDeletion(t *testing.T) {
+	ws, clock := newTestService()
+	for _, id := range []string{"alice", "bob", "shop"} {
+		ws.CreateUser(id, id, id+"@example.com")
+	}
+	ws.Deposit("alice", 100, "seed")
+	ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(5), "refund")
+
+	card, _ := ws.IssueCard("alice", "groceries", CardLimits{}, time.Hour)
+	auth, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(10), Merchant: "grocer"})
+	jobID, _ := ws.SchedulePayment("alice", "bob", decimal.NewFromInt(20), "rent", clock.Now().Add(24*time.Hour), nil)
+	mandate, _ := ws.CreateMandate("alice", "shop", decimal.NewFromInt(50), MandateMonthly)
+
+	ws.CreateOrganization("acme", "Acme", "acme@example.com")
+	ws.AddOrgMember("acme", "alice", OrgRoleFinance)
+	ws.AddOrgMember("acme", "bob", OrgRoleManager)
+
+	preview, err := ws.PreviewUserDeletion("alice")
+	if err != nil {
+		t.Fatalf("PreviewUserDeletion() error = %v", err)
+	}
+
+	want := []struct {
+		kind   DeletionImpactKind
+		id     string
+		action DeletionAction
+	}{
+		{ImpactBalance, "", DeletionSweep},
+		{ImpactForeignBalance, "", DeletionBlocked},
+		{DeletionImpactKind(PendingCardHold), auth.ID, DeletionCancel},
+		{DeletionImpactKind(PendingScheduledPayment), jobID, DeletionCancel},
+		{ImpactMandate, mandate.ID, DeletionRevoke},
+		{ImpactCard, card.ID, DeletionCancel},
+		{ImpactOrgMembership, "acme", DeletionBlocked},
+	}
+	if len(preview.Impacts) != len(want) {
+		t.Fatalf("Impacts = %+v, want %d entries", preview.Impacts, len(want))
+	}
+	for i, w := range want {
+		got := preview.Impacts[i]
+		if got.Kind != w.kind || got.ID != w.id || got.Action != w.action {
+			t.Errorf("Impacts[%d] = %s %s %s, want %s %s %s", i, got.Kind, got.ID, got.Action, w.kind, w.id, w.action)
+		}
+	}
+	if b := preview.Impacts[0].Amount; !b.Equal(decimal.NewFromInt(90)) {
+		t.Errorf("balance to sweep = %s, want 90 after the card hold", b)
+	}
+	if preview.Ready() || len(preview.Blockers) != 2 {
+		t.Errorf("Blockers = %+v, want the EUR balance and the finance role", preview.Blockers)
+	}
+
+	// The preview changed nothing
+	if got, _ := ws.GetMandate(mandate.ID); got.Status != MandateActive {
+		t.Errorf("mandate status = %s after preview", got.Status)
+	}
+
+	// Support resolves the membership blocker by assigning another finance member
+	ws.CreateUser("carol", "Carol", "carol@example.com")
+	ws.AddOrgMember("acme", "carol", OrgRoleFinance)
+	preview, _ = ws.PreviewUserDeletion("alice")
+	if len(preview.Blockers) != 1 || preview.Blockers[0].Kind != ImpactForeignBalance {
+		t.Errorf("Blockers after reassignment = %+v", preview.Blockers)
+	}
+
+	if _, err := ws.PreviewUserDeletion("nobody"); err != ErrUserNotFound {
+		t.Errorf("PreviewUserDeletion(unknown) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
@@ -0,0 +1,125 @@
+// internal/wallet/descriptors.go
+package wallet
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrInvalidDescriptor is returned for statement descriptors breaking the rules below
+var ErrInvalidDescriptor = errors.New("statement descriptor must be 5 to 22 letters, digits, spaces or . - & ' and contain a letter")
+
+// Length bounds of a statement descriptor, after spaces are collapsed
+const (
+	MinDescriptorLength = 5
+	MaxDescriptorLength = 22
+)
+
+// DescriptorChange is one change to a wallet's statement descriptor
+type DescriptorChange struct {
+	Descriptor string // empty when the descriptor was cleared
+	Previous   string
+	ChangedBy  string
+	ChangedAt  int64
+}
+
+// descriptorBook holds statement descriptors and their change history
+type descriptorBook struct {
+	mu      sync.RWMutex
+	current map[string]string
+	history map[string][]DescriptorChange
+}
+
+// SetStatementDescriptor sets the short name userID's counterparties see for it in
+// their history, statements and receipts instead of its user ID or name. actorID is
+// recorded in the change history. An empty descriptor clears it.
+func (ws *WalletService) SetStatementDescriptor(userID, descriptor, actorID string) error {
+	descriptor = strings.Join(strings.Fields(descriptor), " ")
+	if descriptor != "" && !validDescriptor(descriptor) {
+		return ErrInvalidDescriptor
+	}
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	ws.descriptors.mu.Lock()
+	defer ws.descriptors.mu.Unlock()
+	previous := ws.descriptors.current[userID]
+	if previous == descriptor {
+		return nil
+	}
+	if ws.descriptors.current == nil {
+		ws.descriptors.current = make(map[string]string)
+		ws.descriptors.history = make(map[string][]DescriptorChange)
+	}
+	if descriptor == "" {
+		delete(ws.descriptors.current, userID)
+	} else {
+		ws.descriptors.current[userID] = descriptor
+	}
+	ws.descriptors.history[userID] = append(ws.descriptors.history[userID], DescriptorChange{
+		Descriptor: descriptor,
+		Previous:   previous,
+		ChangedBy:  actorID,
+		ChangedAt:  ws.now().Unix(),
+	})
+	return nil
+}
+
+// StatementDescriptor returns userID's statement descriptor, or "" when it has none
+func (ws *WalletService) StatementDescriptor(userID string) string {
+	ws.descriptors.mu.RLock()
+	defer ws.descriptors.mu.RUnlock()
+	return ws.descriptors.current[userID]
+}
+
+// StatementDescriptorHistory returns the changes to userID's statement descriptor,
+// oldest first
+func (ws *WalletService) StatementDescriptorHistory(userID string) ([]DescriptorChange, error) {
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+	ws.descriptors.mu.RLock()
+	defer ws.descriptors.mu.RUnlock()
+	return slices.Clone(ws.descriptors.history[userID]), nil
+}
+
+// CounterpartyLabel returns how tx's other party is shown in viewerID's history: its
+// statement descriptor when it has one, otherwise its ID. It is empty for entries with
+// no other party, such as deposits.
+func (ws *WalletService) CounterpartyLabel(viewerID string, tx *Transaction) string {
+	counterparty := tx.FromUserID
+	if counterparty == viewerID {
+		counterparty = tx.ToUserID
+	}
+	if counterparty == viewerID {
+		return ""
+	}
+	if descriptor := ws.StatementDescriptor(counterparty); descriptor != "" {
+		return descriptor
+	}
+	return counterparty
+}
+
+// validDescriptor reports whether d, with spaces collapsed, follows the descriptor rules
+func validDescriptor(d string) bool {
+	if len(d) < MinDescriptorLength || len(d) > MaxDescriptorLength {
+		return false
+	}
+	hasLetter := false
+	for _, r := range d {
+		switch {
+		case r > unicode.MaxASCII:
+			return false
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r) || strings.ContainsRune(" .-&'", r):
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
@@ -0,0 +1,87 @@
+// internal/wallet/descriptors_test.go
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetStatementDescriptor_Validation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("shop", "Corner Shop Ltd", "shop@example.com")
+
+	tests := []struct {
+		name       string
+		userID     string
+		descriptor string
+		want       string
+		wantErr    error
+	}{
+		{"plain", "shop", "CORNER SHOP", "CORNER SHOP", nil},
+		{"spaces collapsed", "shop", "  Corner   Shop  & Co. ", "Corner Shop & Co.", nil},
+		{"too short", "shop", "Shop", "", ErrInvalidDescriptor},
+		{"too long", "shop", "The Corner Shop On Main Street", "", ErrInvalidDescriptor},
+		{"digits only", "shop", "123456", "", ErrInvalidDescriptor},
+		{"symbols", "shop", "SHOP<script>", "", ErrInvalidDescriptor},
+		{"non-ASCII", "shop", "Café Corner", "", ErrInvalidDescriptor},
+		{"unknown user", "ghost", "GHOST SHOP", "", ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ws.SetStatementDescriptor(tt.userID, tt.descriptor, "shop")
+			if err != tt.wantErr {
+				t.Fatalf("SetStatementDescriptor() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && ws.StatementDescriptor(tt.userID) != tt.want {
+				t.Errorf("StatementDescriptor() = %q, want %q", ws.StatementDescriptor(tt.userID), tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementDescriptor_ShownToCounterparties(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("shop", "Corner Shop Ltd", "shop@example.com")
+	ws.Deposit("alice", 50, "seed")
+
+	ws.SetStatementDescriptor("shop", "CORNER SHOP", "ops")
+	ws.Transfer("alice", "shop", 12, "groceries")
+	history, _ := ws.GetTransactionHistory("alice")
+	if got := ws.CounterpartyLabel("alice", history[1]); got != "CORNER SHOP" {
+		t.Errorf("CounterpartyLabel(alice) = %q, want the descriptor", got)
+	}
+	if got := ws.CounterpartyLabel("alice", history[0]); got != "" {
+		t.Errorf("CounterpartyLabel() of a deposit = %q, want none", got)
+	}
+	if got := ws.CounterpartyLabel("shop", history[1]); got != "alice" {
+		t.Errorf("CounterpartyLabel(shop) = %q, want alice's ID", got)
+	}
+
+	var statement bytes.Buffer
+	ws.ExportTransactionHistory("alice", &statement)
+	if !strings.Contains(statement.String(), "groceries,transfer,CORNER SHOP") {
+		t.Errorf("statement lacks the descriptor:\n%s", statement.String())
+	}
+
+	// Changes are kept with who made them, repeats are not; clearing falls back to the
+	// user ID
+	clock.Advance(time.Minute)
+	ws.SetStatementDescriptor("shop", "CORNER SHOP", "ops")
+	ws.SetStatementDescriptor("shop", "", "shop")
+	changes, err := ws.StatementDescriptorHistory("shop")
+	if err != nil || len(changes) != 2 {
+		t.Fatalf("StatementDescriptorHistory() = %+v, %v, want two changes", changes, err)
+	}
+	if c := changes[1]; c.Descriptor != "" || c.Previous != "CORNER SHOP" || c.ChangedBy != "shop" || c.ChangedAt <= changes[0].ChangedAt {
+		t.Errorf("second change = %+v", c)
+	}
+	if got := ws.CounterpartyLabel("alice", history[1]); got != "shop" {
+		t.Errorf("CounterpartyLabel() after clearing = %q, want shop", got)
+	}
+	if _, err := ws.StatementDescriptorHistory("ghost"); err != ErrUserNotFound {
+		t.Errorf("StatementDescriptorHistory(ghost) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
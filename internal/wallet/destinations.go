@@ -0,0 +1,230 @@
+// internal/wallet/destinations.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for withdrawal destinations
+var (
+	ErrDestinationNotFound    = errors.New("withdrawal destination not found")
+	ErrDestinationExists      = errors.New("withdrawal destination already registered")
+	ErrDestinationCoolingOff  = errors.New("withdrawal destination is still in its cooling-off period")
+	ErrDestinationNotVerified = errors.New("withdrawal destination has not been verified")
+	ErrDestinationRequired    = errors.New("withdrawals must go to a registered destination")
+	ErrInvalidDestination     = errors.New("invalid withdrawal destination")
+)
+
+// DestinationKind classifies where withdrawn funds are sent
+type DestinationKind string
+
+const (
+	DestinationBank   DestinationKind = "bank"
+	DestinationCrypto DestinationKind = "crypto"
+)
+
+// WithdrawalDestination is an external account a user may withdraw to
+type WithdrawalDestination struct {
+	ID        string
+	UserID    string
+	Kind      DestinationKind
+	Reference string // bank account reference or crypto address
+	Label     string
+	Verified  bool
+	AddedAt   int64
+	ActiveAt  int64 // first moment withdrawals may use the destination
+}
+
+// WhitelistConfig controls enforcement of withdrawal destinations
+type WhitelistConfig struct {
+	// CoolingOff delays first use of a newly added destination
+	CoolingOff time.Duration
+	// RequireVerified rejects destinations until VerifyWithdrawalDestination is called
+	RequireVerified bool
+}
+
+// destinationBook holds registered destinations per user
+type destinationBook struct {
+	mu       sync.RWMutex
+	enforced bool
+	config   WhitelistConfig
+	byUser   map[string]map[string]*WithdrawalDestination // user -> destination ID -> destination
+}
+
+// WithWithdrawalWhitelist requires every withdrawal to name a registered destination
+// that satisfies cfg. Plain Withdraw calls are then rejected.
+func WithWithdrawalWhitelist(cfg WhitelistConfig) Option {
+	return func(ws *WalletService) {
+		ws.destinations.enforced = true
+		ws.destinations.config = cfg
+	}
+}
+
+// AddWithdrawalDestination registers an external account for userID
+func (ws *WalletService) AddWithdrawalDestination(userID string, kind DestinationKind, reference, label string) (*WithdrawalDestination, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" || (kind != DestinationBank && kind != DestinationCrypto) {
+		return nil, ErrInvalidDestination
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.destinations.mu.Lock()
+	defer ws.destinations.mu.Unlock()
+
+	for _, d := range ws.destinations.byUser[userID] {
+		if d.Kind == kind && d.Reference == reference {
+			return nil, ErrDestinationExists
+		}
+	}
+
+	now := ws.now()
+	dest := &WithdrawalDestination{
+		ID:        ws.newID("dest"),
+		UserID:    userID,
+		Kind:      kind,
+		Reference: reference,
+		Label:     label,
+		AddedAt:   now.Unix(),
+		ActiveAt:  now.Add(ws.destinations.config.CoolingOff).Unix(),
+	}
+
+	if ws.destinations.byUser == nil {
+		ws.destinations.byUser = make(map[string]map[string]*WithdrawalDestination)
+	}
+	if ws.destinations.byUser[userID] == nil {
+		ws.destinations.byUser[userID] = make(map[string]*WithdrawalDestination)
+	}
+	ws.destinations.byUser[userID][dest.ID] = dest
+
+	copied := *dest
+	return &copied, nil
+}
+
+// RemoveWithdrawalDestination deletes a registered destination
+func (ws *WalletService) RemoveWithdrawalDestination(userID, destinationID string) error {
+	ws.destinations.mu.Lock()
+	defer ws.destinations.mu.Unlock()
+
+	if _, exists := ws.destinations.byUser[userID][destinationID]; !exists {
+		return ErrDestinationNotFound
+	}
+	delete(ws.destinations.byUser[userID], destinationID)
+	return nil
+}
+
+// VerifyWithdrawalDestination marks a destination as verified, e.g. after a micro-deposit
+// or address-ownership check performed by the embedding application
+func (ws *WalletService) VerifyWithdrawalDestination(userID, destinationID string) error {
+	ws.destinations.mu.Lock()
+	defer ws.destinations.mu.Unlock()
+
+	dest, exists := ws.destinations.byUser[userID][destinationID]
+	if !exists {
+		return ErrDestinationNotFound
+	}
+	dest.Verified = true
+	return nil
+}
+
+// ListWithdrawalDestinations returns a user's destinations, oldest first
+func (ws *WalletService) ListWithdrawalDestinations(userID string) []WithdrawalDestination {
+	ws.destinations.mu.RLock()
+	defer ws.destinations.mu.RUnlock()
+
+	list := make([]WithdrawalDestination, 0, len(ws.destinations.byUser[userID]))
+	for _, d := range ws.destinations.byUser[userID] {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].AddedAt != list[j].AddedAt {
+			return list[i].AddedAt < list[j].AddedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// WithdrawTo withdraws amount from userID's wallet to one of their registered destinations
+func (ws *WalletService) WithdrawTo(userID, destinationID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.WithdrawToContext(context.Background(), userID, destinationID, amount, description)
+}
+
+// WithdrawToContext is WithdrawTo on behalf of a caller's ctx
+func (ws *WalletService) WithdrawToContext(ctx context.Context, userID, destinationID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ws.withdrawTo(ctx, userID, destinationID, amount, description, nil)
+}
+
+// withdrawTo is WithdrawTo recording extra metadata on the withdrawal
+func (ws *WalletService) withdrawTo(ctx context.Context, userID, destinationID string, amount decimal.Decimal, description string, metadata map[string]string) (*Transaction, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	dest, err := ws.usableDestination(userID, destinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Transaction{
+		FromUserID:  userID,
+		ToUserID:    userID,
+		Amount:      amount,
+		Type:        TransactionWithdraw,
+		Description: description,
+		Metadata: map[string]string{
+			"destination_id":   dest.ID,
+			"destination_kind": string(dest.Kind),
+			"destination_ref":  dest.Reference,
+		},
+	}
+	for k, v := range metadata {
+		tx.Metadata[k] = v
+	}
+	if err := ws.postDebit(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// usableDestination returns the destination if it is registered, past its cooling-off
+// period and verified when verification is required
+func (ws *WalletService) usableDestination(userID, destinationID string) (WithdrawalDestination, error) {
+	ws.destinations.mu.RLock()
+	defer ws.destinations.mu.RUnlock()
+
+	dest, exists := ws.destinations.byUser[userID][destinationID]
+	if !exists {
+		return WithdrawalDestination{}, ErrDestinationNotFound
+	}
+	if ws.now().Unix() < dest.ActiveAt {
+		return WithdrawalDestination{}, ErrDestinationCoolingOff
+	}
+	if ws.destinations.config.RequireVerified && !dest.Verified {
+		return WithdrawalDestination{}, ErrDestinationNotVerified
+	}
+	return *dest, nil
+}
+
+// whitelistEnforced reports whether withdrawals must name a destination
+func (ws *WalletService) whitelistEnforced() bool {
+	ws.destinations.mu.RLock()
+	defer ws.destinations.mu.RUnlock()
+	return ws.destinations.enforced
+}
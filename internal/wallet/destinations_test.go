@@ -0,0 +1,94 @@
+// internal/wallet/destinations_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawTo_Whitelist(t *testing.T) {
+	ws, clock := newTestService(
+		WithWithdrawalWhitelist(WhitelistConfig{CoolingOff: 24 * time.Hour, RequireVerified: true}),
+	)
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 500, "seed")
+
+	if err := ws.Withdraw("alice", 10, "cash"); err != ErrDestinationRequired {
+		t.Fatalf("Withdraw() error = %v, want %v", err, ErrDestinationRequired)
+	}
+
+	dest, err := ws.AddWithdrawalDestination("alice", DestinationBank, " GB29NWBK60161331926819 ", "Main account")
+	if err != nil {
+		t.Fatalf("AddWithdrawalDestination() error = %v", err)
+	}
+	if _, err := ws.AddWithdrawalDestination("alice", DestinationBank, "GB29NWBK60161331926819", "dup"); err != ErrDestinationExists {
+		t.Errorf("duplicate AddWithdrawalDestination() error = %v, want %v", err, ErrDestinationExists)
+	}
+
+	amount := decimal.NewFromInt(100)
+	steps := []struct {
+		name    string
+		before  func()
+		destID  string
+		wantErr error
+	}{
+		{"unknown destination", nil, "dest_missing", ErrDestinationNotFound},
+		{"cooling off", nil, dest.ID, ErrDestinationCoolingOff},
+		{"unverified", func() { clock.Advance(24 * time.Hour) }, dest.ID, ErrDestinationNotVerified},
+		{"allowed", func() { ws.VerifyWithdrawalDestination("alice", dest.ID) }, dest.ID, nil},
+	}
+
+	for _, step := range steps {
+		if step.before != nil {
+			step.before()
+		}
+		tx, err := ws.WithdrawTo("alice", step.destID, amount, "payout")
+		if err != step.wantErr {
+			t.Fatalf("%s: WithdrawTo() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err == nil && tx.Metadata["destination_ref"] != "GB29NWBK60161331926819" {
+			t.Errorf("%s: metadata = %v", step.name, tx.Metadata)
+		}
+	}
+
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(400)) {
+		t.Errorf("balance = %s, want 400", b)
+	}
+
+	if err := ws.RemoveWithdrawalDestination("alice", dest.ID); err != nil {
+		t.Fatalf("RemoveWithdrawalDestination() error = %v", err)
+	}
+	if _, err := ws.WithdrawTo("alice", dest.ID, amount, "payout"); err != ErrDestinationNotFound {
+		t.Errorf("WithdrawTo() after removal error = %v, want %v", err, ErrDestinationNotFound)
+	}
+	if list := ws.ListWithdrawalDestinations("alice"); len(list) != 0 {
+		t.Errorf("destinations = %d, want 0", len(list))
+	}
+}
+
+func TestAddWithdrawalDestination_Validation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	tests := []struct {
+		name    string
+		userID  string
+		kind    DestinationKind
+		ref     string
+		wantErr error
+	}{
+		{"empty reference", "alice", DestinationCrypto, "  ", ErrInvalidDestination},
+		{"unknown kind", "alice", "paypal", "x@example.com", ErrInvalidDestination},
+		{"unknown user", "ghost", DestinationCrypto, "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh", ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.AddWithdrawalDestination(tt.userID, tt.kind, tt.ref, ""); err != tt.wantErr {
+				t.Errorf("AddWithdrawalDestination() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
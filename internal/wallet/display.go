@@ -0,0 +1,151 @@
+// internal/wallet/display.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/moneymath"
+)
+
+// ErrInvalidDisplayPolicy is returned for display policies with negative places or an
+// unknown trailing zero or rounding setting
+var ErrInvalidDisplayPolicy = errors.New("invalid display policy")
+
+// TrailingZeros says what a display policy does with zeros ending the decimals
+type TrailingZeros string
+
+const (
+	// KeepTrailingZeros always shows Places decimals: "12.50", "12.00"
+	KeepTrailingZeros TrailingZeros = ""
+	// TrimTrailingZeros drops every trailing zero: "12.5", "12"
+	TrimTrailingZeros TrailingZeros = "trim"
+	// TrimWholeZeros drops the decimals of whole amounts only: "12.50", "12"
+	TrimWholeZeros TrailingZeros = "trim_whole"
+)
+
+// DisplayPolicy says how amounts in a currency are rendered for people. It only shapes
+// display strings; stored and exchanged amounts keep their exact value.
+type DisplayPolicy struct {
+	Places        int32 // decimals shown; amounts with more are rounded
+	TrailingZeros TrailingZeros
+	Rounding      moneymath.Rounding
+}
+
+// Format renders amount per the policy
+func (p DisplayPolicy) Format(amount decimal.Decimal) string {
+	s := moneymath.Round(amount, p.Places, p.Rounding).StringFixed(p.Places)
+	whole, decimals, found := strings.Cut(s, ".")
+	if !found {
+		return s
+	}
+	switch p.TrailingZeros {
+	case TrimTrailingZeros:
+		decimals = strings.TrimRight(decimals, "0")
+	case TrimWholeZeros:
+		if strings.Trim(decimals, "0") == "" {
+			decimals = ""
+		}
+	}
+	if decimals == "" {
+		return whole
+	}
+	return whole + "." + decimals
+}
+
+// valid reports whether the policy's settings are known
+func (p DisplayPolicy) valid() bool {
+	switch p.TrailingZeros {
+	case KeepTrailingZeros, TrimTrailingZeros, TrimWholeZeros:
+	default:
+		return false
+	}
+	return p.Places >= 0 && p.Rounding >= moneymath.HalfUp && p.Rounding <= moneymath.Up
+}
+
+// displayBook holds display policies per tenant and currency
+type displayBook struct {
+	mu       sync.Mutex
+	policies map[string]map[string]DisplayPolicy // tenant -> currency -> policy; "" applies to every tenant
+}
+
+// tenantKey is the context key of the tenant a request is served for
+type tenantKey struct{}
+
+// ContextWithTenant returns a copy of ctx carrying the tenant a request is served for.
+// The wallet service has no tenants of its own; APIs use it to pick display policies.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant carried by ctx, if any
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// SetDisplayPolicy sets how tenant's amounts in currency are displayed, or every
+// tenant's without their own when tenant is empty
+func (ws *WalletService) SetDisplayPolicy(tenant, currency string, policy DisplayPolicy) error {
+	if !policy.valid() {
+		return ErrInvalidDisplayPolicy
+	}
+	c, err := ws.GetCurrency(currency)
+	if err != nil {
+		return err
+	}
+
+	ws.display.mu.Lock()
+	defer ws.display.mu.Unlock()
+	if ws.display.policies == nil {
+		ws.display.policies = make(map[string]map[string]DisplayPolicy)
+	}
+	if ws.display.policies[tenant] == nil {
+		ws.display.policies[tenant] = make(map[string]DisplayPolicy)
+	}
+	ws.display.policies[tenant][c.Code] = policy
+	return nil
+}
+
+// ClearDisplayPolicy removes tenant's own display policy for currency
+func (ws *WalletService) ClearDisplayPolicy(tenant, currency string) {
+	ws.display.mu.Lock()
+	defer ws.display.mu.Unlock()
+	delete(ws.display.policies[tenant], normalizeCurrency(currency))
+}
+
+// GetDisplayPolicy returns the policy displaying tenant's amounts in currency: the
+// tenant's own, else the one set for every tenant, else the currency's precision with
+// trailing zeros kept
+func (ws *WalletService) GetDisplayPolicy(tenant, currency string) (DisplayPolicy, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	c, err := ws.GetCurrency(currency)
+	if err != nil {
+		return DisplayPolicy{}, err
+	}
+
+	ws.display.mu.Lock()
+	defer ws.display.mu.Unlock()
+	if p, exists := ws.display.policies[tenant][c.Code]; exists {
+		return p, nil
+	}
+	if p, exists := ws.display.policies[""][c.Code]; exists {
+		return p, nil
+	}
+	return DisplayPolicy{Places: c.Precision}, nil
+}
+
+// DisplayAmount renders amount in currency for tenant. Amounts in currencies the
+// registry does not know are rendered exactly.
+func (ws *WalletService) DisplayAmount(tenant string, amount decimal.Decimal, currency string) string {
+	p, err := ws.GetDisplayPolicy(tenant, currency)
+	if err != nil {
+		return amount.String()
+	}
+	return p.Format(amount)
+}
@@ -0,0 +1,96 @@
+// internal/wallet/display_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/moneymath"
+)
+
+func TestDisplayPolicy_Format(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DisplayPolicy
+		amount string
+		want   string
+	}{
+		{"pads to places", DisplayPolicy{Places: 2}, "12.5", "12.50"},
+		{"keeps whole zeros", DisplayPolicy{Places: 2}, "12", "12.00"},
+		{"rounds half up", DisplayPolicy{Places: 2}, "0.125", "0.13"},
+		{"rounds half even", DisplayPolicy{Places: 2, Rounding: moneymath.HalfEven}, "0.125", "0.12"},
+		{"no places", DisplayPolicy{Places: 0}, "1234.5", "1235"},
+		{"trims", DisplayPolicy{Places: 4, TrailingZeros: TrimTrailingZeros}, "12.5", "12.5"},
+		{"trims to whole", DisplayPolicy{Places: 4, TrailingZeros: TrimTrailingZeros}, "12.00001", "12"},
+		{"trim whole keeps cents", DisplayPolicy{Places: 2, TrailingZeros: TrimWholeZeros}, "12.5", "12.50"},
+		{"trim whole drops zeros", DisplayPolicy{Places: 2, TrailingZeros: TrimWholeZeros}, "12", "12"},
+		{"negative", DisplayPolicy{Places: 2, TrailingZeros: TrimTrailingZeros}, "-3.10", "-3.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Format(decimal.RequireFromString(tt.amount)); got != tt.want {
+				t.Errorf("Format(%s) = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayPolicies(t *testing.T) {
+	ws := NewWalletService()
+	ws.RegisterCurrency(Currency{Code: "PTS", Name: "Points", Precision: 4})
+
+	if err := ws.SetDisplayPolicy("", "PTS", DisplayPolicy{Places: 2, TrailingZeros: TrimTrailingZeros}); err != nil {
+		t.Fatalf("SetDisplayPolicy(every tenant) error = %v", err)
+	}
+	if err := ws.SetDisplayPolicy("acme", "usd", DisplayPolicy{Places: 2, TrailingZeros: TrimWholeZeros}); err != nil {
+		t.Fatalf("SetDisplayPolicy(acme) error = %v", err)
+	}
+
+	amount := decimal.RequireFromString("1500")
+	tests := []struct {
+		name     string
+		tenant   string
+		currency string
+		want     string
+	}{
+		{"currency precision", "", "USD", "1500.00"},
+		{"tenant policy", "acme", "USD", "1500"},
+		{"other tenant", "globex", "USD", "1500.00"},
+		{"policy for every tenant", "acme", "PTS", "1500"},
+		{"default currency", "", "", "1500.00"},
+		{"zero precision", "", "JPY", "1500"},
+		{"unknown currency", "acme", "XYZ", "1500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ws.DisplayAmount(tt.tenant, amount, tt.currency); got != tt.want {
+				t.Errorf("DisplayAmount() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	ws.ClearDisplayPolicy("acme", "USD")
+	if got := ws.DisplayAmount("acme", amount, "USD"); got != "1500.00" {
+		t.Errorf("DisplayAmount() after clearing = %q, want 1500.00", got)
+	}
+
+	invalid := []struct {
+		name     string
+		currency string
+		policy   DisplayPolicy
+		wantErr  error
+	}{
+		{"negative places", "USD", DisplayPolicy{Places: -1}, ErrInvalidDisplayPolicy},
+		{"unknown trailing zeros", "USD", DisplayPolicy{TrailingZeros: "pad"}, ErrInvalidDisplayPolicy},
+		{"unknown rounding", "USD", DisplayPolicy{Rounding: moneymath.Up + 1}, ErrInvalidDisplayPolicy},
+		{"unknown currency", "XYZ", DisplayPolicy{Places: 2}, ErrInvalidCurrency},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.SetDisplayPolicy("acme", tt.currency, tt.policy); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetDisplayPolicy() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
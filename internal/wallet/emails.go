@@ -0,0 +1,203 @@
+// internal/wallet/emails.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Error definitions for email uniqueness
+var (
+	ErrEmailTaken    = errors.New("email already registered to another user")
+	ErrEmailConflict = errors.New("email is shared by several users pending migration")
+)
+
+// emailIndex maps normalised emails to the owning user ID, guarded by ws.mu. Data
+// restored from before emails were unique may share an email between users; those
+// emails are tracked in conflicts until all but one user has changed address.
+type emailIndex struct {
+	byEmail   map[string]string
+	conflicts map[string][]string
+}
+
+// EmailConflict is an email found on more than one user during migration
+type EmailConflict struct {
+	Email   string
+	UserIDs []string // sorted; Kept is the first
+	Kept    string   // user the email is provisionally indexed to
+}
+
+// EmailMigrationReport summarises an email index rebuild
+type EmailMigrationReport struct {
+	Indexed   int // distinct emails indexed
+	Conflicts []EmailConflict
+}
+
+// normalizeEmail returns the form emails are compared in
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// claim indexes email for userID, failing if another user holds it. Empty emails are
+// not indexed. Callers hold ws.mu for writing.
+func (idx *emailIndex) claim(email, userID string) error {
+	key := normalizeEmail(email)
+	if key == "" {
+		return nil
+	}
+	if idx.byEmail == nil {
+		idx.byEmail = make(map[string]string)
+	}
+	if owner, taken := idx.byEmail[key]; taken && owner != userID {
+		return ErrEmailTaken
+	}
+	idx.byEmail[key] = userID
+	return nil
+}
+
+// release removes userID's hold on email; when that leaves a single user in a
+// conflict, the conflict is resolved in their favour. Callers hold ws.mu for writing.
+func (idx *emailIndex) release(email, userID string) {
+	key := normalizeEmail(email)
+	if key == "" {
+		return
+	}
+
+	if holders, conflicted := idx.conflicts[key]; conflicted {
+		remaining := make([]string, 0, len(holders))
+		for _, id := range holders {
+			if id != userID {
+				remaining = append(remaining, id)
+			}
+		}
+		if len(remaining) > 1 {
+			idx.conflicts[key] = remaining
+			idx.byEmail[key] = remaining[0]
+			return
+		}
+		delete(idx.conflicts, key)
+		idx.byEmail[key] = remaining[0]
+		return
+	}
+
+	if idx.byEmail[key] == userID {
+		delete(idx.byEmail, key)
+	}
+}
+
+// lookup returns the user owning email
+func (idx *emailIndex) lookup(email string) (string, error) {
+	key := normalizeEmail(email)
+	if _, conflicted := idx.conflicts[key]; conflicted {
+		return "", ErrEmailConflict
+	}
+	userID, found := idx.byEmail[key]
+	if key == "" || !found {
+		return "", ErrUserNotFound
+	}
+	return userID, nil
+}
+
+// GetUserByEmail returns the user registered with email, compared case-insensitively
+func (ws *WalletService) GetUserByEmail(email string) (*User, error) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	userID, err := ws.emails.lookup(email)
+	if err != nil {
+		return nil, err
+	}
+	user := *ws.users[userID]
+	return &user, nil
+}
+
+// findUserByEmail returns the ID of the user registered with email
+func (ws *WalletService) findUserByEmail(email string) (string, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	userID, err := ws.emails.lookup(email)
+	return userID, err == nil
+}
+
+// UpdateUserEmail changes a user's email, keeping emails unique across users. Moving
+// a user off a shared email is how migration conflicts are resolved.
+func (ws *WalletService) UpdateUserEmail(userID, email string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	user, exists := ws.users[userID]
+	if !exists {
+		return ErrUserNotFound
+	}
+	if normalizeEmail(user.Email) == normalizeEmail(email) {
+		user.Email = email
+		ws.persistUser(user, nil)
+		return nil
+	}
+	if _, err := ws.emails.lookup(email); err != ErrUserNotFound {
+		return ErrEmailTaken
+	}
+
+	ws.emails.release(user.Email, userID)
+	ws.emails.claim(email, userID)
+	user.Email = email
+	ws.persistUser(user, nil)
+	return nil
+}
+
+// MigrateEmailIndex rebuilds the email index from the stored users and reports emails
+// shared by several users. Shared emails are indexed to the lowest user ID but lookups
+// fail with ErrEmailConflict until the other users are given new addresses with
+// UpdateUserEmail. Restoring a snapshot runs this migration automatically.
+func (ws *WalletService) MigrateEmailIndex() EmailMigrationReport {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.rebuildEmailIndex()
+}
+
+// EmailConflicts returns the shared emails still awaiting resolution
+func (ws *WalletService) EmailConflicts() []EmailConflict {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return ws.emails.report()
+}
+
+// rebuildEmailIndex indexes every user's email. Callers hold ws.mu for writing.
+func (ws *WalletService) rebuildEmailIndex() EmailMigrationReport {
+	holders := make(map[string][]string)
+	for id, user := range ws.users {
+		if key := normalizeEmail(user.Email); key != "" {
+			holders[key] = append(holders[key], id)
+		}
+	}
+
+	ws.emails = emailIndex{
+		byEmail:   make(map[string]string, len(holders)),
+		conflicts: make(map[string][]string),
+	}
+	for key, ids := range holders {
+		sort.Strings(ids)
+		ws.emails.byEmail[key] = ids[0]
+		if len(ids) > 1 {
+			ws.emails.conflicts[key] = ids
+		}
+	}
+
+	return EmailMigrationReport{Indexed: len(holders), Conflicts: ws.emails.report()}
+}
+
+// report lists the current conflicts sorted by email
+func (idx *emailIndex) report() []EmailConflict {
+	conflicts := make([]EmailConflict, 0, len(idx.conflicts))
+	for key, ids := range idx.conflicts {
+		conflicts = append(conflicts, EmailConflict{
+			Email:   key,
+			UserIDs: append([]string(nil), ids...),
+			Kept:    ids[0],
+		})
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Email < conflicts[j].Email })
+	return conflicts
+}
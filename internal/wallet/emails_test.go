@@ -0,0 +1,110 @@
+// internal/wallet/emails_test.go
+package wallet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEmailIndex_UniqueAtCreation(t *testing.T) {
+	ws := NewWalletService()
+	if err := ws.CreateUser("alice", "Alice", "alice@example.com"); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		email   string
+		wantErr error
+	}{
+		{"same email", "alice2", "alice@example.com", ErrEmailTaken},
+		{"differs only in case and spacing", "alice3", " Alice@Example.COM", ErrEmailTaken},
+		{"no email", "anon", "", nil},
+		{"second user without email", "anon2", "", nil},
+		{"distinct email", "bob", "bob@example.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.CreateUser(tt.userID, tt.name, tt.email); err != tt.wantErr {
+				t.Errorf("CreateUser() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	user, err := ws.GetUserByEmail("ALICE@example.com")
+	if err != nil || user.ID != "alice" {
+		t.Errorf("GetUserByEmail() = %+v, %v, want alice", user, err)
+	}
+	if _, err := ws.GetUserByEmail(""); err != ErrUserNotFound {
+		t.Errorf("GetUserByEmail(\"\") error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestEmailIndex_UpdateUserEmail(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+
+	if err := ws.UpdateUserEmail("bob", "Alice@example.com"); err != ErrEmailTaken {
+		t.Errorf("UpdateUserEmail() to a taken email error = %v, want %v", err, ErrEmailTaken)
+	}
+	if err := ws.UpdateUserEmail("alice", "alice@new.example.com"); err != nil {
+		t.Fatalf("UpdateUserEmail() error = %v", err)
+	}
+	if _, err := ws.GetUserByEmail("alice@example.com"); err != ErrUserNotFound {
+		t.Errorf("old email lookup error = %v, want %v", err, ErrUserNotFound)
+	}
+	if err := ws.UpdateUserEmail("bob", "alice@example.com"); err != nil {
+		t.Errorf("UpdateUserEmail() to a freed email error = %v", err)
+	}
+	if user, _ := ws.GetUserByEmail("alice@example.com"); user == nil || user.ID != "bob" {
+		t.Errorf("freed email now belongs to %+v, want bob", user)
+	}
+	if err := ws.UpdateUserEmail("nobody", "x@example.com"); err != ErrUserNotFound {
+		t.Errorf("UpdateUserEmail() unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestEmailIndex_MigratesDuplicates(t *testing.T) {
+	// Data written before emails were unique may share addresses
+	snap := &Snapshot{
+		Version: SnapshotVersion,
+		Users: []User{
+			{ID: "u3", Email: "shared@example.com"},
+			{ID: "u1", Email: "Shared@example.com"},
+			{ID: "u2", Email: "solo@example.com"},
+		},
+		Wallets: []WalletSnapshot{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}},
+	}
+	ws, err := RestoreSnapshot(snap)
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+
+	want := []EmailConflict{{Email: "shared@example.com", UserIDs: []string{"u1", "u3"}, Kept: "u1"}}
+	if got := ws.EmailConflicts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("EmailConflicts() = %+v, want %+v", got, want)
+	}
+	if report := ws.MigrateEmailIndex(); report.Indexed != 2 || len(report.Conflicts) != 1 {
+		t.Errorf("MigrateEmailIndex() = %+v, want 2 indexed with 1 conflict", report)
+	}
+	if _, err := ws.GetUserByEmail("shared@example.com"); err != ErrEmailConflict {
+		t.Errorf("GetUserByEmail() on a shared email error = %v, want %v", err, ErrEmailConflict)
+	}
+	if err := ws.CreateUser("u4", "New", "shared@example.com"); err != ErrEmailTaken {
+		t.Errorf("CreateUser() with a shared email error = %v, want %v", err, ErrEmailTaken)
+	}
+
+	// Moving one user off the shared address resolves the conflict for the other
+	if err := ws.UpdateUserEmail("u1", "u1@example.com"); err != nil {
+		t.Fatalf("UpdateUserEmail() error = %v", err)
+	}
+	if conflicts := ws.EmailConflicts(); len(conflicts) != 0 {
+		t.Errorf("EmailConflicts() = %+v, want none", conflicts)
+	}
+	if user, err := ws.GetUserByEmail("shared@example.com"); err != nil || user.ID != "u3" {
+		t.Errorf("GetUserByEmail() = %+v, %v, want u3", user, err)
+	}
+}
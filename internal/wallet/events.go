@@ -0,0 +1,168 @@
+// internal/wallet/events.go
+package wallet
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// EventType identifies the kind of domain event
+type EventType string
+
+const (
+	EventUserCreated EventType = "user_created"
+	EventDeposited   EventType = "deposited"
+	EventWithdrawn   EventType = "withdrawn"
+	EventTransferred EventType = "transferred"
+)
+
+// Event is an entry in the service's ordered event log
+type Event struct {
+	Offset      int64 // position in the log, starting at 1
+	ID          string
+	Type        EventType
+	UserID      string
+	Transaction *Transaction
+	Timestamp   int64
+
+	// Balances holds the base-currency balances of the transaction's parties right
+	// after the operation that produced the event
+	Balances map[string]decimal.Decimal
+}
+
+// eventLog is the append-only, ordered log of emitted events
+type eventLog struct {
+	mu     sync.RWMutex
+	events []Event
+	signal logSignal // advanced on every emit, for Events subscribers
+}
+
+// eventBatch is how many events a subscriber reads from the log at a time
+const eventBatch = 64
+
+// eventTypeFor maps a transaction to the event announcing it. Pending and failed
+// entries are announced as their type and status, e.g. "deposit_pending".
+func eventTypeFor(tx *Transaction) EventType {
+	if !tx.settled() {
+		return EventType(string(tx.Type) + "_" + string(tx.Status))
+	}
+	switch tx.Type {
+	case TransactionDeposit:
+		return EventDeposited
+	case TransactionWithdraw:
+		return EventWithdrawn
+	case TransactionTransfer:
+		return EventTransferred
+	}
+	return EventType(tx.Type)
+}
+
+// emit appends an event to the log, assigning its offset and ID
+func (ws *WalletService) emit(evt Event) {
+	ws.events.mu.Lock()
+	defer ws.events.mu.Unlock()
+
+	evt.Offset = int64(len(ws.events.events)) + 1
+	evt.ID = ws.newID("evt")
+	if evt.Timestamp == 0 {
+		evt.Timestamp = ws.now().Unix()
+	}
+	ws.events.events = append(ws.events.events, evt)
+	ws.events.signal.advance()
+}
+
+// emitTransaction announces a recorded transaction
+func (ws *WalletService) emitTransaction(tx *Transaction) {
+	// Called with ws.mu held
+	userID := tx.FromUserID
+	if transactionKind(tx.Type) == KindCredit {
+		userID = tx.ToUserID
+	}
+
+	balances := make(map[string]decimal.Decimal, 2)
+	for _, id := range []string{tx.FromUserID, tx.ToUserID} {
+		if wallet, exists := ws.wallets[id]; exists {
+			wallet.mu.RLock()
+			balances[id] = wallet.Balance
+			wallet.mu.RUnlock()
+		}
+	}
+
+	copied := *tx
+	ws.emit(Event{
+		Type:        eventTypeFor(tx),
+		UserID:      userID,
+		Transaction: &copied,
+		Timestamp:   tx.Timestamp,
+		Balances:    balances,
+	})
+}
+
+// EventsSince returns up to limit events with an offset greater than offset
+func (ws *WalletService) EventsSince(offset int64, limit int) []Event {
+	ws.events.mu.RLock()
+	defer ws.events.mu.RUnlock()
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= int64(len(ws.events.events)) {
+		return nil
+	}
+
+	events := ws.events.events[offset:]
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return append([]Event(nil), events...)
+}
+
+// LatestEventOffset returns the offset of the most recent event, or 0 if none
+func (ws *WalletService) LatestEventOffset() int64 {
+	ws.events.mu.RLock()
+	defer ws.events.mu.RUnlock()
+	return int64(len(ws.events.events))
+}
+
+// Events subscribes to events emitted from now on, optionally only those of types, and
+// delivers them in log order on the returned channel until ctx is done, when the
+// channel is closed. A slow reader holds back only its own channel; nothing is dropped,
+// as the subscription follows the event log. Events are shared between subscribers and
+// must not be modified.
+func (ws *WalletService) Events(ctx context.Context, types ...EventType) <-chan Event {
+	ch := make(chan Event, eventBatch)
+	offset := ws.LatestEventOffset()
+
+	go func() {
+		defer close(ch)
+		for {
+			// Take the channel before reading the log, so an event emitted in between
+			// still wakes us
+			advanced := ws.events.signal.wait()
+			batch := ws.EventsSince(offset, eventBatch)
+			if len(batch) == 0 {
+				select {
+				case <-advanced:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			for _, evt := range batch {
+				offset = evt.Offset
+				if len(types) > 0 && !slices.Contains(types, evt.Type) {
+					continue
+				}
+				select {
+				case ch <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,60 @@
+// internal/wallet/events_test.go
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventsSubscription(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	all := ws.Events(ctx)
+	money := ws.Events(ctx, EventDeposited, EventTransferred)
+
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 50, "seed")
+	ws.Withdraw("alice", 5, "cash")
+	ws.Transfer("alice", "bob", 10, "lunch")
+
+	receive := func(ch <-chan Event, n int) []EventType {
+		var got []EventType
+		for range n {
+			select {
+			case evt := <-ch:
+				got = append(got, evt.Type)
+			case <-time.After(time.Second):
+				t.Fatalf("timed out after %v", got)
+			}
+		}
+		return got
+	}
+	tests := []struct {
+		name string
+		ch   <-chan Event
+		want []EventType
+	}{
+		{"all", all, []EventType{EventUserCreated, EventDeposited, EventWithdrawn, EventTransferred}},
+		{"filtered", money, []EventType{EventDeposited, EventTransferred}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := receive(tt.ch, len(tt.want))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("events = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	cancel()
+	for range all {
+	}
+	if _, open := <-money; open {
+		t.Error("channel still open after cancel")
+	}
+}
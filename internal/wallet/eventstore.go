@@ -0,0 +1,399 @@
+// internal/wallet/eventstore.go
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultSnapshotEvery is how many transactions an EventStore appends between snapshots
+// when unset
+const DefaultSnapshotEvery = 1000
+
+// ErrInvalidPosition is returned when reconstructing state past the end of the log
+var ErrInvalidPosition = errors.New("log position out of range")
+
+// EventStore is a Store that keeps only facts, appended in order to a file: users,
+// wallet settings and the transaction log. Balances are never written; they are derived
+// by replaying the log, so the stored state cannot drift from its audit trail. Every
+// snapshotEvery transactions the derived state is saved beside the log, so opening the
+// store replays only the entries since. StateAt and StateAsOf reconstruct the state as
+// of any earlier point in the log.
+type EventStore struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	writer   *bufio.Writer
+	sync     bool
+	every    int
+	records  []eventRecord
+	head     *eventState     // state after every record
+	snapshot []eventSnapshot // cut since opening, oldest first
+}
+
+// eventRecord is one line of an EventStore log
+type eventRecord struct {
+	User        *User           `json:"user,omitempty"`
+	Wallet      *walletSettings `json:"wallet,omitempty"`
+	Transaction *Transaction    `json:"transaction,omitempty"`
+}
+
+// walletSettings is the part of a wallet that is not derived from the log
+type walletSettings struct {
+	UserID     string
+	Currency   string
+	AutoSettle bool
+}
+
+// eventSnapshot is the derived state after the first Records records, which hold
+// Position transactions
+type eventSnapshot struct {
+	Records  int
+	Position int
+	Users    []User
+	Wallets  []WalletSnapshot
+}
+
+// eventState is the state derived from a prefix of the log
+type eventState struct {
+	users    map[string]User
+	wallets  map[string]WalletSnapshot
+	records  int
+	position int
+}
+
+// NewEventStore opens or creates the event log at path, with its latest snapshot kept at
+// path+".snapshot". With syncWrites every record is flushed and fsynced before the
+// write returns. snapshotEvery of zero uses DefaultSnapshotEvery.
+func NewEventStore(path string, syncWrites bool, snapshotEvery int) (*EventStore, error) {
+	if snapshotEvery <= 0 {
+		snapshotEvery = DefaultSnapshotEvery
+	}
+	records, err := readEventLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &EventStore{path: path, sync: syncWrites, every: snapshotEvery, records: records}
+	s.head = newEventState()
+	if snap, ok := s.readSnapshot(); ok {
+		s.head = snap.state()
+		s.snapshot = append(s.snapshot, snap)
+	}
+	for _, rec := range records[s.head.records:] {
+		s.head.apply(rec)
+	}
+
+	s.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.writer = bufio.NewWriter(s.file)
+	return s, nil
+}
+
+// SaveUser appends a user record
+func (s *EventStore) SaveUser(user User) error {
+	return s.append(eventRecord{User: &user})
+}
+
+// SaveWallet appends the wallet's settings when they are new or changed. Balances are
+// derived from the log and not stored.
+func (s *EventStore) SaveWallet(wallet WalletSnapshot) error {
+	s.mu.Lock()
+	current, exists := s.head.wallets[wallet.UserID]
+	s.mu.Unlock()
+	if exists && current.Currency == wallet.Currency && current.AutoSettle == wallet.AutoSettle {
+		return nil
+	}
+	return s.append(eventRecord{Wallet: &walletSettings{UserID: wallet.UserID, Currency: wallet.Currency, AutoSettle: wallet.AutoSettle}})
+}
+
+// AppendTransaction appends a transaction record
+func (s *EventStore) AppendTransaction(tx Transaction) error {
+	return s.append(eventRecord{Transaction: &tx})
+}
+
+// CommitTransaction implements AtomicStore. The transaction is a single record, so it
+// and the balances derived from it are committed together.
+func (s *EventStore) CommitTransaction(tx Transaction, wallets []WalletSnapshot) error {
+	return s.AppendTransaction(tx)
+}
+
+// Load returns the state at the end of the log
+func (s *EventStore) Load() (*StoreState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.head.storeState(s.records), nil
+}
+
+// StateAt reconstructs the state as it was when the log held position transactions,
+// replaying from the nearest earlier snapshot
+func (s *EventStore) StateAt(position int) (*StoreState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if position < 0 || position > s.head.position {
+		return nil, ErrInvalidPosition
+	}
+	state := newEventState()
+	for i := len(s.snapshot) - 1; i >= 0; i-- {
+		if s.snapshot[i].Position <= position {
+			state = s.snapshot[i].state()
+			break
+		}
+	}
+	for _, rec := range s.records[state.records:] {
+		if rec.Transaction != nil && state.position == position {
+			break
+		}
+		state.apply(rec)
+	}
+	return state.storeState(s.records), nil
+}
+
+// StateAsOf reconstructs the state as it was at t: every transaction stamped at or
+// before t is applied
+func (s *EventStore) StateAsOf(t time.Time) (*StoreState, error) {
+	s.mu.Lock()
+	position := 0
+	for _, rec := range s.records {
+		if rec.Transaction != nil {
+			if rec.Transaction.Timestamp > t.Unix() {
+				break
+			}
+			position++
+		}
+	}
+	s.mu.Unlock()
+	return s.StateAt(position)
+}
+
+// Snapshot saves the current derived state beside the log at once, rather than waiting
+// for the next snapshotEvery transactions
+func (s *EventStore) Snapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cutSnapshot()
+}
+
+// Flush writes buffered records to the file
+func (s *EventStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+	return s.writer.Flush()
+}
+
+// Close flushes and closes the log
+func (s *EventStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.writer.Flush(), s.file.Close())
+	s.file = nil
+	return err
+}
+
+// append writes one record, applies it to the derived state and cuts a snapshot when due
+func (s *EventStore) append(rec eventRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if s.sync {
+		if err := errors.Join(s.writer.Flush(), s.file.Sync()); err != nil {
+			return err
+		}
+	}
+	s.records = append(s.records, rec)
+	s.head.apply(rec)
+
+	if rec.Transaction != nil && s.head.position%s.every == 0 {
+		return s.cutSnapshot()
+	}
+	return nil
+}
+
+// cutSnapshot records the derived state and writes it beside the log. The log is
+// flushed first, so a snapshot never covers records lost in a crash. Caller must hold
+// s.mu.
+func (s *EventStore) cutSnapshot() error {
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+	snap := s.head.snapshot()
+	s.snapshot = append(s.snapshot, snap)
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(s.writer.Flush(), s.file.Sync()); err != nil {
+		return err
+	}
+	tmp := s.path + ".snapshot.tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path+".snapshot")
+}
+
+// readSnapshot loads the snapshot saved beside the log. A snapshot that does not match
+// the log, such as one left from a log that was since replaced, is ignored.
+func (s *EventStore) readSnapshot() (eventSnapshot, bool) {
+	data, err := os.ReadFile(s.path + ".snapshot")
+	if err != nil {
+		return eventSnapshot{}, false
+	}
+	var snap eventSnapshot
+	if json.Unmarshal(data, &snap) != nil || snap.Records > len(s.records) {
+		return eventSnapshot{}, false
+	}
+	position := 0
+	for _, rec := range s.records[:snap.Records] {
+		if rec.Transaction != nil {
+			position++
+		}
+	}
+	return snap, position == snap.Position
+}
+
+// readEventLog reads every record of the log at path; a missing file is an empty log
+func readEventLog(path string) ([]eventRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []eventRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rec eventRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// newEventState returns the state of an empty log
+func newEventState() *eventState {
+	return &eventState{users: make(map[string]User), wallets: make(map[string]WalletSnapshot)}
+}
+
+// apply advances the state past one record
+func (st *eventState) apply(rec eventRecord) {
+	st.records++
+	switch {
+	case rec.User != nil:
+		st.users[rec.User.ID] = *rec.User
+	case rec.Wallet != nil:
+		w := st.wallets[rec.Wallet.UserID]
+		w.UserID, w.Currency, w.AutoSettle = rec.Wallet.UserID, rec.Wallet.Currency, rec.Wallet.AutoSettle
+		st.wallets[w.UserID] = w
+	case rec.Transaction != nil:
+		st.position++
+		st.applyTransaction(rec.Transaction)
+	}
+}
+
+// applyTransaction adds the balance effect of tx to each wallet it involves
+func (st *eventState) applyTransaction(tx *Transaction) {
+	currencies := []string{tx.currencyOf()}
+	if tx.ToCurrency != "" && tx.ToCurrency != tx.currencyOf() {
+		currencies = append(currencies, tx.ToCurrency)
+	}
+	for i, userID := range []string{tx.FromUserID, tx.ToUserID} {
+		w, exists := st.wallets[userID]
+		if !exists || (i == 1 && userID == tx.FromUserID) {
+			continue
+		}
+		for _, currency := range currencies {
+			delta := tx.balanceEffect(userID, currency)
+			if delta.IsZero() {
+				continue
+			}
+			if currency == w.Currency {
+				w.Balance = w.Balance.Add(delta)
+				continue
+			}
+			foreign := make(map[string]decimal.Decimal, len(w.Foreign)+1)
+			for c, amount := range w.Foreign {
+				foreign[c] = amount
+			}
+			foreign[currency] = foreign[currency].Add(delta)
+			w.Foreign = foreign
+		}
+		st.wallets[userID] = w
+	}
+}
+
+// snapshot returns the state in its saved form. Foreign maps are never modified once
+// set, so they are shared.
+func (st *eventState) snapshot() eventSnapshot {
+	snap := eventSnapshot{Records: st.records, Position: st.position}
+	for _, user := range st.users {
+		snap.Users = append(snap.Users, user)
+	}
+	for _, wallet := range st.wallets {
+		snap.Wallets = append(snap.Wallets, wallet)
+	}
+	sort.Slice(snap.Users, func(i, j int) bool { return snap.Users[i].ID < snap.Users[j].ID })
+	sort.Slice(snap.Wallets, func(i, j int) bool { return snap.Wallets[i].UserID < snap.Wallets[j].UserID })
+	return snap
+}
+
+// state returns a state to replay onward from the snapshot
+func (snap eventSnapshot) state() *eventState {
+	st := newEventState()
+	st.records, st.position = snap.Records, snap.Position
+	for _, user := range snap.Users {
+		st.users[user.ID] = user
+	}
+	for _, wallet := range snap.Wallets {
+		st.wallets[wallet.UserID] = wallet
+	}
+	return st
+}
+
+// storeState returns the state with the first st.position transactions of records
+func (st *eventState) storeState(records []eventRecord) *StoreState {
+	transactions := make([]Transaction, 0, st.position)
+	for _, rec := range records[:st.records] {
+		if rec.Transaction != nil {
+			transactions = append(transactions, *rec.Transaction)
+		}
+	}
+	return newStoreState(st.users, st.wallets, transactions)
+}
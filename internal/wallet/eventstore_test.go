@@ -0,0 +1,99 @@
+// internal/wallet/eventstore_test.go
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	store, err := NewEventStore(path, true, 2)
+	if err != nil {
+		t.Fatalf("NewEventStore() error = %v", err)
+	}
+	populateStore(t, store)
+	store.Close()
+	if _, err := os.Stat(path + ".snapshot"); err != nil {
+		t.Fatalf("no snapshot after four transactions: %v", err)
+	}
+
+	log, _ := os.ReadFile(path)
+	snapshot, _ := os.ReadFile(path + ".snapshot")
+	for _, name := range []string{"from snapshot", "full replay"} {
+		t.Run(name, func(t *testing.T) {
+			copied := filepath.Join(t.TempDir(), "events.jsonl")
+			os.WriteFile(copied, log, 0o600)
+			if name == "from snapshot" {
+				os.WriteFile(copied+".snapshot", snapshot, 0o600)
+			}
+			reopened, err := NewEventStore(copied, false, 2)
+			if err != nil {
+				t.Fatalf("NewEventStore() reopen error = %v", err)
+			}
+			defer reopened.Close()
+			checkReopened(t, reopened)
+		})
+	}
+}
+
+func TestEventStore_PointInTime(t *testing.T) {
+	clock := newFakeClock()
+	store, err := NewEventStore(filepath.Join(t.TempDir(), "events.jsonl"), false, 3)
+	if err != nil {
+		t.Fatalf("NewEventStore() error = %v", err)
+	}
+	defer store.Close()
+	ws := NewWalletService(WithStore(store), WithClock(clock.Now))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	start := clock.Now()
+	for _, amount := range []float64{10, 20, 30, 40, 50} {
+		clock.Advance(time.Hour)
+		ws.Deposit("alice", amount, "top-up")
+		ws.Transfer("alice", "bob", 1, "fee")
+	}
+
+	balances := func(state *StoreState) (alice, bob string) {
+		for _, w := range state.Wallets {
+			switch w.UserID {
+			case "alice":
+				alice = w.Balance.String()
+			case "bob":
+				bob = w.Balance.String()
+			}
+		}
+		return alice, bob
+	}
+	tests := []struct {
+		name      string
+		state     func() (*StoreState, error)
+		wantTxs   int
+		wantAlice string
+		wantBob   string
+	}{
+		{"empty log", func() (*StoreState, error) { return store.StateAt(0) }, 0, "0", "0"},
+		{"before snapshot", func() (*StoreState, error) { return store.StateAt(1) }, 1, "10", "0"},
+		{"between snapshots", func() (*StoreState, error) { return store.StateAt(5) }, 5, "58", "2"},
+		{"end", func() (*StoreState, error) { return store.StateAt(10) }, 10, "145", "5"},
+		{"as of two hours in", func() (*StoreState, error) { return store.StateAsOf(start.Add(2 * time.Hour)) }, 4, "28", "2"},
+		{"as of before any", func() (*StoreState, error) { return store.StateAsOf(start.Add(-time.Hour)) }, 0, "0", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := tt.state()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			alice, bob := balances(state)
+			if len(state.Transactions) != tt.wantTxs || alice != tt.wantAlice || bob != tt.wantBob {
+				t.Errorf("state = %d transactions, alice %s, bob %s; want %d, %s, %s", len(state.Transactions), alice, bob, tt.wantTxs, tt.wantAlice, tt.wantBob)
+			}
+		})
+	}
+	if _, err := store.StateAt(11); err != ErrInvalidPosition {
+		t.Errorf("StateAt(past end) error = %v, want %v", err, ErrInvalidPosition)
+	}
+}
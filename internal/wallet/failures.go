@@ -0,0 +1,253 @@
+// internal/wallet/failures.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for the failed-operation log
+var (
+	ErrFailureNotFound     = errors.New("failed operation not found")
+	ErrFailureNotRetryable = errors.New("failed operation cannot be retried")
+	ErrFailureRetried      = errors.New("failed operation already retried")
+)
+
+// MaxFailuresPerUser is how many failed operations are kept per user; older ones are
+// dropped first
+const MaxFailuresPerUser = 100
+
+// metaRetryOf records on a transaction the failed operation it retried
+const metaRetryOf = "retry_of"
+
+// FailureReason is the reason code of a failed operation
+type FailureReason string
+
+const (
+	FailureInsufficientFunds   FailureReason = "insufficient_funds"
+	FailureLimitExceeded       FailureReason = "limit_exceeded"
+	FailureStepUpRequired      FailureReason = "step_up_required"
+	FailureRiskDenied          FailureReason = "risk_denied"
+	FailureCounterpartyBlocked FailureReason = "counterparty_blocked"
+	FailureWalletUnavailable   FailureReason = "wallet_unavailable"
+)
+
+// failureReasons classifies the errors a failed operation is logged for, in the order
+// they are matched. Retryable reasons may pass once the wallet changes: funds arrive, a
+// limit window rolls over, or the owner completes step-up verification.
+var failureReasons = []struct {
+	err       error
+	reason    FailureReason
+	retryable bool
+}{
+	{ErrInsufficientBalance, FailureInsufficientFunds, true},
+	{ErrBelowMinimumBalance, FailureInsufficientFunds, true},
+	{ErrBelowFloor, FailureInsufficientFunds, true},
+	{ErrLimitExceeded, FailureLimitExceeded, true},
+	{ErrRestrictedLimit, FailureLimitExceeded, true},
+	{ErrAboveMaximumBalance, FailureLimitExceeded, true},
+	{ErrStepUpRequired, FailureStepUpRequired, true},
+	{ErrOperationRejected, FailureRiskDenied, false},
+	{ErrCounterpartyBlocked, FailureCounterpartyBlocked, false},
+	{ErrWalletFrozen, FailureWalletUnavailable, false},
+	{ErrWalletClosed, FailureWalletUnavailable, false},
+}
+
+// FailedOperation is a deposit, withdrawal or transfer the service refused, kept so
+// support and the user can see why it did not happen. Only refusals with a reason code
+// are logged; malformed requests are not.
+type FailedOperation struct {
+	ID             string
+	UserID         string // wallet debited or credited; the sender of a transfer
+	Type           TransactionType
+	CounterpartyID string // recipient of a transfer
+	Amount         decimal.Decimal
+	Description    string
+	IdempotencyKey string // key the request was sent with, reused by retries
+	Reason         FailureReason
+	Error          string
+	Retryable      bool
+	Attempts       int    // times the operation was run, including the first
+	FailedAt       int64  // when the last attempt failed
+	TransactionID  string // set once a retry went through
+	RetriedAt      int64
+}
+
+// failureLog indexes failed operations by ID and by user
+type failureLog struct {
+	mu       sync.Mutex
+	byID     map[string]*FailedOperation
+	byUser   map[string][]*FailedOperation // oldest first
+	retrying map[string]bool               // failures a retry is running
+}
+
+// noteFailure logs op as failed with err, unless err is nil or has no reason code
+func (ws *WalletService) noteFailure(op FailedOperation, err error) {
+	reason, retryable, ok := classifyFailure(err)
+	if !ok {
+		return
+	}
+	op.ID = ws.newID("fail")
+	op.Reason, op.Retryable, op.Error = reason, retryable, err.Error()
+	op.Attempts, op.FailedAt = 1, ws.now().Unix()
+
+	ws.failures.mu.Lock()
+	defer ws.failures.mu.Unlock()
+	if ws.failures.byID == nil {
+		ws.failures.byID = make(map[string]*FailedOperation)
+		ws.failures.byUser = make(map[string][]*FailedOperation)
+	}
+	list := append(ws.failures.byUser[op.UserID], &op)
+	if len(list) > MaxFailuresPerUser {
+		for _, dropped := range list[:len(list)-MaxFailuresPerUser] {
+			delete(ws.failures.byID, dropped.ID)
+		}
+		list = append([]*FailedOperation(nil), list[len(list)-MaxFailuresPerUser:]...)
+	}
+	ws.failures.byID[op.ID] = &op
+	ws.failures.byUser[op.UserID] = list
+	ws.metrics.IncCounter("operation_failures_total", map[string]string{"type": string(op.Type), "reason": string(reason)})
+}
+
+// GetFailedOperation returns a logged failure
+func (ws *WalletService) GetFailedOperation(failureID string) (*FailedOperation, error) {
+	ws.failures.mu.Lock()
+	defer ws.failures.mu.Unlock()
+
+	op, exists := ws.failures.byID[failureID]
+	if !exists {
+		return nil, ErrFailureNotFound
+	}
+	copied := *op
+	return &copied, nil
+}
+
+// ListFailedOperations returns userID's failed operations with reason, or all of them
+// when reason is empty, newest first
+func (ws *WalletService) ListFailedOperations(userID string, reason FailureReason) ([]FailedOperation, error) {
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+
+	ws.failures.mu.Lock()
+	defer ws.failures.mu.Unlock()
+
+	var list []FailedOperation
+	logged := ws.failures.byUser[userID]
+	for i := len(logged) - 1; i >= 0; i-- {
+		if reason == "" || logged[i].Reason == reason {
+			list = append(list, *logged[i])
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].FailedAt > list[j].FailedAt })
+	return list, nil
+}
+
+// RetryOperation runs a failed operation again as it was first requested. Operations
+// sent with an idempotency key are retried under it, so a retry and the client
+// replaying the key cannot both apply. A retry that fails again updates the logged
+// failure and returns its error; one that goes through returns the transaction it
+// created, and a transfer held for review returns it with ErrTransferHeld.
+func (ws *WalletService) RetryOperation(failureID string) (*Transaction, error) {
+	return ws.RetryOperationContext(context.Background(), failureID)
+}
+
+// RetryOperationContext is RetryOperation on behalf of a caller's ctx
+func (ws *WalletService) RetryOperationContext(ctx context.Context, failureID string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	op, err := ws.claimFailure(failureID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ws.rerun(ctx, op)
+
+	ws.failures.mu.Lock()
+	defer ws.failures.mu.Unlock()
+	delete(ws.failures.retrying, failureID)
+	// The failure may have been dropped from the log while the retry ran
+	logged, exists := ws.failures.byID[failureID]
+	if !exists {
+		logged = &op
+	}
+	logged.Attempts++
+	if tx == nil {
+		logged.FailedAt, logged.Error = ws.now().Unix(), err.Error()
+		if reason, retryable, ok := classifyFailure(err); ok {
+			logged.Reason, logged.Retryable = reason, retryable
+		}
+		return nil, err
+	}
+	logged.TransactionID, logged.RetriedAt = tx.ID, tx.Timestamp
+	ws.metrics.IncCounter("operation_retries_total", map[string]string{"type": string(op.Type), "reason": string(op.Reason)})
+	return tx, err
+}
+
+// claimFailure checks failureID may be retried and marks it as being retried, so two
+// retries cannot both run it
+func (ws *WalletService) claimFailure(failureID string) (FailedOperation, error) {
+	ws.failures.mu.Lock()
+	defer ws.failures.mu.Unlock()
+
+	op, exists := ws.failures.byID[failureID]
+	if !exists {
+		return FailedOperation{}, ErrFailureNotFound
+	}
+	if op.TransactionID != "" || ws.failures.retrying[failureID] {
+		return FailedOperation{}, ErrFailureRetried
+	}
+	if !op.Retryable {
+		return FailedOperation{}, ErrFailureNotRetryable
+	}
+	if ws.failures.retrying == nil {
+		ws.failures.retrying = make(map[string]bool)
+	}
+	ws.failures.retrying[failureID] = true
+	return *op, nil
+}
+
+// rerun runs op again, annotating the transaction it creates with the failure it retries
+func (ws *WalletService) rerun(ctx context.Context, op FailedOperation) (*Transaction, error) {
+	apply := func(meta map[string]string) (*Transaction, error) {
+		if meta == nil {
+			meta = make(map[string]string)
+		}
+		meta[metaRetryOf] = op.ID
+		switch op.Type {
+		case TransactionDeposit:
+			return ws.deposit(ctx, op.UserID, op.Amount, op.Description, meta)
+		case TransactionWithdraw:
+			return ws.withdraw(ctx, op.UserID, op.Amount, op.Description, meta)
+		}
+		return ws.transfer(ctx, op.UserID, op.CounterpartyID, op.Amount, op.Description, transferOptions{metadata: meta})
+	}
+	if op.IdempotencyKey == "" {
+		return apply(nil)
+	}
+
+	want := &Transaction{FromUserID: op.UserID, ToUserID: op.UserID, Amount: op.Amount, Type: op.Type}
+	if op.Type == TransactionTransfer {
+		want.ToUserID = op.CounterpartyID
+	}
+	return ws.idempotent(op.IdempotencyKey, want, apply)
+}
+
+// classifyFailure returns the reason code of err and whether the operation may pass
+// when retried; ok is false for errors that are not logged
+func classifyFailure(err error) (reason FailureReason, retryable, ok bool) {
+	if err == nil {
+		return "", false, false
+	}
+	for _, c := range failureReasons {
+		if errors.Is(err, c.err) {
+			return c.reason, c.retryable, true
+		}
+	}
+	return "", false, false
+}
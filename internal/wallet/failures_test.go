@@ -0,0 +1,161 @@
+// internal/wallet/failures_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNoteFailure_Reasons(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.BlockUser("bob", "alice")
+	ws.SetSpendingLimits("alice", SpendingLimits{PerTransaction: decimal.NewFromInt(150)})
+
+	tests := []struct {
+		name          string
+		run           func() error
+		wantReason    FailureReason
+		wantRetryable bool
+	}{
+		{"overdraw", func() error { return ws.Withdraw("alice", 120, "rent") }, FailureInsufficientFunds, true},
+		{"above the limit", func() error { return ws.Withdraw("alice", 200, "tv") }, FailureLimitExceeded, true},
+		{"blocked recipient", func() error { return ws.Transfer("alice", "bob", 10, "hi") }, FailureCounterpartyBlocked, false},
+		{"invalid amount", func() error { return ws.Withdraw("alice", -1, "") }, "", false},
+		{"unknown user", func() error { return ws.Deposit("ghost", 5, "") }, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := ws.ListFailedOperations("alice", "")
+			if err := tt.run(); err == nil {
+				t.Fatal("operation succeeded, want an error")
+			}
+			after, _ := ws.ListFailedOperations("alice", "")
+			if tt.wantReason == "" {
+				if len(after) != len(before) {
+					t.Errorf("logged %+v, want nothing", after[0])
+				}
+				return
+			}
+			if len(after) != len(before)+1 {
+				t.Fatalf("logged %d failures, want one", len(after)-len(before))
+			}
+			if got := after[0]; got.Reason != tt.wantReason || got.Retryable != tt.wantRetryable || got.Attempts != 1 || got.Error == "" {
+				t.Errorf("failure = %+v, want %s with retryable %v", got, tt.wantReason, tt.wantRetryable)
+			}
+		})
+	}
+
+	if list, _ := ws.ListFailedOperations("alice", FailureCounterpartyBlocked); len(list) != 1 || list[0].CounterpartyID != "bob" {
+		t.Errorf("ListFailedOperations(blocked) = %+v", list)
+	}
+	if _, err := ws.ListFailedOperations("ghost", ""); err != ErrUserNotFound {
+		t.Errorf("ListFailedOperations(ghost) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestRetryOperation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 20, "seed")
+
+	ws.Transfer("alice", "bob", 50, "dinner")
+	ws.BlockUser("bob", "alice")
+	ws.Transfer("alice", "bob", 5, "again")
+	failures, _ := ws.ListFailedOperations("alice", "")
+	if len(failures) != 2 {
+		t.Fatalf("logged %+v, want two failures", failures)
+	}
+	blocked, short := failures[0], failures[1]
+
+	if _, err := ws.RetryOperation(blocked.ID); err != ErrFailureNotRetryable {
+		t.Errorf("retrying a blocked transfer error = %v, want %v", err, ErrFailureNotRetryable)
+	}
+	if _, err := ws.RetryOperation("fail_missing"); err != ErrFailureNotFound {
+		t.Errorf("retrying an unknown failure error = %v, want %v", err, ErrFailureNotFound)
+	}
+
+	// Still short, and now blocked too: the failure takes the latest reason
+	if _, err := ws.RetryOperation(short.ID); !errors.Is(err, ErrCounterpartyBlocked) {
+		t.Fatalf("RetryOperation() error = %v, want %v", err, ErrCounterpartyBlocked)
+	}
+	got, _ := ws.GetFailedOperation(short.ID)
+	if got.Attempts != 2 || got.Reason != FailureCounterpartyBlocked || got.Retryable {
+		t.Errorf("failure after retry = %+v", got)
+	}
+	if _, err := ws.RetryOperation(short.ID); err != ErrFailureNotRetryable {
+		t.Errorf("retrying again error = %v, want %v", err, ErrFailureNotRetryable)
+	}
+
+	ws.Withdraw("alice", 30, "rent")
+	ws.Deposit("alice", 40, "payday")
+	rent, _ := ws.ListFailedOperations("alice", FailureInsufficientFunds)
+	tx, err := ws.RetryOperation(rent[0].ID)
+	if err != nil {
+		t.Fatalf("RetryOperation() error = %v", err)
+	}
+	if tx.Type != TransactionWithdraw || !tx.Amount.Equal(decimal.NewFromInt(30)) || tx.Metadata[metaRetryOf] != rent[0].ID {
+		t.Errorf("retried withdrawal = %+v", tx)
+	}
+	if got, _ := ws.GetFailedOperation(rent[0].ID); got.TransactionID != tx.ID || got.Attempts != 2 {
+		t.Errorf("failure after retry = %+v", got)
+	}
+	if _, err := ws.RetryOperation(rent[0].ID); err != ErrFailureRetried {
+		t.Errorf("retrying a retried failure error = %v, want %v", err, ErrFailureRetried)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("balance = %s, want 30", b)
+	}
+}
+
+func TestRetryOperation_IdempotencyKey(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	if _, err := ws.WithdrawIdempotent("key-1", "alice", decimal.NewFromInt(10), "atm"); err != ErrInsufficientBalance {
+		t.Fatalf("WithdrawIdempotent() error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	failures, _ := ws.ListFailedOperations("alice", "")
+	if len(failures) != 1 || failures[0].IdempotencyKey != "key-1" {
+		t.Fatalf("logged %+v, want the keyed withdrawal", failures)
+	}
+
+	// The client replays the key first; the retry returns its withdrawal
+	ws.Deposit("alice", 25, "seed")
+	replayed, err := ws.WithdrawIdempotent("key-1", "alice", decimal.NewFromInt(10), "atm")
+	if err != nil {
+		t.Fatalf("WithdrawIdempotent() replay error = %v", err)
+	}
+	tx, err := ws.RetryOperation(failures[0].ID)
+	if err != nil || tx.ID != replayed.ID {
+		t.Errorf("RetryOperation() = %+v, %v, want the replayed withdrawal %s", tx, err, replayed.ID)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("balance = %s, want 15", b)
+	}
+}
+
+func TestNoteFailure_KeepsNewest(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	var first string
+	for i := 0; i < MaxFailuresPerUser+5; i++ {
+		ws.Withdraw("alice", 1, "")
+		if i == 0 {
+			list, _ := ws.ListFailedOperations("alice", "")
+			first = list[0].ID
+		}
+	}
+	if list, _ := ws.ListFailedOperations("alice", ""); len(list) != MaxFailuresPerUser {
+		t.Errorf("kept %d failures, want %d", len(list), MaxFailuresPerUser)
+	}
+	if _, err := ws.GetFailedOperation(first); err != ErrFailureNotFound {
+		t.Errorf("GetFailedOperation(oldest) error = %v, want %v", err, ErrFailureNotFound)
+	}
+}
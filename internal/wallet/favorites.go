@@ -0,0 +1,250 @@
+// internal/wallet/favorites.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for quick-pay favorites
+var (
+	ErrFavoriteNotFound = errors.New("favorite not found")
+	ErrInvalidFavorite  = errors.New("favorite needs another user as payee and a non-negative amount")
+	ErrFavoriteNoAmount = errors.New("favorite has no default amount")
+	ErrInvalidFavorites = errors.New("reorder must list each favorite exactly once")
+)
+
+// FavoriteSpec describes a saved payee. Memo is a template: {payee}, {label}, {date}
+// and {month} are filled in each time the favorite is paid, so "Rent {month}" becomes
+// "Rent March 2024". A zero Amount leaves the amount to be given at payment time.
+type FavoriteSpec struct {
+	PayeeID string
+	Label   string
+	Amount  decimal.Decimal
+	Memo    string
+}
+
+// Favorite is a saved payee a user can pay again in one step
+type Favorite struct {
+	ID         string
+	UserID     string
+	PayeeID    string
+	Label      string
+	Amount     decimal.Decimal
+	Memo       string
+	CreatedAt  int64
+	LastUsedAt int64 // zero until first paid
+	UseCount   int
+}
+
+// favoriteBook holds saved payees. Each user's favorites are kept in display order.
+type favoriteBook struct {
+	mu     sync.Mutex
+	byID   map[string]*Favorite
+	byUser map[string][]string // user -> favorite IDs in display order
+}
+
+// AddFavorite saves a payee for userID at the end of their favorites
+func (ws *WalletService) AddFavorite(userID string, spec FavoriteSpec) (*Favorite, error) {
+	spec, err := ws.checkFavorite(userID, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Favorite{
+		ID:        ws.newID("fav"),
+		UserID:    userID,
+		PayeeID:   spec.PayeeID,
+		Label:     spec.Label,
+		Amount:    spec.Amount,
+		Memo:      spec.Memo,
+		CreatedAt: ws.now().Unix(),
+	}
+
+	ws.favorites.mu.Lock()
+	defer ws.favorites.mu.Unlock()
+	if ws.favorites.byID == nil {
+		ws.favorites.byID = make(map[string]*Favorite)
+		ws.favorites.byUser = make(map[string][]string)
+	}
+	ws.favorites.byID[f.ID] = f
+	ws.favorites.byUser[userID] = append(ws.favorites.byUser[userID], f.ID)
+	copied := *f
+	return &copied, nil
+}
+
+// UpdateFavorite replaces the payee, label, amount and memo of a favorite, keeping its
+// place and usage
+func (ws *WalletService) UpdateFavorite(userID, favoriteID string, spec FavoriteSpec) (*Favorite, error) {
+	spec, err := ws.checkFavorite(userID, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.favorites.mu.Lock()
+	defer ws.favorites.mu.Unlock()
+	f, exists := ws.favorites.byID[favoriteID]
+	if !exists || f.UserID != userID {
+		return nil, ErrFavoriteNotFound
+	}
+	f.PayeeID, f.Label, f.Amount, f.Memo = spec.PayeeID, spec.Label, spec.Amount, spec.Memo
+	copied := *f
+	return &copied, nil
+}
+
+// RemoveFavorite deletes one of userID's favorites
+func (ws *WalletService) RemoveFavorite(userID, favoriteID string) error {
+	ws.favorites.mu.Lock()
+	defer ws.favorites.mu.Unlock()
+
+	f, exists := ws.favorites.byID[favoriteID]
+	if !exists || f.UserID != userID {
+		return ErrFavoriteNotFound
+	}
+	delete(ws.favorites.byID, favoriteID)
+	ws.favorites.byUser[userID] = slices.DeleteFunc(ws.favorites.byUser[userID], func(id string) bool { return id == favoriteID })
+	return nil
+}
+
+// ListFavorites returns userID's favorites in display order
+func (ws *WalletService) ListFavorites(userID string) []Favorite {
+	ws.favorites.mu.Lock()
+	defer ws.favorites.mu.Unlock()
+
+	ids := ws.favorites.byUser[userID]
+	list := make([]Favorite, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, *ws.favorites.byID[id])
+	}
+	return list
+}
+
+// ReorderFavorites sets the display order of userID's favorites. ids must name every
+// favorite of the user exactly once.
+func (ws *WalletService) ReorderFavorites(userID string, ids []string) error {
+	ws.favorites.mu.Lock()
+	defer ws.favorites.mu.Unlock()
+
+	current := ws.favorites.byUser[userID]
+	if len(ids) != len(current) {
+		return ErrInvalidFavorites
+	}
+	sorted, want := slices.Clone(ids), slices.Clone(current)
+	sort.Strings(sorted)
+	sort.Strings(want)
+	if !slices.Equal(sorted, want) {
+		return ErrInvalidFavorites
+	}
+	ws.favorites.byUser[userID] = slices.Clone(ids)
+	return nil
+}
+
+// QuickPay sends a favorite's default amount to its payee with its memo filled in
+func (ws *WalletService) QuickPay(favoriteID string) (*Transaction, error) {
+	return ws.QuickPayContext(context.Background(), favoriteID)
+}
+
+// QuickPayContext is QuickPay on behalf of a caller's ctx
+func (ws *WalletService) QuickPayContext(ctx context.Context, favoriteID string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ws.quickPay(ctx, favoriteID, decimal.Zero)
+}
+
+// QuickPayAmount pays a favorite like QuickPay but sends amount instead of the default
+func (ws *WalletService) QuickPayAmount(favoriteID string, amount decimal.Decimal) (*Transaction, error) {
+	return ws.QuickPayAmountContext(context.Background(), favoriteID, amount)
+}
+
+// QuickPayAmountContext is QuickPayAmount on behalf of a caller's ctx
+func (ws *WalletService) QuickPayAmountContext(ctx context.Context, favoriteID string, amount decimal.Decimal) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	return ws.quickPay(ctx, favoriteID, amount)
+}
+
+// quickPay transfers amount, or the favorite's default when amount is zero. A transfer
+// held for compliance review counts as a use of the favorite.
+func (ws *WalletService) quickPay(ctx context.Context, favoriteID string, amount decimal.Decimal) (*Transaction, error) {
+	ws.favorites.mu.Lock()
+	stored, exists := ws.favorites.byID[favoriteID]
+	var f Favorite
+	if exists {
+		f = *stored
+	}
+	ws.favorites.mu.Unlock()
+	if !exists {
+		return nil, ErrFavoriteNotFound
+	}
+	if amount.IsZero() {
+		if !f.Amount.IsPositive() {
+			return nil, ErrFavoriteNoAmount
+		}
+		amount = f.Amount
+	}
+
+	memo := ws.expandMemo(f)
+	tx, err := ws.transfer(ctx, f.UserID, f.PayeeID, amount, memo, transferOptions{metadata: map[string]string{"favorite_id": f.ID}})
+	if err != nil && !errors.Is(err, ErrTransferHeld) {
+		return nil, err
+	}
+
+	ws.favorites.mu.Lock()
+	if stored, exists := ws.favorites.byID[favoriteID]; exists {
+		stored.LastUsedAt = tx.Timestamp
+		stored.UseCount++
+	}
+	ws.favorites.mu.Unlock()
+	ws.metrics.IncCounter("quick_payments_total", nil)
+	return tx, err
+}
+
+// checkFavorite trims spec and checks its payee and amount
+func (ws *WalletService) checkFavorite(userID string, spec FavoriteSpec) (FavoriteSpec, error) {
+	spec.PayeeID = strings.TrimSpace(spec.PayeeID)
+	spec.Label = strings.TrimSpace(spec.Label)
+	if spec.PayeeID == "" || spec.PayeeID == userID || spec.Amount.IsNegative() {
+		return spec, ErrInvalidFavorite
+	}
+
+	ws.mu.RLock()
+	_, userExists := ws.users[userID]
+	_, payeeExists := ws.users[spec.PayeeID]
+	ws.mu.RUnlock()
+	if !userExists || !payeeExists {
+		return spec, ErrUserNotFound
+	}
+	return spec, nil
+}
+
+// expandMemo fills in the placeholders of f's memo
+func (ws *WalletService) expandMemo(f Favorite) string {
+	if !strings.Contains(f.Memo, "{") {
+		return f.Memo
+	}
+	payee := f.PayeeID
+	ws.mu.RLock()
+	if u, exists := ws.users[f.PayeeID]; exists && u.Name != "" {
+		payee = u.Name
+	}
+	ws.mu.RUnlock()
+
+	now := ws.now()
+	return strings.NewReplacer(
+		"{payee}", payee,
+		"{label}", f.Label,
+		"{date}", now.Format("2006-01-02"),
+		"{month}", now.Format("January 2006"),
+	).Replace(f.Memo)
+}
@@ -0,0 +1,118 @@
+// internal/wallet/favorites_test.go
+package wallet
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestQuickPay(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 70, "seed")
+
+	rent, err := ws.AddFavorite("alice", FavoriteSpec{PayeeID: "bob", Label: "Rent", Amount: decimal.NewFromInt(40), Memo: "{label} {month} to {payee}"})
+	if err != nil {
+		t.Fatalf("AddFavorite() error = %v", err)
+	}
+	dinner, _ := ws.AddFavorite("alice", FavoriteSpec{PayeeID: "bob", Label: "Dinner"})
+
+	tests := []struct {
+		name     string
+		pay      func() (*Transaction, error)
+		wantErr  error
+		wantMemo string
+	}{
+		{"default amount and memo", func() (*Transaction, error) { return ws.QuickPay(rent.ID) }, nil, "Rent January 2024 to Bob"},
+		{"no default amount", func() (*Transaction, error) { return ws.QuickPay(dinner.ID) }, ErrFavoriteNoAmount, ""},
+		{"amount given", func() (*Transaction, error) { return ws.QuickPayAmount(dinner.ID, decimal.NewFromInt(15)) }, nil, ""},
+		{"insufficient balance", func() (*Transaction, error) { return ws.QuickPay(rent.ID) }, ErrInsufficientBalance, ""},
+		{"unknown favorite", func() (*Transaction, error) { return ws.QuickPay("fav_missing") }, ErrFavoriteNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := tt.pay()
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && tx.Description != tt.wantMemo {
+				t.Errorf("memo = %q, want %q", tx.Description, tt.wantMemo)
+			}
+		})
+	}
+
+	if balance, _ := ws.GetBalanceDecimal("bob"); !balance.Equal(decimal.NewFromInt(55)) {
+		t.Errorf("payee balance = %s, want 55", balance)
+	}
+	list := ws.ListFavorites("alice")
+	if len(list) != 2 || list[0].UseCount != 1 || list[0].LastUsedAt != clock.Now().Unix() || list[1].UseCount != 1 {
+		t.Errorf("favorites = %+v, want one use each", list)
+	}
+}
+
+func TestManageFavorites(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.CreateUser("carol", "Carol", "c@example.com")
+
+	invalid := []struct {
+		name    string
+		spec    FavoriteSpec
+		wantErr error
+	}{
+		{"self", FavoriteSpec{PayeeID: "alice"}, ErrInvalidFavorite},
+		{"negative amount", FavoriteSpec{PayeeID: "bob", Amount: decimal.NewFromInt(-1)}, ErrInvalidFavorite},
+		{"unknown payee", FavoriteSpec{PayeeID: "dave"}, ErrUserNotFound},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.AddFavorite("alice", tt.spec); err != tt.wantErr {
+				t.Errorf("AddFavorite() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	a, _ := ws.AddFavorite("alice", FavoriteSpec{PayeeID: "bob", Label: "A"})
+	b, _ := ws.AddFavorite("alice", FavoriteSpec{PayeeID: "carol", Label: "B"})
+	c, _ := ws.AddFavorite("alice", FavoriteSpec{PayeeID: "bob", Label: "C"})
+	ids := func() []string {
+		var out []string
+		for _, f := range ws.ListFavorites("alice") {
+			out = append(out, f.ID)
+		}
+		return out
+	}
+
+	if err := ws.ReorderFavorites("alice", []string{c.ID, a.ID}); err != ErrInvalidFavorites {
+		t.Errorf("ReorderFavorites(missing one) error = %v, want %v", err, ErrInvalidFavorites)
+	}
+	if err := ws.ReorderFavorites("alice", []string{c.ID, a.ID, a.ID}); err != ErrInvalidFavorites {
+		t.Errorf("ReorderFavorites(duplicate) error = %v, want %v", err, ErrInvalidFavorites)
+	}
+	if err := ws.ReorderFavorites("alice", []string{c.ID, a.ID, b.ID}); err != nil {
+		t.Fatalf("ReorderFavorites() error = %v", err)
+	}
+	if got := ids(); !slices.Equal(got, []string{c.ID, a.ID, b.ID}) {
+		t.Errorf("order = %v, want c, a, b", got)
+	}
+
+	if _, err := ws.UpdateFavorite("bob", a.ID, FavoriteSpec{PayeeID: "carol"}); err != ErrFavoriteNotFound {
+		t.Errorf("UpdateFavorite(other user) error = %v, want %v", err, ErrFavoriteNotFound)
+	}
+	if updated, _ := ws.UpdateFavorite("alice", a.ID, FavoriteSpec{PayeeID: "carol", Label: " A2 "}); updated.PayeeID != "carol" || updated.Label != "A2" {
+		t.Errorf("UpdateFavorite() = %+v", updated)
+	}
+	if err := ws.RemoveFavorite("alice", a.ID); err != nil {
+		t.Fatalf("RemoveFavorite() error = %v", err)
+	}
+	if got := ids(); !slices.Equal(got, []string{c.ID, b.ID}) {
+		t.Errorf("order after remove = %v, want c, b", got)
+	}
+	if _, err := ws.QuickPay(a.ID); err != ErrFavoriteNotFound {
+		t.Errorf("QuickPay(removed) error = %v, want %v", err, ErrFavoriteNotFound)
+	}
+}
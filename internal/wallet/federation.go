@@ -0,0 +1,395 @@
+// internal/wallet/federation.go
+package wallet
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for cross-instance transfers
+var (
+	ErrFederationDisabled = errors.New("federation is not configured")
+	ErrUnknownPeer        = errors.New("unknown federation peer")
+	ErrInvalidSignature   = errors.New("invalid federation signature")
+	ErrWrongInstance      = errors.New("message addressed to another instance")
+	ErrVoucherNotFound    = errors.New("federation voucher not found")
+	ErrVoucherExpired     = errors.New("federation voucher expired")
+)
+
+// DefaultVoucherTTL is how long a receiving instance accepts a voucher after it is issued
+const DefaultVoucherTTL = 24 * time.Hour
+
+// FederationConfig identifies this instance and the peers it exchanges transfers with
+type FederationConfig struct {
+	InstanceID string
+	PeerKeys   map[string][]byte // shared HMAC secret per peer instance ID
+	VoucherTTL time.Duration
+}
+
+// OutboundStatus is the sending side's view of a federated transfer
+type OutboundStatus string
+
+const (
+	OutboundPending  OutboundStatus = "pending"
+	OutboundSettled  OutboundStatus = "settled"
+	OutboundRefunded OutboundStatus = "refunded"
+)
+
+// ReceiptStatus is the receiving side's verdict on a voucher
+type ReceiptStatus string
+
+const (
+	ReceiptAccepted ReceiptStatus = "accepted"
+	ReceiptRejected ReceiptStatus = "rejected"
+)
+
+// FederationVoucher is the signed claim sent from the debiting instance to the crediting one
+type FederationVoucher struct {
+	ID             string
+	SourceInstance string
+	TargetInstance string
+	FromUserID     string
+	ToUserID       string
+	Amount         decimal.Decimal
+	Currency       string
+	Description    string
+	CreatedAt      int64
+	ExpiresAt      int64
+	Signature      string
+}
+
+// FederationReceipt is the signed answer the crediting instance returns for a voucher
+type FederationReceipt struct {
+	VoucherID      string
+	SourceInstance string // instance that processed the voucher
+	TargetInstance string // instance that issued the voucher
+	Status         ReceiptStatus
+	Reason         string
+	TransactionID  string
+	Timestamp      int64
+	Signature      string
+}
+
+// OutboundTransfer tracks a voucher this instance issued until it settles or is refunded
+type OutboundTransfer struct {
+	Voucher    FederationVoucher
+	Status     OutboundStatus
+	DebitTxID  string
+	RefundTxID string
+	Reason     string
+	UpdatedAt  int64
+}
+
+// federationState holds the federation configuration and both legs' bookkeeping
+type federationState struct {
+	mu       sync.Mutex
+	cfg      *FederationConfig
+	outbound map[string]*OutboundTransfer
+	inbound  map[string]*FederationReceipt
+}
+
+// WithFederation enables cross-instance transfers using the given configuration
+func WithFederation(cfg FederationConfig) Option {
+	return func(ws *WalletService) {
+		if cfg.VoucherTTL <= 0 {
+			cfg.VoucherTTL = DefaultVoucherTTL
+		}
+		ws.federation.cfg = &cfg
+		ws.federation.outbound = make(map[string]*OutboundTransfer)
+		ws.federation.inbound = make(map[string]*FederationReceipt)
+	}
+}
+
+// FederatedAddress formats a remote user reference as userID@instanceID
+func FederatedAddress(userID, instanceID string) string {
+	return userID + "@" + instanceID
+}
+
+// SendFederatedTransfer debits fromUserID and issues a signed voucher that the target
+// instance redeems to credit toUserID. Funds stay debited until a receipt arrives.
+func (ws *WalletService) SendFederatedTransfer(fromUserID, targetInstance, toUserID string, amount decimal.Decimal, description string) (*FederationVoucher, error) {
+	return ws.SendFederatedTransferContext(context.Background(), fromUserID, targetInstance, toUserID, amount, description)
+}
+
+// SendFederatedTransferContext is SendFederatedTransfer on behalf of a caller's ctx
+func (ws *WalletService) SendFederatedTransferContext(ctx context.Context, fromUserID, targetInstance, toUserID string, amount decimal.Decimal, description string) (*FederationVoucher, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cfg := ws.federation.cfg
+	if cfg == nil {
+		return nil, ErrFederationDisabled
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	key, known := cfg.PeerKeys[targetInstance]
+	if !known || targetInstance == cfg.InstanceID {
+		return nil, ErrUnknownPeer
+	}
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[fromUserID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	if err := ws.checkAmount(wallet.Currency, amount, true); err != nil {
+		return nil, err
+	}
+
+	now := ws.now()
+	voucher := FederationVoucher{
+		ID:             ws.newID("fedv"),
+		SourceInstance: cfg.InstanceID,
+		TargetInstance: targetInstance,
+		FromUserID:     fromUserID,
+		ToUserID:       toUserID,
+		Amount:         amount,
+		Description:    description,
+		CreatedAt:      now.Unix(),
+		ExpiresAt:      now.Add(cfg.VoucherTTL).Unix(),
+	}
+
+	debit := &Transaction{
+		FromUserID:  fromUserID,
+		ToUserID:    FederatedAddress(toUserID, targetInstance),
+		Amount:      amount,
+		Type:        TransactionFederationOut,
+		Description: description,
+	}
+	if err := ws.postDebit(ctx, debit); err != nil {
+		return nil, err
+	}
+
+	voucher.Currency = debit.Currency
+	voucher.Signature = signFederation(key, voucher.payload())
+
+	ws.federation.mu.Lock()
+	ws.federation.outbound[voucher.ID] = &OutboundTransfer{
+		Voucher:   voucher,
+		Status:    OutboundPending,
+		DebitTxID: debit.ID,
+		UpdatedAt: now.Unix(),
+	}
+	ws.federation.mu.Unlock()
+
+	return &voucher, nil
+}
+
+// ReceiveFederatedTransfer verifies a voucher from a peer and credits the local recipient.
+// Redelivering the same voucher returns the original receipt. Vouchers that cannot be
+// honoured (expired, unknown recipient) produce a rejected receipt so the sender can refund.
+func (ws *WalletService) ReceiveFederatedTransfer(voucher FederationVoucher) (*FederationReceipt, error) {
+	return ws.ReceiveFederatedTransferContext(context.Background(), voucher)
+}
+
+// ReceiveFederatedTransferContext is ReceiveFederatedTransfer on behalf of a caller's ctx
+func (ws *WalletService) ReceiveFederatedTransferContext(ctx context.Context, voucher FederationVoucher) (*FederationReceipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cfg := ws.federation.cfg
+	if cfg == nil {
+		return nil, ErrFederationDisabled
+	}
+	if voucher.TargetInstance != cfg.InstanceID {
+		return nil, ErrWrongInstance
+	}
+	key, known := cfg.PeerKeys[voucher.SourceInstance]
+	if !known {
+		return nil, ErrUnknownPeer
+	}
+	if !verifyFederation(key, voucher.payload(), voucher.Signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	ws.federation.mu.Lock()
+	defer ws.federation.mu.Unlock()
+
+	if receipt, seen := ws.federation.inbound[voucher.ID]; seen {
+		copied := *receipt
+		return &copied, nil
+	}
+
+	receipt := &FederationReceipt{
+		VoucherID:      voucher.ID,
+		SourceInstance: cfg.InstanceID,
+		TargetInstance: voucher.SourceInstance,
+		Status:         ReceiptAccepted,
+		Timestamp:      ws.now().Unix(),
+	}
+
+	if ws.now().Unix() >= voucher.ExpiresAt {
+		receipt.Status, receipt.Reason = ReceiptRejected, ErrVoucherExpired.Error()
+	} else if voucher.Amount.LessThanOrEqual(decimal.Zero) {
+		receipt.Status, receipt.Reason = ReceiptRejected, ErrInvalidAmount.Error()
+	} else {
+		credit := &Transaction{
+			FromUserID:  FederatedAddress(voucher.FromUserID, voucher.SourceInstance),
+			ToUserID:    voucher.ToUserID,
+			Amount:      voucher.Amount,
+			Currency:    voucher.Currency,
+			Type:        TransactionFederationIn,
+			Description: voucher.Description,
+		}
+		if err := ws.postCredit(ctx, credit); err != nil {
+			receipt.Status, receipt.Reason = ReceiptRejected, err.Error()
+		} else {
+			receipt.TransactionID = credit.ID
+		}
+	}
+
+	receipt.Signature = signFederation(key, receipt.payload())
+	ws.federation.inbound[voucher.ID] = receipt
+
+	copied := *receipt
+	return &copied, nil
+}
+
+// ApplyFederationReceipt settles or refunds an outbound transfer based on the peer's receipt.
+// Applying a receipt for an already finalised transfer is a no-op.
+func (ws *WalletService) ApplyFederationReceipt(receipt FederationReceipt) (*OutboundTransfer, error) {
+	return ws.ApplyFederationReceiptContext(context.Background(), receipt)
+}
+
+// ApplyFederationReceiptContext is ApplyFederationReceipt on behalf of a caller's ctx
+func (ws *WalletService) ApplyFederationReceiptContext(ctx context.Context, receipt FederationReceipt) (*OutboundTransfer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cfg := ws.federation.cfg
+	if cfg == nil {
+		return nil, ErrFederationDisabled
+	}
+	if receipt.TargetInstance != cfg.InstanceID {
+		return nil, ErrWrongInstance
+	}
+	key, known := cfg.PeerKeys[receipt.SourceInstance]
+	if !known {
+		return nil, ErrUnknownPeer
+	}
+	if !verifyFederation(key, receipt.payload(), receipt.Signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	ws.federation.mu.Lock()
+	defer ws.federation.mu.Unlock()
+
+	transfer, exists := ws.federation.outbound[receipt.VoucherID]
+	if !exists || transfer.Voucher.TargetInstance != receipt.SourceInstance {
+		return nil, ErrVoucherNotFound
+	}
+
+	if transfer.Status == OutboundPending {
+		switch receipt.Status {
+		case ReceiptAccepted:
+			transfer.Status = OutboundSettled
+		case ReceiptRejected:
+			refund := &Transaction{
+				FromUserID:  FederatedAddress(transfer.Voucher.ToUserID, transfer.Voucher.TargetInstance),
+				ToUserID:    transfer.Voucher.FromUserID,
+				Amount:      transfer.Voucher.Amount,
+				Currency:    transfer.Voucher.Currency,
+				Type:        TransactionFederationRefund,
+				Description: "refund of " + transfer.Voucher.ID,
+				ParentTxID:  transfer.DebitTxID,
+			}
+			if err := ws.postCredit(ctx, refund); err != nil {
+				return nil, err
+			}
+			transfer.Status = OutboundRefunded
+			transfer.RefundTxID = refund.ID
+		}
+		transfer.Reason = receipt.Reason
+		transfer.UpdatedAt = ws.now().Unix()
+	}
+
+	copied := *transfer
+	return &copied, nil
+}
+
+// GetFederatedTransfer returns the state of an outbound transfer by voucher ID
+func (ws *WalletService) GetFederatedTransfer(voucherID string) (*OutboundTransfer, error) {
+	ws.federation.mu.Lock()
+	defer ws.federation.mu.Unlock()
+
+	transfer, exists := ws.federation.outbound[voucherID]
+	if !exists {
+		return nil, ErrVoucherNotFound
+	}
+
+	copied := *transfer
+	return &copied, nil
+}
+
+// PendingFederatedTransfers lists outbound transfers still awaiting a receipt, oldest first.
+// Reconciliation redelivers their vouchers; once expired the peer answers with a rejection
+// and the sender is refunded.
+func (ws *WalletService) PendingFederatedTransfers() []OutboundTransfer {
+	ws.federation.mu.Lock()
+	defer ws.federation.mu.Unlock()
+
+	var pending []OutboundTransfer
+	for _, transfer := range ws.federation.outbound {
+		if transfer.Status == OutboundPending {
+			pending = append(pending, *transfer)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Voucher.CreatedAt < pending[j].Voucher.CreatedAt
+	})
+
+	return pending
+}
+
+// payload returns the canonical byte string covered by the voucher signature
+func (v FederationVoucher) payload() []byte {
+	return canonicalFields(v.ID, v.SourceInstance, v.TargetInstance, v.FromUserID, v.ToUserID,
+		v.Amount.String(), v.Currency, v.Description,
+		fmt.Sprint(v.CreatedAt), fmt.Sprint(v.ExpiresAt))
+}
+
+// payload returns the canonical byte string covered by the receipt signature
+func (r FederationReceipt) payload() []byte {
+	return canonicalFields(r.VoucherID, r.SourceInstance, r.TargetInstance, string(r.Status),
+		r.Reason, r.TransactionID, fmt.Sprint(r.Timestamp))
+}
+
+// canonicalFields length-prefixes each field so that no two field lists encode alike
+func canonicalFields(fields ...string) []byte {
+	var sb strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&sb, "%d:%s;", len(f), f)
+	}
+	return []byte(sb.String())
+}
+
+// signFederation computes the hex HMAC-SHA256 of payload
+func signFederation(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyFederation checks a hex HMAC-SHA256 signature in constant time
+func verifyFederation(key, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,135 @@
+// internal/wallet/federation_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// newFederatedPair creates two instances that trust each other
+func newFederatedPair(clock *fakeClock) (*WalletService, *WalletService) {
+	key := []byte("shared-secret")
+	alpha := NewWalletService(WithClock(clock.Now), WithFederation(FederationConfig{
+		InstanceID: "alpha",
+		PeerKeys:   map[string][]byte{"beta": key},
+		VoucherTTL: time.Hour,
+	}))
+	beta := NewWalletService(WithClock(clock.Now), WithFederation(FederationConfig{
+		InstanceID: "beta",
+		PeerKeys:   map[string][]byte{"alpha": key},
+		VoucherTTL: time.Hour,
+	}))
+	alpha.CreateUser("alice", "Alice", "alice@example.com")
+	beta.CreateUser("bob", "Bob", "bob@example.com")
+	alpha.Deposit("alice", 100.0, "deposit")
+	return alpha, beta
+}
+
+// TestFederation_TransferSettles tests the happy path across two instances
+func TestFederation_TransferSettles(t *testing.T) {
+	alpha, beta := newFederatedPair(newFakeClock())
+
+	voucher, err := alpha.SendFederatedTransfer("alice", "beta", "bob", decimal.NewFromInt(40), "cross-instance")
+	if err != nil {
+		t.Fatalf("SendFederatedTransfer() error = %v", err)
+	}
+
+	receipt, err := beta.ReceiveFederatedTransfer(*voucher)
+	if err != nil {
+		t.Fatalf("ReceiveFederatedTransfer() error = %v", err)
+	}
+	if receipt.Status != ReceiptAccepted {
+		t.Fatalf("Expected accepted receipt, got %+v", receipt)
+	}
+
+	// Redelivery must not credit twice
+	again, err := beta.ReceiveFederatedTransfer(*voucher)
+	if err != nil || again.TransactionID != receipt.TransactionID {
+		t.Errorf("Expected idempotent redelivery, got %+v, %v", again, err)
+	}
+
+	transfer, err := alpha.ApplyFederationReceipt(*receipt)
+	if err != nil || transfer.Status != OutboundSettled {
+		t.Fatalf("ApplyFederationReceipt() = %+v, %v", transfer, err)
+	}
+
+	aliceBalance, _ := alpha.GetBalanceDecimal("alice")
+	bobBalance, _ := beta.GetBalanceDecimal("bob")
+	if !aliceBalance.Equal(decimal.NewFromInt(60)) || !bobBalance.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("Expected 60/40, got %s/%s", aliceBalance.String(), bobBalance.String())
+	}
+	if len(alpha.PendingFederatedTransfers()) != 0 {
+		t.Error("Expected no pending transfers after settlement")
+	}
+
+	for _, ws := range []*WalletService{alpha, beta} {
+		for _, u := range ws.GetAllUsers() {
+			if mismatch, _ := ws.CheckWalletIntegrity(u.ID); mismatch != nil {
+				t.Errorf("Ledger mismatch for %s: %+v", u.ID, mismatch)
+			}
+		}
+	}
+}
+
+// TestFederation_RejectedTransferRefunds tests refunds for unknown recipients and expired vouchers
+func TestFederation_RejectedTransferRefunds(t *testing.T) {
+	clock := newFakeClock()
+	alpha, beta := newFederatedPair(clock)
+
+	voucher, _ := alpha.SendFederatedTransfer("alice", "beta", "nobody", decimal.NewFromInt(25), "typo")
+	receipt, err := beta.ReceiveFederatedTransfer(*voucher)
+	if err != nil || receipt.Status != ReceiptRejected {
+		t.Fatalf("Expected rejected receipt, got %+v, %v", receipt, err)
+	}
+
+	transfer, err := alpha.ApplyFederationReceipt(*receipt)
+	if err != nil || transfer.Status != OutboundRefunded || transfer.RefundTxID == "" {
+		t.Fatalf("Expected refund, got %+v, %v", transfer, err)
+	}
+
+	// A lost voucher is redelivered during reconciliation after expiry and rejected
+	lost, _ := alpha.SendFederatedTransfer("alice", "beta", "bob", decimal.NewFromInt(30), "lost")
+	clock.Advance(2 * time.Hour)
+
+	pending := alpha.PendingFederatedTransfers()
+	if len(pending) != 1 || pending[0].Voucher.ID != lost.ID {
+		t.Fatalf("Expected the lost voucher to be pending, got %+v", pending)
+	}
+	late, _ := beta.ReceiveFederatedTransfer(pending[0].Voucher)
+	if late.Status != ReceiptRejected || late.Reason != ErrVoucherExpired.Error() {
+		t.Errorf("Expected expiry rejection, got %+v", late)
+	}
+	alpha.ApplyFederationReceipt(*late)
+
+	balance, _ := alpha.GetBalanceDecimal("alice")
+	if !balance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected full refund to 100, got %s", balance.String())
+	}
+}
+
+// TestFederation_Verification tests that tampered or misaddressed messages are refused
+func TestFederation_Verification(t *testing.T) {
+	alpha, beta := newFederatedPair(newFakeClock())
+
+	voucher, _ := alpha.SendFederatedTransfer("alice", "beta", "bob", decimal.NewFromInt(10), "pay")
+
+	tampered := *voucher
+	tampered.Amount = decimal.NewFromInt(1000)
+	if _, err := beta.ReceiveFederatedTransfer(tampered); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+	if _, err := alpha.ReceiveFederatedTransfer(*voucher); err != ErrWrongInstance {
+		t.Errorf("Expected ErrWrongInstance, got %v", err)
+	}
+	if _, err := alpha.SendFederatedTransfer("alice", "gamma", "bob", decimal.NewFromInt(1), "pay"); err != ErrUnknownPeer {
+		t.Errorf("Expected ErrUnknownPeer, got %v", err)
+	}
+	if _, err := alpha.SendFederatedTransfer("alice", "beta", "bob", decimal.NewFromInt(1000), "pay"); err != ErrInsufficientBalance {
+		t.Errorf("Expected ErrInsufficientBalance, got %v", err)
+	}
+	if _, err := NewWalletService().SendFederatedTransfer("alice", "beta", "bob", decimal.NewFromInt(1), "pay"); err != ErrFederationDisabled {
+		t.Errorf("Expected ErrFederationDisabled, got %v", err)
+	}
+}
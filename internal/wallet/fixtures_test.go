@@ -0,0 +1,23 @@
+// internal/wallet/fixtures_test.go
+package wallet
+
+import "time"
+
+// fakeClock is a manually advanced time source for tests
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestService returns a service running on a fake clock, with opts applied on top.
+// Tests create their own users and balances.
+func newTestService(opts ...Option) (*WalletService, *fakeClock) {
+	clock := newFakeClock()
+	return NewWalletService(append([]Option{WithClock(clock.Now)}, opts...)...), clock
+}
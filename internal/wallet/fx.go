@@ -0,0 +1,265 @@
+// internal/wallet/fx.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for currency conversion
+var (
+	ErrRateUnavailable = errors.New("exchange rate unavailable")
+	ErrQuoteNotFound   = errors.New("quote not found")
+	ErrQuoteExpired    = errors.New("quote expired")
+	ErrQuoteUsed       = errors.New("quote already used")
+)
+
+// DefaultQuoteTTL is how long a conversion quote's rate stays locked
+const DefaultQuoteTTL = 30 * time.Second
+
+// RateProvider supplies exchange rates: one unit of from buys Rate units of to
+type RateProvider interface {
+	Rate(from, to string) (decimal.Decimal, error)
+}
+
+// StaticRateProvider serves fixed rates keyed by "FROM/TO"
+type StaticRateProvider map[string]decimal.Decimal
+
+// Rate returns the configured rate, deriving the inverse when only the opposite pair is set
+func (p StaticRateProvider) Rate(from, to string) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if rate, ok := p[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := p[to+"/"+from]; ok && !rate.IsZero() {
+		return decimal.NewFromInt(1).DivRound(rate, 16), nil
+	}
+	return decimal.Zero, ErrRateUnavailable
+}
+
+// FXQuote is a conversion priced at a rate that is locked until ExpiresAt
+type FXQuote struct {
+	ID           string
+	UserID       string
+	FromCurrency string
+	ToCurrency   string
+	FromAmount   decimal.Decimal
+	ToAmount     decimal.Decimal
+	Rate         decimal.Decimal
+	RateID       string // record of Rate in the rate history
+	CreatedAt    int64
+	ExpiresAt    int64
+	Used         bool
+}
+
+// fxDesk holds outstanding conversion quotes
+type fxDesk struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	quotes map[string]*FXQuote
+}
+
+func newFXDesk() fxDesk {
+	return fxDesk{
+		ttl:    DefaultQuoteTTL,
+		quotes: make(map[string]*FXQuote),
+	}
+}
+
+// WithRateProvider sets the source of exchange rates used for conversions
+func WithRateProvider(p RateProvider) Option {
+	return func(ws *WalletService) {
+		ws.rates = p
+	}
+}
+
+// WithQuoteTTL sets how long conversion quotes remain executable
+func WithQuoteTTL(ttl time.Duration) Option {
+	return func(ws *WalletService) {
+		ws.fx.ttl = ttl
+	}
+}
+
+// QuoteConversion prices converting amount of from into to for a user and locks the rate
+// for the configured TTL. The quote can be executed once with ConvertWithQuote. Quotes
+// that expired unused are dropped as new ones are issued.
+func (ws *WalletService) QuoteConversion(userID, from, to string, amount decimal.Decimal) (*FXQuote, error) {
+	return ws.QuoteConversionContext(context.Background(), userID, from, to, amount)
+}
+
+// QuoteConversionContext is QuoteConversion on behalf of a caller's ctx
+func (ws *WalletService) QuoteConversionContext(ctx context.Context, userID, from, to string, amount decimal.Decimal) (*FXQuote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+	if from == "" || to == "" || from == to {
+		return nil, ErrInvalidCurrency
+	}
+	rate, err := ws.settlementRate(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.checkAmount(from, amount, false); err != nil {
+		return nil, err
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	now := ws.now()
+	quote := &FXQuote{
+		ID:           ws.newID("quote"),
+		UserID:       userID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		FromAmount:   amount,
+		ToAmount:     amount.Mul(rate).Round(ws.currencyPrecision(to)),
+		Rate:         rate,
+		RateID:       ws.observeRate(from, to, rate),
+		CreatedAt:    now.Unix(),
+		ExpiresAt:    now.Add(ws.fx.ttl).Unix(),
+	}
+
+	ws.fx.mu.Lock()
+	for id, q := range ws.fx.quotes {
+		if !q.Used && now.Unix() >= q.ExpiresAt {
+			delete(ws.fx.quotes, id)
+		}
+	}
+	ws.fx.quotes[quote.ID] = quote
+	ws.fx.mu.Unlock()
+
+	copied := *quote
+	return &copied, nil
+}
+
+// ConvertWithQuote executes a previously issued quote at exactly its locked rate.
+// It fails if the quote has expired or is being executed. An executed quote is
+// discarded, so executing it again fails with ErrQuoteNotFound.
+func (ws *WalletService) ConvertWithQuote(quoteID string) (*Transaction, error) {
+	return ws.ConvertWithQuoteContext(context.Background(), quoteID)
+}
+
+// ConvertWithQuoteContext is ConvertWithQuote on behalf of a caller's ctx
+func (ws *WalletService) ConvertWithQuoteContext(ctx context.Context, quoteID string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	quote, err := ws.claimQuote(quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ws.executeConversion(ctx, quote)
+	ws.fx.mu.Lock()
+	if err != nil {
+		// Let the caller retry with the same quote while it is still valid
+		quote.Used = false
+	} else {
+		delete(ws.fx.quotes, quoteID)
+	}
+	ws.fx.mu.Unlock()
+	return tx, err
+}
+
+// claimQuote marks a quote as used, validating that it exists and is still live
+func (ws *WalletService) claimQuote(quoteID string) (*FXQuote, error) {
+	ws.fx.mu.Lock()
+	defer ws.fx.mu.Unlock()
+
+	quote, exists := ws.fx.quotes[quoteID]
+	if !exists {
+		return nil, ErrQuoteNotFound
+	}
+	if quote.Used {
+		return nil, ErrQuoteUsed
+	}
+	if ws.now().Unix() >= quote.ExpiresAt {
+		delete(ws.fx.quotes, quoteID)
+		return nil, ErrQuoteExpired
+	}
+
+	quote.Used = true
+	return quote, nil
+}
+
+// executeConversion moves funds between a wallet's currency holdings at the quote's rate,
+// within any cap on the bought currency
+func (ws *WalletService) executeConversion(ctx context.Context, quote *FXQuote) (tx *Transaction, err error) {
+	timer := ws.startOp(string(TransactionConversion), quote.UserID)
+	defer func() {
+		timer.finish(err)
+		ws.audit(ctx, string(TransactionConversion), tx, err, quote.UserID)
+	}()
+
+	userLock := ws.userLocks.getLock(quote.UserID)
+	userLock.Lock()
+	defer userLock.Unlock()
+	timer.locked()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[quote.UserID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	tx = &Transaction{
+		ID:          ws.newID("tx"),
+		FromUserID:  quote.UserID,
+		ToUserID:    quote.UserID,
+		Amount:      quote.FromAmount,
+		Currency:    quote.FromCurrency,
+		Type:        TransactionConversion,
+		Description: "conversion " + quote.ID,
+		ToAmount:    quote.ToAmount,
+		ToCurrency:  quote.ToCurrency,
+		Rate:        quote.Rate,
+		Metadata:    map[string]string{"rate_id": quote.RateID},
+	}
+	stampTrace(ctx, tx)
+	if err := ws.validate(tx); err != nil {
+		return nil, err
+	}
+
+	minimum := ws.minimumFor(quote.UserID, quote.FromCurrency, tx.Type)
+	maximum := ws.maximumFor(quote.UserID, quote.ToCurrency, tx.Type)
+	wallet.mu.Lock()
+	if wallet.available(quote.FromCurrency).LessThan(quote.FromAmount) {
+		wallet.mu.Unlock()
+		return nil, ErrInsufficientBalance
+	}
+	if err := wallet.checkMinimum(quote.FromCurrency, quote.FromAmount, decimal.Zero, minimum); err != nil {
+		wallet.mu.Unlock()
+		return nil, err
+	}
+	if err := wallet.checkMaximum(quote.ToCurrency, quote.ToAmount, maximum); err != nil {
+		wallet.mu.Unlock()
+		return nil, err
+	}
+	wallet.adjust(quote.FromCurrency, quote.FromAmount.Neg())
+	wallet.adjust(quote.ToCurrency, quote.ToAmount)
+	wallet.publish()
+	wallet.mu.Unlock()
+
+	tx.Timestamp = ws.now().Unix()
+	ws.recordTransaction(tx)
+
+	return tx, nil
+}
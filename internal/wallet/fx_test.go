@@ -0,0 +1,116 @@
+// internal/wallet/fx_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestWalletService_ConvertWithQuote tests executing a locked-rate conversion
+func TestWalletService_ConvertWithQuote(t *testing.T) {
+	rates := StaticRateProvider{"USD/EUR": decimal.RequireFromString("0.9")}
+	ws, clock := newTestService(WithRateProvider(rates), WithQuoteTTL(10*time.Second))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("user1", 100.0, "deposit")
+
+	quote, err := ws.QuoteConversion("user1", "usd", "eur", decimal.NewFromInt(50))
+	if err != nil {
+		t.Fatalf("QuoteConversion() error = %v", err)
+	}
+	if !quote.ToAmount.Equal(decimal.NewFromInt(45)) {
+		t.Errorf("Expected quoted amount 45, got %s", quote.ToAmount.String())
+	}
+
+	// The market moves, but the quote keeps its rate
+	rates["USD/EUR"] = decimal.RequireFromString("0.5")
+	clock.Advance(5 * time.Second)
+
+	tx, err := ws.ConvertWithQuote(quote.ID)
+	if err != nil {
+		t.Fatalf("ConvertWithQuote() error = %v", err)
+	}
+	if tx.Type != TransactionConversion || !tx.Rate.Equal(decimal.RequireFromString("0.9")) {
+		t.Errorf("Unexpected conversion transaction: %+v", tx)
+	}
+
+	usd, _ := ws.GetCurrencyBalance("user1", "USD")
+	eur, _ := ws.GetCurrencyBalance("user1", "EUR")
+	if !usd.Equal(decimal.NewFromInt(50)) || !eur.Equal(decimal.NewFromInt(45)) {
+		t.Errorf("Expected 50 USD / 45 EUR, got %s USD / %s EUR", usd.String(), eur.String())
+	}
+
+	if _, err := ws.ConvertWithQuote(quote.ID); err != ErrQuoteNotFound {
+		t.Errorf("Expected ErrQuoteNotFound on replay, got %v", err)
+	}
+	if n := len(ws.fx.quotes); n != 0 {
+		t.Errorf("Expected the executed quote to be discarded, %d quotes kept", n)
+	}
+
+	mismatch, err := ws.CheckWalletIntegrity("user1")
+	if err != nil || mismatch != nil {
+		t.Errorf("Expected ledger to match after conversion, got %+v, %v", mismatch, err)
+	}
+}
+
+// TestWalletService_ConvertWithQuote_Errors tests quote expiry and validation failures
+func TestWalletService_ConvertWithQuote_Errors(t *testing.T) {
+	rates := StaticRateProvider{"EUR/USD": decimal.RequireFromString("1.25")}
+	ws, clock := newTestService(WithRateProvider(rates), WithQuoteTTL(10*time.Second))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("user1", 10.0, "deposit")
+
+	// Inverse pair is derived from the configured one
+	quote, err := ws.QuoteConversion("user1", "USD", "EUR", decimal.NewFromInt(10))
+	if err != nil {
+		t.Fatalf("QuoteConversion() error = %v", err)
+	}
+	if !quote.ToAmount.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("Expected quoted amount 8, got %s", quote.ToAmount.String())
+	}
+
+	clock.Advance(10 * time.Second)
+	if _, err := ws.ConvertWithQuote(quote.ID); err != ErrQuoteExpired {
+		t.Errorf("Expected ErrQuoteExpired, got %v", err)
+	}
+
+	big, _ := ws.QuoteConversion("user1", "USD", "EUR", decimal.NewFromInt(20))
+	if _, err := ws.ConvertWithQuote(big.ID); err != ErrInsufficientBalance {
+		t.Errorf("Expected ErrInsufficientBalance, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		from    string
+		to      string
+		amount  decimal.Decimal
+		wantErr error
+	}{
+		{"unknown user", "nonexistent", "USD", "EUR", decimal.NewFromInt(1), ErrUserNotFound},
+		{"same currency", "user1", "USD", "usd", decimal.NewFromInt(1), ErrInvalidCurrency},
+		{"zero amount", "user1", "USD", "EUR", decimal.Zero, ErrInvalidAmount},
+		{"unknown pair", "user1", "USD", "JPY", decimal.NewFromInt(1), ErrRateUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.QuoteConversion(tt.userID, tt.from, tt.to, tt.amount); err != tt.wantErr {
+				t.Errorf("QuoteConversion() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ws.ConvertWithQuote("missing"); err != ErrQuoteNotFound {
+		t.Errorf("Expected ErrQuoteNotFound, got %v", err)
+	}
+
+	// Quotes left to expire, including one whose conversion failed, are swept when the
+	// next one is issued
+	ws.QuoteConversion("user1", "USD", "EUR", decimal.NewFromInt(5))
+	clock.Advance(10 * time.Second)
+	fresh, _ := ws.QuoteConversion("user1", "USD", "EUR", decimal.NewFromInt(5))
+	if _, kept := ws.fx.quotes[fresh.ID]; !kept || len(ws.fx.quotes) != 1 {
+		t.Errorf("Expected only the fresh quote to be kept, got %d quotes", len(ws.fx.quotes))
+	}
+}
@@ -0,0 +1,315 @@
+// internal/wallet/fxorders.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for standing conversion orders
+var (
+	ErrConversionOrderNotFound = errors.New("conversion order not found")
+	ErrConversionOrderInactive = errors.New("conversion order is cancelled")
+)
+
+// ConversionOrderStatus is the lifecycle state of a standing conversion order
+type ConversionOrderStatus string
+
+const (
+	ConversionOrderActive    ConversionOrderStatus = "active"
+	ConversionOrderPaused    ConversionOrderStatus = "paused"
+	ConversionOrderCancelled ConversionOrderStatus = "cancelled"
+)
+
+// ConversionExecution is one scheduled run of a conversion order
+type ConversionExecution struct {
+	At            int64
+	TransactionID string // empty when skipped or failed
+	FromAmount    decimal.Decimal
+	ToAmount      decimal.Decimal
+	Rate          decimal.Decimal
+	RateID        string // record of Rate in the rate history
+	Skipped       string // why the run was skipped: "skip" or "paused"
+	Error         string
+}
+
+// ConversionOrder converts a fixed amount between two of a user's currency holdings on
+// a schedule, e.g. 100 USD to EUR every Friday, buying at whatever rate each run gets
+type ConversionOrder struct {
+	ID           string
+	UserID       string
+	FromCurrency string
+	ToCurrency   string
+	Amount       decimal.Decimal // in FromCurrency, per run
+	Status       ConversionOrderStatus
+	JobID        string
+	CreatedAt    int64
+	SkipNext     bool
+	Executions   []ConversionExecution
+}
+
+// ConversionOrderSummary aggregates the executed runs of an order. AverageRate is
+// weighted by volume: total received over total converted.
+type ConversionOrderSummary struct {
+	OrderID     string
+	Executed    int
+	Skipped     int
+	Failed      int
+	TotalFrom   decimal.Decimal
+	TotalTo     decimal.Decimal
+	AverageRate decimal.Decimal
+	BestRate    decimal.Decimal
+	WorstRate   decimal.Decimal
+	FirstAt     int64
+	LastAt      int64
+}
+
+// conversionOrderBook holds standing conversion orders
+type conversionOrderBook struct {
+	mu     sync.Mutex
+	orders map[string]*ConversionOrder
+}
+
+// CreateConversionOrder schedules converting amount of from into to for userID at the
+// given time and then per recurrence. Each run takes a fresh quote, so the rate is
+// captured in the rate history per execution; runs falling on non-business days roll
+// per the service's business calendar.
+func (ws *WalletService) CreateConversionOrder(userID, from, to string, amount decimal.Decimal, at time.Time, recurrence Recurrence) (*ConversionOrder, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+	if from == "" || to == "" || from == to {
+		return nil, ErrInvalidCurrency
+	}
+	if _, err := ws.settlementRate(from, to); err != nil {
+		return nil, err
+	}
+	if err := ws.checkAmount(from, amount, false); err != nil {
+		return nil, err
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	order := &ConversionOrder{
+		ID:           ws.newID("fxo"),
+		UserID:       userID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Amount:       amount,
+		Status:       ConversionOrderActive,
+		CreatedAt:    ws.now().Unix(),
+	}
+	ws.fxOrders.mu.Lock()
+	if ws.fxOrders.orders == nil {
+		ws.fxOrders.orders = make(map[string]*ConversionOrder)
+	}
+	ws.fxOrders.orders[order.ID] = order
+	ws.fxOrders.mu.Unlock()
+
+	jobID := ws.schedulePayment("conversion_order", userID, at, recurrence, func(now time.Time) error {
+		return ws.runConversionOrder(order.ID, now)
+	})
+
+	ws.fxOrders.mu.Lock()
+	order.JobID = jobID
+	copied := order.copy()
+	ws.fxOrders.mu.Unlock()
+	return copied, nil
+}
+
+// runConversionOrder executes one run of an order. Failed runs are recorded and leave
+// the order active for its next run.
+func (ws *WalletService) runConversionOrder(orderID string, now time.Time) error {
+	ws.fxOrders.mu.Lock()
+	order := ws.fxOrders.orders[orderID]
+	exec := ConversionExecution{At: now.Unix(), FromAmount: order.Amount}
+	switch {
+	case order.Status == ConversionOrderCancelled:
+		ws.fxOrders.mu.Unlock()
+		return nil
+	case order.Status == ConversionOrderPaused:
+		exec.Skipped = "paused"
+	case order.SkipNext:
+		exec.Skipped = "skip"
+		order.SkipNext = false
+	}
+	if exec.Skipped != "" {
+		order.Executions = append(order.Executions, exec)
+		ws.fxOrders.mu.Unlock()
+		return nil
+	}
+	userID, from, to, amount := order.UserID, order.FromCurrency, order.ToCurrency, order.Amount
+	ws.fxOrders.mu.Unlock()
+
+	tx, err := ws.convertAtMarket(userID, from, to, amount)
+	outcome := "executed"
+	if err == nil {
+		exec.TransactionID = tx.ID
+		exec.ToAmount = tx.ToAmount
+		exec.Rate = tx.Rate
+		exec.RateID = tx.Metadata["rate_id"]
+	} else {
+		exec.Error = err.Error()
+		outcome = "failed"
+	}
+
+	ws.fxOrders.mu.Lock()
+	order.Executions = append(order.Executions, exec)
+	ws.fxOrders.mu.Unlock()
+	ws.metrics.IncCounter("conversion_order_runs_total", map[string]string{"outcome": outcome})
+	return err
+}
+
+// convertAtMarket quotes and immediately executes a conversion
+func (ws *WalletService) convertAtMarket(userID, from, to string, amount decimal.Decimal) (*Transaction, error) {
+	quote, err := ws.QuoteConversion(userID, from, to, amount)
+	if err != nil {
+		return nil, err
+	}
+	return ws.ConvertWithQuote(quote.ID)
+}
+
+// SkipNextConversion skips the order's next run only
+func (ws *WalletService) SkipNextConversion(orderID, userID string) error {
+	return ws.updateConversionOrder(orderID, userID, func(o *ConversionOrder) { o.SkipNext = true })
+}
+
+// PauseConversionOrder skips every run until the order is resumed
+func (ws *WalletService) PauseConversionOrder(orderID, userID string) error {
+	return ws.updateConversionOrder(orderID, userID, func(o *ConversionOrder) { o.Status = ConversionOrderPaused })
+}
+
+// ResumeConversionOrder re-enables a paused order from its next run
+func (ws *WalletService) ResumeConversionOrder(orderID, userID string) error {
+	return ws.updateConversionOrder(orderID, userID, func(o *ConversionOrder) { o.Status = ConversionOrderActive })
+}
+
+// CancelConversionOrder permanently stops an order
+func (ws *WalletService) CancelConversionOrder(orderID, userID string) error {
+	var jobID string
+	err := ws.updateConversionOrder(orderID, userID, func(o *ConversionOrder) {
+		o.Status = ConversionOrderCancelled
+		jobID = o.JobID
+	})
+	if err == nil {
+		ws.CancelJob(jobID)
+	}
+	return err
+}
+
+// updateConversionOrder applies fn to one of userID's live orders
+func (ws *WalletService) updateConversionOrder(orderID, userID string, fn func(o *ConversionOrder)) error {
+	ws.fxOrders.mu.Lock()
+	defer ws.fxOrders.mu.Unlock()
+
+	order, exists := ws.fxOrders.orders[orderID]
+	if !exists || order.UserID != userID {
+		return ErrConversionOrderNotFound
+	}
+	if order.Status == ConversionOrderCancelled {
+		return ErrConversionOrderInactive
+	}
+	fn(order)
+	return nil
+}
+
+// GetConversionOrder returns an order with its execution history
+func (ws *WalletService) GetConversionOrder(orderID string) (*ConversionOrder, error) {
+	ws.fxOrders.mu.Lock()
+	defer ws.fxOrders.mu.Unlock()
+
+	order, exists := ws.fxOrders.orders[orderID]
+	if !exists {
+		return nil, ErrConversionOrderNotFound
+	}
+	return order.copy(), nil
+}
+
+// ListConversionOrders returns userID's orders, oldest first
+func (ws *WalletService) ListConversionOrders(userID string) []ConversionOrder {
+	ws.fxOrders.mu.Lock()
+	defer ws.fxOrders.mu.Unlock()
+
+	var list []ConversionOrder
+	for _, o := range ws.fxOrders.orders {
+		if o.UserID == userID {
+			list = append(list, *o.copy())
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt != list[j].CreatedAt {
+			return list[i].CreatedAt < list[j].CreatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// SummarizeConversionOrder aggregates an order's runs between since and until; zero
+// times leave the range open
+func (ws *WalletService) SummarizeConversionOrder(orderID string, since, until time.Time) (*ConversionOrderSummary, error) {
+	order, err := ws.GetConversionOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ConversionOrderSummary{OrderID: orderID}
+	for _, e := range order.Executions {
+		if (!since.IsZero() && e.At < since.Unix()) || (!until.IsZero() && e.At >= until.Unix()) {
+			continue
+		}
+		switch {
+		case e.Skipped != "":
+			s.Skipped++
+			continue
+		case e.Error != "":
+			s.Failed++
+			continue
+		}
+		if s.Executed == 0 || e.Rate.GreaterThan(s.BestRate) {
+			s.BestRate = e.Rate
+		}
+		if s.Executed == 0 || e.Rate.LessThan(s.WorstRate) {
+			s.WorstRate = e.Rate
+		}
+		if s.Executed == 0 {
+			s.FirstAt = e.At
+		}
+		s.LastAt = e.At
+		s.Executed++
+		s.TotalFrom = s.TotalFrom.Add(e.FromAmount)
+		s.TotalTo = s.TotalTo.Add(e.ToAmount)
+	}
+	if s.TotalFrom.IsPositive() {
+		s.AverageRate = s.TotalTo.DivRound(s.TotalFrom, 8)
+	}
+	return s, nil
+}
+
+// cancelConversionOrders cancels every live order of userID and returns how many
+func (ws *WalletService) cancelConversionOrders(userID string) int {
+	stopped := 0
+	for _, o := range ws.ListConversionOrders(userID) {
+		if o.Status != ConversionOrderCancelled && ws.CancelConversionOrder(o.ID, userID) == nil {
+			stopped++
+		}
+	}
+	return stopped
+}
+
+// copy returns a deep copy of the order. Caller must hold ws.fxOrders.mu.
+func (o *ConversionOrder) copy() *ConversionOrder {
+	copied := *o
+	copied.Executions = append([]ConversionExecution(nil), o.Executions...)
+	return &copied
+}
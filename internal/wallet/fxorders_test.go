@@ -0,0 +1,113 @@
+// internal/wallet/fxorders_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestConversionOrder(t *testing.T) {
+	rates := StaticRateProvider{}
+	ws, clock := newTestService(WithRateProvider(rates)) // Monday 2024-01-01
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 250, "salary")
+
+	rates["USD/EUR"] = decimal.RequireFromString("0.90")
+	friday := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+	order, err := ws.CreateConversionOrder("alice", "usd", "eur", decimal.NewFromInt(100), friday, Weekly())
+	if err != nil {
+		t.Fatalf("CreateConversionOrder() error = %v", err)
+	}
+
+	weeks := []struct {
+		name    string
+		rate    string
+		before  func()
+		wantRun ConversionExecution
+	}{
+		{"first friday", "0.90", nil, ConversionExecution{ToAmount: decimal.NewFromInt(90)}},
+		{"skipped once", "0.95", func() { ws.SkipNextConversion(order.ID, "alice") }, ConversionExecution{Skipped: "skip"}},
+		{"back on schedule", "0.92", nil, ConversionExecution{ToAmount: decimal.NewFromInt(92)}},
+		{"paused", "0.99", func() { ws.PauseConversionOrder(order.ID, "alice") }, ConversionExecution{Skipped: "paused"}},
+		{"resumed without funds", "0.91", func() { ws.ResumeConversionOrder(order.ID, "alice") }, ConversionExecution{Error: ErrInsufficientBalance.Error()}},
+	}
+	for i, w := range weeks {
+		t.Run(w.name, func(t *testing.T) {
+			rates["USD/EUR"] = decimal.RequireFromString(w.rate)
+			if w.before != nil {
+				w.before()
+			}
+			clock.t = friday.AddDate(0, 0, 7*i)
+			ws.RunDueJobs()
+
+			got, _ := ws.GetConversionOrder(order.ID)
+			if len(got.Executions) != i+1 {
+				t.Fatalf("executions = %+v, want %d", got.Executions, i+1)
+			}
+			run := got.Executions[i]
+			if run.Skipped != w.wantRun.Skipped || run.Error != w.wantRun.Error || !run.ToAmount.Equal(w.wantRun.ToAmount) {
+				t.Errorf("run = %+v, want %+v", run, w.wantRun)
+			}
+			if run.TransactionID != "" && (run.RateID == "" || !run.Rate.Equal(decimal.RequireFromString(w.rate))) {
+				t.Errorf("rate capture = %s %q, want %s", run.Rate, run.RateID, w.rate)
+			}
+		})
+	}
+
+	summary, err := ws.SummarizeConversionOrder(order.ID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("SummarizeConversionOrder() error = %v", err)
+	}
+	if summary.Executed != 2 || summary.Skipped != 2 || summary.Failed != 1 {
+		t.Errorf("summary counts = %+v", summary)
+	}
+	if !summary.AverageRate.Equal(decimal.RequireFromString("0.91")) || !summary.BestRate.Equal(decimal.RequireFromString("0.92")) ||
+		!summary.WorstRate.Equal(decimal.RequireFromString("0.9")) || !summary.TotalTo.Equal(decimal.NewFromInt(182)) {
+		t.Errorf("summary rates = %+v", summary)
+	}
+	recent, _ := ws.SummarizeConversionOrder(order.ID, friday.AddDate(0, 0, 7), time.Time{})
+	if recent.Executed != 1 || !recent.AverageRate.Equal(decimal.RequireFromString("0.92")) {
+		t.Errorf("summary since week 2 = %+v", recent)
+	}
+
+	if err := ws.CancelConversionOrder(order.ID, "bob"); err != ErrConversionOrderNotFound {
+		t.Errorf("CancelConversionOrder(other user) error = %v, want %v", err, ErrConversionOrderNotFound)
+	}
+	if err := ws.CancelConversionOrder(order.ID, "alice"); err != nil {
+		t.Fatalf("CancelConversionOrder() error = %v", err)
+	}
+	if job, _ := ws.GetJob(order.JobID); job.Status != JobCancelled {
+		t.Errorf("job status = %s, want %s", job.Status, JobCancelled)
+	}
+	if err := ws.PauseConversionOrder(order.ID, "alice"); err != ErrConversionOrderInactive {
+		t.Errorf("PauseConversionOrder(cancelled) error = %v, want %v", err, ErrConversionOrderInactive)
+	}
+}
+
+func TestCreateConversionOrderValidation(t *testing.T) {
+	ws := NewWalletService(WithRateProvider(StaticRateProvider{"USD/EUR": decimal.RequireFromString("0.9")}))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	at := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		userID   string
+		from, to string
+		amount   int64
+		wantErr  error
+	}{
+		{"zero amount", "alice", "USD", "EUR", 0, ErrInvalidAmount},
+		{"same currency", "alice", "USD", "usd", 10, ErrInvalidCurrency},
+		{"no rate", "alice", "USD", "GBP", 10, ErrRateUnavailable},
+		{"unknown user", "ghost", "USD", "EUR", 10, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.CreateConversionOrder(tt.userID, tt.from, tt.to, decimal.NewFromInt(tt.amount), at, Weekly()); err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// internal/wallet/fxrates.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for the rate history
+var (
+	ErrRateRecordNotFound = errors.New("rate record not found")
+	ErrNotConversion      = errors.New("transaction is not a conversion")
+)
+
+// RateSource is implemented by rate providers that can name themselves in the rate
+// history; other providers are recorded by type
+type RateSource interface {
+	Source() string
+}
+
+// RateRecord is one exchange rate the service observed: one unit of From bought Rate
+// units of To according to Source at ObservedAt
+type RateRecord struct {
+	ID         string
+	From       string
+	To         string
+	Rate       decimal.Decimal
+	Source     string
+	ObservedAt int64
+}
+
+// ConversionAudit compares a conversion with the amount re-derived from its stored rate
+type ConversionAudit struct {
+	TransactionID string
+	Rate          RateRecord
+	Recorded      decimal.Decimal // ToAmount on the transaction
+	Derived       decimal.Decimal // Amount at the stored rate, rounded like the original
+	Matches       bool
+}
+
+// rateTable is the append-only history of rates used by the service
+type rateTable struct {
+	mu      sync.RWMutex
+	records []RateRecord
+	byID    map[string]int
+	byPair  map[string][]int // record positions per pair, oldest first
+}
+
+// Source names StaticRateProvider in the rate history
+func (p StaticRateProvider) Source() string {
+	return "static"
+}
+
+// RecordRate adds a rate observed outside a conversion, such as an end-of-day fixing
+// used for revaluation, and returns the stored record
+func (ws *WalletService) RecordRate(from, to string, rate decimal.Decimal, source string) (*RateRecord, error) {
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+	if from == "" || to == "" || from == to {
+		return nil, ErrInvalidCurrency
+	}
+	if rate.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrRateUnavailable
+	}
+	r := ws.fxRates.add(from, to, rate, source, ws.now().Unix(), ws.newID)
+	return &r, nil
+}
+
+// GetRateRecord returns a stored rate by ID
+func (ws *WalletService) GetRateRecord(id string) (*RateRecord, error) {
+	ws.fxRates.mu.RLock()
+	defer ws.fxRates.mu.RUnlock()
+
+	i, exists := ws.fxRates.byID[id]
+	if !exists {
+		return nil, ErrRateRecordNotFound
+	}
+	r := ws.fxRates.records[i]
+	return &r, nil
+}
+
+// RateHistory returns the rates stored for a pair observed in [since, until), oldest
+// first. A zero bound is open.
+func (ws *WalletService) RateHistory(from, to string, since, until time.Time) []RateRecord {
+	ws.fxRates.mu.RLock()
+	defer ws.fxRates.mu.RUnlock()
+
+	var out []RateRecord
+	for _, i := range ws.fxRates.byPair[ratePair(normalizeCurrency(from), normalizeCurrency(to))] {
+		r := ws.fxRates.records[i]
+		if !since.IsZero() && r.ObservedAt < since.Unix() {
+			continue
+		}
+		if !until.IsZero() && r.ObservedAt >= until.Unix() {
+			break
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// RateAt returns the latest rate stored for a pair at or before at
+func (ws *WalletService) RateAt(from, to string, at time.Time) (*RateRecord, error) {
+	ws.fxRates.mu.RLock()
+	defer ws.fxRates.mu.RUnlock()
+
+	positions := ws.fxRates.byPair[ratePair(normalizeCurrency(from), normalizeCurrency(to))]
+	n := sort.Search(len(positions), func(k int) bool {
+		return ws.fxRates.records[positions[k]].ObservedAt > at.Unix()
+	})
+	if n == 0 {
+		return nil, ErrRateRecordNotFound
+	}
+	r := ws.fxRates.records[positions[n-1]]
+	return &r, nil
+}
+
+// AuditConversion re-derives a conversion from the rate it recorded
+func (ws *WalletService) AuditConversion(txID string) (*ConversionAudit, error) {
+	tx, err := ws.GetTransaction(txID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type != TransactionConversion {
+		return nil, ErrNotConversion
+	}
+	rate, err := ws.GetRateRecord(tx.Metadata["rate_id"])
+	if err != nil {
+		return nil, err
+	}
+
+	derived := tx.Amount.Mul(rate.Rate).Round(ws.currencyPrecision(tx.ToCurrency))
+	return &ConversionAudit{
+		TransactionID: tx.ID,
+		Rate:          *rate,
+		Recorded:      tx.ToAmount,
+		Derived:       derived,
+		Matches:       derived.Equal(tx.ToAmount) && rate.Rate.Equal(tx.Rate),
+	}, nil
+}
+
+// observeRate stores a rate fetched from the provider for a conversion and returns its ID
+func (ws *WalletService) observeRate(from, to string, rate decimal.Decimal) string {
+	source := fmt.Sprintf("%T", ws.rates)
+	if s, ok := ws.rates.(RateSource); ok {
+		source = s.Source()
+	}
+	return ws.fxRates.add(from, to, rate, source, ws.now().Unix(), ws.newID).ID
+}
+
+// add stores a rate, reusing the latest record for the pair when nothing changed. newID
+// names new records.
+func (t *rateTable) add(from, to string, rate decimal.Decimal, source string, at int64, newID func(string) string) RateRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pair := ratePair(from, to)
+	if positions := t.byPair[pair]; len(positions) > 0 {
+		last := t.records[positions[len(positions)-1]]
+		if last.ObservedAt == at && last.Source == source && last.Rate.Equal(rate) {
+			return last
+		}
+	}
+
+	r := RateRecord{ID: newID("rate"), From: from, To: to, Rate: rate, Source: source, ObservedAt: at}
+	t.insert(r)
+	return r
+}
+
+// insert appends r keeping each pair ordered by ObservedAt. Callers hold t.mu.
+func (t *rateTable) insert(r RateRecord) {
+	if t.byID == nil {
+		t.byID = make(map[string]int)
+		t.byPair = make(map[string][]int)
+	}
+	t.records = append(t.records, r)
+	pos := len(t.records) - 1
+	t.byID[r.ID] = pos
+
+	pair := ratePair(r.From, r.To)
+	positions := t.byPair[pair]
+	k := sort.Search(len(positions), func(k int) bool { return t.records[positions[k]].ObservedAt > r.ObservedAt })
+	positions = append(positions, 0)
+	copy(positions[k+1:], positions[k:])
+	positions[k] = pos
+	t.byPair[pair] = positions
+}
+
+// snapshot returns every stored rate in insertion order
+func (t *rateTable) snapshot() []RateRecord {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]RateRecord(nil), t.records...)
+}
+
+// restore replaces the table with records from a snapshot
+func (t *rateTable) restore(records []RateRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.records, t.byID, t.byPair = nil, nil, nil
+	for _, r := range records {
+		t.insert(r)
+	}
+}
+
+// ratePair keys a currency pair
+func ratePair(from, to string) string {
+	return from + "/" + to
+}
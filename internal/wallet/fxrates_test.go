@@ -0,0 +1,120 @@
+// internal/wallet/fxrates_test.go
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRates_ConversionsRecordTheirRate(t *testing.T) {
+	rates := StaticRateProvider{"USD/EUR": decimal.RequireFromString("0.9")}
+	ws, clock := newTestService(WithRateProvider(rates))
+	start := clock.Now()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	convert := func(amount int64) *Transaction {
+		t.Helper()
+		quote, err := ws.QuoteConversion("alice", "USD", "EUR", decimal.NewFromInt(amount))
+		if err != nil {
+			t.Fatalf("QuoteConversion() error = %v", err)
+		}
+		tx, err := ws.ConvertWithQuote(quote.ID)
+		if err != nil {
+			t.Fatalf("ConvertWithQuote() error = %v", err)
+		}
+		return tx
+	}
+
+	first := convert(50)
+	convert(5) // same second and rate: reuses the record
+	clock.Advance(time.Hour)
+	rates["USD/EUR"] = decimal.RequireFromString("0.8")
+	second := convert(10)
+
+	history := ws.RateHistory("usd", "eur", time.Time{}, time.Time{})
+	if len(history) != 2 || !history[0].Rate.Equal(decimal.RequireFromString("0.9")) || history[1].Source != "static" {
+		t.Fatalf("RateHistory() = %+v, want two static records", history)
+	}
+	if first.Metadata["rate_id"] != history[0].ID || second.Metadata["rate_id"] != history[1].ID {
+		t.Errorf("conversions reference %s and %s, want %s and %s",
+			first.Metadata["rate_id"], second.Metadata["rate_id"], history[0].ID, history[1].ID)
+	}
+	if later := ws.RateHistory("USD", "EUR", start.Add(time.Minute), time.Time{}); len(later) != 1 {
+		t.Errorf("RateHistory() since +1m = %+v, want the second rate only", later)
+	}
+
+	tests := []struct {
+		at      time.Time
+		want    string
+		wantErr error
+	}{
+		{start.Add(-time.Second), "", ErrRateRecordNotFound},
+		{start, "0.9", nil},
+		{start.Add(30 * time.Minute), "0.9", nil},
+		{start.Add(2 * time.Hour), "0.8", nil},
+	}
+	for _, tt := range tests {
+		r, err := ws.RateAt("USD", "EUR", tt.at)
+		if err != tt.wantErr {
+			t.Errorf("RateAt(%v) error = %v, want %v", tt.at, err, tt.wantErr)
+			continue
+		}
+		if err == nil && r.Rate.String() != tt.want {
+			t.Errorf("RateAt(%v) = %s, want %s", tt.at, r.Rate, tt.want)
+		}
+	}
+
+	audit, err := ws.AuditConversion(second.ID)
+	if err != nil || !audit.Matches || !audit.Derived.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("AuditConversion() = %+v, %v, want a match at 8 EUR", audit, err)
+	}
+	history0, _ := ws.GetTransactionHistory("alice")
+	if _, err := ws.AuditConversion(history0[0].ID); err != ErrNotConversion {
+		t.Errorf("AuditConversion() of a deposit error = %v, want %v", err, ErrNotConversion)
+	}
+
+	// The rate history survives a backup
+	var buf bytes.Buffer
+	ws.Backup(&buf)
+	restored, err := Restore(&buf, WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if audit, err := restored.AuditConversion(first.ID); err != nil || !audit.Matches {
+		t.Errorf("restored AuditConversion() = %+v, %v", audit, err)
+	}
+}
+
+func TestRates_RecordRate(t *testing.T) {
+	ws := NewWalletService()
+
+	r, err := ws.RecordRate("eur", "usd", decimal.RequireFromString("1.08"), "ecb-fixing")
+	if err != nil || r.From != "EUR" || r.Source != "ecb-fixing" {
+		t.Fatalf("RecordRate() = %+v, %v", r, err)
+	}
+	if got, err := ws.GetRateRecord(r.ID); err != nil || !got.Rate.Equal(r.Rate) {
+		t.Errorf("GetRateRecord() = %+v, %v", got, err)
+	}
+
+	tests := []struct {
+		from, to string
+		rate     string
+		wantErr  error
+	}{
+		{"EUR", "EUR", "1", ErrInvalidCurrency},
+		{"", "USD", "1", ErrInvalidCurrency},
+		{"EUR", "USD", "0", ErrRateUnavailable},
+	}
+	for _, tt := range tests {
+		if _, err := ws.RecordRate(tt.from, tt.to, decimal.RequireFromString(tt.rate), "manual"); err != tt.wantErr {
+			t.Errorf("RecordRate(%s, %s, %s) error = %v, want %v", tt.from, tt.to, tt.rate, err, tt.wantErr)
+		}
+	}
+	if _, err := ws.GetRateRecord("rate_missing"); err != ErrRateRecordNotFound {
+		t.Errorf("GetRateRecord() error = %v, want %v", err, ErrRateRecordNotFound)
+	}
+}
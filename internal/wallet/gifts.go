@@ -0,0 +1,388 @@
+// internal/wallet/gifts.go
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for scheduled gifts
+var (
+	ErrGiftNotFound      = errors.New("gift not found")
+	ErrGiftNotCancelable = errors.New("gift can no longer be cancelled")
+	ErrGiftNotClaimable  = errors.New("gift is not awaiting a claim")
+	ErrInvalidRecipient  = errors.New("recipient must be a user ID or an email address")
+	ErrInvalidSchedule   = errors.New("scheduled time must be in the future")
+)
+
+// DefaultGiftClaimTTL is how long an escrowed gift waits for the recipient to sign up
+const DefaultGiftClaimTTL = 30 * 24 * time.Hour
+
+// GiftStatus is the lifecycle state of a gift
+type GiftStatus string
+
+const (
+	GiftScheduled    GiftStatus = "scheduled"
+	GiftDelivered    GiftStatus = "delivered"
+	GiftPendingClaim GiftStatus = "pending_claim"
+	GiftClaimed      GiftStatus = "claimed"
+	GiftRefunded     GiftStatus = "refunded"
+	GiftCancelled    GiftStatus = "cancelled"
+	GiftFailed       GiftStatus = "failed"
+	GiftHeld         GiftStatus = "held_for_review"
+)
+
+// Gift is a transfer scheduled for a future date with a personal message
+type Gift struct {
+	ID             string
+	SenderID       string
+	Recipient      string // user ID or email address as given by the sender
+	RecipientID    string // set once the gift reaches a user
+	Amount         decimal.Decimal
+	Message        string
+	DeliverAt      int64
+	Status         GiftStatus
+	TransactionID  string
+	ClaimExpiresAt int64
+	FailureReason  string
+	JobID          string
+
+	claimToken string
+}
+
+// giftBook holds scheduled and escrowed gifts
+type giftBook struct {
+	mu      sync.Mutex
+	gifts   map[string]*Gift
+	byToken map[string]string // claim token -> gift ID
+}
+
+// ScheduleGift schedules amount to be sent from senderID to recipient at deliverAt.
+// The recipient may be a user ID or an email address; if nobody has that email when
+// the gift executes, the funds are escrowed and a claim link is sent to the address.
+func (ws *WalletService) ScheduleGift(senderID, recipient string, amount decimal.Decimal, message string, deliverAt time.Time) (*Gift, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	if !deliverAt.After(ws.now()) {
+		return nil, ErrInvalidSchedule
+	}
+	recipient = strings.TrimSpace(recipient)
+	if recipient == "" || recipient == senderID {
+		return nil, ErrInvalidRecipient
+	}
+
+	ws.mu.RLock()
+	_, senderExists := ws.users[senderID]
+	_, recipientIsUser := ws.users[recipient]
+	ws.mu.RUnlock()
+
+	if !senderExists {
+		return nil, ErrUserNotFound
+	}
+	if !recipientIsUser && !strings.Contains(recipient, "@") {
+		return nil, ErrInvalidRecipient
+	}
+
+	gift := &Gift{
+		ID:        ws.newID("gift"),
+		SenderID:  senderID,
+		Recipient: recipient,
+		Amount:    amount,
+		Message:   message,
+		DeliverAt: deliverAt.Unix(),
+		Status:    GiftScheduled,
+	}
+
+	ws.gifts.mu.Lock()
+	if ws.gifts.gifts == nil {
+		ws.gifts.gifts = make(map[string]*Gift)
+		ws.gifts.byToken = make(map[string]string)
+	}
+	ws.gifts.gifts[gift.ID] = gift
+	ws.gifts.mu.Unlock()
+
+	jobID := ws.schedule("gift", senderID, deliverAt, nil, func(now time.Time) error {
+		return ws.deliverGift(gift.ID)
+	})
+
+	ws.gifts.mu.Lock()
+	gift.JobID = jobID
+	copied := *gift
+	ws.gifts.mu.Unlock()
+
+	return &copied, nil
+}
+
+// CancelGift cancels a gift that has not executed yet
+func (ws *WalletService) CancelGift(giftID, senderID string) error {
+	ws.gifts.mu.Lock()
+	defer ws.gifts.mu.Unlock()
+
+	gift, exists := ws.gifts.gifts[giftID]
+	if !exists || gift.SenderID != senderID {
+		return ErrGiftNotFound
+	}
+	if gift.Status != GiftScheduled {
+		return ErrGiftNotCancelable
+	}
+
+	gift.Status = GiftCancelled
+	ws.CancelJob(gift.JobID)
+	return nil
+}
+
+// GetGift returns a gift by ID
+func (ws *WalletService) GetGift(giftID string) (*Gift, error) {
+	ws.gifts.mu.Lock()
+	defer ws.gifts.mu.Unlock()
+
+	gift, exists := ws.gifts.gifts[giftID]
+	if !exists {
+		return nil, ErrGiftNotFound
+	}
+	copied := *gift
+	return &copied, nil
+}
+
+// ClaimGift credits an escrowed gift to userID using the token from the claim link
+func (ws *WalletService) ClaimGift(claimToken, userID string) (*Gift, error) {
+	return ws.ClaimGiftContext(context.Background(), claimToken, userID)
+}
+
+// ClaimGiftContext is ClaimGift on behalf of a caller's ctx
+func (ws *WalletService) ClaimGiftContext(ctx context.Context, claimToken, userID string) (*Gift, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ws.gifts.mu.Lock()
+	giftID, exists := ws.gifts.byToken[claimToken]
+	ws.gifts.mu.Unlock()
+
+	if !exists {
+		return nil, ErrGiftNotClaimable
+	}
+	if err := ws.claimGift(ctx, giftID, userID); err != nil {
+		return nil, err
+	}
+	return ws.GetGift(giftID)
+}
+
+// deliverGift executes a scheduled gift
+func (ws *WalletService) deliverGift(giftID string) error {
+	ws.gifts.mu.Lock()
+	gift := ws.gifts.gifts[giftID]
+	if gift == nil || gift.Status != GiftScheduled {
+		ws.gifts.mu.Unlock()
+		return nil
+	}
+	g := *gift
+	ws.gifts.mu.Unlock()
+
+	recipientID, found := g.Recipient, false
+	ws.mu.RLock()
+	_, found = ws.users[recipientID]
+	ws.mu.RUnlock()
+	if !found {
+		recipientID, found = ws.findUserByEmail(g.Recipient)
+	}
+
+	if found {
+		tx, err := ws.transfer(context.Background(), g.SenderID, recipientID, g.Amount, giftDescription(g.Message), transferOptions{priority: PriorityBatch})
+		if errors.Is(err, ErrTransferHeld) {
+			// The compliance case decides whether the recipient gets the funds
+			ws.updateGift(giftID, func(gift *Gift) {
+				gift.Status = GiftHeld
+				gift.RecipientID = recipientID
+				gift.TransactionID = tx.ID
+			})
+			return nil
+		}
+		if err != nil {
+			ws.failGift(giftID, err)
+			return err
+		}
+		ws.updateGift(giftID, func(gift *Gift) {
+			gift.Status = GiftDelivered
+			gift.RecipientID = recipientID
+			gift.TransactionID = tx.ID
+		})
+		ws.notify(Notification{
+			UserID:    recipientID,
+			Type:      "gift_received",
+			Subject:   "You received a gift",
+			Message:   g.Message,
+			Data:      map[string]string{"gift_id": g.ID, "sender_id": g.SenderID, "amount": g.Amount.String()},
+			Timestamp: ws.now().Unix(),
+		})
+		return nil
+	}
+
+	// Recipient has no account yet: escrow the funds and send a claim link
+	escrow := &Transaction{
+		FromUserID:  g.SenderID,
+		ToUserID:    g.Recipient,
+		Amount:      g.Amount,
+		Type:        TransactionGiftEscrow,
+		Description: giftDescription(g.Message),
+	}
+	if err := ws.postDebit(context.Background(), escrow); err != nil {
+		ws.failGift(giftID, err)
+		return err
+	}
+
+	token := ws.newToken()
+	expiresAt := ws.now().Add(DefaultGiftClaimTTL)
+	ws.updateGift(giftID, func(gift *Gift) {
+		gift.Status = GiftPendingClaim
+		gift.TransactionID = escrow.ID
+		gift.ClaimExpiresAt = expiresAt.Unix()
+		gift.claimToken = token
+		ws.gifts.byToken[token] = giftID
+	})
+	ws.schedule("gift_claim_expiry", g.SenderID, expiresAt, nil, func(now time.Time) error {
+		return ws.refundUnclaimedGift(giftID)
+	})
+
+	ws.notify(Notification{
+		Type:    "gift_claim_link",
+		Subject: "You received a gift",
+		Message: g.Message,
+		Data: map[string]string{
+			"email":       g.Recipient,
+			"gift_id":     g.ID,
+			"amount":      g.Amount.String(),
+			"claim_token": token,
+			"expires_at":  fmt.Sprint(expiresAt.Unix()),
+		},
+		Timestamp: ws.now().Unix(),
+	})
+	return nil
+}
+
+// claimGiftsByEmail converts every escrowed gift addressed to email into a credit for userID
+func (ws *WalletService) claimGiftsByEmail(userID, email string) {
+	if email == "" {
+		return
+	}
+
+	ws.gifts.mu.Lock()
+	var pending []string
+	for id, gift := range ws.gifts.gifts {
+		if gift.Status == GiftPendingClaim && strings.EqualFold(gift.Recipient, email) {
+			pending = append(pending, id)
+		}
+	}
+	ws.gifts.mu.Unlock()
+
+	for _, id := range pending {
+		ws.claimGift(context.Background(), id, userID)
+	}
+}
+
+// claimGift moves an escrowed gift into userID's wallet
+func (ws *WalletService) claimGift(ctx context.Context, giftID, userID string) error {
+	ws.gifts.mu.Lock()
+	gift := ws.gifts.gifts[giftID]
+	if gift == nil || gift.Status != GiftPendingClaim {
+		ws.gifts.mu.Unlock()
+		return ErrGiftNotClaimable
+	}
+	// Mark before crediting so a concurrent claim or expiry cannot pay out twice
+	gift.Status = GiftClaimed
+	g := *gift
+	ws.gifts.mu.Unlock()
+
+	claim := &Transaction{
+		FromUserID:  g.SenderID,
+		ToUserID:    userID,
+		Amount:      g.Amount,
+		Type:        TransactionGiftClaim,
+		Description: giftDescription(g.Message),
+		ParentTxID:  g.TransactionID,
+	}
+	if err := ws.postCredit(ctx, claim); err != nil {
+		ws.updateGift(giftID, func(gift *Gift) { gift.Status = GiftPendingClaim })
+		return err
+	}
+
+	ws.updateGift(giftID, func(gift *Gift) {
+		gift.RecipientID = userID
+		gift.TransactionID = claim.ID
+		delete(ws.gifts.byToken, gift.claimToken)
+	})
+	return nil
+}
+
+// refundUnclaimedGift returns an expired escrowed gift to its sender
+func (ws *WalletService) refundUnclaimedGift(giftID string) error {
+	ws.gifts.mu.Lock()
+	gift := ws.gifts.gifts[giftID]
+	if gift == nil || gift.Status != GiftPendingClaim {
+		ws.gifts.mu.Unlock()
+		return nil
+	}
+	gift.Status = GiftRefunded
+	delete(ws.gifts.byToken, gift.claimToken)
+	g := *gift
+	ws.gifts.mu.Unlock()
+
+	return ws.postCredit(context.Background(), &Transaction{
+		FromUserID:  g.Recipient,
+		ToUserID:    g.SenderID,
+		Amount:      g.Amount,
+		Type:        TransactionGiftRefund,
+		Description: "unclaimed gift " + g.ID,
+		ParentTxID:  g.TransactionID,
+	})
+}
+
+// failGift marks a gift as failed and tells the sender why
+func (ws *WalletService) failGift(giftID string, cause error) {
+	var senderID string
+	ws.updateGift(giftID, func(gift *Gift) {
+		gift.Status = GiftFailed
+		gift.FailureReason = cause.Error()
+		senderID = gift.SenderID
+	})
+	ws.notify(Notification{
+		UserID:    senderID,
+		Type:      "gift_failed",
+		Subject:   "Your scheduled gift could not be sent",
+		Message:   cause.Error(),
+		Data:      map[string]string{"gift_id": giftID},
+		Timestamp: ws.now().Unix(),
+	})
+}
+
+// updateGift applies fn to a gift under the gift lock
+func (ws *WalletService) updateGift(giftID string, fn func(*Gift)) {
+	ws.gifts.mu.Lock()
+	defer ws.gifts.mu.Unlock()
+	if gift := ws.gifts.gifts[giftID]; gift != nil {
+		fn(gift)
+	}
+}
+
+// giftDescription builds the transaction description shown for a gift
+func giftDescription(message string) string {
+	if message == "" {
+		return "gift"
+	}
+	return "gift: " + message
+}
+
+// newClaimToken returns an unguessable token for claim links
+func newClaimToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,199 @@
+// internal/wallet/gifts_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestScheduleGift_DeliversToExistingUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		recipient string
+	}{
+		{"by user ID", "bob"},
+		{"by email", "BOB@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			ws, clock := newTestService(WithNotifier(notifier))
+			ws.CreateUser("alice", "Alice", "alice@example.com")
+			ws.CreateUser("bob", "Bob", "bob@example.com")
+			ws.Deposit("alice", 100, "seed")
+
+			gift, err := ws.ScheduleGift("alice", tt.recipient, decimal.NewFromInt(25), "Happy birthday", clock.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatalf("ScheduleGift() error = %v", err)
+			}
+
+			// Not due yet
+			ws.RunDueJobs()
+			if b, _ := ws.GetBalanceDecimal("bob"); !b.IsZero() {
+				t.Fatalf("gift delivered early, bob balance = %s", b)
+			}
+
+			clock.Advance(time.Hour)
+			ws.RunDueJobs()
+
+			if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(25)) {
+				t.Errorf("bob balance = %s, want 25", b)
+			}
+			got, _ := ws.GetGift(gift.ID)
+			if got.Status != GiftDelivered || got.RecipientID != "bob" || got.TransactionID == "" {
+				t.Errorf("gift = %+v, want delivered to bob", got)
+			}
+
+			var received bool
+			for _, n := range notifier.all() {
+				if n.Type == "gift_received" && n.UserID == "bob" && n.Message == "Happy birthday" {
+					received = true
+				}
+			}
+			if !received {
+				t.Error("recipient was not notified")
+			}
+		})
+	}
+}
+
+func TestScheduleGift_Validation(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	later := clock.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		sender    string
+		recipient string
+		amount    decimal.Decimal
+		at        time.Time
+		wantErr   error
+	}{
+		{"zero amount", "alice", "bob", decimal.Zero, later, ErrInvalidAmount},
+		{"past time", "alice", "bob", decimal.NewFromInt(1), clock.Now(), ErrInvalidSchedule},
+		{"unknown sender", "ghost", "bob", decimal.NewFromInt(1), later, ErrUserNotFound},
+		{"to self", "alice", "alice", decimal.NewFromInt(1), later, ErrInvalidRecipient},
+		{"not a user or email", "alice", "carol", decimal.NewFromInt(1), later, ErrInvalidRecipient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.ScheduleGift(tt.sender, tt.recipient, tt.amount, "", tt.at); err != tt.wantErr {
+				t.Errorf("ScheduleGift() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleGift_EscrowAndClaim(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, clock := newTestService(WithNotifier(notifier))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	gift, _ := ws.ScheduleGift("alice", "carol@example.com", decimal.NewFromInt(40), "Welcome", clock.Now().Add(time.Minute))
+	clock.Advance(time.Minute)
+	ws.RunDueJobs()
+
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(60)) {
+		t.Fatalf("alice balance = %s, want 60 after escrow", b)
+	}
+	got, _ := ws.GetGift(gift.ID)
+	if got.Status != GiftPendingClaim {
+		t.Fatalf("status = %s, want %s", got.Status, GiftPendingClaim)
+	}
+
+	var token string
+	for _, n := range notifier.all() {
+		if n.Type == "gift_claim_link" && n.Data["email"] == "carol@example.com" {
+			token = n.Data["claim_token"]
+		}
+	}
+	if token == "" {
+		t.Fatal("no claim link sent")
+	}
+
+	ws.CreateUser("carol", "Carol", "Carol@Example.com")
+
+	if b, _ := ws.GetBalanceDecimal("carol"); !b.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("carol balance = %s, want 40 after signup", b)
+	}
+	if _, err := ws.ClaimGift(token, "carol"); err != ErrGiftNotClaimable {
+		t.Errorf("second claim error = %v, want %v", err, ErrGiftNotClaimable)
+	}
+	for _, user := range []string{"alice", "carol"} {
+		if mismatch, err := ws.CheckWalletIntegrity(user); err != nil || mismatch != nil {
+			t.Errorf("CheckWalletIntegrity(%s) = %+v, %v", user, mismatch, err)
+		}
+	}
+}
+
+func TestScheduleGift_UnclaimedRefund(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	gift, _ := ws.ScheduleGift("alice", "dave@example.com", decimal.NewFromInt(30), "", clock.Now().Add(time.Minute))
+	clock.Advance(time.Minute)
+	ws.RunDueJobs()
+
+	clock.Advance(DefaultGiftClaimTTL)
+	ws.RunDueJobs()
+
+	got, _ := ws.GetGift(gift.ID)
+	if got.Status != GiftRefunded {
+		t.Errorf("status = %s, want %s", got.Status, GiftRefunded)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("alice balance = %s, want 100 after refund", b)
+	}
+}
+
+func TestScheduleGift_CancelAndFailure(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, clock := newTestService(WithNotifier(notifier))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	cancelled, _ := ws.ScheduleGift("alice", "bob", decimal.NewFromInt(10), "", clock.Now().Add(time.Hour))
+	if err := ws.CancelGift(cancelled.ID, "bob"); err != ErrGiftNotFound {
+		t.Errorf("CancelGift by non-sender error = %v, want %v", err, ErrGiftNotFound)
+	}
+	if err := ws.CancelGift(cancelled.ID, "alice"); err != nil {
+		t.Fatalf("CancelGift() error = %v", err)
+	}
+
+	tooLarge, _ := ws.ScheduleGift("alice", "bob", decimal.NewFromInt(500), "", clock.Now().Add(time.Hour))
+	clock.Advance(time.Hour)
+	ws.RunDueJobs()
+
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.IsZero() {
+		t.Errorf("bob balance = %s, want 0", b)
+	}
+	got, _ := ws.GetGift(tooLarge.ID)
+	if got.Status != GiftFailed {
+		t.Errorf("status = %s, want %s", got.Status, GiftFailed)
+	}
+
+	var senderNotified bool
+	for _, n := range notifier.all() {
+		if n.Type == "gift_failed" && n.UserID == "alice" {
+			senderNotified = true
+		}
+	}
+	if !senderNotified {
+		t.Error("sender was not told about the failed gift")
+	}
+	if err := ws.CancelGift(tooLarge.ID, "alice"); err != ErrGiftNotCancelable {
+		t.Errorf("CancelGift after run error = %v, want %v", err, ErrGiftNotCancelable)
+	}
+}
@@ -0,0 +1,99 @@
+// internal/wallet/health.go
+package wallet
+
+import (
+	"sort"
+	"sync"
+)
+
+// HealthStatus is the outcome of a health check
+type HealthStatus string
+
+const (
+	HealthOK       HealthStatus = "ok"
+	HealthDegraded HealthStatus = "degraded"
+	HealthFailing  HealthStatus = "failing"
+)
+
+// HealthCheckResult is the outcome of one named check
+type HealthCheckResult struct {
+	Name   string
+	Status HealthStatus
+	Detail string
+	Data   map[string]string
+}
+
+// HealthCheckFunc reports the health of one subsystem
+type HealthCheckFunc func() HealthCheckResult
+
+// HealthReport aggregates all checks; Status is the worst individual status
+type HealthReport struct {
+	Status    HealthStatus
+	Checks    []HealthCheckResult
+	CheckedAt int64
+}
+
+// healthRegistry holds checks added by the embedding application
+type healthRegistry struct {
+	mu     sync.RWMutex
+	checks map[string]HealthCheckFunc
+}
+
+// RegisterHealthCheck adds or replaces a named health check
+func (ws *WalletService) RegisterHealthCheck(name string, fn HealthCheckFunc) {
+	ws.health.mu.Lock()
+	defer ws.health.mu.Unlock()
+
+	if ws.health.checks == nil {
+		ws.health.checks = make(map[string]HealthCheckFunc)
+	}
+	ws.health.checks[name] = fn
+}
+
+// CheckHealth runs the built-in and registered checks
+func (ws *WalletService) CheckHealth() HealthReport {
+	checks := map[string]HealthCheckFunc{
+		"compliance_cases": ws.complianceHealth,
+		"money_supply":     ws.supplyHealth,
+	}
+	if ws.store != nil {
+		checks["store"] = ws.storeHealth
+	}
+	ws.health.mu.RLock()
+	for name, fn := range ws.health.checks {
+		checks[name] = fn
+	}
+	ws.health.mu.RUnlock()
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := HealthReport{Status: HealthOK, CheckedAt: ws.now().Unix()}
+	for _, name := range names {
+		result := checks[name]()
+		result.Name = name
+		if result.Status == "" {
+			result.Status = HealthOK
+		}
+		if healthRank(result.Status) > healthRank(report.Status) {
+			report.Status = result.Status
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
+
+// healthRank orders statuses from best to worst
+func healthRank(s HealthStatus) int {
+	switch s {
+	case HealthOK:
+		return 0
+	case HealthDegraded:
+		return 1
+	}
+	return 2
+}
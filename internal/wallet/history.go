@@ -0,0 +1,250 @@
+// internal/wallet/history.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for paginated history
+var (
+	ErrInvalidCursor = errors.New("invalid history cursor")
+	ErrCursorExpired = errors.New("history cursor points at entries archived since; start again")
+)
+
+// Page sizes of ListTransactions
+const (
+	DefaultHistoryPageSize = 50
+	MaxHistoryPageSize     = 500
+)
+
+// HistoryDirection filters history by which way money moved for the user
+type HistoryDirection string
+
+const (
+	DirectionAny      HistoryDirection = ""
+	DirectionSent     HistoryDirection = "sent"     // debits and outgoing transfers
+	DirectionReceived HistoryDirection = "received" // credits and incoming transfers
+)
+
+// HistoryQuery selects a page of a user's history. Zero fields leave their filter
+// off. Conversions and other entries that only move money within the wallet match
+// DirectionAny alone.
+type HistoryQuery struct {
+	Cursor    string // NextCursor of the previous page; empty for the first page
+	Limit     int    // DefaultHistoryPageSize when unset, at most MaxHistoryPageSize
+	Types     []TransactionType
+	Since     int64 // inclusive lower bound on Timestamp
+	Until     int64 // exclusive upper bound on Timestamp
+	MinAmount decimal.Decimal
+	MaxAmount decimal.Decimal
+	Direction HistoryDirection
+}
+
+// HistoryPage is one page of a user's history in log order
+type HistoryPage struct {
+	Transactions []*Transaction
+	NextCursor   string // empty on the last page
+}
+
+// historyCursor is where the next page starts: an offset into the user's archived
+// entries, or a position in the hot log
+type historyCursor struct {
+	archived bool
+	n        int
+}
+
+// ListTransactions returns a page of userID's history matching q, oldest first. Hot
+// entries are found through a per-user index rather than a scan of the whole log;
+// archived entries come first and are paged by offset. A cursor into the hot log
+// fails with ErrCursorExpired once the entries it points at have been archived.
+func (ws *WalletService) ListTransactions(userID string, q HistoryQuery) (*HistoryPage, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryPageSize
+	}
+	limit = min(limit, MaxHistoryPageSize)
+
+	cursor := historyCursor{archived: ws.archive != nil}
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = parseHistoryCursor(q.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	page := &HistoryPage{}
+	if cursor.archived {
+		if ws.archive == nil {
+			return nil, ErrInvalidCursor
+		}
+		// Holding evictMu keeps entries from moving to the archive between the archived
+		// part of the page and the hot part
+		ws.retention.evictMu.Lock()
+		defer ws.retention.evictMu.Unlock()
+
+		txs, next, more, err := ws.archivedPage(userID, q, cursor.n, limit)
+		if err != nil {
+			return nil, err
+		}
+		page.Transactions = txs
+		if more {
+			page.NextCursor = historyCursor{archived: true, n: next}.String()
+			return page, nil
+		}
+		ws.mu.RLock()
+		cursor = historyCursor{n: ws.logBase}
+		ws.mu.RUnlock()
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	if cursor.n < ws.logBase {
+		return nil, ErrCursorExpired
+	}
+	positions := ws.txIndex.byUser[userID]
+	for i := sort.SearchInts(positions, cursor.n); i < len(positions); i++ {
+		tx := ws.transactions[positions[i]-ws.logBase]
+		if !q.matches(userID, tx) {
+			continue
+		}
+		if len(page.Transactions) == limit {
+			page.NextCursor = historyCursor{n: positions[i]}.String()
+			break
+		}
+		page.Transactions = append(page.Transactions, tx)
+	}
+	return page, nil
+}
+
+// archivedPage returns up to limit archived entries matching q after skipping the
+// first skip of the user's archived entries, the offset to continue from, and whether
+// more matching entries follow. Caller must hold ws.retention.evictMu.
+func (ws *WalletService) archivedPage(userID string, q HistoryQuery, skip, limit int) ([]*Transaction, int, bool, error) {
+	it, err := ws.archiveIterate(context.Background(), userID, IterateOptions{Since: q.Since, Until: q.Until})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer it.Close()
+
+	var txs []*Transaction
+	n := 0
+	for it.Next() {
+		if n++; n <= skip {
+			continue
+		}
+		tx := it.Transaction()
+		if !q.matches(userID, tx) {
+			continue
+		}
+		if len(txs) == limit {
+			return txs, n - 1, true, nil
+		}
+		txs = append(txs, tx)
+	}
+	return txs, n, false, it.Err()
+}
+
+// matches reports whether tx involves userID and passes every filter of q
+func (q HistoryQuery) matches(userID string, tx *Transaction) bool {
+	if !(IterateOptions{Since: q.Since, Until: q.Until}).matches(userID, tx) {
+		return false
+	}
+	if len(q.Types) > 0 && !slices.Contains(q.Types, tx.Type) {
+		return false
+	}
+	if !q.MinAmount.IsZero() && tx.Amount.LessThan(q.MinAmount) {
+		return false
+	}
+	if !q.MaxAmount.IsZero() && tx.Amount.GreaterThan(q.MaxAmount) {
+		return false
+	}
+	return q.Direction == DirectionAny || tx.direction(userID) == q.Direction
+}
+
+// direction returns which way tx moved money for userID, or DirectionAny when it only
+// moved money within the wallet
+func (tx *Transaction) direction(userID string) HistoryDirection {
+	switch transactionKind(tx.Type) {
+	case KindCredit:
+		if tx.ToUserID == userID {
+			return DirectionReceived
+		}
+	case KindDebit:
+		if tx.FromUserID == userID {
+			return DirectionSent
+		}
+	case KindTransfer:
+		if tx.FromUserID == userID {
+			return DirectionSent
+		}
+		if tx.ToUserID == userID {
+			return DirectionReceived
+		}
+	}
+	return DirectionAny
+}
+
+// String encodes the cursor for HistoryPage.NextCursor
+func (c historyCursor) String() string {
+	prefix := "h"
+	if c.archived {
+		prefix = "a"
+	}
+	return prefix + strconv.Itoa(c.n)
+}
+
+// parseHistoryCursor decodes a cursor made by historyCursor.String
+func parseHistoryCursor(s string) (historyCursor, error) {
+	if len(s) < 2 || (s[0] != 'a' && s[0] != 'h') {
+		return historyCursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 0 {
+		return historyCursor{}, ErrInvalidCursor
+	}
+	return historyCursor{archived: s[0] == 'a', n: n}, nil
+}
+
+// indexUser records the position of tx, just appended to the hot log, under each user
+// it involves. Caller must hold ws.mu.
+func (ws *WalletService) indexUser(tx *Transaction) {
+	idx := &ws.txIndex
+	if idx.byUser == nil {
+		idx.byUser = make(map[string][]int)
+	}
+	position := ws.logBase + len(ws.transactions) - 1
+	for _, userID := range []string{tx.FromUserID, tx.ToUserID} {
+		if _, exists := ws.users[userID]; !exists {
+			continue
+		}
+		if positions := idx.byUser[userID]; len(positions) == 0 || positions[len(positions)-1] != position {
+			idx.byUser[userID] = append(positions, position)
+		}
+	}
+}
+
+// pruneUserIndex drops the positions of batch, just moved to the archive, from the
+// per-user index. Caller must hold ws.mu.
+func (ws *WalletService) pruneUserIndex(batch []*Transaction) {
+	for _, tx := range batch {
+		for _, userID := range []string{tx.FromUserID, tx.ToUserID} {
+			positions := ws.txIndex.byUser[userID]
+			if cut := sort.SearchInts(positions, ws.logBase); cut > 0 {
+				ws.txIndex.byUser[userID] = slices.Delete(positions, 0, cut)
+			}
+		}
+	}
+}
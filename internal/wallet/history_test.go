@@ -0,0 +1,116 @@
+// internal/wallet/history_test.go
+package wallet
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// seedHistory gives alice a mixed history of eight entries an hour apart
+func seedHistory(ws *WalletService, clock *fakeClock) {
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("bob", 100, "seed")
+
+	steps := []func(){
+		func() { ws.Deposit("alice", 100, "d1") },
+		func() { ws.Transfer("alice", "bob", 10, "t1") },
+		func() { ws.Transfer("bob", "alice", 25, "t2") },
+		func() { ws.Withdraw("alice", 5, "w1") },
+		func() { ws.Deposit("bob", 1, "not alice") },
+		func() { ws.Deposit("alice", 50, "d2") },
+		func() { ws.Transfer("alice", "bob", 60, "t3") },
+		func() { ws.Withdraw("alice", 1, "w2") },
+		func() { ws.Transfer("bob", "alice", 2, "t4") },
+	}
+	for _, step := range steps {
+		clock.Advance(time.Hour)
+		step()
+	}
+}
+
+// collect pages through q and returns the descriptions in order
+func collect(t *testing.T, ws *WalletService, q HistoryQuery) []string {
+	t.Helper()
+	var out []string
+	for pages := 0; ; pages++ {
+		page, err := ws.ListTransactions("alice", q)
+		if err != nil {
+			t.Fatalf("ListTransactions() error = %v", err)
+		}
+		if pages > 20 {
+			t.Fatal("pagination does not terminate")
+		}
+		for _, tx := range page.Transactions {
+			out = append(out, tx.Description)
+		}
+		if page.NextCursor == "" {
+			return out
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func TestListTransactionsFilters(t *testing.T) {
+	ws, clock := newTestService()
+	seedHistory(ws, clock)
+	start := clock.Now().Add(-9 * time.Hour).Unix()
+
+	tests := []struct {
+		name  string
+		query HistoryQuery
+		want  []string
+	}{
+		{"all", HistoryQuery{}, []string{"d1", "t1", "t2", "w1", "d2", "t3", "w2", "t4"}},
+		{"types", HistoryQuery{Types: []TransactionType{TransactionDeposit, TransactionWithdraw}}, []string{"d1", "w1", "d2", "w2"}},
+		{"sent", HistoryQuery{Direction: DirectionSent}, []string{"t1", "w1", "t3", "w2"}},
+		{"received", HistoryQuery{Direction: DirectionReceived}, []string{"d1", "t2", "d2", "t4"}},
+		{"date range", HistoryQuery{Since: start + 3*3600, Until: start + 6*3600}, []string{"t2", "w1"}},
+		{"amount range", HistoryQuery{MinAmount: decimal.NewFromInt(5), MaxAmount: decimal.NewFromInt(50)}, []string{"t1", "t2", "w1", "d2"}},
+		{"combined", HistoryQuery{Direction: DirectionSent, Types: []TransactionType{TransactionTransfer}, MinAmount: decimal.NewFromInt(20)}, []string{"t3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, limit := range []int{1, 3, 0} {
+				tt.query.Limit = limit
+				if got := collect(t, ws, tt.query); !slices.Equal(got, tt.want) {
+					t.Errorf("limit %d: got %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestListTransactionsAcrossArchive(t *testing.T) {
+	ws, clock := newTestService(WithArchive(NewMemoryArchive()))
+	seedHistory(ws, clock)
+	start := clock.Now().Add(-9 * time.Hour).Unix()
+	if _, err := ws.ArchiveTransactionsBefore(start + 4*3600); err != nil {
+		t.Fatalf("ArchiveTransactionsBefore() error = %v", err)
+	}
+
+	want := []string{"d1", "t1", "t2", "w1", "d2", "t3", "w2", "t4"}
+	for _, limit := range []int{1, 2, 3, 8} {
+		if got := collect(t, ws, HistoryQuery{Limit: limit}); !slices.Equal(got, want) {
+			t.Errorf("limit %d: got %v, want %v", limit, got, want)
+		}
+	}
+	if got := collect(t, ws, HistoryQuery{Limit: 2, Direction: DirectionSent}); !slices.Equal(got, []string{"t1", "w1", "t3", "w2"}) {
+		t.Errorf("sent across archive = %v", got)
+	}
+
+	// A hot cursor whose entries are archived meanwhile has to start again
+	page, _ := ws.ListTransactions("alice", HistoryQuery{Limit: 5})
+	ws.ArchiveTransactionsBefore(clock.Now().Unix() + 1)
+	if _, err := ws.ListTransactions("alice", HistoryQuery{Cursor: page.NextCursor}); err != ErrCursorExpired {
+		t.Errorf("stale cursor error = %v, want %v", err, ErrCursorExpired)
+	}
+	for _, cursor := range []string{"x1", "h", "h-3", "a2x"} {
+		if _, err := ws.ListTransactions("alice", HistoryQuery{Cursor: cursor}); err != ErrInvalidCursor {
+			t.Errorf("cursor %q error = %v, want %v", cursor, err, ErrInvalidCursor)
+		}
+	}
+}
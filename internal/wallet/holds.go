@@ -0,0 +1,284 @@
+// internal/wallet/holds.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for authorization holds
+var (
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldClosed         = errors.New("hold is no longer active")
+	ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+)
+
+// HoldStatus is the state of an authorization hold
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldReleased HoldStatus = "released"
+)
+
+// Hold reserves part of a wallet's balance without withdrawing it. While active the
+// funds stay in the balance but cannot be spent: available balance is the balance less
+// active holds. Capturing takes some or all of it; the rest is freed.
+type Hold struct {
+	ID            string
+	UserID        string
+	Amount        decimal.Decimal
+	Currency      string
+	Captured      decimal.Decimal
+	Status        HoldStatus
+	TransactionID string // capture entry, once captured
+	CreatedAt     int64
+	UpdatedAt     int64
+}
+
+// holdBook holds authorization holds
+type holdBook struct {
+	mu    sync.Mutex
+	holds map[string]*Hold
+}
+
+// Hold reserves amount of userID's available balance, as for a card-style
+// authorization. Holds are checked like a withdrawal: a wallet being closed,
+// restricted or missing a required consent cannot place them.
+func (ws *WalletService) Hold(userID string, amount decimal.Decimal) (*Hold, error) {
+	return ws.HoldContext(context.Background(), userID, amount)
+}
+
+// HoldContext is Hold on behalf of a caller's ctx
+func (ws *WalletService) HoldContext(ctx context.Context, userID string, amount decimal.Decimal) (*Hold, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	userLock := ws.userLocks.getLock(userID)
+	userLock.Lock()
+	defer userLock.Unlock()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	draft := &Transaction{FromUserID: userID, ToUserID: userID, Amount: amount, Currency: wallet.Currency, Type: TransactionHoldCapture}
+	if err := ws.checkAmount(draft.Currency, amount, false); err != nil {
+		return nil, err
+	}
+	if err := ws.validate(draft); err != nil {
+		return nil, err
+	}
+
+	minimum := ws.minimumFor(userID, draft.Currency, draft.Type)
+	wallet.mu.Lock()
+	if wallet.available(draft.Currency).LessThan(amount) {
+		wallet.mu.Unlock()
+		return nil, ErrInsufficientBalance
+	}
+	if err := wallet.checkMinimum(draft.Currency, amount, decimal.Zero, minimum); err != nil {
+		wallet.mu.Unlock()
+		return nil, err
+	}
+	wallet.hold(draft.Currency, amount)
+	wallet.mu.Unlock()
+
+	now := ws.now().Unix()
+	h := &Hold{
+		ID:        ws.newID("hold"),
+		UserID:    userID,
+		Amount:    amount,
+		Currency:  draft.Currency,
+		Captured:  decimal.Zero,
+		Status:    HoldActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	ws.holds.mu.Lock()
+	if ws.holds.holds == nil {
+		ws.holds.holds = make(map[string]*Hold)
+	}
+	ws.holds.holds[h.ID] = h
+	copied := *h
+	ws.holds.mu.Unlock()
+
+	ws.metrics.IncCounter("holds_total", map[string]string{"status": string(HoldActive)})
+	return &copied, nil
+}
+
+// CaptureHold takes amount of an active hold out of the wallet and frees the rest.
+// A hold is captured at most once.
+func (ws *WalletService) CaptureHold(holdID string, amount decimal.Decimal) (*Hold, error) {
+	return ws.CaptureHoldContext(context.Background(), holdID, amount)
+}
+
+// CaptureHoldContext is CaptureHold on behalf of a caller's ctx
+func (ws *WalletService) CaptureHoldContext(ctx context.Context, holdID string, amount decimal.Decimal) (*Hold, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	h, err := ws.closeHold(holdID, HoldCaptured, func(h *Hold) error {
+		if amount.GreaterThan(h.Amount) {
+			return ErrCaptureExceedsHold
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	capture := &Transaction{
+		FromUserID:  h.UserID,
+		ToUserID:    h.UserID,
+		Amount:      amount,
+		Currency:    h.Currency,
+		Type:        TransactionHoldCapture,
+		Description: "hold captured",
+		Metadata:    map[string]string{"hold_id": h.ID},
+	}
+	if err := ws.postDebitReleasing(ctx, capture, h.Amount); err != nil {
+		ws.holds.mu.Lock()
+		ws.holds.holds[holdID].Status = HoldActive
+		ws.holds.mu.Unlock()
+		return nil, err
+	}
+
+	ws.holds.mu.Lock()
+	defer ws.holds.mu.Unlock()
+	stored := ws.holds.holds[holdID]
+	stored.Captured = amount
+	stored.TransactionID = capture.ID
+	stored.UpdatedAt = ws.now().Unix()
+	ws.metrics.IncCounter("holds_total", map[string]string{"status": string(HoldCaptured)})
+	copied := *stored
+	return &copied, nil
+}
+
+// ReleaseHold frees an active hold without taking anything
+func (ws *WalletService) ReleaseHold(holdID string) (*Hold, error) {
+	return ws.ReleaseHoldContext(context.Background(), holdID)
+}
+
+// ReleaseHoldContext is ReleaseHold on behalf of a caller's ctx
+func (ws *WalletService) ReleaseHoldContext(ctx context.Context, holdID string) (*Hold, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	h, err := ws.closeHold(holdID, HoldReleased, nil)
+	if err != nil {
+		return nil, err
+	}
+	ws.unhold(h)
+	ws.metrics.IncCounter("holds_total", map[string]string{"status": string(HoldReleased)})
+	return h, nil
+}
+
+// GetHold returns a hold by ID
+func (ws *WalletService) GetHold(holdID string) (*Hold, error) {
+	ws.holds.mu.Lock()
+	defer ws.holds.mu.Unlock()
+
+	h, exists := ws.holds.holds[holdID]
+	if !exists {
+		return nil, ErrHoldNotFound
+	}
+	copied := *h
+	return &copied, nil
+}
+
+// ListHolds returns userID's active holds, oldest first
+func (ws *WalletService) ListHolds(userID string) []Hold {
+	ws.holds.mu.Lock()
+	defer ws.holds.mu.Unlock()
+
+	var list []Hold
+	for _, h := range ws.holds.holds {
+		if h.UserID == userID && h.Status == HoldActive {
+			list = append(list, *h)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt != list[j].CreatedAt {
+			return list[i].CreatedAt < list[j].CreatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// GetAvailableBalance returns the user's base-currency balance less active holds
+func (ws *WalletService) GetAvailableBalance(userID string) (decimal.Decimal, error) {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return decimal.Zero, ErrUserNotFound
+	}
+
+	wallet.mu.RLock()
+	defer wallet.mu.RUnlock()
+	return wallet.available(wallet.Currency), nil
+}
+
+// closeHold moves an active hold to status after check accepts it and returns a copy
+func (ws *WalletService) closeHold(holdID string, status HoldStatus, check func(h *Hold) error) (*Hold, error) {
+	ws.holds.mu.Lock()
+	defer ws.holds.mu.Unlock()
+
+	h, exists := ws.holds.holds[holdID]
+	if !exists {
+		return nil, ErrHoldNotFound
+	}
+	if h.Status != HoldActive {
+		return nil, ErrHoldClosed
+	}
+	if check != nil {
+		if err := check(h); err != nil {
+			return nil, err
+		}
+	}
+	h.Status = status
+	h.UpdatedAt = ws.now().Unix()
+	copied := *h
+	return &copied, nil
+}
+
+// unhold returns a hold's funds to the available balance
+func (ws *WalletService) unhold(h *Hold) {
+	ws.mu.RLock()
+	wallet := ws.wallets[h.UserID]
+	ws.mu.RUnlock()
+	if wallet == nil {
+		return
+	}
+	wallet.mu.Lock()
+	wallet.hold(h.Currency, h.Amount.Neg())
+	wallet.mu.Unlock()
+}
+
+// releaseHolds frees every active hold of userID and returns how many
+func (ws *WalletService) releaseHolds(userID string) int {
+	released := 0
+	for _, h := range ws.ListHolds(userID) {
+		if _, err := ws.ReleaseHold(h.ID); err == nil {
+			released++
+		}
+	}
+	return released
+}
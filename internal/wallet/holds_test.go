@@ -0,0 +1,81 @@
+// internal/wallet/holds_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestHoldCaptureAndRelease(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	first, err := ws.Hold("alice", decimal.NewFromInt(60))
+	if err != nil {
+		t.Fatalf("Hold() error = %v", err)
+	}
+	second, _ := ws.Hold("alice", decimal.NewFromInt(30))
+
+	steps := []struct {
+		name          string
+		run           func() error
+		wantErr       error
+		wantBalance   int64
+		wantAvailable int64
+	}{
+		{"holds leave the balance", func() error { return nil }, nil, 100, 10},
+		{"hold beyond available", func() error { _, err := ws.Hold("alice", decimal.NewFromInt(20)); return err }, ErrInsufficientBalance, 100, 10},
+		{"transfer of held funds", func() error { return ws.Transfer("alice", "bob", 20, "") }, ErrInsufficientBalance, 100, 10},
+		{"withdraw of held funds", func() error { return ws.Withdraw("alice", 20, "") }, ErrInsufficientBalance, 100, 10},
+		{"capture beyond hold", func() error { _, err := ws.CaptureHold(first.ID, decimal.NewFromInt(61)); return err }, ErrCaptureExceedsHold, 100, 10},
+		{"partial capture frees the rest", func() error { _, err := ws.CaptureHold(first.ID, decimal.NewFromInt(45)); return err }, nil, 55, 25},
+		{"capture twice", func() error { _, err := ws.CaptureHold(first.ID, decimal.NewFromInt(1)); return err }, ErrHoldClosed, 55, 25},
+		{"release", func() error { _, err := ws.ReleaseHold(second.ID); return err }, nil, 55, 55},
+		{"release twice", func() error { _, err := ws.ReleaseHold(second.ID); return err }, ErrHoldClosed, 55, 55},
+		{"unknown hold", func() error { _, err := ws.ReleaseHold("hold_missing"); return err }, ErrHoldNotFound, 55, 55},
+	}
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			if err := s.run(); err != s.wantErr {
+				t.Fatalf("error = %v, want %v", err, s.wantErr)
+			}
+			balance, _ := ws.GetBalanceDecimal("alice")
+			available, _ := ws.GetAvailableBalance("alice")
+			if !balance.Equal(decimal.NewFromInt(s.wantBalance)) || !available.Equal(decimal.NewFromInt(s.wantAvailable)) {
+				t.Errorf("balance/available = %s/%s, want %d/%d", balance, available, s.wantBalance, s.wantAvailable)
+			}
+		})
+	}
+
+	captured, _ := ws.GetHold(first.ID)
+	if captured.Status != HoldCaptured || !captured.Captured.Equal(decimal.NewFromInt(45)) || captured.TransactionID == "" {
+		t.Errorf("captured hold = %+v", captured)
+	}
+	if len(ws.ListHolds("alice")) != 0 {
+		t.Errorf("active holds = %+v, want none", ws.ListHolds("alice"))
+	}
+	if m, _ := ws.CheckWalletIntegrity("alice"); m != nil {
+		t.Errorf("integrity mismatch = %+v", m)
+	}
+}
+
+func TestHoldsListedAsPending(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 100, "seed")
+	h, _ := ws.Hold("alice", decimal.NewFromInt(25))
+
+	items, _ := ws.GetPendingItems("alice")
+	if len(items) != 1 || items[0].Kind != PendingHold || items[0].ID != h.ID {
+		t.Errorf("pending items = %+v, want the hold", items)
+	}
+	if released := ws.releaseHolds("alice"); released != 1 {
+		t.Errorf("releaseHolds() = %d, want 1", released)
+	}
+	if available, _ := ws.GetAvailableBalance("alice"); !available.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("available = %s, want 100", available)
+	}
+}
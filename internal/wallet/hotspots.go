@@ -0,0 +1,267 @@
+// internal/wallet/hotspots.go
+package wallet
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHotSpotWindow is the span hot-spot telemetry measures rates over when the
+// policy sets none
+const DefaultHotSpotWindow = time.Minute
+
+// HotWallet is one wallet's lock activity over the last one to two telemetry windows
+type HotWallet struct {
+	UserID       string
+	Operations   int64         // acquisitions of the wallet's user lock
+	OpsPerSecond float64       // Operations over the span measured
+	Contended    int64         // acquisitions that had to wait for another holder
+	LockWait     time.Duration // total time callers waited for the lock
+	MaxLockWait  time.Duration
+}
+
+// AverageWait is the mean wait of the contended acquisitions
+func (h HotWallet) AverageWait() time.Duration {
+	if h.Contended == 0 {
+		return 0
+	}
+	return h.LockWait / time.Duration(h.Contended)
+}
+
+// HotSpotHandler is told when a wallet becomes hot and when it cools down again, so
+// it can switch contention relief, such as sharding the balance or queueing
+// writes, on and off for that wallet
+type HotSpotHandler interface {
+	HotSpotDetected(w HotWallet)
+	HotSpotCleared(userID string)
+}
+
+// HotSpotPolicy says when a wallet counts as hot. A wallet is hot when it reaches
+// any threshold that is set.
+type HotSpotPolicy struct {
+	Window          time.Duration // DefaultHotSpotWindow when zero
+	MinOpsPerSecond float64
+	MinLockWait     time.Duration // total wait over the span measured
+	MinContended    int64
+	Handlers        []HotSpotHandler
+}
+
+// windowStats is lock activity within one telemetry window
+type windowStats struct {
+	ops       int64
+	contended int64
+	wait      time.Duration
+	maxWait   time.Duration
+}
+
+// userTelemetry is one wallet's lock activity in the current and previous windows
+type userTelemetry struct {
+	firstSeen time.Time
+	start     time.Time // of the current window
+	cur, prev windowStats
+}
+
+// hotSpotMonitor collects per-wallet lock telemetry and the set of wallets last
+// found hot
+type hotSpotMonitor struct {
+	mu       sync.Mutex
+	policy   HotSpotPolicy
+	detect   bool // a policy was configured
+	users    map[string]*userTelemetry
+	hot      map[string]bool
+	checking sync.Mutex // serializes CheckHotSpots so handlers see transitions in order
+}
+
+// WithHotSpotDetection enables hot-spot detection under policy. CheckHotSpots runs as
+// a scheduled job once per window.
+func WithHotSpotDetection(policy HotSpotPolicy) Option {
+	return func(ws *WalletService) {
+		ws.hotspots.policy = policy
+		ws.hotspots.detect = true
+	}
+}
+
+// GetHotWallets returns up to topN wallets causing the most lock contention, by total
+// lock wait, then contended and total acquisitions. A topN of zero or less returns
+// every wallet with recent activity.
+func (ws *WalletService) GetHotWallets(topN int) []HotWallet {
+	ws.hotspots.mu.Lock()
+	wallets := ws.hotspots.snapshot(ws.now())
+	ws.hotspots.mu.Unlock()
+
+	sort.Slice(wallets, func(i, j int) bool {
+		a, b := wallets[i], wallets[j]
+		if a.LockWait != b.LockWait {
+			return a.LockWait > b.LockWait
+		}
+		if a.Contended != b.Contended {
+			return a.Contended > b.Contended
+		}
+		if a.Operations != b.Operations {
+			return a.Operations > b.Operations
+		}
+		return a.UserID < b.UserID
+	})
+	if topN > 0 && len(wallets) > topN {
+		wallets = wallets[:topN]
+	}
+	return wallets
+}
+
+// IsHotWallet reports whether userID was hot at the last CheckHotSpots
+func (ws *WalletService) IsHotWallet(userID string) bool {
+	ws.hotspots.mu.Lock()
+	defer ws.hotspots.mu.Unlock()
+	return ws.hotspots.hot[userID]
+}
+
+// CheckHotSpots compares each wallet's telemetry with the policy, tells the handlers
+// about wallets that became hot or cooled down and returns the hot wallets. Wallets
+// idle for two windows are forgotten.
+func (ws *WalletService) CheckHotSpots() []HotWallet {
+	m := &ws.hotspots
+	m.checking.Lock()
+	defer m.checking.Unlock()
+
+	m.mu.Lock()
+	now := ws.now()
+	var hot, detected []HotWallet
+	var cleared []string
+	isHot := make(map[string]bool)
+	for _, w := range m.snapshot(now) {
+		if !m.policy.exceededBy(w) {
+			continue
+		}
+		hot = append(hot, w)
+		isHot[w.UserID] = true
+		if !m.hot[w.UserID] {
+			detected = append(detected, w)
+		}
+	}
+	for userID := range m.hot {
+		if !isHot[userID] {
+			cleared = append(cleared, userID)
+		}
+	}
+	m.hot = isHot
+	for userID, t := range m.users {
+		if t.cur.ops == 0 && t.prev.ops == 0 {
+			delete(m.users, userID)
+		}
+	}
+	handlers := m.policy.Handlers
+	m.mu.Unlock()
+
+	// Handlers run outside the lock so they may call back into the service
+	sort.Strings(cleared)
+	for _, w := range detected {
+		ws.metrics.IncCounter("hot_wallets_detected_total", nil)
+		for _, h := range handlers {
+			h.HotSpotDetected(w)
+		}
+	}
+	for _, userID := range cleared {
+		for _, h := range handlers {
+			h.HotSpotCleared(userID)
+		}
+	}
+	sort.Slice(hot, func(i, j int) bool { return hot[i].UserID < hot[j].UserID })
+	return hot
+}
+
+// startHotSpotJob schedules CheckHotSpots when hot-spot detection is enabled
+func (ws *WalletService) startHotSpotJob() {
+	if !ws.hotspots.detect {
+		return
+	}
+	d := ws.hotspots.policy.window()
+	ws.schedule("hot_spot_check", "", ws.now().Add(d), Every(d), func(time.Time) error {
+		ws.CheckHotSpots()
+		return nil
+	})
+}
+
+// record adds one acquisition of userID's lock, after waiting wait, at now
+func (m *hotSpotMonitor) record(userID string, wait time.Duration, contended bool, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.users == nil {
+		m.users = make(map[string]*userTelemetry)
+	}
+	t, exists := m.users[userID]
+	if !exists {
+		t = &userTelemetry{firstSeen: now, start: now}
+		m.users[userID] = t
+	}
+	t.roll(now, m.policy.window())
+	t.cur.ops++
+	if contended {
+		t.cur.contended++
+		t.cur.wait += wait
+		if wait > t.cur.maxWait {
+			t.cur.maxWait = wait
+		}
+	}
+}
+
+// snapshot returns the activity of every wallet seen in the last two windows. Caller
+// must hold m.mu.
+func (m *hotSpotMonitor) snapshot(now time.Time) []HotWallet {
+	window := m.policy.window()
+	wallets := make([]HotWallet, 0, len(m.users))
+	for userID, t := range m.users {
+		t.roll(now, window)
+		w := HotWallet{
+			UserID:      userID,
+			Operations:  t.prev.ops + t.cur.ops,
+			Contended:   t.prev.contended + t.cur.contended,
+			LockWait:    t.prev.wait + t.cur.wait,
+			MaxLockWait: max(t.prev.maxWait, t.cur.maxWait),
+		}
+		if w.Operations == 0 {
+			continue
+		}
+		// Rates run from the start of the previous window, or from the first
+		// acquisition seen when that is later, over at least a second
+		from := t.start.Add(-window)
+		if t.firstSeen.After(from) {
+			from = t.firstSeen
+		}
+		span := max(now.Sub(from), time.Second)
+		w.OpsPerSecond = float64(w.Operations) / span.Seconds()
+		wallets = append(wallets, w)
+	}
+	return wallets
+}
+
+// roll advances the telemetry's current window to the one now falls in
+func (t *userTelemetry) roll(now time.Time, window time.Duration) {
+	elapsed := now.Sub(t.start)
+	if elapsed < window {
+		return
+	}
+	if elapsed < 2*window {
+		t.prev = t.cur
+	} else {
+		t.prev = windowStats{}
+	}
+	t.cur = windowStats{}
+	t.start = t.start.Add(elapsed / window * window)
+}
+
+// window returns the policy's telemetry window
+func (p HotSpotPolicy) window() time.Duration {
+	if p.Window > 0 {
+		return p.Window
+	}
+	return DefaultHotSpotWindow
+}
+
+// exceededBy reports whether w reaches any threshold the policy sets
+func (p HotSpotPolicy) exceededBy(w HotWallet) bool {
+	return (p.MinOpsPerSecond > 0 && w.OpsPerSecond >= p.MinOpsPerSecond) ||
+		(p.MinLockWait > 0 && w.LockWait >= p.MinLockWait) ||
+		(p.MinContended > 0 && w.Contended >= p.MinContended)
+}
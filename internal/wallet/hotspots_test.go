@@ -0,0 +1,110 @@
+// internal/wallet/hotspots_test.go
+package wallet
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHotSpots keeps the transitions a HotSpotHandler is told about
+type recordingHotSpots struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingHotSpots) HotSpotDetected(w HotWallet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, "hot:"+w.UserID)
+}
+
+func (r *recordingHotSpots) HotSpotCleared(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, "cool:"+userID)
+}
+
+func TestGetHotWallets_Contention(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("busy", "Busy", "b@example.com")
+	ws.CreateUser("quiet", "Quiet", "q@example.com")
+	ws.Deposit("quiet", 10, "seed")
+
+	// Hold busy's lock so a deposit has to wait for it
+	lock := ws.userLocks.getLock("busy")
+	lock.Lock()
+	done := make(chan error)
+	go func() { done <- ws.Deposit("busy", 10, "seed") }()
+	time.Sleep(20 * time.Millisecond)
+	lock.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+
+	hot := ws.GetHotWallets(1)
+	if len(hot) != 1 || hot[0].UserID != "busy" {
+		t.Fatalf("GetHotWallets(1) = %+v, want busy first", hot)
+	}
+	if hot[0].Contended != 1 || hot[0].LockWait <= 0 || hot[0].AverageWait() != hot[0].LockWait {
+		t.Errorf("busy = %+v, want one contended acquisition with a wait", hot[0])
+	}
+	if all := ws.GetHotWallets(0); len(all) < 2 {
+		t.Errorf("GetHotWallets(0) = %+v, want both wallets", all)
+	}
+}
+
+func TestCheckHotSpots(t *testing.T) {
+	handler := &recordingHotSpots{}
+	ws, clock := newTestService(WithHotSpotDetection(HotSpotPolicy{
+		Window:       time.Minute,
+		MinContended: 3,
+		MinLockWait:  time.Second,
+		Handlers:     []HotSpotHandler{handler},
+	}))
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		record  func()
+		wantHot []string
+	}{
+		{"quiet", 0, func() { ws.hotspots.record("a", 0, false, clock.Now()) }, nil},
+		{"contended", time.Second, func() {
+			for i := 0; i < 3; i++ {
+				ws.hotspots.record("a", time.Millisecond, true, clock.Now())
+			}
+		}, []string{"a"}},
+		{"long wait", time.Second, func() { ws.hotspots.record("b", 2*time.Second, true, clock.Now()) }, []string{"a", "b"}},
+		{"previous window still counts", time.Minute, func() {}, []string{"a", "b"}},
+		{"cooled down", 2 * time.Minute, func() {}, nil},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			tt.record()
+			var got []string
+			for _, w := range ws.CheckHotSpots() {
+				got = append(got, w.UserID)
+			}
+			if !reflect.DeepEqual(got, tt.wantHot) {
+				t.Errorf("CheckHotSpots() = %v, want %v", got, tt.wantHot)
+			}
+			for _, userID := range []string{"a", "b"} {
+				if want := slices.Contains(tt.wantHot, userID); ws.IsHotWallet(userID) != want {
+					t.Errorf("IsHotWallet(%s) = %v, want %v", userID, !want, want)
+				}
+			}
+		})
+	}
+
+	want := []string{"hot:a", "hot:b", "cool:a", "cool:b"}
+	if !reflect.DeepEqual(handler.events, want) {
+		t.Errorf("handler events = %v, want %v", handler.events, want)
+	}
+	if got := ws.GetHotWallets(0); len(got) != 0 {
+		t.Errorf("GetHotWallets() after cooling = %+v, want idle wallets forgotten", got)
+	}
+}
@@ -0,0 +1,186 @@
+// internal/wallet/idempotency.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for idempotent operations
+var (
+	ErrIdempotencyKeyMissing = errors.New("idempotency key is required")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used for a different operation")
+)
+
+// Metadata recording on a transaction the key of the request that created it and the
+// user who sent it, so keys survive restarts and restores along with the log
+const (
+	metaIdempotencyKey  = "idempotency_key"
+	metaIdempotencyUser = "idempotency_user"
+)
+
+// idempotencyScope is a key as chosen by one user. Keys are client-chosen, so two users
+// may pick the same one; each user's keys are independent.
+type idempotencyScope struct {
+	userID string
+	key    string
+}
+
+// idempotencyScopeOf returns the scope tx was created under and whether it carries a
+// key. Entries logged before keys were scoped belong to their sender.
+func idempotencyScopeOf(tx *Transaction) (idempotencyScope, bool) {
+	key := tx.Metadata[metaIdempotencyKey]
+	if key == "" {
+		return idempotencyScope{}, false
+	}
+	userID, scoped := tx.Metadata[metaIdempotencyUser]
+	if !scoped {
+		userID = tx.FromUserID
+	}
+	return idempotencyScope{userID: userID, key: key}, true
+}
+
+// idempotencyGate serializes requests sharing a key, so a retry racing the original
+// waits for it instead of applying a second time
+type idempotencyGate struct {
+	mu       sync.Mutex
+	inflight map[idempotencyScope]chan struct{}
+}
+
+// DepositIdempotent is DepositDecimal keyed by a client-supplied idempotency key.
+// Replaying a key returns the transaction the first request created without applying
+// it again. Failed requests apply nothing and leave the key unused, so they can be
+// retried.
+func (ws *WalletService) DepositIdempotent(key, userID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.DepositIdempotentContext(context.Background(), key, userID, amount, description)
+}
+
+// DepositIdempotentContext is DepositIdempotent on behalf of a caller's ctx
+func (ws *WalletService) DepositIdempotentContext(ctx context.Context, key, userID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	want := &Transaction{FromUserID: userID, ToUserID: userID, Amount: amount, Type: TransactionDeposit}
+	tx, err := ws.idempotent(key, want, func(meta map[string]string) (*Transaction, error) {
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrInvalidAmount
+		}
+		return ws.deposit(ctx, userID, amount, description, meta)
+	})
+	ws.noteFailure(FailedOperation{UserID: userID, Type: TransactionDeposit, Amount: amount, Description: description, IdempotencyKey: key}, err)
+	return tx, err
+}
+
+// WithdrawIdempotent is WithdrawDecimal keyed by a client-supplied idempotency key, with
+// the replay rules of DepositIdempotent
+func (ws *WalletService) WithdrawIdempotent(key, userID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.WithdrawIdempotentContext(context.Background(), key, userID, amount, description)
+}
+
+// WithdrawIdempotentContext is WithdrawIdempotent on behalf of a caller's ctx
+func (ws *WalletService) WithdrawIdempotentContext(ctx context.Context, key, userID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	want := &Transaction{FromUserID: userID, ToUserID: userID, Amount: amount, Type: TransactionWithdraw}
+	tx, err := ws.idempotent(key, want, func(meta map[string]string) (*Transaction, error) {
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrInvalidAmount
+		}
+		return ws.withdraw(ctx, userID, amount, description, meta)
+	})
+	ws.noteFailure(FailedOperation{UserID: userID, Type: TransactionWithdraw, Amount: amount, Description: description, IdempotencyKey: key}, err)
+	return tx, err
+}
+
+// TransferIdempotent is TransferDecimal keyed by a client-supplied idempotency key, with
+// the replay rules of DepositIdempotent. A transfer held for review was applied: its
+// replays return the held transaction with ErrTransferHeld again.
+func (ws *WalletService) TransferIdempotent(key, fromUserID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.TransferIdempotentContext(context.Background(), key, fromUserID, toUserID, amount, description)
+}
+
+// TransferIdempotentContext is TransferIdempotent on behalf of a caller's ctx
+func (ws *WalletService) TransferIdempotentContext(ctx context.Context, key, fromUserID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	want := &Transaction{FromUserID: fromUserID, ToUserID: toUserID, Amount: amount, Type: TransactionTransfer}
+	tx, err := ws.idempotent(key, want, func(meta map[string]string) (*Transaction, error) {
+		return ws.transfer(ctx, fromUserID, toUserID, amount, description, transferOptions{metadata: meta})
+	})
+	ws.noteFailure(FailedOperation{UserID: fromUserID, Type: TransactionTransfer, CounterpartyID: toUserID, Amount: amount, Description: description, IdempotencyKey: key}, err)
+	return tx, err
+}
+
+// idempotent runs apply unless a transaction already carries key from the same user,
+// want.FromUserID, in which case that transaction is returned if it matches want
+func (ws *WalletService) idempotent(key string, want *Transaction, apply func(meta map[string]string) (*Transaction, error)) (*Transaction, error) {
+	if key == "" {
+		return nil, ErrIdempotencyKeyMissing
+	}
+	scope := idempotencyScope{userID: want.FromUserID, key: key}
+
+	var done chan struct{}
+	for done == nil {
+		ws.idempotency.mu.Lock()
+		if wait, busy := ws.idempotency.inflight[scope]; busy {
+			ws.idempotency.mu.Unlock()
+			<-wait
+			continue
+		}
+		if tx, seen := ws.keyedTransaction(scope); seen {
+			ws.idempotency.mu.Unlock()
+			return ws.replay(tx, want)
+		}
+		if ws.idempotency.inflight == nil {
+			ws.idempotency.inflight = make(map[idempotencyScope]chan struct{})
+		}
+		done = make(chan struct{})
+		ws.idempotency.inflight[scope] = done
+		ws.idempotency.mu.Unlock()
+	}
+	defer func() {
+		ws.idempotency.mu.Lock()
+		delete(ws.idempotency.inflight, scope)
+		ws.idempotency.mu.Unlock()
+		close(done)
+	}()
+
+	return apply(map[string]string{metaIdempotencyKey: key, metaIdempotencyUser: scope.userID})
+}
+
+// replay returns the result of the request that first used key
+func (ws *WalletService) replay(tx, want *Transaction) (*Transaction, error) {
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	sameType := tx.Type == want.Type || (want.Type == TransactionTransfer && tx.Type == TransactionComplianceHold) ||
+		(want.Type == TransactionWithdraw && tx.Type == TransactionOverdraft)
+	if !sameType || tx.FromUserID != want.FromUserID || tx.ToUserID != want.ToUserID || !tx.Amount.Equal(want.Amount) {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	ws.metrics.IncCounter("idempotent_replays_total", map[string]string{"type": string(want.Type)})
+	if tx.Type == TransactionComplianceHold {
+		return tx, ErrTransferHeld
+	}
+	return tx, nil
+}
+
+// keyedTransaction looks up the transaction created under scope. It reports seen with
+// a nil transaction when the key is known but the transaction has left the hot log and
+// cannot be found in the archive.
+func (ws *WalletService) keyedTransaction(scope idempotencyScope) (*Transaction, bool) {
+	ws.mu.RLock()
+	txID, seen := ws.txIndex.keys[scope]
+	ws.mu.RUnlock()
+	if !seen {
+		return nil, false
+	}
+	tx, _ := ws.findTransaction(txID)
+	return tx, true
+}
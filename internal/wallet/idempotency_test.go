@@ -0,0 +1,140 @@
+// internal/wallet/idempotency_test.go
+package wallet
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIdempotentOperations(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+
+	first, err := ws.DepositIdempotent("dep-1", "alice", decimal.NewFromInt(100), "seed")
+	if err != nil {
+		t.Fatalf("DepositIdempotent() error = %v", err)
+	}
+
+	// call runs one keyed operation; deposits and withdrawals use from as the user
+	call := func(op, key, from, to string, amount int64) (*Transaction, error) {
+		switch op {
+		case "deposit":
+			return ws.DepositIdempotent(key, from, decimal.NewFromInt(amount), "")
+		case "withdraw":
+			return ws.WithdrawIdempotent(key, from, decimal.NewFromInt(amount), "")
+		}
+		return ws.TransferIdempotent(key, from, to, decimal.NewFromInt(amount), "")
+	}
+
+	tests := []struct {
+		name     string
+		op       string
+		key      string
+		from, to string
+		amount   int64
+		wantErr  error
+		wantTx   string // ID of the replayed transaction, if any
+	}{
+		{"deposit replay", "deposit", "dep-1", "alice", "", 100, nil, first.ID},
+		{"key reused for another amount", "deposit", "dep-1", "alice", "", 10, ErrIdempotencyKeyReused, ""},
+		{"key reused for another operation", "withdraw", "dep-1", "alice", "", 100, ErrIdempotencyKeyReused, ""},
+		{"missing key", "transfer", "", "alice", "bob", 10, ErrIdempotencyKeyMissing, ""},
+		{"failed request", "withdraw", "wd-1", "bob", "", 10, ErrInsufficientBalance, ""},
+		{"transfer", "transfer", "tr-1", "alice", "bob", 40, nil, ""},
+		{"failed key retried once funded", "withdraw", "wd-1", "bob", "", 10, nil, ""},
+		{"another user's key", "deposit", "dep-1", "bob", "", 20, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := call(tt.op, tt.key, tt.from, tt.to, tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantTx != "" && (tx == nil || tx.ID != tt.wantTx) {
+				t.Errorf("replayed %+v, want transaction %s", tx, tt.wantTx)
+			}
+		})
+	}
+
+	// Replays after a restore still find the original
+	restored, err := RestoreSnapshot(ws.Snapshot())
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	if _, err := restored.TransferIdempotent("tr-1", "alice", "bob", decimal.NewFromInt(40), ""); err != nil {
+		t.Errorf("replay after restore error = %v", err)
+	}
+	for _, svc := range []*WalletService{ws, restored} {
+		// Keys are scoped by user: each replay of dep-1 finds its own user's deposit
+		for _, user := range []struct {
+			id     string
+			amount int64
+		}{{"alice", 100}, {"bob", 20}} {
+			tx, err := svc.DepositIdempotent("dep-1", user.id, decimal.NewFromInt(user.amount), "")
+			if err != nil || tx.ToUserID != user.id {
+				t.Errorf("%s's replay of dep-1 = %+v, %v", user.id, tx, err)
+			}
+		}
+		alice, _ := svc.GetBalanceDecimal("alice")
+		bob, _ := svc.GetBalanceDecimal("bob")
+		if !alice.Equal(decimal.NewFromInt(60)) || !bob.Equal(decimal.NewFromInt(50)) {
+			t.Errorf("balances = %s / %s, want 60 / 50", alice, bob)
+		}
+	}
+}
+
+func TestIdempotentConcurrentRetries(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	var wg sync.WaitGroup
+	ids := make([]string, 20)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := ws.TransferIdempotent("retry", "alice", "bob", decimal.NewFromInt(25), "")
+			if err != nil {
+				t.Errorf("TransferIdempotent() error = %v", err)
+				return
+			}
+			ids[i] = tx.ID
+		}(i)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		if id != ids[0] {
+			t.Fatalf("retries returned different transactions: %v", ids)
+		}
+	}
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("bob balance = %s, want 25 after 20 retries of one transfer", b)
+	}
+}
+
+func TestIdempotentHeldTransferReplay(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.RegisterHoldRule("review_all", func(op *Operation) string { return "manual review" })
+
+	held, err := ws.TransferIdempotent("held-1", "alice", "bob", decimal.NewFromInt(30), "")
+	if err != ErrTransferHeld {
+		t.Fatalf("TransferIdempotent() error = %v, want %v", err, ErrTransferHeld)
+	}
+	replayed, err := ws.TransferIdempotent("held-1", "alice", "bob", decimal.NewFromInt(30), "")
+	if err != ErrTransferHeld || replayed.ID != held.ID {
+		t.Errorf("replay = %v, %v; want held transaction %s", replayed, err, held.ID)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("alice balance = %s, want 70", b)
+	}
+}
@@ -0,0 +1,206 @@
+// internal/wallet/identities.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Error definitions for external identities
+var (
+	ErrInvalidIdentity  = errors.New("invalid external identity")
+	ErrIdentityTaken    = errors.New("external identity already linked to another user")
+	ErrIdentityNotFound = errors.New("external identity not linked to user")
+)
+
+// IdentityKind is the kind of identifier an external identity is
+type IdentityKind string
+
+const (
+	// IdentityOAuth is an OAuth or OpenID Connect subject, unique within its Issuer
+	IdentityOAuth IdentityKind = "oauth"
+	// IdentityPhone is a phone number in E.164 form; it has no Issuer
+	IdentityPhone IdentityKind = "phone"
+)
+
+// ExternalIdentity ties an identifier from outside the service to a user, so callers
+// known only by it can be resolved to their wallet. Each identifier belongs to at most
+// one user. Callers link an identifier once they have verified the user controls it.
+type ExternalIdentity struct {
+	Kind     IdentityKind
+	Issuer   string
+	Subject  string
+	UserID   string
+	LinkedAt int64
+}
+
+// identityBook indexes external identities by key and by user
+type identityBook struct {
+	mu     sync.Mutex
+	byKey  map[string]*ExternalIdentity
+	byUser map[string][]*ExternalIdentity // in linking order
+}
+
+// LinkIdentity links a verified identifier to userID. Phone numbers are stored in E.164
+// form, so differently formatted numbers match.
+func (ws *WalletService) LinkIdentity(userID string, identity ExternalIdentity) (*ExternalIdentity, error) {
+	identity, err := normalizeIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+
+	ws.identities.mu.Lock()
+	defer ws.identities.mu.Unlock()
+
+	key := identity.key()
+	if linked, exists := ws.identities.byKey[key]; exists {
+		if linked.UserID != userID {
+			return nil, ErrIdentityTaken
+		}
+		copied := *linked
+		return &copied, nil
+	}
+
+	identity.UserID = userID
+	identity.LinkedAt = ws.now().Unix()
+	if ws.identities.byKey == nil {
+		ws.identities.byKey = make(map[string]*ExternalIdentity)
+		ws.identities.byUser = make(map[string][]*ExternalIdentity)
+	}
+	ws.identities.byKey[key] = &identity
+	ws.identities.byUser[userID] = append(ws.identities.byUser[userID], &identity)
+	ws.metrics.IncCounter("identities_linked_total", map[string]string{"kind": string(identity.Kind)})
+
+	copied := identity
+	return &copied, nil
+}
+
+// UnlinkIdentity removes an identifier from userID, freeing it for another user
+func (ws *WalletService) UnlinkIdentity(userID string, identity ExternalIdentity) error {
+	identity, err := normalizeIdentity(identity)
+	if err != nil {
+		return err
+	}
+
+	ws.identities.mu.Lock()
+	defer ws.identities.mu.Unlock()
+
+	key := identity.key()
+	linked, exists := ws.identities.byKey[key]
+	if !exists || linked.UserID != userID {
+		return ErrIdentityNotFound
+	}
+	delete(ws.identities.byKey, key)
+
+	kept := ws.identities.byUser[userID][:0]
+	for _, id := range ws.identities.byUser[userID] {
+		if id != linked {
+			kept = append(kept, id)
+		}
+	}
+	if len(kept) == 0 {
+		delete(ws.identities.byUser, userID)
+	} else {
+		ws.identities.byUser[userID] = kept
+	}
+	return nil
+}
+
+// GetUserByIdentity returns the user an identifier is linked to
+func (ws *WalletService) GetUserByIdentity(kind IdentityKind, issuer, subject string) (*User, error) {
+	identity, err := normalizeIdentity(ExternalIdentity{Kind: kind, Issuer: issuer, Subject: subject})
+	if err != nil {
+		return nil, err
+	}
+
+	ws.identities.mu.Lock()
+	linked, exists := ws.identities.byKey[identity.key()]
+	var userID string
+	if exists {
+		userID = linked.UserID
+	}
+	ws.identities.mu.Unlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	user, exists := ws.users[userID]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// ListIdentities returns the identifiers linked to userID, ordered by kind, issuer and
+// subject
+func (ws *WalletService) ListIdentities(userID string) ([]ExternalIdentity, error) {
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+
+	ws.identities.mu.Lock()
+	defer ws.identities.mu.Unlock()
+
+	list := make([]ExternalIdentity, 0, len(ws.identities.byUser[userID]))
+	for _, id := range ws.identities.byUser[userID] {
+		list = append(list, *id)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].key() < list[j].key() })
+	return list, nil
+}
+
+// key indexes an identity; subjects are only unique within their kind and issuer
+func (id ExternalIdentity) key() string {
+	return string(id.Kind) + "\x00" + id.Issuer + "\x00" + id.Subject
+}
+
+// normalizeIdentity validates an identifier and brings it to the form it is indexed in
+func normalizeIdentity(id ExternalIdentity) (ExternalIdentity, error) {
+	id.Issuer = strings.TrimSpace(id.Issuer)
+	id.Subject = strings.TrimSpace(id.Subject)
+	switch id.Kind {
+	case IdentityOAuth:
+		if id.Issuer == "" || id.Subject == "" {
+			return id, ErrInvalidIdentity
+		}
+	case IdentityPhone:
+		phone, ok := normalizePhone(id.Subject)
+		if id.Issuer != "" || !ok {
+			return id, ErrInvalidIdentity
+		}
+		id.Subject = phone
+	default:
+		return id, ErrInvalidIdentity
+	}
+	return id, nil
+}
+
+// normalizePhone returns number in E.164 form, dropping the spaces, dashes, dots and
+// parentheses people write numbers with. The number must carry its country code.
+func normalizePhone(number string) (string, bool) {
+	if !strings.HasPrefix(number, "+") {
+		return "", false
+	}
+	digits := make([]byte, 0, len(number))
+	for _, r := range number[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return "", false
+		}
+	}
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", false
+	}
+	return "+" + string(digits), true
+}
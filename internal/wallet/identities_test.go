@@ -0,0 +1,84 @@
+// internal/wallet/identities_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLinkIdentity(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+
+	google := ExternalIdentity{Kind: IdentityOAuth, Issuer: "https://accounts.google.com", Subject: "1089"}
+	if _, err := ws.LinkIdentity("alice", google); err != nil {
+		t.Fatalf("LinkIdentity() error = %v", err)
+	}
+	if _, err := ws.LinkIdentity("alice", ExternalIdentity{Kind: IdentityPhone, Subject: "+1 (415) 555-0100"}); err != nil {
+		t.Fatalf("LinkIdentity(phone) error = %v", err)
+	}
+
+	steps := []struct {
+		name     string
+		userID   string
+		identity ExternalIdentity
+		wantErr  error
+	}{
+		{"relinking is a no-op", "alice", google, nil},
+		{"same subject, other user", "bob", google, ErrIdentityTaken},
+		{"phone written differently", "bob", ExternalIdentity{Kind: IdentityPhone, Subject: "+14155550100"}, ErrIdentityTaken},
+		{"same subject, other issuer", "bob", ExternalIdentity{Kind: IdentityOAuth, Issuer: "https://login.example.com", Subject: "1089"}, nil},
+		{"unknown user", "ghost", ExternalIdentity{Kind: IdentityOAuth, Issuer: "https://accounts.google.com", Subject: "7"}, ErrUserNotFound},
+		{"no issuer", "bob", ExternalIdentity{Kind: IdentityOAuth, Subject: "7"}, ErrInvalidIdentity},
+		{"phone without country code", "bob", ExternalIdentity{Kind: IdentityPhone, Subject: "415 555 0100"}, ErrInvalidIdentity},
+		{"phone with letters", "bob", ExternalIdentity{Kind: IdentityPhone, Subject: "+1 415 CALL NOW"}, ErrInvalidIdentity},
+		{"phone too short", "bob", ExternalIdentity{Kind: IdentityPhone, Subject: "+1234"}, ErrInvalidIdentity},
+		{"phone with issuer", "bob", ExternalIdentity{Kind: IdentityPhone, Issuer: "carrier", Subject: "+442079460000"}, ErrInvalidIdentity},
+		{"unknown kind", "bob", ExternalIdentity{Kind: "email", Subject: "b@example.com"}, ErrInvalidIdentity},
+	}
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := ws.LinkIdentity(s.userID, s.identity); !errors.Is(err, s.wantErr) {
+				t.Errorf("LinkIdentity() error = %v, want %v", err, s.wantErr)
+			}
+		})
+	}
+
+	if user, err := ws.GetUserByIdentity(IdentityPhone, "", "+1.415.555.0100"); err != nil || user.ID != "alice" {
+		t.Errorf("GetUserByIdentity(phone) = %+v, %v, want alice", user, err)
+	}
+	if user, err := ws.GetUserByIdentity(IdentityOAuth, "https://login.example.com", "1089"); err != nil || user.ID != "bob" {
+		t.Errorf("GetUserByIdentity(other issuer) = %+v, %v, want bob", user, err)
+	}
+	list, _ := ws.ListIdentities("alice")
+	if len(list) != 2 || list[0].Kind != IdentityOAuth || list[1].Subject != "+14155550100" {
+		t.Errorf("ListIdentities() = %+v", list)
+	}
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	phone := ExternalIdentity{Kind: IdentityPhone, Subject: "+44 20 7946 0000"}
+	ws.LinkIdentity("alice", phone)
+
+	if err := ws.UnlinkIdentity("bob", phone); err != ErrIdentityNotFound {
+		t.Errorf("UnlinkIdentity(bob) error = %v, want %v", err, ErrIdentityNotFound)
+	}
+	if err := ws.UnlinkIdentity("alice", phone); err != nil {
+		t.Fatalf("UnlinkIdentity() error = %v", err)
+	}
+	if _, err := ws.GetUserByIdentity(IdentityPhone, "", phone.Subject); err != ErrUserNotFound {
+		t.Errorf("GetUserByIdentity() after unlinking error = %v, want %v", err, ErrUserNotFound)
+	}
+	if list, _ := ws.ListIdentities("alice"); len(list) != 0 {
+		t.Errorf("ListIdentities() after unlinking = %+v", list)
+	}
+
+	// The number is free for another user
+	if linked, err := ws.LinkIdentity("bob", phone); err != nil || linked.UserID != "bob" || linked.Subject != "+442079460000" {
+		t.Errorf("LinkIdentity(bob) = %+v, %v", linked, err)
+	}
+}
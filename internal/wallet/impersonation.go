@@ -0,0 +1,305 @@
+// internal/wallet/impersonation.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for staff impersonation
+var (
+	ErrImpersonationNotFound  = errors.New("impersonation session not found")
+	ErrImpersonationExpired   = errors.New("impersonation session has ended")
+	ErrImpersonationReadOnly  = errors.New("impersonation session does not allow writes")
+	ErrImpersonationReason    = errors.New("impersonation requires a reason code")
+	ErrImpersonationDuration  = errors.New("impersonation duration out of range")
+	ErrImpersonationForbidden = errors.New("staff role cannot open a writable impersonation session")
+)
+
+// MaxImpersonationDuration bounds how long a session may stay open
+const MaxImpersonationDuration = time.Hour
+
+// Metadata keys recorded on transactions made through an impersonation session
+const (
+	metaImpersonatedBy       = "impersonated_by"
+	metaImpersonationSession = "impersonation_session"
+)
+
+// ImpersonationReason is the reason code a staff member gives for acting as a user
+type ImpersonationReason string
+
+const (
+	ReasonCustomerRequest ImpersonationReason = "customer_request"
+	ReasonDispute         ImpersonationReason = "dispute_investigation"
+	ReasonFraudReview     ImpersonationReason = "fraud_review"
+	ReasonTechnicalIssue  ImpersonationReason = "technical_issue"
+)
+
+var impersonationReasons = map[ImpersonationReason]bool{
+	ReasonCustomerRequest: true,
+	ReasonDispute:         true,
+	ReasonFraudReview:     true,
+	ReasonTechnicalIssue:  true,
+}
+
+// ImpersonationRequest opens a session. Writes are off unless AllowWrites is set, which
+// only admins may do.
+type ImpersonationRequest struct {
+	StaffID      string
+	TargetUserID string
+	Reason       ImpersonationReason
+	Note         string
+	Duration     time.Duration // at most MaxImpersonationDuration
+	AllowWrites  bool
+}
+
+// ImpersonationSession is a time-limited grant for a staff member to act as a user
+type ImpersonationSession struct {
+	ID           string
+	StaffID      string
+	TargetUserID string
+	Reason       ImpersonationReason
+	Note         string
+	AllowWrites  bool
+	StartedAt    int64
+	ExpiresAt    int64
+	EndedAt      int64 // set when ended early or found expired
+}
+
+// ImpersonationAuditEntry records one action taken in, or attempted through, a session
+type ImpersonationAuditEntry struct {
+	ID           string
+	SessionID    string
+	StaffID      string
+	TargetUserID string
+	Reason       ImpersonationReason
+	Action       string // e.g. "session_started", "read_balance", "transfer"
+	Detail       string
+	Error        string // set when the action was refused or failed
+	Timestamp    int64
+}
+
+// ImpersonationAuditFilter selects audit entries. Zero fields match everything.
+type ImpersonationAuditFilter struct {
+	SessionID    string
+	StaffID      string
+	TargetUserID string
+}
+
+// impersonationState holds sessions and their audit trail
+type impersonationState struct {
+	mu       sync.Mutex
+	sessions map[string]*ImpersonationSession
+	audit    []ImpersonationAuditEntry
+}
+
+// StartImpersonation opens a session for a registered staff member to act as a user
+func (ws *WalletService) StartImpersonation(req ImpersonationRequest) (*ImpersonationSession, error) {
+	if !impersonationReasons[req.Reason] {
+		return nil, ErrImpersonationReason
+	}
+	if req.Duration <= 0 || req.Duration > MaxImpersonationDuration {
+		return nil, ErrImpersonationDuration
+	}
+
+	ws.annotations.mu.Lock()
+	role, isStaff := ws.annotations.staff[req.StaffID]
+	ws.annotations.mu.Unlock()
+	if !isStaff {
+		return nil, ErrNotStaff
+	}
+	if req.AllowWrites && role != StaffAdmin {
+		return nil, ErrImpersonationForbidden
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.users[req.TargetUserID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	now := ws.now()
+	session := &ImpersonationSession{
+		ID:           ws.newID("imp"),
+		StaffID:      req.StaffID,
+		TargetUserID: req.TargetUserID,
+		Reason:       req.Reason,
+		Note:         req.Note,
+		AllowWrites:  req.AllowWrites,
+		StartedAt:    now.Unix(),
+		ExpiresAt:    now.Add(req.Duration).Unix(),
+	}
+
+	ws.impersonation.mu.Lock()
+	defer ws.impersonation.mu.Unlock()
+
+	if ws.impersonation.sessions == nil {
+		ws.impersonation.sessions = make(map[string]*ImpersonationSession)
+	}
+	ws.impersonation.sessions[session.ID] = session
+	ws.recordImpersonation(session, "session_started", req.Note, nil)
+	ws.metrics.IncCounter("impersonation_sessions_total", map[string]string{"reason": string(req.Reason)})
+
+	copied := *session
+	return &copied, nil
+}
+
+// EndImpersonation closes a session before it expires
+func (ws *WalletService) EndImpersonation(sessionID string) error {
+	ws.impersonation.mu.Lock()
+	defer ws.impersonation.mu.Unlock()
+
+	session, exists := ws.impersonation.sessions[sessionID]
+	if !exists {
+		return ErrImpersonationNotFound
+	}
+	if session.EndedAt != 0 {
+		return ErrImpersonationExpired
+	}
+	session.EndedAt = ws.now().Unix()
+	ws.recordImpersonation(session, "session_ended", "", nil)
+	return nil
+}
+
+// GetImpersonationSession returns a session by ID
+func (ws *WalletService) GetImpersonationSession(sessionID string) (*ImpersonationSession, error) {
+	ws.impersonation.mu.Lock()
+	defer ws.impersonation.mu.Unlock()
+
+	session, exists := ws.impersonation.sessions[sessionID]
+	if !exists {
+		return nil, ErrImpersonationNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// ListImpersonationAudit returns the audit entries matching filter, oldest first
+func (ws *WalletService) ListImpersonationAudit(filter ImpersonationAuditFilter) []ImpersonationAuditEntry {
+	ws.impersonation.mu.Lock()
+	defer ws.impersonation.mu.Unlock()
+
+	result := []ImpersonationAuditEntry{}
+	for _, e := range ws.impersonation.audit {
+		if (filter.SessionID == "" || e.SessionID == filter.SessionID) &&
+			(filter.StaffID == "" || e.StaffID == filter.StaffID) &&
+			(filter.TargetUserID == "" || e.TargetUserID == filter.TargetUserID) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// ImpersonatedBalance returns the target user's balance
+func (ws *WalletService) ImpersonatedBalance(sessionID string) (decimal.Decimal, error) {
+	session, err := ws.useImpersonation(sessionID, "read_balance", false)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return ws.GetBalanceDecimal(session.TargetUserID)
+}
+
+// ImpersonatedHistory returns the target user's transaction history
+func (ws *WalletService) ImpersonatedHistory(sessionID string) ([]*Transaction, error) {
+	session, err := ws.useImpersonation(sessionID, "read_history", false)
+	if err != nil {
+		return nil, err
+	}
+	return ws.GetTransactionHistory(session.TargetUserID)
+}
+
+// ImpersonatedPendingItems returns the target user's pending items
+func (ws *WalletService) ImpersonatedPendingItems(sessionID string) ([]PendingItem, error) {
+	session, err := ws.useImpersonation(sessionID, "read_pending", false)
+	if err != nil {
+		return nil, err
+	}
+	return ws.GetPendingItems(session.TargetUserID)
+}
+
+// ImpersonatedTransfer transfers from the target user through a writable session. The
+// transaction records the staff member and session in its metadata.
+func (ws *WalletService) ImpersonatedTransfer(sessionID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.ImpersonatedTransferContext(context.Background(), sessionID, toUserID, amount, description)
+}
+
+// ImpersonatedTransferContext is ImpersonatedTransfer on behalf of a caller's ctx
+func (ws *WalletService) ImpersonatedTransferContext(ctx context.Context, sessionID, toUserID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	session, err := ws.useImpersonation(sessionID, "transfer", true)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ws.transfer(ctx, session.TargetUserID, toUserID, amount, description, transferOptions{
+		metadata: map[string]string{
+			metaImpersonatedBy:       session.StaffID,
+			metaImpersonationSession: session.ID,
+		},
+	})
+
+	ws.impersonation.mu.Lock()
+	detail := toUserID + " " + amount.String()
+	if tx != nil {
+		detail = tx.ID + " to " + detail
+	}
+	ws.recordImpersonation(session, "transfer_result", detail, err)
+	ws.impersonation.mu.Unlock()
+	return tx, err
+}
+
+// useImpersonation checks that a session is open and allows the action, and records the
+// attempt either way
+func (ws *WalletService) useImpersonation(sessionID, action string, write bool) (*ImpersonationSession, error) {
+	ws.impersonation.mu.Lock()
+	defer ws.impersonation.mu.Unlock()
+
+	session, exists := ws.impersonation.sessions[sessionID]
+	if !exists {
+		return nil, ErrImpersonationNotFound
+	}
+
+	var err error
+	now := ws.now().Unix()
+	switch {
+	case session.EndedAt != 0:
+		err = ErrImpersonationExpired
+	case now >= session.ExpiresAt:
+		session.EndedAt = session.ExpiresAt
+		ws.recordImpersonation(session, "session_expired", "", nil)
+		err = ErrImpersonationExpired
+	case write && !session.AllowWrites:
+		err = ErrImpersonationReadOnly
+	}
+	ws.recordImpersonation(session, action, "", err)
+	if err != nil {
+		return nil, err
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// recordImpersonation appends an audit entry. Caller must hold ws.impersonation.mu.
+func (ws *WalletService) recordImpersonation(session *ImpersonationSession, action, detail string, err error) {
+	entry := ImpersonationAuditEntry{
+		ID:           ws.newID("audit"),
+		SessionID:    session.ID,
+		StaffID:      session.StaffID,
+		TargetUserID: session.TargetUserID,
+		Reason:       session.Reason,
+		Action:       action,
+		Detail:       detail,
+		Timestamp:    ws.now().Unix(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	ws.impersonation.audit = append(ws.impersonation.audit, entry)
+}
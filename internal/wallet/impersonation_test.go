@@ -0,0 +1,134 @@
+// internal/wallet/impersonation_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestImpersonation_ReadOnlySession(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.SetStaffRole("sam", StaffSupport)
+	ws.SetStaffRole("ada", StaffAdmin)
+
+	session, err := ws.StartImpersonation(ImpersonationRequest{
+		StaffID: "sam", TargetUserID: "alice", Reason: ReasonCustomerRequest, Note: "ticket 42", Duration: 15 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("StartImpersonation() error = %v", err)
+	}
+
+	if b, err := ws.ImpersonatedBalance(session.ID); err != nil || !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("ImpersonatedBalance() = %s, %v, want 100", b, err)
+	}
+	if history, err := ws.ImpersonatedHistory(session.ID); err != nil || len(history) != 1 {
+		t.Errorf("ImpersonatedHistory() = %d entries, %v", len(history), err)
+	}
+	if _, err := ws.ImpersonatedTransfer(session.ID, "bob", decimal.NewFromInt(5), ""); err != ErrImpersonationReadOnly {
+		t.Errorf("ImpersonatedTransfer() error = %v, want %v", err, ErrImpersonationReadOnly)
+	}
+
+	clock.Advance(16 * time.Minute)
+	if _, err := ws.ImpersonatedPendingItems(session.ID); err != ErrImpersonationExpired {
+		t.Errorf("ImpersonatedPendingItems() after expiry error = %v, want %v", err, ErrImpersonationExpired)
+	}
+
+	wantActions := []struct{ action, err string }{
+		{"session_started", ""},
+		{"read_balance", ""},
+		{"read_history", ""},
+		{"transfer", ErrImpersonationReadOnly.Error()},
+		{"session_expired", ""},
+		{"read_pending", ErrImpersonationExpired.Error()},
+	}
+	audit := ws.ListImpersonationAudit(ImpersonationAuditFilter{SessionID: session.ID})
+	if len(audit) != len(wantActions) {
+		t.Fatalf("audit = %+v, want %d entries", audit, len(wantActions))
+	}
+	for i, want := range wantActions {
+		e := audit[i]
+		if e.Action != want.action || e.Error != want.err || e.StaffID != "sam" || e.TargetUserID != "alice" || e.Reason != ReasonCustomerRequest {
+			t.Errorf("audit[%d] = %+v, want %s (error %q)", i, e, want.action, want.err)
+		}
+	}
+}
+
+func TestImpersonation_WritableSession(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.SetStaffRole("sam", StaffSupport)
+	ws.SetStaffRole("ada", StaffAdmin)
+
+	session, err := ws.StartImpersonation(ImpersonationRequest{
+		StaffID: "ada", TargetUserID: "alice", Reason: ReasonDispute, Duration: time.Hour, AllowWrites: true,
+	})
+	if err != nil {
+		t.Fatalf("StartImpersonation() error = %v", err)
+	}
+	tx, err := ws.ImpersonatedTransfer(session.ID, "bob", decimal.NewFromInt(30), "refund on behalf")
+	if err != nil {
+		t.Fatalf("ImpersonatedTransfer() error = %v", err)
+	}
+	if tx.Metadata[metaImpersonatedBy] != "ada" || tx.Metadata[metaImpersonationSession] != session.ID {
+		t.Errorf("transaction metadata = %v, want staff and session recorded", tx.Metadata)
+	}
+	if b, _ := ws.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("bob balance = %s, want 30", b)
+	}
+
+	if err := ws.EndImpersonation(session.ID); err != nil {
+		t.Fatalf("EndImpersonation() error = %v", err)
+	}
+	if _, err := ws.ImpersonatedBalance(session.ID); err != ErrImpersonationExpired {
+		t.Errorf("ImpersonatedBalance() after end error = %v, want %v", err, ErrImpersonationExpired)
+	}
+	if err := ws.EndImpersonation(session.ID); err != ErrImpersonationExpired {
+		t.Errorf("EndImpersonation() twice error = %v, want %v", err, ErrImpersonationExpired)
+	}
+
+	audit := ws.ListImpersonationAudit(ImpersonationAuditFilter{StaffID: "ada", TargetUserID: "alice"})
+	if len(audit) != 5 || audit[2].Action != "transfer_result" || audit[2].Error != "" || audit[3].Action != "session_ended" {
+		t.Errorf("audit = %+v", audit)
+	}
+}
+
+func TestImpersonation_StartValidation(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.SetStaffRole("sam", StaffSupport)
+	ws.SetStaffRole("ada", StaffAdmin)
+
+	tests := []struct {
+		name    string
+		req     ImpersonationRequest
+		wantErr error
+	}{
+		{"missing reason", ImpersonationRequest{StaffID: "sam", TargetUserID: "alice", Duration: time.Minute}, ErrImpersonationReason},
+		{"unknown reason", ImpersonationRequest{StaffID: "sam", TargetUserID: "alice", Reason: "curiosity", Duration: time.Minute}, ErrImpersonationReason},
+		{"no duration", ImpersonationRequest{StaffID: "sam", TargetUserID: "alice", Reason: ReasonFraudReview}, ErrImpersonationDuration},
+		{"too long", ImpersonationRequest{StaffID: "sam", TargetUserID: "alice", Reason: ReasonFraudReview, Duration: 2 * time.Hour}, ErrImpersonationDuration},
+		{"not staff", ImpersonationRequest{StaffID: "bob", TargetUserID: "alice", Reason: ReasonFraudReview, Duration: time.Minute}, ErrNotStaff},
+		{"support asking for writes", ImpersonationRequest{StaffID: "sam", TargetUserID: "alice", Reason: ReasonFraudReview, Duration: time.Minute, AllowWrites: true}, ErrImpersonationForbidden},
+		{"unknown target", ImpersonationRequest{StaffID: "sam", TargetUserID: "ghost", Reason: ReasonFraudReview, Duration: time.Minute}, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := ws.StartImpersonation(tt.req); err != tt.wantErr {
+			t.Errorf("%s: StartImpersonation() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := ws.ImpersonatedBalance("nope"); err != ErrImpersonationNotFound {
+		t.Errorf("ImpersonatedBalance(unknown) error = %v, want %v", err, ErrImpersonationNotFound)
+	}
+	if audit := ws.ListImpersonationAudit(ImpersonationAuditFilter{}); len(audit) != 0 {
+		t.Errorf("audit after refused starts = %+v, want none", audit)
+	}
+}
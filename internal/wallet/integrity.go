@@ -0,0 +1,214 @@
+// internal/wallet/integrity.go
+package wallet
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceMismatch describes a wallet whose stored balance disagrees with its ledger
+type BalanceMismatch struct {
+	UserID        string
+	StoredBalance decimal.Decimal
+	LedgerBalance decimal.Decimal
+	DetectedAt    int64
+}
+
+// Difference returns stored minus ledger balance
+func (m BalanceMismatch) Difference() decimal.Decimal {
+	return m.StoredBalance.Sub(m.LedgerBalance)
+}
+
+// CheckWalletIntegrity recomputes a wallet's balance from the ledger and compares it
+// to the stored balance. It returns nil when both agree.
+func (ws *WalletService) CheckWalletIntegrity(userID string) (*BalanceMismatch, error) {
+	userLock := ws.userLocks.getLock(userID)
+	userLock.LockAt(PriorityReporting)
+	defer userLock.Unlock()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	wallet.mu.RLock()
+	stored, currency := wallet.Balance, wallet.Currency
+	wallet.mu.RUnlock()
+
+	ledger := ws.ledgerBalance(userID, currency)
+	if stored.Equal(ledger) {
+		return nil, nil
+	}
+
+	return &BalanceMismatch{
+		UserID:        userID,
+		StoredBalance: stored,
+		LedgerBalance: ledger,
+		DetectedAt:    ws.now().Unix(),
+	}, nil
+}
+
+// IntegrityMonitorConfig controls how often and how widely the monitor samples wallets
+type IntegrityMonitorConfig struct {
+	Interval   time.Duration // time between sampling rounds
+	SampleSize int           // wallets checked per round
+	Seed       int64         // seed for wallet selection; 0 uses the test mode seed or the clock
+}
+
+// IntegrityMonitor periodically samples random wallets and raises alerts when
+// their stored balance no longer matches the ledger
+type IntegrityMonitor struct {
+	ws  *WalletService
+	cfg IntegrityMonitorConfig
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewIntegrityMonitor creates a monitor for the given service; it does not start sampling
+func NewIntegrityMonitor(ws *WalletService, cfg IntegrityMonitorConfig) *IntegrityMonitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.SampleSize <= 0 {
+		cfg.SampleSize = 10
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = ws.testSeed()
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &IntegrityMonitor{
+		ws:  ws,
+		cfg: cfg,
+		rng: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Start launches the background sampling loop
+func (m *IntegrityMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return
+	}
+	m.running = true
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go m.run(m.stop, m.done)
+}
+
+// Stop halts the sampling loop and waits for the current round to finish
+func (m *IntegrityMonitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	close(m.stop)
+	done := m.done
+	m.mu.Unlock()
+
+	<-done
+}
+
+// run samples wallets on every tick until stop is closed
+func (m *IntegrityMonitor) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.SampleOnce()
+		}
+	}
+}
+
+// SampleOnce checks one round of randomly chosen wallets, alerts on every mismatch
+// and returns the mismatches found
+func (m *IntegrityMonitor) SampleOnce() []BalanceMismatch {
+	var mismatches []BalanceMismatch
+
+	for _, userID := range m.pickWallets() {
+		mismatch, err := m.ws.CheckWalletIntegrity(userID)
+		if err != nil {
+			// Wallet disappeared between selection and check; nothing to verify
+			continue
+		}
+		m.ws.metrics.IncCounter("integrity_checks_total", nil)
+
+		if mismatch != nil {
+			mismatches = append(mismatches, *mismatch)
+			m.alert(*mismatch)
+		}
+	}
+
+	return mismatches
+}
+
+// pickWallets selects up to SampleSize distinct wallets at random
+func (m *IntegrityMonitor) pickWallets() []string {
+	m.ws.mu.RLock()
+	userIDs := make([]string, 0, len(m.ws.wallets))
+	for userID := range m.ws.wallets {
+		userIDs = append(userIDs, userID)
+	}
+	m.ws.mu.RUnlock()
+
+	// Sort first so that a fixed seed yields a reproducible selection
+	sort.Strings(userIDs)
+
+	m.rngMu.Lock()
+	m.rng.Shuffle(len(userIDs), func(i, j int) {
+		userIDs[i], userIDs[j] = userIDs[j], userIDs[i]
+	})
+	m.rngMu.Unlock()
+
+	if len(userIDs) > m.cfg.SampleSize {
+		userIDs = userIDs[:m.cfg.SampleSize]
+	}
+	return userIDs
+}
+
+// alert reports a mismatch to metrics and the notifier
+func (m *IntegrityMonitor) alert(mismatch BalanceMismatch) {
+	m.ws.metrics.IncCounter("integrity_mismatches_total", map[string]string{"user_id": mismatch.UserID})
+
+	m.ws.notifier.Notify(Notification{
+		Type:    "integrity_mismatch",
+		Subject: "Wallet balance does not match ledger",
+		Message: fmt.Sprintf("wallet %s: stored %s, ledger %s",
+			mismatch.UserID, mismatch.StoredBalance.String(), mismatch.LedgerBalance.String()),
+		Data: map[string]string{
+			"user_id":        mismatch.UserID,
+			"stored_balance": mismatch.StoredBalance.String(),
+			"ledger_balance": mismatch.LedgerBalance.String(),
+			"difference":     mismatch.Difference().String(),
+		},
+		Timestamp: mismatch.DetectedAt,
+	})
+}
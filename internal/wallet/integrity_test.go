@@ -0,0 +1,107 @@
+// internal/wallet/integrity_test.go
+package wallet
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestWalletService_CheckWalletIntegrity tests ledger recomputation against stored balances
+func TestWalletService_CheckWalletIntegrity(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 100.0, "deposit")
+	ws.Withdraw("user1", 20.0, "withdraw")
+	ws.Transfer("user1", "user2", 30.0, "transfer")
+
+	for _, userID := range []string{"user1", "user2"} {
+		mismatch, err := ws.CheckWalletIntegrity(userID)
+		if err != nil {
+			t.Fatalf("CheckWalletIntegrity(%s) error = %v", userID, err)
+		}
+		if mismatch != nil {
+			t.Errorf("Unexpected mismatch for %s: %+v", userID, mismatch)
+		}
+	}
+
+	// Corrupt the stored balance behind the ledger's back
+	ws.wallets["user2"].Balance = decimal.NewFromFloat(31.0)
+
+	mismatch, err := ws.CheckWalletIntegrity("user2")
+	if err != nil {
+		t.Fatalf("CheckWalletIntegrity() error = %v", err)
+	}
+	if mismatch == nil {
+		t.Fatal("Expected mismatch after corruption")
+	}
+	if !mismatch.Difference().Equal(decimal.NewFromFloat(1.0)) {
+		t.Errorf("Expected difference 1, got %s", mismatch.Difference().String())
+	}
+
+	if _, err := ws.CheckWalletIntegrity("nonexistent"); err != ErrUserNotFound {
+		t.Errorf("Expected user not found error, got %v", err)
+	}
+}
+
+// TestIntegrityMonitor_Alerts tests that sampled mismatches reach metrics and the notifier
+func TestIntegrityMonitor_Alerts(t *testing.T) {
+	notifier := &recordingNotifier{}
+	metrics := NewInMemoryMetrics()
+
+	ws := NewWalletService(WithNotifier(notifier), WithMetrics(metrics))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 50.0, "deposit")
+	ws.wallets["user1"].Balance = decimal.NewFromFloat(55.0)
+
+	monitor := NewIntegrityMonitor(ws, IntegrityMonitorConfig{SampleSize: 5, Seed: 42})
+	mismatches := monitor.SampleOnce()
+
+	if len(mismatches) != 1 || mismatches[0].UserID != "user1" {
+		t.Fatalf("Expected one mismatch for user1, got %+v", mismatches)
+	}
+	if got := metrics.Counter("integrity_checks_total", nil); got != 2 {
+		t.Errorf("Expected 2 checks, got %d", got)
+	}
+	if got := metrics.Counter("integrity_mismatches_total", map[string]string{"user_id": "user1"}); got != 1 {
+		t.Errorf("Expected 1 mismatch metric, got %d", got)
+	}
+	if alerts := notifier.all(); len(alerts) != 1 || alerts[0].Type != "integrity_mismatch" {
+		t.Errorf("Expected one integrity alert, got %+v", alerts)
+	}
+}
+
+// TestIntegrityMonitor_StartStop tests the background loop under concurrent writes
+func TestIntegrityMonitor_StartStop(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithMetrics(metrics))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 1000.0, "deposit")
+
+	monitor := NewIntegrityMonitor(ws, IntegrityMonitorConfig{Interval: time.Millisecond, SampleSize: 2})
+	monitor.Start()
+	monitor.Start() // second start is a no-op
+
+	var wg sync.WaitGroup
+	wg.Add(50)
+	for i := 0; i < 50; i++ {
+		go func() {
+			defer wg.Done()
+			ws.Transfer("user1", "user2", 1.0, "transfer")
+		}()
+	}
+	wg.Wait()
+	time.Sleep(5 * time.Millisecond)
+	monitor.Stop()
+	monitor.Stop()
+
+	if metrics.Counter("integrity_mismatches_total", map[string]string{"user_id": "user1"}) != 0 ||
+		metrics.Counter("integrity_mismatches_total", map[string]string{"user_id": "user2"}) != 0 {
+		t.Error("Monitor reported mismatches for consistent wallets")
+	}
+}
@@ -0,0 +1,408 @@
+// internal/wallet/interest.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+// Error definitions for interest
+var (
+	ErrInterestPolicyNotSet   = errors.New("no interest policy configured")
+	ErrInterestOverrideNotSet = errors.New("no interest override for user")
+	ErrInvalidInterestRate    = errors.New("interest rate must not be negative")
+	ErrInvalidInterestPeriod  = errors.New("interest period must cover whole days ending no later than now")
+	ErrInterestAlreadyPosted  = errors.New("interest already posted for an overlapping period")
+)
+
+// interestDayCount is the number of days an annual rate is spread over when accruing daily
+const interestDayCount = 365
+
+// Metadata recording the period an interest credit pays for, as Unix seconds
+const (
+	metaInterestStart = "interest_start"
+	metaInterestEnd   = "interest_end"
+)
+
+// InterestRateSource says which rule set the rate for part of a period
+type InterestRateSource string
+
+const (
+	InterestFromTier      InterestRateSource = "tier"
+	InterestFromPromotion InterestRateSource = "promotion"
+	InterestFromAccount   InterestRateSource = "account"
+	InterestFromOverride  InterestRateSource = "override"
+)
+
+// InterestPromotion is an introductory APY paid on the whole balance until Ends
+type InterestPromotion struct {
+	APY  decimal.Decimal
+	Ends time.Time
+}
+
+// InterestPolicy pays interest on each wallet's balance in its base currency. Tiers are
+// marginal APY bands in percent, e.g. 2% on the first 1,000 and 1% above; while a
+// Promotion runs it replaces the tiers.
+type InterestPolicy struct {
+	Tiers     []moneymath.Tier
+	Promotion *InterestPromotion
+}
+
+// InterestOverride pins one wallet's APY over tiers and promotions until Until; a zero
+// Until leaves it in place until removed
+type InterestOverride struct {
+	APY    decimal.Decimal
+	Until  time.Time
+	Reason string
+}
+
+// InterestPeriod is the span interest is accrued over, Start inclusive and End
+// exclusive, in whole days
+type InterestPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// InterestLine itemises the interest one band of the balance earned over consecutive
+// days with the same end-of-day balance and rate
+type InterestLine struct {
+	From     time.Time
+	To       time.Time // exclusive
+	Days     int
+	Balance  decimal.Decimal // end-of-day balance on each of the days
+	Source   InterestRateSource
+	BandFrom decimal.Decimal
+	BandTo   decimal.Decimal // zero when unbounded
+	Base     decimal.Decimal // part of Balance inside the band
+	APY      decimal.Decimal
+	Amount   decimal.Decimal // unrounded Base * APY / 100 * Days / 365
+}
+
+// InterestStatement explains the interest for one wallet and period. Amount is the
+// exact Accrued sum rounded down to the currency's precision, which is what is posted.
+type InterestStatement struct {
+	UserID        string
+	Currency      string
+	Period        InterestPeriod
+	Lines         []InterestLine
+	Accrued       decimal.Decimal
+	Amount        decimal.Decimal
+	TransactionID string // empty until posted, or when nothing was due
+	PostedAt      int64
+}
+
+// interestBook holds the interest policy, per-wallet overrides and accounts, and posted
+// statements
+type interestBook struct {
+	mu        sync.Mutex
+	policy    *InterestPolicy
+	overrides map[string]InterestOverride
+	accounts  map[string]*InterestAccount
+	posted    map[string][]*InterestStatement // by user, in posting order
+	jobID     string                          // accrual job, once an account exists
+}
+
+// WithInterestPolicy enables interest accrual under p
+func WithInterestPolicy(p InterestPolicy) Option {
+	return func(ws *WalletService) {
+		p.Tiers = append([]moneymath.Tier(nil), p.Tiers...)
+		ws.interest.policy = &p
+	}
+}
+
+// SetInterestOverride pins userID's APY, replacing any earlier override
+func (ws *WalletService) SetInterestOverride(userID string, o InterestOverride) error {
+	if o.APY.IsNegative() {
+		return ErrInvalidInterestRate
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+	if ws.interest.overrides == nil {
+		ws.interest.overrides = make(map[string]InterestOverride)
+	}
+	ws.interest.overrides[userID] = o
+	return nil
+}
+
+// RemoveInterestOverride returns userID to the policy's tiers and promotions
+func (ws *WalletService) RemoveInterestOverride(userID string) error {
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+
+	if _, exists := ws.interest.overrides[userID]; !exists {
+		return ErrInterestOverrideNotSet
+	}
+	delete(ws.interest.overrides, userID)
+	return nil
+}
+
+// ExplainInterest itemises the interest for userID over period. Once the period has
+// been posted it returns the statement the posted amount was computed from; before
+// that it previews what posting would pay under the current policy.
+func (ws *WalletService) ExplainInterest(userID string, period InterestPeriod) (*InterestStatement, error) {
+	ws.interest.mu.Lock()
+	for _, s := range ws.interest.posted[userID] {
+		if s.Period.Start.Equal(period.Start) && s.Period.End.Equal(period.End) {
+			copied := s.copy()
+			ws.interest.mu.Unlock()
+			return copied, nil
+		}
+	}
+	ws.interest.mu.Unlock()
+
+	s, _, err := ws.accrueInterest(userID, period)
+	return s, err
+}
+
+// PostInterest credits the interest userID earned over a period that has ended. Each
+// day of a wallet's history is paid at most once: periods overlapping one already
+// posted, including before a restart or restore, are rejected.
+func (ws *WalletService) PostInterest(userID string, period InterestPeriod) (*InterestStatement, error) {
+	return ws.PostInterestContext(context.Background(), userID, period)
+}
+
+// PostInterestContext is PostInterest on behalf of a caller's ctx
+func (ws *WalletService) PostInterestContext(ctx context.Context, userID string, period InterestPeriod) (*InterestStatement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s, postedBefore, err := ws.accrueInterest(userID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.interest.mu.Lock()
+	overlaps := postedBefore
+	for _, p := range ws.interest.posted[userID] {
+		overlaps = overlaps || p.Period.overlaps(period)
+	}
+	if overlaps {
+		ws.interest.mu.Unlock()
+		return nil, ErrInterestAlreadyPosted
+	}
+	if ws.interest.posted == nil {
+		ws.interest.posted = make(map[string][]*InterestStatement)
+	}
+	ws.interest.posted[userID] = append(ws.interest.posted[userID], s)
+	ws.interest.mu.Unlock()
+
+	var tx *Transaction
+	if s.Amount.IsPositive() {
+		tx = &Transaction{
+			FromUserID:  userID,
+			ToUserID:    userID,
+			Amount:      s.Amount,
+			Currency:    s.Currency,
+			Type:        TransactionInterest,
+			Description: "Interest " + period.Start.Format(time.DateOnly) + " to " + period.End.Format(time.DateOnly),
+			Metadata: map[string]string{
+				metaInterestStart: strconv.FormatInt(period.Start.Unix(), 10),
+				metaInterestEnd:   strconv.FormatInt(period.End.Unix(), 10),
+			},
+		}
+		err = ws.postCredit(ctx, tx)
+	}
+
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+	if err != nil {
+		ws.interest.forget(userID, s)
+		return nil, err
+	}
+	s.PostedAt = ws.now().Unix()
+	if tx != nil {
+		s.TransactionID = tx.ID
+	}
+	ws.metrics.IncCounter("interest_postings_total", nil)
+	return s.copy(), nil
+}
+
+// accrueInterest computes the statement for userID over period from the wallet's
+// end-of-day balances. It also reports whether the log already holds an interest
+// credit for an overlapping period.
+func (ws *WalletService) accrueInterest(userID string, period InterestPeriod) (*InterestStatement, bool, error) {
+	days, err := period.days(ws.now())
+	if err != nil {
+		return nil, false, err
+	}
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, false, ErrUserNotFound
+	}
+	currency := wallet.Currency
+
+	ws.interest.mu.Lock()
+	policy := ws.interest.policy
+	override, hasOverride := ws.interest.overrides[userID]
+	var accountAPY *decimal.Decimal
+	if account, exists := ws.interest.accounts[userID]; exists {
+		accountAPY = account.APY
+	}
+	ws.interest.mu.Unlock()
+	if policy == nil && !hasOverride && accountAPY == nil {
+		return nil, false, ErrInterestPolicyNotSet
+	}
+
+	// changes[i] is the net movement on day i; history before the period opens it
+	opening := decimal.Zero
+	changes := make([]decimal.Decimal, len(days))
+	posted := false
+	it := ws.iterate(userID, IterateOptions{})
+	defer it.Close()
+	for it.Next() {
+		tx := it.Transaction()
+		if tx.Type == TransactionInterest && tx.ToUserID == userID && interestPeriodOf(tx).overlaps(period) {
+			posted = true
+		}
+		if tx.Timestamp >= period.End.Unix() {
+			continue
+		}
+		effect := tx.balanceEffect(userID, currency)
+		if tx.Timestamp < period.Start.Unix() {
+			opening = opening.Add(effect)
+			continue
+		}
+		i := sort.Search(len(days), func(i int) bool { return days[i].Unix() > tx.Timestamp }) - 1
+		changes[i] = changes[i].Add(effect)
+	}
+	if err := it.Err(); err != nil {
+		return nil, false, err
+	}
+
+	s := &InterestStatement{UserID: userID, Currency: currency, Period: period}
+	balance := opening
+	for i, day := range days {
+		balance = balance.Add(changes[i])
+		var source InterestRateSource
+		var tiers []moneymath.Tier
+		switch {
+		case hasOverride && (override.Until.IsZero() || day.Before(override.Until)):
+			source, tiers = InterestFromOverride, []moneymath.Tier{{Rate: override.APY}}
+		case accountAPY != nil:
+			source, tiers = InterestFromAccount, []moneymath.Tier{{Rate: *accountAPY}}
+		case policy != nil && policy.Promotion != nil && day.Before(policy.Promotion.Ends):
+			source, tiers = InterestFromPromotion, []moneymath.Tier{{Rate: policy.Promotion.APY}}
+		case policy != nil:
+			source, tiers = InterestFromTier, policy.Tiers
+		}
+		if len(tiers) == 0 {
+			continue
+		}
+		_, bands, err := moneymath.Tiered(balance, tiers, 0, moneymath.Down)
+		if err != nil {
+			return nil, false, err
+		}
+		s.Lines = appendInterestDay(s.Lines, day, balance, source, bands)
+	}
+
+	for i := range s.Lines {
+		l := &s.Lines[i]
+		l.Amount = l.Base.Mul(l.APY).Mul(decimal.NewFromInt(int64(l.Days))).Div(decimal.NewFromInt(100 * interestDayCount))
+		s.Accrued = s.Accrued.Add(l.Amount)
+	}
+	s.Amount = moneymath.Round(s.Accrued, ws.currencyPrecision(currency), moneymath.Down)
+	return s, posted, nil
+}
+
+// appendInterestDay adds one day's bands to lines, extending the previous day's lines
+// when balance and rates are unchanged
+func appendInterestDay(lines []InterestLine, day time.Time, balance decimal.Decimal, source InterestRateSource, bands []moneymath.TierCharge) []InterestLine {
+	next := day.AddDate(0, 0, 1)
+	if n := len(bands); n > 0 && len(lines) >= n {
+		prev := lines[len(lines)-n:]
+		same := true
+		for i, b := range bands {
+			p := prev[i]
+			same = same && p.To.Equal(day) && p.Balance.Equal(balance) && p.Source == source &&
+				p.BandFrom.Equal(b.From) && p.APY.Equal(b.Rate)
+		}
+		if same {
+			for i := range prev {
+				prev[i].To = next
+				prev[i].Days++
+			}
+			return lines
+		}
+	}
+	for _, b := range bands {
+		lines = append(lines, InterestLine{
+			From:     day,
+			To:       next,
+			Days:     1,
+			Balance:  balance,
+			Source:   source,
+			BandFrom: b.From,
+			BandTo:   b.To,
+			Base:     b.Base,
+			APY:      b.Rate,
+		})
+	}
+	return lines
+}
+
+// days returns the start of each day in the period, rejecting periods that are empty,
+// not whole days or not yet over at now
+func (p InterestPeriod) days(now time.Time) ([]time.Time, error) {
+	if !p.End.After(p.Start) || p.End.After(now) {
+		return nil, ErrInvalidInterestPeriod
+	}
+	var days []time.Time
+	day := p.Start
+	for day.Before(p.End) {
+		days = append(days, day)
+		day = day.AddDate(0, 0, 1)
+	}
+	if !day.Equal(p.End) {
+		return nil, ErrInvalidInterestPeriod
+	}
+	return days, nil
+}
+
+// overlaps reports whether p and q share any time
+func (p InterestPeriod) overlaps(q InterestPeriod) bool {
+	return p.Start.Before(q.End) && q.Start.Before(p.End)
+}
+
+// interestPeriodOf reads the period an interest credit paid for
+func interestPeriodOf(tx *Transaction) InterestPeriod {
+	start, _ := strconv.ParseInt(tx.Metadata[metaInterestStart], 10, 64)
+	end, _ := strconv.ParseInt(tx.Metadata[metaInterestEnd], 10, 64)
+	return InterestPeriod{Start: time.Unix(start, 0), End: time.Unix(end, 0)}
+}
+
+// forget drops a statement whose posting failed. Caller must hold ws.interest.mu.
+func (b *interestBook) forget(userID string, s *InterestStatement) {
+	kept := b.posted[userID][:0]
+	for _, p := range b.posted[userID] {
+		if p != s {
+			kept = append(kept, p)
+		}
+	}
+	b.posted[userID] = kept
+}
+
+// copy returns a deep copy of the statement. Caller must hold ws.interest.mu.
+func (s *InterestStatement) copy() *InterestStatement {
+	copied := *s
+	copied.Lines = append([]InterestLine(nil), s.Lines...)
+	return &copied
+}
@@ -0,0 +1,118 @@
+// internal/wallet/interest_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+func TestExplainInterest(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	// The fake clock starts at 2024-01-01 12:00
+	ws, clock := newTestService(WithInterestPolicy(InterestPolicy{
+		Tiers: []moneymath.Tier{
+			{UpTo: decimal.NewFromInt(1000), Rate: decimal.NewFromInt(2)},
+			{Rate: decimal.NewFromInt(1)},
+		},
+		Promotion: &InterestPromotion{APY: decimal.NewFromInt(5), Ends: day(11)},
+	}))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 1500, "salary")
+	ws.Deposit("bob", 365, "salary")
+	ws.SetInterestOverride("bob", InterestOverride{APY: decimal.NewFromInt(10), Reason: "staff"})
+	clock.t = day(21).Add(9 * time.Hour)
+	ws.Withdraw("alice", 500, "rent")
+
+	january := InterestPeriod{Start: day(1), End: day(31)}
+	if _, err := ws.ExplainInterest("alice", january); err != ErrInvalidInterestPeriod {
+		t.Errorf("ExplainInterest(open period) error = %v, want %v", err, ErrInvalidInterestPeriod)
+	}
+	clock.t = day(31)
+
+	got, err := ws.ExplainInterest("alice", january)
+	if err != nil {
+		t.Fatalf("ExplainInterest() error = %v", err)
+	}
+	want := []InterestLine{
+		{From: day(1), Days: 10, Balance: decimal.NewFromInt(1500), Source: InterestFromPromotion, Base: decimal.NewFromInt(1500), APY: decimal.NewFromInt(5)},
+		{From: day(11), Days: 10, Balance: decimal.NewFromInt(1500), Source: InterestFromTier, Base: decimal.NewFromInt(1000), APY: decimal.NewFromInt(2)},
+		{From: day(11), Days: 10, Balance: decimal.NewFromInt(1500), Source: InterestFromTier, Base: decimal.NewFromInt(500), APY: decimal.NewFromInt(1)},
+		{From: day(21), Days: 10, Balance: decimal.NewFromInt(1000), Source: InterestFromTier, Base: decimal.NewFromInt(1000), APY: decimal.NewFromInt(2)},
+	}
+	if len(got.Lines) != len(want) {
+		t.Fatalf("lines = %+v, want %d", got.Lines, len(want))
+	}
+	for i, w := range want {
+		l := got.Lines[i]
+		if !l.From.Equal(w.From) || l.Days != w.Days || !l.Balance.Equal(w.Balance) || l.Source != w.Source ||
+			!l.Base.Equal(w.Base) || !l.APY.Equal(w.APY) {
+			t.Errorf("line %d = %+v, want %+v", i, l, w)
+		}
+	}
+	if !got.Amount.Equal(decimal.RequireFromString("3.28")) {
+		t.Errorf("amount = %s (accrued %s), want 3.28", got.Amount, got.Accrued)
+	}
+
+	bob, _ := ws.ExplainInterest("bob", january)
+	if len(bob.Lines) != 1 || bob.Lines[0].Source != InterestFromOverride || !bob.Amount.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("override statement = %+v", bob)
+	}
+}
+
+func TestPostInterest(t *testing.T) {
+	policy := WithInterestPolicy(InterestPolicy{Tiers: []moneymath.Tier{{Rate: decimal.NewFromInt(10)}}})
+	ws, clock := newTestService(policy)
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 365, "salary")
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	january := InterestPeriod{Start: start, End: start.AddDate(0, 0, 31)}
+	clock.t = january.End
+	posted, err := ws.PostInterest("alice", january)
+	if err != nil {
+		t.Fatalf("PostInterest() error = %v", err)
+	}
+	if posted.TransactionID == "" || !posted.Amount.Equal(decimal.RequireFromString("3.1")) {
+		t.Errorf("posted = %+v, want 3.10 credited", posted)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.RequireFromString("368.1")) {
+		t.Errorf("balance = %s, want 368.10", b)
+	}
+
+	// A policy change after posting does not change the explanation of what was paid
+	ws.SetInterestOverride("alice", InterestOverride{APY: decimal.NewFromInt(50)})
+	explained, _ := ws.ExplainInterest("alice", january)
+	if explained.TransactionID != posted.TransactionID || !explained.Amount.Equal(posted.Amount) {
+		t.Errorf("explained = %+v, want the posted statement", explained)
+	}
+
+	restored, err := RestoreSnapshot(ws.Snapshot(), WithClock(clock.Now), policy)
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	unconfigured, _ := RestoreSnapshot(ws.Snapshot(), WithClock(clock.Now))
+	tests := []struct {
+		name    string
+		ws      *WalletService
+		period  InterestPeriod
+		wantErr error
+	}{
+		{"same period", ws, january, ErrInterestAlreadyPosted},
+		{"overlapping period", ws, InterestPeriod{Start: start.AddDate(0, 0, 15), End: january.End}, ErrInterestAlreadyPosted},
+		{"after restore", restored, january, ErrInterestAlreadyPosted},
+		{"partial day", ws, InterestPeriod{Start: start, End: start.Add(36 * time.Hour)}, ErrInvalidInterestPeriod},
+		{"no policy", unconfigured, InterestPeriod{Start: start.AddDate(0, 0, -1), End: start}, ErrInterestPolicyNotSet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.ws.PostInterest("alice", tt.period); err != tt.wantErr {
+				t.Errorf("PostInterest() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// internal/wallet/interestaccrual.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for interest accounts
+var (
+	ErrInvalidCompounding     = errors.New("unknown interest compounding period")
+	ErrInterestAccountNotSet  = errors.New("wallet has no interest account")
+	ErrInterestAccountStarted = errors.New("interest account must start on a whole day no later than today")
+)
+
+// InterestAccrualInterval is how often the accrual job looks for periods that have ended
+const InterestAccrualInterval = time.Hour
+
+// InterestCompounding is how often a wallet's accrued interest is credited; once
+// credited it earns interest itself
+type InterestCompounding string
+
+const (
+	CompoundDaily   InterestCompounding = "daily"
+	CompoundWeekly  InterestCompounding = "weekly"
+	CompoundMonthly InterestCompounding = "monthly"
+)
+
+// InterestAccount enrolls a wallet in automatic interest accrual. Each compounding period
+// is posted with PostInterest once it ends.
+type InterestAccount struct {
+	UserID      string
+	APY         *decimal.Decimal // the wallet's own rate; nil accrues at the policy's
+	Compounding InterestCompounding
+	Start       time.Time      // first day accrued; zero starts today (UTC)
+	Next        InterestPeriod // period to be posted next
+}
+
+// SetInterestAccount enrolls userID's wallet in interest accrual, or changes its rate
+// and compounding. A changed account keeps accruing from the period it had reached.
+func (ws *WalletService) SetInterestAccount(userID string, account InterestAccount) (*InterestAccount, error) {
+	switch account.Compounding {
+	case CompoundDaily, CompoundWeekly, CompoundMonthly:
+	default:
+		return nil, ErrInvalidCompounding
+	}
+	if account.APY != nil && account.APY.IsNegative() {
+		return nil, ErrInvalidInterestRate
+	}
+	today := bucketStart(ws.now(), GranularityDay)
+	if account.Start.IsZero() {
+		account.Start = today
+	}
+	if account.Start.After(today) || !bucketStart(account.Start, GranularityDay).Equal(account.Start) {
+		return nil, ErrInterestAccountStarted
+	}
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+	if account.APY == nil && ws.interest.policy == nil {
+		return nil, ErrInterestPolicyNotSet
+	}
+
+	account.UserID = userID
+	if account.APY != nil {
+		apy := *account.APY
+		account.APY = &apy
+	}
+	start := account.Start
+	if existing, exists := ws.interest.accounts[userID]; exists {
+		account.Start, start = existing.Start, existing.Next.Start
+	}
+	account.Next = InterestPeriod{Start: start, End: account.Compounding.next(account.Start, start)}
+
+	if ws.interest.accounts == nil {
+		ws.interest.accounts = make(map[string]*InterestAccount)
+	}
+	ws.interest.accounts[userID] = &account
+	if ws.interest.jobID == "" {
+		ws.interest.jobID = ws.schedule("interest_accrual", "", ws.now().Add(InterestAccrualInterval), Every(InterestAccrualInterval), func(time.Time) error {
+			_, err := ws.AccrueInterestNow()
+			return err
+		})
+	}
+	return account.copy(), nil
+}
+
+// GetInterestAccount returns userID's interest account
+func (ws *WalletService) GetInterestAccount(userID string) (*InterestAccount, error) {
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+
+	account, exists := ws.interest.accounts[userID]
+	if !exists {
+		return nil, ErrInterestAccountNotSet
+	}
+	return account.copy(), nil
+}
+
+// CloseInterestAccount stops interest accrual on userID's wallet. Interest for the
+// period in progress is not paid.
+func (ws *WalletService) CloseInterestAccount(userID string) error {
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+
+	if _, exists := ws.interest.accounts[userID]; !exists {
+		return ErrInterestAccountNotSet
+	}
+	delete(ws.interest.accounts, userID)
+	return nil
+}
+
+// AccrueInterestNow posts every compounding period of every interest account that has
+// ended by the service clock, as the background job does, and returns the statements
+// posted. Periods missed while the job was not running are posted together, each
+// compounding from the time it is posted. Periods already posted by PostInterest are
+// skipped.
+func (ws *WalletService) AccrueInterestNow() ([]*InterestStatement, error) {
+	now := ws.now()
+
+	ws.interest.mu.Lock()
+	var due []string
+	for userID, account := range ws.interest.accounts {
+		if !account.Next.End.After(now) {
+			due = append(due, userID)
+		}
+	}
+	ws.interest.mu.Unlock()
+	sort.Strings(due)
+
+	var statements []*InterestStatement
+	var errs []error
+	for _, userID := range due {
+		for {
+			period, ok := ws.dueInterestPeriod(userID, now)
+			if !ok {
+				break
+			}
+			s, err := ws.PostInterest(userID, period)
+			if err != nil && !errors.Is(err, ErrInterestAlreadyPosted) {
+				errs = append(errs, err)
+				break
+			}
+			if s != nil {
+				statements = append(statements, s)
+			}
+			ws.advanceInterestAccount(userID, period)
+		}
+	}
+	return statements, errors.Join(errs...)
+}
+
+// dueInterestPeriod returns the next period of userID's account when it has ended by now
+func (ws *WalletService) dueInterestPeriod(userID string, now time.Time) (InterestPeriod, bool) {
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+
+	account, exists := ws.interest.accounts[userID]
+	if !exists || account.Next.End.After(now) {
+		return InterestPeriod{}, false
+	}
+	return account.Next, true
+}
+
+// advanceInterestAccount moves userID's account past period, unless it has moved on or
+// been closed meanwhile
+func (ws *WalletService) advanceInterestAccount(userID string, period InterestPeriod) {
+	ws.interest.mu.Lock()
+	defer ws.interest.mu.Unlock()
+
+	account, exists := ws.interest.accounts[userID]
+	if !exists || !account.Next.Start.Equal(period.Start) {
+		return
+	}
+	account.Next = InterestPeriod{Start: period.End, End: account.Compounding.next(account.Start, period.End)}
+}
+
+// next returns the end of the compounding period starting at start, for an account
+// whose first period started at anchor. Monthly periods end on anchor's day of the
+// month, or the last day of shorter months.
+func (c InterestCompounding) next(anchor, start time.Time) time.Time {
+	switch c {
+	case CompoundWeekly:
+		return start.AddDate(0, 0, 7)
+	case CompoundMonthly:
+		return monthDay(start.Year(), start.Month()+1, anchor.Day(), start)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// copy returns a copy of the account. Caller must hold ws.interest.mu.
+func (a *InterestAccount) copy() *InterestAccount {
+	copied := *a
+	if a.APY != nil {
+		apy := *a.APY
+		copied.APY = &apy
+	}
+	return &copied
+}
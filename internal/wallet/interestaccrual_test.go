@@ -0,0 +1,140 @@
+// internal/wallet/interestaccrual_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+func TestAccrueInterestNow_Compounds(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	ws, clock := newTestService() // 2024-01-01 12:00
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 1000, "savings")
+
+	apy := decimal.RequireFromString("36.5") // 0.1% a day
+	if _, err := ws.SetInterestAccount("alice", InterestAccount{APY: &apy, Compounding: CompoundDaily}); err != nil {
+		t.Fatalf("SetInterestAccount() error = %v", err)
+	}
+	if posted, err := ws.AccrueInterestNow(); err != nil || len(posted) != 0 {
+		t.Fatalf("AccrueInterestNow() mid-period = %+v, %v, want nothing", posted, err)
+	}
+
+	// The background job posts the first day once it is over
+	clock.t = day(2).Add(time.Hour)
+	for _, r := range ws.RunDueJobs() {
+		if r.Kind == "interest_accrual" && r.Err != nil {
+			t.Fatalf("accrual job error = %v", r.Err)
+		}
+	}
+
+	clock.t = day(3).Add(time.Hour)
+	posted, err := ws.AccrueInterestNow()
+	if err != nil || len(posted) != 1 {
+		t.Fatalf("AccrueInterestNow() = %+v, %v, want one statement", posted, err)
+	}
+	// Day one's interest earns interest on day two
+	if !posted[0].Accrued.Equal(decimal.RequireFromString("1.001")) || !posted[0].Amount.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("day two = %s accrued, %s posted; want 1.001, 1", posted[0].Accrued, posted[0].Amount)
+	}
+	if posted[0].Lines[0].Source != InterestFromAccount {
+		t.Errorf("source = %s, want %s", posted[0].Lines[0].Source, InterestFromAccount)
+	}
+
+	// Missed days are caught up one period at a time
+	clock.t = day(6).Add(time.Hour)
+	if posted, err = ws.AccrueInterestNow(); err != nil || len(posted) != 3 {
+		t.Fatalf("AccrueInterestNow() after three days = %d statements, %v", len(posted), err)
+	}
+	if !posted[2].Period.Start.Equal(day(5)) {
+		t.Errorf("last period = %+v, want 5 January", posted[2].Period)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(1005)) {
+		t.Errorf("balance = %s, want 1005", b)
+	}
+	account, _ := ws.GetInterestAccount("alice")
+	if !account.Next.Start.Equal(day(6)) || !account.Next.End.Equal(day(7)) {
+		t.Errorf("next period = %+v, want 6 January", account.Next)
+	}
+
+	ws.CloseInterestAccount("alice")
+	clock.t = day(9)
+	if posted, _ := ws.AccrueInterestNow(); len(posted) != 0 {
+		t.Errorf("AccrueInterestNow() after closing = %+v, want nothing", posted)
+	}
+}
+
+func TestAccrueInterestNow_MonthlyAtPolicyRate(t *testing.T) {
+	ws, clock := newTestService(WithInterestPolicy(InterestPolicy{Tiers: []moneymath.Tier{{Rate: decimal.NewFromInt(10)}}}))
+	clock.t = time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 365, "savings")
+
+	account, err := ws.SetInterestAccount("alice", InterestAccount{Compounding: CompoundMonthly})
+	if err != nil {
+		t.Fatalf("SetInterestAccount() error = %v", err)
+	}
+	// Leap February ends on its last day
+	feb29 := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	if !account.Next.End.Equal(feb29) {
+		t.Errorf("first period = %+v, want to end 29 February", account.Next)
+	}
+
+	// A period posted by hand is not paid again
+	clock.t = feb29.Add(time.Hour)
+	if _, err := ws.PostInterest("alice", account.Next); err != nil {
+		t.Fatalf("PostInterest() error = %v", err)
+	}
+	clock.t = time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)
+	posted, err := ws.AccrueInterestNow()
+	if err != nil || len(posted) != 1 || !posted[0].Period.Start.Equal(feb29) {
+		t.Fatalf("AccrueInterestNow() = %+v, %v, want March only", posted, err)
+	}
+	if posted[0].Lines[0].Source != InterestFromTier {
+		t.Errorf("source = %s, want %s", posted[0].Lines[0].Source, InterestFromTier)
+	}
+}
+
+func TestSetInterestAccount_Validation(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	apy := decimal.NewFromInt(2)
+	negative := decimal.NewFromInt(-1)
+	today := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		userID  string
+		account InterestAccount
+		wantErr error
+	}{
+		{"unknown compounding", "alice", InterestAccount{APY: &apy, Compounding: "hourly"}, ErrInvalidCompounding},
+		{"negative rate", "alice", InterestAccount{APY: &negative, Compounding: CompoundDaily}, ErrInvalidInterestRate},
+		{"future start", "alice", InterestAccount{APY: &apy, Compounding: CompoundDaily, Start: today.AddDate(0, 0, 1)}, ErrInterestAccountStarted},
+		{"partial day", "alice", InterestAccount{APY: &apy, Compounding: CompoundDaily, Start: today.Add(time.Hour)}, ErrInterestAccountStarted},
+		{"no rate", "alice", InterestAccount{Compounding: CompoundDaily}, ErrInterestPolicyNotSet},
+		{"unknown user", "ghost", InterestAccount{APY: &apy, Compounding: CompoundDaily}, ErrUserNotFound},
+		{"past start", "alice", InterestAccount{APY: &apy, Compounding: CompoundWeekly, Start: today.AddDate(0, 0, -14)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.SetInterestAccount(tt.userID, tt.account); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetInterestAccount() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Changing the compounding keeps the period reached
+	account, _ := ws.SetInterestAccount("alice", InterestAccount{APY: &apy, Compounding: CompoundDaily})
+	if want := today.AddDate(0, 0, -14); !account.Start.Equal(want) || !account.Next.Start.Equal(want) || !account.Next.End.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("account = %+v, want daily periods from %s", account, want)
+	}
+	if err := ws.CloseInterestAccount("ghost"); err != ErrInterestAccountNotSet {
+		t.Errorf("CloseInterestAccount(ghost) error = %v, want %v", err, ErrInterestAccountNotSet)
+	}
+}
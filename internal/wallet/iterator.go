@@ -0,0 +1,265 @@
+// internal/wallet/iterator.go
+package wallet
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// DefaultIteratorPageSize is the number of transactions fetched per page when unset
+const DefaultIteratorPageSize = 500
+
+// IterateOptions controls how IterateTransactions pages through history
+type IterateOptions struct {
+	PageSize int
+	Since    int64 // inclusive lower bound on Timestamp; 0 means unbounded
+	Until    int64 // exclusive upper bound on Timestamp; 0 means unbounded
+}
+
+// TransactionIterator streams transactions without materialising the full history.
+//
+//	it, _ := ws.IterateTransactions(userID, IterateOptions{})
+//	defer it.Close()
+//	for it.Next() {
+//		tx := it.Transaction()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type TransactionIterator interface {
+	Next() bool
+	Transaction() *Transaction
+	Err() error
+	Close() error
+}
+
+// IterateTransactions returns an iterator over a user's transactions in log order,
+// fetching PageSize entries at a time so memory stays bounded for large histories
+func (ws *WalletService) IterateTransactions(userID string, opts IterateOptions) (TransactionIterator, error) {
+	return ws.IterateTransactionsContext(context.Background(), userID, opts)
+}
+
+// IterateTransactionsContext is IterateTransactions with the archive lookup bounded by
+// ctx and the archive layer budget
+func (ws *WalletService) IterateTransactionsContext(ctx context.Context, userID string, opts IterateOptions) (TransactionIterator, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	it := ws.iterateContext(ctx, userID, opts)
+	if e, failed := it.(*errIterator); failed {
+		return nil, e.err
+	}
+	return it, nil
+}
+
+// iterate returns an iterator over the hot log, merged with the archive when one is
+// configured, without checking that the user exists
+func (ws *WalletService) iterate(userID string, opts IterateOptions) TransactionIterator {
+	return ws.iterateContext(context.Background(), userID, opts)
+}
+
+// iterateContext is iterate with the archive lookup bounded by ctx
+func (ws *WalletService) iterateContext(ctx context.Context, userID string, opts IterateOptions) TransactionIterator {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultIteratorPageSize
+	}
+
+	hot := &logIterator{ws: ws, userID: userID, opts: opts}
+	if ws.archive == nil {
+		return hot
+	}
+
+	cold, err := ws.archiveIterate(ctx, userID, opts)
+	if err != nil {
+		return &errIterator{err: err}
+	}
+	return newMergedIterator(cold, hot)
+}
+
+// fullHistory returns every transaction in the log and the archive once, each wallet's
+// history in turn
+func (ws *WalletService) fullHistory() ([]*Transaction, error) {
+	ws.mu.RLock()
+	if ws.archive == nil {
+		defer ws.mu.RUnlock()
+		return slices.Clone(ws.transactions), nil
+	}
+	userIDs := slices.Sorted(maps.Keys(ws.wallets))
+	ws.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var history []*Transaction
+	for _, id := range userIDs {
+		it := ws.iterate(id, IterateOptions{})
+		for it.Next() {
+			if tx := it.Transaction(); !seen[tx.ID] {
+				seen[tx.ID] = true
+				history = append(history, tx)
+			}
+		}
+		err := it.Err()
+		it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// logIterator pages through the in-memory transaction log
+type logIterator struct {
+	ws     *WalletService
+	userID string
+	opts   IterateOptions
+
+	cursor int // absolute position of the next log entry to scan
+	page   []*Transaction
+	pos    int
+	cur    *Transaction
+	done   bool
+	closed bool
+}
+
+// Next advances to the next transaction, fetching a new page when needed
+func (it *logIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	for it.pos >= len(it.page) {
+		if it.done {
+			it.cur = nil
+			return false
+		}
+		it.fetchPage()
+	}
+	it.cur = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+// fetchPage scans forward from the cursor until a page is filled or the log ends
+func (it *logIterator) fetchPage() {
+	it.ws.mu.RLock()
+	defer it.ws.mu.RUnlock()
+
+	it.page = it.page[:0]
+	it.pos = 0
+
+	// Entries before logBase were moved to the archive while we were iterating
+	if it.cursor < it.ws.logBase {
+		it.cursor = it.ws.logBase
+	}
+	end := it.ws.logBase + len(it.ws.transactions)
+
+	// Users' entries are found through the per-user index; other IDs scan the log
+	if _, indexed := it.ws.users[it.userID]; indexed {
+		positions := it.ws.txIndex.byUser[it.userID]
+		i := sort.SearchInts(positions, it.cursor)
+		for ; i < len(positions) && len(it.page) < it.opts.PageSize; i++ {
+			if tx := it.ws.transactions[positions[i]-it.ws.logBase]; it.matches(tx) {
+				it.page = append(it.page, tx)
+			}
+		}
+		it.cursor = end
+		if i < len(positions) {
+			it.cursor = positions[i]
+		}
+	}
+	for it.cursor < end && len(it.page) < it.opts.PageSize {
+		tx := it.ws.transactions[it.cursor-it.ws.logBase]
+		it.cursor++
+		if it.matches(tx) {
+			it.page = append(it.page, tx)
+		}
+	}
+	if it.cursor >= end {
+		it.done = true
+	}
+}
+
+// matches reports whether tx belongs to the iterated user and time window
+func (it *logIterator) matches(tx *Transaction) bool {
+	return it.opts.matches(it.userID, tx)
+}
+
+// matches reports whether tx involves userID and falls inside the time window
+func (opts IterateOptions) matches(userID string, tx *Transaction) bool {
+	if tx.FromUserID != userID && tx.ToUserID != userID {
+		return false
+	}
+	if opts.Since != 0 && tx.Timestamp < opts.Since {
+		return false
+	}
+	if opts.Until != 0 && tx.Timestamp >= opts.Until {
+		return false
+	}
+	return true
+}
+
+// Transaction returns the transaction the iterator is positioned on
+func (it *logIterator) Transaction() *Transaction {
+	return it.cur
+}
+
+// Err returns the first error encountered while iterating
+func (it *logIterator) Err() error {
+	return nil
+}
+
+// Close releases the iterator; further calls to Next return false
+func (it *logIterator) Close() error {
+	it.closed = true
+	it.page = nil
+	it.cur = nil
+	return nil
+}
+
+// ExportTransactionHistory streams a user's history to w as CSV, one page at a time
+func (ws *WalletService) ExportTransactionHistory(userID string, w io.Writer) error {
+	return ws.exportHistory(context.Background(), userID, IterateOptions{}, w)
+}
+
+// exportHistory streams the part of userID's history opts selects to w as CSV,
+// stopping between entries once ctx is done
+func (ws *WalletService) exportHistory(ctx context.Context, userID string, opts IterateOptions, w io.Writer) error {
+	it, err := ws.IterateTransactionsContext(ctx, userID, opts)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "timestamp", "type", "from", "to", "amount", "currency", "description", "label", "counterparty"})
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tx := it.Transaction()
+		cw.Write([]string{
+			tx.ID,
+			strconv.FormatInt(tx.Timestamp, 10),
+			string(tx.Type),
+			tx.FromUserID,
+			tx.ToUserID,
+			tx.Amount.String(),
+			tx.currencyOf(),
+			tx.Description,
+			tx.Type.Label(),
+			ws.CounterpartyLabel(userID, tx),
+		})
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
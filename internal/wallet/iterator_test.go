@@ -0,0 +1,82 @@
+// internal/wallet/iterator_test.go
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWalletService_IterateTransactions tests paging, filtering and close semantics
+func TestWalletService_IterateTransactions(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+
+	for i := 0; i < 7; i++ {
+		ws.Deposit("user1", 10.0, "deposit")
+		ws.Deposit("user2", 10.0, "noise")
+		clock.Advance(time.Second)
+	}
+	start := clock.Now().Unix()
+	ws.Transfer("user1", "user2", 5.0, "transfer")
+
+	it, err := ws.IterateTransactions("user1", IterateOptions{PageSize: 3})
+	if err != nil {
+		t.Fatalf("IterateTransactions() error = %v", err)
+	}
+
+	count := 0
+	for it.Next() {
+		tx := it.Transaction()
+		if tx.FromUserID != "user1" && tx.ToUserID != "user1" {
+			t.Errorf("Iterator yielded foreign transaction %+v", tx)
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+	if count != 8 {
+		t.Errorf("Expected 8 transactions, got %d", count)
+	}
+	it.Close()
+	if it.Next() {
+		t.Error("Next() after Close() returned true")
+	}
+
+	windowed, _ := ws.IterateTransactions("user2", IterateOptions{Since: start})
+	defer windowed.Close()
+	count = 0
+	for windowed.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 transaction in window, got %d", count)
+	}
+
+	if _, err := ws.IterateTransactions("nonexistent", IterateOptions{}); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+// TestWalletService_ExportTransactionHistory tests CSV export through the iterator
+func TestWalletService_ExportTransactionHistory(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("user1", 12.5, "salary, march")
+
+	var buf bytes.Buffer
+	if err := ws.ExportTransactionHistory("user1", &buf); err != nil {
+		t.Fatalf("ExportTransactionHistory() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header and one row, got %q", buf.String())
+	}
+	if !strings.Contains(lines[1], `"salary, march"`) || !strings.Contains(lines[1], ",12.5,USD,") {
+		t.Errorf("Unexpected CSV row %q", lines[1])
+	}
+}
@@ -0,0 +1,256 @@
+// internal/wallet/jobcontrol.go
+package wallet
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Error definitions for operating on scheduled jobs
+var (
+	ErrJobRunning            = errors.New("scheduled job is running")
+	ErrJobOccurrenceNotFound = errors.New("scheduled job has no such upcoming run")
+	ErrJobActionReason       = errors.New("scheduled job actions need an actor and a reason")
+)
+
+// MaxJobOccurrences caps how many upcoming runs of one job are computed
+const MaxJobOccurrences = 100
+
+// JobAction names an operator action on a scheduled job
+type JobAction string
+
+const (
+	JobActionCancel  JobAction = "cancel"
+	JobActionSkip    JobAction = "skip"
+	JobActionTrigger JobAction = "trigger"
+)
+
+// UpcomingJob is a scheduled job with the run times it will next execute at, skipped
+// occurrences left out
+type UpcomingJob struct {
+	Job      ScheduledJob
+	Running  bool
+	NextRuns []int64
+}
+
+// UpcomingFilter selects upcoming jobs. Zero fields match everything.
+type UpcomingFilter struct {
+	Kind        string
+	OwnerID     string
+	Before      int64 // only jobs whose next run is before this time
+	Occurrences int   // runs computed per job; 1 when zero, at most MaxJobOccurrences
+}
+
+// JobAuditEntry records one operator action on a scheduled job
+type JobAuditEntry struct {
+	ID        string
+	JobID     string
+	Kind      string
+	Action    JobAction
+	Actor     string
+	Reason    string
+	RunAt     int64  // the occurrence skipped
+	Error     string // set when a triggered run failed
+	Timestamp int64
+}
+
+// ListUpcomingJobs returns the scheduled jobs matching filter with their computed
+// next run times, soonest first
+func (ws *WalletService) ListUpcomingJobs(filter UpcomingFilter) []UpcomingJob {
+	n := filter.Occurrences
+	if n <= 0 {
+		n = 1
+	}
+	n = min(n, MaxJobOccurrences)
+
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	var upcoming []UpcomingJob
+	for _, j := range ws.scheduler.jobs {
+		if j.job.Status != JobScheduled ||
+			(filter.Kind != "" && j.job.Kind != filter.Kind) ||
+			(filter.OwnerID != "" && j.job.OwnerID != filter.OwnerID) ||
+			(filter.Before != 0 && j.job.NextRun >= filter.Before) {
+			continue
+		}
+		upcoming = append(upcoming, UpcomingJob{Job: j.job, Running: j.running, NextRuns: ws.occurrences(j, n)})
+	}
+	sort.Slice(upcoming, func(i, k int) bool {
+		if upcoming[i].Job.NextRun != upcoming[k].Job.NextRun {
+			return upcoming[i].Job.NextRun < upcoming[k].Job.NextRun
+		}
+		return upcoming[i].Job.ID < upcoming[k].Job.ID
+	})
+	return upcoming
+}
+
+// CancelScheduledJob is CancelJob on behalf of an operator, with an audit record
+func (ws *WalletService) CancelScheduledJob(jobID, actor, reason string) error {
+	if err := checkJobAction(actor, reason); err != nil {
+		return err
+	}
+	kind, err := ws.jobKind(jobID)
+	if err != nil {
+		return err
+	}
+	if err := ws.CancelJob(jobID); err != nil {
+		return err
+	}
+	ws.auditJob(JobAuditEntry{JobID: jobID, Kind: kind, Action: JobActionCancel, Actor: actor, Reason: reason})
+	return nil
+}
+
+// SkipJobOccurrence skips the run of jobID due at runAt and leaves the rest of its
+// schedule in place. Skipping the only run of a one-off job cancels it.
+func (ws *WalletService) SkipJobOccurrence(jobID string, runAt int64, actor, reason string) error {
+	if err := checkJobAction(actor, reason); err != nil {
+		return err
+	}
+
+	ws.scheduler.mu.Lock()
+	j, exists := ws.scheduler.jobs[jobID]
+	if !exists || j.job.Status != JobScheduled {
+		ws.scheduler.mu.Unlock()
+		return ErrJobNotFound
+	}
+	if runAt == j.job.NextRun && j.running {
+		ws.scheduler.mu.Unlock()
+		return ErrJobRunning
+	}
+	if !slices.Contains(ws.occurrences(j, MaxJobOccurrences), runAt) {
+		ws.scheduler.mu.Unlock()
+		return ErrJobOccurrenceNotFound
+	}
+	if j.skips == nil {
+		j.skips = make(map[int64]bool)
+	}
+	j.skips[runAt] = true
+	if runAt == j.job.NextRun && !ws.advanceJob(j) {
+		j.job.Status = JobCancelled
+	}
+	kind := j.job.Kind
+	ws.scheduler.mu.Unlock()
+
+	ws.auditJob(JobAuditEntry{JobID: jobID, Kind: kind, Action: JobActionSkip, Actor: actor, Reason: reason, RunAt: runAt})
+	return nil
+}
+
+// TriggerJob runs jobID now, outside its schedule. A recurring job keeps its next run;
+// a one-off job is done once triggered.
+func (ws *WalletService) TriggerJob(jobID, actor, reason string) (JobResult, error) {
+	if err := checkJobAction(actor, reason); err != nil {
+		return JobResult{}, err
+	}
+
+	ws.scheduler.mu.Lock()
+	j, exists := ws.scheduler.jobs[jobID]
+	if !exists || j.job.Status != JobScheduled {
+		ws.scheduler.mu.Unlock()
+		return JobResult{}, ErrJobNotFound
+	}
+	if j.running {
+		ws.scheduler.mu.Unlock()
+		return JobResult{}, ErrJobRunning
+	}
+	j.running = true
+	ws.scheduler.mu.Unlock()
+
+	now := ws.now()
+	err := j.run(now)
+	result := JobResult{JobID: jobID, Kind: j.job.Kind, Err: err}
+
+	ws.scheduler.mu.Lock()
+	j.running = false
+	j.job.Runs++
+	j.job.LastRun = now.Unix()
+	j.job.LastError = ""
+	if err != nil {
+		j.job.LastError = err.Error()
+	}
+	if j.recurrence == nil && j.job.Status == JobScheduled {
+		j.job.Status = JobCompleted
+		if err != nil {
+			j.job.Status = JobFailed
+		}
+	}
+	ws.scheduler.mu.Unlock()
+
+	entry := JobAuditEntry{JobID: jobID, Kind: result.Kind, Action: JobActionTrigger, Actor: actor, Reason: reason}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	ws.auditJob(entry)
+	return result, nil
+}
+
+// ListJobAudit returns the operator actions on jobID, oldest first; every job's when
+// jobID is empty
+func (ws *WalletService) ListJobAudit(jobID string) []JobAuditEntry {
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	var entries []JobAuditEntry
+	for _, e := range ws.scheduler.audit {
+		if jobID == "" || e.JobID == jobID {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// occurrences returns up to n upcoming run times of j that are not skipped. Caller
+// must hold ws.scheduler.mu.
+func (ws *WalletService) occurrences(j *scheduledJob, n int) []int64 {
+	var runs []int64
+	nominal, next := j.nominal, j.next
+	for budget := n + len(j.skips); len(runs) < n && budget > 0; budget-- {
+		if !j.skips[next.Unix()] {
+			runs = append(runs, next.Unix())
+		}
+		if j.recurrence == nil {
+			break
+		}
+		if nominal = j.recurrence(nominal); nominal.IsZero() {
+			break
+		}
+		next = nominal
+		if j.roll {
+			next = ws.rollDate(nominal)
+		}
+	}
+	return runs
+}
+
+// jobKind returns the kind of jobID
+func (ws *WalletService) jobKind(jobID string) (string, error) {
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	j, exists := ws.scheduler.jobs[jobID]
+	if !exists {
+		return "", ErrJobNotFound
+	}
+	return j.job.Kind, nil
+}
+
+// auditJob stamps and stores an operator action
+func (ws *WalletService) auditJob(entry JobAuditEntry) {
+	entry.ID = ws.newID("jobaudit")
+	entry.Timestamp = ws.now().Unix()
+	ws.metrics.IncCounter("scheduler_job_actions_total", map[string]string{"action": string(entry.Action)})
+
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+	ws.scheduler.audit = append(ws.scheduler.audit, entry)
+}
+
+// checkJobAction requires an operator action to say who took it and why
+func checkJobAction(actor, reason string) error {
+	if strings.TrimSpace(actor) == "" || strings.TrimSpace(reason) == "" {
+		return ErrJobActionReason
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// internal/wallet/jobcontrol_test.go
+package wallet
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJobControl_SkipAndTrigger(t *testing.T) {
+	ws, clock := newTestService()
+	start := clock.Now().Add(time.Hour)
+	runs := 0
+	jobID := ws.schedule("report", "alice", start, Every(time.Hour), func(time.Time) error {
+		runs++
+		return nil
+	})
+	at := func(hours int) int64 { return start.Add(time.Duration(hours) * time.Hour).Unix() }
+
+	upcoming := ws.ListUpcomingJobs(UpcomingFilter{Kind: "report", Occurrences: 3})
+	if len(upcoming) != 1 || !reflect.DeepEqual(upcoming[0].NextRuns, []int64{at(0), at(1), at(2)}) {
+		t.Fatalf("ListUpcomingJobs() = %+v, want runs at +0h, +1h, +2h", upcoming)
+	}
+
+	tests := []struct {
+		name    string
+		runAt   int64
+		actor   string
+		wantErr error
+	}{
+		{"no actor", at(1), "", ErrJobActionReason},
+		{"not an occurrence", at(1) + 1, "ops", ErrJobOccurrenceNotFound},
+		{"later occurrence", at(1), "ops", nil},
+		{"next occurrence", at(0), "ops", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.SkipJobOccurrence(jobID, tt.runAt, tt.actor, "maintenance"); err != tt.wantErr {
+				t.Errorf("SkipJobOccurrence() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Both skipped runs are gone; the schedule carries on after them
+	upcoming = ws.ListUpcomingJobs(UpcomingFilter{OwnerID: "alice", Occurrences: 2})
+	if len(upcoming) != 1 || !reflect.DeepEqual(upcoming[0].NextRuns, []int64{at(2), at(3)}) {
+		t.Fatalf("ListUpcomingJobs() after skips = %+v, want runs at +2h, +3h", upcoming)
+	}
+	clock.Advance(2 * time.Hour)
+	if results := ws.RunDueJobs(); len(results) != 0 || runs != 0 {
+		t.Errorf("RunDueJobs() over skipped runs = %+v, ran %d times", results, runs)
+	}
+
+	// A triggered run does not move the schedule
+	if result, err := ws.TriggerJob(jobID, "ops", "backfill"); err != nil || result.Err != nil || runs != 1 {
+		t.Fatalf("TriggerJob() = %+v, %v; ran %d times", result, err, runs)
+	}
+	if job, _ := ws.GetJob(jobID); job.NextRun != at(2) || job.Status != JobScheduled || job.Runs != 1 {
+		t.Errorf("job after trigger = %+v, want next run +2h", job)
+	}
+	clock.Advance(time.Hour)
+	if ws.RunDueJobs(); runs != 2 {
+		t.Errorf("runs = %d after the next due run, want 2", runs)
+	}
+
+	var actions []JobAction
+	for _, e := range ws.ListJobAudit(jobID) {
+		actions = append(actions, e.Action)
+	}
+	if want := []JobAction{JobActionSkip, JobActionSkip, JobActionTrigger}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("ListJobAudit() actions = %v, want %v", actions, want)
+	}
+}
+
+func TestJobControl_OneOff(t *testing.T) {
+	ws, clock := newTestService()
+	next := clock.Now().Add(time.Hour)
+	noop := func(time.Time) error { return nil }
+
+	skipped := ws.schedule("once", "", next, nil, noop)
+	if err := ws.SkipJobOccurrence(skipped, next.Unix(), "ops", "not needed"); err != nil {
+		t.Fatalf("SkipJobOccurrence() error = %v", err)
+	}
+	if job, _ := ws.GetJob(skipped); job.Status != JobCancelled {
+		t.Errorf("status after skipping the only run = %s, want %s", job.Status, JobCancelled)
+	}
+
+	failing := ws.schedule("once", "", next, nil, func(time.Time) error { return errors.New("boom") })
+	if result, err := ws.TriggerJob(failing, "ops", "retry now"); err != nil || result.Err == nil {
+		t.Fatalf("TriggerJob() = %+v, %v; want the run's error in the result", result, err)
+	}
+	if job, _ := ws.GetJob(failing); job.Status != JobFailed || job.LastError != "boom" {
+		t.Errorf("job after trigger = %+v, want failed", job)
+	}
+	if _, err := ws.TriggerJob(failing, "ops", "again"); err != ErrJobNotFound {
+		t.Errorf("second TriggerJob() error = %v, want %v", err, ErrJobNotFound)
+	}
+
+	cancelled := ws.schedule("once", "", next, nil, noop)
+	if err := ws.CancelScheduledJob(cancelled, "ops", "duplicate"); err != nil {
+		t.Fatalf("CancelScheduledJob() error = %v", err)
+	}
+	if got := ws.ListUpcomingJobs(UpcomingFilter{Kind: "once"}); len(got) != 0 {
+		t.Errorf("ListUpcomingJobs() = %+v, want none left", got)
+	}
+	if audit := ws.ListJobAudit(""); len(audit) != 3 || audit[2].Action != JobActionCancel || audit[1].Error != "boom" {
+		t.Errorf("ListJobAudit() = %+v, want skip, trigger and cancel", audit)
+	}
+}
+
+func TestJobControl_RunningJob(t *testing.T) {
+	ws := NewWalletService()
+	started, release := make(chan struct{}), make(chan struct{})
+	jobID := ws.schedule("slow", "", ws.now(), nil, func(time.Time) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan []JobResult)
+	go func() { done <- ws.RunDueJobs() }()
+	<-started
+	if _, err := ws.TriggerJob(jobID, "ops", "impatient"); err != ErrJobRunning {
+		t.Errorf("TriggerJob() while running error = %v, want %v", err, ErrJobRunning)
+	}
+	if upcoming := ws.ListUpcomingJobs(UpcomingFilter{}); len(upcoming) != 1 || !upcoming[0].Running {
+		t.Errorf("ListUpcomingJobs() = %+v, want the job marked running", upcoming)
+	}
+	if results := ws.RunDueJobs(); len(results) != 0 {
+		t.Errorf("concurrent RunDueJobs() = %+v, want the running job left alone", results)
+	}
+	close(release)
+	if results := <-done; len(results) != 1 {
+		t.Errorf("RunDueJobs() = %+v, want one result", results)
+	}
+}
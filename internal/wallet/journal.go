@@ -0,0 +1,185 @@
+// internal/wallet/journal.go
+package wallet
+
+import (
+	"fmt"
+)
+
+// TransferJournal is implemented by stores that cannot commit a transaction and its
+// wallets atomically. Before writing a transaction the service journals it together
+// with the wallets as they look afterwards, and closes the entry once every write was
+// attempted. An entry still open at startup means the process died mid-write, and
+// RecoverTransfers repairs the store before it is loaded.
+type TransferJournal interface {
+	BeginTransfer(entry JournalEntry) error
+	EndTransfer(txID string) error
+	OpenTransfers() ([]JournalEntry, error)
+}
+
+// JournalEntry records a transaction about to be written and the wallets it leaves
+// behind
+type JournalEntry struct {
+	Transaction Transaction
+	Wallets     []WalletSnapshot
+	StartedAt   int64
+}
+
+// RecoveryOutcome is what recovery did with a half-written transaction
+type RecoveryOutcome string
+
+const (
+	// RecoveryCompleted means part of the transaction was durable, so the remaining
+	// writes were replayed from the journal
+	RecoveryCompleted RecoveryOutcome = "completed"
+	// RecoveryCompensated means none of the transaction was durable, so it was
+	// discarded and the stored balances stay as they were before it
+	RecoveryCompensated RecoveryOutcome = "compensated"
+)
+
+// RecoveredTransfer is one journal entry repaired at startup
+type RecoveredTransfer struct {
+	TransactionID string
+	Outcome       RecoveryOutcome
+}
+
+// RecoverTransfers completes or compensates every transaction the store journaled but
+// did not finish writing. A transaction is completed when it reached the log or any of
+// its wallets already reflects it; otherwise nothing of it is durable and it is
+// compensated by discarding it. Stores without a journal need no recovery.
+func RecoverTransfers(store Store) ([]RecoveredTransfer, error) {
+	journal, ok := store.(TransferJournal)
+	if !ok {
+		return nil, nil
+	}
+	open, err := journal.OpenTransfers()
+	if err != nil || len(open) == 0 {
+		return nil, err
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	logged := make(map[string]bool, len(state.Transactions))
+	for _, tx := range state.Transactions {
+		logged[tx.ID] = true
+	}
+	stored := make(map[string]WalletSnapshot, len(state.Wallets))
+	for _, w := range state.Wallets {
+		stored[w.UserID] = w
+	}
+
+	recovered := make([]RecoveredTransfer, 0, len(open))
+	for _, entry := range open {
+		outcome := RecoveryCompensated
+		if logged[entry.Transaction.ID] || anyWalletApplied(stored, entry.Wallets) {
+			outcome = RecoveryCompleted
+		}
+
+		if outcome == RecoveryCompleted {
+			if !logged[entry.Transaction.ID] {
+				if err := store.AppendTransaction(entry.Transaction); err != nil {
+					return recovered, fmt.Errorf("completing %s: %w", entry.Transaction.ID, err)
+				}
+				logged[entry.Transaction.ID] = true
+			}
+			for _, w := range entry.Wallets {
+				if err := store.SaveWallet(w); err != nil {
+					return recovered, fmt.Errorf("completing %s: %w", entry.Transaction.ID, err)
+				}
+				stored[w.UserID] = w
+			}
+		}
+		if err := journal.EndTransfer(entry.Transaction.ID); err != nil {
+			return recovered, err
+		}
+		recovered = append(recovered, RecoveredTransfer{TransactionID: entry.Transaction.ID, Outcome: outcome})
+	}
+	return recovered, nil
+}
+
+// RecoveredTransfers returns the half-written transactions OpenWalletService repaired
+// before loading the store
+func (ws *WalletService) RecoveredTransfers() []RecoveredTransfer {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return append([]RecoveredTransfer(nil), ws.recovered...)
+}
+
+// anyWalletApplied reports whether a stored wallet already matches its journaled state
+func anyWalletApplied(stored map[string]WalletSnapshot, wallets []WalletSnapshot) bool {
+	for _, w := range wallets {
+		if s, ok := stored[w.UserID]; ok && walletSnapshotsEqual(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// walletSnapshotsEqual compares balances and settings of two wallet snapshots
+func walletSnapshotsEqual(a, b WalletSnapshot) bool {
+	if a.UserID != b.UserID || a.Currency != b.Currency || !a.Balance.Equal(b.Balance) || a.AutoSettle != b.AutoSettle {
+		return false
+	}
+	for currency, amount := range a.Foreign {
+		if !amount.Equal(b.Foreign[currency]) {
+			return false
+		}
+	}
+	for currency, amount := range b.Foreign {
+		if !amount.Equal(a.Foreign[currency]) {
+			return false
+		}
+	}
+	return true
+}
+
+// BeginTransfer journals a transaction about to be written
+func (s *MemoryStore) BeginTransfer(entry JournalEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.journal = append(s.journal, entry)
+	return nil
+}
+
+// EndTransfer closes a journal entry
+func (s *MemoryStore) EndTransfer(txID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, entry := range s.journal {
+		if entry.Transaction.ID == txID {
+			s.journal = append(s.journal[:i], s.journal[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// OpenTransfers returns the journal entries not yet closed, oldest first
+func (s *MemoryStore) OpenTransfers() ([]JournalEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]JournalEntry(nil), s.journal...), nil
+}
+
+// BeginTransfer appends a journal record
+func (s *FileStore) BeginTransfer(entry JournalEntry) error {
+	return s.append(storeRecord{JournalBegin: &entry})
+}
+
+// EndTransfer appends a record closing a journal entry
+func (s *FileStore) EndTransfer(txID string) error {
+	return s.append(storeRecord{JournalEnd: txID})
+}
+
+// OpenTransfers replays the file and returns the journal entries not yet closed
+func (s *FileStore) OpenTransfers() ([]JournalEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replay, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+	return replay.open, nil
+}
@@ -0,0 +1,177 @@
+// internal/wallet/journal_test.go
+package wallet
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// crashExitCode is the status the helper process dies with mid-transfer
+const crashExitCode = 3
+
+// crashingStore kills the process instead of performing its crashAt-th write once armed
+type crashingStore struct {
+	*FileStore
+	crashAt, writes int
+}
+
+func (s *crashingStore) write() {
+	if s.crashAt == 0 {
+		return
+	}
+	if s.writes++; s.writes == s.crashAt {
+		os.Exit(crashExitCode)
+	}
+}
+
+func (s *crashingStore) SaveUser(u User) error { s.write(); return s.FileStore.SaveUser(u) }
+func (s *crashingStore) SaveWallet(w WalletSnapshot) error {
+	s.write()
+	return s.FileStore.SaveWallet(w)
+}
+func (s *crashingStore) AppendTransaction(tx Transaction) error {
+	s.write()
+	return s.FileStore.AppendTransaction(tx)
+}
+func (s *crashingStore) BeginTransfer(e JournalEntry) error {
+	s.write()
+	return s.FileStore.BeginTransfer(e)
+}
+func (s *crashingStore) EndTransfer(id string) error { s.write(); return s.FileStore.EndTransfer(id) }
+
+// TestJournalCrashHelper runs in a child process started by
+// TestRecoverTransfers_ProcessKilledMidTransfer and dies on the configured write
+func TestJournalCrashHelper(t *testing.T) {
+	path := os.Getenv("WALLET_CRASH_STORE")
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	crashAt, _ := strconv.Atoi(os.Getenv("WALLET_CRASH_AT"))
+
+	file, err := NewFileStore(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &crashingStore{FileStore: file}
+	ws := NewWalletService(WithStore(store))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	store.crashAt = crashAt
+	ws.Transfer("alice", "bob", 30, "rent")
+	file.Close()
+}
+
+func TestRecoverTransfers_ProcessKilledMidTransfer(t *testing.T) {
+	// A transfer writes: journal entry, transaction, alice, bob, journal close
+	tests := []struct {
+		crashAt     int
+		wantAlice   string
+		wantBob     string
+		wantOutcome RecoveryOutcome
+		wantBobTxs  int
+		wantCrashed bool
+	}{
+		{1, "100", "0", "", 0, true},
+		{2, "100", "0", RecoveryCompensated, 0, true},
+		{3, "70", "30", RecoveryCompleted, 1, true},
+		{4, "70", "30", RecoveryCompleted, 1, true},
+		{5, "70", "30", RecoveryCompleted, 1, true},
+		{6, "70", "30", "", 1, false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "wallet.jsonl")
+		cmd := exec.Command(os.Args[0], "-test.run=^TestJournalCrashHelper$")
+		cmd.Env = append(os.Environ(), "WALLET_CRASH_STORE="+path, "WALLET_CRASH_AT="+strconv.Itoa(tt.crashAt))
+		err := cmd.Run()
+		var exit *exec.ExitError
+		crashed := errors.As(err, &exit) && exit.ExitCode() == crashExitCode
+		if crashed != tt.wantCrashed || (err != nil && !crashed) {
+			t.Fatalf("crash at %d: helper exited with %v", tt.crashAt, err)
+		}
+
+		store, err := NewFileStore(path, false)
+		if err != nil {
+			t.Fatalf("crash at %d: NewFileStore() error = %v", tt.crashAt, err)
+		}
+		ws, err := OpenWalletService(store)
+		if err != nil {
+			t.Fatalf("crash at %d: OpenWalletService() error = %v", tt.crashAt, err)
+		}
+
+		alice, _ := ws.GetBalanceDecimal("alice")
+		bob, _ := ws.GetBalanceDecimal("bob")
+		if alice.String() != tt.wantAlice || bob.String() != tt.wantBob {
+			t.Errorf("crash at %d: balances = %s/%s, want %s/%s", tt.crashAt, alice, bob, tt.wantAlice, tt.wantBob)
+		}
+		if history, _ := ws.GetTransactionHistory("bob"); len(history) != tt.wantBobTxs {
+			t.Errorf("crash at %d: bob history = %d entries, want %d", tt.crashAt, len(history), tt.wantBobTxs)
+		}
+		if deviations := ws.CheckSupply(); len(deviations) != 0 {
+			t.Errorf("crash at %d: CheckSupply() = %+v", tt.crashAt, deviations)
+		}
+
+		recovered := ws.RecoveredTransfers()
+		switch {
+		case tt.wantOutcome == "" && len(recovered) != 0:
+			t.Errorf("crash at %d: recovered = %+v, want none", tt.crashAt, recovered)
+		case tt.wantOutcome != "" && (len(recovered) != 1 || recovered[0].Outcome != tt.wantOutcome):
+			t.Errorf("crash at %d: recovered = %+v, want %s", tt.crashAt, recovered, tt.wantOutcome)
+		}
+
+		// Recovery closed the entry for good
+		if open, _ := store.OpenTransfers(); len(open) != 0 {
+			t.Errorf("crash at %d: open journal entries after recovery = %d", tt.crashAt, len(open))
+		}
+		store.Close()
+	}
+}
+
+// failingWalletStore rejects wallet writes
+type failingWalletStore struct{ *MemoryStore }
+
+func (failingWalletStore) SaveWallet(WalletSnapshot) error { return errors.New("disk full") }
+
+func TestRecoverTransfers_MemoryStore(t *testing.T) {
+	// A failed write in a live process is reported, not left for recovery
+	failing := failingWalletStore{NewMemoryStore()}
+	ws := NewWalletService(WithStore(failing))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 10, "seed")
+	if open, _ := failing.OpenTransfers(); len(open) != 0 {
+		t.Errorf("open journal entries = %d, want 0", len(open))
+	}
+	if report := ws.CheckHealth(); report.Status != HealthFailing {
+		t.Errorf("CheckHealth() status = %s, want %s", report.Status, HealthFailing)
+	}
+
+	// A wallet write landed without its transaction: recovery appends the transaction
+	store := NewMemoryStore()
+	store.SaveUser(User{ID: "alice", Name: "Alice", Email: "alice@example.com"})
+	after := WalletSnapshot{UserID: "alice", Currency: DefaultCurrency, Balance: decimal.NewFromInt(5)}
+	store.SaveWallet(after)
+	tx := Transaction{ID: "tx_1", ToUserID: "alice", Amount: decimal.NewFromInt(5), Type: TransactionDeposit}
+	store.BeginTransfer(JournalEntry{Transaction: tx, Wallets: []WalletSnapshot{after}})
+
+	metrics := NewInMemoryMetrics()
+	restored, err := OpenWalletService(store, WithMetrics(metrics))
+	if err != nil {
+		t.Fatalf("OpenWalletService() error = %v", err)
+	}
+	if got := restored.RecoveredTransfers(); len(got) != 1 || got[0].Outcome != RecoveryCompleted {
+		t.Errorf("RecoveredTransfers() = %+v", got)
+	}
+	if _, err := restored.GetTransaction("tx_1"); err != nil {
+		t.Errorf("GetTransaction(recovered) error = %v", err)
+	}
+	if got := metrics.Counter("transfers_recovered_total", map[string]string{"outcome": "completed"}); got != 1 {
+		t.Errorf("transfers_recovered_total = %d, want 1", got)
+	}
+}
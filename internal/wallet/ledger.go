@@ -0,0 +1,237 @@
+// internal/wallet/ledger.go
+package wallet
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// creditTypes only add funds to ToUserID; money enters the wallet from outside
+var creditTypes = map[TransactionType]bool{
+	TransactionDeposit:           true,
+	TransactionFederationIn:      true,
+	TransactionFederationRefund:  true,
+	TransactionGiftClaim:         true,
+	TransactionGiftRefund:        true,
+	TransactionHoldRelease:       true,
+	TransactionHoldReversal:      true,
+	TransactionAdjustmentCredit:  true,
+	TransactionRailReversal:      true,
+	TransactionCardRelease:       true,
+	TransactionReserveRelease:    true,
+	TransactionCardRefund:        true,
+	TransactionInterest:          true,
+	TransactionLoyaltyRedemption: true,
+	TransactionMint:              true,
+}
+
+// debitTypes only remove funds from FromUserID; money leaves the wallet to outside
+var debitTypes = map[TransactionType]bool{
+	TransactionWithdraw:        true,
+	TransactionFederationOut:   true,
+	TransactionGiftEscrow:      true,
+	TransactionComplianceHold:  true,
+	TransactionAdjustmentDebit: true,
+	TransactionCardHold:        true,
+	TransactionReserveHold:     true,
+	TransactionHoldCapture:     true,
+	TransactionBurn:            true,
+	TransactionOverdraft:       true,
+}
+
+// currencyOf returns the currency a transaction's Amount is denominated in
+func (tx *Transaction) currencyOf() string {
+	if tx.Currency == "" {
+		return DefaultCurrency
+	}
+	return tx.Currency
+}
+
+// balanceEffect returns the signed amount by which tx changes the userID balance held in currency
+func (tx *Transaction) balanceEffect(userID, currency string) decimal.Decimal {
+	if !tx.settled() {
+		return decimal.Zero
+	}
+	if tx.Type == TransactionConversion {
+		if tx.FromUserID != userID {
+			return decimal.Zero
+		}
+		switch currency {
+		case tx.currencyOf():
+			return tx.Amount.Neg()
+		case tx.ToCurrency:
+			return tx.ToAmount
+		}
+		return decimal.Zero
+	}
+
+	if tx.currencyOf() != currency {
+		return decimal.Zero
+	}
+
+	switch transactionKind(tx.Type) {
+	case KindCredit:
+		if tx.ToUserID == userID {
+			return tx.Amount
+		}
+	case KindDebit:
+		if tx.FromUserID == userID {
+			return tx.Amount.Neg()
+		}
+	case KindTransfer:
+		if tx.FromUserID == userID {
+			return tx.Amount.Neg()
+		}
+		if tx.ToUserID == userID {
+			return tx.Amount
+		}
+	}
+	return decimal.Zero
+}
+
+// ledgerBalance recomputes a user's balance in currency by replaying the transaction log,
+// including archived entries. Callers that need a result consistent with the stored
+// balance must hold the user's lock.
+func (ws *WalletService) ledgerBalance(userID, currency string) decimal.Decimal {
+	it := ws.iterate(userID, IterateOptions{})
+	defer it.Close()
+
+	balance := decimal.Zero
+	for it.Next() {
+		balance = balance.Add(it.Transaction().balanceEffect(userID, currency))
+	}
+	return balance
+}
+
+// postCredit adds tx.Amount to tx.ToUserID's holding in tx.Currency and records tx.
+// ID and Timestamp are filled in when empty.
+func (ws *WalletService) postCredit(ctx context.Context, tx *Transaction) (err error) {
+	timer := ws.startOp(string(tx.Type), tx.ToUserID)
+	defer func() {
+		timer.finish(err)
+		ws.auditTx(ctx, tx, err)
+	}()
+
+	userLock := ws.userLocks.getLock(tx.ToUserID)
+	userLock.Lock()
+	defer userLock.Unlock()
+	timer.locked()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[tx.ToUserID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	if tx.Currency == "" {
+		tx.Currency = wallet.Currency
+	}
+	if err := ws.checkAmount(tx.Currency, tx.Amount, false); err != nil {
+		return err
+	}
+	stampTrace(ctx, tx)
+	if err := ws.validate(tx); err != nil {
+		return err
+	}
+
+	maximum := ws.maximumFor(tx.ToUserID, tx.Currency, tx.Type)
+	wallet.mu.Lock()
+	if err := wallet.checkMaximum(tx.Currency, tx.Amount, maximum); err != nil {
+		wallet.mu.Unlock()
+		return err
+	}
+	wallet.adjust(tx.Currency, tx.Amount)
+	wallet.publish()
+	autoSettle := wallet.AutoSettle && tx.Currency != wallet.Currency
+	wallet.mu.Unlock()
+
+	ws.stampTransaction(tx)
+	ws.recordTransaction(tx)
+
+	if autoSettle {
+		ws.autoSettle(wallet, tx)
+	}
+	ws.reserveCredit(wallet, tx)
+
+	return nil
+}
+
+// postDebit removes tx.Amount from tx.FromUserID's holding in tx.Currency and records tx.
+// It fails with ErrInsufficientBalance rather than overdrawing the available holding,
+// except that a withdrawal may draw on the wallet's overdraft and is then recorded as
+// TransactionOverdraft.
+func (ws *WalletService) postDebit(ctx context.Context, tx *Transaction) error {
+	return ws.postDebitReleasing(ctx, tx, decimal.Zero)
+}
+
+// postDebitReleasing is postDebit for settling a hold: unhold is released from the
+// wallet's held funds in the same step, so the debit may draw on it
+func (ws *WalletService) postDebitReleasing(ctx context.Context, tx *Transaction, unhold decimal.Decimal) (err error) {
+	timer := ws.startOp(string(tx.Type), tx.FromUserID)
+	defer func() {
+		timer.finish(err)
+		ws.auditTx(ctx, tx, err)
+	}()
+
+	userLock := ws.userLocks.getLock(tx.FromUserID)
+	userLock.Lock()
+	defer userLock.Unlock()
+	timer.locked()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[tx.FromUserID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	if tx.Currency == "" {
+		tx.Currency = wallet.Currency
+	}
+	if err := ws.checkAmount(tx.Currency, tx.Amount, false); err != nil {
+		return err
+	}
+	stampTrace(ctx, tx)
+	if err := ws.validate(tx); err != nil {
+		return err
+	}
+
+	minimum := ws.minimumFor(tx.FromUserID, tx.Currency, tx.Type)
+	overdraft := ws.overdraftFor(wallet, tx.Currency, tx.Type)
+	wallet.mu.Lock()
+	if wallet.available(tx.Currency).Add(unhold).Add(overdraft).LessThan(tx.Amount) {
+		wallet.mu.Unlock()
+		return ErrInsufficientBalance
+	}
+	if err := wallet.checkMinimum(tx.Currency, tx.Amount, unhold, minimum); err != nil {
+		wallet.mu.Unlock()
+		return err
+	}
+	before := wallet.balanceIn(tx.Currency)
+	wallet.hold(tx.Currency, unhold.Neg())
+	wallet.adjust(tx.Currency, tx.Amount.Neg())
+	if overdraft.IsPositive() {
+		markOverdraft(tx, before, wallet.balanceIn(tx.Currency))
+	}
+	wallet.publish()
+	wallet.mu.Unlock()
+
+	ws.stampTransaction(tx)
+	ws.recordTransaction(tx)
+
+	return nil
+}
+
+// stampTransaction assigns an ID and timestamp to tx when they are missing
+func (ws *WalletService) stampTransaction(tx *Transaction) {
+	if tx.ID == "" {
+		tx.ID = ws.newID("tx")
+	}
+	if tx.Timestamp == 0 {
+		tx.Timestamp = ws.now().Unix()
+	}
+}
@@ -0,0 +1,257 @@
+// internal/wallet/limits.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for spending limits
+var (
+	ErrLimitExceeded        = errors.New("spending limit exceeded")
+	ErrInvalidSpendingLimit = errors.New("spending limits must not be negative")
+)
+
+// Rolling windows of the daily and monthly spending limits
+const (
+	SpendingDay   = 24 * time.Hour
+	SpendingMonth = 30 * SpendingDay
+)
+
+// spendingExemptTypes are debits and transfers spending limits do not count: those the
+// system posts on its own account, admin corrections, treasury burns and rolling
+// reserve withholding, and refunds, which return money the wallet was paid
+var spendingExemptTypes = map[TransactionType]bool{
+	TransactionAdjustmentDebit: true,
+	TransactionBurn:            true,
+	TransactionReserveHold:     true,
+	TransactionRefund:          true,
+}
+
+// SpendingLimits caps the withdrawals, transfers and other debits leaving a wallet.
+// Debits in each currency count separately against the same amounts. Zero fields are
+// unlimited.
+type SpendingLimits struct {
+	PerTransaction decimal.Decimal
+	Daily          decimal.Decimal // over the rolling SpendingDay
+	Monthly        decimal.Decimal // over the rolling SpendingMonth
+}
+
+// SpendingUsage reports a wallet's limits and how much of each window its debits in
+// Currency, the wallet's base currency, use
+type SpendingUsage struct {
+	UserID   string
+	Currency string
+	Limits   SpendingLimits
+	Daily    decimal.Decimal
+	Monthly  decimal.Decimal
+}
+
+// spendEntry is one debit counted against a wallet's limits
+type spendEntry struct {
+	at       int64
+	amount   decimal.Decimal
+	currency string
+}
+
+// spendingBook holds each limited wallet's limits and the debits inside its monthly
+// window, oldest first. Wallets without limits are not tracked.
+type spendingBook struct {
+	mu     sync.Mutex
+	limits map[string]SpendingLimits
+	spent  map[string][]spendEntry
+}
+
+// SetSpendingLimits sets userID's limits, replacing any before. Debits already made
+// inside the windows count against the new limits. Zero limits remove them.
+func (ws *WalletService) SetSpendingLimits(userID string, limits SpendingLimits) error {
+	if limits.PerTransaction.IsNegative() || limits.Daily.IsNegative() || limits.Monthly.IsNegative() {
+		return ErrInvalidSpendingLimit
+	}
+
+	// Debits from userID are recorded under its lock, so none is missed between the
+	// replay and installing the limits
+	userLock := ws.userLocks.getLock(userID)
+	userLock.Lock()
+	defer userLock.Unlock()
+
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	if limits.unlimited() {
+		ws.spending.mu.Lock()
+		delete(ws.spending.limits, userID)
+		delete(ws.spending.spent, userID)
+		ws.spending.mu.Unlock()
+		return nil
+	}
+
+	var spent []spendEntry
+	it := ws.iterate(userID, IterateOptions{Since: ws.now().Add(-SpendingMonth).Unix()})
+	for it.Next() {
+		if tx := it.Transaction(); countsAsSpending(tx) {
+			spent = append(spent, spendEntry{at: tx.Timestamp, amount: tx.Amount, currency: tx.currencyOf()})
+		}
+	}
+	it.Close()
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	ws.spending.mu.Lock()
+	defer ws.spending.mu.Unlock()
+	if ws.spending.limits == nil {
+		ws.spending.limits = make(map[string]SpendingLimits)
+		ws.spending.spent = make(map[string][]spendEntry)
+	}
+	ws.spending.limits[userID] = limits
+	ws.spending.spent[userID] = spent
+	return nil
+}
+
+// GetSpendingLimits returns userID's limits; all zero when it has none
+func (ws *WalletService) GetSpendingLimits(userID string) (SpendingLimits, error) {
+	if !ws.walletExists(userID) {
+		return SpendingLimits{}, ErrUserNotFound
+	}
+	ws.spending.mu.Lock()
+	defer ws.spending.mu.Unlock()
+	return ws.spending.limits[userID], nil
+}
+
+// GetSpendingUsage returns userID's limits and how much of its daily and monthly
+// windows is used
+func (ws *WalletService) GetSpendingUsage(userID string) (*SpendingUsage, error) {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	wallet.mu.RLock()
+	currency := wallet.Currency
+	wallet.mu.RUnlock()
+
+	ws.spending.mu.Lock()
+	defer ws.spending.mu.Unlock()
+	usage := &SpendingUsage{UserID: userID, Currency: currency, Limits: ws.spending.limits[userID]}
+	usage.Daily, usage.Monthly = ws.spentWithin(userID, currency)
+	return usage, nil
+}
+
+// checkSpendingLimit refuses a withdrawal or transfer that would take its sender past
+// a limit. Caller must hold the sender's lock.
+func (ws *WalletService) checkSpendingLimit(tx *Transaction) error {
+	if !countsAsSpending(tx) {
+		return nil
+	}
+
+	ws.spending.mu.Lock()
+	defer ws.spending.mu.Unlock()
+
+	limits, limited := ws.spending.limits[tx.FromUserID]
+	if !limited {
+		return nil
+	}
+	if limits.PerTransaction.IsPositive() && tx.Amount.GreaterThan(limits.PerTransaction) {
+		return fmt.Errorf("%w: %s per transaction", ErrLimitExceeded, limits.PerTransaction)
+	}
+	return ws.checkSpendingWindows(limits, tx.FromUserID, tx.currencyOf(), tx.Amount)
+}
+
+// checkBatchSpending refuses a batch whose legs together would take their sender past
+// a daily or monthly limit; validate has checked each leg alone. The legs share one
+// sender and currency. Caller must hold the sender's lock.
+func (ws *WalletService) checkBatchSpending(txs []*Transaction) error {
+	total := decimal.Zero
+	for _, tx := range txs {
+		if countsAsSpending(tx) {
+			total = total.Add(tx.Amount)
+		}
+	}
+	if total.IsZero() {
+		return nil
+	}
+
+	ws.spending.mu.Lock()
+	defer ws.spending.mu.Unlock()
+	limits, limited := ws.spending.limits[txs[0].FromUserID]
+	if !limited {
+		return nil
+	}
+	return ws.checkSpendingWindows(limits, txs[0].FromUserID, txs[0].currencyOf(), total)
+}
+
+// checkSpendingWindows refuses amount if it would take userID's debits in currency past
+// its daily or monthly limit. Caller must hold ws.spending.mu.
+func (ws *WalletService) checkSpendingWindows(limits SpendingLimits, userID, currency string, amount decimal.Decimal) error {
+	daily, monthly := ws.spentWithin(userID, currency)
+	if limits.Daily.IsPositive() && daily.Add(amount).GreaterThan(limits.Daily) {
+		return fmt.Errorf("%w: %s per day, %s used", ErrLimitExceeded, limits.Daily, daily)
+	}
+	if limits.Monthly.IsPositive() && monthly.Add(amount).GreaterThan(limits.Monthly) {
+		return fmt.Errorf("%w: %s per month, %s used", ErrLimitExceeded, limits.Monthly, monthly)
+	}
+	return nil
+}
+
+// trackSpending counts a recorded withdrawal or transfer against its sender's limits
+func (ws *WalletService) trackSpending(tx *Transaction) {
+	if !countsAsSpending(tx) {
+		return
+	}
+	ws.spending.mu.Lock()
+	defer ws.spending.mu.Unlock()
+
+	if _, limited := ws.spending.limits[tx.FromUserID]; limited {
+		entry := spendEntry{at: tx.Timestamp, amount: tx.Amount, currency: tx.currencyOf()}
+		ws.spending.spent[tx.FromUserID] = append(ws.spending.spent[tx.FromUserID], entry)
+	}
+}
+
+// spentWithin drops userID's debits older than the monthly window and sums the rest
+// in currency over the daily and monthly windows. Caller must hold ws.spending.mu.
+func (ws *WalletService) spentWithin(userID, currency string) (daily, monthly decimal.Decimal) {
+	now := ws.now()
+	monthStart, dayStart := now.Add(-SpendingMonth).Unix(), now.Add(-SpendingDay).Unix()
+
+	spent := ws.spending.spent[userID]
+	for len(spent) > 0 && spent[0].at < monthStart {
+		spent = spent[1:]
+	}
+	if _, tracked := ws.spending.spent[userID]; tracked {
+		ws.spending.spent[userID] = spent
+	}
+
+	daily, monthly = decimal.Zero, decimal.Zero
+	for _, e := range spent {
+		if e.currency != currency {
+			continue
+		}
+		monthly = monthly.Add(e.amount)
+		if e.at >= dayStart {
+			daily = daily.Add(e.amount)
+		}
+	}
+	return daily, monthly
+}
+
+// unlimited reports whether no limit is set
+func (l SpendingLimits) unlimited() bool {
+	return !l.PerTransaction.IsPositive() && !l.Daily.IsPositive() && !l.Monthly.IsPositive()
+}
+
+// countsAsSpending reports whether tx is a debit spending limits count: any built-in or
+// custom debit or transfer out of a wallet that is not exempt
+func countsAsSpending(tx *Transaction) bool {
+	if tx.FromUserID == "" || spendingExemptTypes[tx.Type] {
+		return false
+	}
+	kind := transactionKind(tx.Type)
+	return kind == KindDebit || kind == KindTransfer
+}
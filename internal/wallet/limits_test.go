@@ -0,0 +1,190 @@
+// internal/wallet/limits_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSpendingLimits_Windows(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 1000, "seed")
+
+	// Spending before the limits are set counts against them
+	ws.Withdraw("alice", 20, "cash")
+	limits := SpendingLimits{PerTransaction: decimal.NewFromInt(50), Daily: decimal.NewFromInt(100), Monthly: decimal.NewFromInt(250)}
+	if err := ws.SetSpendingLimits("alice", limits); err != nil {
+		t.Fatalf("SetSpendingLimits() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		op      func() error
+		wantErr error
+		daily   int64
+		monthly int64
+	}{
+		{"over per-transaction", 0, func() error { return ws.Withdraw("alice", 51, "cash") }, ErrLimitExceeded, 20, 20},
+		{"transfer within", 0, func() error { return ws.Transfer("alice", "bob", 50, "rent") }, nil, 70, 70},
+		{"deposit not counted", 0, func() error { return ws.Deposit("alice", 500, "salary") }, nil, 70, 70},
+		{"withdraw up to daily", 0, func() error { return ws.Withdraw("alice", 30, "cash") }, nil, 100, 100},
+		{"over daily", time.Hour, func() error { return ws.Withdraw("alice", 1, "cash") }, ErrLimitExceeded, 100, 100},
+		{"next day", SpendingDay, func() error { return ws.Withdraw("alice", 50, "cash") }, nil, 50, 150},
+		{"day after", SpendingDay + time.Hour, func() error { return ws.Transfer("alice", "bob", 50, "rent") }, nil, 50, 200},
+		{"third day", SpendingDay + time.Hour, func() error { return ws.Withdraw("alice", 50, "cash") }, nil, 50, 250},
+		{"over monthly", SpendingDay + time.Hour, func() error { return ws.Withdraw("alice", 1, "cash") }, ErrLimitExceeded, 0, 250},
+		{"month rolls over", SpendingMonth, func() error { return ws.Withdraw("alice", 50, "cash") }, nil, 50, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if err := tt.op(); !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			usage, err := ws.GetSpendingUsage("alice")
+			if err != nil {
+				t.Fatalf("GetSpendingUsage() error = %v", err)
+			}
+			if !usage.Daily.Equal(decimal.NewFromInt(tt.daily)) || !usage.Monthly.Equal(decimal.NewFromInt(tt.monthly)) {
+				t.Errorf("usage = %s daily, %s monthly; want %d, %d", usage.Daily, usage.Monthly, tt.daily, tt.monthly)
+			}
+		})
+	}
+}
+
+func TestSpendingLimits_Settings(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	tests := []struct {
+		name    string
+		userID  string
+		limits  SpendingLimits
+		wantErr error
+	}{
+		{"negative", "alice", SpendingLimits{Daily: decimal.NewFromInt(-1)}, ErrInvalidSpendingLimit},
+		{"unknown user", "ghost", SpendingLimits{Daily: decimal.NewFromInt(10)}, ErrUserNotFound},
+		{"set", "alice", SpendingLimits{Daily: decimal.NewFromInt(10)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.SetSpendingLimits(tt.userID, tt.limits); err != tt.wantErr {
+				t.Errorf("SetSpendingLimits() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got, _ := ws.GetSpendingLimits("alice"); !got.Daily.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("GetSpendingLimits() = %+v, want daily 10", got)
+	}
+	if err := ws.Withdraw("alice", 11, "cash"); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Withdraw() over limit error = %v, want %v", err, ErrLimitExceeded)
+	}
+
+	// Clearing the limits lifts them
+	ws.SetSpendingLimits("alice", SpendingLimits{})
+	if err := ws.Withdraw("alice", 11, "cash"); err != nil {
+		t.Errorf("Withdraw() after clearing limits error = %v", err)
+	}
+}
+
+func TestSpendingLimits_CountsByKind(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 1000, "seed")
+	limits := SpendingLimits{PerTransaction: decimal.NewFromInt(10), Daily: decimal.NewFromInt(25)}
+	if err := ws.SetSpendingLimits("alice", limits); err != nil {
+		t.Fatalf("SetSpendingLimits() error = %v", err)
+	}
+	custom := func(req CustomTransaction) func() error {
+		return func() error {
+			_, err := ws.PostCustomTransaction(req)
+			return err
+		}
+	}
+
+	tests := []struct {
+		name    string
+		op      func() error
+		wantErr error
+		daily   int64
+	}{
+		{"custom debit over per-transaction", custom(CustomTransaction{Type: testChargebackFee.Type, FromUserID: "alice", Amount: decimal.NewFromInt(11)}), ErrLimitExceeded, 0},
+		{"custom debit within", custom(CustomTransaction{Type: testChargebackFee.Type, FromUserID: "alice", Amount: decimal.NewFromInt(10)}), nil, 10},
+		{"custom transfer within", custom(CustomTransaction{Type: testAllowance.Type, FromUserID: "alice", ToUserID: "bob", Amount: decimal.NewFromInt(10)}), nil, 20},
+		{"custom transfer over daily", custom(CustomTransaction{Type: testAllowance.Type, FromUserID: "alice", ToUserID: "bob", Amount: decimal.NewFromInt(6)}), ErrLimitExceeded, 20},
+		{"custom credit not counted", custom(CustomTransaction{Type: testSalary.Type, ToUserID: "alice", Amount: decimal.NewFromInt(50), Description: "May"}), nil, 20},
+		{"hold over daily", func() error { _, err := ws.Hold("alice", decimal.NewFromInt(6)); return err }, ErrLimitExceeded, 20},
+		{"held and captured", func() error {
+			h, err := ws.Hold("alice", decimal.NewFromInt(5))
+			if err != nil {
+				return err
+			}
+			_, err = ws.CaptureHold(h.ID, decimal.NewFromInt(5))
+			return err
+		}, nil, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			usage, _ := ws.GetSpendingUsage("alice")
+			if !usage.Daily.Equal(decimal.NewFromInt(tt.daily)) {
+				t.Errorf("daily usage = %s, want %d", usage.Daily, tt.daily)
+			}
+		})
+	}
+}
+
+func TestSpendingLimits_BatchTotal(t *testing.T) {
+	ws := NewWalletService()
+	for _, id := range []string{"alice", "bob", "carol", "dave"} {
+		ws.CreateUser(id, id, id+"@example.com")
+	}
+	ws.Deposit("alice", 1000, "seed")
+	if err := ws.SetSpendingLimits("alice", SpendingLimits{Daily: decimal.NewFromInt(20)}); err != nil {
+		t.Fatalf("SetSpendingLimits() error = %v", err)
+	}
+	pay := func(amount int64, ids ...string) []Payout {
+		var payouts []Payout
+		for _, id := range ids {
+			payouts = append(payouts, Payout{UserID: id, Amount: decimal.NewFromInt(amount)})
+		}
+		return payouts
+	}
+
+	tests := []struct {
+		name        string
+		payouts     []Payout
+		wantErr     error
+		wantDaily   int64
+		wantBalance int64
+	}{
+		{"legs together over daily", pay(15, "bob", "carol", "dave"), ErrLimitExceeded, 0, 1000},
+		{"legs together within daily", pay(10, "bob", "carol"), nil, 20, 980},
+		{"any leg over what is left", pay(1, "dave"), ErrLimitExceeded, 20, 980},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.BatchPayout("alice", tt.payouts, "prizes"); !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("BatchPayout() error = %v, want %v", err, tt.wantErr)
+			}
+			usage, _ := ws.GetSpendingUsage("alice")
+			if !usage.Daily.Equal(decimal.NewFromInt(tt.wantDaily)) {
+				t.Errorf("daily usage = %s, want %d", usage.Daily, tt.wantDaily)
+			}
+			if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(tt.wantBalance)) {
+				t.Errorf("alice's balance = %s, want %d", b, tt.wantBalance)
+			}
+		})
+	}
+}
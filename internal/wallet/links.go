@@ -0,0 +1,283 @@
+// internal/wallet/links.go
+package wallet
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrTransactionNotFound is returned when a transaction ID is not in the log or archive
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// TransactionLinkKind distinguishes parent/child links from peer links
+type TransactionLinkKind string
+
+const (
+	LinkParent  TransactionLinkKind = "parent"
+	LinkRelated TransactionLinkKind = "related"
+)
+
+// TransactionLink is an edge of a transaction graph. For parent links From is the
+// parent and To the child.
+type TransactionLink struct {
+	From string
+	To   string
+	Kind TransactionLinkKind
+}
+
+// TransactionTree is the connected graph of transactions linked to one another
+type TransactionTree struct {
+	RootID       string         // topmost ancestor of the requested transaction
+	Transactions []*Transaction // breadth-first from the root
+	Links        []TransactionLink
+}
+
+// txRef locates a linked transaction in the archive after it leaves the hot log
+type txRef struct {
+	userID    string
+	timestamp int64
+}
+
+// txIndex indexes the log by transaction ID and idempotency key and records links.
+// Guarded by ws.mu.
+type txIndex struct {
+	byID     map[string]*Transaction // hot log only
+	refs     map[string]txRef        // linked transactions, kept after archiving
+	children map[string][]string
+	related  map[string][]string
+	keys     map[idempotencyScope]string // user's idempotency key -> transaction ID
+	pending  map[string]string           // unresolved pending transaction ID -> user
+	resolved map[string]string           // pending transaction ID -> entry completing or failing it
+	byUser   map[string][]int            // user -> log positions of the hot entries involving them
+}
+
+// init makes the index's maps on first use
+func (idx *txIndex) init() {
+	if idx.byID == nil {
+		idx.byID = make(map[string]*Transaction)
+		idx.refs = make(map[string]txRef)
+		idx.children = make(map[string][]string)
+		idx.related = make(map[string][]string)
+		idx.keys = make(map[idempotencyScope]string)
+	}
+}
+
+// indexTransaction adds a newly logged transaction to the index. Caller must hold ws.mu.
+func (ws *WalletService) indexTransaction(tx *Transaction) {
+	idx := &ws.txIndex
+	idx.init()
+	idx.byID[tx.ID] = tx
+
+	if scope, keyed := idempotencyScopeOf(tx); keyed {
+		idx.keys[scope] = tx.ID
+		ws.rememberRef(tx.ID)
+	}
+
+	if tx.ParentTxID != "" {
+		idx.children[tx.ParentTxID] = append(idx.children[tx.ParentTxID], tx.ID)
+		ws.rememberRef(tx.ParentTxID)
+		ws.rememberRef(tx.ID)
+	}
+	for _, peer := range tx.RelatedTxIDs {
+		ws.linkRelated(tx.ID, peer)
+	}
+	ws.indexStatus(tx)
+	ws.indexUser(tx)
+}
+
+// rememberRef records where a linked transaction lives. Caller must hold ws.mu.
+func (ws *WalletService) rememberRef(txID string) {
+	if _, known := ws.txIndex.refs[txID]; known {
+		return
+	}
+	if tx, hot := ws.txIndex.byID[txID]; hot {
+		ws.txIndex.refs[txID] = txRef{userID: tx.FromUserID, timestamp: tx.Timestamp}
+	}
+}
+
+// relinkArchived restores the links and idempotency keys of archived entries of
+// history, which a snapshot does not carry, so refunds, transaction trees and replays
+// still reach them after a restore
+func (ws *WalletService) relinkArchived(history []*Transaction) {
+	located := make(map[string]txRef, len(history))
+	for _, tx := range history {
+		located[tx.ID] = txRef{userID: tx.FromUserID, timestamp: tx.Timestamp}
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	idx := &ws.txIndex
+	idx.init()
+	link := func(txID string) {
+		if ref, ok := located[txID]; ok {
+			if _, known := idx.refs[txID]; !known {
+				idx.refs[txID] = ref
+			}
+		}
+	}
+	for _, tx := range history {
+		_, hot := idx.byID[tx.ID]
+		if scope, keyed := idempotencyScopeOf(tx); keyed && !hot {
+			idx.keys[scope] = tx.ID
+			link(tx.ID)
+		}
+		if tx.ParentTxID == "" {
+			continue
+		}
+		if !hot && !slices.Contains(idx.children[tx.ParentTxID], tx.ID) {
+			idx.children[tx.ParentTxID] = append(idx.children[tx.ParentTxID], tx.ID)
+		}
+		link(tx.ParentTxID)
+		link(tx.ID)
+	}
+}
+
+// linkRelated records a symmetric peer link. Caller must hold ws.mu.
+func (ws *WalletService) linkRelated(a, b string) {
+	for _, existing := range ws.txIndex.related[a] {
+		if existing == b {
+			return
+		}
+	}
+	ws.txIndex.related[a] = append(ws.txIndex.related[a], b)
+	ws.txIndex.related[b] = append(ws.txIndex.related[b], a)
+	ws.rememberRef(a)
+	ws.rememberRef(b)
+}
+
+// LinkTransactions records that two already logged transactions belong together.
+// The link is kept in the index and reported by GetTransactionTree; the logged
+// transactions themselves are immutable.
+func (ws *WalletService) LinkTransactions(txID, relatedTxID string) error {
+	if txID == relatedTxID {
+		return ErrTransactionNotFound
+	}
+	for _, id := range []string{txID, relatedTxID} {
+		if _, err := ws.findTransaction(id); err != nil {
+			return err
+		}
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.txIndex.byID == nil {
+		return ErrTransactionNotFound
+	}
+	ws.linkRelated(txID, relatedTxID)
+	return nil
+}
+
+// GetTransaction returns a transaction by ID from the hot log, or from the archive
+// when it has links
+func (ws *WalletService) GetTransaction(txID string) (*Transaction, error) {
+	return ws.findTransaction(txID)
+}
+
+// GetTransactionTree returns every transaction connected to txID through parent or
+// related links: fees and their charges, refunds and originals, conversion legs and
+// netting sets
+func (ws *WalletService) GetTransactionTree(txID string) (*TransactionTree, error) {
+	if _, err := ws.findTransaction(txID); err != nil {
+		return nil, err
+	}
+
+	// Climb to the topmost ancestor
+	root := txID
+	seen := map[string]bool{root: true}
+	for {
+		tx, err := ws.findTransaction(root)
+		if err != nil || tx.ParentTxID == "" || seen[tx.ParentTxID] {
+			break
+		}
+		if _, err := ws.findTransaction(tx.ParentTxID); err != nil {
+			break
+		}
+		root = tx.ParentTxID
+		seen[root] = true
+	}
+
+	tree := &TransactionTree{RootID: root}
+	visited := map[string]bool{root: true}
+	linked := map[TransactionLink]bool{}
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		tx, err := ws.findTransaction(id)
+		if err != nil {
+			return nil, err
+		}
+		tree.Transactions = append(tree.Transactions, tx)
+
+		ws.mu.RLock()
+		var edges []TransactionLink
+		if tx.ParentTxID != "" {
+			edges = append(edges, TransactionLink{From: tx.ParentTxID, To: id, Kind: LinkParent})
+		}
+		for _, child := range ws.txIndex.children[id] {
+			edges = append(edges, TransactionLink{From: id, To: child, Kind: LinkParent})
+		}
+		for _, peer := range ws.txIndex.related[id] {
+			a, b := id, peer
+			if b < a {
+				a, b = b, a
+			}
+			edges = append(edges, TransactionLink{From: a, To: b, Kind: LinkRelated})
+		}
+		ws.mu.RUnlock()
+
+		for _, e := range edges {
+			next := e.To
+			if next == id {
+				next = e.From
+			}
+			if _, err := ws.findTransaction(next); err != nil {
+				continue
+			}
+			if !linked[e] {
+				linked[e] = true
+				tree.Links = append(tree.Links, e)
+			}
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// findTransaction looks a transaction up in the hot log, then in the archive
+func (ws *WalletService) findTransaction(txID string) (*Transaction, error) {
+	ws.mu.RLock()
+	tx, hot := ws.txIndex.byID[txID]
+	ref, linked := ws.txIndex.refs[txID]
+	ws.mu.RUnlock()
+
+	if hot {
+		return tx, nil
+	}
+	if !linked || ws.archive == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	it, err := ws.archive.Iterate(ref.userID, IterateOptions{Since: ref.timestamp, Until: ref.timestamp + 1})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		if it.Transaction().ID == txID {
+			return it.Transaction(), nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrTransactionNotFound
+}
@@ -0,0 +1,77 @@
+// internal/wallet/links_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGetTransactionTree(t *testing.T) {
+	ws := NewWalletService(
+		WithRateProvider(StaticRateProvider{"EUR/USD": decimal.RequireFromString("1.1")}),
+		WithArchive(NewMemoryArchive()),
+	)
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.SetAutoSettle("alice", true)
+
+	// Credit + auto-settle conversion form a parent/child pair
+	ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(10), "invoice")
+	history, _ := ws.GetTransactionHistory("alice")
+	credit, conversion := history[0], history[1]
+
+	// A transfer linked as a peer of the conversion
+	ws.Transfer("alice", "bob", 5, "split")
+	history, _ = ws.GetTransactionHistory("bob")
+	transfer := history[0]
+	if err := ws.LinkTransactions(conversion.ID, transfer.ID); err != nil {
+		t.Fatalf("LinkTransactions() error = %v", err)
+	}
+
+	// Archived transactions stay reachable through their links
+	ws.ArchiveTransactionsBefore(ws.now().Unix() + 1)
+
+	roots := map[string]string{credit.ID: credit.ID, conversion.ID: credit.ID, transfer.ID: transfer.ID}
+	for start, root := range roots {
+		tree, err := ws.GetTransactionTree(start)
+		if err != nil {
+			t.Fatalf("GetTransactionTree(%s) error = %v", start, err)
+		}
+		if tree.RootID != root {
+			t.Errorf("RootID from %s = %s, want %s", start, tree.RootID, root)
+		}
+		if len(tree.Transactions) != 3 || len(tree.Links) != 2 {
+			t.Errorf("tree from %s has %d transactions and %d links, want 3 and 2", start, len(tree.Transactions), len(tree.Links))
+		}
+	}
+
+	tree, _ := ws.GetTransactionTree(credit.ID)
+	want := map[TransactionLink]bool{
+		{From: credit.ID, To: conversion.ID, Kind: LinkParent}: true,
+	}
+	for _, l := range tree.Links {
+		if l.Kind == LinkParent && !want[l] {
+			t.Errorf("unexpected parent link %+v", l)
+		}
+	}
+}
+
+func TestGetTransactionTree_Errors(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 10, "seed")
+	history, _ := ws.GetTransactionHistory("alice")
+
+	if _, err := ws.GetTransactionTree("tx_missing"); err != ErrTransactionNotFound {
+		t.Errorf("GetTransactionTree() error = %v, want %v", err, ErrTransactionNotFound)
+	}
+	if err := ws.LinkTransactions(history[0].ID, "tx_missing"); err != ErrTransactionNotFound {
+		t.Errorf("LinkTransactions() error = %v, want %v", err, ErrTransactionNotFound)
+	}
+
+	tree, err := ws.GetTransactionTree(history[0].ID)
+	if err != nil || len(tree.Transactions) != 1 || len(tree.Links) != 0 {
+		t.Errorf("standalone tree = %+v, %v", tree, err)
+	}
+}
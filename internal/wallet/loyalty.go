@@ -0,0 +1,310 @@
+// internal/wallet/loyalty.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+// ErrInsufficientPoints is returned when a charge asks to redeem more points than the
+// user holds
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+// metaChargeCategory records the spending category of a charge
+const metaChargeCategory = "charge_category"
+
+// loyaltyFunding is the counterparty of redemption credits: the program pays merchants
+// the money value of the points customers burn
+const loyaltyFunding = "loyalty_program"
+
+// EarnRule awards PointsPerUnit points for every whole unit of money spent in Category.
+// A rule with an empty Category applies to categories without a rule of their own.
+type EarnRule struct {
+	Category      string
+	PointsPerUnit decimal.Decimal
+}
+
+// BurnRule sets what points are worth when redeemed against a charge and how many one
+// charge may use. Zero caps leave that limit off.
+type BurnRule struct {
+	PointValue         decimal.Decimal // money per point
+	MaxPointsPerCharge int64
+	MaxPercentOfCharge decimal.Decimal // share of the charge points may pay, in percent
+}
+
+// LoyaltyProgram configures how charges earn and burn points
+type LoyaltyProgram struct {
+	EarnRules []EarnRule
+	Burn      BurnRule
+}
+
+// PointsEntryKind says how an entry changed a points balance
+type PointsEntryKind string
+
+const (
+	PointsEarned PointsEntryKind = "earn"
+	PointsBurned PointsEntryKind = "burn"
+)
+
+// PointsEntry is one movement of a user's points. Points are kept in their own ledger,
+// apart from money; only the value of burned points shows up in the money ledger, as
+// the redemption credit paying the merchant.
+type PointsEntry struct {
+	ID            string
+	UserID        string
+	Kind          PointsEntryKind
+	Points        int64 // positive when earned, negative when burned
+	Category      string
+	TransactionID string // charge the points were earned on or burned against
+	At            int64
+}
+
+// ChargeRequest is a purchase from a merchant, who must be a user of the service.
+// RedeemPoints asks to pay part of it with points, up to the burn caps.
+type ChargeRequest struct {
+	UserID       string
+	MerchantID   string
+	Amount       decimal.Decimal
+	Category     string
+	RedeemPoints int64
+	Description  string
+}
+
+// ChargeReceipt reports how a charge was paid and the points it moved
+type ChargeReceipt struct {
+	CashTransaction       *Transaction // nil when points paid the whole charge
+	RedemptionTransaction *Transaction // nil when no points were burned
+	CashAmount            decimal.Decimal
+	PointsBurned          int64
+	PointsValue           decimal.Decimal
+	PointsEarned          int64
+}
+
+// LoyaltySummary is the program's points position, reported apart from money
+type LoyaltySummary struct {
+	Outstanding   int64 // earned and not yet burned
+	Earned        int64
+	Burned        int64
+	RedeemedValue decimal.Decimal // money paid to merchants for burned points
+}
+
+// loyaltyBook holds the loyalty program and the points ledger
+type loyaltyBook struct {
+	mu       sync.Mutex
+	program  *LoyaltyProgram
+	balances map[string]int64
+	entries  map[string][]PointsEntry // by user, oldest first
+	summary  LoyaltySummary
+}
+
+// WithLoyaltyProgram makes charges earn and burn points under p
+func WithLoyaltyProgram(p LoyaltyProgram) Option {
+	return func(ws *WalletService) {
+		p.EarnRules = slices.Clone(p.EarnRules)
+		ws.loyalty.program = &p
+	}
+}
+
+// Charge pays a merchant for a purchase. Points burned per req.RedeemPoints cover part
+// of the price, paid to the merchant by the program; the payer transfers the rest and
+// earns points on it by category. A charge held for compliance review still burns
+// points but earns none, and is returned with ErrTransferHeld.
+func (ws *WalletService) Charge(req ChargeRequest) (*ChargeReceipt, error) {
+	return ws.ChargeContext(context.Background(), req)
+}
+
+// ChargeContext is Charge on behalf of a caller's ctx
+func (ws *WalletService) ChargeContext(ctx context.Context, req ChargeRequest) (*ChargeReceipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Amount.LessThanOrEqual(decimal.Zero) || req.RedeemPoints < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	ws.mu.RLock()
+	payer, payerExists := ws.wallets[req.UserID]
+	_, merchantExists := ws.wallets[req.MerchantID]
+	ws.mu.RUnlock()
+	if !payerExists || !merchantExists {
+		return nil, ErrUserNotFound
+	}
+	places := ws.currencyPrecision(payer.Currency)
+
+	// Take the points first so concurrent charges cannot burn them twice
+	ws.loyalty.mu.Lock()
+	program := ws.loyalty.program
+	receipt := &ChargeReceipt{PointsValue: decimal.Zero}
+	if req.RedeemPoints > 0 {
+		if program == nil || ws.loyalty.balances[req.UserID] < req.RedeemPoints {
+			ws.loyalty.mu.Unlock()
+			return nil, ErrInsufficientPoints
+		}
+		receipt.PointsBurned = program.Burn.burnable(req.RedeemPoints, req.Amount, places)
+		receipt.PointsValue = program.Burn.PointValue.Mul(decimal.NewFromInt(receipt.PointsBurned))
+		ws.loyalty.balances[req.UserID] -= receipt.PointsBurned
+	}
+	ws.loyalty.mu.Unlock()
+	receipt.CashAmount = req.Amount.Sub(receipt.PointsValue)
+
+	meta := map[string]string{metaChargeCategory: req.Category}
+	var err error
+	if receipt.CashAmount.IsPositive() {
+		receipt.CashTransaction, err = ws.transfer(ctx, req.UserID, req.MerchantID, receipt.CashAmount, req.Description, transferOptions{metadata: meta})
+		if err != nil && !errors.Is(err, ErrTransferHeld) {
+			ws.refundPoints(req.UserID, receipt.PointsBurned)
+			return nil, err
+		}
+	}
+
+	if receipt.PointsBurned > 0 {
+		redemption := &Transaction{
+			FromUserID:  loyaltyFunding,
+			ToUserID:    req.MerchantID,
+			Amount:      receipt.PointsValue,
+			Currency:    payer.Currency,
+			Type:        TransactionLoyaltyRedemption,
+			Description: req.Description,
+			Metadata:    copyMetadata(meta),
+		}
+		if receipt.CashTransaction != nil {
+			redemption.ParentTxID = receipt.CashTransaction.ID
+		}
+		if rerr := ws.postCredit(ctx, redemption); rerr != nil {
+			ws.refundPoints(req.UserID, receipt.PointsBurned)
+			return nil, rerr
+		}
+		receipt.RedemptionTransaction = redemption
+	}
+
+	if err == nil && receipt.CashTransaction != nil && program != nil {
+		receipt.PointsEarned = program.earned(req.Category, receipt.CashAmount)
+	}
+	ws.recordPoints(req, receipt)
+	return receipt, err
+}
+
+// GetPointsBalance returns userID's points
+func (ws *WalletService) GetPointsBalance(userID string) (int64, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return 0, ErrUserNotFound
+	}
+
+	ws.loyalty.mu.Lock()
+	defer ws.loyalty.mu.Unlock()
+	return ws.loyalty.balances[userID], nil
+}
+
+// GetPointsHistory returns userID's points entries, oldest first
+func (ws *WalletService) GetPointsHistory(userID string) ([]PointsEntry, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.loyalty.mu.Lock()
+	defer ws.loyalty.mu.Unlock()
+	return slices.Clone(ws.loyalty.entries[userID]), nil
+}
+
+// GetLoyaltySummary returns the program's points totals
+func (ws *WalletService) GetLoyaltySummary() LoyaltySummary {
+	ws.loyalty.mu.Lock()
+	defer ws.loyalty.mu.Unlock()
+	return ws.loyalty.summary
+}
+
+// recordPoints writes the points a charge burned and earned to the ledger
+func (ws *WalletService) recordPoints(req ChargeRequest, receipt *ChargeReceipt) {
+	txID := ""
+	if receipt.CashTransaction != nil {
+		txID = receipt.CashTransaction.ID
+	} else if receipt.RedemptionTransaction != nil {
+		txID = receipt.RedemptionTransaction.ID
+	}
+	at := ws.now().Unix()
+
+	ws.loyalty.mu.Lock()
+	defer ws.loyalty.mu.Unlock()
+	b := &ws.loyalty
+	if b.entries == nil {
+		b.entries = make(map[string][]PointsEntry)
+	}
+	if receipt.PointsBurned > 0 {
+		b.entries[req.UserID] = append(b.entries[req.UserID], PointsEntry{
+			ID: ws.newID("pts"), UserID: req.UserID, Kind: PointsBurned, Points: -receipt.PointsBurned,
+			Category: req.Category, TransactionID: txID, At: at,
+		})
+		b.summary.Burned += receipt.PointsBurned
+		b.summary.Outstanding -= receipt.PointsBurned
+		b.summary.RedeemedValue = b.summary.RedeemedValue.Add(receipt.PointsValue)
+	}
+	if receipt.PointsEarned > 0 {
+		b.entries[req.UserID] = append(b.entries[req.UserID], PointsEntry{
+			ID: ws.newID("pts"), UserID: req.UserID, Kind: PointsEarned, Points: receipt.PointsEarned,
+			Category: req.Category, TransactionID: txID, At: at,
+		})
+		if b.balances == nil {
+			b.balances = make(map[string]int64)
+		}
+		b.balances[req.UserID] += receipt.PointsEarned
+		b.summary.Earned += receipt.PointsEarned
+		b.summary.Outstanding += receipt.PointsEarned
+	}
+	if receipt.PointsEarned > 0 || receipt.PointsBurned > 0 {
+		ws.metrics.IncCounter("loyalty_charges_total", map[string]string{"category": req.Category})
+	}
+}
+
+// refundPoints returns points taken for a charge that failed
+func (ws *WalletService) refundPoints(userID string, points int64) {
+	if points == 0 {
+		return
+	}
+	ws.loyalty.mu.Lock()
+	ws.loyalty.balances[userID] += points
+	ws.loyalty.mu.Unlock()
+}
+
+// earned returns the points spending amount in category earns, rounded down
+func (p *LoyaltyProgram) earned(category string, amount decimal.Decimal) int64 {
+	rate, found := decimal.Zero, false
+	for _, r := range p.EarnRules {
+		if r.Category == category {
+			rate, found = r.PointsPerUnit, true
+			break
+		}
+		if r.Category == "" && !found {
+			rate = r.PointsPerUnit
+		}
+	}
+	return amount.Mul(rate).Floor().IntPart()
+}
+
+// burnable returns how many of the requested points may be burned against a charge of
+// amount: no more than the caps allow and never worth more than the charge
+func (b BurnRule) burnable(requested int64, amount decimal.Decimal, places int32) int64 {
+	if !b.PointValue.IsPositive() {
+		return 0
+	}
+	limit := amount
+	if b.MaxPercentOfCharge.IsPositive() {
+		limit = moneymath.Percent(amount, b.MaxPercentOfCharge, places, moneymath.Down)
+	}
+	points := min(requested, limit.Div(b.PointValue).Floor().IntPart())
+	if b.MaxPointsPerCharge > 0 {
+		points = min(points, b.MaxPointsPerCharge)
+	}
+	return max(points, 0)
+}
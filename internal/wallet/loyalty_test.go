@@ -0,0 +1,124 @@
+// internal/wallet/loyalty_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// testLoyaltyProgram earns 1 point per unit, 3 on travel, and redeems points at a cent
+// each for up to maxPercent of a charge
+func testLoyaltyProgram(maxPercent int64) LoyaltyProgram {
+	return LoyaltyProgram{
+		EarnRules: []EarnRule{
+			{Category: "", PointsPerUnit: decimal.NewFromInt(1)},
+			{Category: "travel", PointsPerUnit: decimal.NewFromInt(3)},
+		},
+		Burn: BurnRule{
+			PointValue:         decimal.RequireFromString("0.01"),
+			MaxPointsPerCharge: 2000,
+			MaxPercentOfCharge: decimal.NewFromInt(maxPercent),
+		},
+	}
+}
+
+func TestChargeEarnsAndBurnsPoints(t *testing.T) {
+	ws := NewWalletService(WithLoyaltyProgram(testLoyaltyProgram(50)))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("shop", "Shop", "s@example.com")
+	ws.Deposit("alice", 500, "seed")
+
+	tests := []struct {
+		name       string
+		req        ChargeRequest
+		wantErr    error
+		wantCash   string
+		wantBurned int64
+		wantEarned int64
+		wantPoints int64
+	}{
+		{"default rate", ChargeRequest{Amount: decimal.RequireFromString("40.75"), Category: "groceries"}, nil, "40.75", 0, 40, 40},
+		{"category rate", ChargeRequest{Amount: decimal.NewFromInt(100), Category: "travel"}, nil, "100", 0, 300, 340},
+		{"redeem more than held", ChargeRequest{Amount: decimal.NewFromInt(10), RedeemPoints: 341}, ErrInsufficientPoints, "", 0, 0, 340},
+		{"burn capped by percent", ChargeRequest{Amount: decimal.NewFromInt(4), RedeemPoints: 300}, nil, "2", 200, 2, 142},
+		{"burn within caps", ChargeRequest{Amount: decimal.NewFromInt(20), RedeemPoints: 100, Category: "travel"}, nil, "19", 100, 57, 99},
+		{"cash part fails", ChargeRequest{Amount: decimal.NewFromInt(1000), RedeemPoints: 99}, ErrInsufficientBalance, "", 0, 0, 99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.UserID, tt.req.MerchantID = "alice", "shop"
+			receipt, err := ws.Charge(tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("Charge() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if !receipt.CashAmount.Equal(decimal.RequireFromString(tt.wantCash)) || receipt.PointsBurned != tt.wantBurned || receipt.PointsEarned != tt.wantEarned {
+					t.Errorf("receipt = cash %s, burned %d, earned %d; want %s, %d, %d",
+						receipt.CashAmount, receipt.PointsBurned, receipt.PointsEarned, tt.wantCash, tt.wantBurned, tt.wantEarned)
+				}
+			}
+			if points, _ := ws.GetPointsBalance("alice"); points != tt.wantPoints {
+				t.Errorf("points = %d, want %d", points, tt.wantPoints)
+			}
+		})
+	}
+
+	// The merchant gets the full price: cash from alice plus redemptions from the program
+	if balance, _ := ws.GetBalanceDecimal("shop"); !balance.Equal(decimal.RequireFromString("164.75")) {
+		t.Errorf("merchant balance = %s, want 164.75", balance)
+	}
+	if balance, _ := ws.GetBalanceDecimal("alice"); !balance.Equal(decimal.RequireFromString("338.25")) {
+		t.Errorf("payer balance = %s, want 338.25", balance)
+	}
+	summary := ws.GetLoyaltySummary()
+	if summary.Earned != 399 || summary.Burned != 300 || summary.Outstanding != 99 || !summary.RedeemedValue.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("summary = %+v", summary)
+	}
+	history, _ := ws.GetPointsHistory("alice")
+	if len(history) != 6 || history[2].Kind != PointsBurned || history[2].Points != -200 {
+		t.Errorf("history = %+v", history)
+	}
+	for _, userID := range []string{"alice", "shop"} {
+		if m, _ := ws.CheckWalletIntegrity(userID); m != nil {
+			t.Errorf("integrity mismatch for %s = %+v", userID, m)
+		}
+	}
+}
+
+func TestChargePaidWithPointsOnly(t *testing.T) {
+	ws := NewWalletService(WithLoyaltyProgram(testLoyaltyProgram(0)))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("shop", "Shop", "s@example.com")
+	ws.Deposit("alice", 500, "seed")
+	ws.Charge(ChargeRequest{UserID: "alice", MerchantID: "shop", Amount: decimal.NewFromInt(300), Category: "travel"})
+
+	receipt, err := ws.Charge(ChargeRequest{UserID: "alice", MerchantID: "shop", Amount: decimal.RequireFromString("0.50"), RedeemPoints: 900})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	if receipt.CashTransaction != nil || receipt.PointsBurned != 50 || receipt.PointsEarned != 0 {
+		t.Errorf("receipt = %+v, want 50 points burned and no cash", receipt)
+	}
+	if receipt.RedemptionTransaction == nil || receipt.RedemptionTransaction.Type != TransactionLoyaltyRedemption {
+		t.Errorf("redemption = %+v", receipt.RedemptionTransaction)
+	}
+}
+
+func TestChargeWithoutProgram(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("shop", "Shop", "s@example.com")
+	ws.Deposit("alice", 50, "seed")
+
+	receipt, err := ws.Charge(ChargeRequest{UserID: "alice", MerchantID: "shop", Amount: decimal.NewFromInt(20)})
+	if err != nil || receipt.PointsEarned != 0 {
+		t.Errorf("Charge() = %+v, %v; want a plain charge earning nothing", receipt, err)
+	}
+	if _, err := ws.Charge(ChargeRequest{UserID: "alice", MerchantID: "shop", Amount: decimal.NewFromInt(5), RedeemPoints: 1}); err != ErrInsufficientPoints {
+		t.Errorf("Charge(redeem) error = %v, want %v", err, ErrInsufficientPoints)
+	}
+	if _, err := ws.Charge(ChargeRequest{UserID: "alice", MerchantID: "nobody", Amount: decimal.NewFromInt(5)}); err != ErrUserNotFound {
+		t.Errorf("Charge(unknown merchant) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
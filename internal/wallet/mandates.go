@@ -0,0 +1,248 @@
+// internal/wallet/mandates.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for debit mandates
+var (
+	ErrMandateNotFound      = errors.New("mandate not found")
+	ErrMandateInactive      = errors.New("mandate is paused or revoked")
+	ErrMandateLimitExceeded = errors.New("pull exceeds the mandate's remaining allowance for this period")
+	ErrInvalidMandate       = errors.New("invalid mandate")
+)
+
+// MandatePeriod is the window a mandate's allowance resets on
+type MandatePeriod string
+
+const (
+	MandateDaily   MandatePeriod = "daily"
+	MandateWeekly  MandatePeriod = "weekly"
+	MandateMonthly MandatePeriod = "monthly"
+)
+
+// MandateStatus is the lifecycle state of a mandate
+type MandateStatus string
+
+const (
+	MandateActive  MandateStatus = "active"
+	MandatePaused  MandateStatus = "paused"
+	MandateRevoked MandateStatus = "revoked"
+)
+
+// Mandate authorises a merchant to pull up to MaxPerPeriod from a payer each period
+// without per-transaction approval
+type Mandate struct {
+	ID           string
+	PayerID      string
+	MerchantID   string
+	MaxPerPeriod decimal.Decimal
+	Period       MandatePeriod
+	Status       MandateStatus
+	CreatedAt    int64
+
+	// Usage in the current period
+	PeriodStart  int64
+	UsedInPeriod decimal.Decimal
+}
+
+// mandateBook holds all mandates
+type mandateBook struct {
+	mu       sync.Mutex
+	mandates map[string]*Mandate
+}
+
+// CreateMandate lets merchantID pull up to maxPerPeriod from payerID each period
+func (ws *WalletService) CreateMandate(payerID, merchantID string, maxPerPeriod decimal.Decimal, period MandatePeriod) (*Mandate, error) {
+	if maxPerPeriod.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	if payerID == merchantID {
+		return nil, ErrSameUserTransfer
+	}
+	switch period {
+	case MandateDaily, MandateWeekly, MandateMonthly:
+	default:
+		return nil, ErrInvalidMandate
+	}
+
+	ws.mu.RLock()
+	_, payerExists := ws.users[payerID]
+	_, merchantExists := ws.users[merchantID]
+	ws.mu.RUnlock()
+
+	if !payerExists || !merchantExists {
+		return nil, ErrUserNotFound
+	}
+
+	now := ws.now()
+	m := &Mandate{
+		ID:           ws.newID("mandate"),
+		PayerID:      payerID,
+		MerchantID:   merchantID,
+		MaxPerPeriod: maxPerPeriod,
+		Period:       period,
+		Status:       MandateActive,
+		CreatedAt:    now.Unix(),
+		PeriodStart:  periodStart(now, period).Unix(),
+		UsedInPeriod: decimal.Zero,
+	}
+
+	ws.mandates.mu.Lock()
+	defer ws.mandates.mu.Unlock()
+
+	if ws.mandates.mandates == nil {
+		ws.mandates.mandates = make(map[string]*Mandate)
+	}
+	ws.mandates.mandates[m.ID] = m
+
+	copied := *m
+	return &copied, nil
+}
+
+// PullFunds collects amount from the payer of a mandate on behalf of its merchant
+func (ws *WalletService) PullFunds(mandateID, merchantID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	return ws.PullFundsContext(context.Background(), mandateID, merchantID, amount, description)
+}
+
+// PullFundsContext is PullFunds on behalf of a caller's ctx
+func (ws *WalletService) PullFundsContext(ctx context.Context, mandateID, merchantID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	// Reserve the allowance first so concurrent pulls cannot overshoot it together
+	ws.mandates.mu.Lock()
+	m, exists := ws.mandates.mandates[mandateID]
+	if !exists || m.MerchantID != merchantID {
+		ws.mandates.mu.Unlock()
+		return nil, ErrMandateNotFound
+	}
+	if m.Status != MandateActive {
+		ws.mandates.mu.Unlock()
+		return nil, ErrMandateInactive
+	}
+	ws.rollMandatePeriod(m)
+	if m.UsedInPeriod.Add(amount).GreaterThan(m.MaxPerPeriod) {
+		ws.mandates.mu.Unlock()
+		return nil, ErrMandateLimitExceeded
+	}
+	m.UsedInPeriod = m.UsedInPeriod.Add(amount)
+	reservedIn := m.PeriodStart
+	payerID := m.PayerID
+	ws.mandates.mu.Unlock()
+
+	tx, err := ws.transfer(ctx, payerID, merchantID, amount, description, transferOptions{
+		metadata: map[string]string{"mandate_id": mandateID},
+		priority: PriorityBatch,
+	})
+	if err != nil && !errors.Is(err, ErrTransferHeld) {
+		ws.mandates.mu.Lock()
+		if m.PeriodStart == reservedIn {
+			m.UsedInPeriod = m.UsedInPeriod.Sub(amount)
+		}
+		ws.mandates.mu.Unlock()
+		return nil, err
+	}
+
+	return tx, err
+}
+
+// PauseMandate stops pulls until the payer resumes the mandate
+func (ws *WalletService) PauseMandate(mandateID, payerID string) error {
+	return ws.setMandateStatus(mandateID, payerID, MandatePaused)
+}
+
+// ResumeMandate re-enables a paused mandate
+func (ws *WalletService) ResumeMandate(mandateID, payerID string) error {
+	return ws.setMandateStatus(mandateID, payerID, MandateActive)
+}
+
+// RevokeMandate permanently cancels a mandate
+func (ws *WalletService) RevokeMandate(mandateID, payerID string) error {
+	return ws.setMandateStatus(mandateID, payerID, MandateRevoked)
+}
+
+// GetMandate returns a mandate by ID
+func (ws *WalletService) GetMandate(mandateID string) (*Mandate, error) {
+	ws.mandates.mu.Lock()
+	defer ws.mandates.mu.Unlock()
+
+	m, exists := ws.mandates.mandates[mandateID]
+	if !exists {
+		return nil, ErrMandateNotFound
+	}
+	ws.rollMandatePeriod(m)
+	copied := *m
+	return &copied, nil
+}
+
+// ListMandates returns the mandates where userID is payer or merchant, oldest first
+func (ws *WalletService) ListMandates(userID string) []Mandate {
+	ws.mandates.mu.Lock()
+	defer ws.mandates.mu.Unlock()
+
+	var list []Mandate
+	for _, m := range ws.mandates.mandates {
+		if m.PayerID == userID || m.MerchantID == userID {
+			ws.rollMandatePeriod(m)
+			list = append(list, *m)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt != list[j].CreatedAt {
+			return list[i].CreatedAt < list[j].CreatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// setMandateStatus changes a mandate's status on behalf of its payer
+func (ws *WalletService) setMandateStatus(mandateID, payerID string, status MandateStatus) error {
+	ws.mandates.mu.Lock()
+	defer ws.mandates.mu.Unlock()
+
+	m, exists := ws.mandates.mandates[mandateID]
+	if !exists || m.PayerID != payerID {
+		return ErrMandateNotFound
+	}
+	if m.Status == MandateRevoked {
+		return ErrMandateInactive
+	}
+	m.Status = status
+	return nil
+}
+
+// rollMandatePeriod resets usage when the service clock has entered a new period.
+// Caller must hold ws.mandates.mu.
+func (ws *WalletService) rollMandatePeriod(m *Mandate) {
+	start := periodStart(ws.now(), m.Period).Unix()
+	if start != m.PeriodStart {
+		m.PeriodStart = start
+		m.UsedInPeriod = decimal.Zero
+	}
+}
+
+// periodStart returns the start of the day, ISO week or month containing t
+func periodStart(t time.Time, period MandatePeriod) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case MandateWeekly:
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		return day.AddDate(0, 0, -offset)
+	case MandateMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
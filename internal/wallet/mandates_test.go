@@ -0,0 +1,96 @@
+// internal/wallet/mandates_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPullFunds_WithinMandate(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("gym", "Gym", "billing@gym.example.com")
+	ws.CreateUser("other", "Other", "other@example.com")
+	ws.Deposit("alice", 500, "seed")
+
+	m, err := ws.CreateMandate("alice", "gym", decimal.NewFromInt(50), MandateMonthly)
+	if err != nil {
+		t.Fatalf("CreateMandate() error = %v", err)
+	}
+
+	steps := []struct {
+		name     string
+		merchant string
+		amount   int64
+		before   func()
+		wantErr  error
+	}{
+		{"first pull", "gym", 30, nil, nil},
+		{"wrong merchant", "other", 10, nil, ErrMandateNotFound},
+		{"over allowance", "gym", 25, nil, ErrMandateLimitExceeded},
+		{"rest of allowance", "gym", 20, nil, nil},
+		{"paused", "gym", 10, func() { clock.Advance(31 * 24 * time.Hour); ws.PauseMandate(m.ID, "alice") }, ErrMandateInactive},
+		{"new month after resume", "gym", 50, func() { ws.ResumeMandate(m.ID, "alice") }, nil},
+		{"revoked", "gym", 1, func() { ws.RevokeMandate(m.ID, "alice") }, ErrMandateInactive},
+	}
+
+	for _, step := range steps {
+		if step.before != nil {
+			step.before()
+		}
+		tx, err := ws.PullFunds(m.ID, step.merchant, decimal.NewFromInt(step.amount), step.name)
+		if err != step.wantErr {
+			t.Fatalf("%s: PullFunds() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err == nil && tx.Metadata["mandate_id"] != m.ID {
+			t.Errorf("%s: metadata = %v", step.name, tx.Metadata)
+		}
+	}
+
+	if b, _ := ws.GetBalanceDecimal("gym"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("gym balance = %s, want 100", b)
+	}
+	if err := ws.ResumeMandate(m.ID, "alice"); err != ErrMandateInactive {
+		t.Errorf("ResumeMandate() after revoke error = %v, want %v", err, ErrMandateInactive)
+	}
+	if list := ws.ListMandates("alice"); len(list) != 1 || list[0].Status != MandateRevoked {
+		t.Errorf("ListMandates() = %+v", list)
+	}
+}
+
+func TestPullFunds_FailedTransferReleasesAllowance(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("gym", "Gym", "billing@gym.example.com")
+	ws.Deposit("alice", 10, "seed")
+
+	m, _ := ws.CreateMandate("alice", "gym", decimal.NewFromInt(50), MandateWeekly)
+	if _, err := ws.PullFunds(m.ID, "gym", decimal.NewFromInt(40), ""); err != ErrInsufficientBalance {
+		t.Fatalf("PullFunds() error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	got, _ := ws.GetMandate(m.ID)
+	if !got.UsedInPeriod.IsZero() {
+		t.Errorf("UsedInPeriod = %s, want 0", got.UsedInPeriod)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	// Wednesday 2024-01-17 15:30
+	at := time.Date(2024, time.January, 17, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period MandatePeriod
+		want   time.Time
+	}{
+		{MandateDaily, time.Date(2024, time.January, 17, 0, 0, 0, 0, time.UTC)},
+		{MandateWeekly, time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{MandateMonthly, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := periodStart(at, tt.period); !got.Equal(tt.want) {
+			t.Errorf("periodStart(%s) = %s, want %s", tt.period, got, tt.want)
+		}
+	}
+}
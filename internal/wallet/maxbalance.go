@@ -0,0 +1,139 @@
+// internal/wallet/maxbalance.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for maximum balance caps
+var (
+	ErrAboveMaximumBalance   = errors.New("credit would take the balance above its maximum")
+	ErrInvalidMaximumBalance = errors.New("maximum balance must be positive")
+)
+
+// maximumExemptTypes are credits a maximum balance lets through: those returning the
+// wallet's own money, such as refunds, reversals and hold releases, and the system's
+// own postings. Every other credit or transfer in, built-in or custom, is money the
+// holder or a counterparty chooses to send in and is capped.
+var maximumExemptTypes = map[TransactionType]bool{
+	TransactionFederationRefund: true,
+	TransactionGiftRefund:       true,
+	TransactionHoldRelease:      true,
+	TransactionHoldReversal:     true,
+	TransactionAdjustmentCredit: true,
+	TransactionRailReversal:     true,
+	TransactionCardRelease:      true,
+	TransactionReserveRelease:   true,
+	TransactionRefund:           true,
+	TransactionCardRefund:       true,
+	TransactionInterest:         true,
+}
+
+// MaximumBalanceError is returned for a credit a maximum balance cap refuses. It
+// matches ErrAboveMaximumBalance with errors.Is.
+type MaximumBalanceError struct {
+	UserID   string
+	Currency string
+	Maximum  decimal.Decimal
+	Excess   decimal.Decimal // how far above Maximum the credit would take the balance
+}
+
+func (e *MaximumBalanceError) Error() string {
+	return fmt.Sprintf("credit would take %s %s over its %s maximum", e.Excess, e.Currency, e.Maximum)
+}
+
+func (e *MaximumBalanceError) Unwrap() error {
+	return ErrAboveMaximumBalance
+}
+
+// maxBalanceBook holds per-wallet balance caps
+type maxBalanceBook struct {
+	mu      sync.Mutex
+	wallets map[string]map[string]decimal.Decimal // userID -> currency -> maximum
+}
+
+// SetMaximumBalance caps userID's holding in currency. Deposits, incoming transfers,
+// conversions and other credits that would take the balance above it are refused; a
+// balance already above the cap stays, but cannot grow.
+func (ws *WalletService) SetMaximumBalance(userID, currency string, maximum decimal.Decimal) error {
+	code := normalizeCurrency(currency)
+	if code == "" {
+		return ErrInvalidCurrency
+	}
+	if !maximum.IsPositive() {
+		return ErrInvalidMaximumBalance
+	}
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	ws.maxBalances.mu.Lock()
+	defer ws.maxBalances.mu.Unlock()
+	if ws.maxBalances.wallets == nil {
+		ws.maxBalances.wallets = make(map[string]map[string]decimal.Decimal)
+	}
+	if ws.maxBalances.wallets[userID] == nil {
+		ws.maxBalances.wallets[userID] = make(map[string]decimal.Decimal)
+	}
+	ws.maxBalances.wallets[userID][code] = maximum
+	return nil
+}
+
+// ClearMaximumBalance removes userID's cap in currency
+func (ws *WalletService) ClearMaximumBalance(userID, currency string) {
+	ws.maxBalances.mu.Lock()
+	defer ws.maxBalances.mu.Unlock()
+	delete(ws.maxBalances.wallets[userID], normalizeCurrency(currency))
+}
+
+// GetMaximumBalance returns userID's cap in currency and whether it has one
+func (ws *WalletService) GetMaximumBalance(userID, currency string) (decimal.Decimal, bool, error) {
+	if !ws.walletExists(userID) {
+		return decimal.Zero, false, ErrUserNotFound
+	}
+	ws.maxBalances.mu.Lock()
+	defer ws.maxBalances.mu.Unlock()
+	maximum, capped := ws.maxBalances.wallets[userID][normalizeCurrency(currency)]
+	return maximum, capped, nil
+}
+
+// maximumFor returns the cap a credit of txType must respect in userID's holding in
+// currency; zero when there is none. Call it before taking the wallet's mu.
+func (ws *WalletService) maximumFor(userID, currency string, txType TransactionType) decimal.Decimal {
+	if !capsCredit(txType) {
+		return decimal.Zero
+	}
+	ws.maxBalances.mu.Lock()
+	defer ws.maxBalances.mu.Unlock()
+	return ws.maxBalances.wallets[userID][currency]
+}
+
+// checkMaximum fails with a *MaximumBalanceError when crediting amount to the wallet's
+// holding in currency would take it above maximum. Caller must hold w.mu.
+func (w *Wallet) checkMaximum(currency string, amount, maximum decimal.Decimal) error {
+	if !maximum.IsPositive() {
+		return nil
+	}
+	after := w.balanceIn(currency).Add(amount)
+	if after.LessThanOrEqual(maximum) {
+		return nil
+	}
+	return &MaximumBalanceError{UserID: w.UserID, Currency: currency, Maximum: maximum, Excess: after.Sub(maximum)}
+}
+
+// capsCredit reports whether a maximum balance refuses credits of type t: credits and
+// transfers in that are not exempt, and the bought leg of a conversion
+func capsCredit(t TransactionType) bool {
+	if t == TransactionConversion {
+		return true
+	}
+	if maximumExemptTypes[t] {
+		return false
+	}
+	kind := transactionKind(t)
+	return kind == KindCredit || kind == KindTransfer
+}
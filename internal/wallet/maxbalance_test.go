@@ -0,0 +1,151 @@
+// internal/wallet/maxbalance_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMaximumBalance_Credits(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("capped", "Capped", "c@example.com")
+	ws.CreateUser("payer", "Payer", "p@example.com")
+	ws.Deposit("payer", 1000, "seed")
+	ws.Deposit("capped", 50, "seed")
+	if err := ws.SetMaximumBalance("capped", "usd", decimal.NewFromInt(100)); err != nil {
+		t.Fatalf("SetMaximumBalance() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		op          func() error
+		wantExcess  string // "" for success
+		wantBalance int64
+	}{
+		{"deposit up to cap", func() error { return ws.Deposit("capped", 30, "top up") }, "", 80},
+		{"deposit over cap", func() error { return ws.Deposit("capped", 25, "top up") }, "5", 80},
+		{"payout legs together over cap", func() error {
+			_, err := ws.BatchPayout("payer", []Payout{{UserID: "capped", Amount: decimal.NewFromInt(15)}, {UserID: "capped", Amount: decimal.NewFromInt(10)}}, "prize")
+			return err
+		}, "5", 80},
+		{"transfer in over cap", func() error { return ws.Transfer("payer", "capped", 21, "gift") }, "1", 80},
+		{"transfer in up to cap", func() error { return ws.Transfer("payer", "capped", 20, "gift") }, "", 100},
+		{"custom credit over cap", func() error {
+			_, err := ws.PostCustomTransaction(CustomTransaction{Type: testSalary.Type, ToUserID: "capped", Amount: decimal.NewFromInt(1), Description: "May"})
+			return err
+		}, "1", 100},
+		{"custom transfer in over cap", func() error {
+			_, err := ws.PostCustomTransaction(CustomTransaction{Type: testAllowance.Type, FromUserID: "payer", ToUserID: "capped", Amount: decimal.NewFromInt(2)})
+			return err
+		}, "2", 100},
+		{"other currency uncapped", func() error { return ws.DepositCurrency("capped", "EUR", decimal.NewFromInt(500), "travel") }, "", 100},
+		{"withdraw then deposit", func() error {
+			ws.Withdraw("capped", 10, "cash")
+			return ws.Deposit("capped", 10, "top up")
+		}, "", 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+			var maxErr *MaximumBalanceError
+			switch {
+			case tt.wantExcess == "" && err != nil:
+				t.Fatalf("error = %v, want nil", err)
+			case tt.wantExcess != "" && (!errors.As(err, &maxErr) || !errors.Is(err, ErrAboveMaximumBalance)):
+				t.Fatalf("error = %v, want a MaximumBalanceError", err)
+			case tt.wantExcess != "" && !maxErr.Excess.Equal(decimal.RequireFromString(tt.wantExcess)):
+				t.Errorf("excess = %s, want %s", maxErr.Excess, tt.wantExcess)
+			}
+			if got, _ := ws.GetBalanceDecimal("capped"); !got.Equal(decimal.NewFromInt(tt.wantBalance)) {
+				t.Errorf("balance = %s, want %d", got, tt.wantBalance)
+			}
+		})
+	}
+
+	// A refused transfer leaves the payer untouched
+	if got, _ := ws.GetBalanceDecimal("payer"); !got.Equal(decimal.NewFromInt(980)) {
+		t.Errorf("payer balance = %s, want 980", got)
+	}
+}
+
+func TestMaximumBalance_Conversion(t *testing.T) {
+	ws := NewWalletService(WithRateProvider(StaticRateProvider{"USD/EUR": decimal.RequireFromString("0.9")}))
+	ws.CreateUser("capped", "Capped", "c@example.com")
+	ws.Deposit("capped", 500, "seed")
+	if err := ws.SetMaximumBalance("capped", "EUR", decimal.NewFromInt(100)); err != nil {
+		t.Fatalf("SetMaximumBalance() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		amount     int64
+		wantExcess string // "" for success
+		wantUSD    int64
+		wantEUR    int64
+	}{
+		{"bought leg over cap", 200, "80", 500, 0},
+		{"bought leg up to cap", 100, "", 400, 90},
+		{"capped holding cannot grow", 20, "8", 400, 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := ws.QuoteConversion("capped", "USD", "EUR", decimal.NewFromInt(tt.amount))
+			if err != nil {
+				t.Fatalf("QuoteConversion() error = %v", err)
+			}
+			_, err = ws.ConvertWithQuote(quote.ID)
+			var maxErr *MaximumBalanceError
+			switch {
+			case tt.wantExcess == "" && err != nil:
+				t.Fatalf("ConvertWithQuote() error = %v, want nil", err)
+			case tt.wantExcess != "" && !errors.As(err, &maxErr):
+				t.Fatalf("ConvertWithQuote() error = %v, want a MaximumBalanceError", err)
+			case tt.wantExcess != "" && !maxErr.Excess.Equal(decimal.RequireFromString(tt.wantExcess)):
+				t.Errorf("excess = %s, want %s", maxErr.Excess, tt.wantExcess)
+			}
+			usd, _ := ws.GetCurrencyBalance("capped", "USD")
+			eur, _ := ws.GetCurrencyBalance("capped", "EUR")
+			if !usd.Equal(decimal.NewFromInt(tt.wantUSD)) || !eur.Equal(decimal.NewFromInt(tt.wantEUR)) {
+				t.Errorf("balances = %s USD, %s EUR; want %d, %d", usd, eur, tt.wantUSD, tt.wantEUR)
+			}
+		})
+	}
+}
+
+func TestMaximumBalance_Settings(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("capped", "Capped", "c@example.com")
+
+	tests := []struct {
+		name     string
+		userID   string
+		currency string
+		maximum  int64
+		wantErr  error
+	}{
+		{"zero", "capped", "USD", 0, ErrInvalidMaximumBalance},
+		{"no currency", "capped", " ", 10, ErrInvalidCurrency},
+		{"unknown user", "ghost", "USD", 10, ErrUserNotFound},
+		{"set", "capped", "usd", 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.SetMaximumBalance(tt.userID, tt.currency, decimal.NewFromInt(tt.maximum)); err != tt.wantErr {
+				t.Errorf("SetMaximumBalance() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if max, capped, _ := ws.GetMaximumBalance("capped", "USD"); !capped || !max.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("GetMaximumBalance() = %s, %v; want 10, true", max, capped)
+	}
+	ws.ClearMaximumBalance("capped", "usd")
+	if _, capped, _ := ws.GetMaximumBalance("capped", "USD"); capped {
+		t.Error("cap still set after ClearMaximumBalance")
+	}
+	if err := ws.Deposit("capped", 50, "top up"); err != nil {
+		t.Errorf("Deposit() after clearing cap error = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// internal/wallet/metrics.go
+package wallet
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetricsRecorder receives operational metrics emitted by the wallet service
+type MetricsRecorder interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveValue(name string, value float64, labels map[string]string)
+}
+
+// noopMetrics discards every metric
+type noopMetrics struct{}
+
+func (noopMetrics) IncCounter(string, map[string]string)            {}
+func (noopMetrics) ObserveValue(string, float64, map[string]string) {}
+
+// InMemoryMetrics is a MetricsRecorder that keeps counters and last observed values in memory
+type InMemoryMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	values   map[string]float64
+}
+
+// NewInMemoryMetrics creates an empty in-memory metrics recorder
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{
+		counters: make(map[string]int64),
+		values:   make(map[string]float64),
+	}
+}
+
+// IncCounter increments the counter identified by name and labels
+func (m *InMemoryMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[metricKey(name, labels)]++
+}
+
+// ObserveValue records the latest value for the gauge identified by name and labels
+func (m *InMemoryMetrics) ObserveValue(name string, value float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[metricKey(name, labels)] = value
+}
+
+// Counter returns the current value of a counter
+func (m *InMemoryMetrics) Counter(name string, labels map[string]string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[metricKey(name, labels)]
+}
+
+// Value returns the latest observed value of a gauge
+func (m *InMemoryMetrics) Value(name string, labels map[string]string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[metricKey(name, labels)]
+}
+
+// metricKey builds a stable key from a metric name and its labels
+func metricKey(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
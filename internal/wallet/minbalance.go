@@ -0,0 +1,260 @@
+// internal/wallet/minbalance.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for minimum balance requirements
+var (
+	ErrBelowMinimumBalance    = errors.New("debit would take the balance below its minimum")
+	ErrInvalidMinimumBalance  = errors.New("minimum balance must not be negative")
+	ErrMinimumTierNotFound    = errors.New("minimum balance tier not found")
+	ErrMinimumWaiverNotFound  = errors.New("minimum balance is not waived")
+	ErrMinimumWaiverRationale = errors.New("waiving a minimum balance needs an actor and a reason")
+)
+
+// minimumExemptTypes are debits the system posts on its own account, which a minimum
+// balance must not block: admin corrections, treasury burns and rolling reserve
+// withholding
+var minimumExemptTypes = map[TransactionType]bool{
+	TransactionAdjustmentDebit: true,
+	TransactionBurn:            true,
+	TransactionReserveHold:     true,
+}
+
+// MinimumBalanceError is returned for a debit a minimum balance requirement refuses.
+// It matches ErrBelowMinimumBalance with errors.Is.
+type MinimumBalanceError struct {
+	UserID    string
+	Currency  string
+	Minimum   decimal.Decimal
+	Shortfall decimal.Decimal // how far below Minimum the debit would leave the balance
+}
+
+func (e *MinimumBalanceError) Error() string {
+	return fmt.Sprintf("debit would leave %s %s short of its %s minimum", e.Shortfall, e.Currency, e.Minimum)
+}
+
+func (e *MinimumBalanceError) Unwrap() error {
+	return ErrBelowMinimumBalance
+}
+
+// MinimumSource says which rule set a wallet's minimum balance
+type MinimumSource string
+
+const (
+	MinimumFromWallet MinimumSource = "wallet"
+	MinimumFromTier   MinimumSource = "tier"
+)
+
+// MinimumBalance is the requirement in force for one wallet and currency
+type MinimumBalance struct {
+	Currency string
+	Minimum  decimal.Decimal // zero when there is none or it is waived
+	Source   MinimumSource   // "" when there is none
+	Tier     string
+	Waiver   *MinimumWaiver
+}
+
+// MinimumWaiver records an admin lifting a wallet's minimum balance requirements
+type MinimumWaiver struct {
+	UserID   string
+	Actor    string
+	Reason   string
+	WaivedAt int64
+}
+
+// minBalanceBook holds tiers, per-wallet minimums and waivers
+type minBalanceBook struct {
+	mu      sync.Mutex
+	tiers   map[string]map[string]decimal.Decimal // tier -> currency -> minimum
+	tierOf  map[string]string                     // userID -> tier
+	wallets map[string]map[string]decimal.Decimal // userID -> currency -> minimum
+	waivers map[string]*MinimumWaiver
+}
+
+// SetMinimumBalanceTier defines or replaces a tier: the minimum balance, per currency,
+// of every wallet assigned to it
+func (ws *WalletService) SetMinimumBalanceTier(tier string, minimums map[string]decimal.Decimal) error {
+	normalized, err := normalizeMinimums(minimums)
+	if err != nil {
+		return err
+	}
+
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+	if ws.minBalances.tiers == nil {
+		ws.minBalances.tiers = make(map[string]map[string]decimal.Decimal)
+	}
+	ws.minBalances.tiers[tier] = normalized
+	return nil
+}
+
+// AssignMinimumBalanceTier puts userID's wallet in tier; an empty tier removes it from
+// its tier
+func (ws *WalletService) AssignMinimumBalanceTier(userID, tier string) error {
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+	if tier == "" {
+		delete(ws.minBalances.tierOf, userID)
+		return nil
+	}
+	if _, exists := ws.minBalances.tiers[tier]; !exists {
+		return ErrMinimumTierNotFound
+	}
+	if ws.minBalances.tierOf == nil {
+		ws.minBalances.tierOf = make(map[string]string)
+	}
+	ws.minBalances.tierOf[userID] = tier
+	return nil
+}
+
+// SetMinimumBalance sets userID's minimum in currency, taking precedence over its tier.
+// A zero minimum lets the wallet go to zero whatever its tier says.
+func (ws *WalletService) SetMinimumBalance(userID, currency string, minimum decimal.Decimal) error {
+	normalized, err := normalizeMinimums(map[string]decimal.Decimal{currency: minimum})
+	if err != nil {
+		return err
+	}
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+	if ws.minBalances.wallets == nil {
+		ws.minBalances.wallets = make(map[string]map[string]decimal.Decimal)
+	}
+	if ws.minBalances.wallets[userID] == nil {
+		ws.minBalances.wallets[userID] = make(map[string]decimal.Decimal)
+	}
+	for c, m := range normalized {
+		ws.minBalances.wallets[userID][c] = m
+	}
+	return nil
+}
+
+// ClearMinimumBalance removes userID's own minimum in currency, so its tier applies again
+func (ws *WalletService) ClearMinimumBalance(userID, currency string) {
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+	delete(ws.minBalances.wallets[userID], normalizeCurrency(currency))
+}
+
+// WaiveMinimumBalance lifts every minimum balance requirement on userID's wallet until
+// the waiver is revoked
+func (ws *WalletService) WaiveMinimumBalance(userID, actor, reason string) error {
+	if strings.TrimSpace(actor) == "" || strings.TrimSpace(reason) == "" {
+		return ErrMinimumWaiverRationale
+	}
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+	if ws.minBalances.waivers == nil {
+		ws.minBalances.waivers = make(map[string]*MinimumWaiver)
+	}
+	ws.minBalances.waivers[userID] = &MinimumWaiver{UserID: userID, Actor: actor, Reason: reason, WaivedAt: ws.now().Unix()}
+	ws.metrics.IncCounter("minimum_balance_waivers_total", nil)
+	return nil
+}
+
+// RevokeMinimumBalanceWaiver restores the minimum balance requirements on userID's wallet
+func (ws *WalletService) RevokeMinimumBalanceWaiver(userID string) error {
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+
+	if _, exists := ws.minBalances.waivers[userID]; !exists {
+		return ErrMinimumWaiverNotFound
+	}
+	delete(ws.minBalances.waivers, userID)
+	return nil
+}
+
+// GetMinimumBalance returns the requirement in force for userID's holding in currency
+func (ws *WalletService) GetMinimumBalance(userID, currency string) (MinimumBalance, error) {
+	if !ws.walletExists(userID) {
+		return MinimumBalance{}, ErrUserNotFound
+	}
+	currency = normalizeCurrency(currency)
+
+	ws.minBalances.mu.Lock()
+	defer ws.minBalances.mu.Unlock()
+
+	m := MinimumBalance{Currency: currency, Minimum: decimal.Zero, Tier: ws.minBalances.tierOf[userID]}
+	if minimum, exists := ws.minBalances.wallets[userID][currency]; exists {
+		m.Minimum, m.Source = minimum, MinimumFromWallet
+	} else if minimum, exists := ws.minBalances.tiers[m.Tier][currency]; exists {
+		m.Minimum, m.Source = minimum, MinimumFromTier
+	}
+	if w, waived := ws.minBalances.waivers[userID]; waived {
+		copied := *w
+		m.Waiver = &copied
+		m.Minimum = decimal.Zero
+	}
+	return m, nil
+}
+
+// minimumFor returns the minimum a debit of txType must leave in userID's holding in
+// currency. Call it before taking the wallet's mu.
+func (ws *WalletService) minimumFor(userID, currency string, txType TransactionType) decimal.Decimal {
+	if minimumExemptTypes[txType] {
+		return decimal.Zero
+	}
+	m, err := ws.GetMinimumBalance(userID, currency)
+	if err != nil {
+		return decimal.Zero
+	}
+	return m.Minimum
+}
+
+// checkMinimum fails with a *MinimumBalanceError when debiting amount from the wallet's
+// available holding in currency would leave less than minimum. Funds released by the
+// same step, such as the hold a capture settles, are passed as unhold: a debit covered
+// by them is not checked. Caller must hold w.mu.
+func (w *Wallet) checkMinimum(currency string, amount, unhold, minimum decimal.Decimal) error {
+	if !minimum.IsPositive() || !amount.GreaterThan(unhold) {
+		return nil
+	}
+	left := w.available(currency).Add(unhold).Sub(amount)
+	if left.GreaterThanOrEqual(minimum) {
+		return nil
+	}
+	return &MinimumBalanceError{UserID: w.UserID, Currency: currency, Minimum: minimum, Shortfall: minimum.Sub(left)}
+}
+
+// walletExists reports whether userID has a wallet
+func (ws *WalletService) walletExists(userID string) bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	_, exists := ws.wallets[userID]
+	return exists
+}
+
+// normalizeMinimums validates minimums and canonicalizes their currency codes
+func normalizeMinimums(minimums map[string]decimal.Decimal) (map[string]decimal.Decimal, error) {
+	normalized := make(map[string]decimal.Decimal, len(minimums))
+	for currency, minimum := range minimums {
+		code := normalizeCurrency(currency)
+		if code == "" {
+			return nil, ErrInvalidCurrency
+		}
+		if minimum.IsNegative() {
+			return nil, ErrInvalidMinimumBalance
+		}
+		normalized[code] = minimum
+	}
+	return normalized, nil
+}
@@ -0,0 +1,139 @@
+// internal/wallet/minbalance_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMinimumBalance_Debits(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("saver", "Saver", "s@example.com")
+	ws.CreateUser("other", "Other", "o@example.com")
+	ws.Deposit("saver", 100, "seed")
+	if err := ws.SetMinimumBalanceTier("premium", map[string]decimal.Decimal{"usd": decimal.NewFromInt(25)}); err != nil {
+		t.Fatalf("SetMinimumBalanceTier() error = %v", err)
+	}
+	if err := ws.AssignMinimumBalanceTier("saver", "premium"); err != nil {
+		t.Fatalf("AssignMinimumBalanceTier() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		op            func() error
+		wantShortfall string // "" for success
+		wantBalance   int64
+	}{
+		{"withdraw down to minimum", func() error { return ws.Withdraw("saver", 60, "rent") }, "", 40},
+		{"withdraw below minimum", func() error { return ws.Withdraw("saver", 20, "cash") }, "5", 40},
+		{"transfer below minimum", func() error { return ws.Transfer("saver", "other", 30, "gift") }, "15", 40},
+		{"hold below minimum", func() error { _, err := ws.Hold("saver", decimal.NewFromInt(16)); return err }, "1", 40},
+		{"more than the balance", func() error { return ws.Withdraw("saver", 50, "cash") }, "insufficient", 40},
+		{"admin correction exempt", func() error {
+			_, err := ws.SetBalance("saver", decimal.NewFromInt(20), ReasonErrorCorrection)
+			return err
+		}, "", 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+			var minErr *MinimumBalanceError
+			switch tt.wantShortfall {
+			case "":
+				if err != nil {
+					t.Fatalf("error = %v, want nil", err)
+				}
+			case "insufficient":
+				if err != ErrInsufficientBalance {
+					t.Fatalf("error = %v, want %v", err, ErrInsufficientBalance)
+				}
+			default:
+				if !errors.As(err, &minErr) || !errors.Is(err, ErrBelowMinimumBalance) {
+					t.Fatalf("error = %v, want a MinimumBalanceError", err)
+				}
+				if !minErr.Shortfall.Equal(decimal.RequireFromString(tt.wantShortfall)) || !minErr.Minimum.Equal(decimal.NewFromInt(25)) {
+					t.Errorf("error = shortfall %s of minimum %s, want %s of 25", minErr.Shortfall, minErr.Minimum, tt.wantShortfall)
+				}
+			}
+			if got, _ := ws.GetBalanceDecimal("saver"); !got.Equal(decimal.NewFromInt(tt.wantBalance)) {
+				t.Errorf("balance = %s, want %d", got, tt.wantBalance)
+			}
+		})
+	}
+}
+
+func TestMinimumBalance_Resolution(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("saver", "Saver", "s@example.com")
+	ws.Deposit("saver", 100, "seed")
+	ws.SetMinimumBalanceTier("premium", map[string]decimal.Decimal{"USD": decimal.NewFromInt(50)})
+
+	if err := ws.AssignMinimumBalanceTier("saver", "gold"); err != ErrMinimumTierNotFound {
+		t.Errorf("AssignMinimumBalanceTier(gold) error = %v, want %v", err, ErrMinimumTierNotFound)
+	}
+	if err := ws.SetMinimumBalance("saver", "USD", decimal.NewFromInt(-1)); err != ErrInvalidMinimumBalance {
+		t.Errorf("SetMinimumBalance(-1) error = %v, want %v", err, ErrInvalidMinimumBalance)
+	}
+	if err := ws.WaiveMinimumBalance("saver", "ops", ""); err != ErrMinimumWaiverRationale {
+		t.Errorf("WaiveMinimumBalance() without reason error = %v, want %v", err, ErrMinimumWaiverRationale)
+	}
+
+	steps := []struct {
+		name         string
+		apply        func()
+		wantMinimum  int64
+		wantSource   MinimumSource
+		wantWithdraw error
+	}{
+		{"no requirement", func() {}, 0, "", nil},
+		{"tier", func() { ws.AssignMinimumBalanceTier("saver", "premium") }, 50, MinimumFromTier, ErrBelowMinimumBalance},
+		{"wallet overrides tier", func() { ws.SetMinimumBalance("saver", "usd", decimal.NewFromInt(10)) }, 10, MinimumFromWallet, nil},
+		{"cleared back to tier", func() { ws.ClearMinimumBalance("saver", "USD") }, 50, MinimumFromTier, ErrBelowMinimumBalance},
+		{"waived", func() { ws.WaiveMinimumBalance("saver", "ops", "hardship") }, 0, MinimumFromTier, nil},
+		{"waiver revoked", func() { ws.RevokeMinimumBalanceWaiver("saver") }, 50, MinimumFromTier, ErrBelowMinimumBalance},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.apply()
+			m, err := ws.GetMinimumBalance("saver", "usd")
+			if err != nil {
+				t.Fatalf("GetMinimumBalance() error = %v", err)
+			}
+			if !m.Minimum.Equal(decimal.NewFromInt(tt.wantMinimum)) || m.Source != tt.wantSource {
+				t.Errorf("GetMinimumBalance() = %s from %q, want %d from %q", m.Minimum, m.Source, tt.wantMinimum, tt.wantSource)
+			}
+			if (m.Waiver != nil) != (tt.name == "waived") {
+				t.Errorf("waiver = %+v in step %s", m.Waiver, tt.name)
+			}
+			// A withdrawal of 60 would leave 40 of 100; it is undone so steps are independent
+			err = ws.Withdraw("saver", 60, "probe")
+			if !errors.Is(err, tt.wantWithdraw) {
+				t.Errorf("Withdraw() error = %v, want %v", err, tt.wantWithdraw)
+			}
+			if err == nil {
+				ws.Deposit("saver", 60, "undo probe")
+			}
+		})
+	}
+	if err := ws.RevokeMinimumBalanceWaiver("saver"); err != ErrMinimumWaiverNotFound {
+		t.Errorf("second RevokeMinimumBalanceWaiver() error = %v, want %v", err, ErrMinimumWaiverNotFound)
+	}
+}
+
+func TestMinimumBalance_CaptureNotBlocked(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("saver", "Saver", "s@example.com")
+	ws.Deposit("saver", 100, "seed")
+	h, err := ws.Hold("saver", decimal.NewFromInt(70))
+	if err != nil {
+		t.Fatalf("Hold() error = %v", err)
+	}
+
+	// A minimum raised after the hold does not stop the held funds from being captured
+	ws.SetMinimumBalance("saver", "USD", decimal.NewFromInt(50))
+	if _, err := ws.CaptureHold(h.ID, decimal.NewFromInt(70)); err != nil {
+		t.Errorf("CaptureHold() error = %v", err)
+	}
+}
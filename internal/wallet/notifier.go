@@ -0,0 +1,31 @@
+// internal/wallet/notifier.go
+package wallet
+
+// Notification is a message delivered to a user or to operators
+type Notification struct {
+	UserID    string
+	Type      string
+	Channel   Channel // set when delivered according to user preferences
+	Subject   string
+	Message   string
+	Data      map[string]string
+	Timestamp int64
+}
+
+// Notifier delivers notifications over some channel (email, push, pager, ...)
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// NotifierFunc adapts a plain function to the Notifier interface
+type NotifierFunc func(n Notification) error
+
+// Notify calls f(n)
+func (f NotifierFunc) Notify(n Notification) error {
+	return f(n)
+}
+
+// noopNotifier discards every notification
+type noopNotifier struct{}
+
+func (noopNotifier) Notify(Notification) error { return nil }
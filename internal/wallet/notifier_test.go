@@ -0,0 +1,34 @@
+// internal/wallet/notifier_test.go
+package wallet
+
+import "sync"
+
+// recordingNotifier keeps every notification sent through it. It is safe for the
+// service's background goroutines.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (r *recordingNotifier) Notify(n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+// all returns the notifications recorded so far
+func (r *recordingNotifier) all() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Notification(nil), r.sent...)
+}
+
+// take returns the notifications recorded since the last take and forgets them
+func (r *recordingNotifier) take() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.sent
+	r.sent = nil
+	return out
+}
@@ -0,0 +1,207 @@
+// internal/wallet/online_export.go
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for online exports
+var (
+	ErrExportConflict       = errors.New("transactions were archived past the export point during export")
+	ErrSnapshotInconsistent = errors.New("snapshot balances do not match its transactions")
+)
+
+// walletRead is a wallet copy taken under its owner's lock, together with the log
+// position the copy reflects
+type walletRead struct {
+	wallet   WalletSnapshot
+	position int
+}
+
+// SnapshotOnline captures a consistent snapshot without pausing writes. Unlike Snapshot,
+// which holds every user lock at once, each wallet is read under its own lock only
+// briefly. The snapshot is cut at the log position observed when the export started:
+// every wallet read later is rolled back by the transactions it saw past that point, a
+// multi-version read reconstructed from the append-only log. The result is checked with
+// ReconcileSnapshot before it is returned.
+func (ws *WalletService) SnapshotOnline() (*Snapshot, error) {
+	ws.mu.RLock()
+	cut := ws.logBase + len(ws.transactions)
+	users := make([]User, 0, len(ws.users))
+	for _, user := range ws.users {
+		users = append(users, *user)
+	}
+	supply, inTransit := copyAmounts(ws.supply.supply), copyAmounts(ws.supply.inTransit)
+	ws.mu.RUnlock()
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	reads := make([]walletRead, 0, len(users))
+	furthest := cut
+	for _, user := range users {
+		read, ok := ws.readWallet(user.ID)
+		if !ok {
+			continue
+		}
+		reads = append(reads, read)
+		if read.position > furthest {
+			furthest = read.position
+		}
+	}
+
+	ws.mu.RLock()
+	base := ws.logBase
+	if base > cut {
+		ws.mu.RUnlock()
+		return nil, ErrExportConflict
+	}
+	exported := make([]Transaction, 0, cut-base)
+	for _, tx := range ws.transactions[:cut-base] {
+		exported = append(exported, *tx)
+	}
+	later := append([]*Transaction(nil), ws.transactions[cut-base:furthest-base]...)
+	ws.mu.RUnlock()
+
+	snap := &Snapshot{
+		Version:      SnapshotVersion,
+		CreatedAt:    ws.now().Unix(),
+		LogPosition:  cut,
+		Users:        users,
+		Wallets:      make([]WalletSnapshot, 0, len(reads)),
+		Transactions: exported,
+		Supply:       supply,
+		InTransit:    inTransit,
+		Rates:        ws.fxRates.snapshot(),
+	}
+
+	for _, read := range reads {
+		w := read.wallet
+		for _, tx := range later[:read.position-cut] {
+			rollBack(&w, tx)
+		}
+		snap.Wallets = append(snap.Wallets, w)
+	}
+
+	if mismatches := ws.ReconcileSnapshot(snap); len(mismatches) > 0 {
+		return nil, fmt.Errorf("%w: %d wallets differ, first %s", ErrSnapshotInconsistent, len(mismatches), mismatches[0].UserID)
+	}
+
+	return snap, nil
+}
+
+// BackupOnline writes a JSON snapshot taken with SnapshotOnline to w
+func (ws *WalletService) BackupOnline(w io.Writer) error {
+	snap, err := ws.SnapshotOnline()
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(snap)
+}
+
+// ReconcileSnapshot replays each wallet's archived and snapshotted transactions and
+// reports wallets whose base-currency balance in the snapshot differs from the replay.
+// Transactions archived after the snapshot was taken are counted too, so reconcile
+// before archiving past the snapshot point.
+func (ws *WalletService) ReconcileSnapshot(snap *Snapshot) []BalanceMismatch {
+	var mismatches []BalanceMismatch
+	for _, w := range snap.Wallets {
+		expected := decimal.Zero
+		seen := make(map[string]bool)
+
+		if ws.archive != nil {
+			it, err := ws.archive.Iterate(w.UserID, IterateOptions{})
+			if err == nil {
+				for it.Next() {
+					tx := it.Transaction()
+					seen[tx.ID] = true
+					expected = expected.Add(tx.balanceEffect(w.UserID, w.Currency))
+				}
+				it.Close()
+			}
+		}
+		for i := range snap.Transactions {
+			tx := &snap.Transactions[i]
+			if !seen[tx.ID] {
+				expected = expected.Add(tx.balanceEffect(w.UserID, w.Currency))
+			}
+		}
+
+		if !expected.Equal(w.Balance) {
+			mismatches = append(mismatches, BalanceMismatch{
+				UserID:        w.UserID,
+				StoredBalance: w.Balance,
+				LedgerBalance: expected,
+				DetectedAt:    ws.now().Unix(),
+			})
+		}
+	}
+	return mismatches
+}
+
+// readWallet copies a wallet under its owner's lock. Every operation touching the
+// wallet records its transaction before releasing that lock, so the copy reflects
+// exactly the wallet's transactions below the returned log position.
+func (ws *WalletService) readWallet(userID string) (walletRead, bool) {
+	userLock := ws.userLocks.getLock(userID)
+	userLock.LockAt(PriorityReporting)
+	defer userLock.Unlock()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	position := ws.logBase + len(ws.transactions)
+	ws.mu.RUnlock()
+
+	if !exists {
+		return walletRead{}, false
+	}
+
+	wallet.mu.RLock()
+	defer wallet.mu.RUnlock()
+
+	foreign := make(map[string]decimal.Decimal, len(wallet.Foreign))
+	for currency, amount := range wallet.Foreign {
+		foreign[currency] = amount
+	}
+	return walletRead{
+		wallet: WalletSnapshot{
+			UserID:     wallet.UserID,
+			Currency:   wallet.Currency,
+			Balance:    wallet.Balance,
+			Foreign:    foreign,
+			AutoSettle: wallet.AutoSettle,
+		},
+		position: position,
+	}, true
+}
+
+// rollBack undoes tx's effect on a wallet copy
+func rollBack(w *WalletSnapshot, tx *Transaction) {
+	currencies := []string{w.Currency, tx.currencyOf()}
+	if tx.ToCurrency != "" {
+		currencies = append(currencies, tx.ToCurrency)
+	}
+
+	done := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		if done[currency] {
+			continue
+		}
+		done[currency] = true
+
+		effect := tx.balanceEffect(w.UserID, currency)
+		if effect.IsZero() {
+			continue
+		}
+		if currency == w.Currency {
+			w.Balance = w.Balance.Sub(effect)
+			continue
+		}
+		w.Foreign[currency] = w.Foreign[currency].Sub(effect)
+	}
+}
@@ -0,0 +1,115 @@
+// internal/wallet/online_export_test.go
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSnapshotOnline_ConsistentUnderConcurrentWrites(t *testing.T) {
+	ws := NewWalletService()
+	const users = 8
+	for i := 0; i < users; i++ {
+		id := fmt.Sprintf("user%d", i)
+		ws.CreateUser(id, id, id+"@example.com")
+		ws.Deposit(id, 1000, "seed")
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				from := fmt.Sprintf("user%d", (g+i)%users)
+				to := fmt.Sprintf("user%d", (g+i+1)%users)
+				ws.Transfer(from, to, 1, "churn")
+				if i%5 == 0 {
+					ws.Deposit(from, 2, "top up")
+				}
+			}
+		}(g)
+	}
+
+	for i := 0; i < 20; i++ {
+		snap, err := ws.SnapshotOnline()
+		if err != nil {
+			close(stop)
+			wg.Wait()
+			t.Fatalf("SnapshotOnline() error = %v", err)
+		}
+
+		// Money is conserved at the cut: wallet total equals deposits in the export
+		total, deposits := decimal.Zero, decimal.Zero
+		for _, w := range snap.Wallets {
+			total = total.Add(w.Balance)
+		}
+		for _, tx := range snap.Transactions {
+			if tx.Type == TransactionDeposit {
+				deposits = deposits.Add(tx.Amount)
+			}
+		}
+		if !total.Equal(deposits) {
+			t.Errorf("snapshot %d: wallet total %s != deposits %s", i, total, deposits)
+		}
+		if len(snap.Transactions) != snap.LogPosition {
+			t.Errorf("snapshot %d: %d transactions, log position %d", i, len(snap.Transactions), snap.LogPosition)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestBackupOnline_RoundTrip(t *testing.T) {
+	ws := NewWalletService(WithArchive(NewMemoryArchive()))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(5), "eur")
+	ws.Transfer("alice", "bob", 40, "lunch")
+	ws.ArchiveTransactionsBefore(ws.now().Unix() + 1)
+	ws.Transfer("bob", "alice", 10, "change")
+
+	var buf bytes.Buffer
+	if err := ws.BackupOnline(&buf); err != nil {
+		t.Fatalf("BackupOnline() error = %v", err)
+	}
+	restored, err := Restore(&buf)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	for user, want := range map[string]int64{"alice": 70, "bob": 30} {
+		if b, _ := restored.GetBalanceDecimal(user); !b.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("%s balance = %s, want %d", user, b, want)
+		}
+	}
+	if b, _ := restored.GetCurrencyBalance("alice", "EUR"); !b.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("alice EUR = %s, want 5", b)
+	}
+}
+
+func TestReconcileSnapshot_DetectsTampering(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	snap, _ := ws.SnapshotOnline()
+	snap.Wallets[0].Balance = decimal.NewFromInt(1000)
+
+	mismatches := ws.ReconcileSnapshot(snap)
+	if len(mismatches) != 1 || !mismatches[0].LedgerBalance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("mismatches = %+v, want alice with ledger 100", mismatches)
+	}
+}
@@ -0,0 +1,152 @@
+// internal/wallet/optiming.go
+package wallet
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OperationStats aggregates latency for one kind of operation
+type OperationStats struct {
+	Operation     string
+	Count         int64
+	Errors        int64
+	SlowCount     int64
+	TotalDuration time.Duration
+	TotalLockWait time.Duration
+	MaxDuration   time.Duration
+}
+
+// AverageDuration returns the mean end-to-end latency
+func (s OperationStats) AverageDuration() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Count)
+}
+
+// opTimingState holds the slow-operation configuration and aggregated stats
+type opTimingState struct {
+	mu            sync.Mutex
+	slowThreshold time.Duration
+	logger        *slog.Logger
+	stats         map[string]*OperationStats
+}
+
+// WithSlowOpThreshold logs operations whose end-to-end latency exceeds d
+func WithSlowOpThreshold(d time.Duration) Option {
+	return func(ws *WalletService) {
+		ws.timing.slowThreshold = d
+	}
+}
+
+// WithLogger sets the structured logger used for operational logs such as slow operations
+func WithLogger(logger *slog.Logger) Option {
+	return func(ws *WalletService) {
+		ws.timing.logger = logger
+	}
+}
+
+// opTimer measures one operation, separating time spent waiting for user locks from
+// time spent doing the work once they are held. Timing always uses the wall clock,
+// never the service clock, so injected test clocks do not distort it.
+type opTimer struct {
+	ws       *WalletService
+	name     string
+	userIDs  []string
+	start    time.Time
+	acquired time.Time
+}
+
+// startOp begins timing an operation on the given users
+func (ws *WalletService) startOp(name string, userIDs ...string) *opTimer {
+	return &opTimer{ws: ws, name: name, userIDs: userIDs, start: time.Now()}
+}
+
+// locked marks the moment all user locks were acquired
+func (t *opTimer) locked() {
+	t.acquired = time.Now()
+}
+
+// finish records the operation's latency, emitting metrics and a slow-operation log
+// entry when the configured threshold is exceeded
+func (t *opTimer) finish(err error) {
+	end := time.Now()
+	total := end.Sub(t.start)
+	lockWait := time.Duration(0)
+	if !t.acquired.IsZero() {
+		lockWait = t.acquired.Sub(t.start)
+	}
+	work := total - lockWait
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	labels := map[string]string{"operation": t.name, "outcome": outcome}
+	t.ws.metrics.IncCounter("operations_total", labels)
+	t.ws.metrics.ObserveValue("operation_duration_seconds", total.Seconds(), labels)
+	t.ws.metrics.ObserveValue("operation_lock_wait_seconds", lockWait.Seconds(), labels)
+
+	timing := &t.ws.timing
+	timing.mu.Lock()
+	if timing.stats == nil {
+		timing.stats = make(map[string]*OperationStats)
+	}
+	stats := timing.stats[t.name]
+	if stats == nil {
+		stats = &OperationStats{Operation: t.name}
+		timing.stats[t.name] = stats
+	}
+	stats.Count++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.TotalDuration += total
+	stats.TotalLockWait += lockWait
+	if total > stats.MaxDuration {
+		stats.MaxDuration = total
+	}
+	slow := timing.slowThreshold > 0 && total > timing.slowThreshold
+	if slow {
+		stats.SlowCount++
+	}
+	logger := timing.logger
+	timing.mu.Unlock()
+
+	if !slow {
+		return
+	}
+
+	t.ws.metrics.IncCounter("slow_operations_total", map[string]string{"operation": t.name})
+	if logger == nil {
+		logger = slog.Default()
+	}
+	attrs := []any{
+		slog.String("operation", t.name),
+		slog.Any("user_ids", t.userIDs),
+		slog.Duration("total", total),
+		slog.Duration("lock_wait", lockWait),
+		slog.Duration("work", work),
+		slog.String("outcome", outcome),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.Warn("slow wallet operation", attrs...)
+}
+
+// GetOperationStats returns aggregated latency per operation, ordered by name
+func (ws *WalletService) GetOperationStats() []OperationStats {
+	ws.timing.mu.Lock()
+	defer ws.timing.mu.Unlock()
+
+	stats := make([]OperationStats, 0, len(ws.timing.stats))
+	for _, s := range ws.timing.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Operation < stats[j].Operation })
+	return stats
+}
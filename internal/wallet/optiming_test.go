@@ -0,0 +1,73 @@
+// internal/wallet/optiming_test.go
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// TestWalletService_SlowOperationLogging tests lock-wait vs work breakdown in slow-op logs
+func TestWalletService_SlowOperationLogging(t *testing.T) {
+	var logs syncBuffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithLogger(logger), WithMetrics(metrics), WithSlowOpThreshold(10*time.Millisecond))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("user1", 100.0, "fast deposit")
+
+	if logs.buf.Len() != 0 {
+		t.Fatalf("Fast operation was logged: %s", logs.buf.String())
+	}
+
+	// Hold the user's lock so the next deposit spends its time waiting for it
+	lock := ws.userLocks.getLock("user1")
+	lock.Lock()
+	done := make(chan struct{})
+	go func() {
+		ws.Deposit("user1", 1.0, "contended deposit")
+		close(done)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	lock.Unlock()
+	<-done
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON log entry, got %q: %v", logs.buf.String(), err)
+	}
+	if entry["operation"] != "deposit" || entry["msg"] != "slow wallet operation" {
+		t.Errorf("Unexpected log entry %+v", entry)
+	}
+	lockWait, _ := entry["lock_wait"].(float64)
+	work, _ := entry["work"].(float64)
+	if time.Duration(lockWait) < 20*time.Millisecond || work >= lockWait {
+		t.Errorf("Expected lock wait to dominate, got lock_wait=%v work=%v", lockWait, work)
+	}
+
+	stats := ws.GetOperationStats()
+	if len(stats) != 1 || stats[0].Count != 2 || stats[0].SlowCount != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if metrics.Counter("slow_operations_total", map[string]string{"operation": "deposit"}) != 1 {
+		t.Error("Expected slow operation metric")
+	}
+	if metrics.Counter("operations_total", map[string]string{"operation": "deposit", "outcome": "ok"}) != 2 {
+		t.Error("Expected operation counter for both deposits")
+	}
+}
@@ -0,0 +1,28 @@
+// internal/wallet/options.go
+package wallet
+
+import "time"
+
+// Option configures optional collaborators of a WalletService
+type Option func(*WalletService)
+
+// WithNotifier sets the notifier used for user and operator alerts
+func WithNotifier(n Notifier) Option {
+	return func(ws *WalletService) {
+		ws.notifier = n
+	}
+}
+
+// WithMetrics sets the recorder that receives operational metrics
+func WithMetrics(m MetricsRecorder) Option {
+	return func(ws *WalletService) {
+		ws.metrics = m
+	}
+}
+
+// WithClock overrides the time source, mainly for deterministic tests
+func WithClock(now func() time.Time) Option {
+	return func(ws *WalletService) {
+		ws.now = now
+	}
+}
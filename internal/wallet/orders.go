@@ -0,0 +1,216 @@
+// internal/wallet/orders.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+// Error definitions for order settlement
+var (
+	ErrInvalidSplit  = errors.New("each split needs a recipient and exactly one of percent, amount or remainder")
+	ErrSplitMismatch = errors.New("splits do not add up to the order total")
+	ErrOrderSettled  = errors.New("order already settled")
+	ErrOrderNotFound = errors.New("order settlement not found")
+)
+
+// Common split roles; any non-empty role may be used
+const (
+	SplitSeller   = "seller"
+	SplitPlatform = "platform_fee"
+	SplitDelivery = "delivery"
+	SplitTax      = "tax"
+)
+
+// Split is one recipient's share of an order. Set exactly one of Percent (of the order
+// total, rounded half up to the currency precision), Amount, or Remainder. At most one
+// split may take the remainder, which is whatever the other splits leave.
+type Split struct {
+	UserID    string
+	Role      string
+	Percent   decimal.Decimal
+	Amount    decimal.Decimal
+	Remainder bool
+}
+
+// SettlementLeg is one credit made when an order was settled
+type SettlementLeg struct {
+	UserID        string
+	Role          string
+	Amount        decimal.Decimal
+	TransactionID string
+}
+
+// OrderSettlement records how an order total was paid out
+type OrderSettlement struct {
+	OrderRef  string
+	BuyerID   string
+	Total     decimal.Decimal
+	Legs      []SettlementLeg
+	SettledAt int64
+}
+
+// orderBook remembers settled orders so each is paid out once
+type orderBook struct {
+	mu      sync.Mutex
+	settled map[string]*OrderSettlement // by order reference
+}
+
+// SettleOrder debits buyerID total once and credits every split atomically: either all
+// legs are applied or none are. Each leg is a transfer carrying the order reference
+// and the split role in its metadata. An order reference can be settled once.
+func (ws *WalletService) SettleOrder(buyerID, orderRef string, total decimal.Decimal, splits []Split) (*OrderSettlement, error) {
+	return ws.SettleOrderContext(context.Background(), buyerID, orderRef, total, splits)
+}
+
+// SettleOrderContext is SettleOrder on behalf of a caller's ctx
+func (ws *WalletService) SettleOrderContext(ctx context.Context, buyerID, orderRef string, total decimal.Decimal, splits []Split) (settlement *OrderSettlement, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	timer := ws.startOp("settle_order", buyerID)
+	defer func() { timer.finish(err) }()
+
+	if orderRef == "" || len(splits) == 0 {
+		return nil, ErrInvalidSplit
+	}
+	if total.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	ws.mu.RLock()
+	buyer, exists := ws.wallets[buyerID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	amounts, err := resolveSplits(total, splits, ws.currencyPrecision(buyer.Currency))
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve the reference before moving money so a concurrent retry cannot pay twice
+	ws.orders.mu.Lock()
+	if _, done := ws.orders.settled[orderRef]; done {
+		ws.orders.mu.Unlock()
+		return nil, ErrOrderSettled
+	}
+	if ws.orders.settled == nil {
+		ws.orders.settled = make(map[string]*OrderSettlement)
+	}
+	ws.orders.settled[orderRef] = nil
+	ws.orders.mu.Unlock()
+
+	payouts := make([]Payout, 0, len(splits))
+	metadata := make([]map[string]string, 0, len(splits))
+	for i, s := range splits {
+		if amounts[i].IsZero() {
+			continue
+		}
+		payouts = append(payouts, Payout{UserID: s.UserID, Amount: amounts[i]})
+		metadata = append(metadata, map[string]string{"order_ref": orderRef, "split_role": s.Role})
+	}
+
+	txs, err := ws.batchTransfer(ctx, timer, buyerID, payouts, "order "+orderRef, metadata)
+	if err != nil {
+		ws.orders.mu.Lock()
+		delete(ws.orders.settled, orderRef)
+		ws.orders.mu.Unlock()
+		return nil, err
+	}
+
+	settlement = &OrderSettlement{
+		OrderRef:  orderRef,
+		BuyerID:   buyerID,
+		Total:     total,
+		SettledAt: ws.now().Unix(),
+	}
+	for i, tx := range txs {
+		settlement.Legs = append(settlement.Legs, SettlementLeg{
+			UserID:        tx.ToUserID,
+			Role:          metadata[i]["split_role"],
+			Amount:        tx.Amount,
+			TransactionID: tx.ID,
+		})
+	}
+
+	ws.orders.mu.Lock()
+	ws.orders.settled[orderRef] = settlement
+	ws.orders.mu.Unlock()
+
+	ws.metrics.IncCounter("orders_settled_total", nil)
+	return settlement.copy(), nil
+}
+
+// GetOrderSettlement returns how an order was settled
+func (ws *WalletService) GetOrderSettlement(orderRef string) (*OrderSettlement, error) {
+	ws.orders.mu.Lock()
+	defer ws.orders.mu.Unlock()
+
+	settlement := ws.orders.settled[orderRef]
+	if settlement == nil {
+		return nil, ErrOrderNotFound
+	}
+	return settlement.copy(), nil
+}
+
+// resolveSplits turns splits into amounts that add up to exactly total
+func resolveSplits(total decimal.Decimal, splits []Split, places int32) ([]decimal.Decimal, error) {
+	amounts := make([]decimal.Decimal, len(splits))
+	remainder := -1
+	sum := decimal.Zero
+	for i, s := range splits {
+		set := 0
+		if !s.Percent.IsZero() {
+			set++
+		}
+		if !s.Amount.IsZero() {
+			set++
+		}
+		if s.Remainder {
+			set++
+		}
+		if s.UserID == "" || set != 1 || s.Percent.IsNegative() || s.Amount.IsNegative() {
+			return nil, ErrInvalidSplit
+		}
+
+		switch {
+		case s.Remainder:
+			if remainder >= 0 {
+				return nil, ErrInvalidSplit
+			}
+			remainder = i
+			continue
+		case s.Percent.IsPositive():
+			amounts[i] = moneymath.Percent(total, s.Percent, places, moneymath.HalfUp)
+		default:
+			amounts[i] = s.Amount
+		}
+		sum = sum.Add(amounts[i])
+	}
+
+	if remainder >= 0 {
+		amounts[remainder] = total.Sub(sum)
+		if amounts[remainder].IsNegative() {
+			return nil, ErrSplitMismatch
+		}
+		return amounts, nil
+	}
+	if !sum.Equal(total) {
+		return nil, ErrSplitMismatch
+	}
+	return amounts, nil
+}
+
+// copy returns a copy safe to hand to callers
+func (s *OrderSettlement) copy() *OrderSettlement {
+	copied := *s
+	copied.Legs = append([]SettlementLeg(nil), s.Legs...)
+	return &copied
+}
@@ -0,0 +1,98 @@
+// internal/wallet/orders_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// marketplaceSplits pays a 10% fee, 8.25% tax, a fixed 4.99 delivery charge and the rest
+// to the seller
+func marketplaceSplits() []Split {
+	return []Split{
+		{UserID: "seller", Role: SplitSeller, Remainder: true},
+		{UserID: "platform", Role: SplitPlatform, Percent: decimal.NewFromInt(10)},
+		{UserID: "courier", Role: SplitDelivery, Amount: decimal.RequireFromString("4.99")},
+		{UserID: "tax", Role: SplitTax, Percent: decimal.RequireFromString("8.25")},
+	}
+}
+
+func TestSettleOrder(t *testing.T) {
+	ws := NewWalletService()
+	for _, id := range []string{"buyer", "seller", "platform", "courier", "tax"} {
+		ws.CreateUser(id, id, id+"@example.com")
+	}
+	ws.Deposit("buyer", 200, "seed")
+
+	settlement, err := ws.SettleOrder("buyer", "ord-1", decimal.RequireFromString("99.99"), marketplaceSplits())
+	if err != nil {
+		t.Fatalf("SettleOrder() error = %v", err)
+	}
+
+	want := map[string]string{
+		"buyer":    "100.01",
+		"seller":   "76.75",
+		"platform": "10",
+		"courier":  "4.99",
+		"tax":      "8.25",
+	}
+	for id, amount := range want {
+		if b, _ := ws.GetBalanceDecimal(id); !b.Equal(decimal.RequireFromString(amount)) {
+			t.Errorf("%s balance = %s, want %s", id, b, amount)
+		}
+	}
+
+	if len(settlement.Legs) != 4 {
+		t.Fatalf("legs = %+v, want 4", settlement.Legs)
+	}
+	for _, leg := range settlement.Legs {
+		tx, err := ws.GetTransaction(leg.TransactionID)
+		if err != nil || tx.Metadata["order_ref"] != "ord-1" || tx.Metadata["split_role"] != leg.Role {
+			t.Errorf("leg %+v transaction = %+v, %v", leg, tx, err)
+		}
+	}
+
+	if _, err := ws.SettleOrder("buyer", "ord-1", decimal.RequireFromString("99.99"), marketplaceSplits()); err != ErrOrderSettled {
+		t.Errorf("second settlement error = %v, want %v", err, ErrOrderSettled)
+	}
+	if got, err := ws.GetOrderSettlement("ord-1"); err != nil || got.Total.String() != "99.99" {
+		t.Errorf("GetOrderSettlement() = %+v, %v", got, err)
+	}
+}
+
+func TestSettleOrder_AllOrNothing(t *testing.T) {
+	ws := NewWalletService()
+	for _, id := range []string{"buyer", "seller", "platform", "courier", "tax"} {
+		ws.CreateUser(id, id, id+"@example.com")
+	}
+	ws.Deposit("buyer", 200, "seed")
+	ws.BlockUser("courier", "buyer")
+
+	tests := []struct {
+		name    string
+		total   string
+		splits  []Split
+		wantErr error
+	}{
+		{"blocked leg", "50", marketplaceSplits(), ErrCounterpartyBlocked},
+		{"insufficient balance", "500", marketplaceSplits()[:2], ErrInsufficientBalance},
+		{"fixed legs short of total", "50", []Split{{UserID: "seller", Amount: decimal.NewFromInt(40)}}, ErrSplitMismatch},
+		{"legs exceed total", "5", marketplaceSplits(), ErrSplitMismatch},
+		{"two remainders", "50", []Split{{UserID: "seller", Remainder: true}, {UserID: "tax", Remainder: true}}, ErrInvalidSplit},
+		{"percent and amount", "50", []Split{{UserID: "seller", Percent: decimal.NewFromInt(50), Amount: decimal.NewFromInt(25)}}, ErrInvalidSplit},
+		{"unknown recipient", "50", []Split{{UserID: "ghost", Remainder: true}}, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := ws.SettleOrder("buyer", "ord-2", decimal.RequireFromString(tt.total), tt.splits); err != tt.wantErr {
+			t.Errorf("%s: SettleOrder() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if b, _ := ws.GetBalanceDecimal("buyer"); !b.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("buyer balance after failures = %s, want 200", b)
+	}
+	if _, err := ws.GetOrderSettlement("ord-2"); err != ErrOrderNotFound {
+		t.Errorf("GetOrderSettlement() error = %v, want %v", err, ErrOrderNotFound)
+	}
+}
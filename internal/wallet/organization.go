@@ -0,0 +1,323 @@
+// internal/wallet/organization.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for organization wallets and expense approvals
+var (
+	ErrOrganizationNotFound   = errors.New("organization not found")
+	ErrNotOrgMember           = errors.New("user is not a member of the organization")
+	ErrExpenseNotFound        = errors.New("expense not found")
+	ErrExpenseNotPending      = errors.New("expense is not awaiting approval")
+	ErrNotAuthorizedToApprove = errors.New("reviewer cannot approve this step")
+	ErrInvalidApprovalChain   = errors.New("approval chain must have at least one step")
+)
+
+// OrgRole is a member's role within an organization
+type OrgRole string
+
+const (
+	OrgRoleMember  OrgRole = "member"
+	OrgRoleManager OrgRole = "manager"
+	OrgRoleFinance OrgRole = "finance"
+)
+
+// ApprovalStep is one stage of an approval chain, satisfied by any member holding Role
+type ApprovalStep struct {
+	Name string
+	Role OrgRole
+}
+
+// DefaultApprovalChain routes expenses through a manager and then finance
+var DefaultApprovalChain = []ApprovalStep{
+	{Name: "manager", Role: OrgRoleManager},
+	{Name: "finance", Role: OrgRoleFinance},
+}
+
+// Organization is a shared wallet owned by a group of members. Its wallet is keyed by ID.
+type Organization struct {
+	ID            string
+	Name          string
+	Members       map[string]OrgRole
+	ApprovalChain []ApprovalStep
+}
+
+// ExpenseStatus tracks an expense through its approval chain
+type ExpenseStatus string
+
+const (
+	ExpensePending  ExpenseStatus = "pending"
+	ExpenseApproved ExpenseStatus = "approved"
+	ExpenseRejected ExpenseStatus = "rejected"
+)
+
+// Approval is a single reviewer decision in an approval chain
+type Approval struct {
+	Step       string
+	ReviewerID string
+	Approved   bool
+	Comment    string
+	Timestamp  int64
+}
+
+// ExpenseRequest is a request to pay out of an organization wallet
+type ExpenseRequest struct {
+	ID            string
+	OrgID         string
+	SubmitterID   string
+	PayeeID       string
+	Amount        decimal.Decimal
+	Description   string
+	Status        ExpenseStatus
+	CurrentStep   int
+	Chain         []ApprovalStep
+	Approvals     []Approval
+	TransactionID string
+	CreatedAt     int64
+}
+
+// orgRegistry holds organizations and their expense requests
+type orgRegistry struct {
+	mu       sync.RWMutex
+	orgs     map[string]*Organization
+	expenses map[string]*ExpenseRequest
+}
+
+// CreateOrganization creates an organization together with its wallet
+func (ws *WalletService) CreateOrganization(orgID, name, email string) error {
+	if err := ws.CreateUser(orgID, name, email); err != nil {
+		return err
+	}
+
+	ws.orgs.mu.Lock()
+	defer ws.orgs.mu.Unlock()
+
+	if ws.orgs.orgs == nil {
+		ws.orgs.orgs = make(map[string]*Organization)
+		ws.orgs.expenses = make(map[string]*ExpenseRequest)
+	}
+	ws.orgs.orgs[orgID] = &Organization{
+		ID:            orgID,
+		Name:          name,
+		Members:       make(map[string]OrgRole),
+		ApprovalChain: append([]ApprovalStep(nil), DefaultApprovalChain...),
+	}
+
+	return nil
+}
+
+// AddOrgMember adds a user to an organization or changes their role
+func (ws *WalletService) AddOrgMember(orgID, userID string, role OrgRole) error {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	ws.orgs.mu.Lock()
+	defer ws.orgs.mu.Unlock()
+
+	org, exists := ws.orgs.orgs[orgID]
+	if !exists {
+		return ErrOrganizationNotFound
+	}
+	org.Members[userID] = role
+
+	return nil
+}
+
+// RemoveOrgMember removes a user from an organization
+func (ws *WalletService) RemoveOrgMember(orgID, userID string) error {
+	ws.orgs.mu.Lock()
+	defer ws.orgs.mu.Unlock()
+
+	org, exists := ws.orgs.orgs[orgID]
+	if !exists {
+		return ErrOrganizationNotFound
+	}
+	if _, member := org.Members[userID]; !member {
+		return ErrNotOrgMember
+	}
+	delete(org.Members, userID)
+
+	return nil
+}
+
+// SetApprovalChain replaces the approval chain applied to newly submitted expenses
+func (ws *WalletService) SetApprovalChain(orgID string, chain []ApprovalStep) error {
+	if len(chain) == 0 {
+		return ErrInvalidApprovalChain
+	}
+
+	ws.orgs.mu.Lock()
+	defer ws.orgs.mu.Unlock()
+
+	org, exists := ws.orgs.orgs[orgID]
+	if !exists {
+		return ErrOrganizationNotFound
+	}
+	org.ApprovalChain = append([]ApprovalStep(nil), chain...)
+
+	return nil
+}
+
+// SubmitExpense creates an expense request against an organization wallet. No funds move
+// until every step of the organization's approval chain has approved it.
+func (ws *WalletService) SubmitExpense(orgID, submitterID, payeeID string, amount decimal.Decimal, description string) (*ExpenseRequest, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	ws.mu.RLock()
+	_, payeeExists := ws.wallets[payeeID]
+	ws.mu.RUnlock()
+
+	if !payeeExists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.orgs.mu.Lock()
+	defer ws.orgs.mu.Unlock()
+
+	org, exists := ws.orgs.orgs[orgID]
+	if !exists {
+		return nil, ErrOrganizationNotFound
+	}
+	if _, member := org.Members[submitterID]; !member {
+		return nil, ErrNotOrgMember
+	}
+
+	expense := &ExpenseRequest{
+		ID:          ws.newID("exp"),
+		OrgID:       orgID,
+		SubmitterID: submitterID,
+		PayeeID:     payeeID,
+		Amount:      amount,
+		Description: description,
+		Status:      ExpensePending,
+		Chain:       append([]ApprovalStep(nil), org.ApprovalChain...),
+		CreatedAt:   ws.now().Unix(),
+	}
+	ws.orgs.expenses[expense.ID] = expense
+
+	return expense.clone(), nil
+}
+
+// ReviewExpense records a reviewer's decision on the expense's current step. A rejection
+// ends the chain; the final approval transfers the funds and attaches the chain to the
+// resulting transaction.
+func (ws *WalletService) ReviewExpense(expenseID, reviewerID string, approve bool, comment string) (*ExpenseRequest, error) {
+	return ws.ReviewExpenseContext(context.Background(), expenseID, reviewerID, approve, comment)
+}
+
+// ReviewExpenseContext is ReviewExpense on behalf of a caller's ctx
+func (ws *WalletService) ReviewExpenseContext(ctx context.Context, expenseID, reviewerID string, approve bool, comment string) (*ExpenseRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ws.orgs.mu.Lock()
+	defer ws.orgs.mu.Unlock()
+
+	expense, exists := ws.orgs.expenses[expenseID]
+	if !exists {
+		return nil, ErrExpenseNotFound
+	}
+	if expense.Status != ExpensePending {
+		return nil, ErrExpenseNotPending
+	}
+
+	org := ws.orgs.orgs[expense.OrgID]
+	step := expense.Chain[expense.CurrentStep]
+	if reviewerID == expense.SubmitterID || org.Members[reviewerID] != step.Role {
+		return nil, ErrNotAuthorizedToApprove
+	}
+
+	decision := Approval{
+		Step:       step.Name,
+		ReviewerID: reviewerID,
+		Approved:   approve,
+		Comment:    comment,
+		Timestamp:  ws.now().Unix(),
+	}
+
+	if !approve {
+		expense.Approvals = append(expense.Approvals, decision)
+		expense.Status = ExpenseRejected
+		return expense.clone(), nil
+	}
+
+	if expense.CurrentStep < len(expense.Chain)-1 {
+		expense.Approvals = append(expense.Approvals, decision)
+		expense.CurrentStep++
+		return expense.clone(), nil
+	}
+
+	// Final approval releases the funds; a failed transfer leaves the step open for retry.
+	// A compliance hold has already debited the organization, so it counts as approved.
+	approvals := append(append([]Approval(nil), expense.Approvals...), decision)
+	tx, err := ws.transfer(ctx, expense.OrgID, expense.PayeeID, expense.Amount, expense.Description,
+		transferOptions{approvals: approvals})
+	if err != nil && !errors.Is(err, ErrTransferHeld) {
+		return nil, err
+	}
+
+	expense.Approvals = approvals
+	expense.Status = ExpenseApproved
+	expense.TransactionID = tx.ID
+
+	return expense.clone(), err
+}
+
+// GetExpense returns an expense request by ID
+func (ws *WalletService) GetExpense(expenseID string) (*ExpenseRequest, error) {
+	ws.orgs.mu.RLock()
+	defer ws.orgs.mu.RUnlock()
+
+	expense, exists := ws.orgs.expenses[expenseID]
+	if !exists {
+		return nil, ErrExpenseNotFound
+	}
+	return expense.clone(), nil
+}
+
+// GetPendingExpenses returns an organization's expenses awaiting review, oldest first
+func (ws *WalletService) GetPendingExpenses(orgID string) ([]*ExpenseRequest, error) {
+	ws.orgs.mu.RLock()
+	defer ws.orgs.mu.RUnlock()
+
+	if _, exists := ws.orgs.orgs[orgID]; !exists {
+		return nil, ErrOrganizationNotFound
+	}
+
+	var pending []*ExpenseRequest
+	for _, expense := range ws.orgs.expenses {
+		if expense.OrgID == orgID && expense.Status == ExpensePending {
+			pending = append(pending, expense.clone())
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].CreatedAt != pending[j].CreatedAt {
+			return pending[i].CreatedAt < pending[j].CreatedAt
+		}
+		return pending[i].ID < pending[j].ID
+	})
+
+	return pending, nil
+}
+
+// clone returns a copy that callers can keep without racing with later reviews
+func (e *ExpenseRequest) clone() *ExpenseRequest {
+	copied := *e
+	copied.Chain = append([]ApprovalStep(nil), e.Chain...)
+	copied.Approvals = append([]Approval(nil), e.Approvals...)
+	return &copied
+}
@@ -0,0 +1,102 @@
+// internal/wallet/organization_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestWalletService_ExpenseApprovalChain tests that only the final approval releases funds
+func TestWalletService_ExpenseApprovalChain(t *testing.T) {
+	ws := NewWalletService()
+	if err := ws.CreateOrganization("acme", "Acme Corp", "billing@acme.com"); err != nil {
+		t.Fatalf("CreateOrganization() error = %v", err)
+	}
+	ws.CreateUser("emp", "Employee", "emp@acme.com")
+	ws.CreateUser("mgr", "Manager", "mgr@acme.com")
+	ws.CreateUser("fin", "Finance", "fin@acme.com")
+	ws.CreateUser("vendor", "Vendor", "vendor@example.com")
+	ws.AddOrgMember("acme", "emp", OrgRoleMember)
+	ws.AddOrgMember("acme", "mgr", OrgRoleManager)
+	ws.AddOrgMember("acme", "fin", OrgRoleFinance)
+	ws.Deposit("acme", 1000.0, "funding")
+
+	expense, err := ws.SubmitExpense("acme", "emp", "vendor", decimal.NewFromInt(200), "laptops")
+	if err != nil {
+		t.Fatalf("SubmitExpense() error = %v", err)
+	}
+
+	// Finance cannot skip ahead of the manager, and submitters cannot self-approve
+	if _, err := ws.ReviewExpense(expense.ID, "fin", true, "ok"); err != ErrNotAuthorizedToApprove {
+		t.Errorf("Expected ErrNotAuthorizedToApprove for out-of-order review, got %v", err)
+	}
+	if _, err := ws.ReviewExpense(expense.ID, "emp", true, "ok"); err != ErrNotAuthorizedToApprove {
+		t.Errorf("Expected ErrNotAuthorizedToApprove for submitter, got %v", err)
+	}
+
+	expense, err = ws.ReviewExpense(expense.ID, "mgr", true, "needed for new hires")
+	if err != nil || expense.Status != ExpensePending || expense.CurrentStep != 1 {
+		t.Fatalf("Manager approval = %+v, %v", expense, err)
+	}
+	if balance, _ := ws.GetBalanceDecimal("vendor"); !balance.IsZero() {
+		t.Errorf("Funds released before final approval: %s", balance.String())
+	}
+
+	expense, err = ws.ReviewExpense(expense.ID, "fin", true, "within budget")
+	if err != nil || expense.Status != ExpenseApproved || expense.TransactionID == "" {
+		t.Fatalf("Finance approval = %+v, %v", expense, err)
+	}
+
+	balance, _ := ws.GetBalanceDecimal("vendor")
+	if !balance.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("Expected vendor balance 200, got %s", balance.String())
+	}
+
+	history, _ := ws.GetTransactionHistory("vendor")
+	if len(history) != 1 || len(history[0].Approvals) != 2 || history[0].Approvals[1].ReviewerID != "fin" {
+		t.Errorf("Expected approval chain on transaction, got %+v", history)
+	}
+
+	if _, err := ws.ReviewExpense(expense.ID, "fin", true, "again"); err != ErrExpenseNotPending {
+		t.Errorf("Expected ErrExpenseNotPending, got %v", err)
+	}
+}
+
+// TestWalletService_ExpenseRejection tests that a rejection ends the chain without paying
+func TestWalletService_ExpenseRejection(t *testing.T) {
+	ws := NewWalletService()
+	if err := ws.CreateOrganization("acme", "Acme Corp", "billing@acme.com"); err != nil {
+		t.Fatalf("CreateOrganization() error = %v", err)
+	}
+	ws.CreateUser("emp", "Employee", "emp@acme.com")
+	ws.CreateUser("mgr", "Manager", "mgr@acme.com")
+	ws.CreateUser("fin", "Finance", "fin@acme.com")
+	ws.CreateUser("vendor", "Vendor", "vendor@example.com")
+	ws.AddOrgMember("acme", "emp", OrgRoleMember)
+	ws.AddOrgMember("acme", "mgr", OrgRoleManager)
+	ws.AddOrgMember("acme", "fin", OrgRoleFinance)
+	ws.Deposit("acme", 1000.0, "funding")
+	ws.SetApprovalChain("acme", []ApprovalStep{{Name: "finance", Role: OrgRoleFinance}})
+
+	expense, _ := ws.SubmitExpense("acme", "mgr", "vendor", decimal.NewFromInt(50), "team dinner")
+	expense, err := ws.ReviewExpense(expense.ID, "fin", false, "not a business expense")
+	if err != nil || expense.Status != ExpenseRejected || expense.Approvals[0].Comment != "not a business expense" {
+		t.Fatalf("ReviewExpense() = %+v, %v", expense, err)
+	}
+
+	pending, _ := ws.GetPendingExpenses("acme")
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending expenses, got %d", len(pending))
+	}
+	if balance, _ := ws.GetBalanceDecimal("acme"); !balance.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("Expected org balance untouched, got %s", balance.String())
+	}
+
+	if _, err := ws.SubmitExpense("acme", "vendor", "vendor", decimal.NewFromInt(1), "x"); err != ErrNotOrgMember {
+		t.Errorf("Expected ErrNotOrgMember, got %v", err)
+	}
+	if err := ws.SetApprovalChain("acme", nil); err != ErrInvalidApprovalChain {
+		t.Errorf("Expected ErrInvalidApprovalChain, got %v", err)
+	}
+}
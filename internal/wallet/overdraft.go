@@ -0,0 +1,117 @@
+// internal/wallet/overdraft.go
+package wallet
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidOverdraftLimit is returned for a negative overdraft limit
+var ErrInvalidOverdraftLimit = errors.New("overdraft limit must not be negative")
+
+// metaOverdraftDrawn records how much of an overdraft withdrawal was drawn on credit
+const metaOverdraftDrawn = "overdraft_drawn"
+
+// Overdraft reports a wallet's credit line in its base currency. CreditUsed is how far
+// the balance is below zero; credits to the wallet repay it first.
+type Overdraft struct {
+	UserID          string
+	Currency        string
+	Limit           decimal.Decimal
+	CreditUsed      decimal.Decimal
+	CreditAvailable decimal.Decimal // what withdrawals may still draw; never negative
+}
+
+// overdraftBook holds the overdraft limit of each wallet that has one
+type overdraftBook struct {
+	mu     sync.Mutex
+	limits map[string]decimal.Decimal
+}
+
+// SetOverdraftLimit lets withdrawals take userID's balance in its base currency down
+// to -limit, for postpaid accounts. A zero limit removes the facility. Lowering the limit
+// below the credit already used stops further drawing but calls in nothing.
+func (ws *WalletService) SetOverdraftLimit(userID string, limit decimal.Decimal) error {
+	if limit.IsNegative() {
+		return ErrInvalidOverdraftLimit
+	}
+	if !ws.walletExists(userID) {
+		return ErrUserNotFound
+	}
+
+	ws.overdrafts.mu.Lock()
+	defer ws.overdrafts.mu.Unlock()
+	if limit.IsZero() {
+		delete(ws.overdrafts.limits, userID)
+		return nil
+	}
+	if ws.overdrafts.limits == nil {
+		ws.overdrafts.limits = make(map[string]decimal.Decimal)
+	}
+	ws.overdrafts.limits[userID] = limit
+	return nil
+}
+
+// GetOverdraft returns userID's overdraft limit and how much of it is used
+func (ws *WalletService) GetOverdraft(userID string) (*Overdraft, error) {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.overdrafts.mu.Lock()
+	limit := ws.overdrafts.limits[userID]
+	ws.overdrafts.mu.Unlock()
+
+	wallet.mu.RLock()
+	defer wallet.mu.RUnlock()
+	o := &Overdraft{
+		UserID:     userID,
+		Currency:   wallet.Currency,
+		Limit:      limit,
+		CreditUsed: creditUsed(wallet.Balance),
+	}
+	// Held funds count against the line once the balance no longer covers them
+	drawable := limit.Add(decimal.Min(decimal.Zero, wallet.available(wallet.Currency)))
+	o.CreditAvailable = decimal.Max(decimal.Zero, decimal.Min(limit.Sub(o.CreditUsed), drawable))
+	return o, nil
+}
+
+// overdraftFor returns the credit a debit of txType may draw on in userID's holding in
+// currency: the overdraft limit for withdrawals in the base currency, else zero. Call
+// it before taking the wallet's mu.
+func (ws *WalletService) overdraftFor(wallet *Wallet, currency string, txType TransactionType) decimal.Decimal {
+	if txType != TransactionWithdraw || currency != wallet.Currency {
+		return decimal.Zero
+	}
+	ws.overdrafts.mu.Lock()
+	defer ws.overdrafts.mu.Unlock()
+	return ws.overdrafts.limits[wallet.UserID]
+}
+
+// markOverdraft records a withdrawal that took the balance from before to after as an
+// overdraft when it drew on credit
+func markOverdraft(tx *Transaction, before, after decimal.Decimal) {
+	drawn := creditUsed(after).Sub(creditUsed(before))
+	if !drawn.IsPositive() {
+		return
+	}
+	tx.Type = TransactionOverdraft
+	tx.Metadata = copyMetadata(tx.Metadata)
+	if tx.Metadata == nil {
+		tx.Metadata = make(map[string]string)
+	}
+	tx.Metadata[metaOverdraftDrawn] = drawn.String()
+}
+
+// creditUsed returns how far balance is below zero
+func creditUsed(balance decimal.Decimal) decimal.Decimal {
+	if balance.IsNegative() {
+		return balance.Neg()
+	}
+	return decimal.Zero
+}
@@ -0,0 +1,104 @@
+// internal/wallet/overdraft_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestOverdraft_Withdrawals(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("postpaid", "Postpaid", "p@example.com")
+	ws.CreateUser("other", "Other", "o@example.com")
+	ws.Deposit("postpaid", 30, "seed")
+	if err := ws.SetOverdraftLimit("postpaid", decimal.NewFromInt(100)); err != nil {
+		t.Fatalf("SetOverdraftLimit() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		op          func() error
+		wantErr     error
+		wantBalance int64
+		wantUsed    int64
+		wantType    TransactionType // type of the last transaction, when recorded
+	}{
+		{"withdraw within funds", func() error { return ws.Withdraw("postpaid", 20, "cash") }, nil, 10, 0, TransactionWithdraw},
+		{"withdraw into overdraft", func() error { return ws.Withdraw("postpaid", 50, "cash") }, nil, -40, 40, TransactionOverdraft},
+		{"transfer cannot overdraw", func() error { return ws.Transfer("postpaid", "other", 1, "gift") }, ErrInsufficientBalance, -40, 40, ""},
+		{"withdraw past limit", func() error { return ws.Withdraw("postpaid", 61, "cash") }, ErrInsufficientBalance, -40, 40, ""},
+		{"deposit repays credit", func() error { return ws.Deposit("postpaid", 25, "salary") }, nil, -15, 15, TransactionDeposit},
+		{"withdraw up to limit", func() error { return ws.Withdraw("postpaid", 85, "cash") }, nil, -100, 100, TransactionOverdraft},
+		{"deposit clears credit", func() error { return ws.Deposit("postpaid", 120, "salary") }, nil, 20, 0, TransactionDeposit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			o, err := ws.GetOverdraft("postpaid")
+			if err != nil {
+				t.Fatalf("GetOverdraft() error = %v", err)
+			}
+			if b, _ := ws.GetBalanceDecimal("postpaid"); !b.Equal(decimal.NewFromInt(tt.wantBalance)) || !o.CreditUsed.Equal(decimal.NewFromInt(tt.wantUsed)) {
+				t.Errorf("balance = %s, credit used = %s; want %d, %d", b, o.CreditUsed, tt.wantBalance, tt.wantUsed)
+			}
+			if !o.CreditAvailable.Equal(decimal.NewFromInt(100 - tt.wantUsed)) {
+				t.Errorf("credit available = %s, want %d", o.CreditAvailable, 100-tt.wantUsed)
+			}
+			if tt.wantType != "" {
+				history, _ := ws.GetTransactionHistory("postpaid")
+				if last := history[len(history)-1]; last.Type != tt.wantType {
+					t.Errorf("last transaction type = %s, want %s", last.Type, tt.wantType)
+				}
+			}
+		})
+	}
+
+	history, _ := ws.GetTransactionHistory("postpaid")
+	if drawn := history[2].Metadata[metaOverdraftDrawn]; history[2].Type != TransactionOverdraft || drawn != "40" {
+		t.Errorf("overdraft withdrawal = %s drawing %q, want 40 drawn", history[2].Type, drawn)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+}
+
+func TestOverdraft_Settings(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("postpaid", "Postpaid", "p@example.com")
+
+	if err := ws.SetOverdraftLimit("postpaid", decimal.NewFromInt(-1)); err != ErrInvalidOverdraftLimit {
+		t.Errorf("SetOverdraftLimit(-1) error = %v, want %v", err, ErrInvalidOverdraftLimit)
+	}
+	if err := ws.SetOverdraftLimit("ghost", decimal.NewFromInt(10)); err != ErrUserNotFound {
+		t.Errorf("SetOverdraftLimit(ghost) error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	ws.SetOverdraftLimit("postpaid", decimal.NewFromInt(50))
+	ws.Withdraw("postpaid", 40, "cash")
+
+	// A lowered limit stops further drawing without calling in the credit used
+	ws.SetOverdraftLimit("postpaid", decimal.NewFromInt(30))
+	if err := ws.Withdraw("postpaid", 1, "cash"); err != ErrInsufficientBalance {
+		t.Errorf("Withdraw() past lowered limit error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	o, _ := ws.GetOverdraft("postpaid")
+	if !o.Limit.Equal(decimal.NewFromInt(30)) || !o.CreditUsed.Equal(decimal.NewFromInt(40)) || !o.CreditAvailable.IsZero() {
+		t.Errorf("GetOverdraft() = %+v, want limit 30, 40 used, none available", o)
+	}
+
+	// Held funds count against the line
+	ws.SetOverdraftLimit("postpaid", decimal.Zero)
+	ws.Deposit("postpaid", 60, "salary")
+	ws.SetOverdraftLimit("postpaid", decimal.NewFromInt(50))
+	ws.Hold("postpaid", decimal.NewFromInt(20))
+	if o, _ := ws.GetOverdraft("postpaid"); !o.CreditAvailable.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("credit available with a covered hold = %s, want 50", o.CreditAvailable)
+	}
+	ws.Withdraw("postpaid", 30, "cash")
+	if o, _ := ws.GetOverdraft("postpaid"); !o.CreditUsed.Equal(decimal.NewFromInt(10)) || !o.CreditAvailable.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("GetOverdraft() = %+v, want 10 used, 20 available", o)
+	}
+}
@@ -0,0 +1,309 @@
+// internal/wallet/paymentlinks.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for payment links
+var (
+	ErrPaymentLinkNotFound = errors.New("payment link not found")
+	ErrPaymentLinkInactive = errors.New("payment link is paid, expired or cancelled")
+	ErrPaymentLinkAmount   = errors.New("amount does not match the payment link")
+	ErrInvalidPaymentLink  = errors.New("invalid payment link")
+)
+
+// DefaultPaymentLinkURL prefixes link tokens unless WithPaymentLinkBaseURL sets another
+const DefaultPaymentLinkURL = "wallet://pay/"
+
+// PaymentLinkStatus is the lifecycle state of a payment link
+type PaymentLinkStatus string
+
+const (
+	PaymentLinkActive    PaymentLinkStatus = "active"
+	PaymentLinkPaid      PaymentLinkStatus = "paid"
+	PaymentLinkExpired   PaymentLinkStatus = "expired"
+	PaymentLinkCancelled PaymentLinkStatus = "cancelled"
+)
+
+// PaymentLinkUsage says whether a link can be paid once or many times
+type PaymentLinkUsage string
+
+const (
+	PaymentLinkOneTime  PaymentLinkUsage = "one_time"
+	PaymentLinkReusable PaymentLinkUsage = "reusable"
+)
+
+// LinkPayment is one payment made through a link
+type LinkPayment struct {
+	PayerID       string
+	Amount        decimal.Decimal
+	TransactionID string
+	PaidAt        int64
+}
+
+// PaymentLink is a shareable request for money. A zero Amount lets the payer choose.
+type PaymentLink struct {
+	ID          string
+	Token       string // secret part of URL; anyone holding it can pay
+	URL         string
+	RecipientID string
+	Amount      decimal.Decimal
+	Description string
+	Usage       PaymentLinkUsage
+	Status      PaymentLinkStatus
+	ExpiresAt   int64 // 0 means the link never expires
+	CreatedAt   int64
+	Payments    []LinkPayment
+}
+
+// paymentLinkBook holds payment links
+type paymentLinkBook struct {
+	mu      sync.Mutex
+	baseURL string
+	links   map[string]*PaymentLink
+	byToken map[string]string // token -> link ID
+}
+
+// WithPaymentLinkBaseURL sets the prefix payment link tokens are appended to
+func WithPaymentLinkBaseURL(baseURL string) Option {
+	return func(ws *WalletService) {
+		ws.paymentLinks.baseURL = baseURL
+	}
+}
+
+// CreatePaymentLink creates a link anyone can use to pay recipientID. A zero amount
+// lets the payer choose; a zero expiry keeps the link open until it is paid or
+// cancelled.
+func (ws *WalletService) CreatePaymentLink(recipientID string, amount decimal.Decimal, description string, expiry time.Duration, usage PaymentLinkUsage) (*PaymentLink, error) {
+	if amount.IsNegative() {
+		return nil, ErrInvalidAmount
+	}
+	if expiry < 0 || (usage != PaymentLinkOneTime && usage != PaymentLinkReusable) {
+		return nil, ErrInvalidPaymentLink
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.users[recipientID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	now := ws.now()
+	link := &PaymentLink{
+		ID:          ws.newID("paylink"),
+		Token:       ws.newToken(),
+		RecipientID: recipientID,
+		Amount:      amount,
+		Description: description,
+		Usage:       usage,
+		Status:      PaymentLinkActive,
+		CreatedAt:   now.Unix(),
+	}
+	if expiry > 0 {
+		link.ExpiresAt = now.Add(expiry).Unix()
+	}
+
+	ws.paymentLinks.mu.Lock()
+	defer ws.paymentLinks.mu.Unlock()
+
+	if ws.paymentLinks.links == nil {
+		ws.paymentLinks.links = make(map[string]*PaymentLink)
+		ws.paymentLinks.byToken = make(map[string]string)
+	}
+	baseURL := ws.paymentLinks.baseURL
+	if baseURL == "" {
+		baseURL = DefaultPaymentLinkURL
+	}
+	link.URL = baseURL + link.Token
+	ws.paymentLinks.links[link.ID] = link
+	ws.paymentLinks.byToken[link.Token] = link.ID
+
+	return link.copy(), nil
+}
+
+// ResolvePaymentLink returns the link behind a token or URL so the payer can see what
+// they are paying
+func (ws *WalletService) ResolvePaymentLink(tokenOrURL string) (*PaymentLink, error) {
+	ws.paymentLinks.mu.Lock()
+	defer ws.paymentLinks.mu.Unlock()
+
+	link, err := ws.paymentLinks.lookup(tokenOrURL)
+	if err != nil {
+		return nil, err
+	}
+	ws.expirePaymentLink(link)
+	return link.copy(), nil
+}
+
+// PayPaymentLink pays a link from payerID. For fixed-amount links amount may be zero or
+// must equal the link amount; open links need a positive amount. One-time links are
+// paid once; reusable links accept payments until they expire or are cancelled.
+func (ws *WalletService) PayPaymentLink(tokenOrURL, payerID string, amount decimal.Decimal) (*Transaction, error) {
+	return ws.PayPaymentLinkContext(context.Background(), tokenOrURL, payerID, amount)
+}
+
+// PayPaymentLinkContext is PayPaymentLink on behalf of a caller's ctx
+func (ws *WalletService) PayPaymentLinkContext(ctx context.Context, tokenOrURL, payerID string, amount decimal.Decimal) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Claim a one-time link before moving money so concurrent payers cannot both pay it
+	ws.paymentLinks.mu.Lock()
+	link, err := ws.paymentLinks.lookup(tokenOrURL)
+	if err != nil {
+		ws.paymentLinks.mu.Unlock()
+		return nil, err
+	}
+	ws.expirePaymentLink(link)
+	if link.Status != PaymentLinkActive {
+		ws.paymentLinks.mu.Unlock()
+		return nil, ErrPaymentLinkInactive
+	}
+	switch {
+	case link.Amount.IsPositive() && amount.IsZero():
+		amount = link.Amount
+	case link.Amount.IsPositive() && !amount.Equal(link.Amount):
+		ws.paymentLinks.mu.Unlock()
+		return nil, ErrPaymentLinkAmount
+	case !amount.IsPositive():
+		ws.paymentLinks.mu.Unlock()
+		return nil, ErrInvalidAmount
+	}
+	if link.Usage == PaymentLinkOneTime {
+		link.Status = PaymentLinkPaid
+	}
+	l := *link
+	ws.paymentLinks.mu.Unlock()
+
+	tx, err := ws.transfer(ctx, payerID, l.RecipientID, amount, paymentLinkDescription(l.Description), transferOptions{
+		metadata: map[string]string{"payment_link": l.ID},
+	})
+	if err != nil && !errors.Is(err, ErrTransferHeld) {
+		if l.Usage == PaymentLinkOneTime {
+			ws.paymentLinks.mu.Lock()
+			link.Status = PaymentLinkActive
+			ws.paymentLinks.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	ws.paymentLinks.mu.Lock()
+	link.Payments = append(link.Payments, LinkPayment{
+		PayerID:       payerID,
+		Amount:        amount,
+		TransactionID: tx.ID,
+		PaidAt:        tx.Timestamp,
+	})
+	ws.paymentLinks.mu.Unlock()
+
+	ws.metrics.IncCounter("payment_link_payments_total", map[string]string{"usage": string(l.Usage)})
+	ws.notify(Notification{
+		UserID:  l.RecipientID,
+		Type:    "payment_link_paid",
+		Subject: "Payment received",
+		Message: paymentLinkDescription(l.Description),
+		Data: map[string]string{
+			"payment_link": l.ID,
+			"payer_id":     payerID,
+			"amount":       amount.String(),
+		},
+		Timestamp: ws.now().Unix(),
+	})
+	return tx, err
+}
+
+// CancelPaymentLink stops a link from accepting payments
+func (ws *WalletService) CancelPaymentLink(linkID, recipientID string) error {
+	ws.paymentLinks.mu.Lock()
+	defer ws.paymentLinks.mu.Unlock()
+
+	link, exists := ws.paymentLinks.links[linkID]
+	if !exists || link.RecipientID != recipientID {
+		return ErrPaymentLinkNotFound
+	}
+	ws.expirePaymentLink(link)
+	if link.Status != PaymentLinkActive {
+		return ErrPaymentLinkInactive
+	}
+	link.Status = PaymentLinkCancelled
+	return nil
+}
+
+// GetPaymentLink returns a payment link by ID
+func (ws *WalletService) GetPaymentLink(linkID string) (*PaymentLink, error) {
+	ws.paymentLinks.mu.Lock()
+	defer ws.paymentLinks.mu.Unlock()
+
+	link, exists := ws.paymentLinks.links[linkID]
+	if !exists {
+		return nil, ErrPaymentLinkNotFound
+	}
+	ws.expirePaymentLink(link)
+	return link.copy(), nil
+}
+
+// ListPaymentLinks returns the links created for recipientID, oldest first
+func (ws *WalletService) ListPaymentLinks(recipientID string) []PaymentLink {
+	ws.paymentLinks.mu.Lock()
+	defer ws.paymentLinks.mu.Unlock()
+
+	var out []PaymentLink
+	for _, link := range ws.paymentLinks.links {
+		if link.RecipientID == recipientID {
+			ws.expirePaymentLink(link)
+			out = append(out, *link.copy())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// expirePaymentLink marks an active link past its expiry as expired. Callers hold
+// ws.paymentLinks.mu.
+func (ws *WalletService) expirePaymentLink(link *PaymentLink) {
+	if link.Status == PaymentLinkActive && link.ExpiresAt != 0 && ws.now().Unix() >= link.ExpiresAt {
+		link.Status = PaymentLinkExpired
+	}
+}
+
+// lookup finds a link by token or by a URL ending in its token. Callers hold b.mu.
+func (b *paymentLinkBook) lookup(tokenOrURL string) (*PaymentLink, error) {
+	token := tokenOrURL
+	if i := strings.LastIndexAny(token, "/="); i >= 0 {
+		token = token[i+1:]
+	}
+	id, exists := b.byToken[token]
+	if !exists {
+		return nil, ErrPaymentLinkNotFound
+	}
+	return b.links[id], nil
+}
+
+// paymentLinkDescription builds the transaction description for a link payment
+func paymentLinkDescription(description string) string {
+	if description == "" {
+		return "payment link"
+	}
+	return "payment link: " + description
+}
+
+// copy returns a copy safe to hand to callers. Callers hold ws.paymentLinks.mu.
+func (l *PaymentLink) copy() *PaymentLink {
+	copied := *l
+	copied.Payments = append([]LinkPayment(nil), l.Payments...)
+	return &copied
+}
@@ -0,0 +1,148 @@
+// internal/wallet/paymentlinks_test.go
+package wallet
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPaymentLink_OneTimeFixedAmount(t *testing.T) {
+	ws, _ := newTestService(WithPaymentLinkBaseURL("https://pay.example.com/l/"))
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Deposit("bob", 100, "seed")
+
+	link, err := ws.CreatePaymentLink("shop", decimal.NewFromInt(25), "order 17", time.Hour, PaymentLinkOneTime)
+	if err != nil {
+		t.Fatalf("CreatePaymentLink() error = %v", err)
+	}
+	if link.URL != "https://pay.example.com/l/"+link.Token || link.Status != PaymentLinkActive {
+		t.Fatalf("link = %+v", link)
+	}
+
+	tests := []struct {
+		name    string
+		payer   string
+		amount  int64
+		wantErr error
+	}{
+		{"wrong amount", "alice", 20, ErrPaymentLinkAmount},
+		{"zero amount pays the link amount", "alice", 0, nil},
+		{"already paid", "bob", 25, ErrPaymentLinkInactive},
+	}
+	for _, tt := range tests {
+		_, err := ws.PayPaymentLink(link.URL, tt.payer, decimal.NewFromInt(tt.amount))
+		if err != tt.wantErr {
+			t.Errorf("%s: PayPaymentLink() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	got, _ := ws.GetPaymentLink(link.ID)
+	if got.Status != PaymentLinkPaid || len(got.Payments) != 1 || got.Payments[0].PayerID != "alice" {
+		t.Errorf("link after payment = %+v", got)
+	}
+	if b, _ := ws.GetBalanceDecimal("shop"); !b.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("shop balance = %s, want 25", b)
+	}
+	tx, _ := ws.GetTransaction(got.Payments[0].TransactionID)
+	if tx.Metadata["payment_link"] != link.ID || !strings.Contains(tx.Description, "order 17") {
+		t.Errorf("payment transaction = %+v", tx)
+	}
+}
+
+func TestPaymentLink_FailedPaymentReopensLink(t *testing.T) {
+	ws, _ := newTestService(WithPaymentLinkBaseURL("https://pay.example.com/l/"))
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Deposit("bob", 100, "seed")
+	link, _ := ws.CreatePaymentLink("shop", decimal.NewFromInt(150), "", 0, PaymentLinkOneTime)
+
+	if _, err := ws.PayPaymentLink(link.Token, "alice", decimal.Zero); err != ErrInsufficientBalance {
+		t.Fatalf("PayPaymentLink() error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if got, _ := ws.ResolvePaymentLink(link.Token); got.Status != PaymentLinkActive {
+		t.Errorf("status after failed payment = %s, want active", got.Status)
+	}
+}
+
+func TestPaymentLink_ReusablePayerChosenAmount(t *testing.T) {
+	ws, clock := newTestService(WithPaymentLinkBaseURL("https://pay.example.com/l/"))
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Deposit("bob", 100, "seed")
+
+	link, _ := ws.CreatePaymentLink("shop", decimal.Zero, "tips", 24*time.Hour, PaymentLinkReusable)
+	if _, err := ws.PayPaymentLink(link.Token, "alice", decimal.Zero); err != ErrInvalidAmount {
+		t.Errorf("open link without an amount error = %v, want %v", err, ErrInvalidAmount)
+	}
+	ws.PayPaymentLink(link.Token, "alice", decimal.NewFromInt(3))
+	ws.PayPaymentLink(link.Token, "bob", decimal.NewFromInt(7))
+
+	if got, _ := ws.GetPaymentLink(link.ID); got.Status != PaymentLinkActive || len(got.Payments) != 2 {
+		t.Errorf("reusable link = %+v, want active with two payments", got)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if _, err := ws.PayPaymentLink(link.Token, "bob", decimal.NewFromInt(1)); err != ErrPaymentLinkInactive {
+		t.Errorf("expired link error = %v, want %v", err, ErrPaymentLinkInactive)
+	}
+	if got, _ := ws.GetPaymentLink(link.ID); got.Status != PaymentLinkExpired {
+		t.Errorf("status = %s, want expired", got.Status)
+	}
+	if b, _ := ws.GetBalanceDecimal("shop"); !b.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("shop balance = %s, want 10", b)
+	}
+}
+
+func TestPaymentLink_CancelAndValidation(t *testing.T) {
+	ws, _ := newTestService(WithPaymentLinkBaseURL("https://pay.example.com/l/"))
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Deposit("bob", 100, "seed")
+	link, _ := ws.CreatePaymentLink("shop", decimal.Zero, "", 0, PaymentLinkReusable)
+
+	if err := ws.CancelPaymentLink(link.ID, "alice"); err != ErrPaymentLinkNotFound {
+		t.Errorf("cancel by another user error = %v, want %v", err, ErrPaymentLinkNotFound)
+	}
+	if err := ws.CancelPaymentLink(link.ID, "shop"); err != nil {
+		t.Fatalf("CancelPaymentLink() error = %v", err)
+	}
+	if _, err := ws.PayPaymentLink(link.Token, "alice", decimal.NewFromInt(1)); err != ErrPaymentLinkInactive {
+		t.Errorf("cancelled link error = %v, want %v", err, ErrPaymentLinkInactive)
+	}
+	if links := ws.ListPaymentLinks("shop"); len(links) != 1 || links[0].Status != PaymentLinkCancelled {
+		t.Errorf("ListPaymentLinks() = %+v", links)
+	}
+
+	tests := []struct {
+		recipient string
+		amount    int64
+		expiry    time.Duration
+		usage     PaymentLinkUsage
+		wantErr   error
+	}{
+		{"ghost", 1, 0, PaymentLinkOneTime, ErrUserNotFound},
+		{"shop", -1, 0, PaymentLinkOneTime, ErrInvalidAmount},
+		{"shop", 1, -time.Hour, PaymentLinkOneTime, ErrInvalidPaymentLink},
+		{"shop", 1, 0, "forever", ErrInvalidPaymentLink},
+	}
+	for _, tt := range tests {
+		if _, err := ws.CreatePaymentLink(tt.recipient, decimal.NewFromInt(tt.amount), "", tt.expiry, tt.usage); err != tt.wantErr {
+			t.Errorf("CreatePaymentLink(%s, %d, %v, %s) error = %v, want %v", tt.recipient, tt.amount, tt.expiry, tt.usage, err, tt.wantErr)
+		}
+	}
+	if _, err := ws.ResolvePaymentLink("https://pay.example.com/l/unknown"); err != ErrPaymentLinkNotFound {
+		t.Errorf("unknown token error = %v, want %v", err, ErrPaymentLinkNotFound)
+	}
+}
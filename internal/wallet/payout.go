@@ -0,0 +1,276 @@
+// internal/wallet/payout.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+// Error definitions for prize pool distribution
+var (
+	ErrNoParticipants = errors.New("no eligible participants")
+	ErrInvalidWeight  = errors.New("weights must be non-negative with a positive total")
+)
+
+// Payout is a single credit produced by a distribution
+type Payout struct {
+	UserID string
+	Amount decimal.Decimal
+}
+
+// AllocateByWeight splits pool among participants in proportion to their weights.
+// Shares are truncated to places decimals and the leftover units go to the largest
+// fractional remainders, so the payouts always sum to exactly pool. Ties between equal
+// remainders are broken by rng, making the result reproducible for a given seed.
+func AllocateByWeight(pool decimal.Decimal, weights map[string]decimal.Decimal, places int32, rng *rand.Rand) ([]Payout, error) {
+	if pool.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	ids := make([]string, 0, len(weights))
+	total := decimal.Zero
+	for id, w := range weights {
+		if w.IsNegative() {
+			return nil, ErrInvalidWeight
+		}
+		if w.IsPositive() {
+			ids = append(ids, id)
+			total = total.Add(w)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoParticipants
+	}
+	sort.Strings(ids)
+
+	// Shuffle before allocating so equal remainders, which go to earlier weights,
+	// are ordered by the seeded rng
+	if rng != nil {
+		rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	}
+	ordered := make([]decimal.Decimal, len(ids))
+	for i, id := range ids {
+		ordered[i] = weights[id]
+	}
+	shares, err := moneymath.Allocate(pool, ordered, places)
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := make([]Payout, 0, len(shares))
+	for i, amount := range shares {
+		if amount.IsPositive() {
+			payouts = append(payouts, Payout{UserID: ids[i], Amount: amount})
+		}
+	}
+	sort.Slice(payouts, func(i, j int) bool { return payouts[i].UserID < payouts[j].UserID })
+
+	return payouts, nil
+}
+
+// DrawRaffle draws up to winners distinct participants, each draw weighted by ticket count,
+// and splits pool evenly among them. Indivisible units go to the earliest drawn winners.
+// Publishing the seed lets anyone re-run the draw and verify the result.
+func DrawRaffle(pool decimal.Decimal, tickets map[string]int64, winners int, places int32, seed int64) ([]Payout, error) {
+	if pool.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	if winners <= 0 {
+		return nil, ErrNoParticipants
+	}
+
+	ids := make([]string, 0, len(tickets))
+	var total int64
+	for id, n := range tickets {
+		if n < 0 {
+			return nil, ErrInvalidWeight
+		}
+		if n > 0 {
+			ids = append(ids, id)
+			total += n
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoParticipants
+	}
+	sort.Strings(ids)
+
+	rng := rand.New(rand.NewSource(seed))
+	var drawn []string
+	for len(drawn) < winners && total > 0 {
+		pick := rng.Int63n(total)
+		for i, id := range ids {
+			if pick < tickets[id] {
+				drawn = append(drawn, id)
+				total -= tickets[id]
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+			pick -= tickets[id]
+		}
+	}
+
+	shares, err := moneymath.Split(pool, len(drawn), places)
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := make([]Payout, len(drawn))
+	for i, id := range drawn {
+		payouts[i] = Payout{UserID: id, Amount: shares[i]}
+	}
+
+	return payouts, nil
+}
+
+// BatchPayout debits fromUserID once and credits every payout atomically: either all
+// legs are applied or none are. One transfer transaction is recorded per leg.
+func (ws *WalletService) BatchPayout(fromUserID string, payouts []Payout, description string) ([]*Transaction, error) {
+	return ws.BatchPayoutContext(context.Background(), fromUserID, payouts, description)
+}
+
+// BatchPayoutContext is BatchPayout on behalf of a caller's ctx
+func (ws *WalletService) BatchPayoutContext(ctx context.Context, fromUserID string, payouts []Payout, description string) (txs []*Transaction, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	timer := ws.startOp("batch_payout", fromUserID)
+	defer func() { timer.finish(err) }()
+
+	return ws.batchTransfer(ctx, timer, fromUserID, payouts, description, nil)
+}
+
+// batchTransfer applies the legs of a batch under the locks of every party. metadata,
+// when set, holds the annotations of each leg in payout order.
+func (ws *WalletService) batchTransfer(ctx context.Context, timer *opTimer, fromUserID string, payouts []Payout, description string, metadata []map[string]string) (txs []*Transaction, err error) {
+	defer func() {
+		if err != nil {
+			ids := []string{fromUserID}
+			for _, p := range payouts {
+				ids = append(ids, p.UserID)
+			}
+			ws.audit(ctx, timer.name, nil, err, ids...)
+			return
+		}
+		for _, tx := range txs {
+			ws.audit(ctx, timer.name, tx, nil, tx.FromUserID, tx.ToUserID)
+		}
+	}()
+
+	if len(payouts) == 0 {
+		return nil, ErrNoParticipants
+	}
+
+	total := decimal.Zero
+	userIDs := []string{fromUserID}
+	for _, p := range payouts {
+		if p.Amount.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrInvalidAmount
+		}
+		if p.UserID == fromUserID {
+			return nil, ErrSameUserTransfer
+		}
+		total = total.Add(p.Amount)
+		userIDs = append(userIDs, p.UserID)
+	}
+
+	unlock := ws.lockUsers(PriorityBatch, userIDs...)
+	defer unlock()
+	timer.locked()
+
+	ws.mu.RLock()
+	fromWallet, exists := ws.wallets[fromUserID]
+	wallets := make([]*Wallet, len(payouts))
+	for i, p := range payouts {
+		if wallets[i] = ws.wallets[p.UserID]; wallets[i] == nil {
+			exists = false
+		}
+	}
+	ws.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	if err := ws.checkAmount(fromWallet.Currency, total, true); err != nil {
+		return nil, err
+	}
+	for i, p := range payouts {
+		if wallets[i].Currency != fromWallet.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+		if ws.IsBlocked(p.UserID, fromUserID) {
+			return nil, ErrCounterpartyBlocked
+		}
+	}
+
+	txs = make([]*Transaction, len(payouts))
+	for i, p := range payouts {
+		txs[i] = &Transaction{
+			ID:          ws.newID("tx"),
+			FromUserID:  fromUserID,
+			ToUserID:    p.UserID,
+			Amount:      p.Amount,
+			Currency:    fromWallet.Currency,
+			Type:        TransactionTransfer,
+			Description: description,
+		}
+		if metadata != nil {
+			txs[i].Metadata = copyMetadata(metadata[i])
+		}
+		stampTrace(ctx, txs[i])
+		if err := ws.validate(txs[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := ws.checkBatchSpending(txs); err != nil {
+		return nil, err
+	}
+
+	// Every leg must fit under its recipient's cap before any balance moves; legs to the
+	// same recipient count together
+	incoming := make(map[*Wallet]decimal.Decimal, len(payouts))
+	for i, p := range payouts {
+		incoming[wallets[i]] = incoming[wallets[i]].Add(p.Amount)
+		maximum := ws.maximumFor(p.UserID, fromWallet.Currency, TransactionTransfer)
+		wallets[i].mu.RLock()
+		err := wallets[i].checkMaximum(fromWallet.Currency, incoming[wallets[i]], maximum)
+		wallets[i].mu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	minimum := ws.minimumFor(fromUserID, fromWallet.Currency, TransactionTransfer)
+	fromWallet.mu.Lock()
+	if fromWallet.available(fromWallet.Currency).LessThan(total) {
+		fromWallet.mu.Unlock()
+		return nil, ErrInsufficientBalance
+	}
+	if err := fromWallet.checkMinimum(fromWallet.Currency, total, decimal.Zero, minimum); err != nil {
+		fromWallet.mu.Unlock()
+		return nil, err
+	}
+	fromWallet.Balance = fromWallet.Balance.Sub(total)
+	fromWallet.publish()
+	fromWallet.mu.Unlock()
+
+	for i, p := range payouts {
+		wallets[i].mu.Lock()
+		wallets[i].Balance = wallets[i].Balance.Add(p.Amount)
+		wallets[i].publish()
+		wallets[i].mu.Unlock()
+
+		txs[i].Timestamp = ws.now().Unix()
+		ws.recordTransaction(txs[i])
+		ws.reserveCredit(wallets[i], txs[i])
+	}
+
+	return txs, nil
+}
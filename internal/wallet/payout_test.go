@@ -0,0 +1,144 @@
+// internal/wallet/payout_test.go
+package wallet
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// sumPayouts totals the amounts of a distribution
+func sumPayouts(payouts []Payout) decimal.Decimal {
+	total := decimal.Zero
+	for _, p := range payouts {
+		total = total.Add(p.Amount)
+	}
+	return total
+}
+
+// TestAllocateByWeight tests proportional splits that always sum to the pool
+func TestAllocateByWeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		pool    string
+		weights map[string]int64
+		want    map[string]string
+	}{
+		{
+			name:    "three equal weights",
+			pool:    "100",
+			weights: map[string]int64{"a": 1, "b": 1, "c": 1},
+		},
+		{
+			name:    "uneven weights",
+			pool:    "10",
+			weights: map[string]int64{"a": 1, "b": 2},
+			want:    map[string]string{"a": "3.33", "b": "6.67"},
+		},
+		{
+			name:    "zero weight excluded",
+			pool:    "1",
+			weights: map[string]int64{"a": 0, "b": 3},
+			want:    map[string]string{"b": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights := make(map[string]decimal.Decimal)
+			for id, w := range tt.weights {
+				weights[id] = decimal.NewFromInt(w)
+			}
+			pool := decimal.RequireFromString(tt.pool)
+
+			payouts, err := AllocateByWeight(pool, weights, 2, rand.New(rand.NewSource(7)))
+			if err != nil {
+				t.Fatalf("AllocateByWeight() error = %v", err)
+			}
+			if !sumPayouts(payouts).Equal(pool) {
+				t.Errorf("Payouts sum to %s, want %s", sumPayouts(payouts).String(), tt.pool)
+			}
+			for _, p := range payouts {
+				if want, ok := tt.want[p.UserID]; ok && !p.Amount.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("%s got %s, want %s", p.UserID, p.Amount.String(), want)
+				}
+			}
+		})
+	}
+
+	if _, err := AllocateByWeight(decimal.NewFromInt(1), map[string]decimal.Decimal{"a": decimal.Zero}, 2, nil); err != ErrNoParticipants {
+		t.Errorf("Expected ErrNoParticipants, got %v", err)
+	}
+	if _, err := AllocateByWeight(decimal.NewFromInt(1), map[string]decimal.Decimal{"a": decimal.NewFromInt(-1)}, 2, nil); err != ErrInvalidWeight {
+		t.Errorf("Expected ErrInvalidWeight, got %v", err)
+	}
+}
+
+// TestDrawRaffle tests that draws are reproducible per seed and conserve the pool
+func TestDrawRaffle(t *testing.T) {
+	tickets := map[string]int64{"a": 1, "b": 5, "c": 10, "d": 0}
+	pool := decimal.NewFromInt(100)
+
+	first, err := DrawRaffle(pool, tickets, 3, 2, 99)
+	if err != nil {
+		t.Fatalf("DrawRaffle() error = %v", err)
+	}
+	second, _ := DrawRaffle(pool, tickets, 3, 2, 99)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Same seed produced different draws: %+v vs %+v", first, second)
+	}
+	if len(first) != 3 || !sumPayouts(first).Equal(pool) {
+		t.Errorf("Expected 3 winners sharing 100, got %+v", first)
+	}
+	for _, p := range first {
+		if p.UserID == "d" {
+			t.Error("Participant without tickets was drawn")
+		}
+	}
+	if !first[0].Amount.Equal(decimal.RequireFromString("33.34")) {
+		t.Errorf("Expected first winner to receive the remainder, got %s", first[0].Amount.String())
+	}
+}
+
+// TestWalletService_BatchPayout tests that multi-recipient payouts are all-or-nothing
+func TestWalletService_BatchPayout(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("pool", "Prize Pool", "pool@example.com")
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("pool", 100.0, "funding")
+
+	payouts, _ := AllocateByWeight(decimal.NewFromInt(100), map[string]decimal.Decimal{
+		"user1": decimal.NewFromInt(1),
+		"user2": decimal.NewFromInt(3),
+	}, 2, nil)
+
+	txs, err := ws.BatchPayout("pool", payouts, "prize")
+	if err != nil {
+		t.Fatalf("BatchPayout() error = %v", err)
+	}
+	if len(txs) != 2 {
+		t.Errorf("Expected 2 transactions, got %d", len(txs))
+	}
+
+	balance, _ := ws.GetBalanceDecimal("user2")
+	if !balance.Equal(decimal.NewFromInt(75)) {
+		t.Errorf("Expected user2 balance 75, got %s", balance.String())
+	}
+
+	// Pool is now empty: the whole batch must fail without partial credits
+	_, err = ws.BatchPayout("pool", []Payout{{"user1", decimal.NewFromInt(1)}}, "overdraw")
+	if err != ErrInsufficientBalance {
+		t.Errorf("Expected ErrInsufficientBalance, got %v", err)
+	}
+	_, err = ws.BatchPayout("user2", []Payout{{"user1", decimal.NewFromInt(1)}, {"ghost", decimal.NewFromInt(1)}}, "bad")
+	if err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	balance, _ = ws.GetBalanceDecimal("user1")
+	if !balance.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("Expected user1 balance unchanged at 25, got %s", balance.String())
+	}
+}
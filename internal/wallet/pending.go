@@ -0,0 +1,300 @@
+// internal/wallet/pending.go
+package wallet
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// PendingKind names the subsystem a pending item comes from
+type PendingKind string
+
+// Pending item kinds, in the order GetPendingItems returns them
+const (
+	PendingComplianceHold   PendingKind = "compliance_hold"
+	PendingCardHold         PendingKind = "card_hold"
+	PendingHold             PendingKind = "authorization_hold"
+	PendingReservation      PendingKind = "order_reservation"
+	PendingReserve          PendingKind = "reserve"
+	PendingApproval         PendingKind = "approval" // expense waiting for this user's review
+	PendingExpense          PendingKind = "expense"  // expense this user submitted or is paid by
+	PendingScheduledPayment PendingKind = "scheduled_payment"
+	PendingGift             PendingKind = "gift"
+	PendingFederated        PendingKind = "federated_transfer"
+	PendingRail             PendingKind = "rail_transfer"
+	PendingTransaction      PendingKind = "pending_transaction" // awaiting external confirmation
+	PendingPaymentRequest   PendingKind = "payment_request"
+)
+
+// pendingKindOrder ranks kinds for GetPendingItems
+var pendingKindOrder = map[PendingKind]int{
+	PendingComplianceHold:   0,
+	PendingCardHold:         1,
+	PendingHold:             2,
+	PendingReservation:      3,
+	PendingReserve:          4,
+	PendingApproval:         5,
+	PendingExpense:          6,
+	PendingScheduledPayment: 7,
+	PendingGift:             8,
+	PendingFederated:        9,
+	PendingRail:             10,
+	PendingTransaction:      11,
+	PendingPaymentRequest:   12,
+}
+
+// PendingItem is something affecting a user's money that has not settled yet
+type PendingItem struct {
+	Kind           PendingKind
+	ID             string // ID of the case, authorization, job, gift, link etc.
+	Amount         decimal.Decimal
+	Currency       string
+	Incoming       bool   // money will arrive in the user's wallet if it completes
+	CounterpartyID string // other user, merchant or external account, when known
+	Description    string
+	Since          int64 // when the item became pending; 0 when unknown
+	Until          int64 // expiry, release or next run; 0 when open-ended
+}
+
+// scheduledPayment describes the transfer behind a "payment" job
+type scheduledPayment struct {
+	fromUserID  string
+	toUserID    string
+	amount      decimal.Decimal
+	description string
+}
+
+// GetPendingItems lists everything affecting userID that is not a settled transaction:
+// compliance, card and authorization holds, order reservations, rolling reserves,
+// expense approvals, scheduled payments and gifts, unsettled federated and rail
+// transfers, transactions awaiting confirmation and open payment links. Items are
+// grouped by kind and ordered by Since within a kind.
+func (ws *WalletService) GetPendingItems(userID string) ([]PendingItem, error) {
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	var items []PendingItem
+	items = append(items, ws.pendingHolds(userID)...)
+	items = append(items, ws.pendingExpenses(userID)...)
+	items = append(items, ws.pendingSchedules(userID)...)
+	items = append(items, ws.pendingTransfers(userID)...)
+	items = append(items, ws.pendingTransactions(userID)...)
+
+	for i := range items {
+		if items[i].Currency == "" {
+			items[i].Currency = wallet.Currency
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Kind != b.Kind {
+			return pendingKindOrder[a.Kind] < pendingKindOrder[b.Kind]
+		}
+		if a.Since != b.Since {
+			return a.Since < b.Since
+		}
+		return a.ID < b.ID
+	})
+	return items, nil
+}
+
+// pendingHolds returns the user's open compliance cases, card authorizations, holds,
+// order reservations and reserves
+func (ws *WalletService) pendingHolds(userID string) []PendingItem {
+	var items []PendingItem
+
+	ws.compliance.mu.Lock()
+	for _, c := range ws.compliance.cases {
+		if c.Status != CaseOpen || (c.UserID != userID && c.CounterpartyID != userID) {
+			continue
+		}
+		incoming := c.CounterpartyID == userID
+		counterparty := c.CounterpartyID
+		if incoming {
+			counterparty = c.UserID
+		}
+		items = append(items, PendingItem{
+			Kind: PendingComplianceHold, ID: c.ID, Amount: c.Amount, Currency: c.Currency,
+			Incoming: incoming, CounterpartyID: counterparty, Description: "transfer under review",
+			Since: c.OpenedAt,
+		})
+	}
+	ws.compliance.mu.Unlock()
+
+	ws.cards.mu.Lock()
+	for _, a := range ws.cards.auths {
+		if a.UserID == userID && a.Status == CardAuthApproved {
+			items = append(items, PendingItem{
+				Kind: PendingCardHold, ID: a.ID, Amount: a.Amount, CounterpartyID: a.Merchant,
+				Description: "card authorization", Since: a.CreatedAt, Until: a.ExpiresAt,
+			})
+		}
+	}
+	ws.cards.mu.Unlock()
+
+	for _, h := range ws.ListHolds(userID) {
+		items = append(items, PendingItem{
+			Kind: PendingHold, ID: h.ID, Amount: h.Amount, Currency: h.Currency,
+			Description: "authorization hold", Since: h.CreatedAt,
+		})
+	}
+
+	for _, r := range ws.ListReservations(userID) {
+		incoming := r.SellerID == userID
+		counterparty := r.SellerID
+		if incoming {
+			counterparty = r.BuyerID
+		}
+		items = append(items, PendingItem{
+			Kind: PendingReservation, ID: r.ID, Amount: r.Remaining(), Currency: r.Currency,
+			Incoming: incoming, CounterpartyID: counterparty, Description: "order " + r.OrderID,
+			Since: r.CreatedAt, Until: r.Deadline,
+		})
+	}
+
+	ws.reserves.mu.Lock()
+	for _, id := range ws.reserves.byUser[userID] {
+		if e := ws.reserves.entries[id]; e.Status == ReserveHeld {
+			items = append(items, PendingItem{
+				Kind: PendingReserve, ID: e.ID, Amount: e.Amount, Currency: e.Currency, Incoming: true,
+				Description: "rolling reserve", Since: e.HeldAt, Until: e.ReleaseAt,
+			})
+		}
+	}
+	ws.reserves.mu.Unlock()
+
+	return items
+}
+
+// pendingExpenses returns expenses awaiting the user's review and those they submitted
+// or will be paid by
+func (ws *WalletService) pendingExpenses(userID string) []PendingItem {
+	ws.orgs.mu.RLock()
+	defer ws.orgs.mu.RUnlock()
+
+	var items []PendingItem
+	for _, e := range ws.orgs.expenses {
+		if e.Status != ExpensePending {
+			continue
+		}
+		item := PendingItem{
+			ID: e.ID, Amount: e.Amount, CounterpartyID: e.OrgID, Description: e.Description,
+			Since: e.CreatedAt,
+		}
+		step := e.Chain[e.CurrentStep]
+		switch {
+		case userID != e.SubmitterID && ws.orgs.orgs[e.OrgID].Members[userID] == step.Role:
+			item.Kind = PendingApproval
+		case userID == e.PayeeID:
+			item.Kind, item.Incoming = PendingExpense, true
+		case userID == e.SubmitterID:
+			item.Kind = PendingExpense
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// pendingSchedules returns scheduled payments to or from the user and gifts they have
+// not seen through yet
+func (ws *WalletService) pendingSchedules(userID string) []PendingItem {
+	var items []PendingItem
+
+	ws.scheduler.mu.Lock()
+	for _, j := range ws.scheduler.jobs {
+		p := j.payment
+		if p == nil || j.job.Status != JobScheduled || (p.fromUserID != userID && p.toUserID != userID) {
+			continue
+		}
+		incoming := p.toUserID == userID
+		counterparty := p.toUserID
+		if incoming {
+			counterparty = p.fromUserID
+		}
+		items = append(items, PendingItem{
+			Kind: PendingScheduledPayment, ID: j.job.ID, Amount: p.amount, Incoming: incoming,
+			CounterpartyID: counterparty, Description: p.description, Until: j.job.NextRun,
+		})
+	}
+	ws.scheduler.mu.Unlock()
+
+	ws.gifts.mu.Lock()
+	for _, g := range ws.gifts.gifts {
+		if g.SenderID != userID {
+			continue
+		}
+		item := PendingItem{
+			Kind: PendingGift, ID: g.ID, Amount: g.Amount, CounterpartyID: g.Recipient,
+			Description: g.Message,
+		}
+		switch g.Status {
+		case GiftScheduled:
+			item.Until = g.DeliverAt
+		case GiftPendingClaim:
+			item.Since, item.Until = g.DeliverAt, g.ClaimExpiresAt
+		case GiftHeld:
+			item.Since = g.DeliverAt
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	ws.gifts.mu.Unlock()
+
+	return items
+}
+
+// pendingTransfers returns unsettled federated and rail transfers and the user's open
+// payment links
+func (ws *WalletService) pendingTransfers(userID string) []PendingItem {
+	var items []PendingItem
+
+	ws.federation.mu.Lock()
+	for _, t := range ws.federation.outbound {
+		if t.Status == OutboundPending && t.Voucher.FromUserID == userID {
+			v := t.Voucher
+			items = append(items, PendingItem{
+				Kind: PendingFederated, ID: v.ID, Amount: v.Amount, Currency: v.Currency,
+				CounterpartyID: v.TargetInstance + "/" + v.ToUserID, Description: v.Description,
+				Since: v.CreatedAt, Until: v.ExpiresAt,
+			})
+		}
+	}
+	ws.federation.mu.Unlock()
+
+	ws.rails.mu.Lock()
+	for _, rt := range ws.rails.transfers {
+		if rt.Status == RailPending && rt.UserID == userID {
+			items = append(items, PendingItem{
+				Kind: PendingRail, ID: rt.ID, Amount: rt.Amount.Sub(rt.Settled), Currency: rt.Currency,
+				Incoming: rt.Direction == RailDeposit, CounterpartyID: rt.Account,
+				Description: string(rt.Direction), Since: rt.CreatedAt,
+			})
+		}
+	}
+	ws.rails.mu.Unlock()
+
+	ws.paymentLinks.mu.Lock()
+	for _, link := range ws.paymentLinks.links {
+		if link.RecipientID != userID {
+			continue
+		}
+		ws.expirePaymentLink(link)
+		if link.Status == PaymentLinkActive {
+			items = append(items, PendingItem{
+				Kind: PendingPaymentRequest, ID: link.ID, Amount: link.Amount, Incoming: true,
+				Description: link.Description, Since: link.CreatedAt, Until: link.ExpiresAt,
+			})
+		}
+	}
+	ws.paymentLinks.mu.Unlock()
+
+	return items
+}
@@ -0,0 +1,72 @@
+// internal/wallet/pending_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGetPendingItems(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 500, "seed")
+
+	card, _ := ws.IssueCard("alice", "main", CardLimits{}, 365*24*time.Hour)
+	auth, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(20), Merchant: "cafe"})
+	jobID, _ := ws.SchedulePayment("alice", "bob", decimal.NewFromInt(30), "rent", clock.Now().Add(48*time.Hour), nil)
+	gift, _ := ws.ScheduleGift("alice", "carol@example.com", decimal.NewFromInt(5), "hi", clock.Now().Add(time.Hour))
+	link, _ := ws.CreatePaymentLink("alice", decimal.NewFromInt(12), "dinner", 0, PaymentLinkOneTime)
+
+	ws.CreateOrganization("acme", "Acme", "acme@example.com")
+	ws.AddOrgMember("acme", "bob", OrgRoleMember)
+	ws.AddOrgMember("acme", "alice", OrgRoleManager)
+	expense, _ := ws.SubmitExpense("acme", "bob", "bob", decimal.NewFromInt(40), "train")
+
+	tests := []struct {
+		userID string
+		want   []PendingItem
+	}{
+		{"alice", []PendingItem{
+			{Kind: PendingCardHold, ID: auth.ID, Amount: decimal.NewFromInt(20)},
+			{Kind: PendingApproval, ID: expense.ID, Amount: decimal.NewFromInt(40)},
+			{Kind: PendingScheduledPayment, ID: jobID, Amount: decimal.NewFromInt(30)},
+			{Kind: PendingGift, ID: gift.ID, Amount: decimal.NewFromInt(5)},
+			{Kind: PendingPaymentRequest, ID: link.ID, Amount: decimal.NewFromInt(12), Incoming: true},
+		}},
+		{"bob", []PendingItem{
+			{Kind: PendingExpense, ID: expense.ID, Amount: decimal.NewFromInt(40), Incoming: true},
+			{Kind: PendingScheduledPayment, ID: jobID, Amount: decimal.NewFromInt(30), Incoming: true},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := ws.GetPendingItems(tt.userID)
+		if err != nil {
+			t.Fatalf("GetPendingItems(%s) error = %v", tt.userID, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("GetPendingItems(%s) = %+v, want %d items", tt.userID, got, len(tt.want))
+		}
+		for i, want := range tt.want {
+			g := got[i]
+			if g.Kind != want.Kind || g.ID != want.ID || !g.Amount.Equal(want.Amount) || g.Incoming != want.Incoming || g.Currency != DefaultCurrency {
+				t.Errorf("GetPendingItems(%s)[%d] = %+v, want %+v", tt.userID, i, g, want)
+			}
+		}
+	}
+
+	// Settled items drop out of the list
+	ws.CaptureAuthorization(auth.ID, decimal.NewFromInt(20))
+	ws.CancelJob(jobID)
+	ws.CancelGift(gift.ID, "alice")
+	ws.CancelPaymentLink(link.ID, "alice")
+	ws.ReviewExpense(expense.ID, "alice", false, "")
+	if got, _ := ws.GetPendingItems("alice"); len(got) != 0 {
+		t.Errorf("GetPendingItems() after settling = %+v, want none", got)
+	}
+	if _, err := ws.GetPendingItems("ghost"); err != ErrUserNotFound {
+		t.Errorf("GetPendingItems(ghost) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
@@ -0,0 +1,282 @@
+// internal/wallet/preferences.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidPreferences is returned for malformed notification preferences
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+
+// Channel is a delivery medium for user notifications
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelPush  Channel = "push"
+	ChannelSMS   Channel = "sms"
+	ChannelInApp Channel = "in_app"
+)
+
+// NotificationDigest is the notification type of a periodic digest
+const NotificationDigest = "digest"
+
+// QuietHours is a daily window during which instant notifications are held back.
+// Start and End are offsets from local midnight; a window with Start after End spans
+// midnight, and equal offsets disable it.
+type QuietHours struct {
+	Start    time.Duration
+	End      time.Duration
+	TimeZone string // IANA name; empty means UTC
+}
+
+// contains reports whether t falls inside the quiet window
+func (q *QuietHours) contains(t time.Time) bool {
+	if q == nil || q.Start == q.End {
+		return false
+	}
+	loc := time.UTC
+	if q.TimeZone != "" {
+		if l, err := time.LoadLocation(q.TimeZone); err == nil {
+			loc = l
+		}
+	}
+	local := t.In(loc)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// NotificationPreferences controls how a user's notifications are delivered
+type NotificationPreferences struct {
+	// Channels lists the channels per notification type; types not listed use
+	// DefaultChannels, and an empty list mutes the type
+	Channels        map[string][]Channel
+	DefaultChannels []Channel
+	// Digest lists low-priority types batched into the periodic digest instead of
+	// being sent instantly
+	Digest     map[string]bool
+	QuietHours *QuietHours
+}
+
+// channelsFor returns the channels notifications of type kind go to
+func (p *NotificationPreferences) channelsFor(kind string) []Channel {
+	if channels, ok := p.Channels[kind]; ok {
+		return channels
+	}
+	return p.DefaultChannels
+}
+
+// preferenceBook holds user preferences and notifications waiting for delivery
+type preferenceBook struct {
+	mu             sync.Mutex
+	prefs          map[string]*NotificationPreferences
+	digest         map[string][]Notification // per user, batched for the next digest
+	held           map[string][]Notification // per user, held back by quiet hours
+	digestInterval time.Duration
+}
+
+// WithDigestInterval sends pending digests and notifications held by quiet hours on a
+// recurring scheduler job; without it call SendDigests from the embedding application
+func WithDigestInterval(d time.Duration) Option {
+	return func(ws *WalletService) {
+		ws.preferences.digestInterval = d
+	}
+}
+
+// SetNotificationPreferences stores a user's notification preferences
+func (ws *WalletService) SetNotificationPreferences(userID string, prefs NotificationPreferences) error {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return ErrUserNotFound
+	}
+	if q := prefs.QuietHours; q != nil {
+		if q.Start < 0 || q.Start >= 24*time.Hour || q.End < 0 || q.End >= 24*time.Hour {
+			return ErrInvalidPreferences
+		}
+		if q.TimeZone != "" {
+			if _, err := time.LoadLocation(q.TimeZone); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidPreferences, err)
+			}
+		}
+	}
+
+	ws.preferences.mu.Lock()
+	defer ws.preferences.mu.Unlock()
+	if ws.preferences.prefs == nil {
+		ws.preferences.prefs = make(map[string]*NotificationPreferences)
+	}
+	ws.preferences.prefs[userID] = &prefs
+	return nil
+}
+
+// GetNotificationPreferences returns a user's preferences; ok is false when the user
+// has none and receives every notification instantly on the default channel
+func (ws *WalletService) GetNotificationPreferences(userID string) (prefs NotificationPreferences, ok bool) {
+	ws.preferences.mu.Lock()
+	defer ws.preferences.mu.Unlock()
+	if p, exists := ws.preferences.prefs[userID]; exists {
+		return *p, true
+	}
+	return NotificationPreferences{}, false
+}
+
+// notify delivers a notification according to its recipient's preferences. Operator
+// notifications and users without preferences go straight to the notifier.
+func (ws *WalletService) notify(n Notification) error {
+	if n.Timestamp == 0 {
+		n.Timestamp = ws.now().Unix()
+	}
+
+	ws.preferences.mu.Lock()
+	prefs, exists := ws.preferences.prefs[n.UserID]
+	if n.UserID == "" || !exists {
+		ws.preferences.mu.Unlock()
+		return ws.notifier.Notify(n)
+	}
+
+	channels := prefs.channelsFor(n.Type)
+	switch {
+	case len(channels) == 0:
+		ws.preferences.mu.Unlock()
+		ws.metrics.IncCounter("notifications_suppressed_total", map[string]string{"type": n.Type})
+		return nil
+	case prefs.Digest[n.Type]:
+		ws.preferences.queue(&ws.preferences.digest, n)
+		ws.preferences.mu.Unlock()
+		return nil
+	case prefs.QuietHours.contains(ws.now()):
+		ws.preferences.queue(&ws.preferences.held, n)
+		ws.preferences.mu.Unlock()
+		ws.metrics.IncCounter("notifications_deferred_total", map[string]string{"type": n.Type})
+		return nil
+	}
+	ws.preferences.mu.Unlock()
+
+	return ws.deliver(n, channels)
+}
+
+// queue appends n to the user's list in *lists. Callers hold pb.mu.
+func (pb *preferenceBook) queue(lists *map[string][]Notification, n Notification) {
+	if *lists == nil {
+		*lists = make(map[string][]Notification)
+	}
+	(*lists)[n.UserID] = append((*lists)[n.UserID], n)
+}
+
+// deliver sends one copy of n per channel, returning the first error
+func (ws *WalletService) deliver(n Notification, channels []Channel) error {
+	var firstErr error
+	for _, channel := range channels {
+		copied := n
+		copied.Channel = channel
+		if err := ws.notifier.Notify(copied); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SendDigests delivers, for every user outside quiet hours, the notifications held back
+// by quiet hours followed by one digest summarising their batched low-priority
+// notifications. It returns the number of users delivered to.
+func (ws *WalletService) SendDigests() int {
+	now := ws.now()
+
+	type outbox struct {
+		held     []Notification
+		digest   *Notification
+		prefs    *NotificationPreferences
+		channels []Channel
+	}
+	var pending []outbox
+
+	ws.preferences.mu.Lock()
+	userIDs := make(map[string]bool)
+	for id := range ws.preferences.held {
+		userIDs[id] = true
+	}
+	for id := range ws.preferences.digest {
+		userIDs[id] = true
+	}
+	for id := range userIDs {
+		prefs := ws.preferences.prefs[id]
+		if prefs == nil || prefs.QuietHours.contains(now) {
+			continue
+		}
+		out := outbox{held: ws.preferences.held[id], prefs: prefs}
+		if items := ws.preferences.digest[id]; len(items) > 0 {
+			out.digest = buildDigest(id, items, now)
+			out.channels = prefs.channelsFor(NotificationDigest)
+		}
+		delete(ws.preferences.held, id)
+		delete(ws.preferences.digest, id)
+		pending = append(pending, out)
+	}
+	ws.preferences.mu.Unlock()
+
+	for _, out := range pending {
+		for _, n := range out.held {
+			ws.deliver(n, out.prefs.channelsFor(n.Type))
+		}
+		if out.digest != nil {
+			ws.deliver(*out.digest, out.channels)
+			ws.metrics.IncCounter("notification_digests_total", nil)
+		}
+	}
+	return len(pending)
+}
+
+// buildDigest summarises batched notifications, grouped by type in arrival order
+func buildDigest(userID string, items []Notification, now time.Time) *Notification {
+	counts := make(map[string]int)
+	var order []string
+	for _, n := range items {
+		if counts[n.Type] == 0 {
+			order = append(order, n.Type)
+		}
+		counts[n.Type]++
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+
+	data := map[string]string{"count": fmt.Sprint(len(items))}
+	lines := make([]string, 0, len(items))
+	for _, kind := range order {
+		data["type:"+kind] = fmt.Sprint(counts[kind])
+		for _, n := range items {
+			if n.Type == kind {
+				lines = append(lines, fmt.Sprintf("[%s] %s", kind, n.Subject))
+			}
+		}
+	}
+
+	return &Notification{
+		UserID:    userID,
+		Type:      NotificationDigest,
+		Subject:   fmt.Sprintf("%d updates since your last digest", len(items)),
+		Message:   strings.Join(lines, "\n"),
+		Data:      data,
+		Timestamp: now.Unix(),
+	}
+}
+
+// startDigestJob schedules SendDigests when a digest interval is configured
+func (ws *WalletService) startDigestJob() {
+	d := ws.preferences.digestInterval
+	if d <= 0 {
+		return
+	}
+	ws.schedule("notification_digest", "", ws.now().Add(d), Every(d), func(time.Time) error {
+		ws.SendDigests()
+		return nil
+	})
+}
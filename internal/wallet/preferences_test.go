@@ -0,0 +1,126 @@
+// internal/wallet/preferences_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotify_ChannelsPerType(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, _ := newTestService(WithNotifier(notifier))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	ws.notify(Notification{UserID: "alice", Type: "gift_received"})
+	if got := notifier.take(); len(got) != 1 || got[0].Channel != "" {
+		t.Fatalf("without preferences got %+v, want one plain notification", got)
+	}
+
+	ws.SetNotificationPreferences("alice", NotificationPreferences{
+		Channels: map[string][]Channel{
+			"gift_received": {ChannelEmail, ChannelPush},
+			"automation":    {},
+		},
+		DefaultChannels: []Channel{ChannelInApp},
+	})
+
+	tests := []struct {
+		kind string
+		want []Channel
+	}{
+		{"gift_received", []Channel{ChannelEmail, ChannelPush}},
+		{"automation", nil},
+		{"gift_failed", []Channel{ChannelInApp}},
+	}
+	for _, tt := range tests {
+		ws.notify(Notification{UserID: "alice", Type: tt.kind})
+		got := notifier.take()
+		if len(got) != len(tt.want) {
+			t.Errorf("%s delivered %d times, want %d", tt.kind, len(got), len(tt.want))
+			continue
+		}
+		for i, n := range got {
+			if n.Channel != tt.want[i] {
+				t.Errorf("%s copy %d went to %s, want %s", tt.kind, i, n.Channel, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestNotify_QuietHoursHoldInstantNotifications(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, clock := newTestService(WithNotifier(notifier))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	// The fixture clock is 12:00 UTC, 07:00 in New York
+	err := ws.SetNotificationPreferences("alice", NotificationPreferences{
+		DefaultChannels: []Channel{ChannelPush},
+		QuietHours:      &QuietHours{Start: 22 * time.Hour, End: 8 * time.Hour, TimeZone: "America/New_York"},
+	})
+	if err != nil {
+		t.Fatalf("SetNotificationPreferences() error = %v", err)
+	}
+
+	ws.notify(Notification{UserID: "alice", Type: "gift_received", Subject: "gift"})
+	if got := notifier.take(); len(got) != 0 {
+		t.Fatalf("delivered during quiet hours: %+v", got)
+	}
+	if n := ws.SendDigests(); n != 0 {
+		t.Errorf("SendDigests() during quiet hours = %d, want 0", n)
+	}
+
+	clock.Advance(time.Hour)
+	if n := ws.SendDigests(); n != 1 {
+		t.Errorf("SendDigests() after quiet hours = %d, want 1", n)
+	}
+	if got := notifier.take(); len(got) != 1 || got[0].Subject != "gift" || got[0].Channel != ChannelPush {
+		t.Errorf("after quiet hours got %+v, want the held gift notification", got)
+	}
+
+	invalid := NotificationPreferences{QuietHours: &QuietHours{Start: 25 * time.Hour}}
+	if err := ws.SetNotificationPreferences("alice", invalid); err != ErrInvalidPreferences {
+		t.Errorf("out of range quiet hours error = %v, want %v", err, ErrInvalidPreferences)
+	}
+	if err := ws.SetNotificationPreferences("nobody", NotificationPreferences{}); err != ErrUserNotFound {
+		t.Errorf("unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestNotify_DigestBatchesLowPriorityTypes(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, clock := newTestService(WithNotifier(notifier), WithDigestInterval(24*time.Hour))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.SetNotificationPreferences("alice", NotificationPreferences{
+		DefaultChannels: []Channel{ChannelEmail},
+		Digest:          map[string]bool{"automation": true, "gift_failed": true},
+	})
+
+	ws.notify(Notification{UserID: "alice", Type: "automation", Subject: "saved 10"})
+	ws.notify(Notification{UserID: "alice", Type: "gift_failed", Subject: "gift bounced"})
+	ws.notify(Notification{UserID: "alice", Type: "automation", Subject: "saved 20"})
+	ws.notify(Notification{UserID: "alice", Type: "gift_received", Subject: "instant"})
+
+	if got := notifier.take(); len(got) != 1 || got[0].Subject != "instant" {
+		t.Fatalf("instant delivery = %+v, want only the gift_received notification", got)
+	}
+
+	clock.Advance(24 * time.Hour)
+	ws.RunDueJobs()
+
+	got := notifier.take()
+	if len(got) != 1 || got[0].Type != NotificationDigest {
+		t.Fatalf("digest delivery = %+v, want one digest", got)
+	}
+	digest := got[0]
+	if digest.Data["count"] != "3" || digest.Data["type:automation"] != "2" || digest.Data["type:gift_failed"] != "1" {
+		t.Errorf("digest data = %v", digest.Data)
+	}
+	want := "[automation] saved 10\n[automation] saved 20\n[gift_failed] gift bounced"
+	if digest.Message != want {
+		t.Errorf("digest message = %q, want %q", digest.Message, want)
+	}
+
+	if n := ws.SendDigests(); n != 0 {
+		t.Errorf("SendDigests() with nothing pending = %d, want 0", n)
+	}
+}
@@ -0,0 +1,238 @@
+// internal/wallet/priority.go
+package wallet
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Priority is the lane an operation queues in when a user lock is contended. Lower
+// values are served first.
+type Priority int
+
+const (
+	// PriorityInteractive is for user-facing operations such as transfers and deposits
+	PriorityInteractive Priority = iota
+	// PriorityBatch is for bulk and scheduled work such as payouts and automations
+	PriorityBatch
+	// PriorityReporting is for reads that lock wallets, such as snapshots and audits
+	PriorityReporting
+
+	numPriorities = 3
+)
+
+// DefaultLockFairness is how many times a waiting lane may be bypassed by higher
+// priority work before its oldest waiter is served anyway
+const DefaultLockFairness = 8
+
+// String returns the lane name used in metrics
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBatch:
+		return "batch"
+	case PriorityReporting:
+		return "reporting"
+	}
+	return "priority_" + strconv.Itoa(int(p))
+}
+
+// LaneStats aggregates lock acquisitions for one priority lane
+type LaneStats struct {
+	Priority     Priority
+	Acquisitions int64
+	Contended    int64 // acquisitions that had to queue
+	Promoted     int64 // acquisitions granted by the fairness rule ahead of higher lanes
+	TotalWait    time.Duration
+	MaxWait      time.Duration
+}
+
+// WithLockFairness sets how many times a waiting lane may be bypassed before it is
+// served ahead of higher priority lanes; n <= 0 disables priorities entirely, making
+// every user lock strictly first come, first served.
+func WithLockFairness(n int) Option {
+	return func(ws *WalletService) {
+		ws.userLocks.fairness = n
+	}
+}
+
+// lockWaiter is one queued acquisition
+type lockWaiter struct {
+	ready    chan struct{}
+	seq      uint64
+	promoted bool
+}
+
+// priorityLock is a user lock that hands ownership to the highest priority waiter on
+// release, FIFO within a lane. Each grant that bypasses a waiting lane counts against
+// it; once a lane has been bypassed fairness times its oldest waiter goes next, so
+// lower lanes are delayed under load but never starved.
+type priorityLock struct {
+	mgr      *userLockManager
+	userID   string
+	mu       sync.Mutex
+	held     bool
+	seq      uint64
+	lanes    [numPriorities][]*lockWaiter
+	bypassed [numPriorities]int
+}
+
+// Lock acquires the lock in the interactive lane
+func (l *priorityLock) Lock() {
+	l.LockAt(PriorityInteractive)
+}
+
+// LockAt acquires the lock, queueing in lane p while it is held
+func (l *priorityLock) LockAt(p Priority) {
+	if p < 0 || p >= numPriorities {
+		p = PriorityInteractive
+	}
+	start := time.Now()
+
+	l.mu.Lock()
+	if !l.held {
+		l.held = true
+		l.mu.Unlock()
+		l.mgr.granted(l.userID, p, 0, false, false)
+		return
+	}
+	l.seq++
+	w := &lockWaiter{ready: make(chan struct{}), seq: l.seq}
+	l.lanes[p] = append(l.lanes[p], w)
+	l.mu.Unlock()
+
+	// Ownership is handed over directly by Unlock; held stays true throughout
+	<-w.ready
+	l.mgr.granted(l.userID, p, time.Since(start), true, w.promoted)
+}
+
+// Unlock releases the lock, handing it to the next waiter if any
+func (l *priorityLock) Unlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held {
+		panic("wallet: unlock of unlocked user lock")
+	}
+	next, ok := l.next()
+	if !ok {
+		l.held = false
+		return
+	}
+
+	w := l.lanes[next][0]
+	l.lanes[next] = l.lanes[next][1:]
+	l.bypassed[next] = 0
+	for p := range l.lanes {
+		if Priority(p) != next && len(l.lanes[p]) > 0 {
+			l.bypassed[p]++
+		}
+	}
+	close(w.ready)
+}
+
+// next picks the lane to serve: the most bypassed lane that reached the fairness
+// limit, otherwise the highest priority lane with waiters. Callers hold l.mu.
+func (l *priorityLock) next() (Priority, bool) {
+	fairness := l.mgr.fairness
+	top := Priority(-1)
+	for p := range l.lanes {
+		if len(l.lanes[p]) == 0 {
+			continue
+		}
+		if top < 0 {
+			top = Priority(p)
+		}
+		// Without priorities the lanes merge into a single FIFO queue
+		if fairness <= 0 && l.lanes[p][0].seq < l.lanes[top][0].seq {
+			top = Priority(p)
+		}
+	}
+	if top < 0 || fairness <= 0 {
+		return top, top >= 0
+	}
+
+	starved := top
+	for p := top + 1; p < numPriorities; p++ {
+		if len(l.lanes[p]) > 0 && l.bypassed[p] >= fairness && l.bypassed[p] > l.bypassed[starved] {
+			starved = p
+		}
+	}
+	if starved != top {
+		l.lanes[starved][0].promoted = true
+	}
+	return starved, true
+}
+
+// userLockManager manages locks for individual users to prevent deadlocks
+type userLockManager struct {
+	locks    sync.Map
+	fairness int
+	onGrant  func(userID string, p Priority, wait time.Duration, contended, promoted bool)
+
+	statsMu sync.Mutex
+	stats   [numPriorities]LaneStats
+}
+
+// newUserLockManager creates a lock manager with the default fairness limit
+func newUserLockManager() *userLockManager {
+	return &userLockManager{fairness: DefaultLockFairness}
+}
+
+// getLock returns the lock for the given user ID
+func (ulm *userLockManager) getLock(userID string) *priorityLock {
+	lock, _ := ulm.locks.LoadOrStore(userID, &priorityLock{mgr: ulm, userID: userID})
+	return lock.(*priorityLock)
+}
+
+// granted accounts one acquisition of userID's lock in lane p
+func (ulm *userLockManager) granted(userID string, p Priority, wait time.Duration, contended, promoted bool) {
+	ulm.statsMu.Lock()
+	s := &ulm.stats[p]
+	s.Acquisitions++
+	if contended {
+		s.Contended++
+	}
+	if promoted {
+		s.Promoted++
+	}
+	s.TotalWait += wait
+	if wait > s.MaxWait {
+		s.MaxWait = wait
+	}
+	ulm.statsMu.Unlock()
+
+	if ulm.onGrant != nil {
+		ulm.onGrant(userID, p, wait, contended, promoted)
+	}
+}
+
+// recordLockGrant emits metrics for one user lock acquisition and adds it to the
+// wallet's hot-spot telemetry
+func (ws *WalletService) recordLockGrant(userID string, p Priority, wait time.Duration, contended, promoted bool) {
+	ws.hotspots.record(userID, wait, contended, ws.now())
+	if !contended {
+		return
+	}
+	labels := map[string]string{"priority": p.String()}
+	ws.metrics.IncCounter("lock_contended_total", labels)
+	ws.metrics.ObserveValue("lock_queue_wait_seconds", wait.Seconds(), labels)
+	if promoted {
+		ws.metrics.IncCounter("lock_fairness_promotions_total", labels)
+	}
+}
+
+// GetLockStats returns per-lane user lock statistics, highest priority first
+func (ws *WalletService) GetLockStats() []LaneStats {
+	ws.userLocks.statsMu.Lock()
+	defer ws.userLocks.statsMu.Unlock()
+
+	stats := make([]LaneStats, numPriorities)
+	for p := range stats {
+		stats[p] = ws.userLocks.stats[p]
+		stats[p].Priority = Priority(p)
+	}
+	return stats
+}
@@ -0,0 +1,174 @@
+// internal/wallet/priority_test.go
+package wallet
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// queuedLock holds a user lock and queues waiters on it in a fixed order
+type queuedLock struct {
+	lock  *priorityLock
+	mu    sync.Mutex
+	order []string
+	wg    sync.WaitGroup
+}
+
+// enqueue starts a waiter in lane p and returns once it is queued
+func (q *queuedLock) enqueue(t *testing.T, name string, p Priority) {
+	t.Helper()
+	q.lock.mu.Lock()
+	before := len(q.lock.lanes[p])
+	q.lock.mu.Unlock()
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.lock.LockAt(p)
+		q.mu.Lock()
+		q.order = append(q.order, name)
+		q.mu.Unlock()
+		q.lock.Unlock()
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		q.lock.mu.Lock()
+		queued := len(q.lock.lanes[p]) > before
+		q.lock.mu.Unlock()
+		if queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%s never queued", name)
+}
+
+// release unlocks the held lock and returns the order waiters acquired it in
+func (q *queuedLock) release() []string {
+	q.lock.Unlock()
+	q.wg.Wait()
+	return q.order
+}
+
+func TestPriorityLock_ServesLanesInOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		fairness int
+		queue    []Priority
+		want     []string
+	}{
+		{
+			name:     "highest lane first, FIFO within a lane",
+			fairness: DefaultLockFairness,
+			queue:    []Priority{PriorityReporting, PriorityBatch, PriorityInteractive, PriorityBatch},
+			want:     []string{"w2", "w1", "w3", "w0"},
+		},
+		{
+			name:     "bypassed lane is promoted",
+			fairness: 2,
+			queue:    []Priority{PriorityReporting, PriorityInteractive, PriorityInteractive, PriorityInteractive},
+			want:     []string{"w1", "w2", "w0", "w3"},
+		},
+		{
+			name:     "fairness disabled is first come first served",
+			fairness: 0,
+			queue:    []Priority{PriorityReporting, PriorityBatch, PriorityInteractive},
+			want:     []string{"w0", "w1", "w2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := NewWalletService(WithLockFairness(tt.fairness))
+			q := &queuedLock{lock: ws.userLocks.getLock("hot")}
+			q.lock.Lock()
+			for i, p := range tt.queue {
+				q.enqueue(t, "w"+string(rune('0'+i)), p)
+			}
+
+			if got := q.release(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("acquisition order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriorityLock_StatsAndMetrics(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithMetrics(metrics), WithLockFairness(1))
+	q := &queuedLock{lock: ws.userLocks.getLock("hot")}
+	q.lock.Lock()
+	q.enqueue(t, "report", PriorityReporting)
+	q.enqueue(t, "first", PriorityInteractive)
+	q.enqueue(t, "second", PriorityInteractive)
+	q.release()
+
+	stats := ws.GetLockStats()
+	if stats[PriorityInteractive].Acquisitions != 3 || stats[PriorityInteractive].Contended != 2 {
+		t.Errorf("interactive stats = %+v, want 3 acquisitions, 2 contended", stats[PriorityInteractive])
+	}
+	if s := stats[PriorityReporting]; s.Contended != 1 || s.Promoted != 1 || s.MaxWait <= 0 {
+		t.Errorf("reporting stats = %+v, want one promoted contended acquisition", s)
+	}
+
+	labels := map[string]string{"priority": "reporting"}
+	if got := metrics.Counter("lock_fairness_promotions_total", labels); got != 1 {
+		t.Errorf("lock_fairness_promotions_total = %d, want 1", got)
+	}
+	if got := metrics.Counter("lock_contended_total", map[string]string{"priority": "interactive"}); got != 2 {
+		t.Errorf("lock_contended_total = %d, want 2", got)
+	}
+}
+
+func TestPriorityLock_BatchPayoutYieldsToTransfers(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("hot", "Hot", "hot@example.com")
+	ws.CreateUser("payee", "Payee", "payee@example.com")
+	ws.CreateUser("other", "Other", "other@example.com")
+	ws.Deposit("hot", 100, "seed")
+
+	q := &queuedLock{lock: ws.userLocks.getLock("hot")}
+	q.lock.Lock()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		ws.BatchPayout("hot", []Payout{{UserID: "payee", Amount: decimal.NewFromInt(10)}}, "payout")
+	}()
+	waitQueued(t, q.lock, PriorityBatch)
+	go func() {
+		defer wg.Done()
+		ws.Transfer("hot", "other", 5, "coffee")
+	}()
+	waitQueued(t, q.lock, PriorityInteractive)
+
+	q.lock.Unlock()
+	wg.Wait()
+
+	history, _ := ws.GetTransactionHistory("hot")
+	if len(history) != 3 || history[1].Description != "coffee" || history[2].Description != "payout" {
+		t.Errorf("history = %+v, want the transfer before the payout", history)
+	}
+}
+
+// waitQueued waits until lane p of l has a waiter
+func waitQueued(t *testing.T, l *priorityLock, p Priority) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		queued := len(l.lanes[p]) > 0
+		l.mu.Unlock()
+		if queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("no %s waiter queued", p)
+}
@@ -0,0 +1,352 @@
+// internal/wallet/rails.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for payment rails
+var (
+	ErrRailNotConfigured   = errors.New("payment rail not configured")
+	ErrRailTransferUnknown = errors.New("rail transfer not found")
+	ErrRailOverSettlement  = errors.New("rail settled more than was requested")
+)
+
+// RailDirection says which way money moves over a payment rail
+type RailDirection string
+
+const (
+	RailDeposit RailDirection = "deposit" // pulled from an external account into a wallet
+	RailPayout  RailDirection = "payout"  // pushed from a wallet to an external account
+)
+
+// RailStatus is the state of a transfer on a payment rail
+type RailStatus string
+
+const (
+	RailPending RailStatus = "pending"
+	RailSettled RailStatus = "settled"
+	RailFailed  RailStatus = "failed"
+)
+
+// RailRequest asks a rail to move money. ID doubles as the idempotency key.
+type RailRequest struct {
+	ID        string
+	Direction RailDirection
+	UserID    string
+	Account   string // external account reference
+	Amount    decimal.Decimal
+	Currency  string
+}
+
+// RailCallback reports progress on a rail transfer. A settlement may arrive in several
+// parts, each carrying the amount it settles; rails may deliver a callback more than
+// once, so every callback has a unique ID.
+type RailCallback struct {
+	ID        string
+	Reference string // reference returned by Initiate, or the request ID
+	Status    RailStatus
+	Amount    decimal.Decimal // amount settled by this callback
+	Reason    string          // failure reason
+}
+
+// PaymentRail moves money between wallets and external accounts, such as a bank or
+// card network. Initiation only acknowledges the request; the outcome arrives later
+// through WalletService.HandleRailCallback.
+type PaymentRail interface {
+	Name() string
+	Initiate(req RailRequest) (reference string, err error)
+}
+
+// RailTransfer tracks one deposit or payout over the rail
+type RailTransfer struct {
+	RailRequest
+	Reference      string
+	Status         RailStatus
+	Settled        decimal.Decimal
+	Reason         string
+	TransactionIDs []string // credits, debits and reversals posted for the transfer
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+// railDesk holds the configured rail and the transfers in flight
+type railDesk struct {
+	mu          sync.Mutex
+	rail        PaymentRail
+	transfers   map[string]*RailTransfer // by request ID
+	byReference map[string]string
+	callbacks   map[string]bool // callback IDs already applied
+}
+
+// WithPaymentRail sets the rail used for external deposits and payouts
+func WithPaymentRail(rail PaymentRail) Option {
+	return func(ws *WalletService) {
+		ws.rails.rail = rail
+	}
+}
+
+// DepositViaRail asks the rail to pull amount from an external account. The wallet is
+// credited as the rail reports settlements.
+func (ws *WalletService) DepositViaRail(userID, account string, amount decimal.Decimal) (*RailTransfer, error) {
+	return ws.initiateRail(context.Background(), RailDeposit, userID, account, amount)
+}
+
+// DepositViaRailContext is DepositViaRail with the rail call bounded by ctx and the
+// rail layer budget
+func (ws *WalletService) DepositViaRailContext(ctx context.Context, userID, account string, amount decimal.Decimal) (*RailTransfer, error) {
+	return ws.initiateRail(ctx, RailDeposit, userID, account, amount)
+}
+
+// PayoutViaRail debits the wallet and asks the rail to push amount to an external
+// account. Any part the rail fails to settle is credited back.
+func (ws *WalletService) PayoutViaRail(userID, account string, amount decimal.Decimal) (*RailTransfer, error) {
+	return ws.initiateRail(context.Background(), RailPayout, userID, account, amount)
+}
+
+// PayoutViaRailContext is PayoutViaRail with the rail call bounded by ctx and the rail
+// layer budget. A payout the rail does not acknowledge in time is reversed within the
+// budget's reserve.
+func (ws *WalletService) PayoutViaRailContext(ctx context.Context, userID, account string, amount decimal.Decimal) (*RailTransfer, error) {
+	return ws.initiateRail(ctx, RailPayout, userID, account, amount)
+}
+
+// initiateRail registers a rail transfer and hands it to the rail
+func (ws *WalletService) initiateRail(ctx context.Context, direction RailDirection, userID, account string, amount decimal.Decimal) (*RailTransfer, error) {
+	rail := ws.rails.rail
+	if rail == nil {
+		return nil, ErrRailNotConfigured
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	rt := &RailTransfer{
+		RailRequest: RailRequest{
+			ID:        ws.newID("rail"),
+			Direction: direction,
+			UserID:    userID,
+			Account:   account,
+			Amount:    amount,
+			Currency:  wallet.Currency,
+		},
+		Status:    RailPending,
+		CreatedAt: ws.now().Unix(),
+	}
+	rt.UpdatedAt = rt.CreatedAt
+
+	if direction == RailPayout {
+		debit := &Transaction{
+			FromUserID:  userID,
+			ToUserID:    rail.Name() + ":" + account,
+			Amount:      amount,
+			Currency:    rt.Currency,
+			Type:        TransactionWithdraw,
+			Description: "payout via " + rail.Name(),
+			Metadata:    map[string]string{"rail_transfer": rt.ID},
+		}
+		if err := ws.postDebit(ctx, debit); err != nil {
+			return nil, err
+		}
+		rt.TransactionIDs = append(rt.TransactionIDs, debit.ID)
+	}
+
+	// Register before initiating so callbacks that race the acknowledgement and quote
+	// the request ID as their reference still find the transfer
+	ws.rails.mu.Lock()
+	if ws.rails.transfers == nil {
+		ws.rails.transfers = make(map[string]*RailTransfer)
+		ws.rails.byReference = make(map[string]string)
+		ws.rails.callbacks = make(map[string]bool)
+	}
+	ws.rails.transfers[rt.ID] = rt
+	ws.rails.mu.Unlock()
+
+	reference, err := ws.railInitiate(ctx, rail, rt.RailRequest)
+	if err != nil {
+		ws.failRail(ctx, rt.ID, err.Error())
+		return nil, err
+	}
+
+	ws.rails.mu.Lock()
+	rt.Reference = reference
+	ws.rails.byReference[reference] = rt.ID
+	copied := rt.copy()
+	ws.rails.mu.Unlock()
+
+	ws.metrics.IncCounter("rail_transfers_total", map[string]string{"rail": rail.Name(), "direction": string(direction)})
+	return copied, nil
+}
+
+// HandleRailCallback applies a rail's report. Duplicate callbacks are ignored, partial
+// settlements accumulate, and a failure ends the transfer: an unsettled payout
+// remainder is credited back to the wallet.
+func (ws *WalletService) HandleRailCallback(cb RailCallback) error {
+	return ws.HandleRailCallbackContext(context.Background(), cb)
+}
+
+// HandleRailCallbackContext is HandleRailCallback on behalf of a caller's ctx
+func (ws *WalletService) HandleRailCallbackContext(ctx context.Context, cb RailCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ws.rails.mu.Lock()
+	if ws.rails.callbacks[cb.ID] {
+		ws.rails.mu.Unlock()
+		ws.metrics.IncCounter("rail_duplicate_callbacks_total", nil)
+		return nil
+	}
+	id, known := ws.rails.byReference[cb.Reference]
+	if _, registered := ws.rails.transfers[cb.Reference]; !known && registered {
+		id, known = cb.Reference, true
+	}
+	if !known {
+		ws.rails.mu.Unlock()
+		return ErrRailTransferUnknown
+	}
+	rt := ws.rails.transfers[id]
+	if rt.Status != RailPending {
+		ws.rails.callbacks[cb.ID] = true
+		ws.rails.mu.Unlock()
+		return nil
+	}
+
+	switch cb.Status {
+	case RailSettled:
+		if cb.Amount.LessThanOrEqual(decimal.Zero) {
+			ws.rails.mu.Unlock()
+			return ErrInvalidAmount
+		}
+		if rt.Settled.Add(cb.Amount).GreaterThan(rt.Amount) {
+			ws.rails.mu.Unlock()
+			return ErrRailOverSettlement
+		}
+		rt.Settled = rt.Settled.Add(cb.Amount)
+		if rt.Settled.Equal(rt.Amount) {
+			rt.Status = RailSettled
+		}
+	case RailFailed:
+		rt.Status, rt.Reason = RailFailed, cb.Reason
+	default:
+		ws.rails.mu.Unlock()
+		return nil
+	}
+	ws.rails.callbacks[cb.ID] = true
+	rt.UpdatedAt = ws.now().Unix()
+	req, settled, status := rt.RailRequest, rt.Settled, rt.Status
+	ws.rails.mu.Unlock()
+
+	// Post ledger entries outside the rail lock
+	var tx *Transaction
+	switch {
+	case req.Direction == RailDeposit && cb.Status == RailSettled:
+		tx = &Transaction{
+			FromUserID:  ws.rails.rail.Name() + ":" + req.Account,
+			ToUserID:    req.UserID,
+			Amount:      cb.Amount,
+			Currency:    req.Currency,
+			Type:        TransactionDeposit,
+			Description: "deposit via " + ws.rails.rail.Name(),
+		}
+	case req.Direction == RailPayout && status == RailFailed && settled.LessThan(req.Amount):
+		tx = &Transaction{
+			FromUserID:  ws.rails.rail.Name() + ":" + req.Account,
+			ToUserID:    req.UserID,
+			Amount:      req.Amount.Sub(settled),
+			Currency:    req.Currency,
+			Type:        TransactionRailReversal,
+			Description: "failed payout: " + cb.Reason,
+		}
+	}
+	if tx == nil {
+		return nil
+	}
+	tx.Metadata = map[string]string{"rail_transfer": req.ID, "rail_callback": cb.ID}
+	if err := ws.postCredit(ctx, tx); err != nil {
+		return err
+	}
+
+	ws.rails.mu.Lock()
+	rt.TransactionIDs = append(rt.TransactionIDs, tx.ID)
+	ws.rails.mu.Unlock()
+	return nil
+}
+
+// failRail marks a transfer the rail refused at initiation, reversing a payout debit
+func (ws *WalletService) failRail(ctx context.Context, id, reason string) {
+	ws.rails.mu.Lock()
+	rt := ws.rails.transfers[id]
+	rt.Status, rt.Reason = RailFailed, reason
+	rt.UpdatedAt = ws.now().Unix()
+	req := rt.RailRequest
+	ws.rails.mu.Unlock()
+
+	if req.Direction != RailPayout {
+		return
+	}
+	reversal := &Transaction{
+		FromUserID:  ws.rails.rail.Name() + ":" + req.Account,
+		ToUserID:    req.UserID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Type:        TransactionRailReversal,
+		Description: "rejected payout: " + reason,
+		Metadata:    map[string]string{"rail_transfer": req.ID},
+	}
+	if err := ws.postCredit(ctx, reversal); err == nil {
+		ws.rails.mu.Lock()
+		rt.TransactionIDs = append(rt.TransactionIDs, reversal.ID)
+		ws.rails.mu.Unlock()
+	}
+}
+
+// GetRailTransfer returns a rail transfer by ID
+func (ws *WalletService) GetRailTransfer(id string) (*RailTransfer, error) {
+	ws.rails.mu.Lock()
+	defer ws.rails.mu.Unlock()
+
+	rt, exists := ws.rails.transfers[id]
+	if !exists {
+		return nil, ErrRailTransferUnknown
+	}
+	return rt.copy(), nil
+}
+
+// ListRailTransfers returns a user's rail transfers, oldest first
+func (ws *WalletService) ListRailTransfers(userID string) []RailTransfer {
+	ws.rails.mu.Lock()
+	defer ws.rails.mu.Unlock()
+
+	var out []RailTransfer
+	for _, rt := range ws.rails.transfers {
+		if rt.UserID == userID {
+			out = append(out, *rt.copy())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// copy returns a copy safe to hand to callers. Callers hold ws.rails.mu.
+func (rt *RailTransfer) copy() *RailTransfer {
+	copied := *rt
+	copied.TransactionIDs = append([]string(nil), rt.TransactionIDs...)
+	return &copied
+}
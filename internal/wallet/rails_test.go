@@ -0,0 +1,108 @@
+// internal/wallet/rails_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// stubRail records initiated requests and optionally refuses them
+type stubRail struct {
+	requests []RailRequest
+	refuse   error
+}
+
+func (r *stubRail) Name() string { return "stub" }
+
+func (r *stubRail) Initiate(req RailRequest) (string, error) {
+	if r.refuse != nil {
+		return "", r.refuse
+	}
+	r.requests = append(r.requests, req)
+	return "ref-" + req.ID, nil
+}
+
+func TestRail_DepositSettlesInParts(t *testing.T) {
+	rail := &stubRail{}
+	ws := NewWalletService(WithPaymentRail(rail))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	rt, err := ws.DepositViaRail("alice", "DE89-0001", decimal.NewFromInt(100))
+	if err != nil {
+		t.Fatalf("DepositViaRail() error = %v", err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.IsZero() {
+		t.Fatalf("balance = %s before settlement, want 0", b)
+	}
+
+	callbacks := []struct {
+		cb      RailCallback
+		wantErr error
+	}{
+		{RailCallback{ID: "cb1", Reference: rt.Reference, Status: RailSettled, Amount: decimal.NewFromInt(60)}, nil},
+		{RailCallback{ID: "cb1", Reference: rt.Reference, Status: RailSettled, Amount: decimal.NewFromInt(60)}, nil},
+		{RailCallback{ID: "cb2", Reference: rt.Reference, Status: RailSettled, Amount: decimal.NewFromInt(50)}, ErrRailOverSettlement},
+		{RailCallback{ID: "cb3", Reference: rt.ID, Status: RailSettled, Amount: decimal.NewFromInt(40)}, nil},
+		{RailCallback{ID: "cb4", Reference: "unknown", Status: RailSettled, Amount: decimal.NewFromInt(1)}, ErrRailTransferUnknown},
+	}
+	for i, c := range callbacks {
+		if err := ws.HandleRailCallback(c.cb); err != c.wantErr {
+			t.Errorf("callback %d error = %v, want %v", i, err, c.wantErr)
+		}
+	}
+
+	got, _ := ws.GetRailTransfer(rt.ID)
+	if got.Status != RailSettled || !got.Settled.Equal(decimal.NewFromInt(100)) || len(got.TransactionIDs) != 2 {
+		t.Errorf("transfer = %+v, want settled 100 over two credits", got)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("balance = %s, want 100", b)
+	}
+}
+
+func TestRail_FailedPayoutCreditsBackRemainder(t *testing.T) {
+	rail := &stubRail{}
+	ws := NewWalletService(WithPaymentRail(rail))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	rt, err := ws.PayoutViaRail("alice", "DE89-0001", decimal.NewFromInt(80))
+	if err != nil {
+		t.Fatalf("PayoutViaRail() error = %v", err)
+	}
+	ws.HandleRailCallback(RailCallback{ID: "cb1", Reference: rt.Reference, Status: RailSettled, Amount: decimal.NewFromInt(30)})
+	ws.HandleRailCallback(RailCallback{ID: "cb2", Reference: rt.Reference, Status: RailFailed, Reason: "account closed"})
+	ws.HandleRailCallback(RailCallback{ID: "cb3", Reference: rt.Reference, Status: RailSettled, Amount: decimal.NewFromInt(50)})
+
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("balance = %s, want 70 after 50 came back", b)
+	}
+	got, _ := ws.GetRailTransfer(rt.ID)
+	if got.Status != RailFailed || got.Reason != "account closed" {
+		t.Errorf("transfer = %+v, want failed", got)
+	}
+
+	// A payout the rail refuses outright is reversed in full
+	rail.refuse = errors.New("rail offline")
+	if _, err := ws.PayoutViaRail("alice", "DE89-0001", decimal.NewFromInt(20)); err == nil {
+		t.Error("PayoutViaRail() with a refusing rail succeeded")
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("balance = %s, want 70 after the refused payout", b)
+	}
+	if transfers := ws.ListRailTransfers("alice"); len(transfers) != 2 || transfers[1].Status != RailFailed {
+		t.Errorf("ListRailTransfers() = %+v", transfers)
+	}
+
+	if mismatch, err := ws.CheckWalletIntegrity("alice"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+	if _, err := NewWalletService().DepositViaRail("alice", "x", decimal.NewFromInt(1)); err != ErrRailNotConfigured {
+		t.Errorf("DepositViaRail() without a rail error = %v, want %v", err, ErrRailNotConfigured)
+	}
+}
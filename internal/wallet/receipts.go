@@ -0,0 +1,409 @@
+// internal/wallet/receipts.go
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Error definitions for transaction receipts
+var (
+	ErrReceiptNotFound        = errors.New("receipt not found")
+	ErrInvalidReceiptTemplate = errors.New("invalid receipt template")
+)
+
+// NotificationReceipt is the notification type receipts are delivered as. The HTML
+// document travels in the notification's Data under "html".
+const NotificationReceipt = "receipt"
+
+// receiptTypes are the completed transactions receipts are sent for: transfers and
+// charges to cards and holds
+var receiptTypes = map[TransactionType]bool{
+	TransactionTransfer:    true,
+	TransactionCardCapture: true,
+	TransactionHoldCapture: true,
+}
+
+// ReceiptRole says which side of a transaction a receipt is for
+type ReceiptRole string
+
+const (
+	ReceiptPayer ReceiptRole = "payer"
+	ReceiptPayee ReceiptRole = "payee"
+)
+
+// ReceiptTemplate renders receipts. Subject and Text are text/template sources and
+// HTML an html/template source, each executed with ReceiptData; empty fields use the
+// built-in template.
+type ReceiptTemplate struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// ReceiptData is what receipt templates are executed with
+type ReceiptData struct {
+	ReceiptID        string
+	TransactionID    string
+	Type             TransactionType
+	Role             ReceiptRole
+	UserID           string
+	UserName         string
+	Counterparty     string // empty when the transaction has none, such as a hold capture
+	CounterpartyName string
+	Amount           string
+	Currency         string
+	Description      string
+	Time             time.Time // when the transaction was recorded, in UTC
+}
+
+// Receipt is a rendered receipt and its delivery record
+type Receipt struct {
+	ID            string
+	TransactionID string
+	UserID        string
+	Role          ReceiptRole
+	Tenant        string
+	Subject       string
+	Text          string
+	HTML          string
+	CreatedAt     int64
+	Sends         int // deliveries, including resends
+	LastSentAt    int64
+	LastError     string // set when the last delivery failed
+}
+
+// Built-in receipt templates
+const (
+	defaultReceiptSubject = `Receipt: {{if eq .Role "payee"}}you received{{else}}you paid{{end}} {{.Amount}} {{.Currency}}`
+	defaultReceiptText    = `Hello {{.UserName}},
+
+{{if eq .Role "payee"}}You received{{else}}You paid{{end}} {{.Amount}} {{.Currency}}{{with .CounterpartyName}} {{if eq $.Role "payee"}}from{{else}}to{{end}} {{.}}{{end}}.
+{{with .Description}}Description: {{.}}
+{{end}}Transaction: {{.TransactionID}}
+Date: {{.Time.Format "2006-01-02 15:04:05 MST"}}
+Receipt: {{.ReceiptID}}
+`
+	defaultReceiptHTML = `<html><body>
+<p>Hello {{.UserName}},</p>
+<p>{{if eq .Role "payee"}}You received{{else}}You paid{{end}} <strong>{{.Amount}} {{.Currency}}</strong>{{with .CounterpartyName}} {{if eq $.Role "payee"}}from{{else}}to{{end}} {{.}}{{end}}.</p>
+<table>
+{{with .Description}}<tr><th>Description</th><td>{{.}}</td></tr>
+{{end}}<tr><th>Transaction</th><td>{{.TransactionID}}</td></tr>
+<tr><th>Date</th><td>{{.Time.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><th>Receipt</th><td>{{.ReceiptID}}</td></tr>
+</table>
+</body></html>
+`
+)
+
+// receiptTemplates is a parsed ReceiptTemplate
+type receiptTemplates struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+// defaultReceiptTemplates are the built-in templates, parsed once
+var defaultReceiptTemplates = mustParseReceiptTemplate(ReceiptTemplate{
+	Subject: defaultReceiptSubject,
+	Text:    defaultReceiptText,
+	HTML:    defaultReceiptHTML,
+})
+
+// receiptBook holds receipt templates per tenant and the receipts sent
+type receiptBook struct {
+	mu        sync.Mutex
+	enabled   bool
+	tenantOf  func(userID string) string
+	templates map[string]*receiptTemplates // by tenant; "" overrides the built-in one
+	receipts  map[string]*Receipt
+	byTx      map[string][]string
+}
+
+// WithReceipts sends a receipt for every completed transfer and charge to each party
+// with a wallet. tenantOf, when set, names the tenant whose templates render a user's
+// receipts; the wallet service has no tenants of its own.
+func WithReceipts(tenantOf func(userID string) string) Option {
+	return func(ws *WalletService) {
+		ws.receipts.enabled = true
+		ws.receipts.tenantOf = tenantOf
+	}
+}
+
+// SetReceiptTemplate overrides the receipt templates of tenant, or the built-in ones
+// for every tenant without its own when tenant is empty. The templates are checked by
+// rendering a sample receipt.
+func (ws *WalletService) SetReceiptTemplate(tenant string, tmpl ReceiptTemplate) error {
+	parsed, err := parseReceiptTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	sample := ReceiptData{ReceiptID: "rcpt_sample", TransactionID: "tx_sample", Type: TransactionTransfer, Role: ReceiptPayer,
+		UserID: "user", UserName: "User", Counterparty: "payee", CounterpartyName: "Payee", Amount: "1.00",
+		Currency: DefaultCurrency, Description: "sample", Time: time.Unix(0, 0).UTC()}
+	if _, _, _, err := parsed.render(sample); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReceiptTemplate, err)
+	}
+
+	ws.receipts.mu.Lock()
+	defer ws.receipts.mu.Unlock()
+	if ws.receipts.templates == nil {
+		ws.receipts.templates = make(map[string]*receiptTemplates)
+	}
+	ws.receipts.templates[tenant] = parsed
+	return nil
+}
+
+// ClearReceiptTemplate removes tenant's template override
+func (ws *WalletService) ClearReceiptTemplate(tenant string) {
+	ws.receipts.mu.Lock()
+	defer ws.receipts.mu.Unlock()
+	delete(ws.receipts.templates, tenant)
+}
+
+// GetReceipt returns a receipt
+func (ws *WalletService) GetReceipt(receiptID string) (*Receipt, error) {
+	ws.receipts.mu.Lock()
+	defer ws.receipts.mu.Unlock()
+
+	r, exists := ws.receipts.receipts[receiptID]
+	if !exists {
+		return nil, ErrReceiptNotFound
+	}
+	copied := *r
+	return &copied, nil
+}
+
+// GetTransactionReceipts returns the receipts sent for txID, payer first
+func (ws *WalletService) GetTransactionReceipts(txID string) []Receipt {
+	ws.receipts.mu.Lock()
+	defer ws.receipts.mu.Unlock()
+
+	var receipts []Receipt
+	for _, id := range ws.receipts.byTx[txID] {
+		receipts = append(receipts, *ws.receipts.receipts[id])
+	}
+	sort.SliceStable(receipts, func(i, j int) bool { return receipts[i].Role == ReceiptPayer && receipts[j].Role != ReceiptPayer })
+	return receipts
+}
+
+// ResendReceipt delivers a receipt again as it was first rendered
+func (ws *WalletService) ResendReceipt(receiptID string) error {
+	ws.receipts.mu.Lock()
+	r, exists := ws.receipts.receipts[receiptID]
+	if !exists {
+		ws.receipts.mu.Unlock()
+		return ErrReceiptNotFound
+	}
+	copied := *r
+	ws.receipts.mu.Unlock()
+
+	ws.metrics.IncCounter("receipts_resent_total", nil)
+	return ws.deliverReceipt(&copied)
+}
+
+// sendReceipts renders and delivers the receipts of a recorded transaction. Call it
+// without holding ws.mu.
+func (ws *WalletService) sendReceipts(tx *Transaction) {
+	ws.receipts.mu.Lock()
+	enabled, tenantOf := ws.receipts.enabled, ws.receipts.tenantOf
+	ws.receipts.mu.Unlock()
+	if !enabled || !receiptTypes[tx.Type] || !tx.settled() {
+		return
+	}
+
+	parties := []struct {
+		userID, counterparty string
+		role                 ReceiptRole
+	}{
+		{tx.FromUserID, tx.ToUserID, ReceiptPayer},
+		{tx.ToUserID, tx.FromUserID, ReceiptPayee},
+	}
+	for i, p := range parties {
+		if i == 1 && p.userID == tx.FromUserID {
+			break
+		}
+		ws.mu.RLock()
+		user, isUser := ws.users[p.userID]
+		var userName string
+		if isUser {
+			userName = user.Name
+		}
+		counterparty, counterpartyName := p.counterparty, ws.partyName(p.counterparty)
+		ws.mu.RUnlock()
+		if !isUser {
+			continue
+		}
+		if counterparty == p.userID {
+			counterparty, counterpartyName = "", ""
+		}
+
+		tenant := ""
+		if tenantOf != nil {
+			tenant = tenantOf(p.userID)
+		}
+		data := ReceiptData{
+			ReceiptID:        ws.newID("rcpt"),
+			TransactionID:    tx.ID,
+			Type:             tx.Type,
+			Role:             p.role,
+			UserID:           p.userID,
+			UserName:         userName,
+			Counterparty:     counterparty,
+			CounterpartyName: counterpartyName,
+			Amount:           tx.Amount.StringFixed(2),
+			Currency:         tx.currencyOf(),
+			Description:      tx.Description,
+			Time:             time.Unix(tx.Timestamp, 0).UTC(),
+		}
+		r := ws.renderReceipt(tenant, data)
+
+		ws.receipts.mu.Lock()
+		if ws.receipts.receipts == nil {
+			ws.receipts.receipts = make(map[string]*Receipt)
+			ws.receipts.byTx = make(map[string][]string)
+		}
+		ws.receipts.receipts[r.ID] = r
+		ws.receipts.byTx[tx.ID] = append(ws.receipts.byTx[tx.ID], r.ID)
+		copied := *r
+		ws.receipts.mu.Unlock()
+
+		ws.metrics.IncCounter("receipts_sent_total", map[string]string{"type": string(tx.Type)})
+		ws.deliverReceipt(&copied)
+	}
+}
+
+// renderReceipt renders data with tenant's templates, falling back to the overridden
+// and then the built-in templates when rendering fails
+func (ws *WalletService) renderReceipt(tenant string, data ReceiptData) *Receipt {
+	ws.receipts.mu.Lock()
+	candidates := []*receiptTemplates{ws.receipts.templates[tenant], ws.receipts.templates[""], defaultReceiptTemplates}
+	ws.receipts.mu.Unlock()
+
+	r := &Receipt{
+		ID:            data.ReceiptID,
+		TransactionID: data.TransactionID,
+		UserID:        data.UserID,
+		Role:          data.Role,
+		Tenant:        tenant,
+		CreatedAt:     ws.now().Unix(),
+	}
+	for _, t := range candidates {
+		if t == nil {
+			continue
+		}
+		subject, text, html, err := t.render(data)
+		if err == nil {
+			r.Subject, r.Text, r.HTML = subject, text, html
+			break
+		}
+		ws.metrics.IncCounter("receipt_render_failures_total", nil)
+	}
+	return r
+}
+
+// deliverReceipt hands r to the notifier per its recipient's preferences and records
+// the attempt
+func (ws *WalletService) deliverReceipt(r *Receipt) error {
+	err := ws.notify(Notification{
+		UserID:  r.UserID,
+		Type:    NotificationReceipt,
+		Subject: r.Subject,
+		Message: r.Text,
+		Data: map[string]string{
+			"receipt_id":     r.ID,
+			"transaction_id": r.TransactionID,
+			"html":           r.HTML,
+		},
+	})
+
+	ws.receipts.mu.Lock()
+	defer ws.receipts.mu.Unlock()
+	if stored, exists := ws.receipts.receipts[r.ID]; exists {
+		stored.Sends++
+		stored.LastSentAt = ws.now().Unix()
+		stored.LastError = ""
+		if err != nil {
+			stored.LastError = err.Error()
+		}
+	}
+	return err
+}
+
+// partyName returns how a receipt names a transaction party: its statement
+// descriptor, a user's name, a card merchant or the system account. Caller must hold
+// ws.mu.
+func (ws *WalletService) partyName(id string) string {
+	if descriptor := ws.StatementDescriptor(id); descriptor != "" {
+		return descriptor
+	}
+	if user, exists := ws.users[id]; exists {
+		return user.Name
+	}
+	if merchant, isCard := strings.CutPrefix(id, cardCounterparty("")); isCard {
+		return merchant
+	}
+	return id
+}
+
+// render executes the templates with data
+func (t *receiptTemplates) render(data ReceiptData) (subject, text, html string, err error) {
+	var b bytes.Buffer
+	if err := t.subject.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.TrimSpace(b.String())
+	b.Reset()
+	if err := t.text.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	text = b.String()
+	b.Reset()
+	if err := t.html.Execute(&b, data); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, b.String(), nil
+}
+
+// parseReceiptTemplate parses tmpl, taking the built-in source for empty fields
+func parseReceiptTemplate(tmpl ReceiptTemplate) (*receiptTemplates, error) {
+	if tmpl.Subject == "" {
+		tmpl.Subject = defaultReceiptSubject
+	}
+	if tmpl.Text == "" {
+		tmpl.Text = defaultReceiptText
+	}
+	if tmpl.HTML == "" {
+		tmpl.HTML = defaultReceiptHTML
+	}
+
+	var t receiptTemplates
+	var err error
+	if t.subject, err = template.New("subject").Option("missingkey=error").Parse(tmpl.Subject); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReceiptTemplate, err)
+	}
+	if t.text, err = template.New("text").Option("missingkey=error").Parse(tmpl.Text); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReceiptTemplate, err)
+	}
+	if t.html, err = htmltemplate.New("html").Option("missingkey=error").Parse(tmpl.HTML); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReceiptTemplate, err)
+	}
+	return &t, nil
+}
+
+// mustParseReceiptTemplate is parseReceiptTemplate for the built-in templates
+func mustParseReceiptTemplate(tmpl ReceiptTemplate) *receiptTemplates {
+	t, err := parseReceiptTemplate(tmpl)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
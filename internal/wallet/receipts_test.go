@@ -0,0 +1,143 @@
+// internal/wallet/receipts_test.go
+package wallet
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// receiptInbox collects the receipts a notifier is handed
+type receiptInbox struct {
+	mu       sync.Mutex
+	receipts []Notification
+	fail     error
+}
+
+func (in *receiptInbox) Notify(n Notification) error {
+	if n.Type != NotificationReceipt {
+		return nil
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.receipts = append(in.receipts, n)
+	return in.fail
+}
+
+func TestReceipts_Transfer(t *testing.T) {
+	inbox := &receiptInbox{}
+	tenants := map[string]string{"bob": "acme"}
+	ws := NewWalletService(WithNotifier(inbox), WithReceipts(func(userID string) string { return tenants[userID] }))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	if err := ws.SetReceiptTemplate("acme", ReceiptTemplate{Subject: "ACME receipt {{.TransactionID}}", HTML: "<p>{{.Description}}</p>"}); err != nil {
+		t.Fatalf("SetReceiptTemplate() error = %v", err)
+	}
+	ws.Deposit("alice", 100, "salary")
+	ws.Transfer("alice", "bob", 12.5, "<b>dinner</b>")
+
+	history, _ := ws.GetTransactionHistory("bob")
+	txID := history[0].ID
+	receipts := ws.GetTransactionReceipts(txID)
+	if len(receipts) != 2 || len(inbox.receipts) != 2 {
+		t.Fatalf("receipts = %+v, delivered %d; want one per party and none for the deposit", receipts, len(inbox.receipts))
+	}
+
+	tests := []struct {
+		name     string
+		receipt  Receipt
+		role     ReceiptRole
+		tenant   string
+		contains []string
+	}{
+		{"payer gets the built-in template", receipts[0], ReceiptPayer, "", []string{"You paid 12.50 USD to Bob.", "Transaction: " + txID, "Date: 2"}},
+		{"payee gets the tenant's template", receipts[1], ReceiptPayee, "acme", []string{"You received 12.50 USD from Alice."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.receipt
+			if r.Role != tt.role || r.Tenant != tt.tenant || r.Sends != 1 {
+				t.Errorf("receipt = %+v, want role %s, tenant %q, sent once", r, tt.role, tt.tenant)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(r.Text, want) {
+					t.Errorf("text = %q, want it to contain %q", r.Text, want)
+				}
+			}
+		})
+	}
+	if receipts[1].Subject != "ACME receipt "+txID || receipts[1].HTML != "<p>&lt;b&gt;dinner&lt;/b&gt;</p>" {
+		t.Errorf("tenant receipt = %q / %q, want the override with escaped HTML", receipts[1].Subject, receipts[1].HTML)
+	}
+	if n := inbox.receipts[0]; n.UserID != "alice" || n.Data["transaction_id"] != txID || !strings.Contains(n.Data["html"], "<strong>12.50 USD</strong>") {
+		t.Errorf("notification = %+v, want alice's receipt with its HTML", n)
+	}
+
+	// Resending delivers the stored document again
+	inbox.fail = errors.New("smtp down")
+	if err := ws.ResendReceipt(receipts[0].ID); err == nil {
+		t.Errorf("ResendReceipt() error = nil, want the notifier's error")
+	}
+	if r, _ := ws.GetReceipt(receipts[0].ID); r.Sends != 2 || r.LastError != "smtp down" {
+		t.Errorf("receipt after resend = %+v, want two sends and the error", r)
+	}
+	if len(inbox.receipts) != 3 || inbox.receipts[2].Message != receipts[0].Text {
+		t.Errorf("resent notification = %+v, want the same text", inbox.receipts[len(inbox.receipts)-1])
+	}
+	if err := ws.ResendReceipt("rcpt_missing"); err != ErrReceiptNotFound {
+		t.Errorf("ResendReceipt(missing) error = %v, want %v", err, ErrReceiptNotFound)
+	}
+}
+
+func TestReceipts_CardCharge(t *testing.T) {
+	inbox := &receiptInbox{}
+	ws := NewWalletService(WithNotifier(inbox), WithReceipts(nil))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Deposit("alice", 100, "salary")
+	card, _ := ws.IssueCard("alice", "groceries", CardLimits{}, 365*24*time.Hour)
+	auth, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(40), Merchant: "grocer", ProcessorRef: "p1"})
+	if len(inbox.receipts) != 0 {
+		t.Fatalf("receipts before capture = %d, want none for the authorization hold", len(inbox.receipts))
+	}
+	if _, err := ws.CaptureAuthorization(auth.ID, decimal.NewFromInt(40)); err != nil {
+		t.Fatalf("CaptureAuthorization() error = %v", err)
+	}
+	if len(inbox.receipts) != 1 || !strings.Contains(inbox.receipts[0].Message, "You paid 40.00 USD to grocer.") {
+		t.Errorf("receipts = %+v, want one for the charge at grocer", inbox.receipts)
+	}
+}
+
+func TestReceipts_Templates(t *testing.T) {
+	inbox := &receiptInbox{}
+	ws := NewWalletService(WithNotifier(inbox))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+
+	tests := []struct {
+		name    string
+		tmpl    ReceiptTemplate
+		wantErr error
+	}{
+		{"syntax error", ReceiptTemplate{Text: "{{.Amount"}, ErrInvalidReceiptTemplate},
+		{"unknown field", ReceiptTemplate{Subject: "{{.Nope}}"}, ErrInvalidReceiptTemplate},
+		{"valid", ReceiptTemplate{Text: "{{.Amount}} {{.Currency}}"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ws.SetReceiptTemplate("", tt.tmpl); !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("SetReceiptTemplate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Receipts are off unless WithReceipts is given
+	ws.Deposit("alice", 10, "seed")
+	ws.Transfer("alice", "bob", 5, "gift")
+	if len(inbox.receipts) != 0 {
+		t.Errorf("receipts = %d without WithReceipts, want none", len(inbox.receipts))
+	}
+}
@@ -0,0 +1,211 @@
+// internal/wallet/reconcile.go
+package wallet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// DiscrepancyKind names the invariant a reconciliation discrepancy breaks
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyBalance: a wallet's stored holding differs from its transaction log
+	DiscrepancyBalance DiscrepancyKind = "balance"
+	// DiscrepancyLedger: credits less debits across all wallets differ from the money
+	// the system accounts put into circulation, less what is in transit
+	DiscrepancyLedger DiscrepancyKind = "ledger"
+	// DiscrepancySupply, DiscrepancyInTransit and DiscrepancySystem: the tracked supply,
+	// in-transit or system account figures differ from a replay of the log
+	DiscrepancySupply    DiscrepancyKind = "supply"
+	DiscrepancyInTransit DiscrepancyKind = "in_transit"
+	DiscrepancySystem    DiscrepancyKind = "system"
+)
+
+// Discrepancy is one broken invariant found by Reconcile
+type Discrepancy struct {
+	Kind     DiscrepancyKind
+	UserID   string // the wallet, or the system account, concerned; empty for totals
+	Currency string
+	Expected decimal.Decimal // derived from the transaction log
+	Actual   decimal.Decimal // stored or tracked by the service
+}
+
+// Difference returns actual minus expected
+func (d Discrepancy) Difference() decimal.Decimal {
+	return d.Actual.Sub(d.Expected)
+}
+
+// ReconciliationReport is the result of Reconcile. Credits and Debits are the totals
+// moved into and out of wallets per currency, as recorded in the log.
+type ReconciliationReport struct {
+	StartedAt     int64
+	Wallets       int
+	Transactions  int
+	Credits       map[string]decimal.Decimal
+	Debits        map[string]decimal.Decimal
+	Discrepancies []Discrepancy
+}
+
+// OK reports whether every invariant holds
+func (r *ReconciliationReport) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Reconcile checks the service's books against its transaction log, including
+// archived entries: every wallet holding is recomputed from the log and compared with
+// the stored one, credits less debits across all wallets must equal what the system
+// accounts issued less what is in transit, and the tracked supply figures must match a
+// replay of the log. Every user lock is held throughout so no operation is
+// half-applied; discrepancies are reported to metrics and the notifier.
+func (ws *WalletService) Reconcile() (*ReconciliationReport, error) {
+	ws.mu.RLock()
+	userIDs := make([]string, 0, len(ws.wallets))
+	for id := range ws.wallets {
+		userIDs = append(userIDs, id)
+	}
+	ws.mu.RUnlock()
+	sort.Strings(userIDs)
+
+	unlock := ws.lockUsers(PriorityReporting, userIDs...)
+	defer unlock()
+
+	report := &ReconciliationReport{
+		StartedAt: ws.now().Unix(),
+		Wallets:   len(userIDs),
+		Credits:   make(map[string]decimal.Decimal),
+		Debits:    make(map[string]decimal.Decimal),
+	}
+	var replay supplyLedger
+	seen := make(map[string]bool)
+	for _, userID := range userIDs {
+		ws.mu.RLock()
+		wallet := ws.wallets[userID]
+		ws.mu.RUnlock()
+
+		wallet.mu.RLock()
+		stored := copyAmounts(wallet.Foreign)
+		stored[wallet.Currency] = wallet.Balance
+		wallet.mu.RUnlock()
+
+		ledger := make(map[string]decimal.Decimal)
+		it := ws.iterate(userID, IterateOptions{})
+		for it.Next() {
+			tx := it.Transaction()
+			for _, currency := range tx.currencies() {
+				effect := tx.balanceEffect(userID, currency)
+				ledger[currency] = ledger[currency].Add(effect)
+				if effect.IsPositive() {
+					report.Credits[currency] = report.Credits[currency].Add(effect)
+				} else if effect.IsNegative() {
+					report.Debits[currency] = report.Debits[currency].Sub(effect)
+				}
+			}
+			if !seen[tx.ID] {
+				seen[tx.ID] = true
+				replay.apply(tx)
+			}
+		}
+		it.Close()
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, currency := range unionCurrencies(stored, ledger) {
+			if !stored[currency].Equal(ledger[currency]) {
+				report.Discrepancies = append(report.Discrepancies, Discrepancy{
+					Kind: DiscrepancyBalance, UserID: userID, Currency: currency,
+					Expected: ledger[currency], Actual: stored[currency],
+				})
+			}
+		}
+	}
+	report.Transactions = len(seen)
+
+	net := make(map[string]decimal.Decimal)
+	for currency, credits := range report.Credits {
+		net[currency] = credits
+	}
+	for currency, debits := range report.Debits {
+		net[currency] = net[currency].Sub(debits)
+	}
+	issued := make(map[string]decimal.Decimal)
+	for currency, supply := range replay.supply {
+		issued[currency] = supply.Sub(replay.inTransit[currency])
+	}
+	report.Discrepancies = append(report.Discrepancies, compareAmounts(DiscrepancyLedger, "", issued, net)...)
+
+	ws.mu.RLock()
+	report.Discrepancies = append(report.Discrepancies, compareAmounts(DiscrepancySupply, "", replay.supply, ws.supply.supply)...)
+	report.Discrepancies = append(report.Discrepancies, compareAmounts(DiscrepancyInTransit, "", replay.inTransit, ws.supply.inTransit)...)
+	for _, account := range []string{SystemTreasury, SystemExternal, SystemFX} {
+		report.Discrepancies = append(report.Discrepancies, compareAmounts(DiscrepancySystem, account, replay.system[account], ws.supply.system[account])...)
+	}
+	ws.mu.RUnlock()
+
+	ws.alertReconciliation(report)
+	return report, nil
+}
+
+// alertReconciliation reports a reconciliation's discrepancies to metrics and, when
+// there are any, to the notifier
+func (ws *WalletService) alertReconciliation(report *ReconciliationReport) {
+	for _, d := range report.Discrepancies {
+		ws.metrics.IncCounter("reconciliation_discrepancies_total", map[string]string{"kind": string(d.Kind), "currency": d.Currency})
+	}
+	if report.OK() {
+		return
+	}
+	ws.notifier.Notify(Notification{
+		Type:    "reconciliation_discrepancy",
+		Subject: "Reconciliation found discrepancies",
+		Message: fmt.Sprintf("%d discrepancies across %d wallets and %d transactions",
+			len(report.Discrepancies), report.Wallets, report.Transactions),
+		Data: map[string]string{
+			"discrepancies": fmt.Sprint(len(report.Discrepancies)),
+			"first_kind":    string(report.Discrepancies[0].Kind),
+		},
+		Timestamp: report.StartedAt,
+	})
+}
+
+// currencies returns the currencies tx moves: its own and, for conversions, the target
+func (tx *Transaction) currencies() []string {
+	if tx.Type == TransactionConversion && tx.ToCurrency != "" {
+		return []string{tx.currencyOf(), tx.ToCurrency}
+	}
+	return []string{tx.currencyOf()}
+}
+
+// compareAmounts returns a discrepancy of kind for every currency in which actual
+// differs from expected, sorted by currency
+func compareAmounts(kind DiscrepancyKind, userID string, expected, actual map[string]decimal.Decimal) []Discrepancy {
+	var discrepancies []Discrepancy
+	for _, currency := range unionCurrencies(expected, actual) {
+		if !expected[currency].Equal(actual[currency]) {
+			discrepancies = append(discrepancies, Discrepancy{
+				Kind: kind, UserID: userID, Currency: currency,
+				Expected: expected[currency], Actual: actual[currency],
+			})
+		}
+	}
+	return discrepancies
+}
+
+// unionCurrencies returns the currencies keyed in any of maps, sorted
+func unionCurrencies(maps ...map[string]decimal.Decimal) []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, m := range maps {
+		for currency := range m {
+			if !seen[currency] {
+				seen[currency] = true
+				currencies = append(currencies, currency)
+			}
+		}
+	}
+	sort.Strings(currencies)
+	return currencies
+}
@@ -0,0 +1,102 @@
+// internal/wallet/reconcile_test.go
+package wallet
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(ws *WalletService)
+		want    []Discrepancy
+	}{
+		{"consistent", func(ws *WalletService) {}, nil},
+		{"stored balance drifted", func(ws *WalletService) {
+			ws.wallets["bob"].Balance = ws.wallets["bob"].Balance.Add(decimal.NewFromInt(5))
+		}, []Discrepancy{
+			{Kind: DiscrepancyBalance, UserID: "bob", Currency: "USD", Expected: decimal.NewFromInt(30), Actual: decimal.NewFromInt(35)},
+		}},
+		{"tracked supply drifted", func(ws *WalletService) {
+			ws.supply.supply["USD"] = ws.supply.supply["USD"].Sub(decimal.NewFromInt(10))
+			ws.supply.system[SystemTreasury]["USD"] = decimal.Zero
+		}, []Discrepancy{
+			{Kind: DiscrepancySupply, Currency: "USD", Expected: decimal.NewFromInt(115), Actual: decimal.NewFromInt(105)},
+			{Kind: DiscrepancySystem, UserID: SystemTreasury, Currency: "USD", Expected: decimal.NewFromInt(-25), Actual: decimal.Zero},
+		}},
+		{"logged amount altered", func(ws *WalletService) {
+			for _, tx := range ws.transactions {
+				if tx.Type == TransactionMint {
+					tx.Amount = decimal.NewFromInt(20)
+				}
+			}
+		}, []Discrepancy{
+			{Kind: DiscrepancyBalance, UserID: "carol", Currency: "USD", Expected: decimal.NewFromInt(20), Actual: decimal.NewFromInt(25)},
+			{Kind: DiscrepancySupply, Currency: "USD", Expected: decimal.NewFromInt(110), Actual: decimal.NewFromInt(115)},
+			{Kind: DiscrepancySystem, UserID: SystemTreasury, Currency: "USD", Expected: decimal.NewFromInt(-20), Actual: decimal.NewFromInt(-25)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			ws, _ := newTestService(WithNotifier(notifier))
+			ws.CreateUser("alice", "Alice", "alice@example.com")
+			ws.CreateUser("bob", "Bob", "bob@example.com")
+			ws.Deposit("alice", 100, "seed")
+			ws.CreateUser("carol", "Carol", "carol@example.com")
+			ws.Transfer("alice", "bob", 30, "rent")
+			ws.Withdraw("alice", 10, "cash")
+			ws.Mint("carol", decimal.NewFromInt(25))
+			ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(20), "travel")
+			tt.corrupt(ws)
+
+			report, err := ws.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if report.Wallets != 3 {
+				t.Errorf("Wallets = %d, want 3", report.Wallets)
+			}
+			if report.OK() != (len(tt.want) == 0) || len(report.Discrepancies) != len(tt.want) {
+				t.Fatalf("Discrepancies = %+v, want %+v", report.Discrepancies, tt.want)
+			}
+			for i, want := range tt.want {
+				got := report.Discrepancies[i]
+				if got.Kind != want.Kind || got.UserID != want.UserID || got.Currency != want.Currency ||
+					!got.Expected.Equal(want.Expected) || !got.Actual.Equal(want.Actual) {
+					t.Errorf("Discrepancies[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+
+			var alerted bool
+			for _, n := range notifier.all() {
+				alerted = alerted || n.Type == "reconciliation_discrepancy"
+			}
+			if alerted == report.OK() {
+				t.Errorf("alerted = %v with %d discrepancies", alerted, len(report.Discrepancies))
+			}
+		})
+	}
+}
+
+func TestReconcile_Totals(t *testing.T) {
+	ws, _ := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Transfer("alice", "bob", 30, "rent")
+	ws.Withdraw("alice", 10, "cash")
+
+	report, err := ws.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	// The deposit and bob's side of the transfer are credits; the withdrawal and alice's
+	// side of the transfer are debits
+	if report.Transactions != 3 || !report.Credits["USD"].Equal(decimal.NewFromInt(130)) || !report.Debits["USD"].Equal(decimal.NewFromInt(40)) {
+		t.Errorf("report = %d transactions, %s credits, %s debits; want 3, 130, 40",
+			report.Transactions, report.Credits["USD"], report.Debits["USD"])
+	}
+}
@@ -0,0 +1,185 @@
+// internal/wallet/refunds.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for refunds
+var (
+	ErrNotRefundable = errors.New("transaction cannot be refunded")
+	ErrOverRefund    = errors.New("refund exceeds the remaining refundable amount")
+)
+
+// OverRefundError reports a refund larger than what is left of the original
+type OverRefundError struct {
+	TxID      string
+	Requested decimal.Decimal
+	Remaining decimal.Decimal
+}
+
+func (e *OverRefundError) Error() string {
+	return fmt.Sprintf("refund of %s on %s exceeds the remaining refundable amount %s", e.Requested, e.TxID, e.Remaining)
+}
+
+// Is matches ErrOverRefund
+func (e *OverRefundError) Is(target error) bool { return target == ErrOverRefund }
+
+// metaRefundOf marks a refund with its original, also while it is held for review
+const metaRefundOf = "refund_of"
+
+// refundableTypes maps a refundable transaction type to the type of its refunds
+var refundableTypes = map[TransactionType]TransactionType{
+	TransactionTransfer:    TransactionRefund,
+	TransactionCardCapture: TransactionCardRefund,
+}
+
+// refundBook tracks how much of each original has been refunded, including refunds
+// still in flight
+type refundBook struct {
+	mu       sync.Mutex
+	refunded map[string]decimal.Decimal
+}
+
+// RefundTransaction returns amount of a transfer or card capture to the payer. Several
+// partial refunds may be made until the original amount is used up; a larger refund
+// fails with an *OverRefundError carrying the remaining amount. Transfer refunds move
+// money back from the recipient; card refunds are credited from the merchant. Refunds
+// point at the original through ParentTxID.
+func (ws *WalletService) RefundTransaction(txID string, amount decimal.Decimal, reason string) (*Transaction, error) {
+	return ws.RefundTransactionContext(context.Background(), txID, amount, reason)
+}
+
+// RefundTransactionContext is RefundTransaction on behalf of a caller's ctx
+func (ws *WalletService) RefundTransactionContext(ctx context.Context, txID string, amount decimal.Decimal, reason string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+	original, err := ws.findTransaction(txID)
+	if err != nil {
+		return nil, err
+	}
+	refundType, ok := refundableTypes[original.Type]
+	if !ok {
+		return nil, ErrNotRefundable
+	}
+
+	if err := ws.reserveRefund(original, amount); err != nil {
+		return nil, err
+	}
+
+	description := "refund of " + original.ID
+	if reason != "" {
+		description += ": " + reason
+	}
+	var refund *Transaction
+	switch refundType {
+	case TransactionRefund:
+		refund, err = ws.transfer(ctx, original.ToUserID, original.FromUserID, amount, description, transferOptions{
+			skipBlockCheck: true,
+			metadata:       map[string]string{metaRefundOf: original.ID},
+			refundOf:       original.ID,
+		})
+	case TransactionCardRefund:
+		refund = &Transaction{
+			FromUserID:  original.ToUserID,
+			ToUserID:    original.FromUserID,
+			Amount:      amount,
+			Currency:    original.Currency,
+			Type:        TransactionCardRefund,
+			Description: description,
+			Metadata:    map[string]string{metaRefundOf: original.ID},
+			ParentTxID:  original.ID,
+		}
+		err = ws.postCredit(ctx, refund)
+	}
+
+	// A refund held for review has left the refunder's wallet and counts until the case
+	// is reversed
+	if err != nil && !errors.Is(err, ErrTransferHeld) {
+		ws.releaseRefund(original.ID, amount)
+		return nil, err
+	}
+	return refund, err
+}
+
+// GetRefundableAmount returns how much of a transfer or card capture can still be
+// refunded
+func (ws *WalletService) GetRefundableAmount(txID string) (decimal.Decimal, error) {
+	original, err := ws.findTransaction(txID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if _, ok := refundableTypes[original.Type]; !ok {
+		return decimal.Zero, ErrNotRefundable
+	}
+
+	ws.refunds.mu.Lock()
+	defer ws.refunds.mu.Unlock()
+	return original.Amount.Sub(ws.refunds.refunded[original.ID]), nil
+}
+
+// reserveRefund counts amount against the original before the refund is posted, so
+// concurrent refunds cannot together exceed it
+func (ws *WalletService) reserveRefund(original *Transaction, amount decimal.Decimal) error {
+	ws.refunds.mu.Lock()
+	defer ws.refunds.mu.Unlock()
+
+	if ws.refunds.refunded == nil {
+		ws.refunds.refunded = make(map[string]decimal.Decimal)
+	}
+	remaining := original.Amount.Sub(ws.refunds.refunded[original.ID])
+	if amount.GreaterThan(remaining) {
+		return &OverRefundError{TxID: original.ID, Requested: amount, Remaining: remaining}
+	}
+	ws.refunds.refunded[original.ID] = ws.refunds.refunded[original.ID].Add(amount)
+	return nil
+}
+
+// releaseRefund gives amount back to the original's refundable remainder
+func (ws *WalletService) releaseRefund(originalID string, amount decimal.Decimal) {
+	ws.refunds.mu.Lock()
+	defer ws.refunds.mu.Unlock()
+	ws.refunds.refunded[originalID] = ws.refunds.refunded[originalID].Sub(amount)
+}
+
+// cancelHeldRefund releases a refund whose compliance hold was reversed
+func (ws *WalletService) cancelHeldRefund(heldTxID string, amount decimal.Decimal) {
+	held, err := ws.findTransaction(heldTxID)
+	if err != nil || held.Metadata[metaRefundOf] == "" {
+		return
+	}
+	ws.releaseRefund(held.Metadata[metaRefundOf], amount)
+}
+
+// rebuildRefunds recomputes refunded totals after a restore from history, every
+// wallet's history archived entries included, so refunds of archived transactions
+// still count
+func (ws *WalletService) rebuildRefunds(history []*Transaction) {
+	reversed := make(map[string]bool)
+	for _, tx := range history {
+		if tx.Type == TransactionHoldReversal {
+			reversed[tx.ParentTxID] = true
+		}
+	}
+	refunded := make(map[string]decimal.Decimal)
+	for _, tx := range history {
+		originalID := tx.Metadata[metaRefundOf]
+		if originalID == "" || reversed[tx.ID] {
+			continue
+		}
+		refunded[originalID] = refunded[originalID].Add(tx.Amount)
+	}
+
+	ws.refunds.mu.Lock()
+	defer ws.refunds.mu.Unlock()
+	ws.refunds.refunded = refunded
+}
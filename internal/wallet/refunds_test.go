@@ -0,0 +1,191 @@
+// internal/wallet/refunds_test.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRefundTransaction_PartialRefunds(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	original, _ := ws.transfer(context.Background(), "alice", "shop", decimal.NewFromInt(60), "order", transferOptions{})
+
+	tests := []struct {
+		name          string
+		amount        string
+		wantErr       error
+		wantRemaining string
+	}{
+		{"first partial", "25", nil, "35"},
+		{"second partial", "10.5", nil, "24.5"},
+		{"over the remainder", "24.51", ErrOverRefund, "24.5"},
+		{"zero", "0", ErrInvalidAmount, "24.5"},
+		{"the rest", "24.5", nil, "0"},
+		{"nothing left", "0.01", ErrOverRefund, "0"},
+	}
+	for _, tt := range tests {
+		refund, err := ws.RefundTransaction(original.ID, decimal.RequireFromString(tt.amount), "returned item")
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: RefundTransaction() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && (refund.Type != TransactionRefund || refund.ParentTxID != original.ID || refund.FromUserID != "shop") {
+			t.Errorf("%s: refund = %+v", tt.name, refund)
+		}
+		var over *OverRefundError
+		if errors.As(err, &over) && over.Remaining.String() != tt.wantRemaining {
+			t.Errorf("%s: OverRefundError.Remaining = %s, want %s", tt.name, over.Remaining, tt.wantRemaining)
+		}
+		if remaining, _ := ws.GetRefundableAmount(original.ID); remaining.String() != tt.wantRemaining {
+			t.Errorf("%s: GetRefundableAmount() = %s, want %s", tt.name, remaining, tt.wantRemaining)
+		}
+	}
+
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("alice balance = %s, want 100 after a full refund", b)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v", deviations)
+	}
+
+	restored, _ := RestoreSnapshot(ws.Snapshot())
+	if remaining, _ := restored.GetRefundableAmount(original.ID); !remaining.IsZero() {
+		t.Errorf("restored GetRefundableAmount() = %s, want 0", remaining)
+	}
+}
+
+func TestRefundTransaction_RestoredAfterArchiving(t *testing.T) {
+	archive := NewMemoryArchive()
+	ws, clock := newTestService(WithArchive(archive))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	original, _ := ws.transfer(context.Background(), "alice", "shop", decimal.NewFromInt(60), "order", transferOptions{})
+	if _, err := ws.RefundTransaction(original.ID, decimal.NewFromInt(25), "returned item"); err != nil {
+		t.Fatalf("RefundTransaction() error = %v", err)
+	}
+	clock.Advance(time.Hour)
+	if moved, err := ws.ArchiveTransactionsBefore(clock.Now().Unix()); err != nil || moved != 3 {
+		t.Fatalf("ArchiveTransactionsBefore() = %d, %v, want 3", moved, err)
+	}
+
+	restored, err := RestoreSnapshot(ws.Snapshot(), WithClock(clock.Now), WithArchive(archive))
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	if remaining, _ := restored.GetRefundableAmount(original.ID); !remaining.Equal(decimal.NewFromInt(35)) {
+		t.Errorf("restored GetRefundableAmount() = %s, want 35", remaining)
+	}
+	if _, err := restored.RefundTransaction(original.ID, decimal.NewFromInt(36), "returned item"); !errors.Is(err, ErrOverRefund) {
+		t.Errorf("over-refund after restore error = %v, want %v", err, ErrOverRefund)
+	}
+	if _, err := restored.RefundTransaction(original.ID, decimal.NewFromInt(35), "returned item"); err != nil {
+		t.Errorf("refunding the rest after restore error = %v", err)
+	}
+}
+
+func TestRefundTransaction_CardCaptureAndErrors(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 100, "seed")
+	card, _ := ws.IssueCard("alice", "main", CardLimits{}, time.Hour)
+	auth, _ := ws.AuthorizeCard(CardAuthRequest{CardID: card.ID, Amount: decimal.NewFromInt(40), Merchant: "grocer"})
+	captured, _ := ws.CaptureAuthorization(auth.ID, decimal.NewFromInt(40))
+
+	var capture *Transaction
+	for _, id := range captured.TransactionIDs {
+		if tx, _ := ws.GetTransaction(id); tx != nil && tx.Type == TransactionCardCapture {
+			capture = tx
+		}
+	}
+	if capture == nil {
+		t.Fatalf("no capture among %v", captured.TransactionIDs)
+	}
+
+	refund, err := ws.RefundTransaction(capture.ID, decimal.NewFromInt(15), "damaged")
+	if err != nil || refund.Type != TransactionCardRefund {
+		t.Fatalf("RefundTransaction(capture) = %+v, %v", refund, err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(75)) {
+		t.Errorf("alice balance = %s, want 75", b)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v", deviations)
+	}
+
+	history, _ := ws.GetTransactionHistory("alice")
+	if _, err := ws.RefundTransaction(history[0].ID, decimal.NewFromInt(1), ""); err != ErrNotRefundable {
+		t.Errorf("RefundTransaction(deposit) error = %v, want %v", err, ErrNotRefundable)
+	}
+	if _, err := ws.GetRefundableAmount("nope"); err != ErrTransactionNotFound {
+		t.Errorf("GetRefundableAmount(unknown) error = %v, want %v", err, ErrTransactionNotFound)
+	}
+}
+
+func TestRefundTransaction_FailedAndHeldRefunds(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	original, _ := ws.transfer(context.Background(), "alice", "shop", decimal.NewFromInt(50), "order", transferOptions{})
+	ws.Withdraw("shop", 45, "payout")
+
+	// The shop cannot cover the refund; the attempt does not use up the refundable amount
+	if _, err := ws.RefundTransaction(original.ID, decimal.NewFromInt(20), ""); err != ErrInsufficientBalance {
+		t.Fatalf("RefundTransaction() error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if remaining, _ := ws.GetRefundableAmount(original.ID); !remaining.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("GetRefundableAmount() after failure = %s, want 50", remaining)
+	}
+
+	// A held refund counts until its case is reversed
+	ws.RegisterHoldRule("all", func(op *Operation) string { return "review" })
+	if _, err := ws.RefundTransaction(original.ID, decimal.NewFromInt(5), ""); err != ErrTransferHeld {
+		t.Fatalf("RefundTransaction() error = %v, want %v", err, ErrTransferHeld)
+	}
+	if remaining, _ := ws.GetRefundableAmount(original.ID); !remaining.Equal(decimal.NewFromInt(45)) {
+		t.Errorf("GetRefundableAmount() while held = %s, want 45", remaining)
+	}
+	ws.ResolveCase(ws.ListCases(CaseOpen)[0].ID, "reviewer", false, "")
+	if remaining, _ := ws.GetRefundableAmount(original.ID); !remaining.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("GetRefundableAmount() after reversal = %s, want 50", remaining)
+	}
+}
+
+func TestRefundTransaction_ConcurrentRefundsNeverExceedOriginal(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.Deposit("alice", 100, "seed")
+	original, _ := ws.transfer(context.Background(), "alice", "shop", decimal.NewFromInt(10), "order", transferOptions{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ws.RefundTransaction(original.ID, decimal.NewFromInt(1), ""); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 10 {
+		t.Errorf("succeeded refunds = %d, want 10", succeeded)
+	}
+	if b, _ := ws.GetBalanceDecimal("shop"); !b.IsZero() {
+		t.Errorf("shop balance = %s, want 0", b)
+	}
+}
@@ -0,0 +1,376 @@
+// internal/wallet/reservations.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for order reservations
+var (
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationClosed    = errors.New("reservation is no longer open")
+	ErrSettleExceedsReserve = errors.New("settlement exceeds the reserved remainder")
+	ErrOrderReserved        = errors.New("order already has an open reservation")
+	ErrInvalidReservation   = errors.New("reservation needs an order, a buyer, another user as seller and a future deadline")
+)
+
+// ReservationStatus is the state of an order reservation
+type ReservationStatus string
+
+const (
+	ReservationOpen     ReservationStatus = "open"
+	ReservationSettled  ReservationStatus = "settled" // every reserved unit paid to the seller
+	ReservationReleased ReservationStatus = "released"
+	ReservationExpired  ReservationStatus = "expired" // remainder released at the deadline
+)
+
+// ReservationRequest reserves Amount of BuyerID's balance for an order from SellerID
+// until Deadline
+type ReservationRequest struct {
+	OrderID  string
+	BuyerID  string
+	SellerID string
+	Amount   decimal.Decimal
+	Deadline time.Time
+}
+
+// ReservationSettlement is one payment to the seller out of a reservation, typically
+// for the items of one shipment
+type ReservationSettlement struct {
+	TransactionID string
+	Amount        decimal.Decimal
+	Reference     string // shipment or item reference given by the marketplace
+	At            int64
+}
+
+// Reservation is a marketplace variant of a hold: the buyer's funds are reserved when
+// the order is placed and paid to the seller in parts as items ship. Whatever is not
+// settled by the deadline is released back to the buyer.
+type Reservation struct {
+	ID          string
+	OrderID     string
+	BuyerID     string
+	SellerID    string
+	Amount      decimal.Decimal
+	Currency    string
+	Settled     decimal.Decimal
+	Status      ReservationStatus
+	Settlements []ReservationSettlement
+	Deadline    int64
+	CreatedAt   int64
+	UpdatedAt   int64
+
+	jobID    string
+	settling decimal.Decimal // settlements in flight, counted against the remainder
+}
+
+// Remaining returns the amount still reserved for the seller
+func (r *Reservation) Remaining() decimal.Decimal {
+	if r.Status != ReservationOpen {
+		return decimal.Zero
+	}
+	return r.Amount.Sub(r.Settled).Sub(r.settling)
+}
+
+// copy returns a snapshot safe to hand to callers
+func (r *Reservation) copy() *Reservation {
+	c := *r
+	c.Settlements = slices.Clone(r.Settlements)
+	return &c
+}
+
+// reservationBook holds order reservations
+type reservationBook struct {
+	mu           sync.Mutex
+	reservations map[string]*Reservation
+	byOrder      map[string]string // order ID -> latest reservation
+}
+
+// Reserve places an order reservation. The amount leaves the buyer's available balance
+// at once and the reservation is checked like a transfer to the seller.
+func (ws *WalletService) Reserve(req ReservationRequest) (*Reservation, error) {
+	return ws.ReserveContext(context.Background(), req)
+}
+
+// ReserveContext is Reserve on behalf of a caller's ctx
+func (ws *WalletService) ReserveContext(ctx context.Context, req ReservationRequest) (*Reservation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	req.OrderID = strings.TrimSpace(req.OrderID)
+	now := ws.now()
+	if req.OrderID == "" || req.BuyerID == "" || req.BuyerID == req.SellerID || !req.Deadline.After(now) {
+		return nil, ErrInvalidReservation
+	}
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	userLock := ws.userLocks.getLock(req.BuyerID)
+	userLock.Lock()
+	defer userLock.Unlock()
+
+	ws.mu.RLock()
+	buyer, buyerExists := ws.wallets[req.BuyerID]
+	seller, sellerExists := ws.wallets[req.SellerID]
+	ws.mu.RUnlock()
+	if !buyerExists || !sellerExists {
+		return nil, ErrUserNotFound
+	}
+	if buyer.Currency != seller.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	if ws.IsBlocked(req.SellerID, req.BuyerID) {
+		return nil, ErrCounterpartyBlocked
+	}
+	draft := &Transaction{FromUserID: req.BuyerID, ToUserID: req.SellerID, Amount: req.Amount, Currency: buyer.Currency, Type: TransactionTransfer}
+	if err := ws.checkAmount(draft.Currency, req.Amount, true); err != nil {
+		return nil, err
+	}
+	if err := ws.validate(draft); err != nil {
+		return nil, err
+	}
+
+	r := &Reservation{
+		ID:        ws.newID("resv"),
+		OrderID:   req.OrderID,
+		BuyerID:   req.BuyerID,
+		SellerID:  req.SellerID,
+		Amount:    req.Amount,
+		Currency:  buyer.Currency,
+		Settled:   decimal.Zero,
+		Status:    ReservationOpen,
+		Deadline:  req.Deadline.Unix(),
+		CreatedAt: now.Unix(),
+		UpdatedAt: now.Unix(),
+	}
+	minimum := ws.minimumFor(r.BuyerID, r.Currency, draft.Type)
+	ws.reservations.mu.Lock()
+	if id, exists := ws.reservations.byOrder[r.OrderID]; exists && ws.reservations.reservations[id].Status == ReservationOpen {
+		ws.reservations.mu.Unlock()
+		return nil, ErrOrderReserved
+	}
+	buyer.mu.Lock()
+	if buyer.available(r.Currency).LessThan(r.Amount) {
+		buyer.mu.Unlock()
+		ws.reservations.mu.Unlock()
+		return nil, ErrInsufficientBalance
+	}
+	if err := buyer.checkMinimum(r.Currency, r.Amount, decimal.Zero, minimum); err != nil {
+		buyer.mu.Unlock()
+		ws.reservations.mu.Unlock()
+		return nil, err
+	}
+	buyer.hold(r.Currency, r.Amount)
+	buyer.mu.Unlock()
+	if ws.reservations.reservations == nil {
+		ws.reservations.reservations = make(map[string]*Reservation)
+		ws.reservations.byOrder = make(map[string]string)
+	}
+	ws.reservations.reservations[r.ID] = r
+	ws.reservations.byOrder[r.OrderID] = r.ID
+	ws.reservations.mu.Unlock()
+
+	jobID := ws.schedule("reservation_expiry", r.BuyerID, req.Deadline, nil, func(now time.Time) error {
+		return ws.expireReservation(r.ID)
+	})
+	ws.reservations.mu.Lock()
+	r.jobID = jobID
+	copied := r.copy()
+	ws.reservations.mu.Unlock()
+
+	ws.metrics.IncCounter("reservations_total", map[string]string{"status": string(ReservationOpen)})
+	return copied, nil
+}
+
+// SettleReservation pays amount of an open reservation to the seller, e.g. when some of
+// the order's items ship. A reservation may be settled many times until nothing
+// remains; it is then closed as settled. A settlement held for compliance review
+// counts as made.
+func (ws *WalletService) SettleReservation(reservationID string, amount decimal.Decimal, reference string) (*Reservation, error) {
+	return ws.SettleReservationContext(context.Background(), reservationID, amount, reference)
+}
+
+// SettleReservationContext is SettleReservation on behalf of a caller's ctx
+func (ws *WalletService) SettleReservationContext(ctx context.Context, reservationID string, amount decimal.Decimal, reference string) (*Reservation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	ws.reservations.mu.Lock()
+	r, err := ws.reservations.open(reservationID)
+	if err != nil {
+		ws.reservations.mu.Unlock()
+		return nil, err
+	}
+	if amount.GreaterThan(r.Remaining()) {
+		ws.reservations.mu.Unlock()
+		return nil, ErrSettleExceedsReserve
+	}
+	r.settling = r.settling.Add(amount)
+	snapshot := *r
+	ws.reservations.mu.Unlock()
+
+	tx, err := ws.transfer(ctx, snapshot.BuyerID, snapshot.SellerID, amount, "order "+snapshot.OrderID, transferOptions{
+		metadata: map[string]string{"order_id": snapshot.OrderID, "reservation_id": snapshot.ID, "settlement_ref": reference},
+		unhold:   amount,
+	})
+	held := errors.Is(err, ErrTransferHeld)
+
+	ws.reservations.mu.Lock()
+	r.settling = r.settling.Sub(amount)
+	if err != nil && !held {
+		ws.reservations.mu.Unlock()
+		return nil, err
+	}
+	r.Settled = r.Settled.Add(amount)
+	r.Settlements = append(r.Settlements, ReservationSettlement{TransactionID: tx.ID, Amount: amount, Reference: reference, At: tx.Timestamp})
+	r.UpdatedAt = tx.Timestamp
+	if r.Settled.Equal(r.Amount) {
+		r.Status = ReservationSettled
+	}
+	copied := r.copy()
+	ws.reservations.mu.Unlock()
+
+	if copied.Status == ReservationSettled {
+		ws.CancelJob(copied.jobID)
+		ws.metrics.IncCounter("reservations_total", map[string]string{"status": string(ReservationSettled)})
+	}
+	if held {
+		return copied, err
+	}
+	return copied, nil
+}
+
+// ReleaseReservation closes an open reservation and returns its unsettled remainder
+// to the buyer, as when the rest of an order is cancelled
+func (ws *WalletService) ReleaseReservation(reservationID string) (*Reservation, error) {
+	return ws.ReleaseReservationContext(context.Background(), reservationID)
+}
+
+// ReleaseReservationContext is ReleaseReservation on behalf of a caller's ctx
+func (ws *WalletService) ReleaseReservationContext(ctx context.Context, reservationID string) (*Reservation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ws.closeReservation(reservationID, ReservationReleased)
+}
+
+// GetReservation returns a reservation by ID
+func (ws *WalletService) GetReservation(reservationID string) (*Reservation, error) {
+	ws.reservations.mu.Lock()
+	defer ws.reservations.mu.Unlock()
+
+	r, exists := ws.reservations.reservations[reservationID]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+	return r.copy(), nil
+}
+
+// GetOrderReservation returns the latest reservation of an order, reporting how much
+// of it is settled, what remains reserved and whether it is still open
+func (ws *WalletService) GetOrderReservation(orderID string) (*Reservation, error) {
+	ws.reservations.mu.Lock()
+	defer ws.reservations.mu.Unlock()
+
+	id, exists := ws.reservations.byOrder[orderID]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+	return ws.reservations.reservations[id].copy(), nil
+}
+
+// ListReservations returns the open reservations userID buys or sells in, oldest first
+func (ws *WalletService) ListReservations(userID string) []Reservation {
+	ws.reservations.mu.Lock()
+	defer ws.reservations.mu.Unlock()
+
+	var list []Reservation
+	for _, r := range ws.reservations.reservations {
+		if r.Status == ReservationOpen && (r.BuyerID == userID || r.SellerID == userID) {
+			list = append(list, *r.copy())
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt != list[j].CreatedAt {
+			return list[i].CreatedAt < list[j].CreatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// expireReservation releases what a reservation still holds at its deadline
+func (ws *WalletService) expireReservation(reservationID string) error {
+	_, err := ws.closeReservation(reservationID, ReservationExpired)
+	if errors.Is(err, ErrReservationClosed) {
+		return nil
+	}
+	return err
+}
+
+// closeReservation moves an open reservation to status and frees its remainder.
+// Settlements in flight keep their share held until they finish.
+func (ws *WalletService) closeReservation(reservationID string, status ReservationStatus) (*Reservation, error) {
+	ws.reservations.mu.Lock()
+	r, err := ws.reservations.open(reservationID)
+	if err != nil {
+		ws.reservations.mu.Unlock()
+		return nil, err
+	}
+	remainder := r.Remaining()
+	r.Status = status
+	r.UpdatedAt = ws.now().Unix()
+	copied := r.copy()
+	ws.reservations.mu.Unlock()
+
+	ws.mu.RLock()
+	buyer := ws.wallets[copied.BuyerID]
+	ws.mu.RUnlock()
+	if buyer != nil {
+		buyer.mu.Lock()
+		buyer.hold(copied.Currency, remainder.Neg())
+		buyer.mu.Unlock()
+	}
+	if status != ReservationExpired {
+		ws.CancelJob(copied.jobID)
+	}
+	ws.metrics.IncCounter("reservations_total", map[string]string{"status": string(status)})
+	return copied, nil
+}
+
+// releaseReservations releases every open reservation userID buys or sells in and
+// returns how many
+func (ws *WalletService) releaseReservations(userID string) int {
+	released := 0
+	for _, r := range ws.ListReservations(userID) {
+		if _, err := ws.ReleaseReservation(r.ID); err == nil {
+			released++
+		}
+	}
+	return released
+}
+
+// open returns an open reservation. Caller must hold b.mu.
+func (b *reservationBook) open(reservationID string) (*Reservation, error) {
+	r, exists := b.reservations[reservationID]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+	if r.Status != ReservationOpen {
+		return nil, ErrReservationClosed
+	}
+	return r, nil
+}
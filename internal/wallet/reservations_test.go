@@ -0,0 +1,133 @@
+// internal/wallet/reservations_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSettleReservation(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("buyer", "Buyer", "b@example.com")
+	ws.CreateUser("seller", "Seller", "s@example.com")
+	ws.Deposit("buyer", 100, "seed")
+	r, err := ws.Reserve(ReservationRequest{OrderID: "order-1", BuyerID: "buyer", SellerID: "seller", Amount: decimal.NewFromInt(60), Deadline: clock.Now().Add(48 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	if available, _ := ws.GetAvailableBalance("buyer"); !available.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("available after reserve = %s, want 40", available)
+	}
+	if _, err := ws.Reserve(ReservationRequest{OrderID: "order-1", BuyerID: "buyer", SellerID: "seller", Amount: decimal.NewFromInt(1), Deadline: clock.Now().Add(time.Hour)}); err != ErrOrderReserved {
+		t.Errorf("second Reserve() error = %v, want %v", err, ErrOrderReserved)
+	}
+
+	tests := []struct {
+		name        string
+		amount      int64
+		wantErr     error
+		wantSettled int64
+		wantStatus  ReservationStatus
+	}{
+		{"first shipment", 25, nil, 25, ReservationOpen},
+		{"more than remains", 40, ErrSettleExceedsReserve, 25, ReservationOpen},
+		{"second shipment", 15, nil, 40, ReservationOpen},
+		{"last shipment", 20, nil, 60, ReservationSettled},
+		{"after settled", 1, ErrReservationClosed, 60, ReservationSettled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.SettleReservation(r.ID, decimal.NewFromInt(tt.amount), tt.name); err != tt.wantErr {
+				t.Fatalf("SettleReservation() error = %v, want %v", err, tt.wantErr)
+			}
+			got, _ := ws.GetOrderReservation("order-1")
+			if !got.Settled.Equal(decimal.NewFromInt(tt.wantSettled)) || got.Status != tt.wantStatus {
+				t.Errorf("reservation = settled %s, %s; want %d, %s", got.Settled, got.Status, tt.wantSettled, tt.wantStatus)
+			}
+		})
+	}
+
+	got, _ := ws.GetReservation(r.ID)
+	if len(got.Settlements) != 3 || got.Settlements[1].Reference != "second shipment" {
+		t.Errorf("settlements = %+v, want three", got.Settlements)
+	}
+	if balance, _ := ws.GetBalanceDecimal("seller"); !balance.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("seller balance = %s, want 60", balance)
+	}
+	if available, _ := ws.GetAvailableBalance("buyer"); !available.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("buyer available = %s, want 40", available)
+	}
+	if mismatch, err := ws.CheckWalletIntegrity("buyer"); err != nil || mismatch != nil {
+		t.Errorf("CheckWalletIntegrity() = %+v, %v", mismatch, err)
+	}
+}
+
+func TestReservationDeadline(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("buyer", "Buyer", "b@example.com")
+	ws.CreateUser("seller", "Seller", "s@example.com")
+	ws.Deposit("buyer", 100, "seed")
+	r, err := ws.Reserve(ReservationRequest{OrderID: "order-1", BuyerID: "buyer", SellerID: "seller", Amount: decimal.NewFromInt(60), Deadline: clock.Now().Add(48 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	ws.SettleReservation(r.ID, decimal.NewFromInt(10), "partial")
+
+	items, _ := ws.GetPendingItems("seller")
+	if len(items) != 1 || items[0].Kind != PendingReservation || !items[0].Incoming || !items[0].Amount.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("seller pending items = %+v, want the 50 still reserved", items)
+	}
+
+	clock.Advance(47 * time.Hour)
+	ws.RunDueJobs()
+	if got, _ := ws.GetReservation(r.ID); got.Status != ReservationOpen {
+		t.Fatalf("status before deadline = %s, want open", got.Status)
+	}
+	clock.Advance(time.Hour)
+	ws.RunDueJobs()
+
+	got, _ := ws.GetReservation(r.ID)
+	if got.Status != ReservationExpired || !got.Remaining().IsZero() {
+		t.Errorf("reservation after deadline = %s with %s remaining, want expired", got.Status, got.Remaining())
+	}
+	if available, _ := ws.GetAvailableBalance("buyer"); !available.Equal(decimal.NewFromInt(90)) {
+		t.Errorf("buyer available = %s, want 90", available)
+	}
+	if _, err := ws.ReleaseReservation(r.ID); err != ErrReservationClosed {
+		t.Errorf("ReleaseReservation(expired) error = %v, want %v", err, ErrReservationClosed)
+	}
+	if len(ws.ListReservations("buyer")) != 0 {
+		t.Error("expired reservation still listed")
+	}
+}
+
+func TestReserveInvalid(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("buyer", "Buyer", "b@example.com")
+	ws.CreateUser("seller", "Seller", "s@example.com")
+	ws.Deposit("buyer", 10, "seed")
+	later := clock.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		req     ReservationRequest
+		wantErr error
+	}{
+		{"no order", ReservationRequest{BuyerID: "buyer", SellerID: "seller", Amount: decimal.NewFromInt(1), Deadline: later}, ErrInvalidReservation},
+		{"self", ReservationRequest{OrderID: "o", BuyerID: "buyer", SellerID: "buyer", Amount: decimal.NewFromInt(1), Deadline: later}, ErrInvalidReservation},
+		{"past deadline", ReservationRequest{OrderID: "o", BuyerID: "buyer", SellerID: "seller", Amount: decimal.NewFromInt(1), Deadline: clock.Now()}, ErrInvalidReservation},
+		{"zero amount", ReservationRequest{OrderID: "o", BuyerID: "buyer", SellerID: "seller", Deadline: later}, ErrInvalidAmount},
+		{"unknown seller", ReservationRequest{OrderID: "o", BuyerID: "buyer", SellerID: "dave", Amount: decimal.NewFromInt(1), Deadline: later}, ErrUserNotFound},
+		{"insufficient", ReservationRequest{OrderID: "o", BuyerID: "buyer", SellerID: "seller", Amount: decimal.NewFromInt(11), Deadline: later}, ErrInsufficientBalance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ws.Reserve(tt.req); err != tt.wantErr {
+				t.Errorf("Reserve() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// internal/wallet/reserves.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/moneymath"
+)
+
+// Error definitions for rolling reserves
+var (
+	ErrInvalidReservePolicy = errors.New("reserve policy needs a percent in (0, 100], transaction types and a holding period")
+	ErrReservePolicyNotSet  = errors.New("no reserve policy for user")
+)
+
+// ReserveStatus is the state of a reserved amount
+type ReserveStatus string
+
+const (
+	ReserveHeld     ReserveStatus = "held"
+	ReserveReleased ReserveStatus = "released"
+)
+
+// ReservePolicy withholds Percent of every incoming credit of one of Types into the
+// user's reserve and releases it back after HoldFor, e.g. a rolling reserve covering a
+// merchant's chargebacks
+type ReservePolicy struct {
+	Percent decimal.Decimal
+	Types   []TransactionType
+	HoldFor time.Duration
+}
+
+// ReserveEntry is the part of one credit held in reserve
+type ReserveEntry struct {
+	ID          string
+	UserID      string
+	SourceTxID  string // credit the reserve was taken from
+	HoldTxID    string
+	ReleaseTxID string
+	Amount      decimal.Decimal
+	Currency    string
+	Status      ReserveStatus
+	HeldAt      int64
+	ReleaseAt   int64
+	ReleasedAt  int64
+}
+
+// ReserveSummary reports a user's reserve
+type ReserveSummary struct {
+	UserID        string
+	Held          decimal.Decimal // currently in reserve
+	Released      decimal.Decimal // released back so far
+	NextReleaseAt int64           // 0 when nothing is held
+	Pending       []ReserveEntry  // held entries, soonest release first
+}
+
+// reserveBook holds reserve policies and entries
+type reserveBook struct {
+	mu       sync.Mutex
+	policies map[string]ReservePolicy // by user
+	entries  map[string]*ReserveEntry
+	byUser   map[string][]string // entry IDs per user, oldest first
+}
+
+// SetReservePolicy starts reserving part of the user's qualifying credits. Reserves
+// already held keep their original release dates.
+func (ws *WalletService) SetReservePolicy(userID string, policy ReservePolicy) error {
+	if !policy.Percent.IsPositive() || policy.Percent.GreaterThan(decimal.NewFromInt(100)) ||
+		len(policy.Types) == 0 || policy.HoldFor <= 0 {
+		return ErrInvalidReservePolicy
+	}
+	for _, t := range policy.Types {
+		if t == TransactionReserveHold || t == TransactionReserveRelease {
+			return ErrInvalidReservePolicy
+		}
+	}
+
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	ws.reserves.mu.Lock()
+	defer ws.reserves.mu.Unlock()
+	if ws.reserves.policies == nil {
+		ws.reserves.policies = make(map[string]ReservePolicy)
+	}
+	policy.Types = append([]TransactionType(nil), policy.Types...)
+	ws.reserves.policies[userID] = policy
+	return nil
+}
+
+// RemoveReservePolicy stops reserving new credits; held reserves still release on schedule
+func (ws *WalletService) RemoveReservePolicy(userID string) error {
+	ws.reserves.mu.Lock()
+	defer ws.reserves.mu.Unlock()
+
+	if _, exists := ws.reserves.policies[userID]; !exists {
+		return ErrReservePolicyNotSet
+	}
+	delete(ws.reserves.policies, userID)
+	return nil
+}
+
+// GetReservePolicy returns the user's reserve policy
+func (ws *WalletService) GetReservePolicy(userID string) (*ReservePolicy, error) {
+	ws.reserves.mu.Lock()
+	defer ws.reserves.mu.Unlock()
+
+	policy, exists := ws.reserves.policies[userID]
+	if !exists {
+		return nil, ErrReservePolicyNotSet
+	}
+	policy.Types = append([]TransactionType(nil), policy.Types...)
+	return &policy, nil
+}
+
+// GetReserve reports how much of a user's money is in reserve and when it is released
+func (ws *WalletService) GetReserve(userID string) (*ReserveSummary, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	ws.reserves.mu.Lock()
+	defer ws.reserves.mu.Unlock()
+
+	summary := &ReserveSummary{UserID: userID, Held: decimal.Zero, Released: decimal.Zero}
+	for _, id := range ws.reserves.byUser[userID] {
+		e := ws.reserves.entries[id]
+		if e.Status == ReserveReleased {
+			summary.Released = summary.Released.Add(e.Amount)
+			continue
+		}
+		summary.Held = summary.Held.Add(e.Amount)
+		summary.Pending = append(summary.Pending, *e)
+	}
+	sort.SliceStable(summary.Pending, func(i, j int) bool {
+		return summary.Pending[i].ReleaseAt < summary.Pending[j].ReleaseAt
+	})
+	if len(summary.Pending) > 0 {
+		summary.NextReleaseAt = summary.Pending[0].ReleaseAt
+	}
+	return summary, nil
+}
+
+// reserveCredit moves the reserved share of a just-posted credit out of the wallet and
+// schedules its release. Credits in a currency other than the wallet's are not
+// reserved. Caller must hold the owner's user lock.
+func (ws *WalletService) reserveCredit(wallet *Wallet, credit *Transaction) {
+	ws.reserves.mu.Lock()
+	policy, exists := ws.reserves.policies[credit.ToUserID]
+	ws.reserves.mu.Unlock()
+	if !exists || credit.currencyOf() != wallet.Currency || !reservesType(policy, credit.Type) {
+		return
+	}
+
+	amount := moneymath.Percent(credit.Amount, policy.Percent, ws.currencyPrecision(credit.currencyOf()), moneymath.Down)
+	if !amount.IsPositive() {
+		return
+	}
+
+	hold := &Transaction{
+		FromUserID:  credit.ToUserID,
+		ToUserID:    credit.ToUserID,
+		Amount:      amount,
+		Currency:    credit.currencyOf(),
+		Type:        TransactionReserveHold,
+		Description: "rolling reserve for " + credit.ID,
+		ParentTxID:  credit.ID,
+	}
+	if err := ws.validate(hold); err != nil {
+		ws.metrics.IncCounter("reserve_skipped_total", map[string]string{"type": string(credit.Type)})
+		return
+	}
+
+	wallet.mu.Lock()
+	wallet.adjust(hold.Currency, amount.Neg())
+	wallet.publish()
+	wallet.mu.Unlock()
+
+	ws.stampTransaction(hold)
+	ws.recordTransaction(hold)
+
+	releaseAt := ws.now().Add(policy.HoldFor)
+	entry := &ReserveEntry{
+		ID:         ws.newID("reserve"),
+		UserID:     credit.ToUserID,
+		SourceTxID: credit.ID,
+		HoldTxID:   hold.ID,
+		Amount:     amount,
+		Currency:   hold.Currency,
+		Status:     ReserveHeld,
+		HeldAt:     hold.Timestamp,
+		ReleaseAt:  releaseAt.Unix(),
+	}
+
+	ws.reserves.mu.Lock()
+	if ws.reserves.entries == nil {
+		ws.reserves.entries = make(map[string]*ReserveEntry)
+		ws.reserves.byUser = make(map[string][]string)
+	}
+	ws.reserves.entries[entry.ID] = entry
+	ws.reserves.byUser[entry.UserID] = append(ws.reserves.byUser[entry.UserID], entry.ID)
+	ws.reserves.mu.Unlock()
+
+	ws.schedulePayment("reserve_release", entry.UserID, releaseAt, nil, func(now time.Time) error {
+		return ws.releaseReserve(entry.ID)
+	})
+	ws.metrics.IncCounter("reserve_held_total", map[string]string{"type": string(credit.Type)})
+}
+
+// releaseReserve credits a held reserve back to its owner
+func (ws *WalletService) releaseReserve(entryID string) error {
+	ws.reserves.mu.Lock()
+	entry := ws.reserves.entries[entryID]
+	if entry.Status != ReserveHeld {
+		ws.reserves.mu.Unlock()
+		return nil
+	}
+	entry.Status = ReserveReleased
+	e := *entry
+	ws.reserves.mu.Unlock()
+
+	release := &Transaction{
+		FromUserID:  e.UserID,
+		ToUserID:    e.UserID,
+		Amount:      e.Amount,
+		Currency:    e.Currency,
+		Type:        TransactionReserveRelease,
+		Description: "rolling reserve release",
+		ParentTxID:  e.HoldTxID,
+	}
+	if err := ws.postCredit(context.Background(), release); err != nil {
+		ws.reserves.mu.Lock()
+		entry.Status = ReserveHeld
+		ws.reserves.mu.Unlock()
+		return err
+	}
+
+	ws.reserves.mu.Lock()
+	entry.ReleaseTxID = release.ID
+	entry.ReleasedAt = release.Timestamp
+	ws.reserves.mu.Unlock()
+
+	ws.notify(Notification{
+		UserID:  e.UserID,
+		Type:    "reserve_released",
+		Subject: "Reserve released",
+		Message: "Reserved funds were returned to your wallet",
+		Data: map[string]string{
+			"reserve_id": e.ID,
+			"amount":     e.Amount.String(),
+		},
+		Timestamp: ws.now().Unix(),
+	})
+	return nil
+}
+
+// reservesType reports whether policy reserves credits of type t
+func reservesType(policy ReservePolicy, t TransactionType) bool {
+	for _, pt := range policy.Types {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
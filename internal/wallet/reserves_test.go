@@ -0,0 +1,97 @@
+// internal/wallet/reserves_test.go
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestReserves_RollingReserve(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.Deposit("alice", 500, "seed")
+
+	if err := ws.SetReservePolicy("shop", ReservePolicy{
+		Percent: decimal.NewFromInt(10),
+		Types:   []TransactionType{TransactionTransfer},
+		HoldFor: 48 * time.Hour,
+	}); err != nil {
+		t.Fatalf("SetReservePolicy() error = %v", err)
+	}
+
+	ws.Transfer("alice", "shop", 100, "order 1")
+	clock.Advance(24 * time.Hour)
+	ws.Transfer("alice", "shop", 50.55, "order 2")
+	ws.Deposit("shop", 20, "not reserved")
+
+	if b, _ := ws.GetBalanceDecimal("shop"); !b.Equal(decimal.RequireFromString("155.50")) {
+		t.Errorf("shop balance = %s, want 155.50", b)
+	}
+	summary, _ := ws.GetReserve("shop")
+	if !summary.Held.Equal(decimal.RequireFromString("15.05")) || len(summary.Pending) != 2 ||
+		summary.NextReleaseAt != clock.Now().Add(24*time.Hour).Unix() {
+		t.Fatalf("GetReserve() = %+v, want 15.05 held in two entries", summary)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want reserves counted in transit", deviations)
+	}
+
+	tests := []struct {
+		advance      time.Duration
+		wantHeld     string
+		wantReleased string
+		wantBalance  string
+	}{
+		{24 * time.Hour, "5.05", "10", "165.50"},
+		{24 * time.Hour, "0", "15.05", "170.55"},
+	}
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		ws.RunDueJobs()
+		summary, _ := ws.GetReserve("shop")
+		if !summary.Held.Equal(decimal.RequireFromString(tt.wantHeld)) || !summary.Released.Equal(decimal.RequireFromString(tt.wantReleased)) {
+			t.Errorf("after +%v held = %s released = %s, want %s and %s", tt.advance, summary.Held, summary.Released, tt.wantHeld, tt.wantReleased)
+		}
+		if b, _ := ws.GetBalanceDecimal("shop"); !b.Equal(decimal.RequireFromString(tt.wantBalance)) {
+			t.Errorf("after +%v balance = %s, want %s", tt.advance, b, tt.wantBalance)
+		}
+	}
+	if got := ws.ledgerBalance("shop", DefaultCurrency); !got.Equal(decimal.RequireFromString("170.55")) {
+		t.Errorf("ledger balance = %s, want 170.55", got)
+	}
+}
+
+func TestReserves_PolicyValidation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("shop", "Shop", "shop@example.com")
+
+	tests := []struct {
+		name    string
+		userID  string
+		policy  ReservePolicy
+		wantErr error
+	}{
+		{"no percent", "shop", ReservePolicy{Types: []TransactionType{TransactionDeposit}, HoldFor: time.Hour}, ErrInvalidReservePolicy},
+		{"over 100 percent", "shop", ReservePolicy{Percent: decimal.NewFromInt(101), Types: []TransactionType{TransactionDeposit}, HoldFor: time.Hour}, ErrInvalidReservePolicy},
+		{"no types", "shop", ReservePolicy{Percent: decimal.NewFromInt(5), HoldFor: time.Hour}, ErrInvalidReservePolicy},
+		{"reserve of releases", "shop", ReservePolicy{Percent: decimal.NewFromInt(5), Types: []TransactionType{TransactionReserveRelease}, HoldFor: time.Hour}, ErrInvalidReservePolicy},
+		{"no holding period", "shop", ReservePolicy{Percent: decimal.NewFromInt(5), Types: []TransactionType{TransactionDeposit}}, ErrInvalidReservePolicy},
+		{"unknown user", "ghost", ReservePolicy{Percent: decimal.NewFromInt(5), Types: []TransactionType{TransactionDeposit}, HoldFor: time.Hour}, ErrUserNotFound},
+		{"valid", "shop", ReservePolicy{Percent: decimal.NewFromInt(5), Types: []TransactionType{TransactionDeposit}, HoldFor: time.Hour}, nil},
+	}
+	for _, tt := range tests {
+		if err := ws.SetReservePolicy(tt.userID, tt.policy); err != tt.wantErr {
+			t.Errorf("%s: SetReservePolicy() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := ws.RemoveReservePolicy("shop"); err != nil {
+		t.Errorf("RemoveReservePolicy() error = %v", err)
+	}
+	if _, err := ws.GetReservePolicy("shop"); err != ErrReservePolicyNotSet {
+		t.Errorf("GetReservePolicy() error = %v, want %v", err, ErrReservePolicyNotSet)
+	}
+}
@@ -0,0 +1,294 @@
+// internal/wallet/restrict.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for restricted wallets
+var (
+	ErrStepUpRequired      = errors.New("step-up verification required while the wallet is restricted")
+	ErrRestrictedLimit     = errors.New("debit exceeds the hourly limit of a restricted wallet")
+	ErrNotRestricted       = errors.New("wallet is not restricted")
+	ErrRestrictionNoSource = errors.New("restriction source is required")
+)
+
+// RestrictionSource says who put a wallet into restricted mode
+type RestrictionSource string
+
+const (
+	RestrictionRisk  RestrictionSource = "risk"  // a risk hook flagged activity
+	RestrictionAdmin RestrictionSource = "admin" // support or fraud operations
+)
+
+// RestrictionPolicy bounds what a restricted wallet may still do
+type RestrictionPolicy struct {
+	HourlyLimit    decimal.Decimal // debits allowed per rolling hour, per currency
+	Window         time.Duration   // how long a restriction lasts before it expires
+	StepUpValidity time.Duration   // how long a completed step-up verification counts
+}
+
+// DefaultRestrictionPolicy allows 50 per hour for a day, with step-ups valid 15 minutes
+var DefaultRestrictionPolicy = RestrictionPolicy{
+	HourlyLimit:    decimal.NewFromInt(50),
+	Window:         24 * time.Hour,
+	StepUpValidity: 15 * time.Minute,
+}
+
+// Restriction is an emergency mode for a possibly compromised account. Unlike a freeze
+// it keeps the wallet usable: credits are unaffected, and debits go through in small
+// amounts once the owner has passed step-up verification. It lifts itself at ExpiresAt.
+type Restriction struct {
+	UserID    string
+	Source    RestrictionSource
+	Reason    string
+	Policy    RestrictionPolicy
+	StartedAt int64
+	ExpiresAt int64
+
+	// StepUpUntil is when the last step-up verification stops counting; 0 if none
+	StepUpUntil int64
+}
+
+// RiskHookFunc inspects a debit before it is applied and returns a non-empty reason to
+// restrict the sender's wallet. The debit that tripped the hook is then checked against
+// the restriction like any other. Risk hooks run while the affected users are locked
+// and must not call mutating WalletService methods.
+type RiskHookFunc func(op *Operation) (reason string)
+
+// riskHook is a named RiskHookFunc
+type riskHook struct {
+	name string
+	fn   RiskHookFunc
+}
+
+// restrictionBook holds active restrictions by user
+type restrictionBook struct {
+	mu           sync.Mutex
+	policy       *RestrictionPolicy // nil means DefaultRestrictionPolicy
+	restrictions map[string]*Restriction
+	hooks        []riskHook
+}
+
+// WithRestrictionPolicy sets the policy applied to newly restricted wallets
+func WithRestrictionPolicy(p RestrictionPolicy) Option {
+	return func(ws *WalletService) {
+		ws.restrictions.policy = &p
+	}
+}
+
+// RegisterRiskHook adds a named hook evaluated for every debit of an unrestricted
+// wallet, in registration order
+func (ws *WalletService) RegisterRiskHook(name string, fn RiskHookFunc) {
+	ws.restrictions.mu.Lock()
+	defer ws.restrictions.mu.Unlock()
+
+	ws.restrictions.hooks = append(ws.restrictions.hooks, riskHook{name: name, fn: fn})
+}
+
+// RestrictUser puts userID's wallet into restricted mode for the policy window.
+// Restricting an already restricted wallet restarts the window and requires a fresh
+// step-up verification.
+func (ws *WalletService) RestrictUser(userID string, source RestrictionSource, reason string) (*Restriction, error) {
+	if source == "" {
+		return nil, ErrRestrictionNoSource
+	}
+	ws.mu.RLock()
+	_, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	r := ws.restrict(userID, source, reason)
+	ws.notifyRestricted(r)
+	return r, nil
+}
+
+// restrict records a restriction and returns a copy of it
+func (ws *WalletService) restrict(userID string, source RestrictionSource, reason string) *Restriction {
+	now := ws.now()
+
+	ws.restrictions.mu.Lock()
+	defer ws.restrictions.mu.Unlock()
+
+	policy := DefaultRestrictionPolicy
+	if ws.restrictions.policy != nil {
+		policy = *ws.restrictions.policy
+	}
+	if ws.restrictions.restrictions == nil {
+		ws.restrictions.restrictions = make(map[string]*Restriction)
+	}
+	r := &Restriction{
+		UserID:    userID,
+		Source:    source,
+		Reason:    reason,
+		Policy:    policy,
+		StartedAt: now.Unix(),
+		ExpiresAt: now.Add(policy.Window).Unix(),
+	}
+	ws.restrictions.restrictions[userID] = r
+	ws.metrics.IncCounter("wallet_restrictions_total", map[string]string{"source": string(source)})
+
+	copied := *r
+	return &copied
+}
+
+// notifyRestricted tells the owner their wallet was restricted
+func (ws *WalletService) notifyRestricted(r *Restriction) {
+	ws.notify(Notification{
+		UserID:  r.UserID,
+		Type:    "wallet_restricted",
+		Subject: "Your wallet is temporarily restricted",
+		Message: fmt.Sprintf("Withdrawals and transfers are limited to %s per hour and need extra verification until %s",
+			r.Policy.HourlyLimit, time.Unix(r.ExpiresAt, 0).UTC().Format(time.RFC1123)),
+		Data:      map[string]string{"source": string(r.Source), "expires_at": fmt.Sprint(r.ExpiresAt)},
+		Timestamp: r.StartedAt,
+	})
+}
+
+// LiftRestriction ends userID's restriction before it expires
+func (ws *WalletService) LiftRestriction(userID string) error {
+	ws.restrictions.mu.Lock()
+	defer ws.restrictions.mu.Unlock()
+
+	if ws.activeRestriction(userID) == nil {
+		return ErrNotRestricted
+	}
+	delete(ws.restrictions.restrictions, userID)
+	return nil
+}
+
+// GetRestriction returns userID's active restriction
+func (ws *WalletService) GetRestriction(userID string) (*Restriction, bool) {
+	ws.restrictions.mu.Lock()
+	defer ws.restrictions.mu.Unlock()
+
+	r := ws.activeRestriction(userID)
+	if r == nil {
+		return nil, false
+	}
+	copied := *r
+	return &copied, true
+}
+
+// CompleteStepUp records that userID just passed step-up verification (a one-time
+// code, biometric or support call performed by the embedding application), allowing
+// restricted debits for the policy's StepUpValidity
+func (ws *WalletService) CompleteStepUp(userID string) error {
+	ws.restrictions.mu.Lock()
+	defer ws.restrictions.mu.Unlock()
+
+	r := ws.activeRestriction(userID)
+	if r == nil {
+		return ErrNotRestricted
+	}
+	r.StepUpUntil = ws.now().Add(r.Policy.StepUpValidity).Unix()
+	return nil
+}
+
+// activeRestriction returns userID's restriction, dropping it once expired. Caller must
+// hold ws.restrictions.mu.
+func (ws *WalletService) activeRestriction(userID string) *Restriction {
+	r, ok := ws.restrictions.restrictions[userID]
+	if !ok {
+		return nil
+	}
+	if ws.now().Unix() >= r.ExpiresAt {
+		delete(ws.restrictions.restrictions, userID)
+		ws.metrics.IncCounter("wallet_restrictions_expired_total", nil)
+		return nil
+	}
+	return r
+}
+
+// checkRestriction applies restricted mode to money leaving tx's sender: it runs the
+// risk hooks for unrestricted senders, then requires a current step-up and keeps the
+// rolling hour's debits within the limit. Caller must hold the sender's lock.
+func (ws *WalletService) checkRestriction(tx *Transaction) error {
+	if !restrictedDebit(tx) {
+		return nil
+	}
+	userID := tx.FromUserID
+
+	ws.restrictions.mu.Lock()
+	var r *Restriction
+	if active := ws.activeRestriction(userID); active != nil {
+		copied := *active
+		r = &copied
+	}
+	hooks := ws.restrictions.hooks
+	ws.restrictions.mu.Unlock()
+
+	if r == nil {
+		reason := ws.riskReason(tx, hooks)
+		if reason == "" {
+			return nil
+		}
+		r = ws.restrict(userID, RestrictionRisk, reason)
+		ws.notifyRestricted(r)
+	}
+
+	now := ws.now()
+	if now.Unix() >= r.StepUpUntil {
+		return ErrStepUpRequired
+	}
+	used := ws.restrictedVolume(userID, tx.currencyOf(), now.Add(-time.Hour).Unix())
+	if used.Add(tx.Amount).GreaterThan(r.Policy.HourlyLimit) {
+		return fmt.Errorf("%w: %s per hour, %s used", ErrRestrictedLimit, r.Policy.HourlyLimit, used)
+	}
+	return nil
+}
+
+// riskReason returns the first hook's reason to restrict tx's sender, naming the hook
+func (ws *WalletService) riskReason(tx *Transaction, hooks []riskHook) string {
+	if len(hooks) == 0 {
+		return ""
+	}
+	op := &Operation{
+		Type:        tx.Type,
+		FromUserID:  tx.FromUserID,
+		ToUserID:    tx.ToUserID,
+		Amount:      tx.Amount,
+		Currency:    tx.currencyOf(),
+		Description: tx.Description,
+		Time:        ws.now(),
+	}
+	for _, h := range hooks {
+		if reason := h.fn(op); reason != "" {
+			return h.name + ": " + reason
+		}
+	}
+	return ""
+}
+
+// restrictedVolume sums userID's restricted debits in currency since the given time
+func (ws *WalletService) restrictedVolume(userID, currency string, since int64) decimal.Decimal {
+	it := ws.iterate(userID, IterateOptions{Since: since})
+	defer it.Close()
+
+	used := decimal.Zero
+	for it.Next() {
+		tx := it.Transaction()
+		if tx.FromUserID == userID && tx.currencyOf() == currency && restrictedDebit(tx) {
+			used = used.Add(tx.Amount)
+		}
+	}
+	return used
+}
+
+// restrictedDebit reports whether tx moves money out of its sender's control.
+// Conversions stay within the wallet, and admin corrections bypass restrictions like
+// they bypass freezes.
+func restrictedDebit(tx *Transaction) bool {
+	if tx.FromUserID == "" || closureAllowedTypes[tx.Type] {
+		return false
+	}
+	kind := transactionKind(tx.Type)
+	return kind == KindDebit || kind == KindTransfer
+}
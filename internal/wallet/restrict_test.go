@@ -0,0 +1,109 @@
+// internal/wallet/restrict_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRestrictUser(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, clock := newTestService(
+		WithNotifier(notifier),
+		WithRestrictionPolicy(RestrictionPolicy{HourlyLimit: decimal.NewFromInt(30), Window: 6 * time.Hour, StepUpValidity: 10 * time.Minute}),
+	)
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 200, "seed")
+
+	if _, err := ws.RestrictUser("alice", RestrictionAdmin, "reported phishing"); err != nil {
+		t.Fatalf("RestrictUser() error = %v", err)
+	}
+	if notes := notifier.all(); len(notes) != 1 || notes[0].Type != "wallet_restricted" {
+		t.Errorf("notifications = %+v", notes)
+	}
+
+	tests := []struct {
+		name    string
+		stepUp  bool
+		advance time.Duration
+		op      func() error
+		wantErr error
+	}{
+		{"debit needs step-up", false, 0, func() error { return ws.Transfer("alice", "bob", 10, "") }, ErrStepUpRequired},
+		{"credits unaffected", false, 0, func() error { return ws.Deposit("alice", 5, "") }, nil},
+		{"incoming transfer unaffected", false, 0, func() error { ws.Deposit("bob", 5, ""); return ws.Transfer("bob", "alice", 5, "") }, nil},
+		{"within hourly limit", true, 0, func() error { return ws.Transfer("alice", "bob", 20, "") }, nil},
+		{"over hourly limit", false, 0, func() error { return ws.Withdraw("alice", 11, "") }, ErrRestrictedLimit},
+		{"rest of the hour", false, 0, func() error { return ws.Withdraw("alice", 10, "") }, nil},
+		{"step-up lapsed", false, 11 * time.Minute, func() error { return ws.Withdraw("alice", 1, "") }, ErrStepUpRequired},
+		{"next hour", true, 50 * time.Minute, func() error { return ws.Transfer("alice", "bob", 30, "") }, nil},
+		{"expired", false, 6 * time.Hour, func() error { return ws.Transfer("alice", "bob", 100, "") }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if tt.stepUp {
+				if err := ws.CompleteStepUp("alice"); err != nil {
+					t.Fatalf("CompleteStepUp() error = %v", err)
+				}
+			}
+			if err := tt.op(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, ok := ws.GetRestriction("alice"); ok {
+		t.Error("restriction still active after its window")
+	}
+	if err := ws.LiftRestriction("alice"); err != ErrNotRestricted {
+		t.Errorf("LiftRestriction(expired) error = %v, want %v", err, ErrNotRestricted)
+	}
+	if _, err := ws.RestrictUser("ghost", RestrictionAdmin, ""); err != ErrUserNotFound {
+		t.Errorf("RestrictUser(unknown) error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestRiskHookRestrictsSender(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithMetrics(metrics))
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 500, "seed")
+
+	ws.RegisterRiskHook("large_debit", func(op *Operation) string {
+		if op.Amount.GreaterThan(decimal.NewFromInt(100)) {
+			return "unusually large debit"
+		}
+		return ""
+	})
+
+	if err := ws.Transfer("alice", "bob", 80, ""); err != nil {
+		t.Fatalf("Transfer(80) error = %v", err)
+	}
+	if err := ws.Withdraw("alice", 150, ""); !errors.Is(err, ErrStepUpRequired) {
+		t.Fatalf("Withdraw(150) error = %v, want %v", err, ErrStepUpRequired)
+	}
+	r, ok := ws.GetRestriction("alice")
+	if !ok || r.Source != RestrictionRisk || r.Reason != "large_debit: unusually large debit" {
+		t.Errorf("restriction = %+v, %v", r, ok)
+	}
+	if got := metrics.Counter("wallet_restrictions_total", map[string]string{"source": "risk"}); got != 1 {
+		t.Errorf("wallet_restrictions_total = %v, want 1", got)
+	}
+
+	// Lifting by support restores normal limits
+	if err := ws.LiftRestriction("alice"); err != nil {
+		t.Fatalf("LiftRestriction() error = %v", err)
+	}
+	if err := ws.Withdraw("alice", 20, ""); err != nil {
+		t.Errorf("Withdraw after lift error = %v", err)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(400)) {
+		t.Errorf("balance = %s, want 400", b)
+	}
+}
@@ -0,0 +1,143 @@
+// internal/wallet/retention.go
+package wallet
+
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// RetentionLimit caps the in-memory hot log. Zero fields are unlimited.
+type RetentionLimit struct {
+	MaxTransactions int
+	MaxBytes        int64 // estimated from field sizes, see transactionSize
+}
+
+// RetentionStats reports the hot log's size against its limit
+type RetentionStats struct {
+	Limit           RetentionLimit
+	HotTransactions int
+	HotBytes        int64
+	Evicted         int64 // transactions moved to the archive by the limit
+}
+
+// retentionState tracks the hot log's size for the retention limit
+type retentionState struct {
+	limit    RetentionLimit
+	hotBytes int64 // guarded by ws.mu
+	evicted  int64 // guarded by ws.mu
+
+	// evictMu serialises moves from the hot log to the archive so two of them never
+	// copy the same leading run
+	evictMu sync.Mutex
+}
+
+// WithRetentionLimit caps how many transactions, or how many estimated bytes of them,
+// stay in memory. When a new transaction takes the hot log over the limit, the oldest
+// ones are moved to the archive until it is back under 90% of the limit; history and
+// exports keep reading them through the archive. The limit needs WithArchive: without
+// a cold store there is nowhere to evict to and the limit is not enforced.
+func WithRetentionLimit(limit RetentionLimit) Option {
+	return func(ws *WalletService) {
+		ws.retention.limit = limit
+	}
+}
+
+// GetRetentionStats reports the hot log's current size
+func (ws *WalletService) GetRetentionStats() RetentionStats {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	return RetentionStats{
+		Limit:           ws.retention.limit,
+		HotTransactions: len(ws.transactions),
+		HotBytes:        ws.retention.hotBytes,
+		Evicted:         ws.retention.evicted,
+	}
+}
+
+// overRetention reports whether the hot log exceeds the limit. Caller must hold ws.mu.
+func (ws *WalletService) overRetention() bool {
+	limit := ws.retention.limit
+	if ws.archive == nil {
+		return false
+	}
+	return (limit.MaxTransactions > 0 && len(ws.transactions) > limit.MaxTransactions) ||
+		(limit.MaxBytes > 0 && ws.retention.hotBytes > limit.MaxBytes)
+}
+
+// enforceRetention moves the oldest transactions to the archive while the hot log is
+// over the limit. It returns at once when another move is already under way; the next
+// append checks again. A failed archive write leaves the hot log untouched.
+func (ws *WalletService) enforceRetention() {
+	if !ws.retention.evictMu.TryLock() {
+		return
+	}
+	defer ws.retention.evictMu.Unlock()
+
+	ws.mu.RLock()
+	if !ws.overRetention() {
+		ws.mu.RUnlock()
+		return
+	}
+	limit := ws.retention.limit
+	keepCount := limit.MaxTransactions - limit.MaxTransactions/10
+	keepBytes := limit.MaxBytes - limit.MaxBytes/10
+	n, bytes := 0, ws.retention.hotBytes
+	for n < len(ws.transactions) {
+		countOK := limit.MaxTransactions <= 0 || len(ws.transactions)-n <= keepCount
+		bytesOK := limit.MaxBytes <= 0 || bytes <= keepBytes
+		if countOK && bytesOK {
+			break
+		}
+		bytes -= transactionSize(ws.transactions[n])
+		n++
+	}
+	batch := append([]*Transaction(nil), ws.transactions[:n]...)
+	ws.mu.RUnlock()
+
+	if err := ws.archiveAppend(context.Background(), batch); err != nil {
+		ws.metrics.IncCounter("retention_eviction_failures_total", nil)
+		return
+	}
+	ws.trimHotLog(batch)
+
+	ws.mu.Lock()
+	ws.retention.evicted += int64(n)
+	ws.mu.Unlock()
+	ws.metrics.ObserveValue("retention_evicted_transactions", float64(n), nil)
+}
+
+// trimHotLog drops batch, already written to the archive, from the front of the hot
+// log. Caller must hold ws.retention.evictMu.
+func (ws *WalletService) trimHotLog(batch []*Transaction) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	n := len(batch)
+	ws.transactions = append([]*Transaction(nil), ws.transactions[n:]...)
+	ws.logBase += n
+	for _, tx := range batch {
+		delete(ws.txIndex.byID, tx.ID)
+		ws.retention.hotBytes -= transactionSize(tx)
+	}
+	ws.pruneUserIndex(batch)
+}
+
+// transactionSize estimates the memory a logged transaction holds: the struct itself
+// plus its strings, approvals and metadata
+func transactionSize(tx *Transaction) int64 {
+	size := int64(unsafe.Sizeof(*tx))
+	size += int64(len(tx.ID) + len(tx.FromUserID) + len(tx.ToUserID) + len(tx.Currency) +
+		len(tx.Type) + len(tx.Description) + len(tx.ToCurrency) + len(tx.ParentTxID))
+	for _, id := range tx.RelatedTxIDs {
+		size += int64(unsafe.Sizeof(id)) + int64(len(id))
+	}
+	for _, a := range tx.Approvals {
+		size += int64(unsafe.Sizeof(a)) + int64(len(a.Step)+len(a.ReviewerID)+len(a.Comment))
+	}
+	for k, v := range tx.Metadata {
+		size += int64(len(k) + len(v))
+	}
+	return size
+}
@@ -0,0 +1,101 @@
+// internal/wallet/retention_test.go
+package wallet
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetentionLimit_EvictsOldestToArchive(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     RetentionLimit
+		deposits  int
+		wantHot   int
+		wantCold  int
+		noArchive bool
+	}{
+		{"under the count limit", RetentionLimit{MaxTransactions: 10}, 10, 10, 0, false},
+		{"over the count evicts to 90%", RetentionLimit{MaxTransactions: 10}, 11, 9, 2, false},
+		{"repeated evictions", RetentionLimit{MaxTransactions: 10}, 25, 9, 16, false},
+		{"byte limit", RetentionLimit{MaxBytes: 20 * transactionSize(sampleDeposit())}, 30, 18, 12, false},
+		{"no archive leaves the log alone", RetentionLimit{MaxTransactions: 10}, 25, 25, 0, true},
+		{"unlimited", RetentionLimit{}, 25, 25, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			archive := NewMemoryArchive()
+			opts := []Option{WithClock(clock.Now), WithRetentionLimit(tt.limit)}
+			if !tt.noArchive {
+				opts = append(opts, WithArchive(archive))
+			}
+			ws := NewWalletService(opts...)
+			ws.CreateUser("user1", "John Doe", "john@example.com")
+			for i := 0; i < tt.deposits; i++ {
+				ws.Deposit("user1", 1, fmt.Sprintf("d%02d", i))
+				clock.Advance(time.Minute)
+			}
+
+			stats := ws.GetRetentionStats()
+			if stats.HotTransactions != tt.wantHot || archive.Len() != tt.wantCold || stats.Evicted != int64(tt.wantCold) {
+				t.Errorf("hot = %d, archived = %d, evicted = %d, want %d hot and %d archived",
+					stats.HotTransactions, archive.Len(), stats.Evicted, tt.wantHot, tt.wantCold)
+			}
+			if tt.limit.MaxBytes > 0 && stats.HotBytes > tt.limit.MaxBytes {
+				t.Errorf("hot bytes = %d, over the limit %d", stats.HotBytes, tt.limit.MaxBytes)
+			}
+
+			// History falls back to the archive for evicted entries
+			history, err := ws.GetTransactionHistory("user1")
+			if err != nil || len(history) != tt.deposits {
+				t.Fatalf("GetTransactionHistory() = %d entries, %v, want %d", len(history), err, tt.deposits)
+			}
+			for i, tx := range history {
+				if want := fmt.Sprintf("d%02d", i); tx.Description != want {
+					t.Errorf("history[%d] = %s, want %s", i, tx.Description, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetentionLimit_ConcurrentAppends(t *testing.T) {
+	archive := NewMemoryArchive()
+	ws := NewWalletService(WithArchive(archive), WithRetentionLimit(RetentionLimit{MaxTransactions: 50}))
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 1000, "seed")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ws.Transfer("user1", "user2", 1, "")
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := ws.GetRetentionStats()
+	if stats.HotTransactions > 50 {
+		t.Errorf("hot transactions = %d, want at most 50", stats.HotTransactions)
+	}
+	if total := stats.HotTransactions + archive.Len(); total != 401 {
+		t.Errorf("hot + archived = %d, want 401 with no entry duplicated or lost", total)
+	}
+	for _, id := range []string{"user1", "user2"} {
+		if mismatch, err := ws.CheckWalletIntegrity(id); err != nil || mismatch != nil {
+			t.Errorf("CheckWalletIntegrity(%s) = %+v, %v", id, mismatch, err)
+		}
+	}
+}
+
+// sampleDeposit returns a deposit shaped like the ones the test posts
+func sampleDeposit() *Transaction {
+	return &Transaction{ID: "tx_0000000000000000000_00", ToUserID: "user1", Currency: DefaultCurrency, Type: TransactionDeposit, Description: "d00"}
+}
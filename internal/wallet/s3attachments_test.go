@@ -0,0 +1,116 @@
+// internal/wallet/s3attachments_test.go
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory bucket that checks each request's signature
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	failPut int // status to answer PUTs with when set
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	date, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		http.Error(w, "missing date", http.StatusForbidden)
+		return
+	}
+	want := r.Clone(r.Context())
+	want.URL.Host = r.Host
+	signS3Request(want, body, "eu-west-1", "AKID", "secret", date)
+	if r.Header.Get("Authorization") != want.Header.Get("Authorization") {
+		http.Error(w, "signature mismatch", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		if f.failPut != 0 {
+			w.WriteHeader(f.failPut)
+			return
+		}
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		data, exists := f.objects[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3AttachmentStore(t *testing.T) {
+	bucket := &fakeS3{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(bucket)
+	defer srv.Close()
+
+	store := &S3AttachmentStore{Endpoint: srv.URL + "/", Bucket: "evidence", Prefix: "wallet/", Region: "eu-west-1", AccessKey: "AKID", SecretKey: "secret"}
+	if err := store.Put("cases/case-1/evid-1", []byte("%PDF")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, exists := bucket.objects["/evidence/wallet/cases/case-1/evid-1"]; !exists {
+		t.Errorf("objects = %v, want the blob under bucket and prefix", bucket.objects)
+	}
+	if got, err := store.Get("cases/case-1/evid-1"); err != nil || !bytes.Equal(got, []byte("%PDF")) {
+		t.Errorf("Get() = %q, %v; want %%PDF", got, err)
+	}
+	if err := store.Delete("cases/case-1/evid-1"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, err := store.Get("cases/case-1/evid-1"); err != ErrAttachmentNotFound {
+		t.Errorf("Get() after delete error = %v, want %v", err, ErrAttachmentNotFound)
+	}
+	if err := store.Put("../escape", nil); err != ErrAttachmentKey {
+		t.Errorf("Put() of bad key error = %v, want %v", err, ErrAttachmentKey)
+	}
+
+	bucket.failPut = http.StatusServiceUnavailable
+	var s3Err *S3Error
+	if err := store.Put("cases/case-1/evid-2", []byte("x")); !errors.As(err, &s3Err) || s3Err.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Put() error = %v, want S3Error 503", err)
+	}
+
+	wrong := *store
+	wrong.SecretKey = "other"
+	if _, err := wrong.Get("cases/case-1/evid-1"); !errors.As(err, &s3Err) || s3Err.StatusCode != http.StatusForbidden {
+		t.Errorf("Get() with wrong secret error = %v, want S3Error 403", err)
+	}
+}
+
+func TestSignS3Request(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://s3.amazonaws.com/bucket/cases/a", nil)
+	signS3Request(req, nil, "us-east-1", "AKID", "secret", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	auth := req.Header.Get("Authorization")
+	for _, want := range []string{
+		"AWS4-HMAC-SHA256 Credential=AKID/20240102/us-east-1/s3/aws4_request",
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date",
+		"Signature=",
+	} {
+		if !strings.Contains(auth, want) {
+			t.Errorf("Authorization = %q, want it to contain %q", auth, want)
+		}
+	}
+	// SHA-256 of an empty payload
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("X-Amz-Content-Sha256 = %s, want the empty payload hash", got)
+	}
+}
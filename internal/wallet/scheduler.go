@@ -0,0 +1,343 @@
+// internal/wallet/scheduler.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrJobNotFound is returned for unknown or already finished scheduled jobs
+var ErrJobNotFound = errors.New("scheduled job not found")
+
+// JobStatus is the lifecycle state of a scheduled job
+type JobStatus string
+
+const (
+	JobScheduled JobStatus = "scheduled"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Recurrence computes the run after prev; returning the zero time ends the schedule
+type Recurrence func(prev time.Time) time.Time
+
+// Every returns a Recurrence firing at a fixed interval
+func Every(d time.Duration) Recurrence {
+	return func(prev time.Time) time.Time {
+		return prev.Add(d)
+	}
+}
+
+// Weekly returns a Recurrence firing on the same weekday and time of day every week
+func Weekly() Recurrence {
+	return func(prev time.Time) time.Time {
+		return prev.AddDate(0, 0, 7)
+	}
+}
+
+// Monthly returns a Recurrence firing on the given day of every month at the same time
+// of day. Days past the end of a short month fire on its last day.
+func Monthly(day int) Recurrence {
+	return func(prev time.Time) time.Time {
+		return monthDay(prev.Year(), prev.Month()+1, day, prev)
+	}
+}
+
+// monthDay returns day of the given month, clamped to the month's length, at clock's
+// time of day and location
+func monthDay(year int, month time.Month, day int, clock time.Time) time.Time {
+	first := time.Date(year, month, 1, clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// ScheduledJob describes a unit of work run by the scheduler
+type ScheduledJob struct {
+	ID        string
+	Kind      string
+	OwnerID   string
+	NextRun   int64
+	Status    JobStatus
+	Runs      int
+	LastRun   int64
+	LastError string
+}
+
+// JobResult reports the outcome of one job execution
+type JobResult struct {
+	JobID string
+	Kind  string
+	Err   error
+}
+
+// scheduledJob is a job together with its work function
+type scheduledJob struct {
+	job        ScheduledJob
+	next       time.Time
+	nominal    time.Time // next before business-day rolling; recurrences advance from here
+	roll       bool      // move runs onto business days
+	recurrence Recurrence
+	run        func(now time.Time) error
+	payment    *scheduledPayment // set for jobs created by SchedulePayment
+	running    bool              // a run is in progress
+	skips      map[int64]bool    // occurrences, by run time, skipped by SkipJobOccurrence
+}
+
+// scheduler holds pending jobs and the optional background loop
+type scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*scheduledJob
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+	audit   []JobAuditEntry // operator actions, oldest first
+}
+
+// schedule registers run to execute at the given time, repeating per recurrence when set
+func (ws *WalletService) schedule(kind, ownerID string, at time.Time, recurrence Recurrence, run func(now time.Time) error) string {
+	return ws.addJob(kind, ownerID, at, false, recurrence, run)
+}
+
+// schedulePayment is schedule for money movements: runs falling on non-business days
+// are rolled per the service's business calendar
+func (ws *WalletService) schedulePayment(kind, ownerID string, at time.Time, recurrence Recurrence, run func(now time.Time) error) string {
+	return ws.addJob(kind, ownerID, at, true, recurrence, run)
+}
+
+// addJob stores a new scheduled job
+func (ws *WalletService) addJob(kind, ownerID string, at time.Time, roll bool, recurrence Recurrence, run func(now time.Time) error) string {
+	next := at
+	if roll {
+		next = ws.rollDate(at)
+	}
+	j := &scheduledJob{
+		job: ScheduledJob{
+			ID:      ws.newID("job"),
+			Kind:    kind,
+			OwnerID: ownerID,
+			NextRun: next.Unix(),
+			Status:  JobScheduled,
+		},
+		next:       next,
+		nominal:    at,
+		roll:       roll,
+		recurrence: recurrence,
+		run:        run,
+	}
+
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	if ws.scheduler.jobs == nil {
+		ws.scheduler.jobs = make(map[string]*scheduledJob)
+	}
+	ws.scheduler.jobs[j.job.ID] = j
+
+	return j.job.ID
+}
+
+// SchedulePayment schedules a transfer at the given time, repeating per recurrence when
+// set, and returns the job ID. Runs falling on a non-business day are rolled per the
+// service's business calendar.
+func (ws *WalletService) SchedulePayment(fromUserID, toUserID string, amount decimal.Decimal, description string, at time.Time, recurrence Recurrence) (string, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return "", ErrInvalidAmount
+	}
+	if fromUserID == toUserID {
+		return "", ErrSameUserTransfer
+	}
+
+	ws.mu.RLock()
+	_, fromExists := ws.wallets[fromUserID]
+	_, toExists := ws.wallets[toUserID]
+	ws.mu.RUnlock()
+
+	if !fromExists || !toExists {
+		return "", ErrUserNotFound
+	}
+
+	jobID := ws.schedulePayment("payment", fromUserID, at, recurrence, func(now time.Time) error {
+		_, err := ws.transfer(context.Background(), fromUserID, toUserID, amount, description, transferOptions{priority: PriorityBatch})
+		if errors.Is(err, ErrTransferHeld) {
+			// Funds left the sender; the compliance case owns the outcome
+			return nil
+		}
+		return err
+	})
+
+	ws.scheduler.mu.Lock()
+	ws.scheduler.jobs[jobID].payment = &scheduledPayment{
+		fromUserID:  fromUserID,
+		toUserID:    toUserID,
+		amount:      amount,
+		description: description,
+	}
+	ws.scheduler.mu.Unlock()
+
+	return jobID, nil
+}
+
+// CancelJob stops a scheduled job from running again
+func (ws *WalletService) CancelJob(jobID string) error {
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	j, exists := ws.scheduler.jobs[jobID]
+	if !exists || j.job.Status != JobScheduled {
+		return ErrJobNotFound
+	}
+	j.job.Status = JobCancelled
+	return nil
+}
+
+// GetJob returns a scheduled job's state
+func (ws *WalletService) GetJob(jobID string) (*ScheduledJob, error) {
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	j, exists := ws.scheduler.jobs[jobID]
+	if !exists {
+		return nil, ErrJobNotFound
+	}
+	job := j.job
+	return &job, nil
+}
+
+// RunDueJobs executes every job whose next run is at or before the service clock,
+// in due-time order. Jobs run outside the scheduler lock and may schedule new jobs.
+func (ws *WalletService) RunDueJobs() []JobResult {
+	now := ws.now()
+
+	ws.scheduler.mu.Lock()
+	var due []*scheduledJob
+	for _, j := range ws.scheduler.jobs {
+		if j.job.Status == JobScheduled && !j.running && !j.next.After(now) {
+			j.running = true
+			due = append(due, j)
+		}
+	}
+	ws.scheduler.mu.Unlock()
+
+	sort.Slice(due, func(i, k int) bool {
+		if !due[i].next.Equal(due[k].next) {
+			return due[i].next.Before(due[k].next)
+		}
+		return due[i].job.ID < due[k].job.ID
+	})
+
+	results := make([]JobResult, 0, len(due))
+	for _, j := range due {
+		err := j.run(now)
+		results = append(results, JobResult{JobID: j.job.ID, Kind: j.job.Kind, Err: err})
+		ws.finishRun(j, now, err)
+	}
+
+	return results
+}
+
+// finishRun records a job execution and computes its next run
+func (ws *WalletService) finishRun(j *scheduledJob, now time.Time, err error) {
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	j.running = false
+	j.job.Runs++
+	j.job.LastRun = now.Unix()
+	j.job.LastError = ""
+	if err != nil {
+		j.job.LastError = err.Error()
+	}
+
+	if j.job.Status != JobScheduled {
+		// Cancelled while running
+		return
+	}
+
+	if ws.advanceJob(j) {
+		return
+	}
+
+	if err != nil {
+		j.job.Status = JobFailed
+	} else {
+		j.job.Status = JobCompleted
+	}
+}
+
+// advanceJob moves j to its first occurrence after the current one that is not
+// skipped, reporting false when the schedule has none. Caller must hold
+// ws.scheduler.mu.
+func (ws *WalletService) advanceJob(j *scheduledJob) bool {
+	delete(j.skips, j.job.NextRun)
+	if j.recurrence == nil {
+		return false
+	}
+	for {
+		nominal := j.recurrence(j.nominal)
+		if nominal.IsZero() {
+			return false
+		}
+		next := nominal
+		if j.roll {
+			next = ws.rollDate(nominal)
+		}
+		j.nominal = nominal
+		j.next = next
+		j.job.NextRun = next.Unix()
+		if !j.skips[j.job.NextRun] {
+			return true
+		}
+		delete(j.skips, j.job.NextRun)
+	}
+}
+
+// StartScheduler runs due jobs in the background every interval
+func (ws *WalletService) StartScheduler(interval time.Duration) {
+	ws.scheduler.mu.Lock()
+	defer ws.scheduler.mu.Unlock()
+
+	if ws.scheduler.running {
+		return
+	}
+	ws.scheduler.running = true
+	ws.scheduler.stop = make(chan struct{})
+	ws.scheduler.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ws.RunDueJobs()
+			}
+		}
+	}(ws.scheduler.stop, ws.scheduler.done)
+}
+
+// StopScheduler stops the background loop and waits for the current pass to finish
+func (ws *WalletService) StopScheduler() {
+	ws.scheduler.mu.Lock()
+	if !ws.scheduler.running {
+		ws.scheduler.mu.Unlock()
+		return
+	}
+	ws.scheduler.running = false
+	close(ws.scheduler.stop)
+	done := ws.scheduler.done
+	ws.scheduler.mu.Unlock()
+
+	<-done
+}
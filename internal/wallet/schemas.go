@@ -0,0 +1,284 @@
+// internal/wallet/schemas.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Error definitions for event schemas
+var (
+	ErrSchemaVersion        = errors.New("schema version must follow the latest registered version")
+	ErrIncompatibleSchema   = errors.New("schema is incompatible with the previous version")
+	ErrSchemaNotFound       = errors.New("event schema version not found")
+	ErrInvalidSchema        = errors.New("invalid event schema")
+	ErrPayloadMissingFields = errors.New("payload is missing required fields")
+)
+
+// FieldKind is the JSON type of a payload field
+type FieldKind string
+
+const (
+	FieldString  FieldKind = "string"
+	FieldDecimal FieldKind = "decimal" // decimal amount encoded as a string
+	FieldInt     FieldKind = "int"
+	FieldBool    FieldKind = "bool"
+	FieldObject  FieldKind = "object"
+)
+
+// SchemaField describes one top-level field of a payload
+type SchemaField struct {
+	Name     string
+	Kind     FieldKind
+	Required bool
+}
+
+// EventPayload is the versioned, wire-ready form of an event
+type EventPayload map[string]any
+
+// EventSchema defines version Version of the payload for one event type. Encode builds
+// the payload from an event and must fill every required field.
+type EventSchema struct {
+	Type    EventType
+	Version int
+	Fields  []SchemaField
+	Encode  func(evt Event) EventPayload
+
+	// Breaking registers a version that is incompatible with the previous one.
+	// Subscribers pinned to older versions keep receiving those.
+	Breaking bool
+}
+
+// field returns the schema's field called name
+func (s *EventSchema) field(name string) (SchemaField, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return SchemaField{}, false
+}
+
+// schemaRegistry holds payload schemas by event type, in version order
+type schemaRegistry struct {
+	mu     sync.Mutex
+	byType map[EventType][]EventSchema
+}
+
+// RegisterEventSchema adds the next version of an event type's payload. Unless marked
+// Breaking, a new version must be backward compatible: it keeps every field of the
+// previous version with the same kind, keeps required fields required, and only adds
+// optional fields.
+func (ws *WalletService) RegisterEventSchema(schema EventSchema) error {
+	if schema.Type == "" || schema.Encode == nil || len(schema.Fields) == 0 {
+		return ErrInvalidSchema
+	}
+
+	ws.schemas.mu.Lock()
+	defer ws.schemas.mu.Unlock()
+
+	ws.schemas.init()
+	versions := ws.schemas.byType[schema.Type]
+	if schema.Version != len(versions)+1 {
+		return ErrSchemaVersion
+	}
+	if len(versions) > 0 && !schema.Breaking {
+		if err := checkSchemaCompatible(&versions[len(versions)-1], &schema); err != nil {
+			return err
+		}
+	}
+	ws.schemas.byType[schema.Type] = append(versions, schema)
+	return nil
+}
+
+// ListEventSchemas returns every registered version of an event type's payload, oldest
+// first
+func (ws *WalletService) ListEventSchemas(eventType EventType) []EventSchema {
+	ws.schemas.mu.Lock()
+	defer ws.schemas.mu.Unlock()
+
+	ws.schemas.init()
+	return append([]EventSchema(nil), ws.schemas.byType[eventType]...)
+}
+
+// LatestSchemaVersion returns the newest payload version of an event type, or 0 when it
+// has no schema of its own and uses the generic payload
+func (ws *WalletService) LatestSchemaVersion(eventType EventType) int {
+	ws.schemas.mu.Lock()
+	defer ws.schemas.mu.Unlock()
+
+	ws.schemas.init()
+	return len(ws.schemas.byType[eventType])
+}
+
+// EncodeEvent builds the payload of evt at the given schema version. Version 0 selects
+// the latest. Event types without a schema of their own use the generic version 1
+// payload.
+func (ws *WalletService) EncodeEvent(evt Event, version int) (EventPayload, int, error) {
+	ws.schemas.mu.Lock()
+	ws.schemas.init()
+	versions := ws.schemas.byType[evt.Type]
+	ws.schemas.mu.Unlock()
+
+	schema := genericEventSchema(evt)
+	if len(versions) > 0 {
+		if version == 0 {
+			version = len(versions)
+		}
+		if version < 1 || version > len(versions) {
+			return nil, 0, ErrSchemaNotFound
+		}
+		schema = versions[version-1]
+	}
+
+	payload := schema.Encode(evt)
+	var missing []string
+	for _, f := range schema.Fields {
+		if _, ok := payload[f.Name]; f.Required && !ok {
+			missing = append(missing, f.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, 0, fmt.Errorf("%w: %s v%d %v", ErrPayloadMissingFields, evt.Type, schema.Version, missing)
+	}
+	payload["schema_version"] = schema.Version
+	return payload, schema.Version, nil
+}
+
+// checkSchemaCompatible reports whether next can replace prev for existing consumers
+func checkSchemaCompatible(prev, next *EventSchema) error {
+	for _, old := range prev.Fields {
+		f, ok := next.field(old.Name)
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: field %s removed", ErrIncompatibleSchema, old.Name)
+		case f.Kind != old.Kind:
+			return fmt.Errorf("%w: field %s changed from %s to %s", ErrIncompatibleSchema, old.Name, old.Kind, f.Kind)
+		case old.Required && !f.Required:
+			return fmt.Errorf("%w: field %s no longer required", ErrIncompatibleSchema, old.Name)
+		}
+	}
+	for _, f := range next.Fields {
+		if _, existed := prev.field(f.Name); !existed && f.Required {
+			return fmt.Errorf("%w: new field %s is required", ErrIncompatibleSchema, f.Name)
+		}
+	}
+	return nil
+}
+
+// pinSchemaVersions resolves the versions a new subscriber receives: explicit choices
+// are kept and every other type with a schema is pinned to its current latest version,
+// so later versions never reach the subscriber unasked. Types that gain their first
+// schema later are delivered at version 1, the generic payload's version.
+func (ws *WalletService) pinSchemaVersions(requested map[EventType]int) (map[EventType]int, error) {
+	ws.schemas.mu.Lock()
+	defer ws.schemas.mu.Unlock()
+
+	ws.schemas.init()
+	pinned := make(map[EventType]int, len(ws.schemas.byType))
+	for eventType, versions := range ws.schemas.byType {
+		pinned[eventType] = len(versions)
+	}
+	for eventType, version := range requested {
+		if version < 1 || version > len(ws.schemas.byType[eventType]) {
+			return nil, ErrSchemaNotFound
+		}
+		pinned[eventType] = version
+	}
+	return pinned, nil
+}
+
+// init registers the built-in version 1 schemas. Caller must hold r.mu.
+func (r *schemaRegistry) init() {
+	if r.byType != nil {
+		return
+	}
+	r.byType = make(map[EventType][]EventSchema)
+	r.byType[EventUserCreated] = []EventSchema{{
+		Type:    EventUserCreated,
+		Version: 1,
+		Fields:  baseEventFields,
+		Encode:  encodeBaseEvent,
+	}}
+	for _, t := range []EventType{EventDeposited, EventWithdrawn, EventTransferred} {
+		r.byType[t] = []EventSchema{{
+			Type:    t,
+			Version: 1,
+			Fields:  transactionEventFields,
+			Encode:  encodeTransactionEvent,
+		}}
+	}
+}
+
+// baseEventFields are carried by every event payload
+var baseEventFields = []SchemaField{
+	{Name: "event_id", Kind: FieldString, Required: true},
+	{Name: "offset", Kind: FieldInt, Required: true},
+	{Name: "type", Kind: FieldString, Required: true},
+	{Name: "user_id", Kind: FieldString, Required: true},
+	{Name: "timestamp", Kind: FieldInt, Required: true},
+}
+
+// transactionEventFields add the transaction to the base fields
+var transactionEventFields = append(append([]SchemaField(nil), baseEventFields...),
+	SchemaField{Name: "transaction_id", Kind: FieldString, Required: true},
+	SchemaField{Name: "transaction_type", Kind: FieldString, Required: true},
+	SchemaField{Name: "amount", Kind: FieldDecimal, Required: true},
+	SchemaField{Name: "currency", Kind: FieldString, Required: true},
+	SchemaField{Name: "from_user_id", Kind: FieldString},
+	SchemaField{Name: "to_user_id", Kind: FieldString},
+	SchemaField{Name: "description", Kind: FieldString},
+	SchemaField{Name: "balances", Kind: FieldObject},
+)
+
+// encodeBaseEvent encodes the fields every event carries
+func encodeBaseEvent(evt Event) EventPayload {
+	return EventPayload{
+		"event_id":  evt.ID,
+		"offset":    evt.Offset,
+		"type":      string(evt.Type),
+		"user_id":   evt.UserID,
+		"timestamp": evt.Timestamp,
+	}
+}
+
+// encodeTransactionEvent encodes an event announcing a transaction
+func encodeTransactionEvent(evt Event) EventPayload {
+	payload := encodeBaseEvent(evt)
+	tx := evt.Transaction
+	if tx == nil {
+		return payload
+	}
+	payload["transaction_id"] = tx.ID
+	payload["transaction_type"] = string(tx.Type)
+	payload["amount"] = tx.Amount.String()
+	payload["currency"] = tx.currencyOf()
+	payload["from_user_id"] = tx.FromUserID
+	payload["to_user_id"] = tx.ToUserID
+	payload["description"] = tx.Description
+
+	if len(evt.Balances) > 0 {
+		ids := make([]string, 0, len(evt.Balances))
+		for id := range evt.Balances {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		balances := make(map[string]string, len(ids))
+		for _, id := range ids {
+			balances[id] = evt.Balances[id].String()
+		}
+		payload["balances"] = balances
+	}
+	return payload
+}
+
+// genericEventSchema is the version 1 payload of event types without a schema of their
+// own
+func genericEventSchema(evt Event) EventSchema {
+	if evt.Transaction != nil {
+		return EventSchema{Type: evt.Type, Version: 1, Fields: transactionEventFields, Encode: encodeTransactionEvent}
+	}
+	return EventSchema{Type: evt.Type, Version: 1, Fields: baseEventFields, Encode: encodeBaseEvent}
+}
@@ -0,0 +1,165 @@
+// internal/wallet/schemas_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// depositV2 adds an optional memo field to the built-in deposit payload
+func depositV2() EventSchema {
+	return EventSchema{
+		Type:    EventDeposited,
+		Version: 2,
+		Fields:  append(append([]SchemaField(nil), transactionEventFields...), SchemaField{Name: "memo", Kind: FieldString}),
+		Encode: func(evt Event) EventPayload {
+			payload := encodeTransactionEvent(evt)
+			payload["memo"] = "v2"
+			return payload
+		},
+	}
+}
+
+// depositV3 breaks v2 by sending amounts as integer minor units
+func depositV3() EventSchema {
+	fields := []SchemaField{{Name: "event_id", Kind: FieldString, Required: true}, {Name: "amount_minor", Kind: FieldInt, Required: true}}
+	return EventSchema{
+		Type:     EventDeposited,
+		Version:  3,
+		Fields:   fields,
+		Breaking: true,
+		Encode: func(evt Event) EventPayload {
+			return EventPayload{"event_id": evt.ID, "amount_minor": evt.Transaction.Amount.Shift(2).IntPart()}
+		},
+	}
+}
+
+func TestRegisterEventSchema_Compatibility(t *testing.T) {
+	withField := func(f SchemaField, drop string) EventSchema {
+		s := depositV2()
+		s.Fields = nil
+		for _, existing := range transactionEventFields {
+			switch {
+			case existing.Name == drop:
+			case existing.Name == f.Name:
+				s.Fields = append(s.Fields, f)
+			default:
+				s.Fields = append(s.Fields, existing)
+			}
+		}
+		if _, exists := (&EventSchema{Fields: transactionEventFields}).field(f.Name); !exists && f.Name != "" {
+			s.Fields = append(s.Fields, f)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		schema  EventSchema
+		wantErr error
+	}{
+		{"optional field added", withField(SchemaField{Name: "memo", Kind: FieldString}, ""), nil},
+		{"field removed", withField(SchemaField{}, "currency"), ErrIncompatibleSchema},
+		{"kind changed", withField(SchemaField{Name: "amount", Kind: FieldInt, Required: true}, ""), ErrIncompatibleSchema},
+		{"required made optional", withField(SchemaField{Name: "amount", Kind: FieldDecimal}, ""), ErrIncompatibleSchema},
+		{"new required field", withField(SchemaField{Name: "memo", Kind: FieldString, Required: true}, ""), ErrIncompatibleSchema},
+		{"skipped version", func() EventSchema { s := depositV2(); s.Version = 3; return s }(), ErrSchemaVersion},
+		{"no encoder", EventSchema{Type: EventDeposited, Version: 2, Fields: transactionEventFields}, ErrInvalidSchema},
+	}
+	for _, tt := range tests {
+		ws := NewWalletService()
+		if err := ws.RegisterEventSchema(tt.schema); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: RegisterEventSchema() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	ws := NewWalletService()
+	ws.RegisterEventSchema(depositV2())
+	if err := ws.RegisterEventSchema(depositV3()); err != nil {
+		t.Errorf("RegisterEventSchema(breaking v3) error = %v", err)
+	}
+	if got := ws.LatestSchemaVersion(EventDeposited); got != 3 || len(ws.ListEventSchemas(EventDeposited)) != 3 {
+		t.Errorf("LatestSchemaVersion() = %d, want 3", got)
+	}
+}
+
+func TestWebhooks_SchemaVersionNegotiation(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	filter := []EventType{EventDeposited}
+
+	legacy := &recordingTransport{}
+	legacyID, _ := ws.RegisterWebhook(legacy, WebhookConfig{EventTypes: filter, AutoAck: true})
+
+	ws.RegisterEventSchema(depositV2())
+	ws.RegisterEventSchema(depositV3())
+
+	current := &recordingTransport{}
+	ws.RegisterWebhook(current, WebhookConfig{EventTypes: filter, AutoAck: true})
+	pinned := &recordingTransport{}
+	ws.RegisterWebhook(pinned, WebhookConfig{EventTypes: filter, AutoAck: true, SchemaVersions: map[EventType]int{EventDeposited: 2}})
+	if _, err := ws.RegisterWebhook(&recordingTransport{}, WebhookConfig{SchemaVersions: map[EventType]int{EventDeposited: 4}}); err != ErrSchemaNotFound {
+		t.Errorf("RegisterWebhook(unknown version) error = %v, want %v", err, ErrSchemaNotFound)
+	}
+
+	ws.Deposit("user1", 12.5, "first")
+	ws.DispatchWebhooks()
+
+	tests := []struct {
+		name        string
+		transport   *recordingTransport
+		wantVersion int
+		wantField   string
+	}{
+		{"registered before v2 stays on v1", legacy, 1, "amount"},
+		{"registered after v3 gets v3", current, 3, "amount_minor"},
+		{"pinned to v2", pinned, 2, "memo"},
+	}
+	for _, tt := range tests {
+		if len(tt.transport.deliveries) != 1 {
+			t.Fatalf("%s: deliveries = %d, want 1", tt.name, len(tt.transport.deliveries))
+		}
+		d := tt.transport.deliveries[0]
+		if d.SchemaVersion != tt.wantVersion || d.Payload["schema_version"] != tt.wantVersion {
+			t.Errorf("%s: schema version = %d, want %d", tt.name, d.SchemaVersion, tt.wantVersion)
+		}
+		if _, ok := d.Payload[tt.wantField]; !ok {
+			t.Errorf("%s: payload %v lacks %s", tt.name, d.Payload, tt.wantField)
+		}
+	}
+	if got := current.deliveries[0].Payload["amount_minor"]; got != int64(1250) {
+		t.Errorf("v3 amount_minor = %v, want 1250", got)
+	}
+
+	// The legacy consumer upgrades explicitly
+	if err := ws.SetWebhookSchemaVersion(legacyID, EventDeposited, 3); err != nil {
+		t.Fatalf("SetWebhookSchemaVersion() error = %v", err)
+	}
+	ws.Deposit("user1", 1, "second")
+	ws.DispatchWebhooks()
+	if d := legacy.deliveries[1]; d.SchemaVersion != 3 {
+		t.Errorf("upgraded delivery version = %d, want 3", d.SchemaVersion)
+	}
+	if err := ws.SetWebhookSchemaVersion(legacyID, EventDeposited, 9); err != ErrSchemaNotFound {
+		t.Errorf("SetWebhookSchemaVersion(9) error = %v, want %v", err, ErrSchemaNotFound)
+	}
+}
+
+func TestEncodeEvent_GenericPayload(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("user1", 10, "seed")
+	ws.SetBalance("user1", decimal.NewFromInt(4), ReasonErrorCorrection)
+
+	events := ws.EventsSince(0, 0)
+	last := events[len(events)-1]
+	payload, version, err := ws.EncodeEvent(last, 0)
+	if err != nil || version != 1 || payload["transaction_type"] != string(TransactionAdjustmentDebit) {
+		t.Errorf("EncodeEvent(%s) = %v, %d, %v", last.Type, payload, version, err)
+	}
+	if _, _, err := ws.EncodeEvent(events[0], 2); err != ErrSchemaNotFound {
+		t.Errorf("EncodeEvent(user_created v2) error = %v, want %v", err, ErrSchemaNotFound)
+	}
+}
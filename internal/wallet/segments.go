@@ -0,0 +1,387 @@
+// internal/wallet/segments.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Error definitions for segments and admin freezes
+var (
+	ErrWalletAdminFrozen   = errors.New("wallet is frozen by an administrator")
+	ErrEmptySegment        = errors.New("segment filter must set a tag, country or risk flag")
+	ErrSegmentActionReason = errors.New("segment action needs an actor and a reason")
+	ErrSegmentNotFound     = errors.New("segment action not found")
+)
+
+// segmentProgressEvery is how many wallets a segment action processes between progress
+// reports
+const segmentProgressEvery = 100
+
+// UserAttributes are the fields admin segments select users by
+type UserAttributes struct {
+	Country   string   // ISO 3166 alpha-2 code
+	Tags      []string // e.g. "vip", "merchant"
+	RiskFlags []string // e.g. "chargeback_watch", set by risk operations
+}
+
+// SegmentFilter selects users whose attributes match every field set: the country, and
+// each of the tags and risk flags
+type SegmentFilter struct {
+	Country   string
+	Tags      []string
+	RiskFlags []string
+}
+
+// SegmentActionKind is what a segment action did to the wallets it matched
+type SegmentActionKind string
+
+const (
+	SegmentFreeze   SegmentActionKind = "freeze"
+	SegmentUnfreeze SegmentActionKind = "unfreeze"
+)
+
+// SegmentActionRequest asks for a segment action. A dry run only counts the wallets the
+// action would change.
+type SegmentActionRequest struct {
+	Filter     SegmentFilter
+	Actor      string
+	Reason     string
+	DryRun     bool
+	OnProgress func(SegmentProgress) // called from the acting goroutine; optional
+}
+
+// SegmentProgress reports how far a segment action has got
+type SegmentProgress struct {
+	ActionID  string
+	Processed int
+	Total     int
+}
+
+// SegmentAction is the audit record of one segment action, linking every wallet it
+// changed. Wallets already in the requested state are counted as skipped.
+type SegmentAction struct {
+	ID          string
+	Kind        SegmentActionKind
+	Filter      SegmentFilter
+	Actor       string
+	Reason      string
+	Matched     int
+	Processed   int
+	Affected    []string // users whose wallet changed, sorted
+	Skipped     int
+	DryRun      bool
+	StartedAt   int64
+	CompletedAt int64 // 0 while running
+}
+
+// AdminFreeze records why an administrator froze a wallet
+type AdminFreeze struct {
+	UserID   string
+	ActionID string // segment action that froze it
+	Actor    string
+	Reason   string
+	FrozenAt int64
+}
+
+// segmentBook holds user attributes, admin freezes and the segment action audit trail
+type segmentBook struct {
+	mu         sync.Mutex
+	attributes map[string]UserAttributes
+	freezes    map[string]*AdminFreeze
+	actions    []*SegmentAction
+}
+
+// SetUserAttributes replaces the attributes admin segments see for userID
+func (ws *WalletService) SetUserAttributes(userID string, attrs UserAttributes) error {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	attrs.Country = strings.ToUpper(strings.TrimSpace(attrs.Country))
+	attrs.Tags = normalizeLabels(attrs.Tags)
+	attrs.RiskFlags = normalizeLabels(attrs.RiskFlags)
+
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+	if ws.segments.attributes == nil {
+		ws.segments.attributes = make(map[string]UserAttributes)
+	}
+	ws.segments.attributes[userID] = attrs
+	return nil
+}
+
+// GetUserAttributes returns the attributes set for userID
+func (ws *WalletService) GetUserAttributes(userID string) (UserAttributes, error) {
+	ws.mu.RLock()
+	_, exists := ws.users[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return UserAttributes{}, ErrUserNotFound
+	}
+
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+	attrs := ws.segments.attributes[userID]
+	attrs.Tags = slices.Clone(attrs.Tags)
+	attrs.RiskFlags = slices.Clone(attrs.RiskFlags)
+	return attrs, nil
+}
+
+// FreezeSegment freezes every wallet matching req.Filter in one action: frozen wallets
+// refuse transactions except money returning to them, such as hold releases and admin
+// corrections. The action is recorded once, listing each wallet it froze.
+func (ws *WalletService) FreezeSegment(req SegmentActionRequest) (*SegmentAction, error) {
+	return ws.FreezeSegmentContext(context.Background(), req)
+}
+
+// FreezeSegmentContext is FreezeSegment on behalf of a caller's ctx
+func (ws *WalletService) FreezeSegmentContext(ctx context.Context, req SegmentActionRequest) (*SegmentAction, error) {
+	return ws.runSegmentAction(ctx, SegmentFreeze, req)
+}
+
+// UnfreezeSegment lifts the admin freeze of every wallet matching req.Filter
+func (ws *WalletService) UnfreezeSegment(req SegmentActionRequest) (*SegmentAction, error) {
+	return ws.UnfreezeSegmentContext(context.Background(), req)
+}
+
+// UnfreezeSegmentContext is UnfreezeSegment on behalf of a caller's ctx
+func (ws *WalletService) UnfreezeSegmentContext(ctx context.Context, req SegmentActionRequest) (*SegmentAction, error) {
+	return ws.runSegmentAction(ctx, SegmentUnfreeze, req)
+}
+
+// GetSegmentAction returns a segment action by ID. Polling it follows the progress of
+// an action running in another goroutine.
+func (ws *WalletService) GetSegmentAction(actionID string) (*SegmentAction, error) {
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+
+	for _, a := range ws.segments.actions {
+		if a.ID == actionID {
+			return a.copy(), nil
+		}
+	}
+	return nil, ErrSegmentNotFound
+}
+
+// ListSegmentActions returns the segment action audit trail, oldest first. Dry runs are
+// not recorded.
+func (ws *WalletService) ListSegmentActions() []SegmentAction {
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+
+	list := make([]SegmentAction, 0, len(ws.segments.actions))
+	for _, a := range ws.segments.actions {
+		list = append(list, *a.copy())
+	}
+	return list
+}
+
+// GetAdminFreeze returns the admin freeze on userID's wallet, or nil when it is not
+// frozen
+func (ws *WalletService) GetAdminFreeze(userID string) *AdminFreeze {
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+
+	f, exists := ws.segments.freezes[userID]
+	if !exists {
+		return nil
+	}
+	copied := *f
+	return &copied
+}
+
+// runSegmentAction applies kind to each wallet matching req.Filter, one user lock at a
+// time so operations already running on a wallet finish first. Actions other than dry
+// runs are audited with the wallets they changed, under req.Actor when ctx names no
+// actor.
+func (ws *WalletService) runSegmentAction(ctx context.Context, kind SegmentActionKind, req SegmentActionRequest) (result *SegmentAction, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !req.DryRun {
+		defer func() {
+			var affected []string
+			if result != nil {
+				affected = result.Affected
+			}
+			actor := ActorFromContext(ctx)
+			if actor.ID == "" {
+				actor.ID = req.Actor
+			}
+			ws.audit(ContextWithActor(ctx, actor), string(kind)+"_segment", nil, err, affected...)
+		}()
+	}
+
+	if req.Filter.empty() {
+		return nil, ErrEmptySegment
+	}
+	if !req.DryRun && (strings.TrimSpace(req.Actor) == "" || strings.TrimSpace(req.Reason) == "") {
+		return nil, ErrSegmentActionReason
+	}
+
+	matched := ws.matchSegment(req.Filter)
+	action := &SegmentAction{
+		ID:        ws.newID("segact"),
+		Kind:      kind,
+		Filter:    req.Filter,
+		Actor:     req.Actor,
+		Reason:    req.Reason,
+		Matched:   len(matched),
+		DryRun:    req.DryRun,
+		StartedAt: ws.now().Unix(),
+	}
+	ws.segments.mu.Lock()
+	if !req.DryRun {
+		ws.segments.actions = append(ws.segments.actions, action)
+	}
+	ws.segments.mu.Unlock()
+
+	for i, userID := range matched {
+		changed := ws.applySegmentAction(action, userID)
+
+		ws.segments.mu.Lock()
+		action.Processed++
+		if changed {
+			action.Affected = append(action.Affected, userID)
+		} else {
+			action.Skipped++
+		}
+		ws.segments.mu.Unlock()
+
+		if req.OnProgress != nil && ((i+1)%segmentProgressEvery == 0 || i+1 == len(matched)) {
+			req.OnProgress(SegmentProgress{ActionID: action.ID, Processed: i + 1, Total: len(matched)})
+		}
+	}
+
+	ws.segments.mu.Lock()
+	action.CompletedAt = ws.now().Unix()
+	copied := action.copy()
+	ws.segments.mu.Unlock()
+
+	if !req.DryRun {
+		ws.metrics.IncCounter("segment_actions_total", map[string]string{"kind": string(kind)})
+	}
+	return copied, nil
+}
+
+// applySegmentAction freezes or unfreezes one wallet and reports whether it changed.
+// A dry run only reports.
+func (ws *WalletService) applySegmentAction(action *SegmentAction, userID string) bool {
+	userLock := ws.userLocks.getLock(userID)
+	userLock.Lock()
+	defer userLock.Unlock()
+
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+
+	_, frozen := ws.segments.freezes[userID]
+	if frozen == (action.Kind == SegmentFreeze) {
+		return false
+	}
+	if action.DryRun {
+		return true
+	}
+	if action.Kind == SegmentUnfreeze {
+		delete(ws.segments.freezes, userID)
+		return true
+	}
+	if ws.segments.freezes == nil {
+		ws.segments.freezes = make(map[string]*AdminFreeze)
+	}
+	ws.segments.freezes[userID] = &AdminFreeze{
+		UserID:   userID,
+		ActionID: action.ID,
+		Actor:    action.Actor,
+		Reason:   action.Reason,
+		FrozenAt: ws.now().Unix(),
+	}
+	return true
+}
+
+// matchSegment returns the users matching filter, sorted
+func (ws *WalletService) matchSegment(filter SegmentFilter) []string {
+	country := strings.ToUpper(strings.TrimSpace(filter.Country))
+	tags, flags := normalizeLabels(filter.Tags), normalizeLabels(filter.RiskFlags)
+
+	ws.segments.mu.Lock()
+	var matched []string
+	for userID, attrs := range ws.segments.attributes {
+		if country != "" && attrs.Country != country {
+			continue
+		}
+		if !containsAll(attrs.Tags, tags) || !containsAll(attrs.RiskFlags, flags) {
+			continue
+		}
+		matched = append(matched, userID)
+	}
+	ws.segments.mu.Unlock()
+
+	// Users deleted since their attributes were set no longer have a wallet
+	ws.mu.RLock()
+	matched = slices.DeleteFunc(matched, func(userID string) bool {
+		_, exists := ws.wallets[userID]
+		return !exists
+	})
+	ws.mu.RUnlock()
+	sort.Strings(matched)
+	return matched
+}
+
+// checkAdminFreeze rejects transactions touching an admin-frozen wallet, except those
+// closures also let through
+func (ws *WalletService) checkAdminFreeze(tx *Transaction) error {
+	if closureAllowedTypes[tx.Type] {
+		return nil
+	}
+	ws.segments.mu.Lock()
+	defer ws.segments.mu.Unlock()
+
+	for _, id := range []string{tx.FromUserID, tx.ToUserID} {
+		if _, frozen := ws.segments.freezes[id]; frozen {
+			return ErrWalletAdminFrozen
+		}
+	}
+	return nil
+}
+
+// empty reports whether the filter would match every user
+func (f SegmentFilter) empty() bool {
+	return strings.TrimSpace(f.Country) == "" && len(normalizeLabels(f.Tags)) == 0 && len(normalizeLabels(f.RiskFlags)) == 0
+}
+
+// copy returns a snapshot safe to hand to callers. Caller must hold ws.segments.mu.
+func (a *SegmentAction) copy() *SegmentAction {
+	c := *a
+	c.Affected = slices.Clone(a.Affected)
+	return &c
+}
+
+// normalizeLabels lower-cases and trims labels, dropping empty and repeated ones
+func normalizeLabels(labels []string) []string {
+	var out []string
+	for _, l := range labels {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" && !slices.Contains(out, l) {
+			out = append(out, l)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// containsAll reports whether have includes every label of want
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
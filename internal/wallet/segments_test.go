@@ -0,0 +1,157 @@
+// internal/wallet/segments_test.go
+package wallet
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// seedSegments creates users with attributes: two Nigerian merchants, one of them
+// flagged, and a Kenyan merchant
+func seedSegments(t *testing.T, ws *WalletService) {
+	t.Helper()
+	attrs := map[string]UserAttributes{
+		"ng-1": {Country: "ng", Tags: []string{"Merchant"}},
+		"ng-2": {Country: "NG", Tags: []string{"merchant", "vip"}, RiskFlags: []string{"chargeback_watch"}},
+		"ke-1": {Country: "KE", Tags: []string{"merchant"}, RiskFlags: []string{"chargeback_watch"}},
+	}
+	for id, a := range attrs {
+		ws.CreateUser(id, id, id+"@example.com")
+		ws.Deposit(id, 100, "seed")
+		if err := ws.SetUserAttributes(id, a); err != nil {
+			t.Fatalf("SetUserAttributes(%s) error = %v", id, err)
+		}
+	}
+}
+
+func TestFreezeSegment_Matching(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  SegmentFilter
+		wantErr error
+		want    []string
+	}{
+		{"empty filter", SegmentFilter{Tags: []string{" "}}, ErrEmptySegment, nil},
+		{"country", SegmentFilter{Country: "ng"}, nil, []string{"ng-1", "ng-2"}},
+		{"tag", SegmentFilter{Tags: []string{"MERCHANT"}}, nil, []string{"ke-1", "ng-1", "ng-2"}},
+		{"risk flag", SegmentFilter{RiskFlags: []string{"chargeback_watch"}}, nil, []string{"ke-1", "ng-2"}},
+		{"all criteria", SegmentFilter{Country: "NG", Tags: []string{"vip"}, RiskFlags: []string{"chargeback_watch"}}, nil, []string{"ng-2"}},
+		{"no match", SegmentFilter{Country: "US"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := NewWalletService()
+			seedSegments(t, ws)
+			action, err := ws.FreezeSegment(SegmentActionRequest{Filter: tt.filter, DryRun: true})
+			if err != tt.wantErr {
+				t.Fatalf("FreezeSegment() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if action.Matched != len(tt.want) || !slices.Equal(action.Affected, tt.want) {
+				t.Errorf("dry run = %d matched, affected %v; want %v", action.Matched, action.Affected, tt.want)
+			}
+			if got := ws.ListSegmentActions(); len(got) != 0 {
+				t.Errorf("dry run recorded %d actions, want 0", len(got))
+			}
+			for _, id := range tt.want {
+				if ws.GetAdminFreeze(id) != nil {
+					t.Errorf("dry run froze %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFreezeSegment_FreezeAndUnfreeze(t *testing.T) {
+	ws := NewWalletService()
+	seedSegments(t, ws)
+	filter := SegmentFilter{RiskFlags: []string{"chargeback_watch"}}
+
+	if _, err := ws.FreezeSegment(SegmentActionRequest{Filter: filter}); err != ErrSegmentActionReason {
+		t.Fatalf("FreezeSegment() without reason error = %v, want %v", err, ErrSegmentActionReason)
+	}
+	action, err := ws.FreezeSegment(SegmentActionRequest{Filter: filter, Actor: "ops", Reason: "fraud ring"})
+	if err != nil {
+		t.Fatalf("FreezeSegment() error = %v", err)
+	}
+	if !slices.Equal(action.Affected, []string{"ke-1", "ng-2"}) || action.CompletedAt == 0 {
+		t.Errorf("action = %+v, want ke-1 and ng-2 affected and completed", action)
+	}
+	if f := ws.GetAdminFreeze("ng-2"); f == nil || f.ActionID != action.ID || f.Reason != "fraud ring" {
+		t.Errorf("GetAdminFreeze(ng-2) = %+v, want linked to %s", f, action.ID)
+	}
+
+	tests := []struct {
+		name    string
+		op      func() error
+		wantErr error
+	}{
+		{"frozen withdraws", func() error { return ws.Withdraw("ng-2", 10, "cash") }, ErrWalletAdminFrozen},
+		{"transfer to frozen", func() error { return ws.Transfer("ng-1", "ke-1", 10, "pay") }, ErrWalletAdminFrozen},
+		{"unfrozen withdraws", func() error { return ws.Withdraw("ng-1", 10, "cash") }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Wallets already frozen are skipped by a second freeze
+	again, _ := ws.FreezeSegment(SegmentActionRequest{Filter: SegmentFilter{Country: "NG"}, Actor: "ops", Reason: "region"})
+	if !slices.Equal(again.Affected, []string{"ng-1"}) || again.Skipped != 1 {
+		t.Errorf("second freeze = affected %v, skipped %d; want [ng-1], 1", again.Affected, again.Skipped)
+	}
+
+	undo, err := ws.UnfreezeSegment(SegmentActionRequest{Filter: filter, Actor: "ops", Reason: "cleared"})
+	if err != nil {
+		t.Fatalf("UnfreezeSegment() error = %v", err)
+	}
+	if !slices.Equal(undo.Affected, []string{"ke-1", "ng-2"}) {
+		t.Errorf("unfreeze affected %v, want [ke-1 ng-2]", undo.Affected)
+	}
+	if err := ws.Withdraw("ke-1", 10, "cash"); err != nil {
+		t.Errorf("Withdraw() after unfreeze error = %v", err)
+	}
+	if got := ws.ListSegmentActions(); len(got) != 3 || got[2].Kind != SegmentUnfreeze {
+		t.Errorf("ListSegmentActions() = %d actions, want 3 ending in unfreeze", len(got))
+	}
+}
+
+func TestFreezeSegment_Progress(t *testing.T) {
+	ws := NewWalletService()
+	for i := range 250 {
+		id := fmt.Sprintf("u%03d", i)
+		ws.CreateUser(id, id, id+"@example.com")
+		ws.SetUserAttributes(id, UserAttributes{Tags: []string{"bulk"}})
+	}
+
+	var reports []SegmentProgress
+	action, err := ws.FreezeSegment(SegmentActionRequest{
+		Filter:     SegmentFilter{Tags: []string{"bulk"}},
+		Actor:      "ops",
+		Reason:     "test",
+		OnProgress: func(p SegmentProgress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatalf("FreezeSegment() error = %v", err)
+	}
+
+	var processed []int
+	for _, p := range reports {
+		if p.ActionID != action.ID || p.Total != 250 {
+			t.Errorf("progress = %+v, want action %s of 250", p, action.ID)
+		}
+		processed = append(processed, p.Processed)
+	}
+	if want := []int{100, 200, 250}; !slices.Equal(processed, want) {
+		t.Errorf("progress reports = %v, want %v", processed, want)
+	}
+	if got, _ := ws.GetSegmentAction(action.ID); got.Processed != 250 || len(got.Affected) != 250 {
+		t.Errorf("GetSegmentAction() = %d processed, %d affected; want 250", got.Processed, len(got.Affected))
+	}
+}
@@ -0,0 +1,403 @@
+// internal/wallet/service.go
+// Code generated by wallettest/gen. DO NOT EDIT.
+
+package wallet
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Service is every public operation of WalletService, for code that depends on the
+// wallet and wants to swap in a test double from the wallettest package
+type Service interface {
+	AcceptConsent(userID string, kind ConsentKind, version string) error
+	AccrueInterestNow() ([]*InterestStatement, error)
+	AckWebhook(subscriptionID string, ackToken string) error
+	ActiveUsers(from time.Time, to time.Time, g Granularity) ([]ActivityBucket, error)
+	AddCaseNote(caseID string, author string, text string) error
+	AddFavorite(userID string, spec FavoriteSpec) (*Favorite, error)
+	AddOrgMember(orgID string, userID string, role OrgRole) error
+	AddWithdrawalDestination(userID string, kind DestinationKind, reference string, label string) (*WithdrawalDestination, error)
+	AdminAdjust(userID string, amount decimal.Decimal, reason AdjustmentReason, actorID string) (*Transaction, error)
+	AdminAdjustContext(ctx context.Context, userID string, amount decimal.Decimal, reason AdjustmentReason, actorID string) (*Transaction, error)
+	AdminTransfer(fromUserID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	AdminTransferContext(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	AnnotateTransaction(authorID string, txID string, text string, visibility AnnotationVisibility) (*Annotation, error)
+	AnnotateUser(authorID string, userID string, text string, visibility AnnotationVisibility) (*Annotation, error)
+	ApplyFederationReceipt(receipt FederationReceipt) (*OutboundTransfer, error)
+	ApplyFederationReceiptContext(ctx context.Context, receipt FederationReceipt) (*OutboundTransfer, error)
+	ApproveAdjustment(requestID string, checkerID string, comment string) (*AdjustmentRequest, error)
+	ApproveAdjustmentContext(ctx context.Context, requestID string, checkerID string, comment string) (*AdjustmentRequest, error)
+	ArchiveTransactionsBefore(cutoff int64) (int, error)
+	ArchiveTransactionsBeforeContext(ctx context.Context, cutoff int64) (int, error)
+	AssignCase(caseID string, assignee string) error
+	AssignMinimumBalanceTier(userID string, tier string) error
+	AttachCaseEvidence(caseID string, evidence CaseEvidence) error
+	AuditConversion(txID string) (*ConversionAudit, error)
+	AuthorizeCard(req CardAuthRequest) (*CardAuthorization, error)
+	AuthorizeCardContext(ctx context.Context, req CardAuthRequest) (*CardAuthorization, error)
+	AwaitSession(ctx context.Context, token SessionToken) error
+	Backup(w io.Writer) error
+	BackupBinary(w io.Writer) error
+	BackupOnline(w io.Writer) error
+	BatchPayout(fromUserID string, payouts []Payout, description string) ([]*Transaction, error)
+	BatchPayoutContext(ctx context.Context, fromUserID string, payouts []Payout, description string) ([]*Transaction, error)
+	BlockUser(userID string, blockedUserID string) error
+	Burn(fromUserID string, amount decimal.Decimal) (*Transaction, error)
+	BurnContext(ctx context.Context, fromUserID string, amount decimal.Decimal) (*Transaction, error)
+	CancelCard(cardID string, userID string) error
+	CancelConversionOrder(orderID string, userID string) error
+	CancelGift(giftID string, senderID string) error
+	CancelJob(jobID string) error
+	CancelPaymentLink(linkID string, recipientID string) error
+	CancelScheduledJob(jobID string, actor string, reason string) error
+	CancelWalletClosure(userID string) error
+	CaptureAuthorization(authID string, amount decimal.Decimal) (*CardAuthorization, error)
+	CaptureAuthorizationContext(ctx context.Context, authID string, amount decimal.Decimal) (*CardAuthorization, error)
+	CaptureHold(holdID string, amount decimal.Decimal) (*Hold, error)
+	CaptureHoldContext(ctx context.Context, holdID string, amount decimal.Decimal) (*Hold, error)
+	Charge(req ChargeRequest) (*ChargeReceipt, error)
+	ChargeContext(ctx context.Context, req ChargeRequest) (*ChargeReceipt, error)
+	CheckHealth() HealthReport
+	CheckHotSpots() []HotWallet
+	CheckSupply() []SupplyDeviation
+	CheckWalletIntegrity(userID string) (*BalanceMismatch, error)
+	ChurnedWallets(inactiveFor time.Duration) []ChurnedWallet
+	ClaimGift(claimToken string, userID string) (*Gift, error)
+	ClaimGiftContext(ctx context.Context, claimToken string, userID string) (*Gift, error)
+	ClearDisplayPolicy(tenant string, currency string)
+	ClearMaximumBalance(userID string, currency string)
+	ClearMinimumBalance(userID string, currency string)
+	ClearReceiptTemplate(tenant string)
+	CloseInterestAccount(userID string) error
+	CloseWallet(userID string, req ClosureRequest) (*WalletClosure, error)
+	CloseWalletContext(ctx context.Context, userID string, req ClosureRequest) (*WalletClosure, error)
+	CompleteStepUp(userID string) error
+	CompleteTransaction(txID string) (*Transaction, error)
+	CompleteTransactionContext(ctx context.Context, txID string) (*Transaction, error)
+	ConvertWithQuote(quoteID string) (*Transaction, error)
+	ConvertWithQuoteContext(ctx context.Context, quoteID string) (*Transaction, error)
+	CounterpartyLabel(viewerID string, tx *Transaction) string
+	CreateAutomationRule(userID string, name string, trigger AutomationTrigger, action AutomationAction) (*AutomationRule, error)
+	CreateConversionOrder(userID string, from string, to string, amount decimal.Decimal, at time.Time, recurrence Recurrence) (*ConversionOrder, error)
+	CreateMandate(payerID string, merchantID string, maxPerPeriod decimal.Decimal, period MandatePeriod) (*Mandate, error)
+	CreateOrganization(orgID string, name string, email string) error
+	CreatePaymentLink(recipientID string, amount decimal.Decimal, description string, expiry time.Duration, usage PaymentLinkUsage) (*PaymentLink, error)
+	CreatePendingDeposit(userID string, amount decimal.Decimal, description string) (*Transaction, error)
+	CreateUser(userID string, name string, email string) error
+	CreateUserContext(ctx context.Context, userID string, name string, email string) error
+	DeleteAutomationRule(userID string, ruleID string) error
+	Deposit(userID string, amount float64, description string) error
+	DepositContext(ctx context.Context, userID string, amount decimal.Decimal, description string) error
+	DepositCurrency(userID string, currency string, amount decimal.Decimal, description string) error
+	DepositCurrencyContext(ctx context.Context, userID string, currency string, amount decimal.Decimal, description string) error
+	DepositDecimal(userID string, amount decimal.Decimal, description string) error
+	DepositIdempotent(key string, userID string, amount decimal.Decimal, description string) (*Transaction, error)
+	DepositIdempotentContext(ctx context.Context, key string, userID string, amount decimal.Decimal, description string) (*Transaction, error)
+	DepositRetention(from time.Time, to time.Time, g Granularity, periods int) ([]RetentionCohort, error)
+	DepositViaRail(userID string, account string, amount decimal.Decimal) (*RailTransfer, error)
+	DepositViaRailContext(ctx context.Context, userID string, account string, amount decimal.Decimal) (*RailTransfer, error)
+	DispatchWebhooks() int
+	DisplayAmount(tenant string, amount decimal.Decimal, currency string) string
+	EmailConflicts() []EmailConflict
+	EncodeEvent(evt Event, version int) (EventPayload, int, error)
+	EncryptedBackup(w io.Writer, kw KeyWrapper) error
+	EndImpersonation(sessionID string) error
+	Events(ctx context.Context, types ...EventType) <-chan Event
+	EventsSince(offset int64, limit int) []Event
+	ExpireClientTxIDs() (int, error)
+	ExplainInterest(userID string, period InterestPeriod) (*InterestStatement, error)
+	ExportAllHistories(ctx context.Context, sink ExportSink, opts BulkExportOptions) (ExportCheckpoint, error)
+	ExportTenant(tenantID string, includeHistory bool) (*TenantBundle, error)
+	ExportTransactionHistory(userID string, w io.Writer) error
+	FailTransaction(txID string, reason string) (*Transaction, error)
+	FirstTransactionConversion(from time.Time, to time.Time, g Granularity, within time.Duration) ([]ConversionBucket, error)
+	FormatAmount(amount decimal.Decimal, code string) (string, error)
+	FreezeCard(cardID string, userID string) error
+	FreezeSegment(req SegmentActionRequest) (*SegmentAction, error)
+	FreezeSegmentContext(ctx context.Context, req SegmentActionRequest) (*SegmentAction, error)
+	GetAdjustmentLevels() []AdjustmentLevel
+	GetAdjustmentRequest(requestID string) (*AdjustmentRequest, error)
+	GetAdminFreeze(userID string) *AdminFreeze
+	GetAllUsers() []*User
+	GetAuthorization(authID string) (*CardAuthorization, error)
+	GetAutoSettle(userID string) (bool, error)
+	GetAvailableBalance(userID string) (decimal.Decimal, error)
+	GetBalance(userID string) (float64, error)
+	GetBalanceAt(userID string, at time.Time) (decimal.Decimal, error)
+	GetBalanceContext(ctx context.Context, userID string) (decimal.Decimal, error)
+	GetBalanceDecimal(userID string) (decimal.Decimal, error)
+	GetBalances(userIDs []string) (map[string]decimal.Decimal, error)
+	GetBlockedUsers(userID string) ([]BlockedUser, error)
+	GetCard(cardID string) (*Card, error)
+	GetCase(caseID string) (*ComplianceCase, error)
+	GetCaseEvidence(caseID string, evidenceID string) ([]byte, error)
+	GetCaseMetrics() CaseMetrics
+	GetClosureStatus(userID string) (*WalletClosure, error)
+	GetConsentHistory(userID string) ([]ConsentRecord, error)
+	GetConsentVersion(kind ConsentKind) (*ConsentVersion, error)
+	GetConversionOrder(orderID string) (*ConversionOrder, error)
+	GetCurrency(code string) (Currency, error)
+	GetCurrencyBalance(userID string, currency string) (decimal.Decimal, error)
+	GetDisplayPolicy(tenant string, currency string) (DisplayPolicy, error)
+	GetExpense(expenseID string) (*ExpenseRequest, error)
+	GetFailedOperation(failureID string) (*FailedOperation, error)
+	GetFederatedTransfer(voucherID string) (*OutboundTransfer, error)
+	GetGift(giftID string) (*Gift, error)
+	GetHold(holdID string) (*Hold, error)
+	GetHotWallets(topN int) []HotWallet
+	GetImpersonationSession(sessionID string) (*ImpersonationSession, error)
+	GetInterestAccount(userID string) (*InterestAccount, error)
+	GetJob(jobID string) (*ScheduledJob, error)
+	GetLockStats() []LaneStats
+	GetLoyaltySummary() LoyaltySummary
+	GetMandate(mandateID string) (*Mandate, error)
+	GetMaximumBalance(userID string, currency string) (decimal.Decimal, bool, error)
+	GetMinimumBalance(userID string, currency string) (MinimumBalance, error)
+	GetNotificationPreferences(userID string) (NotificationPreferences, bool)
+	GetOperationStats() []OperationStats
+	GetOrderReservation(orderID string) (*Reservation, error)
+	GetOrderSettlement(orderRef string) (*OrderSettlement, error)
+	GetOverdraft(userID string) (*Overdraft, error)
+	GetPaymentLink(linkID string) (*PaymentLink, error)
+	GetPendingExpenses(orgID string) ([]*ExpenseRequest, error)
+	GetPendingItems(userID string) ([]PendingItem, error)
+	GetPointsBalance(userID string) (int64, error)
+	GetPointsHistory(userID string) ([]PointsEntry, error)
+	GetRailTransfer(id string) (*RailTransfer, error)
+	GetRateRecord(id string) (*RateRecord, error)
+	GetReceipt(receiptID string) (*Receipt, error)
+	GetRefundableAmount(txID string) (decimal.Decimal, error)
+	GetReservation(reservationID string) (*Reservation, error)
+	GetReserve(userID string) (*ReserveSummary, error)
+	GetReservePolicy(userID string) (*ReservePolicy, error)
+	GetRestriction(userID string) (*Restriction, bool)
+	GetRetentionStats() RetentionStats
+	GetRuleExecutions(ruleID string) ([]RuleExecution, error)
+	GetSegmentAction(actionID string) (*SegmentAction, error)
+	GetSpendingLimits(userID string) (SpendingLimits, error)
+	GetSpendingUsage(userID string) (*SpendingUsage, error)
+	GetSystemAccounts() []SystemAccount
+	GetTotalSupply() map[string]decimal.Decimal
+	GetTransaction(txID string) (*Transaction, error)
+	GetTransactionHistory(userID string) ([]*Transaction, error)
+	GetTransactionHistoryContext(ctx context.Context, userID string) ([]*Transaction, error)
+	GetTransactionReceipts(txID string) []Receipt
+	GetTransactionStatus(txID string) (TransactionStatus, error)
+	GetTransactionTree(txID string) (*TransactionTree, error)
+	GetUserAttributes(userID string) (UserAttributes, error)
+	GetUserByEmail(email string) (*User, error)
+	GetUserByIdentity(kind IdentityKind, issuer string, subject string) (*User, error)
+	GetWallet(userID string) (*WalletSnapshot, error)
+	GetWebhookSubscription(subscriptionID string) (*WebhookSubscription, error)
+	HandleRailCallback(cb RailCallback) error
+	HandleRailCallbackContext(ctx context.Context, cb RailCallback) error
+	Hold(userID string, amount decimal.Decimal) (*Hold, error)
+	HoldContext(ctx context.Context, userID string, amount decimal.Decimal) (*Hold, error)
+	ImpersonatedBalance(sessionID string) (decimal.Decimal, error)
+	ImpersonatedHistory(sessionID string) ([]*Transaction, error)
+	ImpersonatedPendingItems(sessionID string) ([]PendingItem, error)
+	ImpersonatedTransfer(sessionID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	ImpersonatedTransferContext(ctx context.Context, sessionID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	ImportTenant(bundle *TenantBundle, policy ConflictPolicy) (*TenantImport, error)
+	ImportedHistory(userID string) ([]Transaction, error)
+	IsBlocked(userID string, counterpartyID string) bool
+	IsHotWallet(userID string) bool
+	IssueCard(userID string, label string, limits CardLimits, validFor time.Duration) (*Card, error)
+	IterateTransactions(userID string, opts IterateOptions) (TransactionIterator, error)
+	IterateTransactionsContext(ctx context.Context, userID string, opts IterateOptions) (TransactionIterator, error)
+	LatestEventOffset() int64
+	LatestSchemaVersion(eventType EventType) int
+	LiftRestriction(userID string) error
+	LinkIdentity(userID string, identity ExternalIdentity) (*ExternalIdentity, error)
+	LinkTransactions(txID string, relatedTxID string) error
+	ListAdjustmentRequests(status AdjustmentStatus) []*AdjustmentRequest
+	ListAdjustments(userID string) ([]*Transaction, error)
+	ListAnnotations(viewerID string, subject AnnotationSubject, subjectID string) ([]Annotation, error)
+	ListAutomationRules(userID string) []AutomationRule
+	ListCards(userID string) []Card
+	ListCases(status CaseStatus) []*ComplianceCase
+	ListConversionOrders(userID string) []ConversionOrder
+	ListCurrencies() []Currency
+	ListEventSchemas(eventType EventType) []EventSchema
+	ListFailedOperations(userID string, reason FailureReason) ([]FailedOperation, error)
+	ListFavorites(userID string) []Favorite
+	ListHolds(userID string) []Hold
+	ListIdentities(userID string) ([]ExternalIdentity, error)
+	ListImpersonationAudit(filter ImpersonationAuditFilter) []ImpersonationAuditEntry
+	ListJobAudit(jobID string) []JobAuditEntry
+	ListLegalHolds() []LegalHold
+	ListMandates(userID string) []Mandate
+	ListPaymentLinks(recipientID string) []PaymentLink
+	ListRailTransfers(userID string) []RailTransfer
+	ListReservations(userID string) []Reservation
+	ListRetentionRuns() []RetentionRun
+	ListSegmentActions() []SegmentAction
+	ListTransactions(userID string, q HistoryQuery) (*HistoryPage, error)
+	ListUpcomingJobs(filter UpcomingFilter) []UpcomingJob
+	ListWithdrawalDestinations(userID string) []WithdrawalDestination
+	MigrateEmailIndex() EmailMigrationReport
+	Mint(toUserID string, amount decimal.Decimal) (*Transaction, error)
+	MintContext(ctx context.Context, toUserID string, amount decimal.Decimal) (*Transaction, error)
+	MissingConsents(userID string) ([]ConsentVersion, error)
+	PauseConversionOrder(orderID string, userID string) error
+	PauseMandate(mandateID string, payerID string) error
+	PayPaymentLink(tokenOrURL string, payerID string, amount decimal.Decimal) (*Transaction, error)
+	PayPaymentLinkContext(ctx context.Context, tokenOrURL string, payerID string, amount decimal.Decimal) (*Transaction, error)
+	PayoutViaRail(userID string, account string, amount decimal.Decimal) (*RailTransfer, error)
+	PayoutViaRailContext(ctx context.Context, userID string, account string, amount decimal.Decimal) (*RailTransfer, error)
+	PendingFederatedTransfers() []OutboundTransfer
+	PlaceLegalHold(hold LegalHold) (*LegalHold, error)
+	PostCustomTransaction(req CustomTransaction) (*Transaction, error)
+	PostCustomTransactionContext(ctx context.Context, req CustomTransaction) (*Transaction, error)
+	PostInterest(userID string, period InterestPeriod) (*InterestStatement, error)
+	PostInterestContext(ctx context.Context, userID string, period InterestPeriod) (*InterestStatement, error)
+	PreviewUserDeletion(userID string) (*DeletionPreview, error)
+	ProcessAutomations() []RuleExecution
+	PublishConsentVersion(v ConsentVersion) error
+	PullFunds(mandateID string, merchantID string, amount decimal.Decimal, description string) (*Transaction, error)
+	PullFundsContext(ctx context.Context, mandateID string, merchantID string, amount decimal.Decimal, description string) (*Transaction, error)
+	QueryAuditLog(q AuditQuery) ([]AuditEntry, error)
+	QuickPay(favoriteID string) (*Transaction, error)
+	QuickPayAmount(favoriteID string, amount decimal.Decimal) (*Transaction, error)
+	QuickPayAmountContext(ctx context.Context, favoriteID string, amount decimal.Decimal) (*Transaction, error)
+	QuickPayContext(ctx context.Context, favoriteID string) (*Transaction, error)
+	QuoteConversion(userID string, from string, to string, amount decimal.Decimal) (*FXQuote, error)
+	QuoteConversionContext(ctx context.Context, userID string, from string, to string, amount decimal.Decimal) (*FXQuote, error)
+	RateAt(from string, to string, at time.Time) (*RateRecord, error)
+	RateHistory(from string, to string, since time.Time, until time.Time) []RateRecord
+	ReceiveFederatedTransfer(voucher FederationVoucher) (*FederationReceipt, error)
+	ReceiveFederatedTransferContext(ctx context.Context, voucher FederationVoucher) (*FederationReceipt, error)
+	Reconcile() (*ReconciliationReport, error)
+	ReconcileSnapshot(snap *Snapshot) []BalanceMismatch
+	RecordAudit(ctx context.Context, entry AuditEntry) error
+	RecordRate(from string, to string, rate decimal.Decimal, source string) (*RateRecord, error)
+	RecoveredTransfers() []RecoveredTransfer
+	RefundTransaction(txID string, amount decimal.Decimal, reason string) (*Transaction, error)
+	RefundTransactionContext(ctx context.Context, txID string, amount decimal.Decimal, reason string) (*Transaction, error)
+	RegisterCurrency(c Currency) error
+	RegisterEventSchema(schema EventSchema) error
+	RegisterHealthCheck(name string, fn HealthCheckFunc)
+	RegisterHoldRule(name string, fn HoldRuleFunc)
+	RegisterRiskHook(name string, fn RiskHookFunc)
+	RegisterValidator(txType TransactionType, fn ValidatorFunc)
+	RegisterWebhook(transport WebhookTransport, cfg WebhookConfig) (string, error)
+	RejectAdjustment(requestID string, checkerID string, comment string) (*AdjustmentRequest, error)
+	ReleaseAuthorization(authID string) (*CardAuthorization, error)
+	ReleaseAuthorizationContext(ctx context.Context, authID string) (*CardAuthorization, error)
+	ReleaseHold(holdID string) (*Hold, error)
+	ReleaseHoldContext(ctx context.Context, holdID string) (*Hold, error)
+	ReleaseLegalHold(holdID string, actor string) error
+	ReleaseReservation(reservationID string) (*Reservation, error)
+	ReleaseReservationContext(ctx context.Context, reservationID string) (*Reservation, error)
+	RemoveFavorite(userID string, favoriteID string) error
+	RemoveInterestOverride(userID string) error
+	RemoveOrgMember(orgID string, userID string) error
+	RemoveReservePolicy(userID string) error
+	RemoveStaff(staffID string) error
+	RemoveWithdrawalDestination(userID string, destinationID string) error
+	ReorderFavorites(userID string, ids []string) error
+	ReplayWebhook(subscriptionID string, fromOffset int64) error
+	RequestAdjustment(makerID string, userID string, amount decimal.Decimal, reason AdjustmentReason, note string) (*AdjustmentRequest, error)
+	RequestAdjustmentContext(ctx context.Context, makerID string, userID string, amount decimal.Decimal, reason AdjustmentReason, note string) (*AdjustmentRequest, error)
+	ResendReceipt(receiptID string) error
+	Reserve(req ReservationRequest) (*Reservation, error)
+	ReserveClientTxID(userID string, clientTxID string, ttl time.Duration) (*ClientTxReservation, error)
+	ReserveContext(ctx context.Context, req ReservationRequest) (*Reservation, error)
+	ResolveCase(caseID string, reviewer string, release bool, note string) (*Transaction, error)
+	ResolveCaseContext(ctx context.Context, caseID string, reviewer string, release bool, note string) (*Transaction, error)
+	ResolvePaymentLink(tokenOrURL string) (*PaymentLink, error)
+	RestrictUser(userID string, source RestrictionSource, reason string) (*Restriction, error)
+	ResumeConversionOrder(orderID string, userID string) error
+	ResumeMandate(mandateID string, payerID string) error
+	RetryOperation(failureID string) (*Transaction, error)
+	RetryOperationContext(ctx context.Context, failureID string) (*Transaction, error)
+	ReviewExpense(expenseID string, reviewerID string, approve bool, comment string) (*ExpenseRequest, error)
+	ReviewExpenseContext(ctx context.Context, expenseID string, reviewerID string, approve bool, comment string) (*ExpenseRequest, error)
+	RevokeMandate(mandateID string, payerID string) error
+	RevokeMinimumBalanceWaiver(userID string) error
+	RunDataRetention() *RetentionRun
+	RunDueJobs() []JobResult
+	ScheduleGift(senderID string, recipient string, amount decimal.Decimal, message string, deliverAt time.Time) (*Gift, error)
+	SchedulePayment(fromUserID string, toUserID string, amount decimal.Decimal, description string, at time.Time, recurrence Recurrence) (string, error)
+	SearchAnnotations(viewerID string, query AnnotationQuery) ([]Annotation, error)
+	SendDigests() int
+	SendFederatedTransfer(fromUserID string, targetInstance string, toUserID string, amount decimal.Decimal, description string) (*FederationVoucher, error)
+	SendFederatedTransferContext(ctx context.Context, fromUserID string, targetInstance string, toUserID string, amount decimal.Decimal, description string) (*FederationVoucher, error)
+	SessionToken() SessionToken
+	SetAdjustmentLevels(levels []AdjustmentLevel) error
+	SetApprovalChain(orgID string, chain []ApprovalStep) error
+	SetAutoSettle(userID string, enabled bool) error
+	SetAutomationRulePaused(userID string, ruleID string, paused bool) error
+	SetBalance(userID string, target decimal.Decimal, reason AdjustmentReason) (*Transaction, error)
+	SetBalanceContext(ctx context.Context, userID string, target decimal.Decimal, reason AdjustmentReason) (*Transaction, error)
+	SetCardLimits(cardID string, userID string, limits CardLimits) error
+	SetDisplayPolicy(tenant string, currency string, policy DisplayPolicy) error
+	SetInterestAccount(userID string, account InterestAccount) (*InterestAccount, error)
+	SetInterestOverride(userID string, o InterestOverride) error
+	SetMaximumBalance(userID string, currency string, maximum decimal.Decimal) error
+	SetMinimumBalance(userID string, currency string, minimum decimal.Decimal) error
+	SetMinimumBalanceTier(tier string, minimums map[string]decimal.Decimal) error
+	SetNotificationPreferences(userID string, prefs NotificationPreferences) error
+	SetOverdraftLimit(userID string, limit decimal.Decimal) error
+	SetReceiptTemplate(tenant string, tmpl ReceiptTemplate) error
+	SetReservePolicy(userID string, policy ReservePolicy) error
+	SetSpendingLimits(userID string, limits SpendingLimits) error
+	SetStaffRole(staffID string, role StaffRole) error
+	SetStatementDescriptor(userID string, descriptor string, actorID string) error
+	SetUserAttributes(userID string, attrs UserAttributes) error
+	SetWebhookSchemaVersion(subscriptionID string, eventType EventType, version int) error
+	SettleOrder(buyerID string, orderRef string, total decimal.Decimal, splits []Split) (*OrderSettlement, error)
+	SettleOrderContext(ctx context.Context, buyerID string, orderRef string, total decimal.Decimal, splits []Split) (*OrderSettlement, error)
+	SettleReservation(reservationID string, amount decimal.Decimal, reference string) (*Reservation, error)
+	SettleReservationContext(ctx context.Context, reservationID string, amount decimal.Decimal, reference string) (*Reservation, error)
+	SettlementDate(t time.Time, lag int) time.Time
+	SkipJobOccurrence(jobID string, runAt int64, actor string, reason string) error
+	SkipNextConversion(orderID string, userID string) error
+	Snapshot() *Snapshot
+	SnapshotOnline() (*Snapshot, error)
+	StartImpersonation(req ImpersonationRequest) (*ImpersonationSession, error)
+	StartScheduler(interval time.Duration)
+	StartWebhookDispatcher(interval time.Duration)
+	StatementDescriptor(userID string) string
+	StatementDescriptorHistory(userID string) ([]DescriptorChange, error)
+	StopScheduler()
+	StopWebhookDispatcher()
+	SubmitExpense(orgID string, submitterID string, payeeID string, amount decimal.Decimal, description string) (*ExpenseRequest, error)
+	SummarizeConversionOrder(orderID string, since time.Time, until time.Time) (*ConversionOrderSummary, error)
+	TestClock() *SimClock
+	Transfer(fromUserID string, toUserID string, amount float64, description string) error
+	TransferClientTxID(clientTxID string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	TransferClientTxIDContext(ctx context.Context, clientTxID string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	TransferContext(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, description string) error
+	TransferDecimal(fromUserID string, toUserID string, amount decimal.Decimal, description string) error
+	TransferIdempotent(key string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	TransferIdempotentContext(ctx context.Context, key string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*Transaction, error)
+	TransferWithFloor(fromUserID string, toUserID string, amount decimal.Decimal, minRemaining decimal.Decimal) (*Transaction, error)
+	TransferWithFloorContext(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, minRemaining decimal.Decimal) (*Transaction, error)
+	TriggerJob(jobID string, actor string, reason string) (JobResult, error)
+	UnblockUser(userID string, blockedUserID string) error
+	UnfreezeCard(cardID string, userID string) error
+	UnfreezeSegment(req SegmentActionRequest) (*SegmentAction, error)
+	UnfreezeSegmentContext(ctx context.Context, req SegmentActionRequest) (*SegmentAction, error)
+	UnlinkIdentity(userID string, identity ExternalIdentity) error
+	UnregisterWebhook(subscriptionID string) error
+	UpdateFavorite(userID string, favoriteID string, spec FavoriteSpec) (*Favorite, error)
+	UpdateUserEmail(userID string, email string) error
+	VerifyWithdrawalDestination(userID string, destinationID string) error
+	WaiveMinimumBalance(userID string, actor string, reason string) error
+	Withdraw(userID string, amount float64, description string) error
+	WithdrawConsent(userID string, kind ConsentKind) error
+	WithdrawContext(ctx context.Context, userID string, decimalAmount decimal.Decimal, description string) error
+	WithdrawDecimal(userID string, decimalAmount decimal.Decimal, description string) error
+	WithdrawIdempotent(key string, userID string, amount decimal.Decimal, description string) (*Transaction, error)
+	WithdrawIdempotentContext(ctx context.Context, key string, userID string, amount decimal.Decimal, description string) (*Transaction, error)
+	WithdrawTo(userID string, destinationID string, amount decimal.Decimal, description string) (*Transaction, error)
+	WithdrawToContext(ctx context.Context, userID string, destinationID string, amount decimal.Decimal, description string) (*Transaction, error)
+}
+
+var _ Service = (*WalletService)(nil)
@@ -0,0 +1,134 @@
+// internal/wallet/session.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Error definitions for session consistency
+var (
+	ErrInvalidSessionToken = errors.New("invalid session token")
+	ErrSessionBehind       = errors.New("read could not catch up with the session's writes")
+)
+
+// DefaultSessionWait bounds how long a read waits for a session's writes when its
+// context has no deadline
+const DefaultSessionWait = 2 * time.Second
+
+// SessionToken names a point in the transaction log. A client keeps the token of its
+// latest write and presents it with its reads; a read given a token reflects at least
+// every write up to that point, so a balance fetched right after a deposit never
+// predates the deposit even when served by a copy that lags behind the writer.
+type SessionToken string
+
+// sessionKey is the context key of a session token
+type sessionKey struct{}
+
+// logSignal wakes goroutines waiting for a log to grow, such as reads waiting for their
+// session token or event subscribers waiting for the next event
+type logSignal struct {
+	mu       sync.Mutex
+	advanced chan struct{} // closed when the log grows; nil while nobody waits
+}
+
+// SessionToken returns a token covering every write completed so far. Call it after a
+// write and hand the token to the client.
+func (ws *WalletService) SessionToken() SessionToken {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return SessionToken("s" + strconv.Itoa(ws.logHead()))
+}
+
+// ContextWithSessionToken returns a copy of ctx carrying token. Reads through the
+// Context variants of the service methods then wait for the writes it covers.
+func ContextWithSessionToken(ctx context.Context, token SessionToken) context.Context {
+	return context.WithValue(ctx, sessionKey{}, token)
+}
+
+// SessionTokenFromContext returns the session token carried by ctx, if any
+func SessionTokenFromContext(ctx context.Context) SessionToken {
+	token, _ := ctx.Value(sessionKey{}).(SessionToken)
+	return token
+}
+
+// AwaitSession waits until the service reflects every write covered by token. It gives
+// up with ErrSessionBehind when ctx is done or, without a deadline, after
+// DefaultSessionWait. An empty token is satisfied at once.
+func (ws *WalletService) AwaitSession(ctx context.Context, token SessionToken) error {
+	if token == "" {
+		return nil
+	}
+	target, err := parseSessionToken(token)
+	if err != nil {
+		return err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultSessionWait)
+		defer cancel()
+	}
+
+	for {
+		// Take the channel before checking the log, so a write landing in between
+		// still wakes us
+		advanced := ws.sessions.wait()
+		ws.mu.RLock()
+		head := ws.logHead()
+		ws.mu.RUnlock()
+		if head >= target {
+			return nil
+		}
+		select {
+		case <-advanced:
+		case <-ctx.Done():
+			ws.metrics.IncCounter("session_reads_behind_total", nil)
+			return ErrSessionBehind
+		}
+	}
+}
+
+// awaitContextSession waits for the session token carried by ctx, if any
+func (ws *WalletService) awaitContextSession(ctx context.Context) error {
+	return ws.AwaitSession(ctx, SessionTokenFromContext(ctx))
+}
+
+// logHead returns the log position after the newest entry. Caller must hold ws.mu.
+func (ws *WalletService) logHead() int {
+	return ws.logBase + len(ws.transactions)
+}
+
+// wait returns a channel closed by the next advance
+func (s *logSignal) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.advanced == nil {
+		s.advanced = make(chan struct{})
+	}
+	return s.advanced
+}
+
+// advance wakes everything waiting for the log to grow
+func (s *logSignal) advance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.advanced != nil {
+		close(s.advanced)
+		s.advanced = nil
+	}
+}
+
+// parseSessionToken returns the log position a token made by SessionToken names
+func parseSessionToken(token SessionToken) (int, error) {
+	if len(token) < 2 || token[0] != 's' {
+		return 0, ErrInvalidSessionToken
+	}
+	n, err := strconv.Atoi(string(token[1:]))
+	if err != nil || n < 0 {
+		return 0, ErrInvalidSessionToken
+	}
+	return n, nil
+}
@@ -0,0 +1,68 @@
+// internal/wallet/session_test.go
+package wallet
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAwaitSession(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	before := ws.SessionToken()
+	ws.Deposit("alice", 10, "seed")
+	after := ws.SessionToken()
+	if before == after {
+		t.Fatalf("token did not advance with a write: %q", after)
+	}
+
+	tests := []struct {
+		name    string
+		token   SessionToken
+		wantErr error
+	}{
+		{"none", "", nil},
+		{"older write", before, nil},
+		{"latest write", after, nil},
+		{"not yet written", "s99", ErrSessionBehind},
+		{"malformed", "x1", ErrInvalidSessionToken},
+		{"negative", "s-1", ErrInvalidSessionToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := ws.AwaitSession(ctx, tt.token); err != tt.wantErr {
+				t.Errorf("AwaitSession(%q) error = %v, want %v", tt.token, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionReadWaitsForWrite(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	// A token one entry ahead stands in for a write the reader has not seen yet
+	ahead, _ := parseSessionToken(ws.SessionToken())
+	ctx := ContextWithSessionToken(context.Background(), SessionToken("s"+strconv.Itoa(ahead+1)))
+
+	done := make(chan decimal.Decimal)
+	go func() {
+		balance, err := ws.GetBalanceContext(ctx, "alice")
+		if err != nil {
+			t.Errorf("GetBalanceContext() error = %v", err)
+		}
+		done <- balance
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ws.Deposit("alice", 25, "late write")
+
+	if balance := <-done; !balance.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("balance = %s, want the awaited deposit of 25", balance)
+	}
+}
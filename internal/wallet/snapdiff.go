@@ -0,0 +1,219 @@
+// internal/wallet/snapdiff.go
+package wallet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// SnapshotDiff reports what changed between two snapshots, e.g. before and after a
+// migration or around an incident
+type SnapshotDiff struct {
+	FromCreatedAt   int64
+	ToCreatedAt     int64
+	FromLogPosition int
+	ToLogPosition   int
+
+	AddedUsers   []string
+	RemovedUsers []string
+	ChangedUsers []UserChange
+
+	Balances     []BalanceDelta // per user and currency, only where the balance moved
+	Transactions []TxCountDelta // per user, only where the count changed
+	Supply       []BalanceDelta // per currency, UserID empty
+
+	// MissingTransactions lists IDs present in the older snapshot but not the newer.
+	// The log is append-only, so anything here means lost or archived history.
+	MissingTransactions []string
+}
+
+// UserChange records a user whose profile differs between snapshots
+type UserChange struct {
+	UserID string
+	Field  string // "name" or "email"
+	Before string
+	After  string
+}
+
+// BalanceDelta is a balance that moved between snapshots
+type BalanceDelta struct {
+	UserID   string
+	Currency string
+	Before   decimal.Decimal
+	After    decimal.Decimal
+	Delta    decimal.Decimal
+}
+
+// TxCountDelta is a change in how many transactions involve a user
+type TxCountDelta struct {
+	UserID string
+	Before int
+	After  int
+}
+
+// Empty reports whether the snapshots hold the same users, balances and history
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.AddedUsers) == 0 && len(d.RemovedUsers) == 0 && len(d.ChangedUsers) == 0 &&
+		len(d.Balances) == 0 && len(d.Transactions) == 0 && len(d.Supply) == 0 &&
+		len(d.MissingTransactions) == 0
+}
+
+// DiffSnapshots compares snapshot a with a later snapshot b. Every list in the report is
+// sorted by user and currency so reports of the same snapshots compare equal.
+func DiffSnapshots(a, b *Snapshot) *SnapshotDiff {
+	d := &SnapshotDiff{
+		FromCreatedAt:   a.CreatedAt,
+		ToCreatedAt:     b.CreatedAt,
+		FromLogPosition: a.LogPosition,
+		ToLogPosition:   b.LogPosition,
+	}
+
+	before := make(map[string]User, len(a.Users))
+	for _, u := range a.Users {
+		before[u.ID] = u
+	}
+	after := make(map[string]User, len(b.Users))
+	for _, u := range b.Users {
+		after[u.ID] = u
+		old, existed := before[u.ID]
+		if !existed {
+			d.AddedUsers = append(d.AddedUsers, u.ID)
+			continue
+		}
+		if old.Name != u.Name {
+			d.ChangedUsers = append(d.ChangedUsers, UserChange{UserID: u.ID, Field: "name", Before: old.Name, After: u.Name})
+		}
+		if old.Email != u.Email {
+			d.ChangedUsers = append(d.ChangedUsers, UserChange{UserID: u.ID, Field: "email", Before: old.Email, After: u.Email})
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			d.RemovedUsers = append(d.RemovedUsers, id)
+		}
+	}
+	sort.Strings(d.AddedUsers)
+	sort.Strings(d.RemovedUsers)
+	sort.SliceStable(d.ChangedUsers, func(i, j int) bool { return d.ChangedUsers[i].UserID < d.ChangedUsers[j].UserID })
+
+	d.Balances = balanceDeltas(walletBalances(a.Wallets), walletBalances(b.Wallets))
+	d.Supply = balanceDeltas(map[string]map[string]decimal.Decimal{"": a.Supply}, map[string]map[string]decimal.Decimal{"": b.Supply})
+
+	countsBefore, countsAfter := txCounts(a.Transactions), txCounts(b.Transactions)
+	for _, id := range unionKeys(countsBefore, countsAfter) {
+		if countsBefore[id] != countsAfter[id] {
+			d.Transactions = append(d.Transactions, TxCountDelta{UserID: id, Before: countsBefore[id], After: countsAfter[id]})
+		}
+	}
+
+	ids := make(map[string]bool, len(b.Transactions))
+	for i := range b.Transactions {
+		ids[b.Transactions[i].ID] = true
+	}
+	for i := range a.Transactions {
+		if !ids[a.Transactions[i].ID] {
+			d.MissingTransactions = append(d.MissingTransactions, a.Transactions[i].ID)
+		}
+	}
+	return d
+}
+
+// walletBalances indexes base and foreign balances by user and currency
+func walletBalances(wallets []WalletSnapshot) map[string]map[string]decimal.Decimal {
+	out := make(map[string]map[string]decimal.Decimal, len(wallets))
+	for _, w := range wallets {
+		balances := map[string]decimal.Decimal{w.Currency: w.Balance}
+		for currency, amount := range w.Foreign {
+			balances[currency] = amount
+		}
+		out[w.UserID] = balances
+	}
+	return out
+}
+
+// balanceDeltas lists every user and currency whose balance differs; a missing balance
+// counts as zero
+func balanceDeltas(before, after map[string]map[string]decimal.Decimal) []BalanceDelta {
+	var deltas []BalanceDelta
+	for _, userID := range unionKeys(before, after) {
+		for _, currency := range unionKeys(before[userID], after[userID]) {
+			from, to := before[userID][currency], after[userID][currency]
+			if !from.Equal(to) {
+				deltas = append(deltas, BalanceDelta{UserID: userID, Currency: currency, Before: from, After: to, Delta: to.Sub(from)})
+			}
+		}
+	}
+	return deltas
+}
+
+// txCounts counts the transactions each user is a party to
+func txCounts(txs []Transaction) map[string]int {
+	counts := make(map[string]int)
+	for i := range txs {
+		tx := &txs[i]
+		if tx.FromUserID != "" {
+			counts[tx.FromUserID]++
+		}
+		if tx.ToUserID != "" && tx.ToUserID != tx.FromUserID {
+			counts[tx.ToUserID]++
+		}
+	}
+	return counts
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteJSON writes the report as indented JSON
+func (d *SnapshotDiff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteCSV writes the report as one row per change: kind, user, field or currency,
+// before, after and delta
+func (d *SnapshotDiff) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "user_id", "field", "before", "after", "delta"})
+	for _, id := range d.AddedUsers {
+		cw.Write([]string{"user_added", id, "", "", "", ""})
+	}
+	for _, id := range d.RemovedUsers {
+		cw.Write([]string{"user_removed", id, "", "", "", ""})
+	}
+	for _, c := range d.ChangedUsers {
+		cw.Write([]string{"user_changed", c.UserID, c.Field, c.Before, c.After, ""})
+	}
+	for _, b := range d.Balances {
+		cw.Write([]string{"balance", b.UserID, b.Currency, b.Before.String(), b.After.String(), b.Delta.String()})
+	}
+	for _, c := range d.Transactions {
+		cw.Write([]string{"transactions", c.UserID, "", strconv.Itoa(c.Before), strconv.Itoa(c.After), strconv.Itoa(c.After - c.Before)})
+	}
+	for _, s := range d.Supply {
+		cw.Write([]string{"supply", "", s.Currency, s.Before.String(), s.After.String(), s.Delta.String()})
+	}
+	for _, id := range d.MissingTransactions {
+		cw.Write([]string{"transaction_missing", "", id, "", "", ""})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,98 @@
+// internal/wallet/snapdiff_test.go
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+	before := ws.Snapshot()
+
+	if diff := DiffSnapshots(before, ws.Snapshot()); !diff.Empty() {
+		t.Fatalf("diff of identical state = %+v", diff)
+	}
+
+	ws.CreateUser("carol", "Carol", "c@example.com")
+	ws.UpdateUserEmail("bob", "bob@example.com")
+	ws.Transfer("alice", "bob", 30, "rent")
+	ws.DepositCurrency("carol", "EUR", decimal.NewFromInt(5), "")
+	after := ws.Snapshot()
+
+	diff := DiffSnapshots(before, after)
+	if strings.Join(diff.AddedUsers, ",") != "carol" || len(diff.RemovedUsers) != 0 {
+		t.Errorf("added %v removed %v", diff.AddedUsers, diff.RemovedUsers)
+	}
+	if len(diff.ChangedUsers) != 1 || diff.ChangedUsers[0] != (UserChange{UserID: "bob", Field: "email", Before: "b@example.com", After: "bob@example.com"}) {
+		t.Errorf("ChangedUsers = %+v", diff.ChangedUsers)
+	}
+
+	wantBalances := []struct {
+		user, currency string
+		delta          int64
+	}{
+		{"alice", "USD", -30},
+		{"bob", "USD", 30},
+		{"carol", "EUR", 5},
+	}
+	if len(diff.Balances) != len(wantBalances) {
+		t.Fatalf("Balances = %+v", diff.Balances)
+	}
+	for i, w := range wantBalances {
+		got := diff.Balances[i]
+		if got.UserID != w.user || got.Currency != w.currency || !got.Delta.Equal(decimal.NewFromInt(w.delta)) {
+			t.Errorf("Balances[%d] = %s %s %s, want %s %s %d", i, got.UserID, got.Currency, got.Delta, w.user, w.currency, w.delta)
+		}
+	}
+
+	wantCounts := []TxCountDelta{{"alice", 1, 2}, {"bob", 0, 1}, {"carol", 0, 1}}
+	if len(diff.Transactions) != len(wantCounts) {
+		t.Fatalf("Transactions = %+v", diff.Transactions)
+	}
+	for i, w := range wantCounts {
+		if diff.Transactions[i] != w {
+			t.Errorf("Transactions[%d] = %+v, want %+v", i, diff.Transactions[i], w)
+		}
+	}
+
+	// Reversing the comparison surfaces history the newer side lacks
+	if reverse := DiffSnapshots(after, before); len(reverse.MissingTransactions) != 2 || len(reverse.RemovedUsers) != 1 {
+		t.Errorf("reverse diff = %+v", reverse)
+	}
+
+	var csvOut bytes.Buffer
+	if err := diff.WriteCSV(&csvOut); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	for _, row := range []string{
+		"kind,user_id,field,before,after,delta",
+		"user_added,carol,,,,",
+		"user_changed,bob,email,b@example.com,bob@example.com,",
+		"balance,alice,USD,100,70,-30",
+		"transactions,bob,,0,1,1",
+	} {
+		if !strings.Contains(csvOut.String(), row+"\n") {
+			t.Errorf("CSV missing %q:\n%s", row, csvOut.String())
+		}
+	}
+
+	var jsonOut bytes.Buffer
+	if err := diff.WriteJSON(&jsonOut); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded SnapshotDiff
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding JSON report: %v", err)
+	}
+	if len(decoded.Balances) != 3 || !decoded.Balances[1].After.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("decoded Balances = %+v", decoded.Balances)
+	}
+}
@@ -0,0 +1,548 @@
+// internal/wallet/store.go
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrStoreClosed is returned when writing to a closed FileStore
+var ErrStoreClosed = errors.New("store is closed")
+
+// Store persists users, wallets and the transaction log. The service keeps its working
+// set in memory, where the locking model lives, and writes every change through to the
+// store in log order; OpenWalletService loads the state back after a restart. Writes
+// happen while the log is locked, so implementations should be quick.
+type Store interface {
+	SaveUser(user User) error
+	SaveWallet(wallet WalletSnapshot) error
+	AppendTransaction(tx Transaction) error
+	Load() (*StoreState, error)
+}
+
+// AtomicStore is a Store that can commit a transaction together with the wallets it
+// touched, so a crash never leaves the log and the balances disagreeing. The service
+// uses CommitTransaction instead of separate writes when the store implements it.
+type AtomicStore interface {
+	Store
+	CommitTransaction(tx Transaction, wallets []WalletSnapshot) error
+}
+
+// BatchStore is a Store that can commit a group of transactions, each with the wallets
+// it touched, in one write. With WithWriteBatching the service uses it to write every
+// batch of log appends at once.
+type BatchStore interface {
+	Store
+	CommitTransactions(commits []TransactionCommit) error
+}
+
+// TransactionCommit is a transaction and the wallets as it leaves them
+type TransactionCommit struct {
+	Transaction Transaction
+	Wallets     []WalletSnapshot
+}
+
+// StoreState is everything a Store holds: the latest version of each user and wallet
+// and the full transaction log in order
+type StoreState struct {
+	Users        []User
+	Wallets      []WalletSnapshot
+	Transactions []Transaction
+}
+
+// storeStatus tracks write-through failures. Guarded by ws.mu.
+type storeStatus struct {
+	failures int64
+	lastErr  error
+}
+
+// WithStore writes users, wallets and transactions through to s. Use OpenWalletService
+// to also load the state s already holds.
+func WithStore(s Store) Option {
+	return func(ws *WalletService) {
+		ws.store = s
+	}
+}
+
+// OpenWalletService creates a service backed by store, loading the users, wallets and
+// transactions it holds. Transactions the store journaled but did not finish writing
+// are recovered first.
+func OpenWalletService(store Store, opts ...Option) (*WalletService, error) {
+	recovered, err := RecoverTransfers(store)
+	if err != nil {
+		return nil, fmt.Errorf("recovering transfers: %w", err)
+	}
+	state, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading store: %w", err)
+	}
+	snap := &Snapshot{
+		Version:      SnapshotVersion,
+		LogPosition:  len(state.Transactions),
+		Users:        state.Users,
+		Wallets:      state.Wallets,
+		Transactions: state.Transactions,
+	}
+	ws, err := RestoreSnapshot(snap, append(opts, WithStore(store))...)
+	if err != nil {
+		return nil, err
+	}
+	ws.recovered = recovered
+	for _, r := range recovered {
+		ws.metrics.IncCounter("transfers_recovered_total", map[string]string{"outcome": string(r.Outcome)})
+	}
+	return ws, nil
+}
+
+// persistUser writes a user and their wallet through to the store. Caller must hold
+// ws.mu for writing.
+func (ws *WalletService) persistUser(user *User, wallet *Wallet) {
+	if ws.store == nil {
+		return
+	}
+	ws.storeResult(ws.store.SaveUser(*user))
+	if wallet != nil {
+		wallet.mu.RLock()
+		snap := wallet.snapshot()
+		wallet.mu.RUnlock()
+		ws.storeResult(ws.store.SaveWallet(snap))
+	}
+}
+
+// persistTransactions writes logged transactions through to the store, in one write
+// when there are several and the store is a BatchStore. Caller must hold ws.mu for
+// writing.
+func (ws *WalletService) persistTransactions(txs []*Transaction) {
+	if ws.store == nil {
+		return
+	}
+	batcher, ok := ws.store.(BatchStore)
+	if !ok || len(txs) == 1 {
+		for _, tx := range txs {
+			ws.persistTransaction(tx)
+		}
+		return
+	}
+
+	commits := make([]TransactionCommit, len(txs))
+	for i, tx := range txs {
+		commits[i] = TransactionCommit{Transaction: *tx, Wallets: ws.touchedWallets(tx)}
+	}
+	ws.storeResult(batcher.CommitTransactions(commits))
+}
+
+// persistTransaction writes a logged transaction and the wallets it touched through to
+// the store. Caller must hold ws.mu for writing.
+func (ws *WalletService) persistTransaction(tx *Transaction) {
+	if ws.store == nil {
+		return
+	}
+	wallets := ws.touchedWallets(tx)
+
+	if committer, ok := ws.store.(AtomicStore); ok {
+		ws.storeResult(committer.CommitTransaction(*tx, wallets))
+		return
+	}
+
+	// The entry stays open only if the process dies before every write was attempted
+	journal, journaled := ws.store.(TransferJournal)
+	if journaled {
+		err := journal.BeginTransfer(JournalEntry{Transaction: *tx, Wallets: wallets, StartedAt: ws.now().Unix()})
+		ws.storeResult(err)
+		journaled = err == nil
+	}
+	ws.storeResult(ws.store.AppendTransaction(*tx))
+	for _, snap := range wallets {
+		ws.storeResult(ws.store.SaveWallet(snap))
+	}
+	if journaled {
+		ws.storeResult(journal.EndTransfer(tx.ID))
+	}
+}
+
+// touchedWallets returns snapshots of the wallets tx touched. Caller must hold ws.mu.
+func (ws *WalletService) touchedWallets(tx *Transaction) []WalletSnapshot {
+	ids := []string{tx.FromUserID}
+	if tx.ToUserID != tx.FromUserID {
+		ids = append(ids, tx.ToUserID)
+	}
+	var wallets []WalletSnapshot
+	for _, id := range ids {
+		if wallet, exists := ws.wallets[id]; exists {
+			wallet.mu.RLock()
+			wallets = append(wallets, wallet.snapshot())
+			wallet.mu.RUnlock()
+		}
+	}
+	return wallets
+}
+
+// persistWallet writes one wallet through to the store
+func (ws *WalletService) persistWallet(wallet *Wallet) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.store == nil {
+		return
+	}
+	wallet.mu.RLock()
+	snap := wallet.snapshot()
+	wallet.mu.RUnlock()
+	ws.storeResult(ws.store.SaveWallet(snap))
+}
+
+// storeResult records a failed store write. The in-memory change already happened, so
+// the failure is reported through metrics and the health check. Caller must hold ws.mu
+// for writing.
+func (ws *WalletService) storeResult(err error) {
+	if err == nil {
+		return
+	}
+	ws.storeStatus.failures++
+	ws.storeStatus.lastErr = err
+	ws.metrics.IncCounter("store_write_failures_total", nil)
+}
+
+// storeHealth fails once a write to the store has failed
+func (ws *WalletService) storeHealth() HealthCheckResult {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	if ws.storeStatus.failures == 0 {
+		return HealthCheckResult{Status: HealthOK}
+	}
+	return HealthCheckResult{
+		Status: HealthFailing,
+		Detail: fmt.Sprintf("%d store writes failed, last: %v", ws.storeStatus.failures, ws.storeStatus.lastErr),
+	}
+}
+
+// snapshot returns the persisted form of the wallet. Caller must hold w.mu.
+func (w *Wallet) snapshot() WalletSnapshot {
+	foreign := make(map[string]decimal.Decimal, len(w.Foreign))
+	for currency, amount := range w.Foreign {
+		foreign[currency] = amount
+	}
+	return WalletSnapshot{
+		UserID:   w.UserID,
+		Currency: w.Currency,
+		Balance:  w.Balance,
+		Foreign:  foreign,
+
+		AutoSettle: w.AutoSettle,
+	}
+}
+
+// MemoryStore is an in-process Store, useful for tests and for handing state between
+// services in one process
+type MemoryStore struct {
+	mu           sync.RWMutex
+	users        map[string]User
+	wallets      map[string]WalletSnapshot
+	transactions []Transaction
+	journal      []JournalEntry
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:   make(map[string]User),
+		wallets: make(map[string]WalletSnapshot),
+	}
+}
+
+// SaveUser stores the latest version of a user
+func (s *MemoryStore) SaveUser(user User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+	return nil
+}
+
+// SaveWallet stores the latest version of a wallet
+func (s *MemoryStore) SaveWallet(wallet WalletSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wallets[wallet.UserID] = wallet
+	return nil
+}
+
+// AppendTransaction adds a transaction to the log
+func (s *MemoryStore) AppendTransaction(tx Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactions = append(s.transactions, tx)
+	return nil
+}
+
+// Load returns the stored state
+func (s *MemoryStore) Load() (*StoreState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return newStoreState(s.users, s.wallets, s.transactions), nil
+}
+
+// FileStore is a Store kept in an append-only file of JSON records. Load replays the
+// file, keeping the last record of each user and wallet; Compact rewrites the file
+// with only those.
+type FileStore struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	writer *bufio.Writer
+	sync   bool
+}
+
+// storeRecord is one line of a FileStore
+type storeRecord struct {
+	User        *User           `json:"user,omitempty"`
+	Wallet      *WalletSnapshot `json:"wallet,omitempty"`
+	Transaction *Transaction    `json:"transaction,omitempty"`
+
+	JournalBegin *JournalEntry `json:"journal_begin,omitempty"`
+	JournalEnd   string        `json:"journal_end,omitempty"`
+}
+
+// fileReplay is the result of reading a FileStore from the start
+type fileReplay struct {
+	state *StoreState
+	open  []JournalEntry
+}
+
+// NewFileStore opens or creates the store file at path. With syncWrites every record is
+// flushed and fsynced before the write returns; otherwise records are buffered until
+// Flush or Close.
+func NewFileStore(path string, syncWrites bool) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{path: path, file: f, writer: bufio.NewWriter(f), sync: syncWrites}, nil
+}
+
+// SaveUser appends a user record
+func (s *FileStore) SaveUser(user User) error {
+	return s.append(storeRecord{User: &user})
+}
+
+// SaveWallet appends a wallet record
+func (s *FileStore) SaveWallet(wallet WalletSnapshot) error {
+	return s.append(storeRecord{Wallet: &wallet})
+}
+
+// AppendTransaction appends a transaction record
+func (s *FileStore) AppendTransaction(tx Transaction) error {
+	return s.append(storeRecord{Transaction: &tx})
+}
+
+// Load replays the file
+func (s *FileStore) Load() (*StoreState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// load replays the file. Caller must hold s.mu.
+func (s *FileStore) load() (*StoreState, error) {
+	replay, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+	return replay.state, nil
+}
+
+// replay reads every record, keeping the last of each user and wallet and the journal
+// entries not yet closed. Caller must hold s.mu.
+func (s *FileStore) replay() (*fileReplay, error) {
+	if s.file == nil {
+		return nil, ErrStoreClosed
+	}
+	if err := s.writer.Flush(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	users := make(map[string]User)
+	wallets := make(map[string]WalletSnapshot)
+	var transactions []Transaction
+	var open []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rec storeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", s.path, line, err)
+		}
+		switch {
+		case rec.User != nil:
+			users[rec.User.ID] = *rec.User
+		case rec.Wallet != nil:
+			wallets[rec.Wallet.UserID] = *rec.Wallet
+		case rec.Transaction != nil:
+			transactions = append(transactions, *rec.Transaction)
+		case rec.JournalBegin != nil:
+			open = append(open, *rec.JournalBegin)
+		case rec.JournalEnd != "":
+			for i := range open {
+				if open[i].Transaction.ID == rec.JournalEnd {
+					open = append(open[:i], open[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &fileReplay{state: newStoreState(users, wallets, transactions), open: open}, nil
+}
+
+// Compact rewrites the file with one record per user and wallet followed by the log
+// and any journal entries still open
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replay, err := s.replay()
+	if err != nil {
+		return err
+	}
+	state := replay.state
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := range state.Users {
+		err = errors.Join(err, enc.Encode(storeRecord{User: &state.Users[i]}))
+	}
+	for i := range state.Wallets {
+		err = errors.Join(err, enc.Encode(storeRecord{Wallet: &state.Wallets[i]}))
+	}
+	for i := range state.Transactions {
+		err = errors.Join(err, enc.Encode(storeRecord{Transaction: &state.Transactions[i]}))
+	}
+	for i := range replay.open {
+		err = errors.Join(err, enc.Encode(storeRecord{JournalBegin: &replay.open[i]}))
+	}
+	err = errors.Join(err, w.Flush(), f.Sync(), f.Close())
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	s.file.Close()
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		s.file = nil
+		return err
+	}
+	s.writer = bufio.NewWriter(s.file)
+	return nil
+}
+
+// Flush writes buffered records to the file
+func (s *FileStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+	return s.writer.Flush()
+}
+
+// Close flushes and closes the file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.writer.Flush(), s.file.Close())
+	s.file = nil
+	return err
+}
+
+// CommitTransactions appends each transaction with its wallet records, journaled so a
+// crash part way through the group is recovered like a single transfer, and with one
+// flush and fsync for the whole group
+func (s *FileStore) CommitTransactions(commits []TransactionCommit) error {
+	recs := make([]storeRecord, 0, len(commits)*4)
+	for i := range commits {
+		c := &commits[i]
+		recs = append(recs, storeRecord{JournalBegin: &JournalEntry{Transaction: c.Transaction, Wallets: c.Wallets, StartedAt: c.Transaction.Timestamp}})
+		recs = append(recs, storeRecord{Transaction: &c.Transaction})
+		for j := range c.Wallets {
+			recs = append(recs, storeRecord{Wallet: &c.Wallets[j]})
+		}
+		recs = append(recs, storeRecord{JournalEnd: c.Transaction.ID})
+	}
+	return s.append(recs...)
+}
+
+// append writes records, flushing once after the last
+func (s *FileStore) append(recs ...storeRecord) error {
+	var buf []byte
+	for _, rec := range recs {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+	if _, err := s.writer.Write(buf); err != nil {
+		return err
+	}
+	if !s.sync {
+		return nil
+	}
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// newStoreState copies stored users, wallets and transactions into a StoreState,
+// sorting users and wallets by ID
+func newStoreState(users map[string]User, wallets map[string]WalletSnapshot, transactions []Transaction) *StoreState {
+	state := &StoreState{
+		Users:        make([]User, 0, len(users)),
+		Wallets:      make([]WalletSnapshot, 0, len(wallets)),
+		Transactions: append([]Transaction(nil), transactions...),
+	}
+	for _, user := range users {
+		state.Users = append(state.Users, user)
+	}
+	for _, wallet := range wallets {
+		state.Wallets = append(state.Wallets, wallet)
+	}
+	sort.Slice(state.Users, func(i, j int) bool { return state.Users[i].ID < state.Users[j].ID })
+	sort.Slice(state.Wallets, func(i, j int) bool { return state.Wallets[i].UserID < state.Wallets[j].UserID })
+	return state
+}
@@ -0,0 +1,133 @@
+// internal/wallet/store_test.go
+package wallet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// populateStore runs a few operations against a service writing through to store
+func populateStore(t *testing.T, store Store) {
+	t.Helper()
+	ws := NewWalletService(WithStore(store))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.Transfer("alice", "bob", 30, "rent")
+	ws.Withdraw("bob", 5, "cash")
+	ws.DepositCurrency("alice", "EUR", decimal.NewFromInt(7), "invoice")
+	ws.SetAutoSettle("bob", true)
+	ws.UpdateUserEmail("bob", "robert@example.com")
+	if report := ws.CheckHealth(); report.Status != HealthOK {
+		t.Fatalf("CheckHealth() = %+v", report)
+	}
+}
+
+// checkReopened verifies a service opened from a populated store
+func checkReopened(t *testing.T, store Store) {
+	t.Helper()
+	ws, err := OpenWalletService(store)
+	if err != nil {
+		t.Fatalf("OpenWalletService() error = %v", err)
+	}
+
+	balances := []struct {
+		userID, currency, want string
+	}{
+		{"alice", DefaultCurrency, "70"},
+		{"alice", "EUR", "7"},
+		{"bob", DefaultCurrency, "25"},
+	}
+	for _, b := range balances {
+		if got, _ := ws.GetCurrencyBalance(b.userID, b.currency); got.String() != b.want {
+			t.Errorf("%s %s balance = %s, want %s", b.userID, b.currency, got, b.want)
+		}
+	}
+	if history, _ := ws.GetTransactionHistory("alice"); len(history) != 3 {
+		t.Errorf("alice history = %d entries, want 3", len(history))
+	}
+	if user, err := ws.GetUserByEmail("robert@example.com"); err != nil || user.ID != "bob" {
+		t.Errorf("GetUserByEmail() = %+v, %v", user, err)
+	}
+	if on, _ := ws.GetAutoSettle("bob"); !on {
+		t.Error("bob auto-settle lost across restart")
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v", deviations)
+	}
+
+	// The reopened service keeps writing through
+	if err := ws.Transfer("bob", "alice", 5, "back"); err != nil {
+		t.Fatalf("Transfer() after reopen error = %v", err)
+	}
+	state, _ := store.Load()
+	if len(state.Transactions) != 5 {
+		t.Errorf("stored transactions = %d, want 5", len(state.Transactions))
+	}
+}
+
+func TestMemoryStore_SurvivesRestart(t *testing.T) {
+	store := NewMemoryStore()
+	populateStore(t, store)
+	checkReopened(t, store)
+}
+
+func TestFileStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.jsonl")
+	store, err := NewFileStore(path, true)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	populateStore(t, store)
+	store.Close()
+
+	reopened, err := NewFileStore(path, false)
+	if err != nil {
+		t.Fatalf("NewFileStore() reopen error = %v", err)
+	}
+	defer reopened.Close()
+
+	before, _ := os.Stat(path)
+	if err := reopened.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Errorf("Compact() size = %d, want less than %d", after.Size(), before.Size())
+	}
+	checkReopened(t, reopened)
+
+	if err := reopened.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := reopened.SaveUser(User{ID: "carol"}); err != ErrStoreClosed {
+		t.Errorf("SaveUser() after Close error = %v, want %v", err, ErrStoreClosed)
+	}
+}
+
+// failingStore rejects every write
+type failingStore struct{ *MemoryStore }
+
+func (failingStore) AppendTransaction(Transaction) error { return errors.New("disk full") }
+
+func TestStore_WriteFailuresAreReported(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	ws := NewWalletService(WithStore(failingStore{NewMemoryStore()}), WithMetrics(metrics))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+
+	// The operation itself succeeds; the failed write shows up in health and metrics
+	if err := ws.Deposit("alice", 10, "seed"); err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	report := ws.CheckHealth()
+	if report.Status != HealthFailing {
+		t.Errorf("CheckHealth() status = %s, want %s", report.Status, HealthFailing)
+	}
+	if got := metrics.Counter("store_write_failures_total", nil); got != 1 {
+		t.Errorf("store_write_failures_total = %d, want 1", got)
+	}
+}
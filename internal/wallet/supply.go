@@ -0,0 +1,265 @@
+// internal/wallet/supply.go
+package wallet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// supplyLedger tracks money in the system per currency, guarded by ws.mu. Supply moves
+// only with external flows (deposits, withdrawals, federation legs, conversions and
+// treasury mints and burns); inTransit is the part of it parked outside any wallet,
+// such as escrowed gifts and compliance holds. Every change to supply is posted with
+// the opposite sign to the system account on the other side of the flow, so supply
+// plus the system accounts' balances is always zero.
+type supplyLedger struct {
+	supply    map[string]decimal.Decimal
+	inTransit map[string]decimal.Decimal
+	system    map[string]map[string]decimal.Decimal // system account -> currency -> balance
+}
+
+// supplyTypes change the total supply: +1 brings money in, -1 takes it out
+var supplyTypes = map[TransactionType]int{
+	TransactionDeposit:           1,
+	TransactionFederationIn:      1,
+	TransactionFederationRefund:  1,
+	TransactionAdjustmentCredit:  1,
+	TransactionRailReversal:      1,
+	TransactionWithdraw:          -1,
+	TransactionFederationOut:     -1,
+	TransactionAdjustmentDebit:   -1,
+	TransactionCardCapture:       -1,
+	TransactionCardRefund:        1,
+	TransactionInterest:          1,
+	TransactionHoldCapture:       -1,
+	TransactionLoyaltyRedemption: 1,
+	TransactionMint:              1,
+	TransactionBurn:              -1,
+	TransactionOverdraft:         -1,
+}
+
+// transitTypes move money between wallets and in-transit escrow: +1 parks, -1 returns it
+var transitTypes = map[TransactionType]int{
+	TransactionGiftEscrow:     1,
+	TransactionComplianceHold: 1,
+	TransactionGiftClaim:      -1,
+	TransactionGiftRefund:     -1,
+	TransactionHoldRelease:    -1,
+	TransactionHoldReversal:   -1,
+	TransactionCardHold:       1,
+	TransactionCardCapture:    -1,
+	TransactionCardRelease:    -1,
+	TransactionReserveHold:    1,
+	TransactionReserveRelease: -1,
+}
+
+// SupplyDeviation describes a currency whose tracked supply disagrees with the money
+// actually held in wallets and in transit
+type SupplyDeviation struct {
+	Currency   string
+	Supply     decimal.Decimal // incrementally tracked total supply
+	Balances   decimal.Decimal // sum of all wallet balances
+	InTransit  decimal.Decimal // escrowed gifts and held transfers
+	DetectedAt int64
+}
+
+// Difference returns supply minus the money accounted for
+func (d SupplyDeviation) Difference() decimal.Decimal {
+	return d.Supply.Sub(d.Balances).Sub(d.InTransit)
+}
+
+// apply folds tx into the supply figures. Callers must hold ws.mu for writing.
+func (l *supplyLedger) apply(tx *Transaction) {
+	if l.supply == nil {
+		l.supply = make(map[string]decimal.Decimal)
+		l.inTransit = make(map[string]decimal.Decimal)
+	}
+	if !tx.settled() {
+		return
+	}
+
+	currency := tx.currencyOf()
+	if tx.Type == TransactionConversion {
+		l.issue(currency, tx.Amount.Neg(), SystemFX)
+		l.issue(tx.ToCurrency, tx.ToAmount, SystemFX)
+		return
+	}
+	if sign, ok := supplyTypes[tx.Type]; ok {
+		l.issue(currency, tx.Amount.Mul(decimal.NewFromInt(int64(sign))), systemCounterparty(tx.Type))
+	} else if def, ok := LookupTransactionType(tx.Type); ok {
+		switch def.Kind {
+		case KindCredit:
+			l.issue(currency, tx.Amount, SystemExternal)
+		case KindDebit:
+			l.issue(currency, tx.Amount.Neg(), SystemExternal)
+		}
+	}
+	if sign, ok := transitTypes[tx.Type]; ok {
+		l.inTransit[currency] = l.inTransit[currency].Add(tx.Amount.Mul(decimal.NewFromInt(int64(sign))))
+	}
+}
+
+// issue adds amount to the supply of currency against the system account on the other
+// side
+func (l *supplyLedger) issue(currency string, amount decimal.Decimal, account string) {
+	if l.system == nil {
+		l.system = make(map[string]map[string]decimal.Decimal)
+	}
+	l.supply[currency] = l.supply[currency].Add(amount)
+	l.post(account, currency, amount.Neg())
+}
+
+// copyAmounts returns a copy of m without zero entries
+func copyAmounts(m map[string]decimal.Decimal) map[string]decimal.Decimal {
+	copied := make(map[string]decimal.Decimal, len(m))
+	for currency, amount := range m {
+		if !amount.IsZero() {
+			copied[currency] = amount
+		}
+	}
+	return copied
+}
+
+// GetTotalSupply returns the money in the system per currency, including funds parked
+// in escrow or on compliance hold. It is maintained with every logged transaction, so
+// reading it does not scan wallets.
+func (ws *WalletService) GetTotalSupply() map[string]decimal.Decimal {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return copyAmounts(ws.supply.supply)
+}
+
+// CheckSupply compares the tracked supply with the sum of wallet balances plus funds in
+// transit, alerting on every currency that deviates. Every user lock is held while
+// summing so no operation is half-applied.
+func (ws *WalletService) CheckSupply() []SupplyDeviation {
+	deviations := ws.supplyDeviations()
+
+	for _, d := range deviations {
+		ws.metrics.IncCounter("supply_deviations_total", map[string]string{"currency": d.Currency})
+		ws.notifier.Notify(Notification{
+			Type:    "supply_deviation",
+			Subject: "Total supply does not match balances",
+			Message: fmt.Sprintf("%s: supply %s, balances %s, in transit %s",
+				d.Currency, d.Supply.String(), d.Balances.String(), d.InTransit.String()),
+			Data: map[string]string{
+				"currency":   d.Currency,
+				"supply":     d.Supply.String(),
+				"balances":   d.Balances.String(),
+				"in_transit": d.InTransit.String(),
+				"difference": d.Difference().String(),
+			},
+			Timestamp: d.DetectedAt,
+		})
+	}
+
+	return deviations
+}
+
+// supplyDeviations sums balances under every user lock and returns the currencies
+// whose tracked supply disagrees, sorted by currency
+func (ws *WalletService) supplyDeviations() []SupplyDeviation {
+	ws.mu.RLock()
+	userIDs := make([]string, 0, len(ws.wallets))
+	for id := range ws.wallets {
+		userIDs = append(userIDs, id)
+	}
+	ws.mu.RUnlock()
+
+	unlock := ws.lockUsers(PriorityReporting, userIDs...)
+	defer unlock()
+
+	ws.mu.RLock()
+	balances := make(map[string]decimal.Decimal)
+	for _, wallet := range ws.wallets {
+		wallet.mu.RLock()
+		balances[wallet.Currency] = balances[wallet.Currency].Add(wallet.Balance)
+		for currency, amount := range wallet.Foreign {
+			balances[currency] = balances[currency].Add(amount)
+		}
+		wallet.mu.RUnlock()
+	}
+	supply := copyAmounts(ws.supply.supply)
+	inTransit := copyAmounts(ws.supply.inTransit)
+	ws.mu.RUnlock()
+
+	currencies := make(map[string]bool)
+	for _, m := range []map[string]decimal.Decimal{balances, supply, inTransit} {
+		for currency := range m {
+			currencies[currency] = true
+		}
+	}
+
+	now := ws.now().Unix()
+	var deviations []SupplyDeviation
+	for currency := range currencies {
+		ws.metrics.ObserveValue("total_supply", supply[currency].InexactFloat64(), map[string]string{"currency": currency})
+
+		d := SupplyDeviation{
+			Currency:   currency,
+			Supply:     supply[currency],
+			Balances:   balances[currency],
+			InTransit:  inTransit[currency],
+			DetectedAt: now,
+		}
+		if !d.Difference().IsZero() {
+			deviations = append(deviations, d)
+		}
+	}
+	sort.Slice(deviations, func(i, j int) bool { return deviations[i].Currency < deviations[j].Currency })
+
+	return deviations
+}
+
+// supplyHealth is the built-in health check reporting supply deviations
+func (ws *WalletService) supplyHealth() HealthCheckResult {
+	deviations := ws.supplyDeviations()
+	if len(deviations) == 0 {
+		return HealthCheckResult{Status: HealthOK}
+	}
+
+	data := make(map[string]string, len(deviations))
+	for _, d := range deviations {
+		data[d.Currency] = d.Difference().String()
+	}
+	return HealthCheckResult{
+		Status: HealthFailing,
+		Detail: fmt.Sprintf("%d currencies deviate from balances", len(deviations)),
+		Data:   data,
+	}
+}
+
+// restoreSupply seeds the supply figures of a restored service. Snapshots written before
+// supply tracking carry none, so the figures are derived from the restored balances and
+// the in-transit movements in the snapshotted log.
+func (ws *WalletService) restoreSupply(snap *Snapshot) {
+	ws.supply = supplyLedger{supply: copyAmounts(snap.Supply), inTransit: copyAmounts(snap.InTransit), system: copySystemBalances(snap.System)}
+	if snap.Supply == nil {
+		ws.deriveSupply(snap)
+	}
+	if snap.System == nil {
+		ws.supply.deriveSystemBalances(snap.Transactions)
+	}
+}
+
+// deriveSupply computes the supply figures of a snapshot written before supply tracking
+func (ws *WalletService) deriveSupply(snap *Snapshot) {
+	for i := range snap.Transactions {
+		tx := &snap.Transactions[i]
+		if sign, ok := transitTypes[tx.Type]; ok {
+			currency := tx.currencyOf()
+			ws.supply.inTransit[currency] = ws.supply.inTransit[currency].Add(tx.Amount.Mul(decimal.NewFromInt(int64(sign))))
+		}
+	}
+	for currency, amount := range ws.supply.inTransit {
+		ws.supply.supply[currency] = amount
+	}
+	for _, w := range ws.wallets {
+		ws.supply.supply[w.Currency] = ws.supply.supply[w.Currency].Add(w.Balance)
+		for currency, amount := range w.Foreign {
+			ws.supply.supply[currency] = ws.supply.supply[currency].Add(amount)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// internal/wallet/supply_test.go
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTotalSupply_TracksExternalFlows(t *testing.T) {
+	rates := StaticRateProvider{"EUR/USD": decimal.RequireFromString("1.1")}
+	ws := NewWalletService(WithRateProvider(rates))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+
+	ws.Deposit("alice", 100, "salary")
+	ws.Transfer("alice", "bob", 30, "rent")
+	ws.Withdraw("bob", 10, "cash")
+	ws.SetAutoSettle("bob", true)
+	ws.DepositCurrency("bob", "EUR", decimal.NewFromInt(20), "invoice")
+
+	supply := ws.GetTotalSupply()
+	if !supply["USD"].Equal(decimal.NewFromInt(112)) {
+		t.Errorf("USD supply = %s, want 112", supply["USD"])
+	}
+	if _, ok := supply["EUR"]; ok {
+		t.Errorf("EUR supply = %s, want none after auto-settlement", supply["EUR"])
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+}
+
+func TestTotalSupply_CountsFundsInTransit(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	ws.ScheduleGift("alice", "carol@example.com", decimal.NewFromInt(40), "", clock.Now().Add(time.Minute))
+	clock.Advance(time.Minute)
+	ws.RunDueJobs()
+
+	if supply := ws.GetTotalSupply(); !supply["USD"].Equal(decimal.NewFromInt(100)) {
+		t.Errorf("USD supply = %s, want 100 with the gift in escrow", supply["USD"])
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v, want none", deviations)
+	}
+}
+
+func TestCheckSupply_AlertsOnDeviation(t *testing.T) {
+	notifier := &recordingNotifier{}
+	ws, _ := newTestService(WithNotifier(notifier))
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+
+	wallet := ws.wallets["bob"]
+	wallet.mu.Lock()
+	wallet.Balance = wallet.Balance.Add(decimal.NewFromInt(5))
+	wallet.mu.Unlock()
+
+	deviations := ws.CheckSupply()
+	if len(deviations) != 1 || deviations[0].Currency != "USD" || !deviations[0].Difference().Equal(decimal.NewFromInt(-5)) {
+		t.Fatalf("CheckSupply() = %+v, want USD off by -5", deviations)
+	}
+
+	var alerted bool
+	for _, n := range notifier.all() {
+		if n.Type == "supply_deviation" && n.Data["currency"] == "USD" {
+			alerted = true
+		}
+	}
+	if !alerted {
+		t.Error("no supply_deviation notification sent")
+	}
+
+	if report := ws.CheckHealth(); report.Status != HealthFailing {
+		t.Errorf("health = %s, want failing", report.Status)
+	}
+}
+
+func TestTotalSupply_SurvivesRestore(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("alice", "Alice", "alice@example.com")
+	ws.CreateUser("bob", "Bob", "bob@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.ScheduleGift("alice", "carol@example.com", decimal.NewFromInt(40), "", clock.Now().Add(time.Minute))
+	clock.Advance(time.Minute)
+	ws.RunDueJobs()
+
+	var buf bytes.Buffer
+	if err := ws.Backup(&buf); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	restored, err := Restore(&buf)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if supply := restored.GetTotalSupply(); !supply["USD"].Equal(decimal.NewFromInt(100)) {
+		t.Errorf("restored USD supply = %s, want 100", supply["USD"])
+	}
+
+	// Snapshots taken before supply tracking derive it from balances and the log
+	snap := ws.Snapshot()
+	snap.Supply, snap.InTransit = nil, nil
+	legacy, _ := RestoreSnapshot(snap)
+	if supply := legacy.GetTotalSupply(); !supply["USD"].Equal(decimal.NewFromInt(100)) {
+		t.Errorf("legacy USD supply = %s, want 100", supply["USD"])
+	}
+	if deviations := legacy.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("legacy CheckSupply() = %+v, want none", deviations)
+	}
+}
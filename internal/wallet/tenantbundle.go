@@ -0,0 +1,333 @@
+// internal/wallet/tenantbundle.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for moving tenants between deployments
+var (
+	ErrUnsupportedBundle     = errors.New("unsupported tenant bundle version")
+	ErrInvalidBundle         = errors.New("tenant bundle is malformed")
+	ErrTenantNotFound        = errors.New("tenant has no users")
+	ErrInvalidConflictPolicy = errors.New("unknown import conflict policy")
+	ErrImportConflict        = errors.New("tenant bundle user already exists")
+)
+
+// TenantBundleVersion is the format version written by ExportTenant
+const TenantBundleVersion = 1
+
+// metaImportTenant records on an imported opening balance the tenant it came from
+const metaImportTenant = "import_tenant"
+
+// TenantBundle is a portable copy of one tenant's users and balances, and optionally
+// their history, for moving the tenant to another deployment. It encodes to JSON.
+type TenantBundle struct {
+	Version      int
+	Tenant       string
+	ExportedAt   int64
+	Users        []User
+	Wallets      []WalletSnapshot
+	Transactions []Transaction // empty unless history was exported
+}
+
+// ConflictPolicy decides what ImportTenant does with bundle users that already exist
+type ConflictPolicy string
+
+const (
+	ConflictSkip  ConflictPolicy = "skip"  // keep the existing user untouched
+	ConflictMerge ConflictPolicy = "merge" // credit the bundle balances to the existing wallet
+	ConflictFail  ConflictPolicy = "fail"  // import nothing
+)
+
+// TenantImport reports what ImportTenant did with each bundle user
+type TenantImport struct {
+	Tenant       string
+	Created      []string
+	Merged       []string
+	Skipped      []string
+	Transactions []string // opening-balance adjustments posted
+}
+
+// tenantBook holds the tenant resolver and the history carried in by imports
+type tenantBook struct {
+	tenantOf func(userID string) string
+
+	mu       sync.RWMutex
+	imported map[string][]Transaction
+}
+
+// WithTenants names the tenant each user belongs to, for ExportTenant. Without it every
+// user belongs to the "" tenant.
+func WithTenants(tenantOf func(userID string) string) Option {
+	return func(ws *WalletService) {
+		ws.tenants.tenantOf = tenantOf
+	}
+}
+
+// ExportTenant copies the users of tenantID with their wallets and balances into a
+// bundle ImportTenant can load on another deployment. With includeHistory the bundle
+// also carries every transaction touching those users, oldest first. Every tenant
+// user's lock is held while copying so balances and history agree.
+func (ws *WalletService) ExportTenant(tenantID string, includeHistory bool) (*TenantBundle, error) {
+	ws.mu.RLock()
+	all := slices.Collect(maps.Keys(ws.users))
+	ws.mu.RUnlock()
+
+	var userIDs []string
+	for _, id := range all {
+		if ws.tenantOf(id) == tenantID {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, ErrTenantNotFound
+	}
+	sort.Strings(userIDs)
+
+	unlock := ws.lockUsers(PriorityReporting, userIDs...)
+	defer unlock()
+
+	bundle := &TenantBundle{
+		Version:    TenantBundleVersion,
+		Tenant:     tenantID,
+		ExportedAt: ws.now().Unix(),
+	}
+	ws.mu.RLock()
+	for _, id := range userIDs {
+		user, wallet := ws.users[id], ws.wallets[id]
+		if user == nil || wallet == nil {
+			continue
+		}
+		bundle.Users = append(bundle.Users, *user)
+		wallet.mu.RLock()
+		bundle.Wallets = append(bundle.Wallets, wallet.snapshot())
+		wallet.mu.RUnlock()
+	}
+	ws.mu.RUnlock()
+
+	if includeHistory {
+		history, err := ws.tenantHistory(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Transactions = history
+	}
+	return bundle, nil
+}
+
+// ImportTenant loads a bundle written by ExportTenant. New users are created with
+// wallets in their bundle base currency; their balances are posted as migration
+// adjustments so the ledger explains them. Users that already exist are handled by
+// policy, which is checked for the whole bundle before anything is applied. Bundle
+// history is kept read-only beside the log, see ImportedHistory.
+func (ws *WalletService) ImportTenant(bundle *TenantBundle, policy ConflictPolicy) (*TenantImport, error) {
+	if bundle.Version != TenantBundleVersion {
+		return nil, ErrUnsupportedBundle
+	}
+	switch policy {
+	case ConflictSkip, ConflictMerge, ConflictFail:
+	default:
+		return nil, ErrInvalidConflictPolicy
+	}
+	wallets, err := ws.checkBundle(bundle, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TenantImport{Tenant: bundle.Tenant}
+	for _, user := range bundle.Users {
+		w := wallets[user.ID]
+		switch {
+		case !ws.walletExists(user.ID):
+			if err := ws.addUserWithCurrency(user.ID, user.Name, user.Email, w.Currency); err != nil {
+				return result, fmt.Errorf("import %s: %w", user.ID, err)
+			}
+			result.Created = append(result.Created, user.ID)
+		case policy == ConflictMerge:
+			result.Merged = append(result.Merged, user.ID)
+		default:
+			result.Skipped = append(result.Skipped, user.ID)
+			continue
+		}
+
+		ids, err := ws.creditOpeningBalances(bundle.Tenant, w)
+		result.Transactions = append(result.Transactions, ids...)
+		if err != nil {
+			return result, fmt.Errorf("import %s: %w", user.ID, err)
+		}
+		if w.AutoSettle {
+			ws.SetAutoSettle(user.ID, true)
+		}
+	}
+	ws.keepImportedHistory(bundle.Transactions, append(result.Created, result.Merged...))
+
+	ws.metrics.IncCounter("tenant_imports_total", map[string]string{"policy": string(policy)})
+	return result, nil
+}
+
+// ImportedHistory returns the transactions of userID carried in by ImportTenant from
+// other deployments, oldest first. They are not part of the transaction log.
+func (ws *WalletService) ImportedHistory(userID string) ([]Transaction, error) {
+	if !ws.walletExists(userID) {
+		return nil, ErrUserNotFound
+	}
+	ws.tenants.mu.RLock()
+	defer ws.tenants.mu.RUnlock()
+	return slices.Clone(ws.tenants.imported[userID]), nil
+}
+
+// tenantOf returns the tenant userID belongs to
+func (ws *WalletService) tenantOf(userID string) string {
+	if ws.tenants.tenantOf == nil {
+		return ""
+	}
+	return ws.tenants.tenantOf(userID)
+}
+
+// tenantHistory returns every transaction touching userIDs, history they imported
+// earlier first, then the log oldest first
+func (ws *WalletService) tenantHistory(userIDs []string) ([]Transaction, error) {
+	seen := make(map[string]bool)
+	var imported, logged []Transaction
+
+	ws.tenants.mu.RLock()
+	for _, id := range userIDs {
+		for _, tx := range ws.tenants.imported[id] {
+			if !seen[tx.ID] {
+				seen[tx.ID] = true
+				imported = append(imported, tx)
+			}
+		}
+	}
+	ws.tenants.mu.RUnlock()
+
+	for _, id := range userIDs {
+		it := ws.iterate(id, IterateOptions{})
+		for it.Next() {
+			if tx := it.Transaction(); !seen[tx.ID] {
+				seen[tx.ID] = true
+				logged = append(logged, *tx)
+			}
+		}
+		err := it.Err()
+		it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(imported, func(i, j int) bool { return imported[i].Timestamp < imported[j].Timestamp })
+	sort.SliceStable(logged, func(i, j int) bool { return logged[i].Timestamp < logged[j].Timestamp })
+	return append(imported, logged...), nil
+}
+
+// checkBundle validates bundle against this deployment under policy and returns its
+// wallets by user
+func (ws *WalletService) checkBundle(bundle *TenantBundle, policy ConflictPolicy) (map[string]WalletSnapshot, error) {
+	wallets := make(map[string]WalletSnapshot, len(bundle.Wallets))
+	for _, w := range bundle.Wallets {
+		if _, dup := wallets[w.UserID]; dup || w.Balance.IsNegative() {
+			return nil, ErrInvalidBundle
+		}
+		if _, err := ws.GetCurrency(w.Currency); err != nil {
+			return nil, err
+		}
+		for c, amount := range w.Foreign {
+			if amount.IsNegative() {
+				return nil, ErrInvalidBundle
+			}
+			if _, err := ws.GetCurrency(c); err != nil {
+				return nil, err
+			}
+		}
+		wallets[w.UserID] = w
+	}
+
+	seen := make(map[string]bool, len(bundle.Users))
+	for _, user := range bundle.Users {
+		w, ok := wallets[user.ID]
+		if !ok || seen[user.ID] {
+			return nil, ErrInvalidBundle
+		}
+		seen[user.ID] = true
+		if isSystemAccount(user.ID) {
+			return nil, ErrReservedUserID
+		}
+
+		ws.mu.RLock()
+		existing := ws.wallets[user.ID]
+		ws.mu.RUnlock()
+		switch {
+		case existing == nil:
+		case policy == ConflictFail:
+			return nil, fmt.Errorf("%w: %s", ErrImportConflict, user.ID)
+		case policy == ConflictMerge && existing.Currency != w.Currency:
+			return nil, fmt.Errorf("%w: %s holds %s here", ErrCurrencyMismatch, user.ID, existing.Currency)
+		}
+	}
+	if len(wallets) != len(seen) {
+		return nil, ErrInvalidBundle
+	}
+	return wallets, nil
+}
+
+// creditOpeningBalances posts w's holdings to its user as migration adjustments and
+// returns the IDs of the transactions posted
+func (ws *WalletService) creditOpeningBalances(tenant string, w WalletSnapshot) ([]string, error) {
+	currencies := slices.Sorted(maps.Keys(w.Foreign))
+	amounts := map[string]decimal.Decimal{w.Currency: w.Balance}
+	for _, c := range currencies {
+		amounts[c] = w.Foreign[c]
+	}
+
+	var ids []string
+	for _, c := range append([]string{w.Currency}, currencies...) {
+		if !amounts[c].IsPositive() {
+			continue
+		}
+		tx := &Transaction{
+			ID:          ws.newID("tx"),
+			Type:        TransactionAdjustmentCredit,
+			ToUserID:    w.UserID,
+			Amount:      amounts[c],
+			Currency:    c,
+			Description: "opening balance imported from another deployment",
+			Metadata: map[string]string{
+				"reason_code":    string(ReasonMigration),
+				metaImportTenant: tenant,
+			},
+		}
+		if err := ws.postCredit(context.Background(), tx); err != nil {
+			return ids, err
+		}
+		ids = append(ids, tx.ID)
+	}
+	return ids, nil
+}
+
+// keepImportedHistory files the bundle transactions touching userIDs under each of them
+func (ws *WalletService) keepImportedHistory(history []Transaction, userIDs []string) {
+	if len(history) == 0 {
+		return
+	}
+	ws.tenants.mu.Lock()
+	defer ws.tenants.mu.Unlock()
+	if ws.tenants.imported == nil {
+		ws.tenants.imported = make(map[string][]Transaction)
+	}
+	for _, id := range userIDs {
+		for _, tx := range history {
+			if tx.FromUserID == id || tx.ToUserID == id {
+				ws.tenants.imported[id] = append(ws.tenants.imported[id], tx)
+			}
+		}
+	}
+}
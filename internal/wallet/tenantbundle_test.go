@@ -0,0 +1,172 @@
+// internal/wallet/tenantbundle_test.go
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// acmeTenant puts users with an "acme-" prefix in the acme tenant
+func acmeTenant(userID string) string {
+	if strings.HasPrefix(userID, "acme-") {
+		return "acme"
+	}
+	return ""
+}
+
+func TestExportImportTenant_RoundTrip(t *testing.T) {
+	src := NewWalletService(WithTenants(acmeTenant))
+	src.CreateUser("acme-alice", "Alice", "alice@acme.example")
+	src.CreateUser("acme-bob", "Bob", "bob@acme.example")
+	src.CreateUser("carol", "Carol", "carol@example.com")
+	src.Deposit("acme-alice", 100, "seed")
+	src.Deposit("carol", 5, "seed")
+	src.Transfer("acme-alice", "acme-bob", 30, "rent")
+	src.DepositCurrency("acme-bob", "EUR", decimal.NewFromInt(12), "trip")
+	src.SetAutoSettle("acme-bob", true)
+
+	bundle, err := src.ExportTenant("acme", true)
+	if err != nil {
+		t.Fatalf("ExportTenant() error = %v", err)
+	}
+	if len(bundle.Users) != 2 || len(bundle.Wallets) != 2 || len(bundle.Transactions) != 3 {
+		t.Fatalf("bundle = %d users, %d wallets, %d transactions, want 2, 2, 3", len(bundle.Users), len(bundle.Wallets), len(bundle.Transactions))
+	}
+	if _, err := src.ExportTenant("globex", false); err != ErrTenantNotFound {
+		t.Errorf("ExportTenant(globex) error = %v, want %v", err, ErrTenantNotFound)
+	}
+
+	// The bundle travels between deployments as JSON
+	encoded, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var carried TenantBundle
+	if err := json.Unmarshal(encoded, &carried); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	dst := NewWalletService()
+	result, err := dst.ImportTenant(&carried, ConflictFail)
+	if err != nil {
+		t.Fatalf("ImportTenant() error = %v", err)
+	}
+	if len(result.Created) != 2 || len(result.Transactions) != 3 {
+		t.Errorf("ImportTenant() = %+v, want two users and three opening balances", result)
+	}
+	if b, _ := dst.GetBalanceDecimal("acme-alice"); !b.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("acme-alice's balance = %s, want 70", b)
+	}
+	if b, _ := dst.GetCurrencyBalance("acme-bob", "EUR"); !b.Equal(decimal.NewFromInt(12)) {
+		t.Errorf("acme-bob's EUR balance = %s, want 12", b)
+	}
+	if on, _ := dst.GetAutoSettle("acme-bob"); !on {
+		t.Error("acme-bob lost auto-settle")
+	}
+	if dst.walletExists("carol") {
+		t.Error("user outside the tenant was imported")
+	}
+	for _, id := range result.Created {
+		if m, err := dst.CheckWalletIntegrity(id); m != nil || err != nil {
+			t.Errorf("CheckWalletIntegrity(%s) = %+v, %v", id, m, err)
+		}
+	}
+	if d := dst.CheckSupply(); len(d) != 0 {
+		t.Errorf("CheckSupply() = %+v", d)
+	}
+
+	history, _ := dst.ImportedHistory("acme-bob")
+	if len(history) != 2 || history[0].Description != "rent" {
+		t.Errorf("ImportedHistory(acme-bob) = %+v, want rent and trip", history)
+	}
+	live, _ := dst.GetTransactionHistory("acme-bob")
+	if len(live) != 2 || live[0].Metadata["reason_code"] != string(ReasonMigration) || live[0].Metadata[metaImportTenant] != "acme" {
+		t.Errorf("acme-bob's log = %+v, want two migration credits", live)
+	}
+
+	// Moving the tenant on again carries the earlier history with it
+	again, _ := dst.ExportTenant("", true)
+	if len(again.Transactions) != 3+3 || again.Transactions[0].Description != "seed" {
+		t.Errorf("re-export carries %d transactions, want 6 starting with the original seed", len(again.Transactions))
+	}
+}
+
+func TestImportTenant_ConflictPolicies(t *testing.T) {
+	src := NewWalletService()
+	src.CreateUser("alice", "Alice", "alice@example.com")
+	src.CreateUser("bob", "Bob", "bob@example.com")
+	src.Deposit("alice", 40, "seed")
+	src.Deposit("bob", 15, "seed")
+	bundle, _ := src.ExportTenant("", false)
+
+	tests := []struct {
+		name        string
+		policy      ConflictPolicy
+		wantErr     error
+		wantCreated int
+		wantAlice   int64
+		wantBob     int64
+	}{
+		{"skip", ConflictSkip, nil, 1, 10, 15},
+		{"merge", ConflictMerge, nil, 1, 50, 15},
+		{"fail", ConflictFail, ErrImportConflict, 0, 10, 0},
+		{"unknown policy", "overwrite", ErrInvalidConflictPolicy, 0, 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := NewWalletService()
+			dst.CreateUser("alice", "Alice", "alice@example.com")
+			dst.Deposit("alice", 10, "seed")
+
+			result, err := dst.ImportTenant(bundle, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportTenant() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(result.Created) != tt.wantCreated {
+				t.Errorf("created = %v, want %d users", result.Created, tt.wantCreated)
+			}
+			if b, _ := dst.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(tt.wantAlice)) {
+				t.Errorf("alice's balance = %s, want %d", b, tt.wantAlice)
+			}
+			if b, _ := dst.GetBalanceDecimal("bob"); !b.Equal(decimal.NewFromInt(tt.wantBob)) {
+				t.Errorf("bob's balance = %s, want %d", b, tt.wantBob)
+			}
+		})
+	}
+}
+
+func TestImportTenant_RejectsBadBundles(t *testing.T) {
+	wallet := func(id, currency string, balance int64) WalletSnapshot {
+		return WalletSnapshot{UserID: id, Currency: currency, Balance: decimal.NewFromInt(balance)}
+	}
+	user := User{ID: "alice", Name: "Alice", Email: "alice@example.com"}
+
+	tests := []struct {
+		name    string
+		bundle  TenantBundle
+		wantErr error
+	}{
+		{"future version", TenantBundle{Version: TenantBundleVersion + 1}, ErrUnsupportedBundle},
+		{"user without wallet", TenantBundle{Version: TenantBundleVersion, Users: []User{user}}, ErrInvalidBundle},
+		{"wallet without user", TenantBundle{Version: TenantBundleVersion, Wallets: []WalletSnapshot{wallet("alice", "USD", 1)}}, ErrInvalidBundle},
+		{"negative balance", TenantBundle{Version: TenantBundleVersion, Users: []User{user}, Wallets: []WalletSnapshot{wallet("alice", "USD", -1)}}, ErrInvalidBundle},
+		{"unknown currency", TenantBundle{Version: TenantBundleVersion, Users: []User{user}, Wallets: []WalletSnapshot{wallet("alice", "XTS", 1)}}, ErrInvalidCurrency},
+		{"merge into another currency", TenantBundle{Version: TenantBundleVersion, Users: []User{user}, Wallets: []WalletSnapshot{wallet("alice", "EUR", 1)}}, ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := NewWalletService()
+			dst.CreateUser("alice", "Alice", "alice@example.com")
+			if _, err := dst.ImportTenant(&tt.bundle, ConflictMerge); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ImportTenant() error = %v, want %v", err, tt.wantErr)
+			}
+			if b, _ := dst.GetBalanceDecimal("alice"); !b.IsZero() {
+				t.Errorf("alice's balance = %s after a rejected import", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// internal/wallet/trace.go
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+)
+
+// The XContext variants of the service methods honour cancellation up to the point funds
+// move: a ctx that is done before the operation starts stops it with ctx.Err(), but once
+// balances change the operation completes, so a caller going away never leaves half a
+// transfer. They also record the trace ID carried by ctx on the transactions they create.
+
+// metaTraceID records the trace ID of the request that created a transaction
+const metaTraceID = "trace_id"
+
+// traceKey is the context key of a trace ID
+type traceKey struct{}
+
+// ContextWithTraceID returns a copy of ctx carrying traceID. The Context variants of
+// the service methods record it on the transactions they create, so entries can be
+// tied back to the request that caused them.
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID carried by ctx, if any
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceKey{}).(string)
+	return id
+}
+
+// NewTraceID returns a random trace ID for a request that did not bring its own
+func NewTraceID() string {
+	return rand.Text()
+}
+
+// stampTrace records ctx's trace ID, if any, in tx's metadata. The metadata is copied
+// first so a map passed in by the caller is left alone.
+func stampTrace(ctx context.Context, tx *Transaction) {
+	id := TraceIDFromContext(ctx)
+	if id == "" {
+		return
+	}
+	metadata := copyMetadata(tx.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string, 1)
+	}
+	metadata[metaTraceID] = id
+	tx.Metadata = metadata
+}
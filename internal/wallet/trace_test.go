@@ -0,0 +1,80 @@
+// internal/wallet/trace_test.go
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestContextVariants(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.Deposit("alice", 100, "seed")
+	ws.SetStaffRole("ops", StaffAdmin)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	traced := ContextWithTraceID(context.Background(), "req-1")
+	ten := decimal.NewFromInt(10)
+
+	tests := []struct {
+		name      string
+		run       func(ctx context.Context) error
+		wantAlice int64
+	}{
+		{"create user", func(ctx context.Context) error { return ws.CreateUserContext(ctx, "carol", "Carol", "c@example.com") }, 100},
+		{"deposit", func(ctx context.Context) error { return ws.DepositContext(ctx, "alice", ten, "") }, 110},
+		{"withdraw", func(ctx context.Context) error { return ws.WithdrawContext(ctx, "alice", ten, "") }, 100},
+		{"transfer", func(ctx context.Context) error { return ws.TransferContext(ctx, "alice", "bob", ten, "") }, 90},
+		{"balance", func(ctx context.Context) error { _, err := ws.GetBalanceContext(ctx, "alice"); return err }, 90},
+		{"history", func(ctx context.Context) error { _, err := ws.GetTransactionHistoryContext(ctx, "alice"); return err }, 90},
+		{"mint", func(ctx context.Context) error { _, err := ws.MintContext(ctx, "alice", ten); return err }, 100},
+		{"burn", func(ctx context.Context) error { _, err := ws.BurnContext(ctx, "alice", ten); return err }, 90},
+		{"batch payout", func(ctx context.Context) error {
+			_, err := ws.BatchPayoutContext(ctx, "alice", []Payout{{UserID: "bob", Amount: ten}}, "")
+			return err
+		}, 80},
+		{"hold and capture", func(ctx context.Context) error {
+			h, err := ws.HoldContext(ctx, "alice", ten)
+			if err != nil {
+				return err
+			}
+			_, err = ws.CaptureHoldContext(ctx, h.ID, ten)
+			return err
+		}, 70},
+		{"admin adjust", func(ctx context.Context) error {
+			_, err := ws.AdminAdjustContext(ctx, "alice", ten, ReasonGoodwill, "ops")
+			return err
+		}, 80},
+		{"set balance", func(ctx context.Context) error {
+			_, err := ws.SetBalanceContext(ctx, "alice", decimal.NewFromInt(75), ReasonReconciliation)
+			return err
+		}, 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(cancelled); err != context.Canceled {
+				t.Fatalf("cancelled error = %v, want %v", err, context.Canceled)
+			}
+			if err := tt.run(traced); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if balance, _ := ws.GetBalanceDecimal("alice"); !balance.Equal(decimal.NewFromInt(tt.wantAlice)) {
+				t.Errorf("balance = %s, want %d", balance, tt.wantAlice)
+			}
+		})
+	}
+
+	history, _ := ws.GetTransactionHistory("alice")
+	for _, tx := range history[1:] {
+		if tx.Metadata[metaTraceID] != "req-1" {
+			t.Errorf("%s metadata = %v, want trace ID req-1", tx.Type, tx.Metadata)
+		}
+	}
+	if history[0].Metadata[metaTraceID] != "" {
+		t.Errorf("untraced deposit metadata = %v", history[0].Metadata)
+	}
+}
@@ -0,0 +1,167 @@
+// internal/wallet/treasury.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrReservedUserID is returned for user IDs in the system account namespace
+var ErrReservedUserID = errors.New("user IDs starting with system: are reserved")
+
+// System accounts are the counterparties of money entering and leaving the wallets.
+// They are not wallets: their balances go negative by what they have put into
+// circulation, so wallets, funds in transit and system accounts always sum to zero.
+const (
+	SystemTreasury = "system:treasury" // issues money with Mint and retires it with Burn
+	SystemExternal = "system:external" // deposits, withdrawals and other flows with the outside
+	SystemFX       = "system:fx"       // the other side of currency conversions
+
+	systemAccountPrefix = "system:"
+)
+
+// SystemAccount is a system account's balance per currency
+type SystemAccount struct {
+	ID       string
+	Balances map[string]decimal.Decimal
+}
+
+// Mint issues amount of new money into toUserID's wallet in its base currency, against
+// the treasury
+func (ws *WalletService) Mint(toUserID string, amount decimal.Decimal) (*Transaction, error) {
+	return ws.MintContext(context.Background(), toUserID, amount)
+}
+
+// MintContext is Mint on behalf of a caller's ctx
+func (ws *WalletService) MintContext(ctx context.Context, toUserID string, amount decimal.Decimal) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	tx := &Transaction{
+		FromUserID:  SystemTreasury,
+		ToUserID:    toUserID,
+		Amount:      amount,
+		Type:        TransactionMint,
+		Description: "mint",
+	}
+	if err := ws.postCredit(ctx, tx); err != nil {
+		return nil, err
+	}
+	ws.metrics.IncCounter("treasury_operations_total", map[string]string{"type": string(TransactionMint)})
+	return tx, nil
+}
+
+// Burn retires amount from fromUserID's wallet in its base currency back to the
+// treasury. Minimum balances do not apply.
+func (ws *WalletService) Burn(fromUserID string, amount decimal.Decimal) (*Transaction, error) {
+	return ws.BurnContext(context.Background(), fromUserID, amount)
+}
+
+// BurnContext is Burn on behalf of a caller's ctx
+func (ws *WalletService) BurnContext(ctx context.Context, fromUserID string, amount decimal.Decimal) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	tx := &Transaction{
+		FromUserID:  fromUserID,
+		ToUserID:    SystemTreasury,
+		Amount:      amount,
+		Type:        TransactionBurn,
+		Description: "burn",
+	}
+	if err := ws.postDebit(ctx, tx); err != nil {
+		return nil, err
+	}
+	ws.metrics.IncCounter("treasury_operations_total", map[string]string{"type": string(TransactionBurn)})
+	return tx, nil
+}
+
+// GetSystemAccounts returns every system account that has taken part in a flow, by ID
+func (ws *WalletService) GetSystemAccounts() []SystemAccount {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	accounts := make([]SystemAccount, 0, len(ws.supply.system))
+	for id, balances := range ws.supply.system {
+		accounts = append(accounts, SystemAccount{ID: id, Balances: copyAmounts(balances)})
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+	return accounts
+}
+
+// systemCounterparty returns the system account on the other side of a flow of type t
+func systemCounterparty(t TransactionType) string {
+	switch t {
+	case TransactionMint, TransactionBurn:
+		return SystemTreasury
+	case TransactionConversion:
+		return SystemFX
+	}
+	return SystemExternal
+}
+
+// isSystemAccount reports whether id is in the system account namespace
+func isSystemAccount(id string) bool {
+	return strings.HasPrefix(id, systemAccountPrefix)
+}
+
+// deriveSystemBalances computes the system accounts of a snapshot written before they
+// were tracked: the treasury and FX desk from the flows in the snapshotted log, and
+// the outside world as whatever else the supply came from. Caller must have set the
+// supply figures.
+func (l *supplyLedger) deriveSystemBalances(txs []Transaction) {
+	l.system = make(map[string]map[string]decimal.Decimal)
+	sum := make(map[string]decimal.Decimal)
+	for i := range txs {
+		tx := &txs[i]
+		if !tx.settled() {
+			continue
+		}
+		switch tx.Type {
+		case TransactionMint, TransactionBurn:
+			amount := tx.Amount.Mul(decimal.NewFromInt(int64(supplyTypes[tx.Type])))
+			l.post(SystemTreasury, tx.currencyOf(), amount.Neg())
+			sum[tx.currencyOf()] = sum[tx.currencyOf()].Add(amount)
+		case TransactionConversion:
+			l.post(SystemFX, tx.currencyOf(), tx.Amount)
+			l.post(SystemFX, tx.ToCurrency, tx.ToAmount.Neg())
+			sum[tx.currencyOf()] = sum[tx.currencyOf()].Sub(tx.Amount)
+			sum[tx.ToCurrency] = sum[tx.ToCurrency].Add(tx.ToAmount)
+		}
+	}
+	for currency, supply := range l.supply {
+		if rest := supply.Sub(sum[currency]); !rest.IsZero() {
+			l.post(SystemExternal, currency, rest.Neg())
+		}
+	}
+}
+
+// post adds amount to a system account's balance in currency
+func (l *supplyLedger) post(account, currency string, amount decimal.Decimal) {
+	if l.system[account] == nil {
+		l.system[account] = make(map[string]decimal.Decimal)
+	}
+	l.system[account][currency] = l.system[account][currency].Add(amount)
+}
+
+// copySystemBalances returns a deep copy of system account balances; nil for nil
+func copySystemBalances(m map[string]map[string]decimal.Decimal) map[string]map[string]decimal.Decimal {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]map[string]decimal.Decimal, len(m))
+	for account, balances := range m {
+		copied[account] = copyAmounts(balances)
+	}
+	return copied
+}
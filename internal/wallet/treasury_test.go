@@ -0,0 +1,104 @@
+// internal/wallet/treasury_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// systemBalances returns the USD balance of every system account
+func systemBalances(ws *WalletService) map[string]int64 {
+	balances := make(map[string]int64)
+	for _, a := range ws.GetSystemAccounts() {
+		balances[a.ID] = a.Balances["USD"].IntPart()
+	}
+	return balances
+}
+
+func TestTreasury_MintAndBurn(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.CreateUser("bob", "Bob", "b@example.com")
+	ws.SetMinimumBalance("alice", "USD", decimal.NewFromInt(50))
+
+	tests := []struct {
+		name     string
+		op       func() error
+		wantErr  error
+		alice    int64
+		supply   int64
+		treasury int64
+		external int64
+	}{
+		{"mint", func() error { _, err := ws.Mint("alice", decimal.NewFromInt(100)); return err }, nil, 100, 100, -100, 0},
+		{"deposit", func() error { return ws.Deposit("bob", 30, "cash in") }, nil, 100, 130, -100, -30},
+		{"transfer moves no supply", func() error { return ws.Transfer("alice", "bob", 10, "rent") }, nil, 90, 130, -100, -30},
+		{"burn ignores minimum", func() error { _, err := ws.Burn("alice", decimal.NewFromInt(60)); return err }, nil, 30, 70, -40, -30},
+		{"burn over balance", func() error { _, err := ws.Burn("alice", decimal.NewFromInt(31)); return err }, ErrInsufficientBalance, 30, 70, -40, -30},
+		{"mint zero", func() error { _, err := ws.Mint("alice", decimal.Zero); return err }, ErrInvalidAmount, 30, 70, -40, -30},
+		{"mint to unknown", func() error { _, err := ws.Mint("ghost", decimal.NewFromInt(1)); return err }, ErrUserNotFound, 30, 70, -40, -30},
+		{"withdraw", func() error { return ws.Withdraw("bob", 5, "cash out") }, nil, 30, 65, -40, -25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := ws.GetBalanceDecimal("alice"); !got.Equal(decimal.NewFromInt(tt.alice)) {
+				t.Errorf("alice balance = %s, want %d", got, tt.alice)
+			}
+			if got := ws.GetTotalSupply()["USD"]; !got.Equal(decimal.NewFromInt(tt.supply)) {
+				t.Errorf("supply = %s, want %d", got, tt.supply)
+			}
+			system := systemBalances(ws)
+			if system[SystemTreasury] != tt.treasury || system[SystemExternal] != tt.external {
+				t.Errorf("system accounts = %v, want treasury %d, external %d", system, tt.treasury, tt.external)
+			}
+			if deviations := ws.CheckSupply(); len(deviations) != 0 {
+				t.Errorf("CheckSupply() = %+v, want none", deviations)
+			}
+		})
+	}
+
+	history, _ := ws.GetTransactionHistory("alice")
+	if tx := history[0]; tx.Type != TransactionMint || tx.FromUserID != SystemTreasury {
+		t.Errorf("first transaction = %+v, want a mint from the treasury", tx)
+	}
+}
+
+func TestTreasury_ReservedUserIDs(t *testing.T) {
+	ws := NewWalletService()
+	if err := ws.CreateUser(SystemTreasury, "Treasury", "t@example.com"); err != ErrReservedUserID {
+		t.Errorf("CreateUser(%s) error = %v, want %v", SystemTreasury, err, ErrReservedUserID)
+	}
+	if err := ws.CreateUser("system:other", "Other", "o@example.com"); err != ErrReservedUserID {
+		t.Errorf("CreateUser(system:other) error = %v, want %v", err, ErrReservedUserID)
+	}
+}
+
+func TestTreasury_SurvivesRestore(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	ws.Mint("alice", decimal.NewFromInt(100))
+	ws.Deposit("alice", 20, "cash in")
+	ws.Burn("alice", decimal.NewFromInt(30))
+
+	want := map[string]int64{SystemTreasury: -70, SystemExternal: -20}
+	restored, err := RestoreSnapshot(ws.Snapshot())
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	if got := systemBalances(restored); got[SystemTreasury] != want[SystemTreasury] || got[SystemExternal] != want[SystemExternal] {
+		t.Errorf("restored system accounts = %v, want %v", got, want)
+	}
+
+	// Snapshots taken before system accounts derive them from the log and the supply
+	snap := ws.Snapshot()
+	snap.System = nil
+	legacy, _ := RestoreSnapshot(snap)
+	if got := systemBalances(legacy); got[SystemTreasury] != want[SystemTreasury] || got[SystemExternal] != want[SystemExternal] {
+		t.Errorf("legacy system accounts = %v, want %v", got, want)
+	}
+}
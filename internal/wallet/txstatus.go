@@ -0,0 +1,248 @@
+// internal/wallet/txstatus.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrTransactionNotPending is returned when completing or failing a transaction that is
+// not pending, including one already completed or failed
+var ErrTransactionNotPending = errors.New("transaction is not pending")
+
+// TransactionStatus is where a transaction is in its settlement lifecycle
+type TransactionStatus string
+
+const (
+	StatusPending   TransactionStatus = "pending"
+	StatusCompleted TransactionStatus = "completed"
+	StatusFailed    TransactionStatus = "failed"
+)
+
+// metaFailureReason records on a failed entry why the pending transaction failed
+const metaFailureReason = "failure_reason"
+
+// settlementGate serializes completing and failing a pending transaction, so two
+// confirmations racing each other resolve it once
+type settlementGate struct {
+	mu        sync.Mutex
+	resolving map[string]bool
+}
+
+// settled reports whether tx moved money: entries applied immediately carry no status
+// and count as completed
+func (tx *Transaction) settled() bool {
+	return tx.Status == "" || tx.Status == StatusCompleted
+}
+
+// CreatePendingDeposit records a deposit awaiting external confirmation, such as a card
+// or bank payment that has not cleared. The funds are not in the balance, and cannot be
+// spent, until CompleteTransaction settles it.
+func (ws *WalletService) CreatePendingDeposit(userID string, amount decimal.Decimal, description string) (*Transaction, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	userLock := ws.userLocks.getLock(userID)
+	userLock.Lock()
+	defer userLock.Unlock()
+
+	ws.mu.RLock()
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	tx := &Transaction{
+		FromUserID:  userID,
+		ToUserID:    userID,
+		Amount:      amount,
+		Currency:    wallet.Currency,
+		Type:        TransactionDeposit,
+		Description: description,
+		Status:      StatusPending,
+	}
+	if err := ws.checkAmount(tx.Currency, tx.Amount, false); err != nil {
+		return nil, err
+	}
+	if err := ws.validate(tx); err != nil {
+		return nil, err
+	}
+
+	ws.stampTransaction(tx)
+	ws.recordTransaction(tx)
+	ws.metrics.IncCounter("pending_transactions_total", map[string]string{"status": string(StatusPending)})
+	return tx, nil
+}
+
+// CompleteTransaction settles a pending transaction. The pending entry stays in the log
+// as it was; a completed entry pointing at it through ParentTxID moves the money and is
+// returned.
+func (ws *WalletService) CompleteTransaction(txID string) (*Transaction, error) {
+	return ws.CompleteTransactionContext(context.Background(), txID)
+}
+
+// CompleteTransactionContext is CompleteTransaction on behalf of a caller's ctx
+func (ws *WalletService) CompleteTransactionContext(ctx context.Context, txID string) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	pending, err := ws.claimPending(txID)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.releasePending(txID)
+
+	tx := ws.resolution(pending, StatusCompleted)
+	if err := ws.postCredit(ctx, tx); err != nil {
+		return nil, err
+	}
+	ws.metrics.IncCounter("pending_transactions_total", map[string]string{"status": string(StatusCompleted)})
+	return tx, nil
+}
+
+// FailTransaction resolves a pending transaction that will never settle, e.g. a bounced
+// payment. The failed entry it records moves no money.
+func (ws *WalletService) FailTransaction(txID, reason string) (*Transaction, error) {
+	pending, err := ws.claimPending(txID)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.releasePending(txID)
+
+	tx := ws.resolution(pending, StatusFailed)
+	if reason != "" {
+		tx.Metadata[metaFailureReason] = reason
+	}
+	ws.stampTransaction(tx)
+	ws.recordTransaction(tx)
+	ws.metrics.IncCounter("pending_transactions_total", map[string]string{"status": string(StatusFailed)})
+	return tx, nil
+}
+
+// GetTransactionStatus returns where a transaction is in its lifecycle. A pending entry
+// reports the status of the entry that resolved it, once there is one.
+func (ws *WalletService) GetTransactionStatus(txID string) (TransactionStatus, error) {
+	tx, err := ws.findTransaction(txID)
+	if err != nil {
+		return "", err
+	}
+	if tx.Status == "" {
+		return StatusCompleted, nil
+	}
+	if tx.Status != StatusPending {
+		return tx.Status, nil
+	}
+
+	ws.mu.RLock()
+	resolvedBy, resolved := ws.txIndex.resolved[txID]
+	ws.mu.RUnlock()
+	if !resolved {
+		return StatusPending, nil
+	}
+	resolution, err := ws.findTransaction(resolvedBy)
+	if err != nil {
+		return "", err
+	}
+	return resolution.Status, nil
+}
+
+// resolution builds the entry completing or failing pending
+func (ws *WalletService) resolution(pending *Transaction, status TransactionStatus) *Transaction {
+	meta := copyMetadata(pending.Metadata)
+	if meta == nil {
+		meta = make(map[string]string)
+	}
+	return &Transaction{
+		FromUserID:  pending.FromUserID,
+		ToUserID:    pending.ToUserID,
+		Amount:      pending.Amount,
+		Currency:    pending.Currency,
+		Type:        pending.Type,
+		Description: pending.Description,
+		Status:      status,
+		ParentTxID:  pending.ID,
+		Metadata:    meta,
+	}
+}
+
+// claimPending reserves an unresolved pending transaction for resolving
+func (ws *WalletService) claimPending(txID string) (*Transaction, error) {
+	ws.settling.mu.Lock()
+	defer ws.settling.mu.Unlock()
+
+	ws.mu.RLock()
+	_, pending := ws.txIndex.pending[txID]
+	ws.mu.RUnlock()
+	if !pending || ws.settling.resolving[txID] {
+		if _, err := ws.findTransaction(txID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTransactionNotPending
+	}
+
+	tx, err := ws.findTransaction(txID)
+	if err != nil {
+		return nil, err
+	}
+	if ws.settling.resolving == nil {
+		ws.settling.resolving = make(map[string]bool)
+	}
+	ws.settling.resolving[txID] = true
+	return tx, nil
+}
+
+// releasePending ends a claim taken by claimPending
+func (ws *WalletService) releasePending(txID string) {
+	ws.settling.mu.Lock()
+	delete(ws.settling.resolving, txID)
+	ws.settling.mu.Unlock()
+}
+
+// indexStatus tracks which pending transactions are still open. Caller must hold ws.mu.
+func (ws *WalletService) indexStatus(tx *Transaction) {
+	idx := &ws.txIndex
+	if idx.pending == nil {
+		idx.pending = make(map[string]string)
+		idx.resolved = make(map[string]string)
+	}
+	switch {
+	case tx.Status == StatusPending:
+		idx.pending[tx.ID] = tx.ToUserID
+		ws.rememberRef(tx.ID)
+	case tx.Status != "" && tx.ParentTxID != "":
+		if _, open := idx.pending[tx.ParentTxID]; open {
+			delete(idx.pending, tx.ParentTxID)
+			idx.resolved[tx.ParentTxID] = tx.ID
+		}
+	}
+}
+
+// pendingTransactions returns the user's pending transactions awaiting confirmation
+func (ws *WalletService) pendingTransactions(userID string) []PendingItem {
+	ws.mu.RLock()
+	var ids []string
+	for txID, owner := range ws.txIndex.pending {
+		if owner == userID {
+			ids = append(ids, txID)
+		}
+	}
+	ws.mu.RUnlock()
+
+	var items []PendingItem
+	for _, txID := range ids {
+		tx, err := ws.findTransaction(txID)
+		if err != nil {
+			continue
+		}
+		items = append(items, PendingItem{
+			Kind: PendingTransaction, ID: tx.ID, Amount: tx.Amount, Currency: tx.Currency,
+			Incoming: true, Description: tx.Description, Since: tx.Timestamp,
+		})
+	}
+	return items
+}
@@ -0,0 +1,136 @@
+// internal/wallet/txstatus_test.go
+package wallet
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPendingTransactionLifecycle(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+
+	pending, err := ws.CreatePendingDeposit("alice", decimal.NewFromInt(100), "card top-up")
+	if err != nil {
+		t.Fatalf("CreatePendingDeposit() error = %v", err)
+	}
+	if pending.Status != StatusPending {
+		t.Errorf("status = %q, want %q", pending.Status, StatusPending)
+	}
+	if err := ws.WithdrawDecimal("alice", decimal.NewFromInt(50), "early"); err != ErrInsufficientBalance {
+		t.Errorf("spending pending funds error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	items, _ := ws.GetPendingItems("alice")
+	if len(items) != 1 || items[0].Kind != PendingTransaction || items[0].ID != pending.ID {
+		t.Errorf("pending items = %+v, want the pending deposit", items)
+	}
+	if supply := ws.GetTotalSupply()[DefaultCurrency]; !supply.IsZero() {
+		t.Errorf("supply = %s, want 0 before settlement", supply)
+	}
+
+	completed, err := ws.CompleteTransaction(pending.ID)
+	if err != nil {
+		t.Fatalf("CompleteTransaction() error = %v", err)
+	}
+	if completed.Status != StatusCompleted || completed.ParentTxID != pending.ID {
+		t.Errorf("completed = %+v, want a completed entry for %s", completed, pending.ID)
+	}
+
+	bounced, _ := ws.CreatePendingDeposit("alice", decimal.NewFromInt(30), "bank transfer")
+	failed, err := ws.FailTransaction(bounced.ID, "returned by bank")
+	if err != nil {
+		t.Fatalf("FailTransaction() error = %v", err)
+	}
+	if failed.Metadata[metaFailureReason] != "returned by bank" {
+		t.Errorf("failure reason = %q", failed.Metadata[metaFailureReason])
+	}
+
+	ws.Deposit("alice", 5, "cash")
+	history, _ := ws.GetTransactionHistory("alice")
+	immediate := history[len(history)-1]
+
+	restored, err := RestoreSnapshot(ws.Snapshot())
+	if err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	for _, svc := range []*WalletService{ws, restored} {
+		if b, _ := svc.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(105)) {
+			t.Errorf("balance = %s, want 105", b)
+		}
+		if m, _ := svc.CheckWalletIntegrity("alice"); m != nil {
+			t.Errorf("integrity mismatch = %+v", m)
+		}
+		if items, _ := svc.GetPendingItems("alice"); len(items) != 0 {
+			t.Errorf("pending items after resolution = %+v", items)
+		}
+	}
+
+	tests := []struct {
+		name string
+		txID string
+		want TransactionStatus
+	}{
+		{"completed pending", pending.ID, StatusCompleted},
+		{"failed pending", bounced.ID, StatusFailed},
+		{"failed entry", failed.ID, StatusFailed},
+		{"immediate deposit", immediate.ID, StatusCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, svc := range []*WalletService{ws, restored} {
+				if got, err := svc.GetTransactionStatus(tt.txID); err != nil || got != tt.want {
+					t.Errorf("GetTransactionStatus() = %q, %v; want %q", got, err, tt.want)
+				}
+			}
+		})
+	}
+
+	resolve := []struct {
+		name    string
+		txID    string
+		wantErr error
+	}{
+		{"already completed", pending.ID, ErrTransactionNotPending},
+		{"already failed", bounced.ID, ErrTransactionNotPending},
+		{"not a pending entry", immediate.ID, ErrTransactionNotPending},
+		{"unknown", "tx_missing", ErrTransactionNotFound},
+	}
+	for _, tt := range resolve {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := restored.CompleteTransaction(tt.txID); err != tt.wantErr {
+				t.Errorf("CompleteTransaction() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompleteTransactionConcurrent(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	pending, _ := ws.CreatePendingDeposit("alice", decimal.NewFromInt(100), "card top-up")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ws.CompleteTransaction(pending.ID); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("%d confirmations succeeded, want 1", succeeded)
+	}
+	if b, _ := ws.GetBalanceDecimal("alice"); !b.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("balance = %s, want 100", b)
+	}
+}
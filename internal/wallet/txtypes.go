@@ -0,0 +1,285 @@
+// internal/wallet/txtypes.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error definitions for custom transaction types
+var (
+	ErrInvalidTransactionType = errors.New("invalid transaction type definition")
+	ErrTransactionTypeExists  = errors.New("transaction type already registered")
+	ErrUnknownTransactionType = errors.New("unknown transaction type")
+	ErrTransactionTypeRule    = errors.New("transaction violates its type's rules")
+)
+
+// TransactionKind is how a transaction type moves money
+type TransactionKind string
+
+const (
+	KindCredit   TransactionKind = "credit"   // money enters ToUserID's wallet from outside
+	KindDebit    TransactionKind = "debit"    // money leaves FromUserID's wallet to outside
+	KindTransfer TransactionKind = "transfer" // money moves from FromUserID to ToUserID
+)
+
+// TransactionTypeDef describes an embedder-defined transaction type such as "salary" or
+// "chargeback_fee". Its Kind decides how it moves balances and supply; the rules are
+// enforced on every transaction of the type; Label and Category are for display.
+type TransactionTypeDef struct {
+	Type     TransactionType
+	Kind     TransactionKind
+	Label    string
+	Category string
+
+	MinAmount          decimal.Decimal // zero means no minimum
+	MaxAmount          decimal.Decimal // zero means no maximum
+	DailyLimit         decimal.Decimal // per user and UTC day; zero means unlimited
+	Currencies         []string        // allowed currencies; empty allows any
+	RequireDescription bool
+
+	// CountsAsDeposit makes analytics treat credits of this type like deposits, e.g. a
+	// salary in deposit retention cohorts
+	CountsAsDeposit bool
+}
+
+// customTypes holds the registered transaction types. Like the built-in type tables it
+// is process-wide: a type means the same thing in every service, archive and backup.
+var customTypes = struct {
+	mu   sync.RWMutex
+	defs map[TransactionType]TransactionTypeDef
+}{defs: make(map[TransactionType]TransactionTypeDef)}
+
+// RegisterTransactionType adds a custom transaction type. Built-in types cannot be
+// redefined; registering the same definition again is a no-op, so packages can
+// register their types from init.
+func RegisterTransactionType(def TransactionTypeDef) error {
+	switch {
+	case def.Type == "" || strings.TrimSpace(string(def.Type)) != string(def.Type):
+		return fmt.Errorf("%w: missing or padded type name", ErrInvalidTransactionType)
+	case def.Kind != KindCredit && def.Kind != KindDebit && def.Kind != KindTransfer:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidTransactionType, def.Kind)
+	case def.MinAmount.IsNegative() || def.MaxAmount.IsNegative() || def.DailyLimit.IsNegative():
+		return fmt.Errorf("%w: negative amount rule", ErrInvalidTransactionType)
+	case def.MaxAmount.IsPositive() && def.MinAmount.GreaterThan(def.MaxAmount):
+		return fmt.Errorf("%w: minimum above maximum", ErrInvalidTransactionType)
+	case def.CountsAsDeposit && def.Kind != KindCredit:
+		return fmt.Errorf("%w: only credits count as deposits", ErrInvalidTransactionType)
+	case builtinType(def.Type):
+		return ErrTransactionTypeExists
+	}
+	if def.Label == "" {
+		def.Label = string(def.Type)
+	}
+	def.Currencies = append([]string(nil), def.Currencies...)
+
+	customTypes.mu.Lock()
+	defer customTypes.mu.Unlock()
+
+	if existing, ok := customTypes.defs[def.Type]; ok {
+		if sameTypeDef(existing, def) {
+			return nil
+		}
+		return ErrTransactionTypeExists
+	}
+	customTypes.defs[def.Type] = def
+	return nil
+}
+
+// LookupTransactionType returns the definition of a custom transaction type
+func LookupTransactionType(t TransactionType) (TransactionTypeDef, bool) {
+	customTypes.mu.RLock()
+	defer customTypes.mu.RUnlock()
+
+	def, ok := customTypes.defs[t]
+	def.Currencies = append([]string(nil), def.Currencies...)
+	return def, ok
+}
+
+// ListTransactionTypes returns every custom transaction type, sorted by type
+func ListTransactionTypes() []TransactionTypeDef {
+	customTypes.mu.RLock()
+	defer customTypes.mu.RUnlock()
+
+	defs := make([]TransactionTypeDef, 0, len(customTypes.defs))
+	for _, def := range customTypes.defs {
+		def.Currencies = append([]string(nil), def.Currencies...)
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Type < defs[j].Type })
+	return defs
+}
+
+// Label returns the display label of a transaction type: the registered label of a
+// custom type, or the type itself
+func (t TransactionType) Label() string {
+	if def, ok := LookupTransactionType(t); ok {
+		return def.Label
+	}
+	return string(t)
+}
+
+// CustomTransaction is a money movement of a registered custom type. Credits need
+// ToUserID, debits FromUserID, transfers both; transfers always move the wallets' base
+// currency.
+type CustomTransaction struct {
+	Type        TransactionType
+	FromUserID  string
+	ToUserID    string
+	Amount      decimal.Decimal
+	Currency    string // defaults to the wallet's base currency
+	Description string
+	Metadata    map[string]string
+}
+
+// PostCustomTransaction applies a transaction of a registered custom type. The type's
+// rules are checked along with the registered validators, under the same locks as any
+// other operation.
+func (ws *WalletService) PostCustomTransaction(req CustomTransaction) (*Transaction, error) {
+	return ws.PostCustomTransactionContext(context.Background(), req)
+}
+
+// PostCustomTransactionContext is PostCustomTransaction on behalf of a caller's ctx
+func (ws *WalletService) PostCustomTransactionContext(ctx context.Context, req CustomTransaction) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	def, ok := LookupTransactionType(req.Type)
+	if !ok {
+		return nil, ErrUnknownTransactionType
+	}
+
+	tx := &Transaction{
+		Type:        req.Type,
+		FromUserID:  req.FromUserID,
+		ToUserID:    req.ToUserID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+		Metadata:    copyMetadata(req.Metadata),
+	}
+	var err error
+	switch def.Kind {
+	case KindCredit:
+		tx.FromUserID = ""
+		err = ws.postCredit(ctx, tx)
+	case KindDebit:
+		tx.ToUserID = ""
+		err = ws.postDebit(ctx, tx)
+	case KindTransfer:
+		tx, err = ws.transfer(ctx, req.FromUserID, req.ToUserID, req.Amount, req.Description, transferOptions{
+			metadata: req.Metadata,
+			txType:   req.Type,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// checkTypeRules enforces the rules of a custom transaction type. Caller must hold the
+// lock of the user the rules apply to: the recipient of a credit, otherwise the sender.
+func (ws *WalletService) checkTypeRules(tx *Transaction) error {
+	def, ok := LookupTransactionType(tx.Type)
+	if !ok {
+		return nil
+	}
+
+	currency := tx.currencyOf()
+	switch {
+	case len(def.Currencies) > 0 && !slices.Contains(def.Currencies, currency):
+		return fmt.Errorf("%w: %s not allowed for %s", ErrTransactionTypeRule, currency, def.Type)
+	case def.MinAmount.IsPositive() && tx.Amount.LessThan(def.MinAmount):
+		return fmt.Errorf("%w: %s below minimum %s", ErrTransactionTypeRule, tx.Amount, def.MinAmount)
+	case def.MaxAmount.IsPositive() && tx.Amount.GreaterThan(def.MaxAmount):
+		return fmt.Errorf("%w: %s above maximum %s", ErrTransactionTypeRule, tx.Amount, def.MaxAmount)
+	case def.RequireDescription && strings.TrimSpace(tx.Description) == "":
+		return fmt.Errorf("%w: %s requires a description", ErrTransactionTypeRule, def.Type)
+	}
+
+	if def.DailyLimit.IsPositive() {
+		userID := tx.FromUserID
+		if def.Kind == KindCredit {
+			userID = tx.ToUserID
+		}
+		used := ws.typeVolumeToday(userID, def, currency)
+		if used.Add(tx.Amount).GreaterThan(def.DailyLimit) {
+			return fmt.Errorf("%w: daily limit %s for %s, %s used", ErrTransactionTypeRule, def.DailyLimit, def.Type, used)
+		}
+	}
+	return nil
+}
+
+// typeVolumeToday sums today's transactions of def's type on userID's limited side
+func (ws *WalletService) typeVolumeToday(userID string, def TransactionTypeDef, currency string) decimal.Decimal {
+	it := ws.iterate(userID, IterateOptions{Since: dayStart(ws.now())})
+	defer it.Close()
+
+	used := decimal.Zero
+	for it.Next() {
+		tx := it.Transaction()
+		if tx.Type != def.Type || tx.currencyOf() != currency {
+			continue
+		}
+		if (def.Kind == KindCredit && tx.ToUserID == userID) || (def.Kind != KindCredit && tx.FromUserID == userID) {
+			used = used.Add(tx.Amount)
+		}
+	}
+	return used
+}
+
+// transactionKind returns how a built-in or custom transaction type moves money, or ""
+// for types with their own rules such as conversions and card captures
+func transactionKind(t TransactionType) TransactionKind {
+	switch {
+	case creditTypes[t]:
+		return KindCredit
+	case debitTypes[t]:
+		return KindDebit
+	case t == TransactionTransfer || t == TransactionRefund:
+		return KindTransfer
+	}
+	if def, ok := LookupTransactionType(t); ok {
+		return def.Kind
+	}
+	return ""
+}
+
+// countsAsDeposit reports whether analytics treat credits of type t as deposits
+func countsAsDeposit(t TransactionType) bool {
+	if t == TransactionDeposit {
+		return true
+	}
+	def, ok := LookupTransactionType(t)
+	return ok && def.CountsAsDeposit
+}
+
+// builtinType reports whether t is one of the package's own transaction types
+func builtinType(t TransactionType) bool {
+	if creditTypes[t] || debitTypes[t] {
+		return true
+	}
+	if _, ok := supplyTypes[t]; ok {
+		return true
+	}
+	if _, ok := transitTypes[t]; ok {
+		return true
+	}
+	return t == TransactionTransfer || t == TransactionRefund || t == TransactionConversion
+}
+
+// sameTypeDef compares two custom type definitions
+func sameTypeDef(a, b TransactionTypeDef) bool {
+	return a.Type == b.Type && a.Kind == b.Kind && a.Label == b.Label && a.Category == b.Category &&
+		a.MinAmount.Equal(b.MinAmount) && a.MaxAmount.Equal(b.MaxAmount) && a.DailyLimit.Equal(b.DailyLimit) &&
+		slices.Equal(a.Currencies, b.Currencies) && a.RequireDescription == b.RequireDescription &&
+		a.CountsAsDeposit == b.CountsAsDeposit
+}
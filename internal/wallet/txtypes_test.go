@@ -0,0 +1,136 @@
+// internal/wallet/txtypes_test.go
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Custom types are process-wide, so the tests register them once under test-only names
+var (
+	testSalary = TransactionTypeDef{
+		Type: "test_salary", Kind: KindCredit, Label: "Salary", Category: "income",
+		RequireDescription: true, CountsAsDeposit: true,
+	}
+	testChargebackFee = TransactionTypeDef{
+		Type: "test_chargeback_fee", Kind: KindDebit, Label: "Chargeback fee", Category: "fees",
+		MaxAmount: decimal.NewFromInt(25), Currencies: []string{DefaultCurrency},
+	}
+	testAllowance = TransactionTypeDef{
+		Type: "test_allowance", Kind: KindTransfer, Label: "Allowance", Category: "family",
+		MinAmount: decimal.NewFromInt(1), DailyLimit: decimal.NewFromInt(20),
+	}
+)
+
+func init() {
+	for _, def := range []TransactionTypeDef{testSalary, testChargebackFee, testAllowance} {
+		if err := RegisterTransactionType(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func TestRegisterTransactionType(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     TransactionTypeDef
+		wantErr error
+	}{
+		{"same definition again", testSalary, nil},
+		{"conflicting definition", TransactionTypeDef{Type: "test_salary", Kind: KindDebit}, ErrTransactionTypeExists},
+		{"built-in type", TransactionTypeDef{Type: TransactionDeposit, Kind: KindCredit}, ErrTransactionTypeExists},
+		{"missing name", TransactionTypeDef{Kind: KindCredit}, ErrInvalidTransactionType},
+		{"unknown kind", TransactionTypeDef{Type: "test_bad_kind", Kind: "sideways"}, ErrInvalidTransactionType},
+		{"min above max", TransactionTypeDef{Type: "test_bad_range", Kind: KindDebit, MinAmount: decimal.NewFromInt(5), MaxAmount: decimal.NewFromInt(1)}, ErrInvalidTransactionType},
+		{"debit as deposit", TransactionTypeDef{Type: "test_bad_deposit", Kind: KindDebit, CountsAsDeposit: true}, ErrInvalidTransactionType},
+	}
+	for _, tt := range tests {
+		if err := RegisterTransactionType(tt.def); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: RegisterTransactionType() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if def, ok := LookupTransactionType("test_salary"); !ok || def.Category != "income" {
+		t.Errorf("LookupTransactionType() = %+v, %v", def, ok)
+	}
+	if got := TransactionType("test_chargeback_fee").Label(); got != "Chargeback fee" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := TransactionDeposit.Label(); got != "deposit" {
+		t.Errorf("built-in Label() = %q", got)
+	}
+}
+
+func TestPostCustomTransaction_FirstClass(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("parent", "Parent", "parent@example.com")
+	ws.CreateUser("kid", "Kid", "kid@example.com")
+
+	posts := []struct {
+		name    string
+		req     CustomTransaction
+		wantErr error
+	}{
+		{"salary", CustomTransaction{Type: "test_salary", ToUserID: "parent", Amount: decimal.NewFromInt(100), Description: "March"}, nil},
+		{"salary without description", CustomTransaction{Type: "test_salary", ToUserID: "parent", Amount: decimal.NewFromInt(1)}, ErrTransactionTypeRule},
+		{"fee", CustomTransaction{Type: "test_chargeback_fee", FromUserID: "parent", Amount: decimal.NewFromInt(15)}, nil},
+		{"fee above maximum", CustomTransaction{Type: "test_chargeback_fee", FromUserID: "parent", Amount: decimal.NewFromInt(30)}, ErrTransactionTypeRule},
+		{"fee in another currency", CustomTransaction{Type: "test_chargeback_fee", FromUserID: "parent", Amount: decimal.NewFromInt(1), Currency: "EUR"}, ErrTransactionTypeRule},
+		{"allowance", CustomTransaction{Type: "test_allowance", FromUserID: "parent", ToUserID: "kid", Amount: decimal.NewFromInt(12)}, nil},
+		{"allowance below minimum", CustomTransaction{Type: "test_allowance", FromUserID: "parent", ToUserID: "kid", Amount: decimal.RequireFromString("0.5")}, ErrTransactionTypeRule},
+		{"allowance over the daily limit", CustomTransaction{Type: "test_allowance", FromUserID: "parent", ToUserID: "kid", Amount: decimal.NewFromInt(10)}, ErrTransactionTypeRule},
+		{"unknown type", CustomTransaction{Type: "test_unregistered", ToUserID: "kid", Amount: decimal.NewFromInt(1)}, ErrUnknownTransactionType},
+	}
+	for _, p := range posts {
+		tx, err := ws.PostCustomTransaction(p.req)
+		if !errors.Is(err, p.wantErr) {
+			t.Errorf("%s: PostCustomTransaction() error = %v, want %v", p.name, err, p.wantErr)
+		}
+		if err == nil && tx.Type != p.req.Type {
+			t.Errorf("%s: recorded type = %s", p.name, tx.Type)
+		}
+	}
+
+	// The daily limit resets the next day
+	clock.Advance(24 * time.Hour)
+	if _, err := ws.PostCustomTransaction(CustomTransaction{Type: "test_allowance", FromUserID: "parent", ToUserID: "kid", Amount: decimal.NewFromInt(10)}); err != nil {
+		t.Errorf("next-day allowance error = %v", err)
+	}
+
+	// Balances, supply and the ledger all understand the custom kinds
+	if b, _ := ws.GetBalanceDecimal("parent"); !b.Equal(decimal.NewFromInt(63)) {
+		t.Errorf("parent balance = %s, want 63", b)
+	}
+	if supply := ws.GetTotalSupply()[DefaultCurrency]; !supply.Equal(decimal.NewFromInt(85)) {
+		t.Errorf("total supply = %s, want 85", supply)
+	}
+	if deviations := ws.CheckSupply(); len(deviations) != 0 {
+		t.Errorf("CheckSupply() = %+v", deviations)
+	}
+	for _, id := range []string{"parent", "kid"} {
+		if mismatch, err := ws.CheckWalletIntegrity(id); mismatch != nil || err != nil {
+			t.Errorf("CheckWalletIntegrity(%s) = %+v, %v", id, mismatch, err)
+		}
+	}
+
+	// Statements carry the display label
+	var statement bytes.Buffer
+	ws.ExportTransactionHistory("parent", &statement)
+	for _, label := range []string{"Salary", "Chargeback fee", "Allowance"} {
+		if !strings.Contains(statement.String(), label) {
+			t.Errorf("statement lacks %q:\n%s", label, statement.String())
+		}
+	}
+
+	// Analytics count the salary as a deposit
+	start := clock.Now().AddDate(0, 0, -1)
+	cohorts, _ := ws.DepositRetention(start, start.AddDate(0, 0, 2), GranularityDay, 1)
+	if len(cohorts) == 0 || cohorts[0].Size != 1 {
+		t.Errorf("DepositRetention() = %+v, want one depositor", cohorts)
+	}
+}
@@ -4,6 +4,7 @@ package wallet
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 
 	"github.com/shopspring/decimal"
 )
@@ -15,6 +16,9 @@ var (
 	ErrInvalidAmount       = errors.New("invalid amount")
 	ErrSameUserTransfer    = errors.New("cannot transfer to same user")
 	ErrUserAlreadyExists   = errors.New("user already exists")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrInvalidCurrency     = errors.New("invalid currency")
+	ErrBelowFloor          = errors.New("transfer would leave the balance below the floor")
 )
 
 // User represents a wallet user with basic information
@@ -24,20 +28,81 @@ type User struct {
 	Email string
 }
 
+// DefaultCurrency is the base currency of wallets created without an explicit currency
+const DefaultCurrency = "USD"
+
 // Wallet represents a user's wallet with balance and locking mechanism
 type Wallet struct {
-	UserID  string
-	Balance decimal.Decimal
-	mu      sync.RWMutex
+	UserID   string
+	Currency string
+	Balance  decimal.Decimal
+	Foreign  map[string]decimal.Decimal // holdings in currencies other than Currency
+	// AutoSettle converts incoming foreign-currency credits into Currency on receipt
+	AutoSettle bool
+	mu         sync.RWMutex
+	view       atomic.Pointer[walletView] // published by publish; read without locks
+	held       map[string]decimal.Decimal // reserved by active holds, per currency
 }
 
 // TransactionType defines the type of transaction
 type TransactionType string
 
 const (
-	TransactionDeposit  TransactionType = "deposit"
-	TransactionWithdraw TransactionType = "withdraw"
-	TransactionTransfer TransactionType = "transfer"
+	TransactionDeposit    TransactionType = "deposit"
+	TransactionWithdraw   TransactionType = "withdraw"
+	TransactionTransfer   TransactionType = "transfer"
+	TransactionConversion TransactionType = "conversion"
+
+	// Federation legs between independent wallet-app instances
+	TransactionFederationOut    TransactionType = "federation_out"
+	TransactionFederationIn     TransactionType = "federation_in"
+	TransactionFederationRefund TransactionType = "federation_refund"
+
+	// Gifts to people without an account are escrowed until claimed
+	TransactionGiftEscrow TransactionType = "gift_escrow"
+	TransactionGiftClaim  TransactionType = "gift_claim"
+	TransactionGiftRefund TransactionType = "gift_refund"
+
+	// Transfers held for compliance review debit the sender until the case is resolved
+	TransactionComplianceHold TransactionType = "compliance_hold"
+	TransactionHoldRelease    TransactionType = "hold_release"
+	TransactionHoldReversal   TransactionType = "hold_reversal"
+
+	// Admin corrections post the difference to a target balance instead of overwriting it
+	TransactionAdjustmentCredit TransactionType = "adjustment_credit"
+	TransactionAdjustmentDebit  TransactionType = "adjustment_debit"
+
+	// Payouts a payment rail fails to settle are credited back
+	TransactionRailReversal TransactionType = "rail_reversal"
+
+	// Card authorizations hold funds until the purchase is captured or released
+	TransactionCardHold    TransactionType = "card_hold"
+	TransactionCardCapture TransactionType = "card_capture"
+	TransactionCardRelease TransactionType = "card_release"
+
+	// Rolling reserves withhold part of a credit and return it after a holding period
+	TransactionReserveHold    TransactionType = "reserve_hold"
+	TransactionReserveRelease TransactionType = "reserve_release"
+
+	// Refunds return part or all of a transfer or card capture to the payer
+	TransactionRefund     TransactionType = "refund"
+	TransactionCardRefund TransactionType = "card_refund"
+
+	// Captured holds take the captured amount from the wallet
+	TransactionHoldCapture TransactionType = "hold_capture"
+
+	// Interest accrued on a wallet's balance is credited once per period
+	TransactionInterest TransactionType = "interest"
+
+	// Loyalty redemptions pay a merchant the value of points burned on a charge
+	TransactionLoyaltyRedemption TransactionType = "loyalty_redemption"
+
+	// The treasury issues new money into a wallet and retires it again
+	TransactionMint TransactionType = "mint"
+	TransactionBurn TransactionType = "burn"
+
+	// Withdrawals that take a wallet below zero draw on its overdraft facility
+	TransactionOverdraft TransactionType = "overdraft"
 )
 
 // Transaction represents a financial transaction in the system
@@ -46,7 +111,29 @@ type Transaction struct {
 	FromUserID  string
 	ToUserID    string
 	Amount      decimal.Decimal
+	Currency    string
 	Type        TransactionType
 	Description string
 	Timestamp   int64
+
+	// Status is empty for transactions applied at once, which count as completed.
+	// Pending and failed entries move no money.
+	Status TransactionStatus
+
+	// Conversion legs: Amount/Currency is debited, ToAmount/ToCurrency is credited at Rate
+	ToAmount   decimal.Decimal
+	ToCurrency string
+	Rate       decimal.Decimal
+
+	// Approvals records the sign-off chain that released the funds, if any
+	Approvals []Approval
+
+	// Metadata carries annotations attached by validators and embedding applications
+	Metadata map[string]string
+
+	// ParentTxID points at the transaction this one was derived from, e.g. a refund's
+	// original or a conversion's triggering credit
+	ParentTxID string
+	// RelatedTxIDs links peers that belong together without a parent, e.g. netting sets
+	RelatedTxIDs []string
 }
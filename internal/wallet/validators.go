@@ -0,0 +1,119 @@
+// internal/wallet/validators.go
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrOperationRejected wraps the error returned by a validator that vetoed an operation
+var ErrOperationRejected = errors.New("operation rejected by validator")
+
+// Operation describes a money movement about to be applied, as seen by validators
+type Operation struct {
+	Type        TransactionType
+	FromUserID  string
+	ToUserID    string
+	Amount      decimal.Decimal
+	Currency    string
+	Description string
+	Time        time.Time
+
+	// Annotations added by validators are copied onto the resulting transaction's Metadata
+	Annotations map[string]string
+}
+
+// Annotate attaches a key/value pair to the resulting transaction
+func (op *Operation) Annotate(key, value string) {
+	if op.Annotations == nil {
+		op.Annotations = make(map[string]string)
+	}
+	op.Annotations[key] = value
+}
+
+// ValidatorFunc inspects an operation before it is applied. Returning an error vetoes it.
+// Validators run while the affected users are locked and must not call mutating
+// WalletService methods.
+type ValidatorFunc func(op *Operation) error
+
+// validatorRegistry holds validators keyed by transaction type
+type validatorRegistry struct {
+	mu     sync.RWMutex
+	byType map[TransactionType][]ValidatorFunc
+}
+
+// RegisterValidator adds a validator that runs for every operation of txType, in
+// registration order
+func (ws *WalletService) RegisterValidator(txType TransactionType, fn ValidatorFunc) {
+	ws.validators.mu.Lock()
+	defer ws.validators.mu.Unlock()
+
+	if ws.validators.byType == nil {
+		ws.validators.byType = make(map[TransactionType][]ValidatorFunc)
+	}
+	ws.validators.byType[txType] = append(ws.validators.byType[txType], fn)
+}
+
+// validate rejects transactions touching a wallet being closed, debits a restricted
+// wallet may not make, transactions awaiting a mandatory consent and transactions
+// breaking the rules of a custom type, then runs the validators registered for
+// tx.Type and merges their annotations into tx.Metadata. The first veto stops
+// evaluation.
+func (ws *WalletService) validate(tx *Transaction) error {
+	if err := ws.checkClosure(tx); err != nil {
+		return err
+	}
+	if err := ws.checkAdminFreeze(tx); err != nil {
+		return err
+	}
+	if err := ws.checkRestriction(tx); err != nil {
+		return err
+	}
+	if err := ws.checkSpendingLimit(tx); err != nil {
+		return err
+	}
+	if err := ws.checkConsent(tx); err != nil {
+		return err
+	}
+	if err := ws.checkTypeRules(tx); err != nil {
+		return err
+	}
+
+	ws.validators.mu.RLock()
+	validators := ws.validators.byType[tx.Type]
+	ws.validators.mu.RUnlock()
+
+	if len(validators) == 0 {
+		return nil
+	}
+
+	op := &Operation{
+		Type:        tx.Type,
+		FromUserID:  tx.FromUserID,
+		ToUserID:    tx.ToUserID,
+		Amount:      tx.Amount,
+		Currency:    tx.Currency,
+		Description: tx.Description,
+		Time:        ws.now(),
+	}
+	for _, fn := range validators {
+		if err := fn(op); err != nil {
+			return fmt.Errorf("%w: %w", ErrOperationRejected, err)
+		}
+	}
+
+	if len(op.Annotations) > 0 {
+		if tx.Metadata == nil {
+			tx.Metadata = make(map[string]string, len(op.Annotations))
+		}
+		for k, v := range op.Annotations {
+			tx.Metadata[k] = v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// internal/wallet/validators_test.go
+package wallet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWalletService_RegisterValidator tests vetoes and annotations by transaction type
+func TestWalletService_RegisterValidator(t *testing.T) {
+	ws, clock := newTestService() // 2024-01-01 is a Monday
+	ws.CreateUser("employer", "Acme", "pay@acme.com")
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.Deposit("employer", 1000.0, "funding")
+
+	errWeekend := errors.New("salary transfers only run on weekdays")
+	ws.RegisterValidator(TransactionTransfer, func(op *Operation) error {
+		if !strings.HasPrefix(op.Description, "salary") {
+			return nil
+		}
+		if wd := op.Time.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			return errWeekend
+		}
+		op.Annotate("category", "salary")
+		return nil
+	})
+
+	if err := ws.Transfer("employer", "user1", 100.0, "salary january"); err != nil {
+		t.Fatalf("Weekday salary transfer error = %v", err)
+	}
+	history, _ := ws.GetTransactionHistory("user1")
+	if history[0].Metadata["category"] != "salary" {
+		t.Errorf("Expected salary annotation, got %+v", history[0].Metadata)
+	}
+
+	clock.Advance(5 * 24 * time.Hour) // Saturday
+	err := ws.Transfer("employer", "user1", 100.0, "salary bonus")
+	if !errors.Is(err, ErrOperationRejected) || !errors.Is(err, errWeekend) {
+		t.Errorf("Expected weekend veto, got %v", err)
+	}
+	if err := ws.Transfer("employer", "user1", 10.0, "gift"); err != nil {
+		t.Errorf("Unrelated transfer error = %v", err)
+	}
+
+	// Validators only run for their own type
+	ws.RegisterValidator(TransactionWithdraw, func(op *Operation) error {
+		return errors.New("withdrawals disabled")
+	})
+	if err := ws.Deposit("user1", 5.0, "deposit"); err != nil {
+		t.Errorf("Deposit() error = %v", err)
+	}
+	if err := ws.Withdraw("user1", 5.0, "cash"); !errors.Is(err, ErrOperationRejected) {
+		t.Errorf("Expected withdrawal veto, got %v", err)
+	}
+
+	balance, _ := ws.GetBalance("user1")
+	if balance != 115.0 {
+		t.Errorf("Expected balance 115, got %.2f", balance)
+	}
+}
@@ -2,8 +2,12 @@
 package wallet
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -11,42 +15,158 @@ import (
 
 // WalletService manages all wallet operations and user accounts
 type WalletService struct {
-	users        map[string]*User
-	wallets      map[string]*Wallet
-	transactions []*Transaction
-	mu           sync.RWMutex
-	userLocks    *userLockManager
-}
-
-// userLockManager manages locks for individual users to prevent deadlocks
-type userLockManager struct {
-	locks sync.Map
-}
-
-// getLock returns a mutex for the given user ID
-func (ulm *userLockManager) getLock(userID string) *sync.Mutex {
-	lock, _ := ulm.locks.LoadOrStore(userID, &sync.Mutex{})
-	return lock.(*sync.Mutex)
+	users          map[string]*User
+	wallets        map[string]*Wallet
+	walletIndex    sync.Map // userID -> *Wallet, for lock-free balance reads
+	transactions   []*Transaction
+	mu             sync.RWMutex
+	userLocks      *userLockManager
+	notifier       Notifier
+	metrics        MetricsRecorder
+	rates          RateProvider
+	now            func() time.Time
+	fx             fxDesk
+	blocks         blockList
+	federation     federationState
+	orgs           orgRegistry
+	validators     validatorRegistry
+	events         eventLog
+	webhooks       webhookRegistry
+	currencies     currencyRegistry
+	timing         opTimingState
+	scheduler      scheduler
+	gifts          giftBook
+	compliance     complianceDesk
+	health         healthRegistry
+	calendar       *BusinessCalendar
+	destinations   destinationBook
+	automation     automationEngine
+	mandates       mandateBook
+	rollConvention RollConvention
+	archive        ArchiveStore
+	logBase        int // log position of transactions[0]; earlier entries were archived
+	txIndex        txIndex
+	supply         supplyLedger
+	emails         emailIndex
+	identities     identityBook
+	preferences    preferenceBook
+	rails          railDesk
+	cards          cardBook
+	activity       activityIndex
+	budgets        map[string]LayerBudget
+	fxRates        rateTable
+	paymentLinks   paymentLinkBook
+	orders         orderBook
+	reserves       reserveBook
+	closures       closureBook
+	restrictions   restrictionBook
+	idempotency    idempotencyGate
+	clientTxIDs    ClientTxRegistry
+	settling       settlementGate
+	consents       consentBook
+	holds          holdBook
+	fxOrders       conversionOrderBook
+	interest       interestBook
+	loyalty        loyaltyBook
+	favorites      favoriteBook
+	reservations   reservationBook
+	sessions       logSignal
+	segments       segmentBook
+	attachments    attachmentDesk
+	minBalances    minBalanceBook
+	maxBalances    maxBalanceBook
+	spending       spendingBook
+	overdrafts     overdraftBook
+	hotspots       hotSpotMonitor
+	receipts       receiptBook
+	display        displayBook
+	descriptors    descriptorBook
+	tenants        tenantBook
+	dataRetention  dataRetentionDesk
+	annotations    annotationBook
+	adjustments    adjustmentDesk
+	failures       failureLog
+	auditLog       AuditStore
+	retention      retentionState
+	batcher        logBatcher
+	impersonation  impersonationState
+	refunds        refundBook
+	store          Store
+	storeStatus    storeStatus
+	recovered      []RecoveredTransfer
+	schemas        schemaRegistry
+	ids            *idGenerator // set by WithTestMode
 }
 
 // NewWalletService creates and initializes a new WalletService instance
-func NewWalletService() *WalletService {
-	return &WalletService{
+func NewWalletService(opts ...Option) *WalletService {
+	ws := &WalletService{
 		users:        make(map[string]*User),
 		wallets:      make(map[string]*Wallet),
 		transactions: make([]*Transaction, 0),
-		userLocks:    &userLockManager{},
+		userLocks:    newUserLockManager(),
+		notifier:     noopNotifier{},
+		metrics:      noopMetrics{},
+		now:          time.Now,
+		fx:           newFXDesk(),
+		currencies:   currencyRegistry{byCode: defaultCurrencyMap()},
+		auditLog:     NewMemoryAuditLog(),
 	}
+	ws.userLocks.onGrant = ws.recordLockGrant
+
+	for _, opt := range opts {
+		opt(ws)
+	}
+	ws.initClientTxIDs()
+	ws.startDigestJob()
+	ws.startHotSpotJob()
+	ws.startDataRetentionJob()
+
+	return ws
 }
 
 // CreateUser creates a new user and initializes an empty wallet for them
 func (ws *WalletService) CreateUser(userID, name, email string) error {
+	return ws.CreateUserContext(context.Background(), userID, name, email)
+}
+
+// CreateUserContext is CreateUser on behalf of a caller's ctx
+func (ws *WalletService) CreateUserContext(ctx context.Context, userID, name, email string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := ws.addUser(userID, name, email)
+	ws.audit(ctx, "create_user", nil, err, userID)
+	if err != nil {
+		return err
+	}
+
+	// Gifts sent to this email before signup convert into real credits now
+	ws.claimGiftsByEmail(userID, email)
+
+	return nil
+}
+
+// addUser stores a new user and an empty wallet
+func (ws *WalletService) addUser(userID, name, email string) error {
+	return ws.addUserWithCurrency(userID, name, email, DefaultCurrency)
+}
+
+// addUserWithCurrency is addUser for a wallet based in currency
+func (ws *WalletService) addUserWithCurrency(userID, name, email, currency string) error {
+	if isSystemAccount(userID) {
+		return ErrReservedUserID
+	}
+
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
 	if _, exists := ws.users[userID]; exists {
 		return ErrUserAlreadyExists
 	}
+	if err := ws.emails.claim(email, userID); err != nil {
+		return err
+	}
 
 	user := &User{
 		ID:    userID,
@@ -55,149 +175,177 @@ func (ws *WalletService) CreateUser(userID, name, email string) error {
 	}
 
 	wallet := &Wallet{
-		UserID:  userID,
-		Balance: decimal.NewFromFloat(0.0),
+		UserID:   userID,
+		Currency: currency,
+		Balance:  decimal.NewFromFloat(0.0),
+		Foreign:  make(map[string]decimal.Decimal),
 	}
 
 	ws.users[userID] = user
-	ws.wallets[userID] = wallet
+	ws.registerWallet(wallet)
+	ws.persistUser(user, wallet)
+	ws.activity.signup(userID, ws.now().Unix())
+
+	ws.emit(Event{Type: EventUserCreated, UserID: userID})
 
 	return nil
 }
 
 // Deposit adds funds to a user's wallet
 func (ws *WalletService) Deposit(userID string, amount float64, description string) error {
-	decimalAmount := decimal.NewFromFloat(amount)
-	if decimalAmount.LessThanOrEqual(decimal.Zero) {
-		return ErrInvalidAmount
-	}
-
-	// Get user-specific lock to prevent concurrent operations
-	userLock := ws.userLocks.getLock(userID)
-	userLock.Lock()
-	defer userLock.Unlock()
-
-	ws.mu.RLock()
-	wallet, exists := ws.wallets[userID]
-	ws.mu.RUnlock()
-
-	if !exists {
-		return ErrUserNotFound
-	}
-
-	wallet.mu.Lock()
-	wallet.Balance = wallet.Balance.Add(decimalAmount)
-	wallet.mu.Unlock()
-
-	// Record the transaction
-	tx := &Transaction{
-		ID:          generateTransactionID(),
-		FromUserID:  userID,
-		ToUserID:    userID,
-		Amount:      decimalAmount,
-		Type:        TransactionDeposit,
-		Description: description,
-		Timestamp:   time.Now().Unix(),
-	}
-
-	ws.recordTransaction(tx)
-
-	return nil
+	return ws.DepositDecimal(userID, decimal.NewFromFloat(amount), description)
 }
 
 // DepositDecimal adds funds to a user's wallet using decimal.Decimal
 func (ws *WalletService) DepositDecimal(userID string, amount decimal.Decimal, description string) error {
+	return ws.DepositContext(context.Background(), userID, amount, description)
+}
+
+// DepositContext is DepositDecimal on behalf of a caller's ctx
+func (ws *WalletService) DepositContext(ctx context.Context, userID string, amount decimal.Decimal, description string) error {
 	if amount.LessThanOrEqual(decimal.Zero) {
 		return ErrInvalidAmount
 	}
-
-	// Get user-specific lock to prevent concurrent operations
-	userLock := ws.userLocks.getLock(userID)
-	userLock.Lock()
-	defer userLock.Unlock()
-
-	ws.mu.RLock()
-	wallet, exists := ws.wallets[userID]
-	ws.mu.RUnlock()
-
-	if !exists {
-		return ErrUserNotFound
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	wallet.mu.Lock()
-	wallet.Balance = wallet.Balance.Add(amount)
-	wallet.mu.Unlock()
+	_, err := ws.deposit(ctx, userID, amount, description, nil)
+	ws.noteFailure(FailedOperation{UserID: userID, Type: TransactionDeposit, Amount: amount, Description: description}, err)
+	return err
+}
 
-	// Record the transaction
+// deposit credits a validated amount to userID's wallet
+func (ws *WalletService) deposit(ctx context.Context, userID string, amount decimal.Decimal, description string, metadata map[string]string) (*Transaction, error) {
 	tx := &Transaction{
-		ID:          generateTransactionID(),
 		FromUserID:  userID,
 		ToUserID:    userID,
 		Amount:      amount,
 		Type:        TransactionDeposit,
 		Description: description,
-		Timestamp:   time.Now().Unix(),
+		Metadata:    metadata,
 	}
-
-	ws.recordTransaction(tx)
-
-	return nil
+	if err := ws.postCredit(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
 
 // Withdraw removes funds from a user's wallet
 func (ws *WalletService) Withdraw(userID string, amount float64, description string) error {
-	decimalAmount := decimal.NewFromFloat(amount)
+	return ws.WithdrawDecimal(userID, decimal.NewFromFloat(amount), description)
+}
+
+// WithdrawDecimal removes funds from a user's wallet using decimal.Decimal
+func (ws *WalletService) WithdrawDecimal(userID string, decimalAmount decimal.Decimal, description string) error {
+	return ws.WithdrawContext(context.Background(), userID, decimalAmount, description)
+}
+
+// WithdrawContext is WithdrawDecimal on behalf of a caller's ctx
+func (ws *WalletService) WithdrawContext(ctx context.Context, userID string, decimalAmount decimal.Decimal, description string) error {
 	if decimalAmount.LessThanOrEqual(decimal.Zero) {
 		return ErrInvalidAmount
 	}
-
-	// Get user-specific lock
-	userLock := ws.userLocks.getLock(userID)
-	userLock.Lock()
-	defer userLock.Unlock()
-
-	ws.mu.RLock()
-	wallet, exists := ws.wallets[userID]
-	ws.mu.RUnlock()
-
-	if !exists {
-		return ErrUserNotFound
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	wallet.mu.Lock()
-	defer wallet.mu.Unlock()
+	_, err := ws.withdraw(ctx, userID, decimalAmount, description, nil)
+	ws.noteFailure(FailedOperation{UserID: userID, Type: TransactionWithdraw, Amount: decimalAmount, Description: description}, err)
+	return err
+}
 
-	if wallet.Balance.LessThan(decimalAmount) {
-		return ErrInsufficientBalance
+// withdraw debits a validated amount from userID's wallet
+func (ws *WalletService) withdraw(ctx context.Context, userID string, amount decimal.Decimal, description string, metadata map[string]string) (*Transaction, error) {
+	if ws.whitelistEnforced() {
+		return nil, ErrDestinationRequired
 	}
 
-	wallet.Balance = wallet.Balance.Sub(decimalAmount)
-
-	// Record the transaction
 	tx := &Transaction{
-		ID:          generateTransactionID(),
 		FromUserID:  userID,
 		ToUserID:    userID,
-		Amount:      decimalAmount,
+		Amount:      amount,
 		Type:        TransactionWithdraw,
 		Description: description,
-		Timestamp:   time.Now().Unix(),
+		Metadata:    metadata,
 	}
-
-	ws.recordTransaction(tx)
-
-	return nil
+	if err := ws.postDebit(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
 
 // Transfer moves funds from one user to another
 func (ws *WalletService) Transfer(fromUserID, toUserID string, amount float64, description string) error {
-	decimalAmount := decimal.NewFromFloat(amount)
+	return ws.TransferDecimal(fromUserID, toUserID, decimal.NewFromFloat(amount), description)
+}
+
+// TransferDecimal moves funds from one user to another using decimal.Decimal
+func (ws *WalletService) TransferDecimal(fromUserID, toUserID string, amount decimal.Decimal, description string) error {
+	return ws.TransferContext(context.Background(), fromUserID, toUserID, amount, description)
+}
+
+// TransferContext is TransferDecimal on behalf of a caller's ctx
+func (ws *WalletService) TransferContext(ctx context.Context, fromUserID, toUserID string, amount decimal.Decimal, description string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := ws.transfer(ctx, fromUserID, toUserID, amount, description, transferOptions{})
+	ws.noteFailure(FailedOperation{UserID: fromUserID, Type: TransactionTransfer, CounterpartyID: toUserID, Amount: amount, Description: description}, err)
+	return err
+}
+
+// transferOptions tweaks the checks applied by transfer
+type transferOptions struct {
+	skipBlockCheck bool              // admin override of counterparty blocks
+	approvals      []Approval        // sign-off chain recorded on the transaction
+	metadata       map[string]string // annotations recorded on the transaction
+	priority       Priority          // user lock lane; the zero value is interactive
+	floor          *decimal.Decimal  // minimum balance the sender must keep after the transfer
+	refundOf       string            // original transaction when this transfer is a refund
+	txType         TransactionType   // custom transfer type recorded instead of TransactionTransfer
+	unhold         decimal.Decimal   // sender's held funds the transfer consumes
+}
+
+// TransferWithFloor transfers amount only if the sender keeps at least minRemaining
+// afterwards, e.g. to leave a buffer for upcoming bills. The floor is checked under the
+// sender's lock, atomically with the debit.
+func (ws *WalletService) TransferWithFloor(fromUserID, toUserID string, amount, minRemaining decimal.Decimal) (*Transaction, error) {
+	return ws.TransferWithFloorContext(context.Background(), fromUserID, toUserID, amount, minRemaining)
+}
+
+// TransferWithFloorContext is TransferWithFloor on behalf of a caller's ctx
+func (ws *WalletService) TransferWithFloorContext(ctx context.Context, fromUserID, toUserID string, amount, minRemaining decimal.Decimal) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if minRemaining.IsNegative() {
+		return nil, ErrInvalidAmount
+	}
+	return ws.transfer(ctx, fromUserID, toUserID, amount, "", transferOptions{floor: &minRemaining})
+}
+
+// transfer moves funds between two users after validating the request
+func (ws *WalletService) transfer(ctx context.Context, fromUserID, toUserID string, decimalAmount decimal.Decimal, description string, opts transferOptions) (tx *Transaction, err error) {
+	timer := ws.startOp(string(TransactionTransfer), fromUserID, toUserID)
+	defer func() {
+		timer.finish(err)
+		op, logged := string(TransactionTransfer), tx
+		if tx != nil {
+			op = string(tx.Type)
+		}
+		if err != nil && !errors.Is(err, ErrTransferHeld) {
+			logged = nil
+		}
+		ws.audit(ctx, op, logged, err, fromUserID, toUserID)
+	}()
+
 	if decimalAmount.LessThanOrEqual(decimal.Zero) {
-		return ErrInvalidAmount
+		return nil, ErrInvalidAmount
 	}
 
 	if fromUserID == toUserID {
-		return ErrSameUserTransfer
+		return nil, ErrSameUserTransfer
 	}
 
 	// Verify both users exist
@@ -207,45 +355,112 @@ func (ws *WalletService) Transfer(fromUserID, toUserID string, amount float64, d
 	ws.mu.RUnlock()
 
 	if !fromExists || !toExists {
-		return ErrUserNotFound
+		return nil, ErrUserNotFound
+	}
+
+	if fromWallet.Currency != toWallet.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+
+	if err := ws.checkAmount(fromWallet.Currency, decimalAmount, true); err != nil {
+		return nil, err
+	}
+
+	if !opts.skipBlockCheck && ws.IsBlocked(toUserID, fromUserID) {
+		return nil, ErrCounterpartyBlocked
 	}
 
 	// To prevent deadlocks, always acquire locks in consistent order
 	firstLock, secondLock := ws.getOrderedLocks(fromUserID, toUserID)
 
-	firstLock.Lock()
-	secondLock.Lock()
+	firstLock.LockAt(opts.priority)
+	secondLock.LockAt(opts.priority)
 	defer firstLock.Unlock()
 	defer secondLock.Unlock()
+	timer.locked()
+
+	tx = &Transaction{
+		ID:          ws.newID("tx"),
+		FromUserID:  fromUserID,
+		ToUserID:    toUserID,
+		Amount:      decimalAmount,
+		Currency:    fromWallet.Currency,
+		Type:        TransactionTransfer,
+		Description: description,
+		Approvals:   opts.approvals,
+		Metadata:    copyMetadata(opts.metadata),
+	}
+	if opts.refundOf != "" {
+		tx.Type = TransactionRefund
+		tx.ParentTxID = opts.refundOf
+	}
+	if opts.txType != "" {
+		tx.Type = opts.txType
+	}
+	stampTrace(ctx, tx)
+	if err := ws.validate(tx); err != nil {
+		return nil, err
+	}
+
+	// Transfers flagged by a hold rule only debit the sender until a reviewer decides
+	holdRule, holdReason := ws.holdReason(tx)
+	if holdReason != "" {
+		tx.Type = TransactionComplianceHold
+	}
+
+	// The recipient's cap is checked first, also for transfers held for review, which
+	// reach it when released; its balance cannot move while we hold its lock
+	capType := tx.Type
+	if capType == TransactionComplianceHold {
+		capType = TransactionTransfer
+	}
+	maximum := ws.maximumFor(toUserID, toWallet.Currency, capType)
+	toWallet.mu.RLock()
+	err = toWallet.checkMaximum(toWallet.Currency, decimalAmount, maximum)
+	toWallet.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 
 	// Check sufficient balance
+	minimum := ws.minimumFor(fromUserID, fromWallet.Currency, tx.Type)
 	fromWallet.mu.Lock()
-	if fromWallet.Balance.LessThan(decimalAmount) {
+	if fromWallet.available(fromWallet.Currency).Add(opts.unhold).LessThan(decimalAmount) {
+		fromWallet.mu.Unlock()
+		return nil, ErrInsufficientBalance
+	}
+	if err := fromWallet.checkMinimum(fromWallet.Currency, decimalAmount, opts.unhold, minimum); err != nil {
+		fromWallet.mu.Unlock()
+		return nil, err
+	}
+	if opts.floor != nil && fromWallet.Balance.Sub(decimalAmount).LessThan(*opts.floor) {
 		fromWallet.mu.Unlock()
-		return ErrInsufficientBalance
+		return nil, ErrBelowFloor
 	}
+	fromWallet.hold(fromWallet.Currency, opts.unhold.Neg())
 	fromWallet.Balance = fromWallet.Balance.Sub(decimalAmount)
+	fromWallet.publish()
 	fromWallet.mu.Unlock()
 
+	if holdReason != "" {
+		tx.Timestamp = ws.now().Unix()
+		ws.recordTransaction(tx)
+		ws.openCase(tx, holdRule, holdReason)
+		return tx, ErrTransferHeld
+	}
+
 	// Update recipient balance
 	toWallet.mu.Lock()
 	toWallet.Balance = toWallet.Balance.Add(decimalAmount)
+	toWallet.publish()
 	toWallet.mu.Unlock()
 
 	// Record the transaction
-	tx := &Transaction{
-		ID:          generateTransactionID(),
-		FromUserID:  fromUserID,
-		ToUserID:    toUserID,
-		Amount:      decimalAmount,
-		Type:        TransactionTransfer,
-		Description: description,
-		Timestamp:   time.Now().Unix(),
-	}
-
+	tx.Timestamp = ws.now().Unix()
 	ws.recordTransaction(tx)
+	ws.reserveCredit(toWallet, tx)
 
-	return nil
+	return tx, nil
 }
 
 // GetBalance returns the current balance of a user's wallet as float64
@@ -258,39 +473,117 @@ func (ws *WalletService) GetBalance(userID string) (float64, error) {
 	return balanceFloat, nil
 }
 
-// GetBalanceDecimal returns the current balance of a user's wallet as decimal.Decimal
+// GetBalanceDecimal returns the current balance of a user's wallet as decimal.Decimal.
+// It reads the wallet's published view and never blocks on writers.
 func (ws *WalletService) GetBalanceDecimal(userID string) (decimal.Decimal, error) {
+	return ws.GetBalanceContext(context.Background(), userID)
+}
+
+// GetBalanceContext is GetBalanceDecimal on behalf of a caller's ctx. When ctx carries a
+// session token the balance reflects the writes it covers.
+func (ws *WalletService) GetBalanceContext(ctx context.Context, userID string) (decimal.Decimal, error) {
+	if err := ctx.Err(); err != nil {
+		return decimal.Zero, err
+	}
+	if err := ws.awaitContextSession(ctx); err != nil {
+		return decimal.Zero, err
+	}
+	view, _, err := ws.loadView(userID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return view.balance, nil
+}
+
+// GetBalances returns the balances of many users' wallets, resolving them all under one
+// acquisition of the service lock. It fails with ErrUserNotFound naming the first ID
+// without a wallet.
+func (ws *WalletService) GetBalances(userIDs []string) (map[string]decimal.Decimal, error) {
+	balances := make(map[string]decimal.Decimal, len(userIDs))
+
 	ws.mu.RLock()
 	defer ws.mu.RUnlock()
+	for _, userID := range userIDs {
+		wallet, exists := ws.wallets[userID]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+		}
+		balances[userID] = wallet.view.Load().balance
+	}
+	return balances, nil
+}
 
+// GetBalanceAt returns the balance of a user's wallet as it stood at the end of the
+// second at, replaying the transaction log including archived entries. Pending entries
+// never count: settling one records a separate completed entry at the settle time, so
+// its funds count from then on.
+func (ws *WalletService) GetBalanceAt(userID string, at time.Time) (decimal.Decimal, error) {
+	ws.mu.RLock()
 	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
 	if !exists {
 		return decimal.Zero, ErrUserNotFound
 	}
 
 	wallet.mu.RLock()
-	defer wallet.mu.RUnlock()
+	currency := wallet.Currency
+	wallet.mu.RUnlock()
+
+	it := ws.iterate(userID, IterateOptions{Until: at.Unix() + 1})
+	defer it.Close()
 
-	return wallet.Balance, nil
+	balance := decimal.Zero
+	for it.Next() {
+		balance = balance.Add(it.Transaction().balanceEffect(userID, currency))
+	}
+	if err := it.Err(); err != nil {
+		return decimal.Zero, err
+	}
+	return balance, nil
 }
 
-// GetTransactionHistory returns all transactions for a specific user
-func (ws *WalletService) GetTransactionHistory(userID string) ([]*Transaction, error) {
+// GetWallet returns a copy of userID's wallet: base currency, balances and settings
+func (ws *WalletService) GetWallet(userID string) (*WalletSnapshot, error) {
 	ws.mu.RLock()
-	defer ws.mu.RUnlock()
-
-	if _, exists := ws.users[userID]; !exists {
+	wallet, exists := ws.wallets[userID]
+	ws.mu.RUnlock()
+	if !exists {
 		return nil, ErrUserNotFound
 	}
 
+	wallet.mu.RLock()
+	defer wallet.mu.RUnlock()
+	snap := wallet.snapshot()
+	return &snap, nil
+}
+
+// GetTransactionHistory returns all transactions for a specific user, including archived ones
+func (ws *WalletService) GetTransactionHistory(userID string) ([]*Transaction, error) {
+	return ws.GetTransactionHistoryContext(context.Background(), userID)
+}
+
+// GetTransactionHistoryContext is GetTransactionHistory with the archive lookup bounded
+// by ctx and the archive layer budget. A cancelled ctx stops the read between entries,
+// and a session token carried by ctx is waited for first.
+func (ws *WalletService) GetTransactionHistoryContext(ctx context.Context, userID string) ([]*Transaction, error) {
+	if err := ws.awaitContextSession(ctx); err != nil {
+		return nil, err
+	}
+	it, err := ws.IterateTransactionsContext(ctx, userID, IterateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
 	var userTransactions []*Transaction
-	for _, tx := range ws.transactions {
-		if tx.FromUserID == userID || tx.ToUserID == userID {
-			userTransactions = append(userTransactions, tx)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		userTransactions = append(userTransactions, it.Transaction())
 	}
 
-	return userTransactions, nil
+	return userTransactions, it.Err()
 }
 
 // GetAllUsers returns a list of all users in the system
@@ -307,7 +600,7 @@ func (ws *WalletService) GetAllUsers() []*User {
 }
 
 // getOrderedLocks returns locks for two users in consistent order to prevent deadlocks
-func (ws *WalletService) getOrderedLocks(userID1, userID2 string) (*sync.Mutex, *sync.Mutex) {
+func (ws *WalletService) getOrderedLocks(userID1, userID2 string) (*priorityLock, *priorityLock) {
 	lock1 := ws.userLocks.getLock(userID1)
 	lock2 := ws.userLocks.getLock(userID2)
 
@@ -318,14 +611,83 @@ func (ws *WalletService) getOrderedLocks(userID1, userID2 string) (*sync.Mutex,
 	return lock2, lock1
 }
 
+// lockUsers acquires the locks of all given users in lane p in alphabetical order, the
+// same order getOrderedLocks uses, and returns a function that releases them
+func (ws *WalletService) lockUsers(p Priority, userIDs ...string) func() {
+	ids := make([]string, 0, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	locks := make([]*priorityLock, len(ids))
+	for i, id := range ids {
+		locks[i] = ws.userLocks.getLock(id)
+		locks[i].LockAt(p)
+	}
+
+	return func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+	}
+}
+
 // recordTransaction safely adds a transaction to the history
 func (ws *WalletService) recordTransaction(tx *Transaction) {
+	if ws.batcher.enabled {
+		ws.appendBatched(tx)
+	} else {
+		ws.appendToLog([]*Transaction{tx})
+	}
+	ws.sendReceipts(tx)
+}
+
+// appendToLog appends txs to the log in order, writing them through to the store and
+// updating the indexes, supply and event log, all under one hold of ws.mu
+func (ws *WalletService) appendToLog(txs []*Transaction) {
 	ws.mu.Lock()
-	defer ws.mu.Unlock()
-	ws.transactions = append(ws.transactions, tx)
+	ws.persistTransactions(txs)
+	for _, tx := range txs {
+		ws.transactions = append(ws.transactions, tx)
+		ws.retention.hotBytes += transactionSize(tx)
+		ws.indexTransaction(tx)
+		ws.supply.apply(tx)
+		ws.activity.apply(tx, ws.users)
+		ws.trackSpending(tx)
+
+		// Emitting under ws.mu keeps event order identical to log order
+		ws.emitTransaction(tx)
+	}
+	over := ws.overRetention()
+	ws.mu.Unlock()
+	ws.sessions.advance()
+
+	if over {
+		ws.enforceRetention()
+	}
+}
+
+// copyMetadata returns a copy of m, or nil when m is empty
+func copyMetadata(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
 }
 
-// generateTransactionID creates a unique transaction ID
-func generateTransactionID() string {
-	return fmt.Sprintf("tx_%d", time.Now().UnixNano())
+// idSequence disambiguates IDs generated within the same nanosecond
+var idSequence atomic.Uint64
+
+// generateID creates a unique identifier with the given prefix
+func generateID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().UnixNano(), idSequence.Add(1))
 }
@@ -2,8 +2,10 @@
 package wallet
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -540,6 +542,139 @@ func TestWalletService_WithdrawDecimal(t *testing.T) {
 	}
 }
 
+// TestWalletService_TransferWithFloor tests that transfers keep the sender above a floor
+func TestWalletService_TransferWithFloor(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      string
+		floor       string
+		wantErr     error
+		wantBalance string
+	}{
+		{"stays above floor", "30", "50", nil, "70"},
+		{"lands exactly on floor", "50", "50", nil, "50"},
+		{"would breach floor", "51", "50", ErrBelowFloor, "100"},
+		{"insufficient funds", "150", "0", ErrInsufficientBalance, "100"},
+		{"negative floor", "10", "-1", ErrInvalidAmount, "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := NewWalletService()
+			ws.CreateUser("user1", "John Doe", "john@example.com")
+			ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+			ws.Deposit("user1", 100, "initial deposit")
+
+			_, err := ws.TransferWithFloor("user1", "user2", decimal.RequireFromString(tt.amount), decimal.RequireFromString(tt.floor))
+			if err != tt.wantErr {
+				t.Errorf("TransferWithFloor() error = %v, want %v", err, tt.wantErr)
+			}
+			if balance, _ := ws.GetBalanceDecimal("user1"); !balance.Equal(decimal.RequireFromString(tt.wantBalance)) {
+				t.Errorf("Expected balance %s, got %s", tt.wantBalance, balance.String())
+			}
+		})
+	}
+
+	// Concurrent transfers racing for the same buffer never breach it together
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 100, "initial deposit")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ws.TransferWithFloor("user1", "user2", decimal.NewFromInt(20), decimal.NewFromInt(30))
+		}()
+	}
+	wg.Wait()
+
+	if balance, _ := ws.GetBalanceDecimal("user1"); !balance.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("Expected balance 40 after racing transfers, got %s", balance.String())
+	}
+}
+
+// TestWalletService_GetBalanceAt tests reading balances as of past times
+func TestWalletService_GetBalanceAt(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+
+	start := clock.Now()
+	ws.Deposit("user1", 100, "salary")
+	clock.Advance(24 * time.Hour)
+	ws.Transfer("user1", "user2", 30, "rent")
+	clock.Advance(24 * time.Hour)
+	ws.Withdraw("user1", 20, "cash")
+
+	tests := []struct {
+		name    string
+		userID  string
+		at      time.Time
+		want    int64
+		wantErr error
+	}{
+		{"before any activity", "user1", start.Add(-time.Second), 0, nil},
+		{"same second as deposit", "user1", start, 100, nil},
+		{"after transfer", "user1", start.Add(36 * time.Hour), 70, nil},
+		{"now", "user1", clock.Now(), 50, nil},
+		{"receiving side", "user2", start.Add(36 * time.Hour), 30, nil},
+		{"unknown user", "ghost", clock.Now(), 0, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ws.GetBalanceAt(tt.userID, tt.at)
+			if err != tt.wantErr {
+				t.Fatalf("GetBalanceAt() error = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Equal(decimal.NewFromInt(tt.want)) {
+				t.Errorf("GetBalanceAt() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_GetBalances(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+	ws.CreateUser("user2", "Jane Smith", "jane@example.com")
+	ws.Deposit("user1", 100, "salary")
+	ws.Transfer("user1", "user2", 30, "rent")
+
+	tests := []struct {
+		name    string
+		userIDs []string
+		want    map[string]int64
+		wantErr error
+	}{
+		{"both", []string{"user1", "user2"}, map[string]int64{"user1": 70, "user2": 30}, nil},
+		{"duplicates", []string{"user2", "user2"}, map[string]int64{"user2": 30}, nil},
+		{"none", nil, map[string]int64{}, nil},
+		{"unknown user", []string{"user1", "ghost"}, nil, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ws.GetBalances(tt.userIDs)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("GetBalances() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetBalances() = %v, want %v", got, tt.want)
+			}
+			for userID, want := range tt.want {
+				if !got[userID].Equal(decimal.NewFromInt(want)) {
+					t.Errorf("GetBalances()[%s] = %s, want %d", userID, got[userID], want)
+				}
+			}
+		})
+	}
+}
+
 // BenchmarkWalletService_ConcurrentTransfers benchmarks transfer performance
 func BenchmarkWalletService_ConcurrentTransfers(b *testing.B) {
 	ws := NewWalletService()
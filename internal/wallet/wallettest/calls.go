@@ -0,0 +1,42 @@
+// internal/wallet/wallettest/calls.go
+package wallettest
+
+import "errors"
+
+// ErrNotConfigured is returned by MockService methods whose function is not set
+var ErrNotConfigured = errors.New("wallettest: mock method not configured")
+
+// Call is one recorded MockService method call
+type Call struct {
+	Method string
+	Args   []any // variadic arguments are recorded as one slice
+}
+
+// Calls returns the recorded calls, oldest first; only those to method when it is
+// not empty
+func (mock *MockService) Calls(method string) []Call {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	var calls []Call
+	for _, c := range mock.calls {
+		if method == "" || c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls
+func (mock *MockService) Reset() {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.calls = nil
+}
+
+// record stores one call
+func (mock *MockService) record(method string, args ...any) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	mock.calls = append(mock.calls, Call{Method: method, Args: args})
+}
@@ -0,0 +1,8 @@
+// internal/wallet/wallettest/doc.go
+
+// Package wallettest provides test doubles for code that depends on wallet.Service:
+// MockService, generated from the service's methods, and Fake, a simplified
+// in-memory wallet.
+package wallettest
+
+//go:generate go run ./gen
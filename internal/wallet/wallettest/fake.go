@@ -0,0 +1,278 @@
+// internal/wallet/wallettest/fake.go
+package wallettest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// Fake is a simplified in-memory wallet.Service. It keeps users, single-currency
+// balances and their history, and moves money with deposits, withdrawals and
+// transfers, none of which wallet's limits, holds or validators apply to. Every other
+// method falls through to the embedded MockService, where tests can stub it.
+type Fake struct {
+	MockService
+
+	// Now stamps transactions; time.Now when nil
+	Now func() time.Time
+
+	mu       sync.Mutex
+	users    map[string]*wallet.User
+	balances map[string]decimal.Decimal
+	history  []*wallet.Transaction
+	seq      int
+}
+
+var _ wallet.Service = (*Fake)(nil)
+
+// NewFake returns an empty Fake
+func NewFake() *Fake {
+	return &Fake{
+		users:    make(map[string]*wallet.User),
+		balances: make(map[string]decimal.Decimal),
+	}
+}
+
+// CreateUser adds a user with an empty balance
+func (f *Fake) CreateUser(userID, name, email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.users[userID]; exists {
+		return wallet.ErrUserAlreadyExists
+	}
+	for _, u := range f.users {
+		if email != "" && u.Email == email {
+			return wallet.ErrEmailTaken
+		}
+	}
+	f.users[userID] = &wallet.User{ID: userID, Name: name, Email: email}
+	f.balances[userID] = decimal.Zero
+	return nil
+}
+
+// CreateUserContext is CreateUser unless ctx is done
+func (f *Fake) CreateUserContext(ctx context.Context, userID, name, email string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.CreateUser(userID, name, email)
+}
+
+// GetUserByEmail returns the user registered with email
+func (f *Fake) GetUserByEmail(email string) (*wallet.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, u := range f.users {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, wallet.ErrUserNotFound
+}
+
+// Deposit credits userID
+func (f *Fake) Deposit(userID string, amount float64, description string) error {
+	return f.DepositDecimal(userID, decimal.NewFromFloat(amount), description)
+}
+
+// DepositDecimal credits userID
+func (f *Fake) DepositDecimal(userID string, amount decimal.Decimal, description string) error {
+	_, err := f.move("", userID, amount, wallet.TransactionDeposit, description)
+	return err
+}
+
+// DepositContext is DepositDecimal unless ctx is done
+func (f *Fake) DepositContext(ctx context.Context, userID string, amount decimal.Decimal, description string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.DepositDecimal(userID, amount, description)
+}
+
+// Withdraw debits userID
+func (f *Fake) Withdraw(userID string, amount float64, description string) error {
+	return f.WithdrawDecimal(userID, decimal.NewFromFloat(amount), description)
+}
+
+// WithdrawDecimal debits userID
+func (f *Fake) WithdrawDecimal(userID string, amount decimal.Decimal, description string) error {
+	_, err := f.move(userID, "", amount, wallet.TransactionWithdraw, description)
+	return err
+}
+
+// WithdrawContext is WithdrawDecimal unless ctx is done
+func (f *Fake) WithdrawContext(ctx context.Context, userID string, amount decimal.Decimal, description string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.WithdrawDecimal(userID, amount, description)
+}
+
+// Transfer moves amount from fromUserID to toUserID
+func (f *Fake) Transfer(fromUserID, toUserID string, amount float64, description string) error {
+	return f.TransferDecimal(fromUserID, toUserID, decimal.NewFromFloat(amount), description)
+}
+
+// TransferDecimal moves amount from fromUserID to toUserID
+func (f *Fake) TransferDecimal(fromUserID, toUserID string, amount decimal.Decimal, description string) error {
+	if fromUserID == toUserID {
+		return wallet.ErrSameUserTransfer
+	}
+	_, err := f.move(fromUserID, toUserID, amount, wallet.TransactionTransfer, description)
+	return err
+}
+
+// TransferContext is TransferDecimal unless ctx is done
+func (f *Fake) TransferContext(ctx context.Context, fromUserID, toUserID string, amount decimal.Decimal, description string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.TransferDecimal(fromUserID, toUserID, amount, description)
+}
+
+// GetBalance returns userID's balance as a float64
+func (f *Fake) GetBalance(userID string) (float64, error) {
+	balance, err := f.GetBalanceDecimal(userID)
+	if err != nil {
+		return 0, err
+	}
+	balanceFloat, _ := balance.Float64()
+	return balanceFloat, nil
+}
+
+// GetBalanceDecimal returns userID's balance
+func (f *Fake) GetBalanceDecimal(userID string) (decimal.Decimal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	balance, exists := f.balances[userID]
+	if !exists {
+		return decimal.Zero, wallet.ErrUserNotFound
+	}
+	return balance, nil
+}
+
+// GetBalanceContext is GetBalanceDecimal unless ctx is done
+func (f *Fake) GetBalanceContext(ctx context.Context, userID string) (decimal.Decimal, error) {
+	if err := ctx.Err(); err != nil {
+		return decimal.Zero, err
+	}
+	return f.GetBalanceDecimal(userID)
+}
+
+// GetWallet returns a copy of userID's wallet
+func (f *Fake) GetWallet(userID string) (*wallet.WalletSnapshot, error) {
+	balance, err := f.GetBalanceDecimal(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet.WalletSnapshot{UserID: userID, Currency: wallet.DefaultCurrency, Balance: balance}, nil
+}
+
+// GetBalances returns the balances of userIDs
+func (f *Fake) GetBalances(userIDs []string) (map[string]decimal.Decimal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	balances := make(map[string]decimal.Decimal, len(userIDs))
+	for _, userID := range userIDs {
+		balance, exists := f.balances[userID]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", wallet.ErrUserNotFound, userID)
+		}
+		balances[userID] = balance
+	}
+	return balances, nil
+}
+
+// GetTransaction returns the transaction with txID
+func (f *Fake) GetTransaction(txID string) (*wallet.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, tx := range f.history {
+		if tx.ID == txID {
+			copied := *tx
+			return &copied, nil
+		}
+	}
+	return nil, wallet.ErrTransactionNotFound
+}
+
+// GetTransactionHistory returns the transactions to or from userID, oldest first
+func (f *Fake) GetTransactionHistory(userID string) ([]*wallet.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.users[userID]; !exists {
+		return nil, wallet.ErrUserNotFound
+	}
+	var history []*wallet.Transaction
+	for _, tx := range f.history {
+		if tx.FromUserID == userID || tx.ToUserID == userID {
+			copied := *tx
+			history = append(history, &copied)
+		}
+	}
+	return history, nil
+}
+
+// GetTransactionHistoryContext is GetTransactionHistory unless ctx is done
+func (f *Fake) GetTransactionHistoryContext(ctx context.Context, userID string) ([]*wallet.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.GetTransactionHistory(userID)
+}
+
+// move debits from and credits to, either of which may be empty, and records the
+// transaction
+func (f *Fake) move(from, to string, amount decimal.Decimal, txType wallet.TransactionType, description string) (*wallet.Transaction, error) {
+	if !amount.IsPositive() {
+		return nil, wallet.ErrInvalidAmount
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, userID := range []string{from, to} {
+		if _, exists := f.balances[userID]; userID != "" && !exists {
+			return nil, wallet.ErrUserNotFound
+		}
+	}
+	if from != "" {
+		if f.balances[from].LessThan(amount) {
+			return nil, wallet.ErrInsufficientBalance
+		}
+		f.balances[from] = f.balances[from].Sub(amount)
+	}
+	if to != "" {
+		f.balances[to] = f.balances[to].Add(amount)
+	}
+
+	now := time.Now
+	if f.Now != nil {
+		now = f.Now
+	}
+	f.seq++
+	tx := &wallet.Transaction{
+		ID:          fmt.Sprintf("fake-tx-%d", f.seq),
+		FromUserID:  from,
+		ToUserID:    to,
+		Amount:      amount,
+		Currency:    wallet.DefaultCurrency,
+		Type:        txType,
+		Description: description,
+		Timestamp:   now().Unix(),
+	}
+	f.history = append(f.history, tx)
+	return tx, nil
+}
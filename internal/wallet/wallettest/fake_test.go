@@ -0,0 +1,72 @@
+// internal/wallet/wallettest/fake_test.go
+package wallettest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+func TestFake_MovesMoney(t *testing.T) {
+	f := NewFake()
+	f.CreateUser("alice", "Alice", "a@example.com")
+	f.CreateUser("bob", "Bob", "b@example.com")
+
+	tests := []struct {
+		name    string
+		op      func() error
+		wantErr error
+		alice   int64
+		bob     int64
+	}{
+		{"deposit", func() error { return f.Deposit("alice", 100, "salary") }, nil, 100, 0},
+		{"transfer", func() error { return f.Transfer("alice", "bob", 30, "rent") }, nil, 70, 30},
+		{"withdraw", func() error { return f.Withdraw("bob", 10, "cash") }, nil, 70, 20},
+		{"overdraw", func() error { return f.Withdraw("bob", 21, "cash") }, wallet.ErrInsufficientBalance, 70, 20},
+		{"to self", func() error { return f.Transfer("bob", "bob", 1, "loop") }, wallet.ErrSameUserTransfer, 70, 20},
+		{"zero amount", func() error { return f.Deposit("bob", 0, "nothing") }, wallet.ErrInvalidAmount, 70, 20},
+		{"unknown user", func() error { return f.Transfer("alice", "ghost", 1, "gift") }, wallet.ErrUserNotFound, 70, 20},
+		{"duplicate user", func() error { return f.CreateUser("bob", "Bob", "other@example.com") }, wallet.ErrUserAlreadyExists, 70, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			got, err := f.GetBalances([]string{"alice", "bob"})
+			if err != nil {
+				t.Fatalf("GetBalances() error = %v", err)
+			}
+			if !got["alice"].Equal(decimal.NewFromInt(tt.alice)) || !got["bob"].Equal(decimal.NewFromInt(tt.bob)) {
+				t.Errorf("balances = %v, want alice %d, bob %d", got, tt.alice, tt.bob)
+			}
+		})
+	}
+
+	history, err := f.GetTransactionHistory("bob")
+	if err != nil || len(history) != 2 || history[0].Type != wallet.TransactionTransfer || history[1].Type != wallet.TransactionWithdraw {
+		t.Errorf("GetTransactionHistory(bob) = %v, %v; want the transfer and the withdrawal", history, err)
+	}
+}
+
+func TestFake_StubsOtherOperations(t *testing.T) {
+	f := NewFake()
+	f.CreateUser("alice", "Alice", "a@example.com")
+
+	// Operations the fake does not model fall through to the mock
+	if _, err := f.GetBalanceAt("alice", time.Now()); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("GetBalanceAt() error = %v, want %v", err, ErrNotConfigured)
+	}
+	f.GetBalanceAtFunc = func(userID string, at time.Time) (decimal.Decimal, error) {
+		return decimal.NewFromInt(42), nil
+	}
+	if got, err := f.GetBalanceAt("alice", time.Now()); err != nil || !got.Equal(decimal.NewFromInt(42)) {
+		t.Errorf("stubbed GetBalanceAt() = %s, %v; want 42", got, err)
+	}
+	if calls := f.Calls("GetBalanceAt"); len(calls) != 2 || calls[1].Args[0] != "alice" {
+		t.Errorf("Calls(GetBalanceAt) = %+v, want both calls recorded", calls)
+	}
+}
@@ -0,0 +1,385 @@
+// internal/wallet/wallettest/gen/main.go
+
+// Command gen writes the wallet.Service interface and wallettest.MockService from the
+// exported methods of wallet.WalletService. Run it with go generate in wallettest.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	walletPath  = "wallet-app/internal/wallet"
+	serviceFile = "service.go"
+	mockFile    = "mock.go"
+	receiver    = "mock"
+)
+
+func main() {
+	service, mock, err := generate("..")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("..", serviceFile), service, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(mockFile, mock, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// param is one parameter or result of a method
+type param struct {
+	name     string
+	typ      ast.Expr
+	variadic bool
+}
+
+// method is one exported WalletService method
+type method struct {
+	name    string
+	params  []param
+	results []param
+}
+
+// generate reads the wallet package in dir and returns the contents of service.go and
+// mock.go
+func generate(dir string) (service, mock []byte, err error) {
+	methods, imports, err := parseMethods(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if service, err = writeService(methods, imports); err != nil {
+		return nil, nil, err
+	}
+	if mock, err = writeMock(methods, imports); err != nil {
+		return nil, nil, err
+	}
+	return service, mock, nil
+}
+
+// parseMethods collects the exported methods of *WalletService in dir, sorted by name,
+// and the import path of every package their signatures use, by package name
+func parseMethods(dir string) ([]method, map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+
+	fset := token.NewFileSet()
+	var methods []method
+	imports := make(map[string]string)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == serviceFile {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, err
+		}
+		fileImports := importNames(file)
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() || !isServiceReceiver(fn.Recv) {
+				continue
+			}
+			m := method{name: fn.Name.Name, params: fieldList(fn.Type.Params), results: fieldList(fn.Type.Results)}
+			for _, p := range append(append([]param(nil), m.params...), m.results...) {
+				if err := checkType(p.typ, fileImports, imports); err != nil {
+					return nil, nil, fmt.Errorf("%s: %s: %w", filepath.Base(path), m.name, err)
+				}
+			}
+			methods = append(methods, m)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].name < methods[j].name })
+	return methods, imports, nil
+}
+
+// writeService renders service.go
+func writeService(methods []method, imports map[string]string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// internal/wallet/service.go\n")
+	b.WriteString("// Code generated by wallettest/gen. DO NOT EDIT.\n\n")
+	b.WriteString("package wallet\n\n")
+	writeImports(&b, imports, nil)
+	b.WriteString("// Service is every public operation of WalletService, for code that depends on the\n")
+	b.WriteString("// wallet and wants to swap in a test double from the wallettest package\n")
+	b.WriteString("type Service interface {\n")
+	for _, m := range methods {
+		fmt.Fprintf(&b, "\t%s(%s) %s\n", m.name, paramList(m.params, false, true), resultList(m.results, false))
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("var _ Service = (*WalletService)(nil)\n")
+	return format.Source(b.Bytes())
+}
+
+// writeMock renders mock.go
+func writeMock(methods []method, imports map[string]string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// internal/wallet/wallettest/mock.go\n")
+	b.WriteString("// Code generated by wallettest/gen. DO NOT EDIT.\n\n")
+	b.WriteString("package wallettest\n\n")
+	writeImports(&b, imports, []string{"sync", walletPath})
+	b.WriteString("// MockService is a wallet.Service whose methods call the function field named after\n")
+	b.WriteString("// them with Func appended, recording each call. A method whose function is unset\n")
+	b.WriteString("// returns zero values and, in place of any error, ErrNotConfigured.\n")
+	b.WriteString("type MockService struct {\n")
+	b.WriteString("\tmu    sync.Mutex\n\tcalls []Call\n\n")
+	for _, m := range methods {
+		fmt.Fprintf(&b, "\t%sFunc func(%s) %s\n", m.name, paramList(m.params, true, true), resultList(m.results, true))
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("var _ wallet.Service = (*MockService)(nil)\n")
+
+	for _, m := range methods {
+		names := make([]string, len(m.params))
+		args := make([]string, len(m.params))
+		for i, p := range m.params {
+			names[i] = p.name
+			args[i] = p.name
+			if p.variadic {
+				args[i] += "..."
+			}
+		}
+		fmt.Fprintf(&b, "\n// %s calls %sFunc\n", m.name, m.name)
+		fmt.Fprintf(&b, "func (%s *MockService) %s(%s) %s {\n", receiver, m.name, paramList(m.params, true, true), resultList(m.results, true))
+		fmt.Fprintf(&b, "\t%s.record(%s)\n", receiver, strings.Join(append([]string{strconv.Quote(m.name)}, names...), ", "))
+		fmt.Fprintf(&b, "\tif %s.%sFunc == nil {\n", receiver, m.name)
+		zeros := make([]string, len(m.results))
+		for i, r := range m.results {
+			if ident, ok := r.typ.(*ast.Ident); ok && ident.Name == "error" {
+				zeros[i] = "ErrNotConfigured"
+				continue
+			}
+			zeros[i] = fmt.Sprintf("r%d", i)
+			fmt.Fprintf(&b, "\t\tvar r%d %s\n", i, typeString(r.typ, true))
+		}
+		if len(zeros) == 0 {
+			b.WriteString("\t\treturn\n\t}\n")
+			fmt.Fprintf(&b, "\t%s.%sFunc(%s)\n}\n", receiver, m.name, strings.Join(args, ", "))
+			continue
+		}
+		fmt.Fprintf(&b, "\t\treturn %s\n\t}\n", strings.Join(zeros, ", "))
+		fmt.Fprintf(&b, "\treturn %s.%sFunc(%s)\n}\n", receiver, m.name, strings.Join(args, ", "))
+	}
+	return format.Source(b.Bytes())
+}
+
+// writeImports writes an import block of the packages used and the extra paths
+func writeImports(b *bytes.Buffer, imports map[string]string, extra []string) {
+	var std, other []string
+	seen := make(map[string]bool)
+	paths := append([]string(nil), extra...)
+	for _, path := range imports {
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") || strings.HasPrefix(path, "wallet-app/") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	b.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(b, "\t%q\n", path)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(b, "\t%q\n", path)
+	}
+	b.WriteString(")\n\n")
+}
+
+// paramList renders parameters with their names
+func paramList(params []param, qualify, named bool) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		typ := typeString(p.typ, qualify)
+		if p.variadic {
+			typ = "..." + typ
+		}
+		parts[i] = typ
+		if named {
+			parts[i] = p.name + " " + typ
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resultList renders results without names
+func resultList(results []param, qualify bool) string {
+	switch len(results) {
+	case 0:
+		return ""
+	case 1:
+		return paramList(results, qualify, false)
+	}
+	return "(" + paramList(results, qualify, false) + ")"
+}
+
+// fieldList flattens a parameter or result list, naming every entry
+func fieldList(fields *ast.FieldList) []param {
+	if fields == nil {
+		return nil
+	}
+	var params []param
+	for _, f := range fields.List {
+		typ, variadic := f.Type, false
+		if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+			typ, variadic = ellipsis.Elt, true
+		}
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "_"}}
+		}
+		for _, n := range names {
+			name := n.Name
+			switch name {
+			case "_":
+				name = fmt.Sprintf("p%d", len(params))
+			case receiver, "wallet", "sync":
+				name += "Arg"
+			}
+			params = append(params, param{name: name, typ: typ, variadic: variadic})
+		}
+	}
+	return params
+}
+
+// isServiceReceiver reports whether recv is *WalletService
+func isServiceReceiver(recv *ast.FieldList) bool {
+	if recv == nil || len(recv.List) != 1 {
+		return false
+	}
+	star, ok := recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "WalletService"
+}
+
+// importNames maps the package names file imports to their paths
+func importNames(file *ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		names[name] = path
+	}
+	return names
+}
+
+// checkType fails for types a package outside wallet cannot name and records the
+// imports typ needs
+func checkType(typ ast.Expr, fileImports, imports map[string]string) error {
+	var err error
+	ast.Inspect(typ, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			pkg := n.X.(*ast.Ident).Name
+			imports[pkg] = fileImports[pkg]
+			return false
+		case *ast.Ident:
+			if !n.IsExported() && types.Universe.Lookup(n.Name) == nil && err == nil {
+				err = fmt.Errorf("unexported type %s in signature", n.Name)
+			}
+		case *ast.Field:
+			// Parameter names inside func types are not types
+			ast.Inspect(n.Type, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					pkg := sel.X.(*ast.Ident).Name
+					imports[pkg] = fileImports[pkg]
+					return false
+				}
+				if id, ok := n.(*ast.Ident); ok && !id.IsExported() && types.Universe.Lookup(id.Name) == nil && err == nil {
+					err = fmt.Errorf("unexported type %s in signature", id.Name)
+				}
+				return true
+			})
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// typeString renders typ, qualifying the wallet package's own types when qualify is set
+func typeString(typ ast.Expr, qualify bool) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if qualify && t.IsExported() {
+			return "wallet." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.X.(*ast.Ident).Name + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X, qualify)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + typeString(t.Elt, qualify)
+		}
+		return "[" + t.Len.(*ast.BasicLit).Value + "]" + typeString(t.Elt, qualify)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key, qualify) + "]" + typeString(t.Value, qualify)
+	case *ast.Ellipsis:
+		return "..." + typeString(t.Elt, qualify)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + typeString(t.Value, qualify)
+		case ast.RECV:
+			return "<-chan " + typeString(t.Value, qualify)
+		}
+		return "chan " + typeString(t.Value, qualify)
+	case *ast.FuncType:
+		params := fieldList(t.Params)
+		named := len(t.Params.List) > 0 && len(t.Params.List[0].Names) > 0
+		return "func(" + paramList(params, qualify, named) + ")" + prefixSpace(resultList(fieldList(t.Results), qualify))
+	case *ast.InterfaceType:
+		if len(t.Methods.List) == 0 {
+			return "interface{}"
+		}
+	case *ast.StructType:
+		if len(t.Fields.List) == 0 {
+			return "struct{}"
+		}
+	}
+	panic(fmt.Sprintf("gen: unsupported type %T", typ))
+}
+
+// prefixSpace puts a space before s unless it is empty
+func prefixSpace(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " " + s
+}
@@ -0,0 +1,30 @@
+// internal/wallet/wallettest/gen/main_test.go
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedFilesUpToDate fails when WalletService gained or changed a method
+// without go generate being rerun
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	service, mock, err := generate(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	for path, want := range map[string][]byte{
+		filepath.Join("..", "..", serviceFile): service,
+		filepath.Join("..", mockFile):          mock,
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is stale; run go generate in internal/wallet/wallettest", path)
+		}
+	}
+}
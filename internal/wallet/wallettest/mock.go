@@ -0,0 +1,4148 @@
+// internal/wallet/wallettest/mock.go
+// Code generated by wallettest/gen. DO NOT EDIT.
+
+package wallettest
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// MockService is a wallet.Service whose methods call the function field named after
+// them with Func appended, recording each call. A method whose function is unset
+// returns zero values and, in place of any error, ErrNotConfigured.
+type MockService struct {
+	mu    sync.Mutex
+	calls []Call
+
+	AcceptConsentFunc                    func(userID string, kind wallet.ConsentKind, version string) error
+	AccrueInterestNowFunc                func() ([]*wallet.InterestStatement, error)
+	AckWebhookFunc                       func(subscriptionID string, ackToken string) error
+	ActiveUsersFunc                      func(from time.Time, to time.Time, g wallet.Granularity) ([]wallet.ActivityBucket, error)
+	AddCaseNoteFunc                      func(caseID string, author string, text string) error
+	AddFavoriteFunc                      func(userID string, spec wallet.FavoriteSpec) (*wallet.Favorite, error)
+	AddOrgMemberFunc                     func(orgID string, userID string, role wallet.OrgRole) error
+	AddWithdrawalDestinationFunc         func(userID string, kind wallet.DestinationKind, reference string, label string) (*wallet.WithdrawalDestination, error)
+	AdminAdjustFunc                      func(userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, actorID string) (*wallet.Transaction, error)
+	AdminAdjustContextFunc               func(ctx context.Context, userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, actorID string) (*wallet.Transaction, error)
+	AdminTransferFunc                    func(fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	AdminTransferContextFunc             func(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	AnnotateTransactionFunc              func(authorID string, txID string, text string, visibility wallet.AnnotationVisibility) (*wallet.Annotation, error)
+	AnnotateUserFunc                     func(authorID string, userID string, text string, visibility wallet.AnnotationVisibility) (*wallet.Annotation, error)
+	ApplyFederationReceiptFunc           func(receipt wallet.FederationReceipt) (*wallet.OutboundTransfer, error)
+	ApplyFederationReceiptContextFunc    func(ctx context.Context, receipt wallet.FederationReceipt) (*wallet.OutboundTransfer, error)
+	ApproveAdjustmentFunc                func(requestID string, checkerID string, comment string) (*wallet.AdjustmentRequest, error)
+	ApproveAdjustmentContextFunc         func(ctx context.Context, requestID string, checkerID string, comment string) (*wallet.AdjustmentRequest, error)
+	ArchiveTransactionsBeforeFunc        func(cutoff int64) (int, error)
+	ArchiveTransactionsBeforeContextFunc func(ctx context.Context, cutoff int64) (int, error)
+	AssignCaseFunc                       func(caseID string, assignee string) error
+	AssignMinimumBalanceTierFunc         func(userID string, tier string) error
+	AttachCaseEvidenceFunc               func(caseID string, evidence wallet.CaseEvidence) error
+	AuditConversionFunc                  func(txID string) (*wallet.ConversionAudit, error)
+	AuthorizeCardFunc                    func(req wallet.CardAuthRequest) (*wallet.CardAuthorization, error)
+	AuthorizeCardContextFunc             func(ctx context.Context, req wallet.CardAuthRequest) (*wallet.CardAuthorization, error)
+	AwaitSessionFunc                     func(ctx context.Context, token wallet.SessionToken) error
+	BackupFunc                           func(w io.Writer) error
+	BackupBinaryFunc                     func(w io.Writer) error
+	BackupOnlineFunc                     func(w io.Writer) error
+	BatchPayoutFunc                      func(fromUserID string, payouts []wallet.Payout, description string) ([]*wallet.Transaction, error)
+	BatchPayoutContextFunc               func(ctx context.Context, fromUserID string, payouts []wallet.Payout, description string) ([]*wallet.Transaction, error)
+	BlockUserFunc                        func(userID string, blockedUserID string) error
+	BurnFunc                             func(fromUserID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	BurnContextFunc                      func(ctx context.Context, fromUserID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	CancelCardFunc                       func(cardID string, userID string) error
+	CancelConversionOrderFunc            func(orderID string, userID string) error
+	CancelGiftFunc                       func(giftID string, senderID string) error
+	CancelJobFunc                        func(jobID string) error
+	CancelPaymentLinkFunc                func(linkID string, recipientID string) error
+	CancelScheduledJobFunc               func(jobID string, actor string, reason string) error
+	CancelWalletClosureFunc              func(userID string) error
+	CaptureAuthorizationFunc             func(authID string, amount decimal.Decimal) (*wallet.CardAuthorization, error)
+	CaptureAuthorizationContextFunc      func(ctx context.Context, authID string, amount decimal.Decimal) (*wallet.CardAuthorization, error)
+	CaptureHoldFunc                      func(holdID string, amount decimal.Decimal) (*wallet.Hold, error)
+	CaptureHoldContextFunc               func(ctx context.Context, holdID string, amount decimal.Decimal) (*wallet.Hold, error)
+	ChargeFunc                           func(req wallet.ChargeRequest) (*wallet.ChargeReceipt, error)
+	ChargeContextFunc                    func(ctx context.Context, req wallet.ChargeRequest) (*wallet.ChargeReceipt, error)
+	CheckHealthFunc                      func() wallet.HealthReport
+	CheckHotSpotsFunc                    func() []wallet.HotWallet
+	CheckSupplyFunc                      func() []wallet.SupplyDeviation
+	CheckWalletIntegrityFunc             func(userID string) (*wallet.BalanceMismatch, error)
+	ChurnedWalletsFunc                   func(inactiveFor time.Duration) []wallet.ChurnedWallet
+	ClaimGiftFunc                        func(claimToken string, userID string) (*wallet.Gift, error)
+	ClaimGiftContextFunc                 func(ctx context.Context, claimToken string, userID string) (*wallet.Gift, error)
+	ClearDisplayPolicyFunc               func(tenant string, currency string)
+	ClearMaximumBalanceFunc              func(userID string, currency string)
+	ClearMinimumBalanceFunc              func(userID string, currency string)
+	ClearReceiptTemplateFunc             func(tenant string)
+	CloseInterestAccountFunc             func(userID string) error
+	CloseWalletFunc                      func(userID string, req wallet.ClosureRequest) (*wallet.WalletClosure, error)
+	CloseWalletContextFunc               func(ctx context.Context, userID string, req wallet.ClosureRequest) (*wallet.WalletClosure, error)
+	CompleteStepUpFunc                   func(userID string) error
+	CompleteTransactionFunc              func(txID string) (*wallet.Transaction, error)
+	CompleteTransactionContextFunc       func(ctx context.Context, txID string) (*wallet.Transaction, error)
+	ConvertWithQuoteFunc                 func(quoteID string) (*wallet.Transaction, error)
+	ConvertWithQuoteContextFunc          func(ctx context.Context, quoteID string) (*wallet.Transaction, error)
+	CounterpartyLabelFunc                func(viewerID string, tx *wallet.Transaction) string
+	CreateAutomationRuleFunc             func(userID string, name string, trigger wallet.AutomationTrigger, action wallet.AutomationAction) (*wallet.AutomationRule, error)
+	CreateConversionOrderFunc            func(userID string, from string, to string, amount decimal.Decimal, at time.Time, recurrence wallet.Recurrence) (*wallet.ConversionOrder, error)
+	CreateMandateFunc                    func(payerID string, merchantID string, maxPerPeriod decimal.Decimal, period wallet.MandatePeriod) (*wallet.Mandate, error)
+	CreateOrganizationFunc               func(orgID string, name string, email string) error
+	CreatePaymentLinkFunc                func(recipientID string, amount decimal.Decimal, description string, expiry time.Duration, usage wallet.PaymentLinkUsage) (*wallet.PaymentLink, error)
+	CreatePendingDepositFunc             func(userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	CreateUserFunc                       func(userID string, name string, email string) error
+	CreateUserContextFunc                func(ctx context.Context, userID string, name string, email string) error
+	DeleteAutomationRuleFunc             func(userID string, ruleID string) error
+	DepositFunc                          func(userID string, amount float64, description string) error
+	DepositContextFunc                   func(ctx context.Context, userID string, amount decimal.Decimal, description string) error
+	DepositCurrencyFunc                  func(userID string, currency string, amount decimal.Decimal, description string) error
+	DepositCurrencyContextFunc           func(ctx context.Context, userID string, currency string, amount decimal.Decimal, description string) error
+	DepositDecimalFunc                   func(userID string, amount decimal.Decimal, description string) error
+	DepositIdempotentFunc                func(key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	DepositIdempotentContextFunc         func(ctx context.Context, key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	DepositRetentionFunc                 func(from time.Time, to time.Time, g wallet.Granularity, periods int) ([]wallet.RetentionCohort, error)
+	DepositViaRailFunc                   func(userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error)
+	DepositViaRailContextFunc            func(ctx context.Context, userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error)
+	DispatchWebhooksFunc                 func() int
+	DisplayAmountFunc                    func(tenant string, amount decimal.Decimal, currency string) string
+	EmailConflictsFunc                   func() []wallet.EmailConflict
+	EncodeEventFunc                      func(evt wallet.Event, version int) (wallet.EventPayload, int, error)
+	EncryptedBackupFunc                  func(w io.Writer, kw wallet.KeyWrapper) error
+	EndImpersonationFunc                 func(sessionID string) error
+	EventsFunc                           func(ctx context.Context, types ...wallet.EventType) <-chan wallet.Event
+	EventsSinceFunc                      func(offset int64, limit int) []wallet.Event
+	ExpireClientTxIDsFunc                func() (int, error)
+	ExplainInterestFunc                  func(userID string, period wallet.InterestPeriod) (*wallet.InterestStatement, error)
+	ExportAllHistoriesFunc               func(ctx context.Context, sink wallet.ExportSink, opts wallet.BulkExportOptions) (wallet.ExportCheckpoint, error)
+	ExportTenantFunc                     func(tenantID string, includeHistory bool) (*wallet.TenantBundle, error)
+	ExportTransactionHistoryFunc         func(userID string, w io.Writer) error
+	FailTransactionFunc                  func(txID string, reason string) (*wallet.Transaction, error)
+	FirstTransactionConversionFunc       func(from time.Time, to time.Time, g wallet.Granularity, within time.Duration) ([]wallet.ConversionBucket, error)
+	FormatAmountFunc                     func(amount decimal.Decimal, code string) (string, error)
+	FreezeCardFunc                       func(cardID string, userID string) error
+	FreezeSegmentFunc                    func(req wallet.SegmentActionRequest) (*wallet.SegmentAction, error)
+	FreezeSegmentContextFunc             func(ctx context.Context, req wallet.SegmentActionRequest) (*wallet.SegmentAction, error)
+	GetAdjustmentLevelsFunc              func() []wallet.AdjustmentLevel
+	GetAdjustmentRequestFunc             func(requestID string) (*wallet.AdjustmentRequest, error)
+	GetAdminFreezeFunc                   func(userID string) *wallet.AdminFreeze
+	GetAllUsersFunc                      func() []*wallet.User
+	GetAuthorizationFunc                 func(authID string) (*wallet.CardAuthorization, error)
+	GetAutoSettleFunc                    func(userID string) (bool, error)
+	GetAvailableBalanceFunc              func(userID string) (decimal.Decimal, error)
+	GetBalanceFunc                       func(userID string) (float64, error)
+	GetBalanceAtFunc                     func(userID string, at time.Time) (decimal.Decimal, error)
+	GetBalanceContextFunc                func(ctx context.Context, userID string) (decimal.Decimal, error)
+	GetBalanceDecimalFunc                func(userID string) (decimal.Decimal, error)
+	GetBalancesFunc                      func(userIDs []string) (map[string]decimal.Decimal, error)
+	GetBlockedUsersFunc                  func(userID string) ([]wallet.BlockedUser, error)
+	GetCardFunc                          func(cardID string) (*wallet.Card, error)
+	GetCaseFunc                          func(caseID string) (*wallet.ComplianceCase, error)
+	GetCaseEvidenceFunc                  func(caseID string, evidenceID string) ([]byte, error)
+	GetCaseMetricsFunc                   func() wallet.CaseMetrics
+	GetClosureStatusFunc                 func(userID string) (*wallet.WalletClosure, error)
+	GetConsentHistoryFunc                func(userID string) ([]wallet.ConsentRecord, error)
+	GetConsentVersionFunc                func(kind wallet.ConsentKind) (*wallet.ConsentVersion, error)
+	GetConversionOrderFunc               func(orderID string) (*wallet.ConversionOrder, error)
+	GetCurrencyFunc                      func(code string) (wallet.Currency, error)
+	GetCurrencyBalanceFunc               func(userID string, currency string) (decimal.Decimal, error)
+	GetDisplayPolicyFunc                 func(tenant string, currency string) (wallet.DisplayPolicy, error)
+	GetExpenseFunc                       func(expenseID string) (*wallet.ExpenseRequest, error)
+	GetFailedOperationFunc               func(failureID string) (*wallet.FailedOperation, error)
+	GetFederatedTransferFunc             func(voucherID string) (*wallet.OutboundTransfer, error)
+	GetGiftFunc                          func(giftID string) (*wallet.Gift, error)
+	GetHoldFunc                          func(holdID string) (*wallet.Hold, error)
+	GetHotWalletsFunc                    func(topN int) []wallet.HotWallet
+	GetImpersonationSessionFunc          func(sessionID string) (*wallet.ImpersonationSession, error)
+	GetInterestAccountFunc               func(userID string) (*wallet.InterestAccount, error)
+	GetJobFunc                           func(jobID string) (*wallet.ScheduledJob, error)
+	GetLockStatsFunc                     func() []wallet.LaneStats
+	GetLoyaltySummaryFunc                func() wallet.LoyaltySummary
+	GetMandateFunc                       func(mandateID string) (*wallet.Mandate, error)
+	GetMaximumBalanceFunc                func(userID string, currency string) (decimal.Decimal, bool, error)
+	GetMinimumBalanceFunc                func(userID string, currency string) (wallet.MinimumBalance, error)
+	GetNotificationPreferencesFunc       func(userID string) (wallet.NotificationPreferences, bool)
+	GetOperationStatsFunc                func() []wallet.OperationStats
+	GetOrderReservationFunc              func(orderID string) (*wallet.Reservation, error)
+	GetOrderSettlementFunc               func(orderRef string) (*wallet.OrderSettlement, error)
+	GetOverdraftFunc                     func(userID string) (*wallet.Overdraft, error)
+	GetPaymentLinkFunc                   func(linkID string) (*wallet.PaymentLink, error)
+	GetPendingExpensesFunc               func(orgID string) ([]*wallet.ExpenseRequest, error)
+	GetPendingItemsFunc                  func(userID string) ([]wallet.PendingItem, error)
+	GetPointsBalanceFunc                 func(userID string) (int64, error)
+	GetPointsHistoryFunc                 func(userID string) ([]wallet.PointsEntry, error)
+	GetRailTransferFunc                  func(id string) (*wallet.RailTransfer, error)
+	GetRateRecordFunc                    func(id string) (*wallet.RateRecord, error)
+	GetReceiptFunc                       func(receiptID string) (*wallet.Receipt, error)
+	GetRefundableAmountFunc              func(txID string) (decimal.Decimal, error)
+	GetReservationFunc                   func(reservationID string) (*wallet.Reservation, error)
+	GetReserveFunc                       func(userID string) (*wallet.ReserveSummary, error)
+	GetReservePolicyFunc                 func(userID string) (*wallet.ReservePolicy, error)
+	GetRestrictionFunc                   func(userID string) (*wallet.Restriction, bool)
+	GetRetentionStatsFunc                func() wallet.RetentionStats
+	GetRuleExecutionsFunc                func(ruleID string) ([]wallet.RuleExecution, error)
+	GetSegmentActionFunc                 func(actionID string) (*wallet.SegmentAction, error)
+	GetSpendingLimitsFunc                func(userID string) (wallet.SpendingLimits, error)
+	GetSpendingUsageFunc                 func(userID string) (*wallet.SpendingUsage, error)
+	GetSystemAccountsFunc                func() []wallet.SystemAccount
+	GetTotalSupplyFunc                   func() map[string]decimal.Decimal
+	GetTransactionFunc                   func(txID string) (*wallet.Transaction, error)
+	GetTransactionHistoryFunc            func(userID string) ([]*wallet.Transaction, error)
+	GetTransactionHistoryContextFunc     func(ctx context.Context, userID string) ([]*wallet.Transaction, error)
+	GetTransactionReceiptsFunc           func(txID string) []wallet.Receipt
+	GetTransactionStatusFunc             func(txID string) (wallet.TransactionStatus, error)
+	GetTransactionTreeFunc               func(txID string) (*wallet.TransactionTree, error)
+	GetUserAttributesFunc                func(userID string) (wallet.UserAttributes, error)
+	GetUserByEmailFunc                   func(email string) (*wallet.User, error)
+	GetUserByIdentityFunc                func(kind wallet.IdentityKind, issuer string, subject string) (*wallet.User, error)
+	GetWalletFunc                        func(userID string) (*wallet.WalletSnapshot, error)
+	GetWebhookSubscriptionFunc           func(subscriptionID string) (*wallet.WebhookSubscription, error)
+	HandleRailCallbackFunc               func(cb wallet.RailCallback) error
+	HandleRailCallbackContextFunc        func(ctx context.Context, cb wallet.RailCallback) error
+	HoldFunc                             func(userID string, amount decimal.Decimal) (*wallet.Hold, error)
+	HoldContextFunc                      func(ctx context.Context, userID string, amount decimal.Decimal) (*wallet.Hold, error)
+	ImpersonatedBalanceFunc              func(sessionID string) (decimal.Decimal, error)
+	ImpersonatedHistoryFunc              func(sessionID string) ([]*wallet.Transaction, error)
+	ImpersonatedPendingItemsFunc         func(sessionID string) ([]wallet.PendingItem, error)
+	ImpersonatedTransferFunc             func(sessionID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	ImpersonatedTransferContextFunc      func(ctx context.Context, sessionID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	ImportTenantFunc                     func(bundle *wallet.TenantBundle, policy wallet.ConflictPolicy) (*wallet.TenantImport, error)
+	ImportedHistoryFunc                  func(userID string) ([]wallet.Transaction, error)
+	IsBlockedFunc                        func(userID string, counterpartyID string) bool
+	IsHotWalletFunc                      func(userID string) bool
+	IssueCardFunc                        func(userID string, label string, limits wallet.CardLimits, validFor time.Duration) (*wallet.Card, error)
+	IterateTransactionsFunc              func(userID string, opts wallet.IterateOptions) (wallet.TransactionIterator, error)
+	IterateTransactionsContextFunc       func(ctx context.Context, userID string, opts wallet.IterateOptions) (wallet.TransactionIterator, error)
+	LatestEventOffsetFunc                func() int64
+	LatestSchemaVersionFunc              func(eventType wallet.EventType) int
+	LiftRestrictionFunc                  func(userID string) error
+	LinkIdentityFunc                     func(userID string, identity wallet.ExternalIdentity) (*wallet.ExternalIdentity, error)
+	LinkTransactionsFunc                 func(txID string, relatedTxID string) error
+	ListAdjustmentRequestsFunc           func(status wallet.AdjustmentStatus) []*wallet.AdjustmentRequest
+	ListAdjustmentsFunc                  func(userID string) ([]*wallet.Transaction, error)
+	ListAnnotationsFunc                  func(viewerID string, subject wallet.AnnotationSubject, subjectID string) ([]wallet.Annotation, error)
+	ListAutomationRulesFunc              func(userID string) []wallet.AutomationRule
+	ListCardsFunc                        func(userID string) []wallet.Card
+	ListCasesFunc                        func(status wallet.CaseStatus) []*wallet.ComplianceCase
+	ListConversionOrdersFunc             func(userID string) []wallet.ConversionOrder
+	ListCurrenciesFunc                   func() []wallet.Currency
+	ListEventSchemasFunc                 func(eventType wallet.EventType) []wallet.EventSchema
+	ListFailedOperationsFunc             func(userID string, reason wallet.FailureReason) ([]wallet.FailedOperation, error)
+	ListFavoritesFunc                    func(userID string) []wallet.Favorite
+	ListHoldsFunc                        func(userID string) []wallet.Hold
+	ListIdentitiesFunc                   func(userID string) ([]wallet.ExternalIdentity, error)
+	ListImpersonationAuditFunc           func(filter wallet.ImpersonationAuditFilter) []wallet.ImpersonationAuditEntry
+	ListJobAuditFunc                     func(jobID string) []wallet.JobAuditEntry
+	ListLegalHoldsFunc                   func() []wallet.LegalHold
+	ListMandatesFunc                     func(userID string) []wallet.Mandate
+	ListPaymentLinksFunc                 func(recipientID string) []wallet.PaymentLink
+	ListRailTransfersFunc                func(userID string) []wallet.RailTransfer
+	ListReservationsFunc                 func(userID string) []wallet.Reservation
+	ListRetentionRunsFunc                func() []wallet.RetentionRun
+	ListSegmentActionsFunc               func() []wallet.SegmentAction
+	ListTransactionsFunc                 func(userID string, q wallet.HistoryQuery) (*wallet.HistoryPage, error)
+	ListUpcomingJobsFunc                 func(filter wallet.UpcomingFilter) []wallet.UpcomingJob
+	ListWithdrawalDestinationsFunc       func(userID string) []wallet.WithdrawalDestination
+	MigrateEmailIndexFunc                func() wallet.EmailMigrationReport
+	MintFunc                             func(toUserID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	MintContextFunc                      func(ctx context.Context, toUserID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	MissingConsentsFunc                  func(userID string) ([]wallet.ConsentVersion, error)
+	PauseConversionOrderFunc             func(orderID string, userID string) error
+	PauseMandateFunc                     func(mandateID string, payerID string) error
+	PayPaymentLinkFunc                   func(tokenOrURL string, payerID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	PayPaymentLinkContextFunc            func(ctx context.Context, tokenOrURL string, payerID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	PayoutViaRailFunc                    func(userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error)
+	PayoutViaRailContextFunc             func(ctx context.Context, userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error)
+	PendingFederatedTransfersFunc        func() []wallet.OutboundTransfer
+	PlaceLegalHoldFunc                   func(hold wallet.LegalHold) (*wallet.LegalHold, error)
+	PostCustomTransactionFunc            func(req wallet.CustomTransaction) (*wallet.Transaction, error)
+	PostCustomTransactionContextFunc     func(ctx context.Context, req wallet.CustomTransaction) (*wallet.Transaction, error)
+	PostInterestFunc                     func(userID string, period wallet.InterestPeriod) (*wallet.InterestStatement, error)
+	PostInterestContextFunc              func(ctx context.Context, userID string, period wallet.InterestPeriod) (*wallet.InterestStatement, error)
+	PreviewUserDeletionFunc              func(userID string) (*wallet.DeletionPreview, error)
+	ProcessAutomationsFunc               func() []wallet.RuleExecution
+	PublishConsentVersionFunc            func(v wallet.ConsentVersion) error
+	PullFundsFunc                        func(mandateID string, merchantID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	PullFundsContextFunc                 func(ctx context.Context, mandateID string, merchantID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	QueryAuditLogFunc                    func(q wallet.AuditQuery) ([]wallet.AuditEntry, error)
+	QuickPayFunc                         func(favoriteID string) (*wallet.Transaction, error)
+	QuickPayAmountFunc                   func(favoriteID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	QuickPayAmountContextFunc            func(ctx context.Context, favoriteID string, amount decimal.Decimal) (*wallet.Transaction, error)
+	QuickPayContextFunc                  func(ctx context.Context, favoriteID string) (*wallet.Transaction, error)
+	QuoteConversionFunc                  func(userID string, from string, to string, amount decimal.Decimal) (*wallet.FXQuote, error)
+	QuoteConversionContextFunc           func(ctx context.Context, userID string, from string, to string, amount decimal.Decimal) (*wallet.FXQuote, error)
+	RateAtFunc                           func(from string, to string, at time.Time) (*wallet.RateRecord, error)
+	RateHistoryFunc                      func(from string, to string, since time.Time, until time.Time) []wallet.RateRecord
+	ReceiveFederatedTransferFunc         func(voucher wallet.FederationVoucher) (*wallet.FederationReceipt, error)
+	ReceiveFederatedTransferContextFunc  func(ctx context.Context, voucher wallet.FederationVoucher) (*wallet.FederationReceipt, error)
+	ReconcileFunc                        func() (*wallet.ReconciliationReport, error)
+	ReconcileSnapshotFunc                func(snap *wallet.Snapshot) []wallet.BalanceMismatch
+	RecordAuditFunc                      func(ctx context.Context, entry wallet.AuditEntry) error
+	RecordRateFunc                       func(from string, to string, rate decimal.Decimal, source string) (*wallet.RateRecord, error)
+	RecoveredTransfersFunc               func() []wallet.RecoveredTransfer
+	RefundTransactionFunc                func(txID string, amount decimal.Decimal, reason string) (*wallet.Transaction, error)
+	RefundTransactionContextFunc         func(ctx context.Context, txID string, amount decimal.Decimal, reason string) (*wallet.Transaction, error)
+	RegisterCurrencyFunc                 func(c wallet.Currency) error
+	RegisterEventSchemaFunc              func(schema wallet.EventSchema) error
+	RegisterHealthCheckFunc              func(name string, fn wallet.HealthCheckFunc)
+	RegisterHoldRuleFunc                 func(name string, fn wallet.HoldRuleFunc)
+	RegisterRiskHookFunc                 func(name string, fn wallet.RiskHookFunc)
+	RegisterValidatorFunc                func(txType wallet.TransactionType, fn wallet.ValidatorFunc)
+	RegisterWebhookFunc                  func(transport wallet.WebhookTransport, cfg wallet.WebhookConfig) (string, error)
+	RejectAdjustmentFunc                 func(requestID string, checkerID string, comment string) (*wallet.AdjustmentRequest, error)
+	ReleaseAuthorizationFunc             func(authID string) (*wallet.CardAuthorization, error)
+	ReleaseAuthorizationContextFunc      func(ctx context.Context, authID string) (*wallet.CardAuthorization, error)
+	ReleaseHoldFunc                      func(holdID string) (*wallet.Hold, error)
+	ReleaseHoldContextFunc               func(ctx context.Context, holdID string) (*wallet.Hold, error)
+	ReleaseLegalHoldFunc                 func(holdID string, actor string) error
+	ReleaseReservationFunc               func(reservationID string) (*wallet.Reservation, error)
+	ReleaseReservationContextFunc        func(ctx context.Context, reservationID string) (*wallet.Reservation, error)
+	RemoveFavoriteFunc                   func(userID string, favoriteID string) error
+	RemoveInterestOverrideFunc           func(userID string) error
+	RemoveOrgMemberFunc                  func(orgID string, userID string) error
+	RemoveReservePolicyFunc              func(userID string) error
+	RemoveStaffFunc                      func(staffID string) error
+	RemoveWithdrawalDestinationFunc      func(userID string, destinationID string) error
+	ReorderFavoritesFunc                 func(userID string, ids []string) error
+	ReplayWebhookFunc                    func(subscriptionID string, fromOffset int64) error
+	RequestAdjustmentFunc                func(makerID string, userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, note string) (*wallet.AdjustmentRequest, error)
+	RequestAdjustmentContextFunc         func(ctx context.Context, makerID string, userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, note string) (*wallet.AdjustmentRequest, error)
+	ResendReceiptFunc                    func(receiptID string) error
+	ReserveFunc                          func(req wallet.ReservationRequest) (*wallet.Reservation, error)
+	ReserveClientTxIDFunc                func(userID string, clientTxID string, ttl time.Duration) (*wallet.ClientTxReservation, error)
+	ReserveContextFunc                   func(ctx context.Context, req wallet.ReservationRequest) (*wallet.Reservation, error)
+	ResolveCaseFunc                      func(caseID string, reviewer string, release bool, note string) (*wallet.Transaction, error)
+	ResolveCaseContextFunc               func(ctx context.Context, caseID string, reviewer string, release bool, note string) (*wallet.Transaction, error)
+	ResolvePaymentLinkFunc               func(tokenOrURL string) (*wallet.PaymentLink, error)
+	RestrictUserFunc                     func(userID string, source wallet.RestrictionSource, reason string) (*wallet.Restriction, error)
+	ResumeConversionOrderFunc            func(orderID string, userID string) error
+	ResumeMandateFunc                    func(mandateID string, payerID string) error
+	RetryOperationFunc                   func(failureID string) (*wallet.Transaction, error)
+	RetryOperationContextFunc            func(ctx context.Context, failureID string) (*wallet.Transaction, error)
+	ReviewExpenseFunc                    func(expenseID string, reviewerID string, approve bool, comment string) (*wallet.ExpenseRequest, error)
+	ReviewExpenseContextFunc             func(ctx context.Context, expenseID string, reviewerID string, approve bool, comment string) (*wallet.ExpenseRequest, error)
+	RevokeMandateFunc                    func(mandateID string, payerID string) error
+	RevokeMinimumBalanceWaiverFunc       func(userID string) error
+	RunDataRetentionFunc                 func() *wallet.RetentionRun
+	RunDueJobsFunc                       func() []wallet.JobResult
+	ScheduleGiftFunc                     func(senderID string, recipient string, amount decimal.Decimal, message string, deliverAt time.Time) (*wallet.Gift, error)
+	SchedulePaymentFunc                  func(fromUserID string, toUserID string, amount decimal.Decimal, description string, at time.Time, recurrence wallet.Recurrence) (string, error)
+	SearchAnnotationsFunc                func(viewerID string, query wallet.AnnotationQuery) ([]wallet.Annotation, error)
+	SendDigestsFunc                      func() int
+	SendFederatedTransferFunc            func(fromUserID string, targetInstance string, toUserID string, amount decimal.Decimal, description string) (*wallet.FederationVoucher, error)
+	SendFederatedTransferContextFunc     func(ctx context.Context, fromUserID string, targetInstance string, toUserID string, amount decimal.Decimal, description string) (*wallet.FederationVoucher, error)
+	SessionTokenFunc                     func() wallet.SessionToken
+	SetAdjustmentLevelsFunc              func(levels []wallet.AdjustmentLevel) error
+	SetApprovalChainFunc                 func(orgID string, chain []wallet.ApprovalStep) error
+	SetAutoSettleFunc                    func(userID string, enabled bool) error
+	SetAutomationRulePausedFunc          func(userID string, ruleID string, paused bool) error
+	SetBalanceFunc                       func(userID string, target decimal.Decimal, reason wallet.AdjustmentReason) (*wallet.Transaction, error)
+	SetBalanceContextFunc                func(ctx context.Context, userID string, target decimal.Decimal, reason wallet.AdjustmentReason) (*wallet.Transaction, error)
+	SetCardLimitsFunc                    func(cardID string, userID string, limits wallet.CardLimits) error
+	SetDisplayPolicyFunc                 func(tenant string, currency string, policy wallet.DisplayPolicy) error
+	SetInterestAccountFunc               func(userID string, account wallet.InterestAccount) (*wallet.InterestAccount, error)
+	SetInterestOverrideFunc              func(userID string, o wallet.InterestOverride) error
+	SetMaximumBalanceFunc                func(userID string, currency string, maximum decimal.Decimal) error
+	SetMinimumBalanceFunc                func(userID string, currency string, minimum decimal.Decimal) error
+	SetMinimumBalanceTierFunc            func(tier string, minimums map[string]decimal.Decimal) error
+	SetNotificationPreferencesFunc       func(userID string, prefs wallet.NotificationPreferences) error
+	SetOverdraftLimitFunc                func(userID string, limit decimal.Decimal) error
+	SetReceiptTemplateFunc               func(tenant string, tmpl wallet.ReceiptTemplate) error
+	SetReservePolicyFunc                 func(userID string, policy wallet.ReservePolicy) error
+	SetSpendingLimitsFunc                func(userID string, limits wallet.SpendingLimits) error
+	SetStaffRoleFunc                     func(staffID string, role wallet.StaffRole) error
+	SetStatementDescriptorFunc           func(userID string, descriptor string, actorID string) error
+	SetUserAttributesFunc                func(userID string, attrs wallet.UserAttributes) error
+	SetWebhookSchemaVersionFunc          func(subscriptionID string, eventType wallet.EventType, version int) error
+	SettleOrderFunc                      func(buyerID string, orderRef string, total decimal.Decimal, splits []wallet.Split) (*wallet.OrderSettlement, error)
+	SettleOrderContextFunc               func(ctx context.Context, buyerID string, orderRef string, total decimal.Decimal, splits []wallet.Split) (*wallet.OrderSettlement, error)
+	SettleReservationFunc                func(reservationID string, amount decimal.Decimal, reference string) (*wallet.Reservation, error)
+	SettleReservationContextFunc         func(ctx context.Context, reservationID string, amount decimal.Decimal, reference string) (*wallet.Reservation, error)
+	SettlementDateFunc                   func(t time.Time, lag int) time.Time
+	SkipJobOccurrenceFunc                func(jobID string, runAt int64, actor string, reason string) error
+	SkipNextConversionFunc               func(orderID string, userID string) error
+	SnapshotFunc                         func() *wallet.Snapshot
+	SnapshotOnlineFunc                   func() (*wallet.Snapshot, error)
+	StartImpersonationFunc               func(req wallet.ImpersonationRequest) (*wallet.ImpersonationSession, error)
+	StartSchedulerFunc                   func(interval time.Duration)
+	StartWebhookDispatcherFunc           func(interval time.Duration)
+	StatementDescriptorFunc              func(userID string) string
+	StatementDescriptorHistoryFunc       func(userID string) ([]wallet.DescriptorChange, error)
+	StopSchedulerFunc                    func()
+	StopWebhookDispatcherFunc            func()
+	SubmitExpenseFunc                    func(orgID string, submitterID string, payeeID string, amount decimal.Decimal, description string) (*wallet.ExpenseRequest, error)
+	SummarizeConversionOrderFunc         func(orderID string, since time.Time, until time.Time) (*wallet.ConversionOrderSummary, error)
+	TestClockFunc                        func() *wallet.SimClock
+	TransferFunc                         func(fromUserID string, toUserID string, amount float64, description string) error
+	TransferClientTxIDFunc               func(clientTxID string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	TransferClientTxIDContextFunc        func(ctx context.Context, clientTxID string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	TransferContextFunc                  func(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, description string) error
+	TransferDecimalFunc                  func(fromUserID string, toUserID string, amount decimal.Decimal, description string) error
+	TransferIdempotentFunc               func(key string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	TransferIdempotentContextFunc        func(ctx context.Context, key string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	TransferWithFloorFunc                func(fromUserID string, toUserID string, amount decimal.Decimal, minRemaining decimal.Decimal) (*wallet.Transaction, error)
+	TransferWithFloorContextFunc         func(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, minRemaining decimal.Decimal) (*wallet.Transaction, error)
+	TriggerJobFunc                       func(jobID string, actor string, reason string) (wallet.JobResult, error)
+	UnblockUserFunc                      func(userID string, blockedUserID string) error
+	UnfreezeCardFunc                     func(cardID string, userID string) error
+	UnfreezeSegmentFunc                  func(req wallet.SegmentActionRequest) (*wallet.SegmentAction, error)
+	UnfreezeSegmentContextFunc           func(ctx context.Context, req wallet.SegmentActionRequest) (*wallet.SegmentAction, error)
+	UnlinkIdentityFunc                   func(userID string, identity wallet.ExternalIdentity) error
+	UnregisterWebhookFunc                func(subscriptionID string) error
+	UpdateFavoriteFunc                   func(userID string, favoriteID string, spec wallet.FavoriteSpec) (*wallet.Favorite, error)
+	UpdateUserEmailFunc                  func(userID string, email string) error
+	VerifyWithdrawalDestinationFunc      func(userID string, destinationID string) error
+	WaiveMinimumBalanceFunc              func(userID string, actor string, reason string) error
+	WithdrawFunc                         func(userID string, amount float64, description string) error
+	WithdrawConsentFunc                  func(userID string, kind wallet.ConsentKind) error
+	WithdrawContextFunc                  func(ctx context.Context, userID string, decimalAmount decimal.Decimal, description string) error
+	WithdrawDecimalFunc                  func(userID string, decimalAmount decimal.Decimal, description string) error
+	WithdrawIdempotentFunc               func(key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	WithdrawIdempotentContextFunc        func(ctx context.Context, key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	WithdrawToFunc                       func(userID string, destinationID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+	WithdrawToContextFunc                func(ctx context.Context, userID string, destinationID string, amount decimal.Decimal, description string) (*wallet.Transaction, error)
+}
+
+var _ wallet.Service = (*MockService)(nil)
+
+// AcceptConsent calls AcceptConsentFunc
+func (mock *MockService) AcceptConsent(userID string, kind wallet.ConsentKind, version string) error {
+	mock.record("AcceptConsent", userID, kind, version)
+	if mock.AcceptConsentFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AcceptConsentFunc(userID, kind, version)
+}
+
+// AccrueInterestNow calls AccrueInterestNowFunc
+func (mock *MockService) AccrueInterestNow() ([]*wallet.InterestStatement, error) {
+	mock.record("AccrueInterestNow")
+	if mock.AccrueInterestNowFunc == nil {
+		var r0 []*wallet.InterestStatement
+		return r0, ErrNotConfigured
+	}
+	return mock.AccrueInterestNowFunc()
+}
+
+// AckWebhook calls AckWebhookFunc
+func (mock *MockService) AckWebhook(subscriptionID string, ackToken string) error {
+	mock.record("AckWebhook", subscriptionID, ackToken)
+	if mock.AckWebhookFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AckWebhookFunc(subscriptionID, ackToken)
+}
+
+// ActiveUsers calls ActiveUsersFunc
+func (mock *MockService) ActiveUsers(from time.Time, to time.Time, g wallet.Granularity) ([]wallet.ActivityBucket, error) {
+	mock.record("ActiveUsers", from, to, g)
+	if mock.ActiveUsersFunc == nil {
+		var r0 []wallet.ActivityBucket
+		return r0, ErrNotConfigured
+	}
+	return mock.ActiveUsersFunc(from, to, g)
+}
+
+// AddCaseNote calls AddCaseNoteFunc
+func (mock *MockService) AddCaseNote(caseID string, author string, text string) error {
+	mock.record("AddCaseNote", caseID, author, text)
+	if mock.AddCaseNoteFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AddCaseNoteFunc(caseID, author, text)
+}
+
+// AddFavorite calls AddFavoriteFunc
+func (mock *MockService) AddFavorite(userID string, spec wallet.FavoriteSpec) (*wallet.Favorite, error) {
+	mock.record("AddFavorite", userID, spec)
+	if mock.AddFavoriteFunc == nil {
+		var r0 *wallet.Favorite
+		return r0, ErrNotConfigured
+	}
+	return mock.AddFavoriteFunc(userID, spec)
+}
+
+// AddOrgMember calls AddOrgMemberFunc
+func (mock *MockService) AddOrgMember(orgID string, userID string, role wallet.OrgRole) error {
+	mock.record("AddOrgMember", orgID, userID, role)
+	if mock.AddOrgMemberFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AddOrgMemberFunc(orgID, userID, role)
+}
+
+// AddWithdrawalDestination calls AddWithdrawalDestinationFunc
+func (mock *MockService) AddWithdrawalDestination(userID string, kind wallet.DestinationKind, reference string, label string) (*wallet.WithdrawalDestination, error) {
+	mock.record("AddWithdrawalDestination", userID, kind, reference, label)
+	if mock.AddWithdrawalDestinationFunc == nil {
+		var r0 *wallet.WithdrawalDestination
+		return r0, ErrNotConfigured
+	}
+	return mock.AddWithdrawalDestinationFunc(userID, kind, reference, label)
+}
+
+// AdminAdjust calls AdminAdjustFunc
+func (mock *MockService) AdminAdjust(userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, actorID string) (*wallet.Transaction, error) {
+	mock.record("AdminAdjust", userID, amount, reason, actorID)
+	if mock.AdminAdjustFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.AdminAdjustFunc(userID, amount, reason, actorID)
+}
+
+// AdminAdjustContext calls AdminAdjustContextFunc
+func (mock *MockService) AdminAdjustContext(ctx context.Context, userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, actorID string) (*wallet.Transaction, error) {
+	mock.record("AdminAdjustContext", ctx, userID, amount, reason, actorID)
+	if mock.AdminAdjustContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.AdminAdjustContextFunc(ctx, userID, amount, reason, actorID)
+}
+
+// AdminTransfer calls AdminTransferFunc
+func (mock *MockService) AdminTransfer(fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("AdminTransfer", fromUserID, toUserID, amount, description)
+	if mock.AdminTransferFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.AdminTransferFunc(fromUserID, toUserID, amount, description)
+}
+
+// AdminTransferContext calls AdminTransferContextFunc
+func (mock *MockService) AdminTransferContext(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("AdminTransferContext", ctx, fromUserID, toUserID, amount, description)
+	if mock.AdminTransferContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.AdminTransferContextFunc(ctx, fromUserID, toUserID, amount, description)
+}
+
+// AnnotateTransaction calls AnnotateTransactionFunc
+func (mock *MockService) AnnotateTransaction(authorID string, txID string, text string, visibility wallet.AnnotationVisibility) (*wallet.Annotation, error) {
+	mock.record("AnnotateTransaction", authorID, txID, text, visibility)
+	if mock.AnnotateTransactionFunc == nil {
+		var r0 *wallet.Annotation
+		return r0, ErrNotConfigured
+	}
+	return mock.AnnotateTransactionFunc(authorID, txID, text, visibility)
+}
+
+// AnnotateUser calls AnnotateUserFunc
+func (mock *MockService) AnnotateUser(authorID string, userID string, text string, visibility wallet.AnnotationVisibility) (*wallet.Annotation, error) {
+	mock.record("AnnotateUser", authorID, userID, text, visibility)
+	if mock.AnnotateUserFunc == nil {
+		var r0 *wallet.Annotation
+		return r0, ErrNotConfigured
+	}
+	return mock.AnnotateUserFunc(authorID, userID, text, visibility)
+}
+
+// ApplyFederationReceipt calls ApplyFederationReceiptFunc
+func (mock *MockService) ApplyFederationReceipt(receipt wallet.FederationReceipt) (*wallet.OutboundTransfer, error) {
+	mock.record("ApplyFederationReceipt", receipt)
+	if mock.ApplyFederationReceiptFunc == nil {
+		var r0 *wallet.OutboundTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.ApplyFederationReceiptFunc(receipt)
+}
+
+// ApplyFederationReceiptContext calls ApplyFederationReceiptContextFunc
+func (mock *MockService) ApplyFederationReceiptContext(ctx context.Context, receipt wallet.FederationReceipt) (*wallet.OutboundTransfer, error) {
+	mock.record("ApplyFederationReceiptContext", ctx, receipt)
+	if mock.ApplyFederationReceiptContextFunc == nil {
+		var r0 *wallet.OutboundTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.ApplyFederationReceiptContextFunc(ctx, receipt)
+}
+
+// ApproveAdjustment calls ApproveAdjustmentFunc
+func (mock *MockService) ApproveAdjustment(requestID string, checkerID string, comment string) (*wallet.AdjustmentRequest, error) {
+	mock.record("ApproveAdjustment", requestID, checkerID, comment)
+	if mock.ApproveAdjustmentFunc == nil {
+		var r0 *wallet.AdjustmentRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.ApproveAdjustmentFunc(requestID, checkerID, comment)
+}
+
+// ApproveAdjustmentContext calls ApproveAdjustmentContextFunc
+func (mock *MockService) ApproveAdjustmentContext(ctx context.Context, requestID string, checkerID string, comment string) (*wallet.AdjustmentRequest, error) {
+	mock.record("ApproveAdjustmentContext", ctx, requestID, checkerID, comment)
+	if mock.ApproveAdjustmentContextFunc == nil {
+		var r0 *wallet.AdjustmentRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.ApproveAdjustmentContextFunc(ctx, requestID, checkerID, comment)
+}
+
+// ArchiveTransactionsBefore calls ArchiveTransactionsBeforeFunc
+func (mock *MockService) ArchiveTransactionsBefore(cutoff int64) (int, error) {
+	mock.record("ArchiveTransactionsBefore", cutoff)
+	if mock.ArchiveTransactionsBeforeFunc == nil {
+		var r0 int
+		return r0, ErrNotConfigured
+	}
+	return mock.ArchiveTransactionsBeforeFunc(cutoff)
+}
+
+// ArchiveTransactionsBeforeContext calls ArchiveTransactionsBeforeContextFunc
+func (mock *MockService) ArchiveTransactionsBeforeContext(ctx context.Context, cutoff int64) (int, error) {
+	mock.record("ArchiveTransactionsBeforeContext", ctx, cutoff)
+	if mock.ArchiveTransactionsBeforeContextFunc == nil {
+		var r0 int
+		return r0, ErrNotConfigured
+	}
+	return mock.ArchiveTransactionsBeforeContextFunc(ctx, cutoff)
+}
+
+// AssignCase calls AssignCaseFunc
+func (mock *MockService) AssignCase(caseID string, assignee string) error {
+	mock.record("AssignCase", caseID, assignee)
+	if mock.AssignCaseFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AssignCaseFunc(caseID, assignee)
+}
+
+// AssignMinimumBalanceTier calls AssignMinimumBalanceTierFunc
+func (mock *MockService) AssignMinimumBalanceTier(userID string, tier string) error {
+	mock.record("AssignMinimumBalanceTier", userID, tier)
+	if mock.AssignMinimumBalanceTierFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AssignMinimumBalanceTierFunc(userID, tier)
+}
+
+// AttachCaseEvidence calls AttachCaseEvidenceFunc
+func (mock *MockService) AttachCaseEvidence(caseID string, evidence wallet.CaseEvidence) error {
+	mock.record("AttachCaseEvidence", caseID, evidence)
+	if mock.AttachCaseEvidenceFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AttachCaseEvidenceFunc(caseID, evidence)
+}
+
+// AuditConversion calls AuditConversionFunc
+func (mock *MockService) AuditConversion(txID string) (*wallet.ConversionAudit, error) {
+	mock.record("AuditConversion", txID)
+	if mock.AuditConversionFunc == nil {
+		var r0 *wallet.ConversionAudit
+		return r0, ErrNotConfigured
+	}
+	return mock.AuditConversionFunc(txID)
+}
+
+// AuthorizeCard calls AuthorizeCardFunc
+func (mock *MockService) AuthorizeCard(req wallet.CardAuthRequest) (*wallet.CardAuthorization, error) {
+	mock.record("AuthorizeCard", req)
+	if mock.AuthorizeCardFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.AuthorizeCardFunc(req)
+}
+
+// AuthorizeCardContext calls AuthorizeCardContextFunc
+func (mock *MockService) AuthorizeCardContext(ctx context.Context, req wallet.CardAuthRequest) (*wallet.CardAuthorization, error) {
+	mock.record("AuthorizeCardContext", ctx, req)
+	if mock.AuthorizeCardContextFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.AuthorizeCardContextFunc(ctx, req)
+}
+
+// AwaitSession calls AwaitSessionFunc
+func (mock *MockService) AwaitSession(ctx context.Context, token wallet.SessionToken) error {
+	mock.record("AwaitSession", ctx, token)
+	if mock.AwaitSessionFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.AwaitSessionFunc(ctx, token)
+}
+
+// Backup calls BackupFunc
+func (mock *MockService) Backup(w io.Writer) error {
+	mock.record("Backup", w)
+	if mock.BackupFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.BackupFunc(w)
+}
+
+// BackupBinary calls BackupBinaryFunc
+func (mock *MockService) BackupBinary(w io.Writer) error {
+	mock.record("BackupBinary", w)
+	if mock.BackupBinaryFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.BackupBinaryFunc(w)
+}
+
+// BackupOnline calls BackupOnlineFunc
+func (mock *MockService) BackupOnline(w io.Writer) error {
+	mock.record("BackupOnline", w)
+	if mock.BackupOnlineFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.BackupOnlineFunc(w)
+}
+
+// BatchPayout calls BatchPayoutFunc
+func (mock *MockService) BatchPayout(fromUserID string, payouts []wallet.Payout, description string) ([]*wallet.Transaction, error) {
+	mock.record("BatchPayout", fromUserID, payouts, description)
+	if mock.BatchPayoutFunc == nil {
+		var r0 []*wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.BatchPayoutFunc(fromUserID, payouts, description)
+}
+
+// BatchPayoutContext calls BatchPayoutContextFunc
+func (mock *MockService) BatchPayoutContext(ctx context.Context, fromUserID string, payouts []wallet.Payout, description string) ([]*wallet.Transaction, error) {
+	mock.record("BatchPayoutContext", ctx, fromUserID, payouts, description)
+	if mock.BatchPayoutContextFunc == nil {
+		var r0 []*wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.BatchPayoutContextFunc(ctx, fromUserID, payouts, description)
+}
+
+// BlockUser calls BlockUserFunc
+func (mock *MockService) BlockUser(userID string, blockedUserID string) error {
+	mock.record("BlockUser", userID, blockedUserID)
+	if mock.BlockUserFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.BlockUserFunc(userID, blockedUserID)
+}
+
+// Burn calls BurnFunc
+func (mock *MockService) Burn(fromUserID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("Burn", fromUserID, amount)
+	if mock.BurnFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.BurnFunc(fromUserID, amount)
+}
+
+// BurnContext calls BurnContextFunc
+func (mock *MockService) BurnContext(ctx context.Context, fromUserID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("BurnContext", ctx, fromUserID, amount)
+	if mock.BurnContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.BurnContextFunc(ctx, fromUserID, amount)
+}
+
+// CancelCard calls CancelCardFunc
+func (mock *MockService) CancelCard(cardID string, userID string) error {
+	mock.record("CancelCard", cardID, userID)
+	if mock.CancelCardFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelCardFunc(cardID, userID)
+}
+
+// CancelConversionOrder calls CancelConversionOrderFunc
+func (mock *MockService) CancelConversionOrder(orderID string, userID string) error {
+	mock.record("CancelConversionOrder", orderID, userID)
+	if mock.CancelConversionOrderFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelConversionOrderFunc(orderID, userID)
+}
+
+// CancelGift calls CancelGiftFunc
+func (mock *MockService) CancelGift(giftID string, senderID string) error {
+	mock.record("CancelGift", giftID, senderID)
+	if mock.CancelGiftFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelGiftFunc(giftID, senderID)
+}
+
+// CancelJob calls CancelJobFunc
+func (mock *MockService) CancelJob(jobID string) error {
+	mock.record("CancelJob", jobID)
+	if mock.CancelJobFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelJobFunc(jobID)
+}
+
+// CancelPaymentLink calls CancelPaymentLinkFunc
+func (mock *MockService) CancelPaymentLink(linkID string, recipientID string) error {
+	mock.record("CancelPaymentLink", linkID, recipientID)
+	if mock.CancelPaymentLinkFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelPaymentLinkFunc(linkID, recipientID)
+}
+
+// CancelScheduledJob calls CancelScheduledJobFunc
+func (mock *MockService) CancelScheduledJob(jobID string, actor string, reason string) error {
+	mock.record("CancelScheduledJob", jobID, actor, reason)
+	if mock.CancelScheduledJobFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelScheduledJobFunc(jobID, actor, reason)
+}
+
+// CancelWalletClosure calls CancelWalletClosureFunc
+func (mock *MockService) CancelWalletClosure(userID string) error {
+	mock.record("CancelWalletClosure", userID)
+	if mock.CancelWalletClosureFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CancelWalletClosureFunc(userID)
+}
+
+// CaptureAuthorization calls CaptureAuthorizationFunc
+func (mock *MockService) CaptureAuthorization(authID string, amount decimal.Decimal) (*wallet.CardAuthorization, error) {
+	mock.record("CaptureAuthorization", authID, amount)
+	if mock.CaptureAuthorizationFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.CaptureAuthorizationFunc(authID, amount)
+}
+
+// CaptureAuthorizationContext calls CaptureAuthorizationContextFunc
+func (mock *MockService) CaptureAuthorizationContext(ctx context.Context, authID string, amount decimal.Decimal) (*wallet.CardAuthorization, error) {
+	mock.record("CaptureAuthorizationContext", ctx, authID, amount)
+	if mock.CaptureAuthorizationContextFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.CaptureAuthorizationContextFunc(ctx, authID, amount)
+}
+
+// CaptureHold calls CaptureHoldFunc
+func (mock *MockService) CaptureHold(holdID string, amount decimal.Decimal) (*wallet.Hold, error) {
+	mock.record("CaptureHold", holdID, amount)
+	if mock.CaptureHoldFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.CaptureHoldFunc(holdID, amount)
+}
+
+// CaptureHoldContext calls CaptureHoldContextFunc
+func (mock *MockService) CaptureHoldContext(ctx context.Context, holdID string, amount decimal.Decimal) (*wallet.Hold, error) {
+	mock.record("CaptureHoldContext", ctx, holdID, amount)
+	if mock.CaptureHoldContextFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.CaptureHoldContextFunc(ctx, holdID, amount)
+}
+
+// Charge calls ChargeFunc
+func (mock *MockService) Charge(req wallet.ChargeRequest) (*wallet.ChargeReceipt, error) {
+	mock.record("Charge", req)
+	if mock.ChargeFunc == nil {
+		var r0 *wallet.ChargeReceipt
+		return r0, ErrNotConfigured
+	}
+	return mock.ChargeFunc(req)
+}
+
+// ChargeContext calls ChargeContextFunc
+func (mock *MockService) ChargeContext(ctx context.Context, req wallet.ChargeRequest) (*wallet.ChargeReceipt, error) {
+	mock.record("ChargeContext", ctx, req)
+	if mock.ChargeContextFunc == nil {
+		var r0 *wallet.ChargeReceipt
+		return r0, ErrNotConfigured
+	}
+	return mock.ChargeContextFunc(ctx, req)
+}
+
+// CheckHealth calls CheckHealthFunc
+func (mock *MockService) CheckHealth() wallet.HealthReport {
+	mock.record("CheckHealth")
+	if mock.CheckHealthFunc == nil {
+		var r0 wallet.HealthReport
+		return r0
+	}
+	return mock.CheckHealthFunc()
+}
+
+// CheckHotSpots calls CheckHotSpotsFunc
+func (mock *MockService) CheckHotSpots() []wallet.HotWallet {
+	mock.record("CheckHotSpots")
+	if mock.CheckHotSpotsFunc == nil {
+		var r0 []wallet.HotWallet
+		return r0
+	}
+	return mock.CheckHotSpotsFunc()
+}
+
+// CheckSupply calls CheckSupplyFunc
+func (mock *MockService) CheckSupply() []wallet.SupplyDeviation {
+	mock.record("CheckSupply")
+	if mock.CheckSupplyFunc == nil {
+		var r0 []wallet.SupplyDeviation
+		return r0
+	}
+	return mock.CheckSupplyFunc()
+}
+
+// CheckWalletIntegrity calls CheckWalletIntegrityFunc
+func (mock *MockService) CheckWalletIntegrity(userID string) (*wallet.BalanceMismatch, error) {
+	mock.record("CheckWalletIntegrity", userID)
+	if mock.CheckWalletIntegrityFunc == nil {
+		var r0 *wallet.BalanceMismatch
+		return r0, ErrNotConfigured
+	}
+	return mock.CheckWalletIntegrityFunc(userID)
+}
+
+// ChurnedWallets calls ChurnedWalletsFunc
+func (mock *MockService) ChurnedWallets(inactiveFor time.Duration) []wallet.ChurnedWallet {
+	mock.record("ChurnedWallets", inactiveFor)
+	if mock.ChurnedWalletsFunc == nil {
+		var r0 []wallet.ChurnedWallet
+		return r0
+	}
+	return mock.ChurnedWalletsFunc(inactiveFor)
+}
+
+// ClaimGift calls ClaimGiftFunc
+func (mock *MockService) ClaimGift(claimToken string, userID string) (*wallet.Gift, error) {
+	mock.record("ClaimGift", claimToken, userID)
+	if mock.ClaimGiftFunc == nil {
+		var r0 *wallet.Gift
+		return r0, ErrNotConfigured
+	}
+	return mock.ClaimGiftFunc(claimToken, userID)
+}
+
+// ClaimGiftContext calls ClaimGiftContextFunc
+func (mock *MockService) ClaimGiftContext(ctx context.Context, claimToken string, userID string) (*wallet.Gift, error) {
+	mock.record("ClaimGiftContext", ctx, claimToken, userID)
+	if mock.ClaimGiftContextFunc == nil {
+		var r0 *wallet.Gift
+		return r0, ErrNotConfigured
+	}
+	return mock.ClaimGiftContextFunc(ctx, claimToken, userID)
+}
+
+// ClearDisplayPolicy calls ClearDisplayPolicyFunc
+func (mock *MockService) ClearDisplayPolicy(tenant string, currency string) {
+	mock.record("ClearDisplayPolicy", tenant, currency)
+	if mock.ClearDisplayPolicyFunc == nil {
+		return
+	}
+	mock.ClearDisplayPolicyFunc(tenant, currency)
+}
+
+// ClearMaximumBalance calls ClearMaximumBalanceFunc
+func (mock *MockService) ClearMaximumBalance(userID string, currency string) {
+	mock.record("ClearMaximumBalance", userID, currency)
+	if mock.ClearMaximumBalanceFunc == nil {
+		return
+	}
+	mock.ClearMaximumBalanceFunc(userID, currency)
+}
+
+// ClearMinimumBalance calls ClearMinimumBalanceFunc
+func (mock *MockService) ClearMinimumBalance(userID string, currency string) {
+	mock.record("ClearMinimumBalance", userID, currency)
+	if mock.ClearMinimumBalanceFunc == nil {
+		return
+	}
+	mock.ClearMinimumBalanceFunc(userID, currency)
+}
+
+// ClearReceiptTemplate calls ClearReceiptTemplateFunc
+func (mock *MockService) ClearReceiptTemplate(tenant string) {
+	mock.record("ClearReceiptTemplate", tenant)
+	if mock.ClearReceiptTemplateFunc == nil {
+		return
+	}
+	mock.ClearReceiptTemplateFunc(tenant)
+}
+
+// CloseInterestAccount calls CloseInterestAccountFunc
+func (mock *MockService) CloseInterestAccount(userID string) error {
+	mock.record("CloseInterestAccount", userID)
+	if mock.CloseInterestAccountFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CloseInterestAccountFunc(userID)
+}
+
+// CloseWallet calls CloseWalletFunc
+func (mock *MockService) CloseWallet(userID string, req wallet.ClosureRequest) (*wallet.WalletClosure, error) {
+	mock.record("CloseWallet", userID, req)
+	if mock.CloseWalletFunc == nil {
+		var r0 *wallet.WalletClosure
+		return r0, ErrNotConfigured
+	}
+	return mock.CloseWalletFunc(userID, req)
+}
+
+// CloseWalletContext calls CloseWalletContextFunc
+func (mock *MockService) CloseWalletContext(ctx context.Context, userID string, req wallet.ClosureRequest) (*wallet.WalletClosure, error) {
+	mock.record("CloseWalletContext", ctx, userID, req)
+	if mock.CloseWalletContextFunc == nil {
+		var r0 *wallet.WalletClosure
+		return r0, ErrNotConfigured
+	}
+	return mock.CloseWalletContextFunc(ctx, userID, req)
+}
+
+// CompleteStepUp calls CompleteStepUpFunc
+func (mock *MockService) CompleteStepUp(userID string) error {
+	mock.record("CompleteStepUp", userID)
+	if mock.CompleteStepUpFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CompleteStepUpFunc(userID)
+}
+
+// CompleteTransaction calls CompleteTransactionFunc
+func (mock *MockService) CompleteTransaction(txID string) (*wallet.Transaction, error) {
+	mock.record("CompleteTransaction", txID)
+	if mock.CompleteTransactionFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.CompleteTransactionFunc(txID)
+}
+
+// CompleteTransactionContext calls CompleteTransactionContextFunc
+func (mock *MockService) CompleteTransactionContext(ctx context.Context, txID string) (*wallet.Transaction, error) {
+	mock.record("CompleteTransactionContext", ctx, txID)
+	if mock.CompleteTransactionContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.CompleteTransactionContextFunc(ctx, txID)
+}
+
+// ConvertWithQuote calls ConvertWithQuoteFunc
+func (mock *MockService) ConvertWithQuote(quoteID string) (*wallet.Transaction, error) {
+	mock.record("ConvertWithQuote", quoteID)
+	if mock.ConvertWithQuoteFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ConvertWithQuoteFunc(quoteID)
+}
+
+// ConvertWithQuoteContext calls ConvertWithQuoteContextFunc
+func (mock *MockService) ConvertWithQuoteContext(ctx context.Context, quoteID string) (*wallet.Transaction, error) {
+	mock.record("ConvertWithQuoteContext", ctx, quoteID)
+	if mock.ConvertWithQuoteContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ConvertWithQuoteContextFunc(ctx, quoteID)
+}
+
+// CounterpartyLabel calls CounterpartyLabelFunc
+func (mock *MockService) CounterpartyLabel(viewerID string, tx *wallet.Transaction) string {
+	mock.record("CounterpartyLabel", viewerID, tx)
+	if mock.CounterpartyLabelFunc == nil {
+		var r0 string
+		return r0
+	}
+	return mock.CounterpartyLabelFunc(viewerID, tx)
+}
+
+// CreateAutomationRule calls CreateAutomationRuleFunc
+func (mock *MockService) CreateAutomationRule(userID string, name string, trigger wallet.AutomationTrigger, action wallet.AutomationAction) (*wallet.AutomationRule, error) {
+	mock.record("CreateAutomationRule", userID, name, trigger, action)
+	if mock.CreateAutomationRuleFunc == nil {
+		var r0 *wallet.AutomationRule
+		return r0, ErrNotConfigured
+	}
+	return mock.CreateAutomationRuleFunc(userID, name, trigger, action)
+}
+
+// CreateConversionOrder calls CreateConversionOrderFunc
+func (mock *MockService) CreateConversionOrder(userID string, from string, to string, amount decimal.Decimal, at time.Time, recurrence wallet.Recurrence) (*wallet.ConversionOrder, error) {
+	mock.record("CreateConversionOrder", userID, from, to, amount, at, recurrence)
+	if mock.CreateConversionOrderFunc == nil {
+		var r0 *wallet.ConversionOrder
+		return r0, ErrNotConfigured
+	}
+	return mock.CreateConversionOrderFunc(userID, from, to, amount, at, recurrence)
+}
+
+// CreateMandate calls CreateMandateFunc
+func (mock *MockService) CreateMandate(payerID string, merchantID string, maxPerPeriod decimal.Decimal, period wallet.MandatePeriod) (*wallet.Mandate, error) {
+	mock.record("CreateMandate", payerID, merchantID, maxPerPeriod, period)
+	if mock.CreateMandateFunc == nil {
+		var r0 *wallet.Mandate
+		return r0, ErrNotConfigured
+	}
+	return mock.CreateMandateFunc(payerID, merchantID, maxPerPeriod, period)
+}
+
+// CreateOrganization calls CreateOrganizationFunc
+func (mock *MockService) CreateOrganization(orgID string, name string, email string) error {
+	mock.record("CreateOrganization", orgID, name, email)
+	if mock.CreateOrganizationFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CreateOrganizationFunc(orgID, name, email)
+}
+
+// CreatePaymentLink calls CreatePaymentLinkFunc
+func (mock *MockService) CreatePaymentLink(recipientID string, amount decimal.Decimal, description string, expiry time.Duration, usage wallet.PaymentLinkUsage) (*wallet.PaymentLink, error) {
+	mock.record("CreatePaymentLink", recipientID, amount, description, expiry, usage)
+	if mock.CreatePaymentLinkFunc == nil {
+		var r0 *wallet.PaymentLink
+		return r0, ErrNotConfigured
+	}
+	return mock.CreatePaymentLinkFunc(recipientID, amount, description, expiry, usage)
+}
+
+// CreatePendingDeposit calls CreatePendingDepositFunc
+func (mock *MockService) CreatePendingDeposit(userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("CreatePendingDeposit", userID, amount, description)
+	if mock.CreatePendingDepositFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.CreatePendingDepositFunc(userID, amount, description)
+}
+
+// CreateUser calls CreateUserFunc
+func (mock *MockService) CreateUser(userID string, name string, email string) error {
+	mock.record("CreateUser", userID, name, email)
+	if mock.CreateUserFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CreateUserFunc(userID, name, email)
+}
+
+// CreateUserContext calls CreateUserContextFunc
+func (mock *MockService) CreateUserContext(ctx context.Context, userID string, name string, email string) error {
+	mock.record("CreateUserContext", ctx, userID, name, email)
+	if mock.CreateUserContextFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.CreateUserContextFunc(ctx, userID, name, email)
+}
+
+// DeleteAutomationRule calls DeleteAutomationRuleFunc
+func (mock *MockService) DeleteAutomationRule(userID string, ruleID string) error {
+	mock.record("DeleteAutomationRule", userID, ruleID)
+	if mock.DeleteAutomationRuleFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.DeleteAutomationRuleFunc(userID, ruleID)
+}
+
+// Deposit calls DepositFunc
+func (mock *MockService) Deposit(userID string, amount float64, description string) error {
+	mock.record("Deposit", userID, amount, description)
+	if mock.DepositFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.DepositFunc(userID, amount, description)
+}
+
+// DepositContext calls DepositContextFunc
+func (mock *MockService) DepositContext(ctx context.Context, userID string, amount decimal.Decimal, description string) error {
+	mock.record("DepositContext", ctx, userID, amount, description)
+	if mock.DepositContextFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.DepositContextFunc(ctx, userID, amount, description)
+}
+
+// DepositCurrency calls DepositCurrencyFunc
+func (mock *MockService) DepositCurrency(userID string, currency string, amount decimal.Decimal, description string) error {
+	mock.record("DepositCurrency", userID, currency, amount, description)
+	if mock.DepositCurrencyFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.DepositCurrencyFunc(userID, currency, amount, description)
+}
+
+// DepositCurrencyContext calls DepositCurrencyContextFunc
+func (mock *MockService) DepositCurrencyContext(ctx context.Context, userID string, currency string, amount decimal.Decimal, description string) error {
+	mock.record("DepositCurrencyContext", ctx, userID, currency, amount, description)
+	if mock.DepositCurrencyContextFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.DepositCurrencyContextFunc(ctx, userID, currency, amount, description)
+}
+
+// DepositDecimal calls DepositDecimalFunc
+func (mock *MockService) DepositDecimal(userID string, amount decimal.Decimal, description string) error {
+	mock.record("DepositDecimal", userID, amount, description)
+	if mock.DepositDecimalFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.DepositDecimalFunc(userID, amount, description)
+}
+
+// DepositIdempotent calls DepositIdempotentFunc
+func (mock *MockService) DepositIdempotent(key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("DepositIdempotent", key, userID, amount, description)
+	if mock.DepositIdempotentFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.DepositIdempotentFunc(key, userID, amount, description)
+}
+
+// DepositIdempotentContext calls DepositIdempotentContextFunc
+func (mock *MockService) DepositIdempotentContext(ctx context.Context, key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("DepositIdempotentContext", ctx, key, userID, amount, description)
+	if mock.DepositIdempotentContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.DepositIdempotentContextFunc(ctx, key, userID, amount, description)
+}
+
+// DepositRetention calls DepositRetentionFunc
+func (mock *MockService) DepositRetention(from time.Time, to time.Time, g wallet.Granularity, periods int) ([]wallet.RetentionCohort, error) {
+	mock.record("DepositRetention", from, to, g, periods)
+	if mock.DepositRetentionFunc == nil {
+		var r0 []wallet.RetentionCohort
+		return r0, ErrNotConfigured
+	}
+	return mock.DepositRetentionFunc(from, to, g, periods)
+}
+
+// DepositViaRail calls DepositViaRailFunc
+func (mock *MockService) DepositViaRail(userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error) {
+	mock.record("DepositViaRail", userID, account, amount)
+	if mock.DepositViaRailFunc == nil {
+		var r0 *wallet.RailTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.DepositViaRailFunc(userID, account, amount)
+}
+
+// DepositViaRailContext calls DepositViaRailContextFunc
+func (mock *MockService) DepositViaRailContext(ctx context.Context, userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error) {
+	mock.record("DepositViaRailContext", ctx, userID, account, amount)
+	if mock.DepositViaRailContextFunc == nil {
+		var r0 *wallet.RailTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.DepositViaRailContextFunc(ctx, userID, account, amount)
+}
+
+// DispatchWebhooks calls DispatchWebhooksFunc
+func (mock *MockService) DispatchWebhooks() int {
+	mock.record("DispatchWebhooks")
+	if mock.DispatchWebhooksFunc == nil {
+		var r0 int
+		return r0
+	}
+	return mock.DispatchWebhooksFunc()
+}
+
+// DisplayAmount calls DisplayAmountFunc
+func (mock *MockService) DisplayAmount(tenant string, amount decimal.Decimal, currency string) string {
+	mock.record("DisplayAmount", tenant, amount, currency)
+	if mock.DisplayAmountFunc == nil {
+		var r0 string
+		return r0
+	}
+	return mock.DisplayAmountFunc(tenant, amount, currency)
+}
+
+// EmailConflicts calls EmailConflictsFunc
+func (mock *MockService) EmailConflicts() []wallet.EmailConflict {
+	mock.record("EmailConflicts")
+	if mock.EmailConflictsFunc == nil {
+		var r0 []wallet.EmailConflict
+		return r0
+	}
+	return mock.EmailConflictsFunc()
+}
+
+// EncodeEvent calls EncodeEventFunc
+func (mock *MockService) EncodeEvent(evt wallet.Event, version int) (wallet.EventPayload, int, error) {
+	mock.record("EncodeEvent", evt, version)
+	if mock.EncodeEventFunc == nil {
+		var r0 wallet.EventPayload
+		var r1 int
+		return r0, r1, ErrNotConfigured
+	}
+	return mock.EncodeEventFunc(evt, version)
+}
+
+// EncryptedBackup calls EncryptedBackupFunc
+func (mock *MockService) EncryptedBackup(w io.Writer, kw wallet.KeyWrapper) error {
+	mock.record("EncryptedBackup", w, kw)
+	if mock.EncryptedBackupFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.EncryptedBackupFunc(w, kw)
+}
+
+// EndImpersonation calls EndImpersonationFunc
+func (mock *MockService) EndImpersonation(sessionID string) error {
+	mock.record("EndImpersonation", sessionID)
+	if mock.EndImpersonationFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.EndImpersonationFunc(sessionID)
+}
+
+// Events calls EventsFunc
+func (mock *MockService) Events(ctx context.Context, types ...wallet.EventType) <-chan wallet.Event {
+	mock.record("Events", ctx, types)
+	if mock.EventsFunc == nil {
+		var r0 <-chan wallet.Event
+		return r0
+	}
+	return mock.EventsFunc(ctx, types...)
+}
+
+// EventsSince calls EventsSinceFunc
+func (mock *MockService) EventsSince(offset int64, limit int) []wallet.Event {
+	mock.record("EventsSince", offset, limit)
+	if mock.EventsSinceFunc == nil {
+		var r0 []wallet.Event
+		return r0
+	}
+	return mock.EventsSinceFunc(offset, limit)
+}
+
+// ExpireClientTxIDs calls ExpireClientTxIDsFunc
+func (mock *MockService) ExpireClientTxIDs() (int, error) {
+	mock.record("ExpireClientTxIDs")
+	if mock.ExpireClientTxIDsFunc == nil {
+		var r0 int
+		return r0, ErrNotConfigured
+	}
+	return mock.ExpireClientTxIDsFunc()
+}
+
+// ExplainInterest calls ExplainInterestFunc
+func (mock *MockService) ExplainInterest(userID string, period wallet.InterestPeriod) (*wallet.InterestStatement, error) {
+	mock.record("ExplainInterest", userID, period)
+	if mock.ExplainInterestFunc == nil {
+		var r0 *wallet.InterestStatement
+		return r0, ErrNotConfigured
+	}
+	return mock.ExplainInterestFunc(userID, period)
+}
+
+// ExportAllHistories calls ExportAllHistoriesFunc
+func (mock *MockService) ExportAllHistories(ctx context.Context, sink wallet.ExportSink, opts wallet.BulkExportOptions) (wallet.ExportCheckpoint, error) {
+	mock.record("ExportAllHistories", ctx, sink, opts)
+	if mock.ExportAllHistoriesFunc == nil {
+		var r0 wallet.ExportCheckpoint
+		return r0, ErrNotConfigured
+	}
+	return mock.ExportAllHistoriesFunc(ctx, sink, opts)
+}
+
+// ExportTenant calls ExportTenantFunc
+func (mock *MockService) ExportTenant(tenantID string, includeHistory bool) (*wallet.TenantBundle, error) {
+	mock.record("ExportTenant", tenantID, includeHistory)
+	if mock.ExportTenantFunc == nil {
+		var r0 *wallet.TenantBundle
+		return r0, ErrNotConfigured
+	}
+	return mock.ExportTenantFunc(tenantID, includeHistory)
+}
+
+// ExportTransactionHistory calls ExportTransactionHistoryFunc
+func (mock *MockService) ExportTransactionHistory(userID string, w io.Writer) error {
+	mock.record("ExportTransactionHistory", userID, w)
+	if mock.ExportTransactionHistoryFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ExportTransactionHistoryFunc(userID, w)
+}
+
+// FailTransaction calls FailTransactionFunc
+func (mock *MockService) FailTransaction(txID string, reason string) (*wallet.Transaction, error) {
+	mock.record("FailTransaction", txID, reason)
+	if mock.FailTransactionFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.FailTransactionFunc(txID, reason)
+}
+
+// FirstTransactionConversion calls FirstTransactionConversionFunc
+func (mock *MockService) FirstTransactionConversion(from time.Time, to time.Time, g wallet.Granularity, within time.Duration) ([]wallet.ConversionBucket, error) {
+	mock.record("FirstTransactionConversion", from, to, g, within)
+	if mock.FirstTransactionConversionFunc == nil {
+		var r0 []wallet.ConversionBucket
+		return r0, ErrNotConfigured
+	}
+	return mock.FirstTransactionConversionFunc(from, to, g, within)
+}
+
+// FormatAmount calls FormatAmountFunc
+func (mock *MockService) FormatAmount(amount decimal.Decimal, code string) (string, error) {
+	mock.record("FormatAmount", amount, code)
+	if mock.FormatAmountFunc == nil {
+		var r0 string
+		return r0, ErrNotConfigured
+	}
+	return mock.FormatAmountFunc(amount, code)
+}
+
+// FreezeCard calls FreezeCardFunc
+func (mock *MockService) FreezeCard(cardID string, userID string) error {
+	mock.record("FreezeCard", cardID, userID)
+	if mock.FreezeCardFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.FreezeCardFunc(cardID, userID)
+}
+
+// FreezeSegment calls FreezeSegmentFunc
+func (mock *MockService) FreezeSegment(req wallet.SegmentActionRequest) (*wallet.SegmentAction, error) {
+	mock.record("FreezeSegment", req)
+	if mock.FreezeSegmentFunc == nil {
+		var r0 *wallet.SegmentAction
+		return r0, ErrNotConfigured
+	}
+	return mock.FreezeSegmentFunc(req)
+}
+
+// FreezeSegmentContext calls FreezeSegmentContextFunc
+func (mock *MockService) FreezeSegmentContext(ctx context.Context, req wallet.SegmentActionRequest) (*wallet.SegmentAction, error) {
+	mock.record("FreezeSegmentContext", ctx, req)
+	if mock.FreezeSegmentContextFunc == nil {
+		var r0 *wallet.SegmentAction
+		return r0, ErrNotConfigured
+	}
+	return mock.FreezeSegmentContextFunc(ctx, req)
+}
+
+// GetAdjustmentLevels calls GetAdjustmentLevelsFunc
+func (mock *MockService) GetAdjustmentLevels() []wallet.AdjustmentLevel {
+	mock.record("GetAdjustmentLevels")
+	if mock.GetAdjustmentLevelsFunc == nil {
+		var r0 []wallet.AdjustmentLevel
+		return r0
+	}
+	return mock.GetAdjustmentLevelsFunc()
+}
+
+// GetAdjustmentRequest calls GetAdjustmentRequestFunc
+func (mock *MockService) GetAdjustmentRequest(requestID string) (*wallet.AdjustmentRequest, error) {
+	mock.record("GetAdjustmentRequest", requestID)
+	if mock.GetAdjustmentRequestFunc == nil {
+		var r0 *wallet.AdjustmentRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.GetAdjustmentRequestFunc(requestID)
+}
+
+// GetAdminFreeze calls GetAdminFreezeFunc
+func (mock *MockService) GetAdminFreeze(userID string) *wallet.AdminFreeze {
+	mock.record("GetAdminFreeze", userID)
+	if mock.GetAdminFreezeFunc == nil {
+		var r0 *wallet.AdminFreeze
+		return r0
+	}
+	return mock.GetAdminFreezeFunc(userID)
+}
+
+// GetAllUsers calls GetAllUsersFunc
+func (mock *MockService) GetAllUsers() []*wallet.User {
+	mock.record("GetAllUsers")
+	if mock.GetAllUsersFunc == nil {
+		var r0 []*wallet.User
+		return r0
+	}
+	return mock.GetAllUsersFunc()
+}
+
+// GetAuthorization calls GetAuthorizationFunc
+func (mock *MockService) GetAuthorization(authID string) (*wallet.CardAuthorization, error) {
+	mock.record("GetAuthorization", authID)
+	if mock.GetAuthorizationFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.GetAuthorizationFunc(authID)
+}
+
+// GetAutoSettle calls GetAutoSettleFunc
+func (mock *MockService) GetAutoSettle(userID string) (bool, error) {
+	mock.record("GetAutoSettle", userID)
+	if mock.GetAutoSettleFunc == nil {
+		var r0 bool
+		return r0, ErrNotConfigured
+	}
+	return mock.GetAutoSettleFunc(userID)
+}
+
+// GetAvailableBalance calls GetAvailableBalanceFunc
+func (mock *MockService) GetAvailableBalance(userID string) (decimal.Decimal, error) {
+	mock.record("GetAvailableBalance", userID)
+	if mock.GetAvailableBalanceFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetAvailableBalanceFunc(userID)
+}
+
+// GetBalance calls GetBalanceFunc
+func (mock *MockService) GetBalance(userID string) (float64, error) {
+	mock.record("GetBalance", userID)
+	if mock.GetBalanceFunc == nil {
+		var r0 float64
+		return r0, ErrNotConfigured
+	}
+	return mock.GetBalanceFunc(userID)
+}
+
+// GetBalanceAt calls GetBalanceAtFunc
+func (mock *MockService) GetBalanceAt(userID string, at time.Time) (decimal.Decimal, error) {
+	mock.record("GetBalanceAt", userID, at)
+	if mock.GetBalanceAtFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetBalanceAtFunc(userID, at)
+}
+
+// GetBalanceContext calls GetBalanceContextFunc
+func (mock *MockService) GetBalanceContext(ctx context.Context, userID string) (decimal.Decimal, error) {
+	mock.record("GetBalanceContext", ctx, userID)
+	if mock.GetBalanceContextFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetBalanceContextFunc(ctx, userID)
+}
+
+// GetBalanceDecimal calls GetBalanceDecimalFunc
+func (mock *MockService) GetBalanceDecimal(userID string) (decimal.Decimal, error) {
+	mock.record("GetBalanceDecimal", userID)
+	if mock.GetBalanceDecimalFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetBalanceDecimalFunc(userID)
+}
+
+// GetBalances calls GetBalancesFunc
+func (mock *MockService) GetBalances(userIDs []string) (map[string]decimal.Decimal, error) {
+	mock.record("GetBalances", userIDs)
+	if mock.GetBalancesFunc == nil {
+		var r0 map[string]decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetBalancesFunc(userIDs)
+}
+
+// GetBlockedUsers calls GetBlockedUsersFunc
+func (mock *MockService) GetBlockedUsers(userID string) ([]wallet.BlockedUser, error) {
+	mock.record("GetBlockedUsers", userID)
+	if mock.GetBlockedUsersFunc == nil {
+		var r0 []wallet.BlockedUser
+		return r0, ErrNotConfigured
+	}
+	return mock.GetBlockedUsersFunc(userID)
+}
+
+// GetCard calls GetCardFunc
+func (mock *MockService) GetCard(cardID string) (*wallet.Card, error) {
+	mock.record("GetCard", cardID)
+	if mock.GetCardFunc == nil {
+		var r0 *wallet.Card
+		return r0, ErrNotConfigured
+	}
+	return mock.GetCardFunc(cardID)
+}
+
+// GetCase calls GetCaseFunc
+func (mock *MockService) GetCase(caseID string) (*wallet.ComplianceCase, error) {
+	mock.record("GetCase", caseID)
+	if mock.GetCaseFunc == nil {
+		var r0 *wallet.ComplianceCase
+		return r0, ErrNotConfigured
+	}
+	return mock.GetCaseFunc(caseID)
+}
+
+// GetCaseEvidence calls GetCaseEvidenceFunc
+func (mock *MockService) GetCaseEvidence(caseID string, evidenceID string) ([]byte, error) {
+	mock.record("GetCaseEvidence", caseID, evidenceID)
+	if mock.GetCaseEvidenceFunc == nil {
+		var r0 []byte
+		return r0, ErrNotConfigured
+	}
+	return mock.GetCaseEvidenceFunc(caseID, evidenceID)
+}
+
+// GetCaseMetrics calls GetCaseMetricsFunc
+func (mock *MockService) GetCaseMetrics() wallet.CaseMetrics {
+	mock.record("GetCaseMetrics")
+	if mock.GetCaseMetricsFunc == nil {
+		var r0 wallet.CaseMetrics
+		return r0
+	}
+	return mock.GetCaseMetricsFunc()
+}
+
+// GetClosureStatus calls GetClosureStatusFunc
+func (mock *MockService) GetClosureStatus(userID string) (*wallet.WalletClosure, error) {
+	mock.record("GetClosureStatus", userID)
+	if mock.GetClosureStatusFunc == nil {
+		var r0 *wallet.WalletClosure
+		return r0, ErrNotConfigured
+	}
+	return mock.GetClosureStatusFunc(userID)
+}
+
+// GetConsentHistory calls GetConsentHistoryFunc
+func (mock *MockService) GetConsentHistory(userID string) ([]wallet.ConsentRecord, error) {
+	mock.record("GetConsentHistory", userID)
+	if mock.GetConsentHistoryFunc == nil {
+		var r0 []wallet.ConsentRecord
+		return r0, ErrNotConfigured
+	}
+	return mock.GetConsentHistoryFunc(userID)
+}
+
+// GetConsentVersion calls GetConsentVersionFunc
+func (mock *MockService) GetConsentVersion(kind wallet.ConsentKind) (*wallet.ConsentVersion, error) {
+	mock.record("GetConsentVersion", kind)
+	if mock.GetConsentVersionFunc == nil {
+		var r0 *wallet.ConsentVersion
+		return r0, ErrNotConfigured
+	}
+	return mock.GetConsentVersionFunc(kind)
+}
+
+// GetConversionOrder calls GetConversionOrderFunc
+func (mock *MockService) GetConversionOrder(orderID string) (*wallet.ConversionOrder, error) {
+	mock.record("GetConversionOrder", orderID)
+	if mock.GetConversionOrderFunc == nil {
+		var r0 *wallet.ConversionOrder
+		return r0, ErrNotConfigured
+	}
+	return mock.GetConversionOrderFunc(orderID)
+}
+
+// GetCurrency calls GetCurrencyFunc
+func (mock *MockService) GetCurrency(code string) (wallet.Currency, error) {
+	mock.record("GetCurrency", code)
+	if mock.GetCurrencyFunc == nil {
+		var r0 wallet.Currency
+		return r0, ErrNotConfigured
+	}
+	return mock.GetCurrencyFunc(code)
+}
+
+// GetCurrencyBalance calls GetCurrencyBalanceFunc
+func (mock *MockService) GetCurrencyBalance(userID string, currency string) (decimal.Decimal, error) {
+	mock.record("GetCurrencyBalance", userID, currency)
+	if mock.GetCurrencyBalanceFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetCurrencyBalanceFunc(userID, currency)
+}
+
+// GetDisplayPolicy calls GetDisplayPolicyFunc
+func (mock *MockService) GetDisplayPolicy(tenant string, currency string) (wallet.DisplayPolicy, error) {
+	mock.record("GetDisplayPolicy", tenant, currency)
+	if mock.GetDisplayPolicyFunc == nil {
+		var r0 wallet.DisplayPolicy
+		return r0, ErrNotConfigured
+	}
+	return mock.GetDisplayPolicyFunc(tenant, currency)
+}
+
+// GetExpense calls GetExpenseFunc
+func (mock *MockService) GetExpense(expenseID string) (*wallet.ExpenseRequest, error) {
+	mock.record("GetExpense", expenseID)
+	if mock.GetExpenseFunc == nil {
+		var r0 *wallet.ExpenseRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.GetExpenseFunc(expenseID)
+}
+
+// GetFailedOperation calls GetFailedOperationFunc
+func (mock *MockService) GetFailedOperation(failureID string) (*wallet.FailedOperation, error) {
+	mock.record("GetFailedOperation", failureID)
+	if mock.GetFailedOperationFunc == nil {
+		var r0 *wallet.FailedOperation
+		return r0, ErrNotConfigured
+	}
+	return mock.GetFailedOperationFunc(failureID)
+}
+
+// GetFederatedTransfer calls GetFederatedTransferFunc
+func (mock *MockService) GetFederatedTransfer(voucherID string) (*wallet.OutboundTransfer, error) {
+	mock.record("GetFederatedTransfer", voucherID)
+	if mock.GetFederatedTransferFunc == nil {
+		var r0 *wallet.OutboundTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.GetFederatedTransferFunc(voucherID)
+}
+
+// GetGift calls GetGiftFunc
+func (mock *MockService) GetGift(giftID string) (*wallet.Gift, error) {
+	mock.record("GetGift", giftID)
+	if mock.GetGiftFunc == nil {
+		var r0 *wallet.Gift
+		return r0, ErrNotConfigured
+	}
+	return mock.GetGiftFunc(giftID)
+}
+
+// GetHold calls GetHoldFunc
+func (mock *MockService) GetHold(holdID string) (*wallet.Hold, error) {
+	mock.record("GetHold", holdID)
+	if mock.GetHoldFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.GetHoldFunc(holdID)
+}
+
+// GetHotWallets calls GetHotWalletsFunc
+func (mock *MockService) GetHotWallets(topN int) []wallet.HotWallet {
+	mock.record("GetHotWallets", topN)
+	if mock.GetHotWalletsFunc == nil {
+		var r0 []wallet.HotWallet
+		return r0
+	}
+	return mock.GetHotWalletsFunc(topN)
+}
+
+// GetImpersonationSession calls GetImpersonationSessionFunc
+func (mock *MockService) GetImpersonationSession(sessionID string) (*wallet.ImpersonationSession, error) {
+	mock.record("GetImpersonationSession", sessionID)
+	if mock.GetImpersonationSessionFunc == nil {
+		var r0 *wallet.ImpersonationSession
+		return r0, ErrNotConfigured
+	}
+	return mock.GetImpersonationSessionFunc(sessionID)
+}
+
+// GetInterestAccount calls GetInterestAccountFunc
+func (mock *MockService) GetInterestAccount(userID string) (*wallet.InterestAccount, error) {
+	mock.record("GetInterestAccount", userID)
+	if mock.GetInterestAccountFunc == nil {
+		var r0 *wallet.InterestAccount
+		return r0, ErrNotConfigured
+	}
+	return mock.GetInterestAccountFunc(userID)
+}
+
+// GetJob calls GetJobFunc
+func (mock *MockService) GetJob(jobID string) (*wallet.ScheduledJob, error) {
+	mock.record("GetJob", jobID)
+	if mock.GetJobFunc == nil {
+		var r0 *wallet.ScheduledJob
+		return r0, ErrNotConfigured
+	}
+	return mock.GetJobFunc(jobID)
+}
+
+// GetLockStats calls GetLockStatsFunc
+func (mock *MockService) GetLockStats() []wallet.LaneStats {
+	mock.record("GetLockStats")
+	if mock.GetLockStatsFunc == nil {
+		var r0 []wallet.LaneStats
+		return r0
+	}
+	return mock.GetLockStatsFunc()
+}
+
+// GetLoyaltySummary calls GetLoyaltySummaryFunc
+func (mock *MockService) GetLoyaltySummary() wallet.LoyaltySummary {
+	mock.record("GetLoyaltySummary")
+	if mock.GetLoyaltySummaryFunc == nil {
+		var r0 wallet.LoyaltySummary
+		return r0
+	}
+	return mock.GetLoyaltySummaryFunc()
+}
+
+// GetMandate calls GetMandateFunc
+func (mock *MockService) GetMandate(mandateID string) (*wallet.Mandate, error) {
+	mock.record("GetMandate", mandateID)
+	if mock.GetMandateFunc == nil {
+		var r0 *wallet.Mandate
+		return r0, ErrNotConfigured
+	}
+	return mock.GetMandateFunc(mandateID)
+}
+
+// GetMaximumBalance calls GetMaximumBalanceFunc
+func (mock *MockService) GetMaximumBalance(userID string, currency string) (decimal.Decimal, bool, error) {
+	mock.record("GetMaximumBalance", userID, currency)
+	if mock.GetMaximumBalanceFunc == nil {
+		var r0 decimal.Decimal
+		var r1 bool
+		return r0, r1, ErrNotConfigured
+	}
+	return mock.GetMaximumBalanceFunc(userID, currency)
+}
+
+// GetMinimumBalance calls GetMinimumBalanceFunc
+func (mock *MockService) GetMinimumBalance(userID string, currency string) (wallet.MinimumBalance, error) {
+	mock.record("GetMinimumBalance", userID, currency)
+	if mock.GetMinimumBalanceFunc == nil {
+		var r0 wallet.MinimumBalance
+		return r0, ErrNotConfigured
+	}
+	return mock.GetMinimumBalanceFunc(userID, currency)
+}
+
+// GetNotificationPreferences calls GetNotificationPreferencesFunc
+func (mock *MockService) GetNotificationPreferences(userID string) (wallet.NotificationPreferences, bool) {
+	mock.record("GetNotificationPreferences", userID)
+	if mock.GetNotificationPreferencesFunc == nil {
+		var r0 wallet.NotificationPreferences
+		var r1 bool
+		return r0, r1
+	}
+	return mock.GetNotificationPreferencesFunc(userID)
+}
+
+// GetOperationStats calls GetOperationStatsFunc
+func (mock *MockService) GetOperationStats() []wallet.OperationStats {
+	mock.record("GetOperationStats")
+	if mock.GetOperationStatsFunc == nil {
+		var r0 []wallet.OperationStats
+		return r0
+	}
+	return mock.GetOperationStatsFunc()
+}
+
+// GetOrderReservation calls GetOrderReservationFunc
+func (mock *MockService) GetOrderReservation(orderID string) (*wallet.Reservation, error) {
+	mock.record("GetOrderReservation", orderID)
+	if mock.GetOrderReservationFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.GetOrderReservationFunc(orderID)
+}
+
+// GetOrderSettlement calls GetOrderSettlementFunc
+func (mock *MockService) GetOrderSettlement(orderRef string) (*wallet.OrderSettlement, error) {
+	mock.record("GetOrderSettlement", orderRef)
+	if mock.GetOrderSettlementFunc == nil {
+		var r0 *wallet.OrderSettlement
+		return r0, ErrNotConfigured
+	}
+	return mock.GetOrderSettlementFunc(orderRef)
+}
+
+// GetOverdraft calls GetOverdraftFunc
+func (mock *MockService) GetOverdraft(userID string) (*wallet.Overdraft, error) {
+	mock.record("GetOverdraft", userID)
+	if mock.GetOverdraftFunc == nil {
+		var r0 *wallet.Overdraft
+		return r0, ErrNotConfigured
+	}
+	return mock.GetOverdraftFunc(userID)
+}
+
+// GetPaymentLink calls GetPaymentLinkFunc
+func (mock *MockService) GetPaymentLink(linkID string) (*wallet.PaymentLink, error) {
+	mock.record("GetPaymentLink", linkID)
+	if mock.GetPaymentLinkFunc == nil {
+		var r0 *wallet.PaymentLink
+		return r0, ErrNotConfigured
+	}
+	return mock.GetPaymentLinkFunc(linkID)
+}
+
+// GetPendingExpenses calls GetPendingExpensesFunc
+func (mock *MockService) GetPendingExpenses(orgID string) ([]*wallet.ExpenseRequest, error) {
+	mock.record("GetPendingExpenses", orgID)
+	if mock.GetPendingExpensesFunc == nil {
+		var r0 []*wallet.ExpenseRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.GetPendingExpensesFunc(orgID)
+}
+
+// GetPendingItems calls GetPendingItemsFunc
+func (mock *MockService) GetPendingItems(userID string) ([]wallet.PendingItem, error) {
+	mock.record("GetPendingItems", userID)
+	if mock.GetPendingItemsFunc == nil {
+		var r0 []wallet.PendingItem
+		return r0, ErrNotConfigured
+	}
+	return mock.GetPendingItemsFunc(userID)
+}
+
+// GetPointsBalance calls GetPointsBalanceFunc
+func (mock *MockService) GetPointsBalance(userID string) (int64, error) {
+	mock.record("GetPointsBalance", userID)
+	if mock.GetPointsBalanceFunc == nil {
+		var r0 int64
+		return r0, ErrNotConfigured
+	}
+	return mock.GetPointsBalanceFunc(userID)
+}
+
+// GetPointsHistory calls GetPointsHistoryFunc
+func (mock *MockService) GetPointsHistory(userID string) ([]wallet.PointsEntry, error) {
+	mock.record("GetPointsHistory", userID)
+	if mock.GetPointsHistoryFunc == nil {
+		var r0 []wallet.PointsEntry
+		return r0, ErrNotConfigured
+	}
+	return mock.GetPointsHistoryFunc(userID)
+}
+
+// GetRailTransfer calls GetRailTransferFunc
+func (mock *MockService) GetRailTransfer(id string) (*wallet.RailTransfer, error) {
+	mock.record("GetRailTransfer", id)
+	if mock.GetRailTransferFunc == nil {
+		var r0 *wallet.RailTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.GetRailTransferFunc(id)
+}
+
+// GetRateRecord calls GetRateRecordFunc
+func (mock *MockService) GetRateRecord(id string) (*wallet.RateRecord, error) {
+	mock.record("GetRateRecord", id)
+	if mock.GetRateRecordFunc == nil {
+		var r0 *wallet.RateRecord
+		return r0, ErrNotConfigured
+	}
+	return mock.GetRateRecordFunc(id)
+}
+
+// GetReceipt calls GetReceiptFunc
+func (mock *MockService) GetReceipt(receiptID string) (*wallet.Receipt, error) {
+	mock.record("GetReceipt", receiptID)
+	if mock.GetReceiptFunc == nil {
+		var r0 *wallet.Receipt
+		return r0, ErrNotConfigured
+	}
+	return mock.GetReceiptFunc(receiptID)
+}
+
+// GetRefundableAmount calls GetRefundableAmountFunc
+func (mock *MockService) GetRefundableAmount(txID string) (decimal.Decimal, error) {
+	mock.record("GetRefundableAmount", txID)
+	if mock.GetRefundableAmountFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.GetRefundableAmountFunc(txID)
+}
+
+// GetReservation calls GetReservationFunc
+func (mock *MockService) GetReservation(reservationID string) (*wallet.Reservation, error) {
+	mock.record("GetReservation", reservationID)
+	if mock.GetReservationFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.GetReservationFunc(reservationID)
+}
+
+// GetReserve calls GetReserveFunc
+func (mock *MockService) GetReserve(userID string) (*wallet.ReserveSummary, error) {
+	mock.record("GetReserve", userID)
+	if mock.GetReserveFunc == nil {
+		var r0 *wallet.ReserveSummary
+		return r0, ErrNotConfigured
+	}
+	return mock.GetReserveFunc(userID)
+}
+
+// GetReservePolicy calls GetReservePolicyFunc
+func (mock *MockService) GetReservePolicy(userID string) (*wallet.ReservePolicy, error) {
+	mock.record("GetReservePolicy", userID)
+	if mock.GetReservePolicyFunc == nil {
+		var r0 *wallet.ReservePolicy
+		return r0, ErrNotConfigured
+	}
+	return mock.GetReservePolicyFunc(userID)
+}
+
+// GetRestriction calls GetRestrictionFunc
+func (mock *MockService) GetRestriction(userID string) (*wallet.Restriction, bool) {
+	mock.record("GetRestriction", userID)
+	if mock.GetRestrictionFunc == nil {
+		var r0 *wallet.Restriction
+		var r1 bool
+		return r0, r1
+	}
+	return mock.GetRestrictionFunc(userID)
+}
+
+// GetRetentionStats calls GetRetentionStatsFunc
+func (mock *MockService) GetRetentionStats() wallet.RetentionStats {
+	mock.record("GetRetentionStats")
+	if mock.GetRetentionStatsFunc == nil {
+		var r0 wallet.RetentionStats
+		return r0
+	}
+	return mock.GetRetentionStatsFunc()
+}
+
+// GetRuleExecutions calls GetRuleExecutionsFunc
+func (mock *MockService) GetRuleExecutions(ruleID string) ([]wallet.RuleExecution, error) {
+	mock.record("GetRuleExecutions", ruleID)
+	if mock.GetRuleExecutionsFunc == nil {
+		var r0 []wallet.RuleExecution
+		return r0, ErrNotConfigured
+	}
+	return mock.GetRuleExecutionsFunc(ruleID)
+}
+
+// GetSegmentAction calls GetSegmentActionFunc
+func (mock *MockService) GetSegmentAction(actionID string) (*wallet.SegmentAction, error) {
+	mock.record("GetSegmentAction", actionID)
+	if mock.GetSegmentActionFunc == nil {
+		var r0 *wallet.SegmentAction
+		return r0, ErrNotConfigured
+	}
+	return mock.GetSegmentActionFunc(actionID)
+}
+
+// GetSpendingLimits calls GetSpendingLimitsFunc
+func (mock *MockService) GetSpendingLimits(userID string) (wallet.SpendingLimits, error) {
+	mock.record("GetSpendingLimits", userID)
+	if mock.GetSpendingLimitsFunc == nil {
+		var r0 wallet.SpendingLimits
+		return r0, ErrNotConfigured
+	}
+	return mock.GetSpendingLimitsFunc(userID)
+}
+
+// GetSpendingUsage calls GetSpendingUsageFunc
+func (mock *MockService) GetSpendingUsage(userID string) (*wallet.SpendingUsage, error) {
+	mock.record("GetSpendingUsage", userID)
+	if mock.GetSpendingUsageFunc == nil {
+		var r0 *wallet.SpendingUsage
+		return r0, ErrNotConfigured
+	}
+	return mock.GetSpendingUsageFunc(userID)
+}
+
+// GetSystemAccounts calls GetSystemAccountsFunc
+func (mock *MockService) GetSystemAccounts() []wallet.SystemAccount {
+	mock.record("GetSystemAccounts")
+	if mock.GetSystemAccountsFunc == nil {
+		var r0 []wallet.SystemAccount
+		return r0
+	}
+	return mock.GetSystemAccountsFunc()
+}
+
+// GetTotalSupply calls GetTotalSupplyFunc
+func (mock *MockService) GetTotalSupply() map[string]decimal.Decimal {
+	mock.record("GetTotalSupply")
+	if mock.GetTotalSupplyFunc == nil {
+		var r0 map[string]decimal.Decimal
+		return r0
+	}
+	return mock.GetTotalSupplyFunc()
+}
+
+// GetTransaction calls GetTransactionFunc
+func (mock *MockService) GetTransaction(txID string) (*wallet.Transaction, error) {
+	mock.record("GetTransaction", txID)
+	if mock.GetTransactionFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.GetTransactionFunc(txID)
+}
+
+// GetTransactionHistory calls GetTransactionHistoryFunc
+func (mock *MockService) GetTransactionHistory(userID string) ([]*wallet.Transaction, error) {
+	mock.record("GetTransactionHistory", userID)
+	if mock.GetTransactionHistoryFunc == nil {
+		var r0 []*wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.GetTransactionHistoryFunc(userID)
+}
+
+// GetTransactionHistoryContext calls GetTransactionHistoryContextFunc
+func (mock *MockService) GetTransactionHistoryContext(ctx context.Context, userID string) ([]*wallet.Transaction, error) {
+	mock.record("GetTransactionHistoryContext", ctx, userID)
+	if mock.GetTransactionHistoryContextFunc == nil {
+		var r0 []*wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.GetTransactionHistoryContextFunc(ctx, userID)
+}
+
+// GetTransactionReceipts calls GetTransactionReceiptsFunc
+func (mock *MockService) GetTransactionReceipts(txID string) []wallet.Receipt {
+	mock.record("GetTransactionReceipts", txID)
+	if mock.GetTransactionReceiptsFunc == nil {
+		var r0 []wallet.Receipt
+		return r0
+	}
+	return mock.GetTransactionReceiptsFunc(txID)
+}
+
+// GetTransactionStatus calls GetTransactionStatusFunc
+func (mock *MockService) GetTransactionStatus(txID string) (wallet.TransactionStatus, error) {
+	mock.record("GetTransactionStatus", txID)
+	if mock.GetTransactionStatusFunc == nil {
+		var r0 wallet.TransactionStatus
+		return r0, ErrNotConfigured
+	}
+	return mock.GetTransactionStatusFunc(txID)
+}
+
+// GetTransactionTree calls GetTransactionTreeFunc
+func (mock *MockService) GetTransactionTree(txID string) (*wallet.TransactionTree, error) {
+	mock.record("GetTransactionTree", txID)
+	if mock.GetTransactionTreeFunc == nil {
+		var r0 *wallet.TransactionTree
+		return r0, ErrNotConfigured
+	}
+	return mock.GetTransactionTreeFunc(txID)
+}
+
+// GetUserAttributes calls GetUserAttributesFunc
+func (mock *MockService) GetUserAttributes(userID string) (wallet.UserAttributes, error) {
+	mock.record("GetUserAttributes", userID)
+	if mock.GetUserAttributesFunc == nil {
+		var r0 wallet.UserAttributes
+		return r0, ErrNotConfigured
+	}
+	return mock.GetUserAttributesFunc(userID)
+}
+
+// GetUserByEmail calls GetUserByEmailFunc
+func (mock *MockService) GetUserByEmail(email string) (*wallet.User, error) {
+	mock.record("GetUserByEmail", email)
+	if mock.GetUserByEmailFunc == nil {
+		var r0 *wallet.User
+		return r0, ErrNotConfigured
+	}
+	return mock.GetUserByEmailFunc(email)
+}
+
+// GetUserByIdentity calls GetUserByIdentityFunc
+func (mock *MockService) GetUserByIdentity(kind wallet.IdentityKind, issuer string, subject string) (*wallet.User, error) {
+	mock.record("GetUserByIdentity", kind, issuer, subject)
+	if mock.GetUserByIdentityFunc == nil {
+		var r0 *wallet.User
+		return r0, ErrNotConfigured
+	}
+	return mock.GetUserByIdentityFunc(kind, issuer, subject)
+}
+
+// GetWallet calls GetWalletFunc
+func (mock *MockService) GetWallet(userID string) (*wallet.WalletSnapshot, error) {
+	mock.record("GetWallet", userID)
+	if mock.GetWalletFunc == nil {
+		var r0 *wallet.WalletSnapshot
+		return r0, ErrNotConfigured
+	}
+	return mock.GetWalletFunc(userID)
+}
+
+// GetWebhookSubscription calls GetWebhookSubscriptionFunc
+func (mock *MockService) GetWebhookSubscription(subscriptionID string) (*wallet.WebhookSubscription, error) {
+	mock.record("GetWebhookSubscription", subscriptionID)
+	if mock.GetWebhookSubscriptionFunc == nil {
+		var r0 *wallet.WebhookSubscription
+		return r0, ErrNotConfigured
+	}
+	return mock.GetWebhookSubscriptionFunc(subscriptionID)
+}
+
+// HandleRailCallback calls HandleRailCallbackFunc
+func (mock *MockService) HandleRailCallback(cb wallet.RailCallback) error {
+	mock.record("HandleRailCallback", cb)
+	if mock.HandleRailCallbackFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.HandleRailCallbackFunc(cb)
+}
+
+// HandleRailCallbackContext calls HandleRailCallbackContextFunc
+func (mock *MockService) HandleRailCallbackContext(ctx context.Context, cb wallet.RailCallback) error {
+	mock.record("HandleRailCallbackContext", ctx, cb)
+	if mock.HandleRailCallbackContextFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.HandleRailCallbackContextFunc(ctx, cb)
+}
+
+// Hold calls HoldFunc
+func (mock *MockService) Hold(userID string, amount decimal.Decimal) (*wallet.Hold, error) {
+	mock.record("Hold", userID, amount)
+	if mock.HoldFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.HoldFunc(userID, amount)
+}
+
+// HoldContext calls HoldContextFunc
+func (mock *MockService) HoldContext(ctx context.Context, userID string, amount decimal.Decimal) (*wallet.Hold, error) {
+	mock.record("HoldContext", ctx, userID, amount)
+	if mock.HoldContextFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.HoldContextFunc(ctx, userID, amount)
+}
+
+// ImpersonatedBalance calls ImpersonatedBalanceFunc
+func (mock *MockService) ImpersonatedBalance(sessionID string) (decimal.Decimal, error) {
+	mock.record("ImpersonatedBalance", sessionID)
+	if mock.ImpersonatedBalanceFunc == nil {
+		var r0 decimal.Decimal
+		return r0, ErrNotConfigured
+	}
+	return mock.ImpersonatedBalanceFunc(sessionID)
+}
+
+// ImpersonatedHistory calls ImpersonatedHistoryFunc
+func (mock *MockService) ImpersonatedHistory(sessionID string) ([]*wallet.Transaction, error) {
+	mock.record("ImpersonatedHistory", sessionID)
+	if mock.ImpersonatedHistoryFunc == nil {
+		var r0 []*wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ImpersonatedHistoryFunc(sessionID)
+}
+
+// ImpersonatedPendingItems calls ImpersonatedPendingItemsFunc
+func (mock *MockService) ImpersonatedPendingItems(sessionID string) ([]wallet.PendingItem, error) {
+	mock.record("ImpersonatedPendingItems", sessionID)
+	if mock.ImpersonatedPendingItemsFunc == nil {
+		var r0 []wallet.PendingItem
+		return r0, ErrNotConfigured
+	}
+	return mock.ImpersonatedPendingItemsFunc(sessionID)
+}
+
+// ImpersonatedTransfer calls ImpersonatedTransferFunc
+func (mock *MockService) ImpersonatedTransfer(sessionID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("ImpersonatedTransfer", sessionID, toUserID, amount, description)
+	if mock.ImpersonatedTransferFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ImpersonatedTransferFunc(sessionID, toUserID, amount, description)
+}
+
+// ImpersonatedTransferContext calls ImpersonatedTransferContextFunc
+func (mock *MockService) ImpersonatedTransferContext(ctx context.Context, sessionID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("ImpersonatedTransferContext", ctx, sessionID, toUserID, amount, description)
+	if mock.ImpersonatedTransferContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ImpersonatedTransferContextFunc(ctx, sessionID, toUserID, amount, description)
+}
+
+// ImportTenant calls ImportTenantFunc
+func (mock *MockService) ImportTenant(bundle *wallet.TenantBundle, policy wallet.ConflictPolicy) (*wallet.TenantImport, error) {
+	mock.record("ImportTenant", bundle, policy)
+	if mock.ImportTenantFunc == nil {
+		var r0 *wallet.TenantImport
+		return r0, ErrNotConfigured
+	}
+	return mock.ImportTenantFunc(bundle, policy)
+}
+
+// ImportedHistory calls ImportedHistoryFunc
+func (mock *MockService) ImportedHistory(userID string) ([]wallet.Transaction, error) {
+	mock.record("ImportedHistory", userID)
+	if mock.ImportedHistoryFunc == nil {
+		var r0 []wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ImportedHistoryFunc(userID)
+}
+
+// IsBlocked calls IsBlockedFunc
+func (mock *MockService) IsBlocked(userID string, counterpartyID string) bool {
+	mock.record("IsBlocked", userID, counterpartyID)
+	if mock.IsBlockedFunc == nil {
+		var r0 bool
+		return r0
+	}
+	return mock.IsBlockedFunc(userID, counterpartyID)
+}
+
+// IsHotWallet calls IsHotWalletFunc
+func (mock *MockService) IsHotWallet(userID string) bool {
+	mock.record("IsHotWallet", userID)
+	if mock.IsHotWalletFunc == nil {
+		var r0 bool
+		return r0
+	}
+	return mock.IsHotWalletFunc(userID)
+}
+
+// IssueCard calls IssueCardFunc
+func (mock *MockService) IssueCard(userID string, label string, limits wallet.CardLimits, validFor time.Duration) (*wallet.Card, error) {
+	mock.record("IssueCard", userID, label, limits, validFor)
+	if mock.IssueCardFunc == nil {
+		var r0 *wallet.Card
+		return r0, ErrNotConfigured
+	}
+	return mock.IssueCardFunc(userID, label, limits, validFor)
+}
+
+// IterateTransactions calls IterateTransactionsFunc
+func (mock *MockService) IterateTransactions(userID string, opts wallet.IterateOptions) (wallet.TransactionIterator, error) {
+	mock.record("IterateTransactions", userID, opts)
+	if mock.IterateTransactionsFunc == nil {
+		var r0 wallet.TransactionIterator
+		return r0, ErrNotConfigured
+	}
+	return mock.IterateTransactionsFunc(userID, opts)
+}
+
+// IterateTransactionsContext calls IterateTransactionsContextFunc
+func (mock *MockService) IterateTransactionsContext(ctx context.Context, userID string, opts wallet.IterateOptions) (wallet.TransactionIterator, error) {
+	mock.record("IterateTransactionsContext", ctx, userID, opts)
+	if mock.IterateTransactionsContextFunc == nil {
+		var r0 wallet.TransactionIterator
+		return r0, ErrNotConfigured
+	}
+	return mock.IterateTransactionsContextFunc(ctx, userID, opts)
+}
+
+// LatestEventOffset calls LatestEventOffsetFunc
+func (mock *MockService) LatestEventOffset() int64 {
+	mock.record("LatestEventOffset")
+	if mock.LatestEventOffsetFunc == nil {
+		var r0 int64
+		return r0
+	}
+	return mock.LatestEventOffsetFunc()
+}
+
+// LatestSchemaVersion calls LatestSchemaVersionFunc
+func (mock *MockService) LatestSchemaVersion(eventType wallet.EventType) int {
+	mock.record("LatestSchemaVersion", eventType)
+	if mock.LatestSchemaVersionFunc == nil {
+		var r0 int
+		return r0
+	}
+	return mock.LatestSchemaVersionFunc(eventType)
+}
+
+// LiftRestriction calls LiftRestrictionFunc
+func (mock *MockService) LiftRestriction(userID string) error {
+	mock.record("LiftRestriction", userID)
+	if mock.LiftRestrictionFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.LiftRestrictionFunc(userID)
+}
+
+// LinkIdentity calls LinkIdentityFunc
+func (mock *MockService) LinkIdentity(userID string, identity wallet.ExternalIdentity) (*wallet.ExternalIdentity, error) {
+	mock.record("LinkIdentity", userID, identity)
+	if mock.LinkIdentityFunc == nil {
+		var r0 *wallet.ExternalIdentity
+		return r0, ErrNotConfigured
+	}
+	return mock.LinkIdentityFunc(userID, identity)
+}
+
+// LinkTransactions calls LinkTransactionsFunc
+func (mock *MockService) LinkTransactions(txID string, relatedTxID string) error {
+	mock.record("LinkTransactions", txID, relatedTxID)
+	if mock.LinkTransactionsFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.LinkTransactionsFunc(txID, relatedTxID)
+}
+
+// ListAdjustmentRequests calls ListAdjustmentRequestsFunc
+func (mock *MockService) ListAdjustmentRequests(status wallet.AdjustmentStatus) []*wallet.AdjustmentRequest {
+	mock.record("ListAdjustmentRequests", status)
+	if mock.ListAdjustmentRequestsFunc == nil {
+		var r0 []*wallet.AdjustmentRequest
+		return r0
+	}
+	return mock.ListAdjustmentRequestsFunc(status)
+}
+
+// ListAdjustments calls ListAdjustmentsFunc
+func (mock *MockService) ListAdjustments(userID string) ([]*wallet.Transaction, error) {
+	mock.record("ListAdjustments", userID)
+	if mock.ListAdjustmentsFunc == nil {
+		var r0 []*wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ListAdjustmentsFunc(userID)
+}
+
+// ListAnnotations calls ListAnnotationsFunc
+func (mock *MockService) ListAnnotations(viewerID string, subject wallet.AnnotationSubject, subjectID string) ([]wallet.Annotation, error) {
+	mock.record("ListAnnotations", viewerID, subject, subjectID)
+	if mock.ListAnnotationsFunc == nil {
+		var r0 []wallet.Annotation
+		return r0, ErrNotConfigured
+	}
+	return mock.ListAnnotationsFunc(viewerID, subject, subjectID)
+}
+
+// ListAutomationRules calls ListAutomationRulesFunc
+func (mock *MockService) ListAutomationRules(userID string) []wallet.AutomationRule {
+	mock.record("ListAutomationRules", userID)
+	if mock.ListAutomationRulesFunc == nil {
+		var r0 []wallet.AutomationRule
+		return r0
+	}
+	return mock.ListAutomationRulesFunc(userID)
+}
+
+// ListCards calls ListCardsFunc
+func (mock *MockService) ListCards(userID string) []wallet.Card {
+	mock.record("ListCards", userID)
+	if mock.ListCardsFunc == nil {
+		var r0 []wallet.Card
+		return r0
+	}
+	return mock.ListCardsFunc(userID)
+}
+
+// ListCases calls ListCasesFunc
+func (mock *MockService) ListCases(status wallet.CaseStatus) []*wallet.ComplianceCase {
+	mock.record("ListCases", status)
+	if mock.ListCasesFunc == nil {
+		var r0 []*wallet.ComplianceCase
+		return r0
+	}
+	return mock.ListCasesFunc(status)
+}
+
+// ListConversionOrders calls ListConversionOrdersFunc
+func (mock *MockService) ListConversionOrders(userID string) []wallet.ConversionOrder {
+	mock.record("ListConversionOrders", userID)
+	if mock.ListConversionOrdersFunc == nil {
+		var r0 []wallet.ConversionOrder
+		return r0
+	}
+	return mock.ListConversionOrdersFunc(userID)
+}
+
+// ListCurrencies calls ListCurrenciesFunc
+func (mock *MockService) ListCurrencies() []wallet.Currency {
+	mock.record("ListCurrencies")
+	if mock.ListCurrenciesFunc == nil {
+		var r0 []wallet.Currency
+		return r0
+	}
+	return mock.ListCurrenciesFunc()
+}
+
+// ListEventSchemas calls ListEventSchemasFunc
+func (mock *MockService) ListEventSchemas(eventType wallet.EventType) []wallet.EventSchema {
+	mock.record("ListEventSchemas", eventType)
+	if mock.ListEventSchemasFunc == nil {
+		var r0 []wallet.EventSchema
+		return r0
+	}
+	return mock.ListEventSchemasFunc(eventType)
+}
+
+// ListFailedOperations calls ListFailedOperationsFunc
+func (mock *MockService) ListFailedOperations(userID string, reason wallet.FailureReason) ([]wallet.FailedOperation, error) {
+	mock.record("ListFailedOperations", userID, reason)
+	if mock.ListFailedOperationsFunc == nil {
+		var r0 []wallet.FailedOperation
+		return r0, ErrNotConfigured
+	}
+	return mock.ListFailedOperationsFunc(userID, reason)
+}
+
+// ListFavorites calls ListFavoritesFunc
+func (mock *MockService) ListFavorites(userID string) []wallet.Favorite {
+	mock.record("ListFavorites", userID)
+	if mock.ListFavoritesFunc == nil {
+		var r0 []wallet.Favorite
+		return r0
+	}
+	return mock.ListFavoritesFunc(userID)
+}
+
+// ListHolds calls ListHoldsFunc
+func (mock *MockService) ListHolds(userID string) []wallet.Hold {
+	mock.record("ListHolds", userID)
+	if mock.ListHoldsFunc == nil {
+		var r0 []wallet.Hold
+		return r0
+	}
+	return mock.ListHoldsFunc(userID)
+}
+
+// ListIdentities calls ListIdentitiesFunc
+func (mock *MockService) ListIdentities(userID string) ([]wallet.ExternalIdentity, error) {
+	mock.record("ListIdentities", userID)
+	if mock.ListIdentitiesFunc == nil {
+		var r0 []wallet.ExternalIdentity
+		return r0, ErrNotConfigured
+	}
+	return mock.ListIdentitiesFunc(userID)
+}
+
+// ListImpersonationAudit calls ListImpersonationAuditFunc
+func (mock *MockService) ListImpersonationAudit(filter wallet.ImpersonationAuditFilter) []wallet.ImpersonationAuditEntry {
+	mock.record("ListImpersonationAudit", filter)
+	if mock.ListImpersonationAuditFunc == nil {
+		var r0 []wallet.ImpersonationAuditEntry
+		return r0
+	}
+	return mock.ListImpersonationAuditFunc(filter)
+}
+
+// ListJobAudit calls ListJobAuditFunc
+func (mock *MockService) ListJobAudit(jobID string) []wallet.JobAuditEntry {
+	mock.record("ListJobAudit", jobID)
+	if mock.ListJobAuditFunc == nil {
+		var r0 []wallet.JobAuditEntry
+		return r0
+	}
+	return mock.ListJobAuditFunc(jobID)
+}
+
+// ListLegalHolds calls ListLegalHoldsFunc
+func (mock *MockService) ListLegalHolds() []wallet.LegalHold {
+	mock.record("ListLegalHolds")
+	if mock.ListLegalHoldsFunc == nil {
+		var r0 []wallet.LegalHold
+		return r0
+	}
+	return mock.ListLegalHoldsFunc()
+}
+
+// ListMandates calls ListMandatesFunc
+func (mock *MockService) ListMandates(userID string) []wallet.Mandate {
+	mock.record("ListMandates", userID)
+	if mock.ListMandatesFunc == nil {
+		var r0 []wallet.Mandate
+		return r0
+	}
+	return mock.ListMandatesFunc(userID)
+}
+
+// ListPaymentLinks calls ListPaymentLinksFunc
+func (mock *MockService) ListPaymentLinks(recipientID string) []wallet.PaymentLink {
+	mock.record("ListPaymentLinks", recipientID)
+	if mock.ListPaymentLinksFunc == nil {
+		var r0 []wallet.PaymentLink
+		return r0
+	}
+	return mock.ListPaymentLinksFunc(recipientID)
+}
+
+// ListRailTransfers calls ListRailTransfersFunc
+func (mock *MockService) ListRailTransfers(userID string) []wallet.RailTransfer {
+	mock.record("ListRailTransfers", userID)
+	if mock.ListRailTransfersFunc == nil {
+		var r0 []wallet.RailTransfer
+		return r0
+	}
+	return mock.ListRailTransfersFunc(userID)
+}
+
+// ListReservations calls ListReservationsFunc
+func (mock *MockService) ListReservations(userID string) []wallet.Reservation {
+	mock.record("ListReservations", userID)
+	if mock.ListReservationsFunc == nil {
+		var r0 []wallet.Reservation
+		return r0
+	}
+	return mock.ListReservationsFunc(userID)
+}
+
+// ListRetentionRuns calls ListRetentionRunsFunc
+func (mock *MockService) ListRetentionRuns() []wallet.RetentionRun {
+	mock.record("ListRetentionRuns")
+	if mock.ListRetentionRunsFunc == nil {
+		var r0 []wallet.RetentionRun
+		return r0
+	}
+	return mock.ListRetentionRunsFunc()
+}
+
+// ListSegmentActions calls ListSegmentActionsFunc
+func (mock *MockService) ListSegmentActions() []wallet.SegmentAction {
+	mock.record("ListSegmentActions")
+	if mock.ListSegmentActionsFunc == nil {
+		var r0 []wallet.SegmentAction
+		return r0
+	}
+	return mock.ListSegmentActionsFunc()
+}
+
+// ListTransactions calls ListTransactionsFunc
+func (mock *MockService) ListTransactions(userID string, q wallet.HistoryQuery) (*wallet.HistoryPage, error) {
+	mock.record("ListTransactions", userID, q)
+	if mock.ListTransactionsFunc == nil {
+		var r0 *wallet.HistoryPage
+		return r0, ErrNotConfigured
+	}
+	return mock.ListTransactionsFunc(userID, q)
+}
+
+// ListUpcomingJobs calls ListUpcomingJobsFunc
+func (mock *MockService) ListUpcomingJobs(filter wallet.UpcomingFilter) []wallet.UpcomingJob {
+	mock.record("ListUpcomingJobs", filter)
+	if mock.ListUpcomingJobsFunc == nil {
+		var r0 []wallet.UpcomingJob
+		return r0
+	}
+	return mock.ListUpcomingJobsFunc(filter)
+}
+
+// ListWithdrawalDestinations calls ListWithdrawalDestinationsFunc
+func (mock *MockService) ListWithdrawalDestinations(userID string) []wallet.WithdrawalDestination {
+	mock.record("ListWithdrawalDestinations", userID)
+	if mock.ListWithdrawalDestinationsFunc == nil {
+		var r0 []wallet.WithdrawalDestination
+		return r0
+	}
+	return mock.ListWithdrawalDestinationsFunc(userID)
+}
+
+// MigrateEmailIndex calls MigrateEmailIndexFunc
+func (mock *MockService) MigrateEmailIndex() wallet.EmailMigrationReport {
+	mock.record("MigrateEmailIndex")
+	if mock.MigrateEmailIndexFunc == nil {
+		var r0 wallet.EmailMigrationReport
+		return r0
+	}
+	return mock.MigrateEmailIndexFunc()
+}
+
+// Mint calls MintFunc
+func (mock *MockService) Mint(toUserID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("Mint", toUserID, amount)
+	if mock.MintFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.MintFunc(toUserID, amount)
+}
+
+// MintContext calls MintContextFunc
+func (mock *MockService) MintContext(ctx context.Context, toUserID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("MintContext", ctx, toUserID, amount)
+	if mock.MintContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.MintContextFunc(ctx, toUserID, amount)
+}
+
+// MissingConsents calls MissingConsentsFunc
+func (mock *MockService) MissingConsents(userID string) ([]wallet.ConsentVersion, error) {
+	mock.record("MissingConsents", userID)
+	if mock.MissingConsentsFunc == nil {
+		var r0 []wallet.ConsentVersion
+		return r0, ErrNotConfigured
+	}
+	return mock.MissingConsentsFunc(userID)
+}
+
+// PauseConversionOrder calls PauseConversionOrderFunc
+func (mock *MockService) PauseConversionOrder(orderID string, userID string) error {
+	mock.record("PauseConversionOrder", orderID, userID)
+	if mock.PauseConversionOrderFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.PauseConversionOrderFunc(orderID, userID)
+}
+
+// PauseMandate calls PauseMandateFunc
+func (mock *MockService) PauseMandate(mandateID string, payerID string) error {
+	mock.record("PauseMandate", mandateID, payerID)
+	if mock.PauseMandateFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.PauseMandateFunc(mandateID, payerID)
+}
+
+// PayPaymentLink calls PayPaymentLinkFunc
+func (mock *MockService) PayPaymentLink(tokenOrURL string, payerID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("PayPaymentLink", tokenOrURL, payerID, amount)
+	if mock.PayPaymentLinkFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.PayPaymentLinkFunc(tokenOrURL, payerID, amount)
+}
+
+// PayPaymentLinkContext calls PayPaymentLinkContextFunc
+func (mock *MockService) PayPaymentLinkContext(ctx context.Context, tokenOrURL string, payerID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("PayPaymentLinkContext", ctx, tokenOrURL, payerID, amount)
+	if mock.PayPaymentLinkContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.PayPaymentLinkContextFunc(ctx, tokenOrURL, payerID, amount)
+}
+
+// PayoutViaRail calls PayoutViaRailFunc
+func (mock *MockService) PayoutViaRail(userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error) {
+	mock.record("PayoutViaRail", userID, account, amount)
+	if mock.PayoutViaRailFunc == nil {
+		var r0 *wallet.RailTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.PayoutViaRailFunc(userID, account, amount)
+}
+
+// PayoutViaRailContext calls PayoutViaRailContextFunc
+func (mock *MockService) PayoutViaRailContext(ctx context.Context, userID string, account string, amount decimal.Decimal) (*wallet.RailTransfer, error) {
+	mock.record("PayoutViaRailContext", ctx, userID, account, amount)
+	if mock.PayoutViaRailContextFunc == nil {
+		var r0 *wallet.RailTransfer
+		return r0, ErrNotConfigured
+	}
+	return mock.PayoutViaRailContextFunc(ctx, userID, account, amount)
+}
+
+// PendingFederatedTransfers calls PendingFederatedTransfersFunc
+func (mock *MockService) PendingFederatedTransfers() []wallet.OutboundTransfer {
+	mock.record("PendingFederatedTransfers")
+	if mock.PendingFederatedTransfersFunc == nil {
+		var r0 []wallet.OutboundTransfer
+		return r0
+	}
+	return mock.PendingFederatedTransfersFunc()
+}
+
+// PlaceLegalHold calls PlaceLegalHoldFunc
+func (mock *MockService) PlaceLegalHold(hold wallet.LegalHold) (*wallet.LegalHold, error) {
+	mock.record("PlaceLegalHold", hold)
+	if mock.PlaceLegalHoldFunc == nil {
+		var r0 *wallet.LegalHold
+		return r0, ErrNotConfigured
+	}
+	return mock.PlaceLegalHoldFunc(hold)
+}
+
+// PostCustomTransaction calls PostCustomTransactionFunc
+func (mock *MockService) PostCustomTransaction(req wallet.CustomTransaction) (*wallet.Transaction, error) {
+	mock.record("PostCustomTransaction", req)
+	if mock.PostCustomTransactionFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.PostCustomTransactionFunc(req)
+}
+
+// PostCustomTransactionContext calls PostCustomTransactionContextFunc
+func (mock *MockService) PostCustomTransactionContext(ctx context.Context, req wallet.CustomTransaction) (*wallet.Transaction, error) {
+	mock.record("PostCustomTransactionContext", ctx, req)
+	if mock.PostCustomTransactionContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.PostCustomTransactionContextFunc(ctx, req)
+}
+
+// PostInterest calls PostInterestFunc
+func (mock *MockService) PostInterest(userID string, period wallet.InterestPeriod) (*wallet.InterestStatement, error) {
+	mock.record("PostInterest", userID, period)
+	if mock.PostInterestFunc == nil {
+		var r0 *wallet.InterestStatement
+		return r0, ErrNotConfigured
+	}
+	return mock.PostInterestFunc(userID, period)
+}
+
+// PostInterestContext calls PostInterestContextFunc
+func (mock *MockService) PostInterestContext(ctx context.Context, userID string, period wallet.InterestPeriod) (*wallet.InterestStatement, error) {
+	mock.record("PostInterestContext", ctx, userID, period)
+	if mock.PostInterestContextFunc == nil {
+		var r0 *wallet.InterestStatement
+		return r0, ErrNotConfigured
+	}
+	return mock.PostInterestContextFunc(ctx, userID, period)
+}
+
+// PreviewUserDeletion calls PreviewUserDeletionFunc
+func (mock *MockService) PreviewUserDeletion(userID string) (*wallet.DeletionPreview, error) {
+	mock.record("PreviewUserDeletion", userID)
+	if mock.PreviewUserDeletionFunc == nil {
+		var r0 *wallet.DeletionPreview
+		return r0, ErrNotConfigured
+	}
+	return mock.PreviewUserDeletionFunc(userID)
+}
+
+// ProcessAutomations calls ProcessAutomationsFunc
+func (mock *MockService) ProcessAutomations() []wallet.RuleExecution {
+	mock.record("ProcessAutomations")
+	if mock.ProcessAutomationsFunc == nil {
+		var r0 []wallet.RuleExecution
+		return r0
+	}
+	return mock.ProcessAutomationsFunc()
+}
+
+// PublishConsentVersion calls PublishConsentVersionFunc
+func (mock *MockService) PublishConsentVersion(v wallet.ConsentVersion) error {
+	mock.record("PublishConsentVersion", v)
+	if mock.PublishConsentVersionFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.PublishConsentVersionFunc(v)
+}
+
+// PullFunds calls PullFundsFunc
+func (mock *MockService) PullFunds(mandateID string, merchantID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("PullFunds", mandateID, merchantID, amount, description)
+	if mock.PullFundsFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.PullFundsFunc(mandateID, merchantID, amount, description)
+}
+
+// PullFundsContext calls PullFundsContextFunc
+func (mock *MockService) PullFundsContext(ctx context.Context, mandateID string, merchantID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("PullFundsContext", ctx, mandateID, merchantID, amount, description)
+	if mock.PullFundsContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.PullFundsContextFunc(ctx, mandateID, merchantID, amount, description)
+}
+
+// QueryAuditLog calls QueryAuditLogFunc
+func (mock *MockService) QueryAuditLog(q wallet.AuditQuery) ([]wallet.AuditEntry, error) {
+	mock.record("QueryAuditLog", q)
+	if mock.QueryAuditLogFunc == nil {
+		var r0 []wallet.AuditEntry
+		return r0, ErrNotConfigured
+	}
+	return mock.QueryAuditLogFunc(q)
+}
+
+// QuickPay calls QuickPayFunc
+func (mock *MockService) QuickPay(favoriteID string) (*wallet.Transaction, error) {
+	mock.record("QuickPay", favoriteID)
+	if mock.QuickPayFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.QuickPayFunc(favoriteID)
+}
+
+// QuickPayAmount calls QuickPayAmountFunc
+func (mock *MockService) QuickPayAmount(favoriteID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("QuickPayAmount", favoriteID, amount)
+	if mock.QuickPayAmountFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.QuickPayAmountFunc(favoriteID, amount)
+}
+
+// QuickPayAmountContext calls QuickPayAmountContextFunc
+func (mock *MockService) QuickPayAmountContext(ctx context.Context, favoriteID string, amount decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("QuickPayAmountContext", ctx, favoriteID, amount)
+	if mock.QuickPayAmountContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.QuickPayAmountContextFunc(ctx, favoriteID, amount)
+}
+
+// QuickPayContext calls QuickPayContextFunc
+func (mock *MockService) QuickPayContext(ctx context.Context, favoriteID string) (*wallet.Transaction, error) {
+	mock.record("QuickPayContext", ctx, favoriteID)
+	if mock.QuickPayContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.QuickPayContextFunc(ctx, favoriteID)
+}
+
+// QuoteConversion calls QuoteConversionFunc
+func (mock *MockService) QuoteConversion(userID string, from string, to string, amount decimal.Decimal) (*wallet.FXQuote, error) {
+	mock.record("QuoteConversion", userID, from, to, amount)
+	if mock.QuoteConversionFunc == nil {
+		var r0 *wallet.FXQuote
+		return r0, ErrNotConfigured
+	}
+	return mock.QuoteConversionFunc(userID, from, to, amount)
+}
+
+// QuoteConversionContext calls QuoteConversionContextFunc
+func (mock *MockService) QuoteConversionContext(ctx context.Context, userID string, from string, to string, amount decimal.Decimal) (*wallet.FXQuote, error) {
+	mock.record("QuoteConversionContext", ctx, userID, from, to, amount)
+	if mock.QuoteConversionContextFunc == nil {
+		var r0 *wallet.FXQuote
+		return r0, ErrNotConfigured
+	}
+	return mock.QuoteConversionContextFunc(ctx, userID, from, to, amount)
+}
+
+// RateAt calls RateAtFunc
+func (mock *MockService) RateAt(from string, to string, at time.Time) (*wallet.RateRecord, error) {
+	mock.record("RateAt", from, to, at)
+	if mock.RateAtFunc == nil {
+		var r0 *wallet.RateRecord
+		return r0, ErrNotConfigured
+	}
+	return mock.RateAtFunc(from, to, at)
+}
+
+// RateHistory calls RateHistoryFunc
+func (mock *MockService) RateHistory(from string, to string, since time.Time, until time.Time) []wallet.RateRecord {
+	mock.record("RateHistory", from, to, since, until)
+	if mock.RateHistoryFunc == nil {
+		var r0 []wallet.RateRecord
+		return r0
+	}
+	return mock.RateHistoryFunc(from, to, since, until)
+}
+
+// ReceiveFederatedTransfer calls ReceiveFederatedTransferFunc
+func (mock *MockService) ReceiveFederatedTransfer(voucher wallet.FederationVoucher) (*wallet.FederationReceipt, error) {
+	mock.record("ReceiveFederatedTransfer", voucher)
+	if mock.ReceiveFederatedTransferFunc == nil {
+		var r0 *wallet.FederationReceipt
+		return r0, ErrNotConfigured
+	}
+	return mock.ReceiveFederatedTransferFunc(voucher)
+}
+
+// ReceiveFederatedTransferContext calls ReceiveFederatedTransferContextFunc
+func (mock *MockService) ReceiveFederatedTransferContext(ctx context.Context, voucher wallet.FederationVoucher) (*wallet.FederationReceipt, error) {
+	mock.record("ReceiveFederatedTransferContext", ctx, voucher)
+	if mock.ReceiveFederatedTransferContextFunc == nil {
+		var r0 *wallet.FederationReceipt
+		return r0, ErrNotConfigured
+	}
+	return mock.ReceiveFederatedTransferContextFunc(ctx, voucher)
+}
+
+// Reconcile calls ReconcileFunc
+func (mock *MockService) Reconcile() (*wallet.ReconciliationReport, error) {
+	mock.record("Reconcile")
+	if mock.ReconcileFunc == nil {
+		var r0 *wallet.ReconciliationReport
+		return r0, ErrNotConfigured
+	}
+	return mock.ReconcileFunc()
+}
+
+// ReconcileSnapshot calls ReconcileSnapshotFunc
+func (mock *MockService) ReconcileSnapshot(snap *wallet.Snapshot) []wallet.BalanceMismatch {
+	mock.record("ReconcileSnapshot", snap)
+	if mock.ReconcileSnapshotFunc == nil {
+		var r0 []wallet.BalanceMismatch
+		return r0
+	}
+	return mock.ReconcileSnapshotFunc(snap)
+}
+
+// RecordAudit calls RecordAuditFunc
+func (mock *MockService) RecordAudit(ctx context.Context, entry wallet.AuditEntry) error {
+	mock.record("RecordAudit", ctx, entry)
+	if mock.RecordAuditFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RecordAuditFunc(ctx, entry)
+}
+
+// RecordRate calls RecordRateFunc
+func (mock *MockService) RecordRate(from string, to string, rate decimal.Decimal, source string) (*wallet.RateRecord, error) {
+	mock.record("RecordRate", from, to, rate, source)
+	if mock.RecordRateFunc == nil {
+		var r0 *wallet.RateRecord
+		return r0, ErrNotConfigured
+	}
+	return mock.RecordRateFunc(from, to, rate, source)
+}
+
+// RecoveredTransfers calls RecoveredTransfersFunc
+func (mock *MockService) RecoveredTransfers() []wallet.RecoveredTransfer {
+	mock.record("RecoveredTransfers")
+	if mock.RecoveredTransfersFunc == nil {
+		var r0 []wallet.RecoveredTransfer
+		return r0
+	}
+	return mock.RecoveredTransfersFunc()
+}
+
+// RefundTransaction calls RefundTransactionFunc
+func (mock *MockService) RefundTransaction(txID string, amount decimal.Decimal, reason string) (*wallet.Transaction, error) {
+	mock.record("RefundTransaction", txID, amount, reason)
+	if mock.RefundTransactionFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.RefundTransactionFunc(txID, amount, reason)
+}
+
+// RefundTransactionContext calls RefundTransactionContextFunc
+func (mock *MockService) RefundTransactionContext(ctx context.Context, txID string, amount decimal.Decimal, reason string) (*wallet.Transaction, error) {
+	mock.record("RefundTransactionContext", ctx, txID, amount, reason)
+	if mock.RefundTransactionContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.RefundTransactionContextFunc(ctx, txID, amount, reason)
+}
+
+// RegisterCurrency calls RegisterCurrencyFunc
+func (mock *MockService) RegisterCurrency(c wallet.Currency) error {
+	mock.record("RegisterCurrency", c)
+	if mock.RegisterCurrencyFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RegisterCurrencyFunc(c)
+}
+
+// RegisterEventSchema calls RegisterEventSchemaFunc
+func (mock *MockService) RegisterEventSchema(schema wallet.EventSchema) error {
+	mock.record("RegisterEventSchema", schema)
+	if mock.RegisterEventSchemaFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RegisterEventSchemaFunc(schema)
+}
+
+// RegisterHealthCheck calls RegisterHealthCheckFunc
+func (mock *MockService) RegisterHealthCheck(name string, fn wallet.HealthCheckFunc) {
+	mock.record("RegisterHealthCheck", name, fn)
+	if mock.RegisterHealthCheckFunc == nil {
+		return
+	}
+	mock.RegisterHealthCheckFunc(name, fn)
+}
+
+// RegisterHoldRule calls RegisterHoldRuleFunc
+func (mock *MockService) RegisterHoldRule(name string, fn wallet.HoldRuleFunc) {
+	mock.record("RegisterHoldRule", name, fn)
+	if mock.RegisterHoldRuleFunc == nil {
+		return
+	}
+	mock.RegisterHoldRuleFunc(name, fn)
+}
+
+// RegisterRiskHook calls RegisterRiskHookFunc
+func (mock *MockService) RegisterRiskHook(name string, fn wallet.RiskHookFunc) {
+	mock.record("RegisterRiskHook", name, fn)
+	if mock.RegisterRiskHookFunc == nil {
+		return
+	}
+	mock.RegisterRiskHookFunc(name, fn)
+}
+
+// RegisterValidator calls RegisterValidatorFunc
+func (mock *MockService) RegisterValidator(txType wallet.TransactionType, fn wallet.ValidatorFunc) {
+	mock.record("RegisterValidator", txType, fn)
+	if mock.RegisterValidatorFunc == nil {
+		return
+	}
+	mock.RegisterValidatorFunc(txType, fn)
+}
+
+// RegisterWebhook calls RegisterWebhookFunc
+func (mock *MockService) RegisterWebhook(transport wallet.WebhookTransport, cfg wallet.WebhookConfig) (string, error) {
+	mock.record("RegisterWebhook", transport, cfg)
+	if mock.RegisterWebhookFunc == nil {
+		var r0 string
+		return r0, ErrNotConfigured
+	}
+	return mock.RegisterWebhookFunc(transport, cfg)
+}
+
+// RejectAdjustment calls RejectAdjustmentFunc
+func (mock *MockService) RejectAdjustment(requestID string, checkerID string, comment string) (*wallet.AdjustmentRequest, error) {
+	mock.record("RejectAdjustment", requestID, checkerID, comment)
+	if mock.RejectAdjustmentFunc == nil {
+		var r0 *wallet.AdjustmentRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.RejectAdjustmentFunc(requestID, checkerID, comment)
+}
+
+// ReleaseAuthorization calls ReleaseAuthorizationFunc
+func (mock *MockService) ReleaseAuthorization(authID string) (*wallet.CardAuthorization, error) {
+	mock.record("ReleaseAuthorization", authID)
+	if mock.ReleaseAuthorizationFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.ReleaseAuthorizationFunc(authID)
+}
+
+// ReleaseAuthorizationContext calls ReleaseAuthorizationContextFunc
+func (mock *MockService) ReleaseAuthorizationContext(ctx context.Context, authID string) (*wallet.CardAuthorization, error) {
+	mock.record("ReleaseAuthorizationContext", ctx, authID)
+	if mock.ReleaseAuthorizationContextFunc == nil {
+		var r0 *wallet.CardAuthorization
+		return r0, ErrNotConfigured
+	}
+	return mock.ReleaseAuthorizationContextFunc(ctx, authID)
+}
+
+// ReleaseHold calls ReleaseHoldFunc
+func (mock *MockService) ReleaseHold(holdID string) (*wallet.Hold, error) {
+	mock.record("ReleaseHold", holdID)
+	if mock.ReleaseHoldFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.ReleaseHoldFunc(holdID)
+}
+
+// ReleaseHoldContext calls ReleaseHoldContextFunc
+func (mock *MockService) ReleaseHoldContext(ctx context.Context, holdID string) (*wallet.Hold, error) {
+	mock.record("ReleaseHoldContext", ctx, holdID)
+	if mock.ReleaseHoldContextFunc == nil {
+		var r0 *wallet.Hold
+		return r0, ErrNotConfigured
+	}
+	return mock.ReleaseHoldContextFunc(ctx, holdID)
+}
+
+// ReleaseLegalHold calls ReleaseLegalHoldFunc
+func (mock *MockService) ReleaseLegalHold(holdID string, actor string) error {
+	mock.record("ReleaseLegalHold", holdID, actor)
+	if mock.ReleaseLegalHoldFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ReleaseLegalHoldFunc(holdID, actor)
+}
+
+// ReleaseReservation calls ReleaseReservationFunc
+func (mock *MockService) ReleaseReservation(reservationID string) (*wallet.Reservation, error) {
+	mock.record("ReleaseReservation", reservationID)
+	if mock.ReleaseReservationFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.ReleaseReservationFunc(reservationID)
+}
+
+// ReleaseReservationContext calls ReleaseReservationContextFunc
+func (mock *MockService) ReleaseReservationContext(ctx context.Context, reservationID string) (*wallet.Reservation, error) {
+	mock.record("ReleaseReservationContext", ctx, reservationID)
+	if mock.ReleaseReservationContextFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.ReleaseReservationContextFunc(ctx, reservationID)
+}
+
+// RemoveFavorite calls RemoveFavoriteFunc
+func (mock *MockService) RemoveFavorite(userID string, favoriteID string) error {
+	mock.record("RemoveFavorite", userID, favoriteID)
+	if mock.RemoveFavoriteFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RemoveFavoriteFunc(userID, favoriteID)
+}
+
+// RemoveInterestOverride calls RemoveInterestOverrideFunc
+func (mock *MockService) RemoveInterestOverride(userID string) error {
+	mock.record("RemoveInterestOverride", userID)
+	if mock.RemoveInterestOverrideFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RemoveInterestOverrideFunc(userID)
+}
+
+// RemoveOrgMember calls RemoveOrgMemberFunc
+func (mock *MockService) RemoveOrgMember(orgID string, userID string) error {
+	mock.record("RemoveOrgMember", orgID, userID)
+	if mock.RemoveOrgMemberFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RemoveOrgMemberFunc(orgID, userID)
+}
+
+// RemoveReservePolicy calls RemoveReservePolicyFunc
+func (mock *MockService) RemoveReservePolicy(userID string) error {
+	mock.record("RemoveReservePolicy", userID)
+	if mock.RemoveReservePolicyFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RemoveReservePolicyFunc(userID)
+}
+
+// RemoveStaff calls RemoveStaffFunc
+func (mock *MockService) RemoveStaff(staffID string) error {
+	mock.record("RemoveStaff", staffID)
+	if mock.RemoveStaffFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RemoveStaffFunc(staffID)
+}
+
+// RemoveWithdrawalDestination calls RemoveWithdrawalDestinationFunc
+func (mock *MockService) RemoveWithdrawalDestination(userID string, destinationID string) error {
+	mock.record("RemoveWithdrawalDestination", userID, destinationID)
+	if mock.RemoveWithdrawalDestinationFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RemoveWithdrawalDestinationFunc(userID, destinationID)
+}
+
+// ReorderFavorites calls ReorderFavoritesFunc
+func (mock *MockService) ReorderFavorites(userID string, ids []string) error {
+	mock.record("ReorderFavorites", userID, ids)
+	if mock.ReorderFavoritesFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ReorderFavoritesFunc(userID, ids)
+}
+
+// ReplayWebhook calls ReplayWebhookFunc
+func (mock *MockService) ReplayWebhook(subscriptionID string, fromOffset int64) error {
+	mock.record("ReplayWebhook", subscriptionID, fromOffset)
+	if mock.ReplayWebhookFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ReplayWebhookFunc(subscriptionID, fromOffset)
+}
+
+// RequestAdjustment calls RequestAdjustmentFunc
+func (mock *MockService) RequestAdjustment(makerID string, userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, note string) (*wallet.AdjustmentRequest, error) {
+	mock.record("RequestAdjustment", makerID, userID, amount, reason, note)
+	if mock.RequestAdjustmentFunc == nil {
+		var r0 *wallet.AdjustmentRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.RequestAdjustmentFunc(makerID, userID, amount, reason, note)
+}
+
+// RequestAdjustmentContext calls RequestAdjustmentContextFunc
+func (mock *MockService) RequestAdjustmentContext(ctx context.Context, makerID string, userID string, amount decimal.Decimal, reason wallet.AdjustmentReason, note string) (*wallet.AdjustmentRequest, error) {
+	mock.record("RequestAdjustmentContext", ctx, makerID, userID, amount, reason, note)
+	if mock.RequestAdjustmentContextFunc == nil {
+		var r0 *wallet.AdjustmentRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.RequestAdjustmentContextFunc(ctx, makerID, userID, amount, reason, note)
+}
+
+// ResendReceipt calls ResendReceiptFunc
+func (mock *MockService) ResendReceipt(receiptID string) error {
+	mock.record("ResendReceipt", receiptID)
+	if mock.ResendReceiptFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ResendReceiptFunc(receiptID)
+}
+
+// Reserve calls ReserveFunc
+func (mock *MockService) Reserve(req wallet.ReservationRequest) (*wallet.Reservation, error) {
+	mock.record("Reserve", req)
+	if mock.ReserveFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.ReserveFunc(req)
+}
+
+// ReserveClientTxID calls ReserveClientTxIDFunc
+func (mock *MockService) ReserveClientTxID(userID string, clientTxID string, ttl time.Duration) (*wallet.ClientTxReservation, error) {
+	mock.record("ReserveClientTxID", userID, clientTxID, ttl)
+	if mock.ReserveClientTxIDFunc == nil {
+		var r0 *wallet.ClientTxReservation
+		return r0, ErrNotConfigured
+	}
+	return mock.ReserveClientTxIDFunc(userID, clientTxID, ttl)
+}
+
+// ReserveContext calls ReserveContextFunc
+func (mock *MockService) ReserveContext(ctx context.Context, req wallet.ReservationRequest) (*wallet.Reservation, error) {
+	mock.record("ReserveContext", ctx, req)
+	if mock.ReserveContextFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.ReserveContextFunc(ctx, req)
+}
+
+// ResolveCase calls ResolveCaseFunc
+func (mock *MockService) ResolveCase(caseID string, reviewer string, release bool, note string) (*wallet.Transaction, error) {
+	mock.record("ResolveCase", caseID, reviewer, release, note)
+	if mock.ResolveCaseFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ResolveCaseFunc(caseID, reviewer, release, note)
+}
+
+// ResolveCaseContext calls ResolveCaseContextFunc
+func (mock *MockService) ResolveCaseContext(ctx context.Context, caseID string, reviewer string, release bool, note string) (*wallet.Transaction, error) {
+	mock.record("ResolveCaseContext", ctx, caseID, reviewer, release, note)
+	if mock.ResolveCaseContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.ResolveCaseContextFunc(ctx, caseID, reviewer, release, note)
+}
+
+// ResolvePaymentLink calls ResolvePaymentLinkFunc
+func (mock *MockService) ResolvePaymentLink(tokenOrURL string) (*wallet.PaymentLink, error) {
+	mock.record("ResolvePaymentLink", tokenOrURL)
+	if mock.ResolvePaymentLinkFunc == nil {
+		var r0 *wallet.PaymentLink
+		return r0, ErrNotConfigured
+	}
+	return mock.ResolvePaymentLinkFunc(tokenOrURL)
+}
+
+// RestrictUser calls RestrictUserFunc
+func (mock *MockService) RestrictUser(userID string, source wallet.RestrictionSource, reason string) (*wallet.Restriction, error) {
+	mock.record("RestrictUser", userID, source, reason)
+	if mock.RestrictUserFunc == nil {
+		var r0 *wallet.Restriction
+		return r0, ErrNotConfigured
+	}
+	return mock.RestrictUserFunc(userID, source, reason)
+}
+
+// ResumeConversionOrder calls ResumeConversionOrderFunc
+func (mock *MockService) ResumeConversionOrder(orderID string, userID string) error {
+	mock.record("ResumeConversionOrder", orderID, userID)
+	if mock.ResumeConversionOrderFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ResumeConversionOrderFunc(orderID, userID)
+}
+
+// ResumeMandate calls ResumeMandateFunc
+func (mock *MockService) ResumeMandate(mandateID string, payerID string) error {
+	mock.record("ResumeMandate", mandateID, payerID)
+	if mock.ResumeMandateFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.ResumeMandateFunc(mandateID, payerID)
+}
+
+// RetryOperation calls RetryOperationFunc
+func (mock *MockService) RetryOperation(failureID string) (*wallet.Transaction, error) {
+	mock.record("RetryOperation", failureID)
+	if mock.RetryOperationFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.RetryOperationFunc(failureID)
+}
+
+// RetryOperationContext calls RetryOperationContextFunc
+func (mock *MockService) RetryOperationContext(ctx context.Context, failureID string) (*wallet.Transaction, error) {
+	mock.record("RetryOperationContext", ctx, failureID)
+	if mock.RetryOperationContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.RetryOperationContextFunc(ctx, failureID)
+}
+
+// ReviewExpense calls ReviewExpenseFunc
+func (mock *MockService) ReviewExpense(expenseID string, reviewerID string, approve bool, comment string) (*wallet.ExpenseRequest, error) {
+	mock.record("ReviewExpense", expenseID, reviewerID, approve, comment)
+	if mock.ReviewExpenseFunc == nil {
+		var r0 *wallet.ExpenseRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.ReviewExpenseFunc(expenseID, reviewerID, approve, comment)
+}
+
+// ReviewExpenseContext calls ReviewExpenseContextFunc
+func (mock *MockService) ReviewExpenseContext(ctx context.Context, expenseID string, reviewerID string, approve bool, comment string) (*wallet.ExpenseRequest, error) {
+	mock.record("ReviewExpenseContext", ctx, expenseID, reviewerID, approve, comment)
+	if mock.ReviewExpenseContextFunc == nil {
+		var r0 *wallet.ExpenseRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.ReviewExpenseContextFunc(ctx, expenseID, reviewerID, approve, comment)
+}
+
+// RevokeMandate calls RevokeMandateFunc
+func (mock *MockService) RevokeMandate(mandateID string, payerID string) error {
+	mock.record("RevokeMandate", mandateID, payerID)
+	if mock.RevokeMandateFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RevokeMandateFunc(mandateID, payerID)
+}
+
+// RevokeMinimumBalanceWaiver calls RevokeMinimumBalanceWaiverFunc
+func (mock *MockService) RevokeMinimumBalanceWaiver(userID string) error {
+	mock.record("RevokeMinimumBalanceWaiver", userID)
+	if mock.RevokeMinimumBalanceWaiverFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.RevokeMinimumBalanceWaiverFunc(userID)
+}
+
+// RunDataRetention calls RunDataRetentionFunc
+func (mock *MockService) RunDataRetention() *wallet.RetentionRun {
+	mock.record("RunDataRetention")
+	if mock.RunDataRetentionFunc == nil {
+		var r0 *wallet.RetentionRun
+		return r0
+	}
+	return mock.RunDataRetentionFunc()
+}
+
+// RunDueJobs calls RunDueJobsFunc
+func (mock *MockService) RunDueJobs() []wallet.JobResult {
+	mock.record("RunDueJobs")
+	if mock.RunDueJobsFunc == nil {
+		var r0 []wallet.JobResult
+		return r0
+	}
+	return mock.RunDueJobsFunc()
+}
+
+// ScheduleGift calls ScheduleGiftFunc
+func (mock *MockService) ScheduleGift(senderID string, recipient string, amount decimal.Decimal, message string, deliverAt time.Time) (*wallet.Gift, error) {
+	mock.record("ScheduleGift", senderID, recipient, amount, message, deliverAt)
+	if mock.ScheduleGiftFunc == nil {
+		var r0 *wallet.Gift
+		return r0, ErrNotConfigured
+	}
+	return mock.ScheduleGiftFunc(senderID, recipient, amount, message, deliverAt)
+}
+
+// SchedulePayment calls SchedulePaymentFunc
+func (mock *MockService) SchedulePayment(fromUserID string, toUserID string, amount decimal.Decimal, description string, at time.Time, recurrence wallet.Recurrence) (string, error) {
+	mock.record("SchedulePayment", fromUserID, toUserID, amount, description, at, recurrence)
+	if mock.SchedulePaymentFunc == nil {
+		var r0 string
+		return r0, ErrNotConfigured
+	}
+	return mock.SchedulePaymentFunc(fromUserID, toUserID, amount, description, at, recurrence)
+}
+
+// SearchAnnotations calls SearchAnnotationsFunc
+func (mock *MockService) SearchAnnotations(viewerID string, query wallet.AnnotationQuery) ([]wallet.Annotation, error) {
+	mock.record("SearchAnnotations", viewerID, query)
+	if mock.SearchAnnotationsFunc == nil {
+		var r0 []wallet.Annotation
+		return r0, ErrNotConfigured
+	}
+	return mock.SearchAnnotationsFunc(viewerID, query)
+}
+
+// SendDigests calls SendDigestsFunc
+func (mock *MockService) SendDigests() int {
+	mock.record("SendDigests")
+	if mock.SendDigestsFunc == nil {
+		var r0 int
+		return r0
+	}
+	return mock.SendDigestsFunc()
+}
+
+// SendFederatedTransfer calls SendFederatedTransferFunc
+func (mock *MockService) SendFederatedTransfer(fromUserID string, targetInstance string, toUserID string, amount decimal.Decimal, description string) (*wallet.FederationVoucher, error) {
+	mock.record("SendFederatedTransfer", fromUserID, targetInstance, toUserID, amount, description)
+	if mock.SendFederatedTransferFunc == nil {
+		var r0 *wallet.FederationVoucher
+		return r0, ErrNotConfigured
+	}
+	return mock.SendFederatedTransferFunc(fromUserID, targetInstance, toUserID, amount, description)
+}
+
+// SendFederatedTransferContext calls SendFederatedTransferContextFunc
+func (mock *MockService) SendFederatedTransferContext(ctx context.Context, fromUserID string, targetInstance string, toUserID string, amount decimal.Decimal, description string) (*wallet.FederationVoucher, error) {
+	mock.record("SendFederatedTransferContext", ctx, fromUserID, targetInstance, toUserID, amount, description)
+	if mock.SendFederatedTransferContextFunc == nil {
+		var r0 *wallet.FederationVoucher
+		return r0, ErrNotConfigured
+	}
+	return mock.SendFederatedTransferContextFunc(ctx, fromUserID, targetInstance, toUserID, amount, description)
+}
+
+// SessionToken calls SessionTokenFunc
+func (mock *MockService) SessionToken() wallet.SessionToken {
+	mock.record("SessionToken")
+	if mock.SessionTokenFunc == nil {
+		var r0 wallet.SessionToken
+		return r0
+	}
+	return mock.SessionTokenFunc()
+}
+
+// SetAdjustmentLevels calls SetAdjustmentLevelsFunc
+func (mock *MockService) SetAdjustmentLevels(levels []wallet.AdjustmentLevel) error {
+	mock.record("SetAdjustmentLevels", levels)
+	if mock.SetAdjustmentLevelsFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetAdjustmentLevelsFunc(levels)
+}
+
+// SetApprovalChain calls SetApprovalChainFunc
+func (mock *MockService) SetApprovalChain(orgID string, chain []wallet.ApprovalStep) error {
+	mock.record("SetApprovalChain", orgID, chain)
+	if mock.SetApprovalChainFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetApprovalChainFunc(orgID, chain)
+}
+
+// SetAutoSettle calls SetAutoSettleFunc
+func (mock *MockService) SetAutoSettle(userID string, enabled bool) error {
+	mock.record("SetAutoSettle", userID, enabled)
+	if mock.SetAutoSettleFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetAutoSettleFunc(userID, enabled)
+}
+
+// SetAutomationRulePaused calls SetAutomationRulePausedFunc
+func (mock *MockService) SetAutomationRulePaused(userID string, ruleID string, paused bool) error {
+	mock.record("SetAutomationRulePaused", userID, ruleID, paused)
+	if mock.SetAutomationRulePausedFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetAutomationRulePausedFunc(userID, ruleID, paused)
+}
+
+// SetBalance calls SetBalanceFunc
+func (mock *MockService) SetBalance(userID string, target decimal.Decimal, reason wallet.AdjustmentReason) (*wallet.Transaction, error) {
+	mock.record("SetBalance", userID, target, reason)
+	if mock.SetBalanceFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.SetBalanceFunc(userID, target, reason)
+}
+
+// SetBalanceContext calls SetBalanceContextFunc
+func (mock *MockService) SetBalanceContext(ctx context.Context, userID string, target decimal.Decimal, reason wallet.AdjustmentReason) (*wallet.Transaction, error) {
+	mock.record("SetBalanceContext", ctx, userID, target, reason)
+	if mock.SetBalanceContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.SetBalanceContextFunc(ctx, userID, target, reason)
+}
+
+// SetCardLimits calls SetCardLimitsFunc
+func (mock *MockService) SetCardLimits(cardID string, userID string, limits wallet.CardLimits) error {
+	mock.record("SetCardLimits", cardID, userID, limits)
+	if mock.SetCardLimitsFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetCardLimitsFunc(cardID, userID, limits)
+}
+
+// SetDisplayPolicy calls SetDisplayPolicyFunc
+func (mock *MockService) SetDisplayPolicy(tenant string, currency string, policy wallet.DisplayPolicy) error {
+	mock.record("SetDisplayPolicy", tenant, currency, policy)
+	if mock.SetDisplayPolicyFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetDisplayPolicyFunc(tenant, currency, policy)
+}
+
+// SetInterestAccount calls SetInterestAccountFunc
+func (mock *MockService) SetInterestAccount(userID string, account wallet.InterestAccount) (*wallet.InterestAccount, error) {
+	mock.record("SetInterestAccount", userID, account)
+	if mock.SetInterestAccountFunc == nil {
+		var r0 *wallet.InterestAccount
+		return r0, ErrNotConfigured
+	}
+	return mock.SetInterestAccountFunc(userID, account)
+}
+
+// SetInterestOverride calls SetInterestOverrideFunc
+func (mock *MockService) SetInterestOverride(userID string, o wallet.InterestOverride) error {
+	mock.record("SetInterestOverride", userID, o)
+	if mock.SetInterestOverrideFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetInterestOverrideFunc(userID, o)
+}
+
+// SetMaximumBalance calls SetMaximumBalanceFunc
+func (mock *MockService) SetMaximumBalance(userID string, currency string, maximum decimal.Decimal) error {
+	mock.record("SetMaximumBalance", userID, currency, maximum)
+	if mock.SetMaximumBalanceFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetMaximumBalanceFunc(userID, currency, maximum)
+}
+
+// SetMinimumBalance calls SetMinimumBalanceFunc
+func (mock *MockService) SetMinimumBalance(userID string, currency string, minimum decimal.Decimal) error {
+	mock.record("SetMinimumBalance", userID, currency, minimum)
+	if mock.SetMinimumBalanceFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetMinimumBalanceFunc(userID, currency, minimum)
+}
+
+// SetMinimumBalanceTier calls SetMinimumBalanceTierFunc
+func (mock *MockService) SetMinimumBalanceTier(tier string, minimums map[string]decimal.Decimal) error {
+	mock.record("SetMinimumBalanceTier", tier, minimums)
+	if mock.SetMinimumBalanceTierFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetMinimumBalanceTierFunc(tier, minimums)
+}
+
+// SetNotificationPreferences calls SetNotificationPreferencesFunc
+func (mock *MockService) SetNotificationPreferences(userID string, prefs wallet.NotificationPreferences) error {
+	mock.record("SetNotificationPreferences", userID, prefs)
+	if mock.SetNotificationPreferencesFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetNotificationPreferencesFunc(userID, prefs)
+}
+
+// SetOverdraftLimit calls SetOverdraftLimitFunc
+func (mock *MockService) SetOverdraftLimit(userID string, limit decimal.Decimal) error {
+	mock.record("SetOverdraftLimit", userID, limit)
+	if mock.SetOverdraftLimitFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetOverdraftLimitFunc(userID, limit)
+}
+
+// SetReceiptTemplate calls SetReceiptTemplateFunc
+func (mock *MockService) SetReceiptTemplate(tenant string, tmpl wallet.ReceiptTemplate) error {
+	mock.record("SetReceiptTemplate", tenant, tmpl)
+	if mock.SetReceiptTemplateFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetReceiptTemplateFunc(tenant, tmpl)
+}
+
+// SetReservePolicy calls SetReservePolicyFunc
+func (mock *MockService) SetReservePolicy(userID string, policy wallet.ReservePolicy) error {
+	mock.record("SetReservePolicy", userID, policy)
+	if mock.SetReservePolicyFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetReservePolicyFunc(userID, policy)
+}
+
+// SetSpendingLimits calls SetSpendingLimitsFunc
+func (mock *MockService) SetSpendingLimits(userID string, limits wallet.SpendingLimits) error {
+	mock.record("SetSpendingLimits", userID, limits)
+	if mock.SetSpendingLimitsFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetSpendingLimitsFunc(userID, limits)
+}
+
+// SetStaffRole calls SetStaffRoleFunc
+func (mock *MockService) SetStaffRole(staffID string, role wallet.StaffRole) error {
+	mock.record("SetStaffRole", staffID, role)
+	if mock.SetStaffRoleFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetStaffRoleFunc(staffID, role)
+}
+
+// SetStatementDescriptor calls SetStatementDescriptorFunc
+func (mock *MockService) SetStatementDescriptor(userID string, descriptor string, actorID string) error {
+	mock.record("SetStatementDescriptor", userID, descriptor, actorID)
+	if mock.SetStatementDescriptorFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetStatementDescriptorFunc(userID, descriptor, actorID)
+}
+
+// SetUserAttributes calls SetUserAttributesFunc
+func (mock *MockService) SetUserAttributes(userID string, attrs wallet.UserAttributes) error {
+	mock.record("SetUserAttributes", userID, attrs)
+	if mock.SetUserAttributesFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetUserAttributesFunc(userID, attrs)
+}
+
+// SetWebhookSchemaVersion calls SetWebhookSchemaVersionFunc
+func (mock *MockService) SetWebhookSchemaVersion(subscriptionID string, eventType wallet.EventType, version int) error {
+	mock.record("SetWebhookSchemaVersion", subscriptionID, eventType, version)
+	if mock.SetWebhookSchemaVersionFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SetWebhookSchemaVersionFunc(subscriptionID, eventType, version)
+}
+
+// SettleOrder calls SettleOrderFunc
+func (mock *MockService) SettleOrder(buyerID string, orderRef string, total decimal.Decimal, splits []wallet.Split) (*wallet.OrderSettlement, error) {
+	mock.record("SettleOrder", buyerID, orderRef, total, splits)
+	if mock.SettleOrderFunc == nil {
+		var r0 *wallet.OrderSettlement
+		return r0, ErrNotConfigured
+	}
+	return mock.SettleOrderFunc(buyerID, orderRef, total, splits)
+}
+
+// SettleOrderContext calls SettleOrderContextFunc
+func (mock *MockService) SettleOrderContext(ctx context.Context, buyerID string, orderRef string, total decimal.Decimal, splits []wallet.Split) (*wallet.OrderSettlement, error) {
+	mock.record("SettleOrderContext", ctx, buyerID, orderRef, total, splits)
+	if mock.SettleOrderContextFunc == nil {
+		var r0 *wallet.OrderSettlement
+		return r0, ErrNotConfigured
+	}
+	return mock.SettleOrderContextFunc(ctx, buyerID, orderRef, total, splits)
+}
+
+// SettleReservation calls SettleReservationFunc
+func (mock *MockService) SettleReservation(reservationID string, amount decimal.Decimal, reference string) (*wallet.Reservation, error) {
+	mock.record("SettleReservation", reservationID, amount, reference)
+	if mock.SettleReservationFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.SettleReservationFunc(reservationID, amount, reference)
+}
+
+// SettleReservationContext calls SettleReservationContextFunc
+func (mock *MockService) SettleReservationContext(ctx context.Context, reservationID string, amount decimal.Decimal, reference string) (*wallet.Reservation, error) {
+	mock.record("SettleReservationContext", ctx, reservationID, amount, reference)
+	if mock.SettleReservationContextFunc == nil {
+		var r0 *wallet.Reservation
+		return r0, ErrNotConfigured
+	}
+	return mock.SettleReservationContextFunc(ctx, reservationID, amount, reference)
+}
+
+// SettlementDate calls SettlementDateFunc
+func (mock *MockService) SettlementDate(t time.Time, lag int) time.Time {
+	mock.record("SettlementDate", t, lag)
+	if mock.SettlementDateFunc == nil {
+		var r0 time.Time
+		return r0
+	}
+	return mock.SettlementDateFunc(t, lag)
+}
+
+// SkipJobOccurrence calls SkipJobOccurrenceFunc
+func (mock *MockService) SkipJobOccurrence(jobID string, runAt int64, actor string, reason string) error {
+	mock.record("SkipJobOccurrence", jobID, runAt, actor, reason)
+	if mock.SkipJobOccurrenceFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SkipJobOccurrenceFunc(jobID, runAt, actor, reason)
+}
+
+// SkipNextConversion calls SkipNextConversionFunc
+func (mock *MockService) SkipNextConversion(orderID string, userID string) error {
+	mock.record("SkipNextConversion", orderID, userID)
+	if mock.SkipNextConversionFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.SkipNextConversionFunc(orderID, userID)
+}
+
+// Snapshot calls SnapshotFunc
+func (mock *MockService) Snapshot() *wallet.Snapshot {
+	mock.record("Snapshot")
+	if mock.SnapshotFunc == nil {
+		var r0 *wallet.Snapshot
+		return r0
+	}
+	return mock.SnapshotFunc()
+}
+
+// SnapshotOnline calls SnapshotOnlineFunc
+func (mock *MockService) SnapshotOnline() (*wallet.Snapshot, error) {
+	mock.record("SnapshotOnline")
+	if mock.SnapshotOnlineFunc == nil {
+		var r0 *wallet.Snapshot
+		return r0, ErrNotConfigured
+	}
+	return mock.SnapshotOnlineFunc()
+}
+
+// StartImpersonation calls StartImpersonationFunc
+func (mock *MockService) StartImpersonation(req wallet.ImpersonationRequest) (*wallet.ImpersonationSession, error) {
+	mock.record("StartImpersonation", req)
+	if mock.StartImpersonationFunc == nil {
+		var r0 *wallet.ImpersonationSession
+		return r0, ErrNotConfigured
+	}
+	return mock.StartImpersonationFunc(req)
+}
+
+// StartScheduler calls StartSchedulerFunc
+func (mock *MockService) StartScheduler(interval time.Duration) {
+	mock.record("StartScheduler", interval)
+	if mock.StartSchedulerFunc == nil {
+		return
+	}
+	mock.StartSchedulerFunc(interval)
+}
+
+// StartWebhookDispatcher calls StartWebhookDispatcherFunc
+func (mock *MockService) StartWebhookDispatcher(interval time.Duration) {
+	mock.record("StartWebhookDispatcher", interval)
+	if mock.StartWebhookDispatcherFunc == nil {
+		return
+	}
+	mock.StartWebhookDispatcherFunc(interval)
+}
+
+// StatementDescriptor calls StatementDescriptorFunc
+func (mock *MockService) StatementDescriptor(userID string) string {
+	mock.record("StatementDescriptor", userID)
+	if mock.StatementDescriptorFunc == nil {
+		var r0 string
+		return r0
+	}
+	return mock.StatementDescriptorFunc(userID)
+}
+
+// StatementDescriptorHistory calls StatementDescriptorHistoryFunc
+func (mock *MockService) StatementDescriptorHistory(userID string) ([]wallet.DescriptorChange, error) {
+	mock.record("StatementDescriptorHistory", userID)
+	if mock.StatementDescriptorHistoryFunc == nil {
+		var r0 []wallet.DescriptorChange
+		return r0, ErrNotConfigured
+	}
+	return mock.StatementDescriptorHistoryFunc(userID)
+}
+
+// StopScheduler calls StopSchedulerFunc
+func (mock *MockService) StopScheduler() {
+	mock.record("StopScheduler")
+	if mock.StopSchedulerFunc == nil {
+		return
+	}
+	mock.StopSchedulerFunc()
+}
+
+// StopWebhookDispatcher calls StopWebhookDispatcherFunc
+func (mock *MockService) StopWebhookDispatcher() {
+	mock.record("StopWebhookDispatcher")
+	if mock.StopWebhookDispatcherFunc == nil {
+		return
+	}
+	mock.StopWebhookDispatcherFunc()
+}
+
+// SubmitExpense calls SubmitExpenseFunc
+func (mock *MockService) SubmitExpense(orgID string, submitterID string, payeeID string, amount decimal.Decimal, description string) (*wallet.ExpenseRequest, error) {
+	mock.record("SubmitExpense", orgID, submitterID, payeeID, amount, description)
+	if mock.SubmitExpenseFunc == nil {
+		var r0 *wallet.ExpenseRequest
+		return r0, ErrNotConfigured
+	}
+	return mock.SubmitExpenseFunc(orgID, submitterID, payeeID, amount, description)
+}
+
+// SummarizeConversionOrder calls SummarizeConversionOrderFunc
+func (mock *MockService) SummarizeConversionOrder(orderID string, since time.Time, until time.Time) (*wallet.ConversionOrderSummary, error) {
+	mock.record("SummarizeConversionOrder", orderID, since, until)
+	if mock.SummarizeConversionOrderFunc == nil {
+		var r0 *wallet.ConversionOrderSummary
+		return r0, ErrNotConfigured
+	}
+	return mock.SummarizeConversionOrderFunc(orderID, since, until)
+}
+
+// TestClock calls TestClockFunc
+func (mock *MockService) TestClock() *wallet.SimClock {
+	mock.record("TestClock")
+	if mock.TestClockFunc == nil {
+		var r0 *wallet.SimClock
+		return r0
+	}
+	return mock.TestClockFunc()
+}
+
+// Transfer calls TransferFunc
+func (mock *MockService) Transfer(fromUserID string, toUserID string, amount float64, description string) error {
+	mock.record("Transfer", fromUserID, toUserID, amount, description)
+	if mock.TransferFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.TransferFunc(fromUserID, toUserID, amount, description)
+}
+
+// TransferClientTxID calls TransferClientTxIDFunc
+func (mock *MockService) TransferClientTxID(clientTxID string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("TransferClientTxID", clientTxID, fromUserID, toUserID, amount, description)
+	if mock.TransferClientTxIDFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.TransferClientTxIDFunc(clientTxID, fromUserID, toUserID, amount, description)
+}
+
+// TransferClientTxIDContext calls TransferClientTxIDContextFunc
+func (mock *MockService) TransferClientTxIDContext(ctx context.Context, clientTxID string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("TransferClientTxIDContext", ctx, clientTxID, fromUserID, toUserID, amount, description)
+	if mock.TransferClientTxIDContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.TransferClientTxIDContextFunc(ctx, clientTxID, fromUserID, toUserID, amount, description)
+}
+
+// TransferContext calls TransferContextFunc
+func (mock *MockService) TransferContext(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, description string) error {
+	mock.record("TransferContext", ctx, fromUserID, toUserID, amount, description)
+	if mock.TransferContextFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.TransferContextFunc(ctx, fromUserID, toUserID, amount, description)
+}
+
+// TransferDecimal calls TransferDecimalFunc
+func (mock *MockService) TransferDecimal(fromUserID string, toUserID string, amount decimal.Decimal, description string) error {
+	mock.record("TransferDecimal", fromUserID, toUserID, amount, description)
+	if mock.TransferDecimalFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.TransferDecimalFunc(fromUserID, toUserID, amount, description)
+}
+
+// TransferIdempotent calls TransferIdempotentFunc
+func (mock *MockService) TransferIdempotent(key string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("TransferIdempotent", key, fromUserID, toUserID, amount, description)
+	if mock.TransferIdempotentFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.TransferIdempotentFunc(key, fromUserID, toUserID, amount, description)
+}
+
+// TransferIdempotentContext calls TransferIdempotentContextFunc
+func (mock *MockService) TransferIdempotentContext(ctx context.Context, key string, fromUserID string, toUserID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("TransferIdempotentContext", ctx, key, fromUserID, toUserID, amount, description)
+	if mock.TransferIdempotentContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.TransferIdempotentContextFunc(ctx, key, fromUserID, toUserID, amount, description)
+}
+
+// TransferWithFloor calls TransferWithFloorFunc
+func (mock *MockService) TransferWithFloor(fromUserID string, toUserID string, amount decimal.Decimal, minRemaining decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("TransferWithFloor", fromUserID, toUserID, amount, minRemaining)
+	if mock.TransferWithFloorFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.TransferWithFloorFunc(fromUserID, toUserID, amount, minRemaining)
+}
+
+// TransferWithFloorContext calls TransferWithFloorContextFunc
+func (mock *MockService) TransferWithFloorContext(ctx context.Context, fromUserID string, toUserID string, amount decimal.Decimal, minRemaining decimal.Decimal) (*wallet.Transaction, error) {
+	mock.record("TransferWithFloorContext", ctx, fromUserID, toUserID, amount, minRemaining)
+	if mock.TransferWithFloorContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.TransferWithFloorContextFunc(ctx, fromUserID, toUserID, amount, minRemaining)
+}
+
+// TriggerJob calls TriggerJobFunc
+func (mock *MockService) TriggerJob(jobID string, actor string, reason string) (wallet.JobResult, error) {
+	mock.record("TriggerJob", jobID, actor, reason)
+	if mock.TriggerJobFunc == nil {
+		var r0 wallet.JobResult
+		return r0, ErrNotConfigured
+	}
+	return mock.TriggerJobFunc(jobID, actor, reason)
+}
+
+// UnblockUser calls UnblockUserFunc
+func (mock *MockService) UnblockUser(userID string, blockedUserID string) error {
+	mock.record("UnblockUser", userID, blockedUserID)
+	if mock.UnblockUserFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.UnblockUserFunc(userID, blockedUserID)
+}
+
+// UnfreezeCard calls UnfreezeCardFunc
+func (mock *MockService) UnfreezeCard(cardID string, userID string) error {
+	mock.record("UnfreezeCard", cardID, userID)
+	if mock.UnfreezeCardFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.UnfreezeCardFunc(cardID, userID)
+}
+
+// UnfreezeSegment calls UnfreezeSegmentFunc
+func (mock *MockService) UnfreezeSegment(req wallet.SegmentActionRequest) (*wallet.SegmentAction, error) {
+	mock.record("UnfreezeSegment", req)
+	if mock.UnfreezeSegmentFunc == nil {
+		var r0 *wallet.SegmentAction
+		return r0, ErrNotConfigured
+	}
+	return mock.UnfreezeSegmentFunc(req)
+}
+
+// UnfreezeSegmentContext calls UnfreezeSegmentContextFunc
+func (mock *MockService) UnfreezeSegmentContext(ctx context.Context, req wallet.SegmentActionRequest) (*wallet.SegmentAction, error) {
+	mock.record("UnfreezeSegmentContext", ctx, req)
+	if mock.UnfreezeSegmentContextFunc == nil {
+		var r0 *wallet.SegmentAction
+		return r0, ErrNotConfigured
+	}
+	return mock.UnfreezeSegmentContextFunc(ctx, req)
+}
+
+// UnlinkIdentity calls UnlinkIdentityFunc
+func (mock *MockService) UnlinkIdentity(userID string, identity wallet.ExternalIdentity) error {
+	mock.record("UnlinkIdentity", userID, identity)
+	if mock.UnlinkIdentityFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.UnlinkIdentityFunc(userID, identity)
+}
+
+// UnregisterWebhook calls UnregisterWebhookFunc
+func (mock *MockService) UnregisterWebhook(subscriptionID string) error {
+	mock.record("UnregisterWebhook", subscriptionID)
+	if mock.UnregisterWebhookFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.UnregisterWebhookFunc(subscriptionID)
+}
+
+// UpdateFavorite calls UpdateFavoriteFunc
+func (mock *MockService) UpdateFavorite(userID string, favoriteID string, spec wallet.FavoriteSpec) (*wallet.Favorite, error) {
+	mock.record("UpdateFavorite", userID, favoriteID, spec)
+	if mock.UpdateFavoriteFunc == nil {
+		var r0 *wallet.Favorite
+		return r0, ErrNotConfigured
+	}
+	return mock.UpdateFavoriteFunc(userID, favoriteID, spec)
+}
+
+// UpdateUserEmail calls UpdateUserEmailFunc
+func (mock *MockService) UpdateUserEmail(userID string, email string) error {
+	mock.record("UpdateUserEmail", userID, email)
+	if mock.UpdateUserEmailFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.UpdateUserEmailFunc(userID, email)
+}
+
+// VerifyWithdrawalDestination calls VerifyWithdrawalDestinationFunc
+func (mock *MockService) VerifyWithdrawalDestination(userID string, destinationID string) error {
+	mock.record("VerifyWithdrawalDestination", userID, destinationID)
+	if mock.VerifyWithdrawalDestinationFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.VerifyWithdrawalDestinationFunc(userID, destinationID)
+}
+
+// WaiveMinimumBalance calls WaiveMinimumBalanceFunc
+func (mock *MockService) WaiveMinimumBalance(userID string, actor string, reason string) error {
+	mock.record("WaiveMinimumBalance", userID, actor, reason)
+	if mock.WaiveMinimumBalanceFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.WaiveMinimumBalanceFunc(userID, actor, reason)
+}
+
+// Withdraw calls WithdrawFunc
+func (mock *MockService) Withdraw(userID string, amount float64, description string) error {
+	mock.record("Withdraw", userID, amount, description)
+	if mock.WithdrawFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.WithdrawFunc(userID, amount, description)
+}
+
+// WithdrawConsent calls WithdrawConsentFunc
+func (mock *MockService) WithdrawConsent(userID string, kind wallet.ConsentKind) error {
+	mock.record("WithdrawConsent", userID, kind)
+	if mock.WithdrawConsentFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.WithdrawConsentFunc(userID, kind)
+}
+
+// WithdrawContext calls WithdrawContextFunc
+func (mock *MockService) WithdrawContext(ctx context.Context, userID string, decimalAmount decimal.Decimal, description string) error {
+	mock.record("WithdrawContext", ctx, userID, decimalAmount, description)
+	if mock.WithdrawContextFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.WithdrawContextFunc(ctx, userID, decimalAmount, description)
+}
+
+// WithdrawDecimal calls WithdrawDecimalFunc
+func (mock *MockService) WithdrawDecimal(userID string, decimalAmount decimal.Decimal, description string) error {
+	mock.record("WithdrawDecimal", userID, decimalAmount, description)
+	if mock.WithdrawDecimalFunc == nil {
+		return ErrNotConfigured
+	}
+	return mock.WithdrawDecimalFunc(userID, decimalAmount, description)
+}
+
+// WithdrawIdempotent calls WithdrawIdempotentFunc
+func (mock *MockService) WithdrawIdempotent(key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("WithdrawIdempotent", key, userID, amount, description)
+	if mock.WithdrawIdempotentFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.WithdrawIdempotentFunc(key, userID, amount, description)
+}
+
+// WithdrawIdempotentContext calls WithdrawIdempotentContextFunc
+func (mock *MockService) WithdrawIdempotentContext(ctx context.Context, key string, userID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("WithdrawIdempotentContext", ctx, key, userID, amount, description)
+	if mock.WithdrawIdempotentContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.WithdrawIdempotentContextFunc(ctx, key, userID, amount, description)
+}
+
+// WithdrawTo calls WithdrawToFunc
+func (mock *MockService) WithdrawTo(userID string, destinationID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("WithdrawTo", userID, destinationID, amount, description)
+	if mock.WithdrawToFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.WithdrawToFunc(userID, destinationID, amount, description)
+}
+
+// WithdrawToContext calls WithdrawToContextFunc
+func (mock *MockService) WithdrawToContext(ctx context.Context, userID string, destinationID string, amount decimal.Decimal, description string) (*wallet.Transaction, error) {
+	mock.record("WithdrawToContext", ctx, userID, destinationID, amount, description)
+	if mock.WithdrawToContextFunc == nil {
+		var r0 *wallet.Transaction
+		return r0, ErrNotConfigured
+	}
+	return mock.WithdrawToContextFunc(ctx, userID, destinationID, amount, description)
+}
@@ -0,0 +1,68 @@
+// internal/wallet/wallettest/mock_test.go
+package wallettest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"wallet-app/internal/wallet"
+)
+
+// payRent stands in for downstream code written against wallet.Service
+func payRent(svc wallet.Service, tenant, landlord string) error {
+	balance, err := svc.GetBalanceDecimal(tenant)
+	if err != nil {
+		return err
+	}
+	if balance.LessThan(decimal.NewFromInt(500)) {
+		return wallet.ErrInsufficientBalance
+	}
+	return svc.TransferDecimal(tenant, landlord, decimal.NewFromInt(500), "rent")
+}
+
+func TestMockService(t *testing.T) {
+	tests := []struct {
+		name      string
+		balance   int64
+		wantErr   error
+		transfers int
+	}{
+		{"enough", 600, nil, 1},
+		{"short", 100, wallet.ErrInsufficientBalance, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockService{
+				GetBalanceDecimalFunc: func(userID string) (decimal.Decimal, error) {
+					return decimal.NewFromInt(tt.balance), nil
+				},
+				TransferDecimalFunc: func(fromUserID, toUserID string, amount decimal.Decimal, description string) error {
+					return nil
+				},
+			}
+			if err := payRent(mock, "tenant", "landlord"); err != tt.wantErr {
+				t.Fatalf("payRent() error = %v, want %v", err, tt.wantErr)
+			}
+			calls := mock.Calls("TransferDecimal")
+			if len(calls) != tt.transfers {
+				t.Fatalf("TransferDecimal calls = %+v, want %d", calls, tt.transfers)
+			}
+			if tt.transfers > 0 && (calls[0].Args[0] != "tenant" || calls[0].Args[1] != "landlord") {
+				t.Errorf("TransferDecimal args = %v", calls[0].Args)
+			}
+		})
+	}
+
+	var mock MockService
+	if err := mock.Deposit("alice", 1, "x"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("unset Deposit() error = %v, want %v", err, ErrNotConfigured)
+	}
+	if len(mock.Calls("")) != 1 {
+		t.Errorf("Calls() = %+v, want the unset call recorded", mock.Calls(""))
+	}
+	mock.Reset()
+	if len(mock.Calls("")) != 0 {
+		t.Errorf("Calls() after Reset = %+v, want none", mock.Calls(""))
+	}
+}
@@ -0,0 +1,179 @@
+// internal/wallet/webhookhttp.go
+package wallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP webhook delivery defaults
+const (
+	DefaultWebhookHTTPAttempts = 3
+	DefaultWebhookHTTPBackoff  = 200 * time.Millisecond
+	DefaultWebhookHTTPTimeout  = 10 * time.Second
+)
+
+// Headers set on HTTP webhook requests
+const (
+	WebhookSignatureHeader = "X-Wallet-Signature" // hex HMAC-SHA256 of the body under Secret
+	WebhookEventHeader     = "X-Wallet-Event"
+)
+
+// HTTPWebhookTransport delivers webhooks as JSON POSTs to URL. A delivery is handed
+// over once the endpoint answers 2xx. Network errors, 429 and 5xx answers are retried
+// up to MaxAttempts times with doubling backoff; a delivery still failing is left to
+// the next dispatch pass, which retries it again.
+type HTTPWebhookTransport struct {
+	URL         string
+	Secret      string       // signs each body when set
+	Client      *http.Client // a client with DefaultWebhookHTTPTimeout when nil
+	MaxAttempts int          // DefaultWebhookHTTPAttempts when unset
+	Backoff     time.Duration
+}
+
+// WebhookHTTPError reports an endpoint that refused a delivery
+type WebhookHTTPError struct {
+	StatusCode int
+}
+
+func (e *WebhookHTTPError) Error() string {
+	return fmt.Sprintf("webhook endpoint answered %d", e.StatusCode)
+}
+
+// webhookBody is the JSON body of an HTTP webhook request
+type webhookBody struct {
+	SubscriptionID string       `json:"subscription_id"`
+	EventID        string       `json:"event_id"`
+	EventType      EventType    `json:"event_type"`
+	Offset         int64        `json:"offset"`
+	AckToken       string       `json:"ack_token"`
+	Attempt        int          `json:"attempt"`
+	SchemaVersion  int          `json:"schema_version"`
+	Payload        EventPayload `json:"payload"`
+}
+
+// Deliver implements WebhookTransport
+func (t *HTTPWebhookTransport) Deliver(d WebhookDelivery) error {
+	body, err := json.Marshal(webhookBody{
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.Event.ID,
+		EventType:      d.Event.Type,
+		Offset:         d.Event.Offset,
+		AckToken:       d.AckToken,
+		Attempt:        d.Attempt,
+		SchemaVersion:  d.SchemaVersion,
+		Payload:        d.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	attempts := t.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultWebhookHTTPAttempts
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = DefaultWebhookHTTPBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := t.post(d.Event.Type, body)
+		if err == nil || !retry || attempt == attempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends one request and reports whether a failure is worth retrying
+func (t *HTTPWebhookTransport) post(eventType EventType, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(eventType))
+	if t.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(t.Secret, body))
+	}
+
+	client := t.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, &WebhookHTTPError{StatusCode: resp.StatusCode}
+	default:
+		return false, &WebhookHTTPError{StatusCode: resp.StatusCode}
+	}
+}
+
+// SignWebhook returns the signature of body under secret, as sent in
+// WebhookSignatureHeader. Receivers recompute it to authenticate a request.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StartWebhookDispatcher runs DispatchWebhooks in the background every interval, so
+// new events are delivered and failed deliveries retried without polling by the caller
+func (ws *WalletService) StartWebhookDispatcher(interval time.Duration) {
+	ws.webhooks.mu.Lock()
+	defer ws.webhooks.mu.Unlock()
+
+	if ws.webhooks.running {
+		return
+	}
+	ws.webhooks.running = true
+	ws.webhooks.stop = make(chan struct{})
+	ws.webhooks.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ws.DispatchWebhooks()
+			}
+		}
+	}(ws.webhooks.stop, ws.webhooks.done)
+}
+
+// StopWebhookDispatcher stops the background loop and waits for the current pass to
+// finish
+func (ws *WalletService) StopWebhookDispatcher() {
+	ws.webhooks.mu.Lock()
+	if !ws.webhooks.running {
+		ws.webhooks.mu.Unlock()
+		return
+	}
+	ws.webhooks.running = false
+	close(ws.webhooks.stop)
+	done := ws.webhooks.done
+	ws.webhooks.mu.Unlock()
+
+	<-done
+}
@@ -0,0 +1,83 @@
+// internal/wallet/webhookhttp_test.go
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPWebhookTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // answers in order; the last repeats
+		wantErr      bool
+		wantRequests int32
+	}{
+		{"accepted", []int{http.StatusOK}, false, 1},
+		{"retried after 503", []int{http.StatusServiceUnavailable, http.StatusNoContent}, false, 2},
+		{"gives up after attempts", []int{http.StatusInternalServerError}, true, 3},
+		{"client error not retried", []int{http.StatusBadRequest}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1))
+				body, _ := io.ReadAll(r.Body)
+				if r.Header.Get(WebhookSignatureHeader) != SignWebhook("s3cret", body) {
+					t.Error("bad signature")
+				}
+				var b webhookBody
+				if err := json.Unmarshal(body, &b); err != nil || b.EventType != EventDeposited || b.Payload == nil {
+					t.Errorf("body = %s, %v", body, err)
+				}
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			ws := NewWalletService()
+			ws.CreateUser("alice", "Alice", "a@example.com")
+			transport := &HTTPWebhookTransport{URL: srv.URL, Secret: "s3cret", Backoff: time.Millisecond}
+			subID, _ := ws.RegisterWebhook(transport, WebhookConfig{EventTypes: []EventType{EventDeposited}, AutoAck: true})
+			ws.Deposit("alice", 10, "seed")
+
+			ws.DispatchWebhooks()
+			sub, _ := ws.GetWebhookSubscription(subID)
+			if (sub.Failures > 0) != tt.wantErr || requests.Load() != tt.wantRequests {
+				t.Errorf("failures = %d after %d requests, want error %v after %d", sub.Failures, requests.Load(), tt.wantErr, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("alice", "Alice", "a@example.com")
+	delivered := make(chan WebhookDelivery, 1)
+	ws.RegisterWebhook(WebhookTransportFunc(func(d WebhookDelivery) error {
+		if d.Attempt == 1 {
+			return errors.New("endpoint down")
+		}
+		delivered <- d
+		return nil
+	}), WebhookConfig{AutoAck: true})
+
+	ws.StartWebhookDispatcher(time.Millisecond)
+	defer ws.StopWebhookDispatcher()
+	ws.Deposit("alice", 10, "seed")
+
+	select {
+	case d := <-delivered:
+		if d.Event.Type != EventDeposited || d.Attempt != 2 {
+			t.Errorf("delivery = %s attempt %d, want the deposit retried once", d.Event.Type, d.Attempt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher never retried the delivery")
+	}
+}
@@ -0,0 +1,429 @@
+// internal/wallet/webhooks.go
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Error definitions for webhook subscriptions
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidAckToken      = errors.New("invalid or stale acknowledgement token")
+	ErrInvalidOffset        = errors.New("invalid event offset")
+)
+
+// Webhook delivery defaults
+const (
+	DefaultWebhookMaxInFlight = 10
+	DefaultWebhookAckTimeout  = 30 * time.Second
+)
+
+// WebhookDelivery is one attempt to hand an event to a subscriber. Consumers dedupe on
+// Event.ID and acknowledge processing with AckToken.
+type WebhookDelivery struct {
+	SubscriptionID string
+	Event          Event
+	AckToken       string
+	Attempt        int
+
+	// Payload is Event encoded at the schema version negotiated for the subscriber
+	Payload       EventPayload
+	SchemaVersion int
+}
+
+// WebhookTransport hands deliveries to a subscriber (HTTP, queue, in-process, ...).
+// A nil error means the delivery was handed over, not that it was processed.
+type WebhookTransport interface {
+	Deliver(d WebhookDelivery) error
+}
+
+// WebhookTransportFunc adapts a plain function to the WebhookTransport interface
+type WebhookTransportFunc func(d WebhookDelivery) error
+
+// Deliver calls f(d)
+func (f WebhookTransportFunc) Deliver(d WebhookDelivery) error {
+	return f(d)
+}
+
+// WebhookConfig controls which events a subscriber receives and how acks are handled
+type WebhookConfig struct {
+	EventTypes  []EventType   // empty subscribes to every event type
+	MaxInFlight int           // unacknowledged deliveries allowed at once
+	AckTimeout  time.Duration // redeliver if not acknowledged within this time
+	AutoAck     bool          // treat a successful Deliver as an acknowledgement (at-least-once)
+	FromOffset  int64         // first offset to deliver; 0 starts after the current head
+
+	// SchemaVersions chooses the payload version per event type. Types left out are
+	// pinned to the version current at registration.
+	SchemaVersions map[EventType]int
+}
+
+// WebhookSubscription describes a registered subscriber and its position in the event log
+type WebhookSubscription struct {
+	ID          string
+	Config      WebhookConfig
+	AckedOffset int64 // every matching event up to and including this offset is acknowledged
+	InFlight    int
+	Delivered   int64
+	Failures    int64
+}
+
+// inflightDelivery is a delivery awaiting acknowledgement
+type inflightDelivery struct {
+	delivery WebhookDelivery
+	sentAt   time.Time
+}
+
+// webhookSubscriber is the mutable state behind a subscription
+type webhookSubscriber struct {
+	mu        sync.Mutex
+	sub       WebhookSubscription
+	transport WebhookTransport
+	types     map[EventType]bool
+	sentUpTo  int64                       // highest offset handed to the transport
+	inflight  map[int64]*inflightDelivery // by event offset
+	tokens    map[string]int64            // ack token -> event offset
+}
+
+// webhookRegistry holds all webhook subscribers
+type webhookRegistry struct {
+	mu   sync.RWMutex
+	subs map[string]*webhookSubscriber
+
+	// background dispatcher started by StartWebhookDispatcher
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// RegisterWebhook subscribes a transport to the event log and returns the subscription ID
+func (ws *WalletService) RegisterWebhook(transport WebhookTransport, cfg WebhookConfig) (string, error) {
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = DefaultWebhookMaxInFlight
+	}
+	if cfg.AckTimeout <= 0 {
+		cfg.AckTimeout = DefaultWebhookAckTimeout
+	}
+
+	versions, err := ws.pinSchemaVersions(cfg.SchemaVersions)
+	if err != nil {
+		return "", err
+	}
+	cfg.SchemaVersions = versions
+
+	start := cfg.FromOffset - 1
+	if cfg.FromOffset == 0 {
+		start = ws.LatestEventOffset()
+	}
+	if start < 0 || start > ws.LatestEventOffset() {
+		return "", ErrInvalidOffset
+	}
+
+	s := &webhookSubscriber{
+		sub:       WebhookSubscription{ID: ws.newID("whsub"), Config: cfg, AckedOffset: start},
+		transport: transport,
+		sentUpTo:  start,
+		inflight:  make(map[int64]*inflightDelivery),
+		tokens:    make(map[string]int64),
+	}
+	if len(cfg.EventTypes) > 0 {
+		s.types = make(map[EventType]bool, len(cfg.EventTypes))
+		for _, t := range cfg.EventTypes {
+			s.types[t] = true
+		}
+	}
+
+	ws.webhooks.mu.Lock()
+	defer ws.webhooks.mu.Unlock()
+
+	if ws.webhooks.subs == nil {
+		ws.webhooks.subs = make(map[string]*webhookSubscriber)
+	}
+	ws.webhooks.subs[s.sub.ID] = s
+
+	return s.sub.ID, nil
+}
+
+// UnregisterWebhook removes a subscription
+func (ws *WalletService) UnregisterWebhook(subscriptionID string) error {
+	ws.webhooks.mu.Lock()
+	defer ws.webhooks.mu.Unlock()
+
+	if _, exists := ws.webhooks.subs[subscriptionID]; !exists {
+		return ErrSubscriptionNotFound
+	}
+	delete(ws.webhooks.subs, subscriptionID)
+	return nil
+}
+
+// GetWebhookSubscription returns a subscription's current state
+func (ws *WalletService) GetWebhookSubscription(subscriptionID string) (*WebhookSubscription, error) {
+	s, err := ws.webhookSubscriber(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.sub
+	sub.InFlight = len(s.inflight)
+	return &sub, nil
+}
+
+// DispatchWebhooks performs one delivery pass over every subscription: unacknowledged
+// deliveries past their ack timeout are retried, then new events are sent up to each
+// subscriber's in-flight limit. It returns the number of deliveries attempted.
+func (ws *WalletService) DispatchWebhooks() int {
+	ws.webhooks.mu.RLock()
+	subs := make([]*webhookSubscriber, 0, len(ws.webhooks.subs))
+	for _, s := range ws.webhooks.subs {
+		subs = append(subs, s)
+	}
+	ws.webhooks.mu.RUnlock()
+
+	attempts := 0
+	for _, s := range subs {
+		attempts += ws.dispatchTo(s)
+	}
+	return attempts
+}
+
+// dispatchTo runs one delivery pass for a single subscriber. Deliveries are planned
+// under the subscriber lock and sent outside it, so transports may acknowledge
+// synchronously from within Deliver.
+func (ws *WalletService) dispatchTo(s *webhookSubscriber) int {
+	batch := ws.planDeliveries(s)
+
+	for i, d := range batch {
+		err := s.transport.Deliver(d)
+
+		s.mu.Lock()
+		if err != nil {
+			s.sub.Failures++
+			// Leave this and the unsent remainder due for the next pass
+			for _, pending := range batch[i:] {
+				if inflight, ok := s.inflight[pending.Event.Offset]; ok {
+					inflight.sentAt = time.Time{}
+				}
+			}
+			s.mu.Unlock()
+			return i + 1
+		}
+		s.sub.Delivered++
+		if s.sub.Config.AutoAck {
+			delete(s.tokens, d.AckToken)
+			delete(s.inflight, d.Event.Offset)
+			s.advanceAcked()
+		}
+		s.mu.Unlock()
+	}
+
+	return len(batch)
+}
+
+// planDeliveries selects timed-out deliveries to retry and new events to send, registering
+// each with a fresh ack token
+func (ws *WalletService) planDeliveries(s *webhookSubscriber) []WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := ws.now()
+	var batch []WebhookDelivery
+
+	offsets := make([]int64, 0, len(s.inflight))
+	for offset, d := range s.inflight {
+		if d.sentAt.IsZero() || now.Sub(d.sentAt) >= s.sub.Config.AckTimeout {
+			offsets = append(offsets, offset)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	for _, offset := range offsets {
+		batch = append(batch, ws.encodeDelivery(s, s.track(s.inflight[offset].delivery.Event, now, ws.newID("ack"))))
+	}
+
+	for len(s.inflight) < s.sub.Config.MaxInFlight {
+		next := ws.EventsSince(s.sentUpTo, s.sub.Config.MaxInFlight-len(s.inflight))
+		if len(next) == 0 {
+			break
+		}
+		for _, evt := range next {
+			s.sentUpTo = evt.Offset
+			if s.types == nil || s.types[evt.Type] {
+				batch = append(batch, ws.encodeDelivery(s, s.track(evt, now, ws.newID("ack"))))
+			}
+		}
+	}
+	s.advanceAcked()
+
+	return batch
+}
+
+// encodeDelivery fills in the payload at the subscriber's schema version. A schema that
+// fails to encode leaves Payload nil and is counted. Caller holds s.mu.
+func (ws *WalletService) encodeDelivery(s *webhookSubscriber, d WebhookDelivery) WebhookDelivery {
+	version := s.sub.Config.SchemaVersions[d.Event.Type]
+	if version == 0 {
+		version = 1
+	}
+	payload, version, err := ws.EncodeEvent(d.Event, version)
+	if err != nil {
+		ws.metrics.IncCounter("webhook_encode_failures_total", map[string]string{"event_type": string(d.Event.Type)})
+		return d
+	}
+	d.Payload, d.SchemaVersion = payload, version
+	return d
+}
+
+// SetWebhookSchemaVersion moves a subscription to another payload version of an event
+// type, e.g. once the consumer has been upgraded. Deliveries planned from then on use it.
+func (ws *WalletService) SetWebhookSchemaVersion(subscriptionID string, eventType EventType, version int) error {
+	if version < 1 || version > ws.LatestSchemaVersion(eventType) {
+		return ErrSchemaNotFound
+	}
+	s, err := ws.webhookSubscriber(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := make(map[EventType]int, len(s.sub.Config.SchemaVersions)+1)
+	for t, v := range s.sub.Config.SchemaVersions {
+		versions[t] = v
+	}
+	versions[eventType] = version
+	s.sub.Config.SchemaVersions = versions
+	return nil
+}
+
+// track registers a (re)delivery of evt with a fresh ack token, invalidating any token
+// issued for an earlier attempt. Caller holds s.mu.
+func (s *webhookSubscriber) track(evt Event, now time.Time, ackToken string) WebhookDelivery {
+	d := &inflightDelivery{
+		delivery: WebhookDelivery{
+			SubscriptionID: s.sub.ID,
+			Event:          evt,
+			AckToken:       ackToken,
+			Attempt:        1,
+		},
+		sentAt: now,
+	}
+	if prev, retry := s.inflight[evt.Offset]; retry {
+		d.delivery.Attempt = prev.delivery.Attempt + 1
+		delete(s.tokens, prev.delivery.AckToken)
+	}
+	s.inflight[evt.Offset] = d
+	s.tokens[d.delivery.AckToken] = evt.Offset
+
+	return d.delivery
+}
+
+// advanceAcked moves AckedOffset up to just before the oldest unacknowledged delivery.
+// Caller holds s.mu.
+func (s *webhookSubscriber) advanceAcked() {
+	acked := s.sentUpTo
+	for offset := range s.inflight {
+		if offset-1 < acked {
+			acked = offset - 1
+		}
+	}
+	if acked > s.sub.AckedOffset {
+		s.sub.AckedOffset = acked
+	}
+}
+
+// AckWebhook acknowledges a delivery. Acking the same token twice, or a token replaced
+// by a redelivery, returns ErrInvalidAckToken and has no effect.
+func (ws *WalletService) AckWebhook(subscriptionID, ackToken string) error {
+	s, err := ws.webhookSubscriber(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, ok := s.tokens[ackToken]
+	if !ok {
+		return ErrInvalidAckToken
+	}
+	delete(s.tokens, ackToken)
+	delete(s.inflight, offset)
+	s.advanceAcked()
+
+	return nil
+}
+
+// ReplayWebhook rewinds a subscription so that delivery restarts at fromOffset.
+// Outstanding deliveries are discarded and their ack tokens invalidated.
+func (ws *WalletService) ReplayWebhook(subscriptionID string, fromOffset int64) error {
+	if fromOffset < 1 || fromOffset > ws.LatestEventOffset()+1 {
+		return ErrInvalidOffset
+	}
+
+	s, err := ws.webhookSubscriber(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sub.AckedOffset = fromOffset - 1
+	s.sentUpTo = fromOffset - 1
+	s.inflight = make(map[int64]*inflightDelivery)
+	s.tokens = make(map[string]int64)
+
+	return nil
+}
+
+// webhookSubscriber looks up a subscriber by ID
+func (ws *WalletService) webhookSubscriber(subscriptionID string) (*webhookSubscriber, error) {
+	ws.webhooks.mu.RLock()
+	defer ws.webhooks.mu.RUnlock()
+
+	s, exists := ws.webhooks.subs[subscriptionID]
+	if !exists {
+		return nil, ErrSubscriptionNotFound
+	}
+	return s, nil
+}
+
+// EventDeduplicator helps consumers process each event once by remembering the IDs
+// seen within a sliding window of the most recent events
+type EventDeduplicator struct {
+	mu     sync.Mutex
+	window int
+	seen   map[string]bool
+	order  []string
+}
+
+// NewEventDeduplicator creates a deduplicator remembering the last window event IDs
+func NewEventDeduplicator(window int) *EventDeduplicator {
+	if window <= 0 {
+		window = 1000
+	}
+	return &EventDeduplicator{window: window, seen: make(map[string]bool)}
+}
+
+// FirstDelivery records eventID and reports whether it had not been seen in the window
+func (d *EventDeduplicator) FirstDelivery(eventID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen[eventID] {
+		return false
+	}
+	d.seen[eventID] = true
+	d.order = append(d.order, eventID)
+	if len(d.order) > d.window {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+// internal/wallet/webhooks_test.go
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingTransport captures deliveries and can be told to fail
+type recordingTransport struct {
+	deliveries []WebhookDelivery
+	fail       bool
+}
+
+func (r *recordingTransport) Deliver(d WebhookDelivery) error {
+	if r.fail {
+		return errors.New("endpoint unavailable")
+	}
+	r.deliveries = append(r.deliveries, d)
+	return nil
+}
+
+// TestWebhooks_AckAndRedelivery tests ack tokens, redelivery after timeout and offsets
+func TestWebhooks_AckAndRedelivery(t *testing.T) {
+	ws, clock := newTestService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+
+	transport := &recordingTransport{}
+	subID, err := ws.RegisterWebhook(transport, WebhookConfig{
+		EventTypes: []EventType{EventDeposited},
+		AckTimeout: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("RegisterWebhook() error = %v", err)
+	}
+
+	ws.Deposit("user1", 10.0, "first")
+	ws.Withdraw("user1", 1.0, "filtered out")
+	ws.Deposit("user1", 20.0, "second")
+
+	if n := ws.DispatchWebhooks(); n != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", n)
+	}
+	first, second := transport.deliveries[0], transport.deliveries[1]
+	if first.Event.Transaction.Description != "first" || second.Event.Type != EventDeposited {
+		t.Errorf("Unexpected deliveries %+v", transport.deliveries)
+	}
+
+	// Acking only the second event cannot move the offset past the unacked first one
+	if err := ws.AckWebhook(subID, second.AckToken); err != nil {
+		t.Fatalf("AckWebhook() error = %v", err)
+	}
+	sub, _ := ws.GetWebhookSubscription(subID)
+	if sub.AckedOffset >= first.Event.Offset {
+		t.Errorf("Offset advanced past unacked event: %d", sub.AckedOffset)
+	}
+
+	// Nothing is redelivered before the ack timeout
+	if n := ws.DispatchWebhooks(); n != 0 {
+		t.Errorf("Expected no deliveries before timeout, got %d", n)
+	}
+
+	clock.Advance(2 * time.Minute)
+	ws.DispatchWebhooks()
+	retry := transport.deliveries[2]
+	if retry.Event.ID != first.Event.ID || retry.Attempt != 2 {
+		t.Fatalf("Expected redelivery of first event, got %+v", retry)
+	}
+	if err := ws.AckWebhook(subID, first.AckToken); err != ErrInvalidAckToken {
+		t.Errorf("Expected stale token rejection, got %v", err)
+	}
+	if err := ws.AckWebhook(subID, retry.AckToken); err != nil {
+		t.Fatalf("AckWebhook() error = %v", err)
+	}
+	if err := ws.AckWebhook(subID, retry.AckToken); err != ErrInvalidAckToken {
+		t.Errorf("Expected duplicate ack rejection, got %v", err)
+	}
+
+	sub, _ = ws.GetWebhookSubscription(subID)
+	if sub.AckedOffset != ws.LatestEventOffset() || sub.InFlight != 0 {
+		t.Errorf("Expected fully acked subscription, got %+v", sub)
+	}
+
+	// Replay re-sends history from the requested offset
+	if err := ws.ReplayWebhook(subID, first.Event.Offset); err != nil {
+		t.Fatalf("ReplayWebhook() error = %v", err)
+	}
+	if n := ws.DispatchWebhooks(); n != 2 {
+		t.Errorf("Expected 2 replayed deliveries, got %d", n)
+	}
+}
+
+// TestWebhooks_EffectivelyOnce tests a synchronous consumer that dedupes and acks
+func TestWebhooks_EffectivelyOnce(t *testing.T) {
+	ws := NewWalletService()
+	ws.CreateUser("user1", "John Doe", "john@example.com")
+
+	dedup := NewEventDeduplicator(100)
+	processed := 0
+	failNext := true
+	var subID string
+	transport := WebhookTransportFunc(func(d WebhookDelivery) error {
+		if dedup.FirstDelivery(d.Event.ID) {
+			processed++
+		}
+		if failNext {
+			// Processed but the ack was lost in transit
+			failNext = false
+			return errors.New("connection reset")
+		}
+		return ws.AckWebhook(subID, d.AckToken)
+	})
+	subID, _ = ws.RegisterWebhook(transport, WebhookConfig{})
+
+	ws.Deposit("user1", 5.0, "deposit")
+	ws.DispatchWebhooks()
+	ws.DispatchWebhooks()
+
+	sub, _ := ws.GetWebhookSubscription(subID)
+	if processed != 1 || sub.Failures != 1 || sub.AckedOffset != ws.LatestEventOffset() {
+		t.Errorf("Expected one processing despite redelivery, got processed=%d sub=%+v", processed, sub)
+	}
+
+	if err := ws.ReplayWebhook(subID, 0); err != ErrInvalidOffset {
+		t.Errorf("Expected ErrInvalidOffset, got %v", err)
+	}
+	if err := ws.UnregisterWebhook(subID); err != nil {
+		t.Errorf("UnregisterWebhook() error = %v", err)
+	}
+	if _, err := ws.GetWebhookSubscription(subID); err != ErrSubscriptionNotFound {
+		t.Errorf("Expected ErrSubscriptionNotFound, got %v", err)
+	}
+}